@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/adapter/driven"
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+)
+
+// configCheckTimeout bounds each configuration check.
+const configCheckTimeout = 10 * time.Second
+
+// configChecks returns the checks verifying that cfg can be served: engines
+// reachable, storage writable, upstream URLs resolvable and, when
+// checkListen is set, the listen addresses free.
+func configChecks(cfg config, checkListen bool) []application.ConfigCheck {
+	discard := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var checks []application.ConfigCheck
+	for _, engineURL := range cfg.AceStreamEngineURLs {
+		engine := driven.NewAceStreamHTTPAdapter(engineURL, discard)
+		engine.SetAuth(cfg.AceStreamEngineAuth)
+		checks = append(checks, application.ConfigCheck{
+			Name:   "acestream_engine",
+			Target: engineURL,
+			Hint:   "Start the AceStream engine or point ACESTREAM_ENGINE_URL (or ACESTREAM_ENGINE_URLS) at a running one; if it requires credentials, check ACESTREAM_ENGINE_API_KEY, ACESTREAM_ENGINE_USERNAME and ACESTREAM_ENGINE_PASSWORD",
+			Check:  engine.Ping,
+		})
+	}
+
+	checks = append(checks,
+		application.ConfigCheck{
+			Name:   "db_path",
+			Target: cfg.DBPath,
+			Hint:   "Set DB_PATH to a file in a directory the server can write to",
+			Check:  func(ctx context.Context) error { return checkDirWritable(filepath.Dir(cfg.DBPath)) },
+		},
+		application.ConfigCheck{
+			Name:   "data_dir",
+			Target: cfg.DataDir,
+			Hint:   "Set DATA_DIR to a directory the server can write to",
+			Check:  func(ctx context.Context) error { return checkDirWritable(cfg.DataDir) },
+		},
+	)
+	if cfg.DBDriver == "sqlite" {
+		checks = append(checks, application.ConfigCheck{
+			Name:   "sqlite_path",
+			Target: cfg.SQLitePath,
+			Hint:   "Set SQLITE_PATH to a file in a directory the server can write to",
+			Check:  func(ctx context.Context) error { return checkDirWritable(filepath.Dir(cfg.SQLitePath)) },
+		})
+	}
+
+	for _, source := range []struct {
+		name, url, env string
+	}{
+		{"new_era", cfg.AcestreamSourceNewEraURL, "ACESTREAM_SOURCE_NEW_ERA"},
+		{"elcano", cfg.AcestreamSourceElcanoURL, "ACESTREAM_SOURCE_ELCANO"},
+	} {
+		// Behind a proxy only the proxy's own name has to resolve
+		target, hint := source.url, "Check "+source.env+"_URL and the DNS settings of the host"
+		if proxy := cfg.AcestreamSourceFetch[source.name].ProxyURL; proxy != "" {
+			target, hint = proxy, "Check "+source.env+"_PROXY and the DNS settings of the host"
+		}
+		checks = append(checks, application.ConfigCheck{
+			Name:   "source_" + source.name,
+			Target: target,
+			Hint:   hint,
+			Check:  func(ctx context.Context) error { return checkResolvable(ctx, target) },
+		})
+	}
+
+	checks = append(checks, application.ConfigCheck{
+		Name:   "epg_url",
+		Target: cfg.EPGURL,
+		Hint:   "Set EPG_URL to a reachable XMLTV guide",
+		Check:  func(ctx context.Context) error { return checkFetchable(ctx, cfg.EPGURL) },
+	})
+
+	if cfg.StreamLinkKeys != "" || cfg.StreamLinkRequired {
+		checks = append(checks, application.ConfigCheck{
+			Name:   "stream_link_keys",
+			Target: "STREAM_LINK_KEYS",
+			Hint:   "Set STREAM_LINK_KEYS to comma-separated id:secret pairs with unique IDs",
+			Check: func(ctx context.Context) error {
+				_, err := auth.ParseLinkKeys(cfg.StreamLinkKeys)
+				return err
+			},
+		})
+	}
+
+	if checkListen {
+		checks = append(checks, listenChecks(cfg)...)
+	}
+	return checks
+}
+
+// checkDirWritable creates and removes a file in dir.
+func checkDirWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".iptv-manager-check-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkResolvable resolves the host of rawURL.
+func checkResolvable(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("%q has no host", rawURL)
+	}
+	_, err = net.DefaultResolver.LookupHost(ctx, u.Hostname())
+	return err
+}
+
+// checkFetchable requests rawURL and expects a successful status, without
+// downloading the body.
+func checkFetchable(ctx context.Context, rawURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+	return nil
+}
+
+// listenChecks returns a check per listen address, or a single failing
+// check if LISTEN is malformed.
+func listenChecks(cfg config) []application.ConfigCheck {
+	addrs, err := cfg.listenAddrs()
+	if err != nil {
+		return []application.ConfigCheck{{
+			Name:   "listen",
+			Target: cfg.Listen,
+			Hint:   "Set LISTEN to comma-separated host:port or unix:<path> entries, each optionally followed by ;cert=<file>;key=<file>, and TLS_CERT together with TLS_KEY",
+			Check:  func(ctx context.Context) error { return err },
+		}}
+	}
+
+	var checks []application.ConfigCheck
+	for _, addr := range addrs {
+		name, hint := "port", "Stop whatever listens on the port or set PORT (or LISTEN) to a free one"
+		if addr.Network == "unix" {
+			name, hint = "socket", "Stop the server using the socket or set LISTEN to a path in a directory the server can write to"
+		}
+		if addr.CertFile != "" {
+			hint += "; check that the cert and key files exist and match"
+		}
+		checks = append(checks, application.ConfigCheck{
+			Name:   name,
+			Target: addr.String(),
+			Hint:   hint,
+			Check:  func(ctx context.Context) error { return checkListenFree(addr) },
+		})
+	}
+	return checks
+}
+
+// checkListenFree verifies that l can be bound, without keeping it, and that
+// its TLS key pair loads.
+func checkListenFree(l listenAddr) error {
+	if l.Network == "unix" {
+		if conn, err := net.Dial("unix", l.Address); err == nil {
+			conn.Close()
+			return fmt.Errorf("%s is in use", l.Address)
+		}
+		if err := checkDirWritable(filepath.Dir(l.Address)); err != nil {
+			return err
+		}
+	} else {
+		ln, err := net.Listen(l.Network, l.Address)
+		if err != nil {
+			return err
+		}
+		ln.Close()
+	}
+
+	if l.CertFile != "" {
+		if _, err := tls.LoadX509KeyPair(l.CertFile, l.KeyFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printConfigReport writes report as one line per check, followed by the
+// hint of each failed check.
+func printConfigReport(w io.Writer, report application.ConfigReport) {
+	for _, c := range report.Checks {
+		if c.Status == "ok" {
+			fmt.Fprintf(w, "ok     %-18s %s\n", c.Name, c.Target)
+			continue
+		}
+		fmt.Fprintf(w, "error  %-18s %s: %s\n", c.Name, c.Target, c.Error)
+		fmt.Fprintf(w, "       %-18s %s\n", "", c.Hint)
+	}
+	fmt.Fprintf(w, "configuration %s\n", report.Status)
+}
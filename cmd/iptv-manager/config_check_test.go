@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+func TestConfigChecks(t *testing.T) {
+	engine := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"version":"3.1"}}`))
+	}))
+	defer engine.Close()
+	epg := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/guide.xml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("<tv></tv>"))
+	}))
+	defer epg.Close()
+
+	busy, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer busy.Close()
+	_, busyPort, _ := net.SplitHostPort(busy.Addr().String())
+
+	dir := t.TempDir()
+	cfg := config{
+		Port:                     busyPort,
+		AceStreamEngineURLs:      []string{engine.URL},
+		EPGURL:                   epg.URL + "/missing.xml",
+		DBPath:                   filepath.Join(dir, "iptv-manager.db"),
+		DataDir:                  filepath.Join(dir, "data"),
+		AcestreamSourceNewEraURL: "http://localhost/list.m3u",
+		AcestreamSourceElcanoURL: "not a url",
+	}
+
+	validator := application.NewConfigValidator(func() []application.ConfigCheck {
+		return configChecks(cfg, true)
+	}, 5*time.Second)
+	report := validator.Validate(context.Background())
+
+	status := make(map[string]string)
+	for _, c := range report.Checks {
+		status[c.Name] = c.Status
+	}
+	want := map[string]string{
+		"acestream_engine": "ok",
+		"db_path":          "ok",
+		"data_dir":         "ok",
+		"source_new_era":   "ok",
+		"source_elcano":    "error",
+		"epg_url":          "error",
+		"port":             "error",
+	}
+	for name, s := range want {
+		if status[name] != s {
+			t.Errorf("expected %s to be %s, got %q", name, s, status[name])
+		}
+	}
+	if report.Status != "error" {
+		t.Errorf("expected the report to fail, got %q", report.Status)
+	}
+
+	var out bytes.Buffer
+	printConfigReport(&out, report)
+	if !strings.Contains(out.String(), "Set EPG_URL") || !strings.HasSuffix(out.String(), "configuration error\n") {
+		t.Errorf("unexpected report output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/yamlcodec"
+)
+
+// configFile holds the settings of a config file, keyed by the environment
+// variable each one stands in for.
+type configFile map[string]string
+
+// getenv returns the environment variable if it is set and not empty, and
+// otherwise the config file's value, so the environment takes precedence.
+func (f configFile) getenv(key string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return f[key]
+}
+
+// lookupEnv is like os.LookupEnv, falling back to the config file when the
+// environment variable is not set at all.
+func (f configFile) lookupEnv(key string) (string, bool) {
+	if v, ok := os.LookupEnv(key); ok {
+		return v, true
+	}
+	v, ok := f[key]
+	return v, ok
+}
+
+// secret is like getenv, but when key has no value it reads one from the file
+// named by key_FILE, as mounted by Docker or Kubernetes secrets, so that
+// credentials need not sit in the environment. Trailing newlines are trimmed
+// and a file that cannot be read yields no value.
+func (f configFile) secret(key string) string {
+	if v := f.getenv(key); v != "" {
+		return v
+	}
+	path := f.getenv(key + "_FILE")
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(string(data), "\r\n")
+}
+
+// readConfigFile reads a config file (see parseConfigFile). A missing file
+// yields no settings, so the file is optional.
+func readConfigFile(path string) (configFile, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return configFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	settings, err := parseConfigFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// parseConfigFile parses config.yaml: a YAML mapping of settings, one per
+// line. Keys are the environment variable names in any case, e.g.
+// "log_level: debug" sets LOG_LEVEL. Lists, either "[a, b]" or "- item"
+// lines under an empty key, become comma-separated values; nested settings
+// are rejected.
+func parseConfigFile(r io.Reader) (configFile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	node, err := yamlcodec.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := configFile{}
+	if node == nil {
+		return settings, nil
+	}
+	m, ok := node.(yamlcodec.Mapping)
+	if !ok {
+		return nil, errors.New(`expected "key: value" settings`)
+	}
+	for _, e := range m {
+		if e.Key == "" {
+			return nil, errors.New("empty setting name")
+		}
+		value, err := configValue(e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Key, err)
+		}
+		settings[strings.ToUpper(e.Key)] = value
+	}
+	return settings, nil
+}
+
+// configValue returns the value of a setting as it would be set in the
+// environment, joining lists with commas.
+func configValue(node any) (string, error) {
+	switch v := node.(type) {
+	case nil:
+		return "", nil
+	case yamlcodec.Scalar:
+		return v.Text, nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(yamlcodec.Scalar)
+			if !ok {
+				return "", errors.New("list items must be values")
+			}
+			items = append(items, s.Text)
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", errors.New("nested settings are not supported")
+}
+
+// watchConfigFile calls reload when the process receives SIGHUP and when the
+// config file changes, checking every interval, until ctx ends.
+func watchConfigFile(ctx context.Context, path string, interval time.Duration, reload func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := configFileVersion(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			last = configFileVersion(path)
+			reload()
+		case <-ticker.C:
+			if v := configFileVersion(path); v != last {
+				last = v
+				reload()
+			}
+		}
+	}
+}
+
+// configFileVersion identifies the file's current contents by modification
+// time and size; a missing file has the zero version.
+func configFileVersion(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size())
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	input := `---
+# iptv-manager settings
+log_level: debug
+STREAM_WRITE_TIMEOUT: 30s   # slow clients
+epg_url: "https://example.com/guide.xml?a=1#x"
+auth_password: 'it''s secret'
+acestream_engine_urls: [http://engine1:6878, "http://engine2:6878"]
+request_log_skip_paths:
+  - /metrics
+  - /api/health
+data_dir:
+`
+	got, err := parseConfigFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseConfigFile() error = %v", err)
+	}
+
+	want := map[string]string{
+		"LOG_LEVEL":              "debug",
+		"STREAM_WRITE_TIMEOUT":   "30s",
+		"EPG_URL":                "https://example.com/guide.xml?a=1#x",
+		"AUTH_PASSWORD":          "it's secret",
+		"ACESTREAM_ENGINE_URLS":  "http://engine1:6878,http://engine2:6878",
+		"REQUEST_LOG_SKIP_PATHS": "/metrics,/api/health",
+		"DATA_DIR":               "",
+	}
+	if len(got) != len(want) {
+		t.Errorf("parseConfigFile() returned %d settings, want %d: %v", len(got), len(want), got)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %q, want %q", key, got[key], value)
+		}
+	}
+
+	for _, bad := range []string{
+		"log_level debug",
+		"acestream:\n  engine_url: http://engine:6878",
+		`epg_url: "unterminated`,
+		"acestream_engine_urls: [a, b",
+	} {
+		if _, err := parseConfigFile(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected parseConfigFile(%q) to fail", bad)
+		}
+	}
+}
+
+func TestLoadConfig_FilePrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("log_level: debug\nstream_write_timeout: 30s\nport: 9090\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("STREAM_WRITE_TIMEOUT", "")
+	t.Setenv("PORT", "7070")
+
+	file, err := readConfigFile(path)
+	if err != nil {
+		t.Fatalf("readConfigFile() error = %v", err)
+	}
+	cfg := loadConfig(file)
+
+	if cfg.LogLevel.String() != "DEBUG" {
+		t.Errorf("LogLevel = %v, want DEBUG from the file", cfg.LogLevel)
+	}
+	if cfg.StreamWriteTimeout != 30*time.Second {
+		t.Errorf("StreamWriteTimeout = %v, want 30s from the file", cfg.StreamWriteTimeout)
+	}
+	if cfg.Port != "7070" {
+		t.Errorf("Port = %q, want 7070 from the environment", cfg.Port)
+	}
+
+	missing, err := readConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil || len(missing) != 0 {
+		t.Errorf("readConfigFile() of a missing file = (%v, %v), want no settings", missing, err)
+	}
+}
+
+func TestConfigFile_Secret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+	t.Setenv("ACESTREAM_ENGINE_API_KEY", "")
+	t.Setenv("ACESTREAM_ENGINE_API_KEY_FILE", path)
+
+	file := configFile{}
+	if got := file.secret("ACESTREAM_ENGINE_API_KEY"); got != "from-file" {
+		t.Errorf("secret() = %q, want the trimmed file contents", got)
+	}
+
+	t.Setenv("ACESTREAM_ENGINE_API_KEY", "from-env")
+	if got := file.secret("ACESTREAM_ENGINE_API_KEY"); got != "from-env" {
+		t.Errorf("secret() = %q, want the environment to take precedence", got)
+	}
+
+	t.Setenv("ACESTREAM_ENGINE_API_KEY", "")
+	t.Setenv("ACESTREAM_ENGINE_API_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+	if got := file.secret("ACESTREAM_ENGINE_API_KEY"); got != "" {
+		t.Errorf("secret() = %q, want no value for a missing file", got)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenAddr is one address the HTTP server binds to.
+type listenAddr struct {
+	// Network is "tcp" or "unix".
+	Network string
+	// Address is host:port for TCP and the socket path for Unix sockets.
+	Address string
+	// CertFile and KeyFile serve the listener over TLS when set.
+	CertFile string
+	KeyFile  string
+}
+
+func (l listenAddr) String() string {
+	if l.Network == "unix" {
+		return "unix:" + l.Address
+	}
+	return l.Address
+}
+
+// parseListen parses a comma-separated list of listen addresses. Each entry
+// is host:port ("0.0.0.0:8080", "[::]:8080", ":8080") or "unix:" followed by
+// a socket path, optionally followed by ";cert=<file>;key=<file>" to serve
+// it over TLS.
+func parseListen(s string) ([]listenAddr, error) {
+	var addrs []listenAddr
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ";")
+		var l listenAddr
+		if path, ok := strings.CutPrefix(parts[0], "unix:"); ok {
+			if path == "" {
+				return nil, fmt.Errorf("listen address %q: missing socket path", entry)
+			}
+			l = listenAddr{Network: "unix", Address: path}
+		} else {
+			if _, _, err := net.SplitHostPort(parts[0]); err != nil {
+				return nil, fmt.Errorf("listen address %q: %w", entry, err)
+			}
+			l = listenAddr{Network: "tcp", Address: parts[0]}
+		}
+
+		for _, opt := range parts[1:] {
+			name, value, _ := strings.Cut(opt, "=")
+			switch name {
+			case "cert":
+				l.CertFile = value
+			case "key":
+				l.KeyFile = value
+			default:
+				return nil, fmt.Errorf("listen address %q: unknown option %q", entry, name)
+			}
+		}
+		if (l.CertFile == "") != (l.KeyFile == "") {
+			return nil, fmt.Errorf("listen address %q: TLS needs both cert and key", entry)
+		}
+		addrs = append(addrs, l)
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no listen addresses")
+	}
+	return addrs, nil
+}
+
+// listenAddrs returns the addresses to serve on: LISTEN if set, otherwise
+// every interface on PORT. TLS_CERT and TLS_KEY serve the TCP addresses
+// without a key pair of their own over TLS; Unix sockets, meant for a
+// reverse proxy on the same host, stay plain.
+func (c config) listenAddrs() ([]listenAddr, error) {
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return nil, errors.New("TLS_CERT and TLS_KEY must be set together")
+	}
+
+	addrs := []listenAddr{{Network: "tcp", Address: ":" + c.Port}}
+	if c.Listen != "" {
+		var err error
+		if addrs, err = parseListen(c.Listen); err != nil {
+			return nil, err
+		}
+	}
+	for i, addr := range addrs {
+		if addr.Network == "tcp" && addr.CertFile == "" {
+			addrs[i].CertFile, addrs[i].KeyFile = c.TLSCert, c.TLSKey
+		}
+	}
+	return addrs, nil
+}
+
+// listen binds l. A socket file left behind by a previous run is removed
+// first; one a running server still accepts connections on is kept, so the
+// bind fails instead of stealing it.
+func listen(l listenAddr) (net.Listener, error) {
+	if l.Network == "unix" {
+		if conn, err := net.Dial("unix", l.Address); err == nil {
+			conn.Close()
+		} else if err := os.Remove(l.Address); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return net.Listen(l.Network, l.Address)
+}
+
+// dlnaHTTPPort returns the port of the first TCP listen address without TLS,
+// which DLNA renderers are pointed at.
+func dlnaHTTPPort(addrs []listenAddr) (int, bool) {
+	for _, addr := range addrs {
+		if addr.Network != "tcp" || addr.CertFile != "" {
+			continue
+		}
+		_, port, err := net.SplitHostPort(addr.Address)
+		if err != nil {
+			continue
+		}
+		if n, err := strconv.Atoi(port); err == nil && n > 0 {
+			return n, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseListen(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []listenAddr
+		wantErr bool
+	}{
+		{
+			name:  "dual stack and unix socket",
+			input: "0.0.0.0:8080, [::]:8080,unix:/run/iptv.sock",
+			want: []listenAddr{
+				{Network: "tcp", Address: "0.0.0.0:8080"},
+				{Network: "tcp", Address: "[::]:8080"},
+				{Network: "unix", Address: "/run/iptv.sock"},
+			},
+		},
+		{
+			name:  "per-listener TLS",
+			input: ":8080,:8443;cert=/tls/cert.pem;key=/tls/key.pem",
+			want: []listenAddr{
+				{Network: "tcp", Address: ":8080"},
+				{Network: "tcp", Address: ":8443", CertFile: "/tls/cert.pem", KeyFile: "/tls/key.pem"},
+			},
+		},
+		{name: "missing port", input: "0.0.0.0", wantErr: true},
+		{name: "missing socket path", input: "unix:", wantErr: true},
+		{name: "cert without key", input: ":8443;cert=/tls/cert.pem", wantErr: true},
+		{name: "unknown option", input: ":8080;proto=h2", wantErr: true},
+		{name: "no addresses", input: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseListen(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseListen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseListen() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigListenAddrs_DefaultsToPort(t *testing.T) {
+	got, err := config{Port: "9090"}.listenAddrs()
+	if err != nil {
+		t.Fatalf("listenAddrs() error = %v", err)
+	}
+	if want := []listenAddr{{Network: "tcp", Address: ":9090"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listenAddrs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConfigListenAddrs_TLS(t *testing.T) {
+	cfg := config{
+		Listen:  ":8080,:8443;cert=own.pem;key=own.key,unix:/run/iptv.sock",
+		TLSCert: "cert.pem",
+		TLSKey:  "key.pem",
+	}
+	got, err := cfg.listenAddrs()
+	if err != nil {
+		t.Fatalf("listenAddrs() error = %v", err)
+	}
+	want := []listenAddr{
+		{Network: "tcp", Address: ":8080", CertFile: "cert.pem", KeyFile: "key.pem"},
+		{Network: "tcp", Address: ":8443", CertFile: "own.pem", KeyFile: "own.key"},
+		{Network: "unix", Address: "/run/iptv.sock"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("listenAddrs() = %+v, want %+v", got, want)
+	}
+
+	if _, err := (config{Port: "8080", TLSCert: "cert.pem"}).listenAddrs(); err == nil {
+		t.Error("expected an error for TLS_CERT without TLS_KEY")
+	}
+}
+
+func TestDLNAHTTPPort(t *testing.T) {
+	addrs, err := parseListen("unix:/run/iptv.sock,:8443;cert=cert.pem;key=key.pem,[::]:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if port, ok := dlnaHTTPPort(addrs); !ok || port != 8080 {
+		t.Errorf("dlnaHTTPPort() = %d, %v, want 8080", port, ok)
+	}
+	if _, ok := dlnaHTTPPort(addrs[:2]); ok {
+		t.Error("expected no port without a plain TCP listener")
+	}
+}
+
+func TestListen_UnixSocket(t *testing.T) {
+	addr := listenAddr{Network: "unix", Address: filepath.Join(t.TempDir(), "iptv.sock")}
+
+	// A socket file left behind by a previous run is replaced
+	if err := os.WriteFile(addr.Address, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := listen(addr)
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+	defer l.Close()
+
+	// One still in use is not
+	if _, err := listen(addr); err == nil {
+		t.Error("expected an error binding a socket in use")
+	}
+	if err := checkListenFree(addr); err == nil {
+		t.Error("expected the check to report the socket in use")
+	}
+
+	conn, err := net.Dial("unix", addr.Address)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	conn.Close()
+}
+
+func TestListenChecks_MalformedListen(t *testing.T) {
+	checks := listenChecks(config{Listen: "not-an-address"})
+	if len(checks) != 1 || checks[0].Name != "listen" {
+		t.Fatalf("expected a single listen check, got %+v", checks)
+	}
+	if err := checks[0].Check(context.Background()); err == nil {
+		t.Error("expected the listen check to fail")
+	}
+}
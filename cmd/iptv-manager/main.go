@@ -2,61 +2,183 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/alorle/iptv-manager/internal/access"
 	"github.com/alorle/iptv-manager/internal/adapter/driven"
 	"github.com/alorle/iptv-manager/internal/adapter/driver"
 	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/circuitbreaker"
+	"github.com/alorle/iptv-manager/internal/logging"
+	"github.com/alorle/iptv-manager/internal/metrics"
+	port "github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/ratelimit"
+	"github.com/alorle/iptv-manager/internal/scheduler"
+	"github.com/alorle/iptv-manager/internal/stream"
 	"go.etcd.io/bbolt"
 )
 
 type config struct {
 	Port                        string
-	AceStreamEngineURL          string
+	Listen                      string
+	TLSCert                     string
+	TLSKey                      string
+	AceStreamEngineURLs         []string
+	AceStreamEngineBalancing    driven.EngineBalancing
+	AceStreamEngineAuth         driven.EngineAuth
 	EPGURL                      string
 	DBPath                      string
+	DBDriver                    string
+	SQLitePath                  string
+	DataDir                     string
 	LogLevel                    slog.Level
+	LogSampleRate               int
 	StreamWriteTimeout          time.Duration
+	StreamWriteTimeoutMax       time.Duration
+	StreamResumeGrace           time.Duration
+	ClientBuffer                application.ClientBufferOptions
+	Prebuffer                   application.PrebufferOptions
+	BandwidthLimits             application.BandwidthLimits
 	ProbeInterval               time.Duration
+	EPGSyncSchedule             scheduler.Schedule
+	RefreshInterval             time.Duration
+	FailoverMaxAttempts         int
+	FailoverStallTimeout        time.Duration
+	HLSEnabled                  bool
+	HLSSegmentDuration          time.Duration
+	HLSSegmentRetention         int
+	HLSIdleTimeout              time.Duration
+	AuthUsername                string
+	AuthPassword                string
+	AuthSessionKey              string
+	StreamLinkKeys              string
+	StreamLinkTTL               time.Duration
+	StreamLinkRequired          bool
+	AuthSessionTTL              time.Duration
 	ProbeTimeout                time.Duration
 	ProbeWindow                 time.Duration
 	ProbeDelay                  time.Duration
 	ProbeMaxConsecutiveFailures int
+	ProbeMaxAge                 time.Duration
+	ProbeBatchSize              int
+	ProbePrebufferWait          time.Duration
+	WarmupChannels              []string
+	WarmupLead                  time.Duration
+	EngineBreakerThreshold      int
+	EngineBreakerTimeout        time.Duration
+	ReconnectAttempts           int
+	ReconnectInitialBackoff     time.Duration
+	ReconnectMaxBackoff         time.Duration
+	EngineReaperInterval        time.Duration
+	EngineHealthInterval        time.Duration
+	EngineIdleTimeout           time.Duration
+	ClientReaperInterval        time.Duration
+	ClientIdleTimeout           time.Duration
 	AcestreamSourceNewEraURL    string
 	AcestreamSourceElcanoURL    string
+	AcestreamSourceNameFallback bool
+	AcestreamSourceFetch        map[string]driven.SourceFetchSettings
+	SourceChangesAutoDisable    bool
+	AcestreamSourcePriority     map[string]int
+	SourceConflictPolicy        string
+	BackupInterval              time.Duration
+	BackupRetention             int
+	RecordingRetention          time.Duration
+	StatsHistoryInterval        time.Duration
+	StatsHistoryRawRetention    time.Duration
+	StatsHistoryRetention       time.Duration
+	FFmpegPath                  string
+	PreviewCacheTTL             time.Duration
+	StreamMaxPerClient          int
+	APIRateLimit                float64
+	APIRateBurst                int
+	GeoIPDatabase               string
+	TunerCount                  int
+	StreamQueue                 application.StreamQueueOptions
+	HDHomeRunDeviceID           string
+	HDHomeRunFriendlyName       string
+	DLNAEnabled                 bool
+	DLNAFriendlyName            string
+	DLNAUUID                    string
+	RequestLogEnabled           bool
+	RequestLogSkipPaths         []string
+	PublicBaseURL               string
+	TrustedProxies              []netip.Prefix
+	EPGCacheStaleRevalidate     bool
+	HTTPCacheMemoryLimit        int64
+	HTTPCacheMaxAge             time.Duration
+	EPGAutoMapThreshold         float64
+	EPGAutoMapReviewThreshold   float64
+	PlaylistCatchupDays         int
+	PlaylistAvailability        application.PlaylistAvailability
+	PlaylistMinHealth           float64
 }
 
-func loadConfig() config {
-	port := os.Getenv("PORT")
+// loadConfig reads the configuration from the environment, falling back to
+// the config file for variables that are not set, and to defaults for
+// missing or malformed values.
+func loadConfig(file configFile) config {
+	port := file.getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	aceStreamURL := os.Getenv("ACESTREAM_ENGINE_URL")
+	aceStreamURL := file.getenv("ACESTREAM_ENGINE_URL")
 	if aceStreamURL == "" {
 		aceStreamURL = "http://localhost:6878"
 	}
 
-	epgURL := os.Getenv("EPG_URL")
+	// ACESTREAM_ENGINE_URLS lists several engines, comma-separated, to spread
+	// streams across. It takes precedence over ACESTREAM_ENGINE_URL.
+	aceStreamURLs := []string{aceStreamURL}
+	if urlsStr := file.getenv("ACESTREAM_ENGINE_URLS"); urlsStr != "" {
+		var urls []string
+		for _, u := range strings.Split(urlsStr, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				urls = append(urls, u)
+			}
+		}
+		if len(urls) > 0 {
+			aceStreamURLs = urls
+		}
+	}
+
+	aceStreamBalancing := driven.BalanceLeastStreams
+	if balancingStr := file.getenv("ACESTREAM_ENGINE_BALANCING"); balancingStr != "" {
+		if parsed, err := driven.ParseEngineBalancing(balancingStr); err == nil {
+			aceStreamBalancing = parsed
+		}
+	}
+
+	epgURL := file.getenv("EPG_URL")
 	if epgURL == "" {
 		epgURL = "https://raw.githubusercontent.com/davidmuma/EPG_dobleM/master/guiatv.xml"
 	}
 
-	dbPath := os.Getenv("DB_PATH")
+	dbPath := file.getenv("DB_PATH")
 	if dbPath == "" {
 		dbPath = "iptv-manager.db"
 	}
 
 	logLevel := slog.LevelInfo
-	if logLevelStr := os.Getenv("LOG_LEVEL"); logLevelStr != "" {
+	if logLevelStr := file.getenv("LOG_LEVEL"); logLevelStr != "" {
 		switch strings.ToUpper(logLevelStr) {
 		case "DEBUG":
 			logLevel = slog.LevelDebug
@@ -69,91 +191,833 @@ func loadConfig() config {
 		}
 	}
 
+	// LOG_SAMPLE_RATE logs one in every N occurrences of the per-client
+	// messages of the streaming hot path; 1 logs them all
+	logSampleRate := 100
+	if rateStr := file.getenv("LOG_SAMPLE_RATE"); rateStr != "" {
+		if parsed, err := strconv.Atoi(rateStr); err == nil && parsed > 0 {
+			logSampleRate = parsed
+		}
+	}
+
 	streamWriteTimeout := 10 * time.Second
-	if timeoutStr := os.Getenv("STREAM_WRITE_TIMEOUT"); timeoutStr != "" {
+	if timeoutStr := file.getenv("STREAM_WRITE_TIMEOUT"); timeoutStr != "" {
 		if parsedTimeout, err := time.ParseDuration(timeoutStr); err == nil {
 			streamWriteTimeout = parsedTimeout
 		}
 	}
 
+	// The longest write timeout a client can hint; defaults to six times
+	// the default write timeout
+	streamWriteTimeoutMax := 6 * streamWriteTimeout
+	if timeoutStr := file.getenv("STREAM_WRITE_TIMEOUT_MAX"); timeoutStr != "" {
+		if parsedTimeout, err := time.ParseDuration(timeoutStr); err == nil && parsedTimeout > 0 {
+			streamWriteTimeoutMax = parsedTimeout
+		}
+	}
+
+	// How long a client that drops its connection keeps its stream session
+	// for a reconnect with its session token; disabled by default
+	var streamResumeGrace time.Duration
+	if graceStr := file.getenv("STREAM_RESUME_GRACE"); graceStr != "" {
+		if parsed, err := time.ParseDuration(graceStr); err == nil && parsed >= 0 {
+			streamResumeGrace = parsed
+		}
+	}
+
+	// Buffering of each client of a shared stream and what to do when one
+	// falls behind: drop-oldest (default), disconnect or pause-upstream
+	clientBuffer := application.ClientBufferOptions{MaxLag: 10 * time.Second}
+	if policyStr := file.getenv("CLIENT_BUFFER_POLICY"); policyStr != "" {
+		if parsed, err := application.ParseClientBufferPolicy(policyStr); err == nil {
+			clientBuffer.Policy = parsed
+		}
+	}
+	if sizeStr := file.getenv("CLIENT_BUFFER_SIZE"); sizeStr != "" {
+		if parsed, err := strconv.Atoi(sizeStr); err == nil && parsed > 0 {
+			clientBuffer.Size = parsed
+		}
+	}
+	if lagStr := file.getenv("CLIENT_BUFFER_MAX_LAG"); lagStr != "" {
+		if parsed, err := time.ParseDuration(lagStr); err == nil && parsed >= 0 {
+			clientBuffer.MaxLag = parsed
+		}
+	}
+	if joinStr := file.getenv("CLIENT_BUFFER_JOIN_AT_PAT"); joinStr != "" {
+		if parsed, err := strconv.ParseBool(joinStr); err == nil {
+			clientBuffer.JoinAtPAT = parsed
+		}
+	}
+
+	// How much of a new engine stream to hold back before clients receive
+	// the first byte; both thresholds 0 disables the prebuffer
+	var prebuffer application.PrebufferOptions
+	if durationStr := file.getenv("PREBUFFER_DURATION"); durationStr != "" {
+		if parsed, err := time.ParseDuration(durationStr); err == nil && parsed >= 0 {
+			prebuffer.Duration = parsed
+		}
+	}
+	if bytesStr := file.getenv("PREBUFFER_BYTES"); bytesStr != "" {
+		if parsed, err := strconv.ParseInt(bytesStr, 10, 64); err == nil && parsed >= 0 {
+			prebuffer.Bytes = parsed
+		}
+	}
+	if waitStr := file.getenv("PREBUFFER_MAX_WAIT"); waitStr != "" {
+		if parsed, err := time.ParseDuration(waitStr); err == nil && parsed > 0 {
+			prebuffer.MaxWait = parsed
+		}
+	}
+
+	// Bandwidth ceilings in bytes per second; 0 leaves a limit off. They can
+	// be changed at runtime through PUT /api/settings/bandwidth
+	var bandwidthLimits application.BandwidthLimits
+	for name, dst := range map[string]*int64{
+		"BANDWIDTH_GLOBAL_LIMIT": &bandwidthLimits.Global,
+		"BANDWIDTH_CLIENT_LIMIT": &bandwidthLimits.PerClient,
+		"BANDWIDTH_STREAM_LIMIT": &bandwidthLimits.PerStream,
+	} {
+		if limitStr := file.getenv(name); limitStr != "" {
+			if parsed, err := strconv.ParseInt(limitStr, 10, 64); err == nil && parsed >= 0 {
+				*dst = parsed
+			}
+		}
+	}
+
 	probeInterval := 30 * time.Minute
-	if intervalStr := os.Getenv("PROBE_INTERVAL"); intervalStr != "" {
+	if intervalStr := file.getenv("PROBE_INTERVAL"); intervalStr != "" {
 		if parsed, err := time.ParseDuration(intervalStr); err == nil {
 			probeInterval = parsed
 		}
 	}
 
+	refreshInterval := 6 * time.Hour
+	if intervalStr := file.getenv("REFRESH_INTERVAL"); intervalStr != "" {
+		if parsed, err := time.ParseDuration(intervalStr); err == nil && parsed > 0 {
+			refreshInterval = parsed
+		}
+	}
+
+	// EPG_SYNC_CRON schedules EPG syncs with a cron expression instead of
+	// running them every REFRESH_INTERVAL
+	var epgSyncSchedule scheduler.Schedule = scheduler.Every(refreshInterval)
+	if cronStr := file.getenv("EPG_SYNC_CRON"); cronStr != "" {
+		if parsed, err := scheduler.ParseCron(cronStr); err == nil {
+			epgSyncSchedule = parsed
+		}
+	}
+
+	failoverMaxAttempts := 0
+	if attemptsStr := file.getenv("FAILOVER_MAX_ATTEMPTS"); attemptsStr != "" {
+		if parsed, err := strconv.Atoi(attemptsStr); err == nil && parsed >= 0 {
+			failoverMaxAttempts = parsed
+		}
+	}
+
+	failoverStallTimeout := 15 * time.Second
+	if timeoutStr := file.getenv("FAILOVER_STALL_TIMEOUT"); timeoutStr != "" {
+		if parsed, err := time.ParseDuration(timeoutStr); err == nil {
+			failoverStallTimeout = parsed
+		}
+	}
+
 	probeTimeout := 45 * time.Second
-	if timeoutStr := os.Getenv("PROBE_TIMEOUT"); timeoutStr != "" {
+	if timeoutStr := file.getenv("PROBE_TIMEOUT"); timeoutStr != "" {
 		if parsed, err := time.ParseDuration(timeoutStr); err == nil {
 			probeTimeout = parsed
 		}
 	}
 
 	probeWindow := 24 * time.Hour
-	if windowStr := os.Getenv("PROBE_WINDOW"); windowStr != "" {
+	if windowStr := file.getenv("PROBE_WINDOW"); windowStr != "" {
 		if parsed, err := time.ParseDuration(windowStr); err == nil {
 			probeWindow = parsed
 		}
 	}
 
 	probeDelay := 2 * time.Second
-	if delayStr := os.Getenv("PROBE_DELAY"); delayStr != "" {
+	if delayStr := file.getenv("PROBE_DELAY"); delayStr != "" {
 		if parsed, err := time.ParseDuration(delayStr); err == nil {
 			probeDelay = parsed
 		}
 	}
 
 	probeMaxConsecFailures := 5
-	if failStr := os.Getenv("PROBE_MAX_CONSECUTIVE_FAILURES"); failStr != "" {
+	if failStr := file.getenv("PROBE_MAX_CONSECUTIVE_FAILURES"); failStr != "" {
 		if parsed, err := strconv.Atoi(failStr); err == nil && parsed > 0 {
 			probeMaxConsecFailures = parsed
 		}
 	}
 
-	acestreamSourceNewEraURL := os.Getenv("ACESTREAM_SOURCE_NEW_ERA_URL")
+	// PROBE_MAX_AGE enables a startup pass that prunes probe history older than
+	// the given age. Disabled (0) by default.
+	var probeMaxAge time.Duration
+	if maxAgeStr := file.getenv("PROBE_MAX_AGE"); maxAgeStr != "" {
+		if parsed, err := time.ParseDuration(maxAgeStr); err == nil && parsed > 0 {
+			probeMaxAge = parsed
+		}
+	}
+
+	// PROBE_BATCH_SIZE makes each probe cycle spot-check the next N streams
+	// in rotation instead of all of them. 0 (default) probes every stream.
+	var probeBatchSize int
+	if sizeStr := file.getenv("PROBE_BATCH_SIZE"); sizeStr != "" {
+		if parsed, err := strconv.Atoi(sizeStr); err == nil && parsed >= 0 {
+			probeBatchSize = parsed
+		}
+	}
+
+	var probePrebufferWait time.Duration
+	if waitStr := file.getenv("PROBE_PREBUFFER_WAIT"); waitStr != "" {
+		if parsed, err := time.ParseDuration(waitStr); err == nil && parsed >= 0 {
+			probePrebufferWait = parsed
+		}
+	}
+
+	// WARMUP_CHANNELS lists the channels, by name, whose streams are warmed
+	// up WARMUP_LEAD before each programme in their guide starts and kept
+	// warm until WARMUP_LEAD after it started.
+	var warmupChannels []string
+	for _, name := range strings.Split(file.getenv("WARMUP_CHANNELS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			warmupChannels = append(warmupChannels, name)
+		}
+	}
+
+	warmupLead := 10 * time.Minute
+	if leadStr := file.getenv("WARMUP_LEAD"); leadStr != "" {
+		if parsed, err := time.ParseDuration(leadStr); err == nil && parsed > 0 {
+			warmupLead = parsed
+		}
+	}
+
+	engineBreakerThreshold := 5
+	if thresholdStr := file.getenv("ENGINE_BREAKER_THRESHOLD"); thresholdStr != "" {
+		if parsed, err := strconv.Atoi(thresholdStr); err == nil && parsed >= 0 {
+			engineBreakerThreshold = parsed
+		}
+	}
+
+	engineBreakerTimeout := 30 * time.Second
+	if timeoutStr := file.getenv("ENGINE_BREAKER_TIMEOUT"); timeoutStr != "" {
+		if parsed, err := time.ParseDuration(timeoutStr); err == nil && parsed > 0 {
+			engineBreakerTimeout = parsed
+		}
+	}
+
+	// How a dropped engine stream is restarted; changeable at runtime through
+	// PUT /api/settings/resilience
+	resilience := application.DefaultResilienceConfig()
+	if attemptsStr := file.getenv("RECONNECT_ATTEMPTS"); attemptsStr != "" {
+		if parsed, err := strconv.Atoi(attemptsStr); err == nil && parsed > 0 {
+			resilience.ReconnectAttempts = parsed
+		}
+	}
+	if backoffStr := file.getenv("RECONNECT_INITIAL_BACKOFF"); backoffStr != "" {
+		if parsed, err := time.ParseDuration(backoffStr); err == nil && parsed > 0 {
+			resilience.ReconnectInitialBackoff = parsed
+		}
+	}
+	if backoffStr := file.getenv("RECONNECT_MAX_BACKOFF"); backoffStr != "" {
+		if parsed, err := time.ParseDuration(backoffStr); err == nil && parsed > 0 {
+			resilience.ReconnectMaxBackoff = parsed
+		}
+	}
+	resilience.ReconnectMaxBackoff = max(resilience.ReconnectMaxBackoff, resilience.ReconnectInitialBackoff)
+
+	engineReaperInterval := time.Minute
+	if intervalStr := file.getenv("ENGINE_REAPER_INTERVAL"); intervalStr != "" {
+		if parsed, err := time.ParseDuration(intervalStr); err == nil && parsed > 0 {
+			engineReaperInterval = parsed
+		}
+	}
+
+	// ENGINE_HEALTH_INTERVAL controls how often engine health is checked to
+	// push changes to the live event stream
+	engineHealthInterval := 15 * time.Second
+	if intervalStr := file.getenv("ENGINE_HEALTH_INTERVAL"); intervalStr != "" {
+		if parsed, err := time.ParseDuration(intervalStr); err == nil && parsed > 0 {
+			engineHealthInterval = parsed
+		}
+	}
+
+	engineIdleTimeout := 5 * time.Minute
+	if timeoutStr := file.getenv("ENGINE_IDLE_TIMEOUT"); timeoutStr != "" {
+		if parsed, err := time.ParseDuration(timeoutStr); err == nil && parsed >= 0 {
+			engineIdleTimeout = parsed
+		}
+	}
+
+	clientReaperInterval := 15 * time.Second
+	if intervalStr := file.getenv("CLIENT_REAPER_INTERVAL"); intervalStr != "" {
+		if parsed, err := time.ParseDuration(intervalStr); err == nil && parsed > 0 {
+			clientReaperInterval = parsed
+		}
+	}
+
+	clientIdleTimeout := time.Minute
+	if timeoutStr := file.getenv("CLIENT_IDLE_TIMEOUT"); timeoutStr != "" {
+		if parsed, err := time.ParseDuration(timeoutStr); err == nil && parsed >= 0 {
+			clientIdleTimeout = parsed
+		}
+	}
+
+	hlsEnabled := false
+	if enabledStr := file.getenv("HLS_ENABLED"); enabledStr != "" {
+		if parsed, err := strconv.ParseBool(enabledStr); err == nil {
+			hlsEnabled = parsed
+		}
+	}
+
+	hlsSegmentDuration := 4 * time.Second
+	if durationStr := file.getenv("HLS_SEGMENT_DURATION"); durationStr != "" {
+		if parsed, err := time.ParseDuration(durationStr); err == nil && parsed > 0 {
+			hlsSegmentDuration = parsed
+		}
+	}
+
+	hlsSegmentRetention := 6
+	if retentionStr := file.getenv("HLS_SEGMENT_RETENTION"); retentionStr != "" {
+		if parsed, err := strconv.Atoi(retentionStr); err == nil && parsed > 0 {
+			hlsSegmentRetention = parsed
+		}
+	}
+
+	hlsIdleTimeout := 30 * time.Second
+	if timeoutStr := file.getenv("HLS_IDLE_TIMEOUT"); timeoutStr != "" {
+		if parsed, err := time.ParseDuration(timeoutStr); err == nil && parsed > 0 {
+			hlsIdleTimeout = parsed
+		}
+	}
+
+	// Files other than the database (e.g. cached logos) live next to it by default
+	dataDir := file.getenv("DATA_DIR")
+	if dataDir == "" {
+		dataDir = filepath.Dir(dbPath)
+	}
+
+	dbDriver := "bolt"
+	if driverStr := file.getenv("DB_DRIVER"); driverStr != "" {
+		switch strings.ToLower(driverStr) {
+		case "bolt", "sqlite":
+			dbDriver = strings.ToLower(driverStr)
+		}
+	}
+
+	sqlitePath := file.getenv("SQLITE_PATH")
+	if sqlitePath == "" {
+		sqlitePath = filepath.Join(dataDir, "iptv-manager.sqlite")
+	}
+
+	authSessionTTL := 24 * time.Hour
+	if ttlStr := file.getenv("AUTH_SESSION_TTL"); ttlStr != "" {
+		if parsed, err := time.ParseDuration(ttlStr); err == nil && parsed > 0 {
+			authSessionTTL = parsed
+		}
+	}
+
+	// Signed stream links expire after this long; the keys themselves are
+	// parsed at startup, where malformed ones stop the server
+	streamLinkTTL := 24 * time.Hour
+	if ttlStr := file.getenv("STREAM_LINK_TTL"); ttlStr != "" {
+		if parsed, err := time.ParseDuration(ttlStr); err == nil && parsed > 0 {
+			streamLinkTTL = parsed
+		}
+	}
+	streamLinkRequired := false
+	if requiredStr := file.getenv("STREAM_LINK_REQUIRED"); requiredStr != "" {
+		if parsed, err := strconv.ParseBool(requiredStr); err == nil {
+			streamLinkRequired = parsed
+		}
+	}
+
+	acestreamSourceNewEraURL := file.getenv("ACESTREAM_SOURCE_NEW_ERA_URL")
 	if acestreamSourceNewEraURL == "" {
 		acestreamSourceNewEraURL = "https://ipfs.io/ipns/k2k4r8lm8tkmuxbc8lkmq1in3v0oya1p6pe9o5bu0hu30br5ko08k2gb/data/listas/lista_fuera_iptv.m3u"
 	}
 
-	acestreamSourceElcanoURL := os.Getenv("ACESTREAM_SOURCE_ELCANO_URL")
+	acestreamSourceElcanoURL := file.getenv("ACESTREAM_SOURCE_ELCANO_URL")
 	if acestreamSourceElcanoURL == "" {
 		acestreamSourceElcanoURL = "https://ipfs.io/ipns/k51qzi5uqu5di462t7j4vu4akwfhvtjhy88qbupktvoacqfqe9uforjvhyi4wr/hashes.json"
 	}
 
+	// Per-source fetch settings, e.g. ACESTREAM_SOURCE_NEW_ERA_HEADERS
+	acestreamSourceFetch := map[string]driven.SourceFetchSettings{
+		stream.SourceNewEra: loadSourceFetchSettings(file, "ACESTREAM_SOURCE_NEW_ERA_"),
+		stream.SourceElcano: loadSourceFetchSettings(file, "ACESTREAM_SOURCE_ELCANO_"),
+	}
+
+	acestreamSourceNameFallback := false
+	if fallbackStr := file.getenv("ACESTREAM_SOURCE_NAME_FALLBACK"); fallbackStr != "" {
+		if parsed, err := strconv.ParseBool(fallbackStr); err == nil {
+			acestreamSourceNameFallback = parsed
+		}
+	}
+
+	// Per-source priority, e.g. ACESTREAM_SOURCE_ELCANO_PRIORITY, used to
+	// resolve hashes listed under different channel names by several sources
+	acestreamSourcePriority := make(map[string]int)
+	for source, prefix := range map[string]string{
+		stream.SourceNewEra: "ACESTREAM_SOURCE_NEW_ERA_",
+		stream.SourceElcano: "ACESTREAM_SOURCE_ELCANO_",
+	} {
+		if priorityStr := file.getenv(prefix + "PRIORITY"); priorityStr != "" {
+			if parsed, err := strconv.Atoi(priorityStr); err == nil {
+				acestreamSourcePriority[source] = parsed
+			}
+		}
+	}
+
+	// SOURCE_CONFLICT_POLICY is "priority" (default) or "newest"
+	sourceConflictPolicy := file.getenv("SOURCE_CONFLICT_POLICY")
+	if sourceConflictPolicy == "" {
+		sourceConflictPolicy = string(application.ConflictPolicyPriority)
+	}
+
+	// SOURCE_CHANGES_AUTO_DISABLE archives channels whose every stream
+	// disappeared from the upstream sources
+	sourceChangesAutoDisable := false
+	if autoDisableStr := file.getenv("SOURCE_CHANGES_AUTO_DISABLE"); autoDisableStr != "" {
+		if parsed, err := strconv.ParseBool(autoDisableStr); err == nil {
+			sourceChangesAutoDisable = parsed
+		}
+	}
+
+	// BACKUP_INTERVAL enables periodic database backups to DATA_DIR/backups.
+	// Disabled (0) by default.
+	var backupInterval time.Duration
+	if intervalStr := file.getenv("BACKUP_INTERVAL"); intervalStr != "" {
+		if parsed, err := time.ParseDuration(intervalStr); err == nil && parsed > 0 {
+			backupInterval = parsed
+		}
+	}
+
+	backupRetention := 7
+	if retentionStr := file.getenv("BACKUP_RETENTION"); retentionStr != "" {
+		if parsed, err := strconv.Atoi(retentionStr); err == nil && parsed >= 0 {
+			backupRetention = parsed
+		}
+	}
+
+	// RECORDING_RETENTION deletes finished recordings once their stop time is
+	// older than this; 0 keeps them all
+	recordingRetention := 7 * 24 * time.Hour
+	if retentionStr := file.getenv("RECORDING_RETENTION"); retentionStr != "" {
+		if parsed, err := time.ParseDuration(retentionStr); err == nil && parsed >= 0 {
+			recordingRetention = parsed
+		}
+	}
+
+	// STATS_HISTORY_INTERVAL is how often the engine stats of playing streams
+	// are recorded; 0 disables the stats history. Readings are kept for
+	// STATS_HISTORY_RAW_RETENTION, then compacted into hourly averages kept
+	// for STATS_HISTORY_RETENTION.
+	statsHistoryInterval := time.Minute
+	if intervalStr := file.getenv("STATS_HISTORY_INTERVAL"); intervalStr != "" {
+		if parsed, err := time.ParseDuration(intervalStr); err == nil && parsed >= 0 {
+			statsHistoryInterval = parsed
+		}
+	}
+
+	statsHistoryRawRetention := 24 * time.Hour
+	if retentionStr := file.getenv("STATS_HISTORY_RAW_RETENTION"); retentionStr != "" {
+		if parsed, err := time.ParseDuration(retentionStr); err == nil && parsed > 0 {
+			statsHistoryRawRetention = parsed
+		}
+	}
+
+	statsHistoryRetention := 30 * 24 * time.Hour
+	if retentionStr := file.getenv("STATS_HISTORY_RETENTION"); retentionStr != "" {
+		if parsed, err := time.ParseDuration(retentionStr); err == nil && parsed > 0 {
+			statsHistoryRetention = parsed
+		}
+	}
+
+	// FFMPEG_PATH is the ffmpeg binary used to capture stream previews and
+	// downscale channel video; both are disabled if it cannot be found. Previews are cached for PREVIEW_CACHE_TTL.
+	ffmpegPath := "ffmpeg"
+	if pathStr := file.getenv("FFMPEG_PATH"); pathStr != "" {
+		ffmpegPath = pathStr
+	}
+
+	previewCacheTTL := 5 * time.Minute
+	if ttlStr := file.getenv("PREVIEW_CACHE_TTL"); ttlStr != "" {
+		if parsed, err := time.ParseDuration(ttlStr); err == nil && parsed >= 0 {
+			previewCacheTTL = parsed
+		}
+	}
+
+	// STREAM_MAX_PER_CLIENT caps concurrent /ace/ streams per client IP; 0 disables the cap
+	streamMaxPerClient := 2
+	if maxStr := file.getenv("STREAM_MAX_PER_CLIENT"); maxStr != "" {
+		if parsed, err := strconv.Atoi(maxStr); err == nil && parsed >= 0 {
+			streamMaxPerClient = parsed
+		}
+	}
+
+	// API_RATE_LIMIT limits API requests per second per client IP, allowing
+	// bursts of API_RATE_BURST. Disabled (0) by default.
+	var apiRateLimit float64
+	if rateStr := file.getenv("API_RATE_LIMIT"); rateStr != "" {
+		if parsed, err := strconv.ParseFloat(rateStr, 64); err == nil && parsed >= 0 {
+			apiRateLimit = parsed
+		}
+	}
+
+	apiRateBurst := 20
+	if burstStr := file.getenv("API_RATE_BURST"); burstStr != "" {
+		if parsed, err := strconv.Atoi(burstStr); err == nil && parsed > 0 {
+			apiRateBurst = parsed
+		}
+	}
+
+	// TUNER_COUNT caps concurrent engine streams and is advertised as the
+	// HDHomeRun tuner count. Unlimited (0) by default. Media servers tune from
+	// a single IP, so STREAM_MAX_PER_CLIENT must allow as many streams.
+	var tunerCount int
+	if countStr := file.getenv("TUNER_COUNT"); countStr != "" {
+		if parsed, err := strconv.Atoi(countStr); err == nil && parsed >= 0 {
+			tunerCount = parsed
+		}
+	}
+
+	// STREAM_QUEUE_WAIT holds requests for a new stream back while all tuners
+	// are in use or the engine has too many sessions, for up to
+	// STREAM_QUEUE_MAX_DEPTH requests at once (unlimited by default)
+	streamQueue := application.StreamQueueOptions{MaxWait: 10 * time.Second}
+	if waitStr := file.getenv("STREAM_QUEUE_WAIT"); waitStr != "" {
+		if parsed, err := time.ParseDuration(waitStr); err == nil && parsed >= 0 {
+			streamQueue.MaxWait = parsed
+		}
+	}
+	if depthStr := file.getenv("STREAM_QUEUE_MAX_DEPTH"); depthStr != "" {
+		if parsed, err := strconv.Atoi(depthStr); err == nil && parsed >= 0 {
+			streamQueue.MaxDepth = parsed
+		}
+	}
+
+	hdhrDeviceID := file.getenv("HDHR_DEVICE_ID")
+	if hdhrDeviceID == "" {
+		hdhrDeviceID = "12AB34CD"
+	}
+
+	hdhrFriendlyName := file.getenv("HDHR_FRIENDLY_NAME")
+	if hdhrFriendlyName == "" {
+		hdhrFriendlyName = "IPTV Manager"
+	}
+
+	// DLNA media server announcing the lineup on the LAN, off by default.
+	// Renderers remember the server by its UUID, which defaults to one
+	// derived from the HDHomeRun device ID so it survives restarts
+	dlnaEnabled := false
+	if enabledStr := file.getenv("DLNA_ENABLED"); enabledStr != "" {
+		if parsed, err := strconv.ParseBool(enabledStr); err == nil {
+			dlnaEnabled = parsed
+		}
+	}
+	dlnaFriendlyName := file.getenv("DLNA_FRIENDLY_NAME")
+	if dlnaFriendlyName == "" {
+		dlnaFriendlyName = hdhrFriendlyName
+	}
+	dlnaUUID := file.getenv("DLNA_UUID")
+	if dlnaUUID == "" {
+		sum := sha1.Sum([]byte("iptv-manager-dlna/" + hdhrDeviceID))
+		dlnaUUID = fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+	}
+
+	// Serve an expired EPG channel cache while refreshing it in the background
+	epgCacheStaleRevalidate := true
+	if swrStr := file.getenv("EPG_CACHE_STALE_WHILE_REVALIDATE"); swrStr != "" {
+		if parsed, err := strconv.ParseBool(swrStr); err == nil {
+			epgCacheStaleRevalidate = parsed
+		}
+	}
+
+	// Bytes of downloaded upstream files also kept in memory; 0 reads them
+	// back from disk every time
+	var httpCacheMemoryLimit int64
+	if limitStr := file.getenv("HTTP_CACHE_MEMORY_LIMIT"); limitStr != "" {
+		if parsed, err := strconv.ParseInt(limitStr, 10, 64); err == nil && parsed >= 0 {
+			httpCacheMemoryLimit = parsed
+		}
+	}
+
+	// HTTP_CACHE_MAX_AGE makes the startup cache prune also remove downloaded
+	// files not fetched for this long, such as those of removed sources.
+	// Disabled (0) by default; orphaned files are always removed.
+	var httpCacheMaxAge time.Duration
+	if maxAgeStr := file.getenv("HTTP_CACHE_MAX_AGE"); maxAgeStr != "" {
+		if parsed, err := time.ParseDuration(maxAgeStr); err == nil && parsed > 0 {
+			httpCacheMaxAge = parsed
+		}
+	}
+
+	// Channels without an EPG mapping are mapped to the EPG channel with the
+	// most similar name, from 0.0 to 1.0, when it reaches EPG_AUTOMAP_THRESHOLD.
+	// Mappings below EPG_AUTOMAP_REVIEW_THRESHOLD are listed for review.
+	epgAutoMapThreshold := application.DefaultAutoMapThreshold
+	if thresholdStr := file.getenv("EPG_AUTOMAP_THRESHOLD"); thresholdStr != "" {
+		if parsed, err := strconv.ParseFloat(thresholdStr, 64); err == nil && parsed >= 0 && parsed <= 1 {
+			epgAutoMapThreshold = parsed
+		}
+	}
+
+	epgAutoMapReviewThreshold := application.DefaultAutoMapReviewThreshold
+	if thresholdStr := file.getenv("EPG_AUTOMAP_REVIEW_THRESHOLD"); thresholdStr != "" {
+		if parsed, err := strconv.ParseFloat(thresholdStr, 64); err == nil && parsed >= 0 && parsed <= 1 {
+			epgAutoMapReviewThreshold = parsed
+		}
+	}
+
+	requestLogEnabled := true
+	if enabledStr := file.getenv("REQUEST_LOG_ENABLED"); enabledStr != "" {
+		if parsed, err := strconv.ParseBool(enabledStr); err == nil {
+			requestLogEnabled = parsed
+		}
+	}
+
+	// TRUSTED_PROXIES lists the reverse proxies, as comma-separated IPs or
+	// CIDR ranges, whose X-Forwarded-* headers build the URLs of playlists
+	// when PUBLIC_BASE_URL is unset. Malformed entries are ignored.
+	var trustedProxies []netip.Prefix
+	for _, s := range strings.Split(file.getenv("TRUSTED_PROXIES"), ",") {
+		if network, err := access.ParseNetwork(strings.TrimSpace(s)); err == nil {
+			trustedProxies = append(trustedProxies, network)
+		}
+	}
+
+	// Comma-separated path prefixes left out of the request log
+	requestLogSkipPaths := []string{"/metrics"}
+	if pathsStr, ok := file.lookupEnv("REQUEST_LOG_SKIP_PATHS"); ok {
+		requestLogSkipPaths = nil
+		for _, p := range strings.Split(pathsStr, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				requestLogSkipPaths = append(requestLogSkipPaths, p)
+			}
+		}
+	}
+
+	// PLAYLIST_CATCHUP_DAYS is advertised as the catchup window of extended
+	// M3U playlists (?format=m3u8). Omitted (0) by default.
+	var playlistCatchupDays int
+	if daysStr := file.getenv("PLAYLIST_CATCHUP_DAYS"); daysStr != "" {
+		if parsed, err := strconv.Atoi(daysStr); err == nil && parsed >= 0 {
+			playlistCatchupDays = parsed
+		}
+	}
+
+	// PLAYLIST_AVAILABILITY is how playlists reflect probed channel
+	// availability: off (default), tag or hide
+	playlistAvailability := application.PlaylistAvailabilityOff
+	if modeStr := file.getenv("PLAYLIST_AVAILABILITY"); modeStr != "" {
+		switch mode := application.PlaylistAvailability(strings.ToLower(modeStr)); mode {
+		case application.PlaylistAvailabilityOff, application.PlaylistAvailabilityTag, application.PlaylistAvailabilityHide:
+			playlistAvailability = mode
+		}
+	}
+
+	// PLAYLIST_MIN_HEALTH leaves out of playlists the streams whose health
+	// score, from 0.0 to 1.0, is below it. Disabled (0) by default.
+	var playlistMinHealth float64
+	if healthStr := file.getenv("PLAYLIST_MIN_HEALTH"); healthStr != "" {
+		if parsed, err := strconv.ParseFloat(healthStr, 64); err == nil && parsed >= 0 && parsed <= 1 {
+			playlistMinHealth = parsed
+		}
+	}
+
 	return config{
 		Port:                        port,
-		AceStreamEngineURL:          aceStreamURL,
+		Listen:                      file.getenv("LISTEN"),
+		TLSCert:                     file.getenv("TLS_CERT"),
+		TLSKey:                      file.getenv("TLS_KEY"),
+		AceStreamEngineURLs:         aceStreamURLs,
+		AceStreamEngineBalancing:    aceStreamBalancing,
+		AceStreamEngineAuth:         loadEngineAuth(file),
 		EPGURL:                      epgURL,
 		DBPath:                      dbPath,
+		DBDriver:                    dbDriver,
+		SQLitePath:                  sqlitePath,
+		DataDir:                     dataDir,
 		LogLevel:                    logLevel,
+		LogSampleRate:               logSampleRate,
 		StreamWriteTimeout:          streamWriteTimeout,
+		StreamWriteTimeoutMax:       streamWriteTimeoutMax,
+		StreamResumeGrace:           streamResumeGrace,
+		ClientBuffer:                clientBuffer,
+		Prebuffer:                   prebuffer,
+		BandwidthLimits:             bandwidthLimits,
 		ProbeInterval:               probeInterval,
+		EPGSyncSchedule:             epgSyncSchedule,
+		RefreshInterval:             refreshInterval,
+		FailoverMaxAttempts:         failoverMaxAttempts,
+		FailoverStallTimeout:        failoverStallTimeout,
+		HLSEnabled:                  hlsEnabled,
+		HLSSegmentDuration:          hlsSegmentDuration,
+		HLSSegmentRetention:         hlsSegmentRetention,
+		HLSIdleTimeout:              hlsIdleTimeout,
+		AuthUsername:                file.getenv("AUTH_USERNAME"),
+		AuthPassword:                file.getenv("AUTH_PASSWORD"),
+		AuthSessionKey:              file.getenv("AUTH_SESSION_KEY"),
+		AuthSessionTTL:              authSessionTTL,
+		StreamLinkKeys:              file.getenv("STREAM_LINK_KEYS"),
+		StreamLinkTTL:               streamLinkTTL,
+		StreamLinkRequired:          streamLinkRequired,
 		ProbeTimeout:                probeTimeout,
 		ProbeWindow:                 probeWindow,
 		ProbeDelay:                  probeDelay,
 		ProbeMaxConsecutiveFailures: probeMaxConsecFailures,
+		ProbeMaxAge:                 probeMaxAge,
+		ProbeBatchSize:              probeBatchSize,
+		ProbePrebufferWait:          probePrebufferWait,
+		WarmupChannels:              warmupChannels,
+		WarmupLead:                  warmupLead,
+		EngineBreakerThreshold:      engineBreakerThreshold,
+		EngineBreakerTimeout:        engineBreakerTimeout,
+		ReconnectAttempts:           resilience.ReconnectAttempts,
+		ReconnectInitialBackoff:     resilience.ReconnectInitialBackoff,
+		ReconnectMaxBackoff:         resilience.ReconnectMaxBackoff,
+		EngineReaperInterval:        engineReaperInterval,
+		EngineHealthInterval:        engineHealthInterval,
+		EngineIdleTimeout:           engineIdleTimeout,
+		ClientReaperInterval:        clientReaperInterval,
+		ClientIdleTimeout:           clientIdleTimeout,
 		AcestreamSourceNewEraURL:    acestreamSourceNewEraURL,
 		AcestreamSourceElcanoURL:    acestreamSourceElcanoURL,
+		AcestreamSourceNameFallback: acestreamSourceNameFallback,
+		AcestreamSourceFetch:        acestreamSourceFetch,
+		SourceChangesAutoDisable:    sourceChangesAutoDisable,
+		AcestreamSourcePriority:     acestreamSourcePriority,
+		SourceConflictPolicy:        sourceConflictPolicy,
+		BackupInterval:              backupInterval,
+		BackupRetention:             backupRetention,
+		RecordingRetention:          recordingRetention,
+		StatsHistoryInterval:        statsHistoryInterval,
+		StatsHistoryRawRetention:    statsHistoryRawRetention,
+		StatsHistoryRetention:       statsHistoryRetention,
+		FFmpegPath:                  ffmpegPath,
+		PreviewCacheTTL:             previewCacheTTL,
+		StreamMaxPerClient:          streamMaxPerClient,
+		APIRateLimit:                apiRateLimit,
+		APIRateBurst:                apiRateBurst,
+		GeoIPDatabase:               file.getenv("GEOIP_DATABASE"),
+		TunerCount:                  tunerCount,
+		StreamQueue:                 streamQueue,
+		HDHomeRunDeviceID:           hdhrDeviceID,
+		HDHomeRunFriendlyName:       hdhrFriendlyName,
+		DLNAEnabled:                 dlnaEnabled,
+		DLNAFriendlyName:            dlnaFriendlyName,
+		DLNAUUID:                    dlnaUUID,
+		RequestLogEnabled:           requestLogEnabled,
+		RequestLogSkipPaths:         requestLogSkipPaths,
+		PublicBaseURL:               file.getenv("PUBLIC_BASE_URL"),
+		TrustedProxies:              trustedProxies,
+		EPGCacheStaleRevalidate:     epgCacheStaleRevalidate,
+		HTTPCacheMemoryLimit:        httpCacheMemoryLimit,
+		HTTPCacheMaxAge:             httpCacheMaxAge,
+		EPGAutoMapThreshold:         epgAutoMapThreshold,
+		EPGAutoMapReviewThreshold:   epgAutoMapReviewThreshold,
+		PlaylistCatchupDays:         playlistCatchupDays,
+		PlaylistAvailability:        playlistAvailability,
+		PlaylistMinHealth:           playlistMinHealth,
 	}
 }
 
+// loadEngineAuth reads the credentials of the AceStream engines from
+// ACESTREAM_ENGINE_API_KEY, ACESTREAM_ENGINE_USERNAME,
+// ACESTREAM_ENGINE_PASSWORD and ACESTREAM_ENGINE_HEADERS (a JSON object of
+// header names to values). The API key, password and headers can be read
+// from the file named by the variable with a _FILE suffix instead.
+func loadEngineAuth(file configFile) driven.EngineAuth {
+	auth := driven.EngineAuth{
+		APIKey:   file.secret("ACESTREAM_ENGINE_API_KEY"),
+		Username: file.getenv("ACESTREAM_ENGINE_USERNAME"),
+		Password: file.secret("ACESTREAM_ENGINE_PASSWORD"),
+	}
+	if headersStr := file.secret("ACESTREAM_ENGINE_HEADERS"); headersStr != "" {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(headersStr), &headers); err == nil {
+			auth.Headers = headers
+		}
+	}
+	return auth
+}
+
+// loadSourceFetchSettings reads the fetch settings of one Acestream source
+// from the variables starting with prefix: HEADERS (a JSON object of header
+// names to values), USERNAME, PASSWORD, PROXY and INSECURE_SKIP_VERIFY.
+// Malformed values are ignored.
+func loadSourceFetchSettings(file configFile, prefix string) driven.SourceFetchSettings {
+	settings := driven.SourceFetchSettings{
+		Username: file.getenv(prefix + "USERNAME"),
+		Password: file.getenv(prefix + "PASSWORD"),
+		ProxyURL: file.getenv(prefix + "PROXY"),
+	}
+
+	if headersStr := file.getenv(prefix + "HEADERS"); headersStr != "" {
+		var headers map[string]string
+		if err := json.Unmarshal([]byte(headersStr), &headers); err == nil {
+			settings.Headers = headers
+		}
+	}
+
+	if skipStr := file.getenv(prefix + "INSECURE_SKIP_VERIFY"); skipStr != "" {
+		if parsed, err := strconv.ParseBool(skipStr); err == nil {
+			settings.InsecureSkipVerify = parsed
+		}
+	}
+
+	return settings
+}
+
 func main() {
-	cfg := loadConfig()
+	validate := flag.Bool("validate", false, "check the configuration, print a report and exit")
+	flag.Parse()
+
+	// Settings come from the environment and then the optional config file
+	configPath := os.Getenv("CONFIG_FILE")
+	if configPath == "" {
+		configPath = "config.yaml"
+	}
+	file, err := readConfigFile(configPath)
+	if err != nil {
+		log.Fatalf("failed to read config file: %v", err)
+	}
+	cfg := loadConfig(file)
+
+	// --validate checks what serving would need and exits non-zero if
+	// anything is missing, e.g. as a container's pre-start check
+	if *validate {
+		report := application.NewConfigValidator(func() []application.ConfigCheck {
+			return configChecks(cfg, true)
+		}, configCheckTimeout).Validate(context.Background())
+		printConfigReport(os.Stdout, report)
+		if report.Status != "ok" {
+			os.Exit(1)
+		}
+		return
+	}
 
-	// Create structured logger
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: cfg.LogLevel,
-	}))
+	// Create structured logger; records logged with a request context
+	// carry its request ID. The level can be changed by a config reload.
+	var logLevel slog.LevelVar
+	logLevel.Set(cfg.LogLevel)
+	logger := slog.New(logging.NewContextHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: &logLevel,
+	})))
 	slog.SetDefault(logger)
 
 	logger.Info("starting iptv-manager",
+		"config_file", configPath,
 		"port", cfg.Port,
-		"acestream_url", cfg.AceStreamEngineURL,
+		"acestream_urls", cfg.AceStreamEngineURLs,
+		"acestream_balancing", cfg.AceStreamEngineBalancing,
 		"epg_url", cfg.EPGURL,
+		"epg_sync_schedule", cfg.EPGSyncSchedule.String(),
 		"db_path", cfg.DBPath,
+		"db_driver", cfg.DBDriver,
+		"data_dir", cfg.DataDir,
 		"log_level", cfg.LogLevel.String(),
 		"stream_write_timeout", cfg.StreamWriteTimeout,
+		"stream_write_timeout_max", cfg.StreamWriteTimeoutMax,
 	)
 
 	// Open BoltDB
@@ -167,142 +1031,710 @@ func main() {
 		}
 	}()
 
-	// Create driven adapters (repositories and external services)
-	channelRepo, err := driven.NewChannelBoltDBRepository(db)
-	if err != nil {
-		log.Fatalf("failed to create channel repository: %v", err)
+	// Shared metrics registry, scraped at /metrics
+	metricsRegistry := metrics.NewRegistry()
+	dbDurations := metricsRegistry.NewHistogram("iptv_boltdb_operation_duration_seconds",
+		"Duration of BoltDB repository operations.", nil, "repository", "operation")
+	playlistDurations := metricsRegistry.NewHistogram("iptv_playlist_generation_duration_seconds",
+		"Time taken to generate and serve a playlist.", nil, "format")
+
+	// Create driven adapters (repositories and external services).
+	// Channels and streams can live in SQLite; everything else stays in BoltDB.
+	var baseChannelRepo port.ChannelRepository
+	var baseStreamRepo port.StreamRepository
+	switch cfg.DBDriver {
+	case "sqlite":
+		sqliteDB, err := driven.OpenSQLite(context.Background(), cfg.SQLitePath)
+		if err != nil {
+			log.Fatalf("failed to open sqlite database: %v", err)
+		}
+		defer func() {
+			if err := sqliteDB.Close(); err != nil {
+				log.Printf("error closing sqlite database: %v", err)
+			}
+		}()
+		logger.Info("using sqlite for channels and streams", "sqlite_path", cfg.SQLitePath)
+
+		baseChannelRepo, err = driven.NewChannelSQLiteRepository(sqliteDB)
+		if err != nil {
+			log.Fatalf("failed to create channel repository: %v", err)
+		}
+
+		baseStreamRepo, err = driven.NewStreamSQLiteRepository(sqliteDB)
+		if err != nil {
+			log.Fatalf("failed to create stream repository: %v", err)
+		}
+	default:
+		baseChannelRepo, err = driven.NewChannelBoltDBRepository(db)
+		if err != nil {
+			log.Fatalf("failed to create channel repository: %v", err)
+		}
+
+		baseStreamRepo, err = driven.NewStreamBoltDBRepository(db)
+		if err != nil {
+			log.Fatalf("failed to create stream repository: %v", err)
+		}
 	}
 
-	streamRepo, err := driven.NewStreamBoltDBRepository(db)
+	engines := make([]driven.PoolEngine, len(cfg.AceStreamEngineURLs))
+	for i, engineURL := range cfg.AceStreamEngineURLs {
+		engine := driven.NewAceStreamHTTPAdapter(engineURL, logger)
+		engine.SetAuth(cfg.AceStreamEngineAuth)
+		engines[i] = driven.PoolEngine{Name: engineURL, Engine: engine}
+	}
+	aceStreamEngine, err := driven.NewAceStreamEnginePool(engines, cfg.AceStreamEngineBalancing, logger)
 	if err != nil {
-		log.Fatalf("failed to create stream repository: %v", err)
+		log.Fatalf("failed to create engine pool: %v", err)
 	}
 
-	aceStreamEngine := driven.NewAceStreamHTTPAdapter(cfg.AceStreamEngineURL, logger)
-
-	subscriptionRepo, err := driven.NewSubscriptionBoltDBRepository(db)
+	boltSubscriptionRepo, err := driven.NewSubscriptionBoltDBRepository(db)
 	if err != nil {
 		log.Fatalf("failed to create subscription repository: %v", err)
 	}
 
-	probeRepo, err := driven.NewProbeBoltDBRepository(db)
+	boltGroupRepo, err := driven.NewGroupBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create group repository: %v", err)
+	}
+
+	boltProbeRepo, err := driven.NewProbeBoltDBRepository(db)
 	if err != nil {
 		log.Fatalf("failed to create probe repository: %v", err)
 	}
 
+	boltTokenRepo, err := driven.NewTokenBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create token repository: %v", err)
+	}
+
+	recordingRepo, err := driven.NewRecordingBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create recording repository: %v", err)
+	}
+
+	ruleRepo, err := driven.NewRuleBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create override rule repository: %v", err)
+	}
+
+	engineSessionRepo, err := driven.NewEngineSessionBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create engine session repository: %v", err)
+	}
+
+	userRepo, err := driven.NewUserBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create user repository: %v", err)
+	}
+
+	favoriteRepo, err := driven.NewFavoriteBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create favorite repository: %v", err)
+	}
+
+	sourceChangeRepo, err := driven.NewSourceChangeBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create source change repository: %v", err)
+	}
+
+	webhookRepo, err := driven.NewWebhookBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create webhook repository: %v", err)
+	}
+
+	accessRuleRepo, err := driven.NewAccessRuleBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create access rule repository: %v", err)
+	}
+
+	auditLogRepo, err := driven.NewAuditLogBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create audit log repository: %v", err)
+	}
+
+	statsRepo, err := driven.NewStreamStatsBoltDBRepository(db)
+	if err != nil {
+		log.Fatalf("failed to create stats history repository: %v", err)
+	}
+
+	channelRepo := driven.NewInstrumentedChannelRepository(baseChannelRepo, dbDurations)
+	streamRepo := driven.NewInstrumentedStreamRepository(baseStreamRepo, dbDurations)
+	subscriptionRepo := driven.NewInstrumentedSubscriptionRepository(boltSubscriptionRepo, dbDurations)
+	groupRepo := driven.NewInstrumentedGroupRepository(boltGroupRepo, dbDurations)
+	probeRepo := driven.NewInstrumentedProbeRepository(boltProbeRepo, dbDurations)
+	tokenRepo := driven.NewInstrumentedTokenRepository(boltTokenRepo, dbDurations)
+
+	logoStore, err := driven.NewLogoFileStore(filepath.Join(cfg.DataDir, "logos"))
+	if err != nil {
+		log.Fatalf("failed to create logo store: %v", err)
+	}
+	logoFetcher := driven.NewLogoHTTPFetcher(&http.Client{Timeout: 30 * time.Second})
+	playlistFetcher := driven.NewPlaylistHTTPFetcher(&http.Client{Timeout: 30 * time.Second})
+
+	backupStore, err := driven.NewBackupFileStore(filepath.Join(cfg.DataDir, "backups"))
+	if err != nil {
+		log.Fatalf("failed to create backup store: %v", err)
+	}
+
+	recordingStore, err := driven.NewRecordingFileStore(filepath.Join(cfg.DataDir, "recordings"))
+	if err != nil {
+		log.Fatalf("failed to create recording store: %v", err)
+	}
+
+	// Upstream guide and hash lists are revalidated with conditional GETs
+	httpCache, err := driven.NewHTTPFileCache(filepath.Join(cfg.DataDir, "http-cache"))
+	if err != nil {
+		log.Fatalf("failed to create HTTP cache: %v", err)
+	}
+	httpCache.SetMemoryLimit(cfg.HTTPCacheMemoryLimit)
+	if entries, blobs, err := httpCache.Prune(context.Background(), cfg.HTTPCacheMaxAge); err != nil {
+		logger.Error("startup HTTP cache prune failed", "error", err)
+	} else {
+		logger.Info("startup HTTP cache prune completed", "max_age", cfg.HTTPCacheMaxAge, "entries_pruned", entries, "blobs_pruned", blobs)
+	}
+	registerHTTPCacheMetrics(metricsRegistry, httpCache)
+
 	epgFetcher := driven.NewEPGXMLFetcher(cfg.EPGURL, &http.Client{Timeout: 30 * time.Second})
+	epgFetcher.SetCache(httpCache)
 
 	acestreamSource := driven.NewAcestreamHTTPSource(cfg.AcestreamSourceNewEraURL, cfg.AcestreamSourceElcanoURL)
+	acestreamSource.SetDisplayNameFallback(cfg.AcestreamSourceNameFallback)
+	acestreamSource.SetCache(httpCache)
+	for source, settings := range cfg.AcestreamSourceFetch {
+		if err := acestreamSource.SetFetchSettings(source, settings); err != nil {
+			log.Fatalf("failed to configure acestream source: %v", err)
+		}
+	}
 
 	// Create application services
+	// Live status events pushed to the SPA at /api/events
+	eventBus := application.NewEventBus()
+
 	channelService := application.NewChannelService(channelRepo, streamRepo)
+	channelService.SetEventBus(eventBus)
+	channelService.SetGroupRepository(groupRepo)
+	groupService := application.NewGroupService(groupRepo, channelRepo)
+	groupService.SetEventBus(eventBus)
 	streamService := application.NewStreamService(streamRepo, channelRepo)
+	streamService.SetSearcher(aceStreamEngine)
+	auditService := application.NewAuditService(auditLogRepo)
+	streamService.SetAuditService(auditService)
+	streamService.SetEventBus(eventBus)
+	importService := application.NewImportService(channelRepo, streamRepo, playlistFetcher)
+	stateService := application.NewStateService(channelRepo, streamRepo, groupRepo, ruleRepo)
+	stateService.SetEventBus(eventBus)
+	// Backups cover the BoltDB file only; with DB_DRIVER=sqlite channels and streams are not included
+	backupService := application.NewBackupService(driven.NewBoltDBBackup(db), backupStore, cfg.BackupRetention, logger)
+	backupService.SetEventBus(eventBus)
+	logoService := application.NewLogoService(logoFetcher, logoStore, logger)
 	playlistService := application.NewPlaylistService(streamRepo, channelRepo, probeRepo, cfg.ProbeWindow)
+	playlistService.SetLogoService(logoService)
+	playlistService.SetGroupRepository(groupRepo)
+	playlistService.SetCatchupDays(cfg.PlaylistCatchupDays)
+	playlistService.SetAvailability(cfg.PlaylistAvailability)
+	playlistService.SetMinHealth(cfg.PlaylistMinHealth)
+	playlistService.SetRuleRepository(ruleRepo)
+	playlistService.SetGuide(epgFetcher)
+	overrideRuleService := application.NewOverrideRuleService(ruleRepo, playlistService)
+	userService := application.NewUserService(userRepo, playlistService)
+	favoriteService := application.NewFavoriteService(favoriteRepo, channelRepo, playlistService)
+	overrideRuleService.SetEventBus(eventBus)
 	healthService := application.NewHealthService(channelRepo, aceStreamEngine)
-	aceStreamProxyService := application.NewAceStreamProxyService(aceStreamEngine, logger, cfg.StreamWriteTimeout)
+	healthService.SetEventBus(eventBus)
+	engineBreaker := circuitbreaker.New(cfg.EngineBreakerThreshold, cfg.EngineBreakerTimeout)
+	breakers := circuitbreaker.NewRegistry()
+	breakers.Register("engine", engineBreaker)
+	aceStreamProxyService := application.NewAceStreamProxyService(aceStreamEngine, logger, cfg.StreamWriteTimeout, engineBreaker)
+	aceStreamProxyService.SetEventBus(eventBus)
+	aceStreamProxyService.SetFailoverPolicy(application.FailoverPolicy{
+		MaxAttempts:  cfg.FailoverMaxAttempts,
+		StallTimeout: cfg.FailoverStallTimeout,
+	})
+	aceStreamProxyService.SetEngineIdleTimeout(cfg.EngineIdleTimeout)
+	aceStreamProxyService.SetClientIdleTimeout(cfg.ClientIdleTimeout)
+	aceStreamProxyService.SetMaxEngineStreams(cfg.TunerCount)
+	if err := aceStreamProxyService.SetStreamQueue(cfg.StreamQueue); err != nil {
+		log.Fatalf("invalid stream queue settings: %v", err)
+	}
+	aceStreamProxyService.SetClientBuffer(cfg.ClientBuffer)
+	aceStreamProxyService.SetPrebuffer(cfg.Prebuffer)
+	aceStreamProxyService.SetLogSampleRate(cfg.LogSampleRate)
+	aceStreamProxyService.SetResumeGrace(cfg.StreamResumeGrace)
+	aceStreamProxyService.SetEngineSessionRepository(engineSessionRepo)
+	if err := aceStreamProxyService.SetBandwidthLimits(cfg.BandwidthLimits); err != nil {
+		log.Fatalf("invalid bandwidth limits: %v", err)
+	}
+	// The breaker and buffer settings were applied above; the rest of the
+	// resilience settings start from the configured reconnection backoff
+	resilience := aceStreamProxyService.Resilience()
+	resilience.ReconnectAttempts = cfg.ReconnectAttempts
+	resilience.ReconnectInitialBackoff = cfg.ReconnectInitialBackoff
+	resilience.ReconnectMaxBackoff = cfg.ReconnectMaxBackoff
+	if err := aceStreamProxyService.SetResilience(resilience); err != nil {
+		log.Fatalf("invalid resilience settings: %v", err)
+	}
+	registerStreamMetrics(metricsRegistry, aceStreamProxyService)
 	subscriptionService := application.NewSubscriptionService(subscriptionRepo, epgFetcher)
+	subscriptionService.SetEventBus(eventBus)
+	subscriptionService.SetStaleWhileRevalidate(cfg.EPGCacheStaleRevalidate)
 	epgSyncService := application.NewEPGSyncService(epgFetcher, acestreamSource, channelRepo, streamRepo, subscriptionRepo, logger)
+	epgSyncService.SetLogoService(logoService)
+	epgSyncService.SetEventBus(eventBus)
+	epgSyncService.SetAutoMapThresholds(cfg.EPGAutoMapThreshold, cfg.EPGAutoMapReviewThreshold)
+	sourceChangeService := application.NewSourceChangeService(sourceChangeRepo, streamRepo, channelRepo, logger)
+	sourceChangeService.SetAutoDisable(cfg.SourceChangesAutoDisable)
+	sourceChangeService.SetEventBus(eventBus)
+	epgSyncService.SetSourceChangeService(sourceChangeService)
+	epgSyncService.SetSourcePriorities(cfg.AcestreamSourcePriority)
+	if err := epgSyncService.SetConflictPolicy(application.ConflictPolicy(cfg.SourceConflictPolicy)); err != nil {
+		log.Fatalf("invalid SOURCE_CONFLICT_POLICY: %v", err)
+	}
+	authService := application.NewAuthService(tokenRepo, application.AuthConfig{
+		Username:   cfg.AuthUsername,
+		Password:   cfg.AuthPassword,
+		SessionKey: sessionKey(cfg.AuthSessionKey),
+		SessionTTL: cfg.AuthSessionTTL,
+	})
+	if !authService.Enabled() {
+		logger.Warn("authentication disabled; set AUTH_USERNAME and AUTH_PASSWORD to protect the UI and API")
+	}
+	// Playlist stream links are signed once keys are configured
+	var streamLinks *application.StreamLinks
+	if cfg.StreamLinkKeys != "" {
+		keys, err := auth.ParseLinkKeys(cfg.StreamLinkKeys)
+		if err != nil {
+			log.Fatalf("invalid STREAM_LINK_KEYS: %v", err)
+		}
+		streamLinks = application.NewStreamLinks(auth.NewLinkSigner(keys), cfg.StreamLinkTTL, cfg.StreamLinkRequired, tokenRepo)
+		playlistService.SetStreamLinks(streamLinks)
+	} else if cfg.StreamLinkRequired {
+		log.Fatalf("STREAM_LINK_REQUIRED needs STREAM_LINK_KEYS")
+	}
 	probeService := application.NewProbeService(probeRepo, streamRepo, aceStreamEngine, logger, cfg.ProbeTimeout, cfg.ProbeWindow, aceStreamProxyService, cfg.ProbeDelay, cfg.ProbeMaxConsecutiveFailures)
+	probeService.SetBatchSize(cfg.ProbeBatchSize)
+	probeService.SetPrebufferWait(cfg.ProbePrebufferWait)
+
+	if cfg.ProbeMaxAge > 0 {
+		pruned, err := probeService.PruneOlderThan(context.Background(), cfg.ProbeMaxAge)
+		if err != nil {
+			logger.Error("startup probe prune failed", "error", err)
+		} else {
+			logger.Info("startup probe prune completed", "max_age", cfg.ProbeMaxAge, "pruned", pruned)
+		}
+	}
+
+	recordingService := application.NewRecordingService(recordingRepo, recordingStore, channelRepo, streamRepo, aceStreamProxyService, cfg.RecordingRetention, logger)
+	recordingService.SetProbeService(probeService)
+	recordingService.SetEventBus(eventBus)
+	playlistService.SetRecordingService(recordingService)
+	webhookService := application.NewWebhookService(webhookRepo, driven.NewWebhookHTTPSender(nil), logger)
+	accessService := application.NewAccessService(accessRuleRepo)
+	if cfg.GeoIPDatabase != "" {
+		// Country rules would silently stop matching without the database,
+		// letting in clients they were meant to keep out
+		geoIP, err := driven.NewGeoIPCSVDatabase(cfg.GeoIPDatabase)
+		if err != nil {
+			log.Fatalf("failed to load geoip database: %v", err)
+		}
+		accessService.SetGeoIPLocator(geoIP)
+	}
+	if err := accessService.Load(context.Background()); err != nil {
+		log.Fatalf("failed to load access rules: %v", err)
+	}
+	backupService.SetAccessService(accessService)
+	if err := recordingService.MarkInterrupted(context.Background()); err != nil {
+		logger.Error("failed to mark interrupted recordings", "error", err)
+	}
+
+	warmupService := application.NewWarmupService(aceStreamProxyService, logger)
+	warmupService.SetProbeService(probeService)
+	warmupService.SetSchedule(epgFetcher, channelRepo, streamRepo, cfg.WarmupChannels, cfg.WarmupLead)
+
+	statsHistoryService := application.NewStatsHistoryService(statsRepo, aceStreamProxyService, cfg.StatsHistoryRawRetention, cfg.StatsHistoryRetention, logger)
+
+	// Create background schedulers
+	epgSyncScheduler := scheduler.NewWithSchedule("epg-sync", cfg.EPGSyncSchedule, epgSyncService.SyncChannels, logger)
+	probeScheduler := scheduler.New("stream-probe", cfg.ProbeInterval, probeService.ProbeAllStreams, logger)
+	engineReaperScheduler := scheduler.New("engine-reaper", cfg.EngineReaperInterval, aceStreamProxyService.ReapEngineStreams, logger)
+	clientReaperScheduler := scheduler.New("client-reaper", cfg.ClientReaperInterval, aceStreamProxyService.ReapIdleClients, logger)
+	engineHealthScheduler := scheduler.New("engine-health", cfg.EngineHealthInterval, healthService.WatchEngine, logger)
+	recordingScheduler := scheduler.New("recordings", 15*time.Second, recordingService.RunSchedule, logger)
+	schedulers := []*scheduler.Scheduler{epgSyncScheduler, probeScheduler, engineReaperScheduler, clientReaperScheduler, engineHealthScheduler, recordingScheduler}
+	if cfg.BackupInterval > 0 {
+		schedulers = append(schedulers, scheduler.New("backup", cfg.BackupInterval, backupService.RunScheduledBackup, logger))
+	}
+	if len(cfg.WarmupChannels) > 0 {
+		schedulers = append(schedulers, scheduler.New("warmups", time.Minute, warmupService.RunSchedule, logger))
+	}
+	if cfg.StatsHistoryInterval > 0 {
+		schedulers = append(schedulers,
+			scheduler.New("stats-history", cfg.StatsHistoryInterval, statsHistoryService.Record, logger),
+			scheduler.New("stats-compaction", time.Hour, statsHistoryService.Compact, logger))
+	}
 
 	// Create HTTP handlers
-	channelHandler := driver.NewChannelHTTPHandler(channelService)
-	streamHandler := driver.NewStreamHTTPHandler(streamService)
+	channelHandler := driver.NewChannelHTTPHandler(channelService, probeService)
+	mediaInfoService := application.NewMediaInfoService(aceStreamProxyService, application.MediaInfoConfig{
+		SampleDuration: 3 * time.Second,
+		Timeout:        60 * time.Second,
+	}, logger)
+	channelService.SetMediaAnalyzer(mediaInfoService)
+	streamHandler := driver.NewStreamHTTPHandler(streamService, probeService, mediaInfoService)
+	streamHandler.SetStatsWatcher(aceStreamProxyService, 3*time.Second)
+	streamHandler.SetWarmupService(warmupService)
+	if cfg.StatsHistoryInterval > 0 {
+		streamHandler.SetStatsHistory(statsHistoryService)
+	}
+	if videoTranscoder, err := driven.NewFFmpegVideoTranscoder(cfg.FFmpegPath); err != nil {
+		logger.Info("video transcoding disabled", "error", err)
+	} else {
+		aceStreamProxyService.SetVideoTranscoder(videoTranscoder)
+	}
+	if frameExtractor, err := driven.NewFFmpegFrameExtractor(cfg.FFmpegPath); err != nil {
+		logger.Info("stream previews disabled", "error", err)
+	} else {
+		streamHandler.SetPreviewService(application.NewPreviewService(aceStreamProxyService, frameExtractor, application.PreviewConfig{
+			CacheTTL: cfg.PreviewCacheTTL,
+			Timeout:  60 * time.Second,
+		}, logger))
+	}
+	importHandler := driver.NewImportHTTPHandler(importService)
+	stateHandler := driver.NewStateHTTPHandler(stateService)
+	backupHandler := driver.NewBackupHTTPHandler(backupService, logger)
+	recordingHandler := driver.NewRecordingHTTPHandler(recordingService, logger)
 	playlistHandler := driver.NewPlaylistHTTPHandler(playlistService)
+	playlistHandler.SetUserService(userService)
+	playlistHandler.SetFavoriteService(favoriteService)
+	playlistHandler.SetAuthService(authService)
+	playlistPreviewHandler := driver.NewPlaylistPreviewHTTPHandler(playlistService)
+	playlistPreviewHandler.SetUserService(userService)
+	userHandler := driver.NewUserHTTPHandler(userService)
+	favoriteHandler := driver.NewFavoriteHTTPHandler(favoriteService)
+	sourceChangeHandler := driver.NewSourceChangeHTTPHandler(sourceChangeService)
+	webhookHandler := driver.NewWebhookHTTPHandler(webhookService)
+	accessHandler := driver.NewAccessHTTPHandler(accessService)
+	auditHandler := driver.NewAuditHTTPHandler(auditService)
+	settingsHandler := driver.NewSettingsHTTPHandler(aceStreamProxyService)
+	settingsHandler.SetWriteTimeoutController(aceStreamProxyService)
+	settingsHandler.SetLogLevelController(&logLevel)
+	settingsHandler.SetResilienceController(aceStreamProxyService)
+	settingsHandler.SetStreamQueueController(aceStreamProxyService)
+	// Streams are adapted to players by User-Agent; the profiles can be changed
+	// through PUT /api/settings/player-profiles
+	playerProfiles := application.NewPlayerProfiles()
+	settingsHandler.SetPlayerProfileController(playerProfiles)
+	xmltvHandler := driver.NewXMLTVHTTPHandler(playlistService)
+	// Without a tuner limit, advertise as many tuners as a typical HDHomeRun
+	tunerCount := cfg.TunerCount
+	if tunerCount == 0 {
+		tunerCount = 4
+	}
+	hdhomerunHandler := driver.NewHDHomeRunHTTPHandler(playlistService, driver.HDHomeRunConfig{
+		FriendlyName: cfg.HDHomeRunFriendlyName,
+		DeviceID:     cfg.HDHomeRunDeviceID,
+		TunerCount:   tunerCount,
+	})
+	dlnaConfig := driver.DLNAConfig{
+		FriendlyName: cfg.DLNAFriendlyName,
+		UUID:         cfg.DLNAUUID,
+	}
+	logoHandler := driver.NewLogoHTTPHandler(logoService)
 	healthHandler := driver.NewHealthHTTPHandler(healthService)
-	aceStreamHandler := driver.NewAceStreamHTTPHandler(aceStreamProxyService, logger)
+	// Validates the config file and environment as they are now, so that
+	// changes can be checked before they are reloaded or restarted into. The
+	// port is only checked if it changed, as the server holds the current one
+	configValidateHandler := driver.NewConfigValidateHTTPHandler(application.NewConfigValidator(func() []application.ConfigCheck {
+		file, err := readConfigFile(configPath)
+		if err != nil {
+			return []application.ConfigCheck{{
+				Name:   "config_file",
+				Target: configPath,
+				Hint:   "Fix the syntax of the config file",
+				Check:  func(ctx context.Context) error { return err },
+			}}
+		}
+		next := loadConfig(file)
+		return configChecks(next, next.Port != cfg.Port || next.Listen != cfg.Listen ||
+			next.TLSCert != cfg.TLSCert || next.TLSKey != cfg.TLSKey)
+	}, configCheckTimeout))
+	// HLS remux is opt-in; a nil provider makes the HLS routes respond 404
+	var hlsProvider driver.HLSProvider
+	var hlsService *application.HLSService
+	if cfg.HLSEnabled {
+		hlsService = application.NewHLSService(aceStreamProxyService, application.HLSConfig{
+			SegmentDuration: cfg.HLSSegmentDuration,
+			Retention:       cfg.HLSSegmentRetention,
+			IdleTimeout:     cfg.HLSIdleTimeout,
+			ReadyTimeout:    cfg.HLSSegmentDuration*2 + 30*time.Second,
+		}, logger)
+		hlsProvider = hlsService
+	}
+	aceStreamHandler := driver.NewAceStreamHTTPHandler(aceStreamProxyService, hlsProvider, logger)
+	aceStreamChannelHandler := driver.NewAceStreamChannelHTTPHandler(channelService, streamService, aceStreamProxyService, probeService, logger)
+	catchupHandler := driver.NewCatchupHTTPHandler(recordingService, logger)
+	aceStreamHandler.SetPlayerProfiles(playerProfiles)
+	aceStreamChannelHandler.SetPlayerProfiles(playerProfiles)
+	aceStreamHandler.SetMaxWriteTimeoutHint(cfg.StreamWriteTimeoutMax)
+	aceStreamChannelHandler.SetMaxWriteTimeoutHint(cfg.StreamWriteTimeoutMax)
+	if streamLinks != nil {
+		aceStreamHandler.SetStreamLinks(streamLinks)
+		aceStreamChannelHandler.SetStreamLinks(streamLinks)
+		catchupHandler.SetStreamLinks(streamLinks)
+	}
 	epgHandler := driver.NewEPGHTTPHandler(epgSyncService, subscriptionService, channelService)
 	subscriptionHandler := driver.NewSubscriptionHTTPHandler(subscriptionService)
+	groupHandler := driver.NewGroupHTTPHandler(groupService)
+	overrideRuleHandler := driver.NewOverrideRuleHTTPHandler(overrideRuleService)
+	searchHandler := driver.NewSearchHTTPHandler(streamService)
+	engineHandler := driver.NewEngineHTTPHandler(application.NewEngineService(aceStreamEngine, logger))
 	probeHandler := driver.NewProbeHTTPHandler(probeService)
+	authHandler := driver.NewAuthHTTPHandler(authService)
+	tokenHandler := driver.NewTokenHTTPHandler(authService)
 	dashboardHandler := driver.NewDashboardHTTPHandler(channelService, probeService, aceStreamProxyService, healthService)
 	debugHandler := driver.NewDebugHTTPHandler(aceStreamProxyService)
+	sessionHandler := driver.NewSessionHTTPHandler(aceStreamProxyService)
+	eventsHandler := driver.NewEventsHTTPHandler(eventBus)
+	schedulerHandler := driver.NewSchedulerHTTPHandler(schedulers...)
+	circuitBreakerHandler := driver.NewCircuitBreakerHTTPHandler(breakers)
+	// Cached sources not refreshed within a sync interval are reported stale
+	cacheHandler := driver.NewCacheHTTPHandler(application.NewCacheService(httpCache, epgFetcher, acestreamSource, cfg.RefreshInterval))
 
 	// Register API routes
 	apiMux := http.NewServeMux()
 	apiMux.Handle("/channels", channelHandler)
 	apiMux.Handle("/channels/", channelHandler)
+	apiMux.Handle("/groups", groupHandler)
+	apiMux.Handle("/groups/", groupHandler)
+	apiMux.Handle("/override-rules", overrideRuleHandler)
+	apiMux.Handle("/override-rules/", overrideRuleHandler)
 	apiMux.Handle("/streams", streamHandler)
 	apiMux.Handle("/streams/", streamHandler)
+	apiMux.Handle("/search", searchHandler)
+	apiMux.Handle("/engine", engineHandler)
+	apiMux.Handle("/engine/", engineHandler)
+	apiMux.Handle("/playlist/preview", playlistPreviewHandler)
+	apiMux.Handle("/import/m3u", importHandler)
+	apiMux.Handle("/import/acestream-search", importHandler)
+	apiMux.Handle("/import/tvheadend", importHandler)
+	apiMux.Handle("/state", stateHandler)
+	apiMux.Handle("/backup", backupHandler)
+	apiMux.Handle("/restore", backupHandler)
+	apiMux.Handle("/recordings", recordingHandler)
+	apiMux.Handle("/recordings/", recordingHandler)
 	apiMux.Handle("/health", healthHandler)
+	apiMux.Handle("/config/validate", configValidateHandler)
 	apiMux.Handle("/epg/", epgHandler)
 	apiMux.Handle("/subscriptions", subscriptionHandler)
 	apiMux.Handle("/subscriptions/", subscriptionHandler)
 	apiMux.Handle("/probes/", probeHandler)
 	apiMux.Handle("/quality/", probeHandler)
 	apiMux.Handle("/dashboard", dashboardHandler)
+	apiMux.Handle("/sessions", sessionHandler)
+	apiMux.Handle("/sessions/", sessionHandler)
+	apiMux.Handle("/events", eventsHandler)
 	apiMux.Handle("/debug/streams", debugHandler)
+	apiMux.Handle("/debug/schedulers", schedulerHandler)
+	apiMux.Handle("/circuitbreakers", circuitBreakerHandler)
+	apiMux.Handle("/circuitbreakers/", circuitBreakerHandler)
+	apiMux.Handle("/cache", cacheHandler)
+	apiMux.Handle("/cache/", cacheHandler)
+	apiMux.Handle("/auth/", authHandler)
+	apiMux.Handle("/tokens", tokenHandler)
+	apiMux.Handle("/tokens/", tokenHandler)
+	apiMux.Handle("/users", userHandler)
+	apiMux.Handle("/users/", userHandler)
+	apiMux.Handle("/favorites", favoriteHandler)
+	apiMux.Handle("/favorites/", favoriteHandler)
+	apiMux.Handle("/sources/", sourceChangeHandler)
+	apiMux.Handle("/webhooks", webhookHandler)
+	apiMux.Handle("/webhooks/", webhookHandler)
+	apiMux.Handle("/access-rules", accessHandler)
+	apiMux.Handle("/access-rules/", accessHandler)
+	apiMux.Handle("/audit", auditHandler)
+	apiMux.Handle("/settings/", settingsHandler)
+
+	// Versioned API: the same routes under /api/v1, validated against the
+	// OpenAPI document served alongside them and safe to retry with an
+	// Idempotency-Key. The unversioned /api/ stays for the UI and existing
+	// clients
+	v1Mux := http.NewServeMux()
+	v1Mux.Handle("/openapi.json", driver.NewOpenAPIHTTPHandler())
+	v1Mux.Handle("/", apiMux)
+	validationMiddleware, err := driver.NewRequestValidationMiddleware(driver.NewIdempotencyMiddleware(v1Mux, 24*time.Hour))
+	if err != nil {
+		log.Fatalf("failed to load OpenAPI document: %v", err)
+	}
+	var apiV1 http.Handler = validationMiddleware
 
 	// Root router: API under /api/, streaming routes at root, SPA for everything else
 	rootMux := http.NewServeMux()
 	rootMux.Handle("/api/", http.StripPrefix("/api", apiMux))
-	rootMux.Handle("/playlist.m3u", playlistHandler)
+	rootMux.Handle("/api/v1/", http.StripPrefix("/api/v1", apiV1))
+	rootMux.Handle("/playlist.m3u", metrics.InstrumentHandler(playlistDurations.With("m3u"), playlistHandler))
+	rootMux.Handle("/playlist/", metrics.InstrumentHandler(playlistDurations.With("m3u"), playlistHandler))
+	rootMux.Handle("/epg.xml", metrics.InstrumentHandler(playlistDurations.With("xmltv"), xmltvHandler))
+	rootMux.Handle("/metrics", metricsRegistry)
+	rootMux.Handle("/discover.json", hdhomerunHandler)
+	rootMux.Handle("/lineup.json", hdhomerunHandler)
+	rootMux.Handle("/lineup_status.json", hdhomerunHandler)
+	rootMux.Handle("/lineup.post", hdhomerunHandler)
+	if cfg.DLNAEnabled {
+		rootMux.Handle("/dlna/", driver.NewDLNAHTTPHandler(playlistService, dlnaConfig))
+	}
+	rootMux.Handle("/logos/", logoHandler)
+	rootMux.Handle("/recordings/", recordingHandler)
 	rootMux.Handle("/ace/", aceStreamHandler)
+	rootMux.Handle("/ace/channel/", aceStreamChannelHandler)
+	rootMux.Handle("/ace/c/", aceStreamChannelHandler)
+	rootMux.Handle("/ace/catchup/", catchupHandler)
 	rootMux.Handle("/", newSPAHandler())
 
+	// Per-client limits sit in front of authentication so rejected clients
+	// cannot hammer the login endpoint or the engine either
+	streamLimiter := ratelimit.NewConcurrencyLimiter(cfg.StreamMaxPerClient)
+	apiLimiter := ratelimit.New(cfg.APIRateLimit, cfg.APIRateBurst)
+	registerRateLimitMetrics(metricsRegistry, streamLimiter, apiLimiter)
+	// Playlists, guides and API responses are compressed; engine streams are
+	// already compressed video and must reach the client unbuffered
+	var handler http.Handler = driver.NewCompressionMiddleware(rootMux, driver.CompressionConfig{
+		Paths:        []string{"/playlist.m3u", "/playlist/", "/epg.xml", "/api/"},
+		ExcludePaths: []string{"/ace/", "/api/events", "/api/v1/events"},
+	})
+	handler = driver.NewRateLimitMiddleware(streamLimiter, apiLimiter,
+		driver.NewAuthMiddleware(authService, handler, logger), logger)
+	// Clients outside the access rules are turned away before they count
+	// against any limit
+	handler = driver.NewAccessMiddleware(accessService, handler, logger)
+	// Generated URLs point back through the reverse proxy clients use
+	publicURLMiddleware, err := driver.NewPublicURLMiddleware(cfg.PublicBaseURL, handler)
+	if err != nil {
+		log.Fatalf("invalid PUBLIC_BASE_URL %q: %v", cfg.PublicBaseURL, err)
+	}
+	publicURLMiddleware.SetTrustedProxies(cfg.TrustedProxies)
+	handler = publicURLMiddleware
+
+	// Request IDs are assigned first so that every later log line, including
+	// rejections, can be correlated
+	handler = driver.NewRequestLogMiddleware(handler, driver.RequestLogConfig{
+		Enabled:   cfg.RequestLogEnabled,
+		SkipPaths: cfg.RequestLogSkipPaths,
+	}, logger)
+
 	// Create HTTP server
 	server := &http.Server{
-		Addr:         ":" + cfg.Port,
-		Handler:      rootMux,
+		Handler:      handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 0,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	// Start server in a goroutine
-	go func() {
-		logger.Info("http server listening", "addr", server.Addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+	// Bind every listen address before serving any, so a bad one fails the
+	// start instead of leaving the server half reachable
+	listenAddrs, err := cfg.listenAddrs()
+	if err != nil {
+		log.Fatalf("invalid listen configuration: %v", err)
+	}
+	// Listeners sharing a key pair share its reloader
+	certReloaders := make(map[[2]string]*certReloader)
+	listeners := make([]net.Listener, len(listenAddrs))
+	for i, addr := range listenAddrs {
+		if listeners[i], err = listen(addr); err != nil {
+			log.Fatalf("failed to listen on %s: %v", addr, err)
 		}
-	}()
+		if addr.CertFile == "" {
+			continue
+		}
+		pair := [2]string{addr.CertFile, addr.KeyFile}
+		if certReloaders[pair] == nil {
+			if certReloaders[pair], err = newCertReloader(addr.CertFile, addr.KeyFile, logger); err != nil {
+				log.Fatalf("failed to load tls certificate for %s: %v", addr, err)
+			}
+		}
+		listeners[i] = newTLSListener(listeners[i], certReloaders[pair])
+	}
 
-	// Background EPG sync
-	syncCtx, syncCancel := context.WithCancel(context.Background())
-	defer syncCancel()
-
-	go func() {
-		ticker := time.NewTicker(6 * time.Hour)
-		defer ticker.Stop()
-
-		logger.Info("epg sync scheduler started", "interval", "6h")
-
-		for {
-			select {
-			case <-ticker.C:
-				logger.Info("starting scheduled epg sync")
-				if err := epgSyncService.SyncChannels(syncCtx); err != nil {
-					logger.Error("epg sync failed", "error", err)
-				} else {
-					logger.Info("epg sync completed successfully")
-				}
-			case <-syncCtx.Done():
-				logger.Info("epg sync scheduler stopped")
-				return
+	// Stop the engine streams a previous run left open, e.g. after a crash,
+	// before serving any that could be mistaken for them
+	restoreCtx, restoreCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := aceStreamProxyService.RestoreEngineSessions(restoreCtx); err != nil {
+		logger.Warn("failed to stop engine streams left by the previous run", "error", err)
+	}
+	restoreCancel()
+
+	// Serve each listener in its own goroutine; Shutdown closes them all
+	for i, addr := range listenAddrs {
+		go func(l net.Listener) {
+			logger.Info("http server listening", "addr", addr.String(), "tls", addr.CertFile != "")
+			if err := server.Serve(l); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("server error on %s: %v", addr, err)
+			}
+		}(listeners[i])
+	}
+
+	// Announce the DLNA media server on the LAN, pointing renderers at the
+	// first plain TCP listener
+	dlnaCtx, stopDLNA := context.WithCancel(context.Background())
+	dlnaDone := make(chan struct{})
+	if port, ok := dlnaHTTPPort(listenAddrs); cfg.DLNAEnabled && ok {
+		ssdpServer := driver.NewSSDPServer(dlnaConfig, port, logger)
+		go func() {
+			defer close(dlnaDone)
+			if err := ssdpServer.Run(dlnaCtx); err != nil {
+				logger.Error("dlna announcements stopped", "error", err)
 			}
+		}()
+	} else {
+		if cfg.DLNAEnabled {
+			logger.Warn("dlna needs a listen address without tls, not announcing the media server")
 		}
-	}()
+		close(dlnaDone)
+	}
+
+	// Webhook notifications run until the event bus is closed on shutdown
+	go webhookService.Run(context.Background(), eventBus)
+	// The playlist's Last-Modified follows channel and source changes
+	go playlistService.TrackChanges(context.Background(), eventBus)
+
+	// Background schedulers (EPG sync, stream prober, engine stream reaper, client reaper, engine health, recordings, backups)
+	for _, s := range schedulers {
+		s.Start(context.Background())
+	}
 
-	// Background stream prober
-	go func() {
-		ticker := time.NewTicker(cfg.ProbeInterval)
-		defer ticker.Stop()
-
-		logger.Info("stream prober scheduler started", "interval", cfg.ProbeInterval)
-
-		for {
-			select {
-			case <-ticker.C:
-				logger.Info("starting scheduled stream probe")
-				if err := probeService.ProbeAllStreams(syncCtx); err != nil {
-					logger.Error("stream probe failed", "error", err)
-				} else {
-					logger.Info("stream probe completed successfully")
-				}
-			case <-syncCtx.Done():
-				logger.Info("stream prober scheduler stopped")
-				return
+	// Apply the settings that can change at runtime on SIGHUP or when the
+	// config file changes; everything else needs a restart
+	reloadConfig := func() {
+		file, err := readConfigFile(configPath)
+		if err != nil {
+			logger.Error("config reload failed, keeping current settings", "error", err)
+			return
+		}
+		next := loadConfig(file)
+		logLevel.Set(next.LogLevel)
+		for _, r := range certReloaders {
+			if err := r.reload(); err != nil {
+				logger.Error("tls certificate reload failed, keeping current certificate", "cert_file", r.certFile, "error", err)
 			}
 		}
-	}()
+		aceStreamProxyService.SetWriteTimeout(next.StreamWriteTimeout)
+		aceStreamProxyService.SetResumeGrace(next.StreamResumeGrace)
+		playlistService.SetCatchupDays(next.PlaylistCatchupDays)
+		if err := aceStreamProxyService.SetBandwidthLimits(next.BandwidthLimits); err != nil {
+			logger.Error("invalid bandwidth limits, keeping current limits", "error", err)
+		}
+		logger.Info("configuration reloaded",
+			"config_file", configPath,
+			"log_level", next.LogLevel.String(),
+			"stream_write_timeout", next.StreamWriteTimeout,
+			"stream_resume_grace", next.StreamResumeGrace,
+			"playlist_catchup_days", next.PlaylistCatchupDays,
+			"bandwidth_limits", next.BandwidthLimits)
+	}
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	go watchConfigFile(watchCtx, configPath, 5*time.Second, reloadConfig)
+	// Renewed certificates are picked up when their files change
+	for _, r := range certReloaders {
+		go r.watch(watchCtx, 5*time.Second)
+	}
 
 	// Graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -311,8 +1743,22 @@ func main() {
 
 	logger.Info("shutdown signal received, shutting down gracefully")
 
-	// Cancel background schedulers (EPG sync + stream prober)
-	syncCancel()
+	stopWatch()
+
+	// Tell renderers the media server is leaving
+	stopDLNA()
+	<-dlnaDone
+
+	// Stop background schedulers, waiting for in-flight runs
+	for _, s := range schedulers {
+		s.Stop()
+	}
+	if hlsService != nil {
+		hlsService.Close()
+	}
+
+	// End open event streams so they don't hold up the server shutdown
+	eventBus.Close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -321,5 +1767,102 @@ func main() {
 		logger.Error("server shutdown error", "error", err)
 	}
 
+	// Stop recordings in progress, keeping what they captured
+	if err := recordingService.Shutdown(ctx); err != nil {
+		logger.Error("recording shutdown error", "error", err)
+	}
+
+	if err := warmupService.Shutdown(ctx); err != nil {
+		logger.Error("warm-up shutdown error", "error", err)
+	}
+
+	// Release any engine streams still open so they don't keep transferring
+	// data on the engine after we exit
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer stopCancel()
+	aceStreamProxyService.StopEngineStreams(stopCtx)
+
 	logger.Info("server stopped")
 }
+
+// registerStreamMetrics exposes the proxy's session count and lifecycle
+// counters. Values are read at scrape time so the proxy stays unaware of the
+// registry.
+func registerStreamMetrics(reg *metrics.Registry, proxy *application.AceStreamProxyService) {
+	reg.NewGaugeFunc("iptv_active_streams", "Number of active proxied stream sessions.", func() float64 {
+		return float64(len(proxy.GetActiveStreams()))
+	})
+	reg.NewGaugeFunc("iptv_active_clients", "Number of clients attached to proxied streams.", func() float64 {
+		total := 0
+		for _, s := range proxy.GetActiveStreams() {
+			total += s.ClientCount
+		}
+		return float64(total)
+	})
+	reg.NewCounterFunc("iptv_bytes_streamed_total", "Bytes read from the engine and fanned out to clients.", func() float64 {
+		return float64(proxy.Counters().BytesStreamed)
+	})
+	reg.NewCounterFunc("iptv_engine_start_failures_total", "Engine stream start failures.", func() float64 {
+		return float64(proxy.Counters().StreamStartFailures)
+	})
+	reg.NewCounterFunc("iptv_engine_stop_failures_total", "Engine stream stop failures.", func() float64 {
+		return float64(proxy.Counters().StreamStopFailures)
+	})
+	reg.NewCounterFunc("iptv_client_stalls_total", "Runs of consecutive slow writes to a streaming client.", func() float64 {
+		return float64(proxy.Counters().ClientStalls)
+	})
+	reg.NewGaugeFunc("iptv_stream_queue_depth", "Number of stream requests waiting for a saturated engine.", func() float64 {
+		return float64(proxy.StreamQueueStats().Depth)
+	})
+	reg.NewCounterFunc("iptv_stream_queue_waits_total", "Stream requests that waited for a saturated engine.", func() float64 {
+		return float64(proxy.StreamQueueStats().Queued)
+	})
+	reg.NewCounterFunc("iptv_stream_queue_rejections_total", "Stream requests rejected because the stream queue was full or they waited too long.", func() float64 {
+		return float64(proxy.StreamQueueStats().Rejected)
+	})
+	reg.NewCounterFunc("iptv_clients_reaped_total", "Streaming clients dropped for taking no data for longer than the client idle timeout.", func() float64 {
+		return float64(proxy.Counters().ClientsReaped)
+	})
+	reg.NewCounterFunc("iptv_upstream_resumes_total", "Dropped engine connections resumed from the last byte received.", func() float64 {
+		return float64(proxy.Counters().UpstreamResumes)
+	})
+}
+
+// registerRateLimitMetrics exposes the per-client limiters' state.
+func registerRateLimitMetrics(reg *metrics.Registry, streams *ratelimit.ConcurrencyLimiter, api *ratelimit.Limiter) {
+	reg.NewGaugeFunc("iptv_limited_streams", "Number of streams counted against the per-client concurrency limit.", func() float64 {
+		return float64(streams.Active())
+	})
+	reg.NewCounterFunc("iptv_stream_limit_rejections_total", "Stream requests rejected by the per-client concurrency limit.", func() float64 {
+		return float64(streams.Rejected())
+	})
+	reg.NewCounterFunc("iptv_api_rate_limit_rejections_total", "API requests rejected by the per-client rate limit.", func() float64 {
+		return float64(api.Rejected())
+	})
+}
+
+// registerHTTPCacheMetrics exposes how often cached upstream files are served
+// from memory instead of disk.
+func registerHTTPCacheMetrics(reg *metrics.Registry, cache *driven.HTTPFileCache) {
+	reg.NewCounterFunc("iptv_http_cache_memory_hits_total", "Cached upstream files served from memory.", func() float64 {
+		hits, _ := cache.MemoryStats()
+		return float64(hits)
+	})
+	reg.NewCounterFunc("iptv_http_cache_memory_misses_total", "Cached upstream files read from disk with the memory layer enabled.", func() float64 {
+		_, misses := cache.MemoryStats()
+		return float64(misses)
+	})
+}
+
+// sessionKey returns the configured session signing key, or a random one when
+// none is set. A random key means UI sessions end when the process restarts.
+func sessionKey(configured string) []byte {
+	if configured != "" {
+		return []byte(configured)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("failed to generate session key: %v", err)
+	}
+	return key
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// certReloader serves a certificate key pair from disk, loading it again
+// when either file changes so renewed certificates are picked up without a
+// restart.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	logger   *slog.Logger
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+}
+
+// newCertReloader loads the key pair, failing if it cannot be read.
+func newCertReloader(certFile, keyFile string, logger *slog.Logger) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, logger: logger}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate returns the current certificate, for tls.Config.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// reload loads the key pair again. A pair that fails to load, for instance
+// because only one of the files has been replaced yet, leaves the current
+// certificate in place.
+func (r *certReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	return nil
+}
+
+// filesVersion identifies the current contents of both files.
+func (r *certReloader) filesVersion() string {
+	return configFileVersion(r.certFile) + "/" + configFileVersion(r.keyFile)
+}
+
+// watch reloads the key pair whenever either file changes, until ctx is done.
+func (r *certReloader) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := r.filesVersion()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			v := r.filesVersion()
+			if v == last {
+				continue
+			}
+			last = v
+			if err := r.reload(); err != nil {
+				r.logger.Error("tls certificate reload failed, keeping current certificate",
+					"cert_file", r.certFile, "error", err)
+				continue
+			}
+			r.logger.Info("tls certificate reloaded", "cert_file", r.certFile)
+		}
+	}
+}
+
+// newTLSListener serves l over TLS with the reloader's certificate. Only
+// HTTP/1.1 is offered, as on plain listeners: streams and websockets rely on
+// flushing and hijacking the connection.
+func newTLSListener(l net.Listener, r *certReloader) net.Listener {
+	return tls.NewListener(l, &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+		NextProtos:     []string{"http/1.1"},
+	})
+}
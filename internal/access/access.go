@@ -0,0 +1,204 @@
+// Package access models the network and country rules deciding which
+// clients may fetch playlists and streams.
+package access
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Action is what a rule does with the clients it matches.
+type Action string
+
+const (
+	// ActionAllow admits the clients a rule matches. Once any allow rule
+	// exists, clients matching none are denied.
+	ActionAllow Action = "allow"
+	// ActionDeny rejects the clients a rule matches, even if an allow rule
+	// matches them too.
+	ActionDeny Action = "deny"
+)
+
+// Rule allows or denies the clients in a network or a country.
+type Rule struct {
+	id        string
+	network   netip.Prefix
+	country   string
+	action    Action
+	note      string
+	createdAt time.Time
+}
+
+// NewRule creates a rule with a random ID matching either network, an IP
+// address or CIDR range, or country, an ISO 3166 alpha-2 code.
+// Returns ErrInvalidAction, ErrInvalidNetwork, ErrInvalidCountry or
+// ErrInvalidMatch if both or neither of network and country are given.
+func NewRule(network, country string, action Action, note string, now time.Time) (Rule, error) {
+	if action != ActionAllow && action != ActionDeny {
+		return Rule{}, ErrInvalidAction
+	}
+
+	network, country = strings.TrimSpace(network), strings.ToUpper(strings.TrimSpace(country))
+	if (network == "") == (country == "") {
+		return Rule{}, ErrInvalidMatch
+	}
+
+	var prefix netip.Prefix
+	if network != "" {
+		var err error
+		if prefix, err = ParseNetwork(network); err != nil {
+			return Rule{}, err
+		}
+	}
+	if country != "" && !validCountry(country) {
+		return Rule{}, ErrInvalidCountry
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return Rule{}, err
+	}
+
+	return Rule{
+		id:        hex.EncodeToString(idBytes),
+		network:   prefix,
+		country:   country,
+		action:    action,
+		note:      strings.TrimSpace(note),
+		createdAt: now,
+	}, nil
+}
+
+// ReconstructRule rebuilds a Rule from persisted state.
+// This is intended for repository adapters only — it bypasses validation.
+func ReconstructRule(id string, network netip.Prefix, country string, action Action, note string, createdAt time.Time) Rule {
+	return Rule{
+		id:        id,
+		network:   network,
+		country:   country,
+		action:    action,
+		note:      note,
+		createdAt: createdAt,
+	}
+}
+
+// ParseNetwork parses a CIDR range, or a single IP address as the range
+// holding only it. The range is masked to its network address.
+// Returns ErrInvalidNetwork if s is neither.
+func ParseNetwork(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, ErrInvalidNetwork
+		}
+		if prefix.Addr().Is4In6() {
+			bits := prefix.Bits() - 96
+			if bits < 0 {
+				return netip.Prefix{}, ErrInvalidNetwork
+			}
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), bits)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil || addr.Zone() != "" {
+		return netip.Prefix{}, ErrInvalidNetwork
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// validCountry reports whether code is two uppercase ASCII letters.
+func validCountry(code string) bool {
+	return len(code) == 2 && code[0] >= 'A' && code[0] <= 'Z' && code[1] >= 'A' && code[1] <= 'Z'
+}
+
+// ID returns the rule's identifier.
+func (r Rule) ID() string {
+	return r.id
+}
+
+// Network returns the range the rule matches, invalid for a country rule.
+func (r Rule) Network() netip.Prefix {
+	return r.network
+}
+
+// Country returns the country the rule matches, empty for a network rule.
+func (r Rule) Country() string {
+	return r.country
+}
+
+// Action returns what the rule does with the clients it matches.
+func (r Rule) Action() Action {
+	return r.action
+}
+
+// Note returns the rule's free-text description.
+func (r Rule) Note() string {
+	return r.note
+}
+
+// CreatedAt returns when the rule was created.
+func (r Rule) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+// Matches reports whether a client at addr, located in country (empty if
+// unknown), is in the rule's network or country.
+func (r Rule) Matches(addr netip.Addr, country string) bool {
+	if r.network.IsValid() {
+		return addr.IsValid() && r.network.Contains(addr.Unmap())
+	}
+	return country != "" && strings.EqualFold(r.country, country)
+}
+
+// Decision is the outcome of evaluating the rules for a client.
+type Decision struct {
+	Allowed bool
+	// RuleID is the rule that decided, empty if the client matched none.
+	RuleID string
+}
+
+// Evaluate decides whether a client at addr, located in country (empty if
+// unknown), is let through. A matching deny rule rejects the client;
+// otherwise, once any allow rule exists, the client must match one. Without
+// rules every client is allowed. The first matching rule, in the order
+// given, is reported.
+func Evaluate(rules []Rule, addr netip.Addr, country string) Decision {
+	var allow *Rule
+	allowlist := false
+	for i, r := range rules {
+		switch r.action {
+		case ActionDeny:
+			if r.Matches(addr, country) {
+				return Decision{RuleID: r.id}
+			}
+		case ActionAllow:
+			allowlist = true
+			if allow == nil && r.Matches(addr, country) {
+				allow = &rules[i]
+			}
+		}
+	}
+
+	if allow != nil {
+		return Decision{Allowed: true, RuleID: allow.id}
+	}
+	return Decision{Allowed: !allowlist}
+}
+
+// Sort orders rules by creation time, breaking ties by ID.
+func Sort(rules []Rule) {
+	slices.SortStableFunc(rules, func(a, b Rule) int {
+		if c := a.createdAt.Compare(b.createdAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+}
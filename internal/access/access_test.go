@@ -0,0 +1,96 @@
+package access
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestNewRule(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("normalizes the network and country", func(t *testing.T) {
+		r, err := NewRule(" 192.168.1.77/24 ", "", ActionAllow, " LAN ", now)
+		if err != nil {
+			t.Fatalf("NewRule() error = %v", err)
+		}
+		if r.ID() == "" || r.Network().String() != "192.168.1.0/24" || r.Note() != "LAN" || !r.CreatedAt().Equal(now) {
+			t.Errorf("unexpected rule %q %v %q %v", r.ID(), r.Network(), r.Note(), r.CreatedAt())
+		}
+
+		r, err = NewRule("::ffff:10.0.0.1", "", ActionDeny, "", now)
+		if err != nil || r.Network().String() != "10.0.0.1/32" {
+			t.Errorf("expected a single mapped address as a /32, got %v (%v)", r.Network(), err)
+		}
+
+		r, err = NewRule("", "es", ActionAllow, "", now)
+		if err != nil || r.Country() != "ES" || r.Network().IsValid() {
+			t.Errorf("expected a country rule for ES, got %q %v (%v)", r.Country(), r.Network(), err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		network string
+		country string
+		action  Action
+		wantErr error
+	}{
+		{"unknown action", "10.0.0.0/8", "", "block", ErrInvalidAction},
+		{"no match", "", "", ActionDeny, ErrInvalidMatch},
+		{"network and country", "10.0.0.0/8", "ES", ActionDeny, ErrInvalidMatch},
+		{"malformed network", "10.0.0.0/33", "", ActionDeny, ErrInvalidNetwork},
+		{"hostname", "example.com", "", ActionDeny, ErrInvalidNetwork},
+		{"long country", "", "ESP", ActionDeny, ErrInvalidCountry},
+		{"numeric country", "", "34", ActionDeny, ErrInvalidCountry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRule(tt.network, tt.country, tt.action, "", now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewRule() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	rule := func(network, country string, action Action) Rule {
+		t.Helper()
+		r, err := NewRule(network, country, action, "", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	lan := rule("192.168.0.0/16", "", ActionAllow)
+	spain := rule("", "ES", ActionAllow)
+	guest := rule("192.168.50.0/24", "", ActionDeny)
+	v6 := rule("2001:db8::/32", "", ActionDeny)
+
+	tests := []struct {
+		name    string
+		rules   []Rule
+		addr    string
+		country string
+		want    Decision
+	}{
+		{"no rules allow everyone", nil, "203.0.113.7", "", Decision{Allowed: true}},
+		{"deny rules only deny who they match", []Rule{guest}, "203.0.113.7", "", Decision{Allowed: true}},
+		{"matching deny rule", []Rule{guest}, "192.168.50.3", "", Decision{RuleID: guest.ID()}},
+		{"matching allow rule", []Rule{lan, spain}, "192.168.1.3", "", Decision{Allowed: true, RuleID: lan.ID()}},
+		{"matching allow country", []Rule{lan, spain}, "203.0.113.7", "ES", Decision{Allowed: true, RuleID: spain.ID()}},
+		{"outside the allowlist", []Rule{lan, spain}, "203.0.113.7", "FR", Decision{}},
+		{"deny wins over allow", []Rule{lan, guest}, "192.168.50.3", "", Decision{RuleID: guest.ID()}},
+		{"mapped ipv4 address", []Rule{lan}, "::ffff:192.168.1.3", "", Decision{Allowed: true, RuleID: lan.ID()}},
+		{"ipv6 address", []Rule{v6}, "2001:db8::1", "", Decision{RuleID: v6.ID()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Evaluate(tt.rules, netip.MustParseAddr(tt.addr), tt.country); got != tt.want {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package access
+
+import "errors"
+
+// Domain errors for access rule operations.
+var (
+	// Rule validation errors
+	ErrInvalidAction  = errors.New("access rule action must be allow or deny")
+	ErrInvalidNetwork = errors.New("access rule network must be an ip address or a cidr range")
+	ErrInvalidCountry = errors.New("access rule country must be a two-letter iso 3166 code")
+	ErrInvalidMatch   = errors.New("access rules match either a network or a country")
+
+	// Rule operation errors
+	ErrRuleNotFound = errors.New("access rule not found")
+)
@@ -0,0 +1,92 @@
+// Package acesearch reads the JSON output of acestream-search tools, which
+// query the Acestream search API and list the channels it knows of.
+package acesearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/m3u"
+)
+
+// ErrInvalidOutput is returned when the input is not acestream-search JSON.
+var ErrInvalidOutput = errors.New("not acestream-search output")
+
+// item is a search result. Tools print either the flat list of results or
+// the search API's grouping of results by channel, whose items carry the
+// streams.
+type item struct {
+	Name       string   `json:"name"`
+	InfoHash   string   `json:"infohash"`
+	ContentID  string   `json:"content_id"`
+	URL        string   `json:"url"`
+	TVGID      string   `json:"tvg_id"`
+	Categories []string `json:"categories"`
+	Items      []item   `json:"items"`
+}
+
+// Parse reads the results of an acestream-search JSON output as playlist
+// entries, in order. The output may be a list of results, a list of
+// channels grouping their results under items, or an object holding either
+// under results. Results are named after their channel when grouped, and
+// their stream is taken from infohash, content_id or url, in that order.
+// Returns ErrInvalidOutput if r does not hold such a list.
+func Parse(r io.Reader) ([]m3u.Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading acestream-search output: %w", err)
+	}
+
+	var items []item
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var wrapper struct {
+			Results []item `json:"results"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapper); err != nil || wrapper.Results == nil {
+			return nil, ErrInvalidOutput
+		}
+		items = wrapper.Results
+	} else if err := json.Unmarshal(trimmed, &items); err != nil {
+		return nil, ErrInvalidOutput
+	}
+
+	var entries []m3u.Entry
+	for _, it := range items {
+		if len(it.Items) == 0 {
+			entries = append(entries, it.entry(""))
+			continue
+		}
+		for _, child := range it.Items {
+			entries = append(entries, child.entry(it.Name))
+		}
+	}
+	return entries, nil
+}
+
+// entry converts a result into a playlist entry, named after channel unless
+// it is blank.
+func (it item) entry(channel string) m3u.Entry {
+	e := m3u.Entry{
+		Name:  strings.TrimSpace(channel),
+		TVGID: strings.TrimSpace(it.TVGID),
+	}
+	if e.Name == "" {
+		e.Name = strings.TrimSpace(it.Name)
+	}
+	if len(it.Categories) > 0 {
+		e.GroupTitle = it.Categories[0]
+	}
+	switch {
+	case it.InfoHash != "":
+		e.URL = it.InfoHash
+	case it.ContentID != "":
+		e.URL = it.ContentID
+	default:
+		e.URL = it.URL
+	}
+	return e
+}
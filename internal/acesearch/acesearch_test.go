@@ -0,0 +1,54 @@
+package acesearch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/m3u"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("reads flat results", func(t *testing.T) {
+		input := `[
+			{"name":"HBO","infohash":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA","categories":["movies"],"availability":1},
+			{"name":"Sports","url":"acestream://bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}
+		]`
+		entries, err := Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(entries))
+		}
+		want := m3u.Entry{Name: "HBO", GroupTitle: "movies", URL: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}
+		if entries[0] != want {
+			t.Errorf("entries[0] = %+v, want %+v", entries[0], want)
+		}
+		if entries[0].InfoHash() != strings.Repeat("a", 40) || entries[1].InfoHash() != strings.Repeat("b", 40) {
+			t.Errorf("unexpected infohashes %q and %q", entries[0].InfoHash(), entries[1].InfoHash())
+		}
+	})
+
+	t.Run("names grouped results after their channel", func(t *testing.T) {
+		input := `{"results":[{"name":"HBO","items":[
+			{"name":"HBO HD [ES]","infohash":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"},
+			{"name":"HBO SD","content_id":"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"}
+		]}]}`
+		entries, err := Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if len(entries) != 2 || entries[0].Name != "HBO" || entries[1].Name != "HBO" || entries[1].InfoHash() != strings.Repeat("b", 40) {
+			t.Errorf("unexpected entries %+v", entries)
+		}
+	})
+
+	t.Run("rejects other input", func(t *testing.T) {
+		for _, input := range []string{"#EXTM3U", `{"entries":[]}`, `"HBO"`} {
+			if _, err := Parse(strings.NewReader(input)); !errors.Is(err, ErrInvalidOutput) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidOutput", input, err)
+			}
+		}
+	})
+}
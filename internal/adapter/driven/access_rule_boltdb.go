@@ -0,0 +1,146 @@
+package driven
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/access"
+)
+
+const accessRulesBucket = "access_rules"
+
+// AccessRuleBoltDBRepository implements the AccessRuleRepository port using
+// BoltDB.
+type AccessRuleBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewAccessRuleBoltDBRepository creates a new BoltDB-backed access rule
+// repository. It initializes the required bucket if it doesn't exist.
+func NewAccessRuleBoltDBRepository(db *bbolt.DB) (*AccessRuleBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(accessRulesBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccessRuleBoltDBRepository{db: db}, nil
+}
+
+// accessRuleDTO is used for JSON serialization.
+type accessRuleDTO struct {
+	ID        string        `json:"id"`
+	Network   string        `json:"network,omitempty"`
+	Country   string        `json:"country,omitempty"`
+	Action    access.Action `json:"action"`
+	Note      string        `json:"note,omitempty"`
+	CreatedAt int64         `json:"created_at"`
+}
+
+func (d accessRuleDTO) toDomain() (access.Rule, error) {
+	var network netip.Prefix
+	if d.Network != "" {
+		var err error
+		if network, err = netip.ParsePrefix(d.Network); err != nil {
+			return access.Rule{}, err
+		}
+	}
+	return access.ReconstructRule(d.ID, network, d.Country, d.Action, d.Note, time.Unix(0, d.CreatedAt)), nil
+}
+
+// Save persists a new access rule to BoltDB.
+func (r *AccessRuleBoltDBRepository) Save(ctx context.Context, rule access.Rule) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(accessRulesBucket))
+		if bucket == nil {
+			return errors.New("access rules bucket not found")
+		}
+
+		dto := accessRuleDTO{
+			ID:        rule.ID(),
+			Country:   rule.Country(),
+			Action:    rule.Action(),
+			Note:      rule.Note(),
+			CreatedAt: rule.CreatedAt().UnixNano(),
+		}
+		if rule.Network().IsValid() {
+			dto.Network = rule.Network().String()
+		}
+		data, err := json.Marshal(dto)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(rule.ID()), data)
+	})
+}
+
+// FindAll retrieves all access rules from BoltDB, oldest first.
+func (r *AccessRuleBoltDBRepository) FindAll(ctx context.Context) ([]access.Rule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rules := []access.Rule{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(accessRulesBucket))
+		if bucket == nil {
+			return errors.New("access rules bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var dto accessRuleDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			rule, err := dto.toDomain()
+			if err != nil {
+				return err
+			}
+			rules = append(rules, rule)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	access.Sort(rules)
+	return rules, nil
+}
+
+// Delete removes an access rule by its ID from BoltDB.
+func (r *AccessRuleBoltDBRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(accessRulesBucket))
+		if bucket == nil {
+			return errors.New("access rules bucket not found")
+		}
+
+		key := []byte(id)
+		if bucket.Get(key) == nil {
+			return access.ErrRuleNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
@@ -0,0 +1,67 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/access"
+)
+
+func TestNewAccessRuleBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewAccessRuleBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestAccessRuleBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	repo, err := NewAccessRuleBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	later, _ := access.NewRule("", "ES", access.ActionAllow, "", now.Add(time.Minute))
+	first, _ := access.NewRule("192.168.0.0/16", "", access.ActionDeny, "guest wifi", now)
+	for _, r := range []access.Rule{later, first} {
+		if err := repo.Save(ctx, r); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	all, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].ID() != first.ID() || all[1].ID() != later.ID() {
+		t.Fatalf("FindAll() returned %d rules in the wrong order", len(all))
+	}
+	if got := all[0]; got.Network() != first.Network() || got.Country() != "" || got.Action() != access.ActionDeny ||
+		got.Note() != "guest wifi" || !got.CreatedAt().Equal(now) {
+		t.Errorf("FindAll() returned %+v, want %+v", got, first)
+	}
+	if got := all[1]; got.Network().IsValid() || got.Country() != "ES" {
+		t.Errorf("FindAll() returned %+v, want %+v", got, later)
+	}
+
+	if err := repo.Delete(ctx, first.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(ctx, first.ID()); !errors.Is(err, access.ErrRuleNotFound) {
+		t.Errorf("Delete() error = %v, want ErrRuleNotFound", err)
+	}
+	if all, _ := repo.FindAll(ctx); len(all) != 1 {
+		t.Errorf("expected 1 rule left, got %d", len(all))
+	}
+}
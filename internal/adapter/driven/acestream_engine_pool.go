@@ -0,0 +1,397 @@
+package driven
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/streaming"
+)
+
+// EngineBalancing selects which engine of a pool starts a new stream.
+type EngineBalancing string
+
+const (
+	// BalanceLeastStreams starts new streams on the engine serving the fewest.
+	BalanceLeastStreams EngineBalancing = "least-streams"
+	// BalanceRoundRobin starts new streams on each engine in turn.
+	BalanceRoundRobin EngineBalancing = "round-robin"
+)
+
+// ParseEngineBalancing returns the balancing strategy with the given name.
+func ParseEngineBalancing(name string) (EngineBalancing, error) {
+	switch b := EngineBalancing(name); b {
+	case BalanceLeastStreams, BalanceRoundRobin:
+		return b, nil
+	}
+	return "", fmt.Errorf("unknown engine balancing %q", name)
+}
+
+// PoolEngine is a member of an AceStreamEnginePool. Name identifies it in
+// logs, typically by its URL.
+type PoolEngine struct {
+	Name   string
+	Engine driven.AceStreamEngine
+}
+
+// poolMember tracks the health and load of one engine in the pool.
+type poolMember struct {
+	name    string
+	engine  driven.AceStreamEngine
+	healthy bool
+	streams int
+}
+
+// poolAssignment records which engine serves a started stream.
+type poolAssignment struct {
+	member    *poolMember
+	streamURL string
+}
+
+// AceStreamEnginePool implements the AceStreamEngine port by spreading new
+// streams across several engines. Engines that fail a health check are
+// skipped when starting streams until a later check finds them healthy again;
+// they are only tried as a last resort when no engine is healthy.
+//
+// Ping checks every engine, so running it periodically keeps the health of
+// each engine current. An engine is also checked as soon as a stream start on
+// it fails or a stream from it breaks off. If the engine turns out to be down,
+// the start is retried on the next engine, and a broken stream is restarted
+// elsewhere when the caller reconnects.
+type AceStreamEnginePool struct {
+	mu          sync.Mutex
+	members     []*poolMember
+	balancing   EngineBalancing
+	next        int                       // round-robin cursor
+	assignments map[string]poolAssignment // PID → engine serving it
+	logger      *slog.Logger
+}
+
+// NewAceStreamEnginePool creates a pool of the given engines, all initially
+// considered healthy. An unknown balancing strategy falls back to
+// BalanceLeastStreams.
+func NewAceStreamEnginePool(engines []PoolEngine, balancing EngineBalancing, logger *slog.Logger) (*AceStreamEnginePool, error) {
+	if len(engines) == 0 {
+		return nil, errors.New("engine pool needs at least one engine")
+	}
+	if balancing != BalanceRoundRobin {
+		balancing = BalanceLeastStreams
+	}
+
+	members := make([]*poolMember, len(engines))
+	for i, e := range engines {
+		members[i] = &poolMember{name: e.Name, engine: e.Engine, healthy: true}
+	}
+
+	return &AceStreamEnginePool{
+		members:     members,
+		balancing:   balancing,
+		assignments: make(map[string]poolAssignment),
+		logger:      logger,
+	}, nil
+}
+
+// StartStream starts the stream on the engine chosen by the balancing
+// strategy. If that engine fails and a health check finds it down, the next
+// engine is tried. A failure from an engine that is still up is returned
+// as is, since another engine is unlikely to fare better with the same
+// content.
+func (p *AceStreamEnginePool) StartStream(ctx context.Context, infoHash, pid string, opts driven.StreamOptions) (string, error) {
+	var errs []error
+	for _, m := range p.candidates() {
+		streamURL, err := m.engine.StartStream(ctx, infoHash, pid, opts)
+		if err == nil {
+			p.assign(pid, m, streamURL)
+			p.logger.DebugContext(ctx, "stream assigned to engine", "engine", m.name, "infohash", infoHash, "pid", pid)
+			return streamURL, nil
+		}
+		if ctx.Err() != nil || p.check(ctx, m, err) {
+			return "", err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+	}
+
+	if len(errs) == 1 {
+		return "", errors.Unwrap(errs[0])
+	}
+	return "", fmt.Errorf("all engines failed: %w", errors.Join(errs...))
+}
+
+// GetStats retrieves statistics from the engine serving the PID.
+func (p *AceStreamEnginePool) GetStats(ctx context.Context, pid string) (driven.StreamStats, error) {
+	return p.memberFor(pid).engine.GetStats(ctx, pid)
+}
+
+// StopStream stops the stream on the engine serving the PID and releases
+// the engine's slot, even if the engine cannot be reached.
+func (p *AceStreamEnginePool) StopStream(ctx context.Context, pid string) error {
+	m := p.memberFor(pid)
+	p.release(pid)
+	return m.engine.StopStream(ctx, pid)
+}
+
+// poolSessionHandle is the JSON form of a handle handed out by
+// SessionHandle: the engine serving the stream and its own handle.
+type poolSessionHandle struct {
+	Engine string `json:"engine"`
+	Handle string `json:"handle"`
+}
+
+// SessionHandle returns a handle naming the engine serving pid along with
+// that engine's handle, if it hands them out.
+func (p *AceStreamEnginePool) SessionHandle(pid string) (string, bool) {
+	p.mu.Lock()
+	a, ok := p.assignments[pid]
+	p.mu.Unlock()
+	if !ok {
+		return "", false
+	}
+	restorer, ok := a.member.engine.(driven.AceStreamSessionRestorer)
+	if !ok {
+		return "", false
+	}
+	handle, ok := restorer.SessionHandle(pid)
+	if !ok {
+		return "", false
+	}
+
+	data, err := json.Marshal(poolSessionHandle{Engine: a.member.name, Handle: handle})
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// RestoreSession restores the stream of handle on the engine it names and
+// assigns pid to that engine.
+func (p *AceStreamEnginePool) RestoreSession(pid, handle string) error {
+	var h poolSessionHandle
+	if err := json.Unmarshal([]byte(handle), &h); err != nil {
+		return fmt.Errorf("invalid engine session handle: %w", err)
+	}
+
+	for _, m := range p.members {
+		if m.name != h.Engine {
+			continue
+		}
+		restorer, ok := m.engine.(driven.AceStreamSessionRestorer)
+		if !ok {
+			return fmt.Errorf("engine %s cannot restore sessions", m.name)
+		}
+		if err := restorer.RestoreSession(pid, h.Handle); err != nil {
+			return err
+		}
+		p.assign(pid, m, "")
+		return nil
+	}
+	return fmt.Errorf("engine %s is not in the pool", h.Engine)
+}
+
+// StreamContent copies the stream from the engine that started it. If the
+// stream breaks off, the engine is health checked so that a restart of the
+// stream avoids it if it has died.
+func (p *AceStreamEnginePool) StreamContent(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+	m := p.memberForStream(pid, streamURL)
+	err := m.engine.StreamContent(ctx, streamURL, dst, infoHash, pid, writeTimeout)
+	if err != nil && ctx.Err() == nil && !p.check(ctx, m, err) {
+		p.logger.WarnContext(ctx, "engine died mid-stream, stream will restart on another engine",
+			"engine", m.name,
+			"infohash", infoHash,
+			"pid", pid)
+	}
+	return err
+}
+
+// StreamContentFrom resumes a stream from offset on the engine that started
+// it. Returns streaming.ErrRangeNotSupported if that engine cannot.
+func (p *AceStreamEnginePool) StreamContentFrom(ctx context.Context, streamURL string, offset int64, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+	m := p.memberForStream(pid, streamURL)
+	ranged, ok := m.engine.(driven.AceStreamRangeStreamer)
+	if !ok {
+		return streaming.ErrRangeNotSupported
+	}
+	err := ranged.StreamContentFrom(ctx, streamURL, offset, dst, infoHash, pid, writeTimeout)
+	if err != nil && ctx.Err() == nil && !errors.Is(err, streaming.ErrRangeNotSupported) && !p.check(ctx, m, err) {
+		p.logger.WarnContext(ctx, "engine died mid-stream, stream will restart on another engine",
+			"engine", m.name,
+			"infohash", infoHash,
+			"pid", pid)
+	}
+	return err
+}
+
+// Ping checks the health of every engine in the pool. It returns nil if at
+// least one engine is healthy.
+func (p *AceStreamEnginePool) Ping(ctx context.Context) error {
+	p.mu.Lock()
+	members := slices.Clone(p.members)
+	p.mu.Unlock()
+
+	var errs []error
+	for _, m := range members {
+		err := m.engine.Ping(ctx)
+		p.setHealth(ctx, m, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+		}
+	}
+
+	switch {
+	case len(errs) < len(members):
+		return nil
+	case len(errs) == 1:
+		return errors.Unwrap(errs[0])
+	default:
+		return fmt.Errorf("no healthy engine: %w", errors.Join(errs...))
+	}
+}
+
+// Search runs the query on the first healthy engine that supports search.
+func (p *AceStreamEnginePool) Search(ctx context.Context, query string) ([]driven.SearchResult, error) {
+	for _, m := range p.candidates() {
+		if searcher, ok := m.engine.(driven.AceStreamSearcher); ok {
+			return searcher.Search(ctx, query)
+		}
+	}
+	return nil, errors.New("no engine supports search")
+}
+
+// RunCommand runs the command on every engine of the pool that supports
+// commands, named as in the pool. An engine that fails has its error
+// reported in its result, so one engine being down does not hide the others.
+func (p *AceStreamEnginePool) RunCommand(ctx context.Context, command driven.EngineCommand) ([]driven.EngineCommandResult, error) {
+	p.mu.Lock()
+	members := slices.Clone(p.members)
+	p.mu.Unlock()
+
+	var results []driven.EngineCommandResult
+	for _, m := range members {
+		commander, ok := m.engine.(driven.AceStreamCommander)
+		if !ok {
+			continue
+		}
+		engineResults, err := commander.RunCommand(ctx, command)
+		if err != nil {
+			results = append(results, driven.EngineCommandResult{Engine: m.name, Error: err.Error()})
+			continue
+		}
+		for _, r := range engineResults {
+			r.Engine = m.name
+			results = append(results, r)
+		}
+	}
+	if results == nil {
+		return nil, errors.New("no engine supports commands")
+	}
+	return results, nil
+}
+
+// candidates returns the engines in the order new streams should try them:
+// healthy engines by the balancing strategy, then unhealthy ones.
+func (p *AceStreamEnginePool) candidates() []*poolMember {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ordered := make([]*poolMember, 0, len(p.members))
+	if p.balancing == BalanceRoundRobin {
+		for i := range p.members {
+			ordered = append(ordered, p.members[(p.next+i)%len(p.members)])
+		}
+		p.next = (p.next + 1) % len(p.members)
+	} else {
+		ordered = append(ordered, p.members...)
+		slices.SortStableFunc(ordered, func(a, b *poolMember) int {
+			return a.streams - b.streams
+		})
+	}
+
+	slices.SortStableFunc(ordered, func(a, b *poolMember) int {
+		switch {
+		case a.healthy == b.healthy:
+			return 0
+		case a.healthy:
+			return -1
+		default:
+			return 1
+		}
+	})
+	return ordered
+}
+
+// check pings an engine after cause made it suspect and records the result.
+// Returns whether the engine is healthy.
+func (p *AceStreamEnginePool) check(ctx context.Context, m *poolMember, cause error) bool {
+	ctx = context.WithoutCancel(ctx)
+	err := m.engine.Ping(ctx)
+	if err != nil {
+		err = fmt.Errorf("%w (after: %v)", err, cause)
+	}
+	p.setHealth(ctx, m, err)
+	return err == nil
+}
+
+// setHealth records the result of a health check, logging changes.
+func (p *AceStreamEnginePool) setHealth(ctx context.Context, m *poolMember, err error) {
+	p.mu.Lock()
+	was := m.healthy
+	m.healthy = err == nil
+	p.mu.Unlock()
+
+	switch {
+	case was && err != nil:
+		p.logger.WarnContext(ctx, "engine unhealthy, excluding it from new streams", "engine", m.name, "error", err)
+	case !was && err == nil:
+		p.logger.InfoContext(ctx, "engine healthy again", "engine", m.name)
+	}
+}
+
+func (p *AceStreamEnginePool) assign(pid string, m *poolMember, streamURL string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if old, ok := p.assignments[pid]; ok {
+		old.member.streams--
+	}
+	p.assignments[pid] = poolAssignment{member: m, streamURL: streamURL}
+	m.streams++
+}
+
+func (p *AceStreamEnginePool) release(pid string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if a, ok := p.assignments[pid]; ok {
+		a.member.streams--
+		delete(p.assignments, pid)
+	}
+}
+
+// memberFor returns the engine serving pid, or the first engine if the pool
+// did not start it.
+func (p *AceStreamEnginePool) memberFor(pid string) *poolMember {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if a, ok := p.assignments[pid]; ok {
+		return a.member
+	}
+	return p.members[0]
+}
+
+// memberForStream returns the engine that handed out streamURL, falling back
+// to the engine serving pid.
+func (p *AceStreamEnginePool) memberForStream(pid, streamURL string) *poolMember {
+	p.mu.Lock()
+	for _, a := range p.assignments {
+		if a.streamURL == streamURL {
+			p.mu.Unlock()
+			return a.member
+		}
+	}
+	p.mu.Unlock()
+	return p.memberFor(pid)
+}
@@ -0,0 +1,306 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// fakePoolEngine is an engine whose health can be toggled.
+type fakePoolEngine struct {
+	name string
+
+	mu        sync.Mutex
+	down      bool
+	started   []string
+	stopped   []string
+	restored  map[string]string
+	streamErr error
+}
+
+func (e *fakePoolEngine) setDown(down bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.down = down
+}
+
+func (e *fakePoolEngine) StartStream(ctx context.Context, infoHash, pid string, opts driven.StreamOptions) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.down {
+		return "", errors.New("connection refused")
+	}
+	e.started = append(e.started, pid)
+	return "http://" + e.name + "/stream/" + pid, nil
+}
+
+func (e *fakePoolEngine) GetStats(ctx context.Context, pid string) (driven.StreamStats, error) {
+	return driven.StreamStats{PID: pid, Status: e.name}, nil
+}
+
+func (e *fakePoolEngine) StopStream(ctx context.Context, pid string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stopped = append(e.stopped, pid)
+	return nil
+}
+
+func (e *fakePoolEngine) StreamContent(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.streamErr
+}
+
+func (e *fakePoolEngine) Ping(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.down {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (e *fakePoolEngine) RunCommand(ctx context.Context, command driven.EngineCommand) ([]driven.EngineCommandResult, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.down {
+		return nil, errors.New("connection refused")
+	}
+	return []driven.EngineCommandResult{{Engine: "http://" + e.name, Result: string(command)}}, nil
+}
+
+func (e *fakePoolEngine) SessionHandle(pid string) (string, bool) {
+	return "handle-" + pid, true
+}
+
+func (e *fakePoolEngine) RestoreSession(pid, handle string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.restored == nil {
+		e.restored = make(map[string]string)
+	}
+	e.restored[pid] = handle
+	return nil
+}
+
+func newTestPool(t *testing.T, balancing EngineBalancing, engines ...*fakePoolEngine) *AceStreamEnginePool {
+	t.Helper()
+	members := make([]PoolEngine, len(engines))
+	for i, e := range engines {
+		members[i] = PoolEngine{Name: e.name, Engine: e}
+	}
+	pool, err := NewAceStreamEnginePool(members, balancing, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewAceStreamEnginePool() error = %v", err)
+	}
+	return pool
+}
+
+func TestAceStreamEnginePool_Balancing(t *testing.T) {
+	t.Run("least-streams starts streams on the least loaded engine", func(t *testing.T) {
+		a, b := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+		pool := newTestPool(t, BalanceLeastStreams, a, b)
+		ctx := context.Background()
+
+		for _, pid := range []string{"p1", "p2", "p3"} {
+			if _, err := pool.StartStream(ctx, "hash", pid, driven.StreamOptions{}); err != nil {
+				t.Fatalf("StartStream(%s) error = %v", pid, err)
+			}
+		}
+		if len(a.started) != 2 || len(b.started) != 1 {
+			t.Fatalf("expected 2 streams on a and 1 on b, got %v and %v", a.started, b.started)
+		}
+
+		if err := pool.StopStream(ctx, "p1"); err != nil {
+			t.Fatalf("StopStream error = %v", err)
+		}
+		if _, err := pool.StartStream(ctx, "hash", "p4", driven.StreamOptions{}); err != nil {
+			t.Fatalf("StartStream(p4) error = %v", err)
+		}
+		if len(a.started) != 3 {
+			t.Errorf("expected p4 on a after p1 stopped, got a=%v b=%v", a.started, b.started)
+		}
+	})
+
+	t.Run("round-robin starts streams on each engine in turn", func(t *testing.T) {
+		a, b := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+		pool := newTestPool(t, BalanceRoundRobin, a, b)
+
+		for _, pid := range []string{"p1", "p2", "p3", "p4"} {
+			if _, err := pool.StartStream(context.Background(), "hash", pid, driven.StreamOptions{}); err != nil {
+				t.Fatalf("StartStream(%s) error = %v", pid, err)
+			}
+		}
+		if len(a.started) != 2 || len(b.started) != 2 || a.started[0] != "p1" || b.started[0] != "p2" {
+			t.Errorf("expected alternating engines, got a=%v b=%v", a.started, b.started)
+		}
+	})
+}
+
+func TestAceStreamEnginePool_Routing(t *testing.T) {
+	a, b := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+	pool := newTestPool(t, BalanceRoundRobin, a, b)
+	ctx := context.Background()
+
+	_, _ = pool.StartStream(ctx, "hash", "p1", driven.StreamOptions{})
+	_, _ = pool.StartStream(ctx, "hash", "p2", driven.StreamOptions{})
+
+	stats, err := pool.GetStats(ctx, "p2")
+	if err != nil || stats.Status != "b" {
+		t.Errorf("expected stats of p2 from b, got %+v, %v", stats, err)
+	}
+
+	if err := pool.StopStream(ctx, "p2"); err != nil {
+		t.Fatalf("StopStream error = %v", err)
+	}
+	if len(b.stopped) != 1 || len(a.stopped) != 0 {
+		t.Errorf("expected p2 stopped on b, got a=%v b=%v", a.stopped, b.stopped)
+	}
+}
+
+func TestAceStreamEnginePool_Failover(t *testing.T) {
+	t.Run("start fails over to the next engine when one is down", func(t *testing.T) {
+		a, b := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+		a.setDown(true)
+		pool := newTestPool(t, BalanceLeastStreams, a, b)
+
+		streamURL, err := pool.StartStream(context.Background(), "hash", "p1", driven.StreamOptions{})
+		if err != nil {
+			t.Fatalf("StartStream error = %v", err)
+		}
+		if streamURL != "http://b/stream/p1" {
+			t.Errorf("expected stream from b, got %q", streamURL)
+		}
+
+		// a is now known to be down and is no longer tried first
+		if _, err := pool.StartStream(context.Background(), "hash", "p2", driven.StreamOptions{}); err != nil {
+			t.Fatalf("StartStream error = %v", err)
+		}
+		if len(b.started) != 2 {
+			t.Errorf("expected both streams on b, got %v", b.started)
+		}
+	})
+
+	t.Run("start fails when every engine is down", func(t *testing.T) {
+		a, b := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+		a.setDown(true)
+		b.setDown(true)
+		pool := newTestPool(t, BalanceLeastStreams, a, b)
+
+		if _, err := pool.StartStream(context.Background(), "hash", "p1", driven.StreamOptions{}); err == nil {
+			t.Fatal("expected error when all engines are down")
+		}
+	})
+
+	t.Run("restart after an engine dies mid-stream uses another engine", func(t *testing.T) {
+		a, b := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+		pool := newTestPool(t, BalanceLeastStreams, a, b)
+		ctx := context.Background()
+
+		streamURL, _ := pool.StartStream(ctx, "hash", "p1", driven.StreamOptions{})
+		a.setDown(true)
+		a.streamErr = errors.New("connection reset")
+
+		if err := pool.StreamContent(ctx, streamURL, io.Discard, "hash", "p1", time.Second); err == nil {
+			t.Fatal("expected the broken stream's error")
+		}
+
+		_ = pool.StopStream(ctx, "p1")
+		streamURL, err := pool.StartStream(ctx, "hash", "p1", driven.StreamOptions{})
+		if err != nil {
+			t.Fatalf("restart error = %v", err)
+		}
+		if streamURL != "http://b/stream/p1" {
+			t.Errorf("expected restart on b, got %q", streamURL)
+		}
+	})
+}
+
+func TestAceStreamEnginePool_Ping(t *testing.T) {
+	a, b := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+	pool := newTestPool(t, BalanceLeastStreams, a, b)
+	ctx := context.Background()
+
+	a.setDown(true)
+	if err := pool.Ping(ctx); err != nil {
+		t.Fatalf("expected healthy pool with one engine up, got %v", err)
+	}
+	if _, err := pool.StartStream(ctx, "hash", "p1", driven.StreamOptions{}); err != nil {
+		t.Fatalf("StartStream error = %v", err)
+	}
+	if len(b.started) != 1 || len(a.started) != 0 {
+		t.Errorf("expected new stream on the healthy engine, got a=%v b=%v", a.started, b.started)
+	}
+
+	b.setDown(true)
+	if err := pool.Ping(ctx); err == nil {
+		t.Error("expected error when no engine is healthy")
+	}
+
+	a.setDown(false)
+	if err := pool.Ping(ctx); err != nil {
+		t.Fatalf("expected pool healthy after a recovered, got %v", err)
+	}
+	if _, err := pool.StartStream(ctx, "hash", "p2", driven.StreamOptions{}); err != nil || len(a.started) != 1 {
+		t.Errorf("expected recovered engine to take new streams, got a=%v err=%v", a.started, err)
+	}
+}
+
+func TestAceStreamEnginePool_RunCommand(t *testing.T) {
+	a, b := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+	pool := newTestPool(t, BalanceLeastStreams, a, b)
+	b.setDown(true)
+
+	results, err := pool.RunCommand(context.Background(), driven.EngineCommandVersion)
+	if err != nil {
+		t.Fatalf("RunCommand() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected a result per engine, got %+v", results)
+	}
+	if results[0].Engine != "a" || results[0].Result != "get_version" || results[0].Error != "" {
+		t.Errorf("unexpected result for a: %+v", results[0])
+	}
+	if results[1].Engine != "b" || results[1].Error == "" {
+		t.Errorf("expected the error of b in its result, got %+v", results[1])
+	}
+}
+
+func TestAceStreamEnginePool_RestoreSession(t *testing.T) {
+	a, b := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+	pool := newTestPool(t, BalanceRoundRobin, a, b)
+	ctx := context.Background()
+
+	_, _ = pool.StartStream(ctx, "hash", "p1", driven.StreamOptions{})
+	_, _ = pool.StartStream(ctx, "hash", "p2", driven.StreamOptions{})
+	handle, ok := pool.SessionHandle("p2")
+	if !ok {
+		t.Fatal("expected a handle for p2")
+	}
+	if _, ok := pool.SessionHandle("p3"); ok {
+		t.Error("expected no handle for an unknown PID")
+	}
+
+	// A new pool, as after a restart, routes the restored PID to its engine
+	a2, b2 := &fakePoolEngine{name: "a"}, &fakePoolEngine{name: "b"}
+	restored := newTestPool(t, BalanceRoundRobin, a2, b2)
+	if err := restored.RestoreSession("p2", handle); err != nil {
+		t.Fatalf("RestoreSession() error = %v", err)
+	}
+	if b2.restored["p2"] != "handle-p2" || len(a2.restored) != 0 {
+		t.Errorf("expected p2 restored on b, got a=%v b=%v", a2.restored, b2.restored)
+	}
+	if err := restored.StopStream(ctx, "p2"); err != nil || len(b2.stopped) != 1 {
+		t.Errorf("expected p2 stopped on b, got %v (%v)", b2.stopped, err)
+	}
+
+	if err := restored.RestoreSession("p4", `{"engine":"c","handle":"x"}`); err == nil {
+		t.Error("expected an error for an engine not in the pool")
+	}
+}
@@ -10,9 +10,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/alorle/iptv-manager/internal/channel"
 	"github.com/alorle/iptv-manager/internal/port/driven"
 	"github.com/alorle/iptv-manager/internal/streaming"
 )
@@ -30,18 +33,44 @@ const (
 	defaultGetStatsTimeout    = 5 * time.Second
 	defaultStopStreamTimeout  = 5 * time.Second
 	defaultPingTimeout        = 5 * time.Second
+	defaultSearchTimeout      = 15 * time.Second
+	defaultCommandTimeout     = 30 * time.Second // Clearing the cache may take a while
 )
 
+// engineCommandMethods is the allowlist of web UI API methods RunCommand
+// may call, by command.
+var engineCommandMethods = map[driven.EngineCommand]string{
+	driven.EngineCommandVersion:       "get_version",
+	driven.EngineCommandNetworkStatus: "get_network_connection_status",
+	driven.EngineCommandClearCache:    "clear_cache",
+}
+
+// EngineAuth authenticates the requests to an AceStream engine that requires
+// it, such as one behind an authenticating reverse proxy.
+type EngineAuth struct {
+	// APIKey, if set, is sent as the api_key query parameter.
+	APIKey string
+	// Username and Password, if Username is set, are sent as HTTP basic
+	// auth, replacing any Authorization header.
+	Username string
+	Password string
+	// Headers are added to every request, e.g. Authorization or X-Api-Key.
+	Headers map[string]string
+}
+
 // AceStreamHTTPAdapter implements the AceStreamEngine port using HTTP calls
 // to the AceStream Engine API.
 type AceStreamHTTPAdapter struct {
 	baseURL            string
+	auth               EngineAuth
 	httpClient         *http.Client // For short operations (no timeout set on client)
 	streamHTTPClient   *http.Client // For long-running streams (no timeout)
 	startStreamTimeout time.Duration
 	getStatsTimeout    time.Duration
 	stopStreamTimeout  time.Duration
 	pingTimeout        time.Duration
+	searchTimeout      time.Duration
+	commandTimeout     time.Duration
 	logger             *slog.Logger
 	sessionsMu         sync.RWMutex
 	sessions           map[string]engineSession // PID → session URLs
@@ -98,13 +127,16 @@ func NewAceStreamHTTPAdapter(baseURL string, logger *slog.Logger) *AceStreamHTTP
 		getStatsTimeout:    defaultGetStatsTimeout,
 		stopStreamTimeout:  defaultStopStreamTimeout,
 		pingTimeout:        defaultPingTimeout,
+		searchTimeout:      defaultSearchTimeout,
+		commandTimeout:     defaultCommandTimeout,
 		logger:             logger,
 		sessions:           make(map[string]engineSession),
 	}
 }
 
 // StartStream initiates a stream for the given infohash with a unique PID.
-func (a *AceStreamHTTPAdapter) StartStream(ctx context.Context, infoHash, pid string) (string, error) {
+// Audio transcoding in opts maps to the engine's transcode_* getstream parameters.
+func (a *AceStreamHTTPAdapter) StartStream(ctx context.Context, infoHash, pid string, opts driven.StreamOptions) (string, error) {
 	// Apply operation-specific timeout
 	ctx, cancel := context.WithTimeout(ctx, a.startStreamTimeout)
 	defer cancel()
@@ -114,12 +146,20 @@ func (a *AceStreamHTTPAdapter) StartStream(ctx context.Context, infoHash, pid st
 	params.Set("id", infoHash)
 	params.Set("pid", pid)
 	params.Set("format", "json")
+	switch opts.TranscodeAudio {
+	case channel.AudioTranscodeAll:
+		params.Set("transcode_audio", "1")
+	case channel.AudioTranscodeAC3:
+		params.Set("transcode_ac3", "1")
+	case channel.AudioTranscodeMP3:
+		params.Set("transcode_mp3", "1")
+	}
 
 	reqURL := fmt.Sprintf("%s/ace/getstream?%s", a.baseURL, params.Encode())
 
-	a.logger.Debug("engine request", "method", http.MethodGet, "url", reqURL, "pid", pid, "timeout", a.startStreamTimeout)
+	a.logger.DebugContext(ctx, "engine request", "method", http.MethodGet, "url", reqURL, "pid", pid, "timeout", a.startStreamTimeout)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	req, err := a.newRequest(ctx, reqURL)
 	if err != nil {
 		return "", fmt.Errorf("failed to create start stream request: %w", err)
 	}
@@ -127,16 +167,16 @@ func (a *AceStreamHTTPAdapter) StartStream(ctx context.Context, infoHash, pid st
 	resp, err := a.httpClient.Do(req)
 	if err != nil {
 		if streaming.IsTimeoutError(err) {
-			a.logger.Warn("engine operation timeout", "operation", "StartStream", "url", reqURL, "timeout", a.startStreamTimeout, "error", err)
-			a.logger.Error("stream start failed due to timeout", "infohash", infoHash, "pid", pid, "timeout", a.startStreamTimeout)
+			a.logger.WarnContext(ctx, "engine operation timeout", "operation", "StartStream", "url", reqURL, "timeout", a.startStreamTimeout, "error", err)
+			a.logger.ErrorContext(ctx, "stream start failed due to timeout", "infohash", infoHash, "pid", pid, "timeout", a.startStreamTimeout)
 			return "", fmt.Errorf("start stream timed out after %v: %w", a.startStreamTimeout, err)
 		}
-		a.logger.Warn("engine network error", "operation", "StartStream", "error", err, "url", reqURL)
+		a.logger.WarnContext(ctx, "engine network error", "operation", "StartStream", "error", err, "url", reqURL)
 		return "", fmt.Errorf("failed to start stream: %w", err)
 	}
 	defer resp.Body.Close()
 
-	a.logger.Debug("engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
+	a.logger.DebugContext(ctx, "engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -144,7 +184,10 @@ func (a *AceStreamHTTPAdapter) StartStream(ctx context.Context, infoHash, pid st
 		if len(bodyStr) > 500 {
 			bodyStr = bodyStr[:500]
 		}
-		a.logger.Error("engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
+		a.logger.ErrorContext(ctx, "engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
+		if resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusTooManyRequests {
+			return "", fmt.Errorf("%w: engine returned status %d", driven.ErrEngineBusy, resp.StatusCode)
+		}
 		return "", fmt.Errorf("engine returned status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -155,12 +198,21 @@ func (a *AceStreamHTTPAdapter) StartStream(ctx context.Context, infoHash, pid st
 			StatURL     string `json:"stat_url"`
 			CommandURL  string `json:"command_url"`
 		} `json:"response"`
+		Error string `json:"error"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return "", fmt.Errorf("failed to decode start stream response: %w", err)
 	}
 
+	if result.Error != "" {
+		a.logger.ErrorContext(ctx, "engine start stream error", "infohash", infoHash, "pid", pid, "error", result.Error)
+		if strings.Contains(strings.ToLower(result.Error), "too many") {
+			return "", fmt.Errorf("%w: %s", driven.ErrEngineBusy, result.Error)
+		}
+		return "", fmt.Errorf("engine error: %s", result.Error)
+	}
+
 	if result.Response.PlaybackURL == "" {
 		return "", fmt.Errorf("engine did not return a stream URL")
 	}
@@ -198,9 +250,9 @@ func (a *AceStreamHTTPAdapter) GetStats(ctx context.Context, pid string) (driven
 
 	reqURL := session.statURL
 
-	a.logger.Debug("engine request", "method", http.MethodGet, "url", reqURL, "pid", pid, "timeout", a.getStatsTimeout)
+	a.logger.DebugContext(ctx, "engine request", "method", http.MethodGet, "url", reqURL, "pid", pid, "timeout", a.getStatsTimeout)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	req, err := a.newRequest(ctx, reqURL)
 	if err != nil {
 		return driven.StreamStats{}, fmt.Errorf("failed to create stats request: %w", err)
 	}
@@ -208,15 +260,15 @@ func (a *AceStreamHTTPAdapter) GetStats(ctx context.Context, pid string) (driven
 	resp, err := a.httpClient.Do(req)
 	if err != nil {
 		if streaming.IsTimeoutError(err) {
-			a.logger.Warn("engine operation timeout", "operation", "GetStats", "url", reqURL, "timeout", a.getStatsTimeout, "pid", pid, "error", err)
+			a.logger.WarnContext(ctx, "engine operation timeout", "operation", "GetStats", "url", reqURL, "timeout", a.getStatsTimeout, "pid", pid, "error", err)
 			return driven.StreamStats{}, fmt.Errorf("get stats timed out after %v: %w", a.getStatsTimeout, err)
 		}
-		a.logger.Warn("engine network error", "operation", "GetStats", "error", err, "url", reqURL)
+		a.logger.WarnContext(ctx, "engine network error", "operation", "GetStats", "error", err, "url", reqURL)
 		return driven.StreamStats{}, fmt.Errorf("failed to get stats: %w", err)
 	}
 	defer resp.Body.Close()
 
-	a.logger.Debug("engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
+	a.logger.DebugContext(ctx, "engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -224,7 +276,7 @@ func (a *AceStreamHTTPAdapter) GetStats(ctx context.Context, pid string) (driven
 		if len(bodyStr) > 500 {
 			bodyStr = bodyStr[:500]
 		}
-		a.logger.Error("engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
+		a.logger.ErrorContext(ctx, "engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
 		return driven.StreamStats{}, fmt.Errorf("engine returned status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -267,12 +319,12 @@ func (a *AceStreamHTTPAdapter) StopStream(ctx context.Context, pid string) error
 	a.sessionsMu.Unlock()
 
 	if !ok {
-		a.logger.Warn("no active session to stop", "pid", pid)
+		a.logger.WarnContext(ctx, "no active session to stop", "pid", pid)
 		return nil
 	}
 
 	if session.commandURL == "" {
-		a.logger.Warn("no command URL available, cannot stop stream", "pid", pid)
+		a.logger.WarnContext(ctx, "no command URL available, cannot stop stream", "pid", pid)
 		return nil
 	}
 
@@ -282,9 +334,9 @@ func (a *AceStreamHTTPAdapter) StopStream(ctx context.Context, pid string) error
 
 	reqURL := session.commandURL + "?method=stop"
 
-	a.logger.Debug("engine request", "method", http.MethodGet, "url", reqURL, "pid", pid, "timeout", a.stopStreamTimeout)
+	a.logger.DebugContext(ctx, "engine request", "method", http.MethodGet, "url", reqURL, "pid", pid, "timeout", a.stopStreamTimeout)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	req, err := a.newRequest(ctx, reqURL)
 	if err != nil {
 		return fmt.Errorf("failed to create stop stream request: %w", err)
 	}
@@ -292,15 +344,15 @@ func (a *AceStreamHTTPAdapter) StopStream(ctx context.Context, pid string) error
 	resp, err := a.httpClient.Do(req)
 	if err != nil {
 		if streaming.IsTimeoutError(err) {
-			a.logger.Warn("engine operation timeout", "operation", "StopStream", "url", reqURL, "timeout", a.stopStreamTimeout, "pid", pid, "error", err)
+			a.logger.WarnContext(ctx, "engine operation timeout", "operation", "StopStream", "url", reqURL, "timeout", a.stopStreamTimeout, "pid", pid, "error", err)
 			return fmt.Errorf("stop stream timed out after %v: %w", a.stopStreamTimeout, err)
 		}
-		a.logger.Warn("engine network error", "operation", "StopStream", "error", err, "url", reqURL)
+		a.logger.WarnContext(ctx, "engine network error", "operation", "StopStream", "error", err, "url", reqURL)
 		return fmt.Errorf("failed to stop stream: %w", err)
 	}
 	defer resp.Body.Close()
 
-	a.logger.Debug("engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
+	a.logger.DebugContext(ctx, "engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -308,40 +360,105 @@ func (a *AceStreamHTTPAdapter) StopStream(ctx context.Context, pid string) error
 		if len(bodyStr) > 500 {
 			bodyStr = bodyStr[:500]
 		}
-		a.logger.Error("engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
+		a.logger.ErrorContext(ctx, "engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
 		return fmt.Errorf("engine returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	return nil
 }
 
+// engineSessionHandle is the JSON form of an engineSession handed out by
+// SessionHandle.
+type engineSessionHandle struct {
+	StatURL    string `json:"stat_url"`
+	CommandURL string `json:"command_url"`
+}
+
+// SessionHandle returns the session URLs of the stream started with pid,
+// encoded as a handle for RestoreSession.
+func (a *AceStreamHTTPAdapter) SessionHandle(pid string) (string, bool) {
+	a.sessionsMu.RLock()
+	session, ok := a.sessions[pid]
+	a.sessionsMu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	data, err := json.Marshal(engineSessionHandle{StatURL: session.statURL, CommandURL: session.commandURL})
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// RestoreSession registers the session URLs of handle under pid, as if the
+// stream had been started with StartStream.
+func (a *AceStreamHTTPAdapter) RestoreSession(pid, handle string) error {
+	var h engineSessionHandle
+	if err := json.Unmarshal([]byte(handle), &h); err != nil {
+		return fmt.Errorf("invalid engine session handle: %w", err)
+	}
+
+	a.sessionsMu.Lock()
+	a.sessions[pid] = engineSession{statURL: h.StatURL, commandURL: h.CommandURL}
+	a.sessionsMu.Unlock()
+	return nil
+}
+
 // StreamContent establishes a streaming connection and copies the stream data
 // to the provided writer.
 func (a *AceStreamHTTPAdapter) StreamContent(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
-	a.logger.Debug("starting content stream", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "write_timeout", writeTimeout)
+	return a.streamContent(ctx, streamURL, 0, dst, infoHash, pid, writeTimeout)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+// StreamContentFrom resumes a content stream from offset with a Range
+// request. The engine answering with anything but the requested range
+// returns streaming.ErrRangeNotSupported, the response left unread.
+func (a *AceStreamHTTPAdapter) StreamContentFrom(ctx context.Context, streamURL string, offset int64, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+	return a.streamContent(ctx, streamURL, offset, dst, infoHash, pid, writeTimeout)
+}
+
+// streamContent copies the stream data to dst, from offset on if it is
+// positive.
+func (a *AceStreamHTTPAdapter) streamContent(ctx context.Context, streamURL string, offset int64, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+	a.logger.DebugContext(ctx, "starting content stream", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "write_timeout", writeTimeout, "offset", offset)
+
+	req, err := a.newRequest(ctx, streamURL)
 	if err != nil {
 		return fmt.Errorf("failed to create stream content request: %w", err)
 	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
 
 	// Use the stream-specific HTTP client (no timeout - controlled by context and write timeouts)
 	resp, err := a.streamHTTPClient.Do(req)
 	if err != nil {
-		a.logger.Warn("engine network error", "operation", "StreamContent", "error", err, "url", streamURL)
+		a.logger.WarnContext(ctx, "engine network error", "operation", "StreamContent", "error", err, "url", streamURL)
 		return fmt.Errorf("failed to connect to stream: %w", err)
 	}
 	defer resp.Body.Close()
 
-	a.logger.Debug("engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
+	a.logger.DebugContext(ctx, "engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
 
-	if resp.StatusCode != http.StatusOK {
+	// A resumed stream must carry on exactly where the last one stopped;
+	// content from anywhere else would garble the stream clients receive
+	if offset > 0 {
+		switch {
+		case resp.StatusCode == http.StatusPartialContent && contentRangeStart(resp.Header.Get("Content-Range")) == offset:
+		case resp.StatusCode < http.StatusMultipleChoices || resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+			a.logger.InfoContext(ctx, "engine cannot resume stream", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "offset", offset, "status_code", resp.StatusCode)
+			return streaming.ErrRangeNotSupported
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		bodyStr := string(bodyBytes)
 		if len(bodyStr) > 500 {
 			bodyStr = bodyStr[:500]
 		}
-		a.logger.Error("engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", streamURL)
+		a.logger.ErrorContext(ctx, "engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", streamURL)
 		return fmt.Errorf("stream returned status %d", resp.StatusCode)
 	}
 
@@ -352,7 +469,7 @@ func (a *AceStreamHTTPAdapter) StreamContent(ctx context.Context, streamURL stri
 		}
 	}
 
-	if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
+	if contentLength := resp.Header.Get("Content-Length"); contentLength != "" && offset == 0 {
 		if w, ok := dst.(http.ResponseWriter); ok {
 			w.Header().Set("Content-Length", contentLength)
 		}
@@ -366,21 +483,71 @@ func (a *AceStreamHTTPAdapter) StreamContent(ctx context.Context, streamURL stri
 
 	// Log completion with reason
 	if err == nil {
-		a.logger.Info("content stream completed", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "bytes_written", bytesWritten, "reason", "EOF")
+		a.logger.InfoContext(ctx, "content stream completed", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "bytes_written", bytesWritten, "reason", "EOF")
 	} else if err == context.Canceled {
-		a.logger.Info("content stream completed", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "bytes_written", bytesWritten, "reason", "canceled")
+		a.logger.InfoContext(ctx, "content stream completed", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "bytes_written", bytesWritten, "reason", "canceled")
 	} else if err == streaming.ErrWriteTimeout {
 		// Slow client detected - this is logged by TimeoutWriter
-		a.logger.Info("content stream completed", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "bytes_written", bytesWritten, "reason", "slow_client")
+		a.logger.InfoContext(ctx, "content stream completed", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "bytes_written", bytesWritten, "reason", "slow_client")
 		return err
 	} else {
-		a.logger.Info("content stream completed", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "bytes_written", bytesWritten, "reason", "error", "error", err)
+		a.logger.InfoContext(ctx, "content stream completed", "stream_url", streamURL, "infohash", infoHash, "pid", pid, "bytes_written", bytesWritten, "reason", "error", "error", err)
 		return fmt.Errorf("failed to stream content: %w", err)
 	}
 
 	return nil
 }
 
+// contentRangeStart returns the first byte position of a Content-Range
+// header such as "bytes 1000-1999/5000", or -1 if it cannot be read.
+func contentRangeStart(header string) int64 {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return -1
+	}
+	first, _, ok := strings.Cut(spec, "-")
+	if !ok {
+		return -1
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return start
+}
+
+// SetAuth sets the credentials sent with every request to the engine,
+// including the stream content, stat and command URLs the engine returns.
+func (a *AceStreamHTTPAdapter) SetAuth(auth EngineAuth) {
+	a.auth = auth
+}
+
+// newRequest returns a GET request for rawURL carrying the engine
+// credentials. The API key is added to the request URL only, so that the
+// URLs logged by the callers do not reveal it.
+func (a *AceStreamHTTPAdapter) newRequest(ctx context.Context, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if a.auth.APIKey != "" {
+		// Appended rather than re-encoded, so the engine's own session URLs
+		// keep their parameters as given
+		key := url.Values{"api_key": {a.auth.APIKey}}.Encode()
+		if req.URL.RawQuery != "" {
+			key = req.URL.RawQuery + "&" + key
+		}
+		req.URL.RawQuery = key
+	}
+	for name, value := range a.auth.Headers {
+		req.Header.Set(name, value)
+	}
+	if a.auth.Username != "" {
+		req.SetBasicAuth(a.auth.Username, a.auth.Password)
+	}
+	return req, nil
+}
+
 // SetHTTPClient allows replacing the default HTTP client.
 // Useful for testing with custom transports or timeouts.
 func (a *AceStreamHTTPAdapter) SetHTTPClient(client *http.Client) {
@@ -395,9 +562,9 @@ func (a *AceStreamHTTPAdapter) Ping(ctx context.Context) error {
 
 	reqURL := fmt.Sprintf("%s/webui/api/service?method=get_version", a.baseURL)
 
-	a.logger.Debug("engine request", "method", http.MethodGet, "url", reqURL, "pid", "", "timeout", a.pingTimeout)
+	a.logger.DebugContext(ctx, "engine request", "method", http.MethodGet, "url", reqURL, "pid", "", "timeout", a.pingTimeout)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	req, err := a.newRequest(ctx, reqURL)
 	if err != nil {
 		return fmt.Errorf("failed to create ping request: %w", err)
 	}
@@ -405,15 +572,15 @@ func (a *AceStreamHTTPAdapter) Ping(ctx context.Context) error {
 	resp, err := a.httpClient.Do(req)
 	if err != nil {
 		if streaming.IsTimeoutError(err) {
-			a.logger.Warn("engine operation timeout", "operation", "Ping", "url", reqURL, "timeout", a.pingTimeout, "error", err)
+			a.logger.WarnContext(ctx, "engine operation timeout", "operation", "Ping", "url", reqURL, "timeout", a.pingTimeout, "error", err)
 			return fmt.Errorf("ping timed out after %v: %w", a.pingTimeout, err)
 		}
-		a.logger.Warn("engine network error", "operation", "Ping", "error", err, "url", reqURL)
+		a.logger.WarnContext(ctx, "engine network error", "operation", "Ping", "error", err, "url", reqURL)
 		return fmt.Errorf("acestream engine not reachable: %w", err)
 	}
 	defer resp.Body.Close()
 
-	a.logger.Debug("engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
+	a.logger.DebugContext(ctx, "engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
@@ -421,9 +588,157 @@ func (a *AceStreamHTTPAdapter) Ping(ctx context.Context) error {
 		if len(bodyStr) > 500 {
 			bodyStr = bodyStr[:500]
 		}
-		a.logger.Error("engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
+		a.logger.ErrorContext(ctx, "engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
 		return fmt.Errorf("acestream engine returned status %d", resp.StatusCode)
 	}
 
 	return nil
 }
+
+// Search queries the engine's /search API for streams whose name matches
+// query. Results grouped by the engine under a common name are flattened.
+func (a *AceStreamHTTPAdapter) Search(ctx context.Context, query string) ([]driven.SearchResult, error) {
+	// Apply operation-specific timeout
+	ctx, cancel := context.WithTimeout(ctx, a.searchTimeout)
+	defer cancel()
+
+	params := url.Values{}
+	params.Set("query", query)
+	reqURL := fmt.Sprintf("%s/search?%s", a.baseURL, params.Encode())
+
+	a.logger.DebugContext(ctx, "engine request", "method", http.MethodGet, "url", reqURL, "pid", "", "timeout", a.searchTimeout)
+
+	req, err := a.newRequest(ctx, reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search request: %w", err)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		if streaming.IsTimeoutError(err) {
+			a.logger.WarnContext(ctx, "engine operation timeout", "operation", "Search", "url", reqURL, "timeout", a.searchTimeout, "error", err)
+			return nil, fmt.Errorf("search timed out after %v: %w", a.searchTimeout, err)
+		}
+		a.logger.WarnContext(ctx, "engine network error", "operation", "Search", "error", err, "url", reqURL)
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer resp.Body.Close()
+
+	a.logger.DebugContext(ctx, "engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		bodyStr := string(bodyBytes)
+		if len(bodyStr) > 500 {
+			bodyStr = bodyStr[:500]
+		}
+		a.logger.ErrorContext(ctx, "engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
+		return nil, fmt.Errorf("engine returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result *struct {
+			Results []struct {
+				Items []struct {
+					InfoHash     string   `json:"infohash"`
+					Name         string   `json:"name"`
+					Categories   []string `json:"categories"`
+					Bitrate      int      `json:"bitrate"`
+					Availability float64  `json:"availability"`
+				} `json:"items"`
+			} `json:"results"`
+		} `json:"result"`
+		Error *string `json:"error"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+
+	if result.Error != nil && *result.Error != "" {
+		return nil, fmt.Errorf("engine search failed: %s", *result.Error)
+	}
+
+	results := []driven.SearchResult{}
+	if result.Result == nil {
+		return results, nil
+	}
+	for _, group := range result.Result.Results {
+		for _, item := range group.Items {
+			if item.InfoHash == "" {
+				continue
+			}
+			results = append(results, driven.SearchResult{
+				InfoHash:     item.InfoHash,
+				Name:         item.Name,
+				Categories:   item.Categories,
+				Bitrate:      item.Bitrate,
+				Availability: item.Availability,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// RunCommand calls the web UI API method of an allowed command and returns
+// the engine's result. An error reported by the engine is returned in the
+// result rather than as an error.
+func (a *AceStreamHTTPAdapter) RunCommand(ctx context.Context, command driven.EngineCommand) ([]driven.EngineCommandResult, error) {
+	method, ok := engineCommandMethods[command]
+	if !ok {
+		return nil, fmt.Errorf("engine command %q is not allowed", command)
+	}
+
+	// Apply operation-specific timeout
+	ctx, cancel := context.WithTimeout(ctx, a.commandTimeout)
+	defer cancel()
+
+	params := url.Values{}
+	params.Set("method", method)
+	reqURL := fmt.Sprintf("%s/webui/api/service?%s", a.baseURL, params.Encode())
+
+	a.logger.DebugContext(ctx, "engine request", "method", http.MethodGet, "url", reqURL, "pid", "", "timeout", a.commandTimeout)
+
+	req, err := a.newRequest(ctx, reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create command request: %w", err)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		if streaming.IsTimeoutError(err) {
+			a.logger.WarnContext(ctx, "engine operation timeout", "operation", "RunCommand", "url", reqURL, "timeout", a.commandTimeout, "error", err)
+			return nil, fmt.Errorf("command timed out after %v: %w", a.commandTimeout, err)
+		}
+		a.logger.WarnContext(ctx, "engine network error", "operation", "RunCommand", "error", err, "url", reqURL)
+		return nil, fmt.Errorf("failed to run command: %w", err)
+	}
+	defer resp.Body.Close()
+
+	a.logger.DebugContext(ctx, "engine response", "status_code", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "content_length", resp.Header.Get("Content-Length"))
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		bodyStr := string(bodyBytes)
+		if len(bodyStr) > 500 {
+			bodyStr = bodyStr[:500]
+		}
+		a.logger.ErrorContext(ctx, "engine http error", "status_code", resp.StatusCode, "body", bodyStr, "url", reqURL)
+		return nil, fmt.Errorf("engine returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result any     `json:"result"`
+		Error  *string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode command response: %w", err)
+	}
+
+	out := driven.EngineCommandResult{Engine: a.baseURL, Result: result.Result}
+	if result.Error != nil && *result.Error != "" {
+		out = driven.EngineCommandResult{Engine: a.baseURL, Error: *result.Error}
+	}
+	return []driven.EngineCommandResult{out}, nil
+}
@@ -2,11 +2,14 @@ package driven
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -16,11 +19,34 @@ import (
 
 const defaultFetchTimeout = 30 * time.Second
 
+// SourceFetchSettings customizes how a single source is fetched, for
+// upstreams behind authentication, a proxy or a self-signed certificate.
+type SourceFetchSettings struct {
+	// Headers are added to every request, e.g. User-Agent or Authorization.
+	Headers map[string]string
+	// Username and Password, if Username is set, are sent as HTTP basic
+	// auth, replacing any Authorization header.
+	Username string
+	Password string
+	// ProxyURL routes requests through an HTTP(S) or SOCKS5 proxy instead
+	// of the one configured in the environment.
+	ProxyURL string
+	// InsecureSkipVerify disables TLS certificate verification, for
+	// self-hosted gateways with self-signed certificates.
+	InsecureSkipVerify bool
+}
+
 // AcestreamHTTPSource implements the AcestreamSource port by fetching hash lists
 // from HTTP endpoints (NEW ERA and Elcano.top).
 type AcestreamHTTPSource struct {
-	httpClient *http.Client
-	sourceURLs map[string]string
+	httpClient   *http.Client
+	sourceURLs   map[string]string
+	nameFallback bool
+	cache        *HTTPFileCache
+
+	// Per-source overrides set by SetFetchSettings
+	clients  map[string]*http.Client
+	settings map[string]SourceFetchSettings
 }
 
 // NewAcestreamHTTPSource creates a new HTTP-based Acestream source adapter.
@@ -33,66 +59,133 @@ func NewAcestreamHTTPSource(newEraURL, elcanoURL string) *AcestreamHTTPSource {
 			stream.SourceNewEra: newEraURL,
 			stream.SourceElcano: elcanoURL,
 		},
+		clients:  make(map[string]*http.Client),
+		settings: make(map[string]SourceFetchSettings),
+	}
+}
+
+// SetFetchSettings customizes how the given source is fetched. Sources
+// without settings are fetched with a plain GET.
+// Returns an error if the source is unknown or the proxy URL is invalid.
+func (s *AcestreamHTTPSource) SetFetchSettings(source string, settings SourceFetchSettings) error {
+	if _, ok := s.sourceURLs[source]; !ok {
+		return fmt.Errorf("unknown source: %s", source)
 	}
+
+	client := s.httpClient
+	if settings.ProxyURL != "" || settings.InsecureSkipVerify {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if settings.ProxyURL != "" {
+			proxyURL, err := url.Parse(settings.ProxyURL)
+			if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+				return fmt.Errorf("invalid proxy URL for %s: %q", source, settings.ProxyURL)
+			}
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+		if settings.InsecureSkipVerify {
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // explicit opt-in per source
+		}
+		client = &http.Client{Timeout: s.httpClient.Timeout, Transport: transport}
+	}
+
+	s.clients[source] = client
+	s.settings[source] = settings
+	return nil
+}
+
+// SetDisplayNameFallback backfills the blank display names of NEW ERA entries
+// from their tvg-name attribute, for sources that leave the name after the
+// comma empty. Entries are still grouped by tvg-id either way.
+func (s *AcestreamHTTPSource) SetDisplayNameFallback(enabled bool) {
+	s.nameFallback = enabled
+}
+
+// SetCache keeps the downloaded lists in cache, so that later fetches are
+// conditional GETs that skip the download when a list has not changed.
+func (s *AcestreamHTTPSource) SetCache(cache *HTTPFileCache) {
+	s.cache = cache
 }
 
 // FetchHashes retrieves Acestream hashes from the specified source.
 // Supported sources: "new-era", "elcano".
 func (s *AcestreamHTTPSource) FetchHashes(ctx context.Context, source string) (map[string][]string, error) {
+	hashes, _, err := s.FetchNamedHashes(ctx, source)
+	return hashes, err
+}
+
+// FetchNamedHashes retrieves Acestream hashes from the specified source like
+// FetchHashes, along with the display name of each channel that has one.
+func (s *AcestreamHTTPSource) FetchNamedHashes(ctx context.Context, source string) (map[string][]string, map[string]string, error) {
 	url, ok := s.sourceURLs[source]
 	if !ok {
-		return nil, fmt.Errorf("unknown source: %s", source)
+		return nil, nil, fmt.Errorf("unknown source: %s", source)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request for %s: %w", source, err)
+		return nil, nil, fmt.Errorf("failed to create request for %s: %w", source, err)
 	}
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch %s: %w", source, err)
+	client := s.httpClient
+	if c, ok := s.clients[source]; ok {
+		client = c
+		settings := s.settings[source]
+		for name, value := range settings.Headers {
+			req.Header.Set(name, value)
+		}
+		if settings.Username != "" {
+			req.SetBasicAuth(settings.Username, settings.Password)
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, source)
+	body, err := s.cache.Fetch(client, req, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch %s: %w", source, err)
 	}
 
 	switch source {
 	case stream.SourceNewEra:
-		return s.parseNewEra(resp.Body)
+		return s.parseNewEra(bytes.NewReader(body))
 	case stream.SourceElcano:
-		return s.parseElcano(resp.Body)
+		return s.parseElcano(bytes.NewReader(body))
 	default:
-		return nil, fmt.Errorf("no parser for source: %s", source)
+		return nil, nil, fmt.Errorf("no parser for source: %s", source)
 	}
 }
 
 // parseNewEra parses the NEW ERA M3U playlist format.
 // Format: #EXTINF lines with tvg-id attribute, followed by acestream:// URLs.
-// Groups hashes by tvg-id (which matches EPG channel IDs).
-func (s *AcestreamHTTPSource) parseNewEra(r io.Reader) (map[string][]string, error) {
+// Groups hashes by tvg-id (which matches EPG channel IDs), naming each group
+// after the display name of its first entry that has one. Entries without a
+// tvg-id are skipped, and so are malformed hashes.
+func (s *AcestreamHTTPSource) parseNewEra(r io.Reader) (map[string][]string, map[string]string, error) {
 	result := make(map[string][]string)
+	names := make(map[string]string)
 	scanner := bufio.NewScanner(r)
 	// Increase scanner buffer for long #EXTINF lines with logos
 	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024)
 
-	var currentTVGID string
+	var currentTVGID, currentName string
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 
 		if strings.HasPrefix(line, "#EXTINF:") {
 			currentTVGID = extractTVGID(line)
+			currentName = extractDisplayName(line)
+			if currentName == "" && s.nameFallback {
+				currentName = extractAttribute(line, "tvg-name")
+			}
 			continue
 		}
 
 		// acestream:// URL line following an #EXTINF
 		if currentTVGID != "" && strings.HasPrefix(line, "acestream://") {
-			hash := strings.TrimPrefix(line, "acestream://")
-			if hash != "" {
-				result[currentTVGID] = append(result[currentTVGID], hash)
+			if hash, err := stream.ParseInfoHash(line); err == nil {
+				result[currentTVGID] = append(result[currentTVGID], hash.String())
+				if _, ok := names[currentTVGID]; !ok && currentName != "" {
+					names[currentTVGID] = currentName
+				}
 			}
 			currentTVGID = ""
 			continue
@@ -105,16 +198,22 @@ func (s *AcestreamHTTPSource) parseNewEra(r io.Reader) (map[string][]string, err
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to parse NEW ERA M3U: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse NEW ERA M3U: %w", err)
 	}
 
-	return result, nil
+	return result, names, nil
 }
 
 // extractTVGID extracts the tvg-id attribute value from an #EXTINF line.
 // Returns empty string if tvg-id is not found or empty.
 func extractTVGID(line string) string {
-	const marker = `tvg-id="`
+	return extractAttribute(line, "tvg-id")
+}
+
+// extractAttribute extracts a quoted attribute value (e.g. tvg-name="X") from
+// an #EXTINF line. Returns empty string if the attribute is not found or empty.
+func extractAttribute(line, name string) string {
+	marker := name + `="`
 	idx := strings.Index(line, marker)
 	if idx < 0 {
 		return ""
@@ -127,6 +226,18 @@ func extractTVGID(line string) string {
 	return strings.TrimSpace(line[start : start+end])
 }
 
+// extractDisplayName returns the display name that follows the attribute list
+// of an #EXTINF line. Returns empty string if the source left it blank.
+func extractDisplayName(line string) string {
+	if idx := strings.LastIndex(line, `",`); idx >= 0 {
+		return strings.TrimSpace(line[idx+2:])
+	}
+	if idx := strings.Index(line, ","); idx >= 0 {
+		return strings.TrimSpace(line[idx+1:])
+	}
+	return ""
+}
+
 // elcanoResponse represents the root JSON object from the Elcano source.
 type elcanoResponse struct {
 	Hashes []elcanoEntry `json:"hashes"`
@@ -141,17 +252,21 @@ type elcanoEntry struct {
 
 // parseElcano parses the Elcano JSON format.
 // Format: {"generated": "...", "count": N, "hashes": [{"title": "...", "hash": "...", "tvg_id": "...", ...}]}
-// Groups hashes by tvg_id (which matches EPG channel IDs) for direct matching.
-func (s *AcestreamHTTPSource) parseElcano(r io.Reader) (map[string][]string, error) {
+// Groups hashes by tvg_id (which matches EPG channel IDs) for direct matching,
+// naming each group after the title of its first entry that has one.
+// Malformed hashes are skipped.
+func (s *AcestreamHTTPSource) parseElcano(r io.Reader) (map[string][]string, map[string]string, error) {
 	var resp elcanoResponse
 
 	if err := json.NewDecoder(r).Decode(&resp); err != nil {
-		return nil, fmt.Errorf("failed to parse Elcano JSON: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse Elcano JSON: %w", err)
 	}
 
 	result := make(map[string][]string)
+	names := make(map[string]string)
 	for _, entry := range resp.Hashes {
-		if entry.Hash == "" {
+		hash, err := stream.ParseInfoHash(entry.Hash)
+		if err != nil {
 			continue
 		}
 		// Use tvg_id as the channel key since it directly matches EPG channel IDs.
@@ -161,12 +276,15 @@ func (s *AcestreamHTTPSource) parseElcano(r io.Reader) (map[string][]string, err
 			key = entry.Title
 		}
 		if key != "" {
-			result[key] = append(result[key], entry.Hash)
+			result[key] = append(result[key], hash.String())
+			if _, ok := names[key]; !ok && entry.Title != "" {
+				names[key] = entry.Title
+			}
 		}
 	}
 
-	return result, nil
+	return result, names, nil
 }
 
-// Ensure AcestreamHTTPSource implements the driven.AcestreamSource interface
-var _ driven.AcestreamSource = (*AcestreamHTTPSource)(nil)
+// Ensure AcestreamHTTPSource implements the driven.AcestreamNamedSource interface
+var _ driven.AcestreamNamedSource = (*AcestreamHTTPSource)(nil)
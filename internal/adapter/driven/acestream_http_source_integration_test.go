@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -297,3 +298,109 @@ func TestAcestreamHTTPSource_FetchHashes_Integration(t *testing.T) {
 		t.Logf("Successfully handled empty hashes array")
 	})
 }
+
+func TestAcestreamHTTPSource_ParseNewEra_DisplayNameFallback(t *testing.T) {
+	const playlist = `#EXTM3U
+#EXTINF:-1 tvg-id="HBO HD" tvg-name="Ignored", HBO FHD
+acestream://0123456789abcdef0123456789abcdef01234567
+#EXTINF:-1 tvg-id="La1.es" tvg-name="La 1 HD",
+acestream://1111111111111111111111111111111111111111
+#EXTINF:-1 tvg-id="" tvg-name="Cuatro HD", Cuatro HD
+acestream://2222222222222222222222222222222222222222
+#EXTINF:-1 tvg-id="Other.es" group-title="OTHER",
+acestream://3333333333333333333333333333333333333333
+`
+	wantHashes := map[string]string{
+		"HBO HD":   "0123456789abcdef0123456789abcdef01234567",
+		"La1.es":   "1111111111111111111111111111111111111111",
+		"Other.es": "3333333333333333333333333333333333333333",
+	}
+
+	tests := []struct {
+		name      string
+		fallback  bool
+		wantNames map[string]string
+	}{
+		{
+			name:      "disabled leaves blank display names out",
+			wantNames: map[string]string{"HBO HD": "HBO FHD"},
+		},
+		{
+			name:      "enabled backfills blank display names from tvg-name",
+			fallback:  true,
+			wantNames: map[string]string{"HBO HD": "HBO FHD", "La1.es": "La 1 HD"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewAcestreamHTTPSource(dummyURL, dummyURL)
+			source.SetDisplayNameFallback(tt.fallback)
+
+			hashes, names, err := source.parseNewEra(strings.NewReader(playlist))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Entries are grouped by tvg-id either way
+			if len(hashes) != len(wantHashes) {
+				t.Fatalf("expected %d channels, got %d: %v", len(wantHashes), len(hashes), hashes)
+			}
+			for key, hash := range wantHashes {
+				if got := hashes[key]; len(got) != 1 || got[0] != hash {
+					t.Errorf("channel %q: expected [%s], got %v", key, hash, got)
+				}
+			}
+			if len(names) != len(tt.wantNames) {
+				t.Fatalf("expected names %v, got %v", tt.wantNames, names)
+			}
+			for key, name := range tt.wantNames {
+				if names[key] != name {
+					t.Errorf("channel %q: expected display name %q, got %q", key, name, names[key])
+				}
+			}
+		})
+	}
+}
+
+func TestAcestreamHTTPSource_NormalizesHashes(t *testing.T) {
+	source := NewAcestreamHTTPSource(dummyURL, dummyURL)
+
+	t.Run("NEW ERA", func(t *testing.T) {
+		const playlist = `#EXTM3U
+#EXTINF:-1 tvg-id="HBO HD", HBO
+acestream://0123456789ABCDEF0123456789ABCDEF01234567
+#EXTINF:-1 tvg-id="ESPN HD", ESPN
+acestream://not-a-hash
+`
+		hashes, _, err := source.parseNewEra(strings.NewReader(playlist))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := hashes["HBO HD"]; len(got) != 1 || got[0] != "0123456789abcdef0123456789abcdef01234567" {
+			t.Errorf("expected the lowercase hash for HBO HD, got %v", got)
+		}
+		if _, ok := hashes["ESPN HD"]; ok {
+			t.Errorf("expected the malformed hash to be skipped, got %v", hashes["ESPN HD"])
+		}
+	})
+
+	t.Run("Elcano", func(t *testing.T) {
+		const body = `{"hashes":[
+			{"title":"HBO","hash":"0123456789ABCDEF0123456789ABCDEF01234567","tvg_id":"HBO HD"},
+			{"title":"ESPN","hash":"short","tvg_id":"ESPN HD"}
+		]}`
+		hashes, names, err := source.parseElcano(strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := hashes["HBO HD"]; len(got) != 1 || got[0] != "0123456789abcdef0123456789abcdef01234567" {
+			t.Errorf("expected the lowercase hash for HBO HD, got %v", got)
+		}
+		if _, ok := hashes["ESPN HD"]; ok {
+			t.Errorf("expected the malformed hash to be skipped, got %v", hashes["ESPN HD"])
+		}
+		if names["HBO HD"] != "HBO" {
+			t.Errorf("expected HBO HD to be named after its title, got %q", names["HBO HD"])
+		}
+	})
+}
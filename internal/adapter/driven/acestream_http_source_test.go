@@ -0,0 +1,99 @@
+package driven
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+func TestAcestreamHTTPSource_FetchSettings(t *testing.T) {
+	const playlist = "#EXTM3U\n#EXTINF:-1 tvg-id=\"hbo\",HBO\nacestream://6162633132330000000000000000000000000000\n"
+
+	t.Run("sends custom headers and basic auth", func(t *testing.T) {
+		var gotAgent, gotUser, gotPass string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotAgent = r.Header.Get("User-Agent")
+			gotUser, gotPass, _ = r.BasicAuth()
+			_, _ = w.Write([]byte(playlist))
+		}))
+		defer server.Close()
+
+		source := NewAcestreamHTTPSource(server.URL, dummyURL)
+		err := source.SetFetchSettings(stream.SourceNewEra, SourceFetchSettings{
+			Headers:  map[string]string{"User-Agent": "VLC/3.0"},
+			Username: "user",
+			Password: "secret",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := source.FetchHashes(context.Background(), stream.SourceNewEra); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotAgent != "VLC/3.0" {
+			t.Errorf("expected User-Agent VLC/3.0, got %q", gotAgent)
+		}
+		if gotUser != "user" || gotPass != "secret" {
+			t.Errorf("expected basic auth user:secret, got %q:%q", gotUser, gotPass)
+		}
+	})
+
+	t.Run("accepts self-signed certificates only when allowed", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(playlist))
+		}))
+		defer server.Close()
+
+		source := NewAcestreamHTTPSource(server.URL, dummyURL)
+		if _, err := source.FetchHashes(context.Background(), stream.SourceNewEra); err == nil {
+			t.Fatal("expected certificate error without insecure_skip_verify")
+		}
+
+		if err := source.SetFetchSettings(stream.SourceNewEra, SourceFetchSettings{InsecureSkipVerify: true}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		hashes, err := source.FetchHashes(context.Background(), stream.SourceNewEra)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(hashes["hbo"]) != 1 {
+			t.Errorf("expected hash for hbo, got %v", hashes)
+		}
+	})
+
+	t.Run("routes requests through the proxy", func(t *testing.T) {
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+			_, _ = w.Write([]byte(playlist))
+		}))
+		defer proxy.Close()
+
+		source := NewAcestreamHTTPSource("http://upstream.invalid/list.m3u", dummyURL)
+		if err := source.SetFetchSettings(stream.SourceNewEra, SourceFetchSettings{ProxyURL: proxy.URL}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := source.FetchHashes(context.Background(), stream.SourceNewEra); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if proxied != "http://upstream.invalid/list.m3u" {
+			t.Errorf("expected request for the upstream URL via the proxy, got %q", proxied)
+		}
+	})
+
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		source := NewAcestreamHTTPSource(dummyURL, dummyURL)
+
+		if err := source.SetFetchSettings("unknown", SourceFetchSettings{}); err == nil {
+			t.Error("expected error for unknown source")
+		}
+		if err := source.SetFetchSettings(stream.SourceElcano, SourceFetchSettings{ProxyURL: "not a url"}); err == nil {
+			t.Error("expected error for invalid proxy URL")
+		}
+	})
+}
@@ -8,11 +8,14 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/port/driven"
 	"github.com/alorle/iptv-manager/internal/streaming"
 )
 
@@ -29,7 +32,7 @@ func TestAceStreamHTTPAdapter_StartStream_Timeout(t *testing.T) {
 	adapter.startStreamTimeout = 500 * time.Millisecond
 
 	ctx := context.Background()
-	_, err := adapter.StartStream(ctx, "test-hash", "test-pid")
+	_, err := adapter.StartStream(ctx, "test-hash", "test-pid", driven.StreamOptions{})
 
 	if err == nil {
 		t.Fatal("expected timeout error, got nil")
@@ -51,7 +54,7 @@ func TestAceStreamHTTPAdapter_StartStream_Success(t *testing.T) {
 	adapter := NewAceStreamHTTPAdapter(server.URL, logger)
 
 	ctx := context.Background()
-	streamURL, err := adapter.StartStream(ctx, "test-hash", "test-pid")
+	streamURL, err := adapter.StartStream(ctx, "test-hash", "test-pid", driven.StreamOptions{})
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -77,6 +80,74 @@ func TestAceStreamHTTPAdapter_StartStream_Success(t *testing.T) {
 	}
 }
 
+func TestAceStreamHTTPAdapter_StartStream_TranscodeAudio(t *testing.T) {
+	tests := []struct {
+		transcode channel.AudioTranscode
+		wantParam string
+	}{
+		{transcode: channel.AudioTranscodeAll, wantParam: "transcode_audio"},
+		{transcode: channel.AudioTranscodeAC3, wantParam: "transcode_ac3"},
+		{transcode: channel.AudioTranscodeMP3, wantParam: "transcode_mp3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.transcode), func(t *testing.T) {
+			var query url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"response":{"playback_url":"http://example.com/stream","stat_url":"http://example.com/stat","command_url":"http://example.com/cmd"}}`))
+			}))
+			defer server.Close()
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			adapter := NewAceStreamHTTPAdapter(server.URL, logger)
+
+			opts := driven.StreamOptions{TranscodeAudio: tt.transcode}
+			if _, err := adapter.StartStream(context.Background(), "test-hash", "test-pid", opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := query.Get(tt.wantParam); got != "1" {
+				t.Errorf("expected %s=1, got %q (query %v)", tt.wantParam, got, query)
+			}
+		})
+	}
+}
+
+func TestAceStreamHTTPAdapter_StartStream_EngineBusy(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantBusy bool
+	}{
+		{name: "too many sessions", status: http.StatusOK, body: `{"response":null,"error":"Too many active sessions"}`, wantBusy: true},
+		{name: "service unavailable", status: http.StatusServiceUnavailable, wantBusy: true},
+		{name: "other engine error", status: http.StatusOK, body: `{"response":null,"error":"failed to load content"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			adapter := NewAceStreamHTTPAdapter(server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			_, err := adapter.StartStream(context.Background(), "test-hash", "test-pid", driven.StreamOptions{})
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if got := errors.Is(err, driven.ErrEngineBusy); got != tt.wantBusy {
+				t.Errorf("expected ErrEngineBusy %v, got %v", tt.wantBusy, err)
+			}
+		})
+	}
+}
+
 func TestAceStreamHTTPAdapter_GetStats_Success(t *testing.T) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/ace/getstream", func(w http.ResponseWriter, r *http.Request) {
@@ -264,6 +335,48 @@ func TestAceStreamHTTPAdapter_StopStream_NoSession(t *testing.T) {
 	}
 }
 
+func TestAceStreamHTTPAdapter_RestoreSession(t *testing.T) {
+	var stopped bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ace/cmd/abc/def", func(w http.ResponseWriter, r *http.Request) {
+		stopped = r.URL.Query().Get("method") == "stop"
+		_, _ = w.Write([]byte(`{"response":"ok","error":null}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	previous := NewAceStreamHTTPAdapter(server.URL, logger)
+	previous.sessions["test-pid"] = engineSession{
+		statURL:    server.URL + "/ace/stat/abc/def",
+		commandURL: server.URL + "/ace/cmd/abc/def",
+	}
+
+	handle, ok := previous.SessionHandle("test-pid")
+	if !ok {
+		t.Fatal("expected a handle for test-pid")
+	}
+	if _, ok := previous.SessionHandle("other-pid"); ok {
+		t.Error("expected no handle for an unknown PID")
+	}
+
+	// A new adapter, as after a restart, can stop the stream from its handle
+	adapter := NewAceStreamHTTPAdapter(server.URL, logger)
+	if err := adapter.RestoreSession("test-pid", handle); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := adapter.StopStream(context.Background(), "test-pid"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !stopped {
+		t.Error("expected the restored session to be stopped")
+	}
+
+	if err := adapter.RestoreSession("test-pid", "not json"); err == nil {
+		t.Error("expected an error for a malformed handle")
+	}
+}
+
 func TestAceStreamHTTPAdapter_Ping_Timeout(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(2 * time.Second)
@@ -306,6 +419,64 @@ func TestAceStreamHTTPAdapter_Ping_Success(t *testing.T) {
 	}
 }
 
+func TestAceStreamHTTPAdapter_Auth(t *testing.T) {
+	type received struct {
+		path, apiKey, method, user, password, header string
+	}
+	var requests []received
+	mux := http.NewServeMux()
+	record := func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		requests = append(requests, received{
+			path:     r.URL.Path,
+			apiKey:   r.URL.Query().Get("api_key"),
+			method:   r.URL.Query().Get("method"),
+			user:     user,
+			password: password,
+			header:   r.Header.Get("X-Engine-Token"),
+		})
+	}
+	mux.HandleFunc("/webui/api/service", func(w http.ResponseWriter, r *http.Request) {
+		record(w, r)
+		_, _ = w.Write([]byte(`{"result":"3.2"}`))
+	})
+	mux.HandleFunc("/ace/cmd/abc/def", func(w http.ResponseWriter, r *http.Request) {
+		record(w, r)
+		_, _ = w.Write([]byte(`{"response":"ok","error":null}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	adapter := NewAceStreamHTTPAdapter(server.URL, logger)
+	adapter.SetAuth(EngineAuth{
+		APIKey:   "s3cret",
+		Username: "ace",
+		Password: "hunter2",
+		Headers:  map[string]string{"X-Engine-Token": "token"},
+	})
+	adapter.sessions["test-pid"] = engineSession{commandURL: server.URL + "/ace/cmd/abc/def"}
+
+	if err := adapter.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if err := adapter.StopStream(context.Background(), "test-pid"); err != nil {
+		t.Fatalf("StopStream() error = %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+	for _, r := range requests {
+		if r.apiKey != "s3cret" || r.user != "ace" || r.password != "hunter2" || r.header != "token" {
+			t.Errorf("request to %s missing credentials: %+v", r.path, r)
+		}
+	}
+	if requests[0].method != "get_version" || requests[1].method != "stop" {
+		t.Errorf("expected the original query parameters kept, got %+v", requests)
+	}
+}
+
 func TestAceStreamHTTPAdapter_StreamContent_NoTimeout(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -447,7 +618,7 @@ func TestAceStreamHTTPAdapter_StartStream_StoresSession(t *testing.T) {
 	ctx := context.Background()
 
 	// Step 1: Start stream
-	streamURL, err := adapter.StartStream(ctx, "hash123", "pid-1")
+	streamURL, err := adapter.StartStream(ctx, "hash123", "pid-1", driven.StreamOptions{})
 	if err != nil {
 		t.Fatalf("StartStream: unexpected error: %v", err)
 	}
@@ -481,3 +652,202 @@ func TestAceStreamHTTPAdapter_StartStream_StoresSession(t *testing.T) {
 		t.Error("expected session to be removed after StopStream")
 	}
 }
+
+func TestAceStreamHTTPAdapter_Search(t *testing.T) {
+	t.Run("flattens result groups", func(t *testing.T) {
+		var gotQuery string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/search" {
+				t.Errorf("expected /search, got %s", r.URL.Path)
+			}
+			gotQuery = r.URL.Query().Get("query")
+			_, _ = w.Write([]byte(`{"result":{"total":2,"results":[
+				{"name":"DAZN 1","items":[
+					{"infohash":"abc","name":"DAZN 1 HD","categories":["sport"],"bitrate":500000,"availability":0.9},
+					{"infohash":"","name":"broken"}
+				]},
+				{"name":"DAZN 2","items":[{"infohash":"def","name":"DAZN 2"}]}
+			]},"error":null}`))
+		}))
+		defer server.Close()
+
+		adapter := NewAceStreamHTTPAdapter(server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		results, err := adapter.Search(context.Background(), "dazn & co")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if gotQuery != "dazn & co" {
+			t.Errorf("expected query to be passed through, got %q", gotQuery)
+		}
+		if len(results) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(results))
+		}
+		if results[0].InfoHash != "abc" || results[0].Name != "DAZN 1 HD" || results[0].Bitrate != 500000 || results[0].Availability != 0.9 {
+			t.Errorf("unexpected first result %+v", results[0])
+		}
+		if len(results[0].Categories) != 1 || results[0].Categories[0] != "sport" {
+			t.Errorf("unexpected categories %v", results[0].Categories)
+		}
+		if results[1].InfoHash != "def" {
+			t.Errorf("unexpected second result %+v", results[1])
+		}
+	})
+
+	t.Run("returns engine errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"result":null,"error":"search is not available"}`))
+		}))
+		defer server.Close()
+
+		adapter := NewAceStreamHTTPAdapter(server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		_, err := adapter.Search(context.Background(), "dazn")
+		if err == nil || !strings.Contains(err.Error(), "search is not available") {
+			t.Errorf("expected engine error, got %v", err)
+		}
+	})
+
+	t.Run("returns error on non-200 status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		adapter := NewAceStreamHTTPAdapter(server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if _, err := adapter.Search(context.Background(), "dazn"); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+func TestAceStreamHTTPAdapter_RunCommand(t *testing.T) {
+	t.Run("calls the allowed method and returns its result", func(t *testing.T) {
+		var gotMethod string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/webui/api/service" {
+				t.Errorf("expected /webui/api/service, got %s", r.URL.Path)
+			}
+			gotMethod = r.URL.Query().Get("method")
+			_, _ = w.Write([]byte(`{"result":{"version":"3.1.74","code":3017400},"error":null}`))
+		}))
+		defer server.Close()
+
+		adapter := NewAceStreamHTTPAdapter(server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		results, err := adapter.RunCommand(context.Background(), driven.EngineCommandVersion)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotMethod != "get_version" {
+			t.Errorf("expected method get_version, got %q", gotMethod)
+		}
+		if len(results) != 1 || results[0].Engine != server.URL || results[0].Error != "" {
+			t.Fatalf("unexpected results: %+v", results)
+		}
+		if result, ok := results[0].Result.(map[string]any); !ok || result["version"] != "3.1.74" {
+			t.Errorf("unexpected result: %#v", results[0].Result)
+		}
+	})
+
+	t.Run("reports engine errors in the result", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"result":null,"error":"not ready"}`))
+		}))
+		defer server.Close()
+
+		adapter := NewAceStreamHTTPAdapter(server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		results, err := adapter.RunCommand(context.Background(), driven.EngineCommandClearCache)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results) != 1 || results[0].Error != "not ready" {
+			t.Errorf("expected the engine error in the result, got %+v", results)
+		}
+	})
+
+	t.Run("rejects commands outside the allowlist", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("expected no request to the engine")
+		}))
+		defer server.Close()
+
+		adapter := NewAceStreamHTTPAdapter(server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if _, err := adapter.RunCommand(context.Background(), "shutdown"); err == nil {
+			t.Error("expected error for a command outside the allowlist")
+		}
+	})
+
+	t.Run("returns error on HTTP failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		adapter := NewAceStreamHTTPAdapter(server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		if _, err := adapter.RunCommand(context.Background(), driven.EngineCommandNetworkStatus); err == nil {
+			t.Error("expected error for a failed request")
+		}
+	})
+}
+
+func TestAceStreamHTTPAdapter_StreamContentFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+		wantErr error
+	}{
+		{
+			name: "resumes from the offset on partial content",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Range"); got != "bytes=4-" {
+					t.Errorf("expected Range bytes=4-, got %q", got)
+				}
+				w.Header().Set("Content-Range", "bytes 4-7/8")
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write([]byte("more"))
+			},
+			want: "more",
+		},
+		{
+			name: "rejects a full response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("datamore"))
+			},
+			wantErr: streaming.ErrRangeNotSupported,
+		},
+		{
+			name: "rejects partial content from another offset",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Range", "bytes 0-7/8")
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write([]byte("datamore"))
+			},
+			wantErr: streaming.ErrRangeNotSupported,
+		},
+		{
+			name: "rejects an unsatisfiable range",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			},
+			wantErr: streaming.ErrRangeNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			adapter := NewAceStreamHTTPAdapter(server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			var buf strings.Builder
+			err := adapter.StreamContentFrom(context.Background(), server.URL, 4, &buf, "test-hash", "test-pid", 5*time.Second)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, buf.String())
+			}
+		})
+	}
+}
@@ -30,6 +30,8 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
 )
 
 const (
@@ -128,7 +130,7 @@ func testPing(ctx context.Context, t *testing.T, adapter *AceStreamHTTPAdapter)
 func testStartStream(ctx context.Context, t *testing.T, adapter *AceStreamHTTPAdapter, pid string) (string, error) {
 	t.Logf("Starting stream with PID: %s, InfoHash: %s", pid, testInfoHash)
 	startTime := time.Now()
-	streamURL, err := adapter.StartStream(ctx, testInfoHash, pid)
+	streamURL, err := adapter.StartStream(ctx, testInfoHash, pid, driven.StreamOptions{})
 	duration := time.Since(startTime)
 	t.Logf("StartStream completed in %v", duration)
 	return streamURL, err
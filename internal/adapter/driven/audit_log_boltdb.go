@@ -0,0 +1,113 @@
+package driven
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/audit"
+)
+
+const auditLogBucket = "audit_log"
+
+// AuditLogBoltDBRepository implements the AuditLogRepository port using
+// BoltDB. Entries are keyed by time and a sequence number, so entries made
+// at the same instant keep their order.
+type AuditLogBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewAuditLogBoltDBRepository creates a new BoltDB-backed audit log
+// repository. It initializes the required bucket if it doesn't exist.
+func NewAuditLogBoltDBRepository(db *bbolt.DB) (*AuditLogBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(auditLogBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditLogBoltDBRepository{db: db}, nil
+}
+
+// auditEntryDTO is the JSON serialization format for an entry.
+type auditEntryDTO struct {
+	Action  string `json:"action"`
+	Subject string `json:"subject"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to,omitempty"`
+	Actor   string `json:"actor,omitempty"`
+	At      int64  `json:"at"`
+}
+
+// Append records a new entry in BoltDB.
+func (r *AuditLogBoltDBRepository) Append(ctx context.Context, e audit.Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(auditLogBucket))
+		if bucket == nil {
+			return errors.New("audit log bucket not found")
+		}
+
+		data, err := json.Marshal(auditEntryDTO{
+			Action:  string(e.Action()),
+			Subject: e.Subject(),
+			From:    e.From(),
+			To:      e.To(),
+			Actor:   e.Actor(),
+			At:      e.At().UnixNano(),
+		})
+		if err != nil {
+			return err
+		}
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		return bucket.Put(binary.BigEndian.AppendUint64(timestampToKey(e.At()), seq), data)
+	})
+}
+
+// FindRecent retrieves up to limit entries from BoltDB, most recent first.
+func (r *AuditLogBoltDBRepository) FindRecent(ctx context.Context, limit int) ([]audit.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries := []audit.Entry{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(auditLogBucket))
+		if bucket == nil {
+			return errors.New("audit log bucket not found")
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil && (limit <= 0 || len(entries) < limit); k, v = c.Prev() {
+			var dto auditEntryDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			entries = append(entries, audit.ReconstructEntry(
+				audit.Action(dto.Action), dto.Subject, dto.From, dto.To, dto.Actor, time.Unix(0, dto.At)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,64 @@
+package driven
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/audit"
+)
+
+func TestNewAuditLogBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewAuditLogBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestAuditLogBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	repo, err := NewAuditLogBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	for i, to := range []string{"La 1 HD", "La 1 UHD", "La 1"} {
+		e, _ := audit.NewEntry(audit.ActionStreamMoved, "abc", "La 1", to, "token-1", now.Add(time.Duration(i/2)*time.Minute))
+		if err := repo.Append(ctx, e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	t.Run("lists entries most recent first", func(t *testing.T) {
+		entries, err := repo.FindRecent(ctx, 0)
+		if err != nil {
+			t.Fatalf("FindRecent() error = %v", err)
+		}
+		if len(entries) != 3 || entries[0].To() != "La 1" || entries[1].To() != "La 1 UHD" || entries[2].To() != "La 1 HD" {
+			t.Fatalf("unexpected entries %+v", entries)
+		}
+		if e := entries[0]; e.Action() != audit.ActionStreamMoved || e.Subject() != "abc" || e.From() != "La 1" ||
+			e.Actor() != "token-1" || !e.At().Equal(now.Add(time.Minute)) {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	})
+
+	t.Run("limits the entries returned", func(t *testing.T) {
+		entries, err := repo.FindRecent(ctx, 2)
+		if err != nil {
+			t.Fatalf("FindRecent() error = %v", err)
+		}
+		if len(entries) != 2 || entries[1].To() != "La 1 UHD" {
+			t.Errorf("unexpected entries %+v", entries)
+		}
+	})
+}
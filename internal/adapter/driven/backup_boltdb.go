@@ -0,0 +1,122 @@
+package driven
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/backup"
+)
+
+// BoltDBBackup implements the DatabaseBackup port for a BoltDB database.
+type BoltDBBackup struct {
+	db *bbolt.DB
+}
+
+// NewBoltDBBackup creates a backup adapter for db.
+func NewBoltDBBackup(db *bbolt.DB) *BoltDBBackup {
+	return &BoltDBBackup{db: db}
+}
+
+// WriteBackup writes a consistent snapshot of the database to w from a
+// read-only transaction, so writers are not blocked while it streams.
+func (b *BoltDBBackup) WriteBackup(ctx context.Context, w io.Writer) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var n int64
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+// Restore replaces every bucket in the database with the buckets of the
+// snapshot read from r. The snapshot is staged in a temporary file and
+// consistency-checked first, and the swap happens in a single transaction,
+// so open repositories keep working and see either the old or the new data.
+func (b *BoltDBBackup) Restore(ctx context.Context, r io.Reader) error {
+	tmp, err := os.CreateTemp("", "iptv-manager-restore-*.db")
+	if err != nil {
+		return fmt.Errorf("staging backup: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("staging backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("staging backup: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	src, err := bbolt.Open(tmp.Name(), 0o600, &bbolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return fmt.Errorf("%w: %w", backup.ErrInvalidBackup, err)
+	}
+	defer src.Close()
+
+	return src.View(func(srcTx *bbolt.Tx) error {
+		// Drain every error so the checker goroutine finishes before the
+		// transaction closes
+		var checkErr error
+		for err := range srcTx.Check() {
+			if checkErr == nil {
+				checkErr = err
+			}
+		}
+		if checkErr != nil {
+			return fmt.Errorf("%w: %w", backup.ErrInvalidBackup, checkErr)
+		}
+
+		return b.db.Update(func(tx *bbolt.Tx) error {
+			var existing [][]byte
+			if err := tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+				existing = append(existing, name)
+				return nil
+			}); err != nil {
+				return err
+			}
+			for _, name := range existing {
+				if err := tx.DeleteBucket(name); err != nil {
+					return fmt.Errorf("clearing bucket %s: %w", name, err)
+				}
+			}
+
+			return srcTx.ForEach(func(name []byte, srcBucket *bbolt.Bucket) error {
+				dst, err := tx.CreateBucket(name)
+				if err != nil {
+					return fmt.Errorf("restoring bucket %s: %w", name, err)
+				}
+				return copyBucket(dst, srcBucket)
+			})
+		})
+	})
+}
+
+// copyBucket copies the keys, nested buckets and sequence of src into dst.
+func copyBucket(dst, src *bbolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+	return src.ForEach(func(k, v []byte) error {
+		if v != nil {
+			return dst.Put(k, v)
+		}
+		child, err := dst.CreateBucket(k)
+		if err != nil {
+			return err
+		}
+		return copyBucket(child, src.Bucket(k))
+	})
+}
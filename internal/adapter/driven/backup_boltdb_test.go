@@ -0,0 +1,86 @@
+package driven
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/backup"
+	"github.com/alorle/iptv-manager/internal/channel"
+)
+
+func TestBoltDBBackup_WriteBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+
+	srcDB, cleanupSrc := setupTestDB(t)
+	defer cleanupSrc()
+	srcRepo, err := NewChannelBoltDBRepository(srcDB)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	hbo, _ := channel.NewChannel("HBO")
+	if err := srcRepo.Save(ctx, hbo); err != nil {
+		t.Fatalf("failed to save channel: %v", err)
+	}
+
+	var snapshot bytes.Buffer
+	n, err := NewBoltDBBackup(srcDB).WriteBackup(ctx, &snapshot)
+	if err != nil {
+		t.Fatalf("WriteBackup() error = %v", err)
+	}
+	if n != int64(snapshot.Len()) || n == 0 {
+		t.Errorf("WriteBackup() reported %d bytes, wrote %d", n, snapshot.Len())
+	}
+
+	dstDB, cleanupDst := setupTestDB(t)
+	defer cleanupDst()
+	dstRepo, err := NewChannelBoltDBRepository(dstDB)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	cnn, _ := channel.NewChannel("CNN")
+	if err := dstRepo.Save(ctx, cnn); err != nil {
+		t.Fatalf("failed to save channel: %v", err)
+	}
+
+	if err := NewBoltDBBackup(dstDB).Restore(ctx, &snapshot); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	// The repository created before the restore sees the restored data
+	channels, err := dstRepo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(channels) != 1 || channels[0].Name() != "HBO" {
+		t.Errorf("expected only the restored HBO channel, got %v", channels)
+	}
+}
+
+func TestBoltDBBackup_Restore_Invalid(t *testing.T) {
+	ctx := context.Background()
+
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	repo, err := NewChannelBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	hbo, _ := channel.NewChannel("HBO")
+	if err := repo.Save(ctx, hbo); err != nil {
+		t.Fatalf("failed to save channel: %v", err)
+	}
+
+	for _, input := range []string{"", "not a database", strings.Repeat("x", 64*1024)} {
+		err := NewBoltDBBackup(db).Restore(ctx, strings.NewReader(input))
+		if !errors.Is(err, backup.ErrInvalidBackup) {
+			t.Errorf("Restore(%d bytes) error = %v, want ErrInvalidBackup", len(input), err)
+		}
+	}
+
+	if _, err := repo.FindByName(ctx, "HBO"); err != nil {
+		t.Errorf("expected data to survive a rejected restore, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/alorle/iptv-manager/internal/backup"
+)
+
+// BackupFileStore implements the BackupStore port on the local filesystem.
+// Only file names produced by backup.FileName are accepted, so names cannot
+// escape the backup directory.
+type BackupFileStore struct {
+	dir string
+}
+
+// NewBackupFileStore creates a backup store rooted at dir, creating it if needed.
+func NewBackupFileStore(dir string) (*BackupFileStore, error) {
+	if dir == "" {
+		return nil, errors.New("backup directory cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating backup directory: %w", err)
+	}
+	return &BackupFileStore{dir: dir}, nil
+}
+
+// Save streams the backup into a temporary file and renames it into place
+// once write succeeds.
+func (s *BackupFileStore) Save(ctx context.Context, name string, write func(w io.Writer) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !backup.IsFileName(name) {
+		return fmt.Errorf("invalid backup name %q", name)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
+
+// List returns the names of the backup files in the directory.
+func (s *BackupFileStore) List(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && backup.IsFileName(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Delete removes the named backup file.
+func (s *BackupFileStore) Delete(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !backup.IsFileName(name) {
+		return fmt.Errorf("invalid backup name %q", name)
+	}
+	return os.Remove(filepath.Join(s.dir, name))
+}
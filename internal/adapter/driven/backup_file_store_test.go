@@ -0,0 +1,67 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/backup"
+)
+
+func TestBackupFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "backups")
+
+	store, err := NewBackupFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewBackupFileStore() error = %v", err)
+	}
+
+	name := backup.FileName(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), backup.ReasonScheduled)
+	err = store.Save(ctx, name, func(w io.Writer) error {
+		_, err := w.Write([]byte("snapshot"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil || string(data) != "snapshot" {
+		t.Errorf("expected saved snapshot on disk, got %q (%v)", data, err)
+	}
+
+	failed := backup.FileName(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), backup.ReasonScheduled)
+	writeErr := errors.New("disk full")
+	if err := store.Save(ctx, failed, func(w io.Writer) error { return writeErr }); !errors.Is(err, writeErr) {
+		t.Errorf("Save() error = %v, want %v", err, writeErr)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600); err != nil {
+		t.Fatalf("failed to write unrelated file: %v", err)
+	}
+
+	names, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if !slices.Equal(names, []string{name}) {
+		t.Errorf("List() = %v, want only %q", names, name)
+	}
+
+	if err := store.Save(ctx, "../escape.db", func(w io.Writer) error { return nil }); err == nil {
+		t.Error("expected Save() to reject names outside the backup naming scheme")
+	}
+
+	if err := store.Delete(ctx, name); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if names, _ := store.List(ctx); len(names) != 0 {
+		t.Errorf("expected no backups after Delete(), got %v", names)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"slices"
 	"time"
 
 	"go.etcd.io/bbolt"
@@ -13,6 +14,9 @@ import (
 
 const (
 	channelsBucket = "channels"
+	// channelTagsBucket indexes channels by tag: it holds a bucket per tag
+	// whose keys are the names of the channels with that tag.
+	channelTagsBucket = "channel_tags"
 )
 
 // ChannelBoltDBRepository implements the ChannelRepository port using BoltDB.
@@ -27,9 +31,12 @@ func NewChannelBoltDBRepository(db *bbolt.DB) (*ChannelBoltDBRepository, error)
 		return nil, errors.New("db cannot be nil")
 	}
 
-	// Create the channels bucket if it doesn't exist
+	// Create the channels and tag index buckets if they don't exist
 	err := db.Update(func(tx *bbolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(channelsBucket))
+		if _, err := tx.CreateBucketIfNotExists([]byte(channelsBucket)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists([]byte(channelTagsBucket))
 		return err
 	})
 	if err != nil {
@@ -41,9 +48,19 @@ func NewChannelBoltDBRepository(db *bbolt.DB) (*ChannelBoltDBRepository, error)
 
 // channelDTO is used for JSON serialization.
 type channelDTO struct {
-	Name       string         `json:"name"`
-	Status     string         `json:"status"`
-	EPGMapping *epgMappingDTO `json:"epg_mapping,omitempty"`
+	Name              string            `json:"name"`
+	Status            string            `json:"status"`
+	EPGMapping        *epgMappingDTO    `json:"epg_mapping,omitempty"`
+	TranscodeAudio    string            `json:"transcode_audio,omitempty"`
+	TranscodeVideo    string            `json:"transcode_video,omitempty"`
+	Group             string            `json:"group,omitempty"`
+	Number            int               `json:"number,omitempty"`
+	TVGShiftMinutes   int               `json:"tvg_shift_minutes,omitempty"`
+	Aliases           []string          `json:"aliases,omitempty"`
+	StreamQualities   map[string]string `json:"stream_qualities,omitempty"`
+	QualityPreference []string          `json:"quality_preference,omitempty"`
+	Variants          string            `json:"variants,omitempty"`
+	Tags              []string          `json:"tags,omitempty"`
 }
 
 // epgMappingDTO is used for JSON serialization of EPG mapping data.
@@ -51,12 +68,31 @@ type epgMappingDTO struct {
 	EPGID      string `json:"epg_id"`
 	Source     string `json:"source"`
 	LastSynced string `json:"last_synced"`
+	// Confidence is nil for mappings stored before confidence was recorded
+	Confidence *float64 `json:"confidence,omitempty"`
 }
 
 func channelToDTO(ch channel.Channel) channelDTO {
 	dto := channelDTO{
-		Name:   ch.Name(),
-		Status: string(ch.Status()),
+		Name:            ch.Name(),
+		Status:          string(ch.Status()),
+		TranscodeAudio:  string(ch.AudioTranscode()),
+		TranscodeVideo:  string(ch.VideoTranscode()),
+		Group:           ch.Group(),
+		Number:          ch.Number(),
+		TVGShiftMinutes: int(ch.TVGShift() / time.Minute),
+		Aliases:         ch.Aliases(),
+		Variants:        string(ch.Variants()),
+		Tags:            ch.Tags(),
+	}
+	for infoHash, q := range ch.StreamQualities() {
+		if dto.StreamQualities == nil {
+			dto.StreamQualities = make(map[string]string)
+		}
+		dto.StreamQualities[infoHash] = string(q)
+	}
+	for _, q := range ch.QualityPreference() {
+		dto.QualityPreference = append(dto.QualityPreference, string(q))
 	}
 	if m := ch.EPGMapping(); m != nil {
 		dto.EPGMapping = &epgMappingDTO{
@@ -64,6 +100,9 @@ func channelToDTO(ch channel.Channel) channelDTO {
 			Source:     string(m.Source()),
 			LastSynced: m.LastSynced().Format(time.RFC3339),
 		}
+		if c := m.Confidence(); c < 1 {
+			dto.EPGMapping.Confidence = &c
+		}
 	}
 	return dto
 }
@@ -84,10 +123,76 @@ func dtoToChannel(dto channelDTO) (channel.Channel, error) {
 		if err != nil {
 			return channel.Channel{}, err
 		}
+		if dto.EPGMapping.Confidence != nil {
+			m = m.WithConfidence(*dto.EPGMapping.Confidence)
+		}
 		mapping = &m
 	}
 
-	return channel.ReconstructChannel(dto.Name, status, mapping), nil
+	ch := channel.ReconstructChannel(dto.Name, status, mapping)
+	ch.SetAudioTranscode(channel.AudioTranscode(dto.TranscodeAudio))
+	ch.SetVideoTranscode(channel.VideoTranscode(dto.TranscodeVideo))
+	ch.SetGroup(dto.Group)
+	if err := ch.SetNumber(dto.Number); err != nil {
+		return channel.Channel{}, err
+	}
+	if err := ch.SetTVGShift(time.Duration(dto.TVGShiftMinutes) * time.Minute); err != nil {
+		return channel.Channel{}, err
+	}
+	if err := ch.SetAliases(dto.Aliases); err != nil {
+		return channel.Channel{}, err
+	}
+	for infoHash, q := range dto.StreamQualities {
+		ch.SetStreamQuality(infoHash, channel.Quality(q))
+	}
+	var preference []channel.Quality
+	for _, q := range dto.QualityPreference {
+		preference = append(preference, channel.Quality(q))
+	}
+	ch.SetQualityPreference(preference)
+	ch.SetVariants(channel.Variants(dto.Variants))
+	if err := ch.SetTags(dto.Tags); err != nil {
+		return channel.Channel{}, err
+	}
+	return ch, nil
+}
+
+// indexTags moves the channel name from the index entries of its old tags
+// to those of its new ones.
+func indexTags(tx *bbolt.Tx, name string, oldTags, newTags []string) error {
+	index := tx.Bucket([]byte(channelTagsBucket))
+	if index == nil {
+		return errors.New("channel tags bucket not found")
+	}
+	for _, tag := range oldTags {
+		if slices.Contains(newTags, tag) {
+			continue
+		}
+		if b := index.Bucket([]byte(tag)); b != nil {
+			if err := b.Delete([]byte(name)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, tag := range newTags {
+		b, err := index.CreateBucketIfNotExists([]byte(tag))
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(name), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storedTags returns the tags of a stored channel record.
+func storedTags(data []byte) []string {
+	var dto channelDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil
+	}
+	return dto.Tags
 }
 
 // Save persists a channel to BoltDB.
@@ -115,7 +220,10 @@ func (r *ChannelBoltDBRepository) Save(ctx context.Context, ch channel.Channel)
 			return err
 		}
 
-		return bucket.Put(key, data)
+		if err := bucket.Put(key, data); err != nil {
+			return err
+		}
+		return indexTags(tx, ch.Name(), nil, ch.Tags())
 	})
 }
 
@@ -133,16 +241,21 @@ func (r *ChannelBoltDBRepository) Update(ctx context.Context, ch channel.Channel
 
 		key := []byte(ch.Name())
 
-		if bucket.Get(key) == nil {
+		previous := bucket.Get(key)
+		if previous == nil {
 			return channel.ErrChannelNotFound
 		}
+		oldTags := storedTags(previous)
 
 		data, err := json.Marshal(channelToDTO(ch))
 		if err != nil {
 			return err
 		}
 
-		return bucket.Put(key, data)
+		if err := bucket.Put(key, data); err != nil {
+			return err
+		}
+		return indexTags(tx, ch.Name(), oldTags, ch.Tags())
 	})
 }
 
@@ -225,6 +338,50 @@ func (r *ChannelBoltDBRepository) FindAll(ctx context.Context) ([]channel.Channe
 	return channels, nil
 }
 
+// FindByTag retrieves the channels tagged with tag from BoltDB, through the
+// tag index.
+func (r *ChannelBoltDBRepository) FindByTag(ctx context.Context, tag string) ([]channel.Channel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	channels := []channel.Channel{}
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(channelsBucket))
+		index := tx.Bucket([]byte(channelTagsBucket))
+		if bucket == nil || index == nil {
+			return errors.New("channels bucket not found")
+		}
+
+		tagged := index.Bucket([]byte(tag))
+		if tagged == nil {
+			return nil
+		}
+		return tagged.ForEach(func(name, _ []byte) error {
+			data := bucket.Get(name)
+			if data == nil {
+				return nil
+			}
+			var dto channelDTO
+			if err := json.Unmarshal(data, &dto); err != nil {
+				return err
+			}
+			ch, err := dtoToChannel(dto)
+			if err != nil {
+				return err
+			}
+			channels = append(channels, ch)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
 // Delete removes a channel by its name from BoltDB.
 func (r *ChannelBoltDBRepository) Delete(ctx context.Context, name string) error {
 	// Check context cancellation
@@ -241,9 +398,13 @@ func (r *ChannelBoltDBRepository) Delete(ctx context.Context, name string) error
 		key := []byte(name)
 
 		// Check if channel exists before deleting
-		if bucket.Get(key) == nil {
+		previous := bucket.Get(key)
+		if previous == nil {
 			return channel.ErrChannelNotFound
 		}
+		if err := indexTags(tx, name, storedTags(previous), nil); err != nil {
+			return err
+		}
 
 		return bucket.Delete(key)
 	})
@@ -4,7 +4,9 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
 	"go.etcd.io/bbolt"
 
@@ -99,6 +101,116 @@ func TestChannelBoltDBRepository_Save(t *testing.T) {
 		}
 	})
 
+	t.Run("persists the group assignment", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo, err := NewChannelBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+
+		ch, err := channel.NewChannel("HBO")
+		if err != nil {
+			t.Fatalf("failed to create channel: %v", err)
+		}
+		ch.SetGroup("movies")
+		_ = ch.SetNumber(12)
+		_ = ch.SetTVGShift(-time.Hour)
+		_ = ch.SetAliases([]string{"hbo", "hbo-es"})
+		ch.SetStreamQuality("hash1", channel.Quality1080p)
+		ch.SetStreamQuality("hash2", channel.QualitySD)
+		ch.SetQualityPreference([]channel.Quality{channel.QualitySD, channel.Quality1080p})
+		ch.SetVariants(channel.VariantsPreferred)
+
+		ctx := context.Background()
+		if err := repo.Save(ctx, ch); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByName(ctx, "HBO")
+		if err != nil {
+			t.Fatalf("failed to find saved channel: %v", err)
+		}
+		if found.Group() != "movies" {
+			t.Errorf("expected group 'movies', got %q", found.Group())
+		}
+		if found.Number() != 12 {
+			t.Errorf("expected number 12, got %d", found.Number())
+		}
+		if found.TVGShift() != -time.Hour {
+			t.Errorf("expected tvg-shift -1h, got %v", found.TVGShift())
+		}
+		if got := found.Aliases(); len(got) != 2 || got[0] != "hbo" || got[1] != "hbo-es" {
+			t.Errorf("expected aliases [hbo hbo-es], got %v", got)
+		}
+		if found.StreamQuality("hash1") != channel.Quality1080p || found.StreamQuality("hash2") != channel.QualitySD {
+			t.Errorf("expected stream qualities hash1=1080p hash2=SD, got %v", found.StreamQualities())
+		}
+		if got := found.QualityPreference(); len(got) != 2 || got[0] != channel.QualitySD {
+			t.Errorf("expected quality preference [SD 1080p], got %v", got)
+		}
+		if found.Variants() != channel.VariantsPreferred {
+			t.Errorf("expected variants preferred, got %q", found.Variants())
+		}
+	})
+
+	t.Run("persists the audio transcode setting", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo, err := NewChannelBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+
+		ch, err := channel.NewChannel("HBO")
+		if err != nil {
+			t.Fatalf("failed to create channel: %v", err)
+		}
+		ch.SetAudioTranscode(channel.AudioTranscodeAll)
+
+		ctx := context.Background()
+		if err := repo.Save(ctx, ch); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByName(ctx, "HBO")
+		if err != nil {
+			t.Fatalf("failed to find saved channel: %v", err)
+		}
+		if found.AudioTranscode() != channel.AudioTranscodeAll {
+			t.Errorf("expected audio transcode 'all', got %q", found.AudioTranscode())
+		}
+	})
+
+	t.Run("persists the confidence of an automatic mapping", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo, err := NewChannelBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+
+		mapping, _ := channel.NewEPGMapping("la1.es", channel.MappingAuto, time.Now())
+		ch, _ := channel.NewChannel("La 1 ES")
+		ch.SetEPGMapping(mapping.WithConfidence(0.83))
+
+		ctx := context.Background()
+		if err := repo.Save(ctx, ch); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByName(ctx, "La 1 ES")
+		if err != nil {
+			t.Fatalf("failed to find saved channel: %v", err)
+		}
+		if got := found.EPGMapping().Confidence(); got != 0.83 {
+			t.Errorf("expected confidence 0.83, got %v", got)
+		}
+	})
+
 	t.Run("returns ErrChannelAlreadyExists for duplicate channel", func(t *testing.T) {
 		db, cleanup := setupTestDB(t)
 		defer cleanup()
@@ -305,6 +417,68 @@ func TestChannelBoltDBRepository_FindAll(t *testing.T) {
 	})
 }
 
+func TestChannelBoltDBRepository_FindByTag(t *testing.T) {
+	t.Run("follows tag changes and deletes", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo, err := NewChannelBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+		ctx := context.Background()
+
+		for name, tags := range map[string][]string{
+			"Clan": {"kids", "spanish"},
+			"HBO":  {"4k"},
+			"TVE":  {"spanish"},
+		} {
+			ch, _ := channel.NewChannel(name)
+			if err := ch.SetTags(tags); err != nil {
+				t.Fatalf("failed to tag channel %q: %v", name, err)
+			}
+			if err := repo.Save(ctx, ch); err != nil {
+				t.Fatalf("failed to save channel %q: %v", name, err)
+			}
+		}
+
+		assertTagged := func(tag string, want ...string) {
+			t.Helper()
+			channels, err := repo.FindByTag(ctx, tag)
+			if err != nil {
+				t.Fatalf("FindByTag(%q) error = %v", tag, err)
+			}
+			got := []string{}
+			for _, ch := range channels {
+				got = append(got, ch.Name())
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("FindByTag(%q) = %v, want %v", tag, got, want)
+			}
+		}
+
+		assertTagged("spanish", "Clan", "TVE")
+		assertTagged("missing")
+
+		tve, _ := repo.FindByName(ctx, "TVE")
+		if !tve.HasTag("spanish") {
+			t.Errorf("expected the stored tags to be loaded, got %v", tve.Tags())
+		}
+		_ = tve.SetTags([]string{"news"})
+		if err := repo.Update(ctx, tve); err != nil {
+			t.Fatalf("failed to update channel: %v", err)
+		}
+		assertTagged("spanish", "Clan")
+		assertTagged("news", "TVE")
+
+		if err := repo.Delete(ctx, "Clan"); err != nil {
+			t.Fatalf("failed to delete channel: %v", err)
+		}
+		assertTagged("spanish")
+		assertTagged("kids")
+	})
+}
+
 func TestChannelBoltDBRepository_Delete(t *testing.T) {
 	t.Run("deletes existing channel successfully", func(t *testing.T) {
 		db, cleanup := setupTestDB(t)
@@ -0,0 +1,313 @@
+package driven
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+)
+
+// ChannelSQLiteRepository implements the ChannelRepository port using SQLite.
+type ChannelSQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewChannelSQLiteRepository creates a new SQLite-backed channel repository.
+// The database must have been opened with OpenSQLite so the schema exists.
+func NewChannelSQLiteRepository(db *sql.DB) (*ChannelSQLiteRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	return &ChannelSQLiteRepository{db: db}, nil
+}
+
+// channelColumns returns the column values stored for a channel, after its
+// name, leaving the EPG mapping columns NULL for unmapped channels. Aliases
+// and the quality preference are stored comma-separated, and stream quality
+// labels as comma-separated infohash=label pairs; none can contain a comma.
+func channelColumns(ch channel.Channel) []any {
+	var epgID, epgSource, epgLastSynced sql.NullString
+	epgConfidence := 1.0
+	if m := ch.EPGMapping(); m != nil {
+		epgID = sql.NullString{String: m.EPGID(), Valid: true}
+		epgSource = sql.NullString{String: string(m.Source()), Valid: true}
+		epgLastSynced = sql.NullString{String: m.LastSynced().Format(time.RFC3339), Valid: true}
+		epgConfidence = m.Confidence()
+	}
+	return []any{string(ch.Status()), epgID, epgSource, epgLastSynced, epgConfidence, string(ch.AudioTranscode()), ch.Group(), ch.Number(), strings.Join(ch.Aliases(), ","),
+		encodeStreamQualities(ch.StreamQualities()), encodeQualityPreference(ch.QualityPreference()), string(ch.Variants()), int(ch.TVGShift() / time.Minute),
+		string(ch.VideoTranscode())}
+}
+
+// channelSelect reads channels with their tags, which live in channel_tags
+// so that channels can be looked up by tag.
+const channelSelect = `SELECT name, status, epg_id, epg_source, epg_last_synced, epg_confidence, transcode_audio, group_id, number, aliases, stream_qualities, quality_preference, variants, tvg_shift_minutes, transcode_video,
+	COALESCE((SELECT group_concat(tag) FROM channel_tags WHERE channel_tags.channel_name = channels.name), '') FROM channels`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanChannel(row rowScanner) (channel.Channel, error) {
+	var name, status, transcodeAudio, transcodeVideo, groupID, aliases, qualities, preference, variants, tags string
+	var epgID, epgSource, epgLastSynced sql.NullString
+	var epgConfidence float64
+	var number, tvgShiftMinutes int
+	if err := row.Scan(&name, &status, &epgID, &epgSource, &epgLastSynced, &epgConfidence, &transcodeAudio, &groupID, &number, &aliases, &qualities, &preference, &variants, &tvgShiftMinutes, &transcodeVideo, &tags); err != nil {
+		return channel.Channel{}, err
+	}
+
+	var mapping *channel.EPGMapping
+	if epgID.Valid {
+		lastSynced, err := time.Parse(time.RFC3339, epgLastSynced.String)
+		if err != nil {
+			return channel.Channel{}, err
+		}
+		m, err := channel.NewEPGMapping(epgID.String, channel.MappingSource(epgSource.String), lastSynced)
+		if err != nil {
+			return channel.Channel{}, err
+		}
+		m = m.WithConfidence(epgConfidence)
+		mapping = &m
+	}
+
+	ch := channel.ReconstructChannel(name, channel.Status(status), mapping)
+	ch.SetAudioTranscode(channel.AudioTranscode(transcodeAudio))
+	ch.SetVideoTranscode(channel.VideoTranscode(transcodeVideo))
+	ch.SetGroup(groupID)
+	if err := ch.SetNumber(number); err != nil {
+		return channel.Channel{}, err
+	}
+	if err := ch.SetTVGShift(time.Duration(tvgShiftMinutes) * time.Minute); err != nil {
+		return channel.Channel{}, err
+	}
+	if aliases != "" {
+		if err := ch.SetAliases(strings.Split(aliases, ",")); err != nil {
+			return channel.Channel{}, err
+		}
+	}
+	for infoHash, q := range decodeStreamQualities(qualities) {
+		ch.SetStreamQuality(infoHash, q)
+	}
+	ch.SetQualityPreference(decodeQualityPreference(preference))
+	ch.SetVariants(channel.Variants(variants))
+	if tags != "" {
+		if err := ch.SetTags(strings.Split(tags, ",")); err != nil {
+			return channel.Channel{}, err
+		}
+	}
+	return ch, nil
+}
+
+// replaceTags replaces the tags stored for a channel within tx.
+func replaceTags(ctx context.Context, tx *sql.Tx, name string, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM channel_tags WHERE channel_name = ?`, name); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO channel_tags (tag, channel_name) VALUES (?, ?)`, tag, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeStreamQualities joins quality labels into infohash=label pairs,
+// sorted by infohash.
+func encodeStreamQualities(qualities map[string]channel.Quality) string {
+	pairs := make([]string, 0, len(qualities))
+	for infoHash, q := range qualities {
+		pairs = append(pairs, infoHash+"="+string(q))
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func decodeStreamQualities(s string) map[string]channel.Quality {
+	qualities := make(map[string]channel.Quality)
+	for _, pair := range strings.Split(s, ",") {
+		if infoHash, q, ok := strings.Cut(pair, "="); ok {
+			qualities[infoHash] = channel.Quality(q)
+		}
+	}
+	return qualities
+}
+
+func encodeQualityPreference(preference []channel.Quality) string {
+	labels := make([]string, len(preference))
+	for i, q := range preference {
+		labels[i] = string(q)
+	}
+	return strings.Join(labels, ",")
+}
+
+func decodeQualityPreference(s string) []channel.Quality {
+	if s == "" {
+		return nil
+	}
+	var preference []channel.Quality
+	for _, label := range strings.Split(s, ",") {
+		preference = append(preference, channel.Quality(label))
+	}
+	return preference
+}
+
+// Save persists a new channel to SQLite.
+// Returns ErrChannelAlreadyExists if a channel with the same name already exists.
+func (r *ChannelSQLiteRepository) Save(ctx context.Context, ch channel.Channel) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO channels (name, status, epg_id, epg_source, epg_last_synced, epg_confidence, transcode_audio, group_id, number, aliases, stream_qualities, quality_preference, variants, tvg_shift_minutes, transcode_video)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (name) DO NOTHING`,
+		append([]any{ch.Name()}, channelColumns(ch)...)...)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := rowsAffected(res)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		return channel.ErrChannelAlreadyExists
+	}
+	if err := replaceTags(ctx, tx, ch.Name(), ch.Tags()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Update replaces an existing channel in SQLite.
+// Returns ErrChannelNotFound if the channel doesn't exist.
+func (r *ChannelSQLiteRepository) Update(ctx context.Context, ch channel.Channel) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE channels SET status = ?, epg_id = ?, epg_source = ?, epg_last_synced = ?, epg_confidence = ?, transcode_audio = ?, group_id = ?, number = ?, aliases = ?,
+		stream_qualities = ?, quality_preference = ?, variants = ?, tvg_shift_minutes = ?, transcode_video = ?
+		WHERE name = ?`,
+		append(channelColumns(ch), ch.Name())...)
+	if err != nil {
+		return err
+	}
+
+	updated, err := rowsAffected(res)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return channel.ErrChannelNotFound
+	}
+	if err := replaceTags(ctx, tx, ch.Name(), ch.Tags()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindByName retrieves a channel by its name from SQLite.
+// Returns ErrChannelNotFound if the channel doesn't exist.
+func (r *ChannelSQLiteRepository) FindByName(ctx context.Context, name string) (channel.Channel, error) {
+	row := r.db.QueryRowContext(ctx, channelSelect+` WHERE name = ?`, name)
+
+	ch, err := scanChannel(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return channel.Channel{}, channel.ErrChannelNotFound
+	}
+	return ch, err
+}
+
+// FindAll retrieves all channels from SQLite, ordered by name.
+// Returns an empty slice if no channels exist.
+func (r *ChannelSQLiteRepository) FindAll(ctx context.Context) ([]channel.Channel, error) {
+	rows, err := r.db.QueryContext(ctx, channelSelect+` ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := []channel.Channel{}
+	for rows.Next() {
+		ch, err := scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, ch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// FindByTag retrieves the channels tagged with tag from SQLite, ordered by name.
+// Returns an empty slice if no channel has the tag.
+func (r *ChannelSQLiteRepository) FindByTag(ctx context.Context, tag string) ([]channel.Channel, error) {
+	rows, err := r.db.QueryContext(ctx, channelSelect+` WHERE name IN (SELECT channel_name FROM channel_tags WHERE tag = ?) ORDER BY name`, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := []channel.Channel{}
+	for rows.Next() {
+		ch, err := scanChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, ch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// Delete removes a channel by its name from SQLite.
+// Returns ErrChannelNotFound if the channel doesn't exist.
+func (r *ChannelSQLiteRepository) Delete(ctx context.Context, name string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM channels WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := rowsAffected(res)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return channel.ErrChannelNotFound
+	}
+	if err := replaceTags(ctx, tx, name, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Ping checks if the SQLite database is accessible and operational.
+func (r *ChannelSQLiteRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
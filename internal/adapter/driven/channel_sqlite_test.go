@@ -0,0 +1,399 @@
+package driven
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+)
+
+// setupTestSQLiteDB creates a temporary, fully migrated SQLite database for testing.
+func setupTestSQLiteDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestOpenSQLite(t *testing.T) {
+	t.Run("enables WAL and records the schema version", func(t *testing.T) {
+		db := setupTestSQLiteDB(t)
+
+		var mode string
+		if err := db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil {
+			t.Fatalf("failed to read journal mode: %v", err)
+		}
+		if mode != "wal" {
+			t.Errorf("expected journal mode 'wal', got %q", mode)
+		}
+
+		var version int
+		if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+			t.Fatalf("failed to read schema version: %v", err)
+		}
+		if version != len(sqliteMigrations) {
+			t.Errorf("expected schema version %d, got %d", len(sqliteMigrations), version)
+		}
+	})
+
+	t.Run("reopening an existing database keeps its data", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.sqlite")
+		ctx := context.Background()
+
+		db, err := OpenSQLite(ctx, path)
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		repo, _ := NewChannelSQLiteRepository(db)
+		ch, _ := channel.NewChannel("HBO")
+		if err := repo.Save(ctx, ch); err != nil {
+			t.Fatalf("failed to save channel: %v", err)
+		}
+		db.Close()
+
+		db, err = OpenSQLite(ctx, path)
+		if err != nil {
+			t.Fatalf("failed to reopen database: %v", err)
+		}
+		defer db.Close()
+
+		repo, _ = NewChannelSQLiteRepository(db)
+		if _, err := repo.FindByName(ctx, "HBO"); err != nil {
+			t.Errorf("expected channel to survive reopen, got %v", err)
+		}
+	})
+
+	t.Run("rejects a database from a newer version", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.sqlite")
+		ctx := context.Background()
+
+		db, err := OpenSQLite(ctx, path)
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		if _, err := db.Exec("PRAGMA user_version = 999"); err != nil {
+			t.Fatalf("failed to set schema version: %v", err)
+		}
+		db.Close()
+
+		if db, err := OpenSQLite(ctx, path); err == nil {
+			db.Close()
+			t.Error("expected error for unsupported schema version")
+		}
+	})
+}
+
+func TestNewChannelSQLiteRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewChannelSQLiteRepository(nil)
+		if err == nil {
+			t.Error("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestChannelSQLiteRepository_Save(t *testing.T) {
+	t.Run("saves a new channel with its EPG mapping", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+		ctx := context.Background()
+
+		synced := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		mapping, err := channel.NewEPGMapping("hbo.es", channel.MappingManual, synced)
+		if err != nil {
+			t.Fatalf("failed to create mapping: %v", err)
+		}
+		ch, _ := channel.NewChannel("HBO")
+		ch.SetEPGMapping(mapping)
+		ch.SetAudioTranscode(channel.AudioTranscodeAC3)
+		ch.SetGroup("movies")
+		_ = ch.SetNumber(12)
+		_ = ch.SetTVGShift(-time.Hour)
+		_ = ch.SetAliases([]string{"hbo", "hbo-es"})
+		ch.SetStreamQuality("hash1", channel.Quality1080p)
+		ch.SetStreamQuality("hash2", channel.QualitySD)
+		ch.SetQualityPreference([]channel.Quality{channel.QualitySD, channel.Quality1080p})
+		ch.SetVariants(channel.VariantsPreferred)
+
+		if err := repo.Save(ctx, ch); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByName(ctx, "HBO")
+		if err != nil {
+			t.Fatalf("failed to find saved channel: %v", err)
+		}
+		if found.Status() != channel.StatusActive {
+			t.Errorf("expected status active, got %q", found.Status())
+		}
+		if found.AudioTranscode() != channel.AudioTranscodeAC3 {
+			t.Errorf("expected audio transcode 'ac3', got %q", found.AudioTranscode())
+		}
+		if found.Group() != "movies" {
+			t.Errorf("expected group 'movies', got %q", found.Group())
+		}
+		if found.Number() != 12 {
+			t.Errorf("expected number 12, got %d", found.Number())
+		}
+		if found.TVGShift() != -time.Hour {
+			t.Errorf("expected tvg-shift -1h, got %v", found.TVGShift())
+		}
+		if got := found.Aliases(); len(got) != 2 || got[0] != "hbo" || got[1] != "hbo-es" {
+			t.Errorf("expected aliases [hbo hbo-es], got %v", got)
+		}
+		if found.StreamQuality("hash1") != channel.Quality1080p || found.StreamQuality("hash2") != channel.QualitySD {
+			t.Errorf("expected stream qualities hash1=1080p hash2=SD, got %v", found.StreamQualities())
+		}
+		if got := found.QualityPreference(); len(got) != 2 || got[0] != channel.QualitySD {
+			t.Errorf("expected quality preference [SD 1080p], got %v", got)
+		}
+		if found.Variants() != channel.VariantsPreferred {
+			t.Errorf("expected variants preferred, got %q", found.Variants())
+		}
+		m := found.EPGMapping()
+		if m == nil {
+			t.Fatal("expected EPG mapping to be persisted")
+		}
+		if m.EPGID() != "hbo.es" || m.Source() != channel.MappingManual || !m.LastSynced().Equal(synced) {
+			t.Errorf("unexpected mapping: %q %q %v", m.EPGID(), m.Source(), m.LastSynced())
+		}
+	})
+
+	t.Run("keeps the confidence of an automatic mapping", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+		ctx := context.Background()
+
+		mapping, _ := channel.NewEPGMapping("la1.es", channel.MappingAuto, time.Now())
+		ch, _ := channel.NewChannel("La 1 ES")
+		ch.SetEPGMapping(mapping.WithConfidence(0.83))
+		if err := repo.Save(ctx, ch); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByName(ctx, "La 1 ES")
+		if err != nil {
+			t.Fatalf("failed to find saved channel: %v", err)
+		}
+		if got := found.EPGMapping().Confidence(); got != 0.83 {
+			t.Errorf("expected confidence 0.83, got %v", got)
+		}
+	})
+
+	t.Run("returns ErrChannelAlreadyExists for duplicate channel", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+		ctx := context.Background()
+
+		ch, _ := channel.NewChannel("ESPN")
+		if err := repo.Save(ctx, ch); err != nil {
+			t.Fatalf("expected no error on first save, got %v", err)
+		}
+
+		if err := repo.Save(ctx, ch); err != channel.ErrChannelAlreadyExists {
+			t.Errorf("expected ErrChannelAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("respects context cancellation", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		ch, _ := channel.NewChannel("CNN")
+		if err := repo.Save(ctx, ch); err == nil {
+			t.Error("expected error for cancelled context")
+		}
+	})
+}
+
+func TestChannelSQLiteRepository_Update(t *testing.T) {
+	t.Run("updates status and clears mapping", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+		ctx := context.Background()
+
+		mapping, _ := channel.NewEPGMapping("hbo.es", channel.MappingAuto, time.Now())
+		ch, _ := channel.NewChannel("HBO")
+		ch.SetEPGMapping(mapping)
+		if err := repo.Save(ctx, ch); err != nil {
+			t.Fatalf("failed to save channel: %v", err)
+		}
+
+		if err := repo.Update(ctx, channel.ReconstructChannel("HBO", channel.StatusArchived, nil)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByName(ctx, "HBO")
+		if err != nil {
+			t.Fatalf("failed to find channel: %v", err)
+		}
+		if found.Status() != channel.StatusArchived {
+			t.Errorf("expected status archived, got %q", found.Status())
+		}
+		if found.EPGMapping() != nil {
+			t.Error("expected EPG mapping to be cleared")
+		}
+	})
+
+	t.Run("returns ErrChannelNotFound for non-existent channel", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+
+		ch, _ := channel.NewChannel("Missing")
+		if err := repo.Update(context.Background(), ch); err != channel.ErrChannelNotFound {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+	})
+}
+
+func TestChannelSQLiteRepository_FindByName(t *testing.T) {
+	t.Run("returns ErrChannelNotFound for non-existent channel", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+
+		if _, err := repo.FindByName(context.Background(), "Missing"); err != channel.ErrChannelNotFound {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+	})
+}
+
+func TestChannelSQLiteRepository_FindAll(t *testing.T) {
+	t.Run("returns empty slice when no channels exist", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+
+		channels, err := repo.FindAll(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if channels == nil || len(channels) != 0 {
+			t.Errorf("expected empty non-nil slice, got %v", channels)
+		}
+	})
+
+	t.Run("returns all saved channels ordered by name", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+		ctx := context.Background()
+
+		for _, name := range []string{"HBO", "CNN", "ESPN"} {
+			ch, _ := channel.NewChannel(name)
+			if err := repo.Save(ctx, ch); err != nil {
+				t.Fatalf("failed to save channel %q: %v", name, err)
+			}
+		}
+
+		channels, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		want := []string{"CNN", "ESPN", "HBO"}
+		if len(channels) != len(want) {
+			t.Fatalf("expected %d channels, got %d", len(want), len(channels))
+		}
+		for i, ch := range channels {
+			if ch.Name() != want[i] {
+				t.Errorf("channels[%d]: expected %q, got %q", i, want[i], ch.Name())
+			}
+		}
+	})
+}
+
+func TestChannelSQLiteRepository_FindByTag(t *testing.T) {
+	t.Run("follows tag changes and deletes", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+		ctx := context.Background()
+
+		for name, tags := range map[string][]string{
+			"Clan": {"kids", "spanish"},
+			"HBO":  {"4k"},
+			"TVE":  {"spanish"},
+		} {
+			ch, _ := channel.NewChannel(name)
+			if err := ch.SetTags(tags); err != nil {
+				t.Fatalf("failed to tag channel %q: %v", name, err)
+			}
+			if err := repo.Save(ctx, ch); err != nil {
+				t.Fatalf("failed to save channel %q: %v", name, err)
+			}
+		}
+
+		assertTagged := func(tag string, want ...string) {
+			t.Helper()
+			channels, err := repo.FindByTag(ctx, tag)
+			if err != nil {
+				t.Fatalf("FindByTag(%q) error = %v", tag, err)
+			}
+			got := []string{}
+			for _, ch := range channels {
+				got = append(got, ch.Name())
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("FindByTag(%q) = %v, want %v", tag, got, want)
+			}
+		}
+
+		assertTagged("spanish", "Clan", "TVE")
+		assertTagged("missing")
+
+		tve, _ := repo.FindByName(ctx, "TVE")
+		if !tve.HasTag("spanish") {
+			t.Errorf("expected the stored tags to be loaded, got %v", tve.Tags())
+		}
+		_ = tve.SetTags([]string{"news"})
+		if err := repo.Update(ctx, tve); err != nil {
+			t.Fatalf("failed to update channel: %v", err)
+		}
+		assertTagged("spanish", "Clan")
+		assertTagged("news", "TVE")
+
+		if err := repo.Delete(ctx, "Clan"); err != nil {
+			t.Fatalf("failed to delete channel: %v", err)
+		}
+		assertTagged("spanish")
+		assertTagged("kids")
+	})
+}
+
+func TestChannelSQLiteRepository_Delete(t *testing.T) {
+	t.Run("deletes existing channel successfully", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+		ctx := context.Background()
+
+		ch, _ := channel.NewChannel("HBO")
+		if err := repo.Save(ctx, ch); err != nil {
+			t.Fatalf("failed to save channel: %v", err)
+		}
+
+		if err := repo.Delete(ctx, "HBO"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := repo.FindByName(ctx, "HBO"); err != channel.ErrChannelNotFound {
+			t.Errorf("expected ErrChannelNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrChannelNotFound for non-existent channel", func(t *testing.T) {
+		repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+
+		if err := repo.Delete(context.Background(), "Missing"); err != channel.ErrChannelNotFound {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+	})
+}
+
+func TestChannelSQLiteRepository_Ping(t *testing.T) {
+	repo, _ := NewChannelSQLiteRepository(setupTestSQLiteDB(t))
+
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
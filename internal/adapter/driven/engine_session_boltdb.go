@@ -0,0 +1,129 @@
+package driven
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+const engineSessionsBucket = "engine_sessions"
+
+// EngineSessionBoltDBRepository implements the EngineSessionRepository port
+// using BoltDB.
+type EngineSessionBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewEngineSessionBoltDBRepository creates a new BoltDB-backed engine session
+// repository. It initializes the required bucket if it doesn't exist.
+func NewEngineSessionBoltDBRepository(db *bbolt.DB) (*EngineSessionBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(engineSessionsBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &EngineSessionBoltDBRepository{db: db}, nil
+}
+
+// engineSessionDTO is used for JSON serialization.
+type engineSessionDTO struct {
+	PID       string `json:"pid"`
+	Key       string `json:"key"`
+	InfoHash  string `json:"infohash"`
+	Handle    string `json:"handle,omitempty"`
+	StartedAt int64  `json:"started_at"`
+}
+
+// Save persists an engine session to BoltDB, replacing any with the same PID.
+func (r *EngineSessionBoltDBRepository) Save(ctx context.Context, s driven.EngineSession) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(engineSessionsBucket))
+		if bucket == nil {
+			return errors.New("engine sessions bucket not found")
+		}
+
+		data, err := json.Marshal(engineSessionDTO{
+			PID:       s.PID,
+			Key:       s.Key,
+			InfoHash:  s.InfoHash,
+			Handle:    s.Handle,
+			StartedAt: s.StartedAt.UnixNano(),
+		})
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(s.PID), data)
+	})
+}
+
+// FindAll retrieves all engine sessions from BoltDB, oldest first.
+func (r *EngineSessionBoltDBRepository) FindAll(ctx context.Context) ([]driven.EngineSession, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sessions := []driven.EngineSession{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(engineSessionsBucket))
+		if bucket == nil {
+			return errors.New("engine sessions bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var dto engineSessionDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			sessions = append(sessions, driven.EngineSession{
+				PID:       dto.PID,
+				Key:       dto.Key,
+				InfoHash:  dto.InfoHash,
+				Handle:    dto.Handle,
+				StartedAt: time.Unix(0, dto.StartedAt),
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(sessions, func(a, b driven.EngineSession) int {
+		return cmp.Compare(a.StartedAt.UnixNano(), b.StartedAt.UnixNano())
+	})
+	return sessions, nil
+}
+
+// Delete removes the engine session with the given PID from BoltDB.
+func (r *EngineSessionBoltDBRepository) Delete(ctx context.Context, pid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(engineSessionsBucket))
+		if bucket == nil {
+			return errors.New("engine sessions bucket not found")
+		}
+		return bucket.Delete([]byte(pid))
+	})
+}
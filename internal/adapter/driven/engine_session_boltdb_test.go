@@ -0,0 +1,64 @@
+package driven
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	port "github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+func TestNewEngineSessionBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewEngineSessionBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestEngineSessionBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	repo, err := NewEngineSessionBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	for _, s := range []port.EngineSession{
+		{PID: "pid-2", Key: "hash-2", InfoHash: "hash-2", StartedAt: now.Add(time.Minute)},
+		{PID: "pid-1", Key: "hash-1+transcode_audio=1", InfoHash: "hash-1", Handle: `{"stat_url":"x"}`, StartedAt: now},
+	} {
+		if err := repo.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	all, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].PID != "pid-1" || all[1].PID != "pid-2" {
+		t.Fatalf("expected sessions oldest first, got %+v", all)
+	}
+	if got := all[0]; got.Key != "hash-1+transcode_audio=1" || got.InfoHash != "hash-1" ||
+		got.Handle != `{"stat_url":"x"}` || !got.StartedAt.Equal(now) {
+		t.Errorf("unexpected session %+v", got)
+	}
+
+	if err := repo.Delete(ctx, "pid-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(ctx, "pid-404"); err != nil {
+		t.Errorf("expected deleting a missing session to succeed, got %v", err)
+	}
+	if all, _ := repo.FindAll(ctx); len(all) != 1 || all[0].PID != "pid-2" {
+		t.Errorf("expected only pid-2 left, got %+v", all)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 
@@ -21,6 +20,7 @@ const (
 type EPGXMLFetcher struct {
 	url    string
 	client *http.Client
+	cache  *HTTPFileCache
 }
 
 // NewEPGXMLFetcher creates a new EPG XML fetcher with the given URL.
@@ -41,33 +41,19 @@ func NewEPGXMLFetcher(url string, client *http.Client) *EPGXMLFetcher {
 	}
 }
 
+// SetCache keeps the downloaded guide in cache, so that later fetches are
+// conditional GETs that skip the download when the guide has not changed.
+func (f *EPGXMLFetcher) SetCache(cache *HTTPFileCache) {
+	f.cache = cache
+}
+
 // FetchEPG retrieves EPG channel data from the configured XML source.
 // It fetches the XML file via HTTP, parses it, and returns domain EPG channels.
 // Returns an error if the HTTP request fails, the XML is malformed, or domain validation fails.
 func (f *EPGXMLFetcher) FetchEPG(ctx context.Context) ([]epg.Channel, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating HTTP request: %w", err)
-	}
-
-	resp, err := f.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("fetching EPG XML: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected HTTP status: %d %s", resp.StatusCode, resp.Status)
-	}
-
-	body, err := io.ReadAll(resp.Body)
+	tv, err := f.fetch(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("reading response body: %w", err)
-	}
-
-	var tv tvXML
-	if err := xml.Unmarshal(body, &tv); err != nil {
-		return nil, fmt.Errorf("parsing EPG XML: %w", err)
+		return nil, err
 	}
 
 	channels := make([]epg.Channel, 0, len(tv.Channels))
@@ -97,10 +83,67 @@ func (f *EPGXMLFetcher) FetchEPG(ctx context.Context) ([]epg.Channel, error) {
 	return channels, nil
 }
 
+// FetchProgrammes retrieves the programme guide from the configured XML
+// source. Programmes with unparsable or inconsistent times are skipped, so a
+// few bad entries do not hide the rest of the guide.
+// Returns an error if the HTTP request fails or the XML is malformed.
+func (f *EPGXMLFetcher) FetchProgrammes(ctx context.Context) ([]epg.Programme, error) {
+	tv, err := f.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	programmes := make([]epg.Programme, 0, len(tv.Programmes))
+	for _, p := range tv.Programmes {
+		start, err := time.Parse(xmltvTimeLayout, p.Start)
+		if err != nil {
+			continue
+		}
+		stop, err := time.Parse(xmltvTimeLayout, p.Stop)
+		if err != nil {
+			continue
+		}
+		title := ""
+		if len(p.Titles) > 0 {
+			title = p.Titles[0]
+		}
+		programme, err := epg.NewProgramme(p.Channel, title, start, stop)
+		if err != nil {
+			continue
+		}
+		programmes = append(programmes, programme)
+	}
+
+	return programmes, nil
+}
+
+// fetch downloads and parses the XML source.
+func (f *EPGXMLFetcher) fetch(ctx context.Context) (tvXML, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return tvXML{}, fmt.Errorf("creating HTTP request: %w", err)
+	}
+
+	body, err := f.cache.Fetch(f.client, req, 0)
+	if err != nil {
+		return tvXML{}, fmt.Errorf("fetching EPG XML: %w", err)
+	}
+
+	var tv tvXML
+	if err := xml.Unmarshal(body, &tv); err != nil {
+		return tvXML{}, fmt.Errorf("parsing EPG XML: %w", err)
+	}
+	return tv, nil
+}
+
+// xmltvTimeLayout is the layout of programme start and stop times in XMLTV.
+const xmltvTimeLayout = "20060102150405 -0700"
+
 // tvXML represents the root element of the EPG XML file.
 type tvXML struct {
-	XMLName  xml.Name     `xml:"tv"`
-	Channels []channelXML `xml:"channel"`
+	XMLName    xml.Name       `xml:"tv"`
+	Channels   []channelXML   `xml:"channel"`
+	Programmes []programmeXML `xml:"programme"`
 }
 
 // channelXML represents a channel element in the EPG XML.
@@ -114,3 +157,11 @@ type channelXML struct {
 type iconXML struct {
 	Src string `xml:"src,attr"`
 }
+
+// programmeXML represents a programme element in the EPG XML.
+type programmeXML struct {
+	Channel string   `xml:"channel,attr"`
+	Start   string   `xml:"start,attr"`
+	Stop    string   `xml:"stop,attr"`
+	Titles  []string `xml:"title"`
+}
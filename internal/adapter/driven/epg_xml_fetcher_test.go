@@ -370,3 +370,48 @@ func TestEPGXMLFetcher_FetchEPG(t *testing.T) {
 		}
 	})
 }
+
+func TestEPGXMLFetcher_FetchProgrammes(t *testing.T) {
+	xmlData := `<?xml version="1.0" encoding="UTF-8"?>
+<tv>
+	<channel id="sport-1"><display-name>Sport 1</display-name></channel>
+	<programme start="20240501200000 +0200" stop="20240501220000 +0200" channel="sport-1">
+		<title lang="es">Final</title>
+		<title lang="en">Final (EN)</title>
+	</programme>
+	<programme start="not a time" stop="20240501220000 +0200" channel="sport-1">
+		<title>Bad start</title>
+	</programme>
+	<programme start="20240501220000 +0200" stop="20240501200000 +0200" channel="sport-1">
+		<title>Stops before it starts</title>
+	</programme>
+</tv>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(xmlData))
+	}))
+	defer server.Close()
+
+	programmes, err := NewEPGXMLFetcher(server.URL, nil).FetchProgrammes(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(programmes) != 1 {
+		t.Fatalf("expected 1 programme, got %d", len(programmes))
+	}
+
+	p := programmes[0]
+	if p.ChannelID() != "sport-1" {
+		t.Errorf("expected channel 'sport-1', got %q", p.ChannelID())
+	}
+	if p.Title() != "Final" {
+		t.Errorf("expected the first title, got %q", p.Title())
+	}
+	wantStart := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+	if !p.Start().Equal(wantStart) {
+		t.Errorf("expected start %v, got %v", wantStart, p.Start())
+	}
+	if !p.Stop().Equal(wantStart.Add(2 * time.Hour)) {
+		t.Errorf("expected stop %v, got %v", wantStart.Add(2*time.Hour), p.Stop())
+	}
+}
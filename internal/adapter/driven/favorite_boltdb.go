@@ -0,0 +1,202 @@
+package driven
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/favorite"
+)
+
+const (
+	favoritesBucket = "favorites"
+)
+
+// FavoriteBoltDBRepository implements the FavoriteRepository port using BoltDB.
+type FavoriteBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewFavoriteBoltDBRepository creates a new BoltDB-backed favorite list repository.
+// It initializes the required bucket if it doesn't exist.
+func NewFavoriteBoltDBRepository(db *bbolt.DB) (*FavoriteBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	// Create the favorites bucket if it doesn't exist
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(favoritesBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &FavoriteBoltDBRepository{db: db}, nil
+}
+
+// favoriteDTO is used for JSON serialization.
+type favoriteDTO struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"`
+	Guide    bool     `json:"guide"`
+}
+
+func favoriteToDTO(l favorite.List) favoriteDTO {
+	return favoriteDTO{
+		ID:       l.ID(),
+		Name:     l.Name(),
+		Channels: l.Channels(),
+		Guide:    l.HasGuide(),
+	}
+}
+
+func dtoToFavorite(dto favoriteDTO) favorite.List {
+	return favorite.ReconstructList(dto.ID, dto.Name, dto.Channels, dto.Guide)
+}
+
+// Save persists a new list to BoltDB.
+func (r *FavoriteBoltDBRepository) Save(ctx context.Context, l favorite.List) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(favoritesBucket))
+		if bucket == nil {
+			return errors.New("favorites bucket not found")
+		}
+
+		key := []byte(l.ID())
+
+		if bucket.Get(key) != nil {
+			return favorite.ErrListAlreadyExists
+		}
+
+		data, err := json.Marshal(favoriteToDTO(l))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, data)
+	})
+}
+
+// Update persists changes to an existing list in BoltDB.
+func (r *FavoriteBoltDBRepository) Update(ctx context.Context, l favorite.List) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(favoritesBucket))
+		if bucket == nil {
+			return errors.New("favorites bucket not found")
+		}
+
+		key := []byte(l.ID())
+
+		if bucket.Get(key) == nil {
+			return favorite.ErrListNotFound
+		}
+
+		data, err := json.Marshal(favoriteToDTO(l))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, data)
+	})
+}
+
+// FindAll retrieves all lists from BoltDB, ordered by name.
+func (r *FavoriteBoltDBRepository) FindAll(ctx context.Context) ([]favorite.List, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	lists := []favorite.List{}
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(favoritesBucket))
+		if bucket == nil {
+			return errors.New("favorites bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var dto favoriteDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			lists = append(lists, dtoToFavorite(dto))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(lists, func(a, b favorite.List) int {
+		return cmp.Compare(a.Name(), b.Name())
+	})
+	return lists, nil
+}
+
+// FindByID retrieves a list by its ID from BoltDB.
+func (r *FavoriteBoltDBRepository) FindByID(ctx context.Context, id string) (favorite.List, error) {
+	if err := ctx.Err(); err != nil {
+		return favorite.List{}, err
+	}
+
+	var l favorite.List
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(favoritesBucket))
+		if bucket == nil {
+			return errors.New("favorites bucket not found")
+		}
+
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return favorite.ErrListNotFound
+		}
+
+		var dto favoriteDTO
+		if err := json.Unmarshal(data, &dto); err != nil {
+			return err
+		}
+
+		l = dtoToFavorite(dto)
+		return nil
+	})
+
+	return l, err
+}
+
+// Delete removes a list by its ID from BoltDB.
+func (r *FavoriteBoltDBRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(favoritesBucket))
+		if bucket == nil {
+			return errors.New("favorites bucket not found")
+		}
+
+		key := []byte(id)
+
+		if bucket.Get(key) == nil {
+			return favorite.ErrListNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
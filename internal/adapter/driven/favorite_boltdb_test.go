@@ -0,0 +1,121 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/favorite"
+)
+
+func TestNewFavoriteBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewFavoriteBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestFavoriteBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+
+	newRepo := func(t *testing.T) *FavoriteBoltDBRepository {
+		db, cleanup := setupTestDB(t)
+		t.Cleanup(cleanup)
+		repo, err := NewFavoriteBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+		return repo
+	}
+
+	t.Run("saves and finds a list", func(t *testing.T) {
+		repo := newRepo(t)
+		living, _ := favorite.NewList("Living room TV")
+		_ = living.SetChannels([]string{"Sports", "News"})
+		living.SetGuide(true)
+
+		if err := repo.Save(ctx, living); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		found, err := repo.FindByID(ctx, "living-room-tv")
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		if found.Name() != living.Name() || !slices.Equal(found.Channels(), living.Channels()) || !found.HasGuide() {
+			t.Errorf("FindByID() = %+v, want %+v", found, living)
+		}
+	})
+
+	t.Run("returns ErrListAlreadyExists for a duplicate", func(t *testing.T) {
+		repo := newRepo(t)
+		kids, _ := favorite.NewList("Kids")
+		_ = repo.Save(ctx, kids)
+
+		if err := repo.Save(ctx, kids); !errors.Is(err, favorite.ErrListAlreadyExists) {
+			t.Errorf("Save() error = %v, want ErrListAlreadyExists", err)
+		}
+	})
+
+	t.Run("updates a list", func(t *testing.T) {
+		repo := newRepo(t)
+		kids, _ := favorite.NewList("Kids")
+		_ = repo.Save(ctx, kids)
+
+		_ = kids.SetChannels([]string{"Cartoons"})
+		if err := repo.Update(ctx, kids); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		found, _ := repo.FindByID(ctx, "kids")
+		if !slices.Equal(found.Channels(), []string{"Cartoons"}) {
+			t.Errorf("expected updated channels, got %v", found.Channels())
+		}
+
+		missing, _ := favorite.NewList("Missing")
+		if err := repo.Update(ctx, missing); !errors.Is(err, favorite.ErrListNotFound) {
+			t.Errorf("Update() error = %v, want ErrListNotFound", err)
+		}
+	})
+
+	t.Run("lists all lists by name", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, name := range []string{"Kids tablet", "Bedroom", "Living room"} {
+			l, _ := favorite.NewList(name)
+			_ = repo.Save(ctx, l)
+		}
+
+		lists, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll() error = %v", err)
+		}
+		var names []string
+		for _, l := range lists {
+			names = append(names, l.Name())
+		}
+		if want := []string{"Bedroom", "Kids tablet", "Living room"}; !slices.Equal(names, want) {
+			t.Errorf("FindAll() = %v, want %v", names, want)
+		}
+	})
+
+	t.Run("deletes a list", func(t *testing.T) {
+		repo := newRepo(t)
+		kids, _ := favorite.NewList("Kids")
+		_ = repo.Save(ctx, kids)
+
+		if err := repo.Delete(ctx, "kids"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.FindByID(ctx, "kids"); !errors.Is(err, favorite.ErrListNotFound) {
+			t.Errorf("FindByID() error = %v, want ErrListNotFound", err)
+		}
+		if err := repo.Delete(ctx, "kids"); !errors.Is(err, favorite.ErrListNotFound) {
+			t.Errorf("Delete() error = %v, want ErrListNotFound", err)
+		}
+	})
+}
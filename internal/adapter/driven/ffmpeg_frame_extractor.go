@@ -0,0 +1,64 @@
+package driven
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ffmpegWaitDelay bounds how long ffmpeg's stdin copy may linger once the
+// process has exited, since the stream it reads from never ends on its own.
+const ffmpegWaitDelay = time.Second
+
+// FFmpegFrameExtractor extracts frames by running ffmpeg.
+// It implements the driven.FrameExtractor port.
+type FFmpegFrameExtractor struct {
+	path string
+}
+
+// NewFFmpegFrameExtractor creates a frame extractor running the ffmpeg
+// binary at path, looked up in PATH if it has no slashes.
+// Returns an error if the binary cannot be found.
+func NewFFmpegFrameExtractor(path string) (*FFmpegFrameExtractor, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	return &FFmpegFrameExtractor{path: resolved}, nil
+}
+
+// ExtractFrame pipes r into ffmpeg and returns the first video frame it
+// decodes as a JPEG.
+func (e *FFmpegFrameExtractor) ExtractFrame(ctx context.Context, r io.Reader) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, e.path,
+		"-hide_banner", "-loglevel", "error",
+		"-f", "mpegts", "-i", "pipe:0",
+		"-frames:v", "1", "-q:v", "4",
+		"-f", "image2", "-c:v", "mjpeg", "pipe:1")
+	cmd.Stdin = r
+	cmd.WaitDelay = ffmpegWaitDelay
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if stdout.Len() > 0 && (err == nil || errors.Is(err, exec.ErrWaitDelay)) {
+		return stdout.Bytes(), nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return nil, fmt.Errorf("ffmpeg: %s", msg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("running ffmpeg: %w", err)
+	}
+	return nil, errors.New("ffmpeg produced no frame")
+}
@@ -0,0 +1,71 @@
+package driven
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeFFmpeg writes a shell script standing in for ffmpeg and returns its path.
+func fakeFFmpeg(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on windows")
+	}
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake ffmpeg: %v", err)
+	}
+	return path
+}
+
+// endlessReader is a stream that never ends, like a live channel.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0x47
+	}
+	return len(p), nil
+}
+
+func TestNewFFmpegFrameExtractor(t *testing.T) {
+	if _, err := NewFFmpegFrameExtractor(filepath.Join(t.TempDir(), "missing-ffmpeg")); err == nil {
+		t.Error("expected error for a missing binary")
+	}
+}
+
+func TestFFmpegFrameExtractor_ExtractFrame(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns the frame without reading the whole stream", func(t *testing.T) {
+		extractor, err := NewFFmpegFrameExtractor(fakeFFmpeg(t, `head -c 1000 >/dev/null; printf 'JPEG'`))
+		if err != nil {
+			t.Fatalf("NewFFmpegFrameExtractor() error = %v", err)
+		}
+
+		frame, err := extractor.ExtractFrame(ctx, endlessReader{})
+		if err != nil {
+			t.Fatalf("ExtractFrame() error = %v", err)
+		}
+		if string(frame) != "JPEG" {
+			t.Errorf("ExtractFrame() = %q, want JPEG", frame)
+		}
+	})
+
+	t.Run("reports ffmpeg errors", func(t *testing.T) {
+		extractor, err := NewFFmpegFrameExtractor(fakeFFmpeg(t, `cat >/dev/null; echo 'pipe:0: Invalid data found' >&2; exit 1`))
+		if err != nil {
+			t.Fatalf("NewFFmpegFrameExtractor() error = %v", err)
+		}
+
+		_, err = extractor.ExtractFrame(ctx, io.LimitReader(endlessReader{}, 1000))
+		if err == nil || !strings.Contains(err.Error(), "Invalid data found") {
+			t.Errorf("expected the ffmpeg error, got %v", err)
+		}
+	})
+}
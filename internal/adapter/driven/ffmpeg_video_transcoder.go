@@ -0,0 +1,107 @@
+package driven
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// FFmpegVideoTranscoder downscales video by running ffmpeg.
+// It implements the driven.VideoTranscoder port.
+type FFmpegVideoTranscoder struct {
+	path string
+}
+
+// NewFFmpegVideoTranscoder creates a video transcoder running the ffmpeg
+// binary at path, looked up in PATH if it has no slashes.
+// Returns an error if the binary cannot be found.
+func NewFFmpegVideoTranscoder(path string) (*FFmpegVideoTranscoder, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	return &FFmpegVideoTranscoder{path: resolved}, nil
+}
+
+// Transcode starts ffmpeg scaling the video down to height lines, keeping
+// its aspect ratio, and copying the audio as is. Video already shorter than
+// height keeps its size.
+func (t *FFmpegVideoTranscoder) Transcode(ctx context.Context, dst io.Writer, height int) (io.WriteCloser, error) {
+	if height <= 0 {
+		return nil, fmt.Errorf("invalid video height %d", height)
+	}
+
+	cmd := exec.CommandContext(ctx, t.path,
+		"-hide_banner", "-loglevel", "error",
+		"-f", "mpegts", "-i", "pipe:0",
+		"-map", "0",
+		"-vf", fmt.Sprintf(`scale=-2:min(%d\,ih)`, height),
+		"-c:v", "libx264", "-preset", "veryfast", "-tune", "zerolatency",
+		"-c:a", "copy",
+		"-f", "mpegts", "pipe:1")
+	cmd.Stdout = dst
+	cmd.WaitDelay = ffmpegWaitDelay
+
+	p := &ffmpegProcess{ctx: ctx, cmd: cmd}
+	cmd.Stderr = &p.stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	p.stdin = stdin
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("running ffmpeg: %w", err)
+	}
+	return p, nil
+}
+
+// ffmpegProcess feeds a running ffmpeg through its standard input.
+type ffmpegProcess struct {
+	ctx    context.Context
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr bytes.Buffer
+
+	waitOnce sync.Once
+	waitErr  error
+}
+
+func (p *ffmpegProcess) Write(b []byte) (int, error) {
+	n, err := p.stdin.Write(b)
+	if err != nil {
+		// ffmpeg exiting early closes its input; report why it exited
+		if waitErr := p.wait(); waitErr != nil {
+			return n, waitErr
+		}
+		return n, err
+	}
+	return n, nil
+}
+
+// Close ends ffmpeg's input and waits for it to flush its output.
+func (p *ffmpegProcess) Close() error {
+	p.stdin.Close()
+	return p.wait()
+}
+
+// wait waits for ffmpeg to exit, once, and returns why it failed.
+func (p *ffmpegProcess) wait() error {
+	p.waitOnce.Do(func() {
+		err := p.cmd.Wait()
+		if err == nil || errors.Is(err, exec.ErrWaitDelay) || p.ctx.Err() != nil {
+			return
+		}
+		if msg := strings.TrimSpace(p.stderr.String()); msg != "" {
+			p.waitErr = fmt.Errorf("ffmpeg: %s", msg)
+		} else {
+			p.waitErr = fmt.Errorf("running ffmpeg: %w", err)
+		}
+	})
+	return p.waitErr
+}
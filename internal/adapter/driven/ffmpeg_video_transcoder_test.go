@@ -0,0 +1,59 @@
+package driven
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFFmpegVideoTranscoder_Transcode(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pipes the stream through ffmpeg", func(t *testing.T) {
+		transcoder, err := NewFFmpegVideoTranscoder(fakeFFmpeg(t, `tr a-z A-Z`))
+		if err != nil {
+			t.Fatalf("NewFFmpegVideoTranscoder() error = %v", err)
+		}
+
+		var out bytes.Buffer
+		w, err := transcoder.Transcode(ctx, &out, 720)
+		if err != nil {
+			t.Fatalf("Transcode() error = %v", err)
+		}
+		if _, err := w.Write([]byte("stream")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if out.String() != "STREAM" {
+			t.Errorf("output = %q, want STREAM", out.String())
+		}
+	})
+
+	t.Run("reports ffmpeg errors", func(t *testing.T) {
+		transcoder, err := NewFFmpegVideoTranscoder(fakeFFmpeg(t, `echo 'Unknown encoder libx264' >&2; exit 1`))
+		if err != nil {
+			t.Fatalf("NewFFmpegVideoTranscoder() error = %v", err)
+		}
+
+		w, err := transcoder.Transcode(ctx, &bytes.Buffer{}, 720)
+		if err != nil {
+			t.Fatalf("Transcode() error = %v", err)
+		}
+		if err := w.Close(); err == nil || !strings.Contains(err.Error(), "Unknown encoder") {
+			t.Errorf("expected the ffmpeg error, got %v", err)
+		}
+	})
+
+	t.Run("rejects an invalid height", func(t *testing.T) {
+		transcoder, err := NewFFmpegVideoTranscoder(fakeFFmpeg(t, `cat`))
+		if err != nil {
+			t.Fatalf("NewFFmpegVideoTranscoder() error = %v", err)
+		}
+		if _, err := transcoder.Transcode(ctx, &bytes.Buffer{}, 0); err == nil {
+			t.Error("expected error for a zero height")
+		}
+	})
+}
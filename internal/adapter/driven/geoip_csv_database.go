@@ -0,0 +1,131 @@
+package driven
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// geoIPRange is a range of addresses located in one country.
+type geoIPRange struct {
+	first, last netip.Addr
+	country     string
+}
+
+// GeoIPCSVDatabase implements the GeoIPLocator port from a CSV database held
+// in memory. Each row is either "first,last,country", as in the DB-IP
+// country lite database, or "network,country" with a CIDR range. A header
+// row, lines starting with # and extra columns are ignored.
+type GeoIPCSVDatabase struct {
+	ranges []geoIPRange
+}
+
+// NewGeoIPCSVDatabase loads the CSV database at path.
+func NewGeoIPCSVDatabase(path string) (*GeoIPCSVDatabase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseGeoIPCSV(f)
+}
+
+// parseGeoIPCSV reads the ranges of a CSV database, sorted by first address.
+func parseGeoIPCSV(r io.Reader) (*GeoIPCSVDatabase, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	var ranges []geoIPRange
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rng, err := parseGeoIPRecord(record)
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("geoip database line %d: %w", line, err)
+		}
+		ranges = append(ranges, rng)
+	}
+	if len(ranges) == 0 {
+		return nil, errors.New("geoip database has no ranges")
+	}
+
+	slices.SortFunc(ranges, func(a, b geoIPRange) int { return a.first.Compare(b.first) })
+	return &GeoIPCSVDatabase{ranges: ranges}, nil
+}
+
+// parseGeoIPRecord parses a "first,last,country" or "network,country" row.
+func parseGeoIPRecord(record []string) (geoIPRange, error) {
+	if len(record) < 2 {
+		return geoIPRange{}, errors.New("expected an address range and a country")
+	}
+
+	var rng geoIPRange
+	if prefix, err := netip.ParsePrefix(strings.TrimSpace(record[0])); err == nil {
+		prefix = prefix.Masked()
+		rng.first, rng.last = prefix.Addr(), lastAddr(prefix)
+		rng.country = record[1]
+	} else {
+		if len(record) < 3 {
+			return geoIPRange{}, errors.New("expected a first address, a last address and a country")
+		}
+		if rng.first, err = netip.ParseAddr(strings.TrimSpace(record[0])); err != nil {
+			return geoIPRange{}, err
+		}
+		if rng.last, err = netip.ParseAddr(strings.TrimSpace(record[1])); err != nil {
+			return geoIPRange{}, err
+		}
+		rng.country = record[2]
+	}
+
+	rng.first, rng.last = rng.first.Unmap(), rng.last.Unmap()
+	if rng.first.BitLen() != rng.last.BitLen() || rng.last.Less(rng.first) {
+		return geoIPRange{}, errors.New("invalid address range")
+	}
+	rng.country = strings.ToUpper(strings.TrimSpace(rng.country))
+	if len(rng.country) != 2 {
+		return geoIPRange{}, fmt.Errorf("invalid country %q", rng.country)
+	}
+	return rng, nil
+}
+
+// lastAddr returns the last address of a masked prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// Country returns the country of the range holding addr.
+func (d *GeoIPCSVDatabase) Country(addr netip.Addr) (string, bool) {
+	addr = addr.Unmap()
+	// The last range starting at or before addr is the only one that can
+	// hold it
+	i, found := slices.BinarySearchFunc(d.ranges, addr, func(r geoIPRange, a netip.Addr) int { return r.first.Compare(a) })
+	if !found {
+		i--
+	}
+	if i < 0 || d.ranges[i].last.Less(addr) || d.ranges[i].first.BitLen() != addr.BitLen() {
+		return "", false
+	}
+	return d.ranges[i].country, true
+}
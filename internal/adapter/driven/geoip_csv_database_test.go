@@ -0,0 +1,63 @@
+package driven
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeoIPCSVDatabase(t *testing.T) {
+	const data = `ip_start,ip_end,country
+# DB-IP style ranges
+1.0.0.0,1.0.0.255,au
+2.136.0.0,2.143.255.255,ES
+# CIDR ranges
+5.39.0.0/17,FR,extra
+2001:db8::/32,NL
+`
+	path := filepath.Join(t.TempDir(), "geoip.csv")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := NewGeoIPCSVDatabase(path)
+	if err != nil {
+		t.Fatalf("NewGeoIPCSVDatabase() error = %v", err)
+	}
+
+	tests := []struct {
+		addr    string
+		want    string
+		wantHit bool
+	}{
+		{"1.0.0.0", "AU", true},
+		{"1.0.0.255", "AU", true},
+		{"1.0.1.0", "", false},
+		{"2.140.12.1", "ES", true},
+		{"::ffff:2.140.12.1", "ES", true},
+		{"5.39.127.255", "FR", true},
+		{"5.39.128.0", "", false},
+		{"2001:db8::1", "NL", true},
+		{"192.168.1.1", "", false},
+		{"::1", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := db.Country(netip.MustParseAddr(tt.addr))
+		if got != tt.want || ok != tt.wantHit {
+			t.Errorf("Country(%s) = %q, %v, want %q, %v", tt.addr, got, ok, tt.want, tt.wantHit)
+		}
+	}
+
+	for _, bad := range []string{
+		"network,country\n",
+		"network,country\nnot,a,range\n",
+		"network,country\n1.0.0.255,1.0.0.0,AU\n",
+		"network,country\n1.0.0.0/8,Spain\n",
+		"network,country\n1.0.0.0,::1,AU\n",
+	} {
+		if _, err := parseGeoIPCSV(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package driven
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/group"
+)
+
+const (
+	groupsBucket = "groups"
+)
+
+// GroupBoltDBRepository implements the GroupRepository port using BoltDB.
+type GroupBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewGroupBoltDBRepository creates a new BoltDB-backed group repository.
+// It initializes the required bucket if it doesn't exist.
+func NewGroupBoltDBRepository(db *bbolt.DB) (*GroupBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	// Create the groups bucket if it doesn't exist
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(groupsBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GroupBoltDBRepository{db: db}, nil
+}
+
+// groupDTO is used for JSON serialization.
+type groupDTO struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	Enabled  bool   `json:"enabled"`
+}
+
+func groupToDTO(g group.Group) groupDTO {
+	return groupDTO{
+		ID:       g.ID(),
+		Name:     g.Name(),
+		Position: g.Position(),
+		Enabled:  g.IsEnabled(),
+	}
+}
+
+func dtoToGroup(dto groupDTO) group.Group {
+	return group.ReconstructGroup(dto.ID, dto.Name, dto.Position, dto.Enabled)
+}
+
+// Save persists a new group to BoltDB.
+func (r *GroupBoltDBRepository) Save(ctx context.Context, g group.Group) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(groupsBucket))
+		if bucket == nil {
+			return errors.New("groups bucket not found")
+		}
+
+		key := []byte(g.ID())
+
+		if bucket.Get(key) != nil {
+			return group.ErrGroupAlreadyExists
+		}
+
+		data, err := json.Marshal(groupToDTO(g))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, data)
+	})
+}
+
+// Update persists changes to an existing group in BoltDB.
+func (r *GroupBoltDBRepository) Update(ctx context.Context, g group.Group) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(groupsBucket))
+		if bucket == nil {
+			return errors.New("groups bucket not found")
+		}
+
+		key := []byte(g.ID())
+
+		if bucket.Get(key) == nil {
+			return group.ErrGroupNotFound
+		}
+
+		data, err := json.Marshal(groupToDTO(g))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, data)
+	})
+}
+
+// FindAll retrieves all groups from BoltDB, ordered by position.
+func (r *GroupBoltDBRepository) FindAll(ctx context.Context) ([]group.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	groups := []group.Group{}
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(groupsBucket))
+		if bucket == nil {
+			return errors.New("groups bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var dto groupDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			groups = append(groups, dtoToGroup(dto))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	group.Sort(groups)
+	return groups, nil
+}
+
+// FindByID retrieves a group by its ID from BoltDB.
+func (r *GroupBoltDBRepository) FindByID(ctx context.Context, id string) (group.Group, error) {
+	if err := ctx.Err(); err != nil {
+		return group.Group{}, err
+	}
+
+	var g group.Group
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(groupsBucket))
+		if bucket == nil {
+			return errors.New("groups bucket not found")
+		}
+
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return group.ErrGroupNotFound
+		}
+
+		var dto groupDTO
+		if err := json.Unmarshal(data, &dto); err != nil {
+			return err
+		}
+
+		g = dtoToGroup(dto)
+		return nil
+	})
+
+	return g, err
+}
+
+// Delete removes a group by its ID from BoltDB.
+func (r *GroupBoltDBRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(groupsBucket))
+		if bucket == nil {
+			return errors.New("groups bucket not found")
+		}
+
+		key := []byte(id)
+
+		if bucket.Get(key) == nil {
+			return group.ErrGroupNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
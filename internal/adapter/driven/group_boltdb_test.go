@@ -0,0 +1,124 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/group"
+)
+
+func TestNewGroupBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewGroupBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestGroupBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+
+	newRepo := func(t *testing.T) *GroupBoltDBRepository {
+		db, cleanup := setupTestDB(t)
+		t.Cleanup(cleanup)
+		repo, err := NewGroupBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+		return repo
+	}
+
+	t.Run("saves and finds a group", func(t *testing.T) {
+		repo := newRepo(t)
+		sports, _ := group.NewGroup("Sports")
+		sports.SetPosition(3)
+		sports.SetEnabled(false)
+
+		if err := repo.Save(ctx, sports); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		found, err := repo.FindByID(ctx, "sports")
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		if found != sports {
+			t.Errorf("FindByID() = %+v, want %+v", found, sports)
+		}
+	})
+
+	t.Run("returns ErrGroupAlreadyExists for a duplicate", func(t *testing.T) {
+		repo := newRepo(t)
+		sports, _ := group.NewGroup("Sports")
+		_ = repo.Save(ctx, sports)
+
+		if err := repo.Save(ctx, sports); !errors.Is(err, group.ErrGroupAlreadyExists) {
+			t.Errorf("Save() error = %v, want ErrGroupAlreadyExists", err)
+		}
+	})
+
+	t.Run("lists groups by position", func(t *testing.T) {
+		repo := newRepo(t)
+		for i, name := range []string{"News", "Sports", "Kids"} {
+			g, _ := group.NewGroup(name)
+			g.SetPosition(2 - i)
+			if err := repo.Save(ctx, g); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+		}
+
+		groups, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll() error = %v", err)
+		}
+		var ids []string
+		for _, g := range groups {
+			ids = append(ids, g.ID())
+		}
+		if len(ids) != 3 || ids[0] != "kids" || ids[1] != "sports" || ids[2] != "news" {
+			t.Errorf("FindAll() order = %v, want [kids sports news]", ids)
+		}
+	})
+
+	t.Run("updates an existing group", func(t *testing.T) {
+		repo := newRepo(t)
+		sports, _ := group.NewGroup("Sports")
+		_ = repo.Save(ctx, sports)
+
+		_ = sports.Rename("Deportes")
+		if err := repo.Update(ctx, sports); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		found, _ := repo.FindByID(ctx, "sports")
+		if found.Name() != "Deportes" {
+			t.Errorf("expected renamed group, got %q", found.Name())
+		}
+
+		missing, _ := group.NewGroup("Missing")
+		if err := repo.Update(ctx, missing); !errors.Is(err, group.ErrGroupNotFound) {
+			t.Errorf("Update() error = %v, want ErrGroupNotFound", err)
+		}
+	})
+
+	t.Run("deletes a group", func(t *testing.T) {
+		repo := newRepo(t)
+		sports, _ := group.NewGroup("Sports")
+		_ = repo.Save(ctx, sports)
+
+		if err := repo.Delete(ctx, "sports"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.FindByID(ctx, "sports"); !errors.Is(err, group.ErrGroupNotFound) {
+			t.Errorf("FindByID() error = %v, want ErrGroupNotFound", err)
+		}
+		if err := repo.Delete(ctx, "sports"); !errors.Is(err, group.ErrGroupNotFound) {
+			t.Errorf("Delete() error = %v, want ErrGroupNotFound", err)
+		}
+	})
+}
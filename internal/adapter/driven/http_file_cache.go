@@ -0,0 +1,449 @@
+package driven
+
+import (
+	"cmp"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// HTTPFileCache keeps downloaded response bodies on disk together with their
+// ETag and Last-Modified validators, so that refreshing a large upstream file
+// can be a conditional GET that skips the download when nothing changed.
+//
+// Bodies are content-addressed: each distinct body is stored once under
+// blobs/{sha256 of body}, and entries/{sha256 of URL}.json records which body
+// a URL last returned along with its validators.
+//
+// Bodies can also be kept in memory, see SetMemoryLimit.
+type HTTPFileCache struct {
+	dir string
+	mu  sync.Mutex
+	mem *blobLRU // nil unless a memory limit is set
+
+	memHits   atomic.Uint64
+	memMisses atomic.Uint64
+}
+
+// httpCacheEntry is the JSON record kept for each cached URL.
+type httpCacheEntry struct {
+	URL          string    `json:"url"`
+	Blob         string    `json:"blob"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// NewHTTPFileCache creates a cache rooted at dir, creating it if needed.
+func NewHTTPFileCache(dir string) (*HTTPFileCache, error) {
+	if dir == "" {
+		return nil, errors.New("cache directory cannot be empty")
+	}
+	for _, sub := range []string{"blobs", "entries"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("creating cache directory: %w", err)
+		}
+	}
+	return &HTTPFileCache{dir: dir}, nil
+}
+
+// SetMemoryLimit keeps up to maxBytes of the most recently used bodies in
+// memory, so that answering a 304 does not read the body back from disk.
+// Zero or negative disables the memory layer and drops what it holds.
+func (c *HTTPFileCache) SetMemoryLimit(maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if maxBytes <= 0 {
+		c.mem = nil
+		return
+	}
+	c.mem = newBlobLRU(maxBytes)
+}
+
+// MemoryStats returns how many cached bodies were served from memory and how
+// many had to be read from disk.
+func (c *HTTPFileCache) MemoryStats() (hits, misses uint64) {
+	return c.memHits.Load(), c.memMisses.Load()
+}
+
+// Fetch sends req with client and returns the response body. If an earlier
+// response for the same URL is cached, the request carries its validators
+// (If-None-Match, If-Modified-Since) and a 304 Not Modified is answered from
+// the cache, only bumping the entry's fetch time. Bodies larger than maxSize
+// bytes are rejected when maxSize is positive.
+//
+// A nil cache fetches unconditionally, so callers need not special-case it.
+func (c *HTTPFileCache) Fetch(client *http.Client, req *http.Request, maxSize int64) ([]byte, error) {
+	key := c.key(req.URL.String())
+
+	var entry httpCacheEntry
+	cached := false
+	if c != nil {
+		c.mu.Lock()
+		entry, cached = c.loadEntry(key)
+		c.mu.Unlock()
+		if cached {
+			if entry.ETag != "" {
+				req.Header.Set("If-None-Match", entry.ETag)
+			}
+			if entry.LastModified != "" {
+				req.Header.Set("If-Modified-Since", entry.LastModified)
+			}
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		return c.revalidated(key, entry)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+	}
+
+	reader := io.Reader(resp.Body)
+	if maxSize > 0 {
+		reader = io.LimitReader(resp.Body, maxSize+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	if maxSize > 0 && int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("response exceeds %d bytes", maxSize)
+	}
+
+	if c != nil {
+		// Failing to cache only costs a full download next time
+		_ = c.store(key, httpCacheEntry{
+			URL:          req.URL.String(),
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			FetchedAt:    time.Now(),
+		}, body)
+	}
+	return body, nil
+}
+
+// Entries returns the cached entries ordered by URL.
+func (c *HTTPFileCache) Entries(ctx context.Context) ([]driven.CachedSource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := os.ReadDir(filepath.Join(c.dir, "entries"))
+	if err != nil {
+		return nil, fmt.Errorf("listing cache entries: %w", err)
+	}
+	entries := make([]driven.CachedSource, 0, len(files))
+	for _, f := range files {
+		key, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok {
+			continue
+		}
+		entry, ok := c.loadEntry(key)
+		if !ok {
+			continue
+		}
+		var size int64
+		if info, err := os.Stat(c.blobPath(entry.Blob)); err == nil {
+			size = info.Size()
+		}
+		entries = append(entries, driven.CachedSource{Key: key, URL: entry.URL, Size: size, FetchedAt: entry.FetchedAt})
+	}
+	slices.SortFunc(entries, func(a, b driven.CachedSource) int { return cmp.Compare(a.URL, b.URL) })
+	return entries, nil
+}
+
+// Invalidate removes the entry with the given key, and its body if no other
+// entry shares it. Returns false if there is no such entry.
+func (c *HTTPFileCache) Invalidate(ctx context.Context, key string) (bool, error) {
+	if !isHexKey(key) {
+		return false, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.loadEntry(key)
+	if err := os.Remove(c.entryPath(key)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("removing cache entry: %w", err)
+	}
+	if ok && !c.blobReferenced(entry.Blob) {
+		_ = os.Remove(c.blobPath(entry.Blob))
+		if c.mem != nil {
+			c.mem.remove(entry.Blob)
+		}
+	}
+	return true, nil
+}
+
+// Prune removes entries not fetched for longer than maxAge, such as those of
+// sources removed from the configuration, along with orphaned files: entries
+// that cannot be read or whose body is missing, bodies no entry points at,
+// and temporary files left by an interrupted write. A zero or negative maxAge
+// only removes orphans. Returns how many entries and bodies were removed.
+func (c *HTTPFileCache) Prune(ctx context.Context, maxAge time.Duration) (entries, blobs int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entryDir := filepath.Join(c.dir, "entries")
+	files, err := os.ReadDir(entryDir)
+	if err != nil {
+		return 0, 0, fmt.Errorf("listing cache entries: %w", err)
+	}
+	cutoff := time.Now().Add(-maxAge)
+	referenced := make(map[string]bool)
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return entries, blobs, err
+		}
+		// Writes happen under c.mu, so a temporary file now is a leftover
+		if strings.HasPrefix(f.Name(), ".tmp-") {
+			_ = os.Remove(filepath.Join(entryDir, f.Name()))
+			continue
+		}
+		key, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok {
+			continue
+		}
+		entry, ok := c.loadEntry(key)
+		if ok && (maxAge <= 0 || entry.FetchedAt.After(cutoff)) {
+			referenced[entry.Blob] = true
+			continue
+		}
+		if err := os.Remove(c.entryPath(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return entries, blobs, fmt.Errorf("removing cache entry: %w", err)
+		}
+		entries++
+	}
+
+	blobDir := filepath.Join(c.dir, "blobs")
+	files, err = os.ReadDir(blobDir)
+	if err != nil {
+		return entries, blobs, fmt.Errorf("listing cache bodies: %w", err)
+	}
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return entries, blobs, err
+		}
+		if strings.HasPrefix(f.Name(), ".tmp-") {
+			_ = os.Remove(filepath.Join(blobDir, f.Name()))
+			continue
+		}
+		if referenced[f.Name()] {
+			continue
+		}
+		if err := os.Remove(c.blobPath(f.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return entries, blobs, fmt.Errorf("removing cached body: %w", err)
+		}
+		if c.mem != nil {
+			c.mem.remove(f.Name())
+		}
+		blobs++
+	}
+	return entries, blobs, nil
+}
+
+// isHexKey reports whether key looks like an entry key, which keeps keys
+// from the API from naming files outside the cache.
+func isHexKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
+
+// revalidated returns the cached body of an entry the server confirmed as
+// unchanged and records the new fetch time.
+func (c *HTTPFileCache) revalidated(key string, entry httpCacheEntry) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	body, err := c.readBlob(entry.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("reading cached body: %w", err)
+	}
+	entry.FetchedAt = time.Now()
+	_ = c.writeEntry(key, entry)
+	return body, nil
+}
+
+// store saves body as a blob and points the entry at it, removing the blob
+// the entry pointed at before if nothing else references it.
+func (c *HTTPFileCache) store(key string, entry httpCacheEntry, body []byte) error {
+	sum := sha256.Sum256(body)
+	entry.Blob = hex.EncodeToString(sum[:])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	previous, hadPrevious := c.loadEntry(key)
+	if _, err := os.Stat(c.blobPath(entry.Blob)); err != nil {
+		if err := writeFileAtomic(c.blobPath(entry.Blob), body); err != nil {
+			return err
+		}
+	}
+	if err := c.writeEntry(key, entry); err != nil {
+		return err
+	}
+	if c.mem != nil {
+		c.mem.put(entry.Blob, body)
+	}
+	if hadPrevious && previous.Blob != entry.Blob && !c.blobReferenced(previous.Blob) {
+		_ = os.Remove(c.blobPath(previous.Blob))
+		if c.mem != nil {
+			c.mem.remove(previous.Blob)
+		}
+	}
+	return nil
+}
+
+// readBlob returns a copy of a blob's body, from memory when the memory
+// layer holds it. The caller must hold c.mu.
+func (c *HTTPFileCache) readBlob(blob string) ([]byte, error) {
+	if c.mem == nil {
+		return os.ReadFile(c.blobPath(blob))
+	}
+	if body, ok := c.mem.get(blob); ok {
+		c.memHits.Add(1)
+		return slices.Clone(body), nil
+	}
+	c.memMisses.Add(1)
+	body, err := os.ReadFile(c.blobPath(blob))
+	if err != nil {
+		return nil, err
+	}
+	c.mem.put(blob, body)
+	return slices.Clone(body), nil
+}
+
+// loadEntry reads the entry for key. The caller must hold c.mu. An entry
+// whose blob is missing is treated as absent.
+func (c *HTTPFileCache) loadEntry(key string) (httpCacheEntry, bool) {
+	data, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return httpCacheEntry{}, false
+	}
+	var entry httpCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Blob == "" {
+		return httpCacheEntry{}, false
+	}
+	if _, err := os.Stat(c.blobPath(entry.Blob)); err != nil {
+		return httpCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *HTTPFileCache) writeEntry(key string, entry httpCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(c.entryPath(key), data)
+}
+
+// blobReferenced reports whether any entry points at blob. The caller must
+// hold c.mu.
+func (c *HTTPFileCache) blobReferenced(blob string) bool {
+	files, err := os.ReadDir(filepath.Join(c.dir, "entries"))
+	if err != nil {
+		return true
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(c.dir, "entries", f.Name()))
+		if err != nil {
+			continue
+		}
+		var entry httpCacheEntry
+		if json.Unmarshal(data, &entry) == nil && entry.Blob == blob {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *HTTPFileCache) key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *HTTPFileCache) entryPath(key string) string {
+	return filepath.Join(c.dir, "entries", key+".json")
+}
+
+func (c *HTTPFileCache) blobPath(blob string) string {
+	return filepath.Join(c.dir, "blobs", blob)
+}
+
+// blobLRU holds blob bodies up to a total size, evicting the least recently
+// used first. It is not safe for concurrent use.
+type blobLRU struct {
+	maxBytes int64
+	size     int64
+	order    *list.List // front is the most recently used
+	items    map[string]*list.Element
+}
+
+type blobLRUItem struct {
+	blob string
+	body []byte
+}
+
+func newBlobLRU(maxBytes int64) *blobLRU {
+	return &blobLRU{maxBytes: maxBytes, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (l *blobLRU) get(blob string) ([]byte, bool) {
+	el, ok := l.items[blob]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(el)
+	return el.Value.(*blobLRUItem).body, true
+}
+
+// put stores body under blob. Bodies larger than the whole limit are not kept.
+func (l *blobLRU) put(blob string, body []byte) {
+	l.remove(blob)
+	if int64(len(body)) > l.maxBytes {
+		return
+	}
+	l.items[blob] = l.order.PushFront(&blobLRUItem{blob: blob, body: slices.Clone(body)})
+	l.size += int64(len(body))
+	for l.size > l.maxBytes {
+		l.remove(l.order.Back().Value.(*blobLRUItem).blob)
+	}
+}
+
+func (l *blobLRU) remove(blob string) {
+	el, ok := l.items[blob]
+	if !ok {
+		return
+	}
+	l.order.Remove(el)
+	delete(l.items, blob)
+	l.size -= int64(len(el.Value.(*blobLRUItem).body))
+}
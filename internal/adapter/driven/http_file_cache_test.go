@@ -0,0 +1,306 @@
+package driven
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPFileCache(t *testing.T) {
+	newRequest := func(t *testing.T, url string) *http.Request {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatalf("NewRequest() error = %v", err)
+		}
+		return req
+	}
+
+	t.Run("answers 304 from the cache and bumps the fetch time", func(t *testing.T) {
+		var full, notModified atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			full.Add(1)
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			_, _ = w.Write([]byte("#EXTM3U\n"))
+		}))
+		defer server.Close()
+
+		cache, err := NewHTTPFileCache(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewHTTPFileCache() error = %v", err)
+		}
+
+		body, err := cache.Fetch(server.Client(), newRequest(t, server.URL), 0)
+		if err != nil || string(body) != "#EXTM3U\n" {
+			t.Fatalf("first Fetch() = %q, %v", body, err)
+		}
+		first, _ := cache.loadEntry(cache.key(server.URL))
+		if first.ETag != `"v1"` || first.LastModified == "" {
+			t.Errorf("expected validators to be recorded, got %+v", first)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		body, err = cache.Fetch(server.Client(), newRequest(t, server.URL), 0)
+		if err != nil || string(body) != "#EXTM3U\n" {
+			t.Fatalf("second Fetch() = %q, %v", body, err)
+		}
+		if full.Load() != 1 || notModified.Load() != 1 {
+			t.Errorf("expected one full and one conditional fetch, got %d and %d", full.Load(), notModified.Load())
+		}
+
+		second, _ := cache.loadEntry(cache.key(server.URL))
+		if !second.FetchedAt.After(first.FetchedAt) {
+			t.Errorf("expected fetch time to be bumped, got %v then %v", first.FetchedAt, second.FetchedAt)
+		}
+		if second.Blob != first.Blob {
+			t.Errorf("expected blob to be unchanged")
+		}
+	})
+
+	t.Run("replaces changed bodies and removes the old blob", func(t *testing.T) {
+		version := "one"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("ETag", `"`+version+`"`)
+			_, _ = w.Write([]byte(version))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		cache, err := NewHTTPFileCache(dir)
+		if err != nil {
+			t.Fatalf("NewHTTPFileCache() error = %v", err)
+		}
+
+		if _, err := cache.Fetch(server.Client(), newRequest(t, server.URL), 0); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		version = "two"
+		body, err := cache.Fetch(server.Client(), newRequest(t, server.URL), 0)
+		if err != nil || string(body) != "two" {
+			t.Fatalf("Fetch() = %q, %v", body, err)
+		}
+
+		blobs, _ := os.ReadDir(filepath.Join(dir, "blobs"))
+		if len(blobs) != 1 {
+			t.Errorf("expected 1 blob, got %d", len(blobs))
+		}
+	})
+
+	t.Run("rejects unexpected statuses and oversized bodies", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte("0123456789"))
+		}))
+		defer server.Close()
+
+		cache, err := NewHTTPFileCache(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewHTTPFileCache() error = %v", err)
+		}
+
+		if _, err := cache.Fetch(server.Client(), newRequest(t, server.URL+"/missing"), 0); err == nil {
+			t.Error("expected error for 404")
+		}
+		if _, err := cache.Fetch(server.Client(), newRequest(t, server.URL), 5); err == nil {
+			t.Error("expected error for oversized body")
+		}
+	})
+
+	t.Run("serves revalidated bodies from memory", func(t *testing.T) {
+		version := "one"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == `"`+version+`"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"`+version+`"`)
+			_, _ = w.Write([]byte(version))
+		}))
+		defer server.Close()
+
+		cache, err := NewHTTPFileCache(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewHTTPFileCache() error = %v", err)
+		}
+		cache.SetMemoryLimit(1024)
+
+		for range 2 {
+			body, err := cache.Fetch(server.Client(), newRequest(t, server.URL), 0)
+			if err != nil || string(body) != "one" {
+				t.Fatalf("Fetch() = %q, %v", body, err)
+			}
+		}
+		if hits, misses := cache.MemoryStats(); hits != 1 || misses != 0 {
+			t.Errorf("expected 1 hit and 0 misses, got %d and %d", hits, misses)
+		}
+
+		// A changed body replaces the old one in memory too
+		version = "two"
+		if _, err := cache.Fetch(server.Client(), newRequest(t, server.URL), 0); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		body, err := cache.Fetch(server.Client(), newRequest(t, server.URL), 0)
+		if err != nil || string(body) != "two" {
+			t.Fatalf("Fetch() = %q, %v", body, err)
+		}
+		if len(cache.mem.items) != 1 {
+			t.Errorf("expected the old body to be dropped from memory, got %d bodies", len(cache.mem.items))
+		}
+	})
+
+	t.Run("lists and invalidates entries", func(t *testing.T) {
+		var fetches atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") != "" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fetches.Add(1)
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte("guide"))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		cache, err := NewHTTPFileCache(dir)
+		if err != nil {
+			t.Fatalf("NewHTTPFileCache() error = %v", err)
+		}
+		for _, path := range []string{"/a", "/b"} {
+			if _, err := cache.Fetch(server.Client(), newRequest(t, server.URL+path), 0); err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+		}
+
+		entries, err := cache.Entries(context.Background())
+		if err != nil {
+			t.Fatalf("Entries() error = %v", err)
+		}
+		if len(entries) != 2 || entries[0].URL != server.URL+"/a" || entries[0].Size != 5 || entries[0].FetchedAt.IsZero() {
+			t.Fatalf("unexpected entries %+v", entries)
+		}
+
+		found, err := cache.Invalidate(context.Background(), entries[0].Key)
+		if err != nil || !found {
+			t.Fatalf("Invalidate() = %v, %v", found, err)
+		}
+		blobs, _ := os.ReadDir(filepath.Join(dir, "blobs"))
+		if len(blobs) != 1 {
+			t.Errorf("expected the blob shared with the other entry to be kept, got %d blobs", len(blobs))
+		}
+		if _, err := cache.Fetch(server.Client(), newRequest(t, server.URL+"/a"), 0); err != nil || fetches.Load() != 3 {
+			t.Errorf("expected a full download after invalidation, got %d downloads, %v", fetches.Load(), err)
+		}
+
+		for _, key := range []string{entries[0].Key[:10], "../../etc/passwd", strings.Repeat("0", 64)} {
+			if found, err := cache.Invalidate(context.Background(), key); found || err != nil {
+				t.Errorf("Invalidate(%q) = %v, %v, want false", key, found, err)
+			}
+		}
+	})
+
+	t.Run("prunes old entries and orphaned files", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("body of " + r.URL.Path))
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		cache, err := NewHTTPFileCache(dir)
+		if err != nil {
+			t.Fatalf("NewHTTPFileCache() error = %v", err)
+		}
+		for _, path := range []string{"/old", "/recent"} {
+			if _, err := cache.Fetch(server.Client(), newRequest(t, server.URL+path), 0); err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+		}
+		oldKey := cache.key(server.URL + "/old")
+		old, _ := cache.loadEntry(oldKey)
+		old.FetchedAt = time.Now().Add(-48 * time.Hour)
+		if err := cache.writeEntry(oldKey, old); err != nil {
+			t.Fatalf("writeEntry() error = %v", err)
+		}
+		for _, name := range []string{"blobs/unreferenced", "blobs/.tmp-1", "entries/.tmp-2", "entries/" + strings.Repeat("0", 64) + ".json"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+		}
+
+		entries, blobs, err := cache.Prune(context.Background(), 24*time.Hour)
+		if err != nil {
+			t.Fatalf("Prune() error = %v", err)
+		}
+		if entries != 2 || blobs != 2 {
+			t.Errorf("Prune() removed %d entries and %d blobs, want 2 and 2", entries, blobs)
+		}
+		left, err := cache.Entries(context.Background())
+		if err != nil {
+			t.Fatalf("Entries() error = %v", err)
+		}
+		if len(left) != 1 || left[0].URL != server.URL+"/recent" {
+			t.Errorf("expected only the recent entry to be kept, got %+v", left)
+		}
+		for _, sub := range []string{"blobs", "entries"} {
+			if files, _ := os.ReadDir(filepath.Join(dir, sub)); len(files) != 1 {
+				t.Errorf("expected one file left in %s, got %d", sub, len(files))
+			}
+		}
+
+		// Without a maximum age only orphans go
+		if entries, blobs, err := cache.Prune(context.Background(), 0); err != nil || entries != 0 || blobs != 0 {
+			t.Errorf("Prune(0) = %d, %d, %v, want nothing removed", entries, blobs, err)
+		}
+	})
+
+	t.Run("nil cache fetches unconditionally", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("plain"))
+		}))
+		defer server.Close()
+
+		var cache *HTTPFileCache
+		body, err := cache.Fetch(server.Client(), newRequest(t, server.URL), 0)
+		if err != nil || string(body) != "plain" {
+			t.Errorf("Fetch() = %q, %v", body, err)
+		}
+	})
+}
+
+func TestBlobLRU(t *testing.T) {
+	lru := newBlobLRU(10)
+	lru.put("a", []byte("aaaa"))
+	lru.put("b", []byte("bbbb"))
+	lru.get("a")
+	lru.put("c", []byte("cccc"))
+
+	if _, ok := lru.get("b"); ok {
+		t.Error("expected the least recently used body to be evicted")
+	}
+	if _, ok := lru.get("a"); !ok {
+		t.Error("expected the recently used body to be kept")
+	}
+	if lru.size != 8 {
+		t.Errorf("expected size 8, got %d", lru.size)
+	}
+
+	lru.put("big", []byte("0123456789a"))
+	if _, ok := lru.get("big"); ok || lru.size != 8 {
+		t.Errorf("expected a body over the limit not to be kept, size %d", lru.size)
+	}
+}
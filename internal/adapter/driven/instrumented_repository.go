@@ -0,0 +1,264 @@
+package driven
+
+import (
+	"context"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/metrics"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/probe"
+	"github.com/alorle/iptv-manager/internal/stream"
+	"github.com/alorle/iptv-manager/internal/subscription"
+)
+
+// observeOp records the duration of a repository operation in a histogram
+// labelled by repository and operation.
+func observeOp(durations *metrics.HistogramVec, repository, operation string, start time.Time) {
+	durations.With(repository, operation).ObserveDuration(start)
+}
+
+// InstrumentedChannelRepository wraps a ChannelRepository and records the
+// duration of every operation.
+type InstrumentedChannelRepository struct {
+	next      driven.ChannelRepository
+	durations *metrics.HistogramVec
+}
+
+// NewInstrumentedChannelRepository wraps next. durations must have the
+// labels (repository, operation).
+func NewInstrumentedChannelRepository(next driven.ChannelRepository, durations *metrics.HistogramVec) *InstrumentedChannelRepository {
+	return &InstrumentedChannelRepository{next: next, durations: durations}
+}
+
+func (r *InstrumentedChannelRepository) Save(ctx context.Context, ch channel.Channel) error {
+	defer observeOp(r.durations, "channel", "save", time.Now())
+	return r.next.Save(ctx, ch)
+}
+
+func (r *InstrumentedChannelRepository) Update(ctx context.Context, ch channel.Channel) error {
+	defer observeOp(r.durations, "channel", "update", time.Now())
+	return r.next.Update(ctx, ch)
+}
+
+func (r *InstrumentedChannelRepository) FindByName(ctx context.Context, name string) (channel.Channel, error) {
+	defer observeOp(r.durations, "channel", "find_by_name", time.Now())
+	return r.next.FindByName(ctx, name)
+}
+
+func (r *InstrumentedChannelRepository) FindAll(ctx context.Context) ([]channel.Channel, error) {
+	defer observeOp(r.durations, "channel", "find_all", time.Now())
+	return r.next.FindAll(ctx)
+}
+
+func (r *InstrumentedChannelRepository) FindByTag(ctx context.Context, tag string) ([]channel.Channel, error) {
+	defer observeOp(r.durations, "channel", "find_by_tag", time.Now())
+	return r.next.FindByTag(ctx, tag)
+}
+
+func (r *InstrumentedChannelRepository) Delete(ctx context.Context, name string) error {
+	defer observeOp(r.durations, "channel", "delete", time.Now())
+	return r.next.Delete(ctx, name)
+}
+
+func (r *InstrumentedChannelRepository) Ping(ctx context.Context) error {
+	defer observeOp(r.durations, "channel", "ping", time.Now())
+	return r.next.Ping(ctx)
+}
+
+// InstrumentedStreamRepository wraps a StreamRepository and records the
+// duration of every operation.
+type InstrumentedStreamRepository struct {
+	next      driven.StreamRepository
+	durations *metrics.HistogramVec
+}
+
+// NewInstrumentedStreamRepository wraps next. durations must have the
+// labels (repository, operation).
+func NewInstrumentedStreamRepository(next driven.StreamRepository, durations *metrics.HistogramVec) *InstrumentedStreamRepository {
+	return &InstrumentedStreamRepository{next: next, durations: durations}
+}
+
+func (r *InstrumentedStreamRepository) Save(ctx context.Context, s stream.Stream) error {
+	defer observeOp(r.durations, "stream", "save", time.Now())
+	return r.next.Save(ctx, s)
+}
+
+func (r *InstrumentedStreamRepository) Update(ctx context.Context, s stream.Stream) error {
+	defer observeOp(r.durations, "stream", "update", time.Now())
+	return r.next.Update(ctx, s)
+}
+
+func (r *InstrumentedStreamRepository) FindByInfoHash(ctx context.Context, infoHash string) (stream.Stream, error) {
+	defer observeOp(r.durations, "stream", "find_by_infohash", time.Now())
+	return r.next.FindByInfoHash(ctx, infoHash)
+}
+
+func (r *InstrumentedStreamRepository) FindAll(ctx context.Context) ([]stream.Stream, error) {
+	defer observeOp(r.durations, "stream", "find_all", time.Now())
+	return r.next.FindAll(ctx)
+}
+
+func (r *InstrumentedStreamRepository) FindByChannelName(ctx context.Context, channelName string) ([]stream.Stream, error) {
+	defer observeOp(r.durations, "stream", "find_by_channel_name", time.Now())
+	return r.next.FindByChannelName(ctx, channelName)
+}
+
+func (r *InstrumentedStreamRepository) Delete(ctx context.Context, infoHash string) error {
+	defer observeOp(r.durations, "stream", "delete", time.Now())
+	return r.next.Delete(ctx, infoHash)
+}
+
+func (r *InstrumentedStreamRepository) DeleteByChannelName(ctx context.Context, channelName string) error {
+	defer observeOp(r.durations, "stream", "delete_by_channel_name", time.Now())
+	return r.next.DeleteByChannelName(ctx, channelName)
+}
+
+// InstrumentedSubscriptionRepository wraps a SubscriptionRepository and
+// records the duration of every operation.
+type InstrumentedSubscriptionRepository struct {
+	next      driven.SubscriptionRepository
+	durations *metrics.HistogramVec
+}
+
+// NewInstrumentedSubscriptionRepository wraps next. durations must have the
+// labels (repository, operation).
+func NewInstrumentedSubscriptionRepository(next driven.SubscriptionRepository, durations *metrics.HistogramVec) *InstrumentedSubscriptionRepository {
+	return &InstrumentedSubscriptionRepository{next: next, durations: durations}
+}
+
+func (r *InstrumentedSubscriptionRepository) Save(ctx context.Context, sub subscription.Subscription) error {
+	defer observeOp(r.durations, "subscription", "save", time.Now())
+	return r.next.Save(ctx, sub)
+}
+
+func (r *InstrumentedSubscriptionRepository) Update(ctx context.Context, sub subscription.Subscription) error {
+	defer observeOp(r.durations, "subscription", "update", time.Now())
+	return r.next.Update(ctx, sub)
+}
+
+func (r *InstrumentedSubscriptionRepository) FindAll(ctx context.Context) ([]subscription.Subscription, error) {
+	defer observeOp(r.durations, "subscription", "find_all", time.Now())
+	return r.next.FindAll(ctx)
+}
+
+func (r *InstrumentedSubscriptionRepository) FindByEPGID(ctx context.Context, epgChannelID string) (subscription.Subscription, error) {
+	defer observeOp(r.durations, "subscription", "find_by_epg_id", time.Now())
+	return r.next.FindByEPGID(ctx, epgChannelID)
+}
+
+func (r *InstrumentedSubscriptionRepository) Delete(ctx context.Context, epgChannelID string) error {
+	defer observeOp(r.durations, "subscription", "delete", time.Now())
+	return r.next.Delete(ctx, epgChannelID)
+}
+
+// InstrumentedGroupRepository wraps a GroupRepository and records the
+// duration of every operation.
+type InstrumentedGroupRepository struct {
+	next      driven.GroupRepository
+	durations *metrics.HistogramVec
+}
+
+// NewInstrumentedGroupRepository wraps next. durations must have the
+// labels (repository, operation).
+func NewInstrumentedGroupRepository(next driven.GroupRepository, durations *metrics.HistogramVec) *InstrumentedGroupRepository {
+	return &InstrumentedGroupRepository{next: next, durations: durations}
+}
+
+func (r *InstrumentedGroupRepository) Save(ctx context.Context, g group.Group) error {
+	defer observeOp(r.durations, "group", "save", time.Now())
+	return r.next.Save(ctx, g)
+}
+
+func (r *InstrumentedGroupRepository) Update(ctx context.Context, g group.Group) error {
+	defer observeOp(r.durations, "group", "update", time.Now())
+	return r.next.Update(ctx, g)
+}
+
+func (r *InstrumentedGroupRepository) FindAll(ctx context.Context) ([]group.Group, error) {
+	defer observeOp(r.durations, "group", "find_all", time.Now())
+	return r.next.FindAll(ctx)
+}
+
+func (r *InstrumentedGroupRepository) FindByID(ctx context.Context, id string) (group.Group, error) {
+	defer observeOp(r.durations, "group", "find_by_id", time.Now())
+	return r.next.FindByID(ctx, id)
+}
+
+func (r *InstrumentedGroupRepository) Delete(ctx context.Context, id string) error {
+	defer observeOp(r.durations, "group", "delete", time.Now())
+	return r.next.Delete(ctx, id)
+}
+
+// InstrumentedProbeRepository wraps a ProbeRepository and records the
+// duration of every operation.
+type InstrumentedProbeRepository struct {
+	next      driven.ProbeRepository
+	durations *metrics.HistogramVec
+}
+
+// NewInstrumentedProbeRepository wraps next. durations must have the
+// labels (repository, operation).
+func NewInstrumentedProbeRepository(next driven.ProbeRepository, durations *metrics.HistogramVec) *InstrumentedProbeRepository {
+	return &InstrumentedProbeRepository{next: next, durations: durations}
+}
+
+func (r *InstrumentedProbeRepository) Save(ctx context.Context, result probe.Result) error {
+	defer observeOp(r.durations, "probe", "save", time.Now())
+	return r.next.Save(ctx, result)
+}
+
+func (r *InstrumentedProbeRepository) FindByInfoHash(ctx context.Context, infoHash string) ([]probe.Result, error) {
+	defer observeOp(r.durations, "probe", "find_by_infohash", time.Now())
+	return r.next.FindByInfoHash(ctx, infoHash)
+}
+
+func (r *InstrumentedProbeRepository) FindByInfoHashSince(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+	defer observeOp(r.durations, "probe", "find_by_infohash_since", time.Now())
+	return r.next.FindByInfoHashSince(ctx, infoHash, since)
+}
+
+func (r *InstrumentedProbeRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	defer observeOp(r.durations, "probe", "delete_before", time.Now())
+	return r.next.DeleteBefore(ctx, before)
+}
+
+// InstrumentedTokenRepository wraps a TokenRepository and records the
+// duration of every operation.
+type InstrumentedTokenRepository struct {
+	next      driven.TokenRepository
+	durations *metrics.HistogramVec
+}
+
+// NewInstrumentedTokenRepository wraps next. durations must have the labels
+// (repository, operation).
+func NewInstrumentedTokenRepository(next driven.TokenRepository, durations *metrics.HistogramVec) *InstrumentedTokenRepository {
+	return &InstrumentedTokenRepository{next: next, durations: durations}
+}
+
+func (r *InstrumentedTokenRepository) Save(ctx context.Context, token auth.Token) error {
+	defer observeOp(r.durations, "token", "save", time.Now())
+	return r.next.Save(ctx, token)
+}
+
+func (r *InstrumentedTokenRepository) FindAll(ctx context.Context) ([]auth.Token, error) {
+	defer observeOp(r.durations, "token", "find_all", time.Now())
+	return r.next.FindAll(ctx)
+}
+
+func (r *InstrumentedTokenRepository) FindByID(ctx context.Context, id string) (auth.Token, error) {
+	defer observeOp(r.durations, "token", "find_by_id", time.Now())
+	return r.next.FindByID(ctx, id)
+}
+
+func (r *InstrumentedTokenRepository) FindBySecretHash(ctx context.Context, secretHash string) (auth.Token, error) {
+	defer observeOp(r.durations, "token", "find_by_secret_hash", time.Now())
+	return r.next.FindBySecretHash(ctx, secretHash)
+}
+
+func (r *InstrumentedTokenRepository) Delete(ctx context.Context, id string) error {
+	defer observeOp(r.durations, "token", "delete", time.Now())
+	return r.next.Delete(ctx, id)
+}
@@ -0,0 +1,51 @@
+package driven
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/metrics"
+)
+
+func TestInstrumentedChannelRepository(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	inner, err := NewChannelBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	reg := metrics.NewRegistry()
+	durations := reg.NewHistogram("test_db_seconds", "Test.", nil, "repository", "operation")
+	repo := NewInstrumentedChannelRepository(inner, durations)
+
+	ctx := context.Background()
+	ch, _ := channel.NewChannel("HBO")
+	if err := repo.Save(ctx, ch); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := repo.FindByName(ctx, "HBO")
+	if err != nil {
+		t.Fatalf("FindByName() error = %v", err)
+	}
+	if got.Name() != "HBO" {
+		t.Errorf("FindByName() = %q, want HBO", got.Name())
+	}
+	if _, err := repo.FindByName(ctx, "missing"); err != channel.ErrChannelNotFound {
+		t.Errorf("expected ErrChannelNotFound to pass through, got %v", err)
+	}
+
+	var sb strings.Builder
+	_ = reg.WriteText(&sb)
+	for _, line := range []string{
+		`test_db_seconds_count{repository="channel",operation="save"} 1`,
+		`test_db_seconds_count{repository="channel",operation="find_by_name"} 2`,
+	} {
+		if !strings.Contains(sb.String(), line+"\n") {
+			t.Errorf("output missing %q:\n%s", line, sb.String())
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/alorle/iptv-manager/internal/logo"
+)
+
+// LogoFileStore implements the LogoStore port on the local filesystem.
+// Each logo is stored as {key}.png with its source URL in {key}.src.
+type LogoFileStore struct {
+	dir string
+}
+
+// NewLogoFileStore creates a logo store rooted at dir, creating it if needed.
+func NewLogoFileStore(dir string) (*LogoFileStore, error) {
+	if dir == "" {
+		return nil, errors.New("logo directory cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating logo directory: %w", err)
+	}
+	return &LogoFileStore{dir: dir}, nil
+}
+
+// Save writes the logo and its source URL. Files are written to a temporary
+// name first so readers never see a partial image.
+func (s *LogoFileStore) Save(ctx context.Context, key, sourceURL string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !logo.ValidKey(key) {
+		return fmt.Errorf("invalid logo key %q", key)
+	}
+
+	if err := writeFileAtomic(s.path(key, ".png"), data); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path(key, ".src"), []byte(sourceURL))
+}
+
+// Load reads the logo stored under key.
+func (s *LogoFileStore) Load(ctx context.Context, key string) ([]byte, error) {
+	return s.read(ctx, key, ".png")
+}
+
+// SourceURL reads the URL the logo under key was fetched from.
+func (s *LogoFileStore) SourceURL(ctx context.Context, key string) (string, error) {
+	data, err := s.read(ctx, key, ".src")
+	return string(data), err
+}
+
+func (s *LogoFileStore) read(ctx context.Context, key, ext string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !logo.ValidKey(key) {
+		return nil, logo.ErrLogoNotFound
+	}
+
+	data, err := os.ReadFile(s.path(key, ext))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, logo.ErrLogoNotFound
+	}
+	return data, err
+}
+
+func (s *LogoFileStore) path(key, ext string) string {
+	return filepath.Join(s.dir, key+ext)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
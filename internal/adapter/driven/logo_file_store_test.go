@@ -0,0 +1,65 @@
+package driven
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/logo"
+)
+
+func TestLogoFileStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("saves and loads logos with their source URL", func(t *testing.T) {
+		store, err := NewLogoFileStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewLogoFileStore() error = %v", err)
+		}
+
+		key := logo.Key("hbo.es")
+		data := []byte("\x89PNG fake image")
+		if err := store.Save(ctx, key, "http://example.com/hbo.png", data); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		got, err := store.Load(ctx, key)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("Load() = %q, want %q", got, data)
+		}
+		src, err := store.SourceURL(ctx, key)
+		if err != nil || src != "http://example.com/hbo.png" {
+			t.Errorf("SourceURL() = %q, %v", src, err)
+		}
+	})
+
+	t.Run("returns ErrLogoNotFound for missing or invalid keys", func(t *testing.T) {
+		store, _ := NewLogoFileStore(t.TempDir())
+
+		for _, key := range []string{logo.Key("missing"), "../../etc/passwd"} {
+			if _, err := store.Load(ctx, key); !errors.Is(err, logo.ErrLogoNotFound) {
+				t.Errorf("Load(%q) expected ErrLogoNotFound, got %v", key, err)
+			}
+			if _, err := store.SourceURL(ctx, key); !errors.Is(err, logo.ErrLogoNotFound) {
+				t.Errorf("SourceURL(%q) expected ErrLogoNotFound, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("rejects invalid keys on save", func(t *testing.T) {
+		store, _ := NewLogoFileStore(t.TempDir())
+		if err := store.Save(ctx, "../escape", "u", []byte("x")); err == nil {
+			t.Error("expected error for invalid key")
+		}
+	})
+
+	t.Run("rejects empty directory", func(t *testing.T) {
+		if _, err := NewLogoFileStore(""); err == nil {
+			t.Error("expected error for empty directory")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package driven
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxLogoSize caps downloaded logos; channel logos are small icons.
+const maxLogoSize = 1 << 20
+
+// LogoHTTPFetcher downloads logos over HTTP.
+// It implements the driven.LogoFetcher port.
+type LogoHTTPFetcher struct {
+	client *http.Client
+}
+
+// NewLogoHTTPFetcher creates a logo fetcher. If client is nil, it creates a
+// default HTTP client with a 30-second timeout.
+func NewLogoHTTPFetcher(client *http.Client) *LogoHTTPFetcher {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return &LogoHTTPFetcher{client: client}
+}
+
+// FetchLogo downloads the image at url, rejecting non-image responses and
+// bodies larger than 1 MiB.
+func (f *LogoHTTPFetcher) FetchLogo(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating HTTP request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching logo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLogoSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading logo: %w", err)
+	}
+	if len(data) > maxLogoSize {
+		return nil, fmt.Errorf("logo exceeds %d bytes", maxLogoSize)
+	}
+	if ct := http.DetectContentType(data); !strings.HasPrefix(ct, "image/") && !isSVG(data) {
+		return nil, fmt.Errorf("logo is not an image (%s)", ct)
+	}
+
+	return data, nil
+}
+
+// isSVG reports whether data looks like an SVG document, which content
+// sniffing reports as text.
+func isSVG(data []byte) bool {
+	return strings.Contains(string(data[:min(len(data), 512)]), "<svg")
+}
@@ -0,0 +1,50 @@
+package driven
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLogoHTTPFetcher_FetchLogo(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/logo.png":
+			_, _ = w.Write(png)
+		case "/logo.svg":
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"></svg>`))
+		case "/page.html":
+			_, _ = w.Write([]byte("<html><body>not an image</body></html>"))
+		case "/huge.png":
+			_, _ = w.Write(append(png, make([]byte, maxLogoSize)...))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewLogoHTTPFetcher(nil)
+	ctx := context.Background()
+
+	data, err := fetcher.FetchLogo(ctx, server.URL+"/logo.png")
+	if err != nil {
+		t.Fatalf("FetchLogo() error = %v", err)
+	}
+	if !bytes.Equal(data, png) {
+		t.Error("expected PNG bytes to be returned unchanged")
+	}
+
+	if _, err := fetcher.FetchLogo(ctx, server.URL+"/logo.svg"); err != nil {
+		t.Errorf("expected SVG to be accepted, got %v", err)
+	}
+
+	for _, path := range []string{"/page.html", "/huge.png", "/missing.png"} {
+		if _, err := fetcher.FetchLogo(ctx, server.URL+path); err == nil {
+			t.Errorf("FetchLogo(%s) expected error", path)
+		}
+	}
+}
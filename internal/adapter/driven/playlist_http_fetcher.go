@@ -0,0 +1,56 @@
+package driven
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxPlaylistSize caps downloaded playlists; even large acestream lists are a
+// few hundred kilobytes.
+const maxPlaylistSize = 10 << 20
+
+// PlaylistHTTPFetcher downloads M3U playlists over HTTP.
+// It implements the driven.PlaylistFetcher port.
+type PlaylistHTTPFetcher struct {
+	client *http.Client
+}
+
+// NewPlaylistHTTPFetcher creates a playlist fetcher. If client is nil, it
+// creates a default HTTP client with a 30-second timeout.
+func NewPlaylistHTTPFetcher(client *http.Client) *PlaylistHTTPFetcher {
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	return &PlaylistHTTPFetcher{client: client}
+}
+
+// FetchPlaylist downloads the playlist at url, rejecting bodies larger than
+// 10 MiB.
+func (f *PlaylistHTTPFetcher) FetchPlaylist(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating HTTP request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching playlist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPlaylistSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading playlist: %w", err)
+	}
+	if len(data) > maxPlaylistSize {
+		return nil, fmt.Errorf("playlist exceeds %d bytes", maxPlaylistSize)
+	}
+
+	return data, nil
+}
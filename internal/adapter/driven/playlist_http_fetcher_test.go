@@ -0,0 +1,41 @@
+package driven
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPlaylistHTTPFetcher_FetchPlaylist(t *testing.T) {
+	playlist := "#EXTM3U\n#EXTINF:-1,HBO\nacestream://abc\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/list.m3u":
+			_, _ = w.Write([]byte(playlist))
+		case "/huge.m3u":
+			_, _ = w.Write(make([]byte, maxPlaylistSize+1))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewPlaylistHTTPFetcher(nil)
+	ctx := context.Background()
+
+	data, err := fetcher.FetchPlaylist(ctx, server.URL+"/list.m3u")
+	if err != nil {
+		t.Fatalf("FetchPlaylist() error = %v", err)
+	}
+	if string(data) != playlist {
+		t.Errorf("FetchPlaylist() = %q, want %q", data, playlist)
+	}
+
+	for _, path := range []string{"/huge.m3u", "/missing.m3u"} {
+		if _, err := fetcher.FetchPlaylist(ctx, server.URL+path); err == nil {
+			t.Errorf("FetchPlaylist(%s) expected error", path)
+		}
+	}
+}
@@ -184,13 +184,15 @@ func (r *ProbeBoltDBRepository) FindByInfoHashSince(ctx context.Context, infoHas
 	return results, nil
 }
 
-// DeleteBefore removes all probe results older than the given time.
-func (r *ProbeBoltDBRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+// DeleteBefore removes all probe results older than the given time and
+// returns the number of results removed.
+func (r *ProbeBoltDBRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
 	if err := ctx.Err(); err != nil {
-		return err
+		return 0, err
 	}
 
-	return r.db.Update(func(tx *bbolt.Tx) error {
+	deleted := 0
+	err := r.db.Update(func(tx *bbolt.Tx) error {
 		top := tx.Bucket([]byte(probesBucket))
 		if top == nil {
 			return errors.New("probes bucket not found")
@@ -227,11 +229,17 @@ func (r *ProbeBoltDBRepository) DeleteBefore(ctx context.Context, before time.Ti
 				if err := sub.Delete(dk); err != nil {
 					return err
 				}
+				deleted++
 			}
 
 			return nil
 		})
 	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
 }
 
 // timestampToKey converts a time.Time to an 8-byte big-endian key.
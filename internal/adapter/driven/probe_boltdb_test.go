@@ -181,9 +181,13 @@ func TestProbeBoltDBRepository_DeleteBefore(t *testing.T) {
 
 	// Delete everything older than 24 hours
 	cutoff := now.Add(-24 * time.Hour)
-	if err := repo.DeleteBefore(ctx, cutoff); err != nil {
+	deleted, err := repo.DeleteBefore(ctx, cutoff)
+	if err != nil {
 		t.Fatalf("DeleteBefore failed: %v", err)
 	}
+	if deleted != 2 {
+		t.Errorf("expected 2 results deleted, got %d", deleted)
+	}
 
 	// abc123 should have 1 result left (the recent one)
 	results, err := repo.FindByInfoHash(ctx, "abc123")
@@ -230,7 +234,7 @@ func TestProbeBoltDBRepository_ContextCancellation(t *testing.T) {
 		t.Error("expected error for cancelled context on FindByInfoHashSince")
 	}
 
-	if err := repo.DeleteBefore(ctx, time.Now()); err == nil {
+	if _, err := repo.DeleteBefore(ctx, time.Now()); err == nil {
 		t.Error("expected error for cancelled context on DeleteBefore")
 	}
 }
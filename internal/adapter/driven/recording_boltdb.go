@@ -0,0 +1,186 @@
+package driven
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+const recordingsBucket = "recordings"
+
+// RecordingBoltDBRepository implements the RecordingRepository port using BoltDB.
+type RecordingBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewRecordingBoltDBRepository creates a new BoltDB-backed recording repository.
+// It initializes the required bucket if it doesn't exist.
+func NewRecordingBoltDBRepository(db *bbolt.DB) (*RecordingBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(recordingsBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordingBoltDBRepository{db: db}, nil
+}
+
+// recordingDTO is used for JSON serialization.
+type recordingDTO struct {
+	ID          string `json:"id"`
+	ChannelName string `json:"channel_name"`
+	StartAt     int64  `json:"start_at"`
+	StopAt      int64  `json:"stop_at"`
+	Status      string `json:"status"`
+	Size        int64  `json:"size"`
+	LastError   string `json:"last_error,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+func recordingToDTO(r recording.Recording) recordingDTO {
+	return recordingDTO{
+		ID:          r.ID(),
+		ChannelName: r.ChannelName(),
+		StartAt:     r.StartAt().UnixNano(),
+		StopAt:      r.StopAt().UnixNano(),
+		Status:      string(r.Status()),
+		Size:        r.Size(),
+		LastError:   r.LastError(),
+		CreatedAt:   r.CreatedAt().UnixNano(),
+	}
+}
+
+func (d recordingDTO) toDomain() recording.Recording {
+	return recording.ReconstructRecording(d.ID, d.ChannelName,
+		time.Unix(0, d.StartAt), time.Unix(0, d.StopAt),
+		recording.Status(d.Status), d.Size, d.LastError, time.Unix(0, d.CreatedAt))
+}
+
+// Save persists a new recording to BoltDB.
+func (r *RecordingBoltDBRepository) Save(ctx context.Context, rec recording.Recording) error {
+	return r.put(ctx, rec, false)
+}
+
+// Update persists changes to an existing recording in BoltDB.
+func (r *RecordingBoltDBRepository) Update(ctx context.Context, rec recording.Recording) error {
+	return r.put(ctx, rec, true)
+}
+
+func (r *RecordingBoltDBRepository) put(ctx context.Context, rec recording.Recording, mustExist bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(recordingsBucket))
+		if bucket == nil {
+			return errors.New("recordings bucket not found")
+		}
+
+		key := []byte(rec.ID())
+		if mustExist && bucket.Get(key) == nil {
+			return recording.ErrRecordingNotFound
+		}
+
+		data, err := json.Marshal(recordingToDTO(rec))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, data)
+	})
+}
+
+// FindAll retrieves all recordings from BoltDB, ordered by start time.
+func (r *RecordingBoltDBRepository) FindAll(ctx context.Context) ([]recording.Recording, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	recordings := []recording.Recording{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(recordingsBucket))
+		if bucket == nil {
+			return errors.New("recordings bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var dto recordingDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			recordings = append(recordings, dto.toDomain())
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(recordings, func(i, j int) bool {
+		return recordings[i].StartAt().Before(recordings[j].StartAt())
+	})
+	return recordings, nil
+}
+
+// FindByID retrieves a recording by its ID from BoltDB.
+func (r *RecordingBoltDBRepository) FindByID(ctx context.Context, id string) (recording.Recording, error) {
+	if err := ctx.Err(); err != nil {
+		return recording.Recording{}, err
+	}
+
+	var rec recording.Recording
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(recordingsBucket))
+		if bucket == nil {
+			return errors.New("recordings bucket not found")
+		}
+
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return recording.ErrRecordingNotFound
+		}
+
+		var dto recordingDTO
+		if err := json.Unmarshal(data, &dto); err != nil {
+			return err
+		}
+		rec = dto.toDomain()
+		return nil
+	})
+
+	return rec, err
+}
+
+// Delete removes a recording by its ID from BoltDB.
+func (r *RecordingBoltDBRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(recordingsBucket))
+		if bucket == nil {
+			return errors.New("recordings bucket not found")
+		}
+
+		key := []byte(id)
+		if bucket.Get(key) == nil {
+			return recording.ErrRecordingNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
@@ -0,0 +1,105 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+func TestNewRecordingBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewRecordingBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestRecordingBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+
+	newRepo := func(t *testing.T) *RecordingBoltDBRepository {
+		db, cleanup := setupTestDB(t)
+		t.Cleanup(cleanup)
+		repo, err := NewRecordingBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+		return repo
+	}
+
+	t.Run("saves, updates and finds a recording", func(t *testing.T) {
+		repo := newRepo(t)
+		rec, _ := recording.NewRecording("News", now.Add(time.Hour), now.Add(2*time.Hour), now)
+
+		if err := repo.Save(ctx, rec); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		rec.Start()
+		rec.Finish(1024, errors.New("stream ended"))
+		if err := repo.Update(ctx, rec); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		found, err := repo.FindByID(ctx, rec.ID())
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		if found.ChannelName() != "News" || found.Status() != recording.StatusFailed ||
+			found.Size() != 1024 || found.LastError() != "stream ended" {
+			t.Errorf("FindByID() = (%q, %q, %d, %q), want (News, failed, 1024, stream ended)",
+				found.ChannelName(), found.Status(), found.Size(), found.LastError())
+		}
+		if !found.StartAt().Equal(rec.StartAt()) || !found.StopAt().Equal(rec.StopAt()) || !found.CreatedAt().Equal(now) {
+			t.Errorf("FindByID() times = (%v, %v, %v), want (%v, %v, %v)",
+				found.StartAt(), found.StopAt(), found.CreatedAt(), rec.StartAt(), rec.StopAt(), now)
+		}
+	})
+
+	t.Run("lists recordings by start time", func(t *testing.T) {
+		repo := newRepo(t)
+		late, _ := recording.NewRecording("Late", now.Add(3*time.Hour), now.Add(4*time.Hour), now)
+		early, _ := recording.NewRecording("Early", now.Add(time.Hour), now.Add(2*time.Hour), now)
+		_ = repo.Save(ctx, late)
+		_ = repo.Save(ctx, early)
+
+		all, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll() error = %v", err)
+		}
+		if len(all) != 2 || all[0].ChannelName() != "Early" || all[1].ChannelName() != "Late" {
+			t.Errorf("FindAll() returned %d recordings in the wrong order", len(all))
+		}
+	})
+
+	t.Run("reports missing recordings", func(t *testing.T) {
+		repo := newRepo(t)
+		rec, _ := recording.NewRecording("News", now, now.Add(time.Hour), now)
+
+		if _, err := repo.FindByID(ctx, rec.ID()); !errors.Is(err, recording.ErrRecordingNotFound) {
+			t.Errorf("FindByID() error = %v, want ErrRecordingNotFound", err)
+		}
+		if err := repo.Update(ctx, rec); !errors.Is(err, recording.ErrRecordingNotFound) {
+			t.Errorf("Update() error = %v, want ErrRecordingNotFound", err)
+		}
+		if err := repo.Delete(ctx, rec.ID()); !errors.Is(err, recording.ErrRecordingNotFound) {
+			t.Errorf("Delete() error = %v, want ErrRecordingNotFound", err)
+		}
+
+		_ = repo.Save(ctx, rec)
+		if err := repo.Delete(ctx, rec.ID()); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.FindByID(ctx, rec.ID()); !errors.Is(err, recording.ErrRecordingNotFound) {
+			t.Errorf("expected recording to be deleted, got %v", err)
+		}
+	})
+}
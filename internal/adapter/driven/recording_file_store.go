@@ -0,0 +1,88 @@
+package driven
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+// RecordingFileStore implements the RecordingStore port on the local
+// filesystem, keeping each recording as <id>.ts. Only the hexadecimal IDs
+// produced by recording.NewRecording are accepted, so IDs cannot escape the
+// directory.
+type RecordingFileStore struct {
+	dir string
+}
+
+// NewRecordingFileStore creates a recording store rooted at dir, creating it if needed.
+func NewRecordingFileStore(dir string) (*RecordingFileStore, error) {
+	if dir == "" {
+		return nil, errors.New("recording directory cannot be empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating recording directory: %w", err)
+	}
+	return &RecordingFileStore{dir: dir}, nil
+}
+
+// Create truncates or creates the recording's file for writing.
+func (s *RecordingFileStore) Create(ctx context.Context, id string) (io.WriteCloser, error) {
+	path, err := s.path(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
+
+// Open opens the recording's file for reading.
+func (s *RecordingFileStore) Open(ctx context.Context, id string) (io.ReadSeekCloser, time.Time, error) {
+	path, err := s.path(ctx, id)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, time.Time{}, recording.ErrNoFile
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, time.Time{}, err
+	}
+	return f, info.ModTime(), nil
+}
+
+// Delete removes the recording's file if it exists.
+func (s *RecordingFileStore) Delete(ctx context.Context, id string) error {
+	path, err := s.path(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *RecordingFileStore) path(ctx context.Context, id string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if _, err := hex.DecodeString(id); err != nil || id == "" {
+		return "", fmt.Errorf("invalid recording id %q", id)
+	}
+	return filepath.Join(s.dir, id+".ts"), nil
+}
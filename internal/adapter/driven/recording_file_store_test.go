@@ -0,0 +1,62 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+func TestRecordingFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "recordings")
+
+	store, err := NewRecordingFileStore(dir)
+	if err != nil {
+		t.Fatalf("NewRecordingFileStore() error = %v", err)
+	}
+
+	if _, _, err := store.Open(ctx, "0a1b"); !errors.Is(err, recording.ErrNoFile) {
+		t.Errorf("Open() error = %v, want ErrNoFile", err)
+	}
+
+	w, err := store.Create(ctx, "0a1b")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := w.Write([]byte("ts-data")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	f, modTime, err := store.Open(ctx, "0a1b")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "ts-data" || modTime.IsZero() {
+		t.Errorf("Open() = (%q, %v), want ts-data with a modification time", data, modTime)
+	}
+
+	for _, id := range []string{"", "../escape", "not-hex"} {
+		if _, err := store.Create(ctx, id); err == nil {
+			t.Errorf("expected Create(%q) to reject the ID", id)
+		}
+	}
+
+	if err := store.Delete(ctx, "0a1b"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, _, err := store.Open(ctx, "0a1b"); !errors.Is(err, recording.ErrNoFile) {
+		t.Errorf("expected file to be deleted, got %v", err)
+	}
+	if err := store.Delete(ctx, "0a1b"); err != nil {
+		t.Errorf("Delete() of a missing file error = %v, want nil", err)
+	}
+}
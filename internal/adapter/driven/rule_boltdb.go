@@ -0,0 +1,173 @@
+package driven
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/rule"
+)
+
+const rulesBucket = "override_rules"
+
+// RuleBoltDBRepository implements the RuleRepository port using BoltDB.
+type RuleBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewRuleBoltDBRepository creates a new BoltDB-backed override rule repository.
+// It initializes the required bucket if it doesn't exist.
+func NewRuleBoltDBRepository(db *bbolt.DB) (*RuleBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(rulesBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &RuleBoltDBRepository{db: db}, nil
+}
+
+// ruleDTO is used for JSON serialization.
+type ruleDTO struct {
+	ID          string `json:"id"`
+	NamePattern string `json:"name_pattern,omitempty"`
+	MatchGroup  string `json:"match_group,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Rename      string `json:"rename,omitempty"`
+	SetGroup    string `json:"set_group,omitempty"`
+	TVGID       string `json:"tvg_id,omitempty"`
+	Disable     bool   `json:"disable,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+func ruleToDTO(r rule.Rule) ruleDTO {
+	m, a := r.Match(), r.Action()
+	return ruleDTO{
+		ID:          r.ID(),
+		NamePattern: m.NamePattern,
+		MatchGroup:  m.Group,
+		Source:      m.Source,
+		Rename:      a.Rename,
+		SetGroup:    a.Group,
+		TVGID:       a.TVGID,
+		Disable:     a.Disable,
+		CreatedAt:   r.CreatedAt().UnixNano(),
+	}
+}
+
+func (d ruleDTO) toDomain() rule.Rule {
+	return rule.ReconstructRule(d.ID,
+		rule.Match{NamePattern: d.NamePattern, Group: d.MatchGroup, Source: d.Source},
+		rule.Action{Rename: d.Rename, Group: d.SetGroup, TVGID: d.TVGID, Disable: d.Disable},
+		time.Unix(0, d.CreatedAt))
+}
+
+// Save persists a new rule to BoltDB.
+func (r *RuleBoltDBRepository) Save(ctx context.Context, ru rule.Rule) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(rulesBucket))
+		if bucket == nil {
+			return errors.New("override_rules bucket not found")
+		}
+
+		data, err := json.Marshal(ruleToDTO(ru))
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(ru.ID()), data)
+	})
+}
+
+// FindAll retrieves all rules from BoltDB in the order they apply.
+func (r *RuleBoltDBRepository) FindAll(ctx context.Context) ([]rule.Rule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rules := []rule.Rule{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(rulesBucket))
+		if bucket == nil {
+			return errors.New("override_rules bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var dto ruleDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			rules = append(rules, dto.toDomain())
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Sort(rules)
+	return rules, nil
+}
+
+// FindByID retrieves a rule by its ID from BoltDB.
+func (r *RuleBoltDBRepository) FindByID(ctx context.Context, id string) (rule.Rule, error) {
+	if err := ctx.Err(); err != nil {
+		return rule.Rule{}, err
+	}
+
+	var ru rule.Rule
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(rulesBucket))
+		if bucket == nil {
+			return errors.New("override_rules bucket not found")
+		}
+
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return rule.ErrRuleNotFound
+		}
+
+		var dto ruleDTO
+		if err := json.Unmarshal(data, &dto); err != nil {
+			return err
+		}
+		ru = dto.toDomain()
+		return nil
+	})
+
+	return ru, err
+}
+
+// Delete removes a rule by its ID from BoltDB.
+func (r *RuleBoltDBRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(rulesBucket))
+		if bucket == nil {
+			return errors.New("override_rules bucket not found")
+		}
+
+		key := []byte(id)
+		if bucket.Get(key) == nil {
+			return rule.ErrRuleNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
@@ -0,0 +1,74 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/rule"
+)
+
+func TestNewRuleBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewRuleBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestRuleBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	repo, err := NewRuleBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	later, _ := rule.NewRule(rule.Match{Group: "Adult"}, rule.Action{Disable: true}, now.Add(time.Minute))
+	first, _ := rule.NewRule(
+		rule.Match{NamePattern: "^(.*) HD$", Group: "Sports", Source: "new-era"},
+		rule.Action{Rename: "$1", Group: "Deportes", TVGID: "dazn.es"},
+		now)
+	for _, r := range []rule.Rule{later, first} {
+		if err := repo.Save(ctx, r); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	all, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].ID() != first.ID() || all[1].ID() != later.ID() {
+		t.Fatalf("FindAll() returned %d rules in the wrong order", len(all))
+	}
+
+	found, err := repo.FindByID(ctx, first.ID())
+	if err != nil {
+		t.Fatalf("FindByID() error = %v", err)
+	}
+	if found.Match() != first.Match() || found.Action() != first.Action() || !found.CreatedAt().Equal(now) {
+		t.Errorf("FindByID() = (%+v, %+v), want (%+v, %+v)", found.Match(), found.Action(), first.Match(), first.Action())
+	}
+	if !found.Matches(rule.Subject{ChannelName: "DAZN HD", Group: "sports", Source: "new-era"}) {
+		t.Error("expected the reloaded rule to keep its compiled pattern")
+	}
+
+	if err := repo.Delete(ctx, first.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.FindByID(ctx, first.ID()); !errors.Is(err, rule.ErrRuleNotFound) {
+		t.Errorf("FindByID() error = %v, want ErrRuleNotFound", err)
+	}
+	if err := repo.Delete(ctx, first.ID()); !errors.Is(err, rule.ErrRuleNotFound) {
+		t.Errorf("Delete() error = %v, want ErrRuleNotFound", err)
+	}
+}
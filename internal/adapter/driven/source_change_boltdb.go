@@ -0,0 +1,228 @@
+package driven
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/sourcechange"
+)
+
+const (
+	sourceSnapshotsBucket = "source_snapshots"
+	sourceChangesBucket   = "source_changes"
+)
+
+// SourceChangeBoltDBRepository implements the SourceChangeRepository port
+// using BoltDB. Snapshots are keyed by source; changes use nested buckets,
+// source_changes/<source>, keyed by detection time and a sequence number so
+// changes detected together keep their order.
+type SourceChangeBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewSourceChangeBoltDBRepository creates a new BoltDB-backed source change
+// repository. It initializes the required buckets if they don't exist.
+func NewSourceChangeBoltDBRepository(db *bbolt.DB) (*SourceChangeBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range []string{sourceSnapshotsBucket, sourceChangesBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SourceChangeBoltDBRepository{db: db}, nil
+}
+
+// sourceChangeDTO is the JSON serialization format for a change.
+type sourceChangeDTO struct {
+	Source      string `json:"source"`
+	ChannelName string `json:"channel_name"`
+	InfoHash    string `json:"infohash"`
+	Kind        string `json:"kind"`
+	DetectedAt  int64  `json:"detected_at"`
+}
+
+// FindSnapshot retrieves the hashes of the last refresh of a source.
+func (r *SourceChangeBoltDBRepository) FindSnapshot(ctx context.Context, source string) (map[string][]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var snapshot map[string][]string
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(sourceSnapshotsBucket))
+		if bucket == nil {
+			return errors.New("source_snapshots bucket not found")
+		}
+
+		data := bucket.Get([]byte(source))
+		if data == nil {
+			return sourcechange.ErrSnapshotNotFound
+		}
+		return json.Unmarshal(data, &snapshot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// Record saves a new snapshot of a source and its changes in a single
+// transaction.
+func (r *SourceChangeBoltDBRepository) Record(ctx context.Context, source string, snapshot map[string][]string, changes []sourcechange.Change) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		snapshots := tx.Bucket([]byte(sourceSnapshotsBucket))
+		if snapshots == nil {
+			return errors.New("source_snapshots bucket not found")
+		}
+		top := tx.Bucket([]byte(sourceChangesBucket))
+		if top == nil {
+			return errors.New("source_changes bucket not found")
+		}
+
+		data, err := json.Marshal(snapshot)
+		if err != nil {
+			return err
+		}
+		if err := snapshots.Put([]byte(source), data); err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			return nil
+		}
+		sub, err := top.CreateBucketIfNotExists([]byte(source))
+		if err != nil {
+			return err
+		}
+		for _, c := range changes {
+			data, err := json.Marshal(sourceChangeDTO{
+				Source:      c.Source(),
+				ChannelName: c.ChannelName(),
+				InfoHash:    c.InfoHash(),
+				Kind:        string(c.Kind()),
+				DetectedAt:  c.DetectedAt().UnixNano(),
+			})
+			if err != nil {
+				return err
+			}
+
+			seq, err := sub.NextSequence()
+			if err != nil {
+				return err
+			}
+			key := binary.BigEndian.AppendUint64(timestampToKey(c.DetectedAt()), seq)
+			if err := sub.Put(key, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindBySource retrieves up to limit changes of a source, most recent first.
+func (r *SourceChangeBoltDBRepository) FindBySource(ctx context.Context, source string, limit int) ([]sourcechange.Change, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	changes := []sourcechange.Change{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		top := tx.Bucket([]byte(sourceChangesBucket))
+		if top == nil {
+			return errors.New("source_changes bucket not found")
+		}
+
+		sub := top.Bucket([]byte(source))
+		if sub == nil {
+			return nil
+		}
+
+		c := sub.Cursor()
+		for k, v := c.Last(); k != nil && (limit <= 0 || len(changes) < limit); k, v = c.Prev() {
+			var dto sourceChangeDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			changes = append(changes, sourcechange.ReconstructChange(
+				dto.Source, dto.ChannelName, dto.InfoHash, sourcechange.Kind(dto.Kind), time.Unix(0, dto.DetectedAt)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// DeleteBefore removes all changes detected before the given time and
+// returns the number of changes removed.
+func (r *SourceChangeBoltDBRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		top := tx.Bucket([]byte(sourceChangesBucket))
+		if top == nil {
+			return errors.New("source_changes bucket not found")
+		}
+
+		beforeKey := timestampToKey(before)
+
+		return top.ForEach(func(k, v []byte) error {
+			// v is nil for nested buckets
+			if v != nil {
+				return nil
+			}
+
+			sub := top.Bucket(k)
+			if sub == nil {
+				return nil
+			}
+
+			// Collect keys to delete (can't delete during iteration)
+			var keysToDelete [][]byte
+			c := sub.Cursor()
+			for ck, _ := c.First(); ck != nil && compareKeys(ck[:8], beforeKey) < 0; ck, _ = c.Next() {
+				keyCopy := make([]byte, len(ck))
+				copy(keyCopy, ck)
+				keysToDelete = append(keysToDelete, keyCopy)
+			}
+
+			for _, dk := range keysToDelete {
+				if err := sub.Delete(dk); err != nil {
+					return err
+				}
+				deleted++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
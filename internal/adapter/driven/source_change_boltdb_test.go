@@ -0,0 +1,95 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/sourcechange"
+)
+
+func TestNewSourceChangeBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewSourceChangeBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestSourceChangeBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	first := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	repo, err := NewSourceChangeBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	if _, err := repo.FindSnapshot(ctx, "new-era"); !errors.Is(err, sourcechange.ErrSnapshotNotFound) {
+		t.Fatalf("FindSnapshot() error = %v, want ErrSnapshotNotFound", err)
+	}
+
+	v1 := map[string][]string{"La 1": {"a1", "a2"}}
+	v2 := map[string][]string{"La 1": {"a2", "a3"}}
+	v3 := map[string][]string{"La 1": {"a3"}}
+	if err := repo.Record(ctx, "new-era", v1, nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := repo.Record(ctx, "new-era", v2, sourcechange.Diff("new-era", v1, v2, first)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := repo.Record(ctx, "new-era", v3, sourcechange.Diff("new-era", v2, v3, second)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	snapshot, err := repo.FindSnapshot(ctx, "new-era")
+	if err != nil {
+		t.Fatalf("FindSnapshot() error = %v", err)
+	}
+	if !slices.Equal(snapshot["La 1"], []string{"a3"}) {
+		t.Errorf("FindSnapshot() = %v, want the last refresh", snapshot)
+	}
+
+	changes, err := repo.FindBySource(ctx, "new-era", 0)
+	if err != nil {
+		t.Fatalf("FindBySource() error = %v", err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("FindBySource() returned %d changes, want 3", len(changes))
+	}
+	latest := changes[0]
+	if latest.InfoHash() != "a2" || latest.Kind() != sourcechange.KindRemoved || latest.ChannelName() != "La 1" || !latest.DetectedAt().Equal(second) {
+		t.Errorf("unexpected latest change %+v", latest)
+	}
+	// Changes detected together are returned in reverse of their recorded order.
+	if changes[1].InfoHash() != "a3" || changes[2].InfoHash() != "a1" {
+		t.Errorf("unexpected order %+v", changes)
+	}
+
+	if limited, _ := repo.FindBySource(ctx, "new-era", 1); len(limited) != 1 {
+		t.Errorf("expected the limit to apply, got %d changes", len(limited))
+	}
+	if other, _ := repo.FindBySource(ctx, "elcano", 0); len(other) != 0 {
+		t.Errorf("expected no changes for another source, got %d", len(other))
+	}
+
+	deleted, err := repo.DeleteBefore(ctx, second)
+	if err != nil {
+		t.Fatalf("DeleteBefore() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteBefore() = %d, want 2", deleted)
+	}
+	if changes, _ := repo.FindBySource(ctx, "new-era", 0); len(changes) != 1 {
+		t.Errorf("expected 1 change left, got %d", len(changes))
+	}
+}
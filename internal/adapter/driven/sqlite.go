@@ -0,0 +1,116 @@
+package driven
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	// Register the pure-Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// sqliteMigrations are applied in order to bring a database up to the latest
+// schema. The index of a migration plus one is its schema version, recorded
+// in PRAGMA user_version. Never edit or reorder an existing entry; append a
+// new one instead.
+var sqliteMigrations = []string{
+	`CREATE TABLE channels (
+		name            TEXT PRIMARY KEY,
+		status          TEXT NOT NULL,
+		epg_id          TEXT,
+		epg_source      TEXT,
+		epg_last_synced TEXT
+	);
+	CREATE INDEX idx_channels_epg_id ON channels (epg_id);
+	CREATE TABLE streams (
+		info_hash    TEXT PRIMARY KEY,
+		channel_name TEXT NOT NULL,
+		source       TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX idx_streams_channel_name ON streams (channel_name);`,
+	`ALTER TABLE channels ADD COLUMN transcode_audio TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE channels ADD COLUMN group_id TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE channels ADD COLUMN number INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE channels ADD COLUMN epg_confidence REAL NOT NULL DEFAULT 1;`,
+	`ALTER TABLE channels ADD COLUMN aliases TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE channels ADD COLUMN stream_qualities TEXT NOT NULL DEFAULT '';
+	ALTER TABLE channels ADD COLUMN quality_preference TEXT NOT NULL DEFAULT '';
+	ALTER TABLE channels ADD COLUMN variants TEXT NOT NULL DEFAULT '';`,
+	`CREATE TABLE channel_tags (
+		tag          TEXT NOT NULL,
+		channel_name TEXT NOT NULL,
+		PRIMARY KEY (tag, channel_name)
+	);
+	CREATE INDEX idx_channel_tags_channel_name ON channel_tags (channel_name);`,
+	`ALTER TABLE channels ADD COLUMN tvg_shift_minutes INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE channels ADD COLUMN transcode_video TEXT NOT NULL DEFAULT '';`,
+}
+
+// OpenSQLite opens the SQLite database at path in WAL mode and applies any
+// pending schema migrations.
+func OpenSQLite(ctx context.Context, path string) (*sql.DB, error) {
+	// WAL lets playlist reads proceed while a sync is writing; busy_timeout
+	// makes concurrent writers wait for the lock instead of failing.
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": {"journal_mode(WAL)", "busy_timeout(5000)", "synchronous(NORMAL)"},
+	}.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateSQLite(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// migrateSQLite applies every migration newer than the database's current
+// schema version, each in its own transaction.
+func migrateSQLite(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if version > len(sqliteMigrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, len(sqliteMigrations))
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply migration %d: %w", i+1, err)
+		}
+
+		// PRAGMA does not accept bound parameters.
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", i+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// rowsAffected reports whether a write statement changed at least one row.
+func rowsAffected(res sql.Result) (bool, error) {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -78,6 +78,36 @@ func (r *StreamBoltDBRepository) Save(ctx context.Context, s stream.Stream) erro
 	})
 }
 
+// Update replaces an existing stream in BoltDB.
+func (r *StreamBoltDBRepository) Update(ctx context.Context, s stream.Stream) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(streamsBucket))
+		if bucket == nil {
+			return errors.New("streams bucket not found")
+		}
+
+		key := []byte(s.InfoHash())
+		if bucket.Get(key) == nil {
+			return stream.ErrStreamNotFound
+		}
+
+		data, err := json.Marshal(streamDTO{
+			InfoHash:    s.InfoHash(),
+			ChannelName: s.ChannelName(),
+			Source:      s.Source(),
+		})
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, data)
+	})
+}
+
 // FindByInfoHash retrieves a stream by its infohash from BoltDB.
 func (r *StreamBoltDBRepository) FindByInfoHash(ctx context.Context, infoHash string) (stream.Stream, error) {
 	// Check context cancellation
@@ -103,10 +133,7 @@ func (r *StreamBoltDBRepository) FindByInfoHash(ctx context.Context, infoHash st
 			return err
 		}
 
-		reconstructed, err := stream.NewStream(dto.InfoHash, dto.ChannelName, dto.Source)
-		if err != nil {
-			return err
-		}
+		reconstructed := stream.ReconstructStream(dto.InfoHash, dto.ChannelName, dto.Source)
 
 		s = reconstructed
 		return nil
@@ -136,12 +163,7 @@ func (r *StreamBoltDBRepository) FindAll(ctx context.Context) ([]stream.Stream,
 				return err
 			}
 
-			s, err := stream.NewStream(dto.InfoHash, dto.ChannelName, dto.Source)
-			if err != nil {
-				return err
-			}
-
-			streams = append(streams, s)
+			streams = append(streams, stream.ReconstructStream(dto.InfoHash, dto.ChannelName, dto.Source))
 			return nil
 		})
 	})
@@ -183,12 +205,7 @@ func (r *StreamBoltDBRepository) FindByChannelName(ctx context.Context, channelN
 				return nil
 			}
 
-			s, err := stream.NewStream(dto.InfoHash, dto.ChannelName, dto.Source)
-			if err != nil {
-				return err
-			}
-
-			streams = append(streams, s)
+			streams = append(streams, stream.ReconstructStream(dto.InfoHash, dto.ChannelName, dto.Source))
 			return nil
 		})
 	})
@@ -56,7 +56,7 @@ func TestStreamBoltDBRepository_Save(t *testing.T) {
 			t.Fatalf("failed to create repository: %v", err)
 		}
 
-		s, err := stream.NewStream("abc123", "HBO", "")
+		s, err := stream.NewStream("6162633132330000000000000000000000000000", "HBO", "")
 		if err != nil {
 			t.Fatalf("failed to create stream: %v", err)
 		}
@@ -68,12 +68,12 @@ func TestStreamBoltDBRepository_Save(t *testing.T) {
 		}
 
 		// Verify the stream was saved
-		found, err := repo.FindByInfoHash(ctx, "abc123")
+		found, err := repo.FindByInfoHash(ctx, "6162633132330000000000000000000000000000")
 		if err != nil {
 			t.Fatalf("failed to find saved stream: %v", err)
 		}
-		if found.InfoHash() != "abc123" {
-			t.Errorf("expected infohash 'abc123', got %q", found.InfoHash())
+		if found.InfoHash() != "6162633132330000000000000000000000000000" {
+			t.Errorf("expected infohash %q, got %q", "6162633132330000000000000000000000000000", found.InfoHash())
 		}
 		if found.ChannelName() != "HBO" {
 			t.Errorf("expected channel name 'HBO', got %q", found.ChannelName())
@@ -89,7 +89,7 @@ func TestStreamBoltDBRepository_Save(t *testing.T) {
 			t.Fatalf("failed to create repository: %v", err)
 		}
 
-		s, err := stream.NewStream("def456", "ESPN", "")
+		s, err := stream.NewStream("6465663435360000000000000000000000000000", "ESPN", "")
 		if err != nil {
 			t.Fatalf("failed to create stream: %v", err)
 		}
@@ -118,7 +118,7 @@ func TestStreamBoltDBRepository_Save(t *testing.T) {
 			t.Fatalf("failed to create repository: %v", err)
 		}
 
-		s, err := stream.NewStream("ghi789", "CNN", "")
+		s, err := stream.NewStream("6768693738390000000000000000000000000000", "CNN", "")
 		if err != nil {
 			t.Fatalf("failed to create stream: %v", err)
 		}
@@ -133,6 +133,52 @@ func TestStreamBoltDBRepository_Save(t *testing.T) {
 	})
 }
 
+func TestStreamBoltDBRepository_Update(t *testing.T) {
+	t.Run("moves a stream to another channel", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo, err := NewStreamBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+
+		s, _ := stream.NewStream("6a6b6c3031320000000000000000000000000000", "Discovery", stream.SourceNewEra)
+		ctx := context.Background()
+		if err := repo.Save(ctx, s); err != nil {
+			t.Fatalf("failed to save stream: %v", err)
+		}
+
+		moved, _ := s.MoveTo("Discovery HD")
+		if err := repo.Update(ctx, moved); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByInfoHash(ctx, s.InfoHash())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if found.ChannelName() != "Discovery HD" || found.Source() != stream.SourceNewEra {
+			t.Errorf("expected the stream on Discovery HD from new-era, got %q from %q", found.ChannelName(), found.Source())
+		}
+	})
+
+	t.Run("returns ErrStreamNotFound for non-existent stream", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo, err := NewStreamBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+
+		s, _ := stream.NewStream("6a6b6c3031320000000000000000000000000000", "Discovery", "")
+		if err := repo.Update(context.Background(), s); err != stream.ErrStreamNotFound {
+			t.Errorf("expected ErrStreamNotFound, got %v", err)
+		}
+	})
+}
+
 func TestStreamBoltDBRepository_FindByInfoHash(t *testing.T) {
 	t.Run("finds existing stream", func(t *testing.T) {
 		db, cleanup := setupTestDB(t)
@@ -143,7 +189,7 @@ func TestStreamBoltDBRepository_FindByInfoHash(t *testing.T) {
 			t.Fatalf("failed to create repository: %v", err)
 		}
 
-		s, err := stream.NewStream("jkl012", "Discovery", "")
+		s, err := stream.NewStream("6a6b6c3031320000000000000000000000000000", "Discovery", "")
 		if err != nil {
 			t.Fatalf("failed to create stream: %v", err)
 		}
@@ -154,11 +200,11 @@ func TestStreamBoltDBRepository_FindByInfoHash(t *testing.T) {
 			t.Fatalf("failed to save stream: %v", err)
 		}
 
-		found, err := repo.FindByInfoHash(ctx, "jkl012")
+		found, err := repo.FindByInfoHash(ctx, "6a6b6c3031320000000000000000000000000000")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if found.InfoHash() != "jkl012" {
+		if found.InfoHash() != "6a6b6c3031320000000000000000000000000000" {
 			t.Errorf("expected infohash 'jkl012', got %q", found.InfoHash())
 		}
 		if found.ChannelName() != "Discovery" {
@@ -240,10 +286,10 @@ func TestStreamBoltDBRepository_FindAll(t *testing.T) {
 			infoHash    string
 			channelName string
 		}{
-			{"hash1", "HBO"},
-			{"hash2", "ESPN"},
-			{"hash3", "CNN"},
-			{"hash4", "Discovery"},
+			{"6861736831000000000000000000000000000000", "HBO"},
+			{"6861736832000000000000000000000000000000", "ESPN"},
+			{"6861736833000000000000000000000000000000", "CNN"},
+			{"6861736834000000000000000000000000000000", "Discovery"},
 		}
 
 		for _, ts := range testStreams {
@@ -337,11 +383,11 @@ func TestStreamBoltDBRepository_FindByChannelName(t *testing.T) {
 			infoHash    string
 			channelName string
 		}{
-			{"hash1", "HBO"},
-			{"hash2", "HBO"},
-			{"hash3", "ESPN"},
-			{"hash4", "HBO"},
-			{"hash5", "CNN"},
+			{"6861736831000000000000000000000000000000", "HBO"},
+			{"6861736832000000000000000000000000000000", "HBO"},
+			{"6861736833000000000000000000000000000000", "ESPN"},
+			{"6861736834000000000000000000000000000000", "HBO"},
+			{"6861736835000000000000000000000000000000", "CNN"},
 		}
 
 		for _, ts := range testStreams {
@@ -374,7 +420,7 @@ func TestStreamBoltDBRepository_FindByChannelName(t *testing.T) {
 		}
 
 		// Verify correct infohashes
-		expectedHashes := map[string]bool{"hash1": true, "hash2": true, "hash4": true}
+		expectedHashes := map[string]bool{"6861736831000000000000000000000000000000": true, "6861736832000000000000000000000000000000": true, "6861736834000000000000000000000000000000": true}
 		for _, s := range streams {
 			if !expectedHashes[s.InfoHash()] {
 				t.Errorf("unexpected stream infohash: %q", s.InfoHash())
@@ -411,7 +457,7 @@ func TestStreamBoltDBRepository_Delete(t *testing.T) {
 			t.Fatalf("failed to create repository: %v", err)
 		}
 
-		s, err := stream.NewStream("mno345", "NatGeo", "")
+		s, err := stream.NewStream("6d6e6f3334350000000000000000000000000000", "NatGeo", "")
 		if err != nil {
 			t.Fatalf("failed to create stream: %v", err)
 		}
@@ -422,13 +468,13 @@ func TestStreamBoltDBRepository_Delete(t *testing.T) {
 			t.Fatalf("failed to save stream: %v", err)
 		}
 
-		err = repo.Delete(ctx, "mno345")
+		err = repo.Delete(ctx, "6d6e6f3334350000000000000000000000000000")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
 		// Verify the stream was deleted
-		_, err = repo.FindByInfoHash(ctx, "mno345")
+		_, err = repo.FindByInfoHash(ctx, "6d6e6f3334350000000000000000000000000000")
 		if err != stream.ErrStreamNotFound {
 			t.Errorf("expected ErrStreamNotFound after deletion, got %v", err)
 		}
@@ -486,11 +532,11 @@ func TestStreamBoltDBRepository_DeleteByChannelName(t *testing.T) {
 			infoHash    string
 			channelName string
 		}{
-			{"hash1", "HBO"},
-			{"hash2", "HBO"},
-			{"hash3", "ESPN"},
-			{"hash4", "HBO"},
-			{"hash5", "CNN"},
+			{"6861736831000000000000000000000000000000", "HBO"},
+			{"6861736832000000000000000000000000000000", "HBO"},
+			{"6861736833000000000000000000000000000000", "ESPN"},
+			{"6861736834000000000000000000000000000000", "HBO"},
+			{"6861736835000000000000000000000000000000", "CNN"},
 		}
 
 		for _, ts := range testStreams {
@@ -585,7 +631,7 @@ func TestStreamBoltDBRepository_Integration(t *testing.T) {
 		ctx := context.Background()
 
 		// Create and save
-		s, err := stream.NewStream("integration123", "BBC One", "")
+		s, err := stream.NewStream("696e746567726174696f6e313233000000000000", "BBC One", "")
 		if err != nil {
 			t.Fatalf("failed to create stream: %v", err)
 		}
@@ -596,11 +642,11 @@ func TestStreamBoltDBRepository_Integration(t *testing.T) {
 		}
 
 		// Find by infohash
-		found, err := repo.FindByInfoHash(ctx, "integration123")
+		found, err := repo.FindByInfoHash(ctx, "696e746567726174696f6e313233000000000000")
 		if err != nil {
 			t.Fatalf("failed to find stream: %v", err)
 		}
-		if found.InfoHash() != "integration123" {
+		if found.InfoHash() != "696e746567726174696f6e313233000000000000" {
 			t.Errorf("expected 'integration123', got %q", found.InfoHash())
 		}
 		if found.ChannelName() != "BBC One" {
@@ -626,7 +672,7 @@ func TestStreamBoltDBRepository_Integration(t *testing.T) {
 		}
 
 		// Delete
-		err = repo.Delete(ctx, "integration123")
+		err = repo.Delete(ctx, "696e746567726174696f6e313233000000000000")
 		if err != nil {
 			t.Fatalf("failed to delete stream: %v", err)
 		}
@@ -655,7 +701,7 @@ func TestStreamBoltDBRepository_Integration(t *testing.T) {
 		// Create multiple streams for the same channel
 		channelName := "Sky Sports"
 		for i := 1; i <= 3; i++ {
-			s, err := stream.NewStream("skyhash"+string(rune('0'+i)), channelName, "")
+			s, err := stream.NewStream("736b79686173680000000000000000000000000"+string(rune('0'+i)), channelName, "")
 			if err != nil {
 				t.Fatalf("failed to create stream: %v", err)
 			}
@@ -666,7 +712,7 @@ func TestStreamBoltDBRepository_Integration(t *testing.T) {
 		}
 
 		// Create streams for another channel
-		s, err := stream.NewStream("otherhash", "Other Channel", "")
+		s, err := stream.NewStream("6f74686572686173680000000000000000000000", "Other Channel", "")
 		if err != nil {
 			t.Fatalf("failed to create stream: %v", err)
 		}
@@ -0,0 +1,148 @@
+package driven
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// StreamSQLiteRepository implements the StreamRepository port using SQLite.
+type StreamSQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewStreamSQLiteRepository creates a new SQLite-backed stream repository.
+// The database must have been opened with OpenSQLite so the schema exists.
+func NewStreamSQLiteRepository(db *sql.DB) (*StreamSQLiteRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	return &StreamSQLiteRepository{db: db}, nil
+}
+
+func scanStream(row rowScanner) (stream.Stream, error) {
+	var infoHash, channelName, source string
+	if err := row.Scan(&infoHash, &channelName, &source); err != nil {
+		return stream.Stream{}, err
+	}
+	return stream.ReconstructStream(infoHash, channelName, source), nil
+}
+
+// Save persists a new stream to SQLite.
+// Returns ErrStreamAlreadyExists if a stream with the same infohash already exists.
+func (r *StreamSQLiteRepository) Save(ctx context.Context, s stream.Stream) error {
+	res, err := r.db.ExecContext(ctx,
+		`INSERT INTO streams (info_hash, channel_name, source) VALUES (?, ?, ?)
+		ON CONFLICT (info_hash) DO NOTHING`,
+		s.InfoHash(), s.ChannelName(), s.Source())
+	if err != nil {
+		return err
+	}
+
+	inserted, err := rowsAffected(res)
+	if err != nil {
+		return err
+	}
+	if !inserted {
+		return stream.ErrStreamAlreadyExists
+	}
+	return nil
+}
+
+// Update replaces an existing stream in SQLite.
+// Returns ErrStreamNotFound if the stream doesn't exist.
+func (r *StreamSQLiteRepository) Update(ctx context.Context, s stream.Stream) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE streams SET channel_name = ?, source = ? WHERE info_hash = ?`,
+		s.ChannelName(), s.Source(), s.InfoHash())
+	if err != nil {
+		return err
+	}
+
+	updated, err := rowsAffected(res)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return stream.ErrStreamNotFound
+	}
+	return nil
+}
+
+// FindByInfoHash retrieves a stream by its infohash from SQLite.
+// Returns ErrStreamNotFound if the stream doesn't exist.
+func (r *StreamSQLiteRepository) FindByInfoHash(ctx context.Context, infoHash string) (stream.Stream, error) {
+	row := r.db.QueryRowContext(ctx,
+		`SELECT info_hash, channel_name, source FROM streams WHERE info_hash = ?`, infoHash)
+
+	s, err := scanStream(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return stream.Stream{}, stream.ErrStreamNotFound
+	}
+	return s, err
+}
+
+// FindAll retrieves all streams from SQLite, ordered by infohash.
+// Returns an empty slice if no streams exist.
+func (r *StreamSQLiteRepository) FindAll(ctx context.Context) ([]stream.Stream, error) {
+	return r.query(ctx, `SELECT info_hash, channel_name, source FROM streams ORDER BY info_hash`)
+}
+
+// FindByChannelName retrieves all streams associated with a specific channel from SQLite.
+// Returns an empty slice if the channel has no streams.
+func (r *StreamSQLiteRepository) FindByChannelName(ctx context.Context, channelName string) ([]stream.Stream, error) {
+	return r.query(ctx,
+		`SELECT info_hash, channel_name, source FROM streams WHERE channel_name = ? ORDER BY info_hash`,
+		channelName)
+}
+
+func (r *StreamSQLiteRepository) query(ctx context.Context, query string, args ...any) ([]stream.Stream, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	streams := []stream.Stream{}
+	for rows.Next() {
+		s, err := scanStream(rows)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return streams, nil
+}
+
+// Delete removes a stream by its infohash from SQLite.
+// Returns ErrStreamNotFound if the stream doesn't exist.
+func (r *StreamSQLiteRepository) Delete(ctx context.Context, infoHash string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM streams WHERE info_hash = ?`, infoHash)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := rowsAffected(res)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return stream.ErrStreamNotFound
+	}
+	return nil
+}
+
+// DeleteByChannelName removes all streams associated with a specific channel from SQLite.
+// This supports cascade delete when a channel is removed.
+func (r *StreamSQLiteRepository) DeleteByChannelName(ctx context.Context, channelName string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM streams WHERE channel_name = ?`, channelName)
+	return err
+}
@@ -0,0 +1,243 @@
+package driven
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+func newTestStreamSQLiteRepository(t *testing.T, streams ...stream.Stream) *StreamSQLiteRepository {
+	t.Helper()
+
+	repo, err := NewStreamSQLiteRepository(setupTestSQLiteDB(t))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	for _, s := range streams {
+		if err := repo.Save(context.Background(), s); err != nil {
+			t.Fatalf("failed to save stream %q: %v", s.InfoHash(), err)
+		}
+	}
+
+	return repo
+}
+
+func mustNewStream(t *testing.T, infoHash, channelName, source string) stream.Stream {
+	t.Helper()
+
+	s, err := stream.NewStream(infoHash, channelName, source)
+	if err != nil {
+		t.Fatalf("failed to create stream %q: %v", infoHash, err)
+	}
+	return s
+}
+
+func TestNewStreamSQLiteRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewStreamSQLiteRepository(nil)
+		if err == nil {
+			t.Error("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestStreamSQLiteRepository_Save(t *testing.T) {
+	t.Run("saves a new stream with its source", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t, mustNewStream(t, "6861736831000000000000000000000000000000", "HBO", stream.SourceElcano))
+
+		found, err := repo.FindByInfoHash(context.Background(), "6861736831000000000000000000000000000000")
+		if err != nil {
+			t.Fatalf("failed to find saved stream: %v", err)
+		}
+		if found.ChannelName() != "HBO" || found.Source() != stream.SourceElcano {
+			t.Errorf("unexpected stream: channel %q source %q", found.ChannelName(), found.Source())
+		}
+	})
+
+	t.Run("returns ErrStreamAlreadyExists for duplicate stream", func(t *testing.T) {
+		s := mustNewStream(t, "6861736831000000000000000000000000000000", "HBO", "")
+		repo := newTestStreamSQLiteRepository(t, s)
+
+		if err := repo.Save(context.Background(), s); err != stream.ErrStreamAlreadyExists {
+			t.Errorf("expected ErrStreamAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("respects context cancellation", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := repo.Save(ctx, mustNewStream(t, "6861736831000000000000000000000000000000", "HBO", "")); err == nil {
+			t.Error("expected error for cancelled context")
+		}
+	})
+}
+
+func TestStreamSQLiteRepository_Update(t *testing.T) {
+	t.Run("moves a stream to another channel", func(t *testing.T) {
+		s := mustNewStream(t, "6861736831000000000000000000000000000000", "HBO", stream.SourceElcano)
+		repo := newTestStreamSQLiteRepository(t, s)
+
+		moved, _ := s.MoveTo("HBO HD")
+		if err := repo.Update(context.Background(), moved); err != nil {
+			t.Fatalf("failed to update stream: %v", err)
+		}
+
+		streams, err := repo.FindByChannelName(context.Background(), "HBO HD")
+		if err != nil {
+			t.Fatalf("failed to find streams: %v", err)
+		}
+		if len(streams) != 1 || streams[0].Source() != stream.SourceElcano {
+			t.Errorf("expected the moved stream on HBO HD, got %v", streams)
+		}
+		if old, _ := repo.FindByChannelName(context.Background(), "HBO"); len(old) != 0 {
+			t.Errorf("expected no streams left on HBO, got %v", old)
+		}
+	})
+
+	t.Run("returns ErrStreamNotFound for non-existent stream", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t)
+
+		err := repo.Update(context.Background(), mustNewStream(t, "6861736831000000000000000000000000000000", "HBO", ""))
+		if err != stream.ErrStreamNotFound {
+			t.Errorf("expected ErrStreamNotFound, got %v", err)
+		}
+	})
+}
+
+func TestStreamSQLiteRepository_FindByInfoHash(t *testing.T) {
+	t.Run("returns ErrStreamNotFound for non-existent stream", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t)
+
+		if _, err := repo.FindByInfoHash(context.Background(), "missing"); err != stream.ErrStreamNotFound {
+			t.Errorf("expected ErrStreamNotFound, got %v", err)
+		}
+	})
+}
+
+func TestStreamSQLiteRepository_FindAll(t *testing.T) {
+	t.Run("returns empty slice when no streams exist", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t)
+
+		streams, err := repo.FindAll(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if streams == nil || len(streams) != 0 {
+			t.Errorf("expected empty non-nil slice, got %v", streams)
+		}
+	})
+
+	t.Run("returns all saved streams", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t,
+			mustNewStream(t, "6861736832000000000000000000000000000000", "HBO", ""),
+			mustNewStream(t, "6861736831000000000000000000000000000000", "ESPN", ""),
+		)
+
+		streams, err := repo.FindAll(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(streams) != 2 {
+			t.Fatalf("expected 2 streams, got %d", len(streams))
+		}
+		if streams[0].InfoHash() != "6861736831000000000000000000000000000000" || streams[1].InfoHash() != "6861736832000000000000000000000000000000" {
+			t.Errorf("expected streams ordered by infohash, got %q, %q", streams[0].InfoHash(), streams[1].InfoHash())
+		}
+	})
+}
+
+func TestStreamSQLiteRepository_FindByChannelName(t *testing.T) {
+	t.Run("returns only streams for specified channel", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t,
+			mustNewStream(t, "6861736831000000000000000000000000000000", "HBO", ""),
+			mustNewStream(t, "6861736832000000000000000000000000000000", "HBO", ""),
+			mustNewStream(t, "6861736833000000000000000000000000000000", "ESPN", ""),
+		)
+
+		streams, err := repo.FindByChannelName(context.Background(), "HBO")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(streams) != 2 {
+			t.Fatalf("expected 2 streams for HBO, got %d", len(streams))
+		}
+		for _, s := range streams {
+			if s.ChannelName() != "HBO" {
+				t.Errorf("expected channel 'HBO', got %q", s.ChannelName())
+			}
+		}
+	})
+
+	t.Run("returns empty slice when channel has no streams", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t)
+
+		streams, err := repo.FindByChannelName(context.Background(), "HBO")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if streams == nil || len(streams) != 0 {
+			t.Errorf("expected empty non-nil slice, got %v", streams)
+		}
+	})
+}
+
+func TestStreamSQLiteRepository_Delete(t *testing.T) {
+	t.Run("deletes existing stream successfully", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t, mustNewStream(t, "6861736831000000000000000000000000000000", "HBO", ""))
+		ctx := context.Background()
+
+		if err := repo.Delete(ctx, "6861736831000000000000000000000000000000"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if _, err := repo.FindByInfoHash(ctx, "6861736831000000000000000000000000000000"); err != stream.ErrStreamNotFound {
+			t.Errorf("expected ErrStreamNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrStreamNotFound for non-existent stream", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t)
+
+		if err := repo.Delete(context.Background(), "missing"); err != stream.ErrStreamNotFound {
+			t.Errorf("expected ErrStreamNotFound, got %v", err)
+		}
+	})
+}
+
+func TestStreamSQLiteRepository_DeleteByChannelName(t *testing.T) {
+	t.Run("deletes all streams for a channel", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t,
+			mustNewStream(t, "6861736831000000000000000000000000000000", "HBO", ""),
+			mustNewStream(t, "6861736832000000000000000000000000000000", "HBO", ""),
+			mustNewStream(t, "6861736833000000000000000000000000000000", "ESPN", ""),
+		)
+		ctx := context.Background()
+
+		if err := repo.DeleteByChannelName(ctx, "HBO"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		streams, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("failed to list streams: %v", err)
+		}
+		if len(streams) != 1 || streams[0].InfoHash() != "6861736833000000000000000000000000000000" {
+			t.Errorf("expected only hash3 to remain, got %v", streams)
+		}
+	})
+
+	t.Run("succeeds even if no streams exist for channel", func(t *testing.T) {
+		repo := newTestStreamSQLiteRepository(t)
+
+		if err := repo.DeleteByChannelName(context.Background(), "HBO"); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
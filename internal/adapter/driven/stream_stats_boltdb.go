@@ -0,0 +1,281 @@
+package driven
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/streamstats"
+)
+
+const streamStatsBucket = "stream_stats"
+
+// StreamStatsBoltDBRepository implements the StreamStatsRepository port using
+// BoltDB. It uses nested buckets: stream_stats/<infoHash> with
+// timestamp-keyed entries.
+type StreamStatsBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewStreamStatsBoltDBRepository creates a new BoltDB-backed stats history
+// repository. It initializes the required top-level bucket if it doesn't exist.
+func NewStreamStatsBoltDBRepository(db *bbolt.DB) (*StreamStatsBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(streamStatsBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamStatsBoltDBRepository{db: db}, nil
+}
+
+// streamStatsDTO is the JSON serialization format for a stats sample.
+type streamStatsDTO struct {
+	InfoHash  string `json:"infohash"`
+	Timestamp int64  `json:"timestamp"`
+	Span      int64  `json:"span,omitempty"`
+	Count     int    `json:"count"`
+	Peers     int    `json:"peers"`
+	SpeedDown int64  `json:"speed_down"`
+	SpeedUp   int64  `json:"speed_up"`
+}
+
+// Save persists stats samples to BoltDB.
+func (r *StreamStatsBoltDBRepository) Save(ctx context.Context, samples ...streamstats.Sample) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		top := tx.Bucket([]byte(streamStatsBucket))
+		if top == nil {
+			return errors.New("stream_stats bucket not found")
+		}
+		return putStreamStats(top, samples)
+	})
+}
+
+// FindByInfoHashSince retrieves the samples of a stream since the given
+// time, ordered by timestamp ascending.
+func (r *StreamStatsBoltDBRepository) FindByInfoHashSince(ctx context.Context, infoHash string, since time.Time) ([]streamstats.Sample, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	samples := []streamstats.Sample{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		top := tx.Bucket([]byte(streamStatsBucket))
+		if top == nil {
+			return errors.New("stream_stats bucket not found")
+		}
+
+		sub := top.Bucket([]byte(infoHash))
+		if sub == nil {
+			return nil
+		}
+
+		c := sub.Cursor()
+		for k, v := c.Seek(timestampToKey(since)); k != nil; k, v = c.Next() {
+			sample, err := dtoToStreamStats(v)
+			if err != nil {
+				return err
+			}
+			samples = append(samples, sample)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// FindReadingsBefore retrieves the single readings of every stream older
+// than the given time.
+func (r *StreamStatsBoltDBRepository) FindReadingsBefore(ctx context.Context, before time.Time) ([]streamstats.Sample, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	samples := []streamstats.Sample{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		top := tx.Bucket([]byte(streamStatsBucket))
+		if top == nil {
+			return errors.New("stream_stats bucket not found")
+		}
+
+		return forEachStreamStatsBefore(top, before, func(_ *bbolt.Bucket, _ []byte, sample streamstats.Sample) error {
+			if sample.Span() == 0 {
+				samples = append(samples, sample)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+// ReplaceReadingsBefore removes the single readings older than the given
+// time and saves samples in their place, in a single transaction.
+func (r *StreamStatsBoltDBRepository) ReplaceReadingsBefore(ctx context.Context, before time.Time, samples []streamstats.Sample) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		top := tx.Bucket([]byte(streamStatsBucket))
+		if top == nil {
+			return errors.New("stream_stats bucket not found")
+		}
+
+		_, err := deleteStreamStatsBefore(top, before, func(s streamstats.Sample) bool { return s.Span() == 0 })
+		if err != nil {
+			return err
+		}
+		return putStreamStats(top, samples)
+	})
+}
+
+// DeleteBefore removes all samples older than the given time and returns
+// the number of samples removed.
+func (r *StreamStatsBoltDBRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		top := tx.Bucket([]byte(streamStatsBucket))
+		if top == nil {
+			return errors.New("stream_stats bucket not found")
+		}
+
+		var err error
+		deleted, err = deleteStreamStatsBefore(top, before, func(streamstats.Sample) bool { return true })
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+// putStreamStats stores samples in the sub-bucket of their stream.
+func putStreamStats(top *bbolt.Bucket, samples []streamstats.Sample) error {
+	for _, s := range samples {
+		sub, err := top.CreateBucketIfNotExists([]byte(s.InfoHash()))
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(streamStatsDTO{
+			InfoHash:  s.InfoHash(),
+			Timestamp: s.Timestamp().UnixNano(),
+			Span:      s.Span().Nanoseconds(),
+			Count:     s.Count(),
+			Peers:     s.Peers(),
+			SpeedDown: s.SpeedDown(),
+			SpeedUp:   s.SpeedUp(),
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := sub.Put(timestampToKey(s.Timestamp()), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachStreamStatsBefore calls fn for every sample of every stream older
+// than before, oldest first within each stream.
+func forEachStreamStatsBefore(top *bbolt.Bucket, before time.Time, fn func(sub *bbolt.Bucket, key []byte, sample streamstats.Sample) error) error {
+	beforeKey := timestampToKey(before)
+
+	return top.ForEach(func(k, v []byte) error {
+		// v is nil for nested buckets
+		if v != nil {
+			return nil
+		}
+
+		sub := top.Bucket(k)
+		if sub == nil {
+			return nil
+		}
+
+		c := sub.Cursor()
+		for ck, cv := c.First(); ck != nil && compareKeys(ck, beforeKey) < 0; ck, cv = c.Next() {
+			sample, err := dtoToStreamStats(cv)
+			if err != nil {
+				return err
+			}
+			if err := fn(sub, ck, sample); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteStreamStatsBefore removes the samples older than before that match
+// remove, returning how many were removed.
+func deleteStreamStatsBefore(top *bbolt.Bucket, before time.Time, remove func(streamstats.Sample) bool) (int, error) {
+	type entry struct {
+		sub *bbolt.Bucket
+		key []byte
+	}
+
+	// Collect keys to delete (can't delete during iteration)
+	var toDelete []entry
+	err := forEachStreamStatsBefore(top, before, func(sub *bbolt.Bucket, key []byte, sample streamstats.Sample) error {
+		if remove(sample) {
+			keyCopy := make([]byte, len(key))
+			copy(keyCopy, key)
+			toDelete = append(toDelete, entry{sub: sub, key: keyCopy})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, e := range toDelete {
+		if err := e.sub.Delete(e.key); err != nil {
+			return 0, err
+		}
+	}
+	return len(toDelete), nil
+}
+
+// dtoToStreamStats converts JSON bytes to a stats sample.
+func dtoToStreamStats(data []byte) (streamstats.Sample, error) {
+	var dto streamStatsDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return streamstats.Sample{}, err
+	}
+
+	return streamstats.ReconstructSample(
+		dto.InfoHash,
+		time.Unix(0, dto.Timestamp),
+		time.Duration(dto.Span),
+		dto.Count,
+		dto.Peers,
+		dto.SpeedDown,
+		dto.SpeedUp,
+	), nil
+}
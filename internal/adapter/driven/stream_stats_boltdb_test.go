@@ -0,0 +1,87 @@
+package driven
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streamstats"
+)
+
+func TestNewStreamStatsBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewStreamStatsBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestStreamStatsBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	repo, err := NewStreamStatsBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	var readings []streamstats.Sample
+	for i := range 4 {
+		s, _ := streamstats.NewSample("a", base.Add(time.Duration(i)*30*time.Minute), 10+i, int64(100*i), 10)
+		readings = append(readings, s)
+	}
+	other, _ := streamstats.NewSample("b", base, 3, 300, 30)
+	if err := repo.Save(ctx, append(readings, other)...); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	found, err := repo.FindByInfoHashSince(ctx, "a", base.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("FindByInfoHashSince() error = %v", err)
+	}
+	if len(found) != 3 || !found[0].Timestamp().Equal(base.Add(30*time.Minute)) || found[2].Peers() != 13 {
+		t.Fatalf("FindByInfoHashSince() = %+v, want the last 3 readings oldest first", found)
+	}
+
+	cutoff := base.Add(time.Hour)
+	old, err := repo.FindReadingsBefore(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("FindReadingsBefore() error = %v", err)
+	}
+	if len(old) != 3 {
+		t.Fatalf("FindReadingsBefore() returned %d readings, want 3", len(old))
+	}
+
+	hourly := streamstats.Aggregate(old, time.Hour)
+	if err := repo.ReplaceReadingsBefore(ctx, cutoff, hourly); err != nil {
+		t.Fatalf("ReplaceReadingsBefore() error = %v", err)
+	}
+	if old, _ := repo.FindReadingsBefore(ctx, cutoff); len(old) != 0 {
+		t.Errorf("expected compacted readings to be gone, got %d", len(old))
+	}
+
+	found, _ = repo.FindByInfoHashSince(ctx, "a", base)
+	if len(found) != 3 {
+		t.Fatalf("expected 1 hourly sample and 2 readings, got %+v", found)
+	}
+	if found[0].Span() != time.Hour || found[0].Count() != 2 || found[0].SpeedDown() != 50 {
+		t.Errorf("unexpected hourly sample %+v", found[0])
+	}
+
+	deleted, err := repo.DeleteBefore(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("DeleteBefore() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("DeleteBefore() = %d, want 2", deleted)
+	}
+	if found, _ := repo.FindByInfoHashSince(ctx, "b", base); len(found) != 0 {
+		t.Errorf("expected the old sample of b to be deleted, got %d", len(found))
+	}
+}
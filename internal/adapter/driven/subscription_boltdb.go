@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"go.etcd.io/bbolt"
 
@@ -40,9 +41,50 @@ func NewSubscriptionBoltDBRepository(db *bbolt.DB) (*SubscriptionBoltDBRepositor
 
 // subscriptionDTO is used for JSON serialization.
 type subscriptionDTO struct {
-	EPGChannelID   string `json:"epg_channel_id"`
-	Enabled        bool   `json:"enabled"`
-	ManualOverride bool   `json:"manual_override"`
+	EPGChannelID   string     `json:"epg_channel_id"`
+	Enabled        bool       `json:"enabled"`
+	ManualOverride bool       `json:"manual_override"`
+	Paused         bool       `json:"paused,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+}
+
+// subscriptionToDTO converts a subscription to its persisted form.
+func subscriptionToDTO(sub subscription.Subscription) subscriptionDTO {
+	dto := subscriptionDTO{
+		EPGChannelID:   sub.EPGChannelID(),
+		Enabled:        sub.IsEnabled(),
+		ManualOverride: sub.HasManualOverride(),
+		Paused:         sub.IsPaused(),
+		Notes:          sub.Notes(),
+	}
+	if expiresAt := sub.ExpiresAt(); !expiresAt.IsZero() {
+		dto.ExpiresAt = &expiresAt
+	}
+	return dto
+}
+
+// dtoToSubscription reconstructs a subscription from its persisted form.
+func dtoToSubscription(dto subscriptionDTO) (subscription.Subscription, error) {
+	sub, err := subscription.NewSubscription(dto.EPGChannelID)
+	if err != nil {
+		return subscription.Subscription{}, err
+	}
+
+	// Apply state based on persisted data
+	if !dto.Enabled {
+		sub = sub.Disable()
+	}
+	if dto.ManualOverride && dto.Enabled {
+		sub = sub.Enable()
+	}
+	if dto.Paused {
+		sub = sub.Pause()
+	}
+	if dto.ExpiresAt != nil {
+		sub = sub.WithExpiry(*dto.ExpiresAt)
+	}
+	return sub.WithNotes(dto.Notes)
 }
 
 // Save persists a subscription to BoltDB.
@@ -66,12 +108,34 @@ func (r *SubscriptionBoltDBRepository) Save(ctx context.Context, sub subscriptio
 		}
 
 		// Serialize subscription
-		dto := subscriptionDTO{
-			EPGChannelID:   sub.EPGChannelID(),
-			Enabled:        sub.IsEnabled(),
-			ManualOverride: sub.HasManualOverride(),
+		data, err := json.Marshal(subscriptionToDTO(sub))
+		if err != nil {
+			return err
 		}
-		data, err := json.Marshal(dto)
+
+		return bucket.Put(key, data)
+	})
+}
+
+// Update replaces an existing subscription in BoltDB.
+func (r *SubscriptionBoltDBRepository) Update(ctx context.Context, sub subscription.Subscription) error {
+	// Check context cancellation
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(subscriptionsBucket))
+		if bucket == nil {
+			return errors.New("subscriptions bucket not found")
+		}
+
+		key := []byte(sub.EPGChannelID())
+		if bucket.Get(key) == nil {
+			return subscription.ErrSubscriptionNotFound
+		}
+
+		data, err := json.Marshal(subscriptionToDTO(sub))
 		if err != nil {
 			return err
 		}
@@ -102,19 +166,11 @@ func (r *SubscriptionBoltDBRepository) FindAll(ctx context.Context) ([]subscript
 			}
 
 			// Reconstruct domain entity
-			sub, err := subscription.NewSubscription(dto.EPGChannelID)
+			sub, err := dtoToSubscription(dto)
 			if err != nil {
 				return err
 			}
 
-			// Apply state based on persisted data
-			if !dto.Enabled {
-				sub = sub.Disable()
-			}
-			if dto.ManualOverride && dto.Enabled {
-				sub = sub.Enable()
-			}
-
 			subscriptions = append(subscriptions, sub)
 			return nil
 		})
@@ -158,19 +214,11 @@ func (r *SubscriptionBoltDBRepository) FindByEPGID(ctx context.Context, epgChann
 		}
 
 		// Reconstruct domain entity
-		reconstructed, err := subscription.NewSubscription(dto.EPGChannelID)
+		reconstructed, err := dtoToSubscription(dto)
 		if err != nil {
 			return err
 		}
 
-		// Apply state based on persisted data
-		if !dto.Enabled {
-			reconstructed = reconstructed.Disable()
-		}
-		if dto.ManualOverride && dto.Enabled {
-			reconstructed = reconstructed.Enable()
-		}
-
 		sub = reconstructed
 		return nil
 	})
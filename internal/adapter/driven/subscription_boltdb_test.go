@@ -3,6 +3,7 @@ package driven
 import (
 	"context"
 	"testing"
+	"time"
 
 	"go.etcd.io/bbolt"
 
@@ -331,6 +332,60 @@ func TestSubscriptionBoltDBRepository_FindByEPGID(t *testing.T) {
 	})
 }
 
+func TestSubscriptionBoltDBRepository_Update(t *testing.T) {
+	t.Run("persists pause, expiry and notes", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo, err := NewSubscriptionBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+
+		ctx := context.Background()
+		sub, _ := subscription.NewSubscription("hbo-channel-1")
+		if err := repo.Save(ctx, sub); err != nil {
+			t.Fatalf("failed to save subscription: %v", err)
+		}
+
+		expiresAt := time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC)
+		updated, err := sub.Pause().WithExpiry(expiresAt).WithNotes("trial")
+		if err != nil {
+			t.Fatalf("failed to set notes: %v", err)
+		}
+		if err := repo.Update(ctx, updated); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		found, err := repo.FindByEPGID(ctx, "hbo-channel-1")
+		if err != nil {
+			t.Fatalf("failed to find subscription: %v", err)
+		}
+		if !found.IsPaused() || !found.ExpiresAt().Equal(expiresAt) || found.Notes() != "trial" {
+			t.Errorf("expected paused subscription expiring %v with notes, got paused=%v expires=%v notes=%q",
+				expiresAt, found.IsPaused(), found.ExpiresAt(), found.Notes())
+		}
+		if !found.IsEnabled() {
+			t.Error("expected the subscription to stay enabled")
+		}
+	})
+
+	t.Run("returns error when subscription not found", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		repo, err := NewSubscriptionBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+
+		sub, _ := subscription.NewSubscription("nonexistent-channel")
+		if err := repo.Update(context.Background(), sub); err != subscription.ErrSubscriptionNotFound {
+			t.Errorf("expected ErrSubscriptionNotFound, got %v", err)
+		}
+	})
+}
+
 func TestSubscriptionBoltDBRepository_Delete(t *testing.T) {
 	t.Run("deletes existing subscription", func(t *testing.T) {
 		db, cleanup := setupTestDB(t)
@@ -0,0 +1,210 @@
+package driven
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/auth"
+)
+
+const tokensBucket = "tokens"
+
+// TokenBoltDBRepository implements the TokenRepository port using BoltDB.
+// Tokens are keyed by ID; lookups by secret hash scan the bucket, which is
+// fine for the handful of tokens a single install has.
+type TokenBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewTokenBoltDBRepository creates a new BoltDB-backed token repository.
+// It initializes the required bucket if it doesn't exist.
+func NewTokenBoltDBRepository(db *bbolt.DB) (*TokenBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(tokensBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenBoltDBRepository{db: db}, nil
+}
+
+// tokenDTO is used for JSON serialization.
+type tokenDTO struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	SecretHash string `json:"secret_hash"`
+	CreatedAt  int64  `json:"created_at"`
+
+	PlaylistPrefs *playlistPrefsDTO `json:"playlist_prefs,omitempty"`
+}
+
+// playlistPrefsDTO is used for JSON serialization of a token's playlist
+// preferences.
+type playlistPrefsDTO struct {
+	Groups          []string `json:"groups,omitempty"`
+	Quality         string   `json:"quality,omitempty"`
+	Format          string   `json:"format,omitempty"`
+	IncludeDisabled bool     `json:"include_disabled,omitempty"`
+}
+
+func (d tokenDTO) toDomain() auth.Token {
+	var prefs auth.PlaylistPrefs
+	if p := d.PlaylistPrefs; p != nil {
+		prefs = auth.NewPlaylistPrefs(p.Groups, p.Quality, p.Format, p.IncludeDisabled)
+	}
+	return auth.ReconstructToken(d.ID, d.Name, d.SecretHash, time.Unix(0, d.CreatedAt), prefs)
+}
+
+// Save persists a token to BoltDB, replacing any token with the same ID.
+func (r *TokenBoltDBRepository) Save(ctx context.Context, token auth.Token) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(tokensBucket))
+		if bucket == nil {
+			return errors.New("tokens bucket not found")
+		}
+
+		dto := tokenDTO{
+			ID:         token.ID(),
+			Name:       token.Name(),
+			SecretHash: token.SecretHash(),
+			CreatedAt:  token.CreatedAt().UnixNano(),
+		}
+		if p := token.PlaylistPrefs(); len(p.Groups()) > 0 || p.Quality() != "" || p.Format() != "" || p.IncludeDisabled() {
+			dto.PlaylistPrefs = &playlistPrefsDTO{
+				Groups:          p.Groups(),
+				Quality:         p.Quality(),
+				Format:          p.Format(),
+				IncludeDisabled: p.IncludeDisabled(),
+			}
+		}
+
+		data, err := json.Marshal(dto)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(token.ID()), data)
+	})
+}
+
+// FindAll retrieves all tokens from BoltDB.
+func (r *TokenBoltDBRepository) FindAll(ctx context.Context) ([]auth.Token, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tokens := []auth.Token{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(tokensBucket))
+		if bucket == nil {
+			return errors.New("tokens bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var dto tokenDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			tokens = append(tokens, dto.toDomain())
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// FindByID retrieves the token with the given ID.
+func (r *TokenBoltDBRepository) FindByID(ctx context.Context, id string) (auth.Token, error) {
+	if err := ctx.Err(); err != nil {
+		return auth.Token{}, err
+	}
+
+	var found auth.Token
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(tokensBucket))
+		if bucket == nil {
+			return errors.New("tokens bucket not found")
+		}
+
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return auth.ErrTokenNotFound
+		}
+		var dto tokenDTO
+		if err := json.Unmarshal(data, &dto); err != nil {
+			return err
+		}
+		found = dto.toDomain()
+		return nil
+	})
+
+	return found, err
+}
+
+// FindBySecretHash retrieves the token with the given secret hash.
+func (r *TokenBoltDBRepository) FindBySecretHash(ctx context.Context, secretHash string) (auth.Token, error) {
+	if err := ctx.Err(); err != nil {
+		return auth.Token{}, err
+	}
+
+	var found auth.Token
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(tokensBucket))
+		if bucket == nil {
+			return errors.New("tokens bucket not found")
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var dto tokenDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			if dto.SecretHash == secretHash {
+				found = dto.toDomain()
+				return nil
+			}
+		}
+		return auth.ErrTokenNotFound
+	})
+
+	return found, err
+}
+
+// Delete removes a token by its ID from BoltDB.
+func (r *TokenBoltDBRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(tokensBucket))
+		if bucket == nil {
+			return errors.New("tokens bucket not found")
+		}
+
+		key := []byte(id)
+		if bucket.Get(key) == nil {
+			return auth.ErrTokenNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
@@ -0,0 +1,121 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/auth"
+)
+
+func TestNewTokenBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewTokenBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestTokenBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+
+	t.Run("saves, finds and lists tokens", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+		repo, err := NewTokenBoltDBRepository(db)
+		if err != nil {
+			t.Fatalf("failed to create repository: %v", err)
+		}
+
+		tok, secret, _ := auth.NewToken("tv", now)
+		other, _, _ := auth.NewToken("phone", now)
+		if err := repo.Save(ctx, tok); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if err := repo.Save(ctx, other); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		found, err := repo.FindBySecretHash(ctx, auth.HashSecret(secret))
+		if err != nil {
+			t.Fatalf("FindBySecretHash() error = %v", err)
+		}
+		if found.ID() != tok.ID() || found.Name() != "tv" || !found.CreatedAt().Equal(now) {
+			t.Errorf("FindBySecretHash() = %+v, want %+v", found, tok)
+		}
+		if !found.Matches(secret) {
+			t.Error("expected reconstructed token to match its secret")
+		}
+
+		all, err := repo.FindAll(ctx)
+		if err != nil {
+			t.Fatalf("FindAll() error = %v", err)
+		}
+		if len(all) != 2 {
+			t.Errorf("expected 2 tokens, got %d", len(all))
+		}
+	})
+
+	t.Run("updates and finds tokens by ID with their playlist prefs", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+		repo, _ := NewTokenBoltDBRepository(db)
+
+		tok, _, _ := auth.NewToken("kids tv", now)
+		_ = repo.Save(ctx, tok)
+		prefs := auth.NewPlaylistPrefs([]string{"kids"}, "720p", "m3u8", true)
+		if err := repo.Save(ctx, tok.WithPlaylistPrefs(prefs)); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		found, err := repo.FindByID(ctx, tok.ID())
+		if err != nil {
+			t.Fatalf("FindByID() error = %v", err)
+		}
+		got := found.PlaylistPrefs()
+		if len(got.Groups()) != 1 || got.Groups()[0] != "kids" || got.Quality() != "720p" || got.Format() != "m3u8" || !got.IncludeDisabled() {
+			t.Errorf("FindByID() prefs = %+v, want %+v", got, prefs)
+		}
+		if all, _ := repo.FindAll(ctx); len(all) != 1 {
+			t.Errorf("expected the token to be replaced, got %d tokens", len(all))
+		}
+		if _, err := repo.FindByID(ctx, "nope"); !errors.Is(err, auth.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrTokenNotFound for unknown hash", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+		repo, _ := NewTokenBoltDBRepository(db)
+
+		if _, err := repo.FindBySecretHash(ctx, "nope"); !errors.Is(err, auth.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound, got %v", err)
+		}
+	})
+
+	t.Run("deletes tokens", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+		repo, _ := NewTokenBoltDBRepository(db)
+
+		tok, secret, _ := auth.NewToken("tv", now)
+		_ = repo.Save(ctx, tok)
+
+		if err := repo.Delete(ctx, tok.ID()); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.FindBySecretHash(ctx, auth.HashSecret(secret)); !errors.Is(err, auth.ErrTokenNotFound) {
+			t.Errorf("expected deleted token to be gone, got %v", err)
+		}
+		if err := repo.Delete(ctx, tok.ID()); !errors.Is(err, auth.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound on second delete, got %v", err)
+		}
+	})
+}
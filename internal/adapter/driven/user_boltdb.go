@@ -0,0 +1,205 @@
+package driven
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/user"
+)
+
+const usersBucket = "users"
+
+// UserBoltDBRepository implements the UserRepository port using BoltDB.
+type UserBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewUserBoltDBRepository creates a new BoltDB-backed user repository.
+// It initializes the required bucket if it doesn't exist.
+func NewUserBoltDBRepository(db *bbolt.DB) (*UserBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(usersBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserBoltDBRepository{db: db}, nil
+}
+
+// userDTO is used for JSON serialization.
+type userDTO struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	PlaylistToken string   `json:"playlist_token"`
+	Channels      []string `json:"channels,omitempty"`
+	Groups        []string `json:"groups,omitempty"`
+	CreatedAt     int64    `json:"created_at"`
+}
+
+func (d userDTO) toDomain() user.User {
+	return user.ReconstructUser(d.ID, d.Name, d.PlaylistToken, d.Channels, d.Groups, time.Unix(0, d.CreatedAt))
+}
+
+// Save persists a user to BoltDB.
+func (r *UserBoltDBRepository) Save(ctx context.Context, u user.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(usersBucket))
+		if bucket == nil {
+			return errors.New("users bucket not found")
+		}
+
+		data, err := json.Marshal(userDTO{
+			ID:            u.ID(),
+			Name:          u.Name(),
+			PlaylistToken: u.PlaylistToken(),
+			Channels:      u.Channels(),
+			Groups:        u.Groups(),
+			CreatedAt:     u.CreatedAt().UnixNano(),
+		})
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(u.ID()), data)
+	})
+}
+
+// FindAll retrieves all users from BoltDB, oldest first.
+func (r *UserBoltDBRepository) FindAll(ctx context.Context) ([]user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	users := []user.User{}
+	err := r.forEach(func(dto userDTO) bool {
+		users = append(users, dto.toDomain())
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	user.Sort(users)
+	return users, nil
+}
+
+// FindByID retrieves a user by its ID from BoltDB.
+func (r *UserBoltDBRepository) FindByID(ctx context.Context, id string) (user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return user.User{}, err
+	}
+
+	var u user.User
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(usersBucket))
+		if bucket == nil {
+			return errors.New("users bucket not found")
+		}
+
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return user.ErrUserNotFound
+		}
+
+		var dto userDTO
+		if err := json.Unmarshal(data, &dto); err != nil {
+			return err
+		}
+		u = dto.toDomain()
+		return nil
+	})
+
+	return u, err
+}
+
+// FindByPlaylistToken retrieves the user owning a playlist token. Users are
+// few, so every user is scanned; tokens are compared in constant time.
+func (r *UserBoltDBRepository) FindByPlaylistToken(ctx context.Context, token string) (user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return user.User{}, err
+	}
+	if token == "" {
+		return user.User{}, user.ErrUserNotFound
+	}
+
+	var found *user.User
+	err := r.forEach(func(dto userDTO) bool {
+		if subtle.ConstantTimeCompare([]byte(dto.PlaylistToken), []byte(token)) == 1 {
+			u := dto.toDomain()
+			found = &u
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return user.User{}, err
+	}
+	if found == nil {
+		return user.User{}, user.ErrUserNotFound
+	}
+
+	return *found, nil
+}
+
+// Delete removes a user by its ID from BoltDB.
+func (r *UserBoltDBRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(usersBucket))
+		if bucket == nil {
+			return errors.New("users bucket not found")
+		}
+
+		key := []byte(id)
+		if bucket.Get(key) == nil {
+			return user.ErrUserNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
+
+// forEach calls fn with every stored user until it returns false.
+func (r *UserBoltDBRepository) forEach(fn func(userDTO) bool) error {
+	errStop := errors.New("stop")
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(usersBucket))
+		if bucket == nil {
+			return errors.New("users bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var dto userDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			if !fn(dto) {
+				return errStop
+			}
+			return nil
+		})
+	})
+	if errors.Is(err, errStop) {
+		return nil
+	}
+	return err
+}
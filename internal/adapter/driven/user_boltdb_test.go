@@ -0,0 +1,86 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/user"
+)
+
+func TestNewUserBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewUserBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestUserBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	repo, err := NewUserBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	later, _ := user.NewUser("Kids", nil, []string{"kids"}, now.Add(time.Minute))
+	first, _ := user.NewUser("Grandma", []string{"La 1", "Antena 3"}, []string{"news"}, now)
+	for _, u := range []user.User{later, first} {
+		if err := repo.Save(ctx, u); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	all, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].ID() != first.ID() || all[1].ID() != later.ID() {
+		t.Fatalf("FindAll() returned %d users in the wrong order", len(all))
+	}
+
+	found, err := repo.FindByPlaylistToken(ctx, first.PlaylistToken())
+	if err != nil {
+		t.Fatalf("FindByPlaylistToken() error = %v", err)
+	}
+	if found.ID() != first.ID() || found.Name() != "Grandma" || !found.CreatedAt().Equal(now) ||
+		!slices.Equal(found.Channels(), first.Channels()) || !slices.Equal(found.Groups(), first.Groups()) {
+		t.Errorf("FindByPlaylistToken() = %+v, want %+v", found, first)
+	}
+	for _, token := range []string{"", "unknown"} {
+		if _, err := repo.FindByPlaylistToken(ctx, token); !errors.Is(err, user.ErrUserNotFound) {
+			t.Errorf("FindByPlaylistToken(%q) error = %v, want ErrUserNotFound", token, err)
+		}
+	}
+
+	rotated, _ := first.WithNewPlaylistToken()
+	if err := repo.Save(ctx, rotated); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := repo.FindByPlaylistToken(ctx, first.PlaylistToken()); !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("expected the old playlist token to stop working, got %v", err)
+	}
+	if found, err := repo.FindByID(ctx, first.ID()); err != nil || found.PlaylistToken() != rotated.PlaylistToken() {
+		t.Errorf("FindByID() = %+v, %v; want the rotated token", found, err)
+	}
+
+	if err := repo.Delete(ctx, first.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.FindByID(ctx, first.ID()); !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("FindByID() error = %v, want ErrUserNotFound", err)
+	}
+	if err := repo.Delete(ctx, first.ID()); !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("Delete() error = %v, want ErrUserNotFound", err)
+	}
+}
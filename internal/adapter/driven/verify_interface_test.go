@@ -4,11 +4,107 @@ import (
 	port "github.com/alorle/iptv-manager/internal/port/driven"
 )
 
-// Compile-time check that EPGXMLFetcher implements EPGFetcher interface
-var _ port.EPGFetcher = (*EPGXMLFetcher)(nil)
+// Compile-time checks that EPGXMLFetcher implements the EPG fetcher ports
+var (
+	_ port.EPGFetcher          = (*EPGXMLFetcher)(nil)
+	_ port.EPGProgrammeFetcher = (*EPGXMLFetcher)(nil)
+)
+
+// Compile-time checks that AceStreamHTTPAdapter implements the optional engine ports
+var (
+	_ port.AceStreamSearcher        = (*AceStreamHTTPAdapter)(nil)
+	_ port.AceStreamCommander       = (*AceStreamHTTPAdapter)(nil)
+	_ port.AceStreamRangeStreamer   = (*AceStreamHTTPAdapter)(nil)
+	_ port.AceStreamSessionRestorer = (*AceStreamHTTPAdapter)(nil)
+)
+
+// Compile-time checks that AceStreamEnginePool implements the engine ports
+var (
+	_ port.AceStreamEngine          = (*AceStreamEnginePool)(nil)
+	_ port.AceStreamSearcher        = (*AceStreamEnginePool)(nil)
+	_ port.AceStreamCommander       = (*AceStreamEnginePool)(nil)
+	_ port.AceStreamRangeStreamer   = (*AceStreamEnginePool)(nil)
+	_ port.AceStreamSessionRestorer = (*AceStreamEnginePool)(nil)
+)
 
 // Compile-time check that SubscriptionBoltDBRepository implements SubscriptionRepository interface
 var _ port.SubscriptionRepository = (*SubscriptionBoltDBRepository)(nil)
 
+// Compile-time check that GroupBoltDBRepository implements GroupRepository interface
+var _ port.GroupRepository = (*GroupBoltDBRepository)(nil)
+
+// Compile-time check that FavoriteBoltDBRepository implements FavoriteRepository interface
+var _ port.FavoriteRepository = (*FavoriteBoltDBRepository)(nil)
+
 // Compile-time check that ProbeBoltDBRepository implements ProbeRepository interface
 var _ port.ProbeRepository = (*ProbeBoltDBRepository)(nil)
+
+// Compile-time check that TokenBoltDBRepository implements TokenRepository interface
+var _ port.TokenRepository = (*TokenBoltDBRepository)(nil)
+
+// Compile-time checks that the instrumented decorators implement their ports
+var (
+	_ port.ChannelRepository      = (*InstrumentedChannelRepository)(nil)
+	_ port.StreamRepository       = (*InstrumentedStreamRepository)(nil)
+	_ port.SubscriptionRepository = (*InstrumentedSubscriptionRepository)(nil)
+	_ port.GroupRepository        = (*InstrumentedGroupRepository)(nil)
+	_ port.ProbeRepository        = (*InstrumentedProbeRepository)(nil)
+	_ port.TokenRepository        = (*InstrumentedTokenRepository)(nil)
+)
+
+// Compile-time checks that the SQLite repositories implement their ports
+var (
+	_ port.ChannelRepository = (*ChannelSQLiteRepository)(nil)
+	_ port.StreamRepository  = (*StreamSQLiteRepository)(nil)
+)
+
+// Compile-time check that PlaylistHTTPFetcher implements PlaylistFetcher interface
+var _ port.PlaylistFetcher = (*PlaylistHTTPFetcher)(nil)
+
+// Compile-time checks that the backup adapters implement their ports
+var (
+	_ port.DatabaseBackup = (*BoltDBBackup)(nil)
+	_ port.BackupStore    = (*BackupFileStore)(nil)
+)
+
+// Compile-time checks that the recording adapters implement their ports
+var (
+	_ port.RecordingRepository = (*RecordingBoltDBRepository)(nil)
+	_ port.RecordingStore      = (*RecordingFileStore)(nil)
+)
+
+// Compile-time check that RuleBoltDBRepository implements RuleRepository interface
+var _ port.RuleRepository = (*RuleBoltDBRepository)(nil)
+
+// Compile-time check that StreamStatsBoltDBRepository implements StreamStatsRepository interface
+var _ port.StreamStatsRepository = (*StreamStatsBoltDBRepository)(nil)
+
+// Compile-time check that UserBoltDBRepository implements UserRepository interface
+var _ port.UserRepository = (*UserBoltDBRepository)(nil)
+
+// Compile-time check that SourceChangeBoltDBRepository implements SourceChangeRepository interface
+var _ port.SourceChangeRepository = (*SourceChangeBoltDBRepository)(nil)
+
+// Compile-time checks that the webhook adapters implement their ports
+var (
+	_ port.WebhookRepository = (*WebhookBoltDBRepository)(nil)
+	_ port.WebhookSender     = (*WebhookHTTPSender)(nil)
+)
+
+// Compile-time check that EngineSessionBoltDBRepository implements EngineSessionRepository interface
+var _ port.EngineSessionRepository = (*EngineSessionBoltDBRepository)(nil)
+
+// Compile-time checks that the access control adapters implement their ports
+var (
+	_ port.AccessRuleRepository = (*AccessRuleBoltDBRepository)(nil)
+	_ port.GeoIPLocator         = (*GeoIPCSVDatabase)(nil)
+)
+
+// Compile-time check that AuditLogBoltDBRepository implements AuditLogRepository interface
+var _ port.AuditLogRepository = (*AuditLogBoltDBRepository)(nil)
+
+// Compile-time check that FFmpegFrameExtractor implements FrameExtractor interface
+var _ port.FrameExtractor = (*FFmpegFrameExtractor)(nil)
+
+// Compile-time check that HTTPFileCache implements SourceCache interface
+var _ port.SourceCache = (*HTTPFileCache)(nil)
@@ -0,0 +1,130 @@
+package driven
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/alorle/iptv-manager/internal/webhook"
+)
+
+const webhooksBucket = "webhooks"
+
+// WebhookBoltDBRepository implements the WebhookRepository port using BoltDB.
+type WebhookBoltDBRepository struct {
+	db *bbolt.DB
+}
+
+// NewWebhookBoltDBRepository creates a new BoltDB-backed webhook repository.
+// It initializes the required bucket if it doesn't exist.
+func NewWebhookBoltDBRepository(db *bbolt.DB) (*WebhookBoltDBRepository, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be nil")
+	}
+
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(webhooksBucket))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookBoltDBRepository{db: db}, nil
+}
+
+// webhookDTO is used for JSON serialization.
+type webhookDTO struct {
+	ID        string          `json:"id"`
+	URL       string          `json:"url"`
+	Format    webhook.Format  `json:"format"`
+	ChatID    string          `json:"chat_id,omitempty"`
+	Events    []webhook.Event `json:"events,omitempty"`
+	CreatedAt int64           `json:"created_at"`
+}
+
+func (d webhookDTO) toDomain() webhook.Webhook {
+	return webhook.ReconstructWebhook(d.ID, d.URL, d.Format, d.ChatID, d.Events, time.Unix(0, d.CreatedAt))
+}
+
+// Save persists a new webhook to BoltDB.
+func (r *WebhookBoltDBRepository) Save(ctx context.Context, w webhook.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(webhooksBucket))
+		if bucket == nil {
+			return errors.New("webhooks bucket not found")
+		}
+
+		data, err := json.Marshal(webhookDTO{
+			ID:        w.ID(),
+			URL:       w.URL(),
+			Format:    w.Format(),
+			ChatID:    w.ChatID(),
+			Events:    w.Events(),
+			CreatedAt: w.CreatedAt().UnixNano(),
+		})
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put([]byte(w.ID()), data)
+	})
+}
+
+// FindAll retrieves all webhooks from BoltDB, oldest first.
+func (r *WebhookBoltDBRepository) FindAll(ctx context.Context) ([]webhook.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	webhooks := []webhook.Webhook{}
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(webhooksBucket))
+		if bucket == nil {
+			return errors.New("webhooks bucket not found")
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var dto webhookDTO
+			if err := json.Unmarshal(v, &dto); err != nil {
+				return err
+			}
+			webhooks = append(webhooks, dto.toDomain())
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Sort(webhooks)
+	return webhooks, nil
+}
+
+// Delete removes a webhook by its ID from BoltDB.
+func (r *WebhookBoltDBRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(webhooksBucket))
+		if bucket == nil {
+			return errors.New("webhooks bucket not found")
+		}
+
+		key := []byte(id)
+		if bucket.Get(key) == nil {
+			return webhook.ErrWebhookNotFound
+		}
+
+		return bucket.Delete(key)
+	})
+}
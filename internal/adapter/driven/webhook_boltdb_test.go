@@ -0,0 +1,67 @@
+package driven
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/webhook"
+)
+
+func TestNewWebhookBoltDBRepository(t *testing.T) {
+	t.Run("returns error for nil database", func(t *testing.T) {
+		repo, err := NewWebhookBoltDBRepository(nil)
+		if err == nil {
+			t.Fatal("expected error for nil database")
+		}
+		if repo != nil {
+			t.Error("expected nil repository")
+		}
+	})
+}
+
+func TestWebhookBoltDBRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	db, cleanup := setupTestDB(t)
+	t.Cleanup(cleanup)
+	repo, err := NewWebhookBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+
+	later, _ := webhook.NewWebhook("https://discord.com/api/webhooks/1/x", webhook.FormatDiscord, "", nil, now.Add(time.Minute))
+	first, _ := webhook.NewWebhook("https://api.telegram.org/botX/sendMessage", webhook.FormatTelegram, "42",
+		[]webhook.Event{webhook.EventEngineDown, webhook.EventEPGSyncFailed}, now)
+	for _, w := range []webhook.Webhook{later, first} {
+		if err := repo.Save(ctx, w); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	all, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll() error = %v", err)
+	}
+	if len(all) != 2 || all[0].ID() != first.ID() || all[1].ID() != later.ID() {
+		t.Fatalf("FindAll() returned %d webhooks in the wrong order", len(all))
+	}
+	got := all[0]
+	if got.URL() != first.URL() || got.Format() != webhook.FormatTelegram || got.ChatID() != "42" ||
+		!slices.Equal(got.Events(), first.Events()) || !got.CreatedAt().Equal(now) {
+		t.Errorf("FindAll() returned %+v, want %+v", got, first)
+	}
+
+	if err := repo.Delete(ctx, first.ID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := repo.Delete(ctx, first.ID()); !errors.Is(err, webhook.ErrWebhookNotFound) {
+		t.Errorf("Delete() error = %v, want ErrWebhookNotFound", err)
+	}
+	if all, _ := repo.FindAll(ctx); len(all) != 1 {
+		t.Errorf("expected 1 webhook left, got %d", len(all))
+	}
+}
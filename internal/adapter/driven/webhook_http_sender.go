@@ -0,0 +1,50 @@
+package driven
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds a single webhook delivery, so a slow receiver cannot
+// hold up later notifications.
+const webhookTimeout = 10 * time.Second
+
+// WebhookHTTPSender posts webhook payloads over HTTP.
+// It implements the driven.WebhookSender port.
+type WebhookHTTPSender struct {
+	client *http.Client
+}
+
+// NewWebhookHTTPSender creates a webhook sender. If client is nil, it creates
+// a default HTTP client with a 10-second timeout.
+func NewWebhookHTTPSender(client *http.Client) *WebhookHTTPSender {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &WebhookHTTPSender{client: client}
+}
+
+// Send posts payload to url as JSON.
+func (s *WebhookHTTPSender) Send(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("creating HTTP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected HTTP status: %d %s", resp.StatusCode, resp.Status)
+	}
+	return nil
+}
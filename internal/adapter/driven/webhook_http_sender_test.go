@@ -0,0 +1,37 @@
+package driven
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookHTTPSender_Send(t *testing.T) {
+	var gotBody, gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotType = string(body), r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewWebhookHTTPSender(nil)
+	ctx := context.Background()
+
+	if err := sender.Send(ctx, server.URL+"/hook", []byte(`{"content":"hi"}`)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if gotBody != `{"content":"hi"}` || gotType != "application/json" {
+		t.Errorf("receiver got %q with content type %q", gotBody, gotType)
+	}
+
+	if err := sender.Send(ctx, server.URL+"/broken", []byte(`{}`)); err == nil {
+		t.Error("expected error for a non-2xx response")
+	}
+}
@@ -0,0 +1,134 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/access"
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// AccessHTTPHandler handles HTTP requests for access rules.
+type AccessHTTPHandler struct {
+	service *application.AccessService
+}
+
+// NewAccessHTTPHandler creates a new HTTP handler for access rules.
+func NewAccessHTTPHandler(service *application.AccessService) *AccessHTTPHandler {
+	return &AccessHTTPHandler{service: service}
+}
+
+// accessRuleRequest represents the JSON body for creating an access rule.
+type accessRuleRequest struct {
+	Network string        `json:"network"`
+	Country string        `json:"country"`
+	Action  access.Action `json:"action"`
+	Note    string        `json:"note"`
+}
+
+// accessRuleResponse represents an access rule and its hits in JSON format.
+type accessRuleResponse struct {
+	ID        string        `json:"id"`
+	Network   string        `json:"network,omitempty"`
+	Country   string        `json:"country,omitempty"`
+	Action    access.Action `json:"action"`
+	Note      string        `json:"note,omitempty"`
+	CreatedAt string        `json:"created_at"`
+	Hits      int64         `json:"hits"`
+	LastHitAt string        `json:"last_hit_at,omitempty"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *AccessHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/access-rules")
+
+	// GET /access-rules - list all access rules with their hit counters
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	// POST /access-rules - create an access rule
+	if r.Method == http.MethodPost && path == "" {
+		h.handleCreate(w, r)
+		return
+	}
+
+	// DELETE /access-rules/{id} - delete an access rule
+	if r.Method == http.MethodDelete && path != "" {
+		h.handleDelete(w, r, strings.TrimPrefix(path, "/"))
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toAccessRuleResponse(status application.AccessRuleStatus) accessRuleResponse {
+	r := status.Rule
+	resp := accessRuleResponse{
+		ID:        r.ID(),
+		Country:   r.Country(),
+		Action:    r.Action(),
+		Note:      r.Note(),
+		CreatedAt: formatOptionalTime(r.CreatedAt()),
+		Hits:      status.Hits,
+		LastHitAt: formatOptionalTime(status.LastHit),
+	}
+	if r.Network().IsValid() {
+		resp.Network = r.Network().String()
+	}
+	return resp
+}
+
+// writeAccessError maps access rule errors to HTTP responses.
+func writeAccessError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, access.ErrInvalidAction), errors.Is(err, access.ErrInvalidNetwork),
+		errors.Is(err, access.ErrInvalidCountry), errors.Is(err, access.ErrInvalidMatch),
+		errors.Is(err, application.ErrGeoIPUnavailable):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, access.ErrRuleNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// handleList handles GET /access-rules
+func (h *AccessHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	statuses := h.service.ListRules()
+
+	response := make([]accessRuleResponse, len(statuses))
+	for i, status := range statuses {
+		response[i] = toAccessRuleResponse(status)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleCreate handles POST /access-rules
+func (h *AccessHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req accessRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rule, err := h.service.CreateRule(r.Context(), req.Network, req.Country, req.Action, req.Note)
+	if err != nil {
+		writeAccessError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toAccessRuleResponse(application.AccessRuleStatus{Rule: rule}))
+}
+
+// handleDelete handles DELETE /access-rules/{id}
+func (h *AccessHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteRule(r.Context(), id); err != nil {
+		writeAccessError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
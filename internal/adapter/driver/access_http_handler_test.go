@@ -0,0 +1,102 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/access"
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// mockAccessRuleRepository is an in-memory implementation for testing.
+type mockAccessRuleRepository struct {
+	rules map[string]access.Rule
+}
+
+func (m *mockAccessRuleRepository) Save(ctx context.Context, r access.Rule) error {
+	m.rules[r.ID()] = r
+	return nil
+}
+
+func (m *mockAccessRuleRepository) FindAll(ctx context.Context) ([]access.Rule, error) {
+	rules := make([]access.Rule, 0, len(m.rules))
+	for _, r := range m.rules {
+		rules = append(rules, r)
+	}
+	access.Sort(rules)
+	return rules, nil
+}
+
+func (m *mockAccessRuleRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.rules[id]; !ok {
+		return access.ErrRuleNotFound
+	}
+	delete(m.rules, id)
+	return nil
+}
+
+func TestAccessHTTPHandler(t *testing.T) {
+	service := application.NewAccessService(&mockAccessRuleRepository{rules: make(map[string]access.Rule)})
+	handler := NewAccessHTTPHandler(service)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"invalid body", `{`, http.StatusBadRequest},
+		{"unknown action", `{"network":"10.0.0.0/8","action":"block"}`, http.StatusBadRequest},
+		{"invalid network", `{"network":"10.0.0.0/40","action":"deny"}`, http.StatusBadRequest},
+		{"network and country", `{"network":"10.0.0.0/8","country":"ES","action":"deny"}`, http.StatusBadRequest},
+		{"country without geoip", `{"country":"ES","action":"allow"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := do(http.MethodPost, "/access-rules", tt.body); rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	rec := do(http.MethodPost, "/access-rules", `{"network":"10.1.2.3/8","action":"deny","note":"vpn"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created accessRuleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID == "" || created.Network != "10.0.0.0/8" || created.Action != access.ActionDeny || created.Note != "vpn" {
+		t.Errorf("unexpected rule %+v", created)
+	}
+
+	service.Check("10.9.9.9")
+	rec = do(http.MethodGet, "/access-rules", "")
+	var list []accessRuleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != created.ID || list[0].Hits != 1 || list[0].LastHitAt == "" {
+		t.Errorf("expected the created rule listed with its hit, got %+v", list)
+	}
+
+	if rec := do(http.MethodDelete, "/access-rules/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/access-rules/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/access-rules", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rec.Code)
+	}
+}
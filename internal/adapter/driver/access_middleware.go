@@ -0,0 +1,55 @@
+package driver
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// AccessMiddleware rejects the clients the access rules deny with 403
+// Forbidden on the playlist and stream routes, so that an instance reachable
+// from the internet only serves the networks and countries it is meant to.
+// The API and the web UI are left to authentication, so a rule cannot lock
+// the administrator out of changing the rules.
+//
+// Clients are identified by the connection's remote address, like the rate
+// limits.
+type AccessMiddleware struct {
+	service *application.AccessService
+	next    http.Handler
+	logger  *slog.Logger
+}
+
+// NewAccessMiddleware wraps next with the access rules of service.
+func NewAccessMiddleware(service *application.AccessService, next http.Handler, logger *slog.Logger) *AccessMiddleware {
+	return &AccessMiddleware{
+		service: service,
+		next:    next,
+		logger:  logger,
+	}
+}
+
+// ServeHTTP checks the client of playlist and stream requests before passing
+// them on.
+func (m *AccessMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !isAccessControlled(r.URL.Path) {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	if decision := m.service.Check(clientIP(r)); !decision.Allowed {
+		m.logger.WarnContext(r.Context(), "client denied by access rules",
+			"remote_addr", r.RemoteAddr, "path", r.URL.Path, "rule", decision.RuleID)
+		writeError(w, http.StatusForbidden, "access denied")
+		return
+	}
+
+	m.next.ServeHTTP(w, r)
+}
+
+// isAccessControlled reports whether path serves playlists or streams.
+func isAccessControlled(path string) bool {
+	return path == "/playlist.m3u" || strings.HasPrefix(path, "/playlist/") || strings.HasPrefix(path, "/ace/")
+}
@@ -0,0 +1,90 @@
+package driver
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/access"
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+func TestAccessMiddleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := application.NewAccessService(&mockAccessRuleRepository{rules: make(map[string]access.Rule)})
+	if _, err := service.CreateRule(context.Background(), "192.168.0.0/16", "", access.ActionAllow, ""); err != nil {
+		t.Fatal(err)
+	}
+	m := NewAccessMiddleware(service, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), logger)
+
+	tests := []struct {
+		remoteAddr string
+		path       string
+		wantStatus int
+	}{
+		{"192.168.1.5:5000", "/ace/getstream?id=abc", http.StatusOK},
+		{"192.168.1.5:5000", "/playlist.m3u", http.StatusOK},
+		{"203.0.113.7:5000", "/ace/getstream?id=abc", http.StatusForbidden},
+		{"203.0.113.7:5000", "/ace/channel/DAZN%201", http.StatusForbidden},
+		{"203.0.113.7:5000", "/playlist.m3u", http.StatusForbidden},
+		{"203.0.113.7:5000", "/playlist/sports.m3u", http.StatusForbidden},
+		{"[2001:db8::1]:5000", "/playlist.m3u", http.StatusForbidden},
+		{"203.0.113.7:5000", "/api/access-rules", http.StatusOK},
+		{"203.0.113.7:5000", "/", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remoteAddr+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+			m.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAccessMiddleware_TrustedProxies(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := application.NewAccessService(&mockAccessRuleRepository{rules: make(map[string]access.Rule)})
+	if _, err := service.CreateRule(context.Background(), "192.168.0.0/16", "", access.ActionAllow, ""); err != nil {
+		t.Fatal(err)
+	}
+	handler, err := NewPublicURLMiddleware("", NewAccessMiddleware(service, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.SetTrustedProxies([]netip.Prefix{netip.MustParsePrefix("172.16.0.0/12")})
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		wantStatus   int
+	}{
+		{"allowed client behind the proxy", "172.18.0.2:5000", "192.168.1.5", http.StatusOK},
+		{"denied client behind the proxy", "172.18.0.2:5000", "203.0.113.7", http.StatusForbidden},
+		{"spoofed header of an untrusted peer", "203.0.113.7:5000", "192.168.1.5", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/playlist.m3u", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
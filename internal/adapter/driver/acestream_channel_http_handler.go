@@ -0,0 +1,186 @@
+package driver
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/streaming"
+)
+
+// AceStreamChannelHTTPHandler streams a channel by name, failing over between
+// the channel's streams until one delivers data.
+type AceStreamChannelHTTPHandler struct {
+	channelService *application.ChannelService
+	streamService  *application.StreamService
+	proxyService   *application.AceStreamProxyService
+	probeService   *application.ProbeService
+	links          *application.StreamLinks
+	players        *application.PlayerProfiles
+	// maxWriteTimeout caps the write timeout a client can hint; zero
+	// leaves hints uncapped.
+	maxWriteTimeout time.Duration
+	logger          *slog.Logger
+}
+
+// NewAceStreamChannelHTTPHandler creates a new HTTP handler for channel streaming.
+// The channel's transcode settings are applied to whichever stream is served.
+// If probeService is nil, streams are tried in repository order instead of by
+// quality score.
+func NewAceStreamChannelHTTPHandler(
+	channelService *application.ChannelService,
+	streamService *application.StreamService,
+	proxyService *application.AceStreamProxyService,
+	probeService *application.ProbeService,
+	logger *slog.Logger,
+) *AceStreamChannelHTTPHandler {
+	return &AceStreamChannelHTTPHandler{
+		channelService: channelService,
+		streamService:  streamService,
+		proxyService:   proxyService,
+		probeService:   probeService,
+		logger:         logger,
+	}
+}
+
+// SetStreamLinks makes requests by alias and by channel name check the
+// signature of their link, refusing tampered or expired ones and, if signed
+// links are required, unsigned ones. Playlists sign alias links, and the
+// HDHomeRun and DLNA lineups sign links by channel name.
+func (h *AceStreamChannelHTTPHandler) SetStreamLinks(links *application.StreamLinks) {
+	h.links = links
+}
+
+// SetMaxWriteTimeoutHint caps the write timeout a client can ask for, as
+// AceStreamHTTPHandler.SetMaxWriteTimeoutHint does.
+func (h *AceStreamChannelHTTPHandler) SetMaxWriteTimeoutHint(max time.Duration) {
+	h.maxWriteTimeout = max
+}
+
+// SetPlayerProfiles sets the Content-Type and connection headers of streams
+// from the profile of the player asking for them, by User-Agent. Channels
+// are always served as TS, failing over between streams, even to players
+// whose profile asks for HLS.
+func (h *AceStreamChannelHTTPHandler) SetPlayerProfiles(players *application.PlayerProfiles) {
+	h.players = players
+}
+
+// ServeHTTP handles GET /ace/channel/{channelName} and GET /ace/c/{alias}
+func (h *AceStreamChannelHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	channelName := strings.TrimPrefix(r.URL.Path, "/ace/channel/")
+	if alias, ok := strings.CutPrefix(r.URL.Path, "/ace/c/"); ok {
+		if alias == "" {
+			writeError(w, http.StatusBadRequest, "missing channel alias")
+			return
+		}
+		if h.links != nil {
+			if err := h.links.VerifyAlias(r.Context(), alias, r.URL.Query()); err != nil {
+				h.logger.WarnContext(r.Context(), "stream link refused", "remote_addr", r.RemoteAddr, "alias", alias, "error", err)
+				status, message := streamLinkError(err)
+				writeError(w, status, message)
+				return
+			}
+		}
+		ch, err := h.channelService.ResolveAlias(r.Context(), alias)
+		if err != nil {
+			if errors.Is(err, channel.ErrChannelNotFound) {
+				writeError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		channelName = ch.Name()
+	}
+	if channelName == "" {
+		writeError(w, http.StatusBadRequest, "missing channel name")
+		return
+	}
+	if h.links != nil && strings.HasPrefix(r.URL.Path, "/ace/channel/") {
+		if err := h.links.VerifyChannel(r.Context(), channelName, r.URL.Query()); err != nil {
+			h.logger.WarnContext(r.Context(), "stream link refused", "remote_addr", r.RemoteAddr, "channel", channelName, "error", err)
+			status, message := streamLinkError(err)
+			writeError(w, status, message)
+			return
+		}
+	}
+
+	writeTimeout, err := parseWriteTimeoutHint(r, h.maxWriteTimeout)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "validation error", "error", "invalid write timeout", "remote_addr", r.RemoteAddr, "details", err)
+		writeError(w, http.StatusBadRequest, "invalid write timeout")
+		return
+	}
+
+	opts := application.StreamOptions{WriteTimeout: writeTimeout}
+	ch, err := h.channelService.GetChannel(r.Context(), channelName)
+	switch {
+	case err == nil:
+		opts.TranscodeAudio = ch.AudioTranscode()
+		opts.TranscodeVideo = ch.VideoTranscode()
+	case !errors.Is(err, channel.ErrChannelNotFound):
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	streams, err := h.streamService.ListChannelStreams(r.Context(), channelName)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(streams) == 0 {
+		writeError(w, http.StatusNotFound, "channel has no streams")
+		return
+	}
+
+	infoHashes := make([]string, len(streams))
+	for i, st := range streams {
+		infoHashes[i] = st.InfoHash()
+	}
+	if h.probeService != nil {
+		infoHashes = h.probeService.RankStreams(r.Context(), channelName, infoHashes)
+	}
+
+	h.logger.InfoContext(r.Context(), "channel stream request received", "remote_addr", r.RemoteAddr, "channel", channelName, "candidates", len(infoHashes))
+
+	startTime := time.Now()
+
+	setStreamHeaders(w, playerProfile(h.players, r.Header.Get("User-Agent")))
+
+	infoHash, err := h.proxyService.StreamWithFailover(withClientInfo(r, channelName), channelName, infoHashes, w, opts)
+	duration := time.Since(startTime)
+
+	if err != nil {
+		if infoHash == "" && errors.Is(err, application.ErrStreamLimitReached) {
+			h.logger.WarnContext(r.Context(), "service error", "error", "engine stream limit reached", "remote_addr", r.RemoteAddr, "channel", channelName)
+			writeStreamLimitError(w)
+			h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "channel", channelName, "duration", duration, "reason", "stream_limit_reached")
+			return
+		}
+		if infoHash == "" && errors.Is(err, application.ErrAllStreamsFailed) {
+			h.logger.ErrorContext(r.Context(), "service error", "error", "all channel streams failed", "remote_addr", r.RemoteAddr, "channel", channelName, "details", err)
+			setRetryAfter(w, err)
+			writeError(w, http.StatusServiceUnavailable, "no working stream for channel")
+			h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "channel", channelName, "duration", duration, "reason", "all_streams_failed")
+			return
+		}
+		if streaming.IsClientDisconnectError(err) || r.Context().Err() != nil {
+			h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "channel", channelName, "infohash", infoHash, "duration", duration, "reason", "client_disconnected")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "service error", "error", "stream failed", "remote_addr", r.RemoteAddr, "channel", channelName, "infohash", infoHash, "details", err)
+		h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "channel", channelName, "infohash", infoHash, "duration", duration, "reason", "stream_error")
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "channel", channelName, "infohash", infoHash, "duration", duration, "reason", "success")
+}
@@ -0,0 +1,172 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+func TestAceStreamChannelHTTPHandler_ServeHTTP(t *testing.T) {
+	channel1, _ := channel.NewChannel("Channel1")
+	_ = channel1.SetAliases([]string{"ch1"})
+
+	newHandler := func(streams []stream.Stream, engine *mockAceStreamEngine) *AceStreamChannelHTTPHandler {
+		streamRepo := &mockStreamRepository{
+			findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+				var found []stream.Stream
+				for _, st := range streams {
+					if st.ChannelName() == channelName {
+						found = append(found, st)
+					}
+				}
+				return found, nil
+			},
+		}
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{channel1}, nil
+			},
+		}
+		channelService := application.NewChannelService(channelRepo, streamRepo)
+		streamService := application.NewStreamService(streamRepo, channelRepo)
+		proxyService := application.NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		return NewAceStreamChannelHTTPHandler(channelService, streamService, proxyService, nil, slog.Default())
+	}
+
+	t.Run("GET /ace/channel/{name} returns 404 when channel has no streams", func(t *testing.T) {
+		handler := newHandler(nil, &mockAceStreamEngine{})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/channel/Empty", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("GET /ace/channel/{name} returns 503 when every stream fails", func(t *testing.T) {
+		s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+		s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel1", "")
+		var tried []string
+		engine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				tried = append(tried, infoHash)
+				return "", errors.New("no peers")
+			},
+		}
+		handler := newHandler([]stream.Stream{s1, s2}, engine)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/channel/Channel1", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rec.Code)
+		}
+		if len(tried) != 2 {
+			t.Errorf("expected both streams to be tried, got %v", tried)
+		}
+	})
+
+	t.Run("GET /ace/c/{alias} streams the channel with that alias", func(t *testing.T) {
+		s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+		var tried []string
+		engine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				tried = append(tried, infoHash)
+				return "", errors.New("no peers")
+			},
+		}
+		handler := newHandler([]stream.Stream{s1}, engine)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/c/CH1", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rec.Code)
+		}
+		if len(tried) != 1 || tried[0] != "6861736831000000000000000000000000000000" {
+			t.Errorf("expected the channel's stream to be tried, got %v", tried)
+		}
+	})
+
+	t.Run("GET /ace/c/{alias} returns 404 for an unknown alias", func(t *testing.T) {
+		handler := newHandler(nil, &mockAceStreamEngine{})
+
+		for _, target := range []string{"/ace/c/unknown", "/ace/c/not%20a%20slug"} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("%s: expected status 404, got %d", target, rec.Code)
+			}
+		}
+	})
+
+	t.Run("GET /ace/channel/ returns 400 without channel name", func(t *testing.T) {
+		handler := newHandler(nil, &mockAceStreamEngine{})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/channel/", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("GET /ace/channel/{name} checks the link signature", func(t *testing.T) {
+		s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+		signer := auth.NewLinkSigner([]auth.LinkKey{{ID: "k1", Secret: []byte("link-secret")}})
+		valid := signer.Sign("channel:Channel1", "", time.Now().Add(time.Hour)).Query().Encode()
+		other := signer.Sign("channel:Channel2", "", time.Now().Add(time.Hour)).Query().Encode()
+
+		tests := []struct {
+			name       string
+			query      string
+			wantStatus int
+		}{
+			{"valid signature", "?" + valid, http.StatusServiceUnavailable},
+			{"unsigned while required", "", http.StatusForbidden},
+			{"signed for another channel", "?" + other, http.StatusForbidden},
+		}
+		for _, tt := range tests {
+			var tried []string
+			engine := &mockAceStreamEngine{
+				startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+					tried = append(tried, infoHash)
+					return "", errors.New("no peers")
+				},
+			}
+			handler := newHandler([]stream.Stream{s1}, engine)
+			handler.SetStreamLinks(application.NewStreamLinks(signer, time.Hour, true, nil))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/channel/Channel1"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusForbidden && len(tried) != 0 {
+				t.Errorf("%s: expected no stream for a refused link, got %v", tt.name, tried)
+			}
+		}
+	})
+
+	t.Run("POST /ace/channel/{name} returns 405", func(t *testing.T) {
+		handler := newHandler(nil, &mockAceStreamEngine{})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ace/channel/Channel1", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
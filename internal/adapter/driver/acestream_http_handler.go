@@ -5,86 +5,479 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/circuitbreaker"
+	"github.com/alorle/iptv-manager/internal/stream"
 	"github.com/alorle/iptv-manager/internal/streaming"
 )
 
 // StreamProxy defines the streaming operations needed by the handler.
 type StreamProxy interface {
 	StreamToClient(ctx context.Context, infoHash string, dst io.Writer) error
+	StreamToClientWithOptions(ctx context.Context, infoHash string, dst io.Writer, opts application.StreamOptions) error
 }
 
+// HLSProvider defines the HLS remux operations needed by the handler.
+type HLSProvider interface {
+	Playlist(ctx context.Context, infoHash string, segmentURI func(seq uint64) string) (string, error)
+	Segment(infoHash string, seq uint64) ([]byte, error)
+}
+
+// writeTimeoutHeader lets a client hint its own write timeout, overriding the
+// global STREAM_WRITE_TIMEOUT. The write_timeout query parameter is accepted as
+// an alternative for players that cannot set custom headers.
+const writeTimeoutHeader = "X-Stream-Write-Timeout"
+
+// sessionTokenHeader carries the token a client reconnects with to resume its
+// stream session. It is sent with every stream, and accepted in requests
+// along with the session query parameter.
+const sessionTokenHeader = "X-Stream-Session"
+
 // AceStreamHTTPHandler handles HTTP requests for AceStream proxy.
 type AceStreamHTTPHandler struct {
 	proxyService StreamProxy
-	logger       *slog.Logger
+	hls          HLSProvider
+	links        *application.StreamLinks
+	players      *application.PlayerProfiles
+	// maxWriteTimeout caps the write timeout a client can hint; zero
+	// leaves hints uncapped.
+	maxWriteTimeout time.Duration
+	logger          *slog.Logger
 }
 
 // NewAceStreamHTTPHandler creates a new HTTP handler for AceStream proxy.
-func NewAceStreamHTTPHandler(proxyService StreamProxy, logger *slog.Logger) *AceStreamHTTPHandler {
+// If hls is nil, the HLS routes respond with 404.
+func NewAceStreamHTTPHandler(proxyService StreamProxy, hls HLSProvider, logger *slog.Logger) *AceStreamHTTPHandler {
 	return &AceStreamHTTPHandler{
 		proxyService: proxyService,
+		hls:          hls,
 		logger:       logger,
 	}
 }
 
-// ServeHTTP handles GET /ace/getstream?id={infoHash}
+// SetStreamLinks makes stream, HLS playlist and HLS segment requests check
+// the signature of their link, refusing tampered or expired ones and, if
+// signed links are required, unsigned ones.
+func (h *AceStreamHTTPHandler) SetStreamLinks(links *application.StreamLinks) {
+	h.links = links
+}
+
+// SetMaxWriteTimeoutHint caps the write timeout a client can ask for with
+// the X-Stream-Write-Timeout header or the write_timeout query parameter, so
+// a client cannot hold a stalled connection open indefinitely. Longer hints
+// are lowered to max.
+func (h *AceStreamHTTPHandler) SetMaxWriteTimeoutHint(max time.Duration) {
+	h.maxWriteTimeout = max
+}
+
+// SetPlayerProfiles adapts streams to the player asking for them, by
+// User-Agent: its profile sets the Content-Type and connection headers, and
+// players whose profile asks for HLS are redirected to the stream's HLS
+// playlist if HLS output is enabled.
+func (h *AceStreamHTTPHandler) SetPlayerProfiles(players *application.PlayerProfiles) {
+	h.players = players
+}
+
+// engineResponse is the JSON envelope the AceStream engine answers
+// format=json requests with.
+type engineResponse struct {
+	Response *enginePlayback `json:"response"`
+	Error    *string         `json:"error"`
+}
+
+// enginePlayback describes where to play a stream, as the engine does.
+type enginePlayback struct {
+	InfoHash          string `json:"infohash"`
+	PlaybackURL       string `json:"playback_url"`
+	PlaybackSessionID string `json:"playback_session_id"`
+	IsLive            int    `json:"is_live"`
+	IsEncrypted       int    `json:"is_encrypted"`
+}
+
+// ServeHTTP handles:
+//   - GET /ace/getstream?id={infoHash}
+//   - GET /ace/{infoHash}.m3u8
+//   - GET /ace/hls/{infoHash}/{seq}.ts
+//
+// Like the engine and acexy, getstream accepts infohash= in place of id=, a
+// player-chosen pid= that labels the client session, and format=json to get
+// the playback URL instead of the stream. Streams go through the proxy and
+// are shared by every client whatever their pid. A client reconnecting with
+// the X-Stream-Session token of its previous response, as a header or the
+// session= parameter, resumes that session within the resume grace window.
 func (h *AceStreamHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
+	if strings.HasPrefix(r.URL.Path, "/ace/hls/") {
+		h.serveSegment(w, r)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, ".m3u8") {
+		h.servePlaylist(w, r)
+		return
+	}
+
 	// Extract infohash from query parameter
-	infoHash := r.URL.Query().Get("id")
+	query := r.URL.Query()
+	infoHash := query.Get("id")
 	if infoHash == "" {
-		h.logger.Warn("validation error", "error", "missing infohash", "remote_addr", r.RemoteAddr)
+		infoHash = query.Get("infohash")
+	}
+	asJSON := query.Get("format") == "json"
+	if infoHash == "" {
+		h.logger.WarnContext(r.Context(), "validation error", "error", "missing infohash", "remote_addr", r.RemoteAddr)
+		if asJSON {
+			writeEngineError(w, http.StatusBadRequest, "missing 'id' query parameter")
+			return
+		}
 		writeError(w, http.StatusBadRequest, "missing 'id' query parameter")
 		return
 	}
+	parsed, err := stream.ParseInfoHash(infoHash)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "validation error", "error", "invalid infohash", "remote_addr", r.RemoteAddr, "infohash", infoHash)
+		if asJSON {
+			writeEngineError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	infoHash = parsed.String()
+	if !h.verifyLink(w, r, infoHash, asJSON) {
+		return
+	}
+
+	if asJSON {
+		h.servePlayback(w, r, infoHash)
+		return
+	}
+
+	writeTimeout, err := parseWriteTimeoutHint(r, h.maxWriteTimeout)
+	if err != nil {
+		h.logger.WarnContext(r.Context(), "validation error", "error", "invalid write timeout", "remote_addr", r.RemoteAddr, "details", err)
+		writeError(w, http.StatusBadRequest, "invalid write timeout")
+		return
+	}
 
 	userAgent := r.Header.Get("User-Agent")
-	h.logger.Info("stream request received", "remote_addr", r.RemoteAddr, "infohash", infoHash, "user_agent", userAgent)
+	profile := playerProfile(h.players, userAgent)
+	h.logger.InfoContext(r.Context(), "stream request received", "remote_addr", r.RemoteAddr, "infohash", infoHash, "user_agent", userAgent, "player", profile.Name)
+
+	if profile.Container == application.ContainerHLS && h.hls != nil {
+		// Keep the query, which may carry the link signature
+		target := publicPath(r, "/ace/"+infoHash+".m3u8")
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		w.Header().Set("Vary", "User-Agent")
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
 
 	startTime := time.Now()
 
-	// Set appropriate headers for streaming
-	w.Header().Set("Content-Type", "video/mpeg")
-	w.Header().Set("Transfer-Encoding", "chunked")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.Header().Set("Pragma", "no-cache")
-	w.Header().Set("Expires", "0")
+	setStreamHeaders(w, profile)
+
+	token := sessionToken(r)
+	w.Header().Set(sessionTokenHeader, token)
 
 	// Stream to client
-	err := h.proxyService.StreamToClient(r.Context(), infoHash, w)
+	opts := application.StreamOptions{WriteTimeout: writeTimeout, ResumeToken: token}
+	err = h.proxyService.StreamToClientWithOptions(withClientInfo(r, ""), infoHash, w, opts)
 	duration := time.Since(startTime)
 
 	if err != nil {
 		// Log error but don't write response as streaming may have started
 		if errors.Is(err, application.ErrInvalidInfoHash) {
-			h.logger.Warn("validation error", "error", "invalid infohash", "remote_addr", r.RemoteAddr, "infohash", infoHash)
+			h.logger.WarnContext(r.Context(), "validation error", "error", "invalid infohash", "remote_addr", r.RemoteAddr, "infohash", infoHash)
 			// Only write error if we haven't started streaming
 			writeError(w, http.StatusBadRequest, err.Error())
-			h.logger.Info("request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "validation_error")
+			h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "validation_error")
+			return
+		}
+		if errors.Is(err, application.ErrStreamLimitReached) {
+			h.logger.WarnContext(r.Context(), "service error", "error", "engine stream limit reached", "remote_addr", r.RemoteAddr, "infohash", infoHash)
+			writeStreamLimitError(w)
+			h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "stream_limit_reached")
 			return
 		}
 		if errors.Is(err, application.ErrEngineUnavailable) {
-			h.logger.Error("service error", "error", "engine unavailable", "remote_addr", r.RemoteAddr, "infohash", infoHash)
+			h.logger.ErrorContext(r.Context(), "service error", "error", "engine unavailable", "remote_addr", r.RemoteAddr, "infohash", infoHash)
+			setRetryAfter(w, err)
 			writeError(w, http.StatusServiceUnavailable, "acestream engine unavailable")
-			h.logger.Info("request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "engine_unavailable")
+			h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "engine_unavailable")
 			return
 		}
 		if streaming.IsClientDisconnectError(err) {
-			h.logger.Info("request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "client_disconnected")
+			h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "client_disconnected")
 			return
 		}
-		h.logger.Error("service error", "error", "stream failed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "details", err)
-		h.logger.Info("request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "stream_error")
+		h.logger.ErrorContext(r.Context(), "service error", "error", "stream failed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "details", err)
+		h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "stream_error")
 		return
 	}
 
-	h.logger.Info("request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "success")
+	h.logger.InfoContext(r.Context(), "request completed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "duration", duration, "reason", "success")
+}
+
+// sessionToken returns the resume token the client sent, or a new one if it
+// sent none or one that is not safe to echo back.
+func sessionToken(r *http.Request) string {
+	token := r.Header.Get(sessionTokenHeader)
+	if token == "" {
+		token = r.URL.Query().Get("session")
+	}
+	if !validRequestID(token) {
+		token = newRequestID()
+	}
+	return token
+}
+
+// servePlayback handles GET /ace/getstream?id={infoHash}&format=json by
+// pointing the player back at this proxy, keeping its pid.
+func (h *AceStreamHTTPHandler) servePlayback(w http.ResponseWriter, r *http.Request, infoHash string) {
+	sessionID := r.URL.Query().Get("pid")
+	if sessionID == "" {
+		sessionID = strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	playback := url.Values{"id": {infoHash}, "pid": {sessionID}}
+	writeJSON(w, http.StatusOK, engineResponse{
+		Response: &enginePlayback{
+			InfoHash:          infoHash,
+			PlaybackURL:       publicBaseURL(r) + "/ace/getstream?" + playback.Encode(),
+			PlaybackSessionID: sessionID,
+			IsLive:            1,
+		},
+	})
+}
+
+// writeEngineError writes an error in the engine's format=json envelope.
+func writeEngineError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, engineResponse{Error: &message})
+}
+
+// verifyLink checks the signature of the link to infoHash, writing the error
+// response and returning false if the link is refused.
+func (h *AceStreamHTTPHandler) verifyLink(w http.ResponseWriter, r *http.Request, infoHash string, asJSON bool) bool {
+	if h.links == nil {
+		return true
+	}
+	err := h.links.VerifyStream(r.Context(), infoHash, r.URL.Query())
+	if err == nil {
+		return true
+	}
+
+	h.logger.WarnContext(r.Context(), "stream link refused", "remote_addr", r.RemoteAddr, "infohash", infoHash, "error", err)
+	status, message := streamLinkError(err)
+	if asJSON {
+		writeEngineError(w, status, message)
+		return false
+	}
+	writeError(w, status, message)
+	return false
+}
+
+// streamLinkError maps a stream link verification error to a response.
+func streamLinkError(err error) (int, string) {
+	switch {
+	case errors.Is(err, application.ErrStreamLinkRequired),
+		errors.Is(err, auth.ErrInvalidLinkSignature),
+		errors.Is(err, auth.ErrLinkExpired):
+		return http.StatusForbidden, err.Error()
+	default:
+		return http.StatusInternalServerError, "internal server error"
+	}
+}
+
+// servePlaylist handles GET /ace/{infoHash}.m3u8
+func (h *AceStreamHTTPHandler) servePlaylist(w http.ResponseWriter, r *http.Request) {
+	if h.hls == nil {
+		writeError(w, http.StatusNotFound, "hls output is disabled")
+		return
+	}
+
+	parsed, err := stream.ParseInfoHash(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/ace/"), ".m3u8"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid infohash")
+		return
+	}
+	infoHash := parsed.String()
+	if !h.verifyLink(w, r, infoHash, false) {
+		return
+	}
+
+	// Segments carry the signature of the playlist link, so they are only
+	// served while it is valid.
+	segmentQuery := ""
+	if sig, signed, err := auth.ParseLinkSignature(r.URL.Query()); err == nil && signed {
+		segmentQuery = "?" + sig.Query().Encode()
+	}
+	playlist, err := h.hls.Playlist(r.Context(), infoHash, func(seq uint64) string {
+		return publicPath(r, "/ace/hls/"+infoHash+"/"+strconv.FormatUint(seq, 10)+".ts") + segmentQuery
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrInvalidInfoHash):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, application.ErrStreamLimitReached):
+			writeStreamLimitError(w)
+		case errors.Is(err, application.ErrEngineUnavailable):
+			setRetryAfter(w, err)
+			writeError(w, http.StatusServiceUnavailable, "acestream engine unavailable")
+		case errors.Is(err, application.ErrHLSNotReady):
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+		case streaming.IsClientDisconnectError(err):
+			return
+		default:
+			h.logger.ErrorContext(r.Context(), "service error", "error", "hls playlist failed", "remote_addr", r.RemoteAddr, "infohash", infoHash, "details", err)
+			writeError(w, http.StatusBadGateway, "stream failed")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = io.WriteString(w, playlist)
+}
+
+// serveSegment handles GET /ace/hls/{infoHash}/{seq}.ts
+func (h *AceStreamHTTPHandler) serveSegment(w http.ResponseWriter, r *http.Request) {
+	if h.hls == nil {
+		writeError(w, http.StatusNotFound, "hls output is disabled")
+		return
+	}
+
+	infoHash, name, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/ace/hls/"), "/")
+	seqStr, isTS := strings.CutSuffix(name, ".ts")
+	if !ok || infoHash == "" || !isTS {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid segment number")
+		return
+	}
+	if !h.verifyLink(w, r, infoHash, false) {
+		return
+	}
+
+	data, err := h.hls.Segment(infoHash, seq)
+	if err != nil {
+		if errors.Is(err, application.ErrHLSSegmentNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "video/mp2t")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	_, _ = w.Write(data)
+}
+
+// playerProfile returns the profile of the player with the given
+// User-Agent, or the zero profile if streams are not adapted to players.
+func playerProfile(players *application.PlayerProfiles, userAgent string) application.PlayerProfile {
+	if players == nil {
+		return application.PlayerProfile{}
+	}
+	return players.Match(userAgent)
+}
+
+// setStreamHeaders sets the headers of a raw TS stream for the player's
+// profile.
+func setStreamHeaders(w http.ResponseWriter, profile application.PlayerProfile) {
+	contentType := profile.ContentType
+	if contentType == "" {
+		contentType = "video/mpeg"
+	}
+	w.Header().Set("Content-Type", contentType)
+	if profile.DisableChunking {
+		// net/http then sends the body as is and closes the connection
+		// after it
+		w.Header().Set("Transfer-Encoding", "identity")
+	} else {
+		w.Header().Set("Transfer-Encoding", "chunked")
+	}
+	if profile.KeepAlive {
+		w.Header().Set("Connection", "keep-alive")
+	}
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	w.Header().Set("Vary", "User-Agent")
+}
+
+// writeStreamLimitError answers a request that would exceed the engine stream
+// limit, after any wait in the stream queue. The X-HDHomeRun-Error header
+// lets HDHomeRun clients such as Plex report that all tuners are in use.
+func writeStreamLimitError(w http.ResponseWriter) {
+	w.Header().Set("X-HDHomeRun-Error", "805 All Tuners In Use")
+	w.Header().Set("Retry-After", strconv.FormatInt(int64(streamLimitRetryAfter/time.Second), 10))
+	writeError(w, http.StatusServiceUnavailable, "all tuners in use")
+}
+
+// setRetryAfter sets the Retry-After header from the remaining open time of
+// the circuit breaker that rejected the request, rounded up to whole seconds.
+// Nothing is set if the error did not come from an open breaker.
+func setRetryAfter(w http.ResponseWriter, err error) {
+	var openErr *circuitbreaker.OpenError
+	if !errors.As(err, &openErr) {
+		return
+	}
+	seconds := int64(math.Ceil(openErr.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(max(seconds, 1), 10))
+}
+
+// withClientInfo returns the request context annotated with who is asking for
+// the stream, so the proxy can attribute the client session.
+func withClientInfo(r *http.Request, channel string) context.Context {
+	ip := clientIP(r)
+	return application.WithClientInfo(r.Context(), application.ClientInfo{
+		ClientIP:  ip,
+		UserAgent: r.Header.Get("User-Agent"),
+		Channel:   channel,
+		PlayerID:  r.URL.Query().Get("pid"),
+	})
+}
+
+// parseWriteTimeoutHint extracts the per-client write timeout from the request,
+// lowered to max if max is positive.
+// Returns zero when no hint is present, meaning the service default applies.
+func parseWriteTimeoutHint(r *http.Request, max time.Duration) (time.Duration, error) {
+	raw := r.Header.Get(writeTimeoutHeader)
+	if raw == "" {
+		raw = r.URL.Query().Get("write_timeout")
+	}
+	if raw == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, err
+	}
+	if timeout <= 0 {
+		return 0, errors.New("write timeout must be positive")
+	}
+	if max > 0 && timeout > max {
+		timeout = max
+	}
+	return timeout, nil
 }
@@ -2,19 +2,37 @@ package driver
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/circuitbreaker"
+	"github.com/alorle/iptv-manager/internal/port/driven"
 )
 
 // mockProxyService is a minimal stand-in for AceStreamProxyService.
 // It writes data to the response writer over a configurable duration.
 type mockProxyService struct {
-	streamDuration time.Duration
-	chunkInterval  time.Duration
+	streamDuration   time.Duration
+	chunkInterval    time.Duration
+	lastWriteTimeout time.Duration
+	lastInfoHash     string
+	lastResumeToken  string
+}
+
+func (m *mockProxyService) StreamToClientWithOptions(ctx context.Context, infoHash string, w io.Writer, opts application.StreamOptions) error {
+	m.lastWriteTimeout = opts.WriteTimeout
+	m.lastInfoHash = infoHash
+	m.lastResumeToken = opts.ResumeToken
+	return m.StreamToClient(ctx, infoHash, w)
 }
 
 func (m *mockProxyService) StreamToClient(ctx context.Context, infoHash string, w io.Writer) error {
@@ -44,13 +62,13 @@ func TestAceStreamHTTPHandler_LongStream(t *testing.T) {
 		chunkInterval:  500 * time.Millisecond,
 	}
 	logger := slog.Default()
-	handler := NewAceStreamHTTPHandler(mock, logger)
+	handler := NewAceStreamHTTPHandler(mock, nil, logger)
 
 	// Create a test server with WriteTimeout: 0 (no global timeout)
 	server := httptest.NewServer(handler)
 	defer server.Close()
 
-	resp, err := http.Get(server.URL + "/ace/getstream?id=abc123")
+	resp, err := http.Get(server.URL + "/ace/getstream?id=6162633132330000000000000000000000000000")
 	if err != nil {
 		t.Fatalf("request failed: %v", err)
 	}
@@ -65,3 +83,450 @@ func TestAceStreamHTTPHandler_LongStream(t *testing.T) {
 		t.Fatal("expected data from stream, got empty response")
 	}
 }
+
+func TestAceStreamHTTPHandler_WriteTimeoutHint(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		header      string
+		wantStatus  int
+		wantTimeout time.Duration
+	}{
+		{"no hint uses default", "/ace/getstream?id=6162633132330000000000000000000000000000", "", http.StatusOK, 0},
+		{"header hint", "/ace/getstream?id=6162633132330000000000000000000000000000", "2s", http.StatusOK, 2 * time.Second},
+		{"query hint", "/ace/getstream?id=6162633132330000000000000000000000000000&write_timeout=500ms", "", http.StatusOK, 500 * time.Millisecond},
+		{"header wins over query", "/ace/getstream?id=6162633132330000000000000000000000000000&write_timeout=500ms", "3s", http.StatusOK, 3 * time.Second},
+		{"invalid hint", "/ace/getstream?id=6162633132330000000000000000000000000000&write_timeout=soon", "", http.StatusBadRequest, 0},
+		{"negative hint", "/ace/getstream?id=6162633132330000000000000000000000000000", "-1s", http.StatusBadRequest, 0},
+		{"hint above the maximum is lowered", "/ace/getstream?id=6162633132330000000000000000000000000000", "24h", http.StatusOK, time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockProxyService{
+				streamDuration: 10 * time.Millisecond,
+				chunkInterval:  time.Millisecond,
+			}
+			handler := NewAceStreamHTTPHandler(mock, nil, slog.Default())
+			handler.SetMaxWriteTimeoutHint(time.Minute)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(writeTimeoutHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if mock.lastWriteTimeout != tt.wantTimeout {
+				t.Errorf("expected write timeout %v, got %v", tt.wantTimeout, mock.lastWriteTimeout)
+			}
+		})
+	}
+}
+
+func TestAceStreamHTTPHandler_SessionToken(t *testing.T) {
+	const hash = "94c2fd8fa9b16211252c5e9f0b836d94155b505a"
+	tests := []struct {
+		name      string
+		target    string
+		header    string
+		wantToken string
+	}{
+		{"issued when missing", "/ace/getstream?id=" + hash, "", ""},
+		{"header", "/ace/getstream?id=" + hash, "abc-123", "abc-123"},
+		{"query", "/ace/getstream?id=" + hash + "&session=abc-123", "", "abc-123"},
+		{"unsafe token replaced", "/ace/getstream?id=" + hash + "&session=" + url.QueryEscape("a b"), "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockProxyService{streamDuration: time.Millisecond, chunkInterval: time.Millisecond}
+			handler := NewAceStreamHTTPHandler(mock, nil, slog.Default())
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(sessionTokenHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(sessionTokenHeader)
+			if got == "" || got != mock.lastResumeToken {
+				t.Fatalf("expected the response token %q to be used to resume, got %q", got, mock.lastResumeToken)
+			}
+			if tt.wantToken != "" && got != tt.wantToken {
+				t.Errorf("expected token %q, got %q", tt.wantToken, got)
+			}
+			if tt.wantToken == "" && got == "a b" {
+				t.Errorf("expected an unsafe token to be replaced, got %q", got)
+			}
+		})
+	}
+}
+
+func TestAceStreamHTTPHandler_SignedLinks(t *testing.T) {
+	const hash = "94c2fd8fa9b16211252c5e9f0b836d94155b505a"
+	signer := auth.NewLinkSigner([]auth.LinkKey{{ID: "k1", Secret: []byte("link-secret")}})
+	valid := signer.Sign("stream:"+hash, "", time.Now().Add(time.Hour)).Query().Encode()
+	expired := signer.Sign("stream:"+hash, "", time.Now().Add(-time.Minute)).Query().Encode()
+	other := signer.Sign("stream:0000000000000000000000000000000000000000", "", time.Now().Add(time.Hour)).Query().Encode()
+
+	tests := []struct {
+		name       string
+		required   bool
+		query      string
+		wantStatus int
+	}{
+		{"valid signature", true, "&" + valid, http.StatusOK},
+		{"unsigned while optional", false, "", http.StatusOK},
+		{"unsigned while required", true, "", http.StatusForbidden},
+		{"expired", false, "&" + expired, http.StatusForbidden},
+		{"signed for another stream", false, "&" + other, http.StatusForbidden},
+		{"malformed", false, "&sig=x", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockProxyService{streamDuration: time.Millisecond, chunkInterval: time.Millisecond}
+			handler := NewAceStreamHTTPHandler(mock, nil, slog.Default())
+			handler.SetStreamLinks(application.NewStreamLinks(signer, time.Hour, tt.required, nil))
+			req := httptest.NewRequest(http.MethodGet, "/ace/getstream?id="+hash+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusForbidden && mock.lastInfoHash != "" {
+				t.Errorf("expected no stream for a refused link, got %q", mock.lastInfoHash)
+			}
+		})
+	}
+}
+
+func TestAceStreamHTTPHandler_AcexyCompat(t *testing.T) {
+	newHandler := func() (*AceStreamHTTPHandler, *mockProxyService) {
+		mock := &mockProxyService{streamDuration: 10 * time.Millisecond, chunkInterval: time.Millisecond}
+		return NewAceStreamHTTPHandler(mock, nil, slog.Default()), mock
+	}
+
+	t.Run("infohash parameter and pid are accepted", func(t *testing.T) {
+		handler, mock := newHandler()
+		req := httptest.NewRequest(http.MethodGet, "/ace/getstream?infohash=6162633132330000000000000000000000000000&pid=player1", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if mock.lastInfoHash != "6162633132330000000000000000000000000000" {
+			t.Errorf("expected the stream to be proxied, got infohash %q", mock.lastInfoHash)
+		}
+	})
+
+	t.Run("acestream URIs are normalized", func(t *testing.T) {
+		handler, mock := newHandler()
+		req := httptest.NewRequest(http.MethodGet, "/ace/getstream?id="+url.QueryEscape("acestream://94C2FD8FA9B16211252C5E9F0B836D94155B505A"), nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if mock.lastInfoHash != "94c2fd8fa9b16211252c5e9f0b836d94155b505a" {
+			t.Errorf("expected the normalized infohash to be proxied, got %q", mock.lastInfoHash)
+		}
+	})
+
+	t.Run("malformed infohashes are rejected", func(t *testing.T) {
+		handler, _ := newHandler()
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/getstream?id=abc123", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("format=json returns the playback url", func(t *testing.T) {
+		handler, mock := newHandler()
+		req := httptest.NewRequest(http.MethodGet, "http://proxy:8080/ace/getstream?id=6162633132330000000000000000000000000000&pid=player1&format=json", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var resp engineResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Error != nil || resp.Response == nil {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+		want := "http://proxy:8080/ace/getstream?id=6162633132330000000000000000000000000000&pid=player1"
+		if resp.Response.PlaybackURL != want || resp.Response.PlaybackSessionID != "player1" || resp.Response.IsLive != 1 {
+			t.Errorf("unexpected playback %+v, want url %q", resp.Response, want)
+		}
+		if mock.lastInfoHash != "" {
+			t.Error("expected format=json not to start the stream")
+		}
+	})
+
+	t.Run("format=json reports errors in the envelope", func(t *testing.T) {
+		handler, _ := newHandler()
+		req := httptest.NewRequest(http.MethodGet, "/ace/getstream?format=json", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400, got %d", rec.Code)
+		}
+		var resp engineResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Response != nil || resp.Error == nil {
+			t.Errorf("unexpected response %+v", resp)
+		}
+	})
+}
+
+func TestAceStreamHTTPHandler_BreakerRetryAfter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+	breaker := circuitbreaker.NewWithClock(1, 30*time.Second, clock)
+
+	engine := &mockAceStreamEngine{
+		startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+			return "", errors.New("engine down")
+		},
+	}
+	service := application.NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, breaker)
+	handler := NewAceStreamHTTPHandler(service, nil, slog.Default())
+
+	// First request fails against the engine and trips the breaker.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/getstream?id=6162633132330000000000000000000000000000", nil))
+	if got := breaker.State(); got != circuitbreaker.StateOpen {
+		t.Fatalf("expected breaker to be open, got %q", got)
+	}
+
+	now = now.Add(12 * time.Second)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/getstream?id=6162633132330000000000000000000000000000", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "18" {
+		t.Errorf("expected Retry-After 18 (30s timeout - 12s elapsed), got %q", got)
+	}
+}
+
+func TestAceStreamHTTPHandler_EngineBusy(t *testing.T) {
+	engine := &mockAceStreamEngine{
+		startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+			return "", driven.ErrEngineBusy
+		},
+	}
+	service := application.NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+	if err := service.SetStreamQueue(application.StreamQueueOptions{MaxWait: 50 * time.Millisecond}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler := NewAceStreamHTTPHandler(service, nil, slog.Default())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/getstream?id=6162633132330000000000000000000000000000", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "5" {
+		t.Errorf("expected Retry-After 5, got %q", got)
+	}
+	if stats := service.StreamQueueStats(); stats.Queued != 1 || stats.Rejected != 1 {
+		t.Errorf("expected the request to be queued and then rejected, got %+v", stats)
+	}
+}
+
+// mockHLSProvider implements HLSProvider for testing.
+type mockHLSProvider struct {
+	playlistFunc func(ctx context.Context, infoHash string, segmentURI func(seq uint64) string) (string, error)
+	segmentFunc  func(infoHash string, seq uint64) ([]byte, error)
+}
+
+func (m *mockHLSProvider) Playlist(ctx context.Context, infoHash string, segmentURI func(seq uint64) string) (string, error) {
+	if m.playlistFunc != nil {
+		return m.playlistFunc(ctx, infoHash, segmentURI)
+	}
+	return "", nil
+}
+
+func (m *mockHLSProvider) Segment(infoHash string, seq uint64) ([]byte, error) {
+	if m.segmentFunc != nil {
+		return m.segmentFunc(infoHash, seq)
+	}
+	return nil, application.ErrHLSSegmentNotFound
+}
+
+func TestAceStreamHTTPHandler_HLS(t *testing.T) {
+	provider := &mockHLSProvider{
+		playlistFunc: func(ctx context.Context, infoHash string, segmentURI func(seq uint64) string) (string, error) {
+			switch infoHash {
+			case "6162633132330000000000000000000000000000":
+				return "#EXTM3U\n" + segmentURI(7) + "\n", nil
+			case "736c6f7700000000000000000000000000000000":
+				return "", application.ErrHLSNotReady
+			}
+			return "", application.ErrEngineUnavailable
+		},
+		segmentFunc: func(infoHash string, seq uint64) ([]byte, error) {
+			if infoHash == "6162633132330000000000000000000000000000" && seq == 7 {
+				return []byte("ts-data"), nil
+			}
+			return nil, application.ErrHLSSegmentNotFound
+		},
+	}
+	handler := NewAceStreamHTTPHandler(&mockProxyService{}, provider, slog.Default())
+
+	tests := []struct {
+		name            string
+		target          string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		{"playlist", "/ace/6162633132330000000000000000000000000000.m3u8", http.StatusOK, "application/vnd.apple.mpegurl", "#EXTM3U\n/ace/hls/6162633132330000000000000000000000000000/7.ts\n"},
+		{"playlist not ready", "/ace/736c6f7700000000000000000000000000000000.m3u8", http.StatusServiceUnavailable, "", ""},
+		{"playlist engine unavailable", "/ace/646f776e00000000000000000000000000000000.m3u8", http.StatusServiceUnavailable, "", ""},
+		{"playlist missing infohash", "/ace/.m3u8", http.StatusBadRequest, "", ""},
+		{"segment", "/ace/hls/6162633132330000000000000000000000000000/7.ts", http.StatusOK, "video/mp2t", "ts-data"},
+		{"segment evicted", "/ace/hls/6162633132330000000000000000000000000000/1.ts", http.StatusNotFound, "", ""},
+		{"segment bad number", "/ace/hls/6162633132330000000000000000000000000000/x.ts", http.StatusBadRequest, "", ""},
+		{"segment bad path", "/ace/hls/6162633132330000000000000000000000000000", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantContentType != "" && rec.Header().Get("Content-Type") != tt.wantContentType {
+				t.Errorf("expected Content-Type %q, got %q", tt.wantContentType, rec.Header().Get("Content-Type"))
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("expected body %q, got %q", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+
+	t.Run("segments carry and check the playlist signature", func(t *testing.T) {
+		const hash = "6162633132330000000000000000000000000000"
+		signer := auth.NewLinkSigner([]auth.LinkKey{{ID: "k1", Secret: []byte("link-secret")}})
+		valid := signer.Sign("stream:"+hash, "", time.Now().Add(time.Hour)).Query().Encode()
+		handler := NewAceStreamHTTPHandler(&mockProxyService{}, provider, slog.Default())
+		handler.SetStreamLinks(application.NewStreamLinks(signer, time.Hour, true, nil))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/"+hash+".m3u8?"+valid, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if want := "#EXTM3U\n/ace/hls/" + hash + "/7.ts?" + valid + "\n"; rec.Body.String() != want {
+			t.Errorf("expected body %q, got %q", want, rec.Body.String())
+		}
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/hls/"+hash+"/7.ts?"+valid, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected signed segment status 200, got %d", rec.Code)
+		}
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/hls/"+hash+"/7.ts", nil))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("expected unsigned segment status 403, got %d", rec.Code)
+		}
+	})
+
+	t.Run("disabled when no provider is configured", func(t *testing.T) {
+		handler := NewAceStreamHTTPHandler(&mockProxyService{}, nil, slog.Default())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ace/6162633132330000000000000000000000000000.m3u8", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+}
+
+func TestAceStreamHTTPHandler_PlayerProfiles(t *testing.T) {
+	const target = "/ace/getstream?id=6162633132330000000000000000000000000000&expires=1&sig=abc"
+	request := func(handler http.Handler, userAgent string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("User-Agent", userAgent)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	newHandler := func(hls HLSProvider) *AceStreamHTTPHandler {
+		handler := NewAceStreamHTTPHandler(&mockProxyService{streamDuration: 10 * time.Millisecond, chunkInterval: time.Millisecond}, hls, slog.Default())
+		handler.SetPlayerProfiles(application.NewPlayerProfiles())
+		return handler
+	}
+
+	t.Run("serves raw TS to VLC", func(t *testing.T) {
+		rec := request(newHandler(nil), "VLC/3.0.20 LibVLC/3.0.20")
+		if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || ct != "video/mp2t" {
+			t.Errorf("expected video/mp2t, got status %d and %q", rec.Code, ct)
+		}
+	})
+
+	t.Run("sets the connection headers of smart TVs", func(t *testing.T) {
+		rec := request(newHandler(nil), "Mozilla/5.0 (SMART-TV; Linux; Tizen 6.0)")
+		if rec.Header().Get("Connection") != "keep-alive" {
+			t.Errorf("expected keep-alive, got %q", rec.Header().Get("Connection"))
+		}
+		rec = request(newHandler(nil), "Mozilla/5.0 (Web0S; Linux/SmartTV)")
+		if rec.Header().Get("Transfer-Encoding") != "identity" {
+			t.Errorf("expected chunking disabled, got %q", rec.Header().Get("Transfer-Encoding"))
+		}
+	})
+
+	t.Run("redirects Safari to HLS", func(t *testing.T) {
+		rec := request(newHandler(&mockHLSProvider{}), "AppleCoreMedia/1.0.0.21A329 (iPhone; U; CPU OS 17_0 like Mac OS X)")
+		if rec.Code != http.StatusFound {
+			t.Fatalf("expected status 302, got %d", rec.Code)
+		}
+		want := "/ace/6162633132330000000000000000000000000000.m3u8?id=6162633132330000000000000000000000000000&expires=1&sig=abc"
+		if loc := rec.Header().Get("Location"); loc != want {
+			t.Errorf("expected redirect to %q, got %q", want, loc)
+		}
+
+		// Without HLS output the raw stream is served
+		if rec := request(newHandler(nil), "AppleCoreMedia/1.0.0.21A329 (iPhone)"); rec.Code != http.StatusOK {
+			t.Errorf("expected status 200 without HLS, got %d", rec.Code)
+		}
+	})
+
+	t.Run("serves other players with the fallback", func(t *testing.T) {
+		rec := request(newHandler(nil), "Lavf/60.3.100")
+		if ct := rec.Header().Get("Content-Type"); ct != "video/mpeg" || rec.Header().Get("Transfer-Encoding") != "chunked" {
+			t.Errorf("expected the default headers, got %q", ct)
+		}
+	})
+}
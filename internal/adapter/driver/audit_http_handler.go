@@ -0,0 +1,69 @@
+package driver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// defaultAuditLimit is how many audit entries are listed when no limit is given.
+const defaultAuditLimit = 100
+
+// AuditHTTPHandler handles HTTP requests for the audit log.
+type AuditHTTPHandler struct {
+	service *application.AuditService
+}
+
+// NewAuditHTTPHandler creates a new HTTP handler for the audit log.
+func NewAuditHTTPHandler(service *application.AuditService) *AuditHTTPHandler {
+	return &AuditHTTPHandler{service: service}
+}
+
+// auditEntryResponse represents an audit entry in JSON format.
+type auditEntryResponse struct {
+	Action  string `json:"action"`
+	Subject string `json:"subject"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to,omitempty"`
+	Actor   string `json:"actor,omitempty"`
+	At      string `json:"at"`
+}
+
+// ServeHTTP handles GET /audit with an optional limit query parameter,
+// listing the most recent entries first.
+func (h *AuditHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	limit := defaultAuditLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	entries, err := h.service.ListEntries(r.Context(), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := make([]auditEntryResponse, len(entries))
+	for i, e := range entries {
+		response[i] = auditEntryResponse{
+			Action:  string(e.Action()),
+			Subject: e.Subject(),
+			From:    e.From(),
+			To:      e.To(),
+			Actor:   e.Actor(),
+			At:      formatOptionalTime(e.At()),
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,77 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/audit"
+)
+
+// mockAuditLogRepository returns its entries as the most recent ones.
+type mockAuditLogRepository struct {
+	entries []audit.Entry
+	limit   int
+}
+
+func (m *mockAuditLogRepository) Append(ctx context.Context, e audit.Entry) error {
+	m.entries = append([]audit.Entry{e}, m.entries...)
+	return nil
+}
+
+func (m *mockAuditLogRepository) FindRecent(ctx context.Context, limit int) ([]audit.Entry, error) {
+	m.limit = limit
+	return m.entries, nil
+}
+
+func TestAuditHTTPHandler(t *testing.T) {
+	at := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	entry, _ := audit.NewEntry(audit.ActionStreamMoved, "abc", "La 1", "La 1 HD", "token-1", at)
+
+	t.Run("lists the most recent entries", func(t *testing.T) {
+		repo := &mockAuditLogRepository{entries: []audit.Entry{entry}}
+		handler := NewAuditHTTPHandler(application.NewAuditService(repo))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/audit?limit=10", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp []auditEntryResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := auditEntryResponse{Action: "stream.moved", Subject: "abc", From: "La 1", To: "La 1 HD", Actor: "token-1", At: "2026-05-01T20:00:00Z"}
+		if len(resp) != 1 || resp[0] != want {
+			t.Errorf("unexpected response %+v", resp)
+		}
+		if repo.limit != 10 {
+			t.Errorf("expected limit 10, got %d", repo.limit)
+		}
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		handler := NewAuditHTTPHandler(application.NewAuditService(&mockAuditLogRepository{}))
+
+		for _, tt := range []struct {
+			method string
+			target string
+			status int
+		}{
+			{http.MethodGet, "/audit?limit=0", http.StatusBadRequest},
+			{http.MethodGet, "/audit?limit=x", http.StatusBadRequest},
+			{http.MethodPost, "/audit", http.StatusMethodNotAllowed},
+		} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+			if rec.Code != tt.status {
+				t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.target, tt.status, rec.Code)
+			}
+		}
+	})
+}
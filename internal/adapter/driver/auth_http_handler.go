@@ -0,0 +1,98 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+)
+
+// AuthHTTPHandler handles UI login and logout.
+type AuthHTTPHandler struct {
+	service *application.AuthService
+}
+
+// NewAuthHTTPHandler creates a new HTTP handler for authentication.
+func NewAuthHTTPHandler(service *application.AuthService) *AuthHTTPHandler {
+	return &AuthHTTPHandler{service: service}
+}
+
+// loginRequest represents the JSON body for logging in.
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// sessionResponse describes the caller's authentication state.
+type sessionResponse struct {
+	Enabled       bool `json:"enabled"`
+	Authenticated bool `json:"authenticated"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *AuthHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/auth")
+
+	switch {
+	// POST /api/auth/login - start a UI session
+	case r.Method == http.MethodPost && path == "/login":
+		h.handleLogin(w, r)
+	// POST /api/auth/logout - end the UI session
+	case r.Method == http.MethodPost && path == "/logout":
+		h.handleLogout(w, r)
+	// GET /api/auth/session - report authentication state; only reachable
+	// once the auth middleware has accepted the request
+	case r.Method == http.MethodGet && path == "/session":
+		writeJSON(w, http.StatusOK, sessionResponse{Enabled: h.service.Enabled(), Authenticated: true})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleLogin handles POST /api/auth/login
+func (h *AuthHTTPHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	value, expires, err := h.service.Login(req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	http.SetCookie(w, sessionCookie(r, value, expires))
+	writeJSON(w, http.StatusOK, sessionResponse{Enabled: true, Authenticated: true})
+}
+
+// handleLogout handles POST /api/auth/logout
+func (h *AuthHTTPHandler) handleLogout(w http.ResponseWriter, r *http.Request) {
+	cookie := sessionCookie(r, "", time.Unix(0, 0))
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sessionCookie builds the session cookie, marking it Secure when the request
+// arrived over TLS directly or through a TLS-terminating proxy.
+func sessionCookie(r *http.Request, value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
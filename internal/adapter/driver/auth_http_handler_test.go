@@ -0,0 +1,272 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+)
+
+// mockTokenRepository is an in-memory driven.TokenRepository.
+type mockTokenRepository struct {
+	tokens map[string]auth.Token
+}
+
+func newMockTokenRepository() *mockTokenRepository {
+	return &mockTokenRepository{tokens: make(map[string]auth.Token)}
+}
+
+func (m *mockTokenRepository) Save(ctx context.Context, token auth.Token) error {
+	m.tokens[token.ID()] = token
+	return nil
+}
+
+func (m *mockTokenRepository) FindAll(ctx context.Context) ([]auth.Token, error) {
+	tokens := []auth.Token{}
+	for _, t := range m.tokens {
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+func (m *mockTokenRepository) FindByID(ctx context.Context, id string) (auth.Token, error) {
+	t, ok := m.tokens[id]
+	if !ok {
+		return auth.Token{}, auth.ErrTokenNotFound
+	}
+	return t, nil
+}
+
+func (m *mockTokenRepository) FindBySecretHash(ctx context.Context, secretHash string) (auth.Token, error) {
+	for _, t := range m.tokens {
+		if t.SecretHash() == secretHash {
+			return t, nil
+		}
+	}
+	return auth.Token{}, auth.ErrTokenNotFound
+}
+
+func (m *mockTokenRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.tokens[id]; !ok {
+		return auth.ErrTokenNotFound
+	}
+	delete(m.tokens, id)
+	return nil
+}
+
+func newTestAuthService(username, password string) *application.AuthService {
+	return application.NewAuthService(newMockTokenRepository(), application.AuthConfig{
+		Username:   username,
+		Password:   password,
+		SessionKey: []byte("test-key"),
+		SessionTTL: time.Hour,
+	})
+}
+
+// newAuthTestServer mounts the auth and token handlers behind the middleware,
+// plus a protected /api/ping and /playlist.m3u and a public SPA root.
+func newAuthTestServer(service *application.AuthService) http.Handler {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	apiMux := http.NewServeMux()
+	apiMux.Handle("/auth/", NewAuthHTTPHandler(service))
+	apiMux.Handle("/tokens", NewTokenHTTPHandler(service))
+	apiMux.Handle("/tokens/", NewTokenHTTPHandler(service))
+	apiMux.Handle("/ping", ok)
+	apiMux.Handle("/health", ok)
+	apiMux.Handle("/openapi.json", ok)
+
+	rootMux := http.NewServeMux()
+	rootMux.Handle("/api/", http.StripPrefix("/api", apiMux))
+	rootMux.Handle("/api/v1/", http.StripPrefix("/api/v1", apiMux))
+	rootMux.Handle("/playlist.m3u", ok)
+	rootMux.Handle("/", ok)
+
+	return NewAuthMiddleware(service, rootMux, slog.Default())
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Run("passes everything through when auth is disabled", func(t *testing.T) {
+		handler := newAuthTestServer(newTestAuthService("", ""))
+
+		for _, target := range []string{"/api/ping", "/playlist.m3u"} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s: expected status 200, got %d", target, rec.Code)
+			}
+		}
+	})
+
+	t.Run("protects API and playlist but not SPA or health", func(t *testing.T) {
+		handler := newAuthTestServer(newTestAuthService("admin", "secret"))
+
+		tests := []struct {
+			target     string
+			wantStatus int
+		}{
+			{"/api/ping", http.StatusUnauthorized},
+			{"/playlist.m3u", http.StatusUnauthorized},
+			{"/api/health", http.StatusOK},
+			{"/api/v1/ping", http.StatusUnauthorized},
+			{"/api/v1/health", http.StatusOK},
+			{"/api/v1/openapi.json", http.StatusOK},
+			{"/", http.StatusOK},
+			{"/channels", http.StatusOK},
+			{"/playlist/secret.m3u", http.StatusOK},
+			{"/playlist/tag/sports.m3u", http.StatusUnauthorized},
+			{"/playlist/fav/sports.m3u", http.StatusUnauthorized},
+			{"/playlist/fav/sports.xml", http.StatusUnauthorized},
+			{"/lineup.json", http.StatusUnauthorized},
+			{"/dlna/control/content_directory", http.StatusUnauthorized},
+			{"/discover.json", http.StatusOK},
+			{"/dlna/description.xml", http.StatusOK},
+		}
+		for _, tt := range tests {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("%s: expected status %d, got %d", tt.target, tt.wantStatus, rec.Code)
+			}
+		}
+	})
+
+	t.Run("accepts a session cookie from login", func(t *testing.T) {
+		handler := newAuthTestServer(newTestAuthService("admin", "secret"))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login",
+			strings.NewReader(`{"username":"admin","password":"secret"}`)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("login: expected status 200, got %d", rec.Code)
+		}
+		cookies := rec.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != sessionCookieName || !cookies[0].HttpOnly {
+			t.Fatalf("expected an HttpOnly session cookie, got %v", cookies)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/session", nil)
+		req.AddCookie(cookies[0])
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("session: expected status 200, got %d", rec.Code)
+		}
+		var resp sessionResponse
+		_ = json.NewDecoder(rec.Body).Decode(&resp)
+		if !resp.Enabled || !resp.Authenticated {
+			t.Errorf("expected enabled and authenticated session, got %+v", resp)
+		}
+	})
+
+	t.Run("rejects wrong credentials and forged cookies", func(t *testing.T) {
+		handler := newAuthTestServer(newTestAuthService("admin", "secret"))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/login",
+			strings.NewReader(`{"username":"admin","password":"nope"}`)))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("login: expected status 401, got %d", rec.Code)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/ping", nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: "YWRtaW4.9999999999.forged"})
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("forged cookie: expected status 401, got %d", rec.Code)
+		}
+		if rec.Header().Get("WWW-Authenticate") == "" {
+			t.Error("expected WWW-Authenticate header on 401")
+		}
+	})
+
+	t.Run("accepts API tokens via header and query", func(t *testing.T) {
+		service := newTestAuthService("admin", "secret")
+		handler := newAuthTestServer(service)
+		_, secret, err := service.CreateToken(context.Background(), "kodi")
+		if err != nil {
+			t.Fatalf("CreateToken() error = %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/ping", nil)
+		req.Header.Set("Authorization", "Bearer "+secret)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("bearer header: expected status 200, got %d", rec.Code)
+		}
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/playlist.m3u?token="+secret, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("query token: expected status 200, got %d", rec.Code)
+		}
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/playlist.m3u?token=iptv_bogus", nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("bogus token: expected status 401, got %d", rec.Code)
+		}
+	})
+}
+
+func TestTokenHTTPHandler(t *testing.T) {
+	service := newTestAuthService("admin", "secret")
+	handler := NewTokenHTTPHandler(service)
+
+	// Create
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tokens", strings.NewReader(`{"name":"kodi"}`)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected status 201, got %d", rec.Code)
+	}
+	var created tokenResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID == "" || created.Name != "kodi" || created.Token == "" {
+		t.Errorf("unexpected create response %+v", created)
+	}
+
+	// Create with empty name
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/tokens", strings.NewReader(`{"name":""}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("create empty: expected status 400, got %d", rec.Code)
+	}
+
+	// List never exposes the secret
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tokens", nil))
+	var listed []tokenResponse
+	_ = json.NewDecoder(rec.Body).Decode(&listed)
+	if len(listed) != 1 || listed[0].ID != created.ID || listed[0].Token != "" {
+		t.Errorf("unexpected list response %+v", listed)
+	}
+
+	// Revoke
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/tokens/"+created.ID, nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("revoke: expected status 204, got %d", rec.Code)
+	}
+	if err := service.ValidateToken(context.Background(), created.Token); err == nil {
+		t.Error("expected revoked token to be rejected")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/tokens/"+created.ID, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("revoke again: expected status 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,126 @@
+package driver
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+)
+
+// sessionCookieName is the cookie carrying the UI login session.
+const sessionCookieName = "iptv_session"
+
+// AuthMiddleware enforces authentication on the API and the playlist.
+// A request is accepted with a valid session cookie, an
+// "Authorization: Bearer <token>" header, or a ?token= query parameter for
+// players that cannot set headers. The SPA assets and stream routes stay
+// public so the login page can load and playlist entries keep working, as do
+// per-user playlists, whose URL carries its own secret.
+type AuthMiddleware struct {
+	service *application.AuthService
+	next    http.Handler
+	logger  *slog.Logger
+}
+
+// NewAuthMiddleware wraps next with authentication. If the service has no
+// credentials configured, all requests pass through.
+func NewAuthMiddleware(service *application.AuthService, next http.Handler, logger *slog.Logger) *AuthMiddleware {
+	return &AuthMiddleware{
+		service: service,
+		next:    next,
+		logger:  logger,
+	}
+}
+
+// ServeHTTP authenticates the request before passing it on.
+//
+// A valid API token is recorded in the request context however the request
+// is let in, with a session cookie too or with authentication disabled, so
+// that the token's playlist preferences apply to /playlist.m3u?token=X
+// whichever way it is opened.
+func (m *AuthMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !requiresAuth(r.URL.Path) {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	if secret := requestToken(r); secret != "" {
+		tok, err := m.service.AuthenticateToken(r.Context(), secret)
+		if err == nil {
+			m.next.ServeHTTP(w, r.WithContext(application.WithAPIToken(r.Context(), tok.ID())))
+			return
+		}
+		if !errors.Is(err, auth.ErrInvalidToken) {
+			m.logger.ErrorContext(r.Context(), "token validation failed", "error", err, "remote_addr", r.RemoteAddr)
+			if m.service.Enabled() {
+				writeError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+		}
+	}
+
+	if !m.service.Enabled() {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+	if cookie, err := r.Cookie(sessionCookieName); err == nil && m.service.ValidateSession(cookie.Value) == nil {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set("WWW-Authenticate", `Bearer realm="iptv-manager"`)
+	writeError(w, http.StatusUnauthorized, "authentication required")
+}
+
+// requiresAuth reports whether path is protected. Login, health checks and
+// the API description stay public, in every version of the API, and so do
+// per-user playlists at /playlist/{token}.m3u, which check their own token.
+// Every other playlist, such as those by tag and of favorite lists, whose
+// IDs are guessable slugs of their names, is protected, and so are the
+// HDHomeRun lineup and the DLNA content directory, which hand out signed
+// channel links. Tuner and renderer discovery documents stay public.
+func requiresAuth(path string) bool {
+	if rest, ok := strings.CutPrefix(path, "/api/v1/"); ok {
+		path = "/api/" + rest
+	}
+	switch path {
+	case "/api/auth/login", "/api/auth/logout", "/api/health", "/api/openapi.json":
+		return false
+	case "/playlist.m3u", "/lineup.json":
+		return true
+	}
+	if strings.HasPrefix(path, "/dlna/control/") {
+		return true
+	}
+	if rest, ok := strings.CutPrefix(path, "/playlist/"); ok {
+		return strings.Contains(rest, "/")
+	}
+	return strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/recordings/")
+}
+
+// requestToken extracts an API token from the Authorization header or the
+// token query parameter.
+func requestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if scheme, token, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return r.URL.Query().Get("token")
+}
+
+// forwardedToken returns the ?token= query of r, to append to the URLs a
+// discovery document hands out, so that a tuner or renderer pointed at it
+// with a token keeps sending the token to the protected endpoints. Returns
+// an empty string if r has none.
+func forwardedToken(r *http.Request) string {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		return ""
+	}
+	return "?" + url.Values{"token": {token}}.Encode()
+}
@@ -0,0 +1,96 @@
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/backup"
+)
+
+// maxRestoreBodySize caps uploaded database backups.
+const maxRestoreBodySize = 512 << 20
+
+// BackupHTTPHandler exports and restores the database.
+type BackupHTTPHandler struct {
+	service *application.BackupService
+	logger  *slog.Logger
+}
+
+// NewBackupHTTPHandler creates a new HTTP handler for database backups.
+func NewBackupHTTPHandler(service *application.BackupService, logger *slog.Logger) *BackupHTTPHandler {
+	return &BackupHTTPHandler{service: service, logger: logger}
+}
+
+// restoreResponse reports the outcome of a restore in JSON format.
+type restoreResponse struct {
+	PreRestoreBackup string `json:"pre_restore_backup"`
+	RestartRequired  bool   `json:"restart_required"`
+}
+
+// ServeHTTP routes GET /backup and POST /restore.
+func (h *BackupHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/backup" && r.Method == http.MethodGet:
+		h.handleBackup(w, r)
+	case r.URL.Path == "/restore" && r.Method == http.MethodPost:
+		h.handleRestore(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleBackup handles GET /backup
+func (h *BackupHTTPHandler) handleBackup(w http.ResponseWriter, r *http.Request) {
+	filename := backup.FileName(time.Now(), backup.ReasonScheduled)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// Headers are already sent once the snapshot starts streaming, so a
+	// failure can only be logged
+	if err := h.service.WriteBackup(r.Context(), w); err != nil {
+		h.logger.Error("backup download failed", "remote_addr", r.RemoteAddr, "error", err)
+	}
+}
+
+// handleRestore handles POST /restore. The backup is either the raw request
+// body or the "file" field of a multipart form.
+func (h *BackupHTTPHandler) handleRestore(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRestoreBodySize)
+
+	var body io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "missing file")
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	result, err := h.service.Restore(r.Context(), body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, backup.ErrInvalidBackup):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.As(err, &maxBytesErr):
+			writeError(w, http.StatusRequestEntityTooLarge, "backup too large")
+		default:
+			h.logger.Error("restore failed", "remote_addr", r.RemoteAddr, "error", err)
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, restoreResponse{
+		PreRestoreBackup: result.PreRestoreBackup,
+		RestartRequired:  result.RestartRequired,
+	})
+}
@@ -0,0 +1,150 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/backup"
+)
+
+// mockDatabaseBackup holds the database as an in-memory byte slice and accepts
+// any snapshot starting with "db:".
+type mockDatabaseBackup struct {
+	data []byte
+}
+
+func (m *mockDatabaseBackup) WriteBackup(ctx context.Context, w io.Writer) (int64, error) {
+	n, err := w.Write(m.data)
+	return int64(n), err
+}
+
+func (m *mockDatabaseBackup) Restore(ctx context.Context, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(data, []byte("db:")) {
+		return backup.ErrInvalidBackup
+	}
+	m.data = data
+	return nil
+}
+
+// discardBackupStore accepts snapshots without keeping them.
+type discardBackupStore struct{}
+
+func (discardBackupStore) Save(ctx context.Context, name string, write func(w io.Writer) error) error {
+	return write(io.Discard)
+}
+
+func (discardBackupStore) List(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (discardBackupStore) Delete(ctx context.Context, name string) error { return nil }
+
+func TestBackupHTTPHandler(t *testing.T) {
+	newHandler := func() (*BackupHTTPHandler, *mockDatabaseBackup) {
+		db := &mockDatabaseBackup{data: []byte("db:current")}
+		service := application.NewBackupService(db, discardBackupStore{}, 0, newProbeTestLogger())
+		return NewBackupHTTPHandler(service, newProbeTestLogger()), db
+	}
+
+	t.Run("GET /backup downloads a snapshot", func(t *testing.T) {
+		handler, _ := newHandler()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/backup", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if got := rec.Body.String(); got != "db:current" {
+			t.Errorf("expected snapshot body, got %q", got)
+		}
+		if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment;") {
+			t.Errorf("expected attachment Content-Disposition, got %q", cd)
+		}
+	})
+
+	t.Run("POST /restore restores a raw body", func(t *testing.T) {
+		handler, db := newHandler()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/restore", strings.NewReader("db:restored")))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp restoreResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if !backup.IsFileName(resp.PreRestoreBackup) {
+			t.Errorf("expected pre-restore backup name, got %q", resp.PreRestoreBackup)
+		}
+		if resp.RestartRequired {
+			t.Error("expected no restart to be required")
+		}
+		if string(db.data) != "db:restored" {
+			t.Errorf("expected database to be restored, got %q", db.data)
+		}
+	})
+
+	t.Run("POST /restore restores an uploaded file", func(t *testing.T) {
+		handler, db := newHandler()
+
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, _ := mw.CreateFormFile("file", "backup.db")
+		_, _ = fw.Write([]byte("db:uploaded"))
+		_ = mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/restore", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if string(db.data) != "db:uploaded" {
+			t.Errorf("expected database to be restored, got %q", db.data)
+		}
+	})
+
+	t.Run("POST /restore returns 400 for an invalid backup", func(t *testing.T) {
+		handler, db := newHandler()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/restore", strings.NewReader("garbage")))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+		if string(db.data) != "db:current" {
+			t.Errorf("expected database to be unchanged, got %q", db.data)
+		}
+	})
+
+	t.Run("returns 405 for unsupported methods", func(t *testing.T) {
+		handler, _ := newHandler()
+
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodPost, "/backup", nil),
+			httptest.NewRequest(http.MethodGet, "/restore", nil),
+		} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusMethodNotAllowed {
+				t.Errorf("%s %s: expected status 405, got %d", req.Method, req.URL.Path, rec.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package driver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// CacheHTTPHandler handles HTTP requests inspecting and managing the cache
+// of upstream sources.
+type CacheHTTPHandler struct {
+	service *application.CacheService
+}
+
+// NewCacheHTTPHandler creates a new HTTP handler for the source cache.
+func NewCacheHTTPHandler(service *application.CacheService) *CacheHTTPHandler {
+	return &CacheHTTPHandler{service: service}
+}
+
+// cacheEntryResponse represents a cached upstream file in JSON format.
+type cacheEntryResponse struct {
+	Key        string    `json:"key"`
+	URL        string    `json:"url"`
+	Size       int64     `json:"size"`
+	FetchedAt  time.Time `json:"fetched_at"`
+	AgeSeconds int64     `json:"age_seconds"`
+	Stale      bool      `json:"stale"`
+}
+
+// cacheRefreshResponse represents the outcome of pre-warming a source.
+type cacheRefreshResponse struct {
+	Source string `json:"source"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *CacheHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/cache")
+
+	// GET /cache - list cached entries
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	// POST /cache/refresh - pre-warm the cache from every source
+	if r.Method == http.MethodPost && path == "/refresh" {
+		h.handleRefresh(w, r)
+		return
+	}
+
+	// DELETE /cache/{key} - invalidate an entry
+	if key := strings.TrimPrefix(path, "/"); r.Method == http.MethodDelete && key != "" && !strings.Contains(key, "/") {
+		h.handleInvalidate(w, r, key)
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// handleList handles GET /cache
+func (h *CacheHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.service.Entries(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := make([]cacheEntryResponse, len(entries))
+	for i, e := range entries {
+		response[i] = cacheEntryResponse{
+			Key:        e.Key,
+			URL:        e.URL,
+			Size:       e.Size,
+			FetchedAt:  e.FetchedAt,
+			AgeSeconds: int64(e.Age.Seconds()),
+			Stale:      e.Stale,
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleInvalidate handles DELETE /cache/{key}
+func (h *CacheHTTPHandler) handleInvalidate(w http.ResponseWriter, r *http.Request, key string) {
+	if err := h.service.Invalidate(r.Context(), key); err != nil {
+		if errors.Is(err, application.ErrCacheEntryNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRefresh handles POST /cache/refresh
+func (h *CacheHTTPHandler) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	results := h.service.Refresh(r.Context())
+
+	response := make([]cacheRefreshResponse, len(results))
+	failed := 0
+	for i, res := range results {
+		response[i] = cacheRefreshResponse{Source: res.Source}
+		if res.Err != nil {
+			response[i].Error = res.Err.Error()
+			failed++
+		}
+	}
+
+	// Partial failures are reported per source; only a refresh that failed
+	// everywhere is an error
+	status := http.StatusOK
+	if failed == len(results) {
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, response)
+}
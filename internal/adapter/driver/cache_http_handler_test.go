@@ -0,0 +1,102 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/epg"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// mockSourceCache is a mock implementation for testing.
+type mockSourceCache struct {
+	entries []driven.CachedSource
+}
+
+func (m *mockSourceCache) Entries(ctx context.Context) ([]driven.CachedSource, error) {
+	return m.entries, nil
+}
+
+func (m *mockSourceCache) Invalidate(ctx context.Context, key string) (bool, error) {
+	for i, e := range m.entries {
+		if e.Key == key {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestCacheHTTPHandler(t *testing.T) {
+	newHandler := func(epgErr, sourceErr error) *CacheHTTPHandler {
+		cache := &mockSourceCache{entries: []driven.CachedSource{
+			{Key: "abc", URL: "https://example.com/guide.xml", Size: 42, FetchedAt: time.Now().Add(-7 * time.Hour)},
+		}}
+		epgFetcher := &mockEPGFetcher{fetchEPGFunc: func(ctx context.Context) ([]epg.Channel, error) { return nil, epgErr }}
+		source := &mockAcestreamSource{fetchHashesFunc: func(ctx context.Context, source string) (map[string][]string, error) {
+			return nil, sourceErr
+		}}
+		return NewCacheHTTPHandler(application.NewCacheService(cache, epgFetcher, source, 6*time.Hour))
+	}
+	serve := func(h http.Handler, method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	t.Run("GET /cache lists entries with their age", func(t *testing.T) {
+		rec := serve(newHandler(nil, nil), http.MethodGet, "/cache")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp []cacheEntryResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 1 || resp[0].Key != "abc" || resp[0].Size != 42 || resp[0].AgeSeconds < 7*3600 || !resp[0].Stale {
+			t.Errorf("unexpected response %+v", resp)
+		}
+	})
+
+	t.Run("DELETE /cache/{key} invalidates an entry", func(t *testing.T) {
+		h := newHandler(nil, nil)
+		if rec := serve(h, http.MethodDelete, "/cache/abc"); rec.Code != http.StatusNoContent {
+			t.Errorf("expected status 204, got %d", rec.Code)
+		}
+		if rec := serve(h, http.MethodDelete, "/cache/abc"); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 once invalidated, got %d", rec.Code)
+		}
+	})
+
+	t.Run("POST /cache/refresh reports each source", func(t *testing.T) {
+		rec := serve(newHandler(errors.New("guide down"), nil), http.MethodPost, "/cache/refresh")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200 for a partial failure, got %d", rec.Code)
+		}
+		var resp []cacheRefreshResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 3 || resp[0].Source != "epg" || resp[0].Error != "guide down" || resp[1].Error != "" {
+			t.Errorf("unexpected response %+v", resp)
+		}
+
+		down := errors.New("down")
+		if rec := serve(newHandler(down, down), http.MethodPost, "/cache/refresh"); rec.Code != http.StatusBadGateway {
+			t.Errorf("expected status 502 when every source fails, got %d", rec.Code)
+		}
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		if rec := serve(newHandler(nil, nil), http.MethodPost, "/cache"); rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package driver
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+// CatchupHTTPHandler plays back past programmes of a channel from its
+// recordings, for players following the catchup-source of extended M3U
+// playlists.
+type CatchupHTTPHandler struct {
+	service *application.RecordingService
+	links   *application.StreamLinks
+	logger  *slog.Logger
+}
+
+// NewCatchupHTTPHandler creates a new HTTP handler for catchup playback.
+func NewCatchupHTTPHandler(service *application.RecordingService, logger *slog.Logger) *CatchupHTTPHandler {
+	return &CatchupHTTPHandler{service: service, logger: logger}
+}
+
+// SetStreamLinks makes catchup requests check the signature of their link,
+// as playlists sign the catchup sources they list.
+func (h *CatchupHTTPHandler) SetStreamLinks(links *application.StreamLinks) {
+	h.links = links
+}
+
+// ServeHTTP handles GET /ace/catchup/{channelName}?utc={start}&utcend={end},
+// with start and end as Unix times. Without utcend the recording is played
+// to its end.
+func (h *CatchupHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	channelName := strings.TrimPrefix(r.URL.Path, "/ace/catchup/")
+	if channelName == "" {
+		writeError(w, http.StatusBadRequest, "missing channel name")
+		return
+	}
+	if h.links != nil {
+		if err := h.links.VerifyCatchup(r.Context(), channelName, r.URL.Query()); err != nil {
+			h.logger.WarnContext(r.Context(), "stream link refused", "remote_addr", r.RemoteAddr, "channel", channelName, "error", err)
+			status, message := streamLinkError(err)
+			writeError(w, status, message)
+			return
+		}
+	}
+
+	from, err := parseUnixTime(r.URL.Query().Get("utc"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "utc must be a unix time")
+		return
+	}
+	var to time.Time
+	if end := r.URL.Query().Get("utcend"); end != "" {
+		if to, err = parseUnixTime(end); err != nil || !to.After(from) {
+			writeError(w, http.StatusBadRequest, "utcend must be a unix time after utc")
+			return
+		}
+	}
+
+	rc, err := h.service.OpenCatchup(r.Context(), channelName, from, to)
+	if err != nil {
+		if errors.Is(err, recording.ErrNoCatchup) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "catchup request failed", "channel", channelName, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", "video/mp2t")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rc); err != nil {
+		h.logger.DebugContext(r.Context(), "catchup playback ended", "channel", channelName, "error", err)
+	}
+}
+
+// parseUnixTime parses a Unix time in seconds.
+func parseUnixTime(s string) (time.Time, error) {
+	seconds, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
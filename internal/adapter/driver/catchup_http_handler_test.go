@@ -0,0 +1,65 @@
+package driver
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+func TestCatchupHTTPHandler(t *testing.T) {
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	done := recording.ReconstructRecording("0a1b", "News Channel", start, start.Add(time.Hour),
+		recording.StatusCompleted, 10, "", start)
+	repo := &mockRecordingRepository{recordings: map[string]recording.Recording{done.ID(): done}}
+	store := &mockRecordingStore{files: map[string][]byte{done.ID(): []byte("0123456789")}}
+	service := application.NewRecordingService(repo, store, nil, &mockStreamRepository{}, nil, 0, slog.Default())
+	handler := NewCatchupHTTPHandler(service, slog.Default())
+
+	unix := func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) }
+
+	t.Run("plays back the recording covering the requested time", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ace/catchup/News%20Channel?utc="+unix(start)+"&utcend="+unix(start.Add(time.Hour)), nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "video/mp2t" {
+			t.Errorf("expected video/mp2t, got %q", ct)
+		}
+		if w.Body.String() != "0123456789" {
+			t.Errorf("unexpected body %q", w.Body.String())
+		}
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			method string
+			target string
+			status int
+		}{
+			{"wrong method", http.MethodPost, "/ace/catchup/News%20Channel?utc=" + unix(start), http.StatusMethodNotAllowed},
+			{"missing channel", http.MethodGet, "/ace/catchup/?utc=" + unix(start), http.StatusBadRequest},
+			{"missing start", http.MethodGet, "/ace/catchup/News%20Channel", http.StatusBadRequest},
+			{"end before start", http.MethodGet, "/ace/catchup/News%20Channel?utc=" + unix(start) + "&utcend=" + unix(start.Add(-time.Minute)), http.StatusBadRequest},
+			{"time not recorded", http.MethodGet, "/ace/catchup/News%20Channel?utc=" + unix(start.Add(-time.Hour)), http.StatusNotFound},
+			{"channel not recorded", http.MethodGet, "/ace/catchup/Sports?utc=" + unix(start), http.StatusNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+				if w.Code != tt.status {
+					t.Errorf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+				}
+			})
+		}
+	})
+}
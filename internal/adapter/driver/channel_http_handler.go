@@ -4,20 +4,27 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alorle/iptv-manager/internal/application"
 	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/probe"
+	"github.com/alorle/iptv-manager/internal/stream"
 )
 
 // ChannelHTTPHandler handles HTTP requests for channel management.
 type ChannelHTTPHandler struct {
-	service *application.ChannelService
+	service      *application.ChannelService
+	probeService *application.ProbeService
 }
 
 // NewChannelHTTPHandler creates a new HTTP handler for channels.
-func NewChannelHTTPHandler(service *application.ChannelService) *ChannelHTTPHandler {
-	return &ChannelHTTPHandler{service: service}
+// If probeService is nil, responses do not include channel availability.
+func NewChannelHTTPHandler(service *application.ChannelService, probeService *application.ProbeService) *ChannelHTTPHandler {
+	return &ChannelHTTPHandler{service: service, probeService: probeService}
 }
 
 // errorResponse represents a JSON error response.
@@ -30,18 +37,81 @@ type channelRequest struct {
 	Name string `json:"name"`
 }
 
+// channelPatchRequest represents the JSON body for updating a channel's
+// streaming settings. Omitted fields are left unchanged, as are streams
+// missing from StreamQualities; an empty label there removes a stream's label.
+type channelPatchRequest struct {
+	TranscodeAudio    *string           `json:"transcode_audio"`
+	TranscodeVideo    *string           `json:"transcode_video"`
+	Number            *int              `json:"number"`
+	TVGShift          *float64          `json:"tvg_shift"`
+	Aliases           *[]string         `json:"aliases"`
+	StreamQualities   map[string]string `json:"stream_qualities"`
+	QualityPreference *[]string         `json:"quality_preference"`
+	Variants          *string           `json:"variants"`
+}
+
+// channelTagsRequest represents the JSON body for replacing a channel's tags.
+type channelTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// channelBulkPatchRequest represents the JSON body for applying the same
+// settings to several channels. Omitted fields are left unchanged.
+type channelBulkPatchRequest struct {
+	Names          []string `json:"names"`
+	TranscodeAudio *string  `json:"transcode_audio"`
+	TranscodeVideo *string  `json:"transcode_video"`
+	Group          *string  `json:"group"`
+}
+
+// channelOrderRequest represents the JSON body for renumbering channels.
+type channelOrderRequest struct {
+	Names []string `json:"names"`
+}
+
+// channelMergeRequest represents the JSON body for merging two channels.
+type channelMergeRequest struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// duplicateChannelsResponse represents a suggested pair of duplicate channels.
+type duplicateChannelsResponse struct {
+	Channels [2]string `json:"channels"`
+	Score    float64   `json:"score"`
+}
+
+// defaultDuplicateMinScore is the similarity a pair of channel names needs
+// to be suggested as duplicates when no min_score is given.
+const defaultDuplicateMinScore = 0.8
+
 // epgMappingResponse represents an EPG mapping in JSON format.
 type epgMappingResponse struct {
-	EPGID      string `json:"epg_id"`
-	Source     string `json:"source"`
-	LastSynced string `json:"last_synced"`
+	EPGID      string  `json:"epg_id"`
+	Source     string  `json:"source"`
+	LastSynced string  `json:"last_synced"`
+	Confidence float64 `json:"confidence"`
 }
 
 // channelResponse represents a channel in JSON format.
 type channelResponse struct {
-	Name       string              `json:"name"`
-	Status     string              `json:"status"`
-	EPGMapping *epgMappingResponse `json:"epg_mapping,omitempty"`
+	Name              string              `json:"name"`
+	Status            string              `json:"status"`
+	Available         *bool               `json:"available,omitempty"`
+	Availability      string              `json:"availability,omitempty"`
+	LastChecked       string              `json:"last_checked,omitempty"`
+	EPGMapping        *epgMappingResponse `json:"epg_mapping,omitempty"`
+	TranscodeAudio    string              `json:"transcode_audio,omitempty"`
+	TranscodeVideo    string              `json:"transcode_video,omitempty"`
+	Group             string              `json:"group,omitempty"`
+	Number            int                 `json:"number,omitempty"`
+	TVGShift          float64             `json:"tvg_shift,omitempty"`
+	Aliases           []string            `json:"aliases,omitempty"`
+	StreamQualities   map[string]string   `json:"stream_qualities,omitempty"`
+	QualityPreference []string            `json:"quality_preference,omitempty"`
+	Variants          string              `json:"variants,omitempty"`
+	Tags              []string            `json:"tags,omitempty"`
 }
 
 // writeJSON writes a JSON response with the given status code.
@@ -68,12 +138,48 @@ func (h *ChannelHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// POST /channels/merge - merge one channel into another
+	if r.Method == http.MethodPost && path == "/merge" {
+		h.handleMerge(w, r)
+		return
+	}
+
+	// POST /channels/{name}/qualities - label a channel's streams by analyzing them
+	if name, ok := strings.CutSuffix(path, "/qualities"); ok && r.Method == http.MethodPost && name != "" {
+		h.handleDetectQualities(w, r, strings.TrimPrefix(name, "/"))
+		return
+	}
+
+	// PUT /channels/{name}/tags - replace a channel's tags
+	if name, ok := strings.CutSuffix(path, "/tags"); ok && r.Method == http.MethodPut && name != "" {
+		h.handleUpdateTags(w, r, strings.TrimPrefix(name, "/"))
+		return
+	}
+
 	// GET /channels - list all channels
 	if r.Method == http.MethodGet && path == "" {
 		h.handleList(w, r)
 		return
 	}
 
+	// GET /channels/duplicates - suggest likely duplicate channels
+	if r.Method == http.MethodGet && path == "/duplicates" {
+		h.handleDuplicates(w, r)
+		return
+	}
+
+	// PUT /channels/order - renumber channels
+	if r.Method == http.MethodPut && path == "/order" {
+		h.handleReorder(w, r)
+		return
+	}
+
+	// PATCH /channels/bulk - update several channels at once
+	if r.Method == http.MethodPatch && path == "/bulk" {
+		h.handleBulkPatch(w, r)
+		return
+	}
+
 	// GET /channels/{name} - get a specific channel
 	if r.Method == http.MethodGet && path != "" {
 		name := strings.TrimPrefix(path, "/")
@@ -81,6 +187,13 @@ func (h *ChannelHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// PATCH /channels/{name} - update a channel's streaming settings
+	if r.Method == http.MethodPatch && path != "" {
+		name := strings.TrimPrefix(path, "/")
+		h.handlePatch(w, r, name)
+		return
+	}
+
 	// DELETE /channels/{name} - delete a channel
 	if r.Method == http.MethodDelete && path != "" {
 		name := strings.TrimPrefix(path, "/")
@@ -94,8 +207,25 @@ func (h *ChannelHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // toChannelResponse converts a channel domain object to an API response.
 func toChannelResponse(ch channel.Channel) channelResponse {
 	resp := channelResponse{
-		Name:   ch.Name(),
-		Status: string(ch.Status()),
+		Name:           ch.Name(),
+		Status:         string(ch.Status()),
+		TranscodeAudio: string(ch.AudioTranscode()),
+		TranscodeVideo: string(ch.VideoTranscode()),
+		Group:          ch.Group(),
+		Number:         ch.Number(),
+		TVGShift:       ch.TVGShift().Hours(),
+		Aliases:        ch.Aliases(),
+		Variants:       string(ch.Variants()),
+		Tags:           ch.Tags(),
+	}
+	for infoHash, q := range ch.StreamQualities() {
+		if resp.StreamQualities == nil {
+			resp.StreamQualities = make(map[string]string)
+		}
+		resp.StreamQualities[infoHash] = string(q)
+	}
+	for _, q := range ch.QualityPreference() {
+		resp.QualityPreference = append(resp.QualityPreference, string(q))
 	}
 
 	if mapping := ch.EPGMapping(); mapping != nil {
@@ -103,12 +233,39 @@ func toChannelResponse(ch channel.Channel) channelResponse {
 			EPGID:      mapping.EPGID(),
 			Source:     string(mapping.Source()),
 			LastSynced: mapping.LastSynced().Format("2006-01-02T15:04:05Z07:00"),
+			Confidence: mapping.Confidence(),
 		}
 	}
 
 	return resp
 }
 
+// withAvailability annotates a channel response with the availability
+// aggregated from its streams' probe results and when they were last
+// checked. "available" is left unset when there is no probe data, so
+// clients can tell unknown from dead.
+func (h *ChannelHTTPHandler) withAvailability(r *http.Request, resp channelResponse) channelResponse {
+	if h.probeService == nil {
+		return resp
+	}
+
+	status, err := h.probeService.GetChannelStatus(r.Context(), resp.Name)
+	if err != nil {
+		status = application.ChannelStatus{Availability: probe.AvailabilityUnknown}
+	}
+	if !status.LastChecked.IsZero() {
+		resp.LastChecked = status.LastChecked.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	availability := status.Availability
+	resp.Availability = string(availability)
+	if availability != probe.AvailabilityUnknown {
+		available := availability == probe.AvailabilityAvailable
+		resp.Available = &available
+	}
+	return resp
+}
+
 // handleCreate handles POST /channels
 func (h *ChannelHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 	var req channelRequest
@@ -134,10 +291,178 @@ func (h *ChannelHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request
 	writeJSON(w, http.StatusCreated, toChannelResponse(ch))
 }
 
-// handleList handles GET /channels
+// handleList handles GET /channels with optional sort, page, per_page and
+// fields query parameters
 func (h *ChannelHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
-	channels, err := h.service.ListChannels(r.Context())
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	fields, err := parseFields[channelResponse](r)
 	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	page, err := h.service.ListChannelsPage(r.Context(), opts)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	// Availability needs a probe lookup per channel, skip it if not wanted
+	withAvailability := hasField(fields, "available", "availability", "last_checked")
+	response := make([]channelResponse, len(page.Items))
+	for i, ch := range page.Items {
+		response[i] = toChannelResponse(ch)
+		if withAvailability {
+			response[i] = h.withAvailability(r, response[i])
+		}
+	}
+
+	writeList(w, r, page, response, fields)
+}
+
+// handleGet handles GET /channels/{name}
+func (h *ChannelHTTPHandler) handleGet(w http.ResponseWriter, r *http.Request, name string) {
+	ch, err := h.service.GetChannel(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.withAvailability(r, toChannelResponse(ch)))
+}
+
+// handlePatch handles PATCH /channels/{name}
+func (h *ChannelHTTPHandler) handlePatch(w http.ResponseWriter, r *http.Request, name string) {
+	var req channelPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ch, err := h.service.GetChannel(r.Context(), name)
+	if err == nil && req.TranscodeAudio != nil {
+		ch, err = h.service.UpdateAudioTranscode(r.Context(), name, *req.TranscodeAudio)
+	}
+	if err == nil && req.TranscodeVideo != nil {
+		ch, err = h.service.UpdateVideoTranscode(r.Context(), name, *req.TranscodeVideo)
+	}
+	if err == nil && req.Number != nil {
+		ch, err = h.service.UpdateNumber(r.Context(), name, *req.Number)
+	}
+	if err == nil && req.TVGShift != nil {
+		ch, err = h.service.UpdateTVGShift(r.Context(), name, time.Duration(*req.TVGShift*float64(time.Hour)))
+	}
+	if err == nil && req.Aliases != nil {
+		ch, err = h.service.UpdateAliases(r.Context(), name, *req.Aliases)
+	}
+	if err == nil && req.StreamQualities != nil {
+		ch, err = h.service.UpdateStreamQualities(r.Context(), name, req.StreamQualities)
+	}
+	if err == nil && req.QualityPreference != nil {
+		ch, err = h.service.UpdateQualityPreference(r.Context(), name, *req.QualityPreference)
+	}
+	if err == nil && req.Variants != nil {
+		ch, err = h.service.UpdateVariants(r.Context(), name, *req.Variants)
+	}
+	if err != nil {
+		if errors.Is(err, channel.ErrInvalidAudioTranscode) || errors.Is(err, channel.ErrInvalidVideoTranscode) || errors.Is(err, channel.ErrInvalidNumber) || errors.Is(err, channel.ErrInvalidTVGShift) ||
+			errors.Is(err, channel.ErrInvalidAlias) ||
+			errors.Is(err, channel.ErrInvalidQuality) || errors.Is(err, channel.ErrInvalidVariants) ||
+			errors.Is(err, stream.ErrInvalidInfoHash) || errors.Is(err, stream.ErrStreamNotFound) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, channel.ErrAliasInUse) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.withAvailability(r, toChannelResponse(ch)))
+}
+
+// handleUpdateTags handles PUT /channels/{name}/tags
+func (h *ChannelHTTPHandler) handleUpdateTags(w http.ResponseWriter, r *http.Request, name string) {
+	var req channelTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ch, err := h.service.UpdateTags(r.Context(), name, req.Tags)
+	if err != nil {
+		if errors.Is(err, channel.ErrInvalidTag) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.withAvailability(r, toChannelResponse(ch)))
+}
+
+// handleDetectQualities handles POST /channels/{name}/qualities
+func (h *ChannelHTTPHandler) handleDetectQualities(w http.ResponseWriter, r *http.Request, name string) {
+	ch, err := h.service.DetectQualities(r.Context(), name)
+	if err != nil {
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, application.ErrMediaInfoUnavailable) {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.withAvailability(r, toChannelResponse(ch)))
+}
+
+// handleBulkPatch handles PATCH /channels/bulk
+func (h *ChannelHTTPHandler) handleBulkPatch(w http.ResponseWriter, r *http.Request) {
+	var req channelBulkPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Names) == 0 {
+		writeError(w, http.StatusBadRequest, "names are required")
+		return
+	}
+
+	channels, err := h.service.BulkUpdateChannels(r.Context(), req.Names, application.ChannelUpdate{
+		TranscodeAudio: req.TranscodeAudio,
+		TranscodeVideo: req.TranscodeVideo,
+		Group:          req.Group,
+	})
+	if err != nil {
+		// An unknown name or group is a problem with the request, not a missing resource
+		if errors.Is(err, channel.ErrInvalidAudioTranscode) || errors.Is(err, channel.ErrInvalidVideoTranscode) ||
+			errors.Is(err, channel.ErrChannelNotFound) || errors.Is(err, group.ErrGroupNotFound) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
@@ -150,10 +475,51 @@ func (h *ChannelHTTPHandler) handleList(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusOK, response)
 }
 
-// handleGet handles GET /channels/{name}
-func (h *ChannelHTTPHandler) handleGet(w http.ResponseWriter, r *http.Request, name string) {
-	ch, err := h.service.GetChannel(r.Context(), name)
+// handleReorder handles PUT /channels/order
+func (h *ChannelHTTPHandler) handleReorder(w http.ResponseWriter, r *http.Request) {
+	var req channelOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	channels, err := h.service.ReorderChannels(r.Context(), req.Names)
+	if err != nil {
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			// An unknown name is a problem with the request, not a missing resource
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := make([]channelResponse, len(channels))
+	for i, ch := range channels {
+		response[i] = toChannelResponse(ch)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleMerge handles POST /channels/merge
+func (h *ChannelHTTPHandler) handleMerge(w http.ResponseWriter, r *http.Request) {
+	var req channelMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Source == "" || req.Target == "" {
+		writeError(w, http.StatusBadRequest, "source and target are required")
+		return
+	}
+
+	ch, err := h.service.MergeChannels(r.Context(), req.Source, req.Target)
 	if err != nil {
+		if errors.Is(err, application.ErrMergeSameChannel) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		if errors.Is(err, channel.ErrChannelNotFound) {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
@@ -162,7 +528,33 @@ func (h *ChannelHTTPHandler) handleGet(w http.ResponseWriter, r *http.Request, n
 		return
 	}
 
-	writeJSON(w, http.StatusOK, toChannelResponse(ch))
+	writeJSON(w, http.StatusOK, h.withAvailability(r, toChannelResponse(ch)))
+}
+
+// handleDuplicates handles GET /channels/duplicates
+func (h *ChannelHTTPHandler) handleDuplicates(w http.ResponseWriter, r *http.Request) {
+	minScore := defaultDuplicateMinScore
+	if v := r.URL.Query().Get("min_score"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			writeError(w, http.StatusBadRequest, "min_score must be a number in (0, 1]")
+			return
+		}
+		minScore = parsed
+	}
+
+	duplicates, err := h.service.FindDuplicates(r.Context(), minScore)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := make([]duplicateChannelsResponse, len(duplicates))
+	for i, d := range duplicates {
+		response[i] = duplicateChannelsResponse{Channels: d.Names, Score: d.Score}
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
 
 // handleDelete handles DELETE /channels/{name}
@@ -7,10 +7,15 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alorle/iptv-manager/internal/application"
 	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/probe"
 	"github.com/alorle/iptv-manager/internal/stream"
 )
 
@@ -52,6 +57,21 @@ func (m *mockChannelRepository) FindAll(ctx context.Context) ([]channel.Channel,
 	return []channel.Channel{}, nil
 }
 
+// FindByTag filters the channels returned by FindAll.
+func (m *mockChannelRepository) FindByTag(ctx context.Context, tag string) ([]channel.Channel, error) {
+	all, err := m.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tagged := []channel.Channel{}
+	for _, ch := range all {
+		if ch.HasTag(tag) {
+			tagged = append(tagged, ch)
+		}
+	}
+	return tagged, nil
+}
+
 func (m *mockChannelRepository) Delete(ctx context.Context, name string) error {
 	if m.deleteFunc != nil {
 		return m.deleteFunc(ctx, name)
@@ -69,6 +89,7 @@ func (m *mockChannelRepository) Ping(ctx context.Context) error {
 // mockStreamRepository is a mock implementation for testing.
 type mockStreamRepository struct {
 	saveFunc                func(ctx context.Context, s stream.Stream) error
+	updateFunc              func(ctx context.Context, s stream.Stream) error
 	findByInfoHashFunc      func(ctx context.Context, infoHash string) (stream.Stream, error)
 	findAllFunc             func(ctx context.Context) ([]stream.Stream, error)
 	findByChannelNameFunc   func(ctx context.Context, channelName string) ([]stream.Stream, error)
@@ -83,6 +104,13 @@ func (m *mockStreamRepository) Save(ctx context.Context, s stream.Stream) error
 	return nil
 }
 
+func (m *mockStreamRepository) Update(ctx context.Context, s stream.Stream) error {
+	if m.updateFunc != nil {
+		return m.updateFunc(ctx, s)
+	}
+	return nil
+}
+
 func (m *mockStreamRepository) FindByInfoHash(ctx context.Context, infoHash string) (stream.Stream, error) {
 	if m.findByInfoHashFunc != nil {
 		return m.findByInfoHashFunc(ctx, infoHash)
@@ -127,7 +155,7 @@ func TestChannelHTTPHandler_Create(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		reqBody := bytes.NewBufferString(`{"name":"TestChannel"}`)
 		req := httptest.NewRequest(http.MethodPost, "/channels", reqBody)
@@ -152,7 +180,7 @@ func TestChannelHTTPHandler_Create(t *testing.T) {
 		channelRepo := &mockChannelRepository{}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		reqBody := bytes.NewBufferString(`invalid json`)
 		req := httptest.NewRequest(http.MethodPost, "/channels", reqBody)
@@ -177,7 +205,7 @@ func TestChannelHTTPHandler_Create(t *testing.T) {
 		channelRepo := &mockChannelRepository{}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		reqBody := bytes.NewBufferString(`{"name":""}`)
 		req := httptest.NewRequest(http.MethodPost, "/channels", reqBody)
@@ -206,7 +234,7 @@ func TestChannelHTTPHandler_Create(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		reqBody := bytes.NewBufferString(`{"name":"TestChannel"}`)
 		req := httptest.NewRequest(http.MethodPost, "/channels", reqBody)
@@ -239,7 +267,7 @@ func TestChannelHTTPHandler_List(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/channels", nil)
 		rec := httptest.NewRecorder()
@@ -270,7 +298,7 @@ func TestChannelHTTPHandler_List(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/channels", nil)
 		rec := httptest.NewRecorder()
@@ -291,6 +319,151 @@ func TestChannelHTTPHandler_List(t *testing.T) {
 	})
 }
 
+func TestChannelHTTPHandler_ListPage(t *testing.T) {
+	var channels []channel.Channel
+	for _, name := range []string{"C", "A", "E", "B", "D"} {
+		ch, _ := channel.NewChannel(name)
+		channels = append(channels, ch)
+	}
+	channelRepo := &mockChannelRepository{
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			return slices.Clone(channels), nil
+		},
+	}
+	handler := NewChannelHTTPHandler(application.NewChannelService(channelRepo, &mockStreamRepository{}), nil)
+
+	t.Run("returns the requested page sorted with selected fields", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/channels?sort=-name&page=2&per_page=2&fields=name", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("X-Total-Count"); got != "5" {
+			t.Errorf("expected X-Total-Count 5, got %q", got)
+		}
+		link := rec.Header().Get("Link")
+		if !strings.Contains(link, `page=1&per_page=2&sort=-name>; rel="prev"`) || !strings.Contains(link, `page=3&per_page=2&sort=-name>; rel="next"`) {
+			t.Errorf("unexpected Link header %q", link)
+		}
+
+		var resp []map[string]any
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := []map[string]any{{"name": "C"}, {"name": "B"}}
+		if !reflect.DeepEqual(resp, want) {
+			t.Errorf("expected %v, got %v", want, resp)
+		}
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		for _, query := range []string{"sort=size", "page=0", "per_page=x", "fields=name,size"} {
+			req := httptest.NewRequest(http.MethodGet, "/channels?"+query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", query, rec.Code)
+			}
+		}
+	})
+}
+
+func TestChannelHTTPHandler_Availability(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	ch1, _ := channel.NewChannel("Mixed")
+	ch2, _ := channel.NewChannel("Dead")
+	ch3, _ := channel.NewChannel("Unprobed")
+
+	channelRepo := &mockChannelRepository{
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			return []channel.Channel{ch1, ch2, ch3}, nil
+		},
+	}
+	streamRepo := &mockStreamRepository{
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			switch channelName {
+			case "Mixed":
+				s1, _ := stream.NewStream("616c697665000000000000000000000000000000", channelName, "")
+				s2, _ := stream.NewStream("6465616431000000000000000000000000000000", channelName, "")
+				return []stream.Stream{s1, s2}, nil
+			case "Dead":
+				s1, _ := stream.NewStream("6465616432000000000000000000000000000000", channelName, "")
+				return []stream.Stream{s1}, nil
+			default:
+				s1, _ := stream.NewStream("6672657368000000000000000000000000000000", channelName, "")
+				return []stream.Stream{s1}, nil
+			}
+		},
+	}
+	probeRepo := &mockProbeRepository{
+		findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+			switch infoHash {
+			case "616c697665000000000000000000000000000000":
+				return []probe.Result{probe.ReconstructResult(infoHash, now, true, time.Second, 10, 100000, "dl", "")}, nil
+			case "6465616431000000000000000000000000000000", "6465616432000000000000000000000000000000":
+				return []probe.Result{probe.ReconstructResult(infoHash, now, false, 0, 0, 0, "", "timeout")}, nil
+			}
+			return []probe.Result{}, nil
+		},
+	}
+
+	service := application.NewChannelService(channelRepo, streamRepo)
+	handler := NewChannelHTTPHandler(service, newProbeTestService(probeRepo, streamRepo))
+
+	req := httptest.NewRequest(http.MethodGet, "/channels", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var resp []channelResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp) != 3 {
+		t.Fatalf("expected 3 channels, got %d", len(resp))
+	}
+
+	tests := []struct {
+		name             string
+		wantAvailability string
+		wantAvailable    *bool
+		wantLastChecked  string
+	}{
+		{"Mixed", "available", boolPtr(true), now.Format(time.RFC3339)},
+		{"Dead", "unavailable", boolPtr(false), now.Format(time.RFC3339)},
+		{"Unprobed", "unknown", nil, ""},
+	}
+	for i, tt := range tests {
+		got := resp[i]
+		if got.Name != tt.name {
+			t.Fatalf("channel %d: expected %q, got %q", i, tt.name, got.Name)
+		}
+		if got.Availability != tt.wantAvailability {
+			t.Errorf("%s: expected availability %q, got %q", tt.name, tt.wantAvailability, got.Availability)
+		}
+		if got.LastChecked != tt.wantLastChecked {
+			t.Errorf("%s: expected last_checked %q, got %q", tt.name, tt.wantLastChecked, got.LastChecked)
+		}
+		switch {
+		case tt.wantAvailable == nil && got.Available != nil:
+			t.Errorf("%s: expected available to be unset, got %v", tt.name, *got.Available)
+		case tt.wantAvailable != nil && (got.Available == nil || *got.Available != *tt.wantAvailable):
+			t.Errorf("%s: expected available %v, got %v", tt.name, *tt.wantAvailable, got.Available)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool { return &b }
+
 func TestChannelHTTPHandler_Get(t *testing.T) {
 	t.Run("GET /channels/{name} returns channel", func(t *testing.T) {
 		ch, _ := channel.NewChannel("TestChannel")
@@ -304,7 +477,7 @@ func TestChannelHTTPHandler_Get(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/channels/TestChannel", nil)
 		rec := httptest.NewRecorder()
@@ -332,7 +505,7 @@ func TestChannelHTTPHandler_Get(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/channels/NonExistent", nil)
 		rec := httptest.NewRecorder()
@@ -353,6 +526,447 @@ func TestChannelHTTPHandler_Get(t *testing.T) {
 	})
 }
 
+func TestChannelHTTPHandler_Patch(t *testing.T) {
+	t.Run("PATCH /channels/{name} sets audio transcode", func(t *testing.T) {
+		ch, _ := channel.NewChannel("TestChannel")
+		var updated channel.Channel
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				return ch, nil
+			},
+			updateFunc: func(ctx context.Context, ch channel.Channel) error {
+				updated = ch
+				return nil
+			},
+		}
+		service := application.NewChannelService(channelRepo, &mockStreamRepository{})
+		handler := NewChannelHTTPHandler(service, nil)
+
+		body := bytes.NewBufferString(`{"transcode_audio":"ac3"}`)
+		req := httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", body)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp channelResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.TranscodeAudio != "ac3" {
+			t.Errorf("expected transcode_audio 'ac3', got %q", resp.TranscodeAudio)
+		}
+		if updated.AudioTranscode() != channel.AudioTranscodeAC3 {
+			t.Errorf("expected stored transcode 'ac3', got %q", updated.AudioTranscode())
+		}
+	})
+
+	t.Run("PATCH /channels/{name} sets video transcode", func(t *testing.T) {
+		ch, _ := channel.NewChannel("TestChannel")
+		var updated channel.Channel
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				return ch, nil
+			},
+			updateFunc: func(ctx context.Context, ch channel.Channel) error {
+				updated = ch
+				return nil
+			},
+		}
+		service := application.NewChannelService(channelRepo, &mockStreamRepository{})
+		handler := NewChannelHTTPHandler(service, nil)
+
+		for body, wantStatus := range map[string]int{`{"transcode_video":"720p"}`: http.StatusOK, `{"transcode_video":"4k"}`: http.StatusBadRequest} {
+			req := httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", bytes.NewBufferString(body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != wantStatus {
+				t.Errorf("%s: expected status %d, got %d", body, wantStatus, rec.Code)
+			}
+		}
+		if updated.VideoTranscode() != channel.VideoTranscode720p {
+			t.Errorf("expected stored video transcode '720p', got %q", updated.VideoTranscode())
+		}
+	})
+
+	t.Run("PATCH /channels/{name} returns 400 for unknown transcode", func(t *testing.T) {
+		ch, _ := channel.NewChannel("TestChannel")
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				return ch, nil
+			},
+		}
+		service := application.NewChannelService(channelRepo, &mockStreamRepository{})
+		handler := NewChannelHTTPHandler(service, nil)
+
+		body := bytes.NewBufferString(`{"transcode_audio":"flac"}`)
+		req := httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", body)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("PATCH /channels/{name} returns 404 for non-existent channel", func(t *testing.T) {
+		service := application.NewChannelService(&mockChannelRepository{}, &mockStreamRepository{})
+		handler := NewChannelHTTPHandler(service, nil)
+
+		body := bytes.NewBufferString(`{"transcode_audio":"all"}`)
+		req := httptest.NewRequest(http.MethodPatch, "/channels/NonExistent", body)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+}
+
+func TestChannelHTTPHandler_Number(t *testing.T) {
+	ch, _ := channel.NewChannel("TestChannel")
+	other, _ := channel.NewChannel("Other")
+	channelRepo := &mockChannelRepository{
+		findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+			switch name {
+			case "TestChannel":
+				return ch, nil
+			case "Other":
+				return other, nil
+			}
+			return channel.Channel{}, channel.ErrChannelNotFound
+		},
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			return []channel.Channel{ch, other}, nil
+		},
+	}
+	handler := NewChannelHTTPHandler(application.NewChannelService(channelRepo, &mockStreamRepository{}), nil)
+
+	t.Run("PATCH /channels/{name} sets the channel number", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", bytes.NewBufferString(`{"number":7}`)))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var resp channelResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Number != 7 {
+			t.Errorf("expected number 7, got %d", resp.Number)
+		}
+	})
+
+	t.Run("PATCH /channels/{name} returns 400 for negative numbers", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", bytes.NewBufferString(`{"number":-1}`)))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("PATCH /channels/{name} sets the channel aliases", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", bytes.NewBufferString(`{"aliases":["Test","tc"]}`)))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp channelResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Aliases) != 2 || resp.Aliases[0] != "test" || resp.Aliases[1] != "tc" {
+			t.Errorf("expected aliases [test tc], got %v", resp.Aliases)
+		}
+	})
+
+	t.Run("PATCH /channels/{name} rejects invalid and taken aliases", func(t *testing.T) {
+		_ = other.SetAliases([]string{"other"})
+		defer func() { _ = other.SetAliases(nil) }()
+
+		for body, want := range map[string]int{
+			`{"aliases":["not a slug"]}`: http.StatusBadRequest,
+			`{"aliases":["other"]}`:      http.StatusConflict,
+		} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", bytes.NewBufferString(body)))
+			if rec.Code != want {
+				t.Errorf("%s: expected status %d, got %d", body, want, rec.Code)
+			}
+		}
+	})
+
+	t.Run("PUT /channels/order renumbers channels", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/channels/order", bytes.NewBufferString(`{"names":["Other","TestChannel"]}`)))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp []channelResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 2 || resp[0].Name != "Other" || resp[0].Number != 1 || resp[1].Name != "TestChannel" || resp[1].Number != 2 {
+			t.Errorf("unexpected order %+v", resp)
+		}
+	})
+
+	t.Run("PUT /channels/order returns 400 for unknown channels", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/channels/order", bytes.NewBufferString(`{"names":["Missing"]}`)))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+}
+
+func TestChannelHTTPHandler_Qualities(t *testing.T) {
+	hash := strings.Repeat("a", 40)
+	ch, _ := channel.NewChannel("TestChannel")
+	channelRepo := &mockChannelRepository{
+		findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+			if name == "TestChannel" {
+				return ch, nil
+			}
+			return channel.Channel{}, channel.ErrChannelNotFound
+		},
+		updateFunc: func(ctx context.Context, updated channel.Channel) error {
+			ch = updated
+			return nil
+		},
+	}
+	streamRepo := &mockStreamRepository{
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			s, _ := stream.NewStream(hash, channelName, "")
+			return []stream.Stream{s}, nil
+		},
+	}
+	handler := NewChannelHTTPHandler(application.NewChannelService(channelRepo, streamRepo), nil)
+
+	t.Run("PATCH /channels/{name} sets stream qualities, preference and variants", func(t *testing.T) {
+		body := `{"stream_qualities":{"` + hash + `":"fhd"},"quality_preference":["720p","1080p"],"variants":"preferred"}`
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", bytes.NewBufferString(body)))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp channelResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.StreamQualities[hash] != "1080p" {
+			t.Errorf("expected stream labelled 1080p, got %v", resp.StreamQualities)
+		}
+		if !slices.Equal(resp.QualityPreference, []string{"720p", "1080p"}) || resp.Variants != "preferred" {
+			t.Errorf("expected preference [720p 1080p] and variants preferred, got %v and %q", resp.QualityPreference, resp.Variants)
+		}
+	})
+
+	t.Run("PATCH /channels/{name} rejects invalid quality settings", func(t *testing.T) {
+		for _, body := range []string{
+			`{"stream_qualities":{"` + hash + `":"8k"}}`,
+			`{"stream_qualities":{"` + strings.Repeat("b", 40) + `":"sd"}}`,
+			`{"quality_preference":["best"]}`,
+			`{"variants":"some"}`,
+		} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", bytes.NewBufferString(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+			}
+		}
+	})
+
+	t.Run("POST /channels/{name}/qualities needs media analysis", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/channels/TestChannel/qualities", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rec.Code)
+		}
+	})
+}
+
+func TestChannelHTTPHandler_TVGShift(t *testing.T) {
+	ch, _ := channel.NewChannel("TestChannel")
+	channelRepo := &mockChannelRepository{
+		findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+			if name == "TestChannel" {
+				return ch, nil
+			}
+			return channel.Channel{}, channel.ErrChannelNotFound
+		},
+		updateFunc: func(ctx context.Context, updated channel.Channel) error {
+			ch = updated
+			return nil
+		},
+	}
+	handler := NewChannelHTTPHandler(application.NewChannelService(channelRepo, &mockStreamRepository{}), nil)
+
+	t.Run("PATCH /channels/{name} sets the tvg-shift in hours", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", bytes.NewBufferString(`{"tvg_shift":-1.5}`)))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp channelResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.TVGShift != -1.5 || ch.TVGShift() != -90*time.Minute {
+			t.Errorf("expected a tvg-shift of -1.5h, got %v (stored %v)", resp.TVGShift, ch.TVGShift())
+		}
+	})
+
+	t.Run("PATCH /channels/{name} rejects an invalid tvg-shift", func(t *testing.T) {
+		for _, body := range []string{`{"tvg_shift":0.1}`, `{"tvg_shift":25}`} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/TestChannel", bytes.NewBufferString(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+			}
+		}
+	})
+}
+
+func TestChannelHTTPHandler_Tags(t *testing.T) {
+	ch, _ := channel.NewChannel("TestChannel")
+	channelRepo := &mockChannelRepository{
+		findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+			if name == "TestChannel" {
+				return ch, nil
+			}
+			return channel.Channel{}, channel.ErrChannelNotFound
+		},
+		updateFunc: func(ctx context.Context, updated channel.Channel) error {
+			ch = updated
+			return nil
+		},
+	}
+	handler := NewChannelHTTPHandler(application.NewChannelService(channelRepo, &mockStreamRepository{}), nil)
+	put := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, path, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	rec := put("/channels/TestChannel/tags", `{"tags":["Spanish","kids","spanish"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp channelResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !slices.Equal(resp.Tags, []string{"kids", "spanish"}) {
+		t.Errorf("expected tags [kids spanish], got %v", resp.Tags)
+	}
+
+	for _, tc := range []struct {
+		path, body string
+		want       int
+	}{
+		{"/channels/TestChannel/tags", `{"tags":["no spaces"]}`, http.StatusBadRequest},
+		{"/channels/TestChannel/tags", `{`, http.StatusBadRequest},
+		{"/channels/Missing/tags", `{"tags":["kids"]}`, http.StatusNotFound},
+	} {
+		if rec := put(tc.path, tc.body); rec.Code != tc.want {
+			t.Errorf("PUT %s %s: expected status %d, got %d", tc.path, tc.body, tc.want, rec.Code)
+		}
+	}
+}
+
+func TestChannelHTTPHandler_Merge(t *testing.T) {
+	source, _ := channel.NewChannel("DAZN 1 HD")
+	target, _ := channel.NewChannel("DAZN 1")
+	channelRepo := &mockChannelRepository{
+		findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+			switch name {
+			case "DAZN 1 HD":
+				return source, nil
+			case "DAZN 1":
+				return target, nil
+			}
+			return channel.Channel{}, channel.ErrChannelNotFound
+		},
+	}
+	handler := NewChannelHTTPHandler(application.NewChannelService(channelRepo, &mockStreamRepository{}), nil)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"merges channels", `{"source":"DAZN 1 HD","target":"DAZN 1"}`, http.StatusOK},
+		{"requires source and target", `{"source":"DAZN 1 HD"}`, http.StatusBadRequest},
+		{"rejects merging into itself", `{"source":"DAZN 1","target":"DAZN 1"}`, http.StatusBadRequest},
+		{"returns 404 for unknown channel", `{"source":"Missing","target":"DAZN 1"}`, http.StatusNotFound},
+		{"rejects invalid body", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/channels/merge", bytes.NewBufferString(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestChannelHTTPHandler_Duplicates(t *testing.T) {
+	channelRepo := &mockChannelRepository{
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			a, _ := channel.NewChannel("DAZN 1")
+			b, _ := channel.NewChannel("DAZN 1 FHD")
+			c, _ := channel.NewChannel("La 1")
+			return []channel.Channel{a, b, c}, nil
+		},
+	}
+	handler := NewChannelHTTPHandler(application.NewChannelService(channelRepo, &mockStreamRepository{}), nil)
+
+	t.Run("GET /channels/duplicates suggests similar channels", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/channels/duplicates", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var resp []duplicateChannelsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 1 || resp[0].Channels != [2]string{"DAZN 1", "DAZN 1 FHD"} || resp[0].Score != 1 {
+			t.Errorf("unexpected duplicates %+v", resp)
+		}
+	})
+
+	t.Run("GET /channels/duplicates rejects invalid min_score", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/channels/duplicates?min_score=2", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+}
+
 func TestChannelHTTPHandler_Delete(t *testing.T) {
 	t.Run("DELETE /channels/{name} deletes channel successfully", func(t *testing.T) {
 		ch, _ := channel.NewChannel("TestChannel")
@@ -373,7 +987,7 @@ func TestChannelHTTPHandler_Delete(t *testing.T) {
 			},
 		}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		req := httptest.NewRequest(http.MethodDelete, "/channels/TestChannel", nil)
 		rec := httptest.NewRecorder()
@@ -393,7 +1007,7 @@ func TestChannelHTTPHandler_Delete(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		req := httptest.NewRequest(http.MethodDelete, "/channels/NonExistent", nil)
 		rec := httptest.NewRecorder()
@@ -426,7 +1040,7 @@ func TestChannelHTTPHandler_Delete(t *testing.T) {
 			},
 		}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		req := httptest.NewRequest(http.MethodDelete, "/channels/TestChannel", nil)
 		rec := httptest.NewRecorder()
@@ -452,7 +1066,7 @@ func TestChannelHTTPHandler_MethodNotAllowed(t *testing.T) {
 		channelRepo := &mockChannelRepository{}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewChannelService(channelRepo, streamRepo)
-		handler := NewChannelHTTPHandler(service)
+		handler := NewChannelHTTPHandler(service, nil)
 
 		methods := []string{http.MethodPut, http.MethodPatch, http.MethodHead, http.MethodOptions}
 		for _, method := range methods {
@@ -467,3 +1081,54 @@ func TestChannelHTTPHandler_MethodNotAllowed(t *testing.T) {
 		}
 	})
 }
+
+func TestChannelHTTPHandler_BulkPatch(t *testing.T) {
+	updated := map[string]channel.Channel{}
+	channelRepo := &mockChannelRepository{
+		findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+			if name == "Missing" {
+				return channel.Channel{}, channel.ErrChannelNotFound
+			}
+			return channel.NewChannel(name)
+		},
+		updateFunc: func(ctx context.Context, ch channel.Channel) error {
+			updated[ch.Name()] = ch
+			return nil
+		},
+	}
+	handler := NewChannelHTTPHandler(application.NewChannelService(channelRepo, &mockStreamRepository{}), nil)
+
+	t.Run("PATCH /channels/bulk updates every channel", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		body := `{"names":["One","Two"],"group":"sports","transcode_audio":"all"}`
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/bulk", bytes.NewBufferString(body)))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp []channelResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 2 || resp[0].Group != "sports" || resp[1].TranscodeAudio != "all" {
+			t.Errorf("unexpected response %+v", resp)
+		}
+		if len(updated) != 2 {
+			t.Errorf("expected 2 channels to be saved, got %d", len(updated))
+		}
+	})
+
+	t.Run("PATCH /channels/bulk rejects invalid requests", func(t *testing.T) {
+		for _, body := range []string{
+			`{"names":[],"group":"sports"}`,
+			`{"names":["One","Missing"],"group":"sports"}`,
+			`{"names":["One"],"transcode_audio":"flac"}`,
+		} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, "/channels/bulk", bytes.NewBufferString(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package driver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/circuitbreaker"
+)
+
+// CircuitBreakerHTTPHandler exposes the state of the registered circuit
+// breakers and lets operators reset them.
+type CircuitBreakerHTTPHandler struct {
+	breakers *circuitbreaker.Registry
+}
+
+// NewCircuitBreakerHTTPHandler creates a new handler for the given registry.
+func NewCircuitBreakerHTTPHandler(breakers *circuitbreaker.Registry) *CircuitBreakerHTTPHandler {
+	return &CircuitBreakerHTTPHandler{breakers: breakers}
+}
+
+// circuitBreakerResponse represents a circuit breaker's state in JSON format.
+type circuitBreakerResponse struct {
+	ID        string `json:"id"`
+	State     string `json:"state"`
+	Enabled   bool   `json:"enabled"`
+	Failures  int    `json:"failures"`
+	Threshold int    `json:"threshold"`
+	Timeout   string `json:"timeout"`
+	OpenedAt  string `json:"opened_at,omitempty"`
+	RetryAt   string `json:"retry_at,omitempty"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *CircuitBreakerHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/circuitbreakers")
+
+	// GET /api/circuitbreakers - list breakers
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w)
+		return
+	}
+
+	// POST /api/circuitbreakers/{id}/reset - close a breaker
+	if id, ok := strings.CutSuffix(strings.TrimPrefix(path, "/"), "/reset"); ok && id != "" {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.handleReset(w, id)
+		return
+	}
+
+	if path != "" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// handleList handles GET /api/circuitbreakers
+func (h *CircuitBreakerHTTPHandler) handleList(w http.ResponseWriter) {
+	ids := h.breakers.IDs()
+	response := make([]circuitBreakerResponse, 0, len(ids))
+	for _, id := range ids {
+		b, err := h.breakers.Get(id)
+		if err != nil {
+			continue
+		}
+		response = append(response, toCircuitBreakerResponse(id, b))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleReset handles POST /api/circuitbreakers/{id}/reset
+func (h *CircuitBreakerHTTPHandler) handleReset(w http.ResponseWriter, id string) {
+	b, err := h.breakers.Get(id)
+	if err != nil {
+		if errors.Is(err, circuitbreaker.ErrNotFound) {
+			writeError(w, http.StatusNotFound, circuitbreaker.ErrNotFound.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	b.Reset()
+	writeJSON(w, http.StatusOK, toCircuitBreakerResponse(id, b))
+}
+
+func toCircuitBreakerResponse(id string, b *circuitbreaker.Breaker) circuitBreakerResponse {
+	st := b.Stats()
+	return circuitBreakerResponse{
+		ID:        id,
+		State:     string(st.State),
+		Enabled:   st.Threshold > 0,
+		Failures:  st.Failures,
+		Threshold: st.Threshold,
+		Timeout:   st.Timeout.String(),
+		OpenedAt:  formatOptionalTime(st.OpenedAt),
+		RetryAt:   formatOptionalTime(st.RetryAt),
+	}
+}
@@ -0,0 +1,94 @@
+package driver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/circuitbreaker"
+)
+
+func TestCircuitBreakerHTTPHandler(t *testing.T) {
+	newHandler := func() (*CircuitBreakerHTTPHandler, *circuitbreaker.Breaker) {
+		registry := circuitbreaker.NewRegistry()
+		engine := circuitbreaker.New(1, time.Minute)
+		registry.Register("engine", engine)
+		registry.Register("probe", circuitbreaker.New(0, time.Minute))
+		return NewCircuitBreakerHTTPHandler(registry), engine
+	}
+
+	t.Run("GET /circuitbreakers lists breakers with their state", func(t *testing.T) {
+		handler, engine := newHandler()
+		engine.RecordFailure()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/circuitbreakers", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var resp []circuitBreakerResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 2 {
+			t.Fatalf("expected 2 breakers, got %d", len(resp))
+		}
+		got := resp[0]
+		if got.ID != "engine" || got.State != "open" || got.Failures != 1 || !got.Enabled || got.Timeout != "1m0s" {
+			t.Errorf("unexpected engine breaker %+v", got)
+		}
+		if got.OpenedAt == "" || got.RetryAt == "" {
+			t.Errorf("expected opened and retry times for an open breaker, got %+v", got)
+		}
+		if resp[1].ID != "probe" || resp[1].Enabled {
+			t.Errorf("unexpected disabled breaker %+v", resp[1])
+		}
+	})
+
+	t.Run("POST /circuitbreakers/{id}/reset closes the breaker", func(t *testing.T) {
+		handler, engine := newHandler()
+		engine.RecordFailure()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/circuitbreakers/engine/reset", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var resp circuitBreakerResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.State != "closed" || resp.Failures != 0 || resp.RetryAt != "" {
+			t.Errorf("unexpected breaker after reset %+v", resp)
+		}
+		if engine.State() != circuitbreaker.StateClosed {
+			t.Errorf("expected breaker closed, got %q", engine.State())
+		}
+	})
+
+	t.Run("POST /circuitbreakers/{id}/reset returns 404 for unknown breakers", func(t *testing.T) {
+		handler, _ := newHandler()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/circuitbreakers/missing/reset", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("GET /circuitbreakers/{id}/reset returns 405", func(t *testing.T) {
+		handler, _ := newHandler()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/circuitbreakers/engine/reset", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
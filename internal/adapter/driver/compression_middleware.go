@@ -0,0 +1,214 @@
+package driver
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompressionConfig selects the responses that are compressed.
+type CompressionConfig struct {
+	// Paths lists path prefixes whose responses may be compressed, e.g.
+	// /playlist.m3u or /api/.
+	Paths []string
+	// ExcludePaths lists path prefixes that are never compressed, even when
+	// they also match Paths, e.g. streaming endpoints.
+	ExcludePaths []string
+}
+
+// CompressionMiddleware gzips or deflates responses when the client accepts
+// it. Only textual content types (playlists, XML, JSON) are compressed;
+// binary media, Server-Sent Events and WebSocket upgrades pass through
+// untouched, and so do responses that already set a Content-Encoding.
+type CompressionMiddleware struct {
+	next   http.Handler
+	config CompressionConfig
+}
+
+// NewCompressionMiddleware wraps next with response compression.
+func NewCompressionMiddleware(next http.Handler, config CompressionConfig) *CompressionMiddleware {
+	return &CompressionMiddleware{
+		next:   next,
+		config: config,
+	}
+}
+
+// ServeHTTP compresses the response of matching requests.
+func (m *CompressionMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !m.applies(r) {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+	encoding := negotiateEncoding(r.Header)
+	if encoding == "" || r.Method == http.MethodHead {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	cw := &compressWriter{ResponseWriter: w, encoding: encoding}
+	defer cw.close()
+	m.next.ServeHTTP(cw, r)
+}
+
+func (m *CompressionMiddleware) applies(r *http.Request) bool {
+	if isWebSocketUpgrade(r) {
+		return false
+	}
+	for _, prefix := range m.config.ExcludePaths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	for _, prefix := range m.config.Paths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding picks gzip or deflate from the Accept-Encoding header,
+// preferring gzip. Returns "" if neither is acceptable.
+func negotiateEncoding(h http.Header) string {
+	accepted := make(map[string]bool)
+	wildcard := false
+	for _, value := range h.Values("Accept-Encoding") {
+		for _, part := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(part, ";")
+			name = strings.ToLower(strings.TrimSpace(name))
+			ok := true
+			if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+				if v, err := strconv.ParseFloat(q, 64); err == nil && v <= 0 {
+					ok = false
+				}
+			}
+			if name == "*" {
+				wildcard = ok
+				continue
+			}
+			accepted[name] = ok
+		}
+	}
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		if ok, listed := accepted[encoding]; ok || (!listed && wildcard) {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// compressibleContentType reports whether a response of the given type is
+// worth compressing.
+func compressibleContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"),
+		mediaType == "application/json",
+		mediaType == "application/xml",
+		mediaType == "application/javascript",
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"),
+		strings.HasSuffix(mediaType, "mpegurl"):
+		return true
+	}
+	return false
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() any { return gzip.NewWriter(io.Discard) },
+}
+
+// compressWriter compresses the body once the handler has settled on a
+// compressible content type. It unwraps to the underlying writer so
+// http.ResponseController keeps working.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+
+	decided bool
+	enc     io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if !w.decided {
+		w.decide(status)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// decide sets up compression if the response about to be sent qualifies.
+func (w *compressWriter) decide(status int) {
+	w.decided = true
+
+	h := w.Header()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		h.Get("Content-Encoding") != "" || !compressibleContentType(h.Get("Content-Type")) {
+		return
+	}
+
+	h.Set("Content-Encoding", w.encoding)
+	h.Del("Content-Length")
+	switch w.encoding {
+	case "gzip":
+		gz := gzipWriterPool.Get().(*gzip.Writer)
+		gz.Reset(w.ResponseWriter)
+		w.enc = gz
+	case "deflate":
+		// flate.NewWriter only fails on an invalid level.
+		fw, _ := flate.NewWriter(w.ResponseWriter, flate.DefaultCompression)
+		w.enc = fw
+	}
+}
+
+// Flush writes out the data compressed so far so streamed responses are
+// not held back.
+func (w *compressWriter) Flush() {
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close finishes the compressed stream.
+func (w *compressWriter) close() {
+	if w.enc == nil {
+		return
+	}
+	_ = w.enc.Close()
+	if gz, ok := w.enc.(*gzip.Writer); ok {
+		gzipWriterPool.Put(gz)
+	}
+	w.enc = nil
+}
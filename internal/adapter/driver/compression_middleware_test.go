@@ -0,0 +1,127 @@
+package driver
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	body := strings.Repeat("#EXTINF:-1,Channel\nhttp://localhost/ace/getstream?id=abc\n", 200)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+		case "/api/preview.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+		default:
+			w.Header().Set("Content-Type", "audio/x-mpegurl")
+		}
+		w.Header().Set("Content-Length", "12345")
+		_, _ = io.WriteString(w, body)
+	})
+	m := NewCompressionMiddleware(next, CompressionConfig{
+		Paths:        []string{"/playlist.m3u", "/api/"},
+		ExcludePaths: []string{"/ace/", "/api/events"},
+	})
+
+	serve := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("gzips matching responses", func(t *testing.T) {
+		rec := serve("/playlist.m3u", "deflate, gzip")
+		if rec.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("expected gzip encoding, got %q", rec.Header().Get("Content-Encoding"))
+		}
+		if rec.Header().Get("Content-Length") != "" {
+			t.Error("expected Content-Length to be dropped")
+		}
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("expected Vary: Accept-Encoding, got %q", rec.Header().Get("Vary"))
+		}
+		if rec.Body.Len() >= len(body) {
+			t.Errorf("expected a smaller body, got %d bytes", rec.Body.Len())
+		}
+
+		gz, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("invalid gzip body: %v", err)
+		}
+		got, _ := io.ReadAll(gz)
+		if string(got) != body {
+			t.Error("decompressed body does not match")
+		}
+	})
+
+	t.Run("deflates when gzip is refused", func(t *testing.T) {
+		rec := serve("/api/channels", "gzip;q=0, deflate")
+		if rec.Header().Get("Content-Encoding") != "deflate" {
+			t.Fatalf("expected deflate encoding, got %q", rec.Header().Get("Content-Encoding"))
+		}
+		got, _ := io.ReadAll(flate.NewReader(rec.Body))
+		if string(got) != body {
+			t.Error("decompressed body does not match")
+		}
+	})
+
+	t.Run("passes through", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			path           string
+			acceptEncoding string
+		}{
+			{"without Accept-Encoding", "/playlist.m3u", ""},
+			{"unsupported encodings", "/playlist.m3u", "br"},
+			{"excluded paths", "/api/events", "gzip"},
+			{"unmatched paths", "/ace/getstream", "gzip"},
+			{"binary content", "/api/preview.jpg", "gzip"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rec := serve(tt.path, tt.acceptEncoding)
+				if rec.Header().Get("Content-Encoding") != "" {
+					t.Errorf("expected no encoding, got %q", rec.Header().Get("Content-Encoding"))
+				}
+				if rec.Body.String() != body {
+					t.Error("expected the body to be sent as is")
+				}
+			})
+		}
+	})
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"br, deflate;q=0.5, gzip;q=0.8", "gzip"},
+		{"GZIP", "gzip"},
+		{"*", "gzip"},
+		{"*, gzip;q=0", "deflate"},
+		{"identity", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.header != "" {
+			h.Set("Accept-Encoding", tt.header)
+		}
+		if got := negotiateEncoding(h); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
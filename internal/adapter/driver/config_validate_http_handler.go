@@ -0,0 +1,60 @@
+package driver
+
+import (
+	"net/http"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// ConfigValidateHTTPHandler handles HTTP requests validating the configuration.
+type ConfigValidateHTTPHandler struct {
+	validator *application.ConfigValidator
+}
+
+// NewConfigValidateHTTPHandler creates a new HTTP handler for configuration validation.
+func NewConfigValidateHTTPHandler(validator *application.ConfigValidator) *ConfigValidateHTTPHandler {
+	return &ConfigValidateHTTPHandler{validator: validator}
+}
+
+// configCheckResponse represents the outcome of a configuration check in JSON format.
+type configCheckResponse struct {
+	Name   string `json:"name"`
+	Target string `json:"target,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// configReportResponse represents the JSON response for configuration validation.
+type configReportResponse struct {
+	Status string                `json:"status"`
+	Checks []configCheckResponse `json:"checks"`
+}
+
+// ServeHTTP handles GET /config/validate
+func (h *ConfigValidateHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	report := h.validator.Validate(r.Context())
+
+	resp := configReportResponse{Status: report.Status, Checks: make([]configCheckResponse, len(report.Checks))}
+	for i, c := range report.Checks {
+		resp.Checks[i] = configCheckResponse{
+			Name:   c.Name,
+			Target: c.Target,
+			Status: c.Status,
+			Error:  c.Error,
+			Hint:   c.Hint,
+		}
+	}
+
+	// Failing checks are reported like failing dependencies in /health
+	httpStatus := http.StatusOK
+	if report.Status != "ok" {
+		httpStatus = http.StatusServiceUnavailable
+	}
+	writeJSON(w, httpStatus, resp)
+}
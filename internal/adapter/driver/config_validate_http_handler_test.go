@@ -0,0 +1,67 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+func TestConfigValidateHTTPHandler(t *testing.T) {
+	newHandler := func(err error) *ConfigValidateHTTPHandler {
+		return NewConfigValidateHTTPHandler(application.NewConfigValidator(func() []application.ConfigCheck {
+			return []application.ConfigCheck{{
+				Name:   "epg_url",
+				Target: "http://epg",
+				Hint:   "set EPG_URL",
+				Check:  func(ctx context.Context) error { return err },
+			}}
+		}, time.Second))
+	}
+
+	t.Run("GET /config/validate reports passing checks", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config/validate", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var resp configReportResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Status != "ok" || len(resp.Checks) != 1 || resp.Checks[0].Target != "http://epg" {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("GET /config/validate returns 503 with hints when a check fails", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(errors.New("404 Not Found")).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/config/validate", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("expected status 503, got %d", rec.Code)
+		}
+		var resp configReportResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Status != "error" || resp.Checks[0].Error != "404 Not Found" || resp.Checks[0].Hint != "set EPG_URL" {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/config/validate", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
@@ -43,13 +43,14 @@ type dashboardSystemHealth struct {
 }
 
 type dashboardChannelHealth struct {
-	Name        string               `json:"name"`
-	Status      string               `json:"status"`
-	StreamCount int                  `json:"stream_count"`
-	BestScore   float64              `json:"best_score"`
-	HealthLevel string               `json:"health_level"`
-	LastProbe   *probeResultResponse `json:"last_probe,omitempty"`
-	Watching    int                  `json:"watching"`
+	Name         string               `json:"name"`
+	Status       string               `json:"status"`
+	StreamCount  int                  `json:"stream_count"`
+	BestScore    float64              `json:"best_score"`
+	HealthLevel  string               `json:"health_level"`
+	Availability string               `json:"availability"`
+	LastProbe    *probeResultResponse `json:"last_probe,omitempty"`
+	Watching     int                  `json:"watching"`
 }
 
 type dashboardSession struct {
@@ -99,13 +100,14 @@ func (h *DashboardHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		}
 
 		channelResponses = append(channelResponses, dashboardChannelHealth{
-			Name:        ch.Name(),
-			Status:      string(ch.Status()),
-			StreamCount: health.StreamCount,
-			BestScore:   health.BestScore,
-			HealthLevel: healthLevel(health.BestScore, health.LastProbe != nil),
-			LastProbe:   lastProbe,
-			Watching:    watching,
+			Name:         ch.Name(),
+			Status:       string(ch.Status()),
+			StreamCount:  health.StreamCount,
+			BestScore:    health.BestScore,
+			HealthLevel:  healthLevel(health.BestScore, health.LastProbe != nil),
+			Availability: string(health.Availability),
+			LastProbe:    lastProbe,
+			Watching:     watching,
 		})
 	}
 
@@ -0,0 +1,478 @@
+package driver
+
+import (
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// DLNA device and service types announced over SSDP and described in the
+// device description.
+const (
+	dlnaDeviceType               = "urn:schemas-upnp-org:device:MediaServer:1"
+	dlnaContentDirectoryType     = "urn:schemas-upnp-org:service:ContentDirectory:1"
+	dlnaConnectionManagerType    = "urn:schemas-upnp-org:service:ConnectionManager:1"
+	dlnaDescriptionPath          = "/dlna/description.xml"
+	dlnaContentDirectorySCPDPath = "/dlna/content_directory.xml"
+	dlnaConnectionManagerSCPD    = "/dlna/connection_manager.xml"
+	dlnaContentDirectoryControl  = "/dlna/control/content_directory"
+	dlnaConnectionManagerControl = "/dlna/control/connection_manager"
+	dlnaStreamProtocolInfo       = "http-get:*:video/mp2t:*"
+	dlnaRootID                   = "0"
+	dlnaChannelIDPrefix          = "channel/"
+)
+
+// DLNAConfig describes the announced media server.
+type DLNAConfig struct {
+	FriendlyName string
+	// UUID identifies the device; it must stay the same across restarts so
+	// renderers recognise the server.
+	UUID string
+}
+
+// DLNAHTTPHandler serves a UPnP MediaServer whose content directory lists
+// the channel lineup, so TVs and media renderers on the LAN can browse and
+// play channels. Each item plays through /ace/channel/{name}, like the
+// HDHomeRun lineup.
+type DLNAHTTPHandler struct {
+	playlist *application.PlaylistService
+	config   DLNAConfig
+}
+
+// NewDLNAHTTPHandler creates a new HTTP handler for the DLNA media server.
+func NewDLNAHTTPHandler(playlist *application.PlaylistService, config DLNAConfig) *DLNAHTTPHandler {
+	return &DLNAHTTPHandler{playlist: playlist, config: config}
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *DLNAHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	// GET /dlna/description.xml - device description
+	case r.Method == http.MethodGet && r.URL.Path == dlnaDescriptionPath:
+		h.handleDescription(w, r)
+
+	// GET /dlna/content_directory.xml - ContentDirectory service description
+	case r.Method == http.MethodGet && r.URL.Path == dlnaContentDirectorySCPDPath:
+		writeDLNAXML(w, http.StatusOK, contentDirectorySCPD)
+
+	// GET /dlna/connection_manager.xml - ConnectionManager service description
+	case r.Method == http.MethodGet && r.URL.Path == dlnaConnectionManagerSCPD:
+		writeDLNAXML(w, http.StatusOK, connectionManagerSCPD)
+
+	// POST /dlna/control/content_directory - ContentDirectory actions
+	case r.Method == http.MethodPost && r.URL.Path == dlnaContentDirectoryControl:
+		h.handleContentDirectory(w, r)
+
+	// POST /dlna/control/connection_manager - ConnectionManager actions
+	case r.Method == http.MethodPost && r.URL.Path == dlnaConnectionManagerControl:
+		h.handleConnectionManager(w, r)
+
+	// SUBSCRIBE /dlna/events/{service} - event subscription; accepted so
+	// renderers that insist on one carry on, but no events are sent as the
+	// lineup is polled through GetSystemUpdateID
+	case r.Method == "SUBSCRIBE" && strings.HasPrefix(r.URL.Path, "/dlna/events/"):
+		w.Header().Set("SID", "uuid:"+h.config.UUID+"-"+strings.TrimPrefix(r.URL.Path, "/dlna/events/"))
+		w.Header().Set("TIMEOUT", "Second-1800")
+		w.WriteHeader(http.StatusOK)
+
+	// UNSUBSCRIBE /dlna/events/{service} - end of an event subscription
+	case r.Method == "UNSUBSCRIBE" && strings.HasPrefix(r.URL.Path, "/dlna/events/"):
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// dlnaService is a service entry of the device description.
+type dlnaService struct {
+	ServiceType string `xml:"serviceType"`
+	ServiceID   string `xml:"serviceId"`
+	SCPDURL     string `xml:"SCPDURL"`
+	ControlURL  string `xml:"controlURL"`
+	EventSubURL string `xml:"eventSubURL"`
+}
+
+// dlnaDescription is the body of /dlna/description.xml.
+type dlnaDescription struct {
+	XMLName     xml.Name `xml:"urn:schemas-upnp-org:device-1-0 root"`
+	SpecVersion struct {
+		Major int `xml:"major"`
+		Minor int `xml:"minor"`
+	} `xml:"specVersion"`
+	Device struct {
+		DeviceType   string        `xml:"deviceType"`
+		FriendlyName string        `xml:"friendlyName"`
+		Manufacturer string        `xml:"manufacturer"`
+		ModelName    string        `xml:"modelName"`
+		UDN          string        `xml:"UDN"`
+		Services     []dlnaService `xml:"serviceList>service"`
+	} `xml:"device"`
+}
+
+// handleDescription handles GET /dlna/description.xml
+func (h *DLNAHTTPHandler) handleDescription(w http.ResponseWriter, r *http.Request) {
+	var d dlnaDescription
+	d.SpecVersion.Major, d.SpecVersion.Minor = 1, 0
+	d.Device.DeviceType = dlnaDeviceType
+	d.Device.FriendlyName = h.config.FriendlyName
+	d.Device.Manufacturer = "iptv-manager"
+	d.Device.ModelName = "iptv-manager"
+	d.Device.UDN = "uuid:" + h.config.UUID
+	d.Device.Services = []dlnaService{
+		{
+			ServiceType: dlnaContentDirectoryType,
+			ServiceID:   "urn:upnp-org:serviceId:ContentDirectory",
+			SCPDURL:     dlnaContentDirectorySCPDPath,
+			ControlURL:  dlnaContentDirectoryControl + forwardedToken(r),
+			EventSubURL: "/dlna/events/content_directory",
+		},
+		{
+			ServiceType: dlnaConnectionManagerType,
+			ServiceID:   "urn:upnp-org:serviceId:ConnectionManager",
+			SCPDURL:     dlnaConnectionManagerSCPD,
+			ControlURL:  dlnaConnectionManagerControl + forwardedToken(r),
+			EventSubURL: "/dlna/events/connection_manager",
+		},
+	}
+
+	out, err := xml.MarshalIndent(d, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	writeDLNAXML(w, http.StatusOK, xml.Header+string(out))
+}
+
+// soapEnvelope is a SOAP request; Action holds the requested action element.
+type soapEnvelope struct {
+	Body struct {
+		Action struct {
+			XMLName        xml.Name
+			ObjectID       string `xml:"ObjectID"`
+			BrowseFlag     string `xml:"BrowseFlag"`
+			StartingIndex  int    `xml:"StartingIndex"`
+			RequestedCount int    `xml:"RequestedCount"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// soapArg is an output argument of a SOAP response.
+type soapArg struct {
+	Name  string
+	Value string
+}
+
+// handleContentDirectory handles POST /dlna/control/content_directory
+func (h *DLNAHTTPHandler) handleContentDirectory(w http.ResponseWriter, r *http.Request) {
+	var env soapEnvelope
+	if err := xml.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&env); err != nil {
+		writeSOAPFault(w, 401, "Invalid Action")
+		return
+	}
+	action := env.Body.Action
+
+	switch action.XMLName.Local {
+	case "Browse":
+		lineup, err := h.playlist.Lineup(r.Context())
+		if err != nil {
+			writeSOAPFault(w, 501, "Action Failed")
+			return
+		}
+		channelURL := func(name string) string { return h.playlist.ChannelURL(r.Context(), publicBaseURL(r), name) }
+		result, returned, total, ok := browseLineup(lineup, channelURL, action.ObjectID, action.BrowseFlag, action.StartingIndex, action.RequestedCount)
+		if !ok {
+			writeSOAPFault(w, 701, "No such object")
+			return
+		}
+		writeSOAPResponse(w, dlnaContentDirectoryType, "Browse",
+			soapArg{"Result", result},
+			soapArg{"NumberReturned", strconv.Itoa(returned)},
+			soapArg{"TotalMatches", strconv.Itoa(total)},
+			soapArg{"UpdateID", strconv.FormatUint(uint64(lineupUpdateID(lineup)), 10)})
+
+	case "GetSystemUpdateID":
+		lineup, err := h.playlist.Lineup(r.Context())
+		if err != nil {
+			writeSOAPFault(w, 501, "Action Failed")
+			return
+		}
+		writeSOAPResponse(w, dlnaContentDirectoryType, "GetSystemUpdateID",
+			soapArg{"Id", strconv.FormatUint(uint64(lineupUpdateID(lineup)), 10)})
+
+	case "GetSearchCapabilities":
+		writeSOAPResponse(w, dlnaContentDirectoryType, "GetSearchCapabilities", soapArg{"SearchCaps", ""})
+
+	case "GetSortCapabilities":
+		writeSOAPResponse(w, dlnaContentDirectoryType, "GetSortCapabilities", soapArg{"SortCaps", ""})
+
+	default:
+		writeSOAPFault(w, 401, "Invalid Action")
+	}
+}
+
+// handleConnectionManager handles POST /dlna/control/connection_manager
+func (h *DLNAHTTPHandler) handleConnectionManager(w http.ResponseWriter, r *http.Request) {
+	var env soapEnvelope
+	if err := xml.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&env); err != nil {
+		writeSOAPFault(w, 401, "Invalid Action")
+		return
+	}
+
+	switch env.Body.Action.XMLName.Local {
+	case "GetProtocolInfo":
+		writeSOAPResponse(w, dlnaConnectionManagerType, "GetProtocolInfo",
+			soapArg{"Source", dlnaStreamProtocolInfo},
+			soapArg{"Sink", ""})
+
+	case "GetCurrentConnectionIDs":
+		writeSOAPResponse(w, dlnaConnectionManagerType, "GetCurrentConnectionIDs", soapArg{"ConnectionIDs", "0"})
+
+	case "GetCurrentConnectionInfo":
+		writeSOAPResponse(w, dlnaConnectionManagerType, "GetCurrentConnectionInfo",
+			soapArg{"RcsID", "-1"},
+			soapArg{"AVTransportID", "-1"},
+			soapArg{"ProtocolInfo", ""},
+			soapArg{"PeerConnectionManager", ""},
+			soapArg{"PeerConnectionID", "-1"},
+			soapArg{"Direction", "Output"},
+			soapArg{"Status", "OK"})
+
+	default:
+		writeSOAPFault(w, 401, "Invalid Action")
+	}
+}
+
+// didlLite is the DIDL-Lite document returned by Browse.
+type didlLite struct {
+	XMLName    xml.Name        `xml:"DIDL-Lite"`
+	Xmlns      string          `xml:"xmlns,attr"`
+	XmlnsDC    string          `xml:"xmlns:dc,attr"`
+	XmlnsUPnP  string          `xml:"xmlns:upnp,attr"`
+	Containers []didlContainer `xml:"container"`
+	Items      []didlItem      `xml:"item"`
+}
+
+type didlContainer struct {
+	ID         string `xml:"id,attr"`
+	ParentID   string `xml:"parentID,attr"`
+	Restricted string `xml:"restricted,attr"`
+	ChildCount int    `xml:"childCount,attr"`
+	Title      string `xml:"dc:title"`
+	Class      string `xml:"upnp:class"`
+}
+
+type didlItem struct {
+	ID         string `xml:"id,attr"`
+	ParentID   string `xml:"parentID,attr"`
+	Restricted string `xml:"restricted,attr"`
+	Title      string `xml:"dc:title"`
+	Class      string `xml:"upnp:class"`
+	ChannelNr  int    `xml:"upnp:channelNr"`
+	Res        struct {
+		ProtocolInfo string `xml:"protocolInfo,attr"`
+		URL          string `xml:",chardata"`
+	} `xml:"res"`
+}
+
+// browseLineup answers a Browse of objectID: the root container holds an
+// item per lineup channel. It returns the DIDL-Lite result, the number of
+// objects in it and the total matches, or false if objectID is unknown.
+func browseLineup(lineup []application.LineupEntry, channelURL func(channelName string) string, objectID, browseFlag string, start, count int) (string, int, int, bool) {
+	doc := didlLite{
+		Xmlns:     "urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/",
+		XmlnsDC:   "http://purl.org/dc/elements/1.1/",
+		XmlnsUPnP: "urn:schemas-upnp-org:metadata-1-0/upnp/",
+	}
+	item := func(entry application.LineupEntry) didlItem {
+		i := didlItem{
+			ID:         dlnaChannelIDPrefix + url.PathEscape(entry.ChannelName),
+			ParentID:   dlnaRootID,
+			Restricted: "1",
+			Title:      entry.ChannelName,
+			Class:      "object.item.videoItem.videoBroadcast",
+			ChannelNr:  entry.Number,
+		}
+		i.Res.ProtocolInfo = dlnaStreamProtocolInfo
+		i.Res.URL = channelURL(entry.ChannelName)
+		return i
+	}
+
+	total := 1
+	switch {
+	case objectID == dlnaRootID && browseFlag == "BrowseMetadata":
+		doc.Containers = []didlContainer{{
+			ID:         dlnaRootID,
+			ParentID:   "-1",
+			Restricted: "1",
+			ChildCount: len(lineup),
+			Title:      "Channels",
+			Class:      "object.container",
+		}}
+
+	case objectID == dlnaRootID:
+		total = len(lineup)
+		start = min(max(start, 0), total)
+		end := total
+		if count > 0 {
+			end = min(start+count, total)
+		}
+		for _, entry := range lineup[start:end] {
+			doc.Items = append(doc.Items, item(entry))
+		}
+
+	case strings.HasPrefix(objectID, dlnaChannelIDPrefix):
+		name, err := url.PathUnescape(strings.TrimPrefix(objectID, dlnaChannelIDPrefix))
+		if err != nil {
+			return "", 0, 0, false
+		}
+		found := false
+		for _, entry := range lineup {
+			if entry.ChannelName == name {
+				doc.Items, found = []didlItem{item(entry)}, true
+				break
+			}
+		}
+		if !found || browseFlag != "BrowseMetadata" {
+			return "", 0, 0, false
+		}
+
+	default:
+		return "", 0, 0, false
+	}
+
+	out, err := xml.Marshal(doc)
+	if err != nil {
+		return "", 0, 0, false
+	}
+	return string(out), len(doc.Containers) + len(doc.Items), total, true
+}
+
+// lineupUpdateID changes whenever the lineup does, telling renderers that
+// cached listings are stale.
+func lineupUpdateID(lineup []application.LineupEntry) uint32 {
+	h := fnv.New32a()
+	for _, entry := range lineup {
+		fmt.Fprintf(h, "%d %s\n", entry.Number, entry.ChannelName)
+	}
+	return h.Sum32()
+}
+
+// writeSOAPResponse writes the response of action with its output arguments.
+func writeSOAPResponse(w http.ResponseWriter, serviceType, action string, args ...soapArg) {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(&b, `<u:%sResponse xmlns:u="%s">`, action, serviceType)
+	for _, arg := range args {
+		fmt.Fprintf(&b, "<%s>", arg.Name)
+		_ = xml.EscapeText(&b, []byte(arg.Value))
+		fmt.Fprintf(&b, "</%s>", arg.Name)
+	}
+	fmt.Fprintf(&b, `</u:%sResponse></s:Body></s:Envelope>`, action)
+	writeDLNAXML(w, http.StatusOK, b.String())
+}
+
+// writeSOAPFault writes a UPnP error.
+func writeSOAPFault(w http.ResponseWriter, code int, description string) {
+	writeDLNAXML(w, http.StatusInternalServerError, fmt.Sprintf(xml.Header+
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`+
+		`<s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail>`+
+		`<UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>%d</errorCode><errorDescription>%s</errorDescription></UPnPError>`+
+		`</detail></s:Fault></s:Body></s:Envelope>`, code, description))
+}
+
+func writeDLNAXML(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", `text/xml; charset="utf-8"`)
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, body)
+}
+
+// contentDirectorySCPD describes the supported ContentDirectory actions.
+const contentDirectorySCPD = xml.Header + `<scpd xmlns="urn:schemas-upnp-org:service-1-0">
+  <specVersion><major>1</major><minor>0</minor></specVersion>
+  <actionList>
+    <action><name>Browse</name><argumentList>
+      <argument><name>ObjectID</name><direction>in</direction><relatedStateVariable>A_ARG_TYPE_ObjectID</relatedStateVariable></argument>
+      <argument><name>BrowseFlag</name><direction>in</direction><relatedStateVariable>A_ARG_TYPE_BrowseFlag</relatedStateVariable></argument>
+      <argument><name>Filter</name><direction>in</direction><relatedStateVariable>A_ARG_TYPE_Filter</relatedStateVariable></argument>
+      <argument><name>StartingIndex</name><direction>in</direction><relatedStateVariable>A_ARG_TYPE_Index</relatedStateVariable></argument>
+      <argument><name>RequestedCount</name><direction>in</direction><relatedStateVariable>A_ARG_TYPE_Count</relatedStateVariable></argument>
+      <argument><name>SortCriteria</name><direction>in</direction><relatedStateVariable>A_ARG_TYPE_SortCriteria</relatedStateVariable></argument>
+      <argument><name>Result</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_Result</relatedStateVariable></argument>
+      <argument><name>NumberReturned</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_Count</relatedStateVariable></argument>
+      <argument><name>TotalMatches</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_Count</relatedStateVariable></argument>
+      <argument><name>UpdateID</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_UpdateID</relatedStateVariable></argument>
+    </argumentList></action>
+    <action><name>GetSearchCapabilities</name><argumentList>
+      <argument><name>SearchCaps</name><direction>out</direction><relatedStateVariable>SearchCapabilities</relatedStateVariable></argument>
+    </argumentList></action>
+    <action><name>GetSortCapabilities</name><argumentList>
+      <argument><name>SortCaps</name><direction>out</direction><relatedStateVariable>SortCapabilities</relatedStateVariable></argument>
+    </argumentList></action>
+    <action><name>GetSystemUpdateID</name><argumentList>
+      <argument><name>Id</name><direction>out</direction><relatedStateVariable>SystemUpdateID</relatedStateVariable></argument>
+    </argumentList></action>
+  </actionList>
+  <serviceStateTable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_ObjectID</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_BrowseFlag</name><dataType>string</dataType>
+      <allowedValueList><allowedValue>BrowseMetadata</allowedValue><allowedValue>BrowseDirectChildren</allowedValue></allowedValueList></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_Filter</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_Index</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_Count</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_SortCriteria</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_Result</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_UpdateID</name><dataType>ui4</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>SearchCapabilities</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>SortCapabilities</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>SystemUpdateID</name><dataType>ui4</dataType></stateVariable>
+  </serviceStateTable>
+</scpd>
+`
+
+// connectionManagerSCPD describes the supported ConnectionManager actions.
+const connectionManagerSCPD = xml.Header + `<scpd xmlns="urn:schemas-upnp-org:service-1-0">
+  <specVersion><major>1</major><minor>0</minor></specVersion>
+  <actionList>
+    <action><name>GetProtocolInfo</name><argumentList>
+      <argument><name>Source</name><direction>out</direction><relatedStateVariable>SourceProtocolInfo</relatedStateVariable></argument>
+      <argument><name>Sink</name><direction>out</direction><relatedStateVariable>SinkProtocolInfo</relatedStateVariable></argument>
+    </argumentList></action>
+    <action><name>GetCurrentConnectionIDs</name><argumentList>
+      <argument><name>ConnectionIDs</name><direction>out</direction><relatedStateVariable>CurrentConnectionIDs</relatedStateVariable></argument>
+    </argumentList></action>
+    <action><name>GetCurrentConnectionInfo</name><argumentList>
+      <argument><name>ConnectionID</name><direction>in</direction><relatedStateVariable>A_ARG_TYPE_ConnectionID</relatedStateVariable></argument>
+      <argument><name>RcsID</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_RcsID</relatedStateVariable></argument>
+      <argument><name>AVTransportID</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_AVTransportID</relatedStateVariable></argument>
+      <argument><name>ProtocolInfo</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_ProtocolInfo</relatedStateVariable></argument>
+      <argument><name>PeerConnectionManager</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_ConnectionManager</relatedStateVariable></argument>
+      <argument><name>PeerConnectionID</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_ConnectionID</relatedStateVariable></argument>
+      <argument><name>Direction</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_Direction</relatedStateVariable></argument>
+      <argument><name>Status</name><direction>out</direction><relatedStateVariable>A_ARG_TYPE_ConnectionStatus</relatedStateVariable></argument>
+    </argumentList></action>
+  </actionList>
+  <serviceStateTable>
+    <stateVariable sendEvents="yes"><name>SourceProtocolInfo</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>SinkProtocolInfo</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="yes"><name>CurrentConnectionIDs</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_ConnectionID</name><dataType>i4</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_RcsID</name><dataType>i4</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_AVTransportID</name><dataType>i4</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_ProtocolInfo</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_ConnectionManager</name><dataType>string</dataType></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_Direction</name><dataType>string</dataType>
+      <allowedValueList><allowedValue>Input</allowedValue><allowedValue>Output</allowedValue></allowedValueList></stateVariable>
+    <stateVariable sendEvents="no"><name>A_ARG_TYPE_ConnectionStatus</name><dataType>string</dataType>
+      <allowedValueList><allowedValue>OK</allowedValue><allowedValue>Unknown</allowedValue></allowedValueList></stateVariable>
+  </serviceStateTable>
+</scpd>
+`
@@ -0,0 +1,227 @@
+package driver
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// browseResponse is the SOAP response of a Browse action.
+type browseResponse struct {
+	Body struct {
+		Response struct {
+			Result         string `xml:"Result"`
+			NumberReturned int    `xml:"NumberReturned"`
+			TotalMatches   int    `xml:"TotalMatches"`
+		} `xml:"BrowseResponse"`
+	} `xml:"Body"`
+}
+
+// browseResult is the DIDL-Lite document in a Browse response.
+type browseResult struct {
+	Containers []struct {
+		ID         string `xml:"id,attr"`
+		ChildCount int    `xml:"childCount,attr"`
+	} `xml:"container"`
+	Items []struct {
+		ID    string `xml:"id,attr"`
+		Title string `xml:"title"`
+		Res   string `xml:"res"`
+	} `xml:"item"`
+}
+
+func browseRequest(objectID, flag string, start, count int) string {
+	return `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>` +
+		`<u:Browse xmlns:u="urn:schemas-upnp-org:service:ContentDirectory:1">` +
+		`<ObjectID>` + objectID + `</ObjectID><BrowseFlag>` + flag + `</BrowseFlag><Filter>*</Filter>` +
+		`<StartingIndex>` + strconv.Itoa(start) + `</StartingIndex><RequestedCount>` + strconv.Itoa(count) + `</RequestedCount><SortCriteria></SortCriteria>` +
+		`</u:Browse></s:Body></s:Envelope>`
+}
+
+func TestDLNAHTTPHandler(t *testing.T) {
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			a, _ := stream.NewStream("6162630000000000000000000000000000000000", "La 1", "")
+			b, _ := stream.NewStream("6465660000000000000000000000000000000000", "DAZN 1", "")
+			return []stream.Stream{a, b}, nil
+		},
+	}
+	playlist := application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+	handler := NewDLNAHTTPHandler(playlist, DLNAConfig{FriendlyName: "IPTV Manager", UUID: "0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0"})
+
+	browse := func(t *testing.T, body string) (*httptest.ResponseRecorder, browseResult, browseResponse) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/dlna/control/content_directory", strings.NewReader(body))
+		req.Host = "tv.local:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var resp browseResponse
+		var result browseResult
+		if rec.Code == http.StatusOK {
+			if err := xml.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if err := xml.Unmarshal([]byte(resp.Body.Response.Result), &result); err != nil {
+				t.Fatalf("failed to decode result: %v", err)
+			}
+		}
+		return rec, result, resp
+	}
+
+	t.Run("GET /dlna/description.xml describes the media server", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dlna/description.xml", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var resp dlnaDescription
+		if err := xml.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Device.DeviceType != dlnaDeviceType || resp.Device.UDN != "uuid:0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0" || resp.Device.FriendlyName != "IPTV Manager" {
+			t.Errorf("unexpected device %+v", resp.Device)
+		}
+		if len(resp.Device.Services) != 2 || resp.Device.Services[0].ControlURL != "/dlna/control/content_directory" {
+			t.Errorf("unexpected services %+v", resp.Device.Services)
+		}
+	})
+
+	t.Run("GET /dlna/description.xml hands on the token to the control URLs", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dlna/description.xml?token=s%26cret", nil))
+
+		var resp dlnaDescription
+		if err := xml.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		for _, svc := range resp.Device.Services {
+			if !strings.HasSuffix(svc.ControlURL, "?token=s%26cret") {
+				t.Errorf("expected the token on control URL %q", svc.ControlURL)
+			}
+		}
+	})
+
+	t.Run("Browse lists the lineup under the root container", func(t *testing.T) {
+		rec, result, resp := browse(t, browseRequest("0", "BrowseDirectChildren", 0, 0))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if resp.Body.Response.NumberReturned != 2 || resp.Body.Response.TotalMatches != 2 {
+			t.Errorf("unexpected counts %+v", resp.Body.Response)
+		}
+		if len(result.Items) != 2 {
+			t.Fatalf("expected 2 items, got %d", len(result.Items))
+		}
+		item := result.Items[0]
+		if item.ID != "channel/DAZN%201" || item.Title != "DAZN 1" || item.Res != "http://tv.local:8080/ace/channel/DAZN%201" {
+			t.Errorf("unexpected item %+v", item)
+		}
+	})
+
+	t.Run("Browse pages through the lineup", func(t *testing.T) {
+		_, result, resp := browse(t, browseRequest("0", "BrowseDirectChildren", 1, 5))
+
+		if resp.Body.Response.NumberReturned != 1 || resp.Body.Response.TotalMatches != 2 {
+			t.Errorf("unexpected counts %+v", resp.Body.Response)
+		}
+		if len(result.Items) != 1 || result.Items[0].Title != "La 1" {
+			t.Errorf("unexpected items %+v", result.Items)
+		}
+	})
+
+	t.Run("Browse returns the metadata of the root and of a channel", func(t *testing.T) {
+		_, result, _ := browse(t, browseRequest("0", "BrowseMetadata", 0, 0))
+		if len(result.Containers) != 1 || result.Containers[0].ChildCount != 2 {
+			t.Errorf("unexpected root %+v", result.Containers)
+		}
+
+		_, result, _ = browse(t, browseRequest("channel/La%201", "BrowseMetadata", 0, 0))
+		if len(result.Items) != 1 || result.Items[0].Title != "La 1" {
+			t.Errorf("unexpected channel %+v", result.Items)
+		}
+	})
+
+	t.Run("Browse of an unknown object is a UPnP error", func(t *testing.T) {
+		rec, _, _ := browse(t, browseRequest("channel/Missing", "BrowseMetadata", 0, 0))
+
+		if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "<errorCode>701</errorCode>") {
+			t.Errorf("expected UPnP error 701, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("unsupported actions are a UPnP error", func(t *testing.T) {
+		body := `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><u:DestroyObject xmlns:u="urn:schemas-upnp-org:service:ContentDirectory:1"/></s:Body></s:Envelope>`
+		rec, _, _ := browse(t, body)
+
+		if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "<errorCode>401</errorCode>") {
+			t.Errorf("expected UPnP error 401, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestSSDPServer_SearchResponses(t *testing.T) {
+	server := NewSSDPServer(DLNAConfig{UUID: "0f1e2d3c"}, 8080, nil)
+	location := "http://192.168.1.10:8080/dlna/description.xml"
+	search := func(st string) []byte {
+		return []byte("M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nMAN: \"ssdp:discover\"\r\nMX: 2\r\nST: " + st + "\r\n\r\n")
+	}
+
+	tests := []struct {
+		name    string
+		packet  []byte
+		wantUSN []string
+	}{
+		{
+			name:    "media server search",
+			packet:  search("urn:schemas-upnp-org:device:MediaServer:1"),
+			wantUSN: []string{"uuid:0f1e2d3c::urn:schemas-upnp-org:device:MediaServer:1"},
+		},
+		{
+			name:    "device search",
+			packet:  search("uuid:0f1e2d3c"),
+			wantUSN: []string{"uuid:0f1e2d3c"},
+		},
+		{
+			name:   "search for everything",
+			packet: search("ssdp:all"),
+			wantUSN: []string{
+				"uuid:0f1e2d3c::upnp:rootdevice",
+				"uuid:0f1e2d3c",
+				"uuid:0f1e2d3c::urn:schemas-upnp-org:device:MediaServer:1",
+				"uuid:0f1e2d3c::urn:schemas-upnp-org:service:ContentDirectory:1",
+				"uuid:0f1e2d3c::urn:schemas-upnp-org:service:ConnectionManager:1",
+			},
+		},
+		{name: "other device type", packet: search("urn:schemas-upnp-org:device:MediaRenderer:1")},
+		{name: "announcement", packet: []byte("NOTIFY * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nNT: upnp:rootdevice\r\n\r\n")},
+		{name: "garbage", packet: []byte("not ssdp")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responses := server.searchResponses(tt.packet, location)
+			if len(responses) != len(tt.wantUSN) {
+				t.Fatalf("expected %d responses, got %d", len(tt.wantUSN), len(responses))
+			}
+			for i, response := range responses {
+				if !strings.Contains(string(response), "\r\nUSN: "+tt.wantUSN[i]+"\r\n") {
+					t.Errorf("response %d lacks USN %s:\n%s", i, tt.wantUSN[i], response)
+				}
+				if !strings.Contains(string(response), "\r\nLOCATION: "+location+"\r\n") {
+					t.Errorf("response %d lacks the location:\n%s", i, response)
+				}
+			}
+		})
+	}
+}
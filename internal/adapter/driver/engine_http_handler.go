@@ -0,0 +1,106 @@
+package driver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// EngineHTTPHandler handles HTTP requests running maintenance commands on
+// the AceStream engines.
+type EngineHTTPHandler struct {
+	service *application.EngineService
+}
+
+// NewEngineHTTPHandler creates a new HTTP handler for engine commands.
+func NewEngineHTTPHandler(service *application.EngineService) *EngineHTTPHandler {
+	return &EngineHTTPHandler{service: service}
+}
+
+// engineCommandInfoResponse describes an engine command in JSON format.
+type engineCommandInfoResponse struct {
+	Command string `json:"command"`
+	Mutates bool   `json:"mutates"`
+}
+
+// engineCommandResultResponse represents the result of a command on one engine.
+type engineCommandResultResponse struct {
+	Engine string `json:"engine"`
+	Result any    `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// engineCommandResponse represents the results of a command in JSON format.
+type engineCommandResponse struct {
+	Command string                        `json:"command"`
+	Engines []engineCommandResultResponse `json:"engines"`
+}
+
+// ServeHTTP routes engine command requests.
+// GET /engine - list the commands
+// GET /engine/{command} - run a read-only command
+// POST /engine/{command} - run any command
+func (h *EngineHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	command := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/engine"), "/")
+	if command == "" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.handleList(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodPost:
+		h.handleRun(w, r, command)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleList handles GET /engine
+func (h *EngineHTTPHandler) handleList(w http.ResponseWriter) {
+	commands := h.service.Commands()
+	response := make([]engineCommandInfoResponse, len(commands))
+	for i, c := range commands {
+		response[i] = engineCommandInfoResponse{Command: string(c), Mutates: c.Mutates()}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleRun handles GET and POST /engine/{command}
+func (h *EngineHTTPHandler) handleRun(w http.ResponseWriter, r *http.Request, command string) {
+	results, err := h.service.RunCommand(r.Context(), command, r.Method == http.MethodPost)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrUnknownEngineCommand):
+			writeError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, application.ErrEngineCommandNotAllowed):
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, err.Error())
+		default:
+			writeError(w, http.StatusBadGateway, "acestream engine command failed")
+		}
+		return
+	}
+
+	response := engineCommandResponse{Command: command, Engines: make([]engineCommandResultResponse, len(results))}
+	failed := 0
+	for i, res := range results {
+		response.Engines[i] = engineCommandResultResponse{Engine: res.Engine, Result: res.Result, Error: res.Error}
+		if res.Error != "" {
+			failed++
+		}
+	}
+
+	// Partial failures are reported per engine; only a command that failed
+	// everywhere is an error
+	status := http.StatusOK
+	if failed == len(results) {
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, response)
+}
@@ -0,0 +1,128 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// mockAceStreamCommander is a mock implementation for testing.
+type mockAceStreamCommander struct {
+	results []driven.EngineCommandResult
+	err     error
+	ran     []driven.EngineCommand
+}
+
+func (m *mockAceStreamCommander) RunCommand(ctx context.Context, command driven.EngineCommand) ([]driven.EngineCommandResult, error) {
+	m.ran = append(m.ran, command)
+	return m.results, m.err
+}
+
+func TestEngineHTTPHandler(t *testing.T) {
+	newHandler := func(commander *mockAceStreamCommander) *EngineHTTPHandler {
+		return NewEngineHTTPHandler(application.NewEngineService(commander, slog.Default()))
+	}
+
+	t.Run("GET /engine lists the commands", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(&mockAceStreamCommander{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/engine", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var response []engineCommandInfoResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(response) != len(driven.EngineCommands) {
+			t.Errorf("expected %d commands, got %+v", len(driven.EngineCommands), response)
+		}
+	})
+
+	t.Run("GET /engine/{command} runs a read-only command", func(t *testing.T) {
+		commander := &mockAceStreamCommander{results: []driven.EngineCommandResult{
+			{Engine: "primary", Result: map[string]any{"version": "3.1.74"}},
+			{Engine: "backup", Error: "connection refused"},
+		}}
+
+		rec := httptest.NewRecorder()
+		newHandler(commander).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/engine/get_version", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var response engineCommandResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if response.Command != "get_version" || len(response.Engines) != 2 || response.Engines[1].Error != "connection refused" {
+			t.Errorf("unexpected response: %+v", response)
+		}
+	})
+
+	t.Run("GET /engine/{command} refuses state-changing commands", func(t *testing.T) {
+		commander := &mockAceStreamCommander{}
+
+		rec := httptest.NewRecorder()
+		newHandler(commander).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/engine/clear_cache", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+		if len(commander.ran) != 0 {
+			t.Errorf("expected no command to run, got %v", commander.ran)
+		}
+	})
+
+	t.Run("POST /engine/{command} runs state-changing commands", func(t *testing.T) {
+		commander := &mockAceStreamCommander{results: []driven.EngineCommandResult{{Engine: "primary", Result: true}}}
+
+		rec := httptest.NewRecorder()
+		newHandler(commander).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/engine/clear_cache", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("expected status 200, got %d", rec.Code)
+		}
+		if len(commander.ran) != 1 || commander.ran[0] != driven.EngineCommandClearCache {
+			t.Errorf("expected clear_cache to run, got %v", commander.ran)
+		}
+	})
+
+	t.Run("unknown commands are not found", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(&mockAceStreamCommander{}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/engine/shutdown", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("returns 502 when every engine failed", func(t *testing.T) {
+		commander := &mockAceStreamCommander{results: []driven.EngineCommandResult{{Engine: "primary", Error: "timeout"}}}
+
+		rec := httptest.NewRecorder()
+		newHandler(commander).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/engine/get_network_connection_status", nil))
+
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("expected status 502, got %d", rec.Code)
+		}
+	})
+
+	t.Run("returns 502 when the engines cannot be reached", func(t *testing.T) {
+		commander := &mockAceStreamCommander{err: errors.New("no engine supports commands")}
+
+		rec := httptest.NewRecorder()
+		newHandler(commander).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/engine/get_version", nil))
+
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("expected status 502, got %d", rec.Code)
+		}
+	})
+}
@@ -2,10 +2,12 @@ package driver
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
 )
 
 // EPGHTTPHandler handles HTTP requests for EPG operations.
@@ -40,10 +42,50 @@ type epgChannelResponse struct {
 
 // mappingResponse represents a channel's EPG mapping in JSON format.
 type mappingResponse struct {
-	ChannelName string `json:"channel_name"`
-	EPGID       string `json:"epg_id"`
+	ChannelName string  `json:"channel_name"`
+	EPGID       string  `json:"epg_id"`
+	Source      string  `json:"source"`
+	LastSynced  string  `json:"last_synced"`
+	Confidence  float64 `json:"confidence"`
+}
+
+// epgSyncStatusResponse represents the EPG sync status in JSON format.
+type epgSyncStatusResponse struct {
+	Running      bool                  `json:"running"`
+	LastStarted  string                `json:"last_started,omitempty"`
+	LastFinished string                `json:"last_finished,omitempty"`
+	LastSuccess  string                `json:"last_success,omitempty"`
+	LastError    string                `json:"last_error,omitempty"`
+	LastResult   epgSyncResultResponse `json:"last_result"`
+}
+
+// epgSyncResultResponse represents the changes made by an EPG sync in JSON format.
+type epgSyncResultResponse struct {
+	ChannelsCreated    int `json:"channels_created"`
+	ChannelsUpdated    int `json:"channels_updated"`
+	ChannelsUnchanged  int `json:"channels_unchanged"`
+	ChannelsArchived   int `json:"channels_archived"`
+	ChannelsAutoMapped int `json:"channels_auto_mapped"`
+	StreamsAdded       int `json:"streams_added"`
+	StreamsRemoved     int `json:"streams_removed"`
+}
+
+// sourceConflictResponse represents a hash listed under several channel
+// names in JSON format.
+type sourceConflictResponse struct {
+	InfoHash   string                      `json:"info_hash"`
+	Policy     string                      `json:"policy"`
+	Winner     conflictCandidateResponse   `json:"winner"`
+	Candidates []conflictCandidateResponse `json:"candidates"`
+}
+
+// conflictCandidateResponse represents a channel name a source lists a
+// conflicting hash under in JSON format.
+type conflictCandidateResponse struct {
 	Source      string `json:"source"`
-	LastSynced  string `json:"last_synced"`
+	ChannelName string `json:"channel_name"`
+	Priority    int    `json:"priority"`
+	ListedSince string `json:"listed_since,omitempty"`
 }
 
 // updateMappingRequest represents the JSON body for updating a manual mapping.
@@ -61,6 +103,18 @@ func (h *EPGHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// GET /api/epg/status - report the current and last sync
+	if r.Method == http.MethodGet && path == "/status" {
+		h.handleStatus(w)
+		return
+	}
+
+	// GET /api/epg/conflicts - list hashes the last sync found under several channel names
+	if r.Method == http.MethodGet && path == "/conflicts" {
+		h.handleConflicts(w)
+		return
+	}
+
 	// GET /api/epg/channels - list available EPG channels with filters
 	if r.Method == http.MethodGet && path == "/channels" {
 		h.handleListChannels(w, r)
@@ -73,6 +127,12 @@ func (h *EPGHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// GET /api/epg/mappings/review - list low-confidence automatic mappings
+	if r.Method == http.MethodGet && path == "/mappings/review" {
+		h.handleReviewMappings(w, r)
+		return
+	}
+
 	// PUT /api/epg/mappings/{channelName} - update manual mapping
 	if r.Method == http.MethodPut && strings.HasPrefix(path, "/mappings/") {
 		channelName := strings.TrimPrefix(path, "/mappings/")
@@ -87,6 +147,10 @@ func (h *EPGHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 func (h *EPGHTTPHandler) handleImport(w http.ResponseWriter, r *http.Request) {
 	err := h.epgSyncService.SyncChannels(r.Context())
 	if err != nil {
+		if errors.Is(err, application.ErrEPGSyncInProgress) {
+			writeError(w, http.StatusConflict, application.ErrEPGSyncInProgress.Error())
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "failed to import EPG data")
 		return
 	}
@@ -96,6 +160,59 @@ func (h *EPGHTTPHandler) handleImport(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleStatus handles GET /api/epg/status
+func (h *EPGHTTPHandler) handleStatus(w http.ResponseWriter) {
+	status := h.epgSyncService.Status()
+	result := status.LastResult
+
+	writeJSON(w, http.StatusOK, epgSyncStatusResponse{
+		Running:      status.Running,
+		LastStarted:  formatOptionalTime(status.LastStarted),
+		LastFinished: formatOptionalTime(status.LastFinished),
+		LastSuccess:  formatOptionalTime(status.LastSuccess),
+		LastError:    status.LastError,
+		LastResult: epgSyncResultResponse{
+			ChannelsCreated:    result.ChannelsCreated,
+			ChannelsUpdated:    result.ChannelsUpdated,
+			ChannelsUnchanged:  result.ChannelsUnchanged,
+			ChannelsArchived:   result.ChannelsArchived,
+			ChannelsAutoMapped: result.ChannelsAutoMapped,
+			StreamsAdded:       result.StreamsAdded,
+			StreamsRemoved:     result.StreamsRemoved,
+		},
+	})
+}
+
+// handleConflicts handles GET /api/epg/conflicts
+func (h *EPGHTTPHandler) handleConflicts(w http.ResponseWriter) {
+	conflicts := h.epgSyncService.Conflicts()
+
+	response := make([]sourceConflictResponse, len(conflicts))
+	for i, c := range conflicts {
+		candidates := make([]conflictCandidateResponse, len(c.Candidates))
+		for j, cand := range c.Candidates {
+			candidates[j] = toConflictCandidateResponse(cand)
+		}
+		response[i] = sourceConflictResponse{
+			InfoHash:   c.InfoHash,
+			Policy:     string(c.Policy),
+			Winner:     toConflictCandidateResponse(c.Winner),
+			Candidates: candidates,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func toConflictCandidateResponse(c application.ConflictCandidate) conflictCandidateResponse {
+	return conflictCandidateResponse{
+		Source:      c.Source,
+		ChannelName: c.ChannelName,
+		Priority:    c.Priority,
+		ListedSince: formatOptionalTime(c.ListedSince),
+	}
+}
+
 // handleListChannels handles GET /api/epg/channels with optional filters
 func (h *EPGHTTPHandler) handleListChannels(w http.ResponseWriter, r *http.Request) {
 	// Extract query parameters for filtering
@@ -138,20 +255,30 @@ func (h *EPGHTTPHandler) handleListMappings(w http.ResponseWriter, r *http.Reque
 
 	response := make([]mappingResponse, 0, len(channels))
 	for _, ch := range channels {
-		mapping := ch.EPGMapping()
-		if mapping != nil {
-			response = append(response, mappingResponse{
-				ChannelName: ch.Name(),
-				EPGID:       mapping.EPGID(),
-				Source:      string(mapping.Source()),
-				LastSynced:  mapping.LastSynced().Format("2006-01-02T15:04:05Z07:00"),
-			})
+		if ch.EPGMapping() != nil {
+			response = append(response, toMappingResponse(ch))
 		}
 	}
 
 	writeJSON(w, http.StatusOK, response)
 }
 
+// handleReviewMappings handles GET /api/epg/mappings/review
+func (h *EPGHTTPHandler) handleReviewMappings(w http.ResponseWriter, r *http.Request) {
+	channels, err := h.epgSyncService.LowConfidenceMappings(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to fetch channels")
+		return
+	}
+
+	response := make([]mappingResponse, len(channels))
+	for i, ch := range channels {
+		response[i] = toMappingResponse(ch)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
 // handleUpdateMapping handles PUT /api/epg/mappings/{channelName}
 func (h *EPGHTTPHandler) handleUpdateMapping(w http.ResponseWriter, r *http.Request, channelName string) {
 	var req updateMappingRequest
@@ -186,10 +313,17 @@ func (h *EPGHTTPHandler) handleUpdateMapping(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	writeJSON(w, http.StatusOK, mappingResponse{
+	writeJSON(w, http.StatusOK, toMappingResponse(ch))
+}
+
+// toMappingResponse converts the EPG mapping of a mapped channel to an API response.
+func toMappingResponse(ch channel.Channel) mappingResponse {
+	mapping := ch.EPGMapping()
+	return mappingResponse{
 		ChannelName: ch.Name(),
 		EPGID:       mapping.EPGID(),
 		Source:      string(mapping.Source()),
 		LastSynced:  mapping.LastSynced().Format("2006-01-02T15:04:05Z07:00"),
-	})
+		Confidence:  mapping.Confidence(),
+	}
 }
@@ -117,6 +117,133 @@ func TestEPGHTTPHandler_Import(t *testing.T) {
 	})
 }
 
+func TestEPGHTTPHandler_Status(t *testing.T) {
+	newHandler := func(fetchErr error, fetched chan<- struct{}, release <-chan struct{}) *EPGHTTPHandler {
+		epgFetcher := &mockEPGFetcher{
+			fetchEPGFunc: func(ctx context.Context) ([]epg.Channel, error) {
+				if fetched != nil {
+					fetched <- struct{}{}
+					<-release
+				}
+				return nil, fetchErr
+			},
+		}
+		channelRepo := &mockChannelRepository{}
+		streamRepo := &mockStreamRepository{}
+		subRepo := &mockSubscriptionRepository{}
+
+		epgSyncService := application.NewEPGSyncService(epgFetcher, &mockAcestreamSource{}, channelRepo, streamRepo, subRepo, slog.Default())
+		subscriptionSvc := application.NewSubscriptionService(subRepo, epgFetcher)
+		channelService := application.NewChannelService(channelRepo, streamRepo)
+		return NewEPGHTTPHandler(epgSyncService, subscriptionSvc, channelService)
+	}
+
+	t.Run("GET /epg/status reports the last sync", func(t *testing.T) {
+		handler := newHandler(errors.New("fetch failed"), nil, nil)
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/epg/import", nil))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/epg/status", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		var resp epgSyncStatusResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Running || resp.LastStarted == "" || resp.LastFinished == "" {
+			t.Errorf("expected a finished sync, got %+v", resp)
+		}
+		if resp.LastSuccess != "" || resp.LastError == "" {
+			t.Errorf("expected the failed sync to be reported, got %+v", resp)
+		}
+	})
+
+	t.Run("POST /epg/import returns 409 while a sync is running", func(t *testing.T) {
+		fetched := make(chan struct{})
+		release := make(chan struct{})
+		handler := newHandler(nil, fetched, release)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/epg/import", nil))
+		}()
+		<-fetched
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/epg/import", nil))
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected status 409, got %d", rec.Code)
+		}
+
+		statusRec := httptest.NewRecorder()
+		handler.ServeHTTP(statusRec, httptest.NewRequest(http.MethodGet, "/epg/status", nil))
+		var resp epgSyncStatusResponse
+		if err := json.NewDecoder(statusRec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if !resp.Running {
+			t.Error("expected status to report a running sync")
+		}
+
+		close(release)
+		<-done
+	})
+}
+
+func TestEPGHTTPHandler_Conflicts(t *testing.T) {
+	epgFetcher := &mockEPGFetcher{
+		fetchEPGFunc: func(ctx context.Context) ([]epg.Channel, error) {
+			return []epg.Channel{}, nil
+		},
+	}
+	acestreamSrc := &mockAcestreamSource{
+		fetchHashesFunc: func(ctx context.Context, source string) (map[string][]string, error) {
+			if source == "elcano" {
+				return map[string][]string{"La 1 HD": {"h1"}}, nil
+			}
+			return map[string][]string{"La 1": {"h1"}}, nil
+		},
+	}
+	channelRepo := &mockChannelRepository{
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			return []channel.Channel{}, nil
+		},
+	}
+	streamRepo := &mockStreamRepository{}
+	subRepo := &mockSubscriptionRepository{
+		findAllFunc: func(ctx context.Context) ([]subscription.Subscription, error) {
+			return []subscription.Subscription{}, nil
+		},
+	}
+
+	epgSyncService := application.NewEPGSyncService(epgFetcher, acestreamSrc, channelRepo, streamRepo, subRepo, slog.Default())
+	epgSyncService.SetSourcePriorities(map[string]int{"elcano": 5})
+	handler := NewEPGHTTPHandler(epgSyncService, application.NewSubscriptionService(subRepo, epgFetcher), application.NewChannelService(channelRepo, streamRepo))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/epg/import", nil))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/epg/conflicts", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp []sourceConflictResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp) != 1 || resp[0].InfoHash != "h1" || resp[0].Policy != "priority" || len(resp[0].Candidates) != 2 {
+		t.Fatalf("unexpected conflicts %+v", resp)
+	}
+	if w := resp[0].Winner; w.Source != "elcano" || w.ChannelName != "La 1 HD" || w.Priority != 5 || w.ListedSince != "" {
+		t.Errorf("unexpected winner %+v", w)
+	}
+}
+
 func TestEPGHTTPHandler_ListChannels(t *testing.T) {
 	t.Run("GET /epg/channels returns all channels", func(t *testing.T) {
 		ch1, _ := epg.NewChannel("1", "Channel One", "logo1.png", "Sports", "en", "epg1")
@@ -333,6 +460,55 @@ func TestEPGHTTPHandler_ListMappings(t *testing.T) {
 	})
 }
 
+func TestEPGHTTPHandler_ReviewMappings(t *testing.T) {
+	now := time.Now()
+	auto, _ := channel.NewEPGMapping("epg", channel.MappingAuto, now)
+	manual, _ := channel.NewEPGMapping("epg", channel.MappingManual, now)
+
+	sure, _ := channel.NewChannel("Sure")
+	sure.SetEPGMapping(auto.WithConfidence(0.95))
+	unsure, _ := channel.NewChannel("Unsure")
+	unsure.SetEPGMapping(auto.WithConfidence(0.82))
+	doubtful, _ := channel.NewChannel("Doubtful")
+	doubtful.SetEPGMapping(auto.WithConfidence(0.8))
+	confirmed, _ := channel.NewChannel("Confirmed")
+	confirmed.SetEPGMapping(manual)
+
+	channelRepo := &mockChannelRepository{
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			return []channel.Channel{sure, unsure, doubtful, confirmed}, nil
+		},
+	}
+	streamRepo := &mockStreamRepository{}
+	epgFetcher := &mockEPGFetcher{}
+	subRepo := &mockSubscriptionRepository{}
+
+	channelService := application.NewChannelService(channelRepo, streamRepo)
+	subscriptionSvc := application.NewSubscriptionService(subRepo, epgFetcher)
+	epgSyncService := application.NewEPGSyncService(epgFetcher, &mockAcestreamSource{}, channelRepo, streamRepo, subRepo, slog.Default())
+	handler := NewEPGHTTPHandler(epgSyncService, subscriptionSvc, channelService)
+
+	req := httptest.NewRequest(http.MethodGet, "/epg/mappings/review", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+
+	var resp []mappingResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp) != 2 || resp[0].ChannelName != "Doubtful" || resp[1].ChannelName != "Unsure" {
+		t.Fatalf("expected the low-confidence automatic mappings, least confident first, got %+v", resp)
+	}
+	if resp[0].Confidence != 0.8 || resp[0].Source != "auto" {
+		t.Errorf("unexpected mapping %+v", resp[0])
+	}
+}
+
 func TestEPGHTTPHandler_UpdateMapping(t *testing.T) {
 	t.Run("PUT /epg/mappings/{channelName} updates mapping successfully", func(t *testing.T) {
 		ch, _ := channel.NewChannel("TestChannel")
@@ -0,0 +1,97 @@
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// eventsKeepAliveInterval is how often a comment is sent on an idle event
+// stream so proxies do not close the connection.
+const eventsKeepAliveInterval = 15 * time.Second
+
+// EventSubscriber defines the event bus operation needed by the handler.
+type EventSubscriber interface {
+	Subscribe() (<-chan application.Event, func())
+}
+
+// EventsHTTPHandler pushes live status events to the SPA as Server-Sent Events.
+type EventsHTTPHandler struct {
+	events EventSubscriber
+}
+
+// NewEventsHTTPHandler creates a new HTTP handler for the live event stream.
+func NewEventsHTTPHandler(events EventSubscriber) *EventsHTTPHandler {
+	return &EventsHTTPHandler{events: events}
+}
+
+// eventResponse represents the data of a single Server-Sent Event.
+type eventResponse struct {
+	Type string `json:"type"`
+	Time string `json:"time"`
+	Data any    `json:"data,omitempty"`
+}
+
+// ServeHTTP handles GET /events. Each event is written with the event type
+// as the SSE event name and a JSON payload. The stream stays open until the
+// client disconnects or the event bus is closed.
+func (h *EventsHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	events, unsubscribe := h.events.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Opening comment so the client sees the stream as established
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(eventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(eventResponse{
+				Type: string(event.Type),
+				Time: event.Time.Format("2006-01-02T15:04:05Z07:00"),
+				Data: event.Data,
+			})
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package driver
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+func TestEventsHTTPHandler(t *testing.T) {
+	t.Run("streams published events", func(t *testing.T) {
+		bus := application.NewEventBus()
+		server := httptest.NewServer(NewEventsHTTPHandler(bus))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/events")
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("expected text/event-stream, got %q", ct)
+		}
+
+		reader := bufio.NewReader(resp.Body)
+		if line, _ := reader.ReadString('\n'); line != ": connected\n" {
+			t.Fatalf("expected connected comment, got %q", line)
+		}
+
+		bus.Publish(application.EventStreamStarted, application.StreamEventData{InfoHash: "abc123"})
+
+		var lines []string
+		for len(lines) < 3 {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read event: %v", err)
+			}
+			if line == "\n" && len(lines) == 0 {
+				continue
+			}
+			lines = append(lines, line)
+		}
+
+		if lines[0] != "event: stream.started\n" {
+			t.Errorf("expected event name line, got %q", lines[0])
+		}
+		if !strings.HasPrefix(lines[1], `data: {"type":"stream.started"`) || !strings.Contains(lines[1], `"data":{"infohash":"abc123"}`) {
+			t.Errorf("unexpected data line %q", lines[1])
+		}
+
+		bus.Close()
+		if _, err := reader.ReadString('\n'); err == nil {
+			t.Error("expected stream to end when the bus is closed")
+		}
+	})
+
+	t.Run("returns 405 for non-GET methods", func(t *testing.T) {
+		handler := NewEventsHTTPHandler(application.NewEventBus())
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/favorite"
+)
+
+// FavoriteHTTPHandler handles HTTP requests for favorite list management.
+type FavoriteHTTPHandler struct {
+	service *application.FavoriteService
+}
+
+// NewFavoriteHTTPHandler creates a new HTTP handler for favorite lists.
+func NewFavoriteHTTPHandler(service *application.FavoriteService) *FavoriteHTTPHandler {
+	return &FavoriteHTTPHandler{service: service}
+}
+
+// favoriteRequest represents the JSON body for creating a favorite list.
+type favoriteRequest struct {
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"`
+	Guide    bool     `json:"guide"`
+}
+
+// favoritePatchRequest represents the JSON body for updating a favorite
+// list. Omitted fields are left unchanged.
+type favoritePatchRequest struct {
+	Name     *string  `json:"name"`
+	Channels []string `json:"channels"`
+	Guide    *bool    `json:"guide"`
+}
+
+// favoriteResponse represents a favorite list in JSON format.
+type favoriteResponse struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Channels    []string `json:"channels"`
+	Guide       bool     `json:"guide"`
+	PlaylistURL string   `json:"playlist_url"`
+	GuideURL    string   `json:"guide_url,omitempty"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *FavoriteHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/favorites")
+
+	// GET /favorites - list all favorite lists
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	// POST /favorites - create a new favorite list
+	if r.Method == http.MethodPost && path == "" {
+		h.handleCreate(w, r)
+		return
+	}
+
+	id := strings.TrimPrefix(path, "/")
+	if id != "" && !strings.Contains(id, "/") {
+		switch r.Method {
+		// GET /favorites/{id} - get a specific favorite list
+		case http.MethodGet:
+			h.handleGet(w, r, id)
+			return
+		// PATCH /favorites/{id} - rename a list, replace its channels or toggle its guide
+		case http.MethodPatch:
+			h.handlePatch(w, r, id)
+			return
+		// DELETE /favorites/{id} - delete a favorite list
+		case http.MethodDelete:
+			h.handleDelete(w, r, id)
+			return
+		}
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// toFavoriteResponse converts a favorite list to an API response, with the
+// URLs players load it from on the request's host.
+func toFavoriteResponse(r *http.Request, l favorite.List) favoriteResponse {
+	resp := favoriteResponse{
+		ID:          l.ID(),
+		Name:        l.Name(),
+		Channels:    l.Channels(),
+		Guide:       l.HasGuide(),
+		PlaylistURL: publicBaseURL(r) + "/playlist/fav/" + l.ID() + ".m3u",
+	}
+	if l.HasGuide() {
+		resp.GuideURL = publicBaseURL(r) + "/playlist/fav/" + l.ID() + ".xml"
+	}
+	return resp
+}
+
+// writeFavoriteError maps favorite and channel errors to HTTP responses.
+func writeFavoriteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, favorite.ErrEmptyName), errors.Is(err, favorite.ErrInvalidName),
+		errors.Is(err, favorite.ErrEmptyChannelName):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, favorite.ErrListNotFound), errors.Is(err, channel.ErrChannelNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, favorite.ErrListAlreadyExists):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// handleList handles GET /favorites
+func (h *FavoriteHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	lists, err := h.service.ListLists(r.Context())
+	if err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	response := make([]favoriteResponse, len(lists))
+	for i, l := range lists {
+		response[i] = toFavoriteResponse(r, l)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleCreate handles POST /favorites
+func (h *FavoriteHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req favoriteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	l, err := h.service.CreateList(r.Context(), req.Name, req.Channels, req.Guide)
+	if err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toFavoriteResponse(r, l))
+}
+
+// handleGet handles GET /favorites/{id}
+func (h *FavoriteHTTPHandler) handleGet(w http.ResponseWriter, r *http.Request, id string) {
+	l, err := h.service.GetList(r.Context(), id)
+	if err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toFavoriteResponse(r, l))
+}
+
+// handlePatch handles PATCH /favorites/{id}
+func (h *FavoriteHTTPHandler) handlePatch(w http.ResponseWriter, r *http.Request, id string) {
+	var req favoritePatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	l, err := h.service.UpdateList(r.Context(), id, application.FavoriteUpdate{
+		Name:     req.Name,
+		Channels: req.Channels,
+		Guide:    req.Guide,
+	})
+	if err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toFavoriteResponse(r, l))
+}
+
+// handleDelete handles DELETE /favorites/{id}
+func (h *FavoriteHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteList(r.Context(), id); err != nil {
+		writeFavoriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,152 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/favorite"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// mockFavoriteRepository is an in-memory implementation for testing.
+type mockFavoriteRepository struct {
+	lists map[string]favorite.List
+}
+
+func (m *mockFavoriteRepository) Save(ctx context.Context, l favorite.List) error {
+	if _, ok := m.lists[l.ID()]; ok {
+		return favorite.ErrListAlreadyExists
+	}
+	m.lists[l.ID()] = l
+	return nil
+}
+
+func (m *mockFavoriteRepository) Update(ctx context.Context, l favorite.List) error {
+	if _, ok := m.lists[l.ID()]; !ok {
+		return favorite.ErrListNotFound
+	}
+	m.lists[l.ID()] = l
+	return nil
+}
+
+func (m *mockFavoriteRepository) FindAll(ctx context.Context) ([]favorite.List, error) {
+	lists := make([]favorite.List, 0, len(m.lists))
+	for _, l := range m.lists {
+		lists = append(lists, l)
+	}
+	return lists, nil
+}
+
+func (m *mockFavoriteRepository) FindByID(ctx context.Context, id string) (favorite.List, error) {
+	l, ok := m.lists[id]
+	if !ok {
+		return favorite.List{}, favorite.ErrListNotFound
+	}
+	return l, nil
+}
+
+func (m *mockFavoriteRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.lists[id]; !ok {
+		return favorite.ErrListNotFound
+	}
+	delete(m.lists, id)
+	return nil
+}
+
+// newFavoriteTestServices returns a favorite service and the playlist
+// service it renders with, over the channels La 1 and DAZN 1.
+func newFavoriteTestServices() (*application.FavoriteService, *application.PlaylistService) {
+	la1, _ := stream.NewStream("6c61310000000000000000000000000000000000", "La 1", "")
+	dazn, _ := stream.NewStream("64617a6e00000000000000000000000000000000", "DAZN 1", "")
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{la1, dazn}, nil
+		},
+	}
+	la1Channel, _ := channel.NewChannel("La 1")
+	daznChannel, _ := channel.NewChannel("DAZN 1")
+	channels := []channel.Channel{la1Channel, daznChannel}
+	channelRepo := &mockChannelRepository{
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			return channels, nil
+		},
+		findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+			for _, ch := range channels {
+				if ch.Name() == name {
+					return ch, nil
+				}
+			}
+			return channel.Channel{}, channel.ErrChannelNotFound
+		},
+	}
+	playlist := application.NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+	favorites := application.NewFavoriteService(&mockFavoriteRepository{lists: make(map[string]favorite.List)}, channelRepo, playlist)
+	return favorites, playlist
+}
+
+func TestFavoriteHTTPHandler(t *testing.T) {
+	service, _ := newFavoriteTestServices()
+	handler := NewFavoriteHTTPHandler(service)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Host = "localhost:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, body := range []string{`{`, `{"name":" "}`, `{"name":"Kids","channels":[" "]}`} {
+		if rec := do(http.MethodPost, "/favorites", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+	if rec := do(http.MethodPost, "/favorites", `{"name":"Kids","channels":["Missing"]}`); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown channel, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodPost, "/favorites", `{"name":"Living room","channels":["DAZN 1","La 1"],"guide":true}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created favoriteResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID != "living-room" || len(created.Channels) != 2 || created.Channels[0] != "DAZN 1" ||
+		created.PlaylistURL != "http://localhost:8080/playlist/fav/living-room.m3u" ||
+		created.GuideURL != "http://localhost:8080/playlist/fav/living-room.xml" {
+		t.Errorf("unexpected list %+v", created)
+	}
+	if rec := do(http.MethodPost, "/favorites", `{"name":"Living Room"}`); rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 for a duplicate, got %d", rec.Code)
+	}
+
+	rec = do(http.MethodPatch, "/favorites/living-room", `{"channels":["La 1"],"guide":false}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var updated favoriteResponse
+	if err := json.NewDecoder(rec.Body).Decode(&updated); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if updated.Name != "Living room" || len(updated.Channels) != 1 || updated.Guide || updated.GuideURL != "" {
+		t.Errorf("unexpected list %+v", updated)
+	}
+
+	if rec := do(http.MethodGet, "/favorites", ""); rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 listing lists, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/favorites/living-room", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/favorites/living-room", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 after delete, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,250 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+)
+
+// GroupHTTPHandler handles HTTP requests for channel group management.
+type GroupHTTPHandler struct {
+	service *application.GroupService
+}
+
+// NewGroupHTTPHandler creates a new HTTP handler for channel groups.
+func NewGroupHTTPHandler(service *application.GroupService) *GroupHTTPHandler {
+	return &GroupHTTPHandler{service: service}
+}
+
+// groupRequest represents the JSON body for creating a group.
+type groupRequest struct {
+	Name string `json:"name"`
+}
+
+// groupPatchRequest represents the JSON body for updating a group.
+// Omitted fields are left unchanged.
+type groupPatchRequest struct {
+	Name     *string `json:"name"`
+	Enabled  *bool   `json:"enabled"`
+	Position *int    `json:"position"`
+}
+
+// groupOrderRequest represents the JSON body for reordering groups.
+type groupOrderRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// groupResponse represents a group in JSON format.
+type groupResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *GroupHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/groups")
+
+	// GET /groups - list all groups in order
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	// POST /groups - create a new group
+	if r.Method == http.MethodPost && path == "" {
+		h.handleCreate(w, r)
+		return
+	}
+
+	// PUT /groups/order - reorder groups
+	if r.Method == http.MethodPut && path == "/order" {
+		h.handleReorder(w, r)
+		return
+	}
+
+	id, rest, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+
+	// PUT|DELETE /groups/{id}/channels/{name} - assign or unassign a channel
+	if name, ok := strings.CutPrefix(rest, "channels/"); ok && id != "" && name != "" {
+		switch r.Method {
+		case http.MethodPut:
+			h.handleAssign(w, r, id, name)
+			return
+		case http.MethodDelete:
+			h.handleUnassign(w, r, id, name)
+			return
+		}
+	}
+
+	if id != "" && rest == "" {
+		switch r.Method {
+		// GET /groups/{id} - get a specific group
+		case http.MethodGet:
+			h.handleGet(w, r, id)
+			return
+		// PATCH /groups/{id} - rename, enable/disable or move a group
+		case http.MethodPatch:
+			h.handlePatch(w, r, id)
+			return
+		// DELETE /groups/{id} - delete a group
+		case http.MethodDelete:
+			h.handleDelete(w, r, id)
+			return
+		}
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// toGroupResponse converts a group domain object to an API response.
+func toGroupResponse(g group.Group) groupResponse {
+	return groupResponse{
+		ID:       g.ID(),
+		Name:     g.Name(),
+		Position: g.Position(),
+		Enabled:  g.IsEnabled(),
+	}
+}
+
+func toGroupResponses(groups []group.Group) []groupResponse {
+	response := make([]groupResponse, len(groups))
+	for i, g := range groups {
+		response[i] = toGroupResponse(g)
+	}
+	return response
+}
+
+// writeGroupError maps group and channel errors to HTTP responses.
+func writeGroupError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, group.ErrEmptyName), errors.Is(err, group.ErrInvalidName):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, group.ErrGroupNotFound), errors.Is(err, channel.ErrChannelNotFound),
+		errors.Is(err, application.ErrChannelNotInGroup):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, group.ErrGroupAlreadyExists):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// handleList handles GET /groups
+func (h *GroupHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.service.ListGroups(r.Context())
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toGroupResponses(groups))
+}
+
+// handleCreate handles POST /groups
+func (h *GroupHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req groupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	g, err := h.service.CreateGroup(r.Context(), req.Name)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toGroupResponse(g))
+}
+
+// handleReorder handles PUT /groups/order
+func (h *GroupHTTPHandler) handleReorder(w http.ResponseWriter, r *http.Request) {
+	var req groupOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	groups, err := h.service.ReorderGroups(r.Context(), req.IDs)
+	if err != nil {
+		if errors.Is(err, group.ErrGroupNotFound) {
+			// An unknown ID is a problem with the request, not a missing resource
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeGroupError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toGroupResponses(groups))
+}
+
+// handleGet handles GET /groups/{id}
+func (h *GroupHTTPHandler) handleGet(w http.ResponseWriter, r *http.Request, id string) {
+	g, err := h.service.GetGroup(r.Context(), id)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toGroupResponse(g))
+}
+
+// handlePatch handles PATCH /groups/{id}
+func (h *GroupHTTPHandler) handlePatch(w http.ResponseWriter, r *http.Request, id string) {
+	var req groupPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	g, err := h.service.UpdateGroup(r.Context(), id, application.GroupUpdate{
+		Name:     req.Name,
+		Enabled:  req.Enabled,
+		Position: req.Position,
+	})
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toGroupResponse(g))
+}
+
+// handleDelete handles DELETE /groups/{id}
+func (h *GroupHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteGroup(r.Context(), id); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAssign handles PUT /groups/{id}/channels/{name}
+func (h *GroupHTTPHandler) handleAssign(w http.ResponseWriter, r *http.Request, id, name string) {
+	ch, err := h.service.AssignChannel(r.Context(), id, name)
+	if err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toChannelResponse(ch))
+}
+
+// handleUnassign handles DELETE /groups/{id}/channels/{name}
+func (h *GroupHTTPHandler) handleUnassign(w http.ResponseWriter, r *http.Request, id, name string) {
+	if err := h.service.UnassignChannel(r.Context(), id, name); err != nil {
+		writeGroupError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,197 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+)
+
+// mockGroupRepository is an in-memory implementation for testing.
+type mockGroupRepository struct {
+	groups map[string]group.Group
+}
+
+func newMockGroupRepository(groups ...group.Group) *mockGroupRepository {
+	m := &mockGroupRepository{groups: make(map[string]group.Group)}
+	for _, g := range groups {
+		m.groups[g.ID()] = g
+	}
+	return m
+}
+
+func (m *mockGroupRepository) Save(ctx context.Context, g group.Group) error {
+	if _, ok := m.groups[g.ID()]; ok {
+		return group.ErrGroupAlreadyExists
+	}
+	m.groups[g.ID()] = g
+	return nil
+}
+
+func (m *mockGroupRepository) Update(ctx context.Context, g group.Group) error {
+	if _, ok := m.groups[g.ID()]; !ok {
+		return group.ErrGroupNotFound
+	}
+	m.groups[g.ID()] = g
+	return nil
+}
+
+func (m *mockGroupRepository) FindAll(ctx context.Context) ([]group.Group, error) {
+	groups := make([]group.Group, 0, len(m.groups))
+	for _, g := range m.groups {
+		groups = append(groups, g)
+	}
+	group.Sort(groups)
+	return groups, nil
+}
+
+func (m *mockGroupRepository) FindByID(ctx context.Context, id string) (group.Group, error) {
+	g, ok := m.groups[id]
+	if !ok {
+		return group.Group{}, group.ErrGroupNotFound
+	}
+	return g, nil
+}
+
+func (m *mockGroupRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.groups[id]; !ok {
+		return group.ErrGroupNotFound
+	}
+	delete(m.groups, id)
+	return nil
+}
+
+func TestGroupHTTPHandler(t *testing.T) {
+	newHandler := func(groups ...group.Group) *GroupHTTPHandler {
+		ch, _ := channel.NewChannel("DAZN 1")
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				if name == ch.Name() {
+					return ch, nil
+				}
+				return channel.Channel{}, channel.ErrChannelNotFound
+			},
+		}
+		return NewGroupHTTPHandler(application.NewGroupService(newMockGroupRepository(groups...), channelRepo))
+	}
+	serve := func(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	t.Run("POST /groups creates a group", func(t *testing.T) {
+		rec := serve(newHandler(), http.MethodPost, "/groups", `{"name":"Sports & News"}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d", rec.Code)
+		}
+
+		var resp groupResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.ID != "sports-news" || resp.Name != "Sports & News" || !resp.Enabled {
+			t.Errorf("unexpected response %+v", resp)
+		}
+	})
+
+	t.Run("POST /groups maps validation and conflict errors", func(t *testing.T) {
+		h := newHandler(group.ReconstructGroup("sports", "Sports", 0, true))
+		if rec := serve(h, http.MethodPost, "/groups", `{"name":""}`); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for empty name, got %d", rec.Code)
+		}
+		if rec := serve(h, http.MethodPost, "/groups", `{"name":"Sports"}`); rec.Code != http.StatusConflict {
+			t.Errorf("expected status 409 for duplicate, got %d", rec.Code)
+		}
+	})
+
+	t.Run("GET /groups lists groups in order", func(t *testing.T) {
+		h := newHandler(
+			group.ReconstructGroup("news", "News", 1, true),
+			group.ReconstructGroup("sports", "Sports", 0, false),
+		)
+		rec := serve(h, http.MethodGet, "/groups", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp []groupResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 2 || resp[0].ID != "sports" || resp[1].ID != "news" {
+			t.Errorf("unexpected order %+v", resp)
+		}
+	})
+
+	t.Run("PATCH /groups/{id} disables a group", func(t *testing.T) {
+		h := newHandler(group.ReconstructGroup("sports", "Sports", 0, true))
+		rec := serve(h, http.MethodPatch, "/groups/sports", `{"enabled":false}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp groupResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Enabled || resp.Name != "Sports" {
+			t.Errorf("unexpected response %+v", resp)
+		}
+	})
+
+	t.Run("PUT /groups/order reorders groups", func(t *testing.T) {
+		h := newHandler(
+			group.ReconstructGroup("sports", "Sports", 0, true),
+			group.ReconstructGroup("news", "News", 1, true),
+		)
+		rec := serve(h, http.MethodPut, "/groups/order", `{"ids":["news"]}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp []groupResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 2 || resp[0].ID != "news" || resp[0].Position != 0 || resp[1].Position != 1 {
+			t.Errorf("unexpected order %+v", resp)
+		}
+
+		if rec := serve(h, http.MethodPut, "/groups/order", `{"ids":["missing"]}`); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for unknown ID, got %d", rec.Code)
+		}
+	})
+
+	t.Run("PUT /groups/{id}/channels/{name} assigns a channel", func(t *testing.T) {
+		h := newHandler(group.ReconstructGroup("sports", "Sports", 0, true))
+		rec := serve(h, http.MethodPut, "/groups/sports/channels/DAZN%201", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+
+		var resp channelResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Name != "DAZN 1" || resp.Group != "sports" {
+			t.Errorf("unexpected response %+v", resp)
+		}
+
+		if rec := serve(h, http.MethodPut, "/groups/sports/channels/Missing", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for unknown channel, got %d", rec.Code)
+		}
+	})
+
+	t.Run("DELETE /groups/{id} returns 404 for unknown group", func(t *testing.T) {
+		if rec := serve(newHandler(), http.MethodDelete, "/groups/missing", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+}
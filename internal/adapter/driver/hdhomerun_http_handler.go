@@ -0,0 +1,122 @@
+package driver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// HDHomeRunConfig describes the emulated HDHomeRun tuner.
+type HDHomeRunConfig struct {
+	FriendlyName string
+	DeviceID     string
+	TunerCount   int
+}
+
+// HDHomeRunHTTPHandler emulates the discovery and lineup API of an HDHomeRun
+// network tuner, so media servers such as Plex and Emby can add the manager as
+// a live TV source. Each lineup entry tunes through /ace/channel/{name}.
+type HDHomeRunHTTPHandler struct {
+	playlist *application.PlaylistService
+	config   HDHomeRunConfig
+}
+
+// NewHDHomeRunHTTPHandler creates a new HTTP handler for HDHomeRun emulation.
+func NewHDHomeRunHTTPHandler(playlist *application.PlaylistService, config HDHomeRunConfig) *HDHomeRunHTTPHandler {
+	return &HDHomeRunHTTPHandler{playlist: playlist, config: config}
+}
+
+// hdhomerunDiscoverResponse is the body of /discover.json.
+type hdhomerunDiscoverResponse struct {
+	FriendlyName    string `json:"FriendlyName"`
+	Manufacturer    string `json:"Manufacturer"`
+	ModelNumber     string `json:"ModelNumber"`
+	FirmwareName    string `json:"FirmwareName"`
+	FirmwareVersion string `json:"FirmwareVersion"`
+	DeviceID        string `json:"DeviceID"`
+	DeviceAuth      string `json:"DeviceAuth"`
+	TunerCount      int    `json:"TunerCount"`
+	BaseURL         string `json:"BaseURL"`
+	LineupURL       string `json:"LineupURL"`
+}
+
+// hdhomerunLineupStatusResponse is the body of /lineup_status.json.
+type hdhomerunLineupStatusResponse struct {
+	ScanInProgress int      `json:"ScanInProgress"`
+	ScanPossible   int      `json:"ScanPossible"`
+	Source         string   `json:"Source"`
+	SourceList     []string `json:"SourceList"`
+}
+
+// hdhomerunLineupEntry is an element of /lineup.json.
+type hdhomerunLineupEntry struct {
+	GuideNumber string `json:"GuideNumber"`
+	GuideName   string `json:"GuideName"`
+	URL         string `json:"URL"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *HDHomeRunHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	// GET /discover.json - device description
+	case r.Method == http.MethodGet && r.URL.Path == "/discover.json":
+		h.handleDiscover(w, r)
+
+	// GET /lineup_status.json - channel scan status
+	case r.Method == http.MethodGet && r.URL.Path == "/lineup_status.json":
+		writeJSON(w, http.StatusOK, hdhomerunLineupStatusResponse{
+			ScanPossible: 1,
+			Source:       "Cable",
+			SourceList:   []string{"Cable"},
+		})
+
+	// GET /lineup.json - channels available to tune
+	case r.Method == http.MethodGet && r.URL.Path == "/lineup.json":
+		h.handleLineup(w, r)
+
+	// POST /lineup.post - channel scan request; the lineup is always current
+	case r.Method == http.MethodPost && r.URL.Path == "/lineup.post":
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleDiscover handles GET /discover.json
+func (h *HDHomeRunHTTPHandler) handleDiscover(w http.ResponseWriter, r *http.Request) {
+	baseURL := publicBaseURL(r)
+	writeJSON(w, http.StatusOK, hdhomerunDiscoverResponse{
+		FriendlyName:    h.config.FriendlyName,
+		Manufacturer:    "Silicondust",
+		ModelNumber:     "HDTC-2US",
+		FirmwareName:    "hdhomeruntc_atsc",
+		FirmwareVersion: "20200101",
+		DeviceID:        h.config.DeviceID,
+		DeviceAuth:      "iptv-manager",
+		TunerCount:      h.config.TunerCount,
+		BaseURL:         baseURL,
+		LineupURL:       baseURL + "/lineup.json" + forwardedToken(r),
+	})
+}
+
+// handleLineup handles GET /lineup.json
+func (h *HDHomeRunHTTPHandler) handleLineup(w http.ResponseWriter, r *http.Request) {
+	lineup, err := h.playlist.Lineup(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := make([]hdhomerunLineupEntry, len(lineup))
+	for i, entry := range lineup {
+		response[i] = hdhomerunLineupEntry{
+			GuideNumber: strconv.Itoa(entry.Number),
+			GuideName:   entry.ChannelName,
+			URL:         h.playlist.ChannelURL(r.Context(), publicBaseURL(r), entry.ChannelName),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,149 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+func TestHDHomeRunHTTPHandler(t *testing.T) {
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			a, _ := stream.NewStream("6162630000000000000000000000000000000000", "La 1", "")
+			b, _ := stream.NewStream("6465660000000000000000000000000000000000", "DAZN 1", "")
+			return []stream.Stream{a, b}, nil
+		},
+	}
+	playlist := application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+	handler := NewHDHomeRunHTTPHandler(playlist, HDHomeRunConfig{FriendlyName: "IPTV Manager", DeviceID: "12AB34CD", TunerCount: 3})
+
+	t.Run("GET /discover.json describes the tuner", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/discover.json", nil)
+		req.Host = "tv.local:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp hdhomerunDiscoverResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.TunerCount != 3 || resp.DeviceID != "12AB34CD" || resp.FriendlyName != "IPTV Manager" {
+			t.Errorf("unexpected device %+v", resp)
+		}
+		if resp.BaseURL != "http://tv.local:8080" || resp.LineupURL != "http://tv.local:8080/lineup.json" {
+			t.Errorf("unexpected URLs %q, %q", resp.BaseURL, resp.LineupURL)
+		}
+	})
+
+	t.Run("GET /lineup.json lists channels with tune URLs", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/lineup.json", nil)
+		req.Host = "tv.local:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp []hdhomerunLineupEntry
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(resp))
+		}
+		want := hdhomerunLineupEntry{GuideNumber: "1", GuideName: "DAZN 1", URL: "http://tv.local:8080/ace/channel/DAZN%201"}
+		if resp[0] != want {
+			t.Errorf("entry 0 = %+v, want %+v", resp[0], want)
+		}
+	})
+
+	t.Run("GET /lineup.json signs tune URLs for the caller's token", func(t *testing.T) {
+		tokenRepo := newMockTokenRepository()
+		authService := application.NewAuthService(tokenRepo, application.AuthConfig{Username: "admin", Password: "secret", SessionKey: []byte("test-key")})
+		tok, secret, _ := authService.CreateToken(context.Background(), "plex")
+
+		signer := auth.NewLinkSigner([]auth.LinkKey{{ID: "k1", Secret: []byte("link-secret")}})
+		links := application.NewStreamLinks(signer, time.Hour, true, tokenRepo)
+		signed := application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+		signed.SetStreamLinks(links)
+		handler := NewAuthMiddleware(authService, NewHDHomeRunHTTPHandler(signed, HDHomeRunConfig{FriendlyName: "IPTV Manager", DeviceID: "12AB34CD", TunerCount: 3}), slog.Default())
+
+		// Anonymous callers get no links at all
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lineup.json", nil))
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("expected anonymous lineup requests to be refused, got %d", rec.Code)
+		}
+
+		// Discovery hands the token on to the lineup URL
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/discover.json?token="+secret, nil))
+		var discover hdhomerunDiscoverResponse
+		if err := json.NewDecoder(rec.Body).Decode(&discover); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		lineupURL, err := url.Parse(discover.LineupURL)
+		if err != nil || lineupURL.Query().Get("token") != secret {
+			t.Fatalf("expected the lineup URL to carry the token, got %q", discover.LineupURL)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, lineupURL.RequestURI(), nil)
+		req.Host = "tv.local:8080"
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var resp []hdhomerunLineupEntry
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) == 0 {
+			t.Fatal("expected lineup entries")
+		}
+		u, err := url.Parse(resp[0].URL)
+		if err != nil {
+			t.Fatalf("invalid tune URL %q: %v", resp[0].URL, err)
+		}
+		if u.Path != "/ace/channel/DAZN 1" {
+			t.Errorf("unexpected tune path %q", u.Path)
+		}
+		if err := links.VerifyChannel(context.Background(), "DAZN 1", u.Query()); err != nil {
+			t.Errorf("expected a valid channel signature, got %v", err)
+		}
+
+		// The link is scoped to the token, so revoking it revokes the link
+		if err := authService.RevokeToken(context.Background(), tok.ID()); err != nil {
+			t.Fatalf("RevokeToken() error = %v", err)
+		}
+		if err := links.VerifyChannel(context.Background(), "DAZN 1", u.Query()); !errors.Is(err, auth.ErrInvalidLinkSignature) {
+			t.Errorf("expected the link of a revoked token to be refused, got %v", err)
+		}
+	})
+
+	t.Run("GET /lineup_status.json reports no scan in progress", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/lineup_status.json", nil))
+
+		var resp hdhomerunLineupStatusResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.ScanInProgress != 0 || resp.ScanPossible != 1 {
+			t.Errorf("unexpected status %+v", resp)
+		}
+	})
+}
@@ -39,6 +39,10 @@ func (m *mockChannelRepositoryForHealth) FindAll(ctx context.Context) ([]channel
 	return []channel.Channel{}, nil
 }
 
+func (m *mockChannelRepositoryForHealth) FindByTag(ctx context.Context, tag string) ([]channel.Channel, error) {
+	return []channel.Channel{}, nil
+}
+
 func (m *mockChannelRepositoryForHealth) Update(ctx context.Context, ch channel.Channel) error {
 	return nil
 }
@@ -49,7 +53,9 @@ func (m *mockChannelRepositoryForHealth) Delete(ctx context.Context, name string
 
 // mockAceStreamEngine is a mock implementation for health check testing.
 type mockAceStreamEngine struct {
-	pingFunc func(ctx context.Context) error
+	pingFunc          func(ctx context.Context) error
+	startStreamFunc   func(ctx context.Context, infoHash, pid string) (string, error)
+	streamContentFunc func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error
 }
 
 func (m *mockAceStreamEngine) Ping(ctx context.Context) error {
@@ -59,7 +65,10 @@ func (m *mockAceStreamEngine) Ping(ctx context.Context) error {
 	return nil
 }
 
-func (m *mockAceStreamEngine) StartStream(ctx context.Context, infoHash, pid string) (string, error) {
+func (m *mockAceStreamEngine) StartStream(ctx context.Context, infoHash, pid string, opts driven.StreamOptions) (string, error) {
+	if m.startStreamFunc != nil {
+		return m.startStreamFunc(ctx, infoHash, pid)
+	}
 	return "", nil
 }
 
@@ -72,6 +81,9 @@ func (m *mockAceStreamEngine) StopStream(ctx context.Context, pid string) error
 }
 
 func (m *mockAceStreamEngine) StreamContent(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+	if m.streamContentFunc != nil {
+		return m.streamContentFunc(ctx, streamURL, dst, infoHash, pid, writeTimeout)
+	}
 	return nil
 }
 
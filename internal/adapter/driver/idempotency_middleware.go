@@ -0,0 +1,193 @@
+package driver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// idempotencyKeyHeader carries the client-chosen key of a change.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotencyKeyLength bounds the keys accepted from clients.
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentResponseSize bounds the responses kept for replay; larger
+// responses are sent but not remembered.
+const maxIdempotentResponseSize = 1 << 20
+
+// IdempotencyMiddleware makes POST and PATCH requests carrying an
+// Idempotency-Key header safe to retry: the first response for a key is
+// kept for a while and replayed, marked with Idempotent-Replayed, to
+// retries of the same request instead of repeating the change.
+//
+// Keys are scoped to the caller, so one client cannot be replayed the
+// response to another that chose the same key. Reusing a key for a
+// different request is rejected with 422, and a retry arriving while the
+// first request is still running with 409. Server errors are not
+// remembered, so those can be retried with the same key.
+type IdempotencyMiddleware struct {
+	next http.Handler
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*idempotentEntry
+}
+
+// idempotentEntry is the state of one key.
+type idempotentEntry struct {
+	fingerprint [sha256.Size]byte
+	done        bool
+	expires     time.Time
+	status      int
+	header      http.Header
+	body        []byte
+}
+
+// NewIdempotencyMiddleware wraps next with idempotency keys remembered for ttl.
+func NewIdempotencyMiddleware(next http.Handler, ttl time.Duration) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		next:    next,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*idempotentEntry),
+	}
+}
+
+// ServeHTTP serves the request once per idempotency key.
+func (m *IdempotencyMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		writeError(w, http.StatusBadRequest, "idempotency key too long")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	fingerprint := sha256.Sum256(body)
+	key = callerIdentity(r) + " " + r.Method + " " + r.URL.Path + " " + key
+
+	m.mu.Lock()
+	now := m.now()
+	m.pruneLocked(now)
+	if entry, ok := m.entries[key]; ok {
+		m.mu.Unlock()
+		switch {
+		case entry.fingerprint != fingerprint:
+			writeError(w, http.StatusUnprocessableEntity, "idempotency key already used for a different request")
+		case !entry.done:
+			writeError(w, http.StatusConflict, "a request with this idempotency key is still in progress")
+		default:
+			replayResponse(w, entry)
+		}
+		return
+	}
+	entry := &idempotentEntry{fingerprint: fingerprint, expires: now.Add(m.ttl)}
+	m.entries[key] = entry
+	m.mu.Unlock()
+
+	rec := &responseCapture{ResponseWriter: w, status: http.StatusOK}
+	m.next.ServeHTTP(rec, r)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rec.status >= http.StatusInternalServerError || rec.overflow {
+		delete(m.entries, key)
+		return
+	}
+	entry.done = true
+	entry.status = rec.status
+	entry.header = rec.header
+	entry.body = rec.body.Bytes()
+}
+
+// callerIdentity identifies who sent r: the API token it was authenticated
+// with, or else its session cookie, hashed so the session is not kept.
+// Requests with neither, as when authentication is disabled, share one
+// identity.
+func callerIdentity(r *http.Request) string {
+	if id := application.APITokenFromContext(r.Context()); id != "" {
+		return "token:" + id
+	}
+	if cookie, err := r.Cookie(sessionCookieName); err == nil && cookie.Value != "" {
+		sum := sha256.Sum256([]byte(cookie.Value))
+		return "session:" + hex.EncodeToString(sum[:])
+	}
+	return "anonymous"
+}
+
+// pruneLocked drops expired keys, including those of requests that never
+// finished. Callers must hold m.mu.
+func (m *IdempotencyMiddleware) pruneLocked(now time.Time) {
+	for key, entry := range m.entries {
+		if now.After(entry.expires) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// replayResponse writes a remembered response again, keeping the request ID
+// of the retry.
+func replayResponse(w http.ResponseWriter, entry *idempotentEntry) {
+	for name, values := range entry.header {
+		if name != requestIDHeader {
+			w.Header()[name] = values
+		}
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(entry.status)
+	_, _ = w.Write(entry.body)
+}
+
+// responseCapture passes a response through while keeping a copy of it.
+type responseCapture struct {
+	http.ResponseWriter
+	status      int
+	header      http.Header
+	body        bytes.Buffer
+	overflow    bool
+	wroteHeader bool
+}
+
+func (c *responseCapture) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.status = status
+		c.header = c.ResponseWriter.Header().Clone()
+		c.wroteHeader = true
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *responseCapture) Write(p []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if !c.overflow {
+		if c.body.Len()+len(p) > maxIdempotentResponseSize {
+			c.overflow = true
+			c.body.Reset()
+		} else {
+			c.body.Write(p)
+		}
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+func (c *responseCapture) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
@@ -0,0 +1,136 @@
+package driver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	var calls atomic.Int32
+	status := http.StatusCreated
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"call":%d,"body":%q}`, n, body)
+	})
+	m := NewIdempotencyMiddleware(next, time.Hour)
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	serve := func(method, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/channels", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("replays the first response", func(t *testing.T) {
+		calls.Store(0)
+		first := serve(http.MethodPost, "key-1", `{"name":"La 1"}`)
+		second := serve(http.MethodPost, "key-1", `{"name":"La 1"}`)
+
+		if calls.Load() != 1 {
+			t.Fatalf("expected the handler to run once, ran %d times", calls.Load())
+		}
+		if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+			t.Errorf("expected the first response to be replayed, got %d %s", second.Code, second.Body.String())
+		}
+		if second.Header().Get("Idempotent-Replayed") != "true" || second.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected replay headers %v", second.Header())
+		}
+	})
+
+	t.Run("rejects a key reused for another request", func(t *testing.T) {
+		serve(http.MethodPost, "key-2", `{"name":"La 1"}`)
+		rec := serve(http.MethodPost, "key-2", `{"name":"La 2"}`)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected status 422, got %d", rec.Code)
+		}
+	})
+
+	t.Run("does not remember server errors", func(t *testing.T) {
+		calls.Store(0)
+		status = http.StatusInternalServerError
+		serve(http.MethodPost, "key-3", `{}`)
+		status = http.StatusCreated
+		rec := serve(http.MethodPost, "key-3", `{}`)
+		if calls.Load() != 2 || rec.Code != http.StatusCreated {
+			t.Errorf("expected the retry to run the handler again, got %d calls and status %d", calls.Load(), rec.Code)
+		}
+	})
+
+	t.Run("forgets keys after the TTL", func(t *testing.T) {
+		calls.Store(0)
+		serve(http.MethodPost, "key-4", `{}`)
+		now = now.Add(2 * time.Hour)
+		serve(http.MethodPost, "key-4", `{}`)
+		if calls.Load() != 2 {
+			t.Errorf("expected an expired key to run the handler again, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("ignores requests without a key or with other methods", func(t *testing.T) {
+		calls.Store(0)
+		serve(http.MethodPost, "", `{}`)
+		serve(http.MethodPost, "", `{}`)
+		serve(http.MethodPut, "key-5", `{}`)
+		serve(http.MethodPut, "key-5", `{}`)
+		if calls.Load() != 4 {
+			t.Errorf("expected every request to reach the handler, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("rejects overlong keys", func(t *testing.T) {
+		rec := serve(http.MethodPost, strings.Repeat("k", 256), `{}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+	t.Run("scopes keys to the caller", func(t *testing.T) {
+		calls.Store(0)
+		serveAs := func(setup func(r *http.Request)) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/channels", strings.NewReader(`{"name":"La 1"}`))
+			req.Header.Set("Idempotency-Key", "key-shared")
+			setup(req)
+			rec := httptest.NewRecorder()
+			m.ServeHTTP(rec, req)
+			return rec
+		}
+		withToken := func(id string) func(r *http.Request) {
+			return func(r *http.Request) {
+				*r = *r.WithContext(application.WithAPIToken(r.Context(), id))
+			}
+		}
+		withSession := func(session string) func(r *http.Request) {
+			return func(r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: sessionCookieName, Value: session})
+			}
+		}
+
+		serveAs(withToken("tok-a"))
+		for _, setup := range []func(r *http.Request){withToken("tok-b"), withSession("session-a"), withSession("session-b")} {
+			if rec := serveAs(setup); rec.Header().Get("Idempotent-Replayed") != "" {
+				t.Errorf("expected another caller's response not to be replayed, got %s", rec.Body.String())
+			}
+		}
+		if calls.Load() != 4 {
+			t.Errorf("expected the handler to run once per caller, ran %d times", calls.Load())
+		}
+		if rec := serveAs(withSession("session-a")); rec.Header().Get("Idempotent-Replayed") != "true" {
+			t.Error("expected the same session's retry to be replayed")
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/acesearch"
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/m3u"
+	"github.com/alorle/iptv-manager/internal/tvheadend"
+)
+
+// maxImportBodySize caps uploaded playlists.
+const maxImportBodySize = 10 << 20
+
+// errInvalidImportURL is returned for playlist URLs that are not absolute
+// http(s) URLs.
+var errInvalidImportURL = errors.New("url must be an absolute http or https URL")
+
+// ImportHTTPHandler bootstraps channels and streams from external playlists.
+type ImportHTTPHandler struct {
+	service *application.ImportService
+}
+
+// NewImportHTTPHandler creates a new HTTP handler for playlist imports.
+func NewImportHTTPHandler(service *application.ImportService) *ImportHTTPHandler {
+	return &ImportHTTPHandler{service: service}
+}
+
+// importURLRequest represents the JSON body for importing a remote playlist.
+type importURLRequest struct {
+	URL string `json:"url"`
+}
+
+// importSummaryResponse represents the outcome of an import in JSON format.
+type importSummaryResponse struct {
+	ChannelsCreated int  `json:"channels_created"`
+	ChannelsUpdated int  `json:"channels_updated"`
+	StreamsCreated  int  `json:"streams_created"`
+	StreamsSkipped  int  `json:"streams_skipped"`
+	DryRun          bool `json:"dry_run,omitempty"`
+}
+
+// ServeHTTP handles POST /import/m3u, POST /import/acestream-search and
+// POST /import/tvheadend.
+//
+// The file is taken from a "file" upload or "url" field of a multipart
+// form, the "url" field of a JSON body, or otherwise the raw request body.
+// With ?dry_run=true nothing is saved and the summary reports what the
+// import would change.
+func (h *ImportHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var format application.ImportFormat
+	switch name := strings.TrimPrefix(r.URL.Path, "/import/"); application.ImportFormat(name) {
+	case application.ImportFormatM3U, application.ImportFormatAcestreamSearch, application.ImportFormatTvheadend:
+		format = application.ImportFormat(name)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "dry_run must be a boolean")
+			return
+		}
+		dryRun = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBodySize)
+
+	var (
+		summary application.ImportSummary
+		err     error
+	)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(maxImportBodySize); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid multipart form")
+			return
+		}
+		if rawURL := r.FormValue("url"); rawURL != "" {
+			summary, err = h.importURL(r, format, rawURL, dryRun)
+			break
+		}
+		file, _, ferr := r.FormFile("file")
+		if ferr != nil {
+			writeError(w, http.StatusBadRequest, "missing file or url")
+			return
+		}
+		defer file.Close()
+		summary, err = h.service.Import(r.Context(), format, file, dryRun)
+	case "application/json":
+		var req importURLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		summary, err = h.importURL(r, format, req.URL, dryRun)
+	default:
+		summary, err = h.service.Import(r.Context(), format, r.Body, dryRun)
+	}
+
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, errInvalidImportURL):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.As(err, &maxBytesErr):
+			writeError(w, http.StatusRequestEntityTooLarge, "playlist too large")
+		case errors.Is(err, m3u.ErrNotPlaylist), errors.Is(err, acesearch.ErrInvalidOutput), errors.Is(err, tvheadend.ErrInvalidExport):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, application.ErrPlaylistUnavailable):
+			writeError(w, http.StatusBadGateway, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, importSummaryResponse{
+		ChannelsCreated: summary.ChannelsCreated,
+		ChannelsUpdated: summary.ChannelsUpdated,
+		StreamsCreated:  summary.StreamsCreated,
+		StreamsSkipped:  summary.StreamsSkipped,
+		DryRun:          summary.DryRun,
+	})
+}
+
+// importURL validates rawURL and imports the file it points at.
+func (h *ImportHTTPHandler) importURL(r *http.Request, format application.ImportFormat, rawURL string, dryRun bool) (application.ImportSummary, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return application.ImportSummary{}, errInvalidImportURL
+	}
+	return h.service.ImportFromURL(r.Context(), format, u.String(), dryRun)
+}
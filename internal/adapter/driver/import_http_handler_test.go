@@ -0,0 +1,174 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+type mockPlaylistFetcher struct {
+	data []byte
+	err  error
+}
+
+func (m *mockPlaylistFetcher) FetchPlaylist(ctx context.Context, url string) ([]byte, error) {
+	return m.data, m.err
+}
+
+func TestImportHTTPHandler(t *testing.T) {
+	playlist := "#EXTM3U\n#EXTINF:-1,HBO\nacestream://6861736831000000000000000000000000000000\n#EXTINF:-1,Web\nhttp://example.com/live.m3u8\n"
+
+	newHandler := func(fetcher *mockPlaylistFetcher) (*ImportHTTPHandler, *[]stream.Stream) {
+		var saved []stream.Stream
+		streamRepo := &mockStreamRepository{
+			saveFunc: func(ctx context.Context, s stream.Stream) error {
+				saved = append(saved, s)
+				return nil
+			},
+		}
+		service := application.NewImportService(&mockChannelRepository{}, streamRepo, fetcher)
+		return NewImportHTTPHandler(service), &saved
+	}
+
+	decodeSummary := func(t *testing.T, rec *httptest.ResponseRecorder) importSummaryResponse {
+		t.Helper()
+		var resp importSummaryResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("POST /import/m3u imports a raw playlist body", func(t *testing.T) {
+		handler, saved := newHandler(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/import/m3u", strings.NewReader(playlist))
+		req.Header.Set("Content-Type", "audio/x-mpegurl")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		want := importSummaryResponse{ChannelsCreated: 1, StreamsCreated: 1, StreamsSkipped: 1}
+		if got := decodeSummary(t, rec); got != want {
+			t.Errorf("summary = %+v, want %+v", got, want)
+		}
+		if len(*saved) != 1 || (*saved)[0].InfoHash() != "6861736831000000000000000000000000000000" {
+			t.Errorf("expected hash1 to be saved, got %v", *saved)
+		}
+	})
+
+	t.Run("POST /import/m3u imports an uploaded file", func(t *testing.T) {
+		handler, _ := newHandler(nil)
+
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, _ := mw.CreateFormFile("file", "list.m3u")
+		_, _ = fw.Write([]byte(playlist))
+		_ = mw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/import/m3u", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if got := decodeSummary(t, rec); got.StreamsCreated != 1 {
+			t.Errorf("expected 1 stream created, got %+v", got)
+		}
+	})
+
+	t.Run("POST /import/m3u fetches a playlist URL", func(t *testing.T) {
+		handler, _ := newHandler(&mockPlaylistFetcher{data: []byte(playlist)})
+
+		req := httptest.NewRequest(http.MethodPost, "/import/m3u", strings.NewReader(`{"url":"http://example.com/list.m3u"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if got := decodeSummary(t, rec); got.StreamsCreated != 1 {
+			t.Errorf("expected 1 stream created, got %+v", got)
+		}
+	})
+
+	t.Run("POST /import/tvheadend dry run saves nothing", func(t *testing.T) {
+		handler, saved := newHandler(nil)
+
+		export := `[{"iptv_url":"acestream://6861736831000000000000000000000000000000","iptv_sname":"HBO"}]`
+		req := httptest.NewRequest(http.MethodPost, "/import/tvheadend?dry_run=true", strings.NewReader(export))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		want := importSummaryResponse{ChannelsCreated: 1, StreamsCreated: 1, DryRun: true}
+		if got := decodeSummary(t, rec); got != want {
+			t.Errorf("summary = %+v, want %+v", got, want)
+		}
+		if len(*saved) != 0 {
+			t.Errorf("expected nothing saved, got %v", *saved)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		fetcher     *mockPlaylistFetcher
+		method      string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{"non-playlist body", nil, http.MethodPost, "text/plain", "hello", http.StatusBadRequest},
+		{"non-http url", nil, http.MethodPost, "application/json", `{"url":"file:///etc/passwd"}`, http.StatusBadRequest},
+		{"invalid json", nil, http.MethodPost, "application/json", `{`, http.StatusBadRequest},
+		{"unreachable url", &mockPlaylistFetcher{err: errors.New("timeout")}, http.MethodPost, "application/json", `{"url":"http://example.com/list.m3u"}`, http.StatusBadGateway},
+		{"method not allowed", nil, http.MethodGet, "", "", http.StatusMethodNotAllowed},
+	}
+
+	t.Run("rejects invalid exports, options and formats", func(t *testing.T) {
+		for target, wantStatus := range map[string]int{
+			"/import/tvheadend":         http.StatusBadRequest,
+			"/import/acestream-search":  http.StatusBadRequest,
+			"/import/m3u?dry_run=maybe": http.StatusBadRequest,
+			"/import/xspf":              http.StatusNotFound,
+		} {
+			handler, _ := newHandler(nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, target, strings.NewReader("not json")))
+			if rec.Code != wantStatus {
+				t.Errorf("%s: expected status %d, got %d", target, wantStatus, rec.Code)
+			}
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := newHandler(tt.fetcher)
+
+			req := httptest.NewRequest(tt.method, "/import/m3u", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// errInvalidFields indicates a fields query parameter naming an unknown field.
+var errInvalidFields = errors.New("invalid fields")
+
+// parseListOptions reads the sort, page and per_page query parameters of a
+// listing. Listings are returned whole unless a page is requested.
+func parseListOptions(r *http.Request) (application.ListOptions, error) {
+	query := r.URL.Query()
+	opts := application.ListOptions{Sort: query.Get("sort")}
+
+	for name, dst := range map[string]*int{"page": &opts.Page, "per_page": &opts.PerPage} {
+		s := query.Get(name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return application.ListOptions{}, application.ErrInvalidPagination
+		}
+		*dst = n
+	}
+	if opts.PerPage > 0 && opts.Page == 0 {
+		opts.Page = 1
+	}
+	return opts, nil
+}
+
+// parseFields reads the comma-separated fields query parameter, returning nil
+// when every field is wanted. Each field must be a JSON field of T.
+func parseFields[T any](r *http.Request) ([]string, error) {
+	s := r.URL.Query().Get("fields")
+	if s == "" {
+		return nil, nil
+	}
+
+	known := make(map[string]bool)
+	t := reflect.TypeFor[T]()
+	for i := range t.NumField() {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			known[name] = true
+		}
+	}
+
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !known[f] {
+			return nil, fmt.Errorf("%w: unknown field %q", errInvalidFields, f)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// hasField reports whether fields, as returned by parseFields, includes any of names.
+func hasField(fields []string, names ...string) bool {
+	if fields == nil {
+		return true
+	}
+	for _, f := range fields {
+		for _, name := range names {
+			if f == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeList writes the responses of a page of a listing as a JSON array,
+// keeping only the given fields of each. The total number of items is sent
+// in X-Total-Count and links to the neighbouring pages in Link.
+func writeList[T, R any](w http.ResponseWriter, r *http.Request, page application.ListPage[T], items []R, fields []string) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if page.Page > 0 {
+		var links []string
+		if page.Page > 1 {
+			links = append(links, pageLink(r.URL, page.Page-1, page.PerPage, "prev"))
+		}
+		if page.Page*page.PerPage < page.Total {
+			links = append(links, pageLink(r.URL, page.Page+1, page.PerPage, "next"))
+		}
+		if len(links) > 0 {
+			w.Header().Set("Link", strings.Join(links, ", "))
+		}
+	}
+
+	if fields == nil {
+		writeJSON(w, http.StatusOK, items)
+		return
+	}
+
+	selected := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		selected[i] = make(map[string]json.RawMessage, len(fields))
+		for _, f := range fields {
+			if v, ok := all[f]; ok {
+				selected[i][f] = v
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, selected)
+}
+
+// pageLink formats an RFC 8288 link to another page of the same listing.
+// The link is relative to the request, which may have been received under a
+// path prefix stripped before reaching the handler.
+func pageLink(u *url.URL, page, perPage int, rel string) string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+	return fmt.Sprintf("<?%s>; rel=%q", query.Encode(), rel)
+}
+
+// writeListError maps listing option errors to HTTP responses.
+func writeListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, application.ErrInvalidSortField), errors.Is(err, application.ErrInvalidPagination), errors.Is(err, errInvalidFields):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
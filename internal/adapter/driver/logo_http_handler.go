@@ -0,0 +1,64 @@
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/logo"
+)
+
+// logoContentSecurityPolicy stops scripts in an SVG logo from running when
+// the logo is opened directly, as logos come from untrusted playlists.
+const logoContentSecurityPolicy = "default-src 'none'; style-src 'unsafe-inline'; sandbox"
+
+// LogoHTTPHandler serves locally cached channel logos.
+type LogoHTTPHandler struct {
+	service *application.LogoService
+}
+
+// NewLogoHTTPHandler creates a new HTTP handler for channel logos.
+func NewLogoHTTPHandler(service *application.LogoService) *LogoHTTPHandler {
+	return &LogoHTTPHandler{service: service}
+}
+
+// ServeHTTP handles GET /logos/{key}.png
+func (h *LogoHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	key, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/logos/"), ".png")
+	if !ok || !logo.ValidKey(key) {
+		writeError(w, http.StatusNotFound, logo.ErrLogoNotFound.Error())
+		return
+	}
+
+	data, err := h.service.GetLogo(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, logo.ErrLogoNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	// Logos keep the upstream format; the .png suffix is only a stable URL.
+	contentType := http.DetectContentType(data)
+	if bytes.Contains(data[:min(len(data), 512)], []byte("<svg")) {
+		contentType = "image/svg+xml"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Security-Policy", logoContentSecurityPolicy)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
@@ -0,0 +1,85 @@
+package driver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/logo"
+)
+
+// mockLogoStore is an in-memory driven.LogoStore.
+type mockLogoStore struct {
+	data map[string][]byte
+}
+
+func (m *mockLogoStore) Save(ctx context.Context, key, sourceURL string, data []byte) error {
+	m.data[key] = data
+	return nil
+}
+
+func (m *mockLogoStore) Load(ctx context.Context, key string) ([]byte, error) {
+	if d, ok := m.data[key]; ok {
+		return d, nil
+	}
+	return nil, logo.ErrLogoNotFound
+}
+
+func (m *mockLogoStore) SourceURL(ctx context.Context, key string) (string, error) {
+	if _, ok := m.data[key]; ok {
+		return "http://upstream/logo", nil
+	}
+	return "", logo.ErrLogoNotFound
+}
+
+type mockLogoFetcher struct{}
+
+func (mockLogoFetcher) FetchLogo(ctx context.Context, url string) ([]byte, error) {
+	return nil, nil
+}
+
+func TestLogoHTTPHandler(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 16)...)
+	store := &mockLogoStore{data: map[string][]byte{logo.Key("hbo.es"): png}}
+	handler := NewLogoHTTPHandler(application.NewLogoService(mockLogoFetcher{}, store, newProbeTestLogger()))
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+	}{
+		{"serves cached logo", http.MethodGet, "/logos/" + logo.Key("hbo.es") + ".png", http.StatusOK},
+		{"unknown logo", http.MethodGet, "/logos/" + logo.Key("cnn.us") + ".png", http.StatusNotFound},
+		{"invalid key", http.MethodGet, "/logos/..%2Fdb.png", http.StatusNotFound},
+		{"missing suffix", http.MethodGet, "/logos/" + logo.Key("hbo.es"), http.StatusNotFound},
+		{"method not allowed", http.MethodPost, "/logos/" + logo.Key("hbo.es") + ".png", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusOK {
+				if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+					t.Errorf("expected Content-Type image/png, got %q", ct)
+				}
+				if csp := rec.Header().Get("Content-Security-Policy"); csp != logoContentSecurityPolicy {
+					t.Errorf("expected Content-Security-Policy %q, got %q", logoContentSecurityPolicy, csp)
+				}
+				if nosniff := rec.Header().Get("X-Content-Type-Options"); nosniff != "nosniff" {
+					t.Errorf("expected X-Content-Type-Options nosniff, got %q", nosniff)
+				}
+				if rec.Body.Len() != len(png) {
+					t.Errorf("expected %d bytes, got %d", len(png), rec.Body.Len())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package driver
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec is the OpenAPI document of the versioned API. Request
+// validation is driven by the same document, so the two cannot drift apart.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// OpenAPIHTTPHandler serves the OpenAPI document of the versioned API.
+type OpenAPIHTTPHandler struct{}
+
+// NewOpenAPIHTTPHandler creates a new OpenAPI HTTP handler.
+func NewOpenAPIHTTPHandler() *OpenAPIHTTPHandler {
+	return &OpenAPIHTTPHandler{}
+}
+
+// ServeHTTP handles GET /openapi.json
+func (h *OpenAPIHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(openAPISpec)
+}
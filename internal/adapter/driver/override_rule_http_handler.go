@@ -0,0 +1,197 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/rule"
+)
+
+// OverrideRuleHTTPHandler handles HTTP requests for override rules.
+type OverrideRuleHTTPHandler struct {
+	service *application.OverrideRuleService
+}
+
+// NewOverrideRuleHTTPHandler creates a new HTTP handler for override rules.
+func NewOverrideRuleHTTPHandler(service *application.OverrideRuleService) *OverrideRuleHTTPHandler {
+	return &OverrideRuleHTTPHandler{service: service}
+}
+
+// ruleMatchJSON represents what a rule matches in JSON format.
+type ruleMatchJSON struct {
+	NamePattern string `json:"name_pattern,omitempty"`
+	Group       string `json:"group,omitempty"`
+	Source      string `json:"source,omitempty"`
+}
+
+// ruleActionJSON represents what a rule does in JSON format.
+type ruleActionJSON struct {
+	Rename  string `json:"rename,omitempty"`
+	Group   string `json:"group,omitempty"`
+	TVGID   string `json:"tvg_id,omitempty"`
+	Disable bool   `json:"disable,omitempty"`
+}
+
+// ruleRequest represents the JSON body for creating or previewing a rule.
+type ruleRequest struct {
+	Match  ruleMatchJSON  `json:"match"`
+	Action ruleActionJSON `json:"action"`
+}
+
+// ruleResponse represents a rule in JSON format.
+type ruleResponse struct {
+	ID        string         `json:"id"`
+	Match     ruleMatchJSON  `json:"match"`
+	Action    ruleActionJSON `json:"action"`
+	CreatedAt string         `json:"created_at"`
+}
+
+// ruleSubjectResponse represents the rule-visible part of a playlist entry.
+type ruleSubjectResponse struct {
+	ChannelName string `json:"channel_name"`
+	Group       string `json:"group,omitempty"`
+	Source      string `json:"source,omitempty"`
+	TVGID       string `json:"tvg_id"`
+}
+
+// ruleChangeResponse represents a previewed change in JSON format.
+type ruleChangeResponse struct {
+	InfoHash string              `json:"info_hash"`
+	Before   ruleSubjectResponse `json:"before"`
+	After    ruleSubjectResponse `json:"after"`
+	Disabled bool                `json:"disabled,omitempty"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *OverrideRuleHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/override-rules")
+
+	// GET /override-rules - list all rules in the order they apply
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	// POST /override-rules - create a rule
+	if r.Method == http.MethodPost && path == "" {
+		h.handleCreate(w, r)
+		return
+	}
+
+	// POST /override-rules/preview - show what a rule would change
+	if r.Method == http.MethodPost && path == "/preview" {
+		h.handlePreview(w, r)
+		return
+	}
+
+	// DELETE /override-rules/{id} - delete a rule
+	if r.Method == http.MethodDelete && path != "" {
+		h.handleDelete(w, r, strings.TrimPrefix(path, "/"))
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func (req ruleRequest) toDomain() (rule.Match, rule.Action) {
+	return rule.Match{NamePattern: req.Match.NamePattern, Group: req.Match.Group, Source: req.Match.Source},
+		rule.Action{Rename: req.Action.Rename, Group: req.Action.Group, TVGID: req.Action.TVGID, Disable: req.Action.Disable}
+}
+
+func toRuleResponse(r rule.Rule) ruleResponse {
+	m, a := r.Match(), r.Action()
+	return ruleResponse{
+		ID:        r.ID(),
+		Match:     ruleMatchJSON{NamePattern: m.NamePattern, Group: m.Group, Source: m.Source},
+		Action:    ruleActionJSON{Rename: a.Rename, Group: a.Group, TVGID: a.TVGID, Disable: a.Disable},
+		CreatedAt: formatOptionalTime(r.CreatedAt()),
+	}
+}
+
+func toRuleSubjectResponse(s rule.Subject) ruleSubjectResponse {
+	return ruleSubjectResponse{ChannelName: s.ChannelName, Group: s.Group, Source: s.Source, TVGID: s.TVGID}
+}
+
+// writeRuleError maps rule errors to HTTP responses.
+func writeRuleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, rule.ErrNoMatcher), errors.Is(err, rule.ErrNoAction), errors.Is(err, rule.ErrInvalidPattern):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, rule.ErrRuleNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// handleList handles GET /override-rules
+func (h *OverrideRuleHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListRules(r.Context())
+	if err != nil {
+		writeRuleError(w, err)
+		return
+	}
+
+	response := make([]ruleResponse, len(rules))
+	for i, ru := range rules {
+		response[i] = toRuleResponse(ru)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleCreate handles POST /override-rules
+func (h *OverrideRuleHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req ruleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	match, action := req.toDomain()
+	ru, err := h.service.CreateRule(r.Context(), match, action)
+	if err != nil {
+		writeRuleError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toRuleResponse(ru))
+}
+
+// handlePreview handles POST /override-rules/preview
+func (h *OverrideRuleHTTPHandler) handlePreview(w http.ResponseWriter, r *http.Request) {
+	var req ruleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	match, action := req.toDomain()
+	changes, err := h.service.PreviewRule(r.Context(), match, action)
+	if err != nil {
+		writeRuleError(w, err)
+		return
+	}
+
+	response := make([]ruleChangeResponse, len(changes))
+	for i, c := range changes {
+		response[i] = ruleChangeResponse{
+			InfoHash: c.InfoHash,
+			Before:   toRuleSubjectResponse(c.Before),
+			After:    toRuleSubjectResponse(c.After),
+			Disabled: c.Disabled,
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleDelete handles DELETE /override-rules/{id}
+func (h *OverrideRuleHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteRule(r.Context(), id); err != nil {
+		writeRuleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
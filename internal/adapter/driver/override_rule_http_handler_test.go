@@ -0,0 +1,136 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/rule"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// mockRuleRepository is an in-memory implementation for testing.
+type mockRuleRepository struct {
+	rules map[string]rule.Rule
+}
+
+func (m *mockRuleRepository) Save(ctx context.Context, r rule.Rule) error {
+	m.rules[r.ID()] = r
+	return nil
+}
+
+func (m *mockRuleRepository) FindAll(ctx context.Context) ([]rule.Rule, error) {
+	rules := make([]rule.Rule, 0, len(m.rules))
+	for _, r := range m.rules {
+		rules = append(rules, r)
+	}
+	rule.Sort(rules)
+	return rules, nil
+}
+
+func (m *mockRuleRepository) FindByID(ctx context.Context, id string) (rule.Rule, error) {
+	r, ok := m.rules[id]
+	if !ok {
+		return rule.Rule{}, rule.ErrRuleNotFound
+	}
+	return r, nil
+}
+
+func (m *mockRuleRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.rules[id]; !ok {
+		return rule.ErrRuleNotFound
+	}
+	delete(m.rules, id)
+	return nil
+}
+
+func newOverrideRuleTestHandler() *OverrideRuleHTTPHandler {
+	hd, _ := stream.NewStream("6162633132330000000000000000000000000000", "DAZN 1 HD", stream.SourceNewEra)
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{hd}, nil
+		},
+	}
+	playlist := application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+	ruleRepo := &mockRuleRepository{rules: make(map[string]rule.Rule)}
+	playlist.SetRuleRepository(ruleRepo)
+	return NewOverrideRuleHTTPHandler(application.NewOverrideRuleService(ruleRepo, playlist))
+}
+
+func TestOverrideRuleHTTPHandler(t *testing.T) {
+	handler := newOverrideRuleTestHandler()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"invalid body", `{`, http.StatusBadRequest},
+		{"no matcher", `{"action":{"disable":true}}`, http.StatusBadRequest},
+		{"no action", `{"match":{"group":"Sports"}}`, http.StatusBadRequest},
+		{"invalid pattern", `{"match":{"name_pattern":"("},"action":{"disable":true}}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := do(http.MethodPost, "/override-rules", tt.body); rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	body := `{"match":{"name_pattern":"^(.*) HD$"},"action":{"rename":"$1","tvg_id":"dazn1.es"}}`
+
+	rec := do(http.MethodPost, "/override-rules/preview", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("preview: expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var changes []ruleChangeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&changes); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(changes) != 1 || changes[0].InfoHash != "6162633132330000000000000000000000000000" || changes[0].After.ChannelName != "DAZN 1" || changes[0].After.TVGID != "dazn1.es" {
+		t.Errorf("unexpected preview %+v", changes)
+	}
+
+	rec = do(http.MethodPost, "/override-rules", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created ruleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID == "" || created.Match.NamePattern != "^(.*) HD$" || created.Action.Rename != "$1" {
+		t.Errorf("unexpected rule %+v", created)
+	}
+
+	rec = do(http.MethodGet, "/override-rules", "")
+	var rules []ruleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&rules); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(rules) != 1 || rules[0].ID != created.ID {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+
+	if rec := do(http.MethodDelete, "/override-rules/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: expected status 204, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/override-rules/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("delete missing: expected status 404, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/override-rules", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rec.Code)
+	}
+}
@@ -1,14 +1,39 @@
 package driver
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/favorite"
+	"github.com/alorle/iptv-manager/internal/playlist"
+	"github.com/alorle/iptv-manager/internal/user"
 )
 
+// playlistMediaTypes maps the media types accepted in the Accept header to
+// playlist formats. Enigma2 bouquets have no media type of their own and are
+// only available with ?format=enigma2.
+var playlistMediaTypes = map[string]playlist.Format{
+	"audio/mpegurl":                 playlist.M3U,
+	"audio/x-mpegurl":               playlist.M3U,
+	"application/x-mpegurl":         playlist.ExtendedM3U,
+	"application/vnd.apple.mpegurl": playlist.ExtendedM3U,
+	"application/json":              playlist.JSON,
+}
+
 // PlaylistHTTPHandler handles HTTP requests for playlist generation.
 type PlaylistHTTPHandler struct {
-	service *application.PlaylistService
+	service   *application.PlaylistService
+	users     *application.UserService
+	favorites *application.FavoriteService
+	auth      *application.AuthService
 }
 
 // NewPlaylistHTTPHandler creates a new HTTP handler for playlists.
@@ -16,7 +41,37 @@ func NewPlaylistHTTPHandler(service *application.PlaylistService) *PlaylistHTTPH
 	return &PlaylistHTTPHandler{service: service}
 }
 
-// ServeHTTP handles GET /playlist.m3u
+// SetUserService enables GET /playlist/{token}.m3u, which serves the
+// playlist of the user owning the playlist token.
+func (h *PlaylistHTTPHandler) SetUserService(users *application.UserService) {
+	h.users = users
+}
+
+// SetFavoriteService enables GET /playlist/fav/{id}.m3u, which serves the
+// playlist of a favorite list, and GET /playlist/fav/{id}.xml, which serves
+// the guide of a list that has one. List IDs are slugs of their names, so
+// these routes rely on the auth middleware to keep them private.
+func (h *PlaylistHTTPHandler) SetFavoriteService(favorites *application.FavoriteService) {
+	h.favorites = favorites
+}
+
+// SetAuthService makes GET /playlist.m3u apply the playlist preferences of
+// the API token the request was authenticated with.
+func (h *PlaylistHTTPHandler) SetAuthService(auth *application.AuthService) {
+	h.auth = auth
+}
+
+// ServeHTTP handles GET /playlist.m3u, GET /playlist/tag/{tag}.m3u, with a
+// user service GET /playlist/{token}.m3u and, with a favorite service,
+// GET /playlist/fav/{id}.m3u and GET /playlist/fav/{id}.xml. The output
+// format is chosen with the format query parameter (m3u, m3u8, json or
+// enigma2) or, failing that, the Accept header. M3U is served when neither
+// selects a format; with an auth service, the format in the playlist
+// preferences of the request's API token comes before the Accept header.
+// The min_health query parameter, from 0 to 1, leaves out
+// streams less healthy than that instead of the configured minimum.
+// Playlists carry an ETag, and a request whose If-None-Match lists it gets a
+// 304 Not Modified.
 func (h *PlaylistHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Only GET method is allowed
 	if r.Method != http.MethodGet {
@@ -24,15 +79,162 @@ func (h *PlaylistHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Generate M3U playlist using the request's Host header
-	m3u, err := h.service.GenerateM3U(r.Context(), r.Host)
+	if id, ok := strings.CutPrefix(r.URL.Path, "/playlist/fav/"); ok && h.favorites != nil {
+		if id, ok := strings.CutSuffix(id, ".xml"); ok {
+			h.serveFavoriteGuide(w, r, id)
+			return
+		}
+	}
+
+	ctx, err := withMinHealthParam(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := playlist.M3U
+	if name := r.URL.Query().Get("format"); name != "" {
+		f, err := playlist.ByName(name)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		format = f
+	} else if f, ok := acceptedPlaylistFormat(r.Header.Get("Accept")); ok {
+		format = f
+	}
+
+	if r.URL.Path == "/playlist.m3u" && h.auth != nil {
+		prefs, ok, err := h.auth.RequestPlaylistPrefs(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		if ok {
+			ctx = application.WithPlaylistPrefs(ctx, prefs)
+			if f, err := playlist.ByName(prefs.Format()); err == nil && r.URL.Query().Get("format") == "" {
+				format = f
+			}
+		}
+	}
+
+	// Generate the playlist using the request's Host header
+	var data []byte
+	if tag, ok := strings.CutPrefix(r.URL.Path, "/playlist/tag/"); ok {
+		tag, ok = strings.CutSuffix(tag, ".m3u")
+		if !ok {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		data, err = h.service.GenerateForTag(ctx, publicBaseURL(r), format, tag)
+	} else if id, ok := strings.CutPrefix(r.URL.Path, "/playlist/fav/"); ok {
+		id, ok = strings.CutSuffix(id, ".m3u")
+		if !ok || h.favorites == nil {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		data, err = h.favorites.Playlist(ctx, id, publicBaseURL(r), format)
+	} else if token, ok := strings.CutPrefix(r.URL.Path, "/playlist/"); ok {
+		token, ok = strings.CutSuffix(token, ".m3u")
+		if !ok || h.users == nil {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		data, err = h.users.Playlist(ctx, token, publicBaseURL(r), format)
+	} else {
+		data, err = h.service.Generate(ctx, publicBaseURL(r), format)
+	}
+	if errors.Is(err, user.ErrUserNotFound) || errors.Is(err, channel.ErrInvalidTag) || errors.Is(err, favorite.ErrListNotFound) {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
-	// Write M3U response with proper content type
-	w.Header().Set("Content-Type", "audio/mpegurl")
+	// Players polling the playlist get a 304 while it is unchanged
+	etag := playlistETag(data)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", h.service.LastModified().UTC().Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "no-cache")
+	// Signed links and per-token preferences depend on who asked
+	w.Header().Add("Vary", "Accept, Authorization, Cookie")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	if format == playlist.Enigma2 {
+		w.Header().Set("Content-Disposition", `attachment; filename="userbouquet.iptv-manager.tv"`)
+	}
 	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(m3u))
+	_, _ = w.Write(data)
+}
+
+// withMinHealthParam returns the request's context carrying the minimum
+// health set with the min_health query parameter, if any.
+func withMinHealthParam(r *http.Request) (context.Context, error) {
+	v := r.URL.Query().Get("min_health")
+	if v == "" {
+		return r.Context(), nil
+	}
+	parsed, err := strconv.ParseFloat(v, 64)
+	if err != nil || parsed < 0 || parsed > 1 {
+		return nil, errors.New("min_health must be a number in [0, 1]")
+	}
+	return application.WithMinHealth(r.Context(), parsed), nil
+}
+
+// serveFavoriteGuide handles GET /playlist/fav/{id}.xml
+func (h *PlaylistHTTPHandler) serveFavoriteGuide(w http.ResponseWriter, r *http.Request, id string) {
+	doc, err := h.favorites.Guide(r.Context(), id)
+	if errors.Is(err, favorite.ErrListNotFound) {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(doc)
+}
+
+// playlistETag returns a weak entity tag for a playlist, weak because the
+// compression middleware may re-encode the body.
+func playlistETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedPlaylistFormat returns the format of the first media type in the
+// Accept header that names one. Quality values are not weighed; players
+// list the type they want first.
+func acceptedPlaylistFormat(accept string) (playlist.Format, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if f, ok := playlistMediaTypes[mediaType]; ok {
+			return f, true
+		}
+	}
+	return nil, false
 }
@@ -3,20 +3,24 @@ package driver
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/probe"
 	"github.com/alorle/iptv-manager/internal/stream"
 )
 
 func TestPlaylistHTTPHandler_ServeHTTP(t *testing.T) {
 	t.Run("GET /playlist.m3u returns M3U playlist with streams", func(t *testing.T) {
-		st1, _ := stream.NewStream("abc123", "Channel1", "")
-		st2, _ := stream.NewStream("def456", "Channel2", "")
+		st1, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+		st2, _ := stream.NewStream("6465663435360000000000000000000000000000", "Channel2", "")
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
 				return []stream.Stream{st1, st2}, nil
@@ -43,27 +47,59 @@ func TestPlaylistHTTPHandler_ServeHTTP(t *testing.T) {
 		body := rec.Body.String()
 
 		// Check header
-		if !strings.HasPrefix(body, "#EXTM3U\n") {
-			t.Error("M3U playlist should start with #EXTM3U header")
+		if !strings.HasPrefix(body, "#EXTM3U url-tvg=\"http://localhost:8080/epg.xml\"\n") {
+			t.Error("M3U playlist should start with #EXTM3U header pointing at the XMLTV guide")
 		}
 
 		// Check first stream
-		if !strings.Contains(body, `#EXTINF:-1 tvg-id="Channel1",Channel1 - abc123`) {
+		if !strings.Contains(body, `#EXTINF:-1 tvg-id="Channel1",Channel1 - 6162633132330000000000000000000000000000`) {
 			t.Error("M3U playlist should contain first stream metadata")
 		}
-		if !strings.Contains(body, "http://localhost:8080/ace/getstream?id=abc123") {
+		if !strings.Contains(body, "http://localhost:8080/ace/getstream?id=6162633132330000000000000000000000000000") {
 			t.Error("M3U playlist should contain first stream URL")
 		}
 
 		// Check second stream
-		if !strings.Contains(body, `#EXTINF:-1 tvg-id="Channel2",Channel2 - def456`) {
+		if !strings.Contains(body, `#EXTINF:-1 tvg-id="Channel2",Channel2 - 6465663435360000000000000000000000000000`) {
 			t.Error("M3U playlist should contain second stream metadata")
 		}
-		if !strings.Contains(body, "http://localhost:8080/ace/getstream?id=def456") {
+		if !strings.Contains(body, "http://localhost:8080/ace/getstream?id=6465663435360000000000000000000000000000") {
 			t.Error("M3U playlist should contain second stream URL")
 		}
 	})
 
+	t.Run("GET /playlist.m3u builds URLs from the forwarding headers of trusted proxies", func(t *testing.T) {
+		st, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				return []stream.Stream{st}, nil
+			},
+		}
+		service := application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+		handler, err := NewPublicURLMiddleware("", NewPlaylistHTTPHandler(service))
+		if err != nil {
+			t.Fatalf("NewPublicURLMiddleware() error = %v", err)
+		}
+		handler.SetTrustedProxies([]netip.Prefix{netip.MustParsePrefix("192.0.2.1/32")})
+
+		req := httptest.NewRequest(http.MethodGet, "/playlist.m3u", nil)
+		req.Host = "localhost:8080"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "home.example.com")
+		req.Header.Set("X-Forwarded-Prefix", "/iptv/")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		body := rec.Body.String()
+		if !strings.HasPrefix(body, "#EXTM3U url-tvg=\"https://home.example.com/iptv/epg.xml\"\n") {
+			t.Errorf("expected the guide URL behind the proxy, got %q", body)
+		}
+		if !strings.Contains(body, "https://home.example.com/iptv/ace/getstream?id=6162633132330000000000000000000000000000") {
+			t.Errorf("expected the stream URL behind the proxy, got %q", body)
+		}
+	})
+
 	t.Run("GET /playlist.m3u returns empty playlist when no streams exist", func(t *testing.T) {
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
@@ -84,7 +120,7 @@ func TestPlaylistHTTPHandler_ServeHTTP(t *testing.T) {
 		}
 
 		body := rec.Body.String()
-		if body != "#EXTM3U\n" {
+		if body != "#EXTM3U url-tvg=\"http://localhost:8080/epg.xml\"\n" {
 			t.Errorf("expected only #EXTM3U header, got %q", body)
 		}
 	})
@@ -110,7 +146,7 @@ func TestPlaylistHTTPHandler_ServeHTTP(t *testing.T) {
 	})
 
 	t.Run("GET /playlist.m3u uses request Host header in URLs", func(t *testing.T) {
-		st1, _ := stream.NewStream("xyz789", "TestChannel", "")
+		st1, _ := stream.NewStream("78797a3738390000000000000000000000000000", "TestChannel", "")
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
 				return []stream.Stream{st1}, nil
@@ -130,7 +166,7 @@ func TestPlaylistHTTPHandler_ServeHTTP(t *testing.T) {
 		}
 
 		body := rec.Body.String()
-		if !strings.Contains(body, "http://example.com:9000/ace/getstream?id=xyz789") {
+		if !strings.Contains(body, "http://example.com:9000/ace/getstream?id=78797a3738390000000000000000000000000000") {
 			t.Error("M3U playlist should use the request Host header in stream URLs")
 		}
 	})
@@ -180,3 +216,360 @@ func TestPlaylistHTTPHandler_ServeHTTP(t *testing.T) {
 		}
 	})
 }
+
+func TestPlaylistHTTPHandler_Formats(t *testing.T) {
+	st, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{st}, nil
+		},
+	}
+	service := application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+	handler := NewPlaylistHTTPHandler(service)
+
+	tests := []struct {
+		name        string
+		target      string
+		accept      string
+		status      int
+		contentType string
+		bodyPrefix  string
+	}{
+		{"format parameter selects JSON", "/playlist.m3u?format=json", "", http.StatusOK, "application/json", "{"},
+		{"format parameter selects Enigma2", "/playlist.m3u?format=enigma2", "", http.StatusOK, "text/plain; charset=utf-8", "#NAME "},
+		{"Accept header selects extended M3U", "/playlist.m3u", "application/vnd.apple.mpegurl", http.StatusOK, "application/x-mpegurl", "#EXTM3U"},
+		{"Accept header selects JSON", "/playlist.m3u", "text/html, application/json;q=0.9", http.StatusOK, "application/json", "{"},
+		{"format parameter wins over Accept", "/playlist.m3u?format=m3u", "application/json", http.StatusOK, "audio/mpegurl", "#EXTM3U"},
+		{"unknown Accept falls back to M3U", "/playlist.m3u", "*/*", http.StatusOK, "audio/mpegurl", "#EXTM3U"},
+		{"unknown format parameter is rejected", "/playlist.m3u?format=pls", "", http.StatusBadRequest, "application/json", "{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Host = "localhost:8080"
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rec.Code)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("expected Content-Type %q, got %q", tt.contentType, ct)
+			}
+			if !strings.HasPrefix(rec.Body.String(), tt.bodyPrefix) {
+				t.Errorf("expected body starting with %q, got %q", tt.bodyPrefix, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPlaylistHTTPHandler_UserPlaylist(t *testing.T) {
+	users, playlist := newUserTestServices()
+	handler := NewPlaylistHTTPHandler(playlist)
+	u, _ := users.CreateUser(context.Background(), "Grandma", []string{"La 1"}, nil)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "localhost:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("/playlist/" + u.PlaylistToken() + ".m3u"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without a user service, got %d", rec.Code)
+	}
+
+	handler.SetUserService(users)
+
+	rec := get("/playlist/" + u.PlaylistToken() + ".m3u")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "La 1 - 6c61310000000000000000000000000000000000") || strings.Contains(body, "DAZN") {
+		t.Errorf("expected only the user's channels, got:\n%s", body)
+	}
+
+	for _, path := range []string{"/playlist/unknown.m3u", "/playlist/" + u.PlaylistToken()} {
+		if rec := get(path); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected status 404, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestPlaylistHTTPHandler_TagPlaylist(t *testing.T) {
+	la1, _ := stream.NewStream("6c61310000000000000000000000000000000000", "La 1", "")
+	dazn, _ := stream.NewStream("64617a6e00000000000000000000000000000000", "DAZN 1", "")
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{la1, dazn}, nil
+		},
+	}
+	ch, _ := channel.NewChannel("La 1")
+	_ = ch.SetTags([]string{"spanish"})
+	channelRepo := &mockChannelRepository{
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			return []channel.Channel{ch}, nil
+		},
+	}
+	handler := NewPlaylistHTTPHandler(application.NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "localhost:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/playlist/tag/Spanish.m3u")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "La 1 - 6c61310000000000000000000000000000000000") || strings.Contains(body, "DAZN") {
+		t.Errorf("expected only the tagged channels, got:\n%s", body)
+	}
+
+	if rec := get("/playlist/tag/kids.m3u"); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "#EXTINF") {
+		t.Errorf("expected an empty playlist for an unused tag, got %d:\n%s", rec.Code, rec.Body.String())
+	}
+	for _, path := range []string{"/playlist/tag/spanish", "/playlist/tag/not%20a%20tag.m3u"} {
+		if rec := get(path); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected status 404, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestPlaylistHTTPHandler_FavoritePlaylist(t *testing.T) {
+	favorites, playlist := newFavoriteTestServices()
+	handler := NewPlaylistHTTPHandler(playlist)
+	_, _ = favorites.CreateList(context.Background(), "Living room", []string{"DAZN 1", "La 1"}, true)
+	_, _ = favorites.CreateList(context.Background(), "Kids", []string{"La 1"}, false)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "localhost:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("/playlist/fav/living-room.m3u"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without a favorite service, got %d", rec.Code)
+	}
+
+	handler.SetFavoriteService(favorites)
+
+	rec := get("/playlist/fav/living-room.m3u")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	dazn, la1 := strings.Index(body, "DAZN 1 - "), strings.Index(body, "La 1 - ")
+	if dazn < 0 || la1 < 0 || dazn > la1 {
+		t.Errorf("expected DAZN 1 before La 1, got:\n%s", body)
+	}
+	if !strings.Contains(body, `url-tvg="http://localhost:8080/playlist/fav/living-room.xml"`) {
+		t.Errorf("expected the list's guide URL, got:\n%s", body)
+	}
+
+	rec = get("/playlist/fav/living-room.xml")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/xml") {
+		t.Errorf("expected the list's guide, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	for _, path := range []string{"/playlist/fav/missing.m3u", "/playlist/fav/living-room", "/playlist/fav/kids.xml"} {
+		if rec := get(path); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected status 404, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestPlaylistHTTPHandler_ConditionalGet(t *testing.T) {
+	la1, _ := stream.NewStream("6c61310000000000000000000000000000000000", "La 1", "")
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{la1}, nil
+		},
+	}
+	service := application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+	handler := NewPlaylistHTTPHandler(service)
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Host = "localhost:8080"
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/playlist.m3u", "")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected status 200 with an ETag, got %d %q", rec.Code, etag)
+	}
+	if rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("expected Cache-Control no-cache, got %q", rec.Header().Get("Cache-Control"))
+	}
+	if vary := rec.Header().Get("Vary"); vary != "Accept, Authorization, Cookie" {
+		t.Errorf("expected Vary Accept, Authorization, Cookie, got %q", vary)
+	}
+	if lastModified, err := http.ParseTime(rec.Header().Get("Last-Modified")); err != nil || lastModified.After(time.Now()) {
+		t.Errorf("expected a Last-Modified in the past, got %q", rec.Header().Get("Last-Modified"))
+	}
+
+	for _, ifNoneMatch := range []string{etag, `"other", ` + etag, strings.TrimPrefix(etag, "W/"), "*"} {
+		rec := get("/playlist.m3u", ifNoneMatch)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: expected an empty 304, got %d with %d bytes", ifNoneMatch, rec.Code, rec.Body.Len())
+		}
+	}
+
+	if rec := get("/playlist.m3u", `W/"stale"`); rec.Code != http.StatusOK {
+		t.Errorf("expected status 200 for a stale ETag, got %d", rec.Code)
+	}
+	if rec := get("/playlist.m3u?format=json", etag); rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("expected another format to have its own ETag, got %d", rec.Code)
+	}
+}
+
+func TestPlaylistHTTPHandler_MinHealth(t *testing.T) {
+	la1, _ := stream.NewStream("6c61310000000000000000000000000000000000", "La 1", "")
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{la1}, nil
+		},
+	}
+	probeRepo := &mockProbeRepository{
+		findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+			return []probe.Result{probe.ReconstructResult(infoHash, time.Now(), false, 0, 0, 0, "", "timeout")}, nil
+		},
+	}
+	service := application.NewPlaylistService(streamRepo, &mockChannelRepository{}, probeRepo, 24*time.Hour)
+	handler := NewPlaylistHTTPHandler(service)
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Host = "localhost:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("/playlist.m3u"); !strings.Contains(rec.Body.String(), "La 1") {
+		t.Errorf("expected the failed stream listed without a minimum, got %q", rec.Body.String())
+	}
+	if rec := get("/playlist.m3u?min_health=0.5"); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "La 1") {
+		t.Errorf("expected the failed stream left out, got %d %q", rec.Code, rec.Body.String())
+	}
+	for _, v := range []string{"high", "-0.1", "1.5"} {
+		if rec := get("/playlist.m3u?min_health=" + v); rec.Code != http.StatusBadRequest {
+			t.Errorf("min_health=%s: expected status 400, got %d", v, rec.Code)
+		}
+	}
+}
+
+func TestPlaylistHTTPHandler_TokenPrefs(t *testing.T) {
+	st, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{st}, nil
+		},
+	}
+	authService := application.NewAuthService(newMockTokenRepository(), application.AuthConfig{Username: "admin", Password: "secret", SessionKey: []byte("test-key")})
+	tok, _, _ := authService.CreateToken(context.Background(), "tv")
+	handler := NewPlaylistHTTPHandler(application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour))
+	handler.SetAuthService(authService)
+	tokens := NewTokenHTTPHandler(authService)
+
+	rec := httptest.NewRecorder()
+	tokens.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/tokens/"+tok.ID()+"/playlist-prefs", strings.NewReader(`{"format":"json","quality":"fhd"}`)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"quality":"1080p"`) {
+		t.Fatalf("expected the prefs to be saved, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	for _, tt := range []struct {
+		name, target, contentType string
+		withToken                 bool
+	}{
+		{"token prefs choose the format", "/playlist.m3u", "application/json", true},
+		{"format parameter wins over token prefs", "/playlist.m3u?format=m3u", "audio/mpegurl", true},
+		{"requests without a token are unchanged", "/playlist.m3u", "audio/mpegurl", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.withToken {
+				req = req.WithContext(application.WithAPIToken(req.Context(), tok.ID()))
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || ct != tt.contentType {
+				t.Errorf("expected 200 with Content-Type %q, got %d %q", tt.contentType, rec.Code, ct)
+			}
+		})
+	}
+
+	t.Run("token prefs apply alongside a session cookie", func(t *testing.T) {
+		session, _, err := authService.Login("admin", "secret")
+		if err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+		browser, secret, _ := authService.CreateToken(context.Background(), "browser")
+		if _, err := authService.SetPlaylistPrefs(context.Background(), browser.ID(), nil, "", "json", false); err != nil {
+			t.Fatalf("SetPlaylistPrefs() error = %v", err)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/playlist.m3u?token="+secret, nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: session})
+		rec := httptest.NewRecorder()
+		NewAuthMiddleware(authService, handler, slog.Default()).ServeHTTP(rec, req)
+
+		if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || ct != "application/json" {
+			t.Errorf("expected 200 with the token's JSON format, got %d %q", rec.Code, ct)
+		}
+	})
+
+	t.Run("token prefs apply with authentication disabled", func(t *testing.T) {
+		openAuth := application.NewAuthService(newMockTokenRepository(), application.AuthConfig{})
+		tok, secret, _ := openAuth.CreateToken(context.Background(), "tv")
+		if _, err := openAuth.SetPlaylistPrefs(context.Background(), tok.ID(), nil, "", "json", false); err != nil {
+			t.Fatalf("SetPlaylistPrefs() error = %v", err)
+		}
+		open := NewPlaylistHTTPHandler(application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour))
+		open.SetAuthService(openAuth)
+
+		rec := httptest.NewRecorder()
+		NewAuthMiddleware(openAuth, open, slog.Default()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/playlist.m3u?token="+secret, nil))
+
+		if ct := rec.Header().Get("Content-Type"); rec.Code != http.StatusOK || ct != "application/json" {
+			t.Errorf("expected 200 with the token's JSON format, got %d %q", rec.Code, ct)
+		}
+	})
+
+	t.Run("invalid prefs are rejected", func(t *testing.T) {
+		for _, tt := range []struct {
+			target, body string
+			status       int
+		}{
+			{"/tokens/" + tok.ID() + "/playlist-prefs", `{"format":"pls"}`, http.StatusBadRequest},
+			{"/tokens/" + tok.ID() + "/playlist-prefs", `{"quality":"8k"}`, http.StatusBadRequest},
+			{"/tokens/nope/playlist-prefs", `{}`, http.StatusNotFound},
+		} {
+			rec := httptest.NewRecorder()
+			tokens.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, tt.target, strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Errorf("PUT %s %s: expected %d, got %d", tt.target, tt.body, tt.status, rec.Code)
+			}
+		}
+	})
+}
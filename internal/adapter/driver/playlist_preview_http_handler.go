@@ -0,0 +1,125 @@
+package driver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/playlist"
+	"github.com/alorle/iptv-manager/internal/user"
+)
+
+// PlaylistPreviewHTTPHandler handles HTTP requests for previewing the
+// playlist without serving it.
+type PlaylistPreviewHTTPHandler struct {
+	service *application.PlaylistService
+	users   *application.UserService
+}
+
+// NewPlaylistPreviewHTTPHandler creates a new HTTP handler for playlist
+// previews.
+func NewPlaylistPreviewHTTPHandler(service *application.PlaylistService) *PlaylistPreviewHTTPHandler {
+	return &PlaylistPreviewHTTPHandler{service: service}
+}
+
+// SetUserService enables previewing the playlist of a user with ?user={id}.
+func (h *PlaylistPreviewHTTPHandler) SetUserService(users *application.UserService) {
+	h.users = users
+}
+
+// playlistEntryResponse represents a playlist entry in JSON format.
+type playlistEntryResponse struct {
+	Number       int    `json:"number"`
+	ChannelName  string `json:"channel_name"`
+	DisplayName  string `json:"display_name"`
+	TVGID        string `json:"tvg_id"`
+	LogoURL      string `json:"logo_url,omitempty"`
+	Group        string `json:"group,omitempty"`
+	InfoHash     string `json:"info_hash"`
+	URL          string `json:"url"`
+	Quality      string `json:"quality,omitempty"`
+	Availability string `json:"availability,omitempty"`
+	Source       string `json:"source,omitempty"`
+}
+
+// excludedEntryResponse represents an entry left out of the playlist.
+type excludedEntryResponse struct {
+	playlistEntryResponse
+	Reason string `json:"reason"`
+}
+
+// playlistPreviewResponse represents a playlist preview in JSON format.
+type playlistPreviewResponse struct {
+	Entries  []playlistEntryResponse `json:"entries"`
+	Excluded []excludedEntryResponse `json:"excluded"`
+}
+
+func toPlaylistEntryResponse(e playlist.Entry) playlistEntryResponse {
+	return playlistEntryResponse{
+		Number:       e.Number,
+		ChannelName:  e.ChannelName,
+		DisplayName:  e.DisplayName(),
+		TVGID:        e.TVGID,
+		LogoURL:      e.LogoURL,
+		Group:        e.Group,
+		InfoHash:     e.InfoHash,
+		URL:          e.URL,
+		Quality:      e.Quality,
+		Availability: e.Availability,
+		Source:       e.Source,
+	}
+}
+
+// ServeHTTP handles GET /playlist/preview, which runs the playlist
+// generation and reports the entries it would emit, in order, and every
+// stream it would leave out with the reason: filtered, disabled_group,
+// duplicate, disabled, unavailable or unhealthy. Like the playlist, it takes
+// a min_health query parameter.
+func (h *PlaylistPreviewHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var u *user.User
+	if id := r.URL.Query().Get("user"); id != "" {
+		if h.users == nil {
+			writeError(w, http.StatusNotFound, user.ErrUserNotFound.Error())
+			return
+		}
+		found, err := h.users.GetUser(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				writeError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		u = &found
+	}
+
+	ctx, err := withMinHealthParam(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	preview, err := h.service.Preview(ctx, publicBaseURL(r), u)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	resp := playlistPreviewResponse{
+		Entries:  make([]playlistEntryResponse, len(preview.Entries)),
+		Excluded: make([]excludedEntryResponse, len(preview.Excluded)),
+	}
+	for i, e := range preview.Entries {
+		resp.Entries[i] = toPlaylistEntryResponse(e)
+	}
+	for i, x := range preview.Excluded {
+		resp.Excluded[i] = excludedEntryResponse{playlistEntryResponse: toPlaylistEntryResponse(x.Entry), Reason: x.Reason}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
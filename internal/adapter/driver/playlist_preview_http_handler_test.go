@@ -0,0 +1,78 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPlaylistPreviewHTTPHandler(t *testing.T) {
+	users, playlist := newUserTestServices()
+	handler := NewPlaylistPreviewHTTPHandler(playlist)
+	handler.SetUserService(users)
+
+	kid, err := users.CreateUser(context.Background(), "Kid", []string{"La 1"}, nil)
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	preview := func(t *testing.T, target string) (int, playlistPreviewResponse) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Host = "localhost:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		var resp playlistPreviewResponse
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	t.Run("GET /playlist/preview lists the entries of the full playlist", func(t *testing.T) {
+		code, resp := preview(t, "/playlist/preview")
+
+		if code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", code)
+		}
+		if len(resp.Entries) != 2 || len(resp.Excluded) != 0 {
+			t.Errorf("expected 2 entries and none excluded, got %+v", resp)
+		}
+		if e := resp.Entries[0]; e.URL != "http://localhost:8080/ace/getstream?id="+e.InfoHash || e.DisplayName != e.ChannelName {
+			t.Errorf("unexpected entry %+v", e)
+		}
+	})
+
+	t.Run("GET /playlist/preview?user= reports what the user may not see", func(t *testing.T) {
+		code, resp := preview(t, "/playlist/preview?user="+kid.ID())
+
+		if code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", code)
+		}
+		if len(resp.Entries) != 1 || resp.Entries[0].ChannelName != "La 1" {
+			t.Errorf("expected only La 1 listed, got %+v", resp.Entries)
+		}
+		if len(resp.Excluded) != 1 || resp.Excluded[0].ChannelName != "DAZN 1" || resp.Excluded[0].Reason != "filtered" {
+			t.Errorf("expected DAZN 1 filtered out, got %+v", resp.Excluded)
+		}
+	})
+
+	t.Run("GET /playlist/preview returns 404 for unknown users", func(t *testing.T) {
+		if code, _ := preview(t, "/playlist/preview?user=missing"); code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", code)
+		}
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/playlist/preview", nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
@@ -22,7 +22,7 @@ type mockProbeRepository struct {
 	saveFunc                func(ctx context.Context, r probe.Result) error
 	findByInfoHashFunc      func(ctx context.Context, infoHash string) ([]probe.Result, error)
 	findByInfoHashSinceFunc func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error)
-	deleteBeforeFunc        func(ctx context.Context, before time.Time) error
+	deleteBeforeFunc        func(ctx context.Context, before time.Time) (int, error)
 }
 
 func (m *mockProbeRepository) Save(ctx context.Context, r probe.Result) error {
@@ -46,17 +46,17 @@ func (m *mockProbeRepository) FindByInfoHashSince(ctx context.Context, infoHash
 	return []probe.Result{}, nil
 }
 
-func (m *mockProbeRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+func (m *mockProbeRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
 	if m.deleteBeforeFunc != nil {
 		return m.deleteBeforeFunc(ctx, before)
 	}
-	return nil
+	return 0, nil
 }
 
 // mockAceStreamEngineForProbe is a minimal mock for constructing ProbeService in handler tests.
 type mockAceStreamEngineForProbe struct{}
 
-func (m *mockAceStreamEngineForProbe) StartStream(ctx context.Context, infoHash, pid string) (string, error) {
+func (m *mockAceStreamEngineForProbe) StartStream(ctx context.Context, infoHash, pid string, opts driven.StreamOptions) (string, error) {
 	return "", nil
 }
 func (m *mockAceStreamEngineForProbe) GetStats(ctx context.Context, pid string) (driven.StreamStats, error) {
@@ -197,8 +197,8 @@ func TestProbeHTTPHandler_Metrics(t *testing.T) {
 func TestProbeHTTPHandler_Quality(t *testing.T) {
 	t.Run("GET /quality/{channelName} returns sorted scores", func(t *testing.T) {
 		now := time.Now()
-		s1, _ := stream.NewStream("hash1", "Channel1", "")
-		s2, _ := stream.NewStream("hash2", "Channel1", "")
+		s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+		s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel1", "")
 
 		streamRepo := &mockStreamRepository{
 			findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
@@ -208,7 +208,7 @@ func TestProbeHTTPHandler_Quality(t *testing.T) {
 
 		probeRepo := &mockProbeRepository{
 			findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
-				if infoHash == "hash1" {
+				if infoHash == "6861736831000000000000000000000000000000" {
 					return []probe.Result{
 						probe.ReconstructResult(infoHash, now, true, time.Second, 20, 200000, "dl", ""),
 					}, nil
@@ -238,7 +238,7 @@ func TestProbeHTTPHandler_Quality(t *testing.T) {
 		if len(resp) != 2 {
 			t.Fatalf("expected 2 quality scores, got %d", len(resp))
 		}
-		if resp[0].InfoHash != "hash1" {
+		if resp[0].InfoHash != "6861736831000000000000000000000000000000" {
 			t.Errorf("expected hash1 first (best), got %q", resp[0].InfoHash)
 		}
 		if resp[0].Score <= resp[1].Score {
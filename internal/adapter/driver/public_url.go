@@ -0,0 +1,195 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// errInvalidPublicBaseURL indicates a public base URL that is not an absolute
+// http or https URL.
+var errInvalidPublicBaseURL = errors.New("public base URL must be an absolute http or https URL")
+
+// publicURLKey is the context key of the externally visible base URL.
+type publicURLKey struct{}
+
+// clientAddrKey is the context key of the address of the client, behind any
+// trusted proxies.
+type clientAddrKey struct{}
+
+// publicURL is the externally visible base URL of the server, split so that
+// absolute and root-relative URLs can both be built from it.
+type publicURL struct {
+	base   string // scheme, host and prefix, without a trailing slash
+	prefix string // path prefix, empty or starting with a slash
+}
+
+// PublicURLMiddleware records the externally visible URL of the server in
+// the request context, so that the URLs written into playlists, guides and
+// HLS manifests point back through the reverse proxy clients use rather
+// than at the address the server listens on.
+//
+// With a configured base URL, e.g. https://home.example.com/iptv, every
+// generated URL starts with it. Without one, the scheme, host and path
+// prefix are taken from the X-Forwarded-Proto, X-Forwarded-Host and
+// X-Forwarded-Prefix headers of requests from trusted proxies, falling back
+// to the request itself. Other clients could set those headers to make
+// playlists point anywhere, so they are ignored.
+//
+// It also records the address of the client, taken from the X-Forwarded-For
+// header of requests from trusted proxies, for the access rules and rate
+// limits applied after it.
+type PublicURLMiddleware struct {
+	next    http.Handler
+	base    *publicURL
+	trusted []netip.Prefix
+}
+
+// NewPublicURLMiddleware wraps next with the public base URL base. An empty
+// base derives the URL of each request from its headers.
+// Returns an error if base is not an absolute http or https URL.
+func NewPublicURLMiddleware(base string, next http.Handler) (*PublicURLMiddleware, error) {
+	m := &PublicURLMiddleware{next: next}
+	if base == "" {
+		return m, nil
+	}
+
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, errInvalidPublicBaseURL
+	}
+	prefix := strings.TrimRight(u.EscapedPath(), "/")
+	m.base = &publicURL{
+		base:   u.Scheme + "://" + u.Host + prefix,
+		prefix: prefix,
+	}
+	return m, nil
+}
+
+// SetTrustedProxies makes the forwarding headers of requests from the given
+// networks count: X-Forwarded-For always, and the others when no base URL is
+// configured. Without any, the headers are ignored.
+func (m *PublicURLMiddleware) SetTrustedProxies(networks []netip.Prefix) {
+	m.trusted = networks
+}
+
+// ServeHTTP stores the public base URL and the client address in the request
+// context and passes the request on.
+func (m *PublicURLMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u := m.base
+	if u == nil {
+		derived := derivePublicURL(r, m.trustedPeer(r))
+		u = &derived
+	}
+	ctx := context.WithValue(r.Context(), publicURLKey{}, *u)
+	if addr, ok := m.clientAddr(r); ok {
+		ctx = context.WithValue(ctx, clientAddrKey{}, addr)
+	}
+	m.next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// trustedPeer reports whether r comes directly from a trusted proxy.
+func (m *PublicURLMiddleware) trustedPeer(r *http.Request) bool {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	return m.trustedAddr(addrPort.Addr().Unmap())
+}
+
+// trustedAddr reports whether addr belongs to a trusted proxy.
+func (m *PublicURLMiddleware) trustedAddr(addr netip.Addr) bool {
+	return slices.ContainsFunc(m.trusted, func(network netip.Prefix) bool {
+		return network.Contains(addr)
+	})
+}
+
+// clientAddr returns the address of the client that sent r. Requests from
+// trusted proxies are followed back through their X-Forwarded-For header, as
+// far as the last address not of a trusted proxy; earlier addresses could be
+// set by the client. Reports false if the remote address is not an IP.
+func (m *PublicURLMiddleware) clientAddr(r *http.Request) (netip.Addr, bool) {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	client := addrPort.Addr().Unmap()
+	if !m.trustedAddr(client) {
+		return client, true
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !m.trustedAddr(client) {
+			break
+		}
+	}
+	return client, true
+}
+
+// publicBaseURL returns the externally visible base URL of the server for r,
+// without a trailing slash, such as http://localhost:8080 or
+// https://home.example.com/iptv.
+func publicBaseURL(r *http.Request) string {
+	return requestPublicURL(r).base
+}
+
+// publicPath returns path, which must start with a slash, under the path
+// prefix the server is reachable at, for root-relative URLs.
+func publicPath(r *http.Request, path string) string {
+	return requestPublicURL(r).prefix + path
+}
+
+// requestPublicURL returns the public URL recorded by PublicURLMiddleware,
+// or the one of r itself outside the middleware.
+func requestPublicURL(r *http.Request) publicURL {
+	if u, ok := r.Context().Value(publicURLKey{}).(publicURL); ok {
+		return u
+	}
+	return derivePublicURL(r, false)
+}
+
+// derivePublicURL returns the URL r was sent to, following its forwarding
+// headers when forwarded is true.
+func derivePublicURL(r *http.Request, forwarded bool) publicURL {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+	var prefix string
+
+	if forwarded {
+		if proto := strings.ToLower(firstHeaderValue(r, "X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwardedHost := firstHeaderValue(r, "X-Forwarded-Host"); forwardedHost != "" {
+			host = forwardedHost
+		}
+		prefix = strings.TrimRight(firstHeaderValue(r, "X-Forwarded-Prefix"), "/")
+		if prefix != "" && !strings.HasPrefix(prefix, "/") {
+			prefix = "/" + prefix
+		}
+	}
+
+	return publicURL{
+		base:   scheme + "://" + host + prefix,
+		prefix: prefix,
+	}
+}
+
+// firstHeaderValue returns the first of the comma-separated values of the
+// header key, as appended by each proxy along the way.
+func firstHeaderValue(r *http.Request, key string) string {
+	value, _, _ := strings.Cut(r.Header.Get(key), ",")
+	return strings.TrimSpace(value)
+}
@@ -0,0 +1,146 @@
+package driver
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestPublicURLMiddleware(t *testing.T) {
+	// httptest requests come from 192.0.2.1
+	proxies := []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")}
+	serve := func(t *testing.T, base string, req *http.Request) (string, string) {
+		t.Helper()
+		var gotBase, gotPath string
+		m, err := NewPublicURLMiddleware(base, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotBase = publicBaseURL(r)
+			gotPath = publicPath(r, "/ace/hls/abc/1.ts")
+		}))
+		if err != nil {
+			t.Fatalf("NewPublicURLMiddleware() error = %v", err)
+		}
+		m.SetTrustedProxies(proxies)
+		m.ServeHTTP(httptest.NewRecorder(), req)
+		return gotBase, gotPath
+	}
+
+	t.Run("uses the request host without forwarding headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/playlist.m3u", nil)
+		req.Host = "192.168.1.10:8080"
+
+		base, path := serve(t, "", req)
+		if base != "http://192.168.1.10:8080" || path != "/ace/hls/abc/1.ts" {
+			t.Errorf("got %q and %q", base, path)
+		}
+	})
+
+	t.Run("uses https for TLS connections", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/playlist.m3u", nil)
+		req.Host = "tv.example.com"
+		req.TLS = &tls.ConnectionState{}
+
+		if base, _ := serve(t, "", req); base != "https://tv.example.com" {
+			t.Errorf("got %q", base)
+		}
+	})
+
+	t.Run("follows the forwarding headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/playlist.m3u", nil)
+		req.Host = "127.0.0.1:8080"
+		req.Header.Set("X-Forwarded-Proto", "https, http")
+		req.Header.Set("X-Forwarded-Host", "home.example.com, proxy.internal")
+		req.Header.Set("X-Forwarded-Prefix", "iptv/")
+
+		base, path := serve(t, "", req)
+		if base != "https://home.example.com/iptv" || path != "/iptv/ace/hls/abc/1.ts" {
+			t.Errorf("got %q and %q", base, path)
+		}
+	})
+
+	t.Run("ignores the forwarding headers of untrusted peers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/playlist.m3u", nil)
+		req.RemoteAddr = "203.0.113.7:51000"
+		req.Host = "192.168.1.10:8080"
+		req.Header.Set("X-Forwarded-Proto", "https")
+		req.Header.Set("X-Forwarded-Host", "evil.example.com")
+		req.Header.Set("X-Forwarded-Prefix", "/phish")
+
+		base, path := serve(t, "", req)
+		if base != "http://192.168.1.10:8080" || path != "/ace/hls/abc/1.ts" {
+			t.Errorf("got %q and %q", base, path)
+		}
+	})
+
+	t.Run("ignores unknown forwarded schemes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/playlist.m3u", nil)
+		req.Host = "localhost:8080"
+		req.Header.Set("X-Forwarded-Proto", "javascript")
+
+		if base, _ := serve(t, "", req); base != "http://localhost:8080" {
+			t.Errorf("got %q", base)
+		}
+	})
+
+	t.Run("prefers the configured base URL over the headers", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/playlist.m3u", nil)
+		req.Host = "localhost:8080"
+		req.Header.Set("X-Forwarded-Host", "evil.example.com")
+
+		base, path := serve(t, "https://home.example.com/iptv/", req)
+		if base != "https://home.example.com/iptv" || path != "/iptv/ace/hls/abc/1.ts" {
+			t.Errorf("got %q and %q", base, path)
+		}
+	})
+
+	t.Run("rejects invalid base URLs", func(t *testing.T) {
+		for _, base := range []string{"home.example.com/iptv", "ftp://home.example.com", "https://", "https://home.example.com/?a=b"} {
+			if _, err := NewPublicURLMiddleware(base, http.NotFoundHandler()); !errors.Is(err, errInvalidPublicBaseURL) {
+				t.Errorf("NewPublicURLMiddleware(%q) error = %v, want errInvalidPublicBaseURL", base, err)
+			}
+		}
+	})
+}
+
+func TestPublicURLMiddleware_ClientAddress(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24"), netip.MustParsePrefix("10.0.0.0/8")}
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		wantClientIP string
+	}{
+		{"direct client", "203.0.113.7:51000", nil, "203.0.113.7"},
+		{"untrusted peer cannot forward", "203.0.113.7:51000", []string{"198.51.100.9"}, "203.0.113.7"},
+		{"client behind a trusted proxy", "192.0.2.1:1234", []string{"198.51.100.9"}, "198.51.100.9"},
+		{"client behind a chain of trusted proxies", "192.0.2.1:1234", []string{"198.51.100.9, 10.1.2.3"}, "198.51.100.9"},
+		{"addresses set by the client are skipped", "192.0.2.1:1234", []string{"127.0.0.1, 198.51.100.9", "10.1.2.3"}, "198.51.100.9"},
+		{"invalid hop stops at the proxy before it", "192.0.2.1:1234", []string{"unknown, 10.1.2.3"}, "10.1.2.3"},
+		{"trusted proxy without a header", "192.0.2.1:1234", nil, "192.0.2.1"},
+		{"IPv4-mapped addresses are unmapped", "[::ffff:203.0.113.7]:51000", nil, "203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			m, err := NewPublicURLMiddleware("https://tv.example.com", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = clientIP(r)
+			}))
+			if err != nil {
+				t.Fatalf("NewPublicURLMiddleware() error = %v", err)
+			}
+			m.SetTrustedProxies(proxies)
+
+			req := httptest.NewRequest(http.MethodGet, "/playlist.m3u", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			m.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.wantClientIP {
+				t.Errorf("clientIP() = %q, want %q", got, tt.wantClientIP)
+			}
+		})
+	}
+}
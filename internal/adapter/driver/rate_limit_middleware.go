@@ -0,0 +1,100 @@
+package driver
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/ratelimit"
+)
+
+// streamLimitRetryAfter is the Retry-After hint sent when a client is at its
+// concurrent stream limit or all tuners are in use. There is no way to know
+// when a stream will end, so this only keeps well-behaved players from
+// retrying in a loop.
+const streamLimitRetryAfter = 5 * time.Second
+
+// RateLimitMiddleware protects the AceStream engine and the API from
+// misbehaving clients. It caps the number of concurrent streams per client
+// IP and rate-limits API requests per client IP, answering 429 Too Many
+// Requests with a Retry-After header when a limit is hit.
+//
+// Clients are identified by the connection's remote address, so all clients
+// behind the same reverse proxy or NAT share their limits.
+type RateLimitMiddleware struct {
+	streams *ratelimit.ConcurrencyLimiter
+	api     *ratelimit.Limiter
+	next    http.Handler
+	logger  *slog.Logger
+}
+
+// NewRateLimitMiddleware wraps next with per-client stream and API limits.
+func NewRateLimitMiddleware(streams *ratelimit.ConcurrencyLimiter, api *ratelimit.Limiter, next http.Handler, logger *slog.Logger) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		streams: streams,
+		api:     api,
+		next:    next,
+		logger:  logger,
+	}
+}
+
+// ServeHTTP applies the limit matching the request path before passing it on.
+func (m *RateLimitMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ip := clientIP(r)
+
+	switch {
+	case isStreamRequest(r.URL.Path):
+		release, ok := m.streams.Acquire(ip)
+		if !ok {
+			m.logger.WarnContext(r.Context(), "concurrent stream limit reached", "remote_addr", r.RemoteAddr, "path", r.URL.Path)
+			writeTooManyRequests(w, streamLimitRetryAfter, "too many concurrent streams")
+			return
+		}
+		defer release()
+
+	case strings.HasPrefix(r.URL.Path, "/api/"):
+		if wait, ok := m.api.Allow(ip); !ok {
+			m.logger.DebugContext(r.Context(), "api rate limit reached", "remote_addr", r.RemoteAddr, "path", r.URL.Path)
+			writeTooManyRequests(w, wait, "rate limit exceeded")
+			return
+		}
+	}
+
+	m.next.ServeHTTP(w, r)
+}
+
+// isStreamRequest reports whether path opens a long-lived engine stream.
+// HLS playlists and segments are short requests and are not capped.
+func isStreamRequest(path string) bool {
+	if !strings.HasPrefix(path, "/ace/") {
+		return false
+	}
+	return !strings.HasPrefix(path, "/ace/hls/") && !strings.HasSuffix(path, ".m3u8")
+}
+
+// clientIP returns the client address recorded by PublicURLMiddleware, which
+// follows trusted proxies, or else the host part of the request's remote
+// address.
+func clientIP(r *http.Request) string {
+	if addr, ok := r.Context().Value(clientAddrKey{}).(netip.Addr); ok {
+		return addr.String()
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// writeTooManyRequests writes a 429 response with a Retry-After header
+// rounded up to whole seconds.
+func writeTooManyRequests(w http.ResponseWriter, retryAfter time.Duration, message string) {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(max(seconds, 1), 10))
+	writeError(w, http.StatusTooManyRequests, message)
+}
@@ -0,0 +1,136 @@
+package driver
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/ratelimit"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	t.Run("caps concurrent streams per client IP", func(t *testing.T) {
+		streams := ratelimit.NewConcurrencyLimiter(1)
+		entered := make(chan struct{})
+		unblock := make(chan struct{})
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(entered)
+			<-unblock
+		})
+		m := NewRateLimitMiddleware(streams, ratelimit.New(0, 0), next, logger)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			req := httptest.NewRequest(http.MethodGet, "/ace/getstream?id=abc", nil)
+			req.RemoteAddr = "10.0.0.1:5000"
+			m.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+		<-entered
+
+		req := httptest.NewRequest(http.MethodGet, "/ace/getstream?id=def", nil)
+		req.RemoteAddr = "10.0.0.1:5001"
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusTooManyRequests {
+			t.Errorf("expected status 429, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "5" {
+			t.Errorf("expected Retry-After 5, got %q", got)
+		}
+
+		close(unblock)
+		<-done
+		if got := streams.Active(); got != 0 {
+			t.Errorf("expected stream slot to be released, got %d active", got)
+		}
+	})
+
+	t.Run("does not cap HLS playlists and segments", func(t *testing.T) {
+		streams := ratelimit.NewConcurrencyLimiter(1)
+		release, _ := streams.Acquire("10.0.0.1")
+		defer release()
+
+		m := NewRateLimitMiddleware(streams, ratelimit.New(0, 0), http.NotFoundHandler(), logger)
+		for _, path := range []string{"/ace/abc.m3u8", "/ace/hls/abc/1.ts"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.RemoteAddr = "10.0.0.1:5000"
+			rec := httptest.NewRecorder()
+			m.ServeHTTP(rec, req)
+			if rec.Code == http.StatusTooManyRequests {
+				t.Errorf("%s should not count against the stream limit", path)
+			}
+		}
+	})
+
+	t.Run("rate-limits API requests per client IP", func(t *testing.T) {
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		m := NewRateLimitMiddleware(ratelimit.NewConcurrencyLimiter(0), ratelimit.New(1, 2), next, logger)
+
+		serve := func(path, remoteAddr string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.RemoteAddr = remoteAddr
+			rec := httptest.NewRecorder()
+			m.ServeHTTP(rec, req)
+			return rec
+		}
+
+		for i := 0; i < 2; i++ {
+			if rec := serve("/api/channels", "10.0.0.1:5000"); rec.Code != http.StatusOK {
+				t.Fatalf("request %d within burst: expected 200, got %d", i+1, rec.Code)
+			}
+		}
+
+		rec := serve("/api/channels", "10.0.0.1:5000")
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected status 429, got %d", rec.Code)
+		}
+		if rec.Header().Get("Retry-After") == "" {
+			t.Error("expected Retry-After header")
+		}
+
+		if rec := serve("/api/channels", "10.0.0.2:5000"); rec.Code != http.StatusOK {
+			t.Errorf("other clients should not be limited, got %d", rec.Code)
+		}
+		if rec := serve("/playlist.m3u", "10.0.0.1:5000"); rec.Code != http.StatusOK {
+			t.Errorf("non-API routes should not be rate-limited, got %d", rec.Code)
+		}
+	})
+	t.Run("limits each client behind a trusted proxy apart", func(t *testing.T) {
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+		m, err := NewPublicURLMiddleware("", NewRateLimitMiddleware(ratelimit.NewConcurrencyLimiter(0), ratelimit.New(1, 1), next, logger))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.SetTrustedProxies([]netip.Prefix{netip.MustParsePrefix("172.16.0.0/12")})
+
+		serve := func(forwardedFor string) int {
+			req := httptest.NewRequest(http.MethodGet, "/api/channels", nil)
+			req.RemoteAddr = "172.18.0.2:5000"
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+			rec := httptest.NewRecorder()
+			m.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		if code := serve("198.51.100.1"); code != http.StatusOK {
+			t.Fatalf("first client: expected 200, got %d", code)
+		}
+		if code := serve("198.51.100.1"); code != http.StatusTooManyRequests {
+			t.Errorf("first client over its burst: expected 429, got %d", code)
+		}
+		if code := serve("198.51.100.2"); code != http.StatusOK {
+			t.Errorf("second client behind the same proxy should not be limited, got %d", code)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+// RecordingHTTPHandler schedules recordings and serves the captured files.
+type RecordingHTTPHandler struct {
+	service *application.RecordingService
+	logger  *slog.Logger
+}
+
+// NewRecordingHTTPHandler creates a new HTTP handler for recordings.
+func NewRecordingHTTPHandler(service *application.RecordingService, logger *slog.Logger) *RecordingHTTPHandler {
+	return &RecordingHTTPHandler{service: service, logger: logger}
+}
+
+// recordingRequest represents the JSON body for scheduling a recording.
+// Either start_at and stop_at, or duration_minutes to record from now, must
+// be given.
+type recordingRequest struct {
+	Channel         string     `json:"channel"`
+	StartAt         *time.Time `json:"start_at"`
+	StopAt          *time.Time `json:"stop_at"`
+	DurationMinutes int        `json:"duration_minutes"`
+}
+
+// recordingResponse represents a recording in JSON format.
+type recordingResponse struct {
+	ID        string `json:"id"`
+	Channel   string `json:"channel"`
+	StartAt   string `json:"start_at"`
+	StopAt    string `json:"stop_at"`
+	Status    string `json:"status"`
+	Size      int64  `json:"size"`
+	LastError string `json:"last_error,omitempty"`
+	CreatedAt string `json:"created_at"`
+	URL       string `json:"url"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *RecordingHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/recordings")
+
+	// GET /recordings - list all recordings
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	// POST /recordings - schedule a recording
+	if r.Method == http.MethodPost && path == "" {
+		h.handleCreate(w, r)
+		return
+	}
+
+	id := strings.TrimPrefix(path, "/")
+
+	// GET /recordings/{id}.ts - download or play a recording
+	if file, ok := strings.CutSuffix(id, ".ts"); ok && file != "" {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			h.handleFile(w, r, file)
+			return
+		}
+	}
+
+	if id != "" && !strings.Contains(id, "/") {
+		switch r.Method {
+		// GET /recordings/{id} - get a specific recording
+		case http.MethodGet:
+			h.handleGet(w, r, id)
+			return
+		// DELETE /recordings/{id} - stop and delete a recording
+		case http.MethodDelete:
+			h.handleDelete(w, r, id)
+			return
+		}
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toRecordingResponse(rec recording.Recording) recordingResponse {
+	return recordingResponse{
+		ID:        rec.ID(),
+		Channel:   rec.ChannelName(),
+		StartAt:   formatOptionalTime(rec.StartAt()),
+		StopAt:    formatOptionalTime(rec.StopAt()),
+		Status:    string(rec.Status()),
+		Size:      rec.Size(),
+		LastError: rec.LastError(),
+		CreatedAt: formatOptionalTime(rec.CreatedAt()),
+		URL:       "/recordings/" + rec.ID() + ".ts",
+	}
+}
+
+// writeRecordingError maps recording and channel errors to HTTP responses.
+func (h *RecordingHTTPHandler) writeRecordingError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, recording.ErrEmptyChannelName), errors.Is(err, recording.ErrInvalidWindow),
+		errors.Is(err, recording.ErrWindowPassed):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, recording.ErrRecordingNotFound), errors.Is(err, recording.ErrNoFile),
+		errors.Is(err, channel.ErrChannelNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		h.logger.ErrorContext(r.Context(), "recording request failed", "path", r.URL.Path, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// handleList handles GET /recordings
+func (h *RecordingHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	recordings, err := h.service.ListRecordings(r.Context())
+	if err != nil {
+		h.writeRecordingError(w, r, err)
+		return
+	}
+
+	response := make([]recordingResponse, len(recordings))
+	for i, rec := range recordings {
+		response[i] = toRecordingResponse(rec)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleCreate handles POST /recordings
+func (h *RecordingHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req recordingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var rec recording.Recording
+	var err error
+	switch {
+	case req.DurationMinutes > 0 && req.StartAt == nil && req.StopAt == nil:
+		rec, err = h.service.RecordNow(r.Context(), req.Channel, time.Duration(req.DurationMinutes)*time.Minute)
+	case req.DurationMinutes == 0 && req.StartAt != nil && req.StopAt != nil:
+		rec, err = h.service.Schedule(r.Context(), req.Channel, *req.StartAt, *req.StopAt)
+	default:
+		writeError(w, http.StatusBadRequest, "either start_at and stop_at or a positive duration_minutes is required")
+		return
+	}
+	if err != nil {
+		h.writeRecordingError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toRecordingResponse(rec))
+}
+
+// handleGet handles GET /recordings/{id}
+func (h *RecordingHTTPHandler) handleGet(w http.ResponseWriter, r *http.Request, id string) {
+	rec, err := h.service.GetRecording(r.Context(), id)
+	if err != nil {
+		h.writeRecordingError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toRecordingResponse(rec))
+}
+
+// handleDelete handles DELETE /recordings/{id}
+func (h *RecordingHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteRecording(r.Context(), id); err != nil {
+		h.writeRecordingError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleFile handles GET /recordings/{id}.ts. Range requests are supported so
+// players can seek.
+func (h *RecordingHTTPHandler) handleFile(w http.ResponseWriter, r *http.Request, id string) {
+	rec, f, modTime, err := h.service.OpenRecording(r.Context(), id)
+	if err != nil {
+		h.writeRecordingError(w, r, err)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "video/mp2t")
+	if rec.Status() == recording.StatusRecording {
+		// The file is still growing
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	http.ServeContent(w, r, id+".ts", modTime, f)
+}
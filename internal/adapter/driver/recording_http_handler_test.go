@@ -0,0 +1,213 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+// mockRecordingRepository is an in-memory implementation for testing.
+type mockRecordingRepository struct {
+	recordings map[string]recording.Recording
+}
+
+func (m *mockRecordingRepository) Save(ctx context.Context, rec recording.Recording) error {
+	m.recordings[rec.ID()] = rec
+	return nil
+}
+
+func (m *mockRecordingRepository) Update(ctx context.Context, rec recording.Recording) error {
+	if _, ok := m.recordings[rec.ID()]; !ok {
+		return recording.ErrRecordingNotFound
+	}
+	m.recordings[rec.ID()] = rec
+	return nil
+}
+
+func (m *mockRecordingRepository) FindAll(ctx context.Context) ([]recording.Recording, error) {
+	all := make([]recording.Recording, 0, len(m.recordings))
+	for _, rec := range m.recordings {
+		all = append(all, rec)
+	}
+	return all, nil
+}
+
+func (m *mockRecordingRepository) FindByID(ctx context.Context, id string) (recording.Recording, error) {
+	rec, ok := m.recordings[id]
+	if !ok {
+		return recording.Recording{}, recording.ErrRecordingNotFound
+	}
+	return rec, nil
+}
+
+func (m *mockRecordingRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.recordings[id]; !ok {
+		return recording.ErrRecordingNotFound
+	}
+	delete(m.recordings, id)
+	return nil
+}
+
+// mockRecordingStore keeps recording files in memory.
+type mockRecordingStore struct {
+	files map[string][]byte
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+type nopReadSeekCloser struct{ io.ReadSeeker }
+
+func (nopReadSeekCloser) Close() error { return nil }
+
+func (m *mockRecordingStore) Create(ctx context.Context, id string) (io.WriteCloser, error) {
+	return nopWriteCloser{io.Discard}, nil
+}
+
+func (m *mockRecordingStore) Open(ctx context.Context, id string) (io.ReadSeekCloser, time.Time, error) {
+	data, ok := m.files[id]
+	if !ok {
+		return nil, time.Time{}, recording.ErrNoFile
+	}
+	return nopReadSeekCloser{bytes.NewReader(data)}, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+func (m *mockRecordingStore) Delete(ctx context.Context, id string) error {
+	delete(m.files, id)
+	return nil
+}
+
+func TestRecordingHTTPHandler(t *testing.T) {
+	newHandler := func(t *testing.T) (*RecordingHTTPHandler, *mockRecordingRepository) {
+		t.Helper()
+		now := time.Now()
+		done := recording.ReconstructRecording("0a1b", "News", now.Add(-2*time.Hour), now.Add(-time.Hour),
+			recording.StatusCompleted, 10, "", now.Add(-2*time.Hour))
+		repo := &mockRecordingRepository{recordings: map[string]recording.Recording{done.ID(): done}}
+		store := &mockRecordingStore{files: map[string][]byte{done.ID(): []byte("0123456789")}}
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				if name != "News" {
+					return channel.Channel{}, channel.ErrChannelNotFound
+				}
+				return channel.NewChannel(name)
+			},
+		}
+		service := application.NewRecordingService(repo, store, channelRepo, &mockStreamRepository{}, nil, 0, slog.Default())
+		return NewRecordingHTTPHandler(service, slog.Default()), repo
+	}
+
+	t.Run("schedules a recording", func(t *testing.T) {
+		handler, repo := newHandler(t)
+		start := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		body, _ := json.Marshal(map[string]any{
+			"channel":  "News",
+			"start_at": start,
+			"stop_at":  start.Add(time.Hour),
+		})
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/recordings", bytes.NewReader(body)))
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp recordingResponse
+		_ = json.NewDecoder(w.Body).Decode(&resp)
+		if resp.Status != "scheduled" || resp.Channel != "News" || resp.URL != "/recordings/"+resp.ID+".ts" {
+			t.Errorf("unexpected response %+v", resp)
+		}
+		if _, ok := repo.recordings[resp.ID]; !ok {
+			t.Error("expected recording to be saved")
+		}
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		handler, _ := newHandler(t)
+		tests := []struct {
+			name string
+			body string
+			want int
+		}{
+			{"no times", `{"channel":"News"}`, http.StatusBadRequest},
+			{"both forms", `{"channel":"News","duration_minutes":5,"start_at":"2030-01-01T00:00:00Z","stop_at":"2030-01-01T01:00:00Z"}`, http.StatusBadRequest},
+			{"stop before start", `{"channel":"News","start_at":"2030-01-01T01:00:00Z","stop_at":"2030-01-01T00:00:00Z"}`, http.StatusBadRequest},
+			{"unknown channel", `{"channel":"Missing","duration_minutes":5}`, http.StatusNotFound},
+			{"malformed", `{`, http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/recordings", strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, w.Code)
+			}
+		}
+	})
+
+	t.Run("lists and gets recordings", func(t *testing.T) {
+		handler, _ := newHandler(t)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recordings", nil))
+		var list []recordingResponse
+		_ = json.NewDecoder(w.Body).Decode(&list)
+		if w.Code != http.StatusOK || len(list) != 1 || list[0].ID != "0a1b" {
+			t.Errorf("expected the completed recording, got %d %+v", w.Code, list)
+		}
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recordings/ffff", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 for unknown recording, got %d", w.Code)
+		}
+	})
+
+	t.Run("serves byte ranges of the file", func(t *testing.T) {
+		handler, _ := newHandler(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/recordings/0a1b.ts", nil)
+		req.Header.Set("Range", "bytes=2-5")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusPartialContent {
+			t.Fatalf("expected status 206, got %d", w.Code)
+		}
+		if w.Body.String() != "2345" {
+			t.Errorf("expected bytes 2-5, got %q", w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "video/mp2t" {
+			t.Errorf("expected Content-Type video/mp2t, got %q", ct)
+		}
+	})
+
+	t.Run("deletes a recording", func(t *testing.T) {
+		handler, repo := newHandler(t)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/recordings/0a1b", nil))
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected status 204, got %d", w.Code)
+		}
+		if len(repo.recordings) != 0 {
+			t.Error("expected recording to be deleted")
+		}
+
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recordings/0a1b.ts", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status 404 after delete, got %d", w.Code)
+		}
+	})
+}
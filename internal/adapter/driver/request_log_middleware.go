@@ -0,0 +1,151 @@
+package driver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/logging"
+)
+
+// requestIDHeader carries the request ID in both directions.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs accepted from clients.
+const maxRequestIDLength = 128
+
+// RequestLogConfig tunes the request log.
+type RequestLogConfig struct {
+	// Enabled turns the per-request log line on. Request IDs are assigned
+	// and propagated either way.
+	Enabled bool
+	// SkipPaths lists path prefixes that are never logged, e.g. /metrics.
+	SkipPaths []string
+}
+
+// RequestLogMiddleware assigns each request an ID, stores it in the request
+// context for downstream logging (see logging.ContextHandler), returns it in
+// the X-Request-ID response header and logs one line per request with its
+// method, path, status and duration once it completes.
+//
+// A valid X-Request-ID sent by the client or a reverse proxy is reused, so
+// logs can be correlated across hops.
+type RequestLogMiddleware struct {
+	next   http.Handler
+	config RequestLogConfig
+	logger *slog.Logger
+}
+
+// NewRequestLogMiddleware wraps next with request IDs and request logging.
+func NewRequestLogMiddleware(next http.Handler, config RequestLogConfig, logger *slog.Logger) *RequestLogMiddleware {
+	return &RequestLogMiddleware{
+		next:   next,
+		config: config,
+		logger: logger,
+	}
+}
+
+// ServeHTTP assigns the request ID, serves the request and logs the outcome.
+func (m *RequestLogMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(requestIDHeader)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	w.Header().Set(requestIDHeader, id)
+	r = r.WithContext(logging.WithRequestID(r.Context(), id))
+
+	if !m.config.Enabled || m.skipped(r.URL.Path) {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	m.next.ServeHTTP(rec, r)
+
+	level := slog.LevelInfo
+	if rec.status >= http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+	m.logger.Log(r.Context(), level, "http request",
+		"method", r.Method,
+		"path", r.URL.Path,
+		"status", rec.status,
+		"bytes", rec.bytes,
+		"duration_ms", time.Since(start).Milliseconds(),
+		"remote_addr", r.RemoteAddr,
+	)
+}
+
+func (m *RequestLogMiddleware) skipped(path string) bool {
+	for _, prefix := range m.config.SkipPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// validRequestID reports whether a client-supplied request ID is safe to
+// reuse in headers and logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 16-character hex ID.
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// statusRecorder captures the status code and body size of a response.
+// It unwraps to the underlying writer so http.ResponseController keeps
+// working for streaming handlers.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush forwards to the underlying writer so streamed responses are not
+// buffered.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,90 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/logging"
+)
+
+func TestRequestLogMiddleware(t *testing.T) {
+	newLogger := func(buf *bytes.Buffer) *slog.Logger {
+		return slog.New(logging.NewContextHandler(slog.NewJSONHandler(buf, nil)))
+	}
+
+	t.Run("logs the request with a generated ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		var seenID string
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seenID = logging.RequestID(r.Context())
+			w.WriteHeader(http.StatusTeapot)
+		})
+		m := NewRequestLogMiddleware(next, RequestLogConfig{Enabled: true}, newLogger(&buf))
+
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/channels", nil))
+
+		id := rec.Header().Get("X-Request-ID")
+		if len(id) != 16 {
+			t.Fatalf("expected a generated 16-character request ID, got %q", id)
+		}
+		if seenID != id {
+			t.Errorf("expected handler context to carry %q, got %q", id, seenID)
+		}
+
+		var record map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("failed to decode log line: %v", err)
+		}
+		if record["request_id"] != id || record["path"] != "/api/channels" || record["status"] != float64(http.StatusTeapot) {
+			t.Errorf("unexpected log record %v", record)
+		}
+	})
+
+	t.Run("reuses a valid incoming request ID", func(t *testing.T) {
+		m := NewRequestLogMiddleware(http.NotFoundHandler(), RequestLogConfig{}, newLogger(&bytes.Buffer{}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Request-ID", "proxy-123")
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("X-Request-ID"); got != "proxy-123" {
+			t.Errorf("expected proxy-123, got %q", got)
+		}
+	})
+
+	t.Run("replaces an invalid incoming request ID", func(t *testing.T) {
+		m := NewRequestLogMiddleware(http.NotFoundHandler(), RequestLogConfig{}, newLogger(&bytes.Buffer{}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Request-ID", "bad id\n")
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("X-Request-ID"); got == "bad id\n" || got == "" {
+			t.Errorf("expected a generated request ID, got %q", got)
+		}
+	})
+
+	t.Run("does not log skipped paths or when disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		skipping := NewRequestLogMiddleware(http.NotFoundHandler(), RequestLogConfig{Enabled: true, SkipPaths: []string{"/metrics"}}, newLogger(&buf))
+		skipping.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		disabled := NewRequestLogMiddleware(http.NotFoundHandler(), RequestLogConfig{}, newLogger(&buf))
+		rec := httptest.NewRecorder()
+		disabled.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/channels", nil))
+
+		if buf.Len() != 0 {
+			t.Errorf("expected no log lines, got %s", buf.String())
+		}
+		if rec.Header().Get("X-Request-ID") == "" {
+			t.Error("expected a request ID even when logging is disabled")
+		}
+	})
+}
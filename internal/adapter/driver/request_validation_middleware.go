@@ -0,0 +1,494 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxValidatedBodySize bounds the request bodies read for validation.
+const maxValidatedBodySize = 1 << 20
+
+// openAPIDocument is the part of an OpenAPI 3.0 document that request
+// validation needs.
+type openAPIDocument struct {
+	Paths      map[string]*openAPIPathItem `json:"paths"`
+	Components struct {
+		Schemas    map[string]*openAPISchema    `json:"schemas"`
+		Parameters map[string]*openAPIParameter `json:"parameters"`
+	} `json:"components"`
+}
+
+type openAPIPathItem struct {
+	Parameters []*openAPIParameter `json:"parameters"`
+	Get        *openAPIOperation   `json:"get"`
+	Put        *openAPIOperation   `json:"put"`
+	Post       *openAPIOperation   `json:"post"`
+	Patch      *openAPIOperation   `json:"patch"`
+	Delete     *openAPIOperation   `json:"delete"`
+}
+
+// operation returns the operation for method, or nil if none is described.
+func (p *openAPIPathItem) operation(method string) *openAPIOperation {
+	switch method {
+	case http.MethodGet:
+		return p.Get
+	case http.MethodPut:
+		return p.Put
+	case http.MethodPost:
+		return p.Post
+	case http.MethodPatch:
+		return p.Patch
+	case http.MethodDelete:
+		return p.Delete
+	}
+	return nil
+}
+
+type openAPIOperation struct {
+	Parameters  []*openAPIParameter `json:"parameters"`
+	RequestBody *openAPIRequestBody `json:"requestBody"`
+}
+
+type openAPIParameter struct {
+	Ref      string         `json:"$ref"`
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool `json:"required"`
+	Content  map[string]struct {
+		Schema *openAPISchema `json:"schema"`
+	} `json:"content"`
+}
+
+type openAPISchema struct {
+	Ref              string                    `json:"$ref"`
+	Type             string                    `json:"type"`
+	Nullable         bool                      `json:"nullable"`
+	Required         []string                  `json:"required"`
+	Properties       map[string]*openAPISchema `json:"properties"`
+	Items            *openAPISchema            `json:"items"`
+	Enum             []any                     `json:"enum"`
+	Minimum          *float64                  `json:"minimum"`
+	Maximum          *float64                  `json:"maximum"`
+	ExclusiveMinimum bool                      `json:"exclusiveMinimum"`
+	ExclusiveMaximum bool                      `json:"exclusiveMaximum"`
+	MinLength        *int                      `json:"minLength"`
+	MaxLength        *int                      `json:"maxLength"`
+	MinItems         *int                      `json:"minItems"`
+	MaxItems         *int                      `json:"maxItems"`
+	Pattern          string                    `json:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// validationRoute is a path of the document split into segments, where
+// segments in braces match any value.
+type validationRoute struct {
+	segments []string
+	literals int
+	item     *openAPIPathItem
+}
+
+// RequestValidationMiddleware rejects requests that do not match the
+// OpenAPI document of the versioned API before they reach the handlers:
+// query and header parameters are checked against their schemas and JSON
+// bodies against the request body schema. Paths and methods the document
+// does not describe pass through untouched.
+type RequestValidationMiddleware struct {
+	next   http.Handler
+	doc    *openAPIDocument
+	routes []validationRoute
+}
+
+// NewRequestValidationMiddleware wraps next with validation against the
+// embedded OpenAPI document.
+func NewRequestValidationMiddleware(next http.Handler) (*RequestValidationMiddleware, error) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	m := &RequestValidationMiddleware{next: next, doc: &doc}
+	for _, schema := range doc.Components.Schemas {
+		if err := compilePatterns(schema); err != nil {
+			return nil, err
+		}
+	}
+	for _, param := range doc.Components.Parameters {
+		if err := compilePatterns(param.Schema); err != nil {
+			return nil, err
+		}
+	}
+	for path, item := range doc.Paths {
+		route := validationRoute{segments: strings.Split(strings.Trim(path, "/"), "/"), item: item}
+		for _, segment := range route.segments {
+			if !strings.HasPrefix(segment, "{") {
+				route.literals++
+			}
+		}
+		m.routes = append(m.routes, route)
+
+		for _, op := range []*openAPIOperation{item.Get, item.Put, item.Post, item.Patch, item.Delete} {
+			if op == nil {
+				continue
+			}
+			for _, param := range append(slices.Clone(item.Parameters), op.Parameters...) {
+				if err := compilePatterns(param.Schema); err != nil {
+					return nil, err
+				}
+			}
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					if err := compilePatterns(media.Schema); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// compilePatterns compiles the patterns of s and the schemas nested in it.
+func compilePatterns(s *openAPISchema) error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q in OpenAPI document: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	for _, p := range s.Properties {
+		if err := compilePatterns(p); err != nil {
+			return err
+		}
+	}
+	return compilePatterns(s.Items)
+}
+
+// ServeHTTP validates the request before passing it on.
+func (m *RequestValidationMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	item := m.match(r.URL.Path)
+	if item == nil {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+	op := item.operation(r.Method)
+	if op == nil {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	for _, param := range append(slices.Clone(item.Parameters), op.Parameters...) {
+		if err := m.validateParameter(r, m.parameter(param)); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return
+		}
+	}
+
+	if op.RequestBody != nil {
+		if media, ok := op.RequestBody.Content["application/json"]; ok {
+			status, err := m.validateBody(r, op.RequestBody.Required, media.Schema)
+			if err != nil {
+				writeError(w, status, err.Error())
+				return
+			}
+		}
+	}
+
+	m.next.ServeHTTP(w, r)
+}
+
+// match returns the path item describing path, preferring literal segments
+// over parameters, or nil if the document does not describe it.
+func (m *RequestValidationMiddleware) match(path string) *openAPIPathItem {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var best *validationRoute
+	for i, route := range m.routes {
+		if len(route.segments) != len(segments) || (best != nil && route.literals <= best.literals) {
+			continue
+		}
+		matched := true
+		for j, segment := range route.segments {
+			if strings.HasPrefix(segment, "{") {
+				matched = segments[j] != ""
+			} else {
+				matched = segment == segments[j]
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			best = &m.routes[i]
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.item
+}
+
+// parameter resolves a parameter reference.
+func (m *RequestValidationMiddleware) parameter(p *openAPIParameter) *openAPIParameter {
+	if name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/"); ok {
+		if resolved, ok := m.doc.Components.Parameters[name]; ok {
+			return resolved
+		}
+	}
+	return p
+}
+
+// schema resolves a schema reference.
+func (m *RequestValidationMiddleware) schema(s *openAPISchema) *openAPISchema {
+	if s == nil {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/"); ok {
+		return m.doc.Components.Schemas[name]
+	}
+	return s
+}
+
+// validateParameter checks the query or header values of a parameter.
+// Path parameters are matched by the route and not checked further.
+func (m *RequestValidationMiddleware) validateParameter(r *http.Request, p *openAPIParameter) error {
+	var values []string
+	switch p.In {
+	case "query":
+		values = r.URL.Query()[p.Name]
+	case "header":
+		values = r.Header.Values(p.Name)
+	default:
+		return nil
+	}
+
+	if len(values) == 0 {
+		if p.Required {
+			return fmt.Errorf("%s: is required", p.Name)
+		}
+		return nil
+	}
+
+	schema := m.schema(p.Schema)
+	for _, raw := range values {
+		value, err := parseParameterValue(raw, schema)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Name, err)
+		}
+		if err := m.validateValue(schema, value, p.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseParameterValue converts a parameter to the JSON type of its schema.
+func parseParameterValue(raw string, s *openAPISchema) (any, error) {
+	if s == nil {
+		return raw, nil
+	}
+	switch s.Type {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return float64(n), nil
+	case "number":
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("must be a boolean")
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+// validateBody checks a JSON request body and puts it back for the handler.
+// On failure it returns the status code to answer with.
+func (m *RequestValidationMiddleware) validateBody(r *http.Request, required bool, schema *openAPISchema) (int, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxValidatedBodySize+1))
+	if err != nil {
+		return http.StatusBadRequest, errors.New("invalid request body")
+	}
+	if len(body) > maxValidatedBodySize {
+		return http.StatusRequestEntityTooLarge, errors.New("request body too large")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if required {
+			return http.StatusBadRequest, errors.New("invalid request: request body is required")
+		}
+		return 0, nil
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || mediaType != "application/json" {
+			return http.StatusUnsupportedMediaType, errors.New("content type must be application/json")
+		}
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return http.StatusBadRequest, errors.New("invalid request body")
+	}
+	if err := m.validateValue(m.schema(schema), value, ""); err != nil {
+		return http.StatusBadRequest, errors.New("invalid request: " + err.Error())
+	}
+	return 0, nil
+}
+
+// validateValue checks a decoded JSON value against s. Path names the value
+// in error messages; an empty path is the request body.
+func (m *RequestValidationMiddleware) validateValue(s *openAPISchema, value any, path string) error {
+	s = m.schema(s)
+	if s == nil {
+		return nil
+	}
+	name := path
+	if name == "" {
+		name = "body"
+	}
+
+	if value == nil {
+		if s.Nullable {
+			return nil
+		}
+		return fmt.Errorf("%s: must not be null", name)
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an object", name)
+		}
+		for _, field := range s.Required {
+			if _, ok := obj[field]; !ok {
+				return fmt.Errorf("%s: is required", joinValuePath(path, field))
+			}
+		}
+		for _, field := range slices.Sorted(maps.Keys(s.Properties)) {
+			if v, ok := obj[field]; ok {
+				if err := m.validateValue(s.Properties[field], v, joinValuePath(path, field)); err != nil {
+					return err
+				}
+			}
+		}
+
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an array", name)
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			return fmt.Errorf("%s: must have at least %d items", name, *s.MinItems)
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			return fmt.Errorf("%s: must have at most %d items", name, *s.MaxItems)
+		}
+		for i, v := range arr {
+			if err := m.validateValue(s.Items, v, fmt.Sprintf("%s[%d]", name, i)); err != nil {
+				return err
+			}
+		}
+
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: must be a string", name)
+		}
+		length := utf8.RuneCountInString(str)
+		if s.MinLength != nil && length < *s.MinLength {
+			if *s.MinLength == 1 {
+				return fmt.Errorf("%s: must not be empty", name)
+			}
+			return fmt.Errorf("%s: must be at least %d characters", name, *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fmt.Errorf("%s: must be at most %d characters", name, *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			return fmt.Errorf("%s: has an invalid format", name)
+		}
+
+	case "integer", "number":
+		f, ok := value.(float64)
+		if s.Type == "integer" && (!ok || f != math.Trunc(f)) {
+			return fmt.Errorf("%s: must be an integer", name)
+		}
+		if !ok {
+			return fmt.Errorf("%s: must be a number", name)
+		}
+		if s.Minimum != nil {
+			if s.ExclusiveMinimum && f <= *s.Minimum {
+				return fmt.Errorf("%s: must be greater than %s", name, formatLimit(*s.Minimum))
+			}
+			if f < *s.Minimum {
+				return fmt.Errorf("%s: must be at least %s", name, formatLimit(*s.Minimum))
+			}
+		}
+		if s.Maximum != nil {
+			if s.ExclusiveMaximum && f >= *s.Maximum {
+				return fmt.Errorf("%s: must be less than %s", name, formatLimit(*s.Maximum))
+			}
+			if f > *s.Maximum {
+				return fmt.Errorf("%s: must be at most %s", name, formatLimit(*s.Maximum))
+			}
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: must be a boolean", name)
+		}
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return reflect.DeepEqual(e, value) }) {
+		allowed := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			allowed[i] = fmt.Sprintf("%q", fmt.Sprint(e))
+		}
+		return fmt.Errorf("%s: must be one of %s", name, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+func joinValuePath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func formatLimit(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,102 @@
+package driver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIHTTPHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewOpenAPIHTTPHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var doc struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("expected an OpenAPI 3 document, got %q", doc.OpenAPI)
+	}
+	for _, path := range []string{"/health", "/channels", "/channels/{name}", "/streams", "/streams/{infoHash}", "/epg/mappings/{channelName}"} {
+		if _, ok := doc.Paths[path]; !ok {
+			t.Errorf("expected %s to be documented", path)
+		}
+	}
+}
+
+func TestRequestValidationMiddleware(t *testing.T) {
+	var gotBody string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+	m, err := NewRequestValidationMiddleware(next)
+	if err != nil {
+		t.Fatalf("failed to load the OpenAPI document: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantError   string
+	}{
+		{name: "valid body", method: http.MethodPost, target: "/channels", body: `{"name":"La 1"}`, wantStatus: http.StatusOK},
+		{name: "missing required field", method: http.MethodPost, target: "/channels", body: `{}`, wantStatus: http.StatusBadRequest, wantError: "invalid request: name: is required"},
+		{name: "empty string", method: http.MethodPost, target: "/streams", body: `{"info_hash":"","channel_name":"La 1"}`, wantStatus: http.StatusBadRequest, wantError: "invalid request: info_hash: must not be empty"},
+		{name: "wrong type", method: http.MethodPatch, target: "/channels/La%201", body: `{"number":"7"}`, wantStatus: http.StatusBadRequest, wantError: "invalid request: number: must be an integer"},
+		{name: "value out of range", method: http.MethodPatch, target: "/channels/La 1", body: `{"number":-1}`, wantStatus: http.StatusBadRequest, wantError: "invalid request: number: must be at least 0"},
+		{name: "value not in enum", method: http.MethodPatch, target: "/channels/La 1", body: `{"transcode_audio":"aac"}`, wantStatus: http.StatusBadRequest, wantError: `invalid request: transcode_audio: must be one of "", "all", "ac3", "mp3"`},
+		{name: "array items", method: http.MethodPut, target: "/channels/order", body: `{"names":["a",1]}`, wantStatus: http.StatusBadRequest, wantError: "invalid request: names[1]: must be a string"},
+		{name: "missing body", method: http.MethodPost, target: "/channels/merge", wantStatus: http.StatusBadRequest, wantError: "invalid request: request body is required"},
+		{name: "malformed JSON", method: http.MethodPost, target: "/channels", body: `{"name":`, wantStatus: http.StatusBadRequest, wantError: "invalid request body"},
+		{name: "wrong content type", method: http.MethodPost, target: "/channels", contentType: "text/plain", body: `{"name":"La 1"}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "valid query", method: http.MethodGet, target: "/channels?page=2&per_page=10", wantStatus: http.StatusOK},
+		{name: "invalid query", method: http.MethodGet, target: "/channels?page=0", wantStatus: http.StatusBadRequest, wantError: "invalid request: page: must be at least 1"},
+		{name: "non-numeric query", method: http.MethodGet, target: "/channels/duplicates?min_score=high", wantStatus: http.StatusBadRequest, wantError: "invalid request: min_score: must be a number"},
+		{name: "exclusive minimum", method: http.MethodGet, target: "/channels/duplicates?min_score=0", wantStatus: http.StatusBadRequest, wantError: "invalid request: min_score: must be greater than 0"},
+		{name: "literal path preferred", method: http.MethodGet, target: "/streams/abc/stats/history?range=week", wantStatus: http.StatusBadRequest, wantError: "invalid request: range: has an invalid format"},
+		{name: "undocumented path", method: http.MethodPost, target: "/webhooks", body: `{}`, wantStatus: http.StatusOK},
+		{name: "undocumented method", method: http.MethodPut, target: "/channels", body: `{}`, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req.URL.Path, req.URL.RawQuery, _ = strings.Cut(tt.target, "?")
+			req.Header.Set("Content-Type", "application/json")
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			gotBody = ""
+			m.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantError != "" {
+				var resp errorResponse
+				_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+				if resp.Error != tt.wantError {
+					t.Errorf("expected error %q, got %q", tt.wantError, resp.Error)
+				}
+			}
+			if tt.wantStatus == http.StatusOK && gotBody != tt.body {
+				t.Errorf("expected the handler to read body %q, got %q", tt.body, gotBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package driver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/scheduler"
+)
+
+// SchedulerHTTPHandler exposes run metrics for the background schedulers.
+type SchedulerHTTPHandler struct {
+	schedulers []*scheduler.Scheduler
+}
+
+// NewSchedulerHTTPHandler creates a new handler reporting on the given schedulers.
+func NewSchedulerHTTPHandler(schedulers ...*scheduler.Scheduler) *SchedulerHTTPHandler {
+	return &SchedulerHTTPHandler{schedulers: schedulers}
+}
+
+// schedulerStatsResponse represents a scheduler's run metrics in JSON format.
+type schedulerStatsResponse struct {
+	Name        string `json:"name"`
+	Interval    string `json:"interval,omitempty"`
+	Schedule    string `json:"schedule"`
+	Running     bool   `json:"running"`
+	Runs        int    `json:"runs"`
+	Successes   int    `json:"successes"`
+	Failures    int    `json:"failures"`
+	LastRun     string `json:"last_run,omitempty"`
+	LastSuccess string `json:"last_success,omitempty"`
+	LastFailure string `json:"last_failure,omitempty"`
+	LastError   string `json:"last_error,omitempty"`
+	NextRun     string `json:"next_run,omitempty"`
+}
+
+// ServeHTTP handles GET /debug/schedulers.
+func (h *SchedulerHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	response := make([]schedulerStatsResponse, len(h.schedulers))
+	for i, s := range h.schedulers {
+		st := s.Stats()
+		var interval string
+		if st.Interval > 0 {
+			interval = st.Interval.String()
+		}
+		response[i] = schedulerStatsResponse{
+			Name:        st.Name,
+			Interval:    interval,
+			Schedule:    st.Schedule,
+			Running:     st.Running,
+			Runs:        st.Runs,
+			Successes:   st.Successes,
+			Failures:    st.Failures,
+			LastRun:     formatOptionalTime(st.LastRun),
+			LastSuccess: formatOptionalTime(st.LastSuccess),
+			LastFailure: formatOptionalTime(st.LastFailure),
+			LastError:   st.LastError,
+			NextRun:     formatOptionalTime(st.NextRun),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// formatOptionalTime formats t as RFC 3339, or returns "" for the zero time.
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02T15:04:05Z07:00")
+}
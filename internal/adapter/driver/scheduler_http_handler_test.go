@@ -0,0 +1,65 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/scheduler"
+)
+
+func TestSchedulerHTTPHandler_ServeHTTP(t *testing.T) {
+	t.Run("GET /debug/schedulers reports run metrics", func(t *testing.T) {
+		failing := scheduler.New("epg-sync", 5*time.Millisecond, func(ctx context.Context) error {
+			return errors.New("source unreachable")
+		}, slog.Default())
+		idle := scheduler.New("stream-probe", time.Hour, func(ctx context.Context) error { return nil }, slog.Default())
+
+		failing.Start(context.Background())
+		deadline := time.Now().Add(time.Second)
+		for failing.Stats().Failures == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		failing.Stop()
+
+		handler := NewSchedulerHTTPHandler(failing, idle)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/schedulers", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp []schedulerStatsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 2 {
+			t.Fatalf("expected 2 schedulers, got %d", len(resp))
+		}
+		if resp[0].Name != "epg-sync" || resp[0].Failures == 0 || resp[0].LastError != "source unreachable" {
+			t.Errorf("unexpected epg-sync stats: %+v", resp[0])
+		}
+		if resp[0].LastFailure == "" {
+			t.Error("expected last_failure to be set")
+		}
+		if resp[1].Name != "stream-probe" || resp[1].Runs != 0 || resp[1].Interval != "1h0m0s" || resp[1].Schedule != "every 1h0m0s" {
+			t.Errorf("unexpected stream-probe stats: %+v", resp[1])
+		}
+	})
+
+	t.Run("POST /debug/schedulers returns 405", func(t *testing.T) {
+		handler := NewSchedulerHTTPHandler()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/schedulers", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package driver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// SearchHTTPHandler handles HTTP requests for discovering streams through
+// the AceStream engine's search.
+type SearchHTTPHandler struct {
+	service *application.StreamService
+}
+
+// NewSearchHTTPHandler creates a new HTTP handler for stream search.
+func NewSearchHTTPHandler(service *application.StreamService) *SearchHTTPHandler {
+	return &SearchHTTPHandler{service: service}
+}
+
+// searchResultResponse represents a stream search result in JSON format.
+type searchResultResponse struct {
+	InfoHash     string   `json:"info_hash"`
+	Name         string   `json:"name"`
+	Categories   []string `json:"categories"`
+	Bitrate      int      `json:"bitrate,omitempty"`
+	Availability float64  `json:"availability"`
+	ChannelName  string   `json:"channel_name,omitempty"`
+}
+
+// ServeHTTP handles GET /search?q=
+func (h *SearchHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	candidates, err := h.service.SearchStreams(r.Context(), r.URL.Query().Get("q"))
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrEmptySearchQuery):
+			writeError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, application.ErrSearchUnavailable):
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+		default:
+			writeError(w, http.StatusBadGateway, "acestream engine search failed")
+		}
+		return
+	}
+
+	response := make([]searchResultResponse, len(candidates))
+	for i, c := range candidates {
+		categories := c.Categories
+		if categories == nil {
+			categories = []string{}
+		}
+		response[i] = searchResultResponse{
+			InfoHash:     c.InfoHash,
+			Name:         c.Name,
+			Categories:   categories,
+			Bitrate:      c.Bitrate,
+			Availability: c.Availability,
+			ChannelName:  c.ChannelName,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,95 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// mockAceStreamSearcher is a mock implementation for testing.
+type mockAceStreamSearcher struct {
+	results []driven.SearchResult
+	err     error
+}
+
+func (m *mockAceStreamSearcher) Search(ctx context.Context, query string) ([]driven.SearchResult, error) {
+	return m.results, m.err
+}
+
+func TestSearchHTTPHandler(t *testing.T) {
+	newHandler := func(searcher driven.AceStreamSearcher) *SearchHTTPHandler {
+		attached, _ := stream.NewStream("6162630000000000000000000000000000000000", "DAZN 1", "")
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				return []stream.Stream{attached}, nil
+			},
+		}
+		service := application.NewStreamService(streamRepo, &mockChannelRepository{})
+		if searcher != nil {
+			service.SetSearcher(searcher)
+		}
+		return NewSearchHTTPHandler(service)
+	}
+
+	t.Run("GET /search returns candidates", func(t *testing.T) {
+		handler := newHandler(&mockAceStreamSearcher{results: []driven.SearchResult{
+			{InfoHash: "6162630000000000000000000000000000000000", Name: "DAZN 1 HD", Categories: []string{"sport"}, Availability: 1},
+			{InfoHash: "def", Name: "DAZN 2"},
+		}})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=dazn", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp []searchResultResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 2 {
+			t.Fatalf("expected 2 results, got %d", len(resp))
+		}
+		if resp[0].ChannelName != "DAZN 1" || resp[0].Categories[0] != "sport" {
+			t.Errorf("unexpected first result %+v", resp[0])
+		}
+		if resp[1].ChannelName != "" || resp[1].Categories == nil {
+			t.Errorf("unexpected second result %+v", resp[1])
+		}
+	})
+
+	t.Run("returns 400 for missing query", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(&mockAceStreamSearcher{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("returns 502 when the engine search fails", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(&mockAceStreamSearcher{err: errors.New("boom")}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=dazn", nil))
+
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("expected status 502, got %d", rec.Code)
+		}
+	})
+
+	t.Run("returns 503 without a search backend", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q=dazn", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package driver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// ClientSessionManager defines the client session operations needed by the handler.
+type ClientSessionManager interface {
+	ClientSessions() []application.ClientSession
+	DisconnectClient(id string) error
+}
+
+// SessionHTTPHandler exposes the clients currently connected to streams.
+type SessionHTTPHandler struct {
+	sessions ClientSessionManager
+}
+
+// NewSessionHTTPHandler creates a new HTTP handler for streaming client sessions.
+func NewSessionHTTPHandler(sessions ClientSessionManager) *SessionHTTPHandler {
+	return &SessionHTTPHandler{sessions: sessions}
+}
+
+// clientSessionResponse represents a connected streaming client in JSON format.
+type clientSessionResponse struct {
+	ID         string `json:"id"`
+	ClientIP   string `json:"client_ip"`
+	UserAgent  string `json:"user_agent"`
+	Channel    string `json:"channel,omitempty"`
+	PlayerID   string `json:"player_id,omitempty"`
+	InfoHash   string `json:"infohash"`
+	StartedAt  string `json:"started_at"`
+	BytesSent  int64  `json:"bytes_sent"`
+	BitrateBPS int64  `json:"bitrate_bps"`
+
+	WriteLatency writeLatencyResponse `json:"write_latency"`
+}
+
+// writeLatencyResponse represents how long writes to a client take.
+type writeLatencyResponse struct {
+	P50Ms      int64 `json:"p50_ms"`
+	P95Ms      int64 `json:"p95_ms"`
+	P99Ms      int64 `json:"p99_ms"`
+	MaxMs      int64 `json:"max_ms"`
+	SlowWrites int64 `json:"slow_writes"`
+	Stalls     int64 `json:"stalls"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *SessionHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/sessions")
+
+	// GET /api/sessions - list connected clients
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w)
+		return
+	}
+
+	// DELETE /api/sessions/{id} - disconnect a client
+	if r.Method == http.MethodDelete && path != "" {
+		h.handleDisconnect(w, strings.TrimPrefix(path, "/"))
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// handleList handles GET /api/sessions
+func (h *SessionHTTPHandler) handleList(w http.ResponseWriter) {
+	sessions := h.sessions.ClientSessions()
+
+	response := make([]clientSessionResponse, len(sessions))
+	for i, s := range sessions {
+		response[i] = clientSessionResponse{
+			ID:         s.ID,
+			ClientIP:   s.ClientIP,
+			UserAgent:  s.UserAgent,
+			Channel:    s.Channel,
+			PlayerID:   s.PlayerID,
+			InfoHash:   s.InfoHash,
+			StartedAt:  s.StartedAt.Format("2006-01-02T15:04:05Z07:00"),
+			BytesSent:  s.BytesSent,
+			BitrateBPS: s.Bitrate,
+			WriteLatency: writeLatencyResponse{
+				P50Ms:      s.WriteLatency.P50.Milliseconds(),
+				P95Ms:      s.WriteLatency.P95.Milliseconds(),
+				P99Ms:      s.WriteLatency.P99.Milliseconds(),
+				MaxMs:      s.WriteLatency.Max.Milliseconds(),
+				SlowWrites: s.WriteLatency.SlowWrites,
+				Stalls:     s.WriteLatency.Stalls,
+			},
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleDisconnect handles DELETE /api/sessions/{id}
+func (h *SessionHTTPHandler) handleDisconnect(w http.ResponseWriter, id string) {
+	if err := h.sessions.DisconnectClient(id); err != nil {
+		if errors.Is(err, application.ErrClientSessionNotFound) {
+			writeError(w, http.StatusNotFound, application.ErrClientSessionNotFound.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,141 @@
+package driver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/streaming"
+)
+
+type mockClientSessionManager struct {
+	sessions     []application.ClientSession
+	disconnected []string
+}
+
+func (m *mockClientSessionManager) ClientSessions() []application.ClientSession {
+	return m.sessions
+}
+
+func (m *mockClientSessionManager) DisconnectClient(id string) error {
+	for _, s := range m.sessions {
+		if s.ID == id {
+			m.disconnected = append(m.disconnected, id)
+			return nil
+		}
+	}
+	return application.ErrClientSessionNotFound
+}
+
+func TestSessionHTTPHandler_ServeHTTP(t *testing.T) {
+	startedAt := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	newManager := func() *mockClientSessionManager {
+		return &mockClientSessionManager{sessions: []application.ClientSession{{
+			ID:        "pid-1",
+			ClientIP:  "192.168.1.20",
+			UserAgent: "VLC/3.0.20",
+			Channel:   "HBO",
+			InfoHash:  "abc123",
+			StartedAt: startedAt,
+			BytesSent: 4096,
+			Bitrate:   8_000_000,
+			WriteLatency: streaming.WriteLatencyStats{
+				P50:        time.Millisecond,
+				P95:        40 * time.Millisecond,
+				P99:        600 * time.Millisecond,
+				Max:        2 * time.Second,
+				SlowWrites: 7,
+				Stalls:     1,
+			},
+		}}}
+	}
+
+	t.Run("GET /sessions lists connected clients", func(t *testing.T) {
+		handler := NewSessionHTTPHandler(newManager())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sessions", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp []clientSessionResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 1 {
+			t.Fatalf("expected 1 session, got %d", len(resp))
+		}
+		want := clientSessionResponse{
+			ID:         "pid-1",
+			ClientIP:   "192.168.1.20",
+			UserAgent:  "VLC/3.0.20",
+			Channel:    "HBO",
+			InfoHash:   "abc123",
+			StartedAt:  "2024-05-01T20:00:00Z",
+			BytesSent:  4096,
+			BitrateBPS: 8_000_000,
+			WriteLatency: writeLatencyResponse{
+				P50Ms:      1,
+				P95Ms:      40,
+				P99Ms:      600,
+				MaxMs:      2000,
+				SlowWrites: 7,
+				Stalls:     1,
+			},
+		}
+		if resp[0] != want {
+			t.Errorf("expected %+v, got %+v", want, resp[0])
+		}
+	})
+
+	t.Run("GET /sessions returns an empty list when nobody is watching", func(t *testing.T) {
+		handler := NewSessionHTTPHandler(&mockClientSessionManager{})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sessions", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if body := rec.Body.String(); body != "[]\n" {
+			t.Errorf("expected empty JSON array, got %q", body)
+		}
+	})
+
+	t.Run("DELETE /sessions/{id} disconnects the client", func(t *testing.T) {
+		manager := newManager()
+		handler := NewSessionHTTPHandler(manager)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/sessions/pid-1", nil))
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected status 204, got %d", rec.Code)
+		}
+		if len(manager.disconnected) != 1 || manager.disconnected[0] != "pid-1" {
+			t.Errorf("expected pid-1 to be disconnected, got %v", manager.disconnected)
+		}
+	})
+
+	t.Run("DELETE /sessions/{id} returns 404 for unknown session", func(t *testing.T) {
+		handler := NewSessionHTTPHandler(newManager())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/sessions/pid-404", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("POST /sessions returns 405", func(t *testing.T) {
+		handler := NewSessionHTTPHandler(newManager())
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/sessions", nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
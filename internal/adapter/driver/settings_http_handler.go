@@ -0,0 +1,544 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// BandwidthController defines the proxy operations needed to adjust
+// bandwidth limits at runtime.
+type BandwidthController interface {
+	BandwidthLimits() application.BandwidthLimits
+	SetBandwidthLimits(limits application.BandwidthLimits) error
+}
+
+// WriteTimeoutController defines the proxy operations needed to adjust
+// client write timeouts at runtime.
+type WriteTimeoutController interface {
+	WriteTimeout() time.Duration
+	SetWriteTimeout(d time.Duration)
+	WriteTimeoutRules() []application.WriteTimeoutRule
+	SetWriteTimeoutRules(rules []application.WriteTimeoutRule) error
+}
+
+// LogLevelController defines the operations needed to change the log level
+// at runtime. *slog.LevelVar implements it.
+type LogLevelController interface {
+	Level() slog.Level
+	Set(level slog.Level)
+}
+
+// ResilienceController defines the proxy operations needed to tune stream
+// reconnection, the engine circuit breaker and client buffers at runtime.
+type ResilienceController interface {
+	Resilience() application.ResilienceConfig
+	SetResilience(cfg application.ResilienceConfig) error
+}
+
+// StreamQueueController defines the proxy operations needed to inspect and
+// adjust the queue of stream requests waiting for a saturated engine.
+type StreamQueueController interface {
+	StreamQueue() application.StreamQueueOptions
+	SetStreamQueue(opts application.StreamQueueOptions) error
+	StreamQueueStats() application.StreamQueueStats
+}
+
+// PlayerProfileController defines the operations needed to change how
+// streams are adapted to players at runtime.
+type PlayerProfileController interface {
+	Profiles() []application.PlayerProfile
+	Fallback() application.PlayerProfile
+	SetProfiles(profiles []application.PlayerProfile, fallback application.PlayerProfile) error
+}
+
+// SettingsHTTPHandler handles HTTP requests for settings that can be
+// changed while the server runs. Changes last until the next restart.
+type SettingsHTTPHandler struct {
+	bandwidth     BandwidthController
+	writeTimeouts WriteTimeoutController
+	logLevel      LogLevelController
+	resilience    ResilienceController
+	players       PlayerProfileController
+	streamQueue   StreamQueueController
+}
+
+// NewSettingsHTTPHandler creates a new HTTP handler for runtime settings.
+func NewSettingsHTTPHandler(bandwidth BandwidthController) *SettingsHTTPHandler {
+	return &SettingsHTTPHandler{bandwidth: bandwidth}
+}
+
+// SetWriteTimeoutController enables GET and PUT /settings/write-timeouts.
+func (h *SettingsHTTPHandler) SetWriteTimeoutController(writeTimeouts WriteTimeoutController) {
+	h.writeTimeouts = writeTimeouts
+}
+
+// SetLogLevelController enables GET and PUT /settings/log-level.
+func (h *SettingsHTTPHandler) SetLogLevelController(logLevel LogLevelController) {
+	h.logLevel = logLevel
+}
+
+// SetResilienceController enables GET and PUT /settings/resilience.
+func (h *SettingsHTTPHandler) SetResilienceController(resilience ResilienceController) {
+	h.resilience = resilience
+}
+
+// SetStreamQueueController enables GET and PUT /settings/stream-queue.
+func (h *SettingsHTTPHandler) SetStreamQueueController(streamQueue StreamQueueController) {
+	h.streamQueue = streamQueue
+}
+
+// SetPlayerProfileController enables GET, PUT and DELETE
+// /settings/player-profiles.
+func (h *SettingsHTTPHandler) SetPlayerProfileController(players PlayerProfileController) {
+	h.players = players
+}
+
+// bandwidthSettings represents bandwidth limits in bytes per second in JSON
+// format; zero means unlimited. Fields left out of a PUT keep their value.
+type bandwidthSettings struct {
+	Global    *int64 `json:"global"`
+	PerClient *int64 `json:"per_client"`
+	PerStream *int64 `json:"per_stream"`
+}
+
+// writeTimeoutSettings represents the client write timeouts in JSON format,
+// as Go durations such as "30s". Fields left out of a PUT keep their value;
+// rules replace the current ones as a whole.
+type writeTimeoutSettings struct {
+	Default *string             `json:"default"`
+	Rules   *[]writeTimeoutRule `json:"rules"`
+}
+
+// writeTimeoutRule represents a write timeout rule in JSON format.
+type writeTimeoutRule struct {
+	UserAgent string `json:"user_agent,omitempty"`
+	Token     string `json:"token,omitempty"`
+	Timeout   string `json:"timeout"`
+}
+
+// logLevelSettings represents the log level in JSON format: debug, info,
+// warn or error.
+type logLevelSettings struct {
+	Level string `json:"level"`
+}
+
+// resilienceSettings represents the resilience settings in JSON format,
+// with durations such as "2s". Fields left out of a PUT keep their value.
+type resilienceSettings struct {
+	ReconnectAttempts       *int    `json:"reconnect_attempts"`
+	ReconnectInitialBackoff *string `json:"reconnect_initial_backoff"`
+	ReconnectMaxBackoff     *string `json:"reconnect_max_backoff"`
+	BreakerThreshold        *int    `json:"breaker_threshold"`
+	BreakerTimeout          *string `json:"breaker_timeout"`
+	ClientBufferSize        *int    `json:"client_buffer_size"`
+}
+
+// streamQueueSettings represents the stream queue settings in JSON format,
+// with the wait as a duration such as "30s". Fields left out of a PUT keep
+// their value. Depth, Queued and Rejected are read-only.
+type streamQueueSettings struct {
+	MaxWait  *string `json:"max_wait"`
+	MaxDepth *int    `json:"max_depth"`
+	Depth    int     `json:"depth"`
+	Queued   int64   `json:"queued"`
+	Rejected int64   `json:"rejected"`
+}
+
+// playerProfileSettings represents the player profiles in JSON format.
+// Fields left out of a PUT keep their value; profiles replace the current
+// ones as a whole.
+type playerProfileSettings struct {
+	Default  *playerProfileSetting   `json:"default"`
+	Profiles *[]playerProfileSetting `json:"profiles"`
+}
+
+// playerProfileSetting represents a player profile in JSON format.
+type playerProfileSetting struct {
+	Name            string `json:"name,omitempty"`
+	UserAgent       string `json:"user_agent,omitempty"`
+	Container       string `json:"container"`
+	ContentType     string `json:"content_type,omitempty"`
+	KeepAlive       bool   `json:"keep_alive,omitempty"`
+	DisableChunking bool   `json:"disable_chunking,omitempty"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *SettingsHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/settings")
+
+	// GET /settings/bandwidth - current bandwidth limits
+	if r.Method == http.MethodGet && path == "/bandwidth" {
+		writeJSON(w, http.StatusOK, toBandwidthSettings(h.bandwidth.BandwidthLimits()))
+		return
+	}
+
+	// PUT /settings/bandwidth - change bandwidth limits
+	if r.Method == http.MethodPut && path == "/bandwidth" {
+		h.handleUpdateBandwidth(w, r)
+		return
+	}
+
+	// GET /settings/write-timeouts - default write timeout and per-client rules
+	if r.Method == http.MethodGet && path == "/write-timeouts" && h.writeTimeouts != nil {
+		writeJSON(w, http.StatusOK, h.currentWriteTimeouts())
+		return
+	}
+
+	// PUT /settings/write-timeouts - change the default write timeout or rules
+	if r.Method == http.MethodPut && path == "/write-timeouts" && h.writeTimeouts != nil {
+		h.handleUpdateWriteTimeouts(w, r)
+		return
+	}
+
+	// GET /settings/log-level - current log level
+	if r.Method == http.MethodGet && path == "/log-level" && h.logLevel != nil {
+		writeJSON(w, http.StatusOK, toLogLevelSettings(h.logLevel.Level()))
+		return
+	}
+
+	// PUT /settings/log-level - change the log level
+	if r.Method == http.MethodPut && path == "/log-level" && h.logLevel != nil {
+		h.handleUpdateLogLevel(w, r)
+		return
+	}
+
+	// GET /settings/resilience - reconnection backoff, circuit breaker and buffer settings
+	if r.Method == http.MethodGet && path == "/resilience" && h.resilience != nil {
+		writeJSON(w, http.StatusOK, toResilienceSettings(h.resilience.Resilience()))
+		return
+	}
+
+	// PUT /settings/resilience - change the resilience settings
+	if r.Method == http.MethodPut && path == "/resilience" && h.resilience != nil {
+		h.handleUpdateResilience(w, r)
+		return
+	}
+
+	// GET /settings/stream-queue - how stream requests wait for a saturated engine
+	if r.Method == http.MethodGet && path == "/stream-queue" && h.streamQueue != nil {
+		writeJSON(w, http.StatusOK, h.currentStreamQueue())
+		return
+	}
+
+	// PUT /settings/stream-queue - change how long and how many requests wait
+	if r.Method == http.MethodPut && path == "/stream-queue" && h.streamQueue != nil {
+		h.handleUpdateStreamQueue(w, r)
+		return
+	}
+
+	// GET /settings/player-profiles - how streams are adapted to each player
+	if r.Method == http.MethodGet && path == "/player-profiles" && h.players != nil {
+		writeJSON(w, http.StatusOK, h.currentPlayerProfiles())
+		return
+	}
+
+	// PUT /settings/player-profiles - change the player profiles
+	if r.Method == http.MethodPut && path == "/player-profiles" && h.players != nil {
+		h.handleUpdatePlayerProfiles(w, r)
+		return
+	}
+
+	// DELETE /settings/player-profiles - restore the built-in player profiles
+	if r.Method == http.MethodDelete && path == "/player-profiles" && h.players != nil {
+		if err := h.players.SetProfiles(application.DefaultPlayerProfiles(), application.DefaultPlayerProfile()); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		writeJSON(w, http.StatusOK, h.currentPlayerProfiles())
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toBandwidthSettings(l application.BandwidthLimits) bandwidthSettings {
+	return bandwidthSettings{Global: &l.Global, PerClient: &l.PerClient, PerStream: &l.PerStream}
+}
+
+// handleUpdateBandwidth handles PUT /settings/bandwidth
+func (h *SettingsHTTPHandler) handleUpdateBandwidth(w http.ResponseWriter, r *http.Request) {
+	var req bandwidthSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	limits := h.bandwidth.BandwidthLimits()
+	if req.Global != nil {
+		limits.Global = *req.Global
+	}
+	if req.PerClient != nil {
+		limits.PerClient = *req.PerClient
+	}
+	if req.PerStream != nil {
+		limits.PerStream = *req.PerStream
+	}
+
+	if err := h.bandwidth.SetBandwidthLimits(limits); err != nil {
+		if errors.Is(err, application.ErrInvalidBandwidthLimit) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toBandwidthSettings(limits))
+}
+
+func (h *SettingsHTTPHandler) currentWriteTimeouts() writeTimeoutSettings {
+	def := h.writeTimeouts.WriteTimeout().String()
+	rules := []writeTimeoutRule{}
+	for _, rule := range h.writeTimeouts.WriteTimeoutRules() {
+		rules = append(rules, writeTimeoutRule{UserAgent: rule.UserAgent, Token: rule.Token, Timeout: rule.Timeout.String()})
+	}
+	return writeTimeoutSettings{Default: &def, Rules: &rules}
+}
+
+// handleUpdateWriteTimeouts handles PUT /settings/write-timeouts
+func (h *SettingsHTTPHandler) handleUpdateWriteTimeouts(w http.ResponseWriter, r *http.Request) {
+	var req writeTimeoutSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var def time.Duration
+	if req.Default != nil {
+		d, err := time.ParseDuration(*req.Default)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "default must be a duration above zero")
+			return
+		}
+		def = d
+	}
+	if req.Rules != nil {
+		rules := make([]application.WriteTimeoutRule, len(*req.Rules))
+		for i, rule := range *req.Rules {
+			timeout, err := time.ParseDuration(rule.Timeout)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, application.ErrInvalidWriteTimeoutRule.Error())
+				return
+			}
+			rules[i] = application.WriteTimeoutRule{UserAgent: rule.UserAgent, Token: rule.Token, Timeout: timeout}
+		}
+		if err := h.writeTimeouts.SetWriteTimeoutRules(rules); err != nil {
+			if errors.Is(err, application.ErrInvalidWriteTimeoutRule) {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+	}
+	if def > 0 {
+		h.writeTimeouts.SetWriteTimeout(def)
+	}
+
+	writeJSON(w, http.StatusOK, h.currentWriteTimeouts())
+}
+
+func toLogLevelSettings(level slog.Level) logLevelSettings {
+	return logLevelSettings{Level: strings.ToLower(level.String())}
+}
+
+// handleUpdateLogLevel handles PUT /settings/log-level
+func (h *SettingsHTTPHandler) handleUpdateLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req logLevelSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var level slog.Level
+	switch strings.ToLower(req.Level) {
+	case "debug":
+		level = slog.LevelDebug
+	case "info":
+		level = slog.LevelInfo
+	case "warn":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		writeError(w, http.StatusBadRequest, "level must be debug, info, warn or error")
+		return
+	}
+	h.logLevel.Set(level)
+
+	writeJSON(w, http.StatusOK, toLogLevelSettings(level))
+}
+
+func toResilienceSettings(cfg application.ResilienceConfig) resilienceSettings {
+	initial, maxBackoff, timeout := cfg.ReconnectInitialBackoff.String(), cfg.ReconnectMaxBackoff.String(), cfg.BreakerTimeout.String()
+	return resilienceSettings{
+		ReconnectAttempts:       &cfg.ReconnectAttempts,
+		ReconnectInitialBackoff: &initial,
+		ReconnectMaxBackoff:     &maxBackoff,
+		BreakerThreshold:        &cfg.BreakerThreshold,
+		BreakerTimeout:          &timeout,
+		ClientBufferSize:        &cfg.ClientBufferSize,
+	}
+}
+
+// handleUpdateResilience handles PUT /settings/resilience
+func (h *SettingsHTTPHandler) handleUpdateResilience(w http.ResponseWriter, r *http.Request) {
+	var req resilienceSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	cfg := h.resilience.Resilience()
+	if req.ReconnectAttempts != nil {
+		cfg.ReconnectAttempts = *req.ReconnectAttempts
+	}
+	if req.BreakerThreshold != nil {
+		cfg.BreakerThreshold = *req.BreakerThreshold
+	}
+	if req.ClientBufferSize != nil {
+		cfg.ClientBufferSize = *req.ClientBufferSize
+	}
+	for _, d := range []struct {
+		value *string
+		dst   *time.Duration
+	}{
+		{req.ReconnectInitialBackoff, &cfg.ReconnectInitialBackoff},
+		{req.ReconnectMaxBackoff, &cfg.ReconnectMaxBackoff},
+		{req.BreakerTimeout, &cfg.BreakerTimeout},
+	} {
+		if d.value == nil {
+			continue
+		}
+		parsed, err := time.ParseDuration(*d.value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, application.ErrInvalidResilienceConfig.Error())
+			return
+		}
+		*d.dst = parsed
+	}
+
+	if err := h.resilience.SetResilience(cfg); err != nil {
+		if errors.Is(err, application.ErrInvalidResilienceConfig) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toResilienceSettings(h.resilience.Resilience()))
+}
+
+func (h *SettingsHTTPHandler) currentStreamQueue() streamQueueSettings {
+	opts, stats := h.streamQueue.StreamQueue(), h.streamQueue.StreamQueueStats()
+	maxWait := opts.MaxWait.String()
+	return streamQueueSettings{
+		MaxWait:  &maxWait,
+		MaxDepth: &opts.MaxDepth,
+		Depth:    stats.Depth,
+		Queued:   stats.Queued,
+		Rejected: stats.Rejected,
+	}
+}
+
+// handleUpdateStreamQueue handles PUT /settings/stream-queue
+func (h *SettingsHTTPHandler) handleUpdateStreamQueue(w http.ResponseWriter, r *http.Request) {
+	var req streamQueueSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	opts := h.streamQueue.StreamQueue()
+	if req.MaxDepth != nil {
+		opts.MaxDepth = *req.MaxDepth
+	}
+	if req.MaxWait != nil {
+		parsed, err := time.ParseDuration(*req.MaxWait)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, application.ErrInvalidStreamQueue.Error())
+			return
+		}
+		opts.MaxWait = parsed
+	}
+
+	if err := h.streamQueue.SetStreamQueue(opts); err != nil {
+		if errors.Is(err, application.ErrInvalidStreamQueue) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.currentStreamQueue())
+}
+
+func toPlayerProfileSetting(p application.PlayerProfile) playerProfileSetting {
+	return playerProfileSetting{
+		Name:            p.Name,
+		UserAgent:       p.UserAgent,
+		Container:       string(p.Container),
+		ContentType:     p.ContentType,
+		KeepAlive:       p.KeepAlive,
+		DisableChunking: p.DisableChunking,
+	}
+}
+
+func fromPlayerProfile(p playerProfileSetting) application.PlayerProfile {
+	return application.PlayerProfile{
+		Name:            p.Name,
+		UserAgent:       p.UserAgent,
+		Container:       application.Container(p.Container),
+		ContentType:     p.ContentType,
+		KeepAlive:       p.KeepAlive,
+		DisableChunking: p.DisableChunking,
+	}
+}
+
+func (h *SettingsHTTPHandler) currentPlayerProfiles() playerProfileSettings {
+	fallback := toPlayerProfileSetting(h.players.Fallback())
+	profiles := []playerProfileSetting{}
+	for _, p := range h.players.Profiles() {
+		profiles = append(profiles, toPlayerProfileSetting(p))
+	}
+	return playerProfileSettings{Default: &fallback, Profiles: &profiles}
+}
+
+// handleUpdatePlayerProfiles handles PUT /settings/player-profiles
+func (h *SettingsHTTPHandler) handleUpdatePlayerProfiles(w http.ResponseWriter, r *http.Request) {
+	var req playerProfileSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	profiles, fallback := h.players.Profiles(), h.players.Fallback()
+	if req.Default != nil {
+		fallback = fromPlayerProfile(*req.Default)
+	}
+	if req.Profiles != nil {
+		profiles = make([]application.PlayerProfile, len(*req.Profiles))
+		for i, p := range *req.Profiles {
+			profiles[i] = fromPlayerProfile(p)
+		}
+	}
+
+	if err := h.players.SetProfiles(profiles, fallback); err != nil {
+		if errors.Is(err, application.ErrInvalidPlayerProfile) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.currentPlayerProfiles())
+}
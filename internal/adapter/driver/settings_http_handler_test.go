@@ -0,0 +1,277 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+func TestSettingsHTTPHandler_Bandwidth(t *testing.T) {
+	proxy := application.NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, nil)
+	handler := NewSettingsHTTPHandler(proxy)
+	do := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/settings/bandwidth", bytes.NewBufferString(body)))
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) application.BandwidthLimits {
+		t.Helper()
+		var resp struct {
+			Global    int64 `json:"global"`
+			PerClient int64 `json:"per_client"`
+			PerStream int64 `json:"per_stream"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return application.BandwidthLimits{Global: resp.Global, PerClient: resp.PerClient, PerStream: resp.PerStream}
+	}
+
+	rec := do(http.MethodGet, "")
+	if rec.Code != http.StatusOK || decode(rec) != (application.BandwidthLimits{}) {
+		t.Errorf("expected no limits by default, got status %d", rec.Code)
+	}
+
+	rec = do(http.MethodPut, `{"global":5000000,"per_stream":2000000}`)
+	want := application.BandwidthLimits{Global: 5000000, PerStream: 2000000}
+	if rec.Code != http.StatusOK || decode(rec) != want {
+		t.Fatalf("expected the limits to be updated, got status %d", rec.Code)
+	}
+
+	rec = do(http.MethodPut, `{"per_client":1000000}`)
+	want.PerClient = 1000000
+	if got := decode(rec); got != want || proxy.BandwidthLimits() != want {
+		t.Errorf("expected omitted limits to be kept, got %+v", got)
+	}
+
+	if rec := do(http.MethodPut, `{"global":-1}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a negative limit, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, `{`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid body, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rec.Code)
+	}
+}
+
+func TestSettingsHTTPHandler_WriteTimeouts(t *testing.T) {
+	proxy := application.NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, nil)
+	handler := NewSettingsHTTPHandler(proxy)
+	do := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/settings/write-timeouts", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	if rec := do(http.MethodGet, ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 without a write timeout controller, got %d", rec.Code)
+	}
+	handler.SetWriteTimeoutController(proxy)
+
+	rec := do(http.MethodPut, `{"rules":[{"user_agent":"(?i)tizen","timeout":"30s"},{"token":"tv","timeout":"1m"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp writeTimeoutSettings
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if *resp.Default != "10s" || len(*resp.Rules) != 2 || (*resp.Rules)[1].Timeout != "1m0s" {
+		t.Errorf("unexpected settings %q %+v", *resp.Default, *resp.Rules)
+	}
+
+	if rec := do(http.MethodPut, `{"default":"20s"}`); rec.Code != http.StatusOK || proxy.WriteTimeout() != 20*time.Second {
+		t.Errorf("expected the default to change, got status %d and %v", rec.Code, proxy.WriteTimeout())
+	}
+	if len(proxy.WriteTimeoutRules()) != 2 {
+		t.Error("expected omitted rules to be kept")
+	}
+
+	for _, body := range []string{
+		`{`,
+		`{"default":"0s"}`,
+		`{"rules":[{"user_agent":"VLC","timeout":"soon"}]}`,
+		`{"rules":[{"timeout":"5s"}]}`,
+		`{"rules":[{"user_agent":"(","timeout":"5s"}]}`,
+	} {
+		if rec := do(http.MethodPut, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+	if len(proxy.WriteTimeoutRules()) != 2 {
+		t.Error("expected rejected rules to leave the current ones")
+	}
+}
+
+func TestSettingsHTTPHandler_LogLevel(t *testing.T) {
+	proxy := application.NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, nil)
+	handler := NewSettingsHTTPHandler(proxy)
+	do := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/settings/log-level", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	if rec := do(http.MethodGet, ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 without a log level controller, got %d", rec.Code)
+	}
+
+	var level slog.LevelVar
+	handler.SetLogLevelController(&level)
+
+	rec := do(http.MethodGet, "")
+	if rec.Code != http.StatusOK || rec.Body.String() != "{\"level\":\"info\"}\n" {
+		t.Errorf("expected level info, got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(http.MethodPut, `{"level":"DEBUG"}`)
+	if rec.Code != http.StatusOK || level.Level() != slog.LevelDebug {
+		t.Fatalf("expected the level to be changed to debug, got status %d and level %v", rec.Code, level.Level())
+	}
+	if rec.Body.String() != "{\"level\":\"debug\"}\n" {
+		t.Errorf("expected level debug in the response, got %s", rec.Body.String())
+	}
+
+	for _, body := range []string{`{"level":"verbose"}`, `{`} {
+		if rec := do(http.MethodPut, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+	if level.Level() != slog.LevelDebug {
+		t.Errorf("expected rejected updates to keep the level, got %v", level.Level())
+	}
+}
+
+func TestSettingsHTTPHandler_Resilience(t *testing.T) {
+	proxy := application.NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, nil)
+	handler := NewSettingsHTTPHandler(proxy)
+	do := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/settings/resilience", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	if rec := do(http.MethodGet, ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 without a resilience controller, got %d", rec.Code)
+	}
+	handler.SetResilienceController(proxy)
+
+	rec := do(http.MethodPut, `{"reconnect_attempts":5,"reconnect_max_backoff":"1m","client_buffer_size":1048576}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp resilienceSettings
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if *resp.ReconnectAttempts != 5 || *resp.ReconnectInitialBackoff != "2s" || *resp.ReconnectMaxBackoff != "1m0s" {
+		t.Errorf("unexpected settings %d %q %q", *resp.ReconnectAttempts, *resp.ReconnectInitialBackoff, *resp.ReconnectMaxBackoff)
+	}
+	if cfg := proxy.Resilience(); cfg.ReconnectAttempts != 5 || cfg.ClientBufferSize != 1048576 || cfg.BreakerThreshold != 5 {
+		t.Errorf("expected the proxy to be updated and omitted fields kept, got %+v", cfg)
+	}
+
+	for _, body := range []string{
+		`{`,
+		`{"reconnect_attempts":0}`,
+		`{"breaker_timeout":"soon"}`,
+		`{"reconnect_initial_backoff":"2m"}`,
+	} {
+		if rec := do(http.MethodPut, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+	if cfg := proxy.Resilience(); cfg.ReconnectAttempts != 5 {
+		t.Errorf("expected rejected settings to leave the current ones, got %+v", cfg)
+	}
+}
+
+func TestSettingsHTTPHandler_StreamQueue(t *testing.T) {
+	proxy := application.NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, nil)
+	handler := NewSettingsHTTPHandler(proxy)
+	handler.SetStreamQueueController(proxy)
+	do := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/settings/stream-queue", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPut, `{"max_wait":"30s"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp streamQueueSettings
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if *resp.MaxWait != "30s" || *resp.MaxDepth != 0 || resp.Depth != 0 {
+		t.Errorf("unexpected settings %q %d %d", *resp.MaxWait, *resp.MaxDepth, resp.Depth)
+	}
+	if opts := proxy.StreamQueue(); opts.MaxWait != 30*time.Second {
+		t.Errorf("expected the proxy to be updated, got %+v", opts)
+	}
+
+	for _, body := range []string{`{`, `{"max_wait":"later"}`, `{"max_wait":"-1s"}`} {
+		if rec := do(http.MethodPut, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+	if opts := proxy.StreamQueue(); opts.MaxWait != 30*time.Second {
+		t.Errorf("expected rejected settings to leave the current ones, got %+v", opts)
+	}
+}
+
+func TestSettingsHTTPHandler_PlayerProfiles(t *testing.T) {
+	proxy := application.NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, nil)
+	players := application.NewPlayerProfiles()
+	handler := NewSettingsHTTPHandler(proxy)
+	do := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/settings/player-profiles", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	if rec := do(http.MethodGet, ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405 without a player profile controller, got %d", rec.Code)
+	}
+	handler.SetPlayerProfileController(players)
+
+	rec := do(http.MethodPut, `{"profiles":[{"name":"mpv","user_agent":"^mpv","container":"hls"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp playerProfileSettings
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(*resp.Profiles) != 1 || resp.Default.Name != "default" || players.Match("mpv 0.36").Container != application.ContainerHLS {
+		t.Errorf("unexpected settings %+v %+v", *resp.Profiles, *resp.Default)
+	}
+
+	if rec := do(http.MethodPut, `{"default":{"container":"ts","content_type":"video/mp2t"}}`); rec.Code != http.StatusOK ||
+		players.Match("VLC").ContentType != "video/mp2t" || len(players.Profiles()) != 1 {
+		t.Errorf("expected the default to change and the profiles to be kept, got status %d", rec.Code)
+	}
+
+	for _, body := range []string{
+		`{`,
+		`{"default":{"container":"mkv"}}`,
+		`{"profiles":[{"container":"ts"}]}`,
+		`{"profiles":[{"user_agent":"TV","container":"ts","keep_alive":true,"disable_chunking":true}]}`,
+	} {
+		if rec := do(http.MethodPut, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("PUT %s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+
+	if rec := do(http.MethodDelete, ""); rec.Code != http.StatusOK || len(players.Profiles()) != len(application.DefaultPlayerProfiles()) {
+		t.Errorf("expected the built-in profiles restored, got status %d", rec.Code)
+	}
+}
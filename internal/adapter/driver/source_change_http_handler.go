@@ -0,0 +1,84 @@
+package driver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// defaultSourceChangesLimit is how many changes are listed when no limit is given.
+const defaultSourceChangesLimit = 100
+
+// SourceChangeHTTPHandler handles HTTP requests for the changes detected in
+// upstream Acestream sources.
+type SourceChangeHTTPHandler struct {
+	service *application.SourceChangeService
+}
+
+// NewSourceChangeHTTPHandler creates a new HTTP handler for source changes.
+func NewSourceChangeHTTPHandler(service *application.SourceChangeService) *SourceChangeHTTPHandler {
+	return &SourceChangeHTTPHandler{service: service}
+}
+
+// sourceChangeResponse represents a source change in JSON format.
+type sourceChangeResponse struct {
+	ChannelName string `json:"channel_name"`
+	InfoHash    string `json:"info_hash"`
+	Source      string `json:"source"`
+	Change      string `json:"change"`
+	DetectedAt  string `json:"detected_at"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *SourceChangeHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/sources")
+
+	// GET /sources/{name}/changes - changes detected in a source, most recent first
+	if r.Method == http.MethodGet && strings.HasSuffix(path, "/changes") {
+		name := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/changes")
+		h.handleChanges(w, r, name)
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// handleChanges handles GET /sources/{name}/changes with an optional limit
+// query parameter
+func (h *SourceChangeHTTPHandler) handleChanges(w http.ResponseWriter, r *http.Request, name string) {
+	if name != stream.SourceNewEra && name != stream.SourceElcano {
+		writeError(w, http.StatusNotFound, "unknown source")
+		return
+	}
+
+	limit := defaultSourceChangesLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	changes, err := h.service.Changes(r.Context(), name, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := make([]sourceChangeResponse, len(changes))
+	for i, c := range changes {
+		response[i] = sourceChangeResponse{
+			ChannelName: c.ChannelName(),
+			InfoHash:    c.InfoHash(),
+			Source:      c.Source(),
+			Change:      string(c.Kind()),
+			DetectedAt:  formatOptionalTime(c.DetectedAt()),
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,99 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/sourcechange"
+)
+
+// mockSourceChangeRepository is a mock implementation of driven.SourceChangeRepository for testing.
+type mockSourceChangeRepository struct {
+	findBySourceFunc func(ctx context.Context, source string, limit int) ([]sourcechange.Change, error)
+}
+
+func (m *mockSourceChangeRepository) FindSnapshot(ctx context.Context, source string) (map[string][]string, error) {
+	return nil, sourcechange.ErrSnapshotNotFound
+}
+
+func (m *mockSourceChangeRepository) Record(ctx context.Context, source string, snapshot map[string][]string, changes []sourcechange.Change) error {
+	return nil
+}
+
+func (m *mockSourceChangeRepository) FindBySource(ctx context.Context, source string, limit int) ([]sourcechange.Change, error) {
+	if m.findBySourceFunc != nil {
+		return m.findBySourceFunc(ctx, source, limit)
+	}
+	return []sourcechange.Change{}, nil
+}
+
+func (m *mockSourceChangeRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+func TestSourceChangeHTTPHandler(t *testing.T) {
+	detected := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	var gotSource string
+	var gotLimit int
+	repo := &mockSourceChangeRepository{
+		findBySourceFunc: func(ctx context.Context, source string, limit int) ([]sourcechange.Change, error) {
+			gotSource, gotLimit = source, limit
+			c, _ := sourcechange.NewChange(source, "La 1", "abc123", sourcechange.KindRemoved, detected)
+			return []sourcechange.Change{c}, nil
+		},
+	}
+	handler := NewSourceChangeHTTPHandler(application.NewSourceChangeService(repo, &mockStreamRepository{}, &mockChannelRepository{}, slog.Default()))
+
+	t.Run("lists the changes of a source", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sources/new-era/changes?limit=10", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if gotSource != "new-era" || gotLimit != 10 {
+			t.Errorf("expected new-era changes limited to 10, got %q and %d", gotSource, gotLimit)
+		}
+
+		var resp []sourceChangeResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := sourceChangeResponse{ChannelName: "La 1", InfoHash: "abc123", Source: "new-era", Change: "removed", DetectedAt: "2026-05-01T10:00:00Z"}
+		if len(resp) != 1 || resp[0] != want {
+			t.Errorf("unexpected response %+v", resp)
+		}
+	})
+
+	t.Run("defaults the limit", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sources/elcano/changes", nil))
+
+		if rec.Code != http.StatusOK || gotLimit != defaultSourceChangesLimit {
+			t.Errorf("expected status 200 and the default limit, got %d and %d", rec.Code, gotLimit)
+		}
+	})
+
+	tests := []struct {
+		target     string
+		wantStatus int
+	}{
+		{"/sources/unknown/changes", http.StatusNotFound},
+		{"/sources/new-era/changes?limit=0", http.StatusBadRequest},
+		{"/sources/new-era/changes?limit=x", http.StatusBadRequest},
+		{"/sources/new-era", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.target, tt.wantStatus, rec.Code)
+		}
+	}
+}
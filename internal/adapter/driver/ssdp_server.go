@@ -0,0 +1,206 @@
+package driver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ssdpGroup is the SSDP multicast group and port.
+var ssdpGroup = &net.UDPAddr{IP: net.IPv4(239, 255, 255, 250), Port: 1900}
+
+// ssdpMaxAge is how long announcements stay valid; they are repeated at half
+// that interval.
+const ssdpMaxAge = 30 * time.Minute
+
+// SSDPServer announces the DLNA media server on the LAN and answers
+// discovery searches, pointing renderers at the device description served
+// by DLNAHTTPHandler on httpPort.
+type SSDPServer struct {
+	config   DLNAConfig
+	httpPort int
+	logger   *slog.Logger
+}
+
+// NewSSDPServer creates an SSDP server for the media server described by
+// config, reachable over plain HTTP on httpPort.
+func NewSSDPServer(config DLNAConfig, httpPort int, logger *slog.Logger) *SSDPServer {
+	return &SSDPServer{config: config, httpPort: httpPort, logger: logger}
+}
+
+// Run announces the server and answers searches until ctx is done, then
+// announces that it is leaving.
+func (s *SSDPServer) Run(ctx context.Context) error {
+	conn, err := net.ListenMulticastUDP("udp4", nil, ssdpGroup)
+	if err != nil {
+		return fmt.Errorf("failed to join SSDP multicast group: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	announced := make(chan struct{})
+	go func() {
+		defer close(announced)
+		ticker := time.NewTicker(ssdpMaxAge / 2)
+		defer ticker.Stop()
+		s.notify("ssdp:alive")
+		for {
+			select {
+			case <-ctx.Done():
+				s.notify("ssdp:byebye")
+				return
+			case <-ticker.C:
+				s.notify("ssdp:alive")
+			}
+		}
+	}()
+
+	buf := make([]byte, 8192)
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				<-announced
+				return nil
+			}
+			return err
+		}
+		s.answerSearch(buf[:n], src)
+	}
+}
+
+// answerSearch sends the responses to an M-SEARCH from src, from a socket
+// whose local address is the one src reaches this host at.
+func (s *SSDPServer) answerSearch(packet []byte, src *net.UDPAddr) {
+	reply, err := net.DialUDP("udp4", nil, src)
+	if err != nil {
+		return
+	}
+	defer reply.Close()
+
+	localIP := reply.LocalAddr().(*net.UDPAddr).IP
+	for _, response := range s.searchResponses(packet, s.location(localIP)) {
+		if _, err := reply.Write(response); err != nil {
+			s.logger.Debug("ssdp search response failed", "remote_addr", src.String(), "error", err)
+			return
+		}
+	}
+}
+
+// searchResponses returns the responses to packet if it is an M-SEARCH for
+// this server, none otherwise.
+func (s *SSDPServer) searchResponses(packet []byte, location string) [][]byte {
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(packet)))
+	if err != nil || req.Method != "M-SEARCH" || req.Header.Get("MAN") != `"ssdp:discover"` {
+		return nil
+	}
+
+	st := req.Header.Get("ST")
+	var responses [][]byte
+	for _, target := range s.targets() {
+		if st != "ssdp:all" && st != target {
+			continue
+		}
+		responses = append(responses, []byte("HTTP/1.1 200 OK\r\n"+
+			"CACHE-CONTROL: max-age="+strconv.Itoa(int(ssdpMaxAge.Seconds()))+"\r\n"+
+			"EXT:\r\n"+
+			"LOCATION: "+location+"\r\n"+
+			"SERVER: "+ssdpServerName+"\r\n"+
+			"ST: "+target+"\r\n"+
+			"USN: "+s.usn(target)+"\r\n"+
+			"\r\n"))
+	}
+	return responses
+}
+
+// notify multicasts an announcement of every target with nts, ssdp:alive or
+// ssdp:byebye, on each interface that can reach the group.
+func (s *SSDPServer) notify(nts string) {
+	for _, ip := range multicastIPv4Addrs() {
+		conn, err := net.DialUDP("udp4", &net.UDPAddr{IP: ip}, ssdpGroup)
+		if err != nil {
+			s.logger.Debug("ssdp announcement failed", "addr", ip.String(), "error", err)
+			continue
+		}
+		for _, target := range s.targets() {
+			msg := "NOTIFY * HTTP/1.1\r\n" +
+				"HOST: " + ssdpGroup.String() + "\r\n" +
+				"NT: " + target + "\r\n" +
+				"NTS: " + nts + "\r\n" +
+				"USN: " + s.usn(target) + "\r\n"
+			if nts == "ssdp:alive" {
+				msg += "CACHE-CONTROL: max-age=" + strconv.Itoa(int(ssdpMaxAge.Seconds())) + "\r\n" +
+					"LOCATION: " + s.location(ip) + "\r\n" +
+					"SERVER: " + ssdpServerName + "\r\n"
+			}
+			if _, err := conn.Write([]byte(msg + "\r\n")); err != nil {
+				s.logger.Debug("ssdp announcement failed", "addr", ip.String(), "error", err)
+				break
+			}
+		}
+		conn.Close()
+	}
+}
+
+// ssdpServerName is the SERVER header of SSDP messages.
+const ssdpServerName = "Linux UPnP/1.0 iptv-manager/1.0"
+
+// targets lists what the server announces itself as.
+func (s *SSDPServer) targets() []string {
+	return []string{
+		"upnp:rootdevice",
+		"uuid:" + s.config.UUID,
+		dlnaDeviceType,
+		dlnaContentDirectoryType,
+		dlnaConnectionManagerType,
+	}
+}
+
+// usn returns the unique service name of target.
+func (s *SSDPServer) usn(target string) string {
+	device := "uuid:" + s.config.UUID
+	if target == device {
+		return device
+	}
+	return device + "::" + target
+}
+
+// location returns the device description URL at ip.
+func (s *SSDPServer) location(ip net.IP) string {
+	return "http://" + net.JoinHostPort(ip.String(), strconv.Itoa(s.httpPort)) + dlnaDescriptionPath
+}
+
+// multicastIPv4Addrs returns the IPv4 addresses of the interfaces that are
+// up and support multicast, loopback excluded.
+func multicastIPv4Addrs() []net.IP {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagMulticast == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				if ip := ipNet.IP.To4(); ip != nil {
+					ips = append(ips, ip)
+				}
+			}
+		}
+	}
+	return ips
+}
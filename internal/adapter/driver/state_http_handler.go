@@ -0,0 +1,156 @@
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/state"
+)
+
+// maxStateBodySize caps uploaded state documents.
+const maxStateBodySize = 10 << 20
+
+// StateHTTPHandler exports and applies the lineup as a single declarative
+// document, so it can be kept under version control.
+type StateHTTPHandler struct {
+	service *application.StateService
+}
+
+// NewStateHTTPHandler creates a new HTTP handler for the state document.
+func NewStateHTTPHandler(service *application.StateService) *StateHTTPHandler {
+	return &StateHTTPHandler{service: service}
+}
+
+// stateChangeSetResponse represents what an apply changed of one kind in
+// JSON format.
+type stateChangeSetResponse struct {
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+	Deleted []string `json:"deleted"`
+}
+
+// stateChangesResponse represents the outcome of applying a document in
+// JSON format.
+type stateChangesResponse struct {
+	Groups    stateChangeSetResponse `json:"groups"`
+	Channels  stateChangeSetResponse `json:"channels"`
+	Streams   stateChangeSetResponse `json:"streams"`
+	Overrides stateChangeSetResponse `json:"overrides"`
+	DryRun    bool                   `json:"dry_run,omitempty"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method.
+func (h *StateHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	// GET /state - export the lineup
+	case http.MethodGet:
+		h.handleExport(w, r)
+	// PUT /state - make the lineup match a document
+	case http.MethodPut:
+		h.handleApply(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleExport handles GET /state. The document is YAML unless ?format=json
+// or an Accept header of application/json asks for JSON.
+func (h *StateHTTPHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	asJSON := false
+	switch r.URL.Query().Get("format") {
+	case "json":
+		asJSON = true
+	case "yaml":
+	case "":
+		asJSON = r.Header.Get("Accept") == "application/json"
+	default:
+		writeError(w, http.StatusBadRequest, "format must be yaml or json")
+		return
+	}
+
+	doc, err := h.service.Export(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	if asJSON {
+		writeJSON(w, http.StatusOK, doc)
+		return
+	}
+	var buf bytes.Buffer
+	if err := state.EncodeYAML(&buf, doc); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Vary", "Accept")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// handleApply handles PUT /state. The body is a YAML or JSON document; with
+// ?dry_run=true nothing is changed and the response reports what would be.
+func (h *StateHTTPHandler) handleApply(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "dry_run must be a boolean")
+			return
+		}
+		dryRun = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxStateBodySize)
+	doc, err := state.Decode(r.Body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxErr):
+			writeError(w, http.StatusRequestEntityTooLarge, "state document too large")
+		case errors.Is(err, state.ErrInvalidDocument):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusBadRequest, "failed to read request body")
+		}
+		return
+	}
+
+	changes, err := h.service.Apply(r.Context(), doc, dryRun)
+	if err != nil {
+		if errors.Is(err, state.ErrInvalidDocument) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stateChangesResponse{
+		Groups:    toStateChangeSetResponse(changes.Groups),
+		Channels:  toStateChangeSetResponse(changes.Channels),
+		Streams:   toStateChangeSetResponse(changes.Streams),
+		Overrides: toStateChangeSetResponse(changes.Overrides),
+		DryRun:    changes.DryRun,
+	})
+}
+
+// toStateChangeSetResponse converts a change set, listing nothing changed
+// as empty arrays rather than null.
+func toStateChangeSetResponse(set application.StateChangeSet) stateChangeSetResponse {
+	orEmpty := func(s []string) []string {
+		if s == nil {
+			return []string{}
+		}
+		return s
+	}
+	return stateChangeSetResponse{
+		Created: orEmpty(set.Created),
+		Updated: orEmpty(set.Updated),
+		Deleted: orEmpty(set.Deleted),
+	}
+}
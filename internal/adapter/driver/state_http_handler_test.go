@@ -0,0 +1,123 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/rule"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+func TestStateHTTPHandler(t *testing.T) {
+	newHandler := func() (*StateHTTPHandler, *[]string) {
+		ch, _ := channel.NewChannel("DAZN 1")
+		st, _ := stream.NewStream("6162633132330000000000000000000000000000", "DAZN 1", stream.SourceNewEra)
+		g, _ := group.NewGroup("Sports")
+		saved := &[]string{}
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) { return []channel.Channel{ch}, nil },
+			saveFunc: func(ctx context.Context, ch channel.Channel) error {
+				*saved = append(*saved, ch.Name())
+				return nil
+			},
+		}
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) { return []stream.Stream{st}, nil },
+		}
+		service := application.NewStateService(channelRepo, streamRepo, newMockGroupRepository(g), &mockRuleRepository{rules: make(map[string]rule.Rule)})
+		return NewStateHTTPHandler(service), saved
+	}
+	serve := func(h http.Handler, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("GET /state exports YAML", func(t *testing.T) {
+		h, _ := newHandler()
+		rec := serve(h, http.MethodGet, "/state", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/yaml" {
+			t.Errorf("expected YAML, got %q", ct)
+		}
+		if body := rec.Body.String(); !strings.Contains(body, "  - name: DAZN 1\n") || !strings.Contains(body, "source: new-era") {
+			t.Errorf("unexpected body:\n%s", body)
+		}
+	})
+
+	t.Run("GET /state exports JSON on request", func(t *testing.T) {
+		h, _ := newHandler()
+		for _, rec := range []*httptest.ResponseRecorder{
+			serve(h, http.MethodGet, "/state?format=json", "", nil),
+			serve(h, http.MethodGet, "/state", "", http.Header{"Accept": {"application/json"}}),
+		} {
+			var doc struct {
+				Version int `json:"version"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil || doc.Version != 1 {
+				t.Errorf("expected a JSON document, got %v", err)
+			}
+		}
+		if rec := serve(h, http.MethodGet, "/state?format=xml", "", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for an unknown format, got %d", rec.Code)
+		}
+	})
+
+	t.Run("PUT /state applies a document", func(t *testing.T) {
+		h, saved := newHandler()
+		body := "version: 1\ngroups:\n  - name: Sports\nchannels:\n  - name: DAZN 1\n  - name: La 1\n"
+		rec := serve(h, http.MethodPut, "/state?dry_run=true", body, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp stateChangesResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if !resp.DryRun || len(resp.Channels.Created) != 1 || resp.Channels.Created[0] != "La 1" || len(resp.Streams.Deleted) != 1 {
+			t.Errorf("unexpected response %+v", resp)
+		}
+		if len(*saved) != 0 {
+			t.Errorf("expected a dry run to save nothing, saved %v", *saved)
+		}
+
+		if rec := serve(h, http.MethodPut, "/state", body, nil); rec.Code != http.StatusOK || len(*saved) != 1 {
+			t.Errorf("expected La 1 saved, got status %d and %v", rec.Code, *saved)
+		}
+	})
+
+	t.Run("PUT /state rejects invalid documents", func(t *testing.T) {
+		h, _ := newHandler()
+		for _, body := range []string{
+			"version: 1\nchannel: []\n",
+			"version: 1\nchannels:\n  - name: A\n    group: missing\n",
+		} {
+			if rec := serve(h, http.MethodPut, "/state", body, nil); rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400 for %q, got %d", body, rec.Code)
+			}
+		}
+		if rec := serve(h, http.MethodPut, "/state?dry_run=maybe", "version: 1\n", nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for an invalid dry_run, got %d", rec.Code)
+		}
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		h, _ := newHandler()
+		if rec := serve(h, http.MethodPost, "/state", "", nil); rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
@@ -1,24 +1,83 @@
 package driver
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/alorle/iptv-manager/internal/application"
 	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/probe"
 	"github.com/alorle/iptv-manager/internal/stream"
 )
 
+// defaultStatsInterval is how often live stream stats are pushed when no
+// interval is configured.
+const defaultStatsInterval = 3 * time.Second
+
+// defaultWarmupDuration is how long a stream is warmed when the request does
+// not say.
+const defaultWarmupDuration = time.Minute
+
+// StreamStatsWatcher defines the proxy operations needed to push live engine
+// stats of a playing stream.
+type StreamStatsWatcher interface {
+	IsStreamActive(infoHash string) bool
+	WatchStats(ctx context.Context, infoHash string, interval time.Duration, fn func(driven.StreamStats) error) error
+}
+
 // StreamHTTPHandler handles HTTP requests for stream management.
 type StreamHTTPHandler struct {
-	service *application.StreamService
+	service       *application.StreamService
+	probeService  *application.ProbeService
+	mediaInfo     *application.MediaInfoService
+	stats         StreamStatsWatcher
+	statsInterval time.Duration
+	statsHistory  *application.StatsHistoryService
+	preview       *application.PreviewService
+	warmup        *application.WarmupService
 }
 
 // NewStreamHTTPHandler creates a new HTTP handler for streams.
-func NewStreamHTTPHandler(service *application.StreamService) *StreamHTTPHandler {
-	return &StreamHTTPHandler{service: service}
+// If probeService is nil, the health endpoint is not available.
+// If mediaInfo is nil, the probe endpoint is not available.
+func NewStreamHTTPHandler(service *application.StreamService, probeService *application.ProbeService, mediaInfo *application.MediaInfoService) *StreamHTTPHandler {
+	return &StreamHTTPHandler{service: service, probeService: probeService, mediaInfo: mediaInfo}
+}
+
+// SetStatsWatcher enables GET /streams/{infoHash}/stats/ws, which pushes the
+// engine stats of a playing stream every interval over a WebSocket. A
+// non-positive interval uses the default of three seconds.
+func (h *StreamHTTPHandler) SetStatsWatcher(stats StreamStatsWatcher, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultStatsInterval
+	}
+	h.stats = stats
+	h.statsInterval = interval
+}
+
+// SetStatsHistory enables GET /streams/{infoHash}/stats/history, which
+// returns the recorded engine stats of a stream aggregated for charting.
+func (h *StreamHTTPHandler) SetStatsHistory(history *application.StatsHistoryService) {
+	h.statsHistory = history
+}
+
+// SetPreviewService enables GET /streams/{infoHash}/preview.jpg, which
+// returns a recent still frame of the stream.
+func (h *StreamHTTPHandler) SetPreviewService(preview *application.PreviewService) {
+	h.preview = preview
+}
+
+// SetWarmupService enables POST /streams/{infoHash}/warmup, which keeps the
+// stream open server-side for a while before viewers tune in.
+func (h *StreamHTTPHandler) SetWarmupService(warmup *application.WarmupService) {
+	h.warmup = warmup
 }
 
 type streamRequest struct {
@@ -26,12 +85,76 @@ type streamRequest struct {
 	ChannelName string `json:"channel_name"`
 }
 
+// streamMoveRequest represents the JSON request body for moving a stream.
+type streamMoveRequest struct {
+	ChannelName string `json:"channel_name"`
+}
+
 type streamResponse struct {
 	InfoHash    string `json:"info_hash"`
 	ChannelName string `json:"channel_name"`
 	Source      string `json:"source"`
 }
 
+type streamWarmupResponse struct {
+	InfoHash string `json:"info_hash"`
+	Until    string `json:"until"`
+}
+
+type streamHealthResponse struct {
+	InfoHash    string              `json:"info_hash"`
+	ChannelName string              `json:"channel_name"`
+	Score       float64             `json:"score"`
+	HealthLevel string              `json:"health_level"`
+	Metrics     metricsResponse     `json:"metrics"`
+	LastProbe   probeResultResponse `json:"last_probe"`
+}
+
+type streamProbeResponse struct {
+	InfoHash   string               `json:"info_hash"`
+	Video      *videoInfoResponse   `json:"video"`
+	Audio      []audioTrackResponse `json:"audio"`
+	Bitrate    int64                `json:"bitrate"`
+	DurationMs int64                `json:"duration_ms"`
+}
+
+type streamStatsMessage struct {
+	InfoHash   string `json:"info_hash"`
+	Status     string `json:"status"`
+	Peers      int    `json:"peers"`
+	SpeedDown  int64  `json:"speed_down"`
+	SpeedUp    int64  `json:"speed_up"`
+	Downloaded int64  `json:"downloaded"`
+	Uploaded   int64  `json:"uploaded"`
+	Time       string `json:"time"`
+}
+
+type streamStatsHistoryResponse struct {
+	InfoHash string                     `json:"info_hash"`
+	Range    string                     `json:"range"`
+	Points   []streamStatsPointResponse `json:"points"`
+}
+
+type streamStatsPointResponse struct {
+	Time      string `json:"time"`
+	Samples   int    `json:"samples"`
+	Peers     int    `json:"peers"`
+	SpeedDown int64  `json:"speed_down"`
+	SpeedUp   int64  `json:"speed_up"`
+}
+
+type videoInfoResponse struct {
+	Codec  string `json:"codec"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+type audioTrackResponse struct {
+	PID      uint16 `json:"pid"`
+	Codec    string `json:"codec"`
+	Language string `json:"language,omitempty"`
+}
+
 // ServeHTTP routes the request to the appropriate handler based on method and path.
 func (h *StreamHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/streams")
@@ -48,6 +171,55 @@ func (h *StreamHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// GET /streams/{infoHash}/health - latest health evaluation of a stream
+	if r.Method == http.MethodGet && strings.HasSuffix(path, "/health") && h.probeService != nil {
+		infoHash := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/health")
+		h.handleHealth(w, r, infoHash)
+		return
+	}
+
+	// GET /streams/{infoHash}/probe - analyze the stream's media
+	if r.Method == http.MethodGet && strings.HasSuffix(path, "/probe") && h.mediaInfo != nil {
+		infoHash := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/probe")
+		h.handleProbe(w, r, infoHash)
+		return
+	}
+
+	// GET /streams/{infoHash}/stats/ws - live engine stats over a WebSocket
+	if r.Method == http.MethodGet && strings.HasSuffix(path, "/stats/ws") && h.stats != nil {
+		infoHash := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/stats/ws")
+		h.handleStatsWebSocket(w, r, infoHash)
+		return
+	}
+
+	// GET /streams/{infoHash}/stats/history - recorded engine stats for charting
+	if r.Method == http.MethodGet && strings.HasSuffix(path, "/stats/history") && h.statsHistory != nil {
+		infoHash := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/stats/history")
+		h.handleStatsHistory(w, r, infoHash)
+		return
+	}
+
+	// GET /streams/{infoHash}/preview.jpg - recent still frame of the stream
+	if r.Method == http.MethodGet && strings.HasSuffix(path, "/preview.jpg") && h.preview != nil {
+		infoHash := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/preview.jpg")
+		h.handlePreview(w, r, infoHash)
+		return
+	}
+
+	// POST /streams/{infoHash}/warmup - keep the stream open before viewers tune in
+	if r.Method == http.MethodPost && strings.HasSuffix(path, "/warmup") && h.warmup != nil {
+		infoHash := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/warmup")
+		h.handleWarmup(w, r, infoHash)
+		return
+	}
+
+	// POST /streams/{infoHash}/move - reassign the stream to another channel
+	if r.Method == http.MethodPost && strings.HasSuffix(path, "/move") {
+		infoHash := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/move")
+		h.handleMove(w, r, infoHash)
+		return
+	}
+
 	// GET /streams/{infoHash} - get a specific stream
 	if r.Method == http.MethodGet && path != "" {
 		infoHash := strings.TrimPrefix(path, "/")
@@ -75,7 +247,7 @@ func (h *StreamHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 
 	st, err := h.service.CreateStream(r.Context(), req.InfoHash, req.ChannelName)
 	if err != nil {
-		if errors.Is(err, stream.ErrEmptyInfoHash) || errors.Is(err, stream.ErrEmptyChannelName) {
+		if errors.Is(err, stream.ErrEmptyInfoHash) || errors.Is(err, stream.ErrInvalidInfoHash) || errors.Is(err, stream.ErrEmptyChannelName) {
 			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
@@ -98,16 +270,28 @@ func (h *StreamHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// handleList handles GET /streams
+// handleList handles GET /streams with optional sort, page, per_page and
+// fields query parameters
 func (h *StreamHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
-	streams, err := h.service.ListStreams(r.Context())
+	opts, err := parseListOptions(r)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeListError(w, err)
+		return
+	}
+	fields, err := parseFields[streamResponse](r)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	page, err := h.service.ListStreamsPage(r.Context(), opts)
+	if err != nil {
+		writeListError(w, err)
 		return
 	}
 
-	response := make([]streamResponse, len(streams))
-	for i, st := range streams {
+	response := make([]streamResponse, len(page.Items))
+	for i, st := range page.Items {
 		response[i] = streamResponse{
 			InfoHash:    st.InfoHash(),
 			ChannelName: st.ChannelName(),
@@ -115,7 +299,7 @@ func (h *StreamHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeJSON(w, http.StatusOK, response)
+	writeList(w, r, page, response, fields)
 }
 
 // handleGet handles GET /streams/{infoHash}
@@ -137,6 +321,179 @@ func (h *StreamHTTPHandler) handleGet(w http.ResponseWriter, r *http.Request, in
 	})
 }
 
+// handleHealth handles GET /streams/{infoHash}/health
+func (h *StreamHTTPHandler) handleHealth(w http.ResponseWriter, r *http.Request, infoHash string) {
+	health, err := h.probeService.GetStreamHealth(r.Context(), infoHash)
+	if err != nil {
+		if errors.Is(err, stream.ErrStreamNotFound) || errors.Is(err, probe.ErrNoProbeData) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, streamHealthResponse{
+		InfoHash:    health.InfoHash,
+		ChannelName: health.ChannelName,
+		Score:       health.Score,
+		HealthLevel: healthLevel(health.Score, true),
+		Metrics:     toMetricsResponse(health.Metrics),
+		LastProbe:   toProbeResultResponse(health.LastProbe),
+	})
+}
+
+// handleProbe handles GET /streams/{infoHash}/probe
+func (h *StreamHTTPHandler) handleProbe(w http.ResponseWriter, r *http.Request, infoHash string) {
+	if _, err := h.service.GetStream(r.Context(), infoHash); err != nil {
+		if errors.Is(err, stream.ErrStreamNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	info, err := h.mediaInfo.Analyze(r.Context(), infoHash)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrStreamLimitReached):
+			writeStreamLimitError(w)
+		case errors.Is(err, application.ErrEngineUnavailable):
+			setRetryAfter(w, err)
+			writeError(w, http.StatusServiceUnavailable, "acestream engine unavailable")
+		case errors.Is(err, application.ErrMediaInfoUnavailable):
+			writeError(w, http.StatusBadGateway, err.Error())
+		default:
+			writeError(w, http.StatusBadGateway, "stream failed")
+		}
+		return
+	}
+
+	response := streamProbeResponse{
+		InfoHash:   infoHash,
+		Audio:      make([]audioTrackResponse, len(info.Audio)),
+		Bitrate:    info.Bitrate,
+		DurationMs: info.Duration.Milliseconds(),
+	}
+	if info.Video != nil {
+		response.Video = &videoInfoResponse{
+			Codec:  info.Video.Codec,
+			Width:  info.Video.Width,
+			Height: info.Video.Height,
+		}
+	}
+	for i, track := range info.Audio {
+		response.Audio[i] = audioTrackResponse{
+			PID:      track.PID,
+			Codec:    track.Codec,
+			Language: track.Language,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handlePreview handles GET /streams/{infoHash}/preview.jpg
+func (h *StreamHTTPHandler) handlePreview(w http.ResponseWriter, r *http.Request, infoHash string) {
+	if _, err := h.service.GetStream(r.Context(), infoHash); err != nil {
+		if errors.Is(err, stream.ErrStreamNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	preview, err := h.preview.Preview(r.Context(), infoHash)
+	if err != nil {
+		switch {
+		case errors.Is(err, application.ErrStreamLimitReached):
+			writeStreamLimitError(w)
+		case errors.Is(err, application.ErrEngineUnavailable):
+			setRetryAfter(w, err)
+			writeError(w, http.StatusServiceUnavailable, "acestream engine unavailable")
+		case errors.Is(err, application.ErrPreviewUnavailable):
+			writeError(w, http.StatusBadGateway, application.ErrPreviewUnavailable.Error())
+		default:
+			writeError(w, http.StatusBadGateway, "stream failed")
+		}
+		return
+	}
+
+	// Browsers revalidate with If-Modified-Since, getting 304 until a new frame is captured
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "private, no-cache")
+	http.ServeContent(w, r, "preview.jpg", preview.CapturedAt, bytes.NewReader(preview.JPEG))
+}
+
+// handleWarmup handles POST /streams/{infoHash}/warmup with an optional
+// duration query parameter, 60s by default. The engine stream is started in
+// the background, so the response does not wait for it to be ready.
+func (h *StreamHTTPHandler) handleWarmup(w http.ResponseWriter, r *http.Request, infoHash string) {
+	d := defaultWarmupDuration
+	if raw := r.URL.Query().Get("duration"); raw != "" {
+		var err error
+		if d, err = time.ParseDuration(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid duration")
+			return
+		}
+	}
+
+	if _, err := h.service.GetStream(r.Context(), infoHash); err != nil {
+		if errors.Is(err, stream.ErrStreamNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	warmup, err := h.warmup.Warm(infoHash, d)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidWarmupDuration) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, streamWarmupResponse{
+		InfoHash: warmup.InfoHash,
+		Until:    warmup.Until.UTC().Format(time.RFC3339),
+	})
+}
+
+// handleMove handles POST /streams/{infoHash}/move
+func (h *StreamHTTPHandler) handleMove(w http.ResponseWriter, r *http.Request, infoHash string) {
+	var req streamMoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	st, err := h.service.MoveStream(r.Context(), infoHash, req.ChannelName)
+	if err != nil {
+		if errors.Is(err, stream.ErrStreamNotFound) {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, stream.ErrEmptyChannelName) || errors.Is(err, channel.ErrChannelNotFound) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, streamResponse{
+		InfoHash:    st.InfoHash(),
+		ChannelName: st.ChannelName(),
+		Source:      st.Source(),
+	})
+}
+
 // handleDelete handles DELETE /streams/{infoHash}
 func (h *StreamHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request, infoHash string) {
 	err := h.service.DeleteStream(r.Context(), infoHash)
@@ -151,3 +508,102 @@ func (h *StreamHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request,
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// handleStatsWebSocket handles GET /streams/{infoHash}/stats/ws. The
+// connection is closed normally once the stream stops playing.
+func (h *StreamHTTPHandler) handleStatsWebSocket(w http.ResponseWriter, r *http.Request, infoHash string) {
+	if !h.stats.IsStreamActive(infoHash) {
+		writeError(w, http.StatusNotFound, application.ErrStreamNotActive.Error())
+		return
+	}
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-ws.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err = h.stats.WatchStats(ctx, infoHash, h.statsInterval, func(stats driven.StreamStats) error {
+		data, err := json.Marshal(streamStatsMessage{
+			InfoHash:   infoHash,
+			Status:     stats.Status,
+			Peers:      stats.Peers,
+			SpeedDown:  stats.SpeedDown,
+			SpeedUp:    stats.SpeedUp,
+			Downloaded: stats.Downloaded,
+			Uploaded:   stats.Uploaded,
+			Time:       time.Now().Format("2006-01-02T15:04:05Z07:00"),
+		})
+		if err != nil {
+			return err
+		}
+		return ws.WriteText(data)
+	})
+
+	if err == nil || errors.Is(err, application.ErrStreamNotActive) {
+		_ = ws.Close(wsCloseNormal)
+		return
+	}
+	_ = ws.Close(wsCloseGoingAway)
+}
+
+// handleStatsHistory handles GET /streams/{infoHash}/stats/history with an
+// optional range query parameter, 24h by default.
+func (h *StreamHTTPHandler) handleStatsHistory(w http.ResponseWriter, r *http.Request, infoHash string) {
+	raw := r.URL.Query().Get("range")
+	if raw == "" {
+		raw = "24h"
+	}
+	rng, err := parseStatsRange(raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid range")
+		return
+	}
+
+	points, err := h.statsHistory.History(r.Context(), infoHash, rng)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidStatsRange) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := streamStatsHistoryResponse{
+		InfoHash: infoHash,
+		Range:    raw,
+		Points:   make([]streamStatsPointResponse, len(points)),
+	}
+	for i, p := range points {
+		response.Points[i] = streamStatsPointResponse{
+			Time:      p.Timestamp().UTC().Format(time.RFC3339),
+			Samples:   p.Count(),
+			Peers:     p.Peers(),
+			SpeedDown: p.SpeedDown(),
+			SpeedUp:   p.SpeedUp(),
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// parseStatsRange parses a Go duration, or a whole number of days such as "7d".
+func parseStatsRange(raw string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(raw)
+}
@@ -1,17 +1,28 @@
 package driver
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alorle/iptv-manager/internal/application"
 	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/probe"
 	"github.com/alorle/iptv-manager/internal/stream"
+	"github.com/alorle/iptv-manager/internal/streamstats"
 )
 
 func TestStreamHTTPHandler_Create(t *testing.T) {
@@ -31,9 +42,9 @@ func TestStreamHTTPHandler_Create(t *testing.T) {
 			},
 		}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
-		reqBody := bytes.NewBufferString(`{"info_hash":"abc123","channel_name":"TestChannel"}`)
+		reqBody := bytes.NewBufferString(`{"info_hash":"6162633132330000000000000000000000000000","channel_name":"TestChannel"}`)
 		req := httptest.NewRequest(http.MethodPost, "/streams", reqBody)
 		rec := httptest.NewRecorder()
 
@@ -47,8 +58,8 @@ func TestStreamHTTPHandler_Create(t *testing.T) {
 		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
 			t.Fatalf("failed to decode response: %v", err)
 		}
-		if resp.InfoHash != "abc123" {
-			t.Errorf("expected infohash 'abc123', got %q", resp.InfoHash)
+		if resp.InfoHash != "6162633132330000000000000000000000000000" {
+			t.Errorf("expected infohash %q, got %q", "6162633132330000000000000000000000000000", resp.InfoHash)
 		}
 		if resp.ChannelName != "TestChannel" {
 			t.Errorf("expected channel name 'TestChannel', got %q", resp.ChannelName)
@@ -59,7 +70,7 @@ func TestStreamHTTPHandler_Create(t *testing.T) {
 		channelRepo := &mockChannelRepository{}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
 		reqBody := bytes.NewBufferString(`invalid json`)
 		req := httptest.NewRequest(http.MethodPost, "/streams", reqBody)
@@ -89,7 +100,7 @@ func TestStreamHTTPHandler_Create(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
 		reqBody := bytes.NewBufferString(`{"info_hash":"","channel_name":"TestChannel"}`)
 		req := httptest.NewRequest(http.MethodPost, "/streams", reqBody)
@@ -110,6 +121,61 @@ func TestStreamHTTPHandler_Create(t *testing.T) {
 		}
 	})
 
+	t.Run("POST /streams normalizes a magnet link", func(t *testing.T) {
+		ch, _ := channel.NewChannel("TestChannel")
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				return ch, nil
+			},
+		}
+		var saved stream.Stream
+		streamRepo := &mockStreamRepository{
+			saveFunc: func(ctx context.Context, s stream.Stream) error {
+				saved = s
+				return nil
+			},
+		}
+		service := application.NewStreamService(streamRepo, channelRepo)
+		handler := NewStreamHTTPHandler(service, nil, nil)
+
+		reqBody := bytes.NewBufferString(`{"info_hash":"magnet:?xt=urn:btih:94C2FD8FA9B16211252C5E9F0B836D94155B505A&dn=Test","channel_name":"TestChannel"}`)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/streams", reqBody))
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d", rec.Code)
+		}
+		if saved.InfoHash() != "94c2fd8fa9b16211252c5e9f0b836d94155b505a" {
+			t.Errorf("expected the normalized infohash to be saved, got %q", saved.InfoHash())
+		}
+	})
+
+	t.Run("POST /streams returns 400 for malformed infohash", func(t *testing.T) {
+		ch, _ := channel.NewChannel("TestChannel")
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				return ch, nil
+			},
+		}
+		service := application.NewStreamService(&mockStreamRepository{}, channelRepo)
+		handler := NewStreamHTTPHandler(service, nil, nil)
+
+		reqBody := bytes.NewBufferString(`{"info_hash":"not-a-hash","channel_name":"TestChannel"}`)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/streams", reqBody))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+		var resp errorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode error response: %v", err)
+		}
+		if resp.Error != stream.ErrInvalidInfoHash.Error() {
+			t.Errorf("expected error %q, got %q", stream.ErrInvalidInfoHash.Error(), resp.Error)
+		}
+	})
+
 	t.Run("POST /streams returns 400 for empty channel name", func(t *testing.T) {
 		ch, _ := channel.NewChannel("TestChannel")
 		channelRepo := &mockChannelRepository{
@@ -119,9 +185,9 @@ func TestStreamHTTPHandler_Create(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
-		reqBody := bytes.NewBufferString(`{"info_hash":"abc123","channel_name":""}`)
+		reqBody := bytes.NewBufferString(`{"info_hash":"6162633132330000000000000000000000000000","channel_name":""}`)
 		req := httptest.NewRequest(http.MethodPost, "/streams", reqBody)
 		rec := httptest.NewRecorder()
 
@@ -148,9 +214,9 @@ func TestStreamHTTPHandler_Create(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
-		reqBody := bytes.NewBufferString(`{"info_hash":"abc123","channel_name":"NonExistent"}`)
+		reqBody := bytes.NewBufferString(`{"info_hash":"6162633132330000000000000000000000000000","channel_name":"NonExistent"}`)
 		req := httptest.NewRequest(http.MethodPost, "/streams", reqBody)
 		rec := httptest.NewRecorder()
 
@@ -182,9 +248,9 @@ func TestStreamHTTPHandler_Create(t *testing.T) {
 			},
 		}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
-		reqBody := bytes.NewBufferString(`{"info_hash":"abc123","channel_name":"TestChannel"}`)
+		reqBody := bytes.NewBufferString(`{"info_hash":"6162633132330000000000000000000000000000","channel_name":"TestChannel"}`)
 		req := httptest.NewRequest(http.MethodPost, "/streams", reqBody)
 		rec := httptest.NewRecorder()
 
@@ -206,8 +272,8 @@ func TestStreamHTTPHandler_Create(t *testing.T) {
 
 func TestStreamHTTPHandler_List(t *testing.T) {
 	t.Run("GET /streams returns all streams", func(t *testing.T) {
-		st1, _ := stream.NewStream("abc123", "Channel1", "")
-		st2, _ := stream.NewStream("def456", "Channel2", "")
+		st1, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+		st2, _ := stream.NewStream("6465663435360000000000000000000000000000", "Channel2", "")
 		channelRepo := &mockChannelRepository{}
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
@@ -215,7 +281,7 @@ func TestStreamHTTPHandler_List(t *testing.T) {
 			},
 		}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/streams", nil)
 		rec := httptest.NewRecorder()
@@ -233,7 +299,7 @@ func TestStreamHTTPHandler_List(t *testing.T) {
 		if len(resp) != 2 {
 			t.Fatalf("expected 2 streams, got %d", len(resp))
 		}
-		if resp[0].InfoHash != "abc123" || resp[1].InfoHash != "def456" {
+		if resp[0].InfoHash != "6162633132330000000000000000000000000000" || resp[1].InfoHash != "6465663435360000000000000000000000000000" {
 			t.Errorf("unexpected stream infohashes: %q, %q", resp[0].InfoHash, resp[1].InfoHash)
 		}
 	})
@@ -246,7 +312,7 @@ func TestStreamHTTPHandler_List(t *testing.T) {
 			},
 		}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/streams", nil)
 		rec := httptest.NewRecorder()
@@ -265,24 +331,57 @@ func TestStreamHTTPHandler_List(t *testing.T) {
 			t.Errorf("expected empty array, got %d streams", len(resp))
 		}
 	})
+
+	t.Run("GET /streams sorts and pages streams", func(t *testing.T) {
+		st1, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "manual")
+		st2, _ := stream.NewStream("6465663435360000000000000000000000000000", "Channel2", "elcano")
+		st3, _ := stream.NewStream("6665643738390000000000000000000000000000", "Channel3", "new-era")
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				return []stream.Stream{st1, st2, st3}, nil
+			},
+		}
+		service := application.NewStreamService(streamRepo, &mockChannelRepository{})
+		handler := NewStreamHTTPHandler(service, nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/streams?sort=source&per_page=2", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if got := rec.Header().Get("X-Total-Count"); got != "3" {
+			t.Errorf("expected X-Total-Count 3, got %q", got)
+		}
+
+		var resp []streamResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp) != 2 || resp[0].Source != "elcano" || resp[1].Source != "manual" {
+			t.Errorf("unexpected first page %+v", resp)
+		}
+	})
 }
 
 func TestStreamHTTPHandler_Get(t *testing.T) {
 	t.Run("GET /streams/{infoHash} returns stream", func(t *testing.T) {
-		st, _ := stream.NewStream("abc123", "TestChannel", "")
+		st, _ := stream.NewStream("6162633132330000000000000000000000000000", "TestChannel", "")
 		channelRepo := &mockChannelRepository{}
 		streamRepo := &mockStreamRepository{
 			findByInfoHashFunc: func(ctx context.Context, infoHash string) (stream.Stream, error) {
-				if infoHash == "abc123" {
+				if infoHash == "6162633132330000000000000000000000000000" {
 					return st, nil
 				}
 				return stream.Stream{}, stream.ErrStreamNotFound
 			},
 		}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/streams/abc123", nil)
+		req := httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000", nil)
 		rec := httptest.NewRecorder()
 
 		handler.ServeHTTP(rec, req)
@@ -295,8 +394,8 @@ func TestStreamHTTPHandler_Get(t *testing.T) {
 		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
 			t.Fatalf("failed to decode response: %v", err)
 		}
-		if resp.InfoHash != "abc123" {
-			t.Errorf("expected infohash 'abc123', got %q", resp.InfoHash)
+		if resp.InfoHash != "6162633132330000000000000000000000000000" {
+			t.Errorf("expected infohash %q, got %q", "6162633132330000000000000000000000000000", resp.InfoHash)
 		}
 		if resp.ChannelName != "TestChannel" {
 			t.Errorf("expected channel name 'TestChannel', got %q", resp.ChannelName)
@@ -311,7 +410,7 @@ func TestStreamHTTPHandler_Get(t *testing.T) {
 			},
 		}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
 		req := httptest.NewRequest(http.MethodGet, "/streams/nonexistent", nil)
 		rec := httptest.NewRecorder()
@@ -332,21 +431,150 @@ func TestStreamHTTPHandler_Get(t *testing.T) {
 	})
 }
 
+func TestStreamHTTPHandler_Health(t *testing.T) {
+	now := time.Now()
+	st, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+	streamRepo := &mockStreamRepository{
+		findByInfoHashFunc: func(ctx context.Context, infoHash string) (stream.Stream, error) {
+			if infoHash == "6162633132330000000000000000000000000000" {
+				return st, nil
+			}
+			return stream.Stream{}, stream.ErrStreamNotFound
+		},
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			return []stream.Stream{st}, nil
+		},
+	}
+
+	t.Run("GET /streams/{infoHash}/health returns latest evaluation", func(t *testing.T) {
+		probeRepo := &mockProbeRepository{
+			findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+				return []probe.Result{probe.ReconstructResult(infoHash, now, true, time.Second, 10, 100000, "dl", "")}, nil
+			},
+		}
+		service := application.NewStreamService(streamRepo, &mockChannelRepository{})
+		handler := NewStreamHTTPHandler(service, newProbeTestService(probeRepo, streamRepo), nil)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/health", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+
+		var resp streamHealthResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.InfoHash != "6162633132330000000000000000000000000000" || resp.ChannelName != "Channel1" {
+			t.Errorf("unexpected identity: %+v", resp)
+		}
+		if resp.Score <= 0 || resp.HealthLevel == "" {
+			t.Errorf("expected score and health level, got score=%f level=%q", resp.Score, resp.HealthLevel)
+		}
+		if !resp.LastProbe.Available {
+			t.Error("expected latest probe to be available")
+		}
+	})
+
+	t.Run("GET /streams/{infoHash}/health returns 404 without probe data", func(t *testing.T) {
+		service := application.NewStreamService(streamRepo, &mockChannelRepository{})
+		handler := NewStreamHTTPHandler(service, newProbeTestService(&mockProbeRepository{}, streamRepo), nil)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/health", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("GET /streams/{infoHash}/health returns 404 for unknown stream", func(t *testing.T) {
+		service := application.NewStreamService(streamRepo, &mockChannelRepository{})
+		handler := NewStreamHTTPHandler(service, newProbeTestService(&mockProbeRepository{}, streamRepo), nil)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/missing/health", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+}
+
+func TestStreamHTTPHandler_Probe(t *testing.T) {
+	st, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+	streamRepo := &mockStreamRepository{
+		findByInfoHashFunc: func(ctx context.Context, infoHash string) (stream.Stream, error) {
+			if infoHash == "6162633132330000000000000000000000000000" {
+				return st, nil
+			}
+			return stream.Stream{}, stream.ErrStreamNotFound
+		},
+	}
+	newHandler := func(engine *mockAceStreamEngine) *StreamHTTPHandler {
+		service := application.NewStreamService(streamRepo, &mockChannelRepository{})
+		proxy := application.NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		mediaInfo := application.NewMediaInfoService(proxy, application.MediaInfoConfig{
+			SampleDuration: time.Second,
+			Timeout:        time.Second,
+		}, slog.Default())
+		return NewStreamHTTPHandler(service, nil, mediaInfo)
+	}
+
+	t.Run("GET /streams/{infoHash}/probe returns 404 for unknown stream", func(t *testing.T) {
+		handler := newHandler(&mockAceStreamEngine{})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/missing/probe", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("GET /streams/{infoHash}/probe returns 503 when the engine is down", func(t *testing.T) {
+		handler := newHandler(&mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "", application.ErrEngineUnavailable
+			},
+		})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/probe", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rec.Code)
+		}
+	})
+
+	t.Run("GET /streams/{infoHash}/probe returns 502 when nothing is recognized", func(t *testing.T) {
+		handler := newHandler(&mockAceStreamEngine{})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/probe", nil))
+
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("expected status 502, got %d", rec.Code)
+		}
+	})
+}
+
 func TestStreamHTTPHandler_Delete(t *testing.T) {
 	t.Run("DELETE /streams/{infoHash} deletes stream successfully", func(t *testing.T) {
 		channelRepo := &mockChannelRepository{}
 		streamRepo := &mockStreamRepository{
 			deleteFunc: func(ctx context.Context, infoHash string) error {
-				if infoHash == "abc123" {
+				if infoHash == "6162633132330000000000000000000000000000" {
 					return nil
 				}
 				return stream.ErrStreamNotFound
 			},
 		}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
-		req := httptest.NewRequest(http.MethodDelete, "/streams/abc123", nil)
+		req := httptest.NewRequest(http.MethodDelete, "/streams/6162633132330000000000000000000000000000", nil)
 		rec := httptest.NewRecorder()
 
 		handler.ServeHTTP(rec, req)
@@ -364,7 +592,7 @@ func TestStreamHTTPHandler_Delete(t *testing.T) {
 			},
 		}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
 		req := httptest.NewRequest(http.MethodDelete, "/streams/nonexistent", nil)
 		rec := httptest.NewRecorder()
@@ -392,9 +620,9 @@ func TestStreamHTTPHandler_Delete(t *testing.T) {
 			},
 		}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
-		req := httptest.NewRequest(http.MethodDelete, "/streams/abc123", nil)
+		req := httptest.NewRequest(http.MethodDelete, "/streams/6162633132330000000000000000000000000000", nil)
 		rec := httptest.NewRecorder()
 
 		handler.ServeHTTP(rec, req)
@@ -413,12 +641,90 @@ func TestStreamHTTPHandler_Delete(t *testing.T) {
 	})
 }
 
+func TestStreamHTTPHandler_Move(t *testing.T) {
+	newHandler := func() (*StreamHTTPHandler, *[]stream.Stream) {
+		var updated []stream.Stream
+		streamRepo := &mockStreamRepository{
+			findByInfoHashFunc: func(ctx context.Context, infoHash string) (stream.Stream, error) {
+				if infoHash != "6162633132330000000000000000000000000000" {
+					return stream.Stream{}, stream.ErrStreamNotFound
+				}
+				return stream.NewStream(infoHash, "HBO", stream.SourceManual)
+			},
+			updateFunc: func(ctx context.Context, s stream.Stream) error {
+				updated = append(updated, s)
+				return nil
+			},
+		}
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				if name != "HBO HD" {
+					return channel.Channel{}, channel.ErrChannelNotFound
+				}
+				return channel.NewChannel(name)
+			},
+		}
+		return NewStreamHTTPHandler(application.NewStreamService(streamRepo, channelRepo), nil, nil), &updated
+	}
+
+	t.Run("POST /streams/{infoHash}/move reassigns the stream", func(t *testing.T) {
+		handler, updated := newHandler()
+
+		req := httptest.NewRequest(http.MethodPost, "/streams/6162633132330000000000000000000000000000/move", strings.NewReader(`{"channel_name":"HBO HD"}`))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp streamResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.ChannelName != "HBO HD" || resp.Source != stream.SourceManual {
+			t.Errorf("unexpected response %+v", resp)
+		}
+		if len(*updated) != 1 {
+			t.Errorf("expected the stream to be updated once, got %d", len(*updated))
+		}
+	})
+
+	t.Run("POST /streams/{infoHash}/move rejects invalid moves", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			target string
+			body   string
+			status int
+		}{
+			{"unknown stream", "/streams/missing/move", `{"channel_name":"HBO HD"}`, http.StatusNotFound},
+			{"unknown channel", "/streams/6162633132330000000000000000000000000000/move", `{"channel_name":"Cinemax"}`, http.StatusBadRequest},
+			{"missing channel", "/streams/6162633132330000000000000000000000000000/move", `{}`, http.StatusBadRequest},
+			{"invalid JSON", "/streams/6162633132330000000000000000000000000000/move", `{`, http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				handler, updated := newHandler()
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body)))
+
+				if rec.Code != tt.status {
+					t.Errorf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+				}
+				if len(*updated) != 0 {
+					t.Errorf("expected no update, got %d", len(*updated))
+				}
+			})
+		}
+	})
+}
+
 func TestStreamHTTPHandler_MethodNotAllowed(t *testing.T) {
 	t.Run("returns 405 for unsupported methods", func(t *testing.T) {
 		channelRepo := &mockChannelRepository{}
 		streamRepo := &mockStreamRepository{}
 		service := application.NewStreamService(streamRepo, channelRepo)
-		handler := NewStreamHTTPHandler(service)
+		handler := NewStreamHTTPHandler(service, nil, nil)
 
 		methods := []string{http.MethodPut, http.MethodPatch, http.MethodHead, http.MethodOptions}
 		for _, method := range methods {
@@ -433,3 +739,376 @@ func TestStreamHTTPHandler_MethodNotAllowed(t *testing.T) {
 		}
 	})
 }
+
+// fakeStatsWatcher reports the given readings and then ends, as if the
+// stream stopped playing.
+type fakeStatsWatcher struct {
+	active   bool
+	readings []driven.StreamStats
+}
+
+func (f *fakeStatsWatcher) IsStreamActive(infoHash string) bool {
+	return f.active
+}
+
+func (f *fakeStatsWatcher) WatchStats(ctx context.Context, infoHash string, interval time.Duration, fn func(driven.StreamStats) error) error {
+	for _, stats := range f.readings {
+		if err := fn(stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestStreamHTTPHandler_StatsWebSocket(t *testing.T) {
+	newHandler := func(watcher StreamStatsWatcher) *StreamHTTPHandler {
+		service := application.NewStreamService(&mockStreamRepository{}, &mockChannelRepository{})
+		handler := NewStreamHTTPHandler(service, nil, nil)
+		handler.SetStatsWatcher(watcher, time.Millisecond)
+		return handler
+	}
+
+	t.Run("pushes stats and closes when the stream stops", func(t *testing.T) {
+		watcher := &fakeStatsWatcher{active: true, readings: []driven.StreamStats{
+			{Status: "dl", Peers: 12, SpeedDown: 512000, Downloaded: 1 << 20},
+		}}
+		server := httptest.NewServer(newHandler(watcher))
+		defer server.Close()
+
+		conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		fmt.Fprint(conn, "GET /streams/6162633132330000000000000000000000000000/stats/ws HTTP/1.1\r\n"+
+			"Host: example.com\r\n"+
+			"Connection: Upgrade\r\n"+
+			"Upgrade: websocket\r\n"+
+			"Sec-WebSocket-Version: 13\r\n"+
+			"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			t.Fatalf("reading handshake: %v", err)
+		}
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("expected 101, got %d", resp.StatusCode)
+		}
+		// Example accept value from RFC 6455
+		if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+			t.Errorf("unexpected Sec-WebSocket-Accept %q", got)
+		}
+
+		readFrame := func() (byte, []byte) {
+			t.Helper()
+			head := make([]byte, 2)
+			if _, err := io.ReadFull(reader, head); err != nil {
+				t.Fatalf("reading frame: %v", err)
+			}
+			length := int(head[1] & 0x7F)
+			if length == 126 {
+				ext := make([]byte, 2)
+				if _, err := io.ReadFull(reader, ext); err != nil {
+					t.Fatalf("reading length: %v", err)
+				}
+				length = int(binary.BigEndian.Uint16(ext))
+			}
+			payload := make([]byte, length)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				t.Fatalf("reading payload: %v", err)
+			}
+			return head[0] & 0x0F, payload
+		}
+
+		opcode, payload := readFrame()
+		if opcode != wsOpText {
+			t.Fatalf("expected text frame, got opcode %d", opcode)
+		}
+		var msg streamStatsMessage
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			t.Fatalf("decoding message: %v", err)
+		}
+		if msg.InfoHash != "6162633132330000000000000000000000000000" || msg.Peers != 12 || msg.SpeedDown != 512000 || msg.Downloaded != 1<<20 {
+			t.Errorf("unexpected message %+v", msg)
+		}
+
+		opcode, payload = readFrame()
+		if opcode != wsOpClose || len(payload) != 2 || binary.BigEndian.Uint16(payload) != wsCloseNormal {
+			t.Errorf("expected normal close frame, got opcode %d payload %v", opcode, payload)
+		}
+	})
+
+	t.Run("returns 404 when the stream is not playing", func(t *testing.T) {
+		handler := newHandler(&fakeStatsWatcher{})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/stats/ws", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("returns 400 without an upgrade request", func(t *testing.T) {
+		handler := newHandler(&fakeStatsWatcher{active: true})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/stats/ws", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+}
+
+// mockStreamStatsRepository is a mock implementation of driven.StreamStatsRepository for testing.
+type mockStreamStatsRepository struct {
+	findByInfoHashSinceFunc func(ctx context.Context, infoHash string, since time.Time) ([]streamstats.Sample, error)
+}
+
+func (m *mockStreamStatsRepository) Save(ctx context.Context, samples ...streamstats.Sample) error {
+	return nil
+}
+
+func (m *mockStreamStatsRepository) FindByInfoHashSince(ctx context.Context, infoHash string, since time.Time) ([]streamstats.Sample, error) {
+	if m.findByInfoHashSinceFunc != nil {
+		return m.findByInfoHashSinceFunc(ctx, infoHash, since)
+	}
+	return []streamstats.Sample{}, nil
+}
+
+func (m *mockStreamStatsRepository) FindReadingsBefore(ctx context.Context, before time.Time) ([]streamstats.Sample, error) {
+	return []streamstats.Sample{}, nil
+}
+
+func (m *mockStreamStatsRepository) ReplaceReadingsBefore(ctx context.Context, before time.Time, samples []streamstats.Sample) error {
+	return nil
+}
+
+func (m *mockStreamStatsRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+func TestStreamHTTPHandler_StatsHistory(t *testing.T) {
+	var gotSince time.Time
+	statsRepo := &mockStreamStatsRepository{
+		findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]streamstats.Sample, error) {
+			gotSince = since
+			s1, _ := streamstats.NewSample(infoHash, since.Add(time.Minute), 10, 1000, 100)
+			s2, _ := streamstats.NewSample(infoHash, since.Add(time.Minute), 20, 3000, 300)
+			return []streamstats.Sample{s1, s2}, nil
+		},
+	}
+	history := application.NewStatsHistoryService(statsRepo, nil, 24*time.Hour, 30*24*time.Hour, slog.Default())
+	handler := NewStreamHTTPHandler(application.NewStreamService(&mockStreamRepository{}, &mockChannelRepository{}), nil, nil)
+	handler.SetStatsHistory(history)
+
+	t.Run("returns aggregated points for the range", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/stats/history?range=7d", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if d := time.Since(gotSince); d < 7*24*time.Hour || d > 7*24*time.Hour+time.Minute {
+			t.Errorf("expected samples since 7 days ago, got %v ago", d)
+		}
+
+		var resp streamStatsHistoryResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.InfoHash != "6162633132330000000000000000000000000000" || resp.Range != "7d" || len(resp.Points) != 1 {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+		if p := resp.Points[0]; p.Samples != 2 || p.Peers != 15 || p.SpeedDown != 2000 || p.SpeedUp != 200 {
+			t.Errorf("unexpected point %+v", p)
+		}
+	})
+
+	t.Run("defaults to the last 24 hours", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/stats/history", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if d := time.Since(gotSince); d < 24*time.Hour || d > 24*time.Hour+time.Minute {
+			t.Errorf("expected samples since a day ago, got %v ago", d)
+		}
+	})
+
+	for _, rng := range []string{"soon", "-1h", "0d"} {
+		t.Run("rejects range "+rng, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/stats/history?range="+rng, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+// mockFrameExtractor returns a fixed frame after reading the start of the stream.
+type mockFrameExtractor struct {
+	frame []byte
+}
+
+func (m *mockFrameExtractor) ExtractFrame(ctx context.Context, r io.Reader) ([]byte, error) {
+	if _, err := io.ReadFull(r, make([]byte, 188)); err != nil {
+		return nil, err
+	}
+	return m.frame, nil
+}
+
+func TestStreamHTTPHandler_Preview(t *testing.T) {
+	st, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+	streamRepo := &mockStreamRepository{
+		findByInfoHashFunc: func(ctx context.Context, infoHash string) (stream.Stream, error) {
+			if infoHash == "6162633132330000000000000000000000000000" {
+				return st, nil
+			}
+			return stream.Stream{}, stream.ErrStreamNotFound
+		},
+	}
+	newHandler := func(engine *mockAceStreamEngine) *StreamHTTPHandler {
+		proxy := application.NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		preview := application.NewPreviewService(proxy, &mockFrameExtractor{frame: []byte("jpeg")}, application.PreviewConfig{
+			CacheTTL: time.Minute,
+			Timeout:  time.Second,
+		}, slog.Default())
+		handler := NewStreamHTTPHandler(application.NewStreamService(streamRepo, &mockChannelRepository{}), nil, nil)
+		handler.SetPreviewService(preview)
+		return handler
+	}
+
+	t.Run("GET /streams/{infoHash}/preview.jpg returns a frame", func(t *testing.T) {
+		handler := newHandler(&mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "http://engine/stream", nil
+			},
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				for {
+					if _, err := dst.Write(make([]byte, 188)); err != nil {
+						return err
+					}
+				}
+			},
+		})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/preview.jpg", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if rec.Header().Get("Content-Type") != "image/jpeg" || rec.Body.String() != "jpeg" {
+			t.Errorf("unexpected response %q with content type %q", rec.Body.String(), rec.Header().Get("Content-Type"))
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/preview.jpg", nil)
+		req.Header.Set("If-Modified-Since", rec.Header().Get("Last-Modified"))
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified {
+			t.Errorf("expected status 304 for the cached frame, got %d", rec.Code)
+		}
+	})
+
+	t.Run("GET /streams/{infoHash}/preview.jpg returns 404 for unknown stream", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newHandler(&mockAceStreamEngine{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/missing/preview.jpg", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+
+	t.Run("GET /streams/{infoHash}/preview.jpg returns 503 when the engine is down", func(t *testing.T) {
+		handler := newHandler(&mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "", application.ErrEngineUnavailable
+			},
+		})
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/streams/6162633132330000000000000000000000000000/preview.jpg", nil))
+
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status 503, got %d", rec.Code)
+		}
+	})
+}
+
+func TestStreamHTTPHandler_Warmup(t *testing.T) {
+	const infoHash = "6162633132330000000000000000000000000000"
+	st, _ := stream.NewStream(infoHash, "Channel1", "")
+	streamRepo := &mockStreamRepository{
+		findByInfoHashFunc: func(ctx context.Context, hash string) (stream.Stream, error) {
+			if hash == infoHash {
+				return st, nil
+			}
+			return stream.Stream{}, stream.ErrStreamNotFound
+		},
+	}
+	newHandler := func(t *testing.T) *StreamHTTPHandler {
+		engine := &mockAceStreamEngine{
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+		proxy := application.NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		warmup := application.NewWarmupService(proxy, slog.Default())
+		t.Cleanup(func() { _ = warmup.Shutdown(context.Background()) })
+		handler := NewStreamHTTPHandler(application.NewStreamService(streamRepo, &mockChannelRepository{}), nil, nil)
+		handler.SetWarmupService(warmup)
+		return handler
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantFor    time.Duration
+	}{
+		{name: "default duration", path: "/streams/" + infoHash + "/warmup", wantStatus: http.StatusAccepted, wantFor: time.Minute},
+		{name: "requested duration", path: "/streams/" + infoHash + "/warmup?duration=90s", wantStatus: http.StatusAccepted, wantFor: 90 * time.Second},
+		{name: "unparsable duration", path: "/streams/" + infoHash + "/warmup?duration=soon", wantStatus: http.StatusBadRequest},
+		{name: "duration above the maximum", path: "/streams/" + infoHash + "/warmup?duration=24h", wantStatus: http.StatusBadRequest},
+		{name: "unknown stream", path: "/streams/missing/warmup", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newHandler(t).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				return
+			}
+
+			var response streamWarmupResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.InfoHash != infoHash {
+				t.Errorf("expected info_hash %q, got %q", infoHash, response.InfoHash)
+			}
+			until, err := time.Parse(time.RFC3339, response.Until)
+			if err != nil {
+				t.Fatalf("unexpected until %q: %v", response.Until, err)
+			}
+			if d := time.Until(until); d < tt.wantFor-2*time.Second || d > tt.wantFor {
+				t.Errorf("expected the warm-up to last about %v, got %v", tt.wantFor, d)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/alorle/iptv-manager/internal/application"
 	"github.com/alorle/iptv-manager/internal/subscription"
@@ -25,11 +26,42 @@ type subscribeRequest struct {
 	EPGChannelID string `json:"epg_channel_id"`
 }
 
-// subscriptionResponse represents a subscription in JSON format.
+// subscriptionPatchRequest represents the JSON body for updating a
+// subscription. Omitted fields are left unchanged; an empty expires_at
+// removes the expiry date.
+type subscriptionPatchRequest struct {
+	Paused    *bool   `json:"paused"`
+	ExpiresAt *string `json:"expires_at"`
+	Notes     *string `json:"notes"`
+}
+
+// subscriptionResponse represents a subscription in JSON format. Active is
+// false for disabled, paused and expired subscriptions, whose channels are
+// not synced.
 type subscriptionResponse struct {
 	EPGChannelID   string `json:"epg_channel_id"`
 	Enabled        bool   `json:"enabled"`
 	ManualOverride bool   `json:"manual_override"`
+	Paused         bool   `json:"paused"`
+	ExpiresAt      string `json:"expires_at,omitempty"`
+	Notes          string `json:"notes,omitempty"`
+	Active         bool   `json:"active"`
+}
+
+// toSubscriptionResponse converts a subscription domain object to an API response.
+func toSubscriptionResponse(sub subscription.Subscription) subscriptionResponse {
+	resp := subscriptionResponse{
+		EPGChannelID:   sub.EPGChannelID(),
+		Enabled:        sub.IsEnabled(),
+		ManualOverride: sub.HasManualOverride(),
+		Paused:         sub.IsPaused(),
+		Notes:          sub.Notes(),
+		Active:         sub.IsActive(time.Now()),
+	}
+	if expiresAt := sub.ExpiresAt(); !expiresAt.IsZero() {
+		resp.ExpiresAt = expiresAt.Format(time.RFC3339)
+	}
+	return resp
 }
 
 // ServeHTTP routes the request to the appropriate handler based on method and path.
@@ -48,6 +80,13 @@ func (h *SubscriptionHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// PATCH /api/subscriptions/{id} - pause, resume, set expiry or notes
+	if r.Method == http.MethodPatch && path != "" {
+		id := strings.TrimPrefix(path, "/")
+		h.handlePatch(w, r, id)
+		return
+	}
+
 	// DELETE /api/subscriptions/{id} - unsubscribe
 	if r.Method == http.MethodDelete && path != "" {
 		id := strings.TrimPrefix(path, "/")
@@ -87,11 +126,7 @@ func (h *SubscriptionHTTPHandler) handleSubscribe(w http.ResponseWriter, r *http
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, subscriptionResponse{
-		EPGChannelID:   sub.EPGChannelID(),
-		Enabled:        sub.IsEnabled(),
-		ManualOverride: sub.HasManualOverride(),
-	})
+	writeJSON(w, http.StatusCreated, toSubscriptionResponse(sub))
 }
 
 // handleList handles GET /api/subscriptions
@@ -104,16 +139,51 @@ func (h *SubscriptionHTTPHandler) handleList(w http.ResponseWriter, r *http.Requ
 
 	response := make([]subscriptionResponse, len(subscriptions))
 	for i, sub := range subscriptions {
-		response[i] = subscriptionResponse{
-			EPGChannelID:   sub.EPGChannelID(),
-			Enabled:        sub.IsEnabled(),
-			ManualOverride: sub.HasManualOverride(),
-		}
+		response[i] = toSubscriptionResponse(sub)
 	}
 
 	writeJSON(w, http.StatusOK, response)
 }
 
+// handlePatch handles PATCH /api/subscriptions/{id}
+func (h *SubscriptionHTTPHandler) handlePatch(w http.ResponseWriter, r *http.Request, id string) {
+	var req subscriptionPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	update := application.SubscriptionUpdate{Paused: req.Paused, Notes: req.Notes}
+	if req.ExpiresAt != nil {
+		var expiresAt time.Time
+		if *req.ExpiresAt != "" {
+			t, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "expires_at must be an RFC 3339 date-time")
+				return
+			}
+			expiresAt = t
+		}
+		update.ExpiresAt = &expiresAt
+	}
+
+	sub, err := h.service.UpdateSubscription(r.Context(), id, update)
+	if err != nil {
+		if errors.Is(err, subscription.ErrNotesTooLong) {
+			writeError(w, http.StatusBadRequest, subscription.ErrNotesTooLong.Error())
+			return
+		}
+		if errors.Is(err, subscription.ErrSubscriptionNotFound) {
+			writeError(w, http.StatusNotFound, subscription.ErrSubscriptionNotFound.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toSubscriptionResponse(sub))
+}
+
 // handleUnsubscribe handles DELETE /api/subscriptions/{id}
 func (h *SubscriptionHTTPHandler) handleUnsubscribe(w http.ResponseWriter, r *http.Request, id string) {
 	err := h.service.Unsubscribe(r.Context(), id)
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/alorle/iptv-manager/internal/application"
@@ -16,6 +17,7 @@ import (
 // mockSubscriptionRepository is a mock implementation for testing.
 type mockSubscriptionRepository struct {
 	saveFunc        func(ctx context.Context, sub subscription.Subscription) error
+	updateFunc      func(ctx context.Context, sub subscription.Subscription) error
 	findByEPGIDFunc func(ctx context.Context, epgChannelID string) (subscription.Subscription, error)
 	findAllFunc     func(ctx context.Context) ([]subscription.Subscription, error)
 	deleteFunc      func(ctx context.Context, epgChannelID string) error
@@ -28,6 +30,13 @@ func (m *mockSubscriptionRepository) Save(ctx context.Context, sub subscription.
 	return nil
 }
 
+func (m *mockSubscriptionRepository) Update(ctx context.Context, sub subscription.Subscription) error {
+	if m.updateFunc != nil {
+		return m.updateFunc(ctx, sub)
+	}
+	return nil
+}
+
 func (m *mockSubscriptionRepository) FindByEPGID(ctx context.Context, epgChannelID string) (subscription.Subscription, error) {
 	if m.findByEPGIDFunc != nil {
 		return m.findByEPGIDFunc(ctx, epgChannelID)
@@ -248,6 +257,71 @@ func TestSubscriptionHTTPHandler_List(t *testing.T) {
 	})
 }
 
+func TestSubscriptionHTTPHandler_Patch(t *testing.T) {
+	stored, _ := subscription.NewSubscription("epg123")
+	subRepo := &mockSubscriptionRepository{
+		findByEPGIDFunc: func(ctx context.Context, epgChannelID string) (subscription.Subscription, error) {
+			if epgChannelID == stored.EPGChannelID() {
+				return stored, nil
+			}
+			return subscription.Subscription{}, subscription.ErrSubscriptionNotFound
+		},
+		updateFunc: func(ctx context.Context, sub subscription.Subscription) error {
+			stored = sub
+			return nil
+		},
+	}
+	handler := NewSubscriptionHTTPHandler(application.NewSubscriptionService(subRepo, &mockEPGFetcher{}))
+	patch := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, path, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	t.Run("PATCH /subscriptions/{id} pauses and sets expiry and notes", func(t *testing.T) {
+		rec := patch("/subscriptions/epg123", `{"paused":true,"expires_at":"2030-01-01T00:00:00Z","notes":"family plan"}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp subscriptionResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if !resp.Paused || resp.Active || resp.ExpiresAt != "2030-01-01T00:00:00Z" || resp.Notes != "family plan" {
+			t.Errorf("unexpected subscription %+v", resp)
+		}
+	})
+
+	t.Run("PATCH /subscriptions/{id} resumes and clears the expiry", func(t *testing.T) {
+		rec := patch("/subscriptions/epg123", `{"paused":false,"expires_at":""}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp subscriptionResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Paused || !resp.Active || resp.ExpiresAt != "" || resp.Notes != "family plan" {
+			t.Errorf("unexpected subscription %+v", resp)
+		}
+	})
+
+	t.Run("PATCH /subscriptions/{id} rejects invalid updates", func(t *testing.T) {
+		for _, body := range []string{
+			`{`,
+			`{"expires_at":"tomorrow"}`,
+			`{"notes":"` + strings.Repeat("a", 1001) + `"}`,
+		} {
+			if rec := patch("/subscriptions/epg123", body); rec.Code != http.StatusBadRequest {
+				t.Errorf("%.40s: expected status 400, got %d", body, rec.Code)
+			}
+		}
+		if rec := patch("/subscriptions/missing", `{"paused":true}`); rec.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", rec.Code)
+		}
+	})
+}
+
 func TestSubscriptionHTTPHandler_Unsubscribe(t *testing.T) {
 	t.Run("DELETE /subscriptions/{id} deletes subscription successfully", func(t *testing.T) {
 		subRepo := &mockSubscriptionRepository{
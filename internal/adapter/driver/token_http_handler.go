@@ -0,0 +1,168 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/playlist"
+)
+
+// TokenHTTPHandler handles HTTP requests for API token management.
+type TokenHTTPHandler struct {
+	service *application.AuthService
+}
+
+// NewTokenHTTPHandler creates a new HTTP handler for API tokens.
+func NewTokenHTTPHandler(service *application.AuthService) *TokenHTTPHandler {
+	return &TokenHTTPHandler{service: service}
+}
+
+// createTokenRequest represents the JSON body for creating a token.
+type createTokenRequest struct {
+	Name string `json:"name"`
+}
+
+// playlistPrefsRequest represents the JSON body for setting the playlist
+// preferences of a token, and the preferences in responses.
+type playlistPrefsRequest struct {
+	Groups          []string `json:"groups"`
+	Quality         string   `json:"quality"`
+	Format          string   `json:"format"`
+	IncludeDisabled bool     `json:"include_disabled"`
+}
+
+// tokenResponse represents an API token in JSON format. Token holds the
+// plaintext secret and is only set in the creation response.
+type tokenResponse struct {
+	ID            string               `json:"id"`
+	Name          string               `json:"name"`
+	CreatedAt     string               `json:"created_at"`
+	Token         string               `json:"token,omitempty"`
+	PlaylistPrefs playlistPrefsRequest `json:"playlist_prefs"`
+}
+
+func toTokenResponse(t auth.Token) tokenResponse {
+	prefs := t.PlaylistPrefs()
+	return tokenResponse{
+		ID:        t.ID(),
+		Name:      t.Name(),
+		CreatedAt: t.CreatedAt().Format("2006-01-02T15:04:05Z07:00"),
+		PlaylistPrefs: playlistPrefsRequest{
+			Groups:          append([]string{}, prefs.Groups()...),
+			Quality:         prefs.Quality(),
+			Format:          prefs.Format(),
+			IncludeDisabled: prefs.IncludeDisabled(),
+		},
+	}
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *TokenHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/tokens")
+
+	// POST /api/tokens - create a token
+	if r.Method == http.MethodPost && path == "" {
+		h.handleCreate(w, r)
+		return
+	}
+
+	// GET /api/tokens - list tokens
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	// PUT /api/tokens/{id}/playlist-prefs - set the playlist preferences of a token
+	if id, ok := strings.CutSuffix(strings.TrimPrefix(path, "/"), "/playlist-prefs"); ok && r.Method == http.MethodPut && id != "" {
+		h.handleSetPlaylistPrefs(w, r, id)
+		return
+	}
+
+	// DELETE /api/tokens/{id} - revoke a token
+	if r.Method == http.MethodDelete && path != "" {
+		h.handleRevoke(w, r, strings.TrimPrefix(path, "/"))
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// handleCreate handles POST /api/tokens
+func (h *TokenHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req createTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	tok, secret, err := h.service.CreateToken(r.Context(), req.Name)
+	if err != nil {
+		if errors.Is(err, auth.ErrEmptyTokenName) {
+			writeError(w, http.StatusBadRequest, auth.ErrEmptyTokenName.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	resp := toTokenResponse(tok)
+	resp.Token = secret
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// handleList handles GET /api/tokens
+func (h *TokenHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.service.ListTokens(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := make([]tokenResponse, len(tokens))
+	for i, t := range tokens {
+		response[i] = toTokenResponse(t)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleSetPlaylistPrefs handles PUT /api/tokens/{id}/playlist-prefs
+func (h *TokenHTTPHandler) handleSetPlaylistPrefs(w http.ResponseWriter, r *http.Request, id string) {
+	var req playlistPrefsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	tok, err := h.service.SetPlaylistPrefs(r.Context(), id, req.Groups, req.Quality, req.Format, req.IncludeDisabled)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrTokenNotFound):
+			writeError(w, http.StatusNotFound, auth.ErrTokenNotFound.Error())
+		case errors.Is(err, channel.ErrInvalidQuality), errors.Is(err, playlist.ErrUnknownFormat):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTokenResponse(tok))
+}
+
+// handleRevoke handles DELETE /api/tokens/{id}
+func (h *TokenHTTPHandler) handleRevoke(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.RevokeToken(r.Context(), id); err != nil {
+		if errors.Is(err, auth.ErrTokenNotFound) {
+			writeError(w, http.StatusNotFound, auth.ErrTokenNotFound.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,187 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/user"
+)
+
+// UserHTTPHandler handles HTTP requests for managing the users a server is
+// shared with.
+type UserHTTPHandler struct {
+	service *application.UserService
+}
+
+// NewUserHTTPHandler creates a new HTTP handler for users.
+func NewUserHTTPHandler(service *application.UserService) *UserHTTPHandler {
+	return &UserHTTPHandler{service: service}
+}
+
+// userRequest represents the JSON body for creating or updating a user.
+// Groups may be given by ID or name.
+type userRequest struct {
+	Name     string   `json:"name"`
+	Channels []string `json:"channels"`
+	Groups   []string `json:"groups"`
+}
+
+// userResponse represents a user in JSON format.
+type userResponse struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Channels    []string `json:"channels"`
+	Groups      []string `json:"groups"`
+	PlaylistURL string   `json:"playlist_url"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *UserHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/users")
+
+	// GET /users - list all users
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	// POST /users - create a user
+	if r.Method == http.MethodPost && path == "" {
+		h.handleCreate(w, r)
+		return
+	}
+
+	// POST /users/{id}/playlist-token - give a user a new playlist URL
+	if r.Method == http.MethodPost && strings.HasSuffix(path, "/playlist-token") {
+		id := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/playlist-token")
+		h.handleRegenerateToken(w, r, id)
+		return
+	}
+
+	// GET /users/{id} - get a specific user
+	if r.Method == http.MethodGet && path != "" {
+		h.handleGet(w, r, strings.TrimPrefix(path, "/"))
+		return
+	}
+
+	// PUT /users/{id} - update a user
+	if r.Method == http.MethodPut && path != "" {
+		h.handleUpdate(w, r, strings.TrimPrefix(path, "/"))
+		return
+	}
+
+	// DELETE /users/{id} - delete a user
+	if r.Method == http.MethodDelete && path != "" {
+		h.handleDelete(w, r, strings.TrimPrefix(path, "/"))
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toUserResponse(r *http.Request, u user.User) userResponse {
+	return userResponse{
+		ID:          u.ID(),
+		Name:        u.Name(),
+		Channels:    u.Channels(),
+		Groups:      u.Groups(),
+		PlaylistURL: publicBaseURL(r) + "/playlist/" + u.PlaylistToken() + ".m3u",
+		CreatedAt:   formatOptionalTime(u.CreatedAt()),
+	}
+}
+
+// writeUserError maps user errors to HTTP responses.
+func writeUserError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, user.ErrEmptyName):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, user.ErrUserNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// handleList handles GET /users
+func (h *UserHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	users, err := h.service.ListUsers(r.Context())
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	response := make([]userResponse, len(users))
+	for i, u := range users {
+		response[i] = toUserResponse(r, u)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleCreate handles POST /users
+func (h *UserHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req userRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	u, err := h.service.CreateUser(r.Context(), req.Name, req.Channels, req.Groups)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toUserResponse(r, u))
+}
+
+// handleGet handles GET /users/{id}
+func (h *UserHTTPHandler) handleGet(w http.ResponseWriter, r *http.Request, id string) {
+	u, err := h.service.GetUser(r.Context(), id)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toUserResponse(r, u))
+}
+
+// handleUpdate handles PUT /users/{id}
+func (h *UserHTTPHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id string) {
+	var req userRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	u, err := h.service.UpdateUser(r.Context(), id, req.Name, req.Channels, req.Groups)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toUserResponse(r, u))
+}
+
+// handleRegenerateToken handles POST /users/{id}/playlist-token
+func (h *UserHTTPHandler) handleRegenerateToken(w http.ResponseWriter, r *http.Request, id string) {
+	u, err := h.service.RegeneratePlaylistToken(r.Context(), id)
+	if err != nil {
+		writeUserError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toUserResponse(r, u))
+}
+
+// handleDelete handles DELETE /users/{id}
+func (h *UserHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteUser(r.Context(), id); err != nil {
+		writeUserError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,142 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/stream"
+	"github.com/alorle/iptv-manager/internal/user"
+)
+
+// mockUserRepository is an in-memory implementation for testing.
+type mockUserRepository struct {
+	users map[string]user.User
+}
+
+func (m *mockUserRepository) Save(ctx context.Context, u user.User) error {
+	m.users[u.ID()] = u
+	return nil
+}
+
+func (m *mockUserRepository) FindAll(ctx context.Context) ([]user.User, error) {
+	users := make([]user.User, 0, len(m.users))
+	for _, u := range m.users {
+		users = append(users, u)
+	}
+	user.Sort(users)
+	return users, nil
+}
+
+func (m *mockUserRepository) FindByID(ctx context.Context, id string) (user.User, error) {
+	u, ok := m.users[id]
+	if !ok {
+		return user.User{}, user.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (m *mockUserRepository) FindByPlaylistToken(ctx context.Context, token string) (user.User, error) {
+	for _, u := range m.users {
+		if u.PlaylistToken() == token {
+			return u, nil
+		}
+	}
+	return user.User{}, user.ErrUserNotFound
+}
+
+func (m *mockUserRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.users[id]; !ok {
+		return user.ErrUserNotFound
+	}
+	delete(m.users, id)
+	return nil
+}
+
+func newUserTestServices() (*application.UserService, *application.PlaylistService) {
+	la1, _ := stream.NewStream("6c61310000000000000000000000000000000000", "La 1", "")
+	dazn, _ := stream.NewStream("64617a6e00000000000000000000000000000000", "DAZN 1", "")
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{la1, dazn}, nil
+		},
+	}
+	playlist := application.NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+	return application.NewUserService(&mockUserRepository{users: make(map[string]user.User)}, playlist), playlist
+}
+
+func TestUserHTTPHandler(t *testing.T) {
+	service, _ := newUserTestServices()
+	handler := NewUserHTTPHandler(service)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Host = "localhost:8080"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, body := range []string{`{`, `{"name":" "}`} {
+		if rec := do(http.MethodPost, "/users", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+
+	rec := do(http.MethodPost, "/users", `{"name":"Grandma","channels":["La 1"],"groups":["News"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created userResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.Name != "Grandma" || len(created.Channels) != 1 || len(created.Groups) != 1 || created.Groups[0] != "news" {
+		t.Errorf("unexpected user %+v", created)
+	}
+	if !strings.HasPrefix(created.PlaylistURL, "http://localhost:8080/playlist/") || !strings.HasSuffix(created.PlaylistURL, ".m3u") {
+		t.Errorf("unexpected playlist URL %q", created.PlaylistURL)
+	}
+
+	rec = do(http.MethodPut, "/users/"+created.ID, `{"name":"Grandma","channels":["DAZN 1"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var updated userResponse
+	_ = json.NewDecoder(rec.Body).Decode(&updated)
+	if updated.PlaylistURL != created.PlaylistURL || len(updated.Groups) != 0 || updated.Channels[0] != "DAZN 1" {
+		t.Errorf("unexpected update %+v", updated)
+	}
+
+	rec = do(http.MethodPost, "/users/"+created.ID+"/playlist-token", "")
+	var rotated userResponse
+	_ = json.NewDecoder(rec.Body).Decode(&rotated)
+	if rec.Code != http.StatusOK || rotated.PlaylistURL == created.PlaylistURL {
+		t.Errorf("expected a new playlist URL, got %d %+v", rec.Code, rotated)
+	}
+
+	rec = do(http.MethodGet, "/users", "")
+	var list []userResponse
+	_ = json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list) != 1 || list[0].ID != created.ID {
+		t.Errorf("unexpected list %d %+v", rec.Code, list)
+	}
+
+	if rec := do(http.MethodDelete, "/users/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rec.Code)
+	}
+	for _, req := range [][2]string{
+		{http.MethodGet, "/users/" + created.ID},
+		{http.MethodDelete, "/users/" + created.ID},
+		{http.MethodPost, "/users/" + created.ID + "/playlist-token"},
+	} {
+		if rec := do(req[0], req[1], `{"name":"x"}`); rec.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected status 404, got %d", req[0], req[1], rec.Code)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/webhook"
+)
+
+// WebhookHTTPHandler handles HTTP requests for webhooks.
+type WebhookHTTPHandler struct {
+	service *application.WebhookService
+}
+
+// NewWebhookHTTPHandler creates a new HTTP handler for webhooks.
+func NewWebhookHTTPHandler(service *application.WebhookService) *WebhookHTTPHandler {
+	return &WebhookHTTPHandler{service: service}
+}
+
+// webhookRequest represents the JSON body for creating a webhook.
+type webhookRequest struct {
+	URL    string          `json:"url"`
+	Format webhook.Format  `json:"format"`
+	ChatID string          `json:"chat_id"`
+	Events []webhook.Event `json:"events"`
+}
+
+// webhookResponse represents a webhook in JSON format.
+type webhookResponse struct {
+	ID        string          `json:"id"`
+	URL       string          `json:"url"`
+	Format    webhook.Format  `json:"format"`
+	ChatID    string          `json:"chat_id,omitempty"`
+	Events    []webhook.Event `json:"events"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// ServeHTTP routes the request to the appropriate handler based on method and path.
+func (h *WebhookHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/webhooks")
+
+	// GET /webhooks - list all webhooks
+	if r.Method == http.MethodGet && path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	// POST /webhooks - create a webhook
+	if r.Method == http.MethodPost && path == "" {
+		h.handleCreate(w, r)
+		return
+	}
+
+	// DELETE /webhooks/{id} - delete a webhook
+	if r.Method == http.MethodDelete && path != "" {
+		h.handleDelete(w, r, strings.TrimPrefix(path, "/"))
+		return
+	}
+
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toWebhookResponse(wh webhook.Webhook) webhookResponse {
+	events := wh.Events()
+	if events == nil {
+		events = []webhook.Event{}
+	}
+	return webhookResponse{
+		ID:        wh.ID(),
+		URL:       wh.URL(),
+		Format:    wh.Format(),
+		ChatID:    wh.ChatID(),
+		Events:    events,
+		CreatedAt: formatOptionalTime(wh.CreatedAt()),
+	}
+}
+
+// writeWebhookError maps webhook errors to HTTP responses.
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, webhook.ErrInvalidURL), errors.Is(err, webhook.ErrInvalidFormat),
+		errors.Is(err, webhook.ErrMissingChatID), errors.Is(err, webhook.ErrUnknownEvent):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, webhook.ErrWebhookNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+// handleList handles GET /webhooks
+func (h *WebhookHTTPHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.ListWebhooks(r.Context())
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	response := make([]webhookResponse, len(webhooks))
+	for i, wh := range webhooks {
+		response[i] = toWebhookResponse(wh)
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleCreate handles POST /webhooks
+func (h *WebhookHTTPHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	wh, err := h.service.CreateWebhook(r.Context(), req.URL, req.Format, req.ChatID, req.Events)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toWebhookResponse(wh))
+}
+
+// handleDelete handles DELETE /webhooks/{id}
+func (h *WebhookHTTPHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteWebhook(r.Context(), id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
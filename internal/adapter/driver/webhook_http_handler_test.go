@@ -0,0 +1,109 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/application"
+	"github.com/alorle/iptv-manager/internal/webhook"
+)
+
+// mockWebhookRepository is an in-memory implementation for testing.
+type mockWebhookRepository struct {
+	webhooks map[string]webhook.Webhook
+}
+
+func (m *mockWebhookRepository) Save(ctx context.Context, w webhook.Webhook) error {
+	m.webhooks[w.ID()] = w
+	return nil
+}
+
+func (m *mockWebhookRepository) FindAll(ctx context.Context) ([]webhook.Webhook, error) {
+	webhooks := make([]webhook.Webhook, 0, len(m.webhooks))
+	for _, w := range m.webhooks {
+		webhooks = append(webhooks, w)
+	}
+	webhook.Sort(webhooks)
+	return webhooks, nil
+}
+
+func (m *mockWebhookRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.webhooks[id]; !ok {
+		return webhook.ErrWebhookNotFound
+	}
+	delete(m.webhooks, id)
+	return nil
+}
+
+// mockWebhookSender discards every payload.
+type mockWebhookSender struct{}
+
+func (mockWebhookSender) Send(ctx context.Context, url string, payload []byte) error {
+	return nil
+}
+
+func TestWebhookHTTPHandler(t *testing.T) {
+	service := application.NewWebhookService(&mockWebhookRepository{webhooks: make(map[string]webhook.Webhook)}, mockWebhookSender{}, slog.Default())
+	handler := NewWebhookHTTPHandler(service)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"invalid body", `{`, http.StatusBadRequest},
+		{"invalid url", `{"url":"example.com"}`, http.StatusBadRequest},
+		{"unknown format", `{"url":"https://example.com","format":"slack"}`, http.StatusBadRequest},
+		{"telegram without chat", `{"url":"https://api.telegram.org/botX/sendMessage","format":"telegram"}`, http.StatusBadRequest},
+		{"unknown event", `{"url":"https://example.com","events":["engine.up"]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := do(http.MethodPost, "/webhooks", tt.body); rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	rec := do(http.MethodPost, "/webhooks", `{"url":"https://api.telegram.org/botX/sendMessage","format":"telegram","chat_id":"42","events":["recording.finished","engine.down"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created webhookResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID == "" || created.ChatID != "42" || len(created.Events) != 2 || created.Events[0] != webhook.EventEngineDown {
+		t.Errorf("unexpected webhook %+v", created)
+	}
+
+	rec = do(http.MethodGet, "/webhooks", "")
+	var list []webhookResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != created.ID {
+		t.Errorf("expected the created webhook to be listed, got %+v", list)
+	}
+
+	if rec := do(http.MethodDelete, "/webhooks/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/webhooks/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+	if rec := do(http.MethodPut, "/webhooks", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rec.Code)
+	}
+}
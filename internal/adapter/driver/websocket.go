@@ -0,0 +1,224 @@
+package driver
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // required by the WebSocket handshake
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is the fixed key suffix of the RFC 6455 opening handshake.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes used by the server.
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// Close status codes sent to the client.
+const (
+	wsCloseNormal    = 1000
+	wsCloseGoingAway = 1001
+)
+
+// wsMaxControlPayload bounds frames read from the client; the server only
+// expects control frames and small messages.
+const wsMaxControlPayload = 4096
+
+// errWebSocketClosed is returned when writing to a closed connection.
+var errWebSocketClosed = errors.New("websocket closed")
+
+// isWebSocketUpgrade reports whether r asks to switch to the WebSocket protocol.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// websocketConn is a minimal server side of an RFC 6455 connection. It only
+// sends unfragmented text messages; frames from the client are read to
+// answer pings and to notice when the client goes away.
+type websocketConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+
+	writeMu sync.Mutex
+	closed  bool
+
+	done chan struct{}
+}
+
+// upgradeWebSocket completes the opening handshake and takes over the
+// connection. On failure an error response has already been written.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+	if r.Method != http.MethodGet || !isWebSocketUpgrade(r) {
+		writeError(w, http.StatusBadRequest, "websocket upgrade required")
+		return nil, errors.New("not a websocket upgrade")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		writeError(w, http.StatusUpgradeRequired, "unsupported websocket version")
+		return nil, errors.New("unsupported websocket version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		writeError(w, http.StatusBadRequest, "missing Sec-WebSocket-Key")
+		return nil, errors.New("missing websocket key")
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "websocket not supported")
+		return nil, err
+	}
+	// Clear any deadlines the server set for ordinary requests
+	_ = conn.SetDeadline(time.Time{})
+
+	sum := sha1.Sum([]byte(key + websocketGUID)) //nolint:gosec // required by the WebSocket handshake
+	accept := base64.StdEncoding.EncodeToString(sum[:])
+	_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + accept + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	ws := &websocketConn{conn: conn, rw: rw, done: make(chan struct{})}
+	go ws.readLoop()
+	return ws, nil
+}
+
+// Done is closed once the client closes the connection or it fails.
+func (c *websocketConn) Done() <-chan struct{} {
+	return c.done
+}
+
+// WriteText sends data as a single text message.
+func (c *websocketConn) WriteText(data []byte) error {
+	return c.writeFrame(wsOpText, data)
+}
+
+// Close sends a close frame with the given status code and closes the
+// connection.
+func (c *websocketConn) Close(code int) error {
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	_ = c.writeFrame(wsOpClose, payload)
+
+	c.writeMu.Lock()
+	c.closed = true
+	c.writeMu.Unlock()
+	return c.conn.Close()
+}
+
+func (c *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return errWebSocketClosed
+	}
+
+	header := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// readLoop consumes client frames, answering pings, until the client closes
+// the connection or sends something invalid.
+func (c *websocketConn) readLoop() {
+	defer close(c.done)
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case wsOpClose:
+			_ = c.Close(wsCloseNormal)
+			return
+		case wsOpPing:
+			_ = c.writeFrame(wsOpPong, payload)
+		}
+	}
+}
+
+func (c *websocketConn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	// Clients must mask their frames
+	if !masked || length > wsMaxControlPayload {
+		return 0, nil, errors.New("invalid websocket frame")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
@@ -0,0 +1,35 @@
+package driver
+
+import (
+	"net/http"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+// XMLTVHTTPHandler serves the EPG guide for mapped channels in XMLTV format.
+type XMLTVHTTPHandler struct {
+	service *application.PlaylistService
+}
+
+// NewXMLTVHTTPHandler creates a new HTTP handler for the XMLTV guide.
+func NewXMLTVHTTPHandler(service *application.PlaylistService) *XMLTVHTTPHandler {
+	return &XMLTVHTTPHandler{service: service}
+}
+
+// ServeHTTP handles GET /epg.xml
+func (h *XMLTVHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	doc, err := h.service.GenerateXMLTV(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(doc)
+}
@@ -0,0 +1,61 @@
+package driver
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/application"
+)
+
+func TestXMLTVHTTPHandler_ServeHTTP(t *testing.T) {
+	t.Run("GET /epg.xml returns empty XMLTV document with no mappings", func(t *testing.T) {
+		service := application.NewPlaylistService(&mockStreamRepository{}, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+		handler := NewXMLTVHTTPHandler(service)
+
+		req := httptest.NewRequest(http.MethodGet, "/epg.xml", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+			t.Errorf("expected XML content type, got %q", ct)
+		}
+
+		var doc struct {
+			XMLName   xml.Name `xml:"tv"`
+			Generator string   `xml:"generator-info-name,attr"`
+			Channels  []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"channel"`
+		}
+		if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("response is not valid XMLTV: %v", err)
+		}
+		if doc.Generator != "iptv-manager" {
+			t.Errorf("expected generator-info-name 'iptv-manager', got %q", doc.Generator)
+		}
+		if len(doc.Channels) != 0 {
+			t.Errorf("expected no channels, got %d", len(doc.Channels))
+		}
+	})
+
+	t.Run("POST /epg.xml returns 405 method not allowed", func(t *testing.T) {
+		service := application.NewPlaylistService(&mockStreamRepository{}, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+		handler := NewXMLTVHTTPHandler(service)
+
+		req := httptest.NewRequest(http.MethodPost, "/epg.xml", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
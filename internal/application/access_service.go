@@ -0,0 +1,154 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/access"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// ErrGeoIPUnavailable indicates a country rule created without a GeoIP
+// database to locate clients with.
+var ErrGeoIPUnavailable = errors.New("country rules need a geoip database")
+
+// AccessRuleStatus is an access rule with the number of clients it decided
+// on since startup.
+type AccessRuleStatus struct {
+	Rule    access.Rule
+	Hits    int64
+	LastHit time.Time // zero if never hit
+}
+
+// AccessService decides which clients may fetch playlists and streams.
+// Rules are persisted and held in memory, so that checking a client does not
+// touch the repository; hit counters are kept in memory only.
+type AccessService struct {
+	repo driven.AccessRuleRepository
+	geo  driven.GeoIPLocator
+	now  func() time.Time
+
+	mu    sync.Mutex
+	rules []access.Rule
+	hits  map[string]*accessHits
+}
+
+// accessHits counts the clients a rule decided on.
+type accessHits struct {
+	count int64
+	last  time.Time
+}
+
+// NewAccessService creates a new access service with no rules loaded.
+func NewAccessService(repo driven.AccessRuleRepository) *AccessService {
+	return &AccessService{
+		repo: repo,
+		now:  time.Now,
+		hits: make(map[string]*accessHits),
+	}
+}
+
+// SetGeoIPLocator sets the database clients are located with, enabling
+// country rules.
+func (s *AccessService) SetGeoIPLocator(geo driven.GeoIPLocator) {
+	s.geo = geo
+}
+
+// Load reads the persisted rules, replacing those read before. It is meant
+// to run at startup, before any client is checked, and after the database
+// is restored.
+func (s *AccessService) Load(ctx context.Context) error {
+	rules, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = rules
+	return nil
+}
+
+// CreateRule validates and persists a new access rule, applying it to the
+// clients checked afterwards.
+func (s *AccessService) CreateRule(ctx context.Context, network, country string, action access.Action, note string) (access.Rule, error) {
+	r, err := access.NewRule(network, country, action, note, s.now())
+	if err != nil {
+		return access.Rule{}, err
+	}
+	if r.Country() != "" && s.geo == nil {
+		return access.Rule{}, ErrGeoIPUnavailable
+	}
+	if err := s.repo.Save(ctx, r); err != nil {
+		return access.Rule{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, r)
+	access.Sort(s.rules)
+	return r, nil
+}
+
+// ListRules returns all access rules, oldest first, with their hit counters.
+func (s *AccessService) ListRules() []AccessRuleStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]AccessRuleStatus, len(s.rules))
+	for i, r := range s.rules {
+		statuses[i] = AccessRuleStatus{Rule: r}
+		if hits := s.hits[r.ID()]; hits != nil {
+			statuses[i].Hits, statuses[i].LastHit = hits.count, hits.last
+		}
+	}
+	return statuses
+}
+
+// DeleteRule removes an access rule and its hit counter.
+func (s *AccessService) DeleteRule(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = slices.DeleteFunc(s.rules, func(r access.Rule) bool { return r.ID() == id })
+	delete(s.hits, id)
+	return nil
+}
+
+// Check decides whether the client at ip may fetch playlists and streams,
+// counting a hit for the rule that decided. An ip that does not parse
+// matches no network rule.
+func (s *AccessService) Check(ip string) access.Decision {
+	addr, _ := netip.ParseAddr(ip)
+	addr = addr.Unmap()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.rules) == 0 {
+		return access.Decision{Allowed: true}
+	}
+
+	var country string
+	if s.geo != nil && addr.IsValid() {
+		country, _ = s.geo.Country(addr)
+	}
+
+	decision := access.Evaluate(s.rules, addr, country)
+	if decision.RuleID != "" {
+		hits := s.hits[decision.RuleID]
+		if hits == nil {
+			hits = &accessHits{}
+			s.hits[decision.RuleID] = hits
+		}
+		hits.count++
+		hits.last = s.now()
+	}
+	return decision
+}
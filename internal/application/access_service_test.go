@@ -0,0 +1,125 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/access"
+)
+
+// memAccessRuleRepository is an in-memory driven.AccessRuleRepository for testing.
+type memAccessRuleRepository struct {
+	rules map[string]access.Rule
+}
+
+func (r *memAccessRuleRepository) Save(ctx context.Context, rule access.Rule) error {
+	r.rules[rule.ID()] = rule
+	return nil
+}
+
+func (r *memAccessRuleRepository) FindAll(ctx context.Context) ([]access.Rule, error) {
+	rules := make([]access.Rule, 0, len(r.rules))
+	for _, rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	access.Sort(rules)
+	return rules, nil
+}
+
+func (r *memAccessRuleRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := r.rules[id]; !ok {
+		return access.ErrRuleNotFound
+	}
+	delete(r.rules, id)
+	return nil
+}
+
+// staticGeoIPLocator locates the addresses it maps, and no others.
+type staticGeoIPLocator map[string]string
+
+func (l staticGeoIPLocator) Country(addr netip.Addr) (string, bool) {
+	country, ok := l[addr.String()]
+	return country, ok
+}
+
+func TestAccessService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("allows everyone without rules", func(t *testing.T) {
+		service := NewAccessService(&memAccessRuleRepository{rules: map[string]access.Rule{}})
+		if d := service.Check("203.0.113.7"); !d.Allowed {
+			t.Errorf("expected the client allowed, got %+v", d)
+		}
+	})
+
+	t.Run("applies created rules and counts their hits", func(t *testing.T) {
+		repo := &memAccessRuleRepository{rules: map[string]access.Rule{}}
+		service := NewAccessService(repo)
+		service.SetGeoIPLocator(staticGeoIPLocator{"203.0.113.7": "ES", "198.51.100.1": "FR"})
+		clock := now
+		service.now = func() time.Time { return clock }
+
+		lan, err := service.CreateRule(ctx, "192.168.0.0/16", "", access.ActionAllow, "LAN")
+		if err != nil {
+			t.Fatalf("CreateRule() error = %v", err)
+		}
+		clock = now.Add(time.Minute)
+		spain, err := service.CreateRule(ctx, "", "es", access.ActionAllow, "")
+		if err != nil {
+			t.Fatalf("CreateRule() error = %v", err)
+		}
+
+		for ip, want := range map[string]access.Decision{
+			"192.168.1.10":          {Allowed: true, RuleID: lan.ID()},
+			"::ffff:192.168.1.11":   {Allowed: true, RuleID: lan.ID()},
+			"203.0.113.7":           {Allowed: true, RuleID: spain.ID()},
+			"198.51.100.1":          {},
+			"not-an-ip-address:123": {},
+		} {
+			if got := service.Check(ip); got != want {
+				t.Errorf("Check(%s) = %+v, want %+v", ip, got, want)
+			}
+		}
+
+		statuses := service.ListRules()
+		if len(statuses) != 2 || statuses[0].Rule.ID() != lan.ID() || statuses[0].Hits != 2 || !statuses[0].LastHit.Equal(clock) ||
+			statuses[1].Hits != 1 {
+			t.Errorf("unexpected statuses %+v", statuses)
+		}
+
+		if err := service.DeleteRule(ctx, lan.ID()); err != nil {
+			t.Fatalf("DeleteRule() error = %v", err)
+		}
+		if d := service.Check("192.168.1.10"); d.Allowed {
+			t.Errorf("expected the LAN denied once its rule is deleted, got %+v", d)
+		}
+		if err := service.DeleteRule(ctx, lan.ID()); !errors.Is(err, access.ErrRuleNotFound) {
+			t.Errorf("DeleteRule() error = %v, want ErrRuleNotFound", err)
+		}
+	})
+
+	t.Run("loads persisted rules", func(t *testing.T) {
+		r, _ := access.NewRule("10.0.0.0/8", "", access.ActionDeny, "", now)
+		service := NewAccessService(&memAccessRuleRepository{rules: map[string]access.Rule{r.ID(): r}})
+		if err := service.Load(ctx); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if d := service.Check("10.1.2.3"); d.Allowed || d.RuleID != r.ID() {
+			t.Errorf("expected the client denied by %s, got %+v", r.ID(), d)
+		}
+	})
+
+	t.Run("rejects country rules without a geoip database", func(t *testing.T) {
+		service := NewAccessService(&memAccessRuleRepository{rules: map[string]access.Rule{}})
+		if _, err := service.CreateRule(ctx, "", "ES", access.ActionDeny, ""); !errors.Is(err, ErrGeoIPUnavailable) {
+			t.Errorf("CreateRule() error = %v, want ErrGeoIPUnavailable", err)
+		}
+		if _, err := service.CreateRule(ctx, "10.0.0.0/33", "", access.ActionDeny, ""); !errors.Is(err, access.ErrInvalidNetwork) {
+			t.Errorf("CreateRule() error = %v, want ErrInvalidNetwork", err)
+		}
+	})
+}
@@ -7,9 +7,15 @@ import (
 	"io"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/circuitbreaker"
+	"github.com/alorle/iptv-manager/internal/logging"
 	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/ratelimit"
+	"github.com/alorle/iptv-manager/internal/streaming"
 )
 
 var (
@@ -19,6 +25,9 @@ var (
 	ErrStreamNotActive = errors.New("stream not active")
 	// ErrInvalidInfoHash indicates the infohash is invalid or empty.
 	ErrInvalidInfoHash = errors.New("invalid infohash")
+	// ErrStreamLimitReached indicates starting another engine stream would
+	// exceed the configured maximum.
+	ErrStreamLimitReached = errors.New("maximum concurrent engine streams reached")
 )
 
 // AceStreamProxyService manages multiplexed AceStream connections.
@@ -27,24 +36,114 @@ var (
 type AceStreamProxyService struct {
 	engine       driven.AceStreamEngine
 	sessions     *sessionRegistry
+	clients      *clientRegistry
+	enginePIDs   *enginePIDTracker
 	mu           sync.Mutex
 	pidGen       *pidGenerator
 	logger       *slog.Logger
-	writeTimeout time.Duration
-	counters     streamCounters
-	startedAt    time.Time
+	hotLogger    atomic.Pointer[slog.Logger]
+	writeTimeout atomic.Int64
+	// clientIdleTimeout is how long ReapIdleClients lets a client stall
+	clientIdleTimeout atomic.Int64
+	counters          streamCounters
+	startedAt         time.Time
+	breaker           *circuitbreaker.Breaker
+	failover          failoverState
+	bandwidth         *bandwidthState
+	resume            *resumeState
+	resilience        resilienceState
+	events            *EventBus
+	stall             stallDetection
+	timeoutRules      writeTimeoutRules
+	prebuffer         PrebufferOptions // guarded by mu
+	queue             streamQueue
+	// engineSessions persists enginePIDs, if set
+	engineSessions driven.EngineSessionRepository
+	// transcoder downscales video of sessions asking for it, if set
+	transcoder driven.VideoTranscoder
 }
 
 // NewAceStreamProxyService creates a new proxy service with the given engine.
-func NewAceStreamProxyService(engine driven.AceStreamEngine, logger *slog.Logger, writeTimeout time.Duration) *AceStreamProxyService {
-	return &AceStreamProxyService{
-		engine:       engine,
-		sessions:     newSessionRegistry(),
-		pidGen:       newPIDGenerator(),
-		logger:       logger,
-		writeTimeout: writeTimeout,
-		startedAt:    time.Now(),
+// The optional breaker guards engine stream starts: once open, new sessions
+// fail fast with ErrEngineUnavailable instead of piling up on a dead engine.
+// A nil breaker disables this protection.
+func NewAceStreamProxyService(engine driven.AceStreamEngine, logger *slog.Logger, writeTimeout time.Duration, breaker *circuitbreaker.Breaker) *AceStreamProxyService {
+	s := &AceStreamProxyService{
+		engine:     engine,
+		sessions:   newSessionRegistry(),
+		clients:    newClientRegistry(),
+		enginePIDs: newEnginePIDTracker(),
+		pidGen:     newPIDGenerator(),
+		logger:     logger,
+		startedAt:  time.Now(),
+		breaker:    breaker,
+		bandwidth:  newBandwidthState(),
+		resume:     newResumeState(),
+		resilience: resilienceState{cfg: DefaultResilienceConfig()},
+		stall:      stallDetection{slowWrite: clientSlowWrite, writes: clientStallWrites},
 	}
+	s.SetWriteTimeout(writeTimeout)
+	return s
+}
+
+// SetWriteTimeout changes the default timeout for writes to a client. It may
+// be called while streams are running; streams already reading from the
+// engine keep the timeout they started with.
+func (s *AceStreamProxyService) SetWriteTimeout(d time.Duration) {
+	s.writeTimeout.Store(int64(d))
+}
+
+// SetLogSampleRate logs one in every n occurrences of each message on the
+// streaming hot path: clients joining and leaving streams, and clients
+// falling behind or dropped from a broadcast. It applies to engine streams
+// started afterwards; n of 1 or less logs every occurrence.
+func (s *AceStreamProxyService) SetLogSampleRate(n int) {
+	logger := s.logger
+	if n > 1 {
+		logger = slog.New(logging.NewSamplingHandler(s.logger.Handler(), n))
+	}
+	s.hotLogger.Store(logger)
+}
+
+// hotPathLogger returns the logger of the streaming hot path, sampled if a
+// sample rate is set.
+func (s *AceStreamProxyService) hotPathLogger() *slog.Logger {
+	if logger := s.hotLogger.Load(); logger != nil {
+		return logger
+	}
+	return s.logger
+}
+
+// SetEventBus enables publishing EventStreamStarted and EventStreamStopped
+// as engine streams come and go.
+func (s *AceStreamProxyService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// SetVideoTranscoder enables downscaling the video of streams requested with
+// a video transcode setting. Without one the setting is ignored and video is
+// passed through unchanged.
+func (s *AceStreamProxyService) SetVideoTranscoder(transcoder driven.VideoTranscoder) {
+	s.transcoder = transcoder
+}
+
+// SetMaxEngineStreams caps how many engine streams may run at once. Clients
+// joining a stream that is already running are always accepted; starting a
+// new one beyond the cap fails with ErrStreamLimitReached. Zero or negative
+// removes the cap.
+func (s *AceStreamProxyService) SetMaxEngineStreams(max int) {
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	s.sessions.max = max
+}
+
+// SetClientBuffer configures how each client of a shared stream is buffered
+// and what happens when a client reads slower than the engine delivers.
+// It applies to engine streams started afterwards.
+func (s *AceStreamProxyService) SetClientBuffer(opts ClientBufferOptions) {
+	s.sessions.mu.Lock()
+	defer s.sessions.mu.Unlock()
+	s.sessions.buffer = opts
 }
 
 // StreamToClient initiates a stream for the given infohash and streams content
@@ -54,49 +153,107 @@ func NewAceStreamProxyService(engine driven.AceStreamEngine, logger *slog.Logger
 // goroutine that reads from the engine and broadcasts to all subscribers.
 // Subsequent clients subscribe to the same broadcast.
 func (s *AceStreamProxyService) StreamToClient(ctx context.Context, infoHash string, dst io.Writer) error {
+	return s.StreamToClientWithTimeout(ctx, infoHash, dst, 0)
+}
+
+// StreamToClientWithTimeout behaves like StreamToClient but applies the given
+// write timeout to this client only, so different client classes (e.g. LAN vs
+// WAN) can be tuned independently. A zero or negative timeout falls back to the
+// service-wide default.
+func (s *AceStreamProxyService) StreamToClientWithTimeout(ctx context.Context, infoHash string, dst io.Writer, writeTimeout time.Duration) error {
+	return s.StreamToClientWithOptions(ctx, infoHash, dst, StreamOptions{WriteTimeout: writeTimeout})
+}
+
+// StreamOptions tunes how a single client's stream is served.
+type StreamOptions struct {
+	// WriteTimeout overrides the write timeout rules and the service-wide
+	// write timeout when positive.
+	WriteTimeout time.Duration
+	// TranscodeAudio asks the engine to re-encode the stream's audio.
+	// Clients only share an engine stream when they request the same setting.
+	TranscodeAudio channel.AudioTranscode
+	// TranscodeVideo downscales the stream's video through the video
+	// transcoder, if one is set. Like TranscodeAudio, it splits sessions.
+	TranscodeVideo channel.VideoTranscode
+	// ResumeToken identifies the client across reconnects. When a resume
+	// grace window is set, a client dropping its connection keeps its slot
+	// for the window and a reconnect with the same token takes it over.
+	ResumeToken string
+}
+
+// StreamToClientWithOptions behaves like StreamToClient with per-client options.
+func (s *AceStreamProxyService) StreamToClientWithOptions(ctx context.Context, infoHash string, dst io.Writer, opts StreamOptions) error {
 	if infoHash == "" {
 		return ErrInvalidInfoHash
 	}
 
-	// Generate unique PID for this client
-	pid := s.pidGen.Generate()
-
-	s.counters.clientsServed.Add(1)
-
-	// Register the client session
-	session, isNew, err := s.sessions.AddClient(infoHash, pid, s.logger)
-	if err != nil {
-		s.logger.Error("failed to register client", "infohash", infoHash, "pid", pid, "error", err)
-		return fmt.Errorf("failed to register client: %w", err)
+	engineOpts := driven.StreamOptions{TranscodeAudio: opts.TranscodeAudio}
+	video := opts.TranscodeVideo
+	if s.transcoder == nil {
+		video = channel.VideoTranscodeNone
 	}
+	key := sessionKey(infoHash, engineOpts, video)
 
-	// Ensure cleanup on exit
-	defer s.cleanupClient(infoHash, pid)
-
-	// If this is a new session, start the engine stream and the broadcast pump
-	if isNew {
-		s.logger.Info("creating new stream session", "infohash", infoHash, "pid", pid)
-		if err := s.startEngineStream(ctx, session); err != nil {
-			s.logger.Error("failed to start engine stream", "infohash", infoHash, "pid", pid, "error", err)
-			s.sessions.RemoveClient(infoHash, pid)
-			return fmt.Errorf("failed to start engine stream: %w", err)
-		}
-
-		engineCtx, engineCancel := context.WithCancel(context.Background())
-		session.SetEngineCancel(engineCancel)
-		go s.pumpEngineToSession(engineCtx, session)
+	// A reconnecting client takes over its parked slot; others get a new PID
+	session, pid, resumed := s.resumeClient(opts.ResumeToken, key)
+	if resumed {
+		s.counters.clientsResumed.Add(1)
+		s.logger.InfoContext(ctx, "resuming client session", "infohash", infoHash, "pid", pid)
 	} else {
-		s.logger.Debug("joining existing stream session", "infohash", infoHash, "pid", pid)
-		// Wait for the stream to be ready if another client is starting it
-		if err := s.waitForStreamReady(ctx, session); err != nil {
-			s.logger.Error("stream not ready", "infohash", infoHash, "pid", pid, "error", err)
-			s.sessions.RemoveClient(infoHash, pid)
+		pid = s.pidGen.Generate()
+		s.counters.clientsServed.Add(1)
+	}
+
+	// Track the client so it can be listed and forcibly disconnected
+	reqCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	client := s.clients.Add(pid, infoHash, clientInfoFromContext(ctx), func() {
+		cancel()
+		abortWrites(dst)
+	})
+	defer s.clients.Remove(pid)
+
+	if !resumed {
+		var err error
+		if session, err = s.joinSession(ctx, key, infoHash, engineOpts, video, pid); err != nil {
 			return err
 		}
 	}
 
+	writeTimeout := opts.WriteTimeout
+	if writeTimeout <= 0 {
+		writeTimeout = s.clientWriteTimeout(clientInfoFromContext(ctx).UserAgent, opts.ResumeToken)
+	}
+
+	// Clients without an address, such as recordings, are limited on their own
+	clientKey := clientInfoFromContext(ctx).ClientIP
+	if clientKey == "" {
+		clientKey = pid
+	}
+	limiters, releaseLimiters := s.bandwidth.acquireClient(clientKey)
+	defer releaseLimiters()
+
+	// Write deadlines and flushes need the response itself, so they are
+	// applied to dst below the wrappers that hide its http.ResponseWriter
+	timed := streaming.NewTimeoutWriter(dst, writeTimeout, s.hotPathLogger(), infoHash, pid)
+
+	// Writes are timed below the rate limiter, so that throttling a client
+	// is not mistaken for the client being slow
+	latency := streaming.NewLatencyWriter(timed, s.stall.slowWrite, s.stall.writes, func(stats streaming.WriteLatencyStats) {
+		s.clientStalled(ctx, client, stats)
+	})
+	client.latency.Store(latency)
+	defer s.logSlowClient(ctx, client, latency)
+
 	// Subscribe to the broadcaster — blocks until stream ends or client disconnects
-	return session.GetBroadcaster().Subscribe(ctx, pid, dst, s.writeTimeout)
+	err := session.GetBroadcaster().Subscribe(ctx, pid, client.writer(ratelimit.NewWriter(ctx, latency, limiters...)), 0)
+
+	// Only a client that went away may come back; one disconnected on
+	// purpose or whose stream ended is removed at once
+	dropped := reqCtx.Err() != nil || (ctx.Err() == nil && streaming.IsClientDisconnectError(err))
+	s.releaseClient(key, pid, opts.ResumeToken, dropped)
+	return err
 }
 
 // pumpEngineToSession reads from the engine stream and writes to the session
@@ -104,11 +261,29 @@ func (s *AceStreamProxyService) StreamToClient(ctx context.Context, infoHash str
 func (s *AceStreamProxyService) pumpEngineToSession(ctx context.Context, session *streamSession) {
 	broadcaster := session.GetBroadcaster()
 
+	limiter, releaseLimiter := s.bandwidth.acquireStream(session.Key())
+	defer releaseLimiter()
+
+	// Clients receive the first byte once the prebuffer, if any, is complete
+	var out io.Writer = broadcaster
+	release := func() {}
+	if opts := s.Prebuffer(); opts.enabled() {
+		prebuffer := newPrebufferWriter(broadcaster, opts, func(data StreamPrebufferData) {
+			data.InfoHash = session.InfoHash()
+			s.events.Publish(EventStreamPrebuffer, data)
+		})
+		out, release = prebuffer, prebuffer.Release
+	}
+
 	pid := session.GetFirstPID()
-	err := s.streamWithReconnection(ctx, session, pid, broadcaster)
+	var dst io.Writer = &countingWriter{dst: ratelimit.NewWriter(ctx, out, limiter), count: &s.counters.bytesStreamed}
+	err := s.pumpThroughTranscoder(ctx, session, dst, func(dst io.Writer) error {
+		return s.streamWithReconnection(ctx, session, pid, dst)
+	})
+	release()
 
 	if err != nil && err != context.Canceled {
-		s.logger.Error("engine pump ended with error",
+		s.logger.ErrorContext(ctx, "engine pump ended with error",
 			"infohash", session.InfoHash(),
 			"error", err)
 		broadcaster.CloseWithError(err)
@@ -117,6 +292,25 @@ func (s *AceStreamProxyService) pumpEngineToSession(ctx context.Context, session
 	}
 }
 
+// pumpThroughTranscoder runs pump writing to dst, through the video
+// transcoder when the session downscales video.
+func (s *AceStreamProxyService) pumpThroughTranscoder(ctx context.Context, session *streamSession, dst io.Writer, pump func(io.Writer) error) error {
+	height := session.VideoTranscode().Height()
+	if height == 0 {
+		return pump(dst)
+	}
+
+	transcoded, err := s.transcoder.Transcode(ctx, dst, height)
+	if err != nil {
+		return fmt.Errorf("failed to start video transcoder: %w", err)
+	}
+	err = pump(transcoded)
+	if closeErr := transcoded.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 // startEngineStream initiates the stream with the AceStream engine.
 func (s *AceStreamProxyService) startEngineStream(ctx context.Context, session *streamSession) error {
 	// Use the first PID in the session to start the stream
@@ -125,12 +319,25 @@ func (s *AceStreamProxyService) startEngineStream(ctx context.Context, session *
 		return fmt.Errorf("no PID available to start stream")
 	}
 
-	s.logger.Info("starting stream in engine", "infohash", session.InfoHash(), "pid", firstPID)
+	if s.breaker != nil {
+		if err := s.breaker.Allow(); err != nil {
+			s.logger.WarnContext(ctx, "engine circuit breaker open, rejecting stream start",
+				"infohash", session.InfoHash(),
+				"pid", firstPID,
+				"retry_after", s.breaker.RetryAfter())
+			err = fmt.Errorf("%w: %w", ErrEngineUnavailable, err)
+			session.SetError(err)
+			return err
+		}
+	}
 
-	streamURL, err := s.engine.StartStream(ctx, session.InfoHash(), firstPID)
+	s.logger.InfoContext(ctx, "starting stream in engine", "infohash", session.InfoHash(), "pid", firstPID)
+
+	streamURL, err := s.engine.StartStream(ctx, session.InfoHash(), firstPID, session.EngineOptions())
 	if err != nil {
+		s.recordEngineResult(err)
 		s.counters.streamStartFailures.Add(1)
-		s.logger.Error("engine start failed",
+		s.logger.ErrorContext(ctx, "engine start failed",
 			"infohash", session.InfoHash(),
 			"pid", firstPID,
 			"error", err,
@@ -140,14 +347,17 @@ func (s *AceStreamProxyService) startEngineStream(ctx context.Context, session *
 		return err
 	}
 
+	s.recordEngineResult(nil)
 	s.counters.streamsStarted.Add(1)
-	s.logger.Info("stream ready",
+	s.logger.InfoContext(ctx, "stream ready",
 		"infohash", session.InfoHash(),
 		"stream_url", streamURL,
 		"total_started", s.counters.streamsStarted.Load())
 	session.SetEnginePID(firstPID)
 	session.SetStreamURL(streamURL)
+	s.trackEnginePID(firstPID, session)
 	session.MarkReady()
+	s.events.Publish(EventStreamStarted, StreamEventData{InfoHash: session.InfoHash()})
 	return nil
 }
 
@@ -180,9 +390,18 @@ func (s *AceStreamProxyService) waitForStreamReady(ctx context.Context, session
 }
 
 // streamWithReconnection streams content with automatic reconnection on failure.
+// A dropped engine connection is first resumed from the last byte received,
+// if the engine supports range requests; failing that, the engine stream is
+// restarted, backing off as set with SetResilience.
 func (s *AceStreamProxyService) streamWithReconnection(ctx context.Context, session *streamSession, pid string, dst io.Writer) error {
-	const maxRetries = 3
-	retryDelay := 2 * time.Second
+	cfg := s.resilience.get()
+	maxRetries := cfg.ReconnectAttempts
+
+	// Bytes received since the stream URL was opened, the offset a dropped
+	// connection resumes from
+	var received atomic.Int64
+	dst = &countingWriter{dst: dst, count: &received}
+	ranged, canResume := s.engine.(driven.AceStreamRangeStreamer)
 
 	var lastErr error
 	for attempt := 0; attempt < maxRetries; attempt++ {
@@ -191,7 +410,10 @@ func (s *AceStreamProxyService) streamWithReconnection(ctx context.Context, sess
 			return fmt.Errorf("stream URL not available")
 		}
 
-		err := s.engine.StreamContent(ctx, streamURL, dst, session.InfoHash(), pid, s.writeTimeout)
+		err := s.engine.StreamContent(ctx, streamURL, dst, session.InfoHash(), pid, time.Duration(s.writeTimeout.Load()))
+		if canResume {
+			canResume, err = s.resumeUpstream(ctx, ranged, session, streamURL, pid, dst, &received, err)
+		}
 		if err == nil || err == context.Canceled {
 			return err
 		}
@@ -199,8 +421,9 @@ func (s *AceStreamProxyService) streamWithReconnection(ctx context.Context, sess
 		lastErr = err
 
 		if attempt < maxRetries-1 {
+			retryDelay := cfg.backoff(attempt + 1)
 			s.counters.reconnectionAttempts.Add(1)
-			s.logger.Warn("reconnection attempt",
+			s.logger.WarnContext(ctx, "reconnection attempt",
 				"infohash", session.InfoHash(),
 				"attempt", attempt+1,
 				"max_attempts", maxRetries,
@@ -213,7 +436,7 @@ func (s *AceStreamProxyService) streamWithReconnection(ctx context.Context, sess
 				return ctx.Err()
 			case <-time.After(retryDelay):
 				if restartErr := s.restartStream(ctx, session, pid); restartErr != nil {
-					s.logger.Error("stream restart failed",
+					s.logger.ErrorContext(ctx, "stream restart failed",
 						"infohash", session.InfoHash(),
 						"pid", pid,
 						"restart_error", restartErr,
@@ -222,12 +445,12 @@ func (s *AceStreamProxyService) streamWithReconnection(ctx context.Context, sess
 					return fmt.Errorf("stream failed and could not restart: %w (original: %v)", restartErr, err)
 				}
 				s.counters.reconnectionSuccesses.Add(1)
-				retryDelay *= 2
+				received.Store(0)
 			}
 		}
 	}
 
-	s.logger.Error("reconnection retries exhausted",
+	s.logger.ErrorContext(ctx, "reconnection retries exhausted",
 		"infohash", session.InfoHash(),
 		"final_error", lastErr,
 		"total_start_failures", s.counters.streamStartFailures.Load(),
@@ -237,6 +460,35 @@ func (s *AceStreamProxyService) streamWithReconnection(ctx context.Context, sess
 	return fmt.Errorf("stream failed after %d attempts: %w", maxRetries, lastErr)
 }
 
+// resumeUpstream resumes an engine connection that dropped with err from
+// the last byte received, for as long as each resumed connection delivers
+// more content. Returns the error that finally ended the stream, and false
+// once the engine turns out not to support resuming.
+func (s *AceStreamProxyService) resumeUpstream(ctx context.Context, ranged driven.AceStreamRangeStreamer, session *streamSession, streamURL, pid string, dst io.Writer, received *atomic.Int64, err error) (bool, error) {
+	for err != nil && ctx.Err() == nil && !errors.Is(err, streaming.ErrWriteTimeout) {
+		offset := received.Load()
+		if offset == 0 {
+			break
+		}
+
+		s.logger.InfoContext(ctx, "resuming engine connection",
+			"infohash", session.InfoHash(),
+			"pid", pid,
+			"offset", offset,
+			"previous_error", err)
+		resumeErr := ranged.StreamContentFrom(ctx, streamURL, offset, dst, session.InfoHash(), pid, time.Duration(s.writeTimeout.Load()))
+		if errors.Is(resumeErr, streaming.ErrRangeNotSupported) {
+			return false, err
+		}
+		if received.Load() == offset {
+			return true, resumeErr
+		}
+		s.counters.upstreamResumes.Add(1)
+		err = resumeErr
+	}
+	return true, err
+}
+
 // restartStream attempts to restart a failed stream by first stopping the old
 // one to avoid leaking engine sessions.
 func (s *AceStreamProxyService) restartStream(ctx context.Context, session *streamSession, pid string) error {
@@ -248,15 +500,17 @@ func (s *AceStreamProxyService) restartStream(ctx context.Context, session *stre
 	defer stopCancel()
 	if err := s.engine.StopStream(stopCtx, pid); err != nil {
 		s.counters.streamStopFailures.Add(1)
-		s.logger.Warn("failed to stop old stream during restart",
+		s.logger.WarnContext(ctx, "failed to stop old stream during restart",
 			"infohash", session.InfoHash(),
 			"pid", pid,
 			"error", err)
 	} else {
 		s.counters.streamsStopped.Add(1)
+		s.untrackEnginePID(pid)
 	}
 
-	streamURL, err := s.engine.StartStream(ctx, session.InfoHash(), pid)
+	streamURL, err := s.engine.StartStream(ctx, session.InfoHash(), pid, session.EngineOptions())
+	s.recordEngineResult(err)
 	if err != nil {
 		s.counters.streamStartFailures.Add(1)
 		return err
@@ -265,26 +519,46 @@ func (s *AceStreamProxyService) restartStream(ctx context.Context, session *stre
 	s.counters.streamsStarted.Add(1)
 	session.SetEnginePID(pid)
 	session.SetStreamURL(streamURL)
+	s.trackEnginePID(pid, session)
 	return nil
 }
 
+// recordEngineResult feeds the outcome of an engine start into the breaker.
+// Caller cancellations are not the engine's fault and are ignored.
+func (s *AceStreamProxyService) recordEngineResult(err error) {
+	if s.breaker == nil {
+		return
+	}
+	switch {
+	case err == nil:
+		s.breaker.RecordSuccess()
+	case errors.Is(err, context.Canceled), errors.Is(err, driven.ErrEngineBusy):
+	default:
+		s.breaker.RecordFailure()
+	}
+}
+
 // cleanupClient removes the client and stops the stream if it's the last one.
-func (s *AceStreamProxyService) cleanupClient(infoHash, pid string) {
+func (s *AceStreamProxyService) cleanupClient(key, pid string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	// Grab session before removal so we can access engineCancel
-	session := s.sessions.GetSession(infoHash)
+	session := s.sessions.GetSession(key)
+	if session == nil {
+		return
+	}
+	infoHash := session.InfoHash()
 
-	remainingClients, isLast := s.sessions.RemoveClient(infoHash, pid)
+	remainingClients, isLast := s.sessions.RemoveClient(key, pid)
 
-	s.logger.Info("client disconnecting",
+	s.hotPathLogger().Info("client disconnecting",
 		"infohash", infoHash,
 		"pid", pid,
 		"remaining_clients", remainingClients)
 
 	// If this was the last client, cancel the engine pump and stop the stream
-	if isLast && session != nil {
+	if isLast {
 		s.logger.Info("stopping stream (last client disconnected)",
 			"infohash", infoHash)
 
@@ -301,13 +575,15 @@ func (s *AceStreamProxyService) cleanupClient(infoHash, pid string) {
 			s.logger.Error("failed to stop stream", "infohash", infoHash, "pid", enginePID, "error", err)
 		} else {
 			s.counters.streamsStopped.Add(1)
+			s.untrackEnginePID(enginePID)
 		}
+		s.events.Publish(EventStreamStopped, StreamEventData{InfoHash: infoHash})
 	}
 }
 
 // IsStreamActive returns true if the given infohash has an active session with clients.
 func (s *AceStreamProxyService) IsStreamActive(infoHash string) bool {
-	return s.sessions.GetSession(infoHash) != nil
+	return s.sessions.HasInfoHash(infoHash)
 }
 
 // GetActiveStreams returns information about all active stream sessions.
@@ -315,6 +591,28 @@ func (s *AceStreamProxyService) GetActiveStreams() []StreamInfo {
 	return s.sessions.GetAllSessions()
 }
 
+// ClientSessions returns every connected client with its delivery stats,
+// oldest first.
+func (s *AceStreamProxyService) ClientSessions() []ClientSession {
+	return s.clients.Snapshot()
+}
+
+// DisconnectClient ends the stream of the client with the given session ID.
+// Returns ErrClientSessionNotFound if no such client is connected.
+func (s *AceStreamProxyService) DisconnectClient(id string) error {
+	if err := s.clients.Disconnect(id); err != nil {
+		return err
+	}
+	s.logger.Info("client disconnected by request", "session_id", id)
+	return nil
+}
+
+// Counters returns a point-in-time read of the lifecycle counters without
+// contacting the engine, for cheap periodic scraping.
+func (s *AceStreamProxyService) Counters() StreamCountersSnapshot {
+	return s.counters.snapshot()
+}
+
 // Diagnostics returns a full diagnostic snapshot of the streaming subsystem,
 // including lifecycle counters, active session details, and engine health.
 func (s *AceStreamProxyService) Diagnostics(ctx context.Context) StreamDiagnostics {
@@ -347,7 +645,24 @@ type StreamInfo struct {
 // sessionRegistry manages all active stream sessions.
 type sessionRegistry struct {
 	mu       sync.RWMutex
-	sessions map[string]*streamSession // infohash -> session
+	sessions map[string]*streamSession // session key -> session
+	max      int                       // maximum sessions, 0 for no limit
+	buffer   ClientBufferOptions       // per-client buffering of new sessions
+	freed    chan struct{}             // closed when a session is removed
+}
+
+// sessionKey identifies the engine stream a client needs. Clients of the same
+// infohash share a session unless they request different engine options or
+// video transcoding.
+func sessionKey(infoHash string, opts driven.StreamOptions, video channel.VideoTranscode) string {
+	key := infoHash
+	if opts.TranscodeAudio != channel.AudioTranscodeNone {
+		key += "+transcode_audio=" + string(opts.TranscodeAudio)
+	}
+	if video != channel.VideoTranscodeNone {
+		key += "+transcode_video=" + string(video)
+	}
+	return key
 }
 
 func newSessionRegistry() *sessionRegistry {
@@ -356,16 +671,20 @@ func newSessionRegistry() *sessionRegistry {
 	}
 }
 
-// AddClient adds a client to a session, creating the session if needed.
-// Returns the session, whether it's new, and any error.
-func (r *sessionRegistry) AddClient(infoHash, pid string, logger *slog.Logger) (*streamSession, bool, error) {
+// AddClient adds a client to the session with the given key, creating the
+// session if needed. Returns the session, whether it's new, and any error.
+// Returns ErrStreamLimitReached if a new session would exceed the maximum.
+func (r *sessionRegistry) AddClient(key, infoHash string, opts driven.StreamOptions, video channel.VideoTranscode, pid string, logger *slog.Logger) (*streamSession, bool, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	session, exists := r.sessions[infoHash]
+	session, exists := r.sessions[key]
 	if !exists {
-		session = newStreamSession(infoHash, logger)
-		r.sessions[infoHash] = session
+		if r.max > 0 && len(r.sessions) >= r.max {
+			return nil, false, ErrStreamLimitReached
+		}
+		session = newStreamSession(key, infoHash, opts, video, logger, r.buffer)
+		r.sessions[key] = session
 	}
 
 	session.AddPID(pid)
@@ -380,11 +699,11 @@ func (r *sessionRegistry) AddClient(infoHash, pid string, logger *slog.Logger) (
 
 // RemoveClient removes a client from a session.
 // Returns the remaining client count and true if this was the last client (session removed).
-func (r *sessionRegistry) RemoveClient(infoHash, pid string) (int, bool) {
+func (r *sessionRegistry) RemoveClient(key, pid string) (int, bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	session, exists := r.sessions[infoHash]
+	session, exists := r.sessions[key]
 	if !exists {
 		return 0, false
 	}
@@ -393,18 +712,64 @@ func (r *sessionRegistry) RemoveClient(infoHash, pid string) (int, bool) {
 	remainingClients := session.ClientCount()
 
 	if remainingClients == 0 {
-		delete(r.sessions, infoHash)
+		delete(r.sessions, key)
+		r.notifyFreed()
 		return 0, true
 	}
 
 	return remainingClients, false
 }
 
-// GetSession returns the session for the given infohash, or nil if not found.
-func (r *sessionRegistry) GetSession(infoHash string) *streamSession {
+// slotFreed returns a channel closed when a session is next removed, which
+// frees an engine stream slot.
+func (r *sessionRegistry) slotFreed() <-chan struct{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.freed == nil {
+		r.freed = make(chan struct{})
+	}
+	return r.freed
+}
+
+// notifyFreed wakes the requests waiting for a slot. Callers must hold r.mu.
+func (r *sessionRegistry) notifyFreed() {
+	if r.freed != nil {
+		close(r.freed)
+		r.freed = nil
+	}
+}
+
+// GetSession returns the session for the given key, or nil if not found.
+func (r *sessionRegistry) GetSession(key string) *streamSession {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sessions[key]
+}
+
+// FindByInfoHash returns a session streaming the given infohash, or nil if
+// there is none. Sessions that differ only in engine options share the
+// same engine statistics, so any of them will do.
+func (r *sessionRegistry) FindByInfoHash(infoHash string) *streamSession {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, session := range r.sessions {
+		if session.InfoHash() == infoHash {
+			return session
+		}
+	}
+	return nil
+}
+
+// HasInfoHash reports whether any session streams the given infohash.
+func (r *sessionRegistry) HasInfoHash(infoHash string) bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.sessions[infoHash]
+	for _, session := range r.sessions {
+		if session.InfoHash() == infoHash {
+			return true
+		}
+	}
+	return false
 }
 
 // Count returns the number of active sessions.
@@ -448,6 +813,18 @@ func (r *sessionRegistry) diagnosticSnapshot() []SessionDiagnostic {
 	return result
 }
 
+// list returns every active session.
+func (r *sessionRegistry) list() []*streamSession {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*streamSession, 0, len(r.sessions))
+	for _, session := range r.sessions {
+		result = append(result, session)
+	}
+	return result
+}
+
 // GetAllSessions returns information about all active sessions.
 func (r *sessionRegistry) GetAllSessions() []StreamInfo {
 	r.mu.RLock()
@@ -467,7 +844,10 @@ func (r *sessionRegistry) GetAllSessions() []StreamInfo {
 // streamSession represents an active stream with multiple clients.
 type streamSession struct {
 	mu           sync.RWMutex
+	key          string
 	infoHash     string
+	engineOpts   driven.StreamOptions
+	video        channel.VideoTranscode
 	pids         map[string]struct{}
 	streamURL    string
 	enginePID    string // PID used to start the engine stream
@@ -478,19 +858,34 @@ type streamSession struct {
 	createdAt    time.Time
 }
 
-func newStreamSession(infoHash string, logger *slog.Logger) *streamSession {
+func newStreamSession(key, infoHash string, opts driven.StreamOptions, video channel.VideoTranscode, logger *slog.Logger, buffer ClientBufferOptions) *streamSession {
 	return &streamSession{
+		key:         key,
 		infoHash:    infoHash,
+		engineOpts:  opts,
+		video:       video,
 		pids:        make(map[string]struct{}),
-		broadcaster: newStreamBroadcaster(infoHash, logger),
+		broadcaster: newStreamBroadcaster(infoHash, logger, buffer),
 		createdAt:   time.Now(),
 	}
 }
 
+func (s *streamSession) Key() string {
+	return s.key
+}
+
 func (s *streamSession) InfoHash() string {
 	return s.infoHash
 }
 
+func (s *streamSession) EngineOptions() driven.StreamOptions {
+	return s.engineOpts
+}
+
+func (s *streamSession) VideoTranscode() channel.VideoTranscode {
+	return s.video
+}
+
 func (s *streamSession) AddPID(pid string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
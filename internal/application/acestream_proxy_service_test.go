@@ -6,12 +6,16 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/circuitbreaker"
 	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/streaming"
 )
 
 func TestAceStreamProxyService_StreamToClient(t *testing.T) {
@@ -36,7 +40,7 @@ func TestAceStreamProxyService_StreamToClient(t *testing.T) {
 			},
 		}
 
-		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
 		var buf bytes.Buffer
 
 		err := service.StreamToClient(context.Background(), "test-infohash", &buf)
@@ -57,7 +61,7 @@ func TestAceStreamProxyService_StreamToClient(t *testing.T) {
 
 	t.Run("returns error for empty infohash", func(t *testing.T) {
 		mockEngine := &mockAceStreamEngine{}
-		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
 		var buf bytes.Buffer
 
 		err := service.StreamToClient(context.Background(), "", &buf)
@@ -74,7 +78,7 @@ func TestAceStreamProxyService_StreamToClient(t *testing.T) {
 			},
 		}
 
-		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
 		var buf bytes.Buffer
 
 		err := service.StreamToClient(context.Background(), "test-infohash", &buf)
@@ -109,7 +113,7 @@ func TestAceStreamProxyService_StreamToClient(t *testing.T) {
 			},
 		}
 
-		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
 
 		// Start first client
 		var buf1 bytes.Buffer
@@ -181,6 +185,130 @@ func TestAceStreamProxyService_StreamToClient(t *testing.T) {
 		}
 	})
 
+	t.Run("transcoded clients get their own engine stream", func(t *testing.T) {
+		var mu sync.Mutex
+		var started []channel.AudioTranscode
+		blockStream := make(chan struct{})
+
+		mockEngine := &mockAceStreamEngine{
+			startOptionsFunc: func(infoHash string, opts driven.StreamOptions) {
+				mu.Lock()
+				started = append(started, opts.TranscodeAudio)
+				mu.Unlock()
+			},
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				<-blockStream
+				return nil
+			},
+			stopStreamFunc: func(ctx context.Context, pid string) error {
+				return nil
+			},
+		}
+
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
+
+		done := make(chan error, 2)
+		go func() {
+			done <- service.StreamToClient(context.Background(), "shared-infohash", io.Discard)
+		}()
+		go func() {
+			opts := StreamOptions{TranscodeAudio: channel.AudioTranscodeAC3}
+			done <- service.StreamToClientWithOptions(context.Background(), "shared-infohash", io.Discard, opts)
+		}()
+
+		time.Sleep(100 * time.Millisecond)
+
+		if got := len(service.GetActiveStreams()); got != 2 {
+			t.Errorf("expected 2 active streams, got %d", got)
+		}
+		if !service.IsStreamActive("shared-infohash") {
+			t.Error("expected infohash to be reported active")
+		}
+
+		close(blockStream)
+		<-done
+		<-done
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(started) != 2 {
+			t.Fatalf("expected 2 engine starts, got %d", len(started))
+		}
+		if started[0] == started[1] {
+			t.Errorf("expected different transcode options per engine stream, got %q twice", started[0])
+		}
+	})
+
+	t.Run("downscaled clients stream through the video transcoder", func(t *testing.T) {
+		mockEngine := &mockAceStreamEngine{
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				_, err := dst.Write([]byte("stream"))
+				return err
+			},
+			stopStreamFunc: func(ctx context.Context, pid string) error {
+				return nil
+			},
+		}
+
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
+		opts := StreamOptions{TranscodeVideo: channel.VideoTranscode720p}
+
+		// Without a transcoder the setting is ignored
+		var buf bytes.Buffer
+		if err := service.StreamToClientWithOptions(context.Background(), "test-infohash", &buf, opts); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if buf.String() != "stream" {
+			t.Errorf("expected the original stream, got %q", buf.String())
+		}
+
+		transcoder := &fakeVideoTranscoder{}
+		service.SetVideoTranscoder(transcoder)
+		buf.Reset()
+		if err := service.StreamToClientWithOptions(context.Background(), "test-infohash", &buf, opts); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if buf.String() != "STREAM" {
+			t.Errorf("expected the transcoded stream, got %q", buf.String())
+		}
+		if transcoder.height != 720 {
+			t.Errorf("expected transcoding to 720 lines, got %d", transcoder.height)
+		}
+	})
+
+	t.Run("sets write deadlines and flushes through the client wrappers", func(t *testing.T) {
+		mockEngine := &mockAceStreamEngine{
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				_, err := dst.Write([]byte("stream"))
+				return err
+			},
+			stopStreamFunc: func(ctx context.Context, pid string) error {
+				return nil
+			},
+		}
+
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
+		if err := service.SetBandwidthLimits(BandwidthLimits{PerClient: 1 << 20}); err != nil {
+			t.Fatalf("SetBandwidthLimits() error = %v", err)
+		}
+		rw := &deadlineRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+		start := time.Now()
+		if err := service.StreamToClientWithOptions(context.Background(), "test-infohash", rw, StreamOptions{WriteTimeout: 100 * time.Millisecond}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(rw.deadlines) == 0 {
+			t.Fatal("expected a write deadline to be set")
+		}
+		if d := rw.deadlines[0].Sub(start); d < 100*time.Millisecond || d > time.Second {
+			t.Errorf("expected a deadline 100ms after the write, got %v", d)
+		}
+		if !rw.Flushed || rw.Body.String() != "stream" {
+			t.Errorf("expected the stream flushed to the client, got %q (flushed %v)", rw.Body.String(), rw.Flushed)
+		}
+	})
+
 	t.Run("last client disconnect stops the stream", func(t *testing.T) {
 		stopCalled := false
 		mockEngine := &mockAceStreamEngine{
@@ -197,7 +325,7 @@ func TestAceStreamProxyService_StreamToClient(t *testing.T) {
 			},
 		}
 
-		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
 		var buf bytes.Buffer
 
 		err := service.StreamToClient(context.Background(), "test-infohash", &buf)
@@ -232,7 +360,7 @@ func TestAceStreamProxyService_StreamToClient(t *testing.T) {
 			},
 		}
 
-		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
 		ctx, cancel := context.WithCancel(context.Background())
 		var buf bytes.Buffer
 
@@ -274,7 +402,7 @@ func TestAceStreamProxyService_Reconnection(t *testing.T) {
 			},
 		}
 
-		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
 		var buf bytes.Buffer
 
 		err := service.StreamToClient(context.Background(), "test-infohash", &buf)
@@ -304,7 +432,7 @@ func TestAceStreamProxyService_Reconnection(t *testing.T) {
 			},
 		}
 
-		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
 		var buf bytes.Buffer
 
 		err := service.StreamToClient(context.Background(), "test-infohash", &buf)
@@ -315,6 +443,93 @@ func TestAceStreamProxyService_Reconnection(t *testing.T) {
 			t.Errorf("expected error about max retries, got %v", err)
 		}
 	})
+
+	t.Run("resumes a dropped connection from the last byte received", func(t *testing.T) {
+		var starts int
+		var offsets []int64
+		engine := &mockRangeEngine{
+			mockAceStreamEngine: &mockAceStreamEngine{
+				startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+					starts++
+					return "http://localhost:6878/stream/test", nil
+				},
+				streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+					_, _ = dst.Write([]byte("first "))
+					return errors.New("connection reset")
+				},
+			},
+			streamContentFromFunc: func(ctx context.Context, streamURL string, offset int64, dst io.Writer) error {
+				offsets = append(offsets, offset)
+				_, err := dst.Write([]byte("second"))
+				return err
+			},
+		}
+
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		var buf bytes.Buffer
+
+		if err := service.StreamToClient(context.Background(), "test-infohash", &buf); err != nil {
+			t.Fatalf("expected no error after resuming, got %v", err)
+		}
+		if buf.String() != "first second" {
+			t.Errorf("expected 'first second', got %q", buf.String())
+		}
+		if len(offsets) != 1 || offsets[0] != 6 {
+			t.Errorf("expected a single resume from offset 6, got %v", offsets)
+		}
+		if starts != 1 {
+			t.Errorf("expected the engine stream not to restart, got %d starts", starts)
+		}
+		if c := service.Counters(); c.UpstreamResumes != 1 || c.ReconnectionAttempts != 0 {
+			t.Errorf("expected 1 resume and no reconnection, got %+v", c)
+		}
+	})
+
+	t.Run("restarts the stream when the engine cannot resume", func(t *testing.T) {
+		var starts, resumes int
+		engine := &mockRangeEngine{
+			mockAceStreamEngine: &mockAceStreamEngine{
+				startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+					starts++
+					return "http://localhost:6878/stream/test", nil
+				},
+				streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+					_, _ = dst.Write([]byte("data"))
+					if starts == 1 {
+						return errors.New("connection reset")
+					}
+					return nil
+				},
+			},
+			streamContentFromFunc: func(ctx context.Context, streamURL string, offset int64, dst io.Writer) error {
+				resumes++
+				return streaming.ErrRangeNotSupported
+			},
+		}
+
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		var buf bytes.Buffer
+
+		if err := service.StreamToClient(context.Background(), "test-infohash", &buf); err != nil {
+			t.Fatalf("expected no error after restarting, got %v", err)
+		}
+		if starts != 2 || resumes != 1 {
+			t.Errorf("expected 1 resume attempt and 2 starts, got %d and %d", resumes, starts)
+		}
+		if c := service.Counters(); c.UpstreamResumes != 0 || c.ReconnectionSuccesses != 1 {
+			t.Errorf("expected no resume and 1 reconnection, got %+v", c)
+		}
+	})
+}
+
+// mockRangeEngine is a mockAceStreamEngine that can resume streams.
+type mockRangeEngine struct {
+	*mockAceStreamEngine
+	streamContentFromFunc func(ctx context.Context, streamURL string, offset int64, dst io.Writer) error
+}
+
+func (m *mockRangeEngine) StreamContentFrom(ctx context.Context, streamURL string, offset int64, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+	return m.streamContentFromFunc(ctx, streamURL, offset, dst)
 }
 
 func TestAceStreamProxyService_GetActiveStreams(t *testing.T) {
@@ -333,7 +548,7 @@ func TestAceStreamProxyService_GetActiveStreams(t *testing.T) {
 			},
 		}
 
-		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
 
 		// Start two clients on different infohashes
 		go func() {
@@ -371,13 +586,17 @@ func TestAceStreamProxyService_GetActiveStreams(t *testing.T) {
 // mockAceStreamEngine is a mock implementation of the AceStreamEngine port for testing.
 type mockAceStreamEngine struct {
 	startStreamFunc   func(ctx context.Context, infoHash, pid string) (streamURL string, err error)
+	startOptionsFunc  func(infoHash string, opts driven.StreamOptions)
 	getStatsFunc      func(ctx context.Context, pid string) (stats driven.StreamStats, err error)
 	stopStreamFunc    func(ctx context.Context, pid string) error
 	streamContentFunc func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error
 	pingFunc          func(ctx context.Context) error
 }
 
-func (m *mockAceStreamEngine) StartStream(ctx context.Context, infoHash, pid string) (string, error) {
+func (m *mockAceStreamEngine) StartStream(ctx context.Context, infoHash, pid string, opts driven.StreamOptions) (string, error) {
+	if m.startOptionsFunc != nil {
+		m.startOptionsFunc(infoHash, opts)
+	}
 	if m.startStreamFunc != nil {
 		return m.startStreamFunc(ctx, infoHash, pid)
 	}
@@ -411,3 +630,174 @@ func (m *mockAceStreamEngine) Ping(ctx context.Context) error {
 	}
 	return nil
 }
+
+func TestAceStreamProxyService_MaxEngineStreams(t *testing.T) {
+	blockChan := make(chan struct{})
+	defer close(blockChan)
+	mockEngine := &mockAceStreamEngine{
+		streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+			_, _ = dst.Write([]byte("data"))
+			<-blockChan
+			return nil
+		},
+	}
+
+	service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
+	service.SetMaxEngineStreams(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = service.StreamToClient(ctx, "infohash-1", io.Discard)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !service.IsStreamActive("infohash-1") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	err := service.StreamToClient(context.Background(), "infohash-2", io.Discard)
+	if !errors.Is(err, ErrStreamLimitReached) {
+		t.Fatalf("expected ErrStreamLimitReached for a new stream, got %v", err)
+	}
+
+	joinCtx, joinCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer joinCancel()
+	if err := service.StreamToClient(joinCtx, "infohash-1", io.Discard); errors.Is(err, ErrStreamLimitReached) {
+		t.Error("joining a running stream should not count against the limit")
+	}
+}
+
+func TestAceStreamProxyService_EngineBreaker(t *testing.T) {
+	t.Run("open breaker rejects new sessions without calling the engine", func(t *testing.T) {
+		var startCalls int
+		var mu sync.Mutex
+		mockEngine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				mu.Lock()
+				startCalls++
+				mu.Unlock()
+				return "", errors.New("engine down")
+			},
+		}
+
+		breaker := circuitbreaker.New(2, time.Minute)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, breaker)
+
+		for i := 0; i < 2; i++ {
+			var buf bytes.Buffer
+			if err := service.StreamToClient(context.Background(), "test-infohash", &buf); err == nil {
+				t.Fatalf("attempt %d: expected error, got nil", i+1)
+			}
+		}
+
+		var buf bytes.Buffer
+		err := service.StreamToClient(context.Background(), "test-infohash", &buf)
+		if !errors.Is(err, ErrEngineUnavailable) {
+			t.Fatalf("expected ErrEngineUnavailable, got %v", err)
+		}
+		if !errors.Is(err, circuitbreaker.ErrOpen) {
+			t.Errorf("expected error to wrap circuitbreaker.ErrOpen, got %v", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if startCalls != 2 {
+			t.Errorf("expected 2 engine start calls, got %d", startCalls)
+		}
+		if n := len(service.GetActiveStreams()); n != 0 {
+			t.Errorf("expected no active sessions, got %d", n)
+		}
+	})
+
+	t.Run("successful start resets the breaker", func(t *testing.T) {
+		fail := true
+		mockEngine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				if fail {
+					return "", errors.New("engine hiccup")
+				}
+				return "http://localhost:6878/stream/test", nil
+			},
+		}
+
+		breaker := circuitbreaker.New(2, time.Minute)
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, breaker)
+
+		var buf bytes.Buffer
+		_ = service.StreamToClient(context.Background(), "test-infohash", &buf)
+		if breaker.Failures() != 1 {
+			t.Fatalf("expected 1 failure, got %d", breaker.Failures())
+		}
+
+		fail = false
+		if err := service.StreamToClient(context.Background(), "test-infohash", &buf); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if breaker.Failures() != 0 {
+			t.Errorf("expected failures reset to 0, got %d", breaker.Failures())
+		}
+	})
+}
+
+func TestAceStreamProxyService_Counters(t *testing.T) {
+	t.Run("counts bytes streamed from the engine", func(t *testing.T) {
+		streamContent := []byte("0123456789")
+		mockEngine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "http://localhost:6878/stream/test", nil
+			},
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				_, err := dst.Write(streamContent)
+				return err
+			},
+		}
+
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
+		var buf bytes.Buffer
+		if err := service.StreamToClient(context.Background(), "test-infohash", &buf); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		counters := service.Counters()
+		if counters.BytesStreamed != int64(len(streamContent)) {
+			t.Errorf("expected %d bytes streamed, got %d", len(streamContent), counters.BytesStreamed)
+		}
+		if counters.StreamsStarted != 1 {
+			t.Errorf("expected 1 stream started, got %d", counters.StreamsStarted)
+		}
+	})
+}
+
+// fakeVideoTranscoder upper-cases streams in place of re-encoding them.
+type fakeVideoTranscoder struct {
+	height int
+}
+
+func (f *fakeVideoTranscoder) Transcode(ctx context.Context, dst io.Writer, height int) (io.WriteCloser, error) {
+	f.height = height
+	return upperWriter{dst}, nil
+}
+
+type upperWriter struct {
+	dst io.Writer
+}
+
+func (w upperWriter) Write(p []byte) (int, error) {
+	return w.dst.Write(bytes.ToUpper(p))
+}
+
+func (upperWriter) Close() error {
+	return nil
+}
+
+// deadlineRecorder records the write deadlines set on a response.
+type deadlineRecorder struct {
+	*httptest.ResponseRecorder
+	deadlines []time.Time
+}
+
+func (r *deadlineRecorder) SetWriteDeadline(deadline time.Time) error {
+	r.deadlines = append(r.deadlines, deadline)
+	return nil
+}
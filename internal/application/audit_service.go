@@ -0,0 +1,36 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/audit"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// AuditService keeps the trail of changes made to the lineup.
+type AuditService struct {
+	repo driven.AuditLogRepository
+	now  func() time.Time
+}
+
+// NewAuditService creates a new audit service.
+func NewAuditService(repo driven.AuditLogRepository) *AuditService {
+	return &AuditService{repo: repo, now: time.Now}
+}
+
+// Record appends an entry for a change to subject, attributed to the API
+// token the request was authenticated with, if any.
+func (s *AuditService) Record(ctx context.Context, action audit.Action, subject, from, to string) error {
+	e, err := audit.NewEntry(action, subject, from, to, APITokenFromContext(ctx), s.now())
+	if err != nil {
+		return err
+	}
+	return s.repo.Append(ctx, e)
+}
+
+// ListEntries returns up to limit entries, most recent first. A non-positive
+// limit returns every entry.
+func (s *AuditService) ListEntries(ctx context.Context, limit int) ([]audit.Entry, error) {
+	return s.repo.FindRecent(ctx, limit)
+}
@@ -0,0 +1,203 @@
+package application
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/playlist"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// AuthConfig holds the credentials and session settings for AuthService.
+type AuthConfig struct {
+	// Username and Password protect the web UI. Authentication is disabled
+	// when either is empty.
+	Username string
+	Password string
+	// SessionKey signs session cookies. Sessions do not survive a restart
+	// if the key is regenerated on startup.
+	SessionKey []byte
+	// SessionTTL is how long a login session stays valid.
+	SessionTTL time.Duration
+}
+
+// AuthService authenticates UI logins and API tokens.
+type AuthService struct {
+	tokenRepo driven.TokenRepository
+	config    AuthConfig
+	signer    *auth.SessionSigner
+	now       func() time.Time
+}
+
+// NewAuthService creates a new authentication service.
+func NewAuthService(tokenRepo driven.TokenRepository, config AuthConfig) *AuthService {
+	return &AuthService{
+		tokenRepo: tokenRepo,
+		config:    config,
+		signer:    auth.NewSessionSigner(config.SessionKey),
+		now:       time.Now,
+	}
+}
+
+// Enabled reports whether requests must be authenticated.
+func (s *AuthService) Enabled() bool {
+	return s.config.Username != "" && s.config.Password != ""
+}
+
+// Login checks the UI credentials and returns a signed session value and
+// its expiry. Returns auth.ErrInvalidCredentials on mismatch.
+func (s *AuthService) Login(username, password string) (string, time.Time, error) {
+	userOK := subtle.ConstantTimeCompare([]byte(username), []byte(s.config.Username)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(password), []byte(s.config.Password)) == 1
+	if !s.Enabled() || !userOK || !passOK {
+		return "", time.Time{}, auth.ErrInvalidCredentials
+	}
+
+	expires := s.now().Add(s.config.SessionTTL)
+	return s.signer.Issue(username, expires), expires, nil
+}
+
+// ValidateSession verifies a session value issued by Login.
+// Returns auth.ErrInvalidSession or auth.ErrSessionExpired.
+func (s *AuthService) ValidateSession(value string) error {
+	user, err := s.signer.Verify(value, s.now())
+	if err != nil {
+		return err
+	}
+	if user != s.config.Username {
+		// The configured username changed since the session was issued.
+		return auth.ErrInvalidSession
+	}
+	return nil
+}
+
+// ValidateToken checks an API token secret against the stored tokens.
+// Returns auth.ErrInvalidToken if no token matches.
+func (s *AuthService) ValidateToken(ctx context.Context, secret string) error {
+	_, err := s.AuthenticateToken(ctx, secret)
+	return err
+}
+
+// AuthenticateToken returns the stored token whose secret is secret.
+// Returns auth.ErrInvalidToken if no token matches.
+func (s *AuthService) AuthenticateToken(ctx context.Context, secret string) (auth.Token, error) {
+	if secret == "" {
+		return auth.Token{}, auth.ErrInvalidToken
+	}
+	tok, err := s.tokenRepo.FindBySecretHash(ctx, auth.HashSecret(secret))
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenNotFound) {
+			return auth.Token{}, auth.ErrInvalidToken
+		}
+		return auth.Token{}, fmt.Errorf("failed to look up token: %w", err)
+	}
+	if !tok.Matches(secret) {
+		return auth.Token{}, auth.ErrInvalidToken
+	}
+	return tok, nil
+}
+
+type apiTokenKey struct{}
+
+// WithAPIToken returns a copy of ctx recording that the request was
+// authenticated with the API token tokenID.
+func WithAPIToken(ctx context.Context, tokenID string) context.Context {
+	return context.WithValue(ctx, apiTokenKey{}, tokenID)
+}
+
+// APITokenFromContext returns the ID of the API token recorded by
+// WithAPIToken, or empty string if there is none.
+func APITokenFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(apiTokenKey{}).(string)
+	return id
+}
+
+// CreateToken creates and persists a new API token. The returned secret is
+// the only time the plaintext is available.
+// Returns auth.ErrEmptyTokenName if name is empty.
+func (s *AuthService) CreateToken(ctx context.Context, name string) (auth.Token, string, error) {
+	tok, secret, err := auth.NewToken(name, s.now())
+	if err != nil {
+		return auth.Token{}, "", fmt.Errorf("failed to create token: %w", err)
+	}
+	if err := s.tokenRepo.Save(ctx, tok); err != nil {
+		return auth.Token{}, "", fmt.Errorf("failed to save token: %w", err)
+	}
+	return tok, secret, nil
+}
+
+// ListTokens returns all API tokens, oldest first.
+func (s *AuthService) ListTokens(ctx context.Context) ([]auth.Token, error) {
+	tokens, err := s.tokenRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	slices.SortFunc(tokens, func(a, b auth.Token) int {
+		return a.CreatedAt().Compare(b.CreatedAt())
+	})
+	return tokens, nil
+}
+
+// RevokeToken deletes an API token.
+// Returns auth.ErrTokenNotFound if it does not exist.
+func (s *AuthService) RevokeToken(ctx context.Context, id string) error {
+	if err := s.tokenRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// SetPlaylistPrefs replaces the playlist preferences of an API token.
+// Groups may be given by ID or name, and the quality by any label
+// channel.ParseQuality accepts; both are stored normalized.
+// Returns auth.ErrTokenNotFound if the token does not exist,
+// channel.ErrInvalidQuality if the quality is unknown, and
+// playlist.ErrUnknownFormat if the format is.
+func (s *AuthService) SetPlaylistPrefs(ctx context.Context, id string, groups []string, quality, format string, includeDisabled bool) (auth.Token, error) {
+	if quality != "" {
+		q, err := channel.ParseQuality(quality)
+		if err != nil {
+			return auth.Token{}, err
+		}
+		quality = string(q)
+	}
+	if format != "" {
+		if _, err := playlist.ByName(format); err != nil {
+			return auth.Token{}, err
+		}
+	}
+
+	tok, err := s.tokenRepo.FindByID(ctx, id)
+	if err != nil {
+		return auth.Token{}, err
+	}
+	tok = tok.WithPlaylistPrefs(auth.NewPlaylistPrefs(slugGroups(groups), quality, format, includeDisabled))
+	if err := s.tokenRepo.Save(ctx, tok); err != nil {
+		return auth.Token{}, fmt.Errorf("failed to save token: %w", err)
+	}
+	return tok, nil
+}
+
+// RequestPlaylistPrefs returns the playlist preferences of the API token
+// the request carrying ctx was authenticated with, and false if it was not
+// authenticated with a token or the token was revoked meanwhile.
+func (s *AuthService) RequestPlaylistPrefs(ctx context.Context) (auth.PlaylistPrefs, bool, error) {
+	id := APITokenFromContext(ctx)
+	if id == "" {
+		return auth.PlaylistPrefs{}, false, nil
+	}
+	tok, err := s.tokenRepo.FindByID(ctx, id)
+	if errors.Is(err, auth.ErrTokenNotFound) {
+		return auth.PlaylistPrefs{}, false, nil
+	}
+	if err != nil {
+		return auth.PlaylistPrefs{}, false, fmt.Errorf("failed to look up token: %w", err)
+	}
+	return tok.PlaylistPrefs(), true, nil
+}
@@ -0,0 +1,209 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/playlist"
+)
+
+// mockTokenRepository is an in-memory driven.TokenRepository.
+type mockTokenRepository struct {
+	tokens map[string]auth.Token
+}
+
+func newMockTokenRepository() *mockTokenRepository {
+	return &mockTokenRepository{tokens: make(map[string]auth.Token)}
+}
+
+func (m *mockTokenRepository) Save(ctx context.Context, token auth.Token) error {
+	m.tokens[token.ID()] = token
+	return nil
+}
+
+func (m *mockTokenRepository) FindAll(ctx context.Context) ([]auth.Token, error) {
+	tokens := []auth.Token{}
+	for _, t := range m.tokens {
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+func (m *mockTokenRepository) FindByID(ctx context.Context, id string) (auth.Token, error) {
+	t, ok := m.tokens[id]
+	if !ok {
+		return auth.Token{}, auth.ErrTokenNotFound
+	}
+	return t, nil
+}
+
+func (m *mockTokenRepository) FindBySecretHash(ctx context.Context, secretHash string) (auth.Token, error) {
+	for _, t := range m.tokens {
+		if t.SecretHash() == secretHash {
+			return t, nil
+		}
+	}
+	return auth.Token{}, auth.ErrTokenNotFound
+}
+
+func (m *mockTokenRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := m.tokens[id]; !ok {
+		return auth.ErrTokenNotFound
+	}
+	delete(m.tokens, id)
+	return nil
+}
+
+func newTestAuthService(repo *mockTokenRepository) *AuthService {
+	return NewAuthService(repo, AuthConfig{
+		Username:   "admin",
+		Password:   "secret",
+		SessionKey: []byte("test-key"),
+		SessionTTL: time.Hour,
+	})
+}
+
+func TestAuthService_Login(t *testing.T) {
+	t.Run("issues a session for valid credentials", func(t *testing.T) {
+		service := newTestAuthService(newMockTokenRepository())
+
+		value, expires, err := service.Login("admin", "secret")
+		if err != nil {
+			t.Fatalf("Login() error = %v", err)
+		}
+		if time.Until(expires) <= 0 {
+			t.Errorf("expected expiry in the future, got %v", expires)
+		}
+		if err := service.ValidateSession(value); err != nil {
+			t.Errorf("ValidateSession() error = %v", err)
+		}
+	})
+
+	t.Run("rejects invalid credentials", func(t *testing.T) {
+		service := newTestAuthService(newMockTokenRepository())
+
+		for _, creds := range [][2]string{{"admin", "wrong"}, {"root", "secret"}, {"", ""}} {
+			if _, _, err := service.Login(creds[0], creds[1]); !errors.Is(err, auth.ErrInvalidCredentials) {
+				t.Errorf("Login(%q, %q) expected ErrInvalidCredentials, got %v", creds[0], creds[1], err)
+			}
+		}
+	})
+
+	t.Run("rejects expired sessions", func(t *testing.T) {
+		service := newTestAuthService(newMockTokenRepository())
+		value, _, _ := service.Login("admin", "secret")
+
+		service.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		if err := service.ValidateSession(value); !errors.Is(err, auth.ErrSessionExpired) {
+			t.Errorf("expected ErrSessionExpired, got %v", err)
+		}
+	})
+
+	t.Run("disabled without credentials", func(t *testing.T) {
+		service := NewAuthService(newMockTokenRepository(), AuthConfig{SessionKey: []byte("k")})
+		if service.Enabled() {
+			t.Error("expected auth to be disabled")
+		}
+		if _, _, err := service.Login("", ""); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials, got %v", err)
+		}
+	})
+}
+
+func TestAuthService_Tokens(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("created tokens validate until revoked", func(t *testing.T) {
+		service := newTestAuthService(newMockTokenRepository())
+
+		tok, secret, err := service.CreateToken(ctx, "kodi")
+		if err != nil {
+			t.Fatalf("CreateToken() error = %v", err)
+		}
+		if err := service.ValidateToken(ctx, secret); err != nil {
+			t.Errorf("ValidateToken() error = %v", err)
+		}
+
+		tokens, _ := service.ListTokens(ctx)
+		if len(tokens) != 1 || tokens[0].Name() != "kodi" {
+			t.Errorf("ListTokens() = %v, want one token named kodi", tokens)
+		}
+
+		if err := service.RevokeToken(ctx, tok.ID()); err != nil {
+			t.Fatalf("RevokeToken() error = %v", err)
+		}
+		if err := service.ValidateToken(ctx, secret); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken after revoke, got %v", err)
+		}
+	})
+
+	t.Run("rejects unknown and empty secrets", func(t *testing.T) {
+		service := newTestAuthService(newMockTokenRepository())
+		for _, secret := range []string{"", "iptv_unknown"} {
+			if err := service.ValidateToken(ctx, secret); !errors.Is(err, auth.ErrInvalidToken) {
+				t.Errorf("ValidateToken(%q) expected ErrInvalidToken, got %v", secret, err)
+			}
+		}
+	})
+
+	t.Run("revoking unknown token returns ErrTokenNotFound", func(t *testing.T) {
+		service := newTestAuthService(newMockTokenRepository())
+		if err := service.RevokeToken(ctx, "missing"); !errors.Is(err, auth.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound, got %v", err)
+		}
+	})
+
+	t.Run("rejects empty token name", func(t *testing.T) {
+		service := newTestAuthService(newMockTokenRepository())
+		if _, _, err := service.CreateToken(ctx, " "); !errors.Is(err, auth.ErrEmptyTokenName) {
+			t.Errorf("expected ErrEmptyTokenName, got %v", err)
+		}
+	})
+}
+
+func TestAuthService_PlaylistPrefs(t *testing.T) {
+	ctx := context.Background()
+	repo := newMockTokenRepository()
+	svc := newTestAuthService(repo)
+	tok, _, _ := svc.CreateToken(ctx, "kids tv")
+
+	t.Run("stores normalized prefs", func(t *testing.T) {
+		updated, err := svc.SetPlaylistPrefs(ctx, tok.ID(), []string{"Kids TV"}, "hd", "m3u8", true)
+		if err != nil {
+			t.Fatalf("SetPlaylistPrefs() error = %v", err)
+		}
+		prefs := updated.PlaylistPrefs()
+		if g := prefs.Groups(); len(g) != 1 || g[0] != "kids-tv" || prefs.Quality() != "720p" || prefs.Format() != "m3u8" || !prefs.IncludeDisabled() {
+			t.Errorf("unexpected prefs %+v", prefs)
+		}
+		if repo.tokens[tok.ID()].PlaylistPrefs().Quality() != "720p" {
+			t.Error("expected prefs to be persisted")
+		}
+	})
+
+	t.Run("rejects invalid values and unknown tokens", func(t *testing.T) {
+		if _, err := svc.SetPlaylistPrefs(ctx, tok.ID(), nil, "8k", "", false); !errors.Is(err, channel.ErrInvalidQuality) {
+			t.Errorf("expected ErrInvalidQuality, got %v", err)
+		}
+		if _, err := svc.SetPlaylistPrefs(ctx, tok.ID(), nil, "", "xspf", false); !errors.Is(err, playlist.ErrUnknownFormat) {
+			t.Errorf("expected ErrUnknownFormat, got %v", err)
+		}
+		if _, err := svc.SetPlaylistPrefs(ctx, "nope", nil, "", "", false); !errors.Is(err, auth.ErrTokenNotFound) {
+			t.Errorf("expected ErrTokenNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns the prefs of the request's token", func(t *testing.T) {
+		prefs, ok, err := svc.RequestPlaylistPrefs(WithAPIToken(ctx, tok.ID()))
+		if err != nil || !ok || prefs.Format() != "m3u8" {
+			t.Errorf("RequestPlaylistPrefs() = %+v, %v, %v", prefs, ok, err)
+		}
+		if _, ok, err := svc.RequestPlaylistPrefs(ctx); ok || err != nil {
+			t.Errorf("expected no prefs without a token, got %v, %v", ok, err)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/backup"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// BackupService exports, restores and periodically snapshots the database.
+type BackupService struct {
+	db        driven.DatabaseBackup
+	store     driven.BackupStore
+	retention int
+	access    *AccessService
+	events    *EventBus
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// RestoreResult reports a completed restore.
+type RestoreResult struct {
+	// PreRestoreBackup names the snapshot of the database taken before it
+	// was replaced, so the restore can be undone.
+	PreRestoreBackup string
+	// RestartRequired reports that state kept in memory could not be
+	// reloaded from the restored database, so the server must be restarted
+	// to use all of it.
+	RestartRequired bool
+}
+
+// NewBackupService creates a new BackupService. Stored backups beyond the
+// newest retention ones are deleted after each scheduled backup; zero or less
+// keeps them all.
+func NewBackupService(db driven.DatabaseBackup, store driven.BackupStore, retention int, logger *slog.Logger) *BackupService {
+	return &BackupService{
+		db:        db,
+		store:     store,
+		retention: retention,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetAccessService reloads the access rules of access after a restore.
+func (s *BackupService) SetAccessService(access *AccessService) {
+	s.access = access
+}
+
+// SetEventBus publishes an event after a restore, so services caching what
+// the database held, such as the playlist's Last-Modified time, catch up.
+func (s *BackupService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// WriteBackup streams a consistent snapshot of the database to w.
+func (s *BackupService) WriteBackup(ctx context.Context, w io.Writer) error {
+	_, err := s.db.WriteBackup(ctx, w)
+	return err
+}
+
+// Restore replaces the database with the snapshot read from r. The current
+// database is saved to the backup store first so the restore can be undone.
+// The access rules are then reloaded from the restored database; if that
+// fails, the result reports a restart is required.
+// Returns backup.ErrInvalidBackup if r is not a valid snapshot.
+func (s *BackupService) Restore(ctx context.Context, r io.Reader) (RestoreResult, error) {
+	name, err := s.snapshot(ctx, backup.ReasonPreRestore)
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("failed to save pre-restore backup: %w", err)
+	}
+
+	if err := s.db.Restore(ctx, r); err != nil {
+		return RestoreResult{PreRestoreBackup: name}, err
+	}
+	s.logger.Info("database restored from backup", "pre_restore_backup", name)
+
+	result := RestoreResult{PreRestoreBackup: name}
+	if s.access != nil {
+		if err := s.access.Load(ctx); err != nil {
+			s.logger.Error("failed to reload access rules after restore, restart required", "error", err)
+			result.RestartRequired = true
+		}
+	}
+	s.events.Publish(EventDatabaseRestored, result)
+	return result, nil
+}
+
+// RunScheduledBackup saves a snapshot to the backup store and prunes
+// backups beyond the retention limit. It is meant to be run by a scheduler.
+func (s *BackupService) RunScheduledBackup(ctx context.Context) error {
+	name, err := s.snapshot(ctx, backup.ReasonScheduled)
+	if err != nil {
+		return fmt.Errorf("failed to save scheduled backup: %w", err)
+	}
+	s.logger.Info("scheduled backup saved", "name", name)
+
+	names, err := s.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+	for _, expired := range backup.Expired(names, s.retention) {
+		if err := s.store.Delete(ctx, expired); err != nil {
+			s.logger.Error("failed to delete expired backup", "name", expired, "error", err)
+			continue
+		}
+		s.logger.Info("expired backup deleted", "name", expired)
+	}
+	return nil
+}
+
+// snapshot saves the current database to the backup store.
+func (s *BackupService) snapshot(ctx context.Context, reason backup.Reason) (string, error) {
+	name := backup.FileName(s.now(), reason)
+	err := s.store.Save(ctx, name, func(w io.Writer) error {
+		_, err := s.db.WriteBackup(ctx, w)
+		return err
+	})
+	return name, err
+}
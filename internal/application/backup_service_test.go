@@ -0,0 +1,195 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/access"
+	"github.com/alorle/iptv-manager/internal/backup"
+)
+
+// failingAccessRuleRepository fails to list rules.
+type failingAccessRuleRepository struct {
+	*memAccessRuleRepository
+}
+
+func (failingAccessRuleRepository) FindAll(ctx context.Context) ([]access.Rule, error) {
+	return nil, errors.New("db error")
+}
+
+// mockDatabaseBackup holds the database as an in-memory byte slice.
+type mockDatabaseBackup struct {
+	data []byte
+}
+
+func (m *mockDatabaseBackup) WriteBackup(ctx context.Context, w io.Writer) (int64, error) {
+	n, err := w.Write(m.data)
+	return int64(n), err
+}
+
+func (m *mockDatabaseBackup) Restore(ctx context.Context, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(data, []byte("db:")) {
+		return backup.ErrInvalidBackup
+	}
+	m.data = data
+	return nil
+}
+
+// mockBackupStore is an in-memory driven.BackupStore.
+type mockBackupStore struct {
+	files map[string][]byte
+}
+
+func (m *mockBackupStore) Save(ctx context.Context, name string, write func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+	m.files[name] = buf.Bytes()
+	return nil
+}
+
+func (m *mockBackupStore) List(ctx context.Context) ([]string, error) {
+	var names []string
+	for name := range m.files {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (m *mockBackupStore) Delete(ctx context.Context, name string) error {
+	delete(m.files, name)
+	return nil
+}
+
+func newTestBackupService(retention int) (*BackupService, *mockDatabaseBackup, *mockBackupStore, *time.Time) {
+	db := &mockDatabaseBackup{data: []byte("db:current")}
+	store := &mockBackupStore{files: make(map[string][]byte)}
+	service := NewBackupService(db, store, retention, newTestLogger())
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, db, store, &now
+}
+
+func TestBackupService_Restore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("saves a pre-restore snapshot and swaps in the backup", func(t *testing.T) {
+		service, db, store, now := newTestBackupService(0)
+
+		result, err := service.Restore(ctx, strings.NewReader("db:restored"))
+		if err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		name := result.PreRestoreBackup
+
+		if name != backup.FileName(*now, backup.ReasonPreRestore) {
+			t.Errorf("expected pre-restore snapshot name, got %q", name)
+		}
+		if got := string(store.files[name]); got != "db:current" {
+			t.Errorf("expected pre-restore snapshot of the old database, got %q", got)
+		}
+		if got := string(db.data); got != "db:restored" {
+			t.Errorf("expected restored database, got %q", got)
+		}
+	})
+
+	t.Run("reloads the access rules and announces the restore", func(t *testing.T) {
+		service, _, _, _ := newTestBackupService(0)
+		rules := &memAccessRuleRepository{rules: make(map[string]access.Rule)}
+		accessService := NewAccessService(rules)
+		if err := accessService.Load(ctx); err != nil {
+			t.Fatal(err)
+		}
+		service.SetAccessService(accessService)
+		bus := NewEventBus()
+		events, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+		service.SetEventBus(bus)
+
+		// The restored database denies what the loaded rules let in
+		deny, err := access.NewRule("203.0.113.0/24", "", access.ActionDeny, "", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules.rules[deny.ID()] = deny
+		if !accessService.Check("203.0.113.7").Allowed {
+			t.Fatal("expected the client to be allowed before the restore")
+		}
+
+		result, err := service.Restore(ctx, strings.NewReader("db:restored"))
+		if err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		if result.RestartRequired {
+			t.Error("expected no restart to be required")
+		}
+		if accessService.Check("203.0.113.7").Allowed {
+			t.Error("expected the restored access rules to apply")
+		}
+		select {
+		case e := <-events:
+			if e.Type != EventDatabaseRestored {
+				t.Errorf("expected a database.restored event, got %s", e.Type)
+			}
+		default:
+			t.Error("expected an event to be published")
+		}
+	})
+
+	t.Run("reports a restart is required when reloading fails", func(t *testing.T) {
+		service, _, _, _ := newTestBackupService(0)
+		service.SetAccessService(NewAccessService(failingAccessRuleRepository{&memAccessRuleRepository{}}))
+
+		result, err := service.Restore(ctx, strings.NewReader("db:restored"))
+		if err != nil {
+			t.Fatalf("Restore() error = %v", err)
+		}
+		if !result.RestartRequired {
+			t.Error("expected a restart to be required")
+		}
+	})
+
+	t.Run("leaves the database untouched for an invalid backup", func(t *testing.T) {
+		service, db, _, _ := newTestBackupService(0)
+
+		_, err := service.Restore(ctx, strings.NewReader("garbage"))
+		if !errors.Is(err, backup.ErrInvalidBackup) {
+			t.Errorf("expected ErrInvalidBackup, got %v", err)
+		}
+		if got := string(db.data); got != "db:current" {
+			t.Errorf("expected database to be unchanged, got %q", got)
+		}
+	})
+}
+
+func TestBackupService_RunScheduledBackup(t *testing.T) {
+	ctx := context.Background()
+	service, _, store, now := newTestBackupService(2)
+
+	var taken []string
+	for i := 0; i < 3; i++ {
+		if err := service.RunScheduledBackup(ctx); err != nil {
+			t.Fatalf("RunScheduledBackup() error = %v", err)
+		}
+		taken = append(taken, backup.FileName(*now, backup.ReasonScheduled))
+		*now = now.Add(time.Hour)
+	}
+
+	names, _ := store.List(ctx)
+	slices.Sort(names)
+	if !slices.Equal(names, taken[1:]) {
+		t.Errorf("expected the 2 newest backups to be kept, got %v", names)
+	}
+}
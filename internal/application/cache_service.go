@@ -0,0 +1,118 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// ErrCacheEntryNotFound indicates there is no cache entry with the given key.
+var ErrCacheEntryNotFound = errors.New("cache entry not found")
+
+// CacheEntry is a cached upstream file.
+type CacheEntry struct {
+	Key       string
+	URL       string
+	Size      int64
+	FetchedAt time.Time
+	Age       time.Duration
+	// Stale is set once the entry is older than the refresh interval, which
+	// means the last refreshes failed to reach the upstream.
+	Stale bool
+}
+
+// CacheRefreshResult reports the outcome of pre-warming a single source.
+type CacheRefreshResult struct {
+	Source string
+	Err    error
+}
+
+// cacheSourceEPG names the EPG guide in refresh results.
+const cacheSourceEPG = "epg"
+
+// CacheService provides use cases for inspecting the cache of upstream
+// sources, invalidating poisoned entries and pre-warming it.
+type CacheService struct {
+	cache      driven.SourceCache
+	epg        driven.EPGFetcher
+	sources    driven.AcestreamSource
+	staleAfter time.Duration
+	now        func() time.Time
+}
+
+// NewCacheService creates a new CacheService over the given cache. Fetching
+// the EPG guide and the Acestream sources fills the cache; entries older
+// than staleAfter are reported as stale.
+func NewCacheService(cache driven.SourceCache, epg driven.EPGFetcher, sources driven.AcestreamSource, staleAfter time.Duration) *CacheService {
+	return &CacheService{
+		cache:      cache,
+		epg:        epg,
+		sources:    sources,
+		staleAfter: staleAfter,
+		now:        time.Now,
+	}
+}
+
+// Entries returns the cached entries with their age.
+func (s *CacheService) Entries(ctx context.Context) ([]CacheEntry, error) {
+	cached, err := s.cache.Entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	entries := make([]CacheEntry, len(cached))
+	for i, c := range cached {
+		age := now.Sub(c.FetchedAt)
+		entries[i] = CacheEntry{
+			Key:       c.Key,
+			URL:       c.URL,
+			Size:      c.Size,
+			FetchedAt: c.FetchedAt,
+			Age:       age,
+			Stale:     s.staleAfter > 0 && age > s.staleAfter,
+		}
+	}
+	return entries, nil
+}
+
+// Invalidate removes the entry with the given key, so that the next fetch
+// downloads the file again.
+// Returns ErrCacheEntryNotFound if there is no such entry.
+func (s *CacheService) Invalidate(ctx context.Context, key string) error {
+	found, err := s.cache.Invalidate(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrCacheEntryNotFound
+	}
+	return nil
+}
+
+// Refresh fetches the EPG guide and every Acestream source concurrently,
+// refreshing their cache entries. Results are returned in a fixed order,
+// the guide first; a source that fails does not stop the others.
+func (s *CacheService) Refresh(ctx context.Context) []CacheRefreshResult {
+	sources := []string{stream.SourceNewEra, stream.SourceElcano}
+	results := make([]CacheRefreshResult, len(sources)+1)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := s.epg.FetchEPG(ctx)
+		results[0] = CacheRefreshResult{Source: cacheSourceEPG, Err: err}
+	}()
+	_, fetched, _ := FetchAndMerge(ctx, s.sources, sources)
+	wg.Wait()
+
+	for i, r := range fetched {
+		results[i+1] = CacheRefreshResult{Source: r.Source, Err: r.Err}
+	}
+	return results
+}
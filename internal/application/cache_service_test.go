@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// memSourceCache is an in-memory driven.SourceCache for testing.
+type memSourceCache struct {
+	entries []driven.CachedSource
+}
+
+func (c *memSourceCache) Entries(ctx context.Context) ([]driven.CachedSource, error) {
+	return c.entries, nil
+}
+
+func (c *memSourceCache) Invalidate(ctx context.Context, key string) (bool, error) {
+	for i, e := range c.entries {
+		if e.Key == key {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestCacheService_Entries(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache := &memSourceCache{entries: []driven.CachedSource{
+		{Key: "fresh", URL: "https://example.com/guide.xml", Size: 10, FetchedAt: now.Add(-time.Hour)},
+		{Key: "stale", URL: "https://example.com/hashes.json", Size: 20, FetchedAt: now.Add(-7 * time.Hour)},
+	}}
+	service := NewCacheService(cache, &mockEPGFetcher{}, &mockAcestreamSource{}, 6*time.Hour)
+	service.now = func() time.Time { return now }
+
+	entries, err := service.Entries(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Age != time.Hour || entries[0].Stale {
+		t.Errorf("expected a fresh entry an hour old, got %+v", entries[0])
+	}
+	if !entries[1].Stale {
+		t.Errorf("expected an entry older than the refresh interval to be stale, got %+v", entries[1])
+	}
+}
+
+func TestCacheService_Invalidate(t *testing.T) {
+	cache := &memSourceCache{entries: []driven.CachedSource{{Key: "guide"}}}
+	service := NewCacheService(cache, &mockEPGFetcher{}, &mockAcestreamSource{}, 6*time.Hour)
+
+	if err := service.Invalidate(context.Background(), "guide"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.Invalidate(context.Background(), "guide"); !errors.Is(err, ErrCacheEntryNotFound) {
+		t.Errorf("expected ErrCacheEntryNotFound, got %v", err)
+	}
+}
+
+func TestCacheService_Refresh(t *testing.T) {
+	fetchErr := errors.New("upstream down")
+	service := NewCacheService(&memSourceCache{}, &mockEPGFetcher{err: fetchErr}, &mockAcestreamSource{}, 6*time.Hour)
+
+	results := service.Refresh(context.Background())
+	if len(results) != 3 {
+		t.Fatalf("expected the guide and 2 sources, got %+v", results)
+	}
+	if results[0].Source != "epg" || !errors.Is(results[0].Err, fetchErr) {
+		t.Errorf("expected the guide to fail, got %+v", results[0])
+	}
+	if results[1].Source != stream.SourceNewEra || results[1].Err != nil || results[2].Source != stream.SourceElcano || results[2].Err != nil {
+		t.Errorf("expected the sources to be refreshed, got %+v", results[1:])
+	}
+}
@@ -1,18 +1,44 @@
 package application
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/alorle/iptv-manager/internal/channel"
 	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/stream"
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
 )
 
+// ErrMergeSameChannel indicates a merge whose source and target are the same channel.
+var ErrMergeSameChannel = errors.New("cannot merge a channel into itself")
+
+// DuplicateChannels is a pair of channels whose names are similar enough
+// that they are likely the same channel.
+type DuplicateChannels struct {
+	Names [2]string
+	Score float64
+}
+
+// MediaAnalyzer reports the codecs and resolution of a stream.
+type MediaAnalyzer interface {
+	Analyze(ctx context.Context, infoHash string) (mpegts.MediaInfo, error)
+}
+
 // ChannelService provides use cases for channel management.
 // It depends only on domain packages and port interfaces.
 type ChannelService struct {
 	channelRepo driven.ChannelRepository
 	streamRepo  driven.StreamRepository
+	groupRepo   driven.GroupRepository
+	events      *EventBus
+	analyzer    MediaAnalyzer
 }
 
 // NewChannelService creates a new ChannelService with the given repositories.
@@ -23,6 +49,33 @@ func NewChannelService(channelRepo driven.ChannelRepository, streamRepo driven.S
 	}
 }
 
+// SetEventBus enables publishing EventOverrideUpdated when a channel's
+// settings are changed.
+func (s *ChannelService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// SetGroupRepository enables checking that the group given to
+// BulkUpdateChannels exists.
+func (s *ChannelService) SetGroupRepository(groupRepo driven.GroupRepository) {
+	s.groupRepo = groupRepo
+}
+
+// SetMediaAnalyzer enables DetectQualities, which labels the streams of a
+// channel by the resolution the analyzer finds.
+func (s *ChannelService) SetMediaAnalyzer(analyzer MediaAnalyzer) {
+	s.analyzer = analyzer
+}
+
+// ChannelUpdate holds the channel settings to change; nil fields are left as is.
+type ChannelUpdate struct {
+	TranscodeAudio *string
+	TranscodeVideo *string
+	// Group is the ID of the group to move the channels into; empty
+	// removes them from their group.
+	Group *string
+}
+
 // CreateChannel creates a new channel with the given name.
 // Returns channel.ErrEmptyName if the name is invalid.
 // Returns channel.ErrChannelAlreadyExists if a channel with the same name already exists.
@@ -50,6 +103,25 @@ func (s *ChannelService) ListChannels(ctx context.Context) ([]channel.Channel, e
 	return s.channelRepo.FindAll(ctx)
 }
 
+// channelSortFields are the fields channel listings can be sorted by.
+var channelSortFields = map[string]func(a, b channel.Channel) int{
+	"name":   func(a, b channel.Channel) int { return strings.Compare(a.Name(), b.Name()) },
+	"number": func(a, b channel.Channel) int { return cmp.Compare(a.Number(), b.Number()) },
+	"group":  func(a, b channel.Channel) int { return strings.Compare(a.Group(), b.Group()) },
+	"status": func(a, b channel.Channel) int { return strings.Compare(string(a.Status()), string(b.Status())) },
+}
+
+// ListChannelsPage retrieves one page of the channels, sorted by name,
+// number, group or status.
+// Returns ErrInvalidSortField or ErrInvalidPagination for invalid options.
+func (s *ChannelService) ListChannelsPage(ctx context.Context, opts ListOptions) (ListPage[channel.Channel], error) {
+	channels, err := s.channelRepo.FindAll(ctx)
+	if err != nil {
+		return ListPage[channel.Channel]{}, err
+	}
+	return listItems(channels, opts, channelSortFields)
+}
+
 // DeleteChannel removes a channel and all its associated streams (cascade delete).
 // Returns channel.ErrChannelNotFound if the channel does not exist.
 // If the channel exists but stream deletion fails, the error is returned and the channel is not deleted.
@@ -90,5 +162,544 @@ func (s *ChannelService) UpdateEPGMapping(ctx context.Context, channelName strin
 		ch.SetEPGMapping(mapping)
 	}
 
-	return s.channelRepo.Update(ctx, ch)
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+	return nil
+}
+
+// UpdateAudioTranscode changes how a channel's audio is re-encoded when it is
+// streamed. An empty value disables transcoding.
+// Returns channel.ErrInvalidAudioTranscode if the value is not recognised.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) UpdateAudioTranscode(ctx context.Context, channelName string, transcode string) (channel.Channel, error) {
+	t, err := channel.ParseAudioTranscode(transcode)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	ch.SetAudioTranscode(t)
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// UpdateVideoTranscode changes the resolution a channel's video is
+// downscaled to when it is streamed. An empty value disables downscaling.
+// Returns channel.ErrInvalidVideoTranscode if the value is not recognised.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) UpdateVideoTranscode(ctx context.Context, channelName string, transcode string) (channel.Channel, error) {
+	t, err := channel.ParseVideoTranscode(transcode)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	ch.SetVideoTranscode(t)
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// UpdateNumber assigns the number players list a channel under. Zero
+// removes the assignment.
+// Returns channel.ErrInvalidNumber if the number is negative.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) UpdateNumber(ctx context.Context, channelName string, number int) (channel.Channel, error) {
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	if err := ch.SetNumber(number); err != nil {
+		return channel.Channel{}, err
+	}
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// UpdateTVGShift sets how far the guide times of a channel are moved, for a
+// channel broadcast in another timezone than its guide. Zero removes the
+// shift.
+// Returns channel.ErrInvalidTVGShift if the shift is not a multiple of 15
+// minutes or exceeds channel.MaxTVGShift either way.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) UpdateTVGShift(ctx context.Context, channelName string, shift time.Duration) (channel.Channel, error) {
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	if err := ch.SetTVGShift(shift); err != nil {
+		return channel.Channel{}, err
+	}
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// UpdateAliases replaces the aliases a channel can be streamed by under
+// /ace/c/{alias}. An empty list removes them.
+// Returns channel.ErrInvalidAlias if an alias is not a valid slug.
+// Returns channel.ErrAliasInUse if another channel already has one of them.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) UpdateAliases(ctx context.Context, channelName string, aliases []string) (channel.Channel, error) {
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	if err := ch.SetAliases(aliases); err != nil {
+		return channel.Channel{}, err
+	}
+
+	channels, err := s.channelRepo.FindAll(ctx)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	for _, other := range channels {
+		if other.Name() == ch.Name() {
+			continue
+		}
+		for _, alias := range other.Aliases() {
+			if slices.Contains(ch.Aliases(), alias) {
+				return channel.Channel{}, fmt.Errorf("%w: %q is used by %s", channel.ErrAliasInUse, alias, other.Name())
+			}
+		}
+	}
+
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// UpdateTags replaces the free-form tags of a channel, such as "kids" or
+// "4k". Tags are stored lowercased; an empty list removes them.
+// Returns channel.ErrInvalidTag if a tag is malformed.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) UpdateTags(ctx context.Context, channelName string, tags []string) (channel.Channel, error) {
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	if err := ch.SetTags(tags); err != nil {
+		return channel.Channel{}, err
+	}
+
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// UpdateStreamQualities labels streams of a channel with their quality, by
+// infohash. An empty label removes a stream's label; streams not given keep
+// theirs.
+// Returns channel.ErrInvalidQuality if a label is not recognised.
+// Returns stream.ErrInvalidInfoHash if an infohash is malformed.
+// Returns stream.ErrStreamNotFound if a stream does not belong to the channel.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) UpdateStreamQualities(ctx context.Context, channelName string, labels map[string]string) (channel.Channel, error) {
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	streams, err := s.streamRepo.FindByChannelName(ctx, ch.Name())
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	for hash, label := range labels {
+		infoHash, err := stream.ParseInfoHash(hash)
+		if err != nil {
+			return channel.Channel{}, err
+		}
+		if !slices.ContainsFunc(streams, func(st stream.Stream) bool { return st.InfoHash() == infoHash.String() }) {
+			return channel.Channel{}, fmt.Errorf("%w: %s is not a stream of %s", stream.ErrStreamNotFound, infoHash, ch.Name())
+		}
+		var q channel.Quality
+		if label != "" {
+			if q, err = channel.ParseQuality(label); err != nil {
+				return channel.Channel{}, err
+			}
+		}
+		ch.SetStreamQuality(infoHash.String(), q)
+	}
+
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// UpdateQualityPreference sets the order the streams of a channel are
+// preferred in by quality label. An empty list restores the default of
+// highest resolution first.
+// Returns channel.ErrInvalidQuality if a label is not recognised.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) UpdateQualityPreference(ctx context.Context, channelName string, labels []string) (channel.Channel, error) {
+	preference := make([]channel.Quality, 0, len(labels))
+	for _, label := range labels {
+		q, err := channel.ParseQuality(label)
+		if err != nil {
+			return channel.Channel{}, err
+		}
+		preference = append(preference, q)
+	}
+
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	ch.SetQualityPreference(preference)
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// UpdateVariants sets whether playlists list every stream of a channel,
+// labelled ones suffixed with their quality, or only the preferred one.
+// Returns channel.ErrInvalidVariants if the mode is not recognised.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) UpdateVariants(ctx context.Context, channelName string, variants string) (channel.Channel, error) {
+	v, err := channel.ParseVariants(variants)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	ch.SetVariants(v)
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// DetectQualities analyzes every stream of a channel and labels it with the
+// quality of its video resolution. Streams that cannot be analyzed, for
+// instance because they are offline, keep their label.
+// Returns ErrMediaInfoUnavailable if no media analyzer is set.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *ChannelService) DetectQualities(ctx context.Context, channelName string) (channel.Channel, error) {
+	if s.analyzer == nil {
+		return channel.Channel{}, ErrMediaInfoUnavailable
+	}
+
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	streams, err := s.streamRepo.FindByChannelName(ctx, ch.Name())
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	detected := make(map[string]channel.Quality)
+	for _, st := range streams {
+		info, err := s.analyzer.Analyze(ctx, st.InfoHash())
+		if err != nil || info.Video == nil {
+			continue
+		}
+		if q := channel.QualityForHeight(info.Video.Height); q != "" {
+			detected[st.InfoHash()] = q
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return channel.Channel{}, err
+	}
+
+	// The channel may have been changed while its streams were analyzed
+	ch, err = s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	for infoHash, q := range detected {
+		ch.SetStreamQuality(infoHash, q)
+	}
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+
+	return ch, nil
+}
+
+// ResolveAlias returns the channel with the given alias, matched case
+// insensitively.
+// Returns channel.ErrChannelNotFound if no channel has it.
+func (s *ChannelService) ResolveAlias(ctx context.Context, alias string) (channel.Channel, error) {
+	alias, err := channel.ParseAlias(alias)
+	if err != nil {
+		return channel.Channel{}, channel.ErrChannelNotFound
+	}
+
+	channels, err := s.channelRepo.FindAll(ctx)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	for _, ch := range channels {
+		if slices.Contains(ch.Aliases(), alias) {
+			return ch, nil
+		}
+	}
+	return channel.Channel{}, channel.ErrChannelNotFound
+}
+
+// BulkUpdateChannels applies the same update to every channel with the given
+// names and returns the updated channels in the order given. Duplicate names
+// are updated once.
+// Returns channel.ErrInvalidAudioTranscode or channel.ErrInvalidVideoTranscode
+// if a transcode value is not recognised.
+// Returns channel.ErrChannelNotFound if any name does not exist, or
+// group.ErrGroupNotFound if the group does not exist; no channel is changed
+// in either case.
+func (s *ChannelService) BulkUpdateChannels(ctx context.Context, names []string, update ChannelUpdate) ([]channel.Channel, error) {
+	var transcode channel.AudioTranscode
+	if update.TranscodeAudio != nil {
+		t, err := channel.ParseAudioTranscode(*update.TranscodeAudio)
+		if err != nil {
+			return nil, err
+		}
+		transcode = t
+	}
+	var video channel.VideoTranscode
+	if update.TranscodeVideo != nil {
+		t, err := channel.ParseVideoTranscode(*update.TranscodeVideo)
+		if err != nil {
+			return nil, err
+		}
+		video = t
+	}
+	if update.Group != nil && *update.Group != "" && s.groupRepo != nil {
+		if _, err := s.groupRepo.FindByID(ctx, *update.Group); err != nil {
+			return nil, err
+		}
+	}
+
+	channels := make([]channel.Channel, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		ch, err := s.channelRepo.FindByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, ch)
+	}
+
+	for i := range channels {
+		if update.TranscodeAudio != nil {
+			channels[i].SetAudioTranscode(transcode)
+		}
+		if update.TranscodeVideo != nil {
+			channels[i].SetVideoTranscode(video)
+		}
+		if update.Group != nil {
+			channels[i].SetGroup(*update.Group)
+		}
+		if err := s.channelRepo.Update(ctx, channels[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(channels) > 0 {
+		s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel"})
+	}
+	return channels, nil
+}
+
+// ReorderChannels numbers the channels with the given names from one, in
+// that order. Channels that already had a number follow in their current
+// order; unnumbered channels stay unnumbered. The result lists all channels
+// in playlist order (see channel.CompareOrder).
+// Returns channel.ErrChannelNotFound if any name does not exist; no channel
+// is changed in that case.
+func (s *ChannelService) ReorderChannels(ctx context.Context, names []string) ([]channel.Channel, error) {
+	channels, err := s.channelRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(channels, channel.CompareOrder)
+
+	byName := make(map[string]int, len(channels))
+	for i, ch := range channels {
+		byName[ch.Name()] = i
+	}
+
+	ordered := make([]int, 0, len(channels))
+	placed := make(map[string]bool, len(names))
+	for _, name := range names {
+		i, ok := byName[name]
+		if !ok {
+			return nil, channel.ErrChannelNotFound
+		}
+		if placed[name] {
+			continue
+		}
+		placed[name] = true
+		ordered = append(ordered, i)
+	}
+	for i, ch := range channels {
+		if !placed[ch.Name()] && ch.Number() != 0 {
+			ordered = append(ordered, i)
+		}
+	}
+
+	for n, i := range ordered {
+		if channels[i].Number() == n+1 {
+			continue
+		}
+		_ = channels[i].SetNumber(n + 1)
+		if err := s.channelRepo.Update(ctx, channels[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortFunc(channels, channel.CompareOrder)
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel"})
+	return channels, nil
+}
+
+// MergeChannels merges the source channel into the target: the source's
+// streams are moved to the target, settings the target lacks (including a
+// better EPG mapping, see channel.Channel.Absorb) are taken from the source,
+// and the source is deleted.
+// Returns ErrMergeSameChannel if source and target are the same channel.
+// Returns channel.ErrChannelNotFound if either channel does not exist.
+func (s *ChannelService) MergeChannels(ctx context.Context, sourceName, targetName string) (channel.Channel, error) {
+	if sourceName == targetName {
+		return channel.Channel{}, ErrMergeSameChannel
+	}
+
+	source, err := s.channelRepo.FindByName(ctx, sourceName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	target, err := s.channelRepo.FindByName(ctx, targetName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	streams, err := s.streamRepo.FindByChannelName(ctx, sourceName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	for _, st := range streams {
+		moved, err := stream.NewStream(st.InfoHash(), targetName, st.Source())
+		if err != nil {
+			return channel.Channel{}, err
+		}
+		if err := s.streamRepo.Delete(ctx, st.InfoHash()); err != nil {
+			return channel.Channel{}, err
+		}
+		if err := s.streamRepo.Save(ctx, moved); err != nil {
+			return channel.Channel{}, err
+		}
+	}
+
+	target.Absorb(source)
+	if err := s.channelRepo.Update(ctx, target); err != nil {
+		return channel.Channel{}, err
+	}
+	if err := s.channelRepo.Delete(ctx, sourceName); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: sourceName})
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: targetName})
+	return target, nil
+}
+
+// FindDuplicates suggests pairs of channels that are likely duplicates,
+// comparing names with channel.FuzzyMatch. Pairs scoring at least minScore
+// are returned, best matches first.
+func (s *ChannelService) FindDuplicates(ctx context.Context, minScore float64) ([]DuplicateChannels, error) {
+	channels, err := s.channelRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(channels))
+	normalized := make([]string, len(channels))
+	for i, ch := range channels {
+		names[i] = ch.Name()
+		normalized[i] = channel.NormalizeName(ch.Name())
+	}
+
+	duplicates := []DuplicateChannels{}
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			score := channel.FuzzyMatchNormalized(normalized[i], normalized[j])
+			if score < minScore || score == 0 {
+				continue
+			}
+			pair := [2]string{names[i], names[j]}
+			if pair[1] < pair[0] {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			duplicates = append(duplicates, DuplicateChannels{Names: pair, Score: score})
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Score != duplicates[j].Score {
+			return duplicates[i].Score > duplicates[j].Score
+		}
+		return duplicates[i].Names[0] < duplicates[j].Names[0] ||
+			(duplicates[i].Names[0] == duplicates[j].Names[0] && duplicates[i].Names[1] < duplicates[j].Names[1])
+	})
+	return duplicates, nil
 }
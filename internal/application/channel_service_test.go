@@ -3,10 +3,15 @@ package application
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
 	"github.com/alorle/iptv-manager/internal/stream"
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
 )
 
 // mockChannelRepository is a mock implementation of driven.ChannelRepository for testing.
@@ -47,6 +52,21 @@ func (m *mockChannelRepository) FindAll(ctx context.Context) ([]channel.Channel,
 	return []channel.Channel{}, nil
 }
 
+// FindByTag filters the channels returned by FindAll.
+func (m *mockChannelRepository) FindByTag(ctx context.Context, tag string) ([]channel.Channel, error) {
+	all, err := m.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tagged := []channel.Channel{}
+	for _, ch := range all {
+		if ch.HasTag(tag) {
+			tagged = append(tagged, ch)
+		}
+	}
+	return tagged, nil
+}
+
 func (m *mockChannelRepository) Delete(ctx context.Context, name string) error {
 	if m.deleteFunc != nil {
 		return m.deleteFunc(ctx, name)
@@ -64,6 +84,7 @@ func (m *mockChannelRepository) Ping(ctx context.Context) error {
 // mockStreamRepository is a mock implementation of driven.StreamRepository for testing.
 type mockStreamRepository struct {
 	saveFunc                func(ctx context.Context, s stream.Stream) error
+	updateFunc              func(ctx context.Context, s stream.Stream) error
 	findByInfoHashFunc      func(ctx context.Context, infoHash string) (stream.Stream, error)
 	findAllFunc             func(ctx context.Context) ([]stream.Stream, error)
 	findByChannelNameFunc   func(ctx context.Context, channelName string) ([]stream.Stream, error)
@@ -78,6 +99,13 @@ func (m *mockStreamRepository) Save(ctx context.Context, s stream.Stream) error
 	return nil
 }
 
+func (m *mockStreamRepository) Update(ctx context.Context, s stream.Stream) error {
+	if m.updateFunc != nil {
+		return m.updateFunc(ctx, s)
+	}
+	return nil
+}
+
 func (m *mockStreamRepository) FindByInfoHash(ctx context.Context, infoHash string) (stream.Stream, error) {
 	if m.findByInfoHashFunc != nil {
 		return m.findByInfoHashFunc(ctx, infoHash)
@@ -335,3 +363,485 @@ func TestChannelService_DeleteChannel(t *testing.T) {
 		}
 	})
 }
+
+func TestChannelService_UpdateAudioTranscode(t *testing.T) {
+	t.Run("stores the transcode setting", func(t *testing.T) {
+		var updated channel.Channel
+		ch, _ := channel.NewChannel("TestChannel")
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				return ch, nil
+			},
+			updateFunc: func(ctx context.Context, ch channel.Channel) error {
+				updated = ch
+				return nil
+			},
+		}
+		service := NewChannelService(channelRepo, &mockStreamRepository{})
+
+		got, err := service.UpdateAudioTranscode(context.Background(), "TestChannel", "mp3")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got.AudioTranscode() != channel.AudioTranscodeMP3 {
+			t.Errorf("expected returned transcode 'mp3', got %q", got.AudioTranscode())
+		}
+		if updated.AudioTranscode() != channel.AudioTranscodeMP3 {
+			t.Errorf("expected stored transcode 'mp3', got %q", updated.AudioTranscode())
+		}
+	})
+
+	t.Run("rejects unknown transcode values", func(t *testing.T) {
+		channelRepo := &mockChannelRepository{
+			updateFunc: func(ctx context.Context, ch channel.Channel) error {
+				t.Error("update should not be called for an invalid value")
+				return nil
+			},
+		}
+		service := NewChannelService(channelRepo, &mockStreamRepository{})
+
+		_, err := service.UpdateAudioTranscode(context.Background(), "TestChannel", "flac")
+		if !errors.Is(err, channel.ErrInvalidAudioTranscode) {
+			t.Errorf("expected ErrInvalidAudioTranscode, got %v", err)
+		}
+	})
+
+	t.Run("returns error if channel not found", func(t *testing.T) {
+		service := NewChannelService(&mockChannelRepository{}, &mockStreamRepository{})
+
+		_, err := service.UpdateAudioTranscode(context.Background(), "NonExistent", "all")
+		if !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+	})
+}
+
+func TestChannelService_UpdateAudioTranscode_PublishesOverride(t *testing.T) {
+	ch, _ := channel.NewChannel("HBO")
+	channelRepo := &mockChannelRepository{
+		findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+			return ch, nil
+		},
+	}
+	bus := NewEventBus()
+	events, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	service := NewChannelService(channelRepo, &mockStreamRepository{})
+	service.SetEventBus(bus)
+
+	if _, err := service.UpdateAudioTranscode(context.Background(), "HBO", "mp3"); err != nil {
+		t.Fatalf("UpdateAudioTranscode() error = %v", err)
+	}
+
+	event := <-events
+	data, ok := event.Data.(OverrideEventData)
+	if event.Type != EventOverrideUpdated || !ok || data.Kind != "channel" || data.Name != "HBO" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestChannelService_UpdateNumber(t *testing.T) {
+	ctx := context.Background()
+	ch, _ := channel.NewChannel("HBO")
+	repo, channels := newMemChannelRepository(ch)
+	service := NewChannelService(repo, &mockStreamRepository{})
+
+	updated, err := service.UpdateNumber(ctx, "HBO", 7)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated.Number() != 7 || channels["HBO"].Number() != 7 {
+		t.Errorf("expected stored number 7, got %d", channels["HBO"].Number())
+	}
+
+	if _, err := service.UpdateNumber(ctx, "HBO", -1); !errors.Is(err, channel.ErrInvalidNumber) {
+		t.Errorf("expected ErrInvalidNumber, got %v", err)
+	}
+	if _, err := service.UpdateNumber(ctx, "Missing", 1); !errors.Is(err, channel.ErrChannelNotFound) {
+		t.Errorf("expected ErrChannelNotFound, got %v", err)
+	}
+}
+
+func TestChannelService_UpdateTVGShift(t *testing.T) {
+	ctx := context.Background()
+	ch, _ := channel.NewChannel("La 1 Canarias")
+	repo, channels := newMemChannelRepository(ch)
+	service := NewChannelService(repo, &mockStreamRepository{})
+
+	updated, err := service.UpdateTVGShift(ctx, "La 1 Canarias", -time.Hour)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated.TVGShift() != -time.Hour || channels["La 1 Canarias"].TVGShift() != -time.Hour {
+		t.Errorf("expected stored tvg-shift -1h, got %v", channels["La 1 Canarias"].TVGShift())
+	}
+
+	if _, err := service.UpdateTVGShift(ctx, "La 1 Canarias", time.Minute); !errors.Is(err, channel.ErrInvalidTVGShift) {
+		t.Errorf("expected ErrInvalidTVGShift, got %v", err)
+	}
+	if _, err := service.UpdateTVGShift(ctx, "Missing", time.Hour); !errors.Is(err, channel.ErrChannelNotFound) {
+		t.Errorf("expected ErrChannelNotFound, got %v", err)
+	}
+}
+
+func TestChannelService_UpdateAliases(t *testing.T) {
+	ctx := context.Background()
+	la1, _ := channel.NewChannel("La 1")
+	la2, _ := channel.NewChannel("La 2")
+	_ = la2.SetAliases([]string{"la2"})
+	repo, channels := newMemChannelRepository(la1, la2)
+	service := NewChannelService(repo, &mockStreamRepository{})
+
+	updated, err := service.UpdateAliases(ctx, "La 1", []string{"LA1", "tve1"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := channels["La 1"].Aliases(); len(got) != 2 || got[0] != "la1" || len(updated.Aliases()) != 2 {
+		t.Errorf("expected stored aliases [la1 tve1], got %v", got)
+	}
+
+	if _, err := service.UpdateAliases(ctx, "La 1", []string{"la2"}); !errors.Is(err, channel.ErrAliasInUse) {
+		t.Errorf("expected ErrAliasInUse, got %v", err)
+	}
+	if _, err := service.UpdateAliases(ctx, "La 1", []string{"la 1"}); !errors.Is(err, channel.ErrInvalidAlias) {
+		t.Errorf("expected ErrInvalidAlias, got %v", err)
+	}
+	if _, err := service.UpdateAliases(ctx, "Missing", []string{"x"}); !errors.Is(err, channel.ErrChannelNotFound) {
+		t.Errorf("expected ErrChannelNotFound, got %v", err)
+	}
+
+	resolved, err := service.ResolveAlias(ctx, "TVE1")
+	if err != nil || resolved.Name() != "La 1" {
+		t.Errorf("ResolveAlias() = %q, %v; want La 1", resolved.Name(), err)
+	}
+	for _, alias := range []string{"la3", "not a slug"} {
+		if _, err := service.ResolveAlias(ctx, alias); !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("ResolveAlias(%q): expected ErrChannelNotFound, got %v", alias, err)
+		}
+	}
+}
+
+func TestChannelService_UpdateTags(t *testing.T) {
+	ctx := context.Background()
+	la1, _ := channel.NewChannel("La 1")
+	repo, channels := newMemChannelRepository(la1)
+	service := NewChannelService(repo, &mockStreamRepository{})
+
+	if _, err := service.UpdateTags(ctx, "La 1", []string{"Spanish", "news"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := channels["La 1"].Tags(); !slices.Equal(got, []string{"news", "spanish"}) {
+		t.Errorf("expected stored tags [news spanish], got %v", got)
+	}
+
+	if _, err := service.UpdateTags(ctx, "La 1", []string{"no spaces"}); !errors.Is(err, channel.ErrInvalidTag) {
+		t.Errorf("expected ErrInvalidTag, got %v", err)
+	}
+	if got := channels["La 1"].Tags(); len(got) != 2 {
+		t.Errorf("expected a rejected update to keep the tags, got %v", got)
+	}
+	if _, err := service.UpdateTags(ctx, "Missing", nil); !errors.Is(err, channel.ErrChannelNotFound) {
+		t.Errorf("expected ErrChannelNotFound, got %v", err)
+	}
+}
+
+// mediaAnalyzerFunc adapts a function to MediaAnalyzer.
+type mediaAnalyzerFunc func(ctx context.Context, infoHash string) (mpegts.MediaInfo, error)
+
+func (f mediaAnalyzerFunc) Analyze(ctx context.Context, infoHash string) (mpegts.MediaInfo, error) {
+	return f(ctx, infoHash)
+}
+
+func TestChannelService_Qualities(t *testing.T) {
+	ctx := context.Background()
+	hd := strings.Repeat("a", 40)
+	sd := strings.Repeat("b", 40)
+	streamRepo := &mockStreamRepository{
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			s1, _ := stream.NewStream(hd, channelName, "")
+			s2, _ := stream.NewStream(sd, channelName, "")
+			return []stream.Stream{s1, s2}, nil
+		},
+	}
+
+	t.Run("labels streams and sets the preference", func(t *testing.T) {
+		la1, _ := channel.NewChannel("La 1")
+		repo, channels := newMemChannelRepository(la1)
+		service := NewChannelService(repo, streamRepo)
+
+		if _, err := service.UpdateStreamQualities(ctx, "La 1", map[string]string{strings.ToUpper(hd): "FHD", sd: "sd"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := channels["La 1"].StreamQualities(); got[hd] != channel.Quality1080p || got[sd] != channel.QualitySD {
+			t.Errorf("expected stored labels 1080p and SD, got %v", got)
+		}
+		if _, err := service.UpdateStreamQualities(ctx, "La 1", map[string]string{sd: ""}); err != nil || len(channels["La 1"].StreamQualities()) != 1 {
+			t.Errorf("expected the SD label removed, got %v, %v", channels["La 1"].StreamQualities(), err)
+		}
+
+		if _, err := service.UpdateQualityPreference(ctx, "La 1", []string{"sd", "1080p"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := channels["La 1"].QualityPreference(); len(got) != 2 || got[0] != channel.QualitySD {
+			t.Errorf("expected stored preference [SD 1080p], got %v", got)
+		}
+		if _, err := service.UpdateVariants(ctx, "La 1", "preferred"); err != nil || channels["La 1"].Variants() != channel.VariantsPreferred {
+			t.Errorf("expected stored variants preferred, got %q, %v", channels["La 1"].Variants(), err)
+		}
+	})
+
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		la1, _ := channel.NewChannel("La 1")
+		repo, _ := newMemChannelRepository(la1)
+		service := NewChannelService(repo, streamRepo)
+
+		if _, err := service.UpdateStreamQualities(ctx, "La 1", map[string]string{hd: "8k"}); !errors.Is(err, channel.ErrInvalidQuality) {
+			t.Errorf("expected ErrInvalidQuality, got %v", err)
+		}
+		if _, err := service.UpdateStreamQualities(ctx, "La 1", map[string]string{strings.Repeat("c", 40): "sd"}); !errors.Is(err, stream.ErrStreamNotFound) {
+			t.Errorf("expected ErrStreamNotFound for a stream of another channel, got %v", err)
+		}
+		if _, err := service.UpdateStreamQualities(ctx, "La 1", map[string]string{"abc": "sd"}); !errors.Is(err, stream.ErrInvalidInfoHash) {
+			t.Errorf("expected ErrInvalidInfoHash, got %v", err)
+		}
+		if _, err := service.UpdateQualityPreference(ctx, "La 1", []string{"best"}); !errors.Is(err, channel.ErrInvalidQuality) {
+			t.Errorf("expected ErrInvalidQuality, got %v", err)
+		}
+		if _, err := service.UpdateVariants(ctx, "La 1", "some"); !errors.Is(err, channel.ErrInvalidVariants) {
+			t.Errorf("expected ErrInvalidVariants, got %v", err)
+		}
+		if _, err := service.UpdateVariants(ctx, "Missing", ""); !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+	})
+
+	t.Run("detects qualities from the stream resolution", func(t *testing.T) {
+		la1, _ := channel.NewChannel("La 1")
+		la1.SetStreamQuality(sd, channel.Quality720p)
+		repo, channels := newMemChannelRepository(la1)
+		service := NewChannelService(repo, streamRepo)
+
+		if _, err := service.DetectQualities(ctx, "La 1"); !errors.Is(err, ErrMediaInfoUnavailable) {
+			t.Errorf("expected ErrMediaInfoUnavailable without an analyzer, got %v", err)
+		}
+
+		service.SetMediaAnalyzer(mediaAnalyzerFunc(func(ctx context.Context, infoHash string) (mpegts.MediaInfo, error) {
+			if infoHash == sd {
+				return mpegts.MediaInfo{}, ErrMediaInfoUnavailable
+			}
+			return mpegts.MediaInfo{Video: &mpegts.VideoInfo{Width: 1920, Height: 1080}}, nil
+		}))
+		updated, err := service.DetectQualities(ctx, "La 1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := channels["La 1"].StreamQualities(); got[hd] != channel.Quality1080p || got[sd] != channel.Quality720p {
+			t.Errorf("expected 1080p detected and the offline stream's label kept, got %v", got)
+		}
+		if updated.StreamQuality(hd) != channel.Quality1080p {
+			t.Errorf("expected the returned channel to carry the detected label, got %v", updated.StreamQualities())
+		}
+	})
+}
+
+func TestChannelService_ReorderChannels(t *testing.T) {
+	ctx := context.Background()
+	numbered := func(name string, number int) channel.Channel {
+		ch, _ := channel.NewChannel(name)
+		_ = ch.SetNumber(number)
+		return ch
+	}
+
+	t.Run("numbers given channels first and keeps numbered ones after them", func(t *testing.T) {
+		repo, channels := newMemChannelRepository(
+			numbered("A", 1),
+			numbered("B", 2),
+			numbered("C", 0),
+			numbered("D", 0),
+		)
+		service := NewChannelService(repo, &mockStreamRepository{})
+
+		ordered, err := service.ReorderChannels(ctx, []string{"C", "B"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		want := map[string]int{"C": 1, "B": 2, "A": 3, "D": 0}
+		for name, number := range want {
+			if got := channels[name].Number(); got != number {
+				t.Errorf("expected %s to be number %d, got %d", name, number, got)
+			}
+		}
+		var names []string
+		for _, ch := range ordered {
+			names = append(names, ch.Name())
+		}
+		if len(names) != 4 || names[0] != "C" || names[1] != "B" || names[2] != "A" || names[3] != "D" {
+			t.Errorf("expected order [C B A D], got %v", names)
+		}
+	})
+
+	t.Run("rejects unknown names without changing anything", func(t *testing.T) {
+		repo, channels := newMemChannelRepository(numbered("A", 0))
+		service := NewChannelService(repo, &mockStreamRepository{})
+
+		if _, err := service.ReorderChannels(ctx, []string{"A", "Missing"}); !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+		if channels["A"].Number() != 0 {
+			t.Errorf("expected A to stay unnumbered, got %d", channels["A"].Number())
+		}
+	})
+}
+
+func TestChannelService_MergeChannels(t *testing.T) {
+	t.Run("moves streams, combines settings and deletes the source", func(t *testing.T) {
+		mapping, _ := channel.NewEPGMapping("dazn1.es", channel.MappingManual, time.Now())
+		source := channel.ReconstructChannel("DAZN 1 HD", channel.StatusActive, &mapping)
+		target, _ := channel.NewChannel("DAZN 1")
+		channelRepo, channels := newMemChannelRepository(source, target)
+		channelRepo.deleteFunc = func(ctx context.Context, name string) error {
+			delete(channels, name)
+			return nil
+		}
+
+		s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "DAZN 1 HD", stream.SourceNewEra)
+		streams := map[string]stream.Stream{"6861736831000000000000000000000000000000": s1}
+		streamRepo := &mockStreamRepository{
+			findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+				var result []stream.Stream
+				for _, st := range streams {
+					if st.ChannelName() == channelName {
+						result = append(result, st)
+					}
+				}
+				return result, nil
+			},
+			deleteFunc: func(ctx context.Context, infoHash string) error {
+				delete(streams, infoHash)
+				return nil
+			},
+			saveFunc: func(ctx context.Context, st stream.Stream) error {
+				streams[st.InfoHash()] = st
+				return nil
+			},
+		}
+		service := NewChannelService(channelRepo, streamRepo)
+
+		merged, err := service.MergeChannels(context.Background(), "DAZN 1 HD", "DAZN 1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if merged.EPGMapping() == nil || merged.EPGMapping().EPGID() != "dazn1.es" {
+			t.Errorf("expected EPG mapping from source, got %v", merged.EPGMapping())
+		}
+		if st := streams["6861736831000000000000000000000000000000"]; st.ChannelName() != "DAZN 1" || st.Source() != stream.SourceNewEra {
+			t.Errorf("expected stream moved to target keeping its source, got %q/%q", st.ChannelName(), st.Source())
+		}
+		if _, ok := channels["DAZN 1 HD"]; ok {
+			t.Error("expected source channel to be deleted")
+		}
+		if channels["DAZN 1"].EPGMapping() == nil {
+			t.Error("expected target channel to be updated")
+		}
+	})
+
+	t.Run("rejects merging a channel into itself", func(t *testing.T) {
+		service := NewChannelService(&mockChannelRepository{}, &mockStreamRepository{})
+
+		if _, err := service.MergeChannels(context.Background(), "A", "A"); !errors.Is(err, ErrMergeSameChannel) {
+			t.Errorf("expected ErrMergeSameChannel, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrChannelNotFound for unknown channels", func(t *testing.T) {
+		target, _ := channel.NewChannel("Target")
+		channelRepo, _ := newMemChannelRepository(target)
+		service := NewChannelService(channelRepo, &mockStreamRepository{})
+
+		if _, err := service.MergeChannels(context.Background(), "Missing", "Target"); !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+	})
+}
+
+func TestChannelService_FindDuplicates(t *testing.T) {
+	var channels []channel.Channel
+	for _, name := range []string{"DAZN 1", "DAZN 1 HD", "Movistar Plus+", "M+ Deportes", "Movistar Plus"} {
+		ch, _ := channel.NewChannel(name)
+		channels = append(channels, ch)
+	}
+	channelRepo, _ := newMemChannelRepository(channels...)
+	service := NewChannelService(channelRepo, &mockStreamRepository{})
+
+	duplicates, err := service.FindDuplicates(context.Background(), 0.8)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []DuplicateChannels{
+		{Names: [2]string{"DAZN 1", "DAZN 1 HD"}, Score: 1},
+		{Names: [2]string{"Movistar Plus", "Movistar Plus+"}, Score: 1},
+	}
+	if len(duplicates) != len(want) {
+		t.Fatalf("expected %d duplicates, got %+v", len(want), duplicates)
+	}
+	for i := range want {
+		if duplicates[i] != want[i] {
+			t.Errorf("duplicate %d: expected %+v, got %+v", i, want[i], duplicates[i])
+		}
+	}
+}
+
+func TestChannelService_BulkUpdateChannels(t *testing.T) {
+	ctx := context.Background()
+	adult, _ := group.NewGroup("Adult")
+
+	newService := func() (*ChannelService, map[string]channel.Channel) {
+		a, _ := channel.NewChannel("A")
+		b, _ := channel.NewChannel("B")
+		c, _ := channel.NewChannel("C")
+		channelRepo, byName := newMemChannelRepository(a, b, c)
+		service := NewChannelService(channelRepo, &mockStreamRepository{})
+		service.SetGroupRepository(newMemGroupRepository(adult))
+		return service, byName
+	}
+
+	t.Run("applies the update to every channel", func(t *testing.T) {
+		service, byName := newService()
+		groupID, transcode := "adult", "all"
+
+		updated, err := service.BulkUpdateChannels(ctx, []string{"A", "B", "A"}, ChannelUpdate{Group: &groupID, TranscodeAudio: &transcode})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(updated) != 2 {
+			t.Fatalf("expected 2 updated channels, got %d", len(updated))
+		}
+		for _, name := range []string{"A", "B"} {
+			if ch := byName[name]; ch.Group() != "adult" || ch.AudioTranscode() != channel.AudioTranscodeAll {
+				t.Errorf("%s: expected group adult transcoding all audio, got %q %q", name, ch.Group(), ch.AudioTranscode())
+			}
+		}
+		if byName["C"].Group() != "" {
+			t.Error("expected C to be left unchanged")
+		}
+	})
+
+	t.Run("changes nothing if a channel or group is unknown", func(t *testing.T) {
+		service, byName := newService()
+		groupID, missing := "adult", "missing"
+
+		if _, err := service.BulkUpdateChannels(ctx, []string{"A", "Nope"}, ChannelUpdate{Group: &groupID}); !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+		if _, err := service.BulkUpdateChannels(ctx, []string{"A"}, ChannelUpdate{Group: &missing}); !errors.Is(err, group.ErrGroupNotFound) {
+			t.Errorf("expected ErrGroupNotFound, got %v", err)
+		}
+		if byName["A"].Group() != "" {
+			t.Errorf("expected A to be left unchanged, got group %q", byName["A"].Group())
+		}
+	})
+}
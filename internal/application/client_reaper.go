@@ -0,0 +1,56 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrClientIdle indicates a client was dropped because it stopped taking the
+// data buffered for it for longer than the client idle timeout.
+var ErrClientIdle = errors.New("client idle")
+
+// SetClientIdleTimeout configures how long a client may go without taking
+// the data waiting for it before ReapIdleClients drops it. Zero or negative
+// disables reaping.
+func (s *AceStreamProxyService) SetClientIdleTimeout(timeout time.Duration) {
+	s.clientIdleTimeout.Store(int64(timeout))
+}
+
+// ReapIdleClients drops clients whose writes have made no progress for
+// longer than the client idle timeout, such as those whose connection broke
+// without being closed. Their stream is released as if they had left, so a
+// stream left without clients is stopped. It is meant to run periodically.
+func (s *AceStreamProxyService) ReapIdleClients(ctx context.Context) error {
+	timeout := time.Duration(s.clientIdleTimeout.Load())
+	if timeout <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, session := range s.sessions.list() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, pid := range session.GetBroadcaster().reapIdle(timeout, now) {
+			s.counters.clientsReaped.Add(1)
+			s.logger.Warn("reaping idle client",
+				"infohash", session.InfoHash(),
+				"pid", pid,
+				"idle_timeout", timeout)
+			// The client may already be on its way out
+			_ = s.clients.Disconnect(pid)
+		}
+	}
+	return nil
+}
+
+// abortWrites makes a write to dst blocked on the network fail at once, if
+// dst is an HTTP response supporting write deadlines.
+func abortWrites(dst io.Writer) {
+	if rw, ok := dst.(http.ResponseWriter); ok {
+		_ = http.NewResponseController(rw).SetWriteDeadline(time.Now())
+	}
+}
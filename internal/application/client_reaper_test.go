@@ -0,0 +1,108 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// stuckResponseWriter simulates a client whose connection broke without being
+// closed: every write blocks until the write deadline is moved to the past.
+type stuckResponseWriter struct {
+	header  http.Header
+	once    sync.Once
+	aborted chan struct{}
+}
+
+func newStuckResponseWriter() *stuckResponseWriter {
+	return &stuckResponseWriter{header: make(http.Header), aborted: make(chan struct{})}
+}
+
+func (w *stuckResponseWriter) Header() http.Header { return w.header }
+
+func (w *stuckResponseWriter) WriteHeader(int) {}
+
+func (w *stuckResponseWriter) SetWriteDeadline(deadline time.Time) error {
+	if !deadline.After(time.Now()) {
+		w.once.Do(func() { close(w.aborted) })
+	}
+	return nil
+}
+
+func (w *stuckResponseWriter) Write(p []byte) (int, error) {
+	<-w.aborted
+	return 0, os.ErrDeadlineExceeded
+}
+
+func TestAceStreamProxyService_ReapIdleClients(t *testing.T) {
+	engine, stopped := blockingEngine("dl")
+	engine.streamContentFunc = func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+		if _, err := dst.Write([]byte("data")); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	t.Run("drops a stuck client and stops its stream", func(t *testing.T) {
+		service := NewAceStreamProxyService(engine, slog.Default(), time.Hour, nil)
+		service.SetClientIdleTimeout(10 * time.Millisecond)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- service.StreamToClient(context.Background(), "infohash-1", newStuckResponseWriter())
+		}()
+		waitForClientSessions(t, service, 1)
+		time.Sleep(20 * time.Millisecond)
+
+		if err := service.ReapIdleClients(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, ErrClientIdle) {
+				t.Errorf("expected ErrClientIdle, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("stuck client was not dropped")
+		}
+		if service.IsStreamActive("infohash-1") {
+			t.Error("expected the stream without clients to be removed")
+		}
+		if got := stopped(); len(got) != 1 {
+			t.Errorf("expected the engine stream to be stopped, got %v", got)
+		}
+		if got := service.Counters().ClientsReaped; got != 1 {
+			t.Errorf("expected 1 reaped client, got %d", got)
+		}
+	})
+
+	t.Run("leaves clients alone when disabled", func(t *testing.T) {
+		service := NewAceStreamProxyService(engine, slog.Default(), time.Hour, nil)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- service.StreamToClient(context.Background(), "infohash-1", newStuckResponseWriter())
+		}()
+		id := waitForClientSessions(t, service, 1)[0].ID
+		defer func() {
+			_ = service.DisconnectClient(id)
+			<-done
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		if err := service.ReapIdleClients(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !service.IsStreamActive("infohash-1") || service.Counters().ClientsReaped != 0 {
+			t.Error("expected the client to be kept")
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming"
+)
+
+// ErrClientSessionNotFound indicates no connected client has the given session ID.
+var ErrClientSessionNotFound = errors.New("client session not found")
+
+// bitrateWindow is how often a client's current bitrate is recalculated.
+// A client that has received nothing for two windows reports a zero bitrate.
+const bitrateWindow = 2 * time.Second
+
+// ClientInfo identifies who requested a stream. Drivers attach it to the
+// request context with WithClientInfo so client sessions can be attributed.
+type ClientInfo struct {
+	ClientIP  string
+	UserAgent string
+	Channel   string
+	// PlayerID is the pid a player sent, as it would to the engine. It only
+	// labels the session; streams are shared whatever the pid.
+	PlayerID string
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo returns a copy of ctx carrying info for the streaming layer.
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+func clientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info
+}
+
+// ClientSession is a point-in-time view of one connected streaming client.
+type ClientSession struct {
+	ID        string
+	ClientIP  string
+	UserAgent string
+	Channel   string
+	PlayerID  string
+	InfoHash  string
+	StartedAt time.Time
+	BytesSent int64
+	// Bitrate is the delivery rate in bits per second over the last window.
+	Bitrate int64
+	// WriteLatency tells how long writes to the client take.
+	WriteLatency streaming.WriteLatencyStats
+}
+
+// clientSession tracks a single client from connection until it leaves.
+type clientSession struct {
+	id        string
+	infoHash  string
+	info      ClientInfo
+	startedAt time.Time
+	cancel    context.CancelFunc
+	bytesSent atomic.Int64
+	latency   atomic.Pointer[streaming.LatencyWriter]
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowBytes int64
+	bitrate     int64
+}
+
+// record accounts n bytes delivered to the client at now.
+func (c *clientSession) record(n int, now time.Time) {
+	c.bytesSent.Add(int64(n))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.windowBytes += int64(n)
+	if elapsed := now.Sub(c.windowStart); elapsed >= bitrateWindow {
+		c.bitrate = c.windowBytes * 8 * int64(time.Second) / int64(elapsed)
+		c.windowStart = now
+		c.windowBytes = 0
+	}
+}
+
+func (c *clientSession) snapshot(now time.Time) ClientSession {
+	c.mu.Lock()
+	bitrate := c.bitrate
+	if now.Sub(c.windowStart) >= 2*bitrateWindow {
+		bitrate = 0
+	}
+	c.mu.Unlock()
+
+	var latency streaming.WriteLatencyStats
+	if lw := c.latency.Load(); lw != nil {
+		latency = lw.Stats()
+	}
+
+	return ClientSession{
+		ID:           c.id,
+		ClientIP:     c.info.ClientIP,
+		UserAgent:    c.info.UserAgent,
+		Channel:      c.info.Channel,
+		PlayerID:     c.info.PlayerID,
+		InfoHash:     c.infoHash,
+		StartedAt:    c.startedAt,
+		BytesSent:    c.bytesSent.Load(),
+		Bitrate:      bitrate,
+		WriteLatency: latency,
+	}
+}
+
+// writer wraps dst so every byte delivered to the client is recorded.
+func (c *clientSession) writer(dst io.Writer) io.Writer {
+	return &clientSessionWriter{dst: dst, session: c}
+}
+
+type clientSessionWriter struct {
+	dst     io.Writer
+	session *clientSession
+}
+
+func (w *clientSessionWriter) Write(p []byte) (int, error) {
+	n, err := w.dst.Write(p)
+	w.session.record(n, time.Now())
+	return n, err
+}
+
+// clientRegistry indexes connected clients by session ID.
+type clientRegistry struct {
+	mu      sync.RWMutex
+	clients map[string]*clientSession
+}
+
+func newClientRegistry() *clientRegistry {
+	return &clientRegistry{
+		clients: make(map[string]*clientSession),
+	}
+}
+
+// Add registers a client. Cancelling cancel must make the client's stream return.
+func (r *clientRegistry) Add(id, infoHash string, info ClientInfo, cancel context.CancelFunc) *clientSession {
+	now := time.Now()
+	c := &clientSession{
+		id:          id,
+		infoHash:    infoHash,
+		info:        info,
+		startedAt:   now,
+		cancel:      cancel,
+		windowStart: now,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[id] = c
+	return c
+}
+
+// Remove forgets a client once its stream has returned.
+func (r *clientRegistry) Remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.clients, id)
+}
+
+// Disconnect cancels the stream of the client with the given session ID.
+func (r *clientRegistry) Disconnect(id string) error {
+	r.mu.RLock()
+	c, ok := r.clients[id]
+	r.mu.RUnlock()
+
+	if !ok {
+		return ErrClientSessionNotFound
+	}
+	c.cancel()
+	return nil
+}
+
+// Snapshot returns every connected client, oldest first.
+func (r *clientRegistry) Snapshot() []ClientSession {
+	now := time.Now()
+
+	r.mu.RLock()
+	result := make([]ClientSession, 0, len(r.clients))
+	for _, c := range r.clients {
+		result = append(result, c.snapshot(now))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt.Before(result[j].StartedAt)
+	})
+	return result
+}
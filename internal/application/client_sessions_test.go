@@ -0,0 +1,154 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestAceStreamProxyService_ClientSessions(t *testing.T) {
+	t.Run("tracks connected clients until they leave", func(t *testing.T) {
+		release := make(chan struct{})
+		mockEngine := &mockAceStreamEngine{
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				if _, err := dst.Write([]byte("0123456789")); err != nil {
+					return err
+				}
+				select {
+				case <-release:
+				case <-ctx.Done():
+				}
+				return nil
+			},
+		}
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
+
+		ctx := WithClientInfo(context.Background(), ClientInfo{ClientIP: "10.0.0.5", UserAgent: "VLC", Channel: "HBO", PlayerID: "kodi-1"})
+		done := make(chan error, 1)
+		go func() {
+			done <- service.StreamToClient(ctx, "infohash-1", io.Discard)
+		}()
+
+		var sessions []ClientSession
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			sessions = service.ClientSessions()
+			if len(sessions) == 1 && sessions[0].BytesSent > 0 {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		if len(sessions) != 1 {
+			t.Fatalf("expected 1 client session, got %d", len(sessions))
+		}
+		got := sessions[0]
+		if got.ClientIP != "10.0.0.5" || got.UserAgent != "VLC" || got.Channel != "HBO" || got.PlayerID != "kodi-1" || got.InfoHash != "infohash-1" {
+			t.Errorf("unexpected session attribution: %+v", got)
+		}
+		if got.BytesSent != 10 {
+			t.Errorf("expected 10 bytes sent, got %d", got.BytesSent)
+		}
+		if got.ID == "" || got.StartedAt.IsZero() {
+			t.Errorf("expected ID and start time to be set: %+v", got)
+		}
+
+		close(release)
+		if err := <-done; err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if n := len(service.ClientSessions()); n != 0 {
+			t.Errorf("expected no sessions after client left, got %d", n)
+		}
+	})
+
+	t.Run("DisconnectClient ends only that client's stream", func(t *testing.T) {
+		mockEngine := &mockAceStreamEngine{
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+		service := NewAceStreamProxyService(mockEngine, slog.Default(), 10*time.Second, nil)
+
+		first := make(chan error, 1)
+		go func() {
+			first <- service.StreamToClient(context.Background(), "infohash-1", io.Discard)
+		}()
+		waitForClientSessions(t, service, 1)
+
+		secondCtx, cancelSecond := context.WithCancel(context.Background())
+		defer cancelSecond()
+		second := make(chan error, 1)
+		go func() {
+			second <- service.StreamToClient(secondCtx, "infohash-1", io.Discard)
+		}()
+		sessions := waitForClientSessions(t, service, 2)
+
+		if err := service.DisconnectClient(sessions[0].ID); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		select {
+		case <-first:
+		case <-time.After(time.Second):
+			t.Fatal("disconnected client is still streaming")
+		}
+
+		remaining := waitForClientSessions(t, service, 1)
+		if remaining[0].ID != sessions[1].ID {
+			t.Errorf("expected %s to remain connected, got %s", sessions[1].ID, remaining[0].ID)
+		}
+
+		cancelSecond()
+		<-second
+	})
+
+	t.Run("DisconnectClient returns ErrClientSessionNotFound for unknown ID", func(t *testing.T) {
+		service := NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, nil)
+
+		if err := service.DisconnectClient("pid-404"); !errors.Is(err, ErrClientSessionNotFound) {
+			t.Errorf("expected ErrClientSessionNotFound, got %v", err)
+		}
+	})
+}
+
+func waitForClientSessions(t *testing.T, service *AceStreamProxyService, n int) []ClientSession {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if sessions := service.ClientSessions(); len(sessions) == n {
+			return sessions
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d client sessions, got %d", n, len(service.ClientSessions()))
+	return nil
+}
+
+func TestClientSession_Bitrate(t *testing.T) {
+	start := time.Now()
+	c := newClientRegistry().Add("pid-1", "infohash", ClientInfo{}, func() {})
+	c.windowStart = start
+
+	c.record(500_000, start.Add(time.Second))
+	if got := c.snapshot(start.Add(time.Second)).Bitrate; got != 0 {
+		t.Errorf("expected no bitrate before the first window completes, got %d", got)
+	}
+
+	c.record(500_000, start.Add(bitrateWindow))
+	if got := c.snapshot(start.Add(bitrateWindow)).Bitrate; got != 4_000_000 {
+		t.Errorf("expected 4000000 bps, got %d", got)
+	}
+
+	if got := c.snapshot(start.Add(4 * bitrateWindow)).Bitrate; got != 0 {
+		t.Errorf("expected a stalled client to report 0 bps, got %d", got)
+	}
+	if got := c.snapshot(start).BytesSent; got != 1_000_000 {
+		t.Errorf("expected 1000000 bytes sent, got %d", got)
+	}
+}
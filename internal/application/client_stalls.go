@@ -0,0 +1,64 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming"
+)
+
+const (
+	// clientSlowWrite is how long a write to a client may take before it
+	// counts as slow. Writes to a client keeping up take microseconds.
+	clientSlowWrite = 500 * time.Millisecond
+	// clientStallWrites is how many slow writes in a row make a client stalled.
+	clientStallWrites = 5
+)
+
+// stallDetection sets when a client counts as stalled.
+type stallDetection struct {
+	slowWrite time.Duration
+	writes    int
+}
+
+// clientStalled reports a client whose writes have been slow
+// clientStallWrites times in a row.
+func (s *AceStreamProxyService) clientStalled(ctx context.Context, client *clientSession, stats streaming.WriteLatencyStats) {
+	s.counters.clientStalls.Add(1)
+	s.logger.WarnContext(ctx, "client stalled",
+		"infohash", client.infoHash,
+		"pid", client.id,
+		"client_ip", client.info.ClientIP,
+		"consecutive_slow_writes", stats.ConsecutiveSlow,
+		"write_p95", stats.P95,
+		"stalls", stats.Stalls)
+	s.events.Publish(EventClientStalled, ClientStalledData{
+		SessionID:       client.id,
+		ClientIP:        client.info.ClientIP,
+		InfoHash:        client.infoHash,
+		ConsecutiveSlow: stats.ConsecutiveSlow,
+		P95Ms:           stats.P95.Milliseconds(),
+		Stalls:          stats.Stalls,
+	})
+}
+
+// logSlowClient logs the write latency of a client that stalled while it was
+// connected, so that chronically slow clients can be told apart by address.
+func (s *AceStreamProxyService) logSlowClient(ctx context.Context, client *clientSession, latency *streaming.LatencyWriter) {
+	stats := latency.Stats()
+	if stats.Stalls == 0 {
+		return
+	}
+	s.logger.WarnContext(ctx, "slow client disconnected",
+		"infohash", client.infoHash,
+		"pid", client.id,
+		"client_ip", client.info.ClientIP,
+		"user_agent", client.info.UserAgent,
+		"stalls", stats.Stalls,
+		"slow_writes", stats.SlowWrites,
+		"writes", stats.Writes,
+		"write_p50", stats.P50,
+		"write_p95", stats.P95,
+		"write_p99", stats.P99,
+		"write_max", stats.Max)
+}
@@ -0,0 +1,74 @@
+package application
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// slowClientWriter accepts writes after delay.
+type slowClientWriter struct {
+	delay time.Duration
+}
+
+func (w *slowClientWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(p), nil
+}
+
+func TestAceStreamProxyService_ClientStalls(t *testing.T) {
+	service, _, _ := newResumeTestService(0)
+	service.stall = stallDetection{slowWrite: 5 * time.Millisecond, writes: 3}
+	events := NewEventBus()
+	service.SetEventBus(events)
+	sub, unsubscribe := events.Subscribe()
+	defer unsubscribe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = service.StreamToClientWithOptions(ctx, "resume-infohash", &slowClientWriter{delay: 10 * time.Millisecond}, StreamOptions{})
+	}()
+
+	var stalled *ClientStalledData
+	for stalled == nil {
+		select {
+		case event := <-sub:
+			if data, ok := event.Data.(ClientStalledData); ok {
+				stalled = &data
+			}
+		case <-ctx.Done():
+			t.Fatal("expected a client stalled event")
+		}
+	}
+	if stalled.InfoHash != "resume-infohash" || stalled.ConsecutiveSlow != 3 || stalled.Stalls != 1 {
+		t.Errorf("unexpected stall event: %+v", *stalled)
+	}
+
+	sessions := service.ClientSessions()
+	if len(sessions) != 1 || sessions[0].WriteLatency.Stalls < 1 || sessions[0].WriteLatency.P50 < 5*time.Millisecond {
+		t.Errorf("expected the session to report its slow writes, got %+v", sessions)
+	}
+	if service.Counters().ClientStalls < 1 {
+		t.Errorf("expected the stall to be counted, got %+v", service.Counters())
+	}
+
+	cancel()
+	<-done
+}
+
+func TestAceStreamProxyService_ClientStalls_FastClient(t *testing.T) {
+	service, _, _ := newResumeTestService(0)
+	service.stall = stallDetection{slowWrite: time.Second, writes: 1}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_ = service.StreamToClientWithOptions(ctx, "resume-infohash", io.Discard, StreamOptions{})
+
+	if service.Counters().ClientStalls != 0 {
+		t.Errorf("expected no stalls for a fast client, got %d", service.Counters().ClientStalls)
+	}
+}
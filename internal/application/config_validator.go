@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ConfigCheck verifies one part of the configuration, such as an engine
+// being reachable or a directory being writable.
+type ConfigCheck struct {
+	Name   string                          // what is checked, e.g. "acestream_engine"
+	Target string                          // the URL, path or address checked
+	Hint   string                          // what to change when the check fails
+	Check  func(ctx context.Context) error // nil error means the check passed
+}
+
+// ConfigCheckResult is the outcome of a single ConfigCheck.
+type ConfigCheckResult struct {
+	Name   string
+	Target string
+	Status string // "ok" or "error"
+	Error  string // empty if status is "ok"
+	Hint   string // empty if status is "ok"
+}
+
+// ConfigReport is the outcome of validating the configuration.
+type ConfigReport struct {
+	Status string // "ok" if every check passed, "error" otherwise
+	Checks []ConfigCheckResult
+}
+
+// ConfigValidator runs the configuration checks and reports which failed and
+// how to fix them.
+type ConfigValidator struct {
+	checks  func() []ConfigCheck
+	timeout time.Duration
+}
+
+// NewConfigValidator creates a validator running the checks returned by
+// checks, each bounded by timeout. checks is called on every validation so
+// that it can reflect the configuration as it is at that time.
+func NewConfigValidator(checks func() []ConfigCheck, timeout time.Duration) *ConfigValidator {
+	return &ConfigValidator{checks: checks, timeout: timeout}
+}
+
+// Validate runs all checks concurrently and returns their results in the
+// order the checks were given.
+func (v *ConfigValidator) Validate(ctx context.Context) ConfigReport {
+	checks := v.checks()
+	report := ConfigReport{Status: "ok", Checks: make([]ConfigCheckResult, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, v.timeout)
+			defer cancel()
+
+			result := ConfigCheckResult{Name: c.Name, Target: c.Target, Status: "ok"}
+			if err := c.Check(checkCtx); err != nil {
+				result.Status = "error"
+				result.Error = err.Error()
+				result.Hint = c.Hint
+			}
+			report.Checks[i] = result
+		}()
+	}
+	wg.Wait()
+
+	for _, r := range report.Checks {
+		if r.Status != "ok" {
+			report.Status = "error"
+			break
+		}
+	}
+	return report
+}
@@ -0,0 +1,58 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfigValidator_Validate(t *testing.T) {
+	t.Run("reports failed checks with their hints in order", func(t *testing.T) {
+		validator := NewConfigValidator(func() []ConfigCheck {
+			return []ConfigCheck{
+				{Name: "engine", Target: "http://engine", Hint: "start the engine", Check: func(ctx context.Context) error {
+					return errors.New("connection refused")
+				}},
+				{Name: "db_path", Target: "/data", Hint: "fix permissions", Check: func(ctx context.Context) error {
+					return nil
+				}},
+			}
+		}, time.Second)
+
+		report := validator.Validate(context.Background())
+
+		if report.Status != "error" || len(report.Checks) != 2 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+		if got := report.Checks[0]; got.Name != "engine" || got.Status != "error" || got.Error != "connection refused" || got.Hint != "start the engine" {
+			t.Errorf("unexpected failed check: %+v", got)
+		}
+		if got := report.Checks[1]; got.Name != "db_path" || got.Status != "ok" || got.Hint != "" {
+			t.Errorf("unexpected passed check: %+v", got)
+		}
+	})
+
+	t.Run("bounds each check by the timeout", func(t *testing.T) {
+		validator := NewConfigValidator(func() []ConfigCheck {
+			return []ConfigCheck{{Name: "slow", Check: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}}}
+		}, 10*time.Millisecond)
+
+		report := validator.Validate(context.Background())
+
+		if report.Status != "error" || report.Checks[0].Error != context.DeadlineExceeded.Error() {
+			t.Errorf("expected the slow check to time out, got %+v", report)
+		}
+	})
+
+	t.Run("passes when every check passes", func(t *testing.T) {
+		validator := NewConfigValidator(func() []ConfigCheck { return nil }, time.Second)
+
+		if report := validator.Validate(context.Background()); report.Status != "ok" {
+			t.Errorf("expected status ok, got %+v", report)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrEngineStreamIdle indicates a session was ended because the engine
+// reported its stream idle for longer than the configured idle timeout.
+var ErrEngineStreamIdle = errors.New("engine stream idle")
+
+// engineIdleStatus is the status the engine reports for a stream that is no
+// longer transferring data.
+const engineIdleStatus = "idle"
+
+// enginePIDTracker records every PID the proxy has started on the engine and
+// not yet successfully stopped, so leaked engine sessions can be found.
+type enginePIDTracker struct {
+	mu          sync.Mutex
+	idleTimeout time.Duration
+	pids        map[string]*trackedPID
+}
+
+type trackedPID struct {
+	infoHash  string
+	idleSince time.Time
+}
+
+func newEnginePIDTracker() *enginePIDTracker {
+	return &enginePIDTracker{
+		pids: make(map[string]*trackedPID),
+	}
+}
+
+func (t *enginePIDTracker) Track(pid, infoHash string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pids[pid] = &trackedPID{infoHash: infoHash}
+}
+
+func (t *enginePIDTracker) Untrack(pid string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pids, pid)
+}
+
+// Snapshot returns the tracked PIDs mapped to their infohash.
+func (t *enginePIDTracker) Snapshot() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make(map[string]string, len(t.pids))
+	for pid, p := range t.pids {
+		result[pid] = p.infoHash
+	}
+	return result
+}
+
+// ObserveStatus records the engine status of pid at now and reports whether
+// the stream has been idle for at least the idle timeout.
+func (t *enginePIDTracker) ObserveStatus(pid, status string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.pids[pid]
+	if !ok {
+		return false
+	}
+
+	if status != engineIdleStatus {
+		p.idleSince = time.Time{}
+		return false
+	}
+	if p.idleSince.IsZero() {
+		p.idleSince = now
+	}
+	return now.Sub(p.idleSince) >= t.idleTimeout
+}
+
+// SetEngineIdleTimeout configures how long the engine may report a stream as
+// idle before ReapEngineStreams ends its session. Zero or negative disables
+// the idle check; orphaned PIDs are still stopped.
+func (s *AceStreamProxyService) SetEngineIdleTimeout(timeout time.Duration) {
+	s.enginePIDs.mu.Lock()
+	defer s.enginePIDs.mu.Unlock()
+	s.enginePIDs.idleTimeout = timeout
+}
+
+// ReapEngineStreams stops engine streams that are no longer needed. A PID
+// that no active session owns, typically because stopping it failed when its
+// last client disconnected, is stopped again. A session whose engine stream
+// has been idle for longer than the idle timeout is ended, which stops its
+// engine stream once its clients are gone. It is meant to run periodically.
+func (s *AceStreamProxyService) ReapEngineStreams(ctx context.Context) error {
+	s.enginePIDs.mu.Lock()
+	idleTimeout := s.enginePIDs.idleTimeout
+	s.enginePIDs.mu.Unlock()
+
+	var stopErr error
+	for pid, infoHash := range s.enginePIDs.Snapshot() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		session := s.ownerSession(pid, infoHash)
+		if session == nil {
+			if err := s.stopOrphanedPID(ctx, pid, infoHash); err != nil {
+				stopErr = err
+			}
+			continue
+		}
+
+		if idleTimeout <= 0 {
+			continue
+		}
+
+		stats, err := s.engine.GetStats(ctx, pid)
+		if err != nil {
+			s.logger.Debug("failed to get engine stats for reaping", "infohash", infoHash, "pid", pid, "error", err)
+			continue
+		}
+
+		if s.enginePIDs.ObserveStatus(pid, stats.Status, time.Now()) {
+			s.logger.Warn("ending idle engine stream",
+				"infohash", infoHash,
+				"pid", pid,
+				"idle_timeout", idleTimeout,
+				"clients", session.ClientCount())
+			// Close with the error before cancelling the pump, which would
+			// otherwise close the broadcaster cleanly first.
+			session.GetBroadcaster().CloseWithError(ErrEngineStreamIdle)
+			session.CancelEngine()
+		}
+	}
+
+	return stopErr
+}
+
+// StopEngineStreams stops every engine stream the proxy still has open. It is
+// meant for process shutdown, after clients have been disconnected.
+func (s *AceStreamProxyService) StopEngineStreams(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for pid, infoHash := range s.enginePIDs.Snapshot() {
+		if err := s.engine.StopStream(ctx, pid); err != nil {
+			s.counters.streamStopFailures.Add(1)
+			s.logger.Error("failed to stop engine stream on shutdown", "infohash", infoHash, "pid", pid, "error", err)
+			continue
+		}
+		s.counters.streamsStopped.Add(1)
+		s.untrackEnginePID(pid)
+	}
+}
+
+// ownerSession returns the active session whose engine stream uses pid.
+func (s *AceStreamProxyService) ownerSession(pid, infoHash string) *streamSession {
+	session := s.sessions.GetSession(infoHash)
+	if session == nil || session.GetEnginePID() != pid {
+		return nil
+	}
+	return session
+}
+
+// stopOrphanedPID stops an engine stream that no session owns. It holds the
+// proxy lock so it cannot race a client cleanup or restart of the same PID.
+func (s *AceStreamProxyService) stopOrphanedPID(ctx context.Context, pid, infoHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Re-check under the lock: a new session may have adopted the PID, or a
+	// cleanup may have stopped it while we were waiting.
+	if s.ownerSession(pid, infoHash) != nil {
+		return nil
+	}
+	if _, tracked := s.enginePIDs.Snapshot()[pid]; !tracked {
+		return nil
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// The lifecycle counters already recorded the failed stop that orphaned
+	// this PID, so a retry is not counted again.
+	if err := s.engine.StopStream(stopCtx, pid); err != nil {
+		s.logger.Error("failed to stop orphaned engine stream", "infohash", infoHash, "pid", pid, "error", err)
+		return err
+	}
+
+	s.untrackEnginePID(pid)
+	s.logger.Info("stopped orphaned engine stream", "infohash", infoHash, "pid", pid)
+	return nil
+}
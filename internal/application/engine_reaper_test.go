@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// blockingEngine returns a mock engine whose streams deliver nothing and stay
+// open until cancelled, recording every PID passed to StopStream.
+func blockingEngine(status string, stopErrs ...error) (*mockAceStreamEngine, func() []string) {
+	var mu sync.Mutex
+	var stopped []string
+	engine := &mockAceStreamEngine{
+		streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		getStatsFunc: func(ctx context.Context, pid string) (driven.StreamStats, error) {
+			return driven.StreamStats{PID: pid, Status: status}, nil
+		},
+		stopStreamFunc: func(ctx context.Context, pid string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			stopped = append(stopped, pid)
+			if len(stopErrs) > 0 {
+				err := stopErrs[0]
+				stopErrs = stopErrs[1:]
+				return err
+			}
+			return nil
+		},
+	}
+	return engine, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), stopped...)
+	}
+}
+
+func TestAceStreamProxyService_ReapEngineStreams(t *testing.T) {
+	t.Run("stops a PID whose stop failed when its last client left", func(t *testing.T) {
+		engine, stopped := blockingEngine("dl", errors.New("engine busy"))
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- service.StreamToClient(ctx, "infohash-1", io.Discard)
+		}()
+		pid := waitForClientSessions(t, service, 1)[0].ID
+		cancel()
+		<-done
+
+		if got := stopped(); len(got) != 1 {
+			t.Fatalf("expected the failed stop on disconnect, got %v", got)
+		}
+
+		if err := service.ReapEngineStreams(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := stopped(); len(got) != 2 || got[1] != pid {
+			t.Fatalf("expected orphaned %s to be stopped again, got %v", pid, got)
+		}
+
+		// Once stopped, the PID is no longer tracked.
+		if err := service.ReapEngineStreams(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := stopped(); len(got) != 2 {
+			t.Errorf("expected no further stops, got %v", got)
+		}
+	})
+
+	t.Run("ends a session idle for longer than the idle timeout", func(t *testing.T) {
+		engine, stopped := blockingEngine("idle")
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		service.SetEngineIdleTimeout(10 * time.Millisecond)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- service.StreamToClient(context.Background(), "infohash-1", io.Discard)
+		}()
+		waitForClientSessions(t, service, 1)
+
+		// The first observation only starts the idle clock.
+		if err := service.ReapEngineStreams(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("session ended before the idle timeout: %v", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		if err := service.ReapEngineStreams(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, ErrEngineStreamIdle) {
+				t.Errorf("expected ErrEngineStreamIdle, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("idle session was not ended")
+		}
+		if got := stopped(); len(got) != 1 {
+			t.Errorf("expected the engine stream to be stopped once, got %v", got)
+		}
+	})
+
+	t.Run("leaves active sessions alone", func(t *testing.T) {
+		engine, stopped := blockingEngine("dl")
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		service.SetEngineIdleTimeout(time.Nanosecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = service.StreamToClient(ctx, "infohash-1", io.Discard)
+		}()
+		waitForClientSessions(t, service, 1)
+
+		for range 3 {
+			if err := service.ReapEngineStreams(context.Background()); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		if !service.IsStreamActive("infohash-1") {
+			t.Error("expected session to stay active")
+		}
+		if got := stopped(); len(got) != 0 {
+			t.Errorf("expected no stops, got %v", got)
+		}
+	})
+}
+
+func TestAceStreamProxyService_StopEngineStreams(t *testing.T) {
+	engine, stopped := blockingEngine("dl")
+	service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = service.StreamToClient(ctx, "infohash-1", io.Discard)
+	}()
+	pid := waitForClientSessions(t, service, 1)[0].ID
+
+	service.StopEngineStreams(context.Background())
+
+	if got := stopped(); len(got) != 1 || got[0] != pid {
+		t.Errorf("expected %s to be stopped, got %v", pid, got)
+	}
+}
@@ -0,0 +1,58 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+var (
+	ErrUnknownEngineCommand    = errors.New("unknown engine command")
+	ErrEngineCommandNotAllowed = errors.New("engine command changes engine state and must be run explicitly")
+)
+
+// EngineService runs the allowlisted maintenance commands of the AceStream
+// engines, such as reading their version or clearing their cache.
+type EngineService struct {
+	commander driven.AceStreamCommander
+	logger    *slog.Logger
+}
+
+// NewEngineService creates a new engine command service.
+func NewEngineService(commander driven.AceStreamCommander, logger *slog.Logger) *EngineService {
+	return &EngineService{
+		commander: commander,
+		logger:    logger,
+	}
+}
+
+// Commands returns the commands that can be run.
+func (s *EngineService) Commands() []driven.EngineCommand {
+	return slices.Clone(driven.EngineCommands)
+}
+
+// RunCommand runs the named command on the engines and returns a result per
+// engine. Commands that change engine state are only run when mutate is set,
+// so that they cannot be triggered by a read-only request.
+// Returns ErrUnknownEngineCommand if the command is not allowlisted.
+// Returns ErrEngineCommandNotAllowed for a state-changing command without mutate.
+func (s *EngineService) RunCommand(ctx context.Context, name string, mutate bool) ([]driven.EngineCommandResult, error) {
+	command := driven.EngineCommand(name)
+	if !slices.Contains(driven.EngineCommands, command) {
+		return nil, ErrUnknownEngineCommand
+	}
+	if command.Mutates() && !mutate {
+		return nil, ErrEngineCommandNotAllowed
+	}
+
+	s.logger.InfoContext(ctx, "running engine command", "command", command)
+	results, err := s.commander.RunCommand(ctx, command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run engine command %q: %w", command, err)
+	}
+	return results, nil
+}
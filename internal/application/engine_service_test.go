@@ -0,0 +1,54 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+type mockAceStreamCommander struct {
+	commands []driven.EngineCommand
+}
+
+func (m *mockAceStreamCommander) RunCommand(ctx context.Context, command driven.EngineCommand) ([]driven.EngineCommandResult, error) {
+	m.commands = append(m.commands, command)
+	return []driven.EngineCommandResult{{Engine: "engine-1", Result: "ok"}}, nil
+}
+
+func TestEngineService_RunCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		mutate  bool
+		wantErr error
+	}{
+		{name: "read-only command", command: "get_version"},
+		{name: "state-changing command run explicitly", command: "clear_cache", mutate: true},
+		{name: "state-changing command without mutate", command: "clear_cache", wantErr: ErrEngineCommandNotAllowed},
+		{name: "unknown command", command: "shutdown", mutate: true, wantErr: ErrUnknownEngineCommand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commander := &mockAceStreamCommander{}
+			service := NewEngineService(commander, slog.Default())
+
+			results, err := service.RunCommand(context.Background(), tt.command, tt.mutate)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RunCommand() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(commander.commands) != 0 {
+					t.Errorf("expected no command to reach the engine, got %v", commander.commands)
+				}
+				return
+			}
+			if len(results) != 1 || len(commander.commands) != 1 || string(commander.commands[0]) != tt.command {
+				t.Errorf("expected %q to run once, got %v with results %+v", tt.command, commander.commands, results)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// engineSessionTimeout bounds each write of an engine session to the
+// repository.
+const engineSessionTimeout = 5 * time.Second
+
+// SetEngineSessionRepository persists the engine streams the proxy has open,
+// so that RestoreEngineSessions can find the ones a previous process left
+// running on the engine. Failing to persist a stream is logged and does not
+// affect it.
+func (s *AceStreamProxyService) SetEngineSessionRepository(repo driven.EngineSessionRepository) {
+	s.engineSessions = repo
+}
+
+// trackEnginePID records that the engine stream of session runs under pid,
+// persisting it if a repository is set.
+func (s *AceStreamProxyService) trackEnginePID(pid string, session *streamSession) {
+	s.enginePIDs.Track(pid, session.Key())
+	if s.engineSessions == nil {
+		return
+	}
+
+	record := driven.EngineSession{PID: pid, Key: session.Key(), InfoHash: session.InfoHash(), StartedAt: time.Now()}
+	if restorer, ok := s.engine.(driven.AceStreamSessionRestorer); ok {
+		record.Handle, _ = restorer.SessionHandle(pid)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), engineSessionTimeout)
+	defer cancel()
+	if err := s.engineSessions.Save(ctx, record); err != nil {
+		s.logger.Warn("failed to persist engine stream", "infohash", session.InfoHash(), "pid", pid, "error", err)
+	}
+}
+
+// untrackEnginePID forgets the engine stream of pid once it is stopped.
+func (s *AceStreamProxyService) untrackEnginePID(pid string) {
+	s.enginePIDs.Untrack(pid)
+	if s.engineSessions == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), engineSessionTimeout)
+	defer cancel()
+	if err := s.engineSessions.Delete(ctx, pid); err != nil {
+		s.logger.Warn("failed to forget engine stream", "pid", pid, "error", err)
+	}
+}
+
+// RestoreEngineSessions reconciles the engine streams persisted by a previous
+// process with the engine. It is meant to run once at startup, before
+// streams are served. Every persisted stream is tracked again as orphaned
+// and stopped, logging those the engine still reports through its stat
+// endpoint; a stream the engine no longer reports is forgotten even if
+// stopping it fails. If the engine cannot be reached, ReapEngineStreams
+// stops them once it can.
+func (s *AceStreamProxyService) RestoreEngineSessions(ctx context.Context) error {
+	if s.engineSessions == nil {
+		return nil
+	}
+	records, err := s.engineSessions.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	restorer, _ := s.engine.(driven.AceStreamSessionRestorer)
+	for _, r := range records {
+		if restorer != nil && r.Handle != "" {
+			if err := restorer.RestoreSession(r.PID, r.Handle); err != nil {
+				s.logger.Warn("failed to restore engine stream", "infohash", r.InfoHash, "pid", r.PID, "error", err)
+			}
+		}
+		s.enginePIDs.Track(r.PID, r.Key)
+	}
+
+	if err := s.engine.Ping(ctx); err != nil {
+		s.logger.Warn("engine unreachable, streams left by the previous run will be stopped by the reaper",
+			"streams", len(records), "error", err)
+		return nil
+	}
+
+	var stopErr error
+	stopped, gone := 0, 0
+	for _, r := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		stats, err := s.engine.GetStats(ctx, r.PID)
+		running := err == nil && stats.Status != ""
+		if running {
+			s.logger.Info("found engine stream left by the previous run",
+				"infohash", r.InfoHash,
+				"pid", r.PID,
+				"started_at", r.StartedAt,
+				"status", stats.Status)
+		}
+
+		// Streams the engine no longer reports are stopped all the same, to
+		// release what the engine adapter restored for them
+		if err := s.stopOrphanedPID(ctx, r.PID, r.Key); err != nil {
+			if running {
+				stopErr = err
+				continue
+			}
+			s.untrackEnginePID(r.PID)
+		}
+		if running {
+			stopped++
+		} else {
+			gone++
+		}
+	}
+
+	s.logger.Info("reconciled engine streams left by the previous run", "stopped", stopped, "gone", gone)
+	return stopErr
+}
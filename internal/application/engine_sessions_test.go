@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// memoryEngineSessionRepository is an in-memory EngineSessionRepository.
+type memoryEngineSessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]driven.EngineSession
+}
+
+func newMemoryEngineSessionRepository(sessions ...driven.EngineSession) *memoryEngineSessionRepository {
+	r := &memoryEngineSessionRepository{sessions: make(map[string]driven.EngineSession)}
+	for _, s := range sessions {
+		r.sessions[s.PID] = s
+	}
+	return r
+}
+
+func (r *memoryEngineSessionRepository) Save(ctx context.Context, s driven.EngineSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[s.PID] = s
+	return nil
+}
+
+func (r *memoryEngineSessionRepository) FindAll(ctx context.Context) ([]driven.EngineSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessions := make([]driven.EngineSession, 0, len(r.sessions))
+	for _, s := range r.sessions {
+		sessions = append(sessions, s)
+	}
+	slices.SortFunc(sessions, func(a, b driven.EngineSession) int { return a.StartedAt.Compare(b.StartedAt) })
+	return sessions, nil
+}
+
+func (r *memoryEngineSessionRepository) Delete(ctx context.Context, pid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, pid)
+	return nil
+}
+
+func (r *memoryEngineSessionRepository) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.sessions)
+}
+
+func TestAceStreamProxyService_EngineSessions(t *testing.T) {
+	t.Run("persists engine streams while they run", func(t *testing.T) {
+		engine, _ := blockingEngine("dl")
+		repo := newMemoryEngineSessionRepository()
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		service.SetEngineSessionRepository(repo)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- service.StreamToClient(ctx, "infohash-1", io.Discard)
+		}()
+		pid := waitForClientSessions(t, service, 1)[0].ID
+
+		sessions, _ := repo.FindAll(context.Background())
+		if len(sessions) != 1 || sessions[0].PID != pid || sessions[0].InfoHash != "infohash-1" || sessions[0].StartedAt.IsZero() {
+			t.Fatalf("expected the engine stream persisted, got %+v", sessions)
+		}
+
+		cancel()
+		<-done
+		if n := repo.len(); n != 0 {
+			t.Errorf("expected the stopped stream forgotten, %d left", n)
+		}
+	})
+
+	t.Run("stops the streams a previous run left", func(t *testing.T) {
+		// The stream the engine no longer reports fails to stop
+		engine, stopped := blockingEngine("dl", nil, errors.New("unknown session"))
+		engine.getStatsFunc = func(ctx context.Context, pid string) (driven.StreamStats, error) {
+			if pid == "pid-gone" {
+				return driven.StreamStats{}, errors.New("session not found")
+			}
+			return driven.StreamStats{PID: pid, Status: "dl"}, nil
+		}
+		repo := newMemoryEngineSessionRepository(
+			driven.EngineSession{PID: "pid-running", Key: "infohash-1", InfoHash: "infohash-1", StartedAt: time.Now().Add(-time.Hour)},
+			driven.EngineSession{PID: "pid-gone", Key: "infohash-2", InfoHash: "infohash-2", StartedAt: time.Now()},
+		)
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		service.SetEngineSessionRepository(repo)
+
+		if err := service.RestoreEngineSessions(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := stopped(); len(got) != 2 {
+			t.Errorf("expected both streams stopped, got %v", got)
+		}
+		if n := repo.len(); n != 0 {
+			t.Errorf("expected the streams forgotten, %d left", n)
+		}
+		if tracked := service.enginePIDs.Snapshot(); len(tracked) != 0 {
+			t.Errorf("expected no PID tracked, got %v", tracked)
+		}
+	})
+
+	t.Run("leaves the streams to the reaper while the engine is down", func(t *testing.T) {
+		engine, stopped := blockingEngine("dl")
+		engineDown := true
+		engine.pingFunc = func(ctx context.Context) error {
+			if engineDown {
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+		repo := newMemoryEngineSessionRepository(
+			driven.EngineSession{PID: "pid-1", Key: "infohash-1", InfoHash: "infohash-1", StartedAt: time.Now()},
+		)
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		service.SetEngineSessionRepository(repo)
+
+		if err := service.RestoreEngineSessions(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := stopped(); len(got) != 0 || repo.len() != 1 {
+			t.Fatalf("expected the stream kept while the engine is down, stopped %v", got)
+		}
+
+		engineDown = false
+		if err := service.ReapEngineStreams(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := stopped(); len(got) != 1 || got[0] != "pid-1" {
+			t.Errorf("expected the reaper to stop pid-1, got %v", got)
+		}
+		if n := repo.len(); n != 0 {
+			t.Errorf("expected the stream forgotten, %d left", n)
+		}
+	})
+}
@@ -1,10 +1,13 @@
 package application
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/alorle/iptv-manager/internal/channel"
@@ -15,6 +18,40 @@ import (
 
 const fuzzyMatchThreshold = 0.7
 
+// Default thresholds of the EPG auto-mapper, see SetAutoMapThresholds.
+const (
+	DefaultAutoMapThreshold       = 0.8
+	DefaultAutoMapReviewThreshold = 0.9
+)
+
+// ErrEPGSyncInProgress indicates a sync was requested while another one is running.
+var ErrEPGSyncInProgress = errors.New("epg sync already in progress")
+
+// EPGSyncResult counts what a sync changed. Channels whose EPG mapping and
+// streams already matched are left untouched and counted as unchanged.
+type EPGSyncResult struct {
+	ChannelsCreated   int
+	ChannelsUpdated   int
+	ChannelsUnchanged int
+	ChannelsArchived  int
+	// ChannelsAutoMapped counts unmapped channels matched to an EPG channel by name.
+	ChannelsAutoMapped int
+	StreamsAdded       int
+	StreamsRemoved     int
+}
+
+// EPGSyncStatus reports the current and last EPG sync.
+type EPGSyncStatus struct {
+	Running      bool
+	LastStarted  time.Time
+	LastFinished time.Time
+	LastSuccess  time.Time
+	// LastError is the error of the last sync, empty if it succeeded.
+	LastError string
+	// LastResult holds the changes made by the last sync, even a failed one.
+	LastResult EPGSyncResult
+}
+
 // EPGSyncService orchestrates the EPG sync workflow:
 // fetch EPG data, match with Acestream sources, merge channels, and update streams.
 type EPGSyncService struct {
@@ -23,7 +60,22 @@ type EPGSyncService struct {
 	channelRepo      driven.ChannelRepository
 	streamRepo       driven.StreamRepository
 	subscriptionRepo driven.SubscriptionRepository
+	logos            *LogoService
+	changes          *SourceChangeService
+	events           *EventBus
 	logger           *slog.Logger
+	now              func() time.Time
+
+	// autoMapThreshold is the minimum similarity to map a channel automatically;
+	// automatic mappings below reviewThreshold are listed for review.
+	autoMapThreshold float64
+	reviewThreshold  float64
+
+	mu             sync.Mutex
+	status         EPGSyncStatus
+	priorities     map[string]int
+	conflictPolicy ConflictPolicy
+	conflicts      []SourceConflict
 }
 
 // NewEPGSyncService creates a new EPG sync service with the required dependencies.
@@ -42,39 +94,131 @@ func NewEPGSyncService(
 		streamRepo:       streamRepo,
 		subscriptionRepo: subscriptionRepo,
 		logger:           logger,
+		now:              time.Now,
+		autoMapThreshold: DefaultAutoMapThreshold,
+		reviewThreshold:  DefaultAutoMapReviewThreshold,
+		conflictPolicy:   ConflictPolicyPriority,
 	}
 }
 
+// SetLogoService enables caching the logos of synced channels locally.
+func (s *EPGSyncService) SetLogoService(logos *LogoService) {
+	s.logos = logos
+}
+
+// SetSourceChangeService enables recording what changed in each Acestream
+// source since the previous sync.
+func (s *EPGSyncService) SetSourceChangeService(changes *SourceChangeService) {
+	s.changes = changes
+}
+
+// SetEventBus enables publishing EventEPGSyncProgress while syncing.
+func (s *EPGSyncService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// SetAutoMapThresholds sets the minimum name similarity, from 0.0 to 1.0, at
+// which unmapped channels are mapped to an EPG channel during syncs, and the
+// confidence below which those mappings are listed by LowConfidenceMappings.
+func (s *EPGSyncService) SetAutoMapThresholds(threshold, review float64) {
+	s.autoMapThreshold = threshold
+	s.reviewThreshold = review
+}
+
+// Status returns the state of the current or last sync.
+func (s *EPGSyncService) Status() EPGSyncStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
 // SyncChannels performs the full EPG synchronization workflow:
 // 1. Fetch EPG channels from external source
-// 2. Fetch Acestream hash lists from both sources (new-era, elcano)
-// 3. Match EPG channels with Acestream hashes using fuzzy matching
-// 4. Create/update channels and streams for subscribed EPG channels
-// 5. Archive channels that disappeared from EPG
+// 2. Fetch Acestream hash lists from both sources (new-era, elcano) concurrently,
+// and merging them in order of source priority, recording what changed since the
+// previous sync if a source change service is set
+// 3. Resolve hashes listed under more than one channel name with the conflict policy
+// 4. Match EPG channels with Acestream hashes using fuzzy matching
+// 5. Create/update channels and streams for subscribed EPG channels
+// 6. Archive channels that disappeared from EPG
+// 7. Map the remaining unmapped channels to EPG channels with similar names
+// 8. Download logos of synced channels, if a logo service is set
 //
 // Errors during individual channel processing are logged but do not stop the sync.
+// A single unavailable Acestream source is logged and the sync continues with the others.
 // Only critical errors (unable to fetch data, unable to load subscriptions) return an error.
+//
+// The sync is incremental: channels and streams that already match the EPG
+// are not rewritten. The outcome is recorded and reported by Status, and a
+// failed sync is published as a "failed" progress event.
+// Returns ErrEPGSyncInProgress if another sync is running.
 func (s *EPGSyncService) SyncChannels(ctx context.Context) error {
+	s.mu.Lock()
+	if s.status.Running {
+		s.mu.Unlock()
+		return ErrEPGSyncInProgress
+	}
+	s.status.Running = true
+	s.status.LastStarted = s.now()
+	s.mu.Unlock()
+
+	var result EPGSyncResult
+	err := s.syncChannels(ctx, &result)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.Running = false
+	s.status.LastFinished = s.now()
+	s.status.LastResult = result
+	if err != nil {
+		s.status.LastError = err.Error()
+		s.events.Publish(EventEPGSyncProgress, EPGSyncProgress{Phase: "failed", Error: err.Error()})
+	} else {
+		s.status.LastError = ""
+		s.status.LastSuccess = s.status.LastFinished
+	}
+	return err
+}
+
+// syncChannels runs one sync, accumulating its changes into result.
+func (s *EPGSyncService) syncChannels(ctx context.Context, result *EPGSyncResult) error {
 	// Fetch EPG channels
 	epgChannels, err := s.epgFetcher.FetchEPG(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch EPG data: %w", err)
 	}
 
-	newEraHashes, err := s.acestreamSrc.FetchHashes(ctx, stream.SourceNewEra)
+	sources := s.prioritizedSources([]string{stream.SourceNewEra, stream.SourceElcano})
+	merged, sourceResults, err := FetchAndMerge(ctx, s.acestreamSrc, sources)
 	if err != nil {
-		return fmt.Errorf("failed to fetch new-era hashes: %w", err)
+		return err
 	}
-
-	elcanoHashes, err := s.acestreamSrc.FetchHashes(ctx, stream.SourceElcano)
-	if err != nil {
-		return fmt.Errorf("failed to fetch elcano hashes: %w", err)
+	allHashes := sourceHashes(merged)
+	// Only streams of the sources fetched in this run are pruned, so a
+	// source that is down does not lose its streams.
+	fetched := make(map[string]bool, len(sourceResults))
+	for _, r := range sourceResults {
+		if r.Err != nil {
+			s.logger.Warn("acestream source unavailable, continuing with remaining sources", "source", r.Source, "error", r.Err)
+			continue
+		}
+		fetched[r.Source] = true
+	}
+	if s.changes != nil {
+		s.changes.Track(ctx, sourceResults)
 	}
 
-	allHashes := mergeTaggedHashMaps(
-		tagHashMap(newEraHashes, stream.SourceNewEra),
-		tagHashMap(elcanoHashes, stream.SourceElcano),
-	)
+	names := sourceNames(sourceResults)
+	conflicts := s.resolveConflicts(ctx, allHashes, sourceResults)
+	for _, c := range conflicts {
+		s.logger.Debug("hash listed under several channels", "hash", c.InfoHash, "candidates", len(c.Candidates), "channel", c.Winner.ChannelName, "source", c.Winner.Source)
+	}
+	if len(conflicts) > 0 {
+		s.logger.Info("resolved source conflicts", "conflicts", len(conflicts), "policy", conflicts[0].Policy)
+	}
+	s.mu.Lock()
+	s.conflicts = conflicts
+	s.mu.Unlock()
 
 	// Load all subscriptions
 	subscriptions, err := s.subscriptionRepo.FindAll(ctx)
@@ -82,10 +226,12 @@ func (s *EPGSyncService) SyncChannels(ctx context.Context) error {
 		return fmt.Errorf("failed to load subscriptions: %w", err)
 	}
 
-	// Build a set of subscribed EPG IDs for quick lookup
+	// Build a set of subscribed EPG IDs for quick lookup. Paused and expired
+	// subscriptions are left out, so their channels are archived below.
+	now := s.now()
 	subscribedEPGIDs := make(map[string]bool)
 	for _, sub := range subscriptions {
-		if sub.IsEnabled() {
+		if sub.IsActive(now) {
 			subscribedEPGIDs[sub.EPGChannelID()] = true
 		}
 	}
@@ -104,6 +250,16 @@ func (s *EPGSyncService) SyncChannels(ctx context.Context) error {
 
 	// Track which channels were processed
 	processedChannelNames := make(map[string]bool)
+	logoURLs := make(map[string]string)
+
+	progress := EPGSyncProgress{Phase: "started"}
+	for _, epgChannel := range epgChannels {
+		if subscribedEPGIDs[epgChannel.EPGID()] {
+			progress.Total++
+		}
+	}
+	s.events.Publish(EventEPGSyncProgress, progress)
+	progress.Phase = "channels"
 
 	// Process each EPG channel
 	for _, epgChannel := range epgChannels {
@@ -111,8 +267,10 @@ func (s *EPGSyncService) SyncChannels(ctx context.Context) error {
 		if !subscribedEPGIDs[epgChannel.EPGID()] {
 			continue
 		}
+		progress.Processed++
+		s.events.Publish(EventEPGSyncProgress, progress)
 
-		matchedHashes, matchScore := s.matchChannelWithHashes(epgChannel, allHashes)
+		matchedHashes, matchScore := s.matchChannelWithHashes(epgChannel, allHashes, names)
 
 		if matchScore < fuzzyMatchThreshold {
 			s.logger.Debug("skipping epg channel, no automatic match", "channel", epgChannel.Name(), "epg_id", epgChannel.EPGID(), "score", matchScore)
@@ -124,7 +282,7 @@ func (s *EPGSyncService) SyncChannels(ctx context.Context) error {
 			continue
 		}
 
-		if err := s.processChannel(ctx, epgChannel, matchedHashes, existingChannelMap); err != nil {
+		if err := s.processChannel(ctx, epgChannel, matchedHashes, fetched, existingChannelMap, result); err != nil {
 			// Log error but continue processing other channels
 			s.logger.Error("failed to process channel", "channel", epgChannel.Name(), "error", err)
 			continue
@@ -132,6 +290,9 @@ func (s *EPGSyncService) SyncChannels(ctx context.Context) error {
 
 		// Mark this channel as processed
 		processedChannelNames[epgChannel.Name()] = true
+		if epgChannel.Logo() != "" {
+			logoURLs[epgChannel.EPGID()] = epgChannel.Logo()
+		}
 	}
 
 	// Archive channels that disappeared from EPG (only if they were active)
@@ -141,15 +302,30 @@ func (s *EPGSyncService) SyncChannels(ctx context.Context) error {
 			if err := s.channelRepo.Update(ctx, existingChannel); err != nil {
 				s.logger.Error("failed to archive channel", "channel", existingChannel.Name(), "error", err)
 			} else {
+				result.ChannelsArchived++
 				s.logger.Info("archived channel, no longer in epg", "channel", existingChannel.Name())
 			}
 		}
 	}
 
+	if err := s.autoMapChannels(ctx, epgChannels, result); err != nil {
+		s.logger.Error("failed to auto-map channels", "error", err)
+	}
+
+	if s.logos != nil {
+		downloaded := s.logos.Refresh(ctx, logoURLs)
+		s.logger.Info("channel logos refreshed", "channels", len(logoURLs), "downloaded", downloaded)
+	}
+
+	progress.Phase = "completed"
+	s.events.Publish(EventEPGSyncProgress, progress)
 	return nil
 }
 
-func (s *EPGSyncService) matchChannelWithHashes(epgChannel epg.Channel, allHashes map[string][]taggedHash) ([]taggedHash, float64) {
+// matchChannelWithHashes finds the hashes of an EPG channel: those listed
+// under its EPG ID, or else under the most similar channel name or display
+// name, if similar enough.
+func (s *EPGSyncService) matchChannelWithHashes(epgChannel epg.Channel, allHashes map[string][]TaggedHash, names map[string]string) ([]TaggedHash, float64) {
 	if hashes, ok := allHashes[epgChannel.EPGID()]; ok {
 		return hashes, 1.0
 	}
@@ -159,6 +335,9 @@ func (s *EPGSyncService) matchChannelWithHashes(epgChannel epg.Channel, allHashe
 
 	for acestreamName := range allHashes {
 		score := channel.FuzzyMatch(epgChannel.Name(), acestreamName)
+		if name, ok := names[acestreamName]; ok {
+			score = max(score, channel.FuzzyMatch(epgChannel.Name(), name))
+		}
 		if score > bestScore {
 			bestScore = score
 			bestMatch = acestreamName
@@ -172,11 +351,78 @@ func (s *EPGSyncService) matchChannelWithHashes(epgChannel epg.Channel, allHashe
 	return allHashes[bestMatch], bestScore
 }
 
+// autoMapChannels maps every channel without an EPG mapping to the EPG
+// channel with the most similar name, when the similarity reaches the
+// auto-map threshold. The similarity is kept as the mapping's confidence.
+func (s *EPGSyncService) autoMapChannels(ctx context.Context, epgChannels []epg.Channel, result *EPGSyncResult) error {
+	channels, err := s.channelRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load channels: %w", err)
+	}
+
+	for _, ch := range channels {
+		if ch.EPGMapping() != nil {
+			continue
+		}
+
+		var best epg.Channel
+		var bestScore float64
+		for _, epgChannel := range epgChannels {
+			if score := channel.Similarity(ch.Name(), epgChannel.Name()); score > bestScore {
+				best, bestScore = epgChannel, score
+			}
+		}
+		if bestScore < s.autoMapThreshold {
+			continue
+		}
+
+		mapping, err := channel.NewEPGMapping(best.EPGID(), channel.MappingAuto, s.now())
+		if err != nil {
+			return fmt.Errorf("failed to create EPG mapping: %w", err)
+		}
+		ch.SetEPGMapping(mapping.WithConfidence(bestScore))
+		if err := s.channelRepo.Update(ctx, ch); err != nil {
+			s.logger.Error("failed to auto-map channel", "channel", ch.Name(), "error", err)
+			continue
+		}
+		result.ChannelsAutoMapped++
+		s.logger.Info("auto-mapped channel to epg", "channel", ch.Name(), "epg_id", best.EPGID(), "confidence", bestScore)
+	}
+	return nil
+}
+
+// LowConfidenceMappings returns the channels whose automatic EPG mapping has
+// a confidence below the review threshold, least confident first. Setting a
+// manual mapping on a channel confirms or corrects it.
+func (s *EPGSyncService) LowConfidenceMappings(ctx context.Context) ([]channel.Channel, error) {
+	channels, err := s.channelRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var review []channel.Channel
+	for _, ch := range channels {
+		if m := ch.EPGMapping(); m != nil && m.Source() == channel.MappingAuto && m.Confidence() < s.reviewThreshold {
+			review = append(review, ch)
+		}
+	}
+	slices.SortStableFunc(review, func(a, b channel.Channel) int {
+		return cmp.Compare(a.EPGMapping().Confidence(), b.EPGMapping().Confidence())
+	})
+	return review, nil
+}
+
+// processChannel creates or updates the channel for a matched EPG channel
+// and reconciles its streams with those of the fetched sources. An existing
+// channel already mapped to the EPG ID is not rewritten, which also preserves
+// manual mappings to it.
 func (s *EPGSyncService) processChannel(
 	ctx context.Context,
 	epgChannel epg.Channel,
-	hashes []taggedHash,
+	hashes []TaggedHash,
+	fetched map[string]bool,
 	existingChannels map[string]channel.Channel,
+	result *EPGSyncResult,
 ) error {
 	channelName := epgChannel.Name()
 
@@ -184,11 +430,12 @@ func (s *EPGSyncService) processChannel(
 	existingChannel, exists := existingChannels[channelName]
 
 	// Create EPG mapping (same for new and existing channels)
-	mapping, err := channel.NewEPGMapping(epgChannel.EPGID(), channel.MappingAuto, time.Now())
+	mapping, err := channel.NewEPGMapping(epgChannel.EPGID(), channel.MappingAuto, s.now())
 	if err != nil {
 		return fmt.Errorf("failed to create EPG mapping: %w", err)
 	}
 
+	created, mappingChanged := false, false
 	if !exists {
 		ch, err := channel.NewChannel(channelName)
 		if err != nil {
@@ -199,25 +446,46 @@ func (s *EPGSyncService) processChannel(
 		if err := s.channelRepo.Save(ctx, ch); err != nil {
 			if errors.Is(err, channel.ErrChannelAlreadyExists) {
 				s.logger.Warn("channel already exists, treating as update", "channel", channelName)
+				mappingChanged = true
 			} else {
 				return fmt.Errorf("failed to save channel: %w", err)
 			}
+		} else {
+			created = true
 		}
-	} else {
+	} else if current := existingChannel.EPGMapping(); current == nil || current.EPGID() != epgChannel.EPGID() {
 		existingChannel.SetEPGMapping(mapping)
 		if err := s.channelRepo.Update(ctx, existingChannel); err != nil {
 			return fmt.Errorf("failed to update channel: %w", err)
 		}
+		mappingChanged = true
 	}
 
 	// Update streams for this channel
-	return s.updateChannelStreams(ctx, channelName, hashes)
+	added, removed, err := s.updateChannelStreams(ctx, channelName, hashes, fetched)
+	result.StreamsAdded += added
+	result.StreamsRemoved += removed
+
+	switch {
+	case created:
+		result.ChannelsCreated++
+	case mappingChanged || added > 0 || removed > 0:
+		result.ChannelsUpdated++
+	default:
+		result.ChannelsUnchanged++
+	}
+	return err
 }
 
-func (s *EPGSyncService) updateChannelStreams(ctx context.Context, channelName string, hashes []taggedHash) error {
+// updateChannelStreams adds streams for new hashes and removes streams whose
+// hash is no longer listed by their source, leaving the rest untouched.
+// Only streams of a source in fetched are removed, so streams added manually
+// or imported, and those of sources that failed to fetch, are kept. It
+// returns how many streams were added and removed.
+func (s *EPGSyncService) updateChannelStreams(ctx context.Context, channelName string, hashes []TaggedHash, fetched map[string]bool) (int, int, error) {
 	existingStreams, err := s.streamRepo.FindByChannelName(ctx, channelName)
 	if err != nil && !errors.Is(err, stream.ErrStreamNotFound) {
-		return fmt.Errorf("failed to load existing streams: %w", err)
+		return 0, 0, fmt.Errorf("failed to load existing streams: %w", err)
 	}
 
 	existingHashSet := make(map[string]bool)
@@ -225,71 +493,71 @@ func (s *EPGSyncService) updateChannelStreams(ctx context.Context, channelName s
 		existingHashSet[s.InfoHash()] = true
 	}
 
+	added := 0
 	for _, th := range hashes {
-		if existingHashSet[th.hash] {
+		if existingHashSet[th.InfoHash] {
 			continue
 		}
 
-		newStream, err := stream.NewStream(th.hash, channelName, th.source)
+		newStream, err := stream.NewStream(th.InfoHash, channelName, th.Source)
 		if err != nil {
-			s.logger.Error("failed to create stream", "channel", channelName, "hash", th.hash, "error", err)
+			s.logger.Error("failed to create stream", "channel", channelName, "hash", th.InfoHash, "error", err)
 			continue
 		}
 
 		if err := s.streamRepo.Save(ctx, newStream); err != nil {
 			if !errors.Is(err, stream.ErrStreamAlreadyExists) {
-				s.logger.Error("failed to save stream", "channel", channelName, "hash", th.hash, "error", err)
+				s.logger.Error("failed to save stream", "channel", channelName, "hash", th.InfoHash, "error", err)
 			}
 			continue
 		}
+		added++
 	}
 
 	hashSet := make(map[string]bool)
 	for _, th := range hashes {
-		hashSet[th.hash] = true
+		hashSet[th.InfoHash] = true
 	}
 
+	removed := 0
 	for _, existingStream := range existingStreams {
-		if !hashSet[existingStream.InfoHash()] {
+		if fetched[existingStream.Source()] && !hashSet[existingStream.InfoHash()] {
 			if err := s.streamRepo.Delete(ctx, existingStream.InfoHash()); err != nil {
 				s.logger.Error("failed to delete obsolete stream", "hash", existingStream.InfoHash(), "channel", channelName, "error", err)
+				continue
 			}
+			removed++
 		}
 	}
 
-	return nil
-}
-
-type taggedHash struct {
-	hash   string
-	source string
+	return added, removed, nil
 }
 
-func tagHashMap(m map[string][]string, source string) map[string][]taggedHash {
-	result := make(map[string][]taggedHash, len(m))
+func tagHashMap(m map[string][]string, source string) map[string][]TaggedHash {
+	result := make(map[string][]TaggedHash, len(m))
 	for key, hashes := range m {
-		tagged := make([]taggedHash, len(hashes))
+		tagged := make([]TaggedHash, len(hashes))
 		for i, h := range hashes {
-			tagged[i] = taggedHash{hash: h, source: source}
+			tagged[i] = TaggedHash{InfoHash: h, Source: source}
 		}
 		result[key] = tagged
 	}
 	return result
 }
 
-func mergeTaggedHashMaps(maps ...map[string][]taggedHash) map[string][]taggedHash {
-	result := make(map[string][]taggedHash)
+func mergeTaggedHashMaps(maps ...map[string][]TaggedHash) map[string][]TaggedHash {
+	result := make(map[string][]TaggedHash)
 
 	for _, m := range maps {
 		for channelName, hashes := range m {
 			existing := make(map[string]bool)
 			for _, th := range result[channelName] {
-				existing[th.hash] = true
+				existing[th.InfoHash] = true
 			}
 			for _, th := range hashes {
-				if !existing[th.hash] {
+				if !existing[th.InfoHash] {
 					result[channelName] = append(result[channelName], th)
-					existing[th.hash] = true
+					existing[th.InfoHash] = true
 				}
 			}
 		}
@@ -2,15 +2,19 @@ package application
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"go.etcd.io/bbolt"
 
 	"github.com/alorle/iptv-manager/internal/adapter/driven"
+	"github.com/alorle/iptv-manager/internal/channel"
 	"github.com/alorle/iptv-manager/internal/epg"
+	"github.com/alorle/iptv-manager/internal/stream"
 	"github.com/alorle/iptv-manager/internal/subscription"
 )
 
@@ -317,13 +321,13 @@ func TestEPGSyncService_SyncChannels_E2E(t *testing.T) {
 			hashes: map[string]map[string][]string{
 				"new-era": {
 					"HBO": {
-						"hash1111111111111111111111111111111111111",
-						"hash2222222222222222222222222222222222222",
+						"1111111111111111111111111111111111111111",
+						"2222222222222222222222222222222222222222",
 					},
 				},
 				"elcano": {
 					"HBO": {
-						"hash3333333333333333333333333333333333333",
+						"3333333333333333333333333333333333333333",
 					},
 				},
 			},
@@ -356,13 +360,13 @@ func TestEPGSyncService_SyncChannels_E2E(t *testing.T) {
 			hashSet[s.InfoHash()] = true
 		}
 
-		if !hashSet["hash1111111111111111111111111111111111111"] {
+		if !hashSet["1111111111111111111111111111111111111111"] {
 			t.Error("expected hash1 from new-era source")
 		}
-		if !hashSet["hash2222222222222222222222222222222222222"] {
+		if !hashSet["2222222222222222222222222222222222222222"] {
 			t.Error("expected hash2 from new-era source")
 		}
-		if !hashSet["hash3333333333333333333333333333333333333"] {
+		if !hashSet["3333333333333333333333333333333333333333"] {
 			t.Error("expected hash3 from elcano source")
 		}
 
@@ -439,4 +443,259 @@ func TestEPGSyncService_SyncChannels_E2E(t *testing.T) {
 
 		t.Log("Successfully skipped disabled subscription during sync")
 	})
+
+	t.Run("paused and expired subscriptions are skipped and their channels archived", func(t *testing.T) {
+		db, cleanup := setupE2ETestDB(t)
+		defer cleanup()
+
+		channelRepo, _ := driven.NewChannelBoltDBRepository(db)
+		streamRepo, _ := driven.NewStreamBoltDBRepository(db)
+		subscriptionRepo, _ := driven.NewSubscriptionBoltDBRepository(db)
+
+		ctx := context.Background()
+
+		hbo, _ := epg.NewChannel("hbo.epg", "HBO", "", "Movies", "en", "hbo.epg")
+		espn, _ := epg.NewChannel("espn.epg", "ESPN", "", "Sports", "en", "espn.epg")
+		cnn, _ := epg.NewChannel("cnn.epg", "CNN", "", "News", "en", "cnn.epg")
+		epgFetcher := &mockEPGFetcher{channels: []epg.Channel{hbo, espn, cnn}}
+		acestreamSource := &mockAcestreamSource{
+			hashes: map[string]map[string][]string{
+				"new-era": {
+					"HBO":  {"0123456789abcdef0123456789abcdef01234567"},
+					"ESPN": {"fedcba9876543210fedcba9876543210fedcba98"},
+					"CNN":  {"1111111111111111111111111111111111111111"},
+				},
+				"elcano": {},
+			},
+		}
+
+		syncService := NewEPGSyncService(epgFetcher, acestreamSource, channelRepo, streamRepo, subscriptionRepo, slog.Default())
+		now := time.Now()
+		syncService.now = func() time.Time { return now }
+
+		for _, id := range []string{"hbo.epg", "espn.epg", "cnn.epg"} {
+			sub, _ := subscription.NewSubscription(id)
+			if id == "cnn.epg" {
+				sub = sub.WithExpiry(now.Add(time.Hour))
+			}
+			if err := subscriptionRepo.Save(ctx, sub); err != nil {
+				t.Fatalf("failed to save subscription %q: %v", id, err)
+			}
+		}
+		if err := syncService.SyncChannels(ctx); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		// Pause ESPN and let the CNN subscription expire
+		espnSub, _ := subscriptionRepo.FindByEPGID(ctx, "espn.epg")
+		if err := subscriptionRepo.Update(ctx, espnSub.Pause()); err != nil {
+			t.Fatalf("failed to pause subscription: %v", err)
+		}
+		now = now.Add(2 * time.Hour)
+
+		if err := syncService.SyncChannels(ctx); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		want := map[string]channel.Status{
+			"HBO":  channel.StatusActive,
+			"ESPN": channel.StatusArchived,
+			"CNN":  channel.StatusArchived,
+		}
+		for name, status := range want {
+			ch, err := channelRepo.FindByName(ctx, name)
+			if err != nil {
+				t.Fatalf("failed to find channel %q: %v", name, err)
+			}
+			if ch.Status() != status {
+				t.Errorf("channel %q: expected status %q, got %q", name, status, ch.Status())
+			}
+		}
+	})
+}
+
+// TestEPGSyncService_IncrementalSync verifies a repeated sync leaves unchanged
+// channels alone and that the outcome is reported by Status.
+func TestEPGSyncService_IncrementalSync(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	db, cleanup := setupE2ETestDB(t)
+	defer cleanup()
+
+	channelRepo, err := driven.NewChannelBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create channel repository: %v", err)
+	}
+	streamRepo, err := driven.NewStreamBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create stream repository: %v", err)
+	}
+	subscriptionRepo, err := driven.NewSubscriptionBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create subscription repository: %v", err)
+	}
+
+	hbo, _ := epg.NewChannel("hbo.epg", "HBO", "", "Movies", "en", "hbo.epg")
+	cnn, _ := epg.NewChannel("cnn.epg", "CNN", "", "News", "en", "cnn.epg")
+	epgFetcher := &mockEPGFetcher{channels: []epg.Channel{hbo, cnn}}
+	acestreamSource := &mockAcestreamSource{
+		hashes: map[string]map[string][]string{
+			"new-era": {
+				"HBO": {"0123456789abcdef0123456789abcdef01234567"},
+				"CNN": {"1111111111111111111111111111111111111111"},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	for _, id := range []string{"hbo.epg", "cnn.epg"} {
+		sub, _ := subscription.NewSubscription(id)
+		if err := subscriptionRepo.Save(ctx, sub); err != nil {
+			t.Fatalf("failed to save subscription: %v", err)
+		}
+	}
+
+	syncService := NewEPGSyncService(epgFetcher, acestreamSource, channelRepo, streamRepo, subscriptionRepo, slog.Default())
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	syncService.now = func() time.Time { return now }
+
+	if err := syncService.SyncChannels(ctx); err != nil {
+		t.Fatalf("first sync failed: %v", err)
+	}
+	first := syncService.Status()
+	if first.LastResult.ChannelsCreated != 2 || first.LastResult.StreamsAdded != 2 {
+		t.Errorf("unexpected first sync result: %+v", first.LastResult)
+	}
+	if first.Running || first.LastError != "" || !first.LastSuccess.Equal(now) {
+		t.Errorf("unexpected status after first sync: %+v", first)
+	}
+
+	// Only CNN's streams change before the second sync
+	acestreamSource.hashes["new-era"]["CNN"] = []string{"2222222222222222222222222222222222222222"}
+	now = now.Add(time.Hour)
+
+	if err := syncService.SyncChannels(ctx); err != nil {
+		t.Fatalf("second sync failed: %v", err)
+	}
+	second := syncService.Status().LastResult
+	want := EPGSyncResult{ChannelsUpdated: 1, ChannelsUnchanged: 1, StreamsAdded: 1, StreamsRemoved: 1}
+	if second != want {
+		t.Errorf("second sync result = %+v, want %+v", second, want)
+	}
+
+	// HBO's mapping was not rewritten by the second sync
+	ch, err := channelRepo.FindByName(ctx, "HBO")
+	if err != nil {
+		t.Fatalf("failed to find HBO: %v", err)
+	}
+	if synced := ch.EPGMapping().LastSynced(); !synced.Equal(now.Add(-time.Hour)) {
+		t.Errorf("expected untouched mapping from the first sync, got last synced %v", synced)
+	}
+
+	// A stream added manually is not pruned although no source lists it
+	manual, _ := stream.NewStream("3333333333333333333333333333333333333333", "CNN", stream.SourceManual)
+	if err := streamRepo.Save(ctx, manual); err != nil {
+		t.Fatalf("failed to save manual stream: %v", err)
+	}
+	if err := syncService.SyncChannels(ctx); err != nil {
+		t.Fatalf("third sync failed: %v", err)
+	}
+	if third := syncService.Status().LastResult; third.StreamsRemoved != 0 {
+		t.Errorf("expected no stream removed, got %+v", third)
+	}
+	if _, err := streamRepo.FindByInfoHash(ctx, manual.InfoHash()); err != nil {
+		t.Errorf("expected the manual stream to be kept, got %v", err)
+	}
+
+	// A failed sync is recorded without losing the last success
+	epgFetcher.err = errors.New("epg unreachable")
+	if err := syncService.SyncChannels(ctx); err == nil {
+		t.Fatal("expected sync to fail")
+	}
+	status := syncService.Status()
+	if status.LastError == "" || !status.LastSuccess.Equal(now) {
+		t.Errorf("unexpected status after failed sync: %+v", status)
+	}
+}
+
+func TestEPGSyncService_AutoMap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	db, cleanup := setupE2ETestDB(t)
+	defer cleanup()
+
+	channelRepo, err := driven.NewChannelBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create channel repository: %v", err)
+	}
+	streamRepo, err := driven.NewStreamBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create stream repository: %v", err)
+	}
+	subscriptionRepo, err := driven.NewSubscriptionBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create subscription repository: %v", err)
+	}
+
+	la1, _ := epg.NewChannel("la1.es", "La 1", "", "General", "es", "la1.es")
+	deportes, _ := epg.NewChannel("mdeportes.es", "Movistar Deportes", "", "Sports", "es", "mdeportes.es")
+	epgFetcher := &mockEPGFetcher{channels: []epg.Channel{la1, deportes}}
+
+	ctx := context.Background()
+	manual, _ := channel.NewEPGMapping("custom.es", channel.MappingManual, time.Now())
+	for _, name := range []string{"La 1 ES HD", "Movistar Deprotes", "Cuatro"} {
+		ch, _ := channel.NewChannel(name)
+		if err := channelRepo.Save(ctx, ch); err != nil {
+			t.Fatalf("failed to save channel: %v", err)
+		}
+	}
+	pinned, _ := channel.NewChannel("La 1")
+	pinned.SetEPGMapping(manual)
+	if err := channelRepo.Save(ctx, pinned); err != nil {
+		t.Fatalf("failed to save channel: %v", err)
+	}
+
+	syncService := NewEPGSyncService(epgFetcher, &mockAcestreamSource{}, channelRepo, streamRepo, subscriptionRepo, slog.Default())
+	syncService.SetAutoMapThresholds(0.8, 0.95)
+	if err := syncService.SyncChannels(ctx); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	if got := syncService.Status().LastResult.ChannelsAutoMapped; got != 2 {
+		t.Errorf("expected 2 auto-mapped channels, got %d", got)
+	}
+
+	tests := []struct {
+		name       string
+		wantEPGID  string
+		wantSource channel.MappingSource
+	}{
+		{"La 1 ES HD", "la1.es", channel.MappingAuto},
+		{"Movistar Deprotes", "mdeportes.es", channel.MappingAuto},
+		{"La 1", "custom.es", channel.MappingManual},
+	}
+	for _, tt := range tests {
+		ch, err := channelRepo.FindByName(ctx, tt.name)
+		if err != nil {
+			t.Fatalf("failed to find %q: %v", tt.name, err)
+		}
+		m := ch.EPGMapping()
+		if m == nil || m.EPGID() != tt.wantEPGID || m.Source() != tt.wantSource {
+			t.Errorf("%q: unexpected mapping %+v", tt.name, m)
+		}
+	}
+	if ch, _ := channelRepo.FindByName(ctx, "Cuatro"); ch.EPGMapping() != nil {
+		t.Error("expected a channel without a similar EPG channel to stay unmapped")
+	}
+
+	review, err := syncService.LowConfidenceMappings(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(review) != 1 || review[0].Name() != "Movistar Deprotes" {
+		t.Errorf("expected only the misspelt channel to need review, got %d channels", len(review))
+	}
 }
@@ -0,0 +1,203 @@
+package application
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of a live status event.
+type EventType string
+
+const (
+	// EventStreamStarted is published when the engine starts serving an infohash.
+	EventStreamStarted EventType = "stream.started"
+	// EventStreamPrebuffer is published as a new engine stream fills its
+	// prebuffer, and once more when clients start receiving it.
+	EventStreamPrebuffer EventType = "stream.prebuffer"
+	// EventStreamStopped is published when the last client leaves a stream.
+	EventStreamStopped EventType = "stream.stopped"
+	// EventEPGSyncProgress is published as an EPG sync starts, advances and ends.
+	EventEPGSyncProgress EventType = "epg.sync"
+	// EventEngineHealth is published when the AceStream engine becomes reachable or unreachable.
+	EventEngineHealth EventType = "engine.health"
+	// EventOverrideUpdated is published when a user changes a channel or subscription setting.
+	EventOverrideUpdated EventType = "override.updated"
+	// EventStreamFailover is published when a client had to be moved past the
+	// first candidate stream of a channel.
+	EventStreamFailover EventType = "stream.failover"
+	// EventSourceChanged is published when streams are added to or removed from an upstream source.
+	EventSourceChanged EventType = "source.changed"
+	// EventRecordingFinished is published when a recording ends, successfully or not.
+	EventRecordingFinished EventType = "recording.finished"
+	// EventClientStalled is published when writes to a streaming client have
+	// been slow several times in a row.
+	EventClientStalled EventType = "client.stalled"
+	// EventDatabaseRestored is published when the database is replaced with
+	// a backup.
+	EventDatabaseRestored EventType = "database.restored"
+)
+
+// eventBufferSize is how many events a subscriber may fall behind before
+// further events are dropped for it.
+const eventBufferSize = 64
+
+// Event is a live status update pushed to subscribers.
+type Event struct {
+	Type EventType
+	Time time.Time
+	Data any
+}
+
+// StreamEventData describes a stream started or stopped event.
+type StreamEventData struct {
+	InfoHash string `json:"infohash"`
+}
+
+// StreamPrebufferData describes the progress of a stream's prebuffer.
+// Percent is how close the larger of Bytes and BufferedMs is to its
+// threshold.
+type StreamPrebufferData struct {
+	InfoHash   string `json:"infohash"`
+	Phase      string `json:"phase"` // "buffering" or "ready"
+	Bytes      int64  `json:"bytes"`
+	BufferedMs int64  `json:"buffered_ms"`
+	Percent    int    `json:"percent"`
+}
+
+// EPGSyncProgress describes the state of a running EPG sync.
+// Processed counts the subscribed channels reached so far out of Total.
+type EPGSyncProgress struct {
+	Phase     string `json:"phase"` // "started", "channels", "completed" or "failed"
+	Processed int    `json:"processed"`
+	Total     int    `json:"total"`
+	Error     string `json:"error,omitempty"`
+}
+
+// EngineHealthData describes the current AceStream engine health.
+type EngineHealthData struct {
+	Status string `json:"status"` // "ok" or "error"
+	Error  string `json:"error,omitempty"`
+}
+
+// OverrideEventData describes a user change to a channel or subscription.
+type OverrideEventData struct {
+	Kind string `json:"kind"` // "channel", "subscription" or "group"
+	Name string `json:"name"`
+}
+
+// StreamFailoverData describes a stream failover event. InfoHash is the
+// stream the client ended up on, empty if every candidate failed.
+type StreamFailoverData struct {
+	Key      string `json:"key"`
+	InfoHash string `json:"infohash,omitempty"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ClientStalledData describes a client stalled event.
+type ClientStalledData struct {
+	SessionID       string `json:"session_id"`
+	ClientIP        string `json:"client_ip,omitempty"`
+	InfoHash        string `json:"infohash"`
+	ConsecutiveSlow int    `json:"consecutive_slow_writes"`
+	P95Ms           int64  `json:"p95_ms"`
+	Stalls          int64  `json:"stalls"`
+}
+
+// SourceChangeEventData describes the channels whose streams were added to
+// or removed from an upstream source.
+type SourceChangeEventData struct {
+	Source  string   `json:"source"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// RecordingEventData describes a finished recording.
+type RecordingEventData struct {
+	ID          string `json:"id"`
+	ChannelName string `json:"channel_name"`
+	Status      string `json:"status"`
+	Bytes       int64  `json:"bytes"`
+	Error       string `json:"error,omitempty"`
+}
+
+// EventBus fans out events to every subscriber. Publishing never blocks:
+// a subscriber that is not keeping up misses events instead of stalling
+// the publisher. A nil *EventBus discards every event.
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+	now         func() time.Time
+}
+
+// NewEventBus creates an event bus with no subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[chan Event]struct{}),
+		now:         time.Now,
+	}
+}
+
+// Subscribe registers a new subscriber. The channel is closed when the
+// returned unsubscribe function is called or the bus is closed; the
+// function must be called once the subscriber is done.
+func (b *EventBus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish sends an event of the given type to all current subscribers.
+func (b *EventBus) Publish(eventType EventType, data any) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := Event{Type: eventType, Time: b.now(), Data: data}
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Close disconnects all subscribers so long-lived event streams end and
+// the HTTP server can shut down. Later subscribers get a closed channel.
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// SubscriberCount returns the number of active subscribers.
+func (b *EventBus) SubscriberCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
@@ -0,0 +1,113 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestEventBus(t *testing.T) {
+	t.Run("delivers events to every subscriber", func(t *testing.T) {
+		bus := NewEventBus()
+		first, unsubscribeFirst := bus.Subscribe()
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := bus.Subscribe()
+		defer unsubscribeSecond()
+
+		bus.Publish(EventStreamStarted, StreamEventData{InfoHash: "abc"})
+
+		for _, ch := range []<-chan Event{first, second} {
+			event := <-ch
+			if event.Type != EventStreamStarted {
+				t.Errorf("expected %q, got %q", EventStreamStarted, event.Type)
+			}
+			if data, ok := event.Data.(StreamEventData); !ok || data.InfoHash != "abc" {
+				t.Errorf("unexpected event data %#v", event.Data)
+			}
+		}
+	})
+
+	t.Run("drops events for a subscriber that falls behind", func(t *testing.T) {
+		bus := NewEventBus()
+		events, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+
+		for i := 0; i < eventBufferSize+10; i++ {
+			bus.Publish(EventStreamStarted, nil)
+		}
+
+		if got := len(events); got != eventBufferSize {
+			t.Errorf("expected %d buffered events, got %d", eventBufferSize, got)
+		}
+	})
+
+	t.Run("unsubscribe closes the channel", func(t *testing.T) {
+		bus := NewEventBus()
+		events, unsubscribe := bus.Subscribe()
+
+		unsubscribe()
+		unsubscribe()
+
+		if _, ok := <-events; ok {
+			t.Error("expected channel to be closed")
+		}
+		if got := bus.SubscriberCount(); got != 0 {
+			t.Errorf("expected no subscribers, got %d", got)
+		}
+	})
+
+	t.Run("close ends all subscriptions", func(t *testing.T) {
+		bus := NewEventBus()
+		events, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+
+		bus.Close()
+
+		if _, ok := <-events; ok {
+			t.Error("expected channel to be closed")
+		}
+		late, _ := bus.Subscribe()
+		if _, ok := <-late; ok {
+			t.Error("expected subscription after close to be closed")
+		}
+	})
+
+	t.Run("nil bus discards events", func(t *testing.T) {
+		var bus *EventBus
+		bus.Publish(EventStreamStopped, nil)
+	})
+}
+
+func TestHealthService_WatchEngine(t *testing.T) {
+	var pingErr error
+	engine := &mockAceStreamEngine{
+		pingFunc: func(ctx context.Context) error { return pingErr },
+	}
+	bus := NewEventBus()
+	events, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	service := NewHealthService(&mockChannelRepository{}, engine)
+	service.SetEventBus(bus)
+
+	run := func() {
+		if err := service.WatchEngine(context.Background()); err != nil {
+			t.Fatalf("WatchEngine() error = %v", err)
+		}
+	}
+
+	run()
+	run()
+	pingErr = errors.New("connection refused")
+	run()
+
+	if got := len(events); got != 2 {
+		t.Fatalf("expected 2 events (initial status and change), got %d", got)
+	}
+	if data := (<-events).Data.(EngineHealthData); data.Status != "ok" {
+		t.Errorf("expected initial status ok, got %q", data.Status)
+	}
+	if data := (<-events).Data.(EngineHealthData); data.Status != "error" || data.Error != "connection refused" {
+		t.Errorf("expected error status, got %+v", data)
+	}
+}
@@ -0,0 +1,143 @@
+package application
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/favorite"
+	"github.com/alorle/iptv-manager/internal/playlist"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// FavoriteService provides use cases for managing favorite lists and
+// serving their playlists and guides.
+type FavoriteService struct {
+	favoriteRepo driven.FavoriteRepository
+	channelRepo  driven.ChannelRepository
+	playlist     *PlaylistService
+}
+
+// NewFavoriteService creates a new FavoriteService with the given
+// repositories, rendering playlists with the playlist service.
+func NewFavoriteService(favoriteRepo driven.FavoriteRepository, channelRepo driven.ChannelRepository, playlist *PlaylistService) *FavoriteService {
+	return &FavoriteService{
+		favoriteRepo: favoriteRepo,
+		channelRepo:  channelRepo,
+		playlist:     playlist,
+	}
+}
+
+// FavoriteUpdate holds the list fields to change; nil fields are left as is.
+type FavoriteUpdate struct {
+	Name     *string
+	Channels []string
+	Guide    *bool
+}
+
+// CreateList creates a list of the given channels, in order.
+// Returns favorite.ErrEmptyName or favorite.ErrInvalidName if the name is invalid.
+// Returns favorite.ErrEmptyChannelName or channel.ErrChannelNotFound if a
+// channel is invalid.
+// Returns favorite.ErrListAlreadyExists if a list with the same ID already exists.
+func (s *FavoriteService) CreateList(ctx context.Context, name string, channels []string, guide bool) (favorite.List, error) {
+	l, err := favorite.NewList(name)
+	if err != nil {
+		return favorite.List{}, err
+	}
+	if err := s.setChannels(ctx, &l, channels); err != nil {
+		return favorite.List{}, err
+	}
+	l.SetGuide(guide)
+
+	if err := s.favoriteRepo.Save(ctx, l); err != nil {
+		return favorite.List{}, err
+	}
+	return l, nil
+}
+
+// GetList retrieves a list by its ID.
+// Returns favorite.ErrListNotFound if the list does not exist.
+func (s *FavoriteService) GetList(ctx context.Context, id string) (favorite.List, error) {
+	return s.favoriteRepo.FindByID(ctx, id)
+}
+
+// ListLists retrieves all lists ordered by name.
+func (s *FavoriteService) ListLists(ctx context.Context) ([]favorite.List, error) {
+	return s.favoriteRepo.FindAll(ctx)
+}
+
+// UpdateList renames a list, replaces its channels, or enables or disables
+// its guide. A non-nil Channels replaces the channels, so an empty slice
+// clears them.
+// Returns favorite.ErrListNotFound if the list does not exist.
+// Returns favorite.ErrEmptyName if the new name is empty.
+// Returns favorite.ErrEmptyChannelName or channel.ErrChannelNotFound if a
+// channel is invalid.
+func (s *FavoriteService) UpdateList(ctx context.Context, id string, update FavoriteUpdate) (favorite.List, error) {
+	l, err := s.favoriteRepo.FindByID(ctx, id)
+	if err != nil {
+		return favorite.List{}, err
+	}
+
+	if update.Name != nil {
+		if err := l.Rename(*update.Name); err != nil {
+			return favorite.List{}, err
+		}
+	}
+	if update.Channels != nil {
+		if err := s.setChannels(ctx, &l, update.Channels); err != nil {
+			return favorite.List{}, err
+		}
+	}
+	if update.Guide != nil {
+		l.SetGuide(*update.Guide)
+	}
+
+	if err := s.favoriteRepo.Update(ctx, l); err != nil {
+		return favorite.List{}, err
+	}
+	return l, nil
+}
+
+// DeleteList deletes a list.
+// Returns favorite.ErrListNotFound if the list does not exist.
+func (s *FavoriteService) DeleteList(ctx context.Context, id string) error {
+	return s.favoriteRepo.Delete(ctx, id)
+}
+
+// Playlist renders the playlist of the list with the given ID in the given
+// format. Channels deleted since they were added are left out.
+// Returns favorite.ErrListNotFound if the list does not exist.
+func (s *FavoriteService) Playlist(ctx context.Context, id, baseURL string, format playlist.Format) ([]byte, error) {
+	l, err := s.favoriteRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.playlist.GenerateForFavorites(ctx, baseURL, format, l)
+}
+
+// Guide renders the XMLTV guide of the list with the given ID.
+// Returns favorite.ErrListNotFound if the list does not exist or has its
+// guide disabled.
+func (s *FavoriteService) Guide(ctx context.Context, id string) ([]byte, error) {
+	l, err := s.favoriteRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !l.HasGuide() {
+		return nil, favorite.ErrListNotFound
+	}
+	return s.playlist.GenerateXMLTVForFavorites(ctx, l)
+}
+
+// setChannels replaces the channels of l after checking they all exist.
+func (s *FavoriteService) setChannels(ctx context.Context, l *favorite.List, channels []string) error {
+	if err := l.SetChannels(channels); err != nil {
+		return err
+	}
+	for _, name := range l.Channels() {
+		if _, err := s.channelRepo.FindByName(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/favorite"
+	"github.com/alorle/iptv-manager/internal/playlist"
+	"github.com/alorle/iptv-manager/internal/rule"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// memFavoriteRepository is an in-memory driven.FavoriteRepository for testing.
+type memFavoriteRepository struct {
+	lists map[string]favorite.List
+}
+
+func newMemFavoriteRepository() *memFavoriteRepository {
+	return &memFavoriteRepository{lists: make(map[string]favorite.List)}
+}
+
+func (r *memFavoriteRepository) Save(ctx context.Context, l favorite.List) error {
+	if _, ok := r.lists[l.ID()]; ok {
+		return favorite.ErrListAlreadyExists
+	}
+	r.lists[l.ID()] = l
+	return nil
+}
+
+func (r *memFavoriteRepository) Update(ctx context.Context, l favorite.List) error {
+	if _, ok := r.lists[l.ID()]; !ok {
+		return favorite.ErrListNotFound
+	}
+	r.lists[l.ID()] = l
+	return nil
+}
+
+func (r *memFavoriteRepository) FindAll(ctx context.Context) ([]favorite.List, error) {
+	lists := make([]favorite.List, 0, len(r.lists))
+	for _, l := range r.lists {
+		lists = append(lists, l)
+	}
+	slices.SortFunc(lists, func(a, b favorite.List) int { return cmp.Compare(a.Name(), b.Name()) })
+	return lists, nil
+}
+
+func (r *memFavoriteRepository) FindByID(ctx context.Context, id string) (favorite.List, error) {
+	l, ok := r.lists[id]
+	if !ok {
+		return favorite.List{}, favorite.ErrListNotFound
+	}
+	return l, nil
+}
+
+func (r *memFavoriteRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := r.lists[id]; !ok {
+		return favorite.ErrListNotFound
+	}
+	delete(r.lists, id)
+	return nil
+}
+
+// newFavoriteTestService returns a FavoriteService over the channels Alpha,
+// Beta and Gamma, each with one stream; only Beta is EPG-mapped.
+func newFavoriteTestService(t *testing.T, rules ...rule.Rule) *FavoriteService {
+	t.Helper()
+
+	alpha, _ := channel.NewChannel("Alpha")
+	beta, _ := channel.NewChannel("Beta")
+	mapping, _ := channel.NewEPGMapping("beta.tv", channel.MappingManual, time.Now())
+	beta.SetEPGMapping(mapping)
+	gamma, _ := channel.NewChannel("Gamma")
+	channelRepo, _ := newMemChannelRepository(alpha, beta, gamma)
+	channelRepo.findAllFunc = func(ctx context.Context) ([]channel.Channel, error) {
+		return []channel.Channel{alpha, beta, gamma}, nil
+	}
+
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			a, _ := stream.NewStream(strings.Repeat("a", 40), "Alpha", "")
+			b, _ := stream.NewStream(strings.Repeat("b", 40), "Beta", "")
+			g, _ := stream.NewStream(strings.Repeat("c", 40), "Gamma", "")
+			return []stream.Stream{a, b, g}, nil
+		},
+	}
+
+	playlistService := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+	ruleRepo := &memRuleRepository{rules: make(map[string]rule.Rule)}
+	for _, r := range rules {
+		ruleRepo.rules[r.ID()] = r
+	}
+	playlistService.SetRuleRepository(ruleRepo)
+	return NewFavoriteService(newMemFavoriteRepository(), channelRepo, playlistService)
+}
+
+func TestFavoriteService_CreateList(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates a list of existing channels", func(t *testing.T) {
+		service := newFavoriteTestService(t)
+
+		l, err := service.CreateList(ctx, "Living room", []string{"Gamma", "Alpha"}, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if l.ID() != "living-room" || !slices.Equal(l.Channels(), []string{"Gamma", "Alpha"}) || !l.HasGuide() {
+			t.Errorf("unexpected list %q %v guide=%v", l.ID(), l.Channels(), l.HasGuide())
+		}
+		if _, err := service.CreateList(ctx, "Living Room", nil, false); !errors.Is(err, favorite.ErrListAlreadyExists) {
+			t.Errorf("expected ErrListAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("rejects unknown channels", func(t *testing.T) {
+		service := newFavoriteTestService(t)
+
+		if _, err := service.CreateList(ctx, "Kids", []string{"Alpha", "Missing"}, false); !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+		if _, err := service.GetList(ctx, "kids"); !errors.Is(err, favorite.ErrListNotFound) {
+			t.Errorf("expected the list not to be saved, got %v", err)
+		}
+	})
+}
+
+func TestFavoriteService_UpdateList(t *testing.T) {
+	ctx := context.Background()
+	service := newFavoriteTestService(t)
+	_, _ = service.CreateList(ctx, "Kids", []string{"Alpha"}, false)
+
+	name, guide := "Kids tablet", true
+	l, err := service.UpdateList(ctx, "kids", FavoriteUpdate{Name: &name, Channels: []string{"Beta", "Alpha"}, Guide: &guide})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l.ID() != "kids" || l.Name() != "Kids tablet" || !slices.Equal(l.Channels(), []string{"Beta", "Alpha"}) || !l.HasGuide() {
+		t.Errorf("unexpected list %q %q %v guide=%v", l.ID(), l.Name(), l.Channels(), l.HasGuide())
+	}
+
+	l, _ = service.UpdateList(ctx, "kids", FavoriteUpdate{Channels: []string{}})
+	if len(l.Channels()) != 0 {
+		t.Errorf("expected an empty channel list to clear the channels, got %v", l.Channels())
+	}
+	if _, err := service.UpdateList(ctx, "kids", FavoriteUpdate{Channels: []string{"Missing"}}); !errors.Is(err, channel.ErrChannelNotFound) {
+		t.Errorf("expected ErrChannelNotFound, got %v", err)
+	}
+	if _, err := service.UpdateList(ctx, "missing", FavoriteUpdate{}); !errors.Is(err, favorite.ErrListNotFound) {
+		t.Errorf("expected ErrListNotFound, got %v", err)
+	}
+}
+
+func TestFavoriteService_Playlist(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("lists the channels in the order of the list", func(t *testing.T) {
+		rename, _ := rule.NewRule(rule.Match{NamePattern: "^Gamma$"}, rule.Action{Rename: "Gamma HD"}, time.Now())
+		service := newFavoriteTestService(t, rename)
+		_, _ = service.CreateList(ctx, "Living room", []string{"Gamma", "Alpha"}, false)
+
+		data, err := service.Playlist(ctx, "living-room", "http://localhost:8080", playlist.JSON)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out := string(data)
+		gamma, alpha := strings.Index(out, "Gamma HD"), strings.Index(out, "Alpha")
+		if gamma < 0 || alpha < 0 || gamma > alpha {
+			t.Errorf("expected Gamma HD before Alpha, got %s", out)
+		}
+		if strings.Contains(out, "Beta") {
+			t.Errorf("expected Beta to be left out, got %s", out)
+		}
+		if !strings.Contains(out, "http://localhost:8080/epg.xml") {
+			t.Errorf("expected the full guide without a list guide, got %s", out)
+		}
+	})
+
+	t.Run("points players at the list's guide", func(t *testing.T) {
+		service := newFavoriteTestService(t)
+		_, _ = service.CreateList(ctx, "Kids", []string{"Beta"}, true)
+
+		data, err := service.Playlist(ctx, "kids", "http://localhost:8080", playlist.M3U)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(string(data), `url-tvg="http://localhost:8080/playlist/fav/kids.xml"`) {
+			t.Errorf("expected the list's guide URL, got %s", data)
+		}
+	})
+
+	t.Run("returns ErrListNotFound for an unknown list", func(t *testing.T) {
+		service := newFavoriteTestService(t)
+		if _, err := service.Playlist(ctx, "missing", "http://localhost:8080", playlist.M3U); !errors.Is(err, favorite.ErrListNotFound) {
+			t.Errorf("expected ErrListNotFound, got %v", err)
+		}
+	})
+}
+
+func TestFavoriteService_Guide(t *testing.T) {
+	ctx := context.Background()
+	service := newFavoriteTestService(t)
+	_, _ = service.CreateList(ctx, "Kids", []string{"Alpha", "Beta"}, true)
+	_, _ = service.CreateList(ctx, "Empty", nil, true)
+	_, _ = service.CreateList(ctx, "No guide", []string{"Beta"}, false)
+
+	data, err := service.Guide(ctx, "kids")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `<channel id="beta.tv">`) {
+		t.Errorf("expected the mapped channel of the list, got %s", data)
+	}
+
+	data, _ = service.Guide(ctx, "empty")
+	if strings.Contains(string(data), "beta.tv") {
+		t.Errorf("expected channels outside the list to be left out, got %s", data)
+	}
+
+	if _, err := service.Guide(ctx, "no-guide"); !errors.Is(err, favorite.ErrListNotFound) {
+		t.Errorf("expected ErrListNotFound for a list without a guide, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package application
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// ErrChannelNotInGroup indicates a channel is not assigned to the given group.
+var ErrChannelNotInGroup = errors.New("channel not in group")
+
+// GroupService provides use cases for managing channel groups and the
+// assignment of channels to them.
+type GroupService struct {
+	groupRepo   driven.GroupRepository
+	channelRepo driven.ChannelRepository
+	events      *EventBus
+}
+
+// NewGroupService creates a new GroupService with the given repositories.
+func NewGroupService(groupRepo driven.GroupRepository, channelRepo driven.ChannelRepository) *GroupService {
+	return &GroupService{
+		groupRepo:   groupRepo,
+		channelRepo: channelRepo,
+	}
+}
+
+// SetEventBus enables publishing EventOverrideUpdated when groups or
+// channel assignments change.
+func (s *GroupService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// GroupUpdate holds the group fields to change; nil fields are left as is.
+type GroupUpdate struct {
+	Name     *string
+	Enabled  *bool
+	Position *int
+}
+
+// CreateGroup creates an enabled group listed after all existing groups.
+// Returns group.ErrEmptyName or group.ErrInvalidName if the name is invalid.
+// Returns group.ErrGroupAlreadyExists if a group with the same ID already exists.
+func (s *GroupService) CreateGroup(ctx context.Context, name string) (group.Group, error) {
+	g, err := group.NewGroup(name)
+	if err != nil {
+		return group.Group{}, err
+	}
+
+	existing, err := s.groupRepo.FindAll(ctx)
+	if err != nil {
+		return group.Group{}, err
+	}
+	for _, e := range existing {
+		if e.Position() >= g.Position() {
+			g.SetPosition(e.Position() + 1)
+		}
+	}
+
+	if err := s.groupRepo.Save(ctx, g); err != nil {
+		return group.Group{}, err
+	}
+
+	s.publish(g.ID())
+	return g, nil
+}
+
+// GetGroup retrieves a group by its ID.
+// Returns group.ErrGroupNotFound if the group does not exist.
+func (s *GroupService) GetGroup(ctx context.Context, id string) (group.Group, error) {
+	return s.groupRepo.FindByID(ctx, id)
+}
+
+// ListGroups retrieves all groups ordered by position.
+func (s *GroupService) ListGroups(ctx context.Context) ([]group.Group, error) {
+	return s.groupRepo.FindAll(ctx)
+}
+
+// UpdateGroup renames, enables or disables, or moves a group.
+// Returns group.ErrGroupNotFound if the group does not exist.
+// Returns group.ErrEmptyName if the new name is empty.
+func (s *GroupService) UpdateGroup(ctx context.Context, id string, update GroupUpdate) (group.Group, error) {
+	g, err := s.groupRepo.FindByID(ctx, id)
+	if err != nil {
+		return group.Group{}, err
+	}
+
+	if update.Name != nil {
+		if err := g.Rename(*update.Name); err != nil {
+			return group.Group{}, err
+		}
+	}
+	if update.Enabled != nil {
+		g.SetEnabled(*update.Enabled)
+	}
+	if update.Position != nil {
+		g.SetPosition(*update.Position)
+	}
+
+	if err := s.groupRepo.Update(ctx, g); err != nil {
+		return group.Group{}, err
+	}
+
+	s.publish(g.ID())
+	return g, nil
+}
+
+// ReorderGroups lists the groups with the given IDs first, in that order,
+// followed by any remaining groups in their current order. Positions are
+// renumbered from zero.
+// Returns group.ErrGroupNotFound if any ID does not exist; no group is
+// changed in that case.
+func (s *GroupService) ReorderGroups(ctx context.Context, ids []string) ([]group.Group, error) {
+	groups, err := s.groupRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]group.Group, len(groups))
+	for _, g := range groups {
+		byID[g.ID()] = g
+	}
+
+	ordered := make([]group.Group, 0, len(groups))
+	placed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		g, ok := byID[id]
+		if !ok {
+			return nil, group.ErrGroupNotFound
+		}
+		if placed[id] {
+			continue
+		}
+		placed[id] = true
+		ordered = append(ordered, g)
+	}
+	for _, g := range groups {
+		if !placed[g.ID()] {
+			ordered = append(ordered, g)
+		}
+	}
+
+	for i := range ordered {
+		if ordered[i].Position() == i {
+			continue
+		}
+		ordered[i].SetPosition(i)
+		if err := s.groupRepo.Update(ctx, ordered[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	s.publish("")
+	return ordered, nil
+}
+
+// DeleteGroup removes a group. Its channels are left ungrouped.
+// Returns group.ErrGroupNotFound if the group does not exist.
+func (s *GroupService) DeleteGroup(ctx context.Context, id string) error {
+	if _, err := s.groupRepo.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	channels, err := s.channelRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ch := range channels {
+		if ch.Group() != id {
+			continue
+		}
+		ch.SetGroup("")
+		if err := s.channelRepo.Update(ctx, ch); err != nil {
+			return err
+		}
+	}
+
+	if err := s.groupRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.publish(id)
+	return nil
+}
+
+// AssignChannel moves a channel into the group with the given ID, replacing
+// any previous assignment.
+// Returns group.ErrGroupNotFound if the group does not exist.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+func (s *GroupService) AssignChannel(ctx context.Context, groupID, channelName string) (channel.Channel, error) {
+	if _, err := s.groupRepo.FindByID(ctx, groupID); err != nil {
+		return channel.Channel{}, err
+	}
+
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+
+	ch.SetGroup(groupID)
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return channel.Channel{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+	return ch, nil
+}
+
+// UnassignChannel removes a channel from the group with the given ID.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+// Returns ErrChannelNotInGroup if the channel is not assigned to that group.
+func (s *GroupService) UnassignChannel(ctx context.Context, groupID, channelName string) error {
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return err
+	}
+	if ch.Group() != groupID {
+		return ErrChannelNotInGroup
+	}
+
+	ch.SetGroup("")
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: channelName})
+	return nil
+}
+
+// publish announces a change to the group with the given ID, or to the
+// group order if id is empty.
+func (s *GroupService) publish(id string) {
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "group", Name: id})
+}
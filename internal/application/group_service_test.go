@@ -0,0 +1,240 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+)
+
+// memGroupRepository is an in-memory driven.GroupRepository for testing.
+type memGroupRepository struct {
+	groups map[string]group.Group
+}
+
+func newMemGroupRepository(groups ...group.Group) *memGroupRepository {
+	r := &memGroupRepository{groups: make(map[string]group.Group)}
+	for _, g := range groups {
+		r.groups[g.ID()] = g
+	}
+	return r
+}
+
+func (r *memGroupRepository) Save(ctx context.Context, g group.Group) error {
+	if _, ok := r.groups[g.ID()]; ok {
+		return group.ErrGroupAlreadyExists
+	}
+	r.groups[g.ID()] = g
+	return nil
+}
+
+func (r *memGroupRepository) Update(ctx context.Context, g group.Group) error {
+	if _, ok := r.groups[g.ID()]; !ok {
+		return group.ErrGroupNotFound
+	}
+	r.groups[g.ID()] = g
+	return nil
+}
+
+func (r *memGroupRepository) FindAll(ctx context.Context) ([]group.Group, error) {
+	groups := make([]group.Group, 0, len(r.groups))
+	for _, g := range r.groups {
+		groups = append(groups, g)
+	}
+	group.Sort(groups)
+	return groups, nil
+}
+
+func (r *memGroupRepository) FindByID(ctx context.Context, id string) (group.Group, error) {
+	g, ok := r.groups[id]
+	if !ok {
+		return group.Group{}, group.ErrGroupNotFound
+	}
+	return g, nil
+}
+
+func (r *memGroupRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := r.groups[id]; !ok {
+		return group.ErrGroupNotFound
+	}
+	delete(r.groups, id)
+	return nil
+}
+
+// newMemChannelRepository returns a mockChannelRepository backed by a map.
+func newMemChannelRepository(channels ...channel.Channel) (*mockChannelRepository, map[string]channel.Channel) {
+	byName := make(map[string]channel.Channel)
+	for _, ch := range channels {
+		byName[ch.Name()] = ch
+	}
+	repo := &mockChannelRepository{
+		updateFunc: func(ctx context.Context, ch channel.Channel) error {
+			byName[ch.Name()] = ch
+			return nil
+		},
+		findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+			ch, ok := byName[name]
+			if !ok {
+				return channel.Channel{}, channel.ErrChannelNotFound
+			}
+			return ch, nil
+		},
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			all := make([]channel.Channel, 0, len(byName))
+			for _, ch := range byName {
+				all = append(all, ch)
+			}
+			return all, nil
+		},
+	}
+	return repo, byName
+}
+
+func groupIDs(groups []group.Group) []string {
+	ids := make([]string, len(groups))
+	for i, g := range groups {
+		ids[i] = g.ID()
+	}
+	return ids
+}
+
+func TestGroupService_CreateGroup(t *testing.T) {
+	ctx := context.Background()
+	channelRepo, _ := newMemChannelRepository()
+	service := NewGroupService(newMemGroupRepository(), channelRepo)
+
+	sports, err := service.CreateGroup(ctx, "Sports")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	news, err := service.CreateGroup(ctx, "News")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if sports.Position() != 0 || news.Position() != 1 {
+		t.Errorf("expected new groups to be appended, got positions %d and %d", sports.Position(), news.Position())
+	}
+	if !news.IsEnabled() {
+		t.Error("expected new group to be enabled")
+	}
+
+	if _, err := service.CreateGroup(ctx, "sports"); !errors.Is(err, group.ErrGroupAlreadyExists) {
+		t.Errorf("expected ErrGroupAlreadyExists, got %v", err)
+	}
+	if _, err := service.CreateGroup(ctx, "  "); !errors.Is(err, group.ErrEmptyName) {
+		t.Errorf("expected ErrEmptyName, got %v", err)
+	}
+}
+
+func TestGroupService_UpdateGroup(t *testing.T) {
+	ctx := context.Background()
+	g, _ := group.NewGroup("Sports")
+	channelRepo, _ := newMemChannelRepository()
+	service := NewGroupService(newMemGroupRepository(g), channelRepo)
+
+	name := "Sport"
+	enabled := false
+	updated, err := service.UpdateGroup(ctx, "sports", GroupUpdate{Name: &name, Enabled: &enabled})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if updated.ID() != "sports" || updated.Name() != "Sport" || updated.IsEnabled() {
+		t.Errorf("unexpected group after update: %+v", updated)
+	}
+
+	if _, err := service.UpdateGroup(ctx, "missing", GroupUpdate{}); !errors.Is(err, group.ErrGroupNotFound) {
+		t.Errorf("expected ErrGroupNotFound, got %v", err)
+	}
+}
+
+func TestGroupService_ReorderGroups(t *testing.T) {
+	ctx := context.Background()
+	a := group.ReconstructGroup("a", "A", 0, true)
+	b := group.ReconstructGroup("b", "B", 1, true)
+	c := group.ReconstructGroup("c", "C", 2, true)
+	groupRepo := newMemGroupRepository(a, b, c)
+	channelRepo, _ := newMemChannelRepository()
+	service := NewGroupService(groupRepo, channelRepo)
+
+	t.Run("lists given groups first and renumbers", func(t *testing.T) {
+		ordered, err := service.ReorderGroups(ctx, []string{"c", "a"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got := groupIDs(ordered)
+		if len(got) != 3 || got[0] != "c" || got[1] != "a" || got[2] != "b" {
+			t.Errorf("expected order [c a b], got %v", got)
+		}
+
+		stored, _ := groupRepo.FindAll(ctx)
+		for i, g := range stored {
+			if g.Position() != i {
+				t.Errorf("expected %s at position %d, got %d", g.ID(), i, g.Position())
+			}
+		}
+	})
+
+	t.Run("rejects unknown group IDs", func(t *testing.T) {
+		if _, err := service.ReorderGroups(ctx, []string{"a", "missing"}); !errors.Is(err, group.ErrGroupNotFound) {
+			t.Errorf("expected ErrGroupNotFound, got %v", err)
+		}
+	})
+}
+
+func TestGroupService_ChannelAssignment(t *testing.T) {
+	ctx := context.Background()
+	g, _ := group.NewGroup("Sports")
+	ch, _ := channel.NewChannel("DAZN 1")
+	channelRepo, channels := newMemChannelRepository(ch)
+	service := NewGroupService(newMemGroupRepository(g), channelRepo)
+
+	t.Run("assigns a channel to a group", func(t *testing.T) {
+		assigned, err := service.AssignChannel(ctx, "sports", "DAZN 1")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if assigned.Group() != "sports" || channels["DAZN 1"].Group() != "sports" {
+			t.Errorf("expected channel to be stored in group sports, got %q", channels["DAZN 1"].Group())
+		}
+	})
+
+	t.Run("rejects unknown groups and channels", func(t *testing.T) {
+		if _, err := service.AssignChannel(ctx, "missing", "DAZN 1"); !errors.Is(err, group.ErrGroupNotFound) {
+			t.Errorf("expected ErrGroupNotFound, got %v", err)
+		}
+		if _, err := service.AssignChannel(ctx, "sports", "Missing"); !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+	})
+
+	t.Run("unassigns a channel only from its own group", func(t *testing.T) {
+		if err := service.UnassignChannel(ctx, "news", "DAZN 1"); !errors.Is(err, ErrChannelNotInGroup) {
+			t.Errorf("expected ErrChannelNotInGroup, got %v", err)
+		}
+		if err := service.UnassignChannel(ctx, "sports", "DAZN 1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if channels["DAZN 1"].Group() != "" {
+			t.Errorf("expected channel to be ungrouped, got %q", channels["DAZN 1"].Group())
+		}
+	})
+
+	t.Run("deleting a group ungroups its channels", func(t *testing.T) {
+		if _, err := service.AssignChannel(ctx, "sports", "DAZN 1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := service.DeleteGroup(ctx, "sports"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if channels["DAZN 1"].Group() != "" {
+			t.Errorf("expected channel to be ungrouped, got %q", channels["DAZN 1"].Group())
+		}
+		if _, err := service.GetGroup(ctx, "sports"); !errors.Is(err, group.ErrGroupNotFound) {
+			t.Errorf("expected ErrGroupNotFound, got %v", err)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package application
 
 import (
 	"context"
+	"sync"
 
 	"github.com/alorle/iptv-manager/internal/port/driven"
 )
@@ -10,6 +11,10 @@ import (
 type HealthService struct {
 	db     driven.ChannelRepository
 	engine driven.AceStreamEngine
+	events *EventBus
+
+	mu           sync.Mutex
+	engineStatus string // last status seen by WatchEngine, empty before the first check
 }
 
 // NewHealthService creates a new health check service.
@@ -68,3 +73,28 @@ func (s *HealthService) Check(ctx context.Context) HealthStatus {
 
 	return status
 }
+
+// SetEventBus enables publishing EventEngineHealth from WatchEngine.
+func (s *HealthService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// WatchEngine pings the AceStream engine and publishes EventEngineHealth
+// when its status differs from the previous check. The first check always
+// publishes. It is meant to be run by a scheduler and never fails.
+func (s *HealthService) WatchEngine(ctx context.Context) error {
+	health := ComponentHealth{Status: "ok"}
+	if err := s.engine.Ping(ctx); err != nil {
+		health = ComponentHealth{Status: "error", Error: err.Error()}
+	}
+
+	s.mu.Lock()
+	changed := s.engineStatus != health.Status
+	s.engineStatus = health.Status
+	s.mu.Unlock()
+
+	if changed {
+		s.events.Publish(EventEngineHealth, EngineHealthData{Status: health.Status, Error: health.Error})
+	}
+	return nil
+}
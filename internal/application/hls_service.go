@@ -0,0 +1,217 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming/hls"
+)
+
+var (
+	// ErrHLSSegmentNotFound is returned when a segment is unknown or has
+	// already left the retention window.
+	ErrHLSSegmentNotFound = errors.New("hls segment not found")
+	// ErrHLSNotReady is returned when no segment became available in time.
+	ErrHLSNotReady = errors.New("hls stream not ready")
+)
+
+// HLSConfig tunes the HLS remux mode.
+type HLSConfig struct {
+	// SegmentDuration is the target length of each segment.
+	SegmentDuration time.Duration
+	// Retention is how many completed segments are kept per stream.
+	Retention int
+	// IdleTimeout stops a remux session when no client has requested its
+	// playlist or segments for this long.
+	IdleTimeout time.Duration
+	// ReadyTimeout bounds how long a playlist request waits for the first
+	// segment of a new session.
+	ReadyTimeout time.Duration
+}
+
+// HLSService remuxes proxied streams into HLS. Each infohash gets one remux
+// session that subscribes to the proxy like any other client, so HLS and
+// progressive clients share the same engine stream.
+type HLSService struct {
+	proxy  *AceStreamProxyService
+	config HLSConfig
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	sessions map[string]*hlsSession
+}
+
+// hlsSession is a single remux of one infohash.
+type hlsSession struct {
+	segmenter *hls.Segmenter
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	mu         sync.Mutex
+	lastAccess time.Time
+}
+
+func (s *hlsSession) touch() {
+	s.mu.Lock()
+	s.lastAccess = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *hlsSession) idleFor() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Since(s.lastAccess)
+}
+
+// NewHLSService creates a new HLS remux service on top of the proxy.
+func NewHLSService(proxy *AceStreamProxyService, config HLSConfig, logger *slog.Logger) *HLSService {
+	return &HLSService{
+		proxy:    proxy,
+		config:   config,
+		logger:   logger,
+		sessions: make(map[string]*hlsSession),
+	}
+}
+
+// Playlist returns the media playlist for the given infohash, starting a
+// remux session if none is running. segmentURI maps a segment sequence number
+// to the URI clients should fetch it from.
+func (s *HLSService) Playlist(ctx context.Context, infoHash string, segmentURI func(seq uint64) string) (string, error) {
+	if infoHash == "" {
+		return "", ErrInvalidInfoHash
+	}
+
+	session := s.getOrStart(infoHash)
+	session.touch()
+
+	readyCtx, cancel := context.WithTimeout(ctx, s.config.ReadyTimeout)
+	defer cancel()
+	select {
+	case <-session.segmenter.Ready():
+	case <-readyCtx.Done():
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", ErrHLSNotReady
+	}
+
+	if session.segmenter.SegmentCount() == 0 {
+		// The stream ended before producing any data; drop the session so the
+		// next request starts afresh.
+		s.remove(infoHash, session)
+		if err := session.segmenter.Err(); err != nil {
+			return "", err
+		}
+		return "", ErrHLSNotReady
+	}
+
+	return session.segmenter.Playlist(segmentURI), nil
+}
+
+// Segment returns the data of a retained segment.
+func (s *HLSService) Segment(infoHash string, seq uint64) ([]byte, error) {
+	s.mu.Lock()
+	session, ok := s.sessions[infoHash]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrHLSSegmentNotFound
+	}
+	session.touch()
+
+	seg, ok := session.segmenter.Segment(seq)
+	if !ok {
+		return nil, ErrHLSSegmentNotFound
+	}
+	return seg.Data, nil
+}
+
+// Close stops all remux sessions.
+func (s *HLSService) Close() {
+	s.mu.Lock()
+	sessions := make([]*hlsSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.sessions = make(map[string]*hlsSession)
+	s.mu.Unlock()
+
+	for _, session := range sessions {
+		session.cancel()
+		<-session.done
+	}
+}
+
+func (s *HLSService) getOrStart(infoHash string) *hlsSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if session, ok := s.sessions[infoHash]; ok {
+		return session
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session := &hlsSession{
+		segmenter:  hls.New(s.config.SegmentDuration, s.config.Retention),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+		lastAccess: time.Now(),
+	}
+	s.sessions[infoHash] = session
+
+	s.logger.Info("starting hls session", "infohash", infoHash)
+	go s.run(ctx, infoHash, session)
+	go s.reapWhenIdle(ctx, infoHash, session)
+
+	return session
+}
+
+// run feeds the proxied stream into the session's segmenter until the stream
+// ends or the session is cancelled.
+func (s *HLSService) run(ctx context.Context, infoHash string, session *hlsSession) {
+	defer close(session.done)
+
+	err := s.proxy.StreamToClient(ctx, infoHash, session.segmenter)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Warn("hls session ended with error", "infohash", infoHash, "error", err)
+		session.segmenter.CloseWithError(err)
+		return
+	}
+	s.logger.Info("hls session ended", "infohash", infoHash)
+	session.segmenter.Close()
+}
+
+// reapWhenIdle stops the session once it has not been accessed for the idle
+// timeout. Ended sessions are kept until then so clients can drain the last
+// segments.
+func (s *HLSService) reapWhenIdle(ctx context.Context, infoHash string, session *hlsSession) {
+	interval := max(s.config.IdleTimeout/4, 10*time.Millisecond)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if session.idleFor() >= s.config.IdleTimeout {
+				s.logger.Info("stopping idle hls session", "infohash", infoHash)
+				s.remove(infoHash, session)
+				return
+			}
+		}
+	}
+}
+
+// remove cancels the session and forgets it if it is still the registered
+// session for infoHash.
+func (s *HLSService) remove(infoHash string, session *hlsSession) {
+	s.mu.Lock()
+	if s.sessions[infoHash] == session {
+		delete(s.sessions, infoHash)
+	}
+	s.mu.Unlock()
+	session.cancel()
+}
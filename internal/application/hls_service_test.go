@@ -0,0 +1,137 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
+)
+
+// tsPacket returns a TS packet flagged as a random access point.
+func tsPacket() []byte {
+	pkt := make([]byte, mpegts.PacketSize)
+	pkt[0] = 0x47
+	pkt[3] = 0x30
+	pkt[4] = 1
+	pkt[5] = 0x40
+	return pkt
+}
+
+func newTestHLSService(engine *mockAceStreamEngine, idle time.Duration) *HLSService {
+	proxy := NewAceStreamProxyService(engine, newTestLogger(), 10*time.Second, nil)
+	return NewHLSService(proxy, HLSConfig{
+		SegmentDuration: time.Millisecond,
+		Retention:       3,
+		IdleTimeout:     idle,
+		ReadyTimeout:    2 * time.Second,
+	}, newTestLogger())
+}
+
+func TestHLSService_Playlist(t *testing.T) {
+	t.Run("serves playlist and segments from the proxied stream", func(t *testing.T) {
+		engine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "http://engine/stream", nil
+			},
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				for {
+					if _, err := dst.Write(tsPacket()); err != nil {
+						return err
+					}
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(2 * time.Millisecond):
+					}
+				}
+			},
+		}
+		service := newTestHLSService(engine, time.Minute)
+		defer service.Close()
+
+		playlist, err := service.Playlist(context.Background(), "abc123", func(seq uint64) string {
+			return "seg-" + strconv.FormatUint(seq, 10) + ".ts"
+		})
+		if err != nil {
+			t.Fatalf("Playlist() error = %v", err)
+		}
+		if !strings.HasPrefix(playlist, "#EXTM3U\n") {
+			t.Errorf("expected an M3U8 playlist, got:\n%s", playlist)
+		}
+		if !strings.Contains(playlist, "seg-0.ts") {
+			t.Errorf("expected first segment in playlist, got:\n%s", playlist)
+		}
+
+		data, err := service.Segment("abc123", 0)
+		if err != nil {
+			t.Fatalf("Segment() error = %v", err)
+		}
+		if len(data)%mpegts.PacketSize != 0 || len(data) == 0 {
+			t.Errorf("expected whole TS packets, got %d bytes", len(data))
+		}
+	})
+
+	t.Run("returns engine error when stream cannot start", func(t *testing.T) {
+		engine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "", errors.New("engine down")
+			},
+		}
+		service := newTestHLSService(engine, time.Minute)
+		defer service.Close()
+
+		_, err := service.Playlist(context.Background(), "abc123", func(uint64) string { return "" })
+		if err == nil {
+			t.Fatal("expected error when the engine fails")
+		}
+		if _, err := service.Segment("abc123", 0); !errors.Is(err, ErrHLSSegmentNotFound) {
+			t.Errorf("expected failed session to be dropped, got %v", err)
+		}
+	})
+
+	t.Run("rejects empty infohash", func(t *testing.T) {
+		service := newTestHLSService(&mockAceStreamEngine{}, time.Minute)
+		if _, err := service.Playlist(context.Background(), "", func(uint64) string { return "" }); !errors.Is(err, ErrInvalidInfoHash) {
+			t.Errorf("expected ErrInvalidInfoHash, got %v", err)
+		}
+	})
+}
+
+func TestHLSService_IdleSessionsAreStopped(t *testing.T) {
+	stopped := make(chan struct{})
+	engine := &mockAceStreamEngine{
+		startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+			return "http://engine/stream", nil
+		},
+		streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+			_, _ = dst.Write(tsPacket())
+			time.Sleep(2 * time.Millisecond)
+			_, _ = dst.Write(tsPacket())
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		stopStreamFunc: func(ctx context.Context, pid string) error {
+			close(stopped)
+			return nil
+		},
+	}
+	service := newTestHLSService(engine, 50*time.Millisecond)
+
+	if _, err := service.Playlist(context.Background(), "abc123", func(uint64) string { return "" }); err != nil {
+		t.Fatalf("Playlist() error = %v", err)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected idle session to stop the engine stream")
+	}
+	if _, err := service.Segment("abc123", 0); !errors.Is(err, ErrHLSSegmentNotFound) {
+		t.Errorf("expected idle session to be removed, got %v", err)
+	}
+}
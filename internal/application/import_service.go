@@ -0,0 +1,217 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/acesearch"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/m3u"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/stream"
+	"github.com/alorle/iptv-manager/internal/tvheadend"
+)
+
+// ErrPlaylistUnavailable indicates a remote playlist could not be downloaded.
+var ErrPlaylistUnavailable = errors.New("playlist unavailable")
+
+// ImportSummary reports what an import changed.
+type ImportSummary struct {
+	// ChannelsCreated counts channels that did not exist before the import.
+	ChannelsCreated int
+	// ChannelsUpdated counts existing channels that gained at least one stream.
+	ChannelsUpdated int
+	// StreamsCreated counts new streams.
+	StreamsCreated int
+	// StreamsSkipped counts entries that were not imported: non-acestream
+	// URLs, entries without a name, and infohashes that already exist.
+	StreamsSkipped int
+	// DryRun is set when nothing was saved and the counts report what the
+	// import would have changed.
+	DryRun bool
+}
+
+// ImportFormat is a format channels and streams can be imported from.
+type ImportFormat string
+
+const (
+	// ImportFormatM3U is an extended M3U playlist.
+	ImportFormatM3U ImportFormat = "m3u"
+	// ImportFormatAcestreamSearch is the JSON output of acestream-search
+	// tools.
+	ImportFormatAcestreamSearch ImportFormat = "acestream-search"
+	// ImportFormatTvheadend is a Tvheadend IPTV network or mux export.
+	ImportFormatTvheadend ImportFormat = "tvheadend"
+)
+
+// ErrUnknownImportFormat indicates an import format that is not supported.
+var ErrUnknownImportFormat = errors.New("unknown import format")
+
+// ImportService bootstraps channels and streams from playlists produced by
+// other acestream tools.
+type ImportService struct {
+	channelRepo driven.ChannelRepository
+	streamRepo  driven.StreamRepository
+	fetcher     driven.PlaylistFetcher
+}
+
+// NewImportService creates a new ImportService.
+func NewImportService(channelRepo driven.ChannelRepository, streamRepo driven.StreamRepository, fetcher driven.PlaylistFetcher) *ImportService {
+	return &ImportService{
+		channelRepo: channelRepo,
+		streamRepo:  streamRepo,
+		fetcher:     fetcher,
+	}
+}
+
+// ImportM3UFromURL downloads the playlist at url and imports it.
+// Returns ErrPlaylistUnavailable, wrapping the cause, if the download fails.
+func (s *ImportService) ImportM3UFromURL(ctx context.Context, url string) (ImportSummary, error) {
+	return s.ImportFromURL(ctx, ImportFormatM3U, url, false)
+}
+
+// ImportFromURL downloads the file at url and imports it in the given
+// format, as Import does.
+// Returns ErrPlaylistUnavailable, wrapping the cause, if the download fails.
+func (s *ImportService) ImportFromURL(ctx context.Context, format ImportFormat, url string, dryRun bool) (ImportSummary, error) {
+	data, err := s.fetcher.FetchPlaylist(ctx, url)
+	if err != nil {
+		return ImportSummary{}, fmt.Errorf("%w: %w", ErrPlaylistUnavailable, err)
+	}
+	return s.Import(ctx, format, bytes.NewReader(data), dryRun)
+}
+
+// ImportM3U creates a channel for every named acestream entry of an M3U
+// playlist and a stream for every infohash not already known. Channels are
+// named after the entry's display name; new channels with a tvg-id get a
+// manual EPG mapping to it. Existing streams are never moved between channels.
+// Returns m3u.ErrNotPlaylist if r is not an M3U playlist.
+func (s *ImportService) ImportM3U(ctx context.Context, r io.Reader) (ImportSummary, error) {
+	return s.Import(ctx, ImportFormatM3U, r, false)
+}
+
+// Import imports channels and streams from r in the given format, as
+// ImportM3U does for playlists: acestream-search results and Tvheadend IPTV
+// muxes become entries named after their channel or service. With dryRun
+// nothing is saved, and the summary reports what the import would change.
+// Returns ErrUnknownImportFormat for an unsupported format, and
+// m3u.ErrNotPlaylist, acesearch.ErrInvalidOutput or
+// tvheadend.ErrInvalidExport if r is not in the format given.
+func (s *ImportService) Import(ctx context.Context, format ImportFormat, r io.Reader, dryRun bool) (ImportSummary, error) {
+	var parse func(io.Reader) ([]m3u.Entry, error)
+	switch format {
+	case ImportFormatM3U:
+		parse = m3u.Parse
+	case ImportFormatAcestreamSearch:
+		parse = acesearch.Parse
+	case ImportFormatTvheadend:
+		parse = tvheadend.Parse
+	default:
+		return ImportSummary{}, ErrUnknownImportFormat
+	}
+
+	entries, err := parse(r)
+	if err != nil {
+		return ImportSummary{}, err
+	}
+	return s.importEntries(ctx, entries, dryRun)
+}
+
+// importEntries imports the named acestream entries as Import describes.
+func (s *ImportService) importEntries(ctx context.Context, entries []m3u.Entry, dryRun bool) (ImportSummary, error) {
+	summary := ImportSummary{DryRun: dryRun}
+	created := make(map[string]bool)
+	updated := make(map[string]bool)
+	// A dry run saves nothing, so streams listed twice are caught here
+	seen := make(map[string]bool)
+
+	for _, entry := range entries {
+		infoHash := entry.InfoHash()
+		name := entry.ChannelName()
+		if infoHash == "" || name == "" || seen[infoHash] {
+			summary.StreamsSkipped++
+			continue
+		}
+
+		_, err := s.streamRepo.FindByInfoHash(ctx, infoHash)
+		if err == nil {
+			summary.StreamsSkipped++
+			continue
+		}
+		if !errors.Is(err, stream.ErrStreamNotFound) {
+			return summary, fmt.Errorf("failed to look up stream %s: %w", infoHash, err)
+		}
+
+		st, err := stream.NewStream(infoHash, name, stream.SourceImport)
+		if err != nil {
+			summary.StreamsSkipped++
+			continue
+		}
+		seen[infoHash] = true
+
+		isNew, err := s.ensureChannel(ctx, name, entry.TVGID, dryRun)
+		if err != nil {
+			return summary, err
+		}
+
+		if !dryRun {
+			if err := s.streamRepo.Save(ctx, st); err != nil {
+				if errors.Is(err, stream.ErrStreamAlreadyExists) {
+					summary.StreamsSkipped++
+					continue
+				}
+				return summary, fmt.Errorf("failed to save stream %s: %w", infoHash, err)
+			}
+		}
+		summary.StreamsCreated++
+
+		switch {
+		case isNew:
+			created[st.ChannelName()] = true
+		case !created[st.ChannelName()]:
+			updated[st.ChannelName()] = true
+		}
+	}
+
+	summary.ChannelsCreated = len(created)
+	summary.ChannelsUpdated = len(updated)
+	return summary, nil
+}
+
+// ensureChannel creates the named channel if it does not exist yet and reports
+// whether it did so. With dryRun it only reports whether it would.
+func (s *ImportService) ensureChannel(ctx context.Context, name, epgID string, dryRun bool) (bool, error) {
+	ch, err := channel.NewChannel(name)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := s.channelRepo.FindByName(ctx, ch.Name()); err == nil {
+		return false, nil
+	} else if !errors.Is(err, channel.ErrChannelNotFound) {
+		return false, fmt.Errorf("failed to look up channel %s: %w", name, err)
+	}
+	if dryRun {
+		return true, nil
+	}
+
+	if epgID != "" {
+		mapping, err := channel.NewEPGMapping(epgID, channel.MappingManual, time.Now())
+		if err != nil {
+			return false, err
+		}
+		ch.SetEPGMapping(mapping)
+	}
+
+	if err := s.channelRepo.Save(ctx, ch); err != nil {
+		if errors.Is(err, channel.ErrChannelAlreadyExists) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to save channel %s: %w", name, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,197 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/adapter/driven"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/m3u"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+type mockPlaylistFetcher struct {
+	data []byte
+	err  error
+}
+
+func (m *mockPlaylistFetcher) FetchPlaylist(ctx context.Context, url string) ([]byte, error) {
+	return m.data, m.err
+}
+
+func newTestImportService(t *testing.T, fetcher *mockPlaylistFetcher) (*ImportService, *driven.ChannelBoltDBRepository, *driven.StreamBoltDBRepository) {
+	t.Helper()
+
+	db, cleanup := setupE2ETestDB(t)
+	t.Cleanup(cleanup)
+
+	channelRepo, err := driven.NewChannelBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create channel repository: %v", err)
+	}
+	streamRepo, err := driven.NewStreamBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create stream repository: %v", err)
+	}
+
+	return NewImportService(channelRepo, streamRepo, fetcher), channelRepo, streamRepo
+}
+
+func TestImportService_ImportM3U(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates channels and streams and skips duplicates", func(t *testing.T) {
+		service, channelRepo, streamRepo := newTestImportService(t, nil)
+
+		existing, _ := channel.NewChannel("Existing")
+		if err := channelRepo.Save(ctx, existing); err != nil {
+			t.Fatalf("failed to seed channel: %v", err)
+		}
+		known, _ := stream.NewStream("6b6e6f776e000000000000000000000000000000", "Existing", stream.SourceManual)
+		if err := streamRepo.Save(ctx, known); err != nil {
+			t.Fatalf("failed to seed stream: %v", err)
+		}
+
+		playlist := `#EXTM3U
+#EXTINF:-1 tvg-id="hbo.es",HBO
+acestream://6861736831000000000000000000000000000000
+#EXTINF:-1,HBO
+http://127.0.0.1:6878/ace/getstream?id=6861736832000000000000000000000000000000
+#EXTINF:-1,Existing
+acestream://6861736833000000000000000000000000000000
+#EXTINF:-1,Existing
+acestream://6b6e6f776e000000000000000000000000000000
+#EXTINF:-1,Web Only
+http://example.com/live.m3u8
+#EXTINF:-1,HBO Duplicate
+acestream://6861736831000000000000000000000000000000
+`
+		summary, err := service.ImportM3U(ctx, strings.NewReader(playlist))
+		if err != nil {
+			t.Fatalf("ImportM3U() error = %v", err)
+		}
+
+		want := ImportSummary{ChannelsCreated: 1, ChannelsUpdated: 1, StreamsCreated: 3, StreamsSkipped: 3}
+		if summary != want {
+			t.Errorf("ImportM3U() summary = %+v, want %+v", summary, want)
+		}
+
+		hbo, err := channelRepo.FindByName(ctx, "HBO")
+		if err != nil {
+			t.Fatalf("expected HBO channel to be created: %v", err)
+		}
+		if m := hbo.EPGMapping(); m == nil || m.EPGID() != "hbo.es" || m.Source() != channel.MappingManual {
+			t.Errorf("expected manual EPG mapping to hbo.es, got %+v", m)
+		}
+
+		streams, err := streamRepo.FindByChannelName(ctx, "HBO")
+		if err != nil {
+			t.Fatalf("failed to list HBO streams: %v", err)
+		}
+		if len(streams) != 2 {
+			t.Fatalf("expected 2 HBO streams, got %d", len(streams))
+		}
+		for _, st := range streams {
+			if st.Source() != stream.SourceImport {
+				t.Errorf("expected source %q, got %q", stream.SourceImport, st.Source())
+			}
+		}
+
+		st, err := streamRepo.FindByInfoHash(ctx, "6b6e6f776e000000000000000000000000000000")
+		if err != nil || st.ChannelName() != "Existing" {
+			t.Errorf("expected existing stream to stay on its channel, got %v (%v)", st.ChannelName(), err)
+		}
+	})
+
+	t.Run("rejects input that is not a playlist", func(t *testing.T) {
+		service, _, _ := newTestImportService(t, nil)
+
+		_, err := service.ImportM3U(ctx, strings.NewReader("not a playlist"))
+		if !errors.Is(err, m3u.ErrNotPlaylist) {
+			t.Errorf("expected ErrNotPlaylist, got %v", err)
+		}
+	})
+}
+
+func TestImportService_ImportM3UFromURL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("imports the fetched playlist", func(t *testing.T) {
+		fetcher := &mockPlaylistFetcher{data: []byte("#EXTM3U\n#EXTINF:-1,HBO\nacestream://6861736831000000000000000000000000000000\n")}
+		service, _, _ := newTestImportService(t, fetcher)
+
+		summary, err := service.ImportM3UFromURL(ctx, "http://example.com/list.m3u")
+		if err != nil {
+			t.Fatalf("ImportM3UFromURL() error = %v", err)
+		}
+		if summary.ChannelsCreated != 1 || summary.StreamsCreated != 1 {
+			t.Errorf("unexpected summary %+v", summary)
+		}
+	})
+
+	t.Run("returns fetch errors", func(t *testing.T) {
+		fetchErr := errors.New("connection refused")
+		service, _, _ := newTestImportService(t, &mockPlaylistFetcher{err: fetchErr})
+
+		_, err := service.ImportM3UFromURL(ctx, "http://example.com/list.m3u")
+		if !errors.Is(err, ErrPlaylistUnavailable) || !errors.Is(err, fetchErr) {
+			t.Errorf("expected ErrPlaylistUnavailable wrapping the fetch error, got %v", err)
+		}
+	})
+}
+
+func TestImportService_Import(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("imports Tvheadend muxes", func(t *testing.T) {
+		service, channelRepo, _ := newTestImportService(t, nil)
+
+		export := `{"entries":[
+			{"iptv_url":"acestream://6861736831000000000000000000000000000000","iptv_sname":"HBO","iptv_epgid":"hbo.es"},
+			{"iptv_url":"http://127.0.0.1:6878/ace/getstream?id=6861736832000000000000000000000000000000","iptv_muxname":"Movistar"}
+		],"total":2}`
+		summary, err := service.Import(ctx, ImportFormatTvheadend, strings.NewReader(export), false)
+		if err != nil {
+			t.Fatalf("Import() error = %v", err)
+		}
+		if want := (ImportSummary{ChannelsCreated: 2, StreamsCreated: 2}); summary != want {
+			t.Errorf("Import() summary = %+v, want %+v", summary, want)
+		}
+		if _, err := channelRepo.FindByName(ctx, "Movistar"); err != nil {
+			t.Errorf("expected a channel named after the mux: %v", err)
+		}
+	})
+
+	t.Run("dry run reports the changes without saving them", func(t *testing.T) {
+		service, channelRepo, streamRepo := newTestImportService(t, nil)
+
+		output := `[
+			{"name":"HBO","items":[{"infohash":"6861736831000000000000000000000000000000"},{"infohash":"6861736832000000000000000000000000000000"}]},
+			{"name":"HBO Again","infohash":"6861736831000000000000000000000000000000"}
+		]`
+		summary, err := service.Import(ctx, ImportFormatAcestreamSearch, strings.NewReader(output), true)
+		if err != nil {
+			t.Fatalf("Import() error = %v", err)
+		}
+		if want := (ImportSummary{ChannelsCreated: 1, StreamsCreated: 2, StreamsSkipped: 1, DryRun: true}); summary != want {
+			t.Errorf("Import() summary = %+v, want %+v", summary, want)
+		}
+		if _, err := channelRepo.FindByName(ctx, "HBO"); !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected no channel saved, got %v", err)
+		}
+		if _, err := streamRepo.FindByInfoHash(ctx, "6861736831000000000000000000000000000000"); !errors.Is(err, stream.ErrStreamNotFound) {
+			t.Errorf("expected no stream saved, got %v", err)
+		}
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		service, _, _ := newTestImportService(t, nil)
+
+		_, err := service.Import(ctx, ImportFormat("xspf"), strings.NewReader(""), false)
+		if !errors.Is(err, ErrUnknownImportFormat) {
+			t.Errorf("expected ErrUnknownImportFormat, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package application
+
+import (
+	"context"
+	"net/url"
+	"slices"
+
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// LineupEntry is a channel offered to network tuner clients such as Plex.
+type LineupEntry struct {
+	Number      int
+	ChannelName string
+}
+
+// ChannelURL returns the URL that streams the channel named channelName,
+// failing over between its streams, for network tuner clients. The URL is
+// signed if stream links are set. The baseURL parameter is the externally
+// visible URL of the server, without a trailing slash.
+func (p *PlaylistService) ChannelURL(ctx context.Context, baseURL, channelName string) string {
+	u := baseURL + "/ace/channel/" + url.PathEscape(channelName)
+	if p.links != nil {
+		u = p.links.sign(ctx, u, channelResource(channelName))
+	}
+	return u
+}
+
+// Lineup lists every channel that has at least one stream, in the same
+// order and with the same numbers as the M3U playlist. Channels of disabled
+// groups are left out. Channels without an assigned number are numbered
+// after the assigned ones in channel order, so their numbers shift when
+// channels are added or removed.
+func (p *PlaylistService) Lineup(ctx context.Context) ([]LineupEntry, error) {
+	streams, err := p.streamRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := p.buildChannelMap(ctx)
+	slices.SortStableFunc(streams, func(a, b stream.Stream) int {
+		return compareChannelNames(channels, a.ChannelName(), b.ChannelName())
+	})
+	ordered := p.orderByGroup(streams, channels, p.buildGroupMap(ctx), false)
+	numbers := channelNumbers(ordered, channels)
+
+	lineup := []LineupEntry{}
+	seen := make(map[string]bool)
+	for _, s := range ordered {
+		if seen[s.ChannelName()] {
+			continue
+		}
+		seen[s.ChannelName()] = true
+		lineup = append(lineup, LineupEntry{Number: numbers[s.ChannelName()], ChannelName: s.ChannelName()})
+	}
+	return lineup, nil
+}
@@ -0,0 +1,74 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+func TestPlaylistService_Lineup(t *testing.T) {
+	t.Run("numbers channels with streams in playlist order", func(t *testing.T) {
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				b1, _ := stream.NewStream("6231000000000000000000000000000000000000", "Beta", "")
+				a1, _ := stream.NewStream("6131000000000000000000000000000000000000", "Alpha", "")
+				b2, _ := stream.NewStream("6232000000000000000000000000000000000000", "Beta", "")
+				return []stream.Stream{b1, a1, b2}, nil
+			},
+		}
+		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+
+		lineup, err := service.Lineup(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		want := []LineupEntry{{Number: 1, ChannelName: "Alpha"}, {Number: 2, ChannelName: "Beta"}}
+		if len(lineup) != len(want) {
+			t.Fatalf("expected %d entries, got %+v", len(want), lineup)
+		}
+		for i := range want {
+			if lineup[i] != want[i] {
+				t.Errorf("entry %d = %+v, want %+v", i, lineup[i], want[i])
+			}
+		}
+	})
+
+	t.Run("follows group order and skips disabled groups", func(t *testing.T) {
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				a, _ := stream.NewStream("6100000000000000000000000000000000000000", "Alpha", "")
+				b, _ := stream.NewStream("6200000000000000000000000000000000000000", "Beta", "")
+				c, _ := stream.NewStream("6300000000000000000000000000000000000000", "Gamma", "")
+				return []stream.Stream{a, b, c}, nil
+			},
+		}
+		beta := channel.ReconstructChannel("Beta", channel.StatusActive, nil)
+		beta.SetGroup("sports")
+		gamma := channel.ReconstructChannel("Gamma", channel.StatusActive, nil)
+		gamma.SetGroup("hidden")
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{beta, gamma}, nil
+			},
+		}
+		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+		service.SetGroupRepository(newMemGroupRepository(
+			group.ReconstructGroup("sports", "Sports", 0, true),
+			group.ReconstructGroup("hidden", "Hidden", 1, false),
+		))
+
+		lineup, err := service.Lineup(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(lineup) != 2 || lineup[0].ChannelName != "Beta" || lineup[1].ChannelName != "Alpha" {
+			t.Errorf("expected [Beta Alpha], got %+v", lineup)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Listing errors
+var (
+	ErrInvalidSortField  = errors.New("invalid sort field")
+	ErrInvalidPagination = errors.New("page and per_page must be positive")
+)
+
+// MaxPerPage caps the page size of listings.
+const MaxPerPage = 500
+
+// ListOptions selects the order of a listing and which page of it to return.
+type ListOptions struct {
+	// Sort is a field name, prefixed with "-" for descending order. Empty
+	// keeps the listing's default order.
+	Sort string
+	// Page is 1-based. Zero returns every item.
+	Page int
+	// PerPage defaults to 50 and is capped at MaxPerPage.
+	PerPage int
+}
+
+// ListPage is one page of a listing.
+type ListPage[T any] struct {
+	Items []T
+	// Total counts the items of every page.
+	Total   int
+	Page    int
+	PerPage int
+}
+
+// listItems sorts items with the comparer named by opts.Sort and cuts the
+// requested page out of them. Sorting is stable, so items comparing equal
+// keep their default order.
+// Returns ErrInvalidSortField or ErrInvalidPagination for invalid options.
+func listItems[T any](items []T, opts ListOptions, sortFields map[string]func(a, b T) int) (ListPage[T], error) {
+	if opts.Page < 0 || opts.PerPage < 0 {
+		return ListPage[T]{}, ErrInvalidPagination
+	}
+
+	if opts.Sort != "" {
+		field, desc := strings.CutPrefix(opts.Sort, "-")
+		compare, ok := sortFields[field]
+		if !ok {
+			return ListPage[T]{}, ErrInvalidSortField
+		}
+		slices.SortStableFunc(items, func(a, b T) int {
+			if desc {
+				return compare(b, a)
+			}
+			return compare(a, b)
+		})
+	}
+
+	page := ListPage[T]{Items: items, Total: len(items)}
+	if opts.Page == 0 {
+		return page, nil
+	}
+
+	page.Page, page.PerPage = opts.Page, opts.PerPage
+	if page.PerPage == 0 {
+		page.PerPage = 50
+	}
+	page.PerPage = min(page.PerPage, MaxPerPage)
+
+	start := len(items)
+	if page.Page-1 <= len(items)/page.PerPage {
+		start = min((page.Page-1)*page.PerPage, len(items))
+	}
+	end := min(start+page.PerPage, len(items))
+	page.Items = items[start:end]
+	return page, nil
+}
@@ -0,0 +1,58 @@
+package application
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestListItems(t *testing.T) {
+	sortFields := map[string]func(a, b string) int{"name": strings.Compare}
+	items := func() []string { return []string{"c", "a", "e", "b", "d"} }
+
+	tests := []struct {
+		name      string
+		opts      ListOptions
+		wantItems []string
+		wantPage  int
+		wantErr   error
+	}{
+		{name: "defaults return everything in order", opts: ListOptions{}, wantItems: []string{"c", "a", "e", "b", "d"}},
+		{name: "sorts ascending", opts: ListOptions{Sort: "name"}, wantItems: []string{"a", "b", "c", "d", "e"}},
+		{name: "sorts descending", opts: ListOptions{Sort: "-name"}, wantItems: []string{"e", "d", "c", "b", "a"}},
+		{name: "cuts a page", opts: ListOptions{Sort: "name", Page: 2, PerPage: 2}, wantItems: []string{"c", "d"}, wantPage: 2},
+		{name: "last page is short", opts: ListOptions{Sort: "name", Page: 3, PerPage: 2}, wantItems: []string{"e"}, wantPage: 3},
+		{name: "page past the end is empty", opts: ListOptions{Page: 9, PerPage: 2}, wantItems: []string{}, wantPage: 9},
+		{name: "unknown sort field", opts: ListOptions{Sort: "size"}, wantErr: ErrInvalidSortField},
+		{name: "negative page", opts: ListOptions{Page: -1}, wantErr: ErrInvalidPagination},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := listItems(items(), tt.opts, sortFields)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !slices.Equal(page.Items, tt.wantItems) {
+				t.Errorf("expected items %v, got %v", tt.wantItems, page.Items)
+			}
+			if page.Total != 5 || page.Page != tt.wantPage {
+				t.Errorf("unexpected page %d of %d items", page.Page, page.Total)
+			}
+		})
+	}
+
+	t.Run("per page defaults and is capped", func(t *testing.T) {
+		many := make([]string, MaxPerPage+10)
+		if page, _ := listItems(many, ListOptions{Page: 1}, sortFields); len(page.Items) != 50 {
+			t.Errorf("expected 50 items by default, got %d", len(page.Items))
+		}
+		if page, _ := listItems(many, ListOptions{Page: 1, PerPage: MaxPerPage * 2}, sortFields); page.PerPage != MaxPerPage {
+			t.Errorf("expected per page capped at %d, got %d", MaxPerPage, page.PerPage)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/alorle/iptv-manager/internal/logo"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// LogoService keeps a local copy of channel logos so playlists can point
+// players at this server instead of third-party image hosts.
+type LogoService struct {
+	fetcher driven.LogoFetcher
+	store   driven.LogoStore
+	logger  *slog.Logger
+}
+
+// NewLogoService creates a new logo service with the required dependencies.
+func NewLogoService(fetcher driven.LogoFetcher, store driven.LogoStore, logger *slog.Logger) *LogoService {
+	return &LogoService{
+		fetcher: fetcher,
+		store:   store,
+		logger:  logger,
+	}
+}
+
+// Refresh downloads logos for the given EPG IDs (mapped to their logo URLs).
+// Logos already cached from the same URL are skipped. Failures are logged and
+// do not stop the refresh; the number of newly downloaded logos is returned.
+func (s *LogoService) Refresh(ctx context.Context, logoURLs map[string]string) int {
+	downloaded := 0
+	for epgID, url := range logoURLs {
+		if ctx.Err() != nil {
+			break
+		}
+		if url == "" {
+			continue
+		}
+
+		key := logo.Key(epgID)
+		if cached, err := s.store.SourceURL(ctx, key); err == nil && cached == url {
+			continue
+		}
+
+		data, err := s.fetcher.FetchLogo(ctx, url)
+		if err != nil {
+			s.logger.Warn("failed to download logo", "epg_id", epgID, "url", url, "error", err)
+			continue
+		}
+		if err := s.store.Save(ctx, key, url, data); err != nil {
+			s.logger.Error("failed to store logo", "epg_id", epgID, "error", err)
+			continue
+		}
+		downloaded++
+	}
+	return downloaded
+}
+
+// LogoPath returns the server path of the cached logo for an EPG ID, or
+// false if none is cached.
+func (s *LogoService) LogoPath(ctx context.Context, epgID string) (string, bool) {
+	key := logo.Key(epgID)
+	if _, err := s.store.SourceURL(ctx, key); err != nil {
+		return "", false
+	}
+	return "/logos/" + key + ".png", true
+}
+
+// GetLogo returns the cached logo for key.
+// Returns logo.ErrLogoNotFound if it is not cached.
+func (s *LogoService) GetLogo(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.store.Load(ctx, key)
+	if err != nil {
+		if errors.Is(err, logo.ErrLogoNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to load logo: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,97 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/logo"
+)
+
+// mockLogoStore is an in-memory driven.LogoStore.
+type mockLogoStore struct {
+	data    map[string][]byte
+	sources map[string]string
+}
+
+func newMockLogoStore() *mockLogoStore {
+	return &mockLogoStore{data: map[string][]byte{}, sources: map[string]string{}}
+}
+
+func (m *mockLogoStore) Save(ctx context.Context, key, sourceURL string, data []byte) error {
+	m.data[key] = data
+	m.sources[key] = sourceURL
+	return nil
+}
+
+func (m *mockLogoStore) Load(ctx context.Context, key string) ([]byte, error) {
+	if d, ok := m.data[key]; ok {
+		return d, nil
+	}
+	return nil, logo.ErrLogoNotFound
+}
+
+func (m *mockLogoStore) SourceURL(ctx context.Context, key string) (string, error) {
+	if s, ok := m.sources[key]; ok {
+		return s, nil
+	}
+	return "", logo.ErrLogoNotFound
+}
+
+// mockLogoFetcher records fetched URLs and fails for URLs containing "broken".
+type mockLogoFetcher struct {
+	fetched []string
+}
+
+func (m *mockLogoFetcher) FetchLogo(ctx context.Context, url string) ([]byte, error) {
+	m.fetched = append(m.fetched, url)
+	if strings.Contains(url, "broken") {
+		return nil, errors.New("download failed")
+	}
+	return []byte("img:" + url), nil
+}
+
+func TestLogoService_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("downloads new logos and skips unchanged ones", func(t *testing.T) {
+		store := newMockLogoStore()
+		fetcher := &mockLogoFetcher{}
+		service := NewLogoService(fetcher, store, newTestLogger())
+
+		n := service.Refresh(ctx, map[string]string{"hbo.es": "http://x/hbo.png", "cnn.us": "http://x/broken.png", "empty": ""})
+		if n != 1 {
+			t.Errorf("expected 1 download, got %d", n)
+		}
+
+		fetcher.fetched = nil
+		service.Refresh(ctx, map[string]string{"hbo.es": "http://x/hbo.png"})
+		if len(fetcher.fetched) != 0 {
+			t.Errorf("expected unchanged logo to be skipped, fetched %v", fetcher.fetched)
+		}
+
+		service.Refresh(ctx, map[string]string{"hbo.es": "http://x/hbo-v2.png"})
+		data, _ := service.GetLogo(ctx, logo.Key("hbo.es"))
+		if string(data) != "img:http://x/hbo-v2.png" {
+			t.Errorf("expected logo to be replaced when its URL changes, got %q", data)
+		}
+	})
+
+	t.Run("resolves paths only for cached logos", func(t *testing.T) {
+		store := newMockLogoStore()
+		service := NewLogoService(&mockLogoFetcher{}, store, newTestLogger())
+		service.Refresh(ctx, map[string]string{"hbo.es": "http://x/hbo.png"})
+
+		path, ok := service.LogoPath(ctx, "hbo.es")
+		if !ok || path != "/logos/"+logo.Key("hbo.es")+".png" {
+			t.Errorf("LogoPath() = %q, %v", path, ok)
+		}
+		if _, ok := service.LogoPath(ctx, "unknown"); ok {
+			t.Error("expected no path for uncached logo")
+		}
+		if _, err := service.GetLogo(ctx, logo.Key("unknown")); !errors.Is(err, logo.ErrLogoNotFound) {
+			t.Errorf("expected ErrLogoNotFound, got %v", err)
+		}
+	})
+}
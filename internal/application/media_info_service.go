@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
+)
+
+// ErrMediaInfoUnavailable is returned when no program information could be
+// read from a stream before the analysis timed out or the stream ended.
+var ErrMediaInfoUnavailable = errors.New("media info unavailable")
+
+// MediaInfoConfig tunes stream analysis.
+type MediaInfoConfig struct {
+	// SampleDuration is how much stream time to read to measure the bitrate.
+	SampleDuration time.Duration
+	// Timeout bounds a whole analysis, including engine startup.
+	Timeout time.Duration
+}
+
+// MediaInfoService reports the codecs, resolution, bitrate and audio tracks
+// of a stream by briefly reading it through the proxy. The analysis
+// subscribes like any other client, so it shares a running engine stream or
+// starts one that is torn down again when it finishes.
+type MediaInfoService struct {
+	proxy  *AceStreamProxyService
+	config MediaInfoConfig
+	logger *slog.Logger
+}
+
+// NewMediaInfoService creates a new MediaInfoService on top of the proxy.
+func NewMediaInfoService(proxy *AceStreamProxyService, config MediaInfoConfig, logger *slog.Logger) *MediaInfoService {
+	return &MediaInfoService{
+		proxy:  proxy,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Analyze reads the stream for the given infohash until its programs,
+// video resolution and bitrate are known, or until the timeout, and returns
+// what was learned.
+// Returns ErrMediaInfoUnavailable if not even the program's streams were found.
+func (s *MediaInfoService) Analyze(ctx context.Context, infoHash string) (mpegts.MediaInfo, error) {
+	if infoHash == "" {
+		return mpegts.MediaInfo{}, ErrInvalidInfoHash
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	analyzer := mpegts.NewAnalyzer()
+	dst := &analysisWriter{analyzer: analyzer, sample: s.config.SampleDuration, done: cancel}
+
+	start := time.Now()
+	err := s.proxy.StreamToClient(ctx, infoHash, dst)
+	info := analyzer.Info()
+	s.logger.Info("stream analyzed", "infohash", infoHash, "bytes", info.Bytes, "duration", time.Since(start))
+
+	if info.Video == nil && len(info.Audio) == 0 {
+		if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			return mpegts.MediaInfo{}, err
+		}
+		return mpegts.MediaInfo{}, ErrMediaInfoUnavailable
+	}
+	return info, nil
+}
+
+// analysisWriter feeds the analyzer and stops the stream once enough has
+// been learned.
+type analysisWriter struct {
+	analyzer *mpegts.Analyzer
+	sample   time.Duration
+	done     context.CancelFunc
+}
+
+func (w *analysisWriter) Write(p []byte) (int, error) {
+	n, err := w.analyzer.Write(p)
+	if w.analyzer.Complete(w.sample) {
+		w.done()
+	}
+	return n, err
+}
@@ -0,0 +1,112 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
+)
+
+// audioOnlyTS returns a transport stream whose single program carries one
+// AAC track on PID 0x101, followed by PCR packets one second apart.
+func audioOnlyTS() [][]byte {
+	psiPacket := func(pid uint16, section []byte) []byte {
+		pkt := make([]byte, mpegts.PacketSize)
+		pkt[0], pkt[1], pkt[2], pkt[3] = 0x47, 0x40|byte(pid>>8), byte(pid), 0x10
+		n := copy(pkt[5:], section)
+		for i := 5 + n; i < len(pkt); i++ {
+			pkt[i] = 0xFF
+		}
+		return pkt
+	}
+	pcrPacket := func(seconds int64) []byte {
+		pkt := make([]byte, mpegts.PacketSize)
+		pkt[0], pkt[1], pkt[2], pkt[3] = 0x47, 0x01, 0x01, 0x20
+		pkt[4], pkt[5] = mpegts.PacketSize-5, 0x10
+		base := seconds * 90000
+		pkt[6], pkt[7], pkt[8], pkt[9], pkt[10] = byte(base>>25), byte(base>>17), byte(base>>9), byte(base>>1), byte(base<<7)
+		return pkt
+	}
+
+	pat := []byte{0x00, 0xB0, 0x0D, 0x00, 0x01, 0xC1, 0x00, 0x00, 0x00, 0x01, 0xF0, 0x00, 0, 0, 0, 0}
+	pmt := []byte{
+		0x02, 0xB0, 0x18, 0x00, 0x01, 0xC1, 0x00, 0x00, 0xE1, 0x01, 0xF0, 0x00,
+		0x0F, 0xE1, 0x01, 0xF0, 0x06, 0x0A, 0x04, 'e', 'n', 'g', 0x00,
+		0, 0, 0, 0,
+	}
+	return [][]byte{psiPacket(0x0000, pat), psiPacket(0x1000, pmt), pcrPacket(0), pcrPacket(1), pcrPacket(2)}
+}
+
+func newTestMediaInfoService(engine *mockAceStreamEngine, timeout time.Duration) *MediaInfoService {
+	proxy := NewAceStreamProxyService(engine, newTestLogger(), 10*time.Second, nil)
+	return NewMediaInfoService(proxy, MediaInfoConfig{
+		SampleDuration: time.Second,
+		Timeout:        timeout,
+	}, newTestLogger())
+}
+
+func TestMediaInfoService_Analyze(t *testing.T) {
+	t.Run("stops the stream once enough has been read", func(t *testing.T) {
+		engine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "http://engine/stream", nil
+			},
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				for _, pkt := range audioOnlyTS() {
+					if _, err := dst.Write(pkt); err != nil {
+						return err
+					}
+					time.Sleep(time.Millisecond)
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+		service := newTestMediaInfoService(engine, 5*time.Second)
+
+		info, err := service.Analyze(context.Background(), "abc123")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if info.Video != nil {
+			t.Errorf("expected no video, got %+v", *info.Video)
+		}
+		if len(info.Audio) != 1 || info.Audio[0].Codec != "aac" || info.Audio[0].Language != "eng" {
+			t.Errorf("unexpected audio tracks %+v", info.Audio)
+		}
+		if info.Duration < time.Second {
+			t.Errorf("expected at least 1s of stream time, got %v", info.Duration)
+		}
+		if service.proxy.IsStreamActive("abc123") {
+			t.Error("expected the stream to be torn down")
+		}
+	})
+
+	t.Run("returns ErrMediaInfoUnavailable when nothing is recognized", func(t *testing.T) {
+		engine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "http://engine/stream", nil
+			},
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+		service := newTestMediaInfoService(engine, 50*time.Millisecond)
+
+		if _, err := service.Analyze(context.Background(), "abc123"); !errors.Is(err, ErrMediaInfoUnavailable) {
+			t.Errorf("expected ErrMediaInfoUnavailable, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrInvalidInfoHash for empty infohash", func(t *testing.T) {
+		service := newTestMediaInfoService(&mockAceStreamEngine{}, time.Second)
+
+		if _, err := service.Analyze(context.Background(), ""); !errors.Is(err, ErrInvalidInfoHash) {
+			t.Errorf("expected ErrInvalidInfoHash, got %v", err)
+		}
+	})
+}
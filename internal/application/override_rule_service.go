@@ -0,0 +1,76 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/rule"
+)
+
+// OverrideRuleService provides use cases for managing override rules, which
+// the PlaylistService applies when generating playlists.
+type OverrideRuleService struct {
+	ruleRepo driven.RuleRepository
+	playlist *PlaylistService
+	events   *EventBus
+}
+
+// NewOverrideRuleService creates a new OverrideRuleService. The playlist
+// service previews rules against the current playlist.
+func NewOverrideRuleService(ruleRepo driven.RuleRepository, playlist *PlaylistService) *OverrideRuleService {
+	return &OverrideRuleService{
+		ruleRepo: ruleRepo,
+		playlist: playlist,
+	}
+}
+
+// SetEventBus enables publishing EventOverrideUpdated when rules change.
+func (s *OverrideRuleService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// CreateRule adds a rule that applies after all existing rules.
+// Returns rule.ErrNoMatcher, rule.ErrNoAction or rule.ErrInvalidPattern if
+// the rule is invalid.
+func (s *OverrideRuleService) CreateRule(ctx context.Context, match rule.Match, action rule.Action) (rule.Rule, error) {
+	r, err := rule.NewRule(match, action, time.Now())
+	if err != nil {
+		return rule.Rule{}, err
+	}
+
+	if err := s.ruleRepo.Save(ctx, r); err != nil {
+		return rule.Rule{}, err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "rule", Name: r.ID()})
+	return r, nil
+}
+
+// ListRules retrieves all rules in the order they apply.
+func (s *OverrideRuleService) ListRules(ctx context.Context) ([]rule.Rule, error) {
+	return s.ruleRepo.FindAll(ctx)
+}
+
+// DeleteRule removes a rule.
+// Returns rule.ErrRuleNotFound if the rule does not exist.
+func (s *OverrideRuleService) DeleteRule(ctx context.Context, id string) error {
+	if err := s.ruleRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "rule", Name: id})
+	return nil
+}
+
+// PreviewRule reports the playlist entries a rule would change if it were
+// created, without saving it.
+// Returns rule.ErrNoMatcher, rule.ErrNoAction or rule.ErrInvalidPattern if
+// the rule is invalid.
+func (s *OverrideRuleService) PreviewRule(ctx context.Context, match rule.Match, action rule.Action) ([]RuleChange, error) {
+	r, err := rule.NewRule(match, action, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.playlist.PreviewRule(ctx, r)
+}
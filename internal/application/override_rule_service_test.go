@@ -0,0 +1,139 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/rule"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// memRuleRepository is an in-memory driven.RuleRepository for testing.
+type memRuleRepository struct {
+	rules map[string]rule.Rule
+}
+
+func (r *memRuleRepository) Save(ctx context.Context, ru rule.Rule) error {
+	r.rules[ru.ID()] = ru
+	return nil
+}
+
+func (r *memRuleRepository) FindAll(ctx context.Context) ([]rule.Rule, error) {
+	rules := make([]rule.Rule, 0, len(r.rules))
+	for _, ru := range r.rules {
+		rules = append(rules, ru)
+	}
+	rule.Sort(rules)
+	return rules, nil
+}
+
+func (r *memRuleRepository) FindByID(ctx context.Context, id string) (rule.Rule, error) {
+	ru, ok := r.rules[id]
+	if !ok {
+		return rule.Rule{}, rule.ErrRuleNotFound
+	}
+	return ru, nil
+}
+
+func (r *memRuleRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := r.rules[id]; !ok {
+		return rule.ErrRuleNotFound
+	}
+	delete(r.rules, id)
+	return nil
+}
+
+func newOverrideRuleTestServices() (*OverrideRuleService, *PlaylistService) {
+	dazn, _ := stream.NewStream("64617a6e00000000000000000000000000000000", "DAZN 1 HD", stream.SourceNewEra)
+	late, _ := stream.NewStream("6c61746500000000000000000000000000000000", "Late Night", stream.SourceElcano)
+	news, _ := stream.NewStream("6e65777300000000000000000000000000000000", "News HD", stream.SourceManual)
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{dazn, late, news}, nil
+		},
+	}
+
+	lateCh, _ := channel.NewChannel("Late Night")
+	lateCh.SetGroup("adult")
+	channelRepo, _ := newMemChannelRepository(lateCh)
+	adult, _ := group.NewGroup("Adult")
+
+	playlistService := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+	playlistService.SetGroupRepository(newMemGroupRepository(adult))
+	ruleRepo := &memRuleRepository{rules: make(map[string]rule.Rule)}
+	playlistService.SetRuleRepository(ruleRepo)
+
+	return NewOverrideRuleService(ruleRepo, playlistService), playlistService
+}
+
+func TestOverrideRuleService_RulesApplyToPlaylist(t *testing.T) {
+	ctx := context.Background()
+	service, playlistService := newOverrideRuleTestServices()
+
+	if _, err := service.CreateRule(ctx, rule.Match{NamePattern: `^(.*) HD$`, Source: "new-era"}, rule.Action{Rename: "$1", Group: "Sports"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.CreateRule(ctx, rule.Match{Group: "Adult"}, rule.Action{Disable: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m3u, err := playlistService.GenerateM3U(ctx, "http://localhost:8080")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(m3u, `group-title="Sports",DAZN 1 - 64617a6e00000000000000000000000000000000`) {
+		t.Errorf("expected the new-era HD stream to be renamed and re-grouped, got:\n%s", m3u)
+	}
+	if !strings.Contains(m3u, `News HD - 6e65777300000000000000000000000000000000`) {
+		t.Errorf("expected streams of other sources to be left alone, got:\n%s", m3u)
+	}
+	if strings.Contains(m3u, "Late Night") {
+		t.Errorf("expected the Adult group to be disabled, got:\n%s", m3u)
+	}
+
+	rules, _ := service.ListRules(ctx)
+	for _, r := range rules {
+		if err := service.DeleteRule(ctx, r.ID()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	m3u, _ = playlistService.GenerateM3U(ctx, "http://localhost:8080")
+	if !strings.Contains(m3u, "Late Night") || !strings.Contains(m3u, "DAZN 1 HD") {
+		t.Errorf("expected deleted rules to stop applying, got:\n%s", m3u)
+	}
+
+	if err := service.DeleteRule(ctx, "missing"); !errors.Is(err, rule.ErrRuleNotFound) {
+		t.Errorf("expected ErrRuleNotFound, got %v", err)
+	}
+}
+
+func TestOverrideRuleService_PreviewRule(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newOverrideRuleTestServices()
+
+	if _, err := service.PreviewRule(ctx, rule.Match{NamePattern: ` HD$`}, rule.Action{}); !errors.Is(err, rule.ErrNoAction) {
+		t.Errorf("expected ErrNoAction, got %v", err)
+	}
+
+	changes, err := service.PreviewRule(ctx, rule.Match{NamePattern: ` HD$`}, rule.Action{TVGID: "hd.es"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	for _, c := range changes {
+		if c.After.TVGID != "hd.es" || c.Before.TVGID == "hd.es" || c.Disabled {
+			t.Errorf("unexpected change %+v", c)
+		}
+	}
+
+	if rules, _ := service.ListRules(ctx); len(rules) != 0 {
+		t.Errorf("expected preview not to save the rule, got %d rules", len(rules))
+	}
+}
@@ -0,0 +1,153 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// Container is the format a stream is delivered to a player in.
+type Container string
+
+const (
+	// ContainerTS is the raw MPEG-TS stream, as the engine produces it.
+	ContainerTS Container = "ts"
+	// ContainerHLS is an HLS playlist of TS segments, for players such as
+	// Safari that cannot play a progressive TS stream.
+	ContainerHLS Container = "hls"
+)
+
+// ErrInvalidPlayerProfile indicates a player profile with a malformed user
+// agent pattern, an unknown container or conflicting connection settings.
+var ErrInvalidPlayerProfile = errors.New("player profiles need a user agent pattern and a container of ts or hls, and cannot keep alive an unchunked stream")
+
+// PlayerProfile adapts stream responses to the players whose User-Agent it
+// matches. The zero value serves the raw stream as video/mpeg, chunked.
+type PlayerProfile struct {
+	// Name labels the profile in logs.
+	Name string
+	// UserAgent is a regular expression matched against the client's
+	// User-Agent header.
+	UserAgent string
+	Container Container
+	// ContentType replaces video/mpeg as the Content-Type of TS streams.
+	ContentType string
+	// KeepAlive sends "Connection: keep-alive", which some smart TV
+	// firmwares wait for before playing.
+	KeepAlive bool
+	// DisableChunking sends the stream without chunked transfer encoding,
+	// ending it by closing the connection, for firmwares that cannot parse
+	// chunks.
+	DisableChunking bool
+}
+
+// DefaultPlayerProfiles returns the built-in profiles: raw TS as video/mp2t
+// for VLC and Kodi, HLS for Safari and iOS, and the connection settings
+// known to help Samsung, LG and Philips TVs.
+func DefaultPlayerProfiles() []PlayerProfile {
+	return []PlayerProfile{
+		{Name: "vlc", UserAgent: `(?i)\bVLC/|LibVLC`, Container: ContainerTS, ContentType: "video/mp2t"},
+		{Name: "kodi", UserAgent: `(?i)\bKodi/|\bXBMC/`, Container: ContainerTS, ContentType: "video/mp2t"},
+		{Name: "apple", UserAgent: `AppleCoreMedia/|\((iPhone|iPad|iPod)\b|Version/[\d.]+.* Safari/`, Container: ContainerHLS},
+		{Name: "tizen", UserAgent: `(?i)\bTizen\b|SMART-TV`, Container: ContainerTS, ContentType: "video/mp2t", KeepAlive: true},
+		{Name: "webos", UserAgent: `(?i)\bWeb0S\b|\bwebOS\b|NetCast`, Container: ContainerTS, ContentType: "video/mp2t", DisableChunking: true},
+		{Name: "philips", UserAgent: `(?i)\bPhilipsTV\b|NETTV/`, Container: ContainerTS, ContentType: "video/mp2t", KeepAlive: true},
+	}
+}
+
+// DefaultPlayerProfile returns the built-in profile for players matching no
+// other profile, serving the raw stream as video/mpeg.
+func DefaultPlayerProfile() PlayerProfile {
+	return PlayerProfile{Name: "default", Container: ContainerTS}
+}
+
+// PlayerProfiles chooses the profile of a player by its User-Agent, the
+// first matching profile winning. It is safe for concurrent use.
+type PlayerProfiles struct {
+	mu       sync.RWMutex
+	profiles []PlayerProfile
+	patterns []*regexp.Regexp
+	fallback PlayerProfile
+}
+
+// NewPlayerProfiles creates player profiles holding DefaultPlayerProfiles
+// and DefaultPlayerProfile.
+func NewPlayerProfiles() *PlayerProfiles {
+	p := &PlayerProfiles{}
+	// The built-in profiles are valid
+	_ = p.SetProfiles(DefaultPlayerProfiles(), DefaultPlayerProfile())
+	return p
+}
+
+// SetProfiles replaces the profiles and the fallback given to players
+// matching none. The fallback's user agent pattern is ignored. They apply
+// to streams requested afterwards.
+// Returns ErrInvalidPlayerProfile if a profile is malformed.
+func (p *PlayerProfiles) SetProfiles(profiles []PlayerProfile, fallback PlayerProfile) error {
+	patterns := make([]*regexp.Regexp, len(profiles))
+	for i, profile := range profiles {
+		if err := validatePlayerProfile(profile); err != nil {
+			return err
+		}
+		if profile.UserAgent == "" {
+			return ErrInvalidPlayerProfile
+		}
+		pattern, err := regexp.Compile(profile.UserAgent)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidPlayerProfile, err)
+		}
+		patterns[i] = pattern
+	}
+	fallback.UserAgent = ""
+	if err := validatePlayerProfile(fallback); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.profiles = slices.Clone(profiles)
+	p.patterns = patterns
+	p.fallback = fallback
+	return nil
+}
+
+// validatePlayerProfile checks the container and connection settings of a
+// profile.
+func validatePlayerProfile(profile PlayerProfile) error {
+	if profile.Container != ContainerTS && profile.Container != ContainerHLS {
+		return ErrInvalidPlayerProfile
+	}
+	if profile.KeepAlive && profile.DisableChunking {
+		return ErrInvalidPlayerProfile
+	}
+	return nil
+}
+
+// Profiles returns the profiles, in the order they are matched.
+func (p *PlayerProfiles) Profiles() []PlayerProfile {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return slices.Clone(p.profiles)
+}
+
+// Fallback returns the profile of players matching no other profile.
+func (p *PlayerProfiles) Fallback() PlayerProfile {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.fallback
+}
+
+// Match returns the first profile matching userAgent, or the fallback if
+// none does.
+func (p *PlayerProfiles) Match(userAgent string) PlayerProfile {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for i, pattern := range p.patterns {
+		if pattern.MatchString(userAgent) {
+			return p.profiles[i]
+		}
+	}
+	return p.fallback
+}
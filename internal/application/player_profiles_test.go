@@ -0,0 +1,57 @@
+package application
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlayerProfiles(t *testing.T) {
+	players := NewPlayerProfiles()
+
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"VLC/3.0.20 LibVLC/3.0.20", "vlc"},
+		{"Kodi/20.2 (Linux; Android 11.0) ARM 64bit", "kodi"},
+		{"AppleCoreMedia/1.0.0.21A329 (iPhone; U; CPU OS 17_0 like Mac OS X)", "apple"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15", "apple"},
+		{"Mozilla/5.0 (SMART-TV; Linux; Tizen 6.0) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/4.0 Chrome/76.0.3809.146 TV Safari/537.36", "tizen"},
+		{"Mozilla/5.0 (Web0S; Linux/SmartTV) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.79 Safari/537.36", "webos"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36", "default"},
+		{"", "default"},
+	}
+	for _, tt := range tests {
+		if got := players.Match(tt.userAgent); got.Name != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.userAgent, got.Name, tt.want)
+		}
+	}
+
+	for _, profiles := range [][]PlayerProfile{
+		{{UserAgent: "VLC", Container: "mkv"}},
+		{{Container: ContainerTS}},
+		{{UserAgent: "(", Container: ContainerTS}},
+		{{UserAgent: "TV", Container: ContainerTS, KeepAlive: true, DisableChunking: true}},
+	} {
+		if err := players.SetProfiles(profiles, DefaultPlayerProfile()); !errors.Is(err, ErrInvalidPlayerProfile) {
+			t.Errorf("SetProfiles(%+v): expected ErrInvalidPlayerProfile, got %v", profiles, err)
+		}
+	}
+	if err := players.SetProfiles(nil, PlayerProfile{}); !errors.Is(err, ErrInvalidPlayerProfile) {
+		t.Errorf("expected a fallback without a container to be rejected, got %v", err)
+	}
+	if len(players.Profiles()) != len(DefaultPlayerProfiles()) {
+		t.Error("expected rejected profiles to leave the current ones")
+	}
+
+	fallback := PlayerProfile{Name: "tv", UserAgent: "ignored", Container: ContainerTS, ContentType: "video/mp2t"}
+	if err := players.SetProfiles([]PlayerProfile{{Name: "mpv", UserAgent: "^mpv", Container: ContainerHLS}}, fallback); err != nil {
+		t.Fatalf("SetProfiles() error = %v", err)
+	}
+	if got := players.Match("mpv 0.36.0"); got.Name != "mpv" {
+		t.Errorf("expected the new profile to match, got %q", got.Name)
+	}
+	if got := players.Match("VLC/3.0.20"); got.Name != "tv" || got.UserAgent != "" {
+		t.Errorf("expected the new fallback without its pattern, got %+v", got)
+	}
+}
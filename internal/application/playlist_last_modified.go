@@ -0,0 +1,56 @@
+package application
+
+import (
+	"context"
+	"time"
+)
+
+// LastModified returns when the channels or sources behind the playlist last
+// changed, as seen by TrackChanges, or when the service was created.
+func (p *PlaylistService) LastModified() time.Time {
+	return time.Unix(0, p.lastModified.Load())
+}
+
+// TrackChanges moves LastModified forward as channels, groups, rules or
+// subscriptions are changed, upstream sources change, EPG syncs complete and
+// backups are restored, until ctx is done or the bus is closed.
+func (p *PlaylistService) TrackChanges(ctx context.Context, events *EventBus) {
+	ch, unsubscribe := events.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			if changesPlaylist(event) {
+				p.touch(event.Time)
+			}
+		}
+	}
+}
+
+// changesPlaylist reports whether an event may change the playlist.
+func changesPlaylist(e Event) bool {
+	switch e.Type {
+	case EventOverrideUpdated, EventSourceChanged, EventDatabaseRestored:
+		return true
+	case EventEPGSyncProgress:
+		progress, ok := e.Data.(EPGSyncProgress)
+		return ok && progress.Phase == "completed"
+	}
+	return false
+}
+
+// touch records a change at t, unless a later one was already recorded.
+func (p *PlaylistService) touch(t time.Time) {
+	for {
+		current := p.lastModified.Load()
+		if t.UnixNano() <= current || p.lastModified.CompareAndSwap(current, t.UnixNano()) {
+			return
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPlaylistService_TrackChanges(t *testing.T) {
+	service := NewPlaylistService(&mockStreamRepository{}, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+	created := service.LastModified()
+
+	bus := NewEventBus()
+	now := created.Add(time.Hour)
+	bus.now = func() time.Time { return now }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.TrackChanges(ctx, bus)
+	waitFor(t, func() bool { return bus.SubscriberCount() == 1 })
+
+	bus.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: "La 1"})
+	waitFor(t, func() bool { return service.LastModified().Equal(now) })
+
+	now = now.Add(time.Hour)
+	bus.Publish(EventEPGSyncProgress, EPGSyncProgress{Phase: "completed"})
+	waitFor(t, func() bool { return service.LastModified().Equal(now) })
+
+	// An older change does not move LastModified back
+	service.touch(created)
+	if !service.LastModified().Equal(now) {
+		t.Errorf("expected LastModified to stay at %v, got %v", now, service.LastModified())
+	}
+
+	// Events that leave the playlist alone do not count as changes
+	for _, e := range []Event{
+		{Type: EventStreamStarted, Data: StreamEventData{InfoHash: "abc"}},
+		{Type: EventEPGSyncProgress, Data: EPGSyncProgress{Phase: "started"}},
+		{Type: EventEPGSyncProgress, Data: EPGSyncProgress{Phase: "failed"}},
+	} {
+		if changesPlaylist(e) {
+			t.Errorf("expected %s %+v not to change the playlist", e.Type, e.Data)
+		}
+	}
+}
@@ -1,28 +1,69 @@
 package application
 
 import (
+	"bytes"
 	"cmp"
 	"context"
-	"fmt"
 	"log/slog"
+	"math"
+	"net/url"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/epg"
+	"github.com/alorle/iptv-manager/internal/favorite"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/playlist"
 	"github.com/alorle/iptv-manager/internal/port/driven"
 	"github.com/alorle/iptv-manager/internal/probe"
+	"github.com/alorle/iptv-manager/internal/rule"
 	"github.com/alorle/iptv-manager/internal/stream"
+	"github.com/alorle/iptv-manager/internal/user"
 )
 
 // PlaylistService provides use cases for playlist generation.
 // It depends only on port interfaces.
 type PlaylistService struct {
-	streamRepo  driven.StreamRepository
-	channelRepo driven.ChannelRepository
-	probeRepo   driven.ProbeRepository
-	window      time.Duration
+	streamRepo   driven.StreamRepository
+	channelRepo  driven.ChannelRepository
+	probeRepo    driven.ProbeRepository
+	window       time.Duration
+	logos        *LogoService
+	groupRepo    driven.GroupRepository
+	ruleRepo     driven.RuleRepository
+	catchupDays  atomic.Int64
+	recordings   *RecordingService
+	links        *StreamLinks
+	availability PlaylistAvailability
+	minHealth    float64
+	lastModified atomic.Int64
+
+	guide          driven.EPGProgrammeFetcher
+	guideMu        sync.Mutex
+	programmes     []epg.Programme
+	guideFetchedAt time.Time
 }
 
+// PlaylistAvailability is how a playlist reflects the availability of
+// channels as last probed.
+type PlaylistAvailability string
+
+const (
+	// PlaylistAvailabilityOff ignores availability.
+	PlaylistAvailabilityOff PlaylistAvailability = "off"
+	// PlaylistAvailabilityTag marks every entry with its channel's
+	// availability.
+	PlaylistAvailabilityTag PlaylistAvailability = "tag"
+	// PlaylistAvailabilityHide leaves out channels whose streams all failed
+	// their latest probe.
+	PlaylistAvailabilityHide PlaylistAvailability = "hide"
+)
+
 // NewPlaylistService creates a new PlaylistService with the given dependencies.
 func NewPlaylistService(
 	streamRepo driven.StreamRepository,
@@ -30,72 +71,640 @@ func NewPlaylistService(
 	probeRepo driven.ProbeRepository,
 	window time.Duration,
 ) *PlaylistService {
-	return &PlaylistService{
+	p := &PlaylistService{
 		streamRepo:  streamRepo,
 		channelRepo: channelRepo,
 		probeRepo:   probeRepo,
 		window:      window,
 	}
+	p.lastModified.Store(time.Now().UnixNano())
+	return p
+}
+
+// SetLogoService enables tvg-logo attributes pointing at locally cached logos.
+func (p *PlaylistService) SetLogoService(logos *LogoService) {
+	p.logos = logos
+}
+
+// SetGroupRepository enables manager-defined channel groups in the playlist:
+// channels are listed by group position with their group's name as the
+// group-title, and channels of disabled groups are left out.
+func (p *PlaylistService) SetGroupRepository(groupRepo driven.GroupRepository) {
+	p.groupRepo = groupRepo
+}
+
+// SetRuleRepository enables override rules, which rename, re-group, disable
+// or set the tvg-id of matching entries as the playlist is generated.
+func (p *PlaylistService) SetRuleRepository(ruleRepo driven.RuleRepository) {
+	p.ruleRepo = ruleRepo
+}
+
+// SetStreamLinks signs the stream URLs of playlist entries with links, so
+// they expire and can be verified by the stream handlers.
+func (p *PlaylistService) SetStreamLinks(links *StreamLinks) {
+	p.links = links
+}
+
+// SetAvailability sets how playlists reflect channel availability. The
+// default, PlaylistAvailabilityOff, ignores it.
+func (p *PlaylistService) SetAvailability(mode PlaylistAvailability) {
+	p.availability = mode
+}
+
+// SetCatchupDays sets the catchup window advertised in extended M3U
+// playlists. Zero, the default, omits the catchup tags. It may be called
+// while playlists are being served.
+func (p *PlaylistService) SetCatchupDays(days int) {
+	p.catchupDays.Store(int64(days))
+}
+
+// SetGuide lists the programmes guide fetches in the XMLTV documents, for
+// the channels they describe. The guide is fetched again at most once every
+// playlistGuideRefresh.
+func (p *PlaylistService) SetGuide(guide driven.EPGProgrammeFetcher) {
+	p.guide = guide
+}
+
+// SetRecordingService points the extended M3U entries of channels with
+// recordings at their catchup URL, /ace/catchup/{channel}, so players can
+// play back past programmes from the recordings. The catchup window of
+// those entries reaches back to their channel's oldest recording.
+func (p *PlaylistService) SetRecordingService(recordings *RecordingService) {
+	p.recordings = recordings
+}
+
+// SetMinHealth leaves out of playlists the streams whose health score, as
+// shown at /streams/{infoHash}/health, is below threshold, or whose latest
+// probe failed. Streams that have not been probed are kept. Zero, the
+// default, keeps every stream. A request may set its own minimum with
+// WithMinHealth.
+//
+// The health filter takes precedence over overrides: an unhealthy stream is
+// left out before override rules see it and even if its channel prefers it,
+// and a channel listed once moves on to its next healthy stream.
+func (p *PlaylistService) SetMinHealth(threshold float64) {
+	p.minHealth = threshold
+}
+
+type minHealthKey struct{}
+
+// WithMinHealth returns a copy of ctx that makes playlist generation use
+// threshold instead of the minimum health set with SetMinHealth. Zero keeps
+// every stream.
+func WithMinHealth(ctx context.Context, threshold float64) context.Context {
+	return context.WithValue(ctx, minHealthKey{}, threshold)
+}
+
+// minHealthFor returns the minimum health score for a playlist generated
+// with ctx.
+func (p *PlaylistService) minHealthFor(ctx context.Context) float64 {
+	if threshold, ok := ctx.Value(minHealthKey{}).(float64); ok {
+		return threshold
+	}
+	return p.minHealth
+}
+
+type playlistPrefsKey struct{}
+
+// WithPlaylistPrefs returns a copy of ctx that makes playlist generation
+// apply the given preferences: only the channels of the listed groups are
+// included, channels of disabled groups are kept if asked to, and the
+// streams of each channel with the preferred quality are listed first.
+func WithPlaylistPrefs(ctx context.Context, prefs auth.PlaylistPrefs) context.Context {
+	return context.WithValue(ctx, playlistPrefsKey{}, prefs)
+}
+
+// playlistPrefsFor returns the playlist preferences of ctx, the zero value
+// if none were set.
+func playlistPrefsFor(ctx context.Context) auth.PlaylistPrefs {
+	prefs, _ := ctx.Value(playlistPrefsKey{}).(auth.PlaylistPrefs)
+	return prefs
 }
 
 // GenerateM3U generates an M3U playlist with all available streams.
-// The host parameter is used to build the proxy URL for each stream.
+// The baseURL parameter, such as "http://localhost:8080" or
+// "https://home.example.com/iptv", is used to build the proxy URL for each
+// stream and the url-tvg header pointing players at the /epg.xml guide.
 // Returns a playlist with only the #EXTM3U header if no streams are found.
-func (p *PlaylistService) GenerateM3U(ctx context.Context, host string) (string, error) {
-	streams, err := p.streamRepo.FindAll(ctx)
+func (p *PlaylistService) GenerateM3U(ctx context.Context, baseURL string) (string, error) {
+	data, err := p.Generate(ctx, baseURL, playlist.M3U)
 	if err != nil {
 		return "", err
 	}
+	return string(data), nil
+}
+
+// Generate renders the playlist of all available streams in the given
+// format. Channels are listed by group and name, and the streams of each
+// channel by quality. The baseURL parameter is the externally visible URL of
+// the server, without a trailing slash, and is used to build the proxy URL
+// for each stream and the URLs of the guide and cached logos.
+func (p *PlaylistService) Generate(ctx context.Context, baseURL string, format playlist.Format) ([]byte, error) {
+	pl, err := p.build(ctx, baseURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return encodePlaylist(pl, format)
+}
+
+// GenerateFor renders the playlist of a user in the given format, like
+// Generate but leaving out the channels the user may not see. Channel
+// numbers are the same as in the full playlist.
+func (p *PlaylistService) GenerateFor(ctx context.Context, baseURL string, format playlist.Format, u user.User) ([]byte, error) {
+	pl, err := p.build(ctx, baseURL, u.CanSee, nil)
+	if err != nil {
+		return nil, err
+	}
+	return encodePlaylist(pl, format)
+}
+
+// GenerateForTag renders the playlist of the channels carrying tag in the
+// given format, like Generate but leaving out every other channel. Channel
+// numbers are the same as in the full playlist.
+// Returns channel.ErrInvalidTag if the tag is malformed.
+func (p *PlaylistService) GenerateForTag(ctx context.Context, baseURL string, format playlist.Format, tag string) ([]byte, error) {
+	tag, err := channel.ParseTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	tagged, err := p.channelRepo.FindByTag(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(tagged))
+	for _, ch := range tagged {
+		names[ch.Name()] = true
+	}
+
+	pl, err := p.build(ctx, baseURL, func(channelName, _ string) bool { return names[channelName] }, nil)
+	if err != nil {
+		return nil, err
+	}
+	return encodePlaylist(pl, format)
+}
+
+// GenerateForFavorites renders the playlist of a favorite list in the given
+// format, like Generate but listing only the list's channels, in the order
+// of the list. Channel numbers are the same as in the full playlist. If the
+// list has a guide, the playlist points players at it instead of the full
+// guide.
+func (p *PlaylistService) GenerateForFavorites(ctx context.Context, baseURL string, format playlist.Format, list favorite.List) ([]byte, error) {
+	position := make(map[string]int)
+	for i, name := range list.Channels() {
+		position[name] = i
+	}
 
-	epgIDs := p.buildEPGIDMap(ctx)
+	pl, err := p.build(ctx, baseURL, func(channelName, _ string) bool {
+		_, ok := position[channelName]
+		return ok
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
 
-	sorted := p.sortByQuality(ctx, streams)
+	// Override rules may rename entries, so they are placed by the channel
+	// of their stream
+	streams, err := p.streamRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	channelOf := make(map[string]string, len(streams))
+	for _, s := range streams {
+		channelOf[s.InfoHash()] = s.ChannelName()
+	}
+	slices.SortStableFunc(pl.Entries, func(a, b playlist.Entry) int {
+		return cmp.Compare(position[channelOf[a.InfoHash]], position[channelOf[b.InfoHash]])
+	})
 
-	var builder strings.Builder
-	builder.WriteString("#EXTM3U\n")
+	if list.HasGuide() {
+		pl.GuideURL = baseURL + "/playlist/fav/" + list.ID() + ".xml"
+	}
+	return encodePlaylist(pl, format)
+}
+
+// encodePlaylist renders pl in the given format.
+func encodePlaylist(pl playlist.Playlist, format playlist.Format) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := format.Encode(&buf, pl); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Reasons an entry is left out of a playlist, as reported by Preview.
+const (
+	ExclusionFiltered      = "filtered"       // The user may not see the channel
+	ExclusionDisabledGroup = "disabled_group" // The channel's group is disabled
+	ExclusionDuplicate     = "duplicate"      // The channel is listed once, by alias or preferred variant, and another of its streams was
+	ExclusionDisabled      = "disabled"       // An override rule disabled the entry
+	ExclusionUnavailable   = "unavailable"    // Every stream of the channel failed its latest probe
+	ExclusionUnhealthy     = "unhealthy"      // The stream's health score is below the minimum, or its latest probe failed
+)
+
+// ExcludedEntry is an entry left out of a playlist and why.
+type ExcludedEntry struct {
+	Entry  playlist.Entry
+	Reason string
+}
+
+// PlaylistPreview is what a playlist would list, and what it would leave
+// out, were it generated now.
+type PlaylistPreview struct {
+	Entries  []playlist.Entry
+	Excluded []ExcludedEntry
+}
+
+// Preview runs the whole playlist generation and reports the entries it
+// emits, in order, along with every stream it leaves out and the reason.
+// If u is not nil, the playlist previewed is the user's.
+func (p *PlaylistService) Preview(ctx context.Context, baseURL string, u *user.User) (PlaylistPreview, error) {
+	var visible func(channelName, groupID string) bool
+	if u != nil {
+		visible = u.CanSee
+	}
+
+	preview := PlaylistPreview{Excluded: []ExcludedEntry{}}
+	pl, err := p.build(ctx, baseURL, visible, func(e playlist.Entry, reason string) {
+		preview.Excluded = append(preview.Excluded, ExcludedEntry{Entry: e, Reason: reason})
+	})
+	if err != nil {
+		return PlaylistPreview{}, err
+	}
+	preview.Entries = pl.Entries
+	return preview, nil
+}
+
+// build collects the playlist entries of all available streams. If visible
+// is not nil, only the streams of channels it accepts, by name and group ID,
+// are included. If exclude is not nil, it is called with every stream left
+// out and the reason.
+func (p *PlaylistService) build(ctx context.Context, baseURL string, visible func(channelName, groupID string) bool, exclude func(e playlist.Entry, reason string)) (playlist.Playlist, error) {
+	streams, err := p.streamRepo.FindAll(ctx)
+	if err != nil {
+		return playlist.Playlist{}, err
+	}
+
+	channels := p.buildChannelMap(ctx)
+	groups := p.buildGroupMap(ctx)
+
+	prefs := playlistPrefsFor(ctx)
+	byQuality, health := p.sortByQuality(ctx, streams, channels)
+	sorted := p.orderByGroup(byQuality, channels, groups, prefs.IncludeDisabled())
+	numbers := channelNumbers(sorted, channels)
+
+	pl := playlist.Playlist{
+		GuideURL:    baseURL + "/epg.xml",
+		CatchupDays: int(p.catchupDays.Load()),
+		Entries:     make([]playlist.Entry, 0, len(sorted)),
+	}
+
+	if exclude != nil && len(sorted) < len(byQuality) {
+		kept := make(map[string]bool, len(sorted))
+		for _, s := range sorted {
+			kept[s.InfoHash()] = true
+		}
+		for _, s := range byQuality {
+			if !kept[s.InfoHash()] {
+				exclude(p.newEntry(ctx, baseURL, s, channels, groups, 0), ExclusionDisabledGroup)
+			}
+		}
+	}
+
+	rules := p.loadRules(ctx)
+	availability := p.channelAvailability(ctx, sorted)
+	catchup := p.catchupWindows(ctx)
+	minHealth := p.minHealthFor(ctx)
+
+	// Channels with an alias are listed once, under a URL that picks their
+	// best stream when played instead of pinning one by infohash, and so are
+	// channels that list only their preferred variant
+	listedOnce := make(map[string]bool)
 
 	for _, s := range sorted {
-		tvgID := s.ChannelName()
-		if id, ok := epgIDs[s.ChannelName()]; ok {
-			tvgID = id
+		reason := ""
+		switch {
+		case visible != nil && !visible(s.ChannelName(), channels[s.ChannelName()].Group()),
+			!prefs.ShowsGroup(channels[s.ChannelName()].Group()):
+			reason = ExclusionFiltered
+		case listedOnce[s.ChannelName()]:
+			reason = ExclusionDuplicate
+		case p.availability == PlaylistAvailabilityHide && availability[s.ChannelName()] == probe.AvailabilityUnavailable:
+			reason = ExclusionUnavailable
+		case minHealth > 0 && !health[s.InfoHash()].healthy(minHealth):
+			reason = ExclusionUnhealthy
+		}
+		if reason != "" {
+			if exclude != nil {
+				exclude(p.newEntry(ctx, baseURL, s, channels, groups, numbers[s.ChannelName()]), reason)
+			}
+			continue
+		}
+
+		entry := p.newEntry(ctx, baseURL, s, channels, groups, numbers[s.ChannelName()])
+		if !applyRules(rules, &entry) {
+			if exclude != nil {
+				exclude(entry, ExclusionDisabled)
+			}
+			continue
+		}
+		ch := channels[s.ChannelName()]
+		once := len(ch.Aliases()) > 0 || ch.Variants() == channel.VariantsPreferred
+		if !once {
+			entry.Quality = string(ch.StreamQuality(s.InfoHash()))
+		}
+		if p.availability == PlaylistAvailabilityTag {
+			entry.Availability = string(availability[s.ChannelName()])
+		}
+		if oldest, ok := catchup[s.ChannelName()]; ok {
+			p.setCatchup(ctx, baseURL, &entry, s.ChannelName(), oldest)
 		}
+		pl.Entries = append(pl.Entries, entry)
+		listedOnce[s.ChannelName()] = once
+	}
+
+	return pl, nil
+}
+
+// newEntry returns the playlist entry of a stream, before override rules.
+func (p *PlaylistService) newEntry(ctx context.Context, baseURL string, s stream.Stream, channels map[string]channel.Channel, groups map[string]group.Group, number int) playlist.Entry {
+	entry := playlist.Entry{
+		Number:      number,
+		ChannelName: s.ChannelName(),
+		TVGID:       s.ChannelName(),
+		InfoHash:    s.InfoHash(),
+		URL:         baseURL + "/ace/getstream?id=" + s.InfoHash(),
+		Source:      s.Source(),
+	}
+	if p.links != nil {
+		entry.URL = p.links.sign(ctx, entry.URL, streamResource(s.InfoHash()))
+	}
+	ch, ok := channels[s.ChannelName()]
+	if !ok {
+		return entry
+	}
+	if m := ch.EPGMapping(); m != nil && m.EPGID() != "" {
+		entry.TVGID = m.EPGID()
+		if p.logos != nil {
+			if path, ok := p.logos.LogoPath(ctx, entry.TVGID); ok {
+				entry.LogoURL = baseURL + path
+			}
+		}
+	}
+	if g, ok := groups[ch.Group()]; ok {
+		entry.Group = g.Name()
+	}
+	entry.TVGShift = ch.TVGShift().Hours()
+	entry.NumberAssigned = ch.Number() != 0
+	if aliases := ch.Aliases(); len(aliases) > 0 {
+		entry.URL = baseURL + "/ace/c/" + aliases[0]
+		if p.links != nil {
+			entry.URL = p.links.sign(ctx, entry.URL, aliasResource(aliases[0]))
+		}
+	}
+	return entry
+}
+
+// catchupWindows returns the start of the oldest recording of each channel
+// players can play back. Without a recording service, or on error, it
+// returns none. Errors are logged.
+func (p *PlaylistService) catchupWindows(ctx context.Context) map[string]time.Time {
+	if p.recordings == nil {
+		return nil
+	}
+
+	windows, err := p.recordings.CatchupWindows(ctx)
+	if err != nil {
+		slog.Warn("failed to fetch recordings for catchup", "error", err)
+		return nil
+	}
+	return windows
+}
+
+// setCatchup points entry at the catchup URL of the channel named
+// channelName, whose oldest recording started at oldest.
+func (p *PlaylistService) setCatchup(ctx context.Context, baseURL string, entry *playlist.Entry, channelName string, oldest time.Time) {
+	source := baseURL + "/ace/catchup/" + url.PathEscape(channelName)
+	if p.links != nil {
+		source = p.links.sign(ctx, source, catchupResource(channelName))
+	}
+	sep := "?"
+	if strings.Contains(source, "?") {
+		sep = "&"
+	}
+	entry.CatchupSource = source + sep + "utc={utc}&utcend={utcend}"
+	entry.CatchupDays = int(math.Ceil(time.Since(oldest).Hours() / 24))
+}
+
+// channelAvailability aggregates the latest probe result of each stream
+// within the rolling window into the availability of its channel, keyed by
+// channel name. With availability off it returns nil.
+func (p *PlaylistService) channelAvailability(ctx context.Context, streams []stream.Stream) map[string]probe.Availability {
+	if p.availability == "" || p.availability == PlaylistAvailabilityOff {
+		return nil
+	}
+
+	since := time.Now().Add(-p.window)
+	latest := make(map[string][]probe.Result)
+	for _, s := range streams {
+		if _, ok := latest[s.ChannelName()]; !ok {
+			latest[s.ChannelName()] = nil
+		}
+		results, err := p.probeRepo.FindByInfoHashSince(ctx, s.InfoHash(), since)
+		if err != nil || len(results) == 0 {
+			continue
+		}
+		latest[s.ChannelName()] = append(latest[s.ChannelName()], results[0])
+	}
+
+	availability := make(map[string]probe.Availability, len(latest))
+	for name, results := range latest {
+		availability[name] = probe.AggregateAvailability(results)
+	}
+	return availability
+}
+
+// loadRules fetches the override rules in the order they apply. Without a
+// rule repository, or on error, it returns none. Errors are logged.
+func (p *PlaylistService) loadRules(ctx context.Context) []rule.Rule {
+	if p.ruleRepo == nil {
+		return nil
+	}
+
+	rules, err := p.ruleRepo.FindAll(ctx)
+	if err != nil {
+		slog.Warn("failed to fetch override rules", "error", err)
+		return nil
+	}
+	return rules
+}
+
+// entrySubject returns the part of an entry that override rules see.
+func entrySubject(e playlist.Entry) rule.Subject {
+	return rule.Subject{ChannelName: e.ChannelName, Group: e.Group, Source: e.Source, TVGID: e.TVGID}
+}
+
+// applyRules transforms the entry by the rules and reports whether it stays
+// in the playlist.
+func applyRules(rules []rule.Rule, e *playlist.Entry) bool {
+	if len(rules) == 0 {
+		return true
+	}
+	s, keep := rule.ApplyAll(rules, entrySubject(*e))
+	e.ChannelName, e.Group, e.TVGID = s.ChannelName, s.Group, s.TVGID
+	return keep
+}
 
-		fmt.Fprintf(&builder, "#EXTINF:-1 tvg-id=\"%s\",%s - %s\n",
-			tvgID,
-			s.ChannelName(),
-			s.InfoHash())
+// RuleChange describes how an override rule would change a playlist entry.
+type RuleChange struct {
+	InfoHash string
+	Before   rule.Subject
+	After    rule.Subject
+	Disabled bool
+}
 
-		fmt.Fprintf(&builder, "http://%s/ace/getstream?id=%s\n",
-			host,
-			s.InfoHash())
+// PreviewRule reports the entries the rule would change if it were added
+// after the existing rules, without changing anything.
+func (p *PlaylistService) PreviewRule(ctx context.Context, r rule.Rule) ([]RuleChange, error) {
+	pl, err := p.build(ctx, "", nil, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	return builder.String(), nil
+	changes := []RuleChange{}
+	for _, e := range pl.Entries {
+		before := entrySubject(e)
+		if !r.Matches(before) {
+			continue
+		}
+		after, keep := r.Apply(before)
+		if keep && after == before {
+			continue
+		}
+		changes = append(changes, RuleChange{InfoHash: e.InfoHash, Before: before, After: after, Disabled: !keep})
+	}
+	return changes, nil
 }
 
-// buildEPGIDMap fetches all channels and returns a map from channel name to EPG ID.
-// Channels without an EPG mapping are omitted. Errors are logged and result in an empty map.
-func (p *PlaylistService) buildEPGIDMap(ctx context.Context) map[string]string {
+// channelNumbers returns the number of each channel of the ordered streams.
+// Channels keep the number assigned to them; the others are numbered in
+// order after the highest assigned number, so numbers never collide.
+func channelNumbers(ordered []stream.Stream, channels map[string]channel.Channel) map[string]int {
+	next := 1
+	for _, ch := range channels {
+		if ch.Number() >= next {
+			next = ch.Number() + 1
+		}
+	}
+
+	numbers := make(map[string]int)
+	for _, s := range ordered {
+		name := s.ChannelName()
+		if _, ok := numbers[name]; ok {
+			continue
+		}
+		if n := channels[name].Number(); n != 0 {
+			numbers[name] = n
+			continue
+		}
+		numbers[name] = next
+		next++
+	}
+	return numbers
+}
+
+// compareChannelNames orders channel names as channel.CompareOrder orders
+// channels. Names missing from channels count as unnumbered channels.
+func compareChannelNames(channels map[string]channel.Channel, a, b string) int {
+	chA, okA := channels[a]
+	if !okA {
+		chA = channel.ReconstructChannel(a, channel.StatusActive, nil)
+	}
+	chB, okB := channels[b]
+	if !okB {
+		chB = channel.ReconstructChannel(b, channel.StatusActive, nil)
+	}
+	return channel.CompareOrder(chA, chB)
+}
+
+// buildChannelMap fetches all channels and returns them by name.
+// Errors are logged and result in an empty map.
+func (p *PlaylistService) buildChannelMap(ctx context.Context) map[string]channel.Channel {
 	channels, err := p.channelRepo.FindAll(ctx)
 	if err != nil {
 		slog.Warn("failed to fetch channels for EPG ID mapping", "error", err)
 		return nil
 	}
 
-	epgIDs := make(map[string]string, len(channels))
+	byName := make(map[string]channel.Channel, len(channels))
 	for _, ch := range channels {
-		if m := ch.EPGMapping(); m != nil && m.EPGID() != "" {
-			epgIDs[ch.Name()] = m.EPGID()
+		byName[ch.Name()] = ch
+	}
+	return byName
+}
+
+// buildGroupMap fetches all groups and returns them by ID. Without a group
+// repository, or on error, it returns an empty map. Errors are logged.
+func (p *PlaylistService) buildGroupMap(ctx context.Context) map[string]group.Group {
+	if p.groupRepo == nil {
+		return nil
+	}
+
+	groups, err := p.groupRepo.FindAll(ctx)
+	if err != nil {
+		slog.Warn("failed to fetch channel groups", "error", err)
+		return nil
+	}
+
+	byID := make(map[string]group.Group, len(groups))
+	for _, g := range groups {
+		byID[g.ID()] = g
+	}
+	return byID
+}
+
+// orderByGroup drops the streams of channels in disabled groups, unless
+// includeDisabled is set, and lists the rest by group position, keeping
+// their existing order within a group. Streams of ungrouped channels come
+// last.
+func (p *PlaylistService) orderByGroup(streams []stream.Stream, channels map[string]channel.Channel, groups map[string]group.Group, includeDisabled bool) []stream.Stream {
+	if len(groups) == 0 {
+		return streams
+	}
+
+	ungrouped := len(groups)
+	rank := func(s stream.Stream) (int, int, bool) {
+		g, ok := groups[channels[s.ChannelName()].Group()]
+		if !ok {
+			return ungrouped, 0, true
+		}
+		return 0, g.Position(), g.IsEnabled()
+	}
+
+	kept := make([]stream.Stream, 0, len(streams))
+	for _, s := range streams {
+		if _, _, enabled := rank(s); enabled || includeDisabled {
+			kept = append(kept, s)
 		}
 	}
-	return epgIDs
+
+	slices.SortStableFunc(kept, func(a, b stream.Stream) int {
+		aLast, aPos, _ := rank(a)
+		bLast, bPos, _ := rank(b)
+		if c := cmp.Compare(aLast, bLast); c != 0 {
+			return c
+		}
+		return cmp.Compare(aPos, bPos)
+	})
+	return kept
 }
 
-// sortByQuality groups streams by channel name, sorts channel groups
-// alphabetically, and within each group sorts streams by quality score
-// descending. Streams without probe data sort after scored streams,
-// with infohash as the final tiebreaker.
-func (p *PlaylistService) sortByQuality(ctx context.Context, streams []stream.Stream) []stream.Stream {
+// sortByQuality groups streams by channel name, sorts channel groups by
+// channel number and then name, and within each group sorts streams by the
+// channel's quality preference and then by quality score descending. Streams
+// without probe data sort after scored streams, with infohash as the final
+// tiebreaker. It also returns the health of every probed stream, by
+// infohash.
+func (p *PlaylistService) sortByQuality(ctx context.Context, streams []stream.Stream, channels map[string]channel.Channel) ([]stream.Stream, map[string]streamHealth) {
 	groups := make(map[string][]stream.Stream)
 	var channelNames []string
 	for _, s := range streams {
@@ -106,16 +715,49 @@ func (p *PlaylistService) sortByQuality(ctx context.Context, streams []stream.St
 		groups[name] = append(groups[name], s)
 	}
 
-	slices.Sort(channelNames)
+	slices.SortFunc(channelNames, func(a, b string) int {
+		return compareChannelNames(channels, a, b)
+	})
 
 	since := time.Now().Add(-p.window)
+	health := make(map[string]streamHealth, len(streams))
 
 	var result []stream.Stream
 	for _, name := range channelNames {
-		result = append(result, p.sortGroupByQuality(ctx, groups[name], since)...)
+		group := p.sortGroupByQuality(ctx, groups[name], since, health)
+		if ch, ok := channels[name]; ok {
+			slices.SortStableFunc(group, func(a, b stream.Stream) int {
+				return cmp.Compare(ch.QualityRank(a.InfoHash()), ch.QualityRank(b.InfoHash()))
+			})
+			if preferred := channel.Quality(playlistPrefsFor(ctx).Quality()); preferred != "" {
+				rank := func(s stream.Stream) int {
+					if ch.StreamQuality(s.InfoHash()) == preferred {
+						return 0
+					}
+					return 1
+				}
+				slices.SortStableFunc(group, func(a, b stream.Stream) int {
+					return cmp.Compare(rank(a), rank(b))
+				})
+			}
+		}
+		result = append(result, group...)
 	}
 
-	return result
+	return result, health
+}
+
+// streamHealth is the health of a probed stream.
+type streamHealth struct {
+	probed    bool
+	score     float64
+	available bool // Whether its latest probe succeeded
+}
+
+// healthy reports whether the stream is healthy enough for a playlist with
+// the given minimum score. Streams that have not been probed are.
+func (h streamHealth) healthy(threshold float64) bool {
+	return !h.probed || (h.available && h.score >= threshold)
 }
 
 type scoredStream struct {
@@ -125,9 +767,11 @@ type scoredStream struct {
 }
 
 // sortGroupByQuality sorts streams within a single channel group by
-// quality score descending, using per-group normalization ceilings.
-func (p *PlaylistService) sortGroupByQuality(ctx context.Context, group []stream.Stream, since time.Time) []stream.Stream {
+// quality score descending, using per-group normalization ceilings, and
+// records the health of each probed stream in health.
+func (p *PlaylistService) sortGroupByQuality(ctx context.Context, group []stream.Stream, since time.Time, health map[string]streamHealth) []stream.Stream {
 	metricsMap := make(map[string]probe.Metrics, len(group))
+	latest := make(map[string]probe.Result, len(group))
 	for _, s := range group {
 		results, err := p.probeRepo.FindByInfoHashSince(ctx, s.InfoHash(), since)
 		if err != nil {
@@ -138,6 +782,7 @@ func (p *PlaylistService) sortGroupByQuality(ctx context.Context, group []stream
 			continue
 		}
 		metricsMap[s.InfoHash()] = m
+		latest[s.InfoHash()] = results[0]
 	}
 
 	var maxSpeed, maxPeers float64
@@ -156,6 +801,7 @@ func (p *PlaylistService) sortGroupByQuality(ctx context.Context, group []stream
 		if ok {
 			score := probe.ComputeQualityScore(m, maxSpeed, maxPeers)
 			scored = append(scored, scoredStream{s: s, score: score, hasScore: true})
+			health[s.InfoHash()] = streamHealth{probed: true, score: score, available: latest[s.InfoHash()].Available()}
 		} else {
 			scored = append(scored, scoredStream{s: s, hasScore: false})
 		}
@@ -3,19 +3,28 @@ package application
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/alorle/iptv-manager/internal/auth"
 	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/logo"
+	"github.com/alorle/iptv-manager/internal/playlist"
 	"github.com/alorle/iptv-manager/internal/probe"
+	"github.com/alorle/iptv-manager/internal/recording"
+	"github.com/alorle/iptv-manager/internal/rule"
 	"github.com/alorle/iptv-manager/internal/stream"
+	"github.com/alorle/iptv-manager/internal/user"
 )
 
 func TestPlaylistService_GenerateM3U(t *testing.T) {
 	t.Run("generates M3U playlist with streams successfully", func(t *testing.T) {
-		st1, _ := stream.NewStream("abc123", "Channel1", "")
-		st2, _ := stream.NewStream("def456", "Channel2", "")
+		st1, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+		st2, _ := stream.NewStream("6465663435360000000000000000000000000000", "Channel2", "")
 		expectedStreams := []stream.Stream{st1, st2}
 
 		streamRepo := &mockStreamRepository{
@@ -25,29 +34,29 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
 		// Check header
-		if !strings.HasPrefix(m3u, "#EXTM3U\n") {
-			t.Error("M3U playlist should start with #EXTM3U header")
+		if !strings.HasPrefix(m3u, "#EXTM3U url-tvg=\"http://localhost:8080/epg.xml\"\n") {
+			t.Error("M3U playlist should start with #EXTM3U header pointing at the XMLTV guide")
 		}
 
 		// Check first stream entry
-		if !strings.Contains(m3u, `#EXTINF:-1 tvg-id="Channel1",Channel1 - abc123`) {
+		if !strings.Contains(m3u, `#EXTINF:-1 tvg-id="Channel1",Channel1 - 6162633132330000000000000000000000000000`) {
 			t.Error("M3U playlist should contain first stream metadata")
 		}
-		if !strings.Contains(m3u, "http://localhost:8080/ace/getstream?id=abc123") {
+		if !strings.Contains(m3u, "http://localhost:8080/ace/getstream?id=6162633132330000000000000000000000000000") {
 			t.Error("M3U playlist should contain first stream URL")
 		}
 
 		// Check second stream entry
-		if !strings.Contains(m3u, `#EXTINF:-1 tvg-id="Channel2",Channel2 - def456`) {
+		if !strings.Contains(m3u, `#EXTINF:-1 tvg-id="Channel2",Channel2 - 6465663435360000000000000000000000000000`) {
 			t.Error("M3U playlist should contain second stream metadata")
 		}
-		if !strings.Contains(m3u, "http://localhost:8080/ace/getstream?id=def456") {
+		if !strings.Contains(m3u, "http://localhost:8080/ace/getstream?id=6465663435360000000000000000000000000000") {
 			t.Error("M3U playlist should contain second stream URL")
 		}
 	})
@@ -60,13 +69,13 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
 		// Should only contain header
-		if m3u != "#EXTM3U\n" {
+		if m3u != "#EXTM3U url-tvg=\"http://localhost:8080/epg.xml\"\n" {
 			t.Errorf("expected only #EXTM3U header, got %q", m3u)
 		}
 	})
@@ -80,14 +89,14 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
 
-		_, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		_, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if !errors.Is(err, expectedError) {
 			t.Errorf("expected repository error, got %v", err)
 		}
 	})
 
 	t.Run("uses correct host in stream URLs", func(t *testing.T) {
-		st1, _ := stream.NewStream("xyz789", "TestChannel", "")
+		st1, _ := stream.NewStream("78797a3738390000000000000000000000000000", "TestChannel", "")
 		expectedStreams := []stream.Stream{st1}
 
 		streamRepo := &mockStreamRepository{
@@ -97,21 +106,43 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "example.com:9000")
+		m3u, err := service.GenerateM3U(context.Background(), "http://example.com:9000")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
 		// Check that the custom host is used
-		if !strings.Contains(m3u, "http://example.com:9000/ace/getstream?id=xyz789") {
+		if !strings.Contains(m3u, "http://example.com:9000/ace/getstream?id=78797a3738390000000000000000000000000000") {
 			t.Error("M3U playlist should use the provided host in stream URLs")
 		}
 	})
 
+	t.Run("uses a base URL behind a reverse proxy", func(t *testing.T) {
+		st1, _ := stream.NewStream("78797a3738390000000000000000000000000000", "TestChannel", "")
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				return []stream.Stream{st1}, nil
+			},
+		}
+		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+
+		m3u, err := service.GenerateM3U(context.Background(), "https://home.example.com/iptv")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if !strings.Contains(m3u, `url-tvg="https://home.example.com/iptv/epg.xml"`) {
+			t.Errorf("expected the guide URL under the base URL, got %q", m3u)
+		}
+		if !strings.Contains(m3u, "https://home.example.com/iptv/ace/getstream?id=78797a3738390000000000000000000000000000") {
+			t.Errorf("expected stream URLs under the base URL, got %q", m3u)
+		}
+	})
+
 	t.Run("sorts streams by quality score within channel group", func(t *testing.T) {
 		now := time.Now()
-		good, _ := stream.NewStream("hash_good", "SameChannel", "")
-		poor, _ := stream.NewStream("hash_poor", "SameChannel", "")
+		good, _ := stream.NewStream("686173685f676f6f640000000000000000000000", "SameChannel", "")
+		poor, _ := stream.NewStream("686173685f706f6f720000000000000000000000", "SameChannel", "")
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
 				return []stream.Stream{poor, good}, nil
@@ -119,7 +150,7 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		probeRepo := &mockProbeRepository{
 			findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
-				if infoHash == "hash_good" {
+				if infoHash == "686173685f676f6f640000000000000000000000" {
 					return []probe.Result{
 						probe.ReconstructResult(infoHash, now, true, time.Second, 20, 200000, "dl", ""),
 						probe.ReconstructResult(infoHash, now.Add(-30*time.Minute), true, time.Second, 20, 200000, "dl", ""),
@@ -133,13 +164,13 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, probeRepo, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
-		goodIdx := strings.Index(m3u, "hash_good")
-		poorIdx := strings.Index(m3u, "hash_poor")
+		goodIdx := strings.Index(m3u, "686173685f676f6f640000000000000000000000")
+		poorIdx := strings.Index(m3u, "686173685f706f6f720000000000000000000000")
 		if goodIdx < 0 || poorIdx < 0 {
 			t.Fatal("both streams should appear in the playlist")
 		}
@@ -150,8 +181,8 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 
 	t.Run("streams without probe data sort after scored streams", func(t *testing.T) {
 		now := time.Now()
-		scored, _ := stream.NewStream("hash_scored", "Chan", "")
-		unscored, _ := stream.NewStream("hash_unscored", "Chan", "")
+		scored, _ := stream.NewStream("686173685f73636f726564000000000000000000", "Chan", "")
+		unscored, _ := stream.NewStream("686173685f756e73636f72656400000000000000", "Chan", "")
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
 				return []stream.Stream{unscored, scored}, nil
@@ -159,7 +190,7 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		probeRepo := &mockProbeRepository{
 			findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
-				if infoHash == "hash_scored" {
+				if infoHash == "686173685f73636f726564000000000000000000" {
 					return []probe.Result{
 						probe.ReconstructResult(infoHash, now, true, time.Second, 10, 100000, "dl", ""),
 					}, nil
@@ -169,13 +200,13 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, probeRepo, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
-		scoredIdx := strings.Index(m3u, "hash_scored")
-		unscoredIdx := strings.Index(m3u, "hash_unscored")
+		scoredIdx := strings.Index(m3u, "686173685f73636f726564000000000000000000")
+		unscoredIdx := strings.Index(m3u, "686173685f756e73636f72656400000000000000")
 		if scoredIdx < 0 || unscoredIdx < 0 {
 			t.Fatal("both streams should appear in the playlist")
 		}
@@ -185,8 +216,8 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 	})
 
 	t.Run("degrades to infohash sort when no probe data exists", func(t *testing.T) {
-		s1, _ := stream.NewStream("zzz999", "Chan", "")
-		s2, _ := stream.NewStream("aaa111", "Chan", "")
+		s1, _ := stream.NewStream("7a7a7a3939390000000000000000000000000000", "Chan", "")
+		s2, _ := stream.NewStream("6161613131310000000000000000000000000000", "Chan", "")
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
 				return []stream.Stream{s1, s2}, nil
@@ -194,20 +225,20 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
 
-		idx1 := strings.Index(m3u, "aaa111")
-		idx2 := strings.Index(m3u, "zzz999")
+		idx1 := strings.Index(m3u, "6161613131310000000000000000000000000000")
+		idx2 := strings.Index(m3u, "7a7a7a3939390000000000000000000000000000")
 		if idx1 >= idx2 {
 			t.Error("when no probe data exists, streams should sort by infohash ascending")
 		}
 	})
 
 	t.Run("probeRepo error degrades gracefully", func(t *testing.T) {
-		s1, _ := stream.NewStream("abc123", "Channel1", "")
+		s1, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
 				return []stream.Stream{s1}, nil
@@ -220,19 +251,19 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, probeRepo, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if err != nil {
 			t.Fatalf("expected no error despite probeRepo failure, got %v", err)
 		}
 
-		if !strings.Contains(m3u, "abc123") {
+		if !strings.Contains(m3u, "6162633132330000000000000000000000000000") {
 			t.Error("stream should still appear in playlist despite probe error")
 		}
 	})
 
 	t.Run("uses EPG ID from channel mapping as tvg-id", func(t *testing.T) {
-		st1, _ := stream.NewStream("abc123", "La 1", "")
-		st2, _ := stream.NewStream("def456", "Antena 3", "")
+		st1, _ := stream.NewStream("6162633132330000000000000000000000000000", "La 1", "")
+		st2, _ := stream.NewStream("6465663435360000000000000000000000000000", "Antena 3", "")
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
 				return []stream.Stream{st1, st2}, nil
@@ -251,7 +282,7 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -262,13 +293,53 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		if !strings.Contains(m3u, `tvg-id="Antena3.es"`) {
 			t.Errorf("expected tvg-id Antena3.es, got:\n%s", m3u)
 		}
-		if !strings.Contains(m3u, ",La 1 - abc123") {
+		if !strings.Contains(m3u, ",La 1 - 6162633132330000000000000000000000000000") {
 			t.Error("channel display name should still be the channel name")
 		}
 	})
 
+	t.Run("adds tvg-logo for channels with a cached logo", func(t *testing.T) {
+		st1, _ := stream.NewStream("6162633132330000000000000000000000000000", "La 1", "")
+		st2, _ := stream.NewStream("6465663435360000000000000000000000000000", "Antena 3", "")
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				return []stream.Stream{st1, st2}, nil
+			},
+		}
+
+		epgMapping, _ := channel.NewEPGMapping("La1.es", channel.MappingAuto, time.Now())
+		epgMapping2, _ := channel.NewEPGMapping("Antena3.es", channel.MappingAuto, time.Now())
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{
+					channel.ReconstructChannel("La 1", channel.StatusActive, &epgMapping),
+					channel.ReconstructChannel("Antena 3", channel.StatusActive, &epgMapping2),
+				}, nil
+			},
+		}
+
+		logos := NewLogoService(&mockLogoFetcher{}, newMockLogoStore(), newTestLogger())
+		logos.Refresh(context.Background(), map[string]string{"La1.es": "http://upstream/la1.png"})
+
+		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+		service.SetLogoService(logos)
+
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		want := `#EXTINF:-1 tvg-id="La1.es" tvg-logo="http://localhost:8080/logos/` + logo.Key("La1.es") + `.png",La 1 - 6162633132330000000000000000000000000000`
+		if !strings.Contains(m3u, want) {
+			t.Errorf("expected line %q, got:\n%s", want, m3u)
+		}
+		if !strings.Contains(m3u, `#EXTINF:-1 tvg-id="Antena3.es",Antena 3 - 6465663435360000000000000000000000000000`) {
+			t.Errorf("expected no tvg-logo for uncached logo, got:\n%s", m3u)
+		}
+	})
+
 	t.Run("falls back to channel name when no EPG mapping exists", func(t *testing.T) {
-		st1, _ := stream.NewStream("abc123", "NoEPG Channel", "")
+		st1, _ := stream.NewStream("6162633132330000000000000000000000000000", "NoEPG Channel", "")
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
 				return []stream.Stream{st1}, nil
@@ -283,7 +354,7 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -294,7 +365,7 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 	})
 
 	t.Run("channelRepo error degrades gracefully using channel names", func(t *testing.T) {
-		st1, _ := stream.NewStream("abc123", "Channel1", "")
+		st1, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
 				return []stream.Stream{st1}, nil
@@ -307,7 +378,7 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 		}
 		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
 
-		m3u, err := service.GenerateM3U(context.Background(), "localhost:8080")
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
 		if err != nil {
 			t.Fatalf("expected no error despite channelRepo failure, got %v", err)
 		}
@@ -316,4 +387,535 @@ func TestPlaylistService_GenerateM3U(t *testing.T) {
 			t.Errorf("expected tvg-id to fall back to channel name, got:\n%s", m3u)
 		}
 	})
+
+	t.Run("orders channels by group and emits group-title", func(t *testing.T) {
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				a, _ := stream.NewStream("6161610000000000000000000000000000000000", "Alpha", "")
+				b, _ := stream.NewStream("6262620000000000000000000000000000000000", "Beta", "")
+				c, _ := stream.NewStream("6363630000000000000000000000000000000000", "Gamma", "")
+				d, _ := stream.NewStream("6464640000000000000000000000000000000000", "Delta", "")
+				return []stream.Stream{a, b, c, d}, nil
+			},
+		}
+
+		alpha := channel.ReconstructChannel("Alpha", channel.StatusActive, nil)
+		beta := channel.ReconstructChannel("Beta", channel.StatusActive, nil)
+		beta.SetGroup("news")
+		gamma := channel.ReconstructChannel("Gamma", channel.StatusActive, nil)
+		gamma.SetGroup("sports")
+		delta := channel.ReconstructChannel("Delta", channel.StatusActive, nil)
+		delta.SetGroup("hidden")
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{alpha, beta, gamma, delta}, nil
+			},
+		}
+
+		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+		service.SetGroupRepository(newMemGroupRepository(
+			group.ReconstructGroup("sports", "Sports", 0, true),
+			group.ReconstructGroup("news", "News", 1, true),
+			group.ReconstructGroup("hidden", "Hidden", 2, false),
+		))
+
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		gammaLine := `#EXTINF:-1 tvg-id="Gamma" group-title="Sports",Gamma - 6363630000000000000000000000000000000000`
+		betaLine := `#EXTINF:-1 tvg-id="Beta" group-title="News",Beta - 6262620000000000000000000000000000000000`
+		alphaLine := `#EXTINF:-1 tvg-id="Alpha",Alpha - 6161610000000000000000000000000000000000`
+		for _, line := range []string{gammaLine, betaLine, alphaLine} {
+			if !strings.Contains(m3u, line) {
+				t.Errorf("expected line %q, got:\n%s", line, m3u)
+			}
+		}
+		if strings.Index(m3u, gammaLine) > strings.Index(m3u, betaLine) || strings.Index(m3u, betaLine) > strings.Index(m3u, alphaLine) {
+			t.Errorf("expected grouped channels by position with ungrouped last, got:\n%s", m3u)
+		}
+		if strings.Contains(m3u, "6464640000000000000000000000000000000000") {
+			t.Errorf("expected channels of disabled groups to be left out, got:\n%s", m3u)
+		}
+	})
+
+	t.Run("numbers channels in the extended M3U format", func(t *testing.T) {
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				a1, _ := stream.NewStream("6161610000000000000000000000000000000000", "Alpha", "")
+				a2, _ := stream.NewStream("6162620000000000000000000000000000000000", "Alpha", "")
+				b, _ := stream.NewStream("6262620000000000000000000000000000000000", "Beta", "")
+				return []stream.Stream{b, a2, a1}, nil
+			},
+		}
+		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+
+		data, err := service.Generate(context.Background(), "http://localhost:8080", playlist.ExtendedM3U)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got := string(data)
+		for _, want := range []string{
+			`tvg-chno="1" tvg-name="Alpha",Alpha - 6161610000000000000000000000000000000000`,
+			`tvg-chno="1" tvg-name="Alpha",Alpha - 6162620000000000000000000000000000000000`,
+			`tvg-chno="2" tvg-name="Beta",Beta - 6262620000000000000000000000000000000000`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected %q, got:\n%s", want, got)
+			}
+		}
+	})
+
+	t.Run("points channels with recordings at their catchup URL", func(t *testing.T) {
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				a, _ := stream.NewStream("6161610000000000000000000000000000000000", "Alpha TV", "")
+				b, _ := stream.NewStream("6262620000000000000000000000000000000000", "Beta", "")
+				return []stream.Stream{a, b}, nil
+			},
+		}
+		start := time.Now().Add(-36 * time.Hour)
+		rec := recording.ReconstructRecording("rec-1", "Alpha TV", start, start.Add(time.Hour), recording.StatusCompleted, 1024, "", start)
+		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+		service.SetCatchupDays(1)
+		service.SetRecordingService(newCatchupTestService(t, 1024, rec))
+
+		data, err := service.Generate(context.Background(), "http://localhost:8080", playlist.ExtendedM3U)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got := string(data)
+		for _, want := range []string{
+			`catchup="default" catchup-days="2" catchup-source="http://localhost:8080/ace/catchup/Alpha%20TV?utc={utc}&utcend={utcend}",Alpha TV - `,
+			`catchup="default" catchup-days="1",Beta - `,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected %q, got:\n%s", want, got)
+			}
+		}
+	})
+
+	t.Run("lists aliased channels once under their alias URL", func(t *testing.T) {
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				a1, _ := stream.NewStream("6161610000000000000000000000000000000000", "Alpha", "")
+				a2, _ := stream.NewStream("6162620000000000000000000000000000000000", "Alpha", "")
+				b, _ := stream.NewStream("6262620000000000000000000000000000000000", "Beta", "")
+				return []stream.Stream{a1, a2, b}, nil
+			},
+		}
+		alpha, _ := channel.NewChannel("Alpha")
+		_ = alpha.SetAliases([]string{"alpha", "a"})
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{alpha}, nil
+			},
+		}
+		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if got := strings.Count(m3u, "http://localhost:8080/ace/c/alpha\n"); got != 1 {
+			t.Errorf("expected one entry for the aliased channel, got %d:\n%s", got, m3u)
+		}
+		if strings.Contains(m3u, "getstream?id=6161610000000000000000000000000000000000") || strings.Contains(m3u, "getstream?id=6162620000000000000000000000000000000000") {
+			t.Errorf("expected no infohash URLs for the aliased channel, got:\n%s", m3u)
+		}
+		if !strings.Contains(m3u, "http://localhost:8080/ace/getstream?id=6262620000000000000000000000000000000000") {
+			t.Errorf("expected other channels to keep their stream URLs, got:\n%s", m3u)
+		}
+	})
+
+	t.Run("labels variants and lists preferred ones alone", func(t *testing.T) {
+		hd, sd, beta := strings.Repeat("a", 40), strings.Repeat("b", 40), strings.Repeat("c", 40)
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				s1, _ := stream.NewStream(sd, "Alpha", "")
+				s2, _ := stream.NewStream(hd, "Alpha", "")
+				s3, _ := stream.NewStream(beta, "Beta", "")
+				return []stream.Stream{s1, s2, s3}, nil
+			},
+		}
+		alpha, _ := channel.NewChannel("Alpha")
+		alpha.SetStreamQuality(hd, channel.Quality1080p)
+		alpha.SetStreamQuality(sd, channel.QualitySD)
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{alpha}, nil
+			},
+		}
+		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		hdAt, sdAt := strings.Index(m3u, ",Alpha [1080p] - "+hd), strings.Index(m3u, ",Alpha [SD] - "+sd)
+		if hdAt < 0 || sdAt < 0 || hdAt > sdAt {
+			t.Errorf("expected labelled variants with 1080p first, got:\n%s", m3u)
+		}
+		if !strings.Contains(m3u, ",Beta - "+beta) {
+			t.Errorf("expected unlabelled channels to keep their name, got:\n%s", m3u)
+		}
+
+		alpha.SetQualityPreference([]channel.Quality{channel.QualitySD})
+		alpha.SetVariants(channel.VariantsPreferred)
+		m3u, err = service.GenerateM3U(context.Background(), "http://localhost:8080")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if strings.Count(m3u, ",Alpha - ") != 1 || !strings.Contains(m3u, "getstream?id="+sd) || strings.Contains(m3u, "getstream?id="+hd) {
+			t.Errorf("expected only the preferred SD variant, unlabelled, got:\n%s", m3u)
+		}
+	})
+
+	t.Run("lists numbered channels first and emits their tvg-chno", func(t *testing.T) {
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				a, _ := stream.NewStream("6161610000000000000000000000000000000000", "Alpha", "")
+				z, _ := stream.NewStream("7a7a7a0000000000000000000000000000000000", "Zulu", "")
+				return []stream.Stream{a, z}, nil
+			},
+		}
+		zulu, _ := channel.NewChannel("Zulu")
+		_ = zulu.SetNumber(5)
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{zulu}, nil
+			},
+		}
+		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		zuluLine := `#EXTINF:-1 tvg-id="Zulu" tvg-chno="5",Zulu - 7a7a7a0000000000000000000000000000000000`
+		alphaLine := `#EXTINF:-1 tvg-id="Alpha",Alpha - 6161610000000000000000000000000000000000`
+		if !strings.Contains(m3u, zuluLine) || !strings.Contains(m3u, alphaLine) {
+			t.Fatalf("expected numbered and unnumbered entries, got:\n%s", m3u)
+		}
+		if strings.Index(m3u, zuluLine) > strings.Index(m3u, alphaLine) {
+			t.Errorf("expected numbered channel first, got:\n%s", m3u)
+		}
+
+		lineup, err := service.Lineup(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(lineup) != 2 || lineup[0].Number != 5 || lineup[1].Number != 6 {
+			t.Errorf("expected lineup numbers [5 6], got %+v", lineup)
+		}
+	})
+
+	t.Run("emits the tvg-shift of shifted channels", func(t *testing.T) {
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				s, _ := stream.NewStream("6161610000000000000000000000000000000000", "Alpha", "")
+				return []stream.Stream{s}, nil
+			},
+		}
+		alpha, _ := channel.NewChannel("Alpha")
+		_ = alpha.SetTVGShift(-90 * time.Minute)
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{alpha}, nil
+			},
+		}
+		service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+
+		m3u, err := service.GenerateM3U(context.Background(), "http://localhost:8080")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if want := `#EXTINF:-1 tvg-id="Alpha" tvg-shift="-1.5",Alpha - `; !strings.Contains(m3u, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, m3u)
+		}
+	})
+}
+
+func TestPlaylistService_Preview(t *testing.T) {
+	hash := func(c string) string { return strings.Repeat(c, 40) }
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			a1, _ := stream.NewStream(hash("a"), "Alpha", "")
+			a2, _ := stream.NewStream(hash("b"), "Alpha", "")
+			b, _ := stream.NewStream(hash("c"), "Beta", "")
+			g, _ := stream.NewStream(hash("d"), "Gamma", "")
+			d, _ := stream.NewStream(hash("e"), "Delta", "")
+			return []stream.Stream{a1, a2, b, g, d}, nil
+		},
+	}
+	alpha, _ := channel.NewChannel("Alpha")
+	_ = alpha.SetAliases([]string{"alpha"})
+	beta, _ := channel.NewChannel("Beta")
+	beta.SetGroup("hidden")
+	channelRepo := &mockChannelRepository{
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			return []channel.Channel{alpha, beta}, nil
+		},
+	}
+	disableGamma, _ := rule.NewRule(rule.Match{NamePattern: "^Gamma$"}, rule.Action{Disable: true}, time.Now())
+
+	service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+	service.SetGroupRepository(newMemGroupRepository(group.ReconstructGroup("hidden", "Hidden", 0, false)))
+	service.SetRuleRepository(&memRuleRepository{rules: map[string]rule.Rule{disableGamma.ID(): disableGamma}})
+
+	reasons := func(preview PlaylistPreview) map[string]string {
+		got := make(map[string]string)
+		for _, x := range preview.Excluded {
+			got[x.Entry.InfoHash] = x.Reason
+		}
+		return got
+	}
+
+	t.Run("reports the entries emitted and why the others are left out", func(t *testing.T) {
+		preview, err := service.Preview(context.Background(), "http://localhost:8080", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var emitted []string
+		for _, e := range preview.Entries {
+			emitted = append(emitted, e.ChannelName)
+		}
+		if !slices.Equal(emitted, []string{"Alpha", "Delta"}) {
+			t.Errorf("expected Alpha and Delta emitted, got %v", emitted)
+		}
+		want := map[string]string{
+			hash("b"): ExclusionDuplicate,
+			hash("c"): ExclusionDisabledGroup,
+			hash("d"): ExclusionDisabled,
+		}
+		if got := reasons(preview); !maps.Equal(got, want) {
+			t.Errorf("expected exclusions %v, got %v", want, got)
+		}
+	})
+
+	t.Run("reports channels the user may not see as filtered", func(t *testing.T) {
+		u := user.ReconstructUser("u1", "Kid", "token", []string{"Alpha"}, nil, time.Now())
+		preview, err := service.Preview(context.Background(), "http://localhost:8080", &u)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(preview.Entries) != 1 || preview.Entries[0].ChannelName != "Alpha" {
+			t.Errorf("expected only Alpha emitted, got %+v", preview.Entries)
+		}
+		if got := reasons(preview); got[hash("e")] != ExclusionFiltered || got[hash("d")] != ExclusionFiltered {
+			t.Errorf("expected Gamma and Delta filtered, got %v", got)
+		}
+	})
+}
+
+func TestPlaylistService_Availability(t *testing.T) {
+	hash := func(c string) string { return strings.Repeat(c, 40) }
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			up, _ := stream.NewStream(hash("a"), "Up", "")
+			down, _ := stream.NewStream(hash("b"), "Down", "")
+			unprobed, _ := stream.NewStream(hash("c"), "Unprobed", "")
+			return []stream.Stream{up, down, unprobed}, nil
+		},
+	}
+	now := time.Now()
+	probeRepo := &mockProbeRepository{
+		findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+			switch infoHash {
+			case hash("a"):
+				return []probe.Result{probe.ReconstructResult(infoHash, now, true, time.Second, 10, 100000, "dl", "")}, nil
+			case hash("b"):
+				return []probe.Result{probe.ReconstructResult(infoHash, now, false, 0, 0, 0, "", "timeout")}, nil
+			}
+			return nil, nil
+		},
+	}
+	newService := func(mode PlaylistAvailability) *PlaylistService {
+		service := NewPlaylistService(streamRepo, &mockChannelRepository{}, probeRepo, 24*time.Hour)
+		service.SetAvailability(mode)
+		return service
+	}
+	availability := func(preview PlaylistPreview) map[string]string {
+		got := make(map[string]string)
+		for _, e := range preview.Entries {
+			got[e.ChannelName] = e.Availability
+		}
+		return got
+	}
+
+	t.Run("tags entries with their channel's availability", func(t *testing.T) {
+		preview, err := newService(PlaylistAvailabilityTag).Preview(context.Background(), "http://localhost:8080", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		want := map[string]string{"Up": "available", "Down": "unavailable", "Unprobed": "unknown"}
+		if got := availability(preview); !maps.Equal(got, want) {
+			t.Errorf("expected availability %v, got %v", want, got)
+		}
+	})
+
+	t.Run("hides unavailable channels", func(t *testing.T) {
+		preview, err := newService(PlaylistAvailabilityHide).Preview(context.Background(), "http://localhost:8080", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		want := map[string]string{"Up": "", "Unprobed": ""}
+		if got := availability(preview); !maps.Equal(got, want) {
+			t.Errorf("expected Up and Unprobed untagged, got %v", got)
+		}
+		if len(preview.Excluded) != 1 || preview.Excluded[0].Entry.InfoHash != hash("b") || preview.Excluded[0].Reason != ExclusionUnavailable {
+			t.Errorf("expected Down excluded as unavailable, got %+v", preview.Excluded)
+		}
+	})
+
+	t.Run("ignores availability when off", func(t *testing.T) {
+		preview, err := newService(PlaylistAvailabilityOff).Preview(context.Background(), "http://localhost:8080", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := availability(preview); len(got) != 3 || got["Down"] != "" {
+			t.Errorf("expected every channel listed untagged, got %v", got)
+		}
+	})
+}
+
+func TestPlaylistService_MinHealth(t *testing.T) {
+	hash := func(c string) string { return strings.Repeat(c, 40) }
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			healthy, _ := stream.NewStream(hash("a"), "Alpha", "")
+			failed, _ := stream.NewStream(hash("b"), "Alpha", "")
+			flaky, _ := stream.NewStream(hash("c"), "Beta", "")
+			unprobed, _ := stream.NewStream(hash("d"), "Delta", "")
+			return []stream.Stream{healthy, failed, flaky, unprobed}, nil
+		},
+	}
+	now := time.Now()
+	up := func(infoHash string, at time.Time) probe.Result {
+		return probe.ReconstructResult(infoHash, at, true, time.Second, 10, 100000, "dl", "")
+	}
+	down := func(infoHash string, at time.Time) probe.Result {
+		return probe.ReconstructResult(infoHash, at, false, 0, 0, 0, "", "timeout")
+	}
+	probeRepo := &mockProbeRepository{
+		findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+			switch infoHash {
+			case hash("a"):
+				return []probe.Result{up(infoHash, now)}, nil
+			case hash("b"):
+				return []probe.Result{down(infoHash, now), up(infoHash, now.Add(-time.Hour))}, nil
+			case hash("c"):
+				// Up now, but half of its probes failed
+				return []probe.Result{up(infoHash, now), down(infoHash, now.Add(-time.Hour))}, nil
+			}
+			return nil, nil
+		},
+	}
+	service := NewPlaylistService(streamRepo, &mockChannelRepository{}, probeRepo, 24*time.Hour)
+	service.SetMinHealth(0.8)
+
+	t.Run("leaves out unhealthy streams and keeps unprobed ones", func(t *testing.T) {
+		preview, err := service.Preview(context.Background(), "http://localhost:8080", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var emitted []string
+		for _, e := range preview.Entries {
+			emitted = append(emitted, e.InfoHash)
+		}
+		if !slices.Equal(emitted, []string{hash("a"), hash("d")}) {
+			t.Errorf("expected the healthy and unprobed streams emitted, got %v", emitted)
+		}
+		want := map[string]string{hash("b"): ExclusionUnhealthy, hash("c"): ExclusionUnhealthy}
+		got := make(map[string]string)
+		for _, x := range preview.Excluded {
+			got[x.Entry.InfoHash] = x.Reason
+		}
+		if !maps.Equal(got, want) {
+			t.Errorf("expected exclusions %v, got %v", want, got)
+		}
+	})
+
+	t.Run("a request may set its own minimum", func(t *testing.T) {
+		preview, err := service.Preview(WithMinHealth(context.Background(), 0), "http://localhost:8080", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(preview.Entries) != 4 || len(preview.Excluded) != 0 {
+			t.Errorf("expected every stream listed, got %+v", preview)
+		}
+	})
+}
+
+func TestPlaylistService_PlaylistPrefs(t *testing.T) {
+	hash := func(c string) string { return strings.Repeat(c, 40) }
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			hd, _ := stream.NewStream(hash("a"), "Alpha", "")
+			sd, _ := stream.NewStream(hash("b"), "Alpha", "")
+			news, _ := stream.NewStream(hash("c"), "Beta", "")
+			hidden, _ := stream.NewStream(hash("d"), "Delta", "")
+			return []stream.Stream{hd, sd, news, hidden}, nil
+		},
+	}
+	alpha := channel.ReconstructChannel("Alpha", channel.StatusActive, nil)
+	alpha.SetGroup("sports")
+	alpha.SetStreamQuality(hash("a"), channel.Quality1080p)
+	alpha.SetStreamQuality(hash("b"), channel.QualitySD)
+	beta := channel.ReconstructChannel("Beta", channel.StatusActive, nil)
+	beta.SetGroup("news")
+	delta := channel.ReconstructChannel("Delta", channel.StatusActive, nil)
+	delta.SetGroup("hidden")
+	channelRepo := &mockChannelRepository{
+		findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+			return []channel.Channel{alpha, beta, delta}, nil
+		},
+	}
+	service := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+	service.SetGroupRepository(newMemGroupRepository(
+		group.ReconstructGroup("sports", "Sports", 0, true),
+		group.ReconstructGroup("news", "News", 1, true),
+		group.ReconstructGroup("hidden", "Hidden", 2, false),
+	))
+
+	emitted := func(ctx context.Context) []string {
+		t.Helper()
+		preview, err := service.Preview(ctx, "http://localhost:8080", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var hashes []string
+		for _, e := range preview.Entries {
+			hashes = append(hashes, e.InfoHash)
+		}
+		return hashes
+	}
+
+	t.Run("without prefs", func(t *testing.T) {
+		if got := emitted(context.Background()); !slices.Equal(got, []string{hash("a"), hash("b"), hash("c")}) {
+			t.Errorf("unexpected entries %v", got)
+		}
+	})
+
+	t.Run("lists the preferred quality first", func(t *testing.T) {
+		ctx := WithPlaylistPrefs(context.Background(), auth.NewPlaylistPrefs(nil, "SD", "", false))
+		if got := emitted(ctx); !slices.Equal(got, []string{hash("b"), hash("a"), hash("c")}) {
+			t.Errorf("unexpected entries %v", got)
+		}
+	})
+
+	t.Run("lists only the chosen groups, disabled ones included", func(t *testing.T) {
+		ctx := WithPlaylistPrefs(context.Background(), auth.NewPlaylistPrefs([]string{"news", "hidden"}, "", "", true))
+		if got := emitted(ctx); !slices.Equal(got, []string{hash("c"), hash("d")}) {
+			t.Errorf("unexpected entries %v", got)
+		}
+	})
 }
@@ -0,0 +1,154 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// ErrPreviewUnavailable is returned when no frame could be extracted from a
+// stream before the capture timed out or the stream ended.
+var ErrPreviewUnavailable = errors.New("preview unavailable")
+
+// PreviewConfig tunes stream previews.
+type PreviewConfig struct {
+	// CacheTTL is how long a captured preview is served before a new one is taken.
+	CacheTTL time.Duration
+	// Timeout bounds a whole capture, including engine startup.
+	Timeout time.Duration
+}
+
+// StreamPreview is a still frame of a stream.
+type StreamPreview struct {
+	JPEG       []byte
+	CapturedAt time.Time
+}
+
+// PreviewService captures still frames of streams so channels can be
+// checked at a glance. A capture subscribes through the proxy like any
+// other client, so previewing a stream that is being watched adds no engine
+// load. Previews are cached, and concurrent requests for the same stream
+// share a single capture.
+type PreviewService struct {
+	proxy     *AceStreamProxyService
+	extractor driven.FrameExtractor
+	config    PreviewConfig
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu       sync.Mutex
+	cache    map[string]StreamPreview
+	inflight map[string]*previewCapture
+}
+
+// previewCapture is a capture in progress that requests wait on.
+type previewCapture struct {
+	done    chan struct{}
+	preview StreamPreview
+	err     error
+}
+
+// NewPreviewService creates a new PreviewService on top of the proxy.
+func NewPreviewService(proxy *AceStreamProxyService, extractor driven.FrameExtractor, config PreviewConfig, logger *slog.Logger) *PreviewService {
+	return &PreviewService{
+		proxy:     proxy,
+		extractor: extractor,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+		cache:     make(map[string]StreamPreview),
+		inflight:  make(map[string]*previewCapture),
+	}
+}
+
+// Preview returns a recent frame of the stream for the given infohash,
+// capturing one if the cached frame is missing or older than the cache TTL.
+// A capture outlives a caller that gives up on it, so the frame is cached
+// for the next request.
+// Returns ErrPreviewUnavailable if no frame could be extracted.
+func (s *PreviewService) Preview(ctx context.Context, infoHash string) (StreamPreview, error) {
+	if infoHash == "" {
+		return StreamPreview{}, ErrInvalidInfoHash
+	}
+
+	s.mu.Lock()
+	if p, ok := s.cache[infoHash]; ok && s.now().Sub(p.CapturedAt) < s.config.CacheTTL {
+		s.mu.Unlock()
+		return p, nil
+	}
+	capture, ok := s.inflight[infoHash]
+	if !ok {
+		capture = &previewCapture{done: make(chan struct{})}
+		s.inflight[infoHash] = capture
+		go s.capture(infoHash, capture)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-capture.done:
+		return capture.preview, capture.err
+	case <-ctx.Done():
+		return StreamPreview{}, ctx.Err()
+	}
+}
+
+// capture extracts a frame of the stream, caches it and hands it to the
+// requests waiting on c.
+func (s *PreviewService) capture(infoHash string, c *previewCapture) {
+	c.preview, c.err = s.extract(infoHash)
+
+	s.mu.Lock()
+	delete(s.inflight, infoHash)
+	if c.err == nil {
+		now := s.now()
+		for hash, p := range s.cache {
+			if now.Sub(p.CapturedAt) >= s.config.CacheTTL {
+				delete(s.cache, hash)
+			}
+		}
+		s.cache[infoHash] = c.preview
+	}
+	s.mu.Unlock()
+	close(c.done)
+}
+
+// extract streams the infohash into the frame extractor until it returns.
+func (s *PreviewService) extract(infoHash string) (StreamPreview, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	streamDone := make(chan error, 1)
+	go func() {
+		err := s.proxy.StreamToClient(ctx, infoHash, pw)
+		pw.CloseWithError(err)
+		streamDone <- err
+	}()
+
+	start := time.Now()
+	frame, err := s.extractor.ExtractFrame(ctx, pr)
+	// Leave the stream: the proxy's next write fails and the client is dropped
+	_ = pr.Close()
+	cancel()
+	streamErr := <-streamDone
+
+	if err == nil && len(frame) > 0 {
+		s.logger.Info("stream preview captured", "infohash", infoHash, "bytes", len(frame), "duration", time.Since(start))
+		return StreamPreview{JPEG: frame, CapturedAt: s.now()}, nil
+	}
+
+	if streamErr != nil && !errors.Is(streamErr, context.Canceled) && !errors.Is(streamErr, context.DeadlineExceeded) && !errors.Is(streamErr, io.ErrClosedPipe) {
+		return StreamPreview{}, streamErr
+	}
+	s.logger.Warn("stream preview failed", "infohash", infoHash, "error", err)
+	if err == nil {
+		return StreamPreview{}, ErrPreviewUnavailable
+	}
+	return StreamPreview{}, fmt.Errorf("%w: %w", ErrPreviewUnavailable, err)
+}
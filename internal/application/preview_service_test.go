@@ -0,0 +1,117 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubFrameExtractor reads a little of the stream and returns a fixed frame.
+type stubFrameExtractor struct {
+	calls atomic.Int32
+	frame []byte
+	err   error
+}
+
+func (e *stubFrameExtractor) ExtractFrame(ctx context.Context, r io.Reader) ([]byte, error) {
+	e.calls.Add(1)
+	buf := make([]byte, 188)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return e.frame, e.err
+}
+
+// endlessStreamEngine returns an engine whose streams write TS packets until cancelled.
+func endlessStreamEngine() *mockAceStreamEngine {
+	return &mockAceStreamEngine{
+		streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+			pkt := make([]byte, 188)
+			pkt[0] = 0x47
+			for {
+				if _, err := dst.Write(pkt); err != nil {
+					return err
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Millisecond):
+				}
+			}
+		},
+	}
+}
+
+func TestPreviewService_Preview(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("captures a frame and caches it", func(t *testing.T) {
+		proxy := NewAceStreamProxyService(endlessStreamEngine(), newTestLogger(), 10*time.Second, nil)
+		extractor := &stubFrameExtractor{frame: []byte("jpeg")}
+		service := NewPreviewService(proxy, extractor, PreviewConfig{CacheTTL: time.Minute, Timeout: 5 * time.Second}, newTestLogger())
+		now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+		service.now = func() time.Time { return now }
+
+		preview, err := service.Preview(ctx, "abc123")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(preview.JPEG) != "jpeg" || !preview.CapturedAt.Equal(now) {
+			t.Errorf("unexpected preview %+v", preview)
+		}
+
+		if _, err := service.Preview(ctx, "abc123"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := extractor.calls.Load(); got != 1 {
+			t.Errorf("expected the cached preview to be served, got %d captures", got)
+		}
+
+		now = now.Add(time.Minute)
+		if _, err := service.Preview(ctx, "abc123"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := extractor.calls.Load(); got != 2 {
+			t.Errorf("expected an expired preview to be captured again, got %d captures", got)
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for proxy.IsStreamActive("abc123") && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if proxy.IsStreamActive("abc123") {
+			t.Error("expected the capture to leave the stream")
+		}
+	})
+
+	t.Run("reports a stream without a frame", func(t *testing.T) {
+		proxy := NewAceStreamProxyService(endlessStreamEngine(), newTestLogger(), 10*time.Second, nil)
+		extractor := &stubFrameExtractor{err: errors.New("no video stream")}
+		service := NewPreviewService(proxy, extractor, PreviewConfig{CacheTTL: time.Minute, Timeout: 5 * time.Second}, newTestLogger())
+
+		if _, err := service.Preview(ctx, "abc123"); !errors.Is(err, ErrPreviewUnavailable) {
+			t.Errorf("expected ErrPreviewUnavailable, got %v", err)
+		}
+		if _, err := service.Preview(ctx, ""); !errors.Is(err, ErrInvalidInfoHash) {
+			t.Errorf("expected ErrInvalidInfoHash, got %v", err)
+		}
+	})
+
+	t.Run("reports engine errors", func(t *testing.T) {
+		engine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "", errors.New("engine down")
+			},
+		}
+		proxy := NewAceStreamProxyService(engine, newTestLogger(), 10*time.Second, nil)
+		service := NewPreviewService(proxy, &stubFrameExtractor{}, PreviewConfig{CacheTTL: time.Minute, Timeout: 5 * time.Second}, newTestLogger())
+
+		_, err := service.Preview(ctx, "abc123")
+		if err == nil || errors.Is(err, ErrPreviewUnavailable) {
+			t.Errorf("expected the engine error, got %v", err)
+		}
+	})
+}
@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"log/slog"
 	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/alorle/iptv-manager/internal/port/driven"
 	"github.com/alorle/iptv-manager/internal/probe"
+	"github.com/alorle/iptv-manager/internal/stream"
 )
 
 var errEngineFailure = errors.New("engine failure during probe")
@@ -33,6 +36,13 @@ type ProbeService struct {
 	window                 time.Duration
 	probeDelay             time.Duration
 	maxConsecutiveFailures int
+
+	batchSize     int
+	prebufferWait time.Duration
+	prebufferPoll time.Duration
+
+	mu     sync.Mutex
+	cursor string // infohash of the last stream probed by a batch
 }
 
 // NewProbeService creates a new ProbeService.
@@ -57,10 +67,27 @@ func NewProbeService(
 		window:                 window,
 		probeDelay:             probeDelay,
 		maxConsecutiveFailures: maxConsecutiveFailures,
+		prebufferPoll:          time.Second,
 	}
 }
 
-// ProbeAllStreams runs a health-check probe on every known stream sequentially.
+// SetBatchSize makes each probe cycle spot-check the next n streams, in
+// infohash order and wrapping around, instead of every stream, so a large
+// lineup is covered over several cycles without keeping the engine busy.
+// Zero, the default, probes every stream each cycle.
+func (s *ProbeService) SetBatchSize(n int) {
+	s.batchSize = n
+}
+
+// SetPrebufferWait makes probes wait up to wait for a stream to leave
+// prebuffering before its stats are judged, so a slow start is not taken
+// for a dead stream. Zero, the default, judges the first stats.
+func (s *ProbeService) SetPrebufferWait(wait time.Duration) {
+	s.prebufferWait = wait
+}
+
+// ProbeAllStreams runs a health-check probe on every known stream
+// sequentially, or on the next batch of them if a batch size is set.
 // It skips streams that are actively being watched, throttles between probes,
 // and trips a circuit breaker after consecutive engine failures.
 func (s *ProbeService) ProbeAllStreams(ctx context.Context) error {
@@ -68,6 +95,7 @@ func (s *ProbeService) ProbeAllStreams(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to fetch streams: %w", err)
 	}
+	streams = s.nextBatch(streams)
 
 	s.logger.Info("starting probe cycle", "stream_count", len(streams), "probe_delay", s.probeDelay)
 
@@ -130,6 +158,35 @@ func (s *ProbeService) ProbeAllStreams(ctx context.Context) error {
 	return nil
 }
 
+// nextBatch returns the streams the current cycle probes: all of them
+// without a batch size, otherwise the batchSize streams following the last
+// one probed, in infohash order.
+func (s *ProbeService) nextBatch(streams []stream.Stream) []stream.Stream {
+	if s.batchSize <= 0 || len(streams) <= s.batchSize {
+		return streams
+	}
+
+	sorted := slices.Clone(streams)
+	slices.SortFunc(sorted, func(a, b stream.Stream) int {
+		return strings.Compare(a.InfoHash(), b.InfoHash())
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start, _ := slices.BinarySearchFunc(sorted, s.cursor, func(st stream.Stream, cursor string) int {
+		if st.InfoHash() <= cursor {
+			return -1
+		}
+		return 1
+	})
+	batch := make([]stream.Stream, 0, s.batchSize)
+	for i := range s.batchSize {
+		batch = append(batch, sorted[(start+i)%len(sorted)])
+	}
+	s.cursor = batch[len(batch)-1].InfoHash()
+	return batch
+}
+
 // probeStream executes a single health-check probe for the given stream.
 func (s *ProbeService) probeStream(ctx context.Context, infoHash string) (probe.Result, error) {
 	pid := fmt.Sprintf("probe-%d", time.Now().UnixNano())
@@ -138,7 +195,7 @@ func (s *ProbeService) probeStream(ctx context.Context, infoHash string) (probe.
 	defer cancel()
 
 	startTime := time.Now()
-	_, err := s.engine.StartStream(probeCtx, infoHash, pid)
+	_, err := s.engine.StartStream(probeCtx, infoHash, pid, driven.StreamOptions{})
 	if err != nil {
 		result, resultErr := probe.NewResult(
 			infoHash, time.Now(), false, 0, 0, 0, "", err.Error(),
@@ -154,7 +211,7 @@ func (s *ProbeService) probeStream(ctx context.Context, infoHash string) (probe.
 
 	startupLatency := time.Since(startTime)
 
-	stats, statsErr := s.engine.GetStats(probeCtx, pid)
+	stats, statsErr := s.awaitPrebuffer(probeCtx, pid)
 
 	if stopErr := s.engine.StopStream(ctx, pid); stopErr != nil {
 		s.logger.Warn("failed to stop probe stream",
@@ -209,6 +266,24 @@ func (s *ProbeService) probeStream(ctx context.Context, infoHash string) (probe.
 	return result, nil
 }
 
+// awaitPrebuffer returns the stats of the probe stream pid once it has left
+// prebuffering, or the last stats seen when the prebuffer wait or ctx runs
+// out first.
+func (s *ProbeService) awaitPrebuffer(ctx context.Context, pid string) (driven.StreamStats, error) {
+	deadline := time.Now().Add(s.prebufferWait)
+	for {
+		stats, err := s.engine.GetStats(ctx, pid)
+		if err != nil || stats.Status != "prebuf" || !time.Now().Add(s.prebufferPoll).Before(deadline) {
+			return stats, err
+		}
+		select {
+		case <-ctx.Done():
+			return stats, nil
+		case <-time.After(s.prebufferPoll):
+		}
+	}
+}
+
 // GetMetrics computes aggregated metrics for a stream within the rolling window.
 func (s *ProbeService) GetMetrics(ctx context.Context, infoHash string) (probe.Metrics, error) {
 	since := time.Now().Add(-s.window)
@@ -281,6 +356,83 @@ func (s *ProbeService) GetQualityScores(ctx context.Context, channelName string)
 	return qualities, nil
 }
 
+// StreamHealth is the latest health evaluation of a single stream.
+type StreamHealth struct {
+	InfoHash    string
+	ChannelName string
+	Score       float64
+	Metrics     probe.Metrics
+	LastProbe   probe.Result
+}
+
+// GetStreamHealth returns the quality score, metrics and latest probe of a
+// stream. The score is normalized against the other streams of its channel.
+// Returns stream.ErrStreamNotFound if the stream does not exist and
+// probe.ErrNoProbeData if it has not been probed within the rolling window.
+func (s *ProbeService) GetStreamHealth(ctx context.Context, infoHash string) (StreamHealth, error) {
+	st, err := s.streamRepo.FindByInfoHash(ctx, infoHash)
+	if err != nil {
+		return StreamHealth{}, err
+	}
+
+	scores, err := s.GetQualityScores(ctx, st.ChannelName())
+	if err != nil {
+		return StreamHealth{}, err
+	}
+
+	idx := slices.IndexFunc(scores, func(q StreamQuality) bool { return q.InfoHash == infoHash })
+	if idx < 0 {
+		return StreamHealth{}, probe.ErrNoProbeData
+	}
+
+	history, err := s.GetProbeHistory(ctx, infoHash)
+	if err != nil {
+		return StreamHealth{}, fmt.Errorf("failed to fetch probe history: %w", err)
+	}
+	if len(history) == 0 {
+		return StreamHealth{}, probe.ErrNoProbeData
+	}
+
+	return StreamHealth{
+		InfoHash:    infoHash,
+		ChannelName: st.ChannelName(),
+		Score:       scores[idx].Score,
+		Metrics:     scores[idx].Metrics,
+		LastProbe:   history[0],
+	}, nil
+}
+
+// RankStreams orders the given infohashes of a channel by quality score,
+// best first. Streams without probe data keep their relative order after
+// all scored streams. If scores cannot be computed the input order is kept.
+func (s *ProbeService) RankStreams(ctx context.Context, channelName string, infoHashes []string) []string {
+	scores, err := s.GetQualityScores(ctx, channelName)
+	if err != nil {
+		s.logger.Warn("failed to rank streams, keeping original order", "channel", channelName, "error", err)
+		return infoHashes
+	}
+
+	wanted := make(map[string]bool, len(infoHashes))
+	for _, h := range infoHashes {
+		wanted[h] = true
+	}
+
+	ranked := make([]string, 0, len(infoHashes))
+	scored := make(map[string]bool, len(scores))
+	for _, q := range scores {
+		if wanted[q.InfoHash] {
+			ranked = append(ranked, q.InfoHash)
+			scored[q.InfoHash] = true
+		}
+	}
+	for _, h := range infoHashes {
+		if !scored[h] {
+			ranked = append(ranked, h)
+		}
+	}
+	return ranked
+}
+
 // GetProbeHistory returns raw probe results for a stream within the rolling window.
 func (s *ProbeService) GetProbeHistory(ctx context.Context, infoHash string) ([]probe.Result, error) {
 	since := time.Now().Add(-s.window)
@@ -299,6 +451,9 @@ type ChannelHealth struct {
 	StreamCount int
 	InfoHashes  []string
 	LastProbe   *probe.Result
+	// Availability aggregates the latest probe of each stream, as
+	// GetChannelAvailability does.
+	Availability probe.Availability
 }
 
 // GetChannelHealth computes the health summary for a single channel.
@@ -309,9 +464,10 @@ func (s *ProbeService) GetChannelHealth(ctx context.Context, channelName string)
 	}
 
 	health := ChannelHealth{
-		ChannelName: channelName,
-		StreamCount: len(streams),
-		InfoHashes:  make([]string, len(streams)),
+		ChannelName:  channelName,
+		StreamCount:  len(streams),
+		InfoHashes:   make([]string, len(streams)),
+		Availability: probe.AvailabilityUnknown,
 	}
 	for i, st := range streams {
 		health.InfoHashes[i] = st.InfoHash()
@@ -330,23 +486,91 @@ func (s *ProbeService) GetChannelHealth(ctx context.Context, channelName string)
 	}
 
 	since := time.Now().Add(-s.window)
+	var latestResults []probe.Result
 	for _, st := range streams {
 		results, err := s.probeRepo.FindByInfoHashSince(ctx, st.InfoHash(), since)
 		if err != nil || len(results) == 0 {
 			continue
 		}
 		latest := results[0]
+		latestResults = append(latestResults, latest)
 		if health.LastProbe == nil || latest.Timestamp().After(health.LastProbe.Timestamp()) {
 			r := latest
 			health.LastProbe = &r
 		}
 	}
+	health.Availability = probe.AggregateAvailability(latestResults)
 
 	return health, nil
 }
 
+// ChannelStatus is the availability of a channel and when its streams were
+// last checked.
+type ChannelStatus struct {
+	Availability probe.Availability
+	// LastChecked is the time of the latest probe of any of the channel's
+	// streams, zero if there is none within the rolling window.
+	LastChecked time.Time
+}
+
+// GetChannelAvailability aggregates the latest probe result of each of the
+// channel's streams within the rolling window into a single availability.
+// Streams without probe data in the window are ignored; a channel with no
+// probe data at all is reported as unknown.
+func (s *ProbeService) GetChannelAvailability(ctx context.Context, channelName string) (probe.Availability, error) {
+	status, err := s.GetChannelStatus(ctx, channelName)
+	return status.Availability, err
+}
+
+// GetChannelStatus returns the availability of a channel, as
+// GetChannelAvailability does, along with when it was last checked.
+func (s *ProbeService) GetChannelStatus(ctx context.Context, channelName string) (ChannelStatus, error) {
+	unknown := ChannelStatus{Availability: probe.AvailabilityUnknown}
+	streams, err := s.streamRepo.FindByChannelName(ctx, channelName)
+	if err != nil {
+		return unknown, fmt.Errorf("failed to fetch streams: %w", err)
+	}
+
+	since := time.Now().Add(-s.window)
+	latest := make([]probe.Result, 0, len(streams))
+	for _, st := range streams {
+		results, err := s.probeRepo.FindByInfoHashSince(ctx, st.InfoHash(), since)
+		if err != nil {
+			return unknown, fmt.Errorf("failed to fetch probe results: %w", err)
+		}
+		if len(results) > 0 {
+			latest = append(latest, results[0])
+		}
+	}
+
+	status := ChannelStatus{Availability: probe.AggregateAvailability(latest)}
+	for _, r := range latest {
+		if r.Timestamp().After(status.LastChecked) {
+			status.LastChecked = r.Timestamp()
+		}
+	}
+	return status, nil
+}
+
 // Cleanup removes probe data older than twice the rolling window.
 func (s *ProbeService) Cleanup(ctx context.Context) error {
 	cutoff := time.Now().Add(-s.window * 2)
-	return s.probeRepo.DeleteBefore(ctx, cutoff)
+	_, err := s.probeRepo.DeleteBefore(ctx, cutoff)
+	return err
+}
+
+// PruneOlderThan removes probe data older than maxAge and returns the number of
+// results removed. Unlike Cleanup, the cutoff is independent of the rolling
+// window; it is meant for a one-off startup pass that clears stale history,
+// e.g. for streams whose source has since been removed.
+func (s *ProbeService) PruneOlderThan(ctx context.Context, maxAge time.Duration) (int, error) {
+	if maxAge <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-maxAge)
+	pruned, err := s.probeRepo.DeleteBefore(ctx, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune probe results: %w", err)
+	}
+	return pruned, nil
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
@@ -18,7 +19,7 @@ type mockProbeRepository struct {
 	saveFunc                func(ctx context.Context, r probe.Result) error
 	findByInfoHashFunc      func(ctx context.Context, infoHash string) ([]probe.Result, error)
 	findByInfoHashSinceFunc func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error)
-	deleteBeforeFunc        func(ctx context.Context, before time.Time) error
+	deleteBeforeFunc        func(ctx context.Context, before time.Time) (int, error)
 }
 
 func (m *mockProbeRepository) Save(ctx context.Context, r probe.Result) error {
@@ -42,11 +43,11 @@ func (m *mockProbeRepository) FindByInfoHashSince(ctx context.Context, infoHash
 	return []probe.Result{}, nil
 }
 
-func (m *mockProbeRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+func (m *mockProbeRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
 	if m.deleteBeforeFunc != nil {
 		return m.deleteBeforeFunc(ctx, before)
 	}
-	return nil
+	return 0, nil
 }
 
 type mockActiveStreamChecker struct {
@@ -70,8 +71,8 @@ func newTestProbeService(probeRepo driven.ProbeRepository, streamRepo driven.Str
 
 func TestProbeService_ProbeAllStreams(t *testing.T) {
 	t.Run("probes all streams sequentially", func(t *testing.T) {
-		s1, _ := stream.NewStream("hash1", "Channel1", "")
-		s2, _ := stream.NewStream("hash2", "Channel2", "")
+		s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+		s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel2", "")
 
 		var probeOrder []string
 		var savedResults []probe.Result
@@ -109,7 +110,7 @@ func TestProbeService_ProbeAllStreams(t *testing.T) {
 		if len(probeOrder) != 2 {
 			t.Fatalf("expected 2 probes, got %d", len(probeOrder))
 		}
-		if probeOrder[0] != "hash1" || probeOrder[1] != "hash2" {
+		if probeOrder[0] != "6861736831000000000000000000000000000000" || probeOrder[1] != "6861736832000000000000000000000000000000" {
 			t.Errorf("probe order = %v, want [hash1, hash2]", probeOrder)
 		}
 		if len(savedResults) != 2 {
@@ -123,8 +124,8 @@ func TestProbeService_ProbeAllStreams(t *testing.T) {
 	})
 
 	t.Run("continues on engine failure", func(t *testing.T) {
-		s1, _ := stream.NewStream("hash1", "Channel1", "")
-		s2, _ := stream.NewStream("hash2", "Channel2", "")
+		s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+		s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel2", "")
 
 		var savedResults []probe.Result
 
@@ -136,7 +137,7 @@ func TestProbeService_ProbeAllStreams(t *testing.T) {
 
 		engine := &mockAceStreamEngine{
 			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
-				if infoHash == "hash1" {
+				if infoHash == "6861736831000000000000000000000000000000" {
 					return "", errors.New("engine unavailable")
 				}
 				return "http://localhost/stream", nil
@@ -172,8 +173,8 @@ func TestProbeService_ProbeAllStreams(t *testing.T) {
 	})
 
 	t.Run("stops on context cancellation", func(t *testing.T) {
-		s1, _ := stream.NewStream("hash1", "Channel1", "")
-		s2, _ := stream.NewStream("hash2", "Channel2", "")
+		s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+		s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel2", "")
 
 		streamRepo := &mockStreamRepository{
 			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
@@ -286,8 +287,8 @@ func TestProbeService_ZombieDetection(t *testing.T) {
 }
 
 func TestProbeService_SkipsActiveStreams(t *testing.T) {
-	s1, _ := stream.NewStream("active-hash", "Channel1", "")
-	s2, _ := stream.NewStream("inactive-hash", "Channel2", "")
+	s1, _ := stream.NewStream("6163746976652d68617368000000000000000000", "Channel1", "")
+	s2, _ := stream.NewStream("696e6163746976652d6861736800000000000000", "Channel2", "")
 
 	var probedHashes []string
 
@@ -309,7 +310,7 @@ func TestProbeService_SkipsActiveStreams(t *testing.T) {
 
 	checker := &mockActiveStreamChecker{
 		isStreamActiveFunc: func(infoHash string) bool {
-			return infoHash == "active-hash"
+			return infoHash == "6163746976652d68617368000000000000000000"
 		},
 	}
 
@@ -323,7 +324,7 @@ func TestProbeService_SkipsActiveStreams(t *testing.T) {
 	if len(probedHashes) != 1 {
 		t.Fatalf("expected 1 probe, got %d: %v", len(probedHashes), probedHashes)
 	}
-	if probedHashes[0] != "inactive-hash" {
+	if probedHashes[0] != "696e6163746976652d6861736800000000000000" {
 		t.Errorf("expected inactive-hash to be probed, got %q", probedHashes[0])
 	}
 }
@@ -332,7 +333,7 @@ func TestProbeService_CircuitBreaker(t *testing.T) {
 	t.Run("trips after consecutive engine failures", func(t *testing.T) {
 		streams := make([]stream.Stream, 10)
 		for i := range streams {
-			s, _ := stream.NewStream("hash"+string(rune('a'+i)), "Channel", "")
+			s, _ := stream.NewStream("686173680000000000000000000000000000000"+string(rune('0'+i)), "Channel", "")
 			streams[i] = s
 		}
 
@@ -362,7 +363,7 @@ func TestProbeService_CircuitBreaker(t *testing.T) {
 	t.Run("resets counter on successful probe", func(t *testing.T) {
 		streams := make([]stream.Stream, 7)
 		for i := range streams {
-			s, _ := stream.NewStream("hash"+string(rune('a'+i)), "Channel", "")
+			s, _ := stream.NewStream("686173680000000000000000000000000000000"+string(rune('0'+i)), "Channel", "")
 			streams[i] = s
 		}
 
@@ -399,9 +400,9 @@ func TestProbeService_CircuitBreaker(t *testing.T) {
 }
 
 func TestProbeService_Throttle(t *testing.T) {
-	s1, _ := stream.NewStream("hash1", "Channel1", "")
-	s2, _ := stream.NewStream("hash2", "Channel2", "")
-	s3, _ := stream.NewStream("hash3", "Channel3", "")
+	s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+	s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel2", "")
+	s3, _ := stream.NewStream("6861736833000000000000000000000000000000", "Channel3", "")
 
 	var timestamps []time.Time
 
@@ -488,8 +489,8 @@ func TestProbeService_GetMetrics_NoData(t *testing.T) {
 func TestProbeService_GetQualityScores(t *testing.T) {
 	now := time.Now()
 
-	s1, _ := stream.NewStream("hash1", "Channel1", "")
-	s2, _ := stream.NewStream("hash2", "Channel1", "")
+	s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+	s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel1", "")
 
 	streamRepo := &mockStreamRepository{
 		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
@@ -499,7 +500,7 @@ func TestProbeService_GetQualityScores(t *testing.T) {
 
 	probeRepo := &mockProbeRepository{
 		findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
-			if infoHash == "hash1" {
+			if infoHash == "6861736831000000000000000000000000000000" {
 				// Good stream: always up, fast, many peers
 				return []probe.Result{
 					probe.ReconstructResult(infoHash, now, true, time.Second, 20, 200000, "dl", ""),
@@ -526,10 +527,10 @@ func TestProbeService_GetQualityScores(t *testing.T) {
 	}
 
 	// hash1 should score higher than hash2
-	if scores[0].InfoHash != "hash1" {
+	if scores[0].InfoHash != "6861736831000000000000000000000000000000" {
 		t.Errorf("expected hash1 first (best), got %q", scores[0].InfoHash)
 	}
-	if scores[1].InfoHash != "hash2" {
+	if scores[1].InfoHash != "6861736832000000000000000000000000000000" {
 		t.Errorf("expected hash2 second, got %q", scores[1].InfoHash)
 	}
 	if scores[0].Score <= scores[1].Score {
@@ -555,13 +556,188 @@ func TestProbeService_GetQualityScores_NoStreams(t *testing.T) {
 	}
 }
 
+func TestProbeService_GetChannelAvailability(t *testing.T) {
+	now := time.Now()
+	s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+	s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel1", "")
+
+	streamRepo := &mockStreamRepository{
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			return []stream.Stream{s1, s2}, nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		results map[string][]probe.Result
+		want    probe.Availability
+	}{
+		{
+			name: "one available and one dead stream is available",
+			results: map[string][]probe.Result{
+				"6861736831000000000000000000000000000000": {probe.ReconstructResult("6861736831000000000000000000000000000000", now, true, time.Second, 10, 100000, "dl", "")},
+				"6861736832000000000000000000000000000000": {probe.ReconstructResult("6861736832000000000000000000000000000000", now, false, 0, 0, 0, "", "timeout")},
+			},
+			want: probe.AvailabilityAvailable,
+		},
+		{
+			name: "all dead streams is unavailable",
+			results: map[string][]probe.Result{
+				"6861736831000000000000000000000000000000": {probe.ReconstructResult("6861736831000000000000000000000000000000", now, false, 0, 0, 0, "", "timeout")},
+				"6861736832000000000000000000000000000000": {
+					probe.ReconstructResult("6861736832000000000000000000000000000000", now, false, 0, 0, 0, "", "timeout"),
+					probe.ReconstructResult("6861736832000000000000000000000000000000", now.Add(-time.Hour), true, time.Second, 10, 100000, "dl", ""),
+				},
+			},
+			want: probe.AvailabilityUnavailable,
+		},
+		{
+			name:    "no probe data is unknown",
+			results: map[string][]probe.Result{},
+			want:    probe.AvailabilityUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probeRepo := &mockProbeRepository{
+				findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+					return tt.results[infoHash], nil
+				},
+			}
+			svc := newTestProbeService(probeRepo, streamRepo, &mockAceStreamEngine{})
+
+			got, err := svc.GetChannelAvailability(context.Background(), "Channel1")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetChannelAvailability() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeService_GetChannelStatus(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+	s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel1", "")
+	streamRepo := &mockStreamRepository{
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			return []stream.Stream{s1, s2}, nil
+		},
+	}
+	probeRepo := &mockProbeRepository{
+		findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+			if infoHash == s1.InfoHash() {
+				return []probe.Result{probe.ReconstructResult(infoHash, now.Add(-time.Hour), true, time.Second, 10, 100000, "dl", "")}, nil
+			}
+			return []probe.Result{probe.ReconstructResult(infoHash, now, false, 0, 0, 0, "", "timeout")}, nil
+		},
+	}
+	svc := newTestProbeService(probeRepo, streamRepo, &mockAceStreamEngine{})
+
+	status, err := svc.GetChannelStatus(context.Background(), "Channel1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Availability != probe.AvailabilityAvailable {
+		t.Errorf("Availability = %q, want available", status.Availability)
+	}
+	if !status.LastChecked.Equal(now) {
+		t.Errorf("LastChecked = %v, want the latest probe at %v", status.LastChecked, now)
+	}
+}
+
+func TestProbeService_BatchSize(t *testing.T) {
+	hashes := []string{
+		"6861736833000000000000000000000000000000",
+		"6861736831000000000000000000000000000000",
+		"6861736832000000000000000000000000000000",
+	}
+	var streams []stream.Stream
+	for _, h := range hashes {
+		st, _ := stream.NewStream(h, "Channel", "")
+		streams = append(streams, st)
+	}
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return streams, nil
+		},
+	}
+
+	var probed []string
+	engine := &mockAceStreamEngine{
+		startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+			probed = append(probed, infoHash)
+			return "http://localhost/stream", nil
+		},
+		getStatsFunc: func(ctx context.Context, pid string) (driven.StreamStats, error) {
+			return driven.StreamStats{Peers: 10, SpeedDown: 100000, Status: "dl"}, nil
+		},
+	}
+	svc := newTestProbeService(&mockProbeRepository{}, streamRepo, engine)
+	svc.SetBatchSize(2)
+
+	for range 3 {
+		if err := svc.ProbeAllStreams(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := []string{hashes[1], hashes[2], hashes[0], hashes[1], hashes[2], hashes[0]}
+	if !slices.Equal(probed, want) {
+		t.Errorf("probed %v, want every stream in turn %v", probed, want)
+	}
+}
+
+func TestProbeService_PrebufferWait(t *testing.T) {
+	tests := []struct {
+		name          string
+		wait          time.Duration
+		wantAvailable bool
+	}{
+		{"judged after prebuffering", time.Second, true},
+		{"judged at once without a wait", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls := 0
+			engine := &mockAceStreamEngine{
+				startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+					return "http://localhost/stream", nil
+				},
+				getStatsFunc: func(ctx context.Context, pid string) (driven.StreamStats, error) {
+					polls++
+					if polls < 3 {
+						return driven.StreamStats{Status: "prebuf"}, nil
+					}
+					return driven.StreamStats{Peers: 10, SpeedDown: 100000, Status: "dl"}, nil
+				},
+			}
+			svc := newTestProbeService(&mockProbeRepository{}, &mockStreamRepository{}, engine)
+			svc.SetPrebufferWait(tt.wait)
+			svc.prebufferPoll = time.Millisecond
+
+			result, err := svc.ProbeStream(context.Background(), "6861736831000000000000000000000000000000")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Available() != tt.wantAvailable {
+				t.Errorf("Available() = %v, want %v", result.Available(), tt.wantAvailable)
+			}
+		})
+	}
+}
+
 func TestProbeService_Cleanup(t *testing.T) {
 	var deletedBefore time.Time
 
 	probeRepo := &mockProbeRepository{
-		deleteBeforeFunc: func(ctx context.Context, before time.Time) error {
+		deleteBeforeFunc: func(ctx context.Context, before time.Time) (int, error) {
 			deletedBefore = before
-			return nil
+			return 0, nil
 		},
 	}
 
@@ -581,3 +757,162 @@ func TestProbeService_Cleanup(t *testing.T) {
 		t.Errorf("DeleteBefore cutoff = %v, expected ~%v (diff: %v)", deletedBefore, expectedCutoff, diff)
 	}
 }
+
+func TestProbeService_PruneOlderThan(t *testing.T) {
+	t.Run("removes only results older than max age", func(t *testing.T) {
+		now := time.Now()
+		old, _ := probe.NewResult("abc123", now.Add(-10*24*time.Hour), true, time.Second, 5, 1000, "dl", "")
+		recent, _ := probe.NewResult("abc123", now.Add(-time.Hour), true, time.Second, 5, 1000, "dl", "")
+		stored := []probe.Result{old, recent}
+
+		probeRepo := &mockProbeRepository{
+			deleteBeforeFunc: func(ctx context.Context, before time.Time) (int, error) {
+				kept := stored[:0]
+				deleted := 0
+				for _, r := range stored {
+					if r.Timestamp().Before(before) {
+						deleted++
+						continue
+					}
+					kept = append(kept, r)
+				}
+				stored = kept
+				return deleted, nil
+			},
+		}
+
+		svc := NewProbeService(probeRepo, &mockStreamRepository{}, &mockAceStreamEngine{}, newTestLogger(), 30*time.Second, 24*time.Hour, nil, 0, 0)
+
+		pruned, err := svc.PruneOlderThan(context.Background(), 7*24*time.Hour)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pruned != 1 {
+			t.Errorf("expected 1 pruned result, got %d", pruned)
+		}
+		if len(stored) != 1 || !stored[0].Timestamp().Equal(recent.Timestamp()) {
+			t.Errorf("expected only the recent result to remain, got %v", stored)
+		}
+	})
+
+	t.Run("zero max age disables pruning", func(t *testing.T) {
+		probeRepo := &mockProbeRepository{
+			deleteBeforeFunc: func(ctx context.Context, before time.Time) (int, error) {
+				t.Fatal("DeleteBefore should not be called when pruning is disabled")
+				return 0, nil
+			},
+		}
+
+		svc := NewProbeService(probeRepo, &mockStreamRepository{}, &mockAceStreamEngine{}, newTestLogger(), 30*time.Second, 24*time.Hour, nil, 0, 0)
+
+		pruned, err := svc.PruneOlderThan(context.Background(), 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pruned != 0 {
+			t.Errorf("expected 0 pruned results, got %d", pruned)
+		}
+	})
+}
+
+func TestProbeService_GetStreamHealth(t *testing.T) {
+	now := time.Now()
+	s1, _ := stream.NewStream("6861736831000000000000000000000000000000", "Channel1", "")
+	s2, _ := stream.NewStream("6861736832000000000000000000000000000000", "Channel1", "")
+
+	streamRepo := &mockStreamRepository{
+		findByInfoHashFunc: func(ctx context.Context, infoHash string) (stream.Stream, error) {
+			switch infoHash {
+			case "6861736831000000000000000000000000000000":
+				return s1, nil
+			case "6861736832000000000000000000000000000000":
+				return s2, nil
+			}
+			return stream.Stream{}, stream.ErrStreamNotFound
+		},
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			return []stream.Stream{s1, s2}, nil
+		},
+	}
+	probeRepo := &mockProbeRepository{
+		findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+			if infoHash == "6861736831000000000000000000000000000000" {
+				return []probe.Result{
+					probe.ReconstructResult(infoHash, now, true, time.Second, 20, 200000, "dl", ""),
+					probe.ReconstructResult(infoHash, now.Add(-time.Hour), false, 0, 0, 0, "", "timeout"),
+				}, nil
+			}
+			return []probe.Result{}, nil
+		},
+	}
+	svc := newTestProbeService(probeRepo, streamRepo, &mockAceStreamEngine{})
+
+	t.Run("returns score, metrics and latest probe", func(t *testing.T) {
+		health, err := svc.GetStreamHealth(context.Background(), "6861736831000000000000000000000000000000")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if health.ChannelName != "Channel1" {
+			t.Errorf("ChannelName = %q, want Channel1", health.ChannelName)
+		}
+		if health.Score <= 0 {
+			t.Errorf("expected positive score, got %f", health.Score)
+		}
+		if health.Metrics.TotalProbes() != 2 {
+			t.Errorf("TotalProbes = %d, want 2", health.Metrics.TotalProbes())
+		}
+		if !health.LastProbe.Timestamp().Equal(now) {
+			t.Errorf("expected latest probe at %v, got %v", now, health.LastProbe.Timestamp())
+		}
+	})
+
+	t.Run("returns ErrNoProbeData for unprobed stream", func(t *testing.T) {
+		if _, err := svc.GetStreamHealth(context.Background(), "6861736832000000000000000000000000000000"); !errors.Is(err, probe.ErrNoProbeData) {
+			t.Errorf("expected ErrNoProbeData, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrStreamNotFound for unknown stream", func(t *testing.T) {
+		if _, err := svc.GetStreamHealth(context.Background(), "missing"); !errors.Is(err, stream.ErrStreamNotFound) {
+			t.Errorf("expected ErrStreamNotFound, got %v", err)
+		}
+	})
+}
+
+func TestProbeService_RankStreams(t *testing.T) {
+	now := time.Now()
+	streams := make([]stream.Stream, 0, 4)
+	for _, h := range []string{"756e70726f626564310000000000000000000000", "7765616b00000000000000000000000000000000", "7374726f6e670000000000000000000000000000", "756e70726f626564320000000000000000000000"} {
+		st, _ := stream.NewStream(h, "Channel1", "")
+		streams = append(streams, st)
+	}
+
+	streamRepo := &mockStreamRepository{
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			return streams, nil
+		},
+	}
+	probeRepo := &mockProbeRepository{
+		findByInfoHashSinceFunc: func(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error) {
+			switch infoHash {
+			case "7374726f6e670000000000000000000000000000":
+				return []probe.Result{probe.ReconstructResult(infoHash, now, true, time.Second, 20, 200000, "dl", "")}, nil
+			case "7765616b00000000000000000000000000000000":
+				return []probe.Result{probe.ReconstructResult(infoHash, now, false, 0, 0, 0, "", "timeout")}, nil
+			}
+			return []probe.Result{}, nil
+		},
+	}
+	svc := newTestProbeService(probeRepo, streamRepo, &mockAceStreamEngine{})
+
+	got := svc.RankStreams(context.Background(), "Channel1", []string{"756e70726f626564310000000000000000000000", "7765616b00000000000000000000000000000000", "7374726f6e670000000000000000000000000000", "756e70726f626564320000000000000000000000"})
+	want := []string{"7374726f6e670000000000000000000000000000", "7765616b00000000000000000000000000000000", "756e70726f626564310000000000000000000000", "756e70726f626564320000000000000000000000"}
+	if len(got) != len(want) {
+		t.Fatalf("RankStreams() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("RankStreams() = %v, want %v", got, want)
+		}
+	}
+}
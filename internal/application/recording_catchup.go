@@ -0,0 +1,117 @@
+package application
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/recording"
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
+)
+
+// CatchupWindows returns, by channel name, the start of the oldest recording
+// that captured something, for the channels players can play back from
+// their recordings.
+func (s *RecordingService) CatchupWindows(ctx context.Context) (map[string]time.Time, error) {
+	recordings, err := s.ListRecordings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := make(map[string]time.Time)
+	for _, rec := range recordings {
+		if !hasCatchup(rec) {
+			continue
+		}
+		if start, ok := windows[rec.ChannelName()]; !ok || rec.StartAt().Before(start) {
+			windows[rec.ChannelName()] = rec.StartAt()
+		}
+	}
+	return windows, nil
+}
+
+// hasCatchup reports whether a recording has started capturing and, once
+// finished, captured something.
+func hasCatchup(rec recording.Recording) bool {
+	switch {
+	case rec.Status() == recording.StatusRecording:
+		return true
+	case rec.IsFinished():
+		return rec.Size() > 0
+	}
+	return false
+}
+
+// OpenCatchup returns the channel as recorded from from until to, or until
+// the end of the recording if to is zero, which the caller must close. The
+// recording covering from is played from the position its duration and
+// size place from at, so the timing is only as exact as the stream's
+// bitrate is constant. A recording in progress is played up to what it has
+// captured so far.
+// Returns recording.ErrNoCatchup if no recording of the channel covers from.
+func (s *RecordingService) OpenCatchup(ctx context.Context, channelName string, from, to time.Time) (io.ReadCloser, error) {
+	recordings, err := s.ListRecordings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Recordings are ordered by start time; the latest to start wins where
+	// they overlap
+	var rec recording.Recording
+	found := false
+	for _, r := range recordings {
+		if r.ChannelName() == channelName && hasCatchup(r) && !from.Before(r.StartAt()) && from.Before(r.StopAt()) {
+			rec, found = r, true
+		}
+	}
+	if !found {
+		return nil, recording.ErrNoCatchup
+	}
+
+	f, modTime, err := s.store.Open(ctx, rec.ID())
+	if err != nil {
+		return nil, err
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	// The capture ended when its file was last written, or is still going
+	end := rec.StopAt()
+	if now := s.now(); rec.Status() == recording.StatusRecording && now.Before(end) {
+		end = now
+	} else if rec.IsFinished() && modTime.After(rec.StartAt()) && modTime.Before(end) {
+		end = modTime
+	}
+	if !from.Before(end) {
+		f.Close()
+		return nil, recording.ErrNoCatchup
+	}
+
+	start := catchupOffset(rec.StartAt(), end, size, from)
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if to.IsZero() || !to.Before(end) {
+		return f, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(f, catchupOffset(rec.StartAt(), end, size, to)-start), f}, nil
+}
+
+// catchupOffset returns the position of at in a file of size bytes captured
+// from start to end, rounded down to a TS packet so players can sync to the
+// stream at once.
+func catchupOffset(start, end time.Time, size int64, at time.Time) int64 {
+	if !at.After(start) {
+		return 0
+	}
+	offset := int64(float64(size) * float64(at.Sub(start)) / float64(end.Sub(start)))
+	offset -= offset % mpegts.PacketSize
+	return min(offset, size)
+}
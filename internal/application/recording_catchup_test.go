@@ -0,0 +1,104 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/recording"
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
+)
+
+// newCatchupTestService returns a RecordingService holding the given
+// recordings, each with a file of size bytes whose every TS packet is
+// filled with its index.
+func newCatchupTestService(t *testing.T, size int, recordings ...recording.Recording) *RecordingService {
+	t.Helper()
+
+	repo := newMemRecordingRepository()
+	store := newMemRecordingStore()
+	for _, rec := range recordings {
+		if err := repo.Save(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Status() == recording.StatusScheduled {
+			continue
+		}
+		w, _ := store.Create(context.Background(), rec.ID())
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i / mpegts.PacketSize)
+		}
+		_, _ = w.Write(data)
+	}
+	return NewRecordingService(repo, store, nil, nil, nil, 0, slog.Default())
+}
+
+func TestRecordingService_OpenCatchup(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	stop := start.Add(100 * time.Second)
+	completed := recording.ReconstructRecording("rec-1", "News", start, stop, recording.StatusCompleted, 100*mpegts.PacketSize, "", start)
+	scheduled := recording.ReconstructRecording("rec-2", "News", stop.Add(time.Hour), stop.Add(2*time.Hour), recording.StatusScheduled, 0, "", start)
+	service := newCatchupTestService(t, 100*mpegts.PacketSize, completed, scheduled)
+
+	read := func(t *testing.T, from, to time.Time) []byte {
+		t.Helper()
+		rc, err := service.OpenCatchup(ctx, "News", from, to)
+		if err != nil {
+			t.Fatalf("OpenCatchup() error = %v", err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	t.Run("plays from the position of the requested time", func(t *testing.T) {
+		data := read(t, start.Add(50*time.Second), time.Time{})
+		if len(data) != 50*mpegts.PacketSize || data[0] != 50 {
+			t.Errorf("expected the last 50 packets, got %d bytes starting with packet %d", len(data), data[0])
+		}
+	})
+
+	t.Run("stops at the requested end", func(t *testing.T) {
+		data := read(t, start.Add(50*time.Second), start.Add(60*time.Second))
+		if len(data) != 10*mpegts.PacketSize || data[0] != 50 || data[len(data)-1] != 59 {
+			t.Errorf("expected packets 50 to 59, got %d bytes", len(data))
+		}
+	})
+
+	t.Run("reports times no recording covers", func(t *testing.T) {
+		for _, tt := range []struct {
+			channel string
+			from    time.Time
+		}{
+			{"News", start.Add(-time.Second)},
+			{"News", stop},
+			{"News", stop.Add(90 * time.Minute)},
+			{"Sports", start.Add(time.Second)},
+		} {
+			if _, err := service.OpenCatchup(ctx, tt.channel, tt.from, time.Time{}); !errors.Is(err, recording.ErrNoCatchup) {
+				t.Errorf("OpenCatchup(%s, %v) error = %v, want ErrNoCatchup", tt.channel, tt.from, err)
+			}
+		}
+	})
+
+	t.Run("lists the channels with recordings to play back", func(t *testing.T) {
+		empty := recording.ReconstructRecording("rec-3", "Sports", start, stop, recording.StatusFailed, 0, "no data received", start)
+		service := newCatchupTestService(t, 100*mpegts.PacketSize, completed, scheduled, empty)
+
+		windows, err := service.CatchupWindows(ctx)
+		if err != nil {
+			t.Fatalf("CatchupWindows() error = %v", err)
+		}
+		if len(windows) != 1 || !windows["News"].Equal(start) {
+			t.Errorf("expected only News from %v, got %v", start, windows)
+		}
+	})
+}
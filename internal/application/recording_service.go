@@ -0,0 +1,415 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/recording"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// recordingRetryDelay is how long a recording waits before re-attaching to a
+// channel whose stream ended before the stop time.
+const recordingRetryDelay = 5 * time.Second
+
+// errNoRecordingData is recorded when a capture ends without any data.
+var errNoRecordingData = errors.New("no data received")
+
+// RecordingService schedules and captures recordings of channels. A capture
+// joins the channel's stream through the proxy like any other client, so
+// recording a channel that is being watched adds no engine load.
+type RecordingService struct {
+	repo        driven.RecordingRepository
+	store       driven.RecordingStore
+	channelRepo driven.ChannelRepository
+	streamRepo  driven.StreamRepository
+	proxy       *AceStreamProxyService
+	probe       *ProbeService
+	events      *EventBus
+	retention   time.Duration
+	logger      *slog.Logger
+	now         func() time.Time
+
+	mu     sync.Mutex
+	active map[string]*activeRecording
+	wg     sync.WaitGroup
+}
+
+// activeRecording is a capture in progress.
+type activeRecording struct {
+	cancel    context.CancelFunc
+	done      chan struct{}
+	written   atomic.Int64
+	cancelled atomic.Bool
+}
+
+// NewRecordingService creates a new RecordingService. Finished recordings are
+// deleted once their stop time is older than retention; zero or less keeps
+// them all.
+func NewRecordingService(
+	repo driven.RecordingRepository,
+	store driven.RecordingStore,
+	channelRepo driven.ChannelRepository,
+	streamRepo driven.StreamRepository,
+	proxy *AceStreamProxyService,
+	retention time.Duration,
+	logger *slog.Logger,
+) *RecordingService {
+	return &RecordingService{
+		repo:        repo,
+		store:       store,
+		channelRepo: channelRepo,
+		streamRepo:  streamRepo,
+		proxy:       proxy,
+		retention:   retention,
+		logger:      logger,
+		now:         time.Now,
+		active:      make(map[string]*activeRecording),
+	}
+}
+
+// SetProbeService makes captures try a channel's streams best quality first.
+func (s *RecordingService) SetProbeService(probe *ProbeService) {
+	s.probe = probe
+}
+
+// SetEventBus makes the service publish EventRecordingFinished whenever a
+// capture ends, except when the recording was cancelled.
+func (s *RecordingService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// Schedule records the channel between startAt and stopAt. A recording whose
+// start time has already passed begins immediately.
+// Returns channel.ErrChannelNotFound if the channel does not exist.
+// Returns recording.ErrInvalidWindow or recording.ErrWindowPassed if the
+// times are unusable.
+func (s *RecordingService) Schedule(ctx context.Context, channelName string, startAt, stopAt time.Time) (recording.Recording, error) {
+	rec, err := recording.NewRecording(channelName, startAt, stopAt, s.now())
+	if err != nil {
+		return recording.Recording{}, err
+	}
+	if _, err := s.channelRepo.FindByName(ctx, rec.ChannelName()); err != nil {
+		return recording.Recording{}, err
+	}
+
+	if err := s.repo.Save(ctx, rec); err != nil {
+		return recording.Recording{}, err
+	}
+	s.logger.InfoContext(ctx, "recording scheduled",
+		"id", rec.ID(),
+		"channel", rec.ChannelName(),
+		"start_at", rec.StartAt(),
+		"stop_at", rec.StopAt())
+
+	if rec.IsDue(s.now()) {
+		if err := s.start(&rec); err != nil {
+			return recording.Recording{}, err
+		}
+	}
+	return rec, nil
+}
+
+// RecordNow records the channel from now for the given duration.
+func (s *RecordingService) RecordNow(ctx context.Context, channelName string, d time.Duration) (recording.Recording, error) {
+	now := s.now()
+	return s.Schedule(ctx, channelName, now, now.Add(d))
+}
+
+// ListRecordings returns all recordings ordered by start time. Recordings in
+// progress report the bytes captured so far.
+func (s *RecordingService) ListRecordings(ctx context.Context) ([]recording.Recording, error) {
+	recordings, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range recordings {
+		recordings[i] = s.withProgress(recordings[i])
+	}
+	return recordings, nil
+}
+
+// GetRecording retrieves a recording by its ID.
+// Returns recording.ErrRecordingNotFound if the recording does not exist.
+func (s *RecordingService) GetRecording(ctx context.Context, id string) (recording.Recording, error) {
+	rec, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return recording.Recording{}, err
+	}
+	return s.withProgress(rec), nil
+}
+
+// OpenRecording returns the recording and its captured file, which the caller
+// must close. A recording in progress can be read while it grows.
+// Returns recording.ErrRecordingNotFound if the recording does not exist.
+// Returns recording.ErrNoFile if capturing has not started.
+func (s *RecordingService) OpenRecording(ctx context.Context, id string) (recording.Recording, io.ReadSeekCloser, time.Time, error) {
+	rec, err := s.GetRecording(ctx, id)
+	if err != nil {
+		return recording.Recording{}, nil, time.Time{}, err
+	}
+	f, modTime, err := s.store.Open(ctx, id)
+	if err != nil {
+		return recording.Recording{}, nil, time.Time{}, err
+	}
+	return rec, f, modTime, nil
+}
+
+// DeleteRecording stops the recording if it is capturing, then removes it
+// and its file.
+// Returns recording.ErrRecordingNotFound if the recording does not exist.
+func (s *RecordingService) DeleteRecording(ctx context.Context, id string) error {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	active := s.active[id]
+	s.mu.Unlock()
+	if active != nil {
+		active.cancelled.Store(true)
+		active.cancel()
+		<-active.done
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := s.store.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete recording file: %w", err)
+	}
+	s.logger.InfoContext(ctx, "recording deleted", "id", id)
+	return nil
+}
+
+// RunSchedule starts the recordings that are due and deletes finished
+// recordings older than the retention. It is meant to be run by a scheduler.
+func (s *RecordingService) RunSchedule(ctx context.Context) error {
+	recordings, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	now := s.now()
+	for _, rec := range recordings {
+		switch {
+		case rec.IsDue(now):
+			if err := s.start(&rec); err != nil {
+				s.logger.Error("failed to start recording", "id", rec.ID(), "error", err)
+			}
+		case rec.IsFinished() && s.retention > 0 && now.Sub(rec.StopAt()) > s.retention:
+			if err := s.DeleteRecording(ctx, rec.ID()); err != nil {
+				s.logger.Error("failed to delete expired recording", "id", rec.ID(), "error", err)
+				continue
+			}
+			s.logger.Info("expired recording deleted", "id", rec.ID(), "channel", rec.ChannelName())
+		}
+	}
+	return nil
+}
+
+// MarkInterrupted fails the recordings left capturing by a previous process,
+// keeping whatever they captured. It is meant to be called once at startup,
+// before RunSchedule.
+func (s *RecordingService) MarkInterrupted(ctx context.Context) error {
+	recordings, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	for _, rec := range recordings {
+		if rec.Status() != recording.StatusRecording {
+			continue
+		}
+		size := rec.Size()
+		if f, _, err := s.store.Open(ctx, rec.ID()); err == nil {
+			size, _ = f.Seek(0, io.SeekEnd)
+			f.Close()
+		}
+		rec.Finish(size, errors.New("interrupted by restart"))
+		if err := s.repo.Update(ctx, rec); err != nil {
+			return fmt.Errorf("failed to update interrupted recording: %w", err)
+		}
+		s.logger.Warn("recording interrupted by restart", "id", rec.ID(), "channel", rec.ChannelName(), "bytes", size)
+	}
+	return nil
+}
+
+// Shutdown stops all captures in progress and waits for them to save their
+// state, or for ctx to end.
+func (s *RecordingService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	for _, active := range s.active {
+		active.cancel()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *RecordingService) withProgress(rec recording.Recording) recording.Recording {
+	s.mu.Lock()
+	active := s.active[rec.ID()]
+	s.mu.Unlock()
+	if active == nil || rec.Status() != recording.StatusRecording {
+		return rec
+	}
+	return recording.ReconstructRecording(rec.ID(), rec.ChannelName(), rec.StartAt(), rec.StopAt(),
+		rec.Status(), active.written.Load(), rec.LastError(), rec.CreatedAt())
+}
+
+// start marks the recording as capturing and begins the capture in the
+// background.
+func (s *RecordingService) start(rec *recording.Recording) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.active[rec.ID()]; ok {
+		return nil
+	}
+
+	ctx := context.Background()
+	rec.Start()
+	if err := s.repo.Update(ctx, *rec); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, rec.StopAt())
+	active := &activeRecording{cancel: cancel, done: make(chan struct{})}
+	s.active[rec.ID()] = active
+	s.wg.Add(1)
+	go s.capture(ctx, *rec, active)
+	return nil
+}
+
+// capture writes the channel's stream to the recording's file until the stop
+// time, re-attaching whenever the stream ends early.
+func (s *RecordingService) capture(ctx context.Context, rec recording.Recording, active *activeRecording) {
+	defer s.wg.Done()
+	defer close(active.done)
+	defer func() {
+		s.mu.Lock()
+		delete(s.active, rec.ID())
+		s.mu.Unlock()
+		active.cancel()
+	}()
+
+	s.logger.Info("recording started", "id", rec.ID(), "channel", rec.ChannelName(), "stop_at", rec.StopAt())
+
+	streamErr := s.captureToFile(ctx, rec, active)
+	size := active.written.Load()
+
+	switch {
+	case active.cancelled.Load():
+		rec.Cancel(size)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) && size > 0:
+		rec.Finish(size, nil)
+	case errors.Is(ctx.Err(), context.Canceled):
+		rec.Finish(size, errors.New("interrupted by shutdown"))
+	default:
+		if streamErr == nil {
+			streamErr = errNoRecordingData
+		}
+		rec.Finish(size, streamErr)
+	}
+
+	if active.cancelled.Load() {
+		return
+	}
+	if err := s.repo.Update(context.Background(), rec); err != nil {
+		s.logger.Error("failed to save recording", "id", rec.ID(), "error", err)
+	}
+	s.logger.Info("recording ended",
+		"id", rec.ID(),
+		"channel", rec.ChannelName(),
+		"status", rec.Status(),
+		"bytes", size,
+		"error", rec.LastError())
+	s.events.Publish(EventRecordingFinished, RecordingEventData{
+		ID:          rec.ID(),
+		ChannelName: rec.ChannelName(),
+		Status:      string(rec.Status()),
+		Bytes:       size,
+		Error:       rec.LastError(),
+	})
+}
+
+// captureToFile streams the channel into the recording's file until ctx
+// ends, re-attaching whenever the stream ends early. Returns the last error
+// that ended a stream, or the error that made capturing impossible.
+func (s *RecordingService) captureToFile(ctx context.Context, rec recording.Recording, active *activeRecording) error {
+	file, err := s.store.Create(ctx, rec.ID())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	dst := &countingWriter{dst: file, count: &active.written}
+	ctx = WithClientInfo(ctx, ClientInfo{UserAgent: "recorder", Channel: rec.ChannelName()})
+
+	var lastErr error
+	for {
+		err := s.streamChannel(ctx, rec.ChannelName(), dst)
+		if ctx.Err() != nil {
+			return lastErr
+		}
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			return err
+		}
+		if err == nil {
+			err = errors.New("stream ended")
+		}
+		lastErr = err
+
+		s.logger.Warn("recording stream ended early, retrying",
+			"id", rec.ID(),
+			"channel", rec.ChannelName(),
+			"error", err,
+			"delay", recordingRetryDelay)
+		select {
+		case <-ctx.Done():
+			return lastErr
+		case <-time.After(recordingRetryDelay):
+		}
+	}
+}
+
+// streamChannel streams the channel to dst with failover between its streams.
+func (s *RecordingService) streamChannel(ctx context.Context, channelName string, dst io.Writer) error {
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		return err
+	}
+
+	streams, err := s.streamRepo.FindByChannelName(ctx, channelName)
+	if err != nil && !errors.Is(err, stream.ErrStreamNotFound) {
+		return err
+	}
+	infoHashes := make([]string, len(streams))
+	for i, st := range streams {
+		infoHashes[i] = st.InfoHash()
+	}
+	if s.probe != nil {
+		infoHashes = s.probe.RankStreams(ctx, channelName, infoHashes)
+	}
+
+	_, err = s.proxy.StreamWithFailover(ctx, channelName, infoHashes, dst, StreamOptions{TranscodeAudio: ch.AudioTranscode(), TranscodeVideo: ch.VideoTranscode()})
+	return err
+}
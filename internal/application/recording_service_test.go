@@ -0,0 +1,334 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/recording"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// memRecordingRepository is an in-memory RecordingRepository safe for use by
+// background captures.
+type memRecordingRepository struct {
+	mu   sync.Mutex
+	byID map[string]recording.Recording
+}
+
+func newMemRecordingRepository() *memRecordingRepository {
+	return &memRecordingRepository{byID: make(map[string]recording.Recording)}
+}
+
+func (r *memRecordingRepository) Save(ctx context.Context, rec recording.Recording) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byID[rec.ID()] = rec
+	return nil
+}
+
+func (r *memRecordingRepository) Update(ctx context.Context, rec recording.Recording) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byID[rec.ID()]; !ok {
+		return recording.ErrRecordingNotFound
+	}
+	r.byID[rec.ID()] = rec
+	return nil
+}
+
+func (r *memRecordingRepository) FindAll(ctx context.Context) ([]recording.Recording, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	all := make([]recording.Recording, 0, len(r.byID))
+	for _, rec := range r.byID {
+		all = append(all, rec)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].StartAt().Before(all[j].StartAt()) })
+	return all, nil
+}
+
+func (r *memRecordingRepository) FindByID(ctx context.Context, id string) (recording.Recording, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.byID[id]
+	if !ok {
+		return recording.Recording{}, recording.ErrRecordingNotFound
+	}
+	return rec, nil
+}
+
+func (r *memRecordingRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byID[id]; !ok {
+		return recording.ErrRecordingNotFound
+	}
+	delete(r.byID, id)
+	return nil
+}
+
+// memRecordingStore is an in-memory RecordingStore.
+type memRecordingStore struct {
+	mu    sync.Mutex
+	files map[string]*bytes.Buffer
+}
+
+func newMemRecordingStore() *memRecordingStore {
+	return &memRecordingStore{files: make(map[string]*bytes.Buffer)}
+}
+
+type memRecordingFile struct {
+	store *memRecordingStore
+	buf   *bytes.Buffer
+}
+
+func (f *memRecordingFile) Write(p []byte) (int, error) {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+	return f.buf.Write(p)
+}
+
+func (f *memRecordingFile) Close() error { return nil }
+
+type memRecordingReader struct {
+	*bytes.Reader
+}
+
+func (memRecordingReader) Close() error { return nil }
+
+func (s *memRecordingStore) Create(ctx context.Context, id string) (io.WriteCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf := &bytes.Buffer{}
+	s.files[id] = buf
+	return &memRecordingFile{store: s, buf: buf}, nil
+}
+
+func (s *memRecordingStore) Open(ctx context.Context, id string) (io.ReadSeekCloser, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf, ok := s.files[id]
+	if !ok {
+		return nil, time.Time{}, recording.ErrNoFile
+	}
+	return memRecordingReader{bytes.NewReader(bytes.Clone(buf.Bytes()))}, time.Time{}, nil
+}
+
+func (s *memRecordingStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, id)
+	return nil
+}
+
+func (s *memRecordingStore) content(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf, ok := s.files[id]
+	if !ok {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// newRecordingTestService returns a RecordingService for a channel "News"
+// whose single stream writes data and then stays open until stopped.
+func newRecordingTestService(t *testing.T, data string) (*RecordingService, *memRecordingRepository, *memRecordingStore) {
+	t.Helper()
+
+	ch, _ := channel.NewChannel("News")
+	channelRepo, _ := newMemChannelRepository(ch)
+	st, _ := stream.NewStream("6e6577732d686173680000000000000000000000", "News", stream.SourceManual)
+	streamRepo := &mockStreamRepository{
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			return []stream.Stream{st}, nil
+		},
+	}
+
+	engine := &mockAceStreamEngine{
+		streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+			// Give the subscriber time to attach to the broadcaster
+			time.Sleep(20 * time.Millisecond)
+			if _, err := dst.Write([]byte(data)); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	proxy := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+	repo := newMemRecordingRepository()
+	store := newMemRecordingStore()
+	service := NewRecordingService(repo, store, channelRepo, streamRepo, proxy, 24*time.Hour, slog.Default())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = service.Shutdown(ctx)
+	})
+	return service, repo, store
+}
+
+// waitForStatus polls until the recording reaches the status or fails the test.
+func waitForStatus(t *testing.T, repo *memRecordingRepository, id string, status recording.Status) recording.Recording {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		rec, err := repo.FindByID(context.Background(), id)
+		if err == nil && rec.Status() == status {
+			return rec
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	rec, _ := repo.FindByID(context.Background(), id)
+	t.Fatalf("expected status %q, got %q (error %q)", status, rec.Status(), rec.LastError())
+	return rec
+}
+
+func TestRecordingService_Schedule(t *testing.T) {
+	t.Run("rejects unknown channel", func(t *testing.T) {
+		service, _, _ := newRecordingTestService(t, "data")
+		now := time.Now()
+		_, err := service.Schedule(context.Background(), "Missing", now.Add(time.Hour), now.Add(2*time.Hour))
+		if !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected ErrChannelNotFound, got %v", err)
+		}
+	})
+
+	t.Run("rejects invalid window", func(t *testing.T) {
+		service, _, _ := newRecordingTestService(t, "data")
+		now := time.Now()
+		_, err := service.Schedule(context.Background(), "News", now.Add(2*time.Hour), now.Add(time.Hour))
+		if !errors.Is(err, recording.ErrInvalidWindow) {
+			t.Errorf("expected ErrInvalidWindow, got %v", err)
+		}
+	})
+
+	t.Run("future recording waits for the schedule", func(t *testing.T) {
+		service, repo, store := newRecordingTestService(t, "data")
+		now := time.Now()
+		rec, err := service.Schedule(context.Background(), "News", now.Add(time.Hour), now.Add(2*time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rec.Status() != recording.StatusScheduled {
+			t.Errorf("expected status scheduled, got %q", rec.Status())
+		}
+
+		if err := service.RunSchedule(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := store.content(rec.ID()); ok {
+			t.Error("expected no capture before the start time")
+		}
+
+		service.now = func() time.Time { return now.Add(time.Hour) }
+		if err := service.RunSchedule(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		waitForStatus(t, repo, rec.ID(), recording.StatusRecording)
+	})
+}
+
+func TestRecordingService_RecordNow(t *testing.T) {
+	service, repo, store := newRecordingTestService(t, "ts-data")
+
+	rec, err := service.RecordNow(context.Background(), "News", 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Status() != recording.StatusRecording {
+		t.Errorf("expected status recording, got %q", rec.Status())
+	}
+
+	done := waitForStatus(t, repo, rec.ID(), recording.StatusCompleted)
+	if done.Size() != int64(len("ts-data")) {
+		t.Errorf("expected size %d, got %d", len("ts-data"), done.Size())
+	}
+	if content, _ := store.content(rec.ID()); content != "ts-data" {
+		t.Errorf("expected captured content 'ts-data', got %q", content)
+	}
+}
+
+func TestRecordingService_DeleteRecording(t *testing.T) {
+	service, repo, store := newRecordingTestService(t, "ts-data")
+
+	rec, err := service.RecordNow(context.Background(), "News", time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := service.DeleteRecording(context.Background(), rec.ID()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.FindByID(context.Background(), rec.ID()); !errors.Is(err, recording.ErrRecordingNotFound) {
+		t.Errorf("expected recording to be removed, got %v", err)
+	}
+	if _, ok := store.content(rec.ID()); ok {
+		t.Error("expected recording file to be removed")
+	}
+
+	if err := service.DeleteRecording(context.Background(), rec.ID()); !errors.Is(err, recording.ErrRecordingNotFound) {
+		t.Errorf("expected ErrRecordingNotFound, got %v", err)
+	}
+}
+
+func TestRecordingService_RunScheduleRetention(t *testing.T) {
+	service, repo, store := newRecordingTestService(t, "data")
+	now := time.Now()
+
+	expired := recording.ReconstructRecording("aa", "News", now.Add(-50*time.Hour), now.Add(-49*time.Hour),
+		recording.StatusCompleted, 4, "", now.Add(-50*time.Hour))
+	recent := recording.ReconstructRecording("bb", "News", now.Add(-2*time.Hour), now.Add(-time.Hour),
+		recording.StatusCompleted, 4, "", now.Add(-2*time.Hour))
+	for _, rec := range []recording.Recording{expired, recent} {
+		_ = repo.Save(context.Background(), rec)
+		w, _ := store.Create(context.Background(), rec.ID())
+		_, _ = w.Write([]byte("data"))
+	}
+
+	if err := service.RunSchedule(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := repo.FindByID(context.Background(), "aa"); !errors.Is(err, recording.ErrRecordingNotFound) {
+		t.Errorf("expected expired recording to be deleted, got %v", err)
+	}
+	if _, ok := store.content("aa"); ok {
+		t.Error("expected expired recording file to be deleted")
+	}
+	if _, err := repo.FindByID(context.Background(), "bb"); err != nil {
+		t.Errorf("expected recent recording to be kept, got %v", err)
+	}
+}
+
+func TestRecordingService_MarkInterrupted(t *testing.T) {
+	service, repo, store := newRecordingTestService(t, "data")
+	now := time.Now()
+
+	stale := recording.ReconstructRecording("aa", "News", now.Add(-time.Hour), now.Add(time.Hour),
+		recording.StatusRecording, 0, "", now.Add(-time.Hour))
+	_ = repo.Save(context.Background(), stale)
+	w, _ := store.Create(context.Background(), "aa")
+	_, _ = w.Write([]byte("partial"))
+
+	if err := service.MarkInterrupted(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec, _ := repo.FindByID(context.Background(), "aa")
+	if rec.Status() != recording.StatusFailed {
+		t.Errorf("expected status failed, got %q", rec.Status())
+	}
+	if rec.Size() != int64(len("partial")) {
+		t.Errorf("expected size %d, got %d", len("partial"), rec.Size())
+	}
+}
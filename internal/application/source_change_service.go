@@ -0,0 +1,222 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/sourcechange"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// sourceChangeRetention is how long detected source changes are kept.
+const sourceChangeRetention = 30 * 24 * time.Hour
+
+// SourceChangeService detects the hashes added to and removed from upstream
+// sources between refreshes and keeps a report of them.
+type SourceChangeService struct {
+	repo        driven.SourceChangeRepository
+	streamRepo  driven.StreamRepository
+	channelRepo driven.ChannelRepository
+	autoDisable bool
+	events      *EventBus
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+// NewSourceChangeService creates a new source change service.
+func NewSourceChangeService(
+	repo driven.SourceChangeRepository,
+	streamRepo driven.StreamRepository,
+	channelRepo driven.ChannelRepository,
+	logger *slog.Logger,
+) *SourceChangeService {
+	return &SourceChangeService{
+		repo:        repo,
+		streamRepo:  streamRepo,
+		channelRepo: channelRepo,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// SetAutoDisable controls whether active channels are archived when every
+// one of their streams disappears upstream.
+func (s *SourceChangeService) SetAutoDisable(enabled bool) {
+	s.autoDisable = enabled
+}
+
+// SetEventBus makes the service publish EventSourceChanged whenever a
+// source changed since its previous refresh.
+func (s *SourceChangeService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// Track compares the sources fetched successfully with their previous
+// refresh and records the changes. The first refresh of a source only
+// records a baseline. With auto-disable, channels whose every stream was
+// removed upstream, and is not listed by another source, are archived.
+// Errors are logged and do not stop tracking the other sources.
+func (s *SourceChangeService) Track(ctx context.Context, results []SourceResult) {
+	now := s.now()
+	current := make(map[string]bool)
+	removed := make(map[string]bool)
+
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		for _, hashes := range r.Hashes {
+			for _, h := range hashes {
+				current[h] = true
+			}
+		}
+
+		previous, err := s.repo.FindSnapshot(ctx, r.Source)
+		if err != nil && !errors.Is(err, sourcechange.ErrSnapshotNotFound) {
+			s.logger.Error("failed to load source snapshot", "source", r.Source, "error", err)
+			continue
+		}
+
+		var changes []sourcechange.Change
+		if err == nil {
+			changes = sourcechange.Diff(r.Source, previous, r.Hashes, now)
+		}
+		if err := s.repo.Record(ctx, r.Source, r.Hashes, changes); err != nil {
+			s.logger.Error("failed to record source changes", "source", r.Source, "error", err)
+			continue
+		}
+		if len(changes) > 0 {
+			s.logger.Info("upstream source changed", "source", r.Source, "changes", len(changes))
+			s.events.Publish(EventSourceChanged, sourceChangeEventData(r.Source, changes))
+		}
+
+		for _, c := range changes {
+			if c.Kind() == sourcechange.KindRemoved {
+				removed[c.InfoHash()] = true
+			}
+		}
+	}
+
+	if _, err := s.repo.DeleteBefore(ctx, now.Add(-sourceChangeRetention)); err != nil {
+		s.logger.Error("failed to delete old source changes", "error", err)
+	}
+
+	if !s.autoDisable {
+		return
+	}
+	for h := range current {
+		delete(removed, h)
+	}
+	s.disableOrphanedChannels(ctx, removed)
+}
+
+// sourceChangeEventData lists the channels with added and removed streams,
+// each once, in the order of changes.
+func sourceChangeEventData(source string, changes []sourcechange.Change) SourceChangeEventData {
+	data := SourceChangeEventData{Source: source}
+	seen := make(map[sourcechange.Kind]map[string]bool)
+	for _, c := range changes {
+		if seen[c.Kind()] == nil {
+			seen[c.Kind()] = make(map[string]bool)
+		}
+		if seen[c.Kind()][c.ChannelName()] {
+			continue
+		}
+		seen[c.Kind()][c.ChannelName()] = true
+
+		if c.Kind() == sourcechange.KindAdded {
+			data.Added = append(data.Added, c.ChannelName())
+		} else {
+			data.Removed = append(data.Removed, c.ChannelName())
+		}
+	}
+	return data
+}
+
+// disableOrphanedChannels archives the active channels all of whose streams
+// have a removed hash.
+func (s *SourceChangeService) disableOrphanedChannels(ctx context.Context, removed map[string]bool) {
+	checked := make(map[string]bool)
+	for h := range removed {
+		st, err := s.streamRepo.FindByInfoHash(ctx, h)
+		if err != nil {
+			if !errors.Is(err, stream.ErrStreamNotFound) {
+				s.logger.Error("failed to load stream", "hash", h, "error", err)
+			}
+			continue
+		}
+		if checked[st.ChannelName()] {
+			continue
+		}
+		checked[st.ChannelName()] = true
+
+		if err := s.disableIfOrphaned(ctx, st.ChannelName(), removed); err != nil {
+			s.logger.Error("failed to disable channel", "channel", st.ChannelName(), "error", err)
+		}
+	}
+}
+
+func (s *SourceChangeService) disableIfOrphaned(ctx context.Context, channelName string, removed map[string]bool) error {
+	streams, err := s.streamRepo.FindByChannelName(ctx, channelName)
+	if err != nil {
+		return fmt.Errorf("failed to load streams: %w", err)
+	}
+	for _, st := range streams {
+		if !removed[st.InfoHash()] {
+			return nil
+		}
+	}
+
+	ch, err := s.channelRepo.FindByName(ctx, channelName)
+	if err != nil {
+		if errors.Is(err, channel.ErrChannelNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load channel: %w", err)
+	}
+	if ch.Status() != channel.StatusActive {
+		return nil
+	}
+
+	ch.Archive()
+	if err := s.channelRepo.Update(ctx, ch); err != nil {
+		return fmt.Errorf("failed to archive channel: %w", err)
+	}
+	s.logger.Info("archived channel, its streams disappeared upstream", "channel", channelName)
+	return nil
+}
+
+// Changes returns up to limit changes detected in a source, most recent
+// first. A non-positive limit returns every change.
+func (s *SourceChangeService) Changes(ctx context.Context, source string, limit int) ([]sourcechange.Change, error) {
+	return s.repo.FindBySource(ctx, source, limit)
+}
+
+// AddedAt returns when each hash was last detected being added to a source,
+// keyed by hash and then by channel name. Hashes listed since the first
+// refresh of the source, or longer than changes are kept, are left out.
+func (s *SourceChangeService) AddedAt(ctx context.Context, source string) (map[string]map[string]time.Time, error) {
+	changes, err := s.repo.FindBySource(ctx, source, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	added := make(map[string]map[string]time.Time)
+	for _, c := range changes {
+		if c.Kind() != sourcechange.KindAdded {
+			continue
+		}
+		if added[c.InfoHash()] == nil {
+			added[c.InfoHash()] = make(map[string]time.Time)
+		}
+		if t, ok := added[c.InfoHash()][c.ChannelName()]; !ok || c.DetectedAt().After(t) {
+			added[c.InfoHash()][c.ChannelName()] = c.DetectedAt()
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,142 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/sourcechange"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// memSourceChangeRepository is an in-memory driven.SourceChangeRepository for testing.
+type memSourceChangeRepository struct {
+	snapshots map[string]map[string][]string
+	changes   []sourcechange.Change
+}
+
+func (r *memSourceChangeRepository) FindSnapshot(ctx context.Context, source string) (map[string][]string, error) {
+	snapshot, ok := r.snapshots[source]
+	if !ok {
+		return nil, sourcechange.ErrSnapshotNotFound
+	}
+	return snapshot, nil
+}
+
+func (r *memSourceChangeRepository) Record(ctx context.Context, source string, snapshot map[string][]string, changes []sourcechange.Change) error {
+	r.snapshots[source] = snapshot
+	r.changes = append(r.changes, changes...)
+	return nil
+}
+
+func (r *memSourceChangeRepository) FindBySource(ctx context.Context, source string, limit int) ([]sourcechange.Change, error) {
+	var result []sourcechange.Change
+	for i := len(r.changes) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		if r.changes[i].Source() == source {
+			result = append(result, r.changes[i])
+		}
+	}
+	return result, nil
+}
+
+func (r *memSourceChangeRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+func TestSourceChangeService_Track(t *testing.T) {
+	ctx := context.Background()
+
+	la1a, _ := stream.NewStream("6131000000000000000000000000000000000000", "La 1", stream.SourceNewEra)
+	dazn, _ := stream.NewStream("6431000000000000000000000000000000000000", "DAZN 1", stream.SourceNewEra)
+	shared, _ := stream.NewStream("7331000000000000000000000000000000000000", "Shared", stream.SourceNewEra)
+	streams := []stream.Stream{la1a, dazn, shared}
+	streamRepo := &mockStreamRepository{
+		findByInfoHashFunc: func(ctx context.Context, infoHash string) (stream.Stream, error) {
+			for _, st := range streams {
+				if st.InfoHash() == infoHash {
+					return st, nil
+				}
+			}
+			return stream.Stream{}, stream.ErrStreamNotFound
+		},
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			var result []stream.Stream
+			for _, st := range streams {
+				if st.ChannelName() == channelName {
+					result = append(result, st)
+				}
+			}
+			return result, nil
+		},
+	}
+	la1, _ := channel.NewChannel("La 1")
+	daznCh, _ := channel.NewChannel("DAZN 1")
+	sharedCh, _ := channel.NewChannel("Shared")
+	channelRepo, channels := newMemChannelRepository(la1, daznCh, sharedCh)
+
+	repo := &memSourceChangeRepository{snapshots: make(map[string]map[string][]string)}
+	service := NewSourceChangeService(repo, streamRepo, channelRepo, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.SetAutoDisable(true)
+	bus := NewEventBus()
+	events, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+	service.SetEventBus(bus)
+
+	service.Track(ctx, []SourceResult{
+		{Source: stream.SourceNewEra, Hashes: map[string][]string{"la1.es": {"6131000000000000000000000000000000000000"}, "dazn.es": {"6431000000000000000000000000000000000000"}, "shared.es": {"7331000000000000000000000000000000000000"}}},
+		{Source: stream.SourceElcano, Hashes: map[string][]string{"Shared": {"7331000000000000000000000000000000000000"}}},
+	})
+	if len(repo.changes) != 0 {
+		t.Fatalf("expected the first refresh to be a baseline, got %+v", repo.changes)
+	}
+
+	// DAZN's only stream and the shared stream disappear from new-era, but
+	// the shared stream is still listed by elcano. A failed source is skipped.
+	service.Track(ctx, []SourceResult{
+		{Source: stream.SourceNewEra, Hashes: map[string][]string{"la1.es": {"6131000000000000000000000000000000000000", "a2"}}},
+		{Source: stream.SourceElcano, Hashes: map[string][]string{"Shared": {"7331000000000000000000000000000000000000"}}},
+		{Source: "other", Err: errors.New("unavailable")},
+	})
+
+	changes, err := service.Changes(ctx, stream.SourceNewEra, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", changes)
+	}
+	kinds := make(map[string]sourcechange.Kind)
+	for _, c := range changes {
+		kinds[c.InfoHash()] = c.Kind()
+	}
+	if kinds["a2"] != sourcechange.KindAdded || kinds["6431000000000000000000000000000000000000"] != sourcechange.KindRemoved || kinds["7331000000000000000000000000000000000000"] != sourcechange.KindRemoved {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+	if elcano, _ := service.Changes(ctx, stream.SourceElcano, 0); len(elcano) != 0 {
+		t.Errorf("expected no elcano changes, got %+v", elcano)
+	}
+
+	select {
+	case e := <-events:
+		data, ok := e.Data.(SourceChangeEventData)
+		if e.Type != EventSourceChanged || !ok || data.Source != stream.SourceNewEra || len(data.Added) != 1 || len(data.Removed) != 2 {
+			t.Errorf("unexpected event %+v", e)
+		}
+	default:
+		t.Error("expected a source changed event")
+	}
+	if len(events) != 0 {
+		t.Errorf("expected a single event, got %d more", len(events))
+	}
+
+	if channels["DAZN 1"].Status() != channel.StatusArchived {
+		t.Error("expected the channel whose only stream disappeared to be archived")
+	}
+	if channels["Shared"].Status() != channel.StatusActive || channels["La 1"].Status() != channel.StatusActive {
+		t.Error("expected channels with remaining streams to stay active")
+	}
+}
@@ -0,0 +1,196 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidConflictPolicy indicates an unknown source conflict policy.
+var ErrInvalidConflictPolicy = errors.New("invalid source conflict policy")
+
+// ConflictPolicy decides which channel keeps a hash that the Acestream
+// sources list under more than one channel name.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyPriority prefers the source with the highest priority.
+	ConflictPolicyPriority ConflictPolicy = "priority"
+	// ConflictPolicyNewest prefers the channel name the hash was most
+	// recently listed under, as recorded by the source change service.
+	// Names listed since before the first recorded refresh are the oldest.
+	ConflictPolicyNewest ConflictPolicy = "newest"
+)
+
+// Validate checks the policy is known.
+// Returns ErrInvalidConflictPolicy otherwise.
+func (p ConflictPolicy) Validate() error {
+	if p != ConflictPolicyPriority && p != ConflictPolicyNewest {
+		return ErrInvalidConflictPolicy
+	}
+	return nil
+}
+
+// ConflictCandidate is one channel name a source lists a conflicting hash under.
+type ConflictCandidate struct {
+	Source      string
+	ChannelName string
+	Priority    int
+	// ListedSince is when the source started listing the hash under the
+	// channel name; zero if unknown.
+	ListedSince time.Time
+}
+
+// SourceConflict is a hash listed under more than one channel name, and the
+// candidate the conflict policy chose. Candidates are ordered from the
+// preferred one, so Winner is always the first.
+type SourceConflict struct {
+	InfoHash   string
+	Candidates []ConflictCandidate
+	Winner     ConflictCandidate
+	Policy     ConflictPolicy
+}
+
+// SetSourcePriorities sets the priority of each Acestream source; sources
+// not listed have priority zero. Higher priority sources are merged first,
+// so they win hashes listed more than once under the same channel name, and
+// win conflicts under ConflictPolicyPriority.
+func (s *EPGSyncService) SetSourcePriorities(priorities map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priorities = priorities
+}
+
+// SetConflictPolicy sets how a hash listed under more than one channel name
+// is resolved. The default is ConflictPolicyPriority.
+// Returns ErrInvalidConflictPolicy if the policy is unknown.
+func (s *EPGSyncService) SetConflictPolicy(policy ConflictPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conflictPolicy = policy
+	return nil
+}
+
+// Conflicts returns the conflicts detected by the last sync that fetched
+// the Acestream sources, ordered by hash.
+func (s *EPGSyncService) Conflicts() []SourceConflict {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conflicts
+}
+
+// prioritizedSources returns sources ordered by priority, highest first,
+// keeping the given order between sources of equal priority.
+func (s *EPGSyncService) prioritizedSources(sources []string) []string {
+	s.mu.Lock()
+	priorities := s.priorities
+	s.mu.Unlock()
+
+	ordered := slices.Clone(sources)
+	slices.SortStableFunc(ordered, func(a, b string) int {
+		return cmp.Compare(priorities[b], priorities[a])
+	})
+	return ordered
+}
+
+// resolveConflicts finds the hashes that the sources list under more than
+// one channel name and keeps each only under the channel chosen by the
+// conflict policy, removing it from the others in merged. Ties are broken by
+// priority, then by the order of results, then by channel name, so the
+// outcome does not depend on map iteration order.
+func (s *EPGSyncService) resolveConflicts(ctx context.Context, merged map[string][]TaggedHash, results []SourceResult) []SourceConflict {
+	s.mu.Lock()
+	priorities, policy := s.priorities, s.conflictPolicy
+	s.mu.Unlock()
+
+	order := make(map[string]int, len(results))
+	candidates := make(map[string][]ConflictCandidate)
+	for i, r := range results {
+		order[r.Source] = i
+		for channelName, hashes := range r.Hashes {
+			for _, h := range hashes {
+				candidates[h] = append(candidates[h], ConflictCandidate{
+					Source:      r.Source,
+					ChannelName: channelName,
+					Priority:    priorities[r.Source],
+				})
+			}
+		}
+	}
+
+	var conflicts []SourceConflict
+	var listedSince map[string]map[string]map[string]time.Time
+	for h, cs := range candidates {
+		names := make(map[string]bool)
+		for _, c := range cs {
+			names[c.ChannelName] = true
+		}
+		if len(names) < 2 {
+			continue
+		}
+
+		if policy == ConflictPolicyNewest && s.changes != nil {
+			if listedSince == nil {
+				listedSince = s.listedSince(ctx, results)
+			}
+			for i := range cs {
+				cs[i].ListedSince = listedSince[cs[i].Source][h][cs[i].ChannelName]
+			}
+		}
+		slices.SortFunc(cs, func(a, b ConflictCandidate) int {
+			newest := 0
+			if policy == ConflictPolicyNewest {
+				newest = b.ListedSince.Compare(a.ListedSince)
+			}
+			return cmp.Or(
+				newest,
+				cmp.Compare(b.Priority, a.Priority),
+				cmp.Compare(order[a.Source], order[b.Source]),
+				strings.Compare(a.ChannelName, b.ChannelName),
+			)
+		})
+
+		winner := cs[0]
+		for name := range names {
+			if name != winner.ChannelName {
+				merged[name] = slices.DeleteFunc(merged[name], func(th TaggedHash) bool { return th.InfoHash == h })
+				if len(merged[name]) == 0 {
+					delete(merged, name)
+				}
+			}
+		}
+		for i, th := range merged[winner.ChannelName] {
+			if th.InfoHash == h {
+				merged[winner.ChannelName][i].Source = winner.Source
+			}
+		}
+		conflicts = append(conflicts, SourceConflict{InfoHash: h, Candidates: cs, Winner: winner, Policy: policy})
+	}
+
+	slices.SortFunc(conflicts, func(a, b SourceConflict) int { return strings.Compare(a.InfoHash, b.InfoHash) })
+	return conflicts
+}
+
+// listedSince returns, per source, hash and channel name, when the source
+// change service last detected the hash being added under the name.
+func (s *EPGSyncService) listedSince(ctx context.Context, results []SourceResult) map[string]map[string]map[string]time.Time {
+	listed := make(map[string]map[string]map[string]time.Time, len(results))
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		added, err := s.changes.AddedAt(ctx, r.Source)
+		if err != nil {
+			s.logger.Error("failed to load source changes", "source", r.Source, "error", err)
+			continue
+		}
+		listed[r.Source] = added
+	}
+	return listed
+}
@@ -0,0 +1,115 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/sourcechange"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+func TestEPGSyncService_ResolveConflicts(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	results := []SourceResult{
+		{Source: stream.SourceNewEra, Hashes: map[string][]string{"La 1": {"h1", "h2"}, "TVE 1": {"h1"}}},
+		{Source: stream.SourceElcano, Hashes: map[string][]string{"La 1 HD": {"h1"}, "Clan": {"h3"}}},
+	}
+	merge := func() map[string][]TaggedHash {
+		return mergeTaggedHashMaps(tagHashMap(results[0].Hashes, results[0].Source), tagHashMap(results[1].Hashes, results[1].Source))
+	}
+
+	t.Run("priority prefers the higher priority source", func(t *testing.T) {
+		s := NewEPGSyncService(nil, nil, nil, nil, nil, logger)
+		s.SetSourcePriorities(map[string]int{stream.SourceElcano: 1})
+		merged := merge()
+
+		conflicts := s.resolveConflicts(ctx, merged, results)
+
+		if len(conflicts) != 1 || conflicts[0].InfoHash != "h1" {
+			t.Fatalf("expected a conflict for h1, got %+v", conflicts)
+		}
+		c := conflicts[0]
+		if c.Winner.Source != stream.SourceElcano || c.Winner.ChannelName != "La 1 HD" || c.Policy != ConflictPolicyPriority {
+			t.Errorf("unexpected winner %+v", c.Winner)
+		}
+		if len(c.Candidates) != 3 || c.Candidates[0] != c.Winner {
+			t.Errorf("expected 3 candidates led by the winner, got %+v", c.Candidates)
+		}
+		if got := merged["La 1"]; len(got) != 1 || got[0].InfoHash != "h2" {
+			t.Errorf("expected h1 removed from La 1, got %+v", got)
+		}
+		if _, ok := merged["TVE 1"]; ok {
+			t.Error("expected TVE 1 without hashes to be dropped")
+		}
+		if got := merged["La 1 HD"]; len(got) != 1 || got[0] != (TaggedHash{InfoHash: "h1", Source: stream.SourceElcano}) {
+			t.Errorf("unexpected La 1 HD hashes %+v", got)
+		}
+	})
+
+	t.Run("equal priorities fall back to source order and name", func(t *testing.T) {
+		s := NewEPGSyncService(nil, nil, nil, nil, nil, logger)
+		merged := merge()
+
+		conflicts := s.resolveConflicts(ctx, merged, results)
+
+		if len(conflicts) != 1 || conflicts[0].Winner.ChannelName != "La 1" || conflicts[0].Winner.Source != stream.SourceNewEra {
+			t.Fatalf("expected La 1 from new-era to win, got %+v", conflicts)
+		}
+		if got := merged["La 1"]; len(got) != 2 {
+			t.Errorf("expected La 1 to keep both hashes, got %+v", got)
+		}
+	})
+
+	t.Run("newest prefers the most recently listed name", func(t *testing.T) {
+		now := time.Now()
+		repo := &memSourceChangeRepository{
+			snapshots: map[string]map[string][]string{},
+			changes: []sourcechange.Change{
+				sourcechange.ReconstructChange(stream.SourceNewEra, "TVE 1", "h1", sourcechange.KindAdded, now.Add(-time.Hour)),
+				sourcechange.ReconstructChange(stream.SourceElcano, "La 1 HD", "h1", sourcechange.KindAdded, now.Add(-2*time.Hour)),
+			},
+		}
+		s := NewEPGSyncService(nil, nil, nil, nil, nil, logger)
+		s.SetSourceChangeService(NewSourceChangeService(repo, nil, nil, logger))
+		s.SetSourcePriorities(map[string]int{stream.SourceElcano: 1})
+		if err := s.SetConflictPolicy(ConflictPolicyNewest); err != nil {
+			t.Fatalf("SetConflictPolicy() error = %v", err)
+		}
+		merged := merge()
+
+		conflicts := s.resolveConflicts(ctx, merged, results)
+
+		if len(conflicts) != 1 || conflicts[0].Winner.ChannelName != "TVE 1" {
+			t.Fatalf("expected TVE 1 to win, got %+v", conflicts)
+		}
+		if !conflicts[0].Winner.ListedSince.Equal(now.Add(-time.Hour)) {
+			t.Errorf("unexpected listed since %v", conflicts[0].Winner.ListedSince)
+		}
+		if got := conflicts[0].Candidates[2]; got.ChannelName != "La 1" || !got.ListedSince.IsZero() {
+			t.Errorf("expected the unrecorded name last, got %+v", got)
+		}
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		s := NewEPGSyncService(nil, nil, nil, nil, nil, logger)
+		if err := s.SetConflictPolicy("oldest"); !errors.Is(err, ErrInvalidConflictPolicy) {
+			t.Errorf("SetConflictPolicy() error = %v, want %v", err, ErrInvalidConflictPolicy)
+		}
+	})
+}
+
+func TestEPGSyncService_PrioritizedSources(t *testing.T) {
+	s := NewEPGSyncService(nil, nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.SetSourcePriorities(map[string]int{stream.SourceElcano: 2})
+
+	got := s.prioritizedSources([]string{stream.SourceNewEra, stream.SourceElcano})
+
+	if len(got) != 2 || got[0] != stream.SourceElcano || got[1] != stream.SourceNewEra {
+		t.Errorf("prioritizedSources() = %v, want elcano first", got)
+	}
+}
@@ -0,0 +1,135 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/alorle/iptv-manager/internal/playlist"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// SourceResult reports the outcome of fetching a single Acestream source.
+type SourceResult struct {
+	Source     string
+	EntryCount int
+	// Hashes maps channel names to the hashes fetched; nil if Err is set.
+	Hashes map[string][]string
+	// Names maps channel names to the display names the source lists them
+	// under, for sources that report them; nil otherwise.
+	Names map[string]string
+	Err   error
+}
+
+// TaggedHash is an Acestream hash and the source that listed it.
+type TaggedHash struct {
+	InfoHash string
+	Source   string
+}
+
+// FetchAndMerge fetches all sources concurrently and merges them into a
+// single playlist with one entry per hash, tagged with the source it came
+// from. An entry's TVGID is the channel name the sources list it under, and
+// its ChannelName the display name the sources give that channel, or the
+// channel name if they give none. Entries are sorted by ChannelName, and
+// sources are merged in the given order, so earlier sources win when the
+// same hash appears more than once and name channels first. Per-source
+// outcomes are returned in the same order as sources, with display names for
+// the sources implementing driven.AcestreamNamedSource. An error is returned
+// only if every source fails; a partial failure still yields the hashes of
+// the sources that succeeded.
+func FetchAndMerge(ctx context.Context, src driven.AcestreamSource, sources []string) (*playlist.Playlist, []SourceResult, error) {
+	fetched := make([]map[string][]string, len(sources))
+	results := make([]SourceResult, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var hashes map[string][]string
+			var names map[string]string
+			var err error
+			if named, ok := src.(driven.AcestreamNamedSource); ok {
+				hashes, names, err = named.FetchNamedHashes(ctx, source)
+			} else {
+				hashes, err = src.FetchHashes(ctx, source)
+			}
+			results[i] = SourceResult{Source: source, Err: err}
+			if err == nil {
+				fetched[i] = hashes
+				results[i].EntryCount = len(hashes)
+				results[i].Hashes = hashes
+				results[i].Names = names
+			}
+		}()
+	}
+	wg.Wait()
+
+	tagged := make([]map[string][]TaggedHash, 0, len(sources))
+	var errs []error
+	for i, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch %s hashes: %w", r.Source, r.Err))
+			continue
+		}
+		tagged = append(tagged, tagHashMap(fetched[i], r.Source))
+	}
+
+	if len(sources) > 0 && len(errs) == len(sources) {
+		return nil, results, errors.Join(errs...)
+	}
+
+	return sourcePlaylist(mergeTaggedHashMaps(tagged...), sourceNames(results)), results, nil
+}
+
+// sourcePlaylist lists merged hashes as playlist entries, as described by
+// FetchAndMerge. The hashes of a channel keep their merged order.
+func sourcePlaylist(merged map[string][]TaggedHash, names map[string]string) *playlist.Playlist {
+	pl := &playlist.Playlist{}
+	for key, hashes := range merged {
+		name := names[key]
+		if name == "" {
+			name = key
+		}
+		for _, th := range hashes {
+			pl.Entries = append(pl.Entries, playlist.Entry{
+				ChannelName: name,
+				TVGID:       key,
+				InfoHash:    th.InfoHash,
+				Source:      th.Source,
+			})
+		}
+	}
+	slices.SortStableFunc(pl.Entries, func(a, b playlist.Entry) int {
+		return cmp.Or(cmp.Compare(a.ChannelName, b.ChannelName), cmp.Compare(a.TVGID, b.TVGID))
+	})
+	return pl
+}
+
+// sourceHashes groups the entries of a playlist returned by FetchAndMerge
+// by the channel name the sources list them under.
+func sourceHashes(pl *playlist.Playlist) map[string][]TaggedHash {
+	hashes := make(map[string][]TaggedHash)
+	for _, e := range pl.Entries {
+		hashes[e.TVGID] = append(hashes[e.TVGID], TaggedHash{InfoHash: e.InfoHash, Source: e.Source})
+	}
+	return hashes
+}
+
+// sourceNames merges the display names reported by the sources fetched, so
+// earlier sources win when several name the same channel.
+func sourceNames(results []SourceResult) map[string]string {
+	names := make(map[string]string)
+	for _, r := range results {
+		for key, name := range r.Names {
+			if _, ok := names[key]; !ok {
+				names[key] = name
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,212 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/epg"
+	"github.com/alorle/iptv-manager/internal/playlist"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// funcAcestreamSource adapts a function to the AcestreamSource port.
+type funcAcestreamSource func(ctx context.Context, source string) (map[string][]string, error)
+
+func (f funcAcestreamSource) FetchHashes(ctx context.Context, source string) (map[string][]string, error) {
+	return f(ctx, source)
+}
+
+// namedAcestreamSource is an AcestreamNamedSource listing fixed hashes and
+// display names per source.
+type namedAcestreamSource struct {
+	hashes map[string]map[string][]string
+	names  map[string]map[string]string
+}
+
+func (s namedAcestreamSource) FetchHashes(ctx context.Context, source string) (map[string][]string, error) {
+	return s.hashes[source], nil
+}
+
+func (s namedAcestreamSource) FetchNamedHashes(ctx context.Context, source string) (map[string][]string, map[string]string, error) {
+	return s.hashes[source], s.names[source], nil
+}
+
+func TestFetchAndMerge(t *testing.T) {
+	sources := []string{stream.SourceNewEra, stream.SourceElcano}
+
+	t.Run("all sources succeed", func(t *testing.T) {
+		src := funcAcestreamSource(func(ctx context.Context, source string) (map[string][]string, error) {
+			if source == stream.SourceNewEra {
+				return map[string][]string{"Channel1": {"hash1", "shared"}}, nil
+			}
+			return map[string][]string{"Channel1": {"shared", "hash2"}, "Channel2": {"hash3"}}, nil
+		})
+
+		pl, results, err := FetchAndMerge(context.Background(), src, sources)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		merged := sourceHashes(pl)
+
+		if len(results) != 2 || results[0].Source != stream.SourceNewEra || results[1].Source != stream.SourceElcano {
+			t.Fatalf("expected results in source order, got %+v", results)
+		}
+		for _, r := range results {
+			if r.Err != nil {
+				t.Errorf("%s: unexpected error %v", r.Source, r.Err)
+			}
+		}
+		if results[0].EntryCount != 1 || results[1].EntryCount != 2 {
+			t.Errorf("unexpected entry counts: %+v", results)
+		}
+
+		want := []TaggedHash{
+			{InfoHash: "hash1", Source: stream.SourceNewEra},
+			{InfoHash: "shared", Source: stream.SourceNewEra},
+			{InfoHash: "hash2", Source: stream.SourceElcano},
+		}
+		got := merged["Channel1"]
+		if len(got) != len(want) {
+			t.Fatalf("Channel1: expected %d hashes, got %d (%+v)", len(want), len(got), got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("Channel1[%d] = %+v, want %+v", i, got[i], want[i])
+			}
+		}
+		if len(merged["Channel2"]) != 1 || merged["Channel2"][0].Source != stream.SourceElcano {
+			t.Errorf("Channel2: unexpected hashes %+v", merged["Channel2"])
+		}
+	})
+
+	t.Run("partial failure returns hashes from healthy sources", func(t *testing.T) {
+		src := funcAcestreamSource(func(ctx context.Context, source string) (map[string][]string, error) {
+			if source == stream.SourceNewEra {
+				return nil, errors.New("ipfs gateway timeout")
+			}
+			return map[string][]string{"Channel1": {"hash2"}}, nil
+		})
+
+		pl, results, err := FetchAndMerge(context.Background(), src, sources)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		merged := sourceHashes(pl)
+		if results[0].Err == nil {
+			t.Error("expected new-era result to carry its error")
+		}
+		if results[1].Err != nil {
+			t.Errorf("expected elcano to succeed, got %v", results[1].Err)
+		}
+		if len(merged["Channel1"]) != 1 || merged["Channel1"][0].Source != stream.SourceElcano {
+			t.Errorf("unexpected merged hashes %+v", merged)
+		}
+	})
+
+	t.Run("all sources failing returns an error", func(t *testing.T) {
+		sourceErr := errors.New("network down")
+		src := funcAcestreamSource(func(ctx context.Context, source string) (map[string][]string, error) {
+			return nil, sourceErr
+		})
+
+		pl, results, err := FetchAndMerge(context.Background(), src, sources)
+		if !errors.Is(err, sourceErr) {
+			t.Fatalf("expected wrapped source error, got %v", err)
+		}
+		if pl != nil {
+			t.Errorf("expected nil merge result, got %+v", pl)
+		}
+		if len(results) != 2 {
+			t.Errorf("expected per-source results even on failure, got %d", len(results))
+		}
+	})
+
+	t.Run("sources are fetched concurrently", func(t *testing.T) {
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		src := funcAcestreamSource(func(ctx context.Context, source string) (map[string][]string, error) {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+
+			time.Sleep(50 * time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return map[string][]string{}, nil
+		})
+
+		if _, _, err := FetchAndMerge(context.Background(), src, sources); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if maxInFlight != 2 {
+			t.Errorf("expected both sources in flight at once, max was %d", maxInFlight)
+		}
+	})
+}
+
+func TestFetchAndMerge_DisplayNames(t *testing.T) {
+	src := namedAcestreamSource{
+		hashes: map[string]map[string][]string{
+			stream.SourceNewEra: {"La1.es": {"hash1"}},
+			stream.SourceElcano: {"La1.es": {"hash2"}, "Cuatro.es": {"hash3"}},
+		},
+		names: map[string]map[string]string{
+			stream.SourceNewEra: {"La1.es": "La 1 HD"},
+			stream.SourceElcano: {"La1.es": "La 1", "Cuatro.es": "Cuatro"},
+		},
+	}
+
+	pl, results, err := FetchAndMerge(context.Background(), src, []string{stream.SourceNewEra, stream.SourceElcano})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	merged := sourceHashes(pl)
+
+	// Entries sort and render under their display names
+	wantEntries := []playlist.Entry{
+		{ChannelName: "Cuatro", TVGID: "Cuatro.es", InfoHash: "hash3", Source: stream.SourceElcano},
+		{ChannelName: "La 1 HD", TVGID: "La1.es", InfoHash: "hash1", Source: stream.SourceNewEra},
+		{ChannelName: "La 1 HD", TVGID: "La1.es", InfoHash: "hash2", Source: stream.SourceElcano},
+	}
+	if !slices.Equal(pl.Entries, wantEntries) {
+		t.Errorf("expected entries %+v, got %+v", wantEntries, pl.Entries)
+	}
+	var buf bytes.Buffer
+	if err := playlist.ExtendedM3U.Encode(&buf, *pl); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `tvg-id="La1.es" tvg-chno="0" tvg-name="La 1 HD"`) {
+		t.Errorf("expected La1.es to render as La 1 HD, got %q", buf.String())
+	}
+
+	if results[0].Names["La1.es"] != "La 1 HD" || results[1].Names["Cuatro.es"] != "Cuatro" {
+		t.Fatalf("expected the display names of each source, got %+v", results)
+	}
+
+	names := sourceNames(results)
+	if names["La1.es"] != "La 1 HD" || names["Cuatro.es"] != "Cuatro" {
+		t.Errorf("expected earlier sources to name channels first, got %v", names)
+	}
+
+	// The channel is found by its display name, since its key is unlike
+	// the EPG channel's name and ID
+	la1, _ := epg.NewChannel("la1.epg", "La 1", "", "", "es", "la1.epg")
+	service := NewEPGSyncService(nil, src, nil, nil, nil, slog.Default())
+	hashes, score := service.matchChannelWithHashes(la1, merged, names)
+	if score < fuzzyMatchThreshold || len(hashes) != 2 {
+		t.Errorf("expected La 1 to match by display name, got %+v (score %.2f)", hashes, score)
+	}
+	if _, score := service.matchChannelWithHashes(la1, merged, nil); score >= fuzzyMatchThreshold {
+		t.Errorf("expected no match without display names, got score %.2f", score)
+	}
+}
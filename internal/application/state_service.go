@@ -0,0 +1,606 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/rule"
+	"github.com/alorle/iptv-manager/internal/state"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// StateChangeSet lists what applying a state document changes of one kind:
+// group IDs, channel names, stream infohashes or override rule IDs.
+type StateChangeSet struct {
+	Created []string
+	Updated []string
+	Deleted []string
+}
+
+// StateChanges reports what applying a state document changed, or would
+// change in a dry run.
+type StateChanges struct {
+	Groups    StateChangeSet
+	Channels  StateChangeSet
+	Streams   StateChangeSet
+	Overrides StateChangeSet
+	DryRun    bool
+}
+
+// Empty reports whether nothing changed.
+func (c StateChanges) Empty() bool {
+	for _, set := range []StateChangeSet{c.Groups, c.Channels, c.Streams, c.Overrides} {
+		if len(set.Created)+len(set.Updated)+len(set.Deleted) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// StateService exports the channel lineup as a declarative document and
+// applies documents to it, changing whatever differs.
+type StateService struct {
+	channelRepo driven.ChannelRepository
+	streamRepo  driven.StreamRepository
+	groupRepo   driven.GroupRepository
+	ruleRepo    driven.RuleRepository
+	events      *EventBus
+	// mu serializes applies, so each one diffs against the state the
+	// previous one left
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewStateService creates a new StateService over the lineup's repositories.
+func NewStateService(channelRepo driven.ChannelRepository, streamRepo driven.StreamRepository, groupRepo driven.GroupRepository, ruleRepo driven.RuleRepository) *StateService {
+	return &StateService{
+		channelRepo: channelRepo,
+		streamRepo:  streamRepo,
+		groupRepo:   groupRepo,
+		ruleRepo:    ruleRepo,
+		now:         time.Now,
+	}
+}
+
+// SetEventBus enables publishing EventOverrideUpdated when a document
+// changes the lineup.
+func (s *StateService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// Export returns the current lineup as a document: groups in order,
+// channels by name with their streams, and override rules in the order they
+// apply. Streams of channels that do not exist are left out.
+func (s *StateService) Export(ctx context.Context) (state.Document, error) {
+	current, err := s.load(ctx)
+	if err != nil {
+		return state.Document{}, err
+	}
+
+	doc := state.Document{
+		Version:   state.Version,
+		Groups:    make([]state.Group, len(current.groups)),
+		Channels:  make([]state.Channel, 0, len(current.channels)),
+		Overrides: make([]state.Override, len(current.rules)),
+	}
+	for i, g := range current.groups {
+		doc.Groups[i] = state.Group{ID: g.ID(), Name: g.Name(), Disabled: !g.IsEnabled()}
+	}
+	for _, name := range slices.Sorted(maps.Keys(current.channels)) {
+		doc.Channels = append(doc.Channels, channelDocument(current.channels[name], current.streamsOf(name)))
+	}
+	for i, r := range current.rules {
+		m, a := r.Match(), r.Action()
+		doc.Overrides[i] = state.Override{
+			Match:  state.OverrideMatch{NamePattern: m.NamePattern, Group: m.Group, Source: m.Source},
+			Action: state.OverrideAction{Rename: a.Rename, Group: a.Group, TVGID: a.TVGID, Disable: a.Disable},
+		}
+	}
+	return doc, nil
+}
+
+// Apply makes the lineup match doc: groups, channels, streams and override
+// rules it lists are created or updated, and the ones it does not are
+// deleted. Nothing is changed unless the whole document is valid, and if a
+// change fails the ones already made are reverted. With dryRun nothing is
+// changed and the changes are only reported.
+// Returns state.ErrInvalidDocument, wrapping the cause, if doc describes an
+// impossible lineup, such as a channel in a group it does not list.
+func (s *StateService) Apply(ctx context.Context, doc state.Document, dryRun bool) (StateChanges, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	desired, err := s.desiredState(doc)
+	if err != nil {
+		return StateChanges{}, fmt.Errorf("%w: %w", state.ErrInvalidDocument, err)
+	}
+	current, err := s.load(ctx)
+	if err != nil {
+		return StateChanges{}, err
+	}
+
+	changes, ops := s.plan(current, desired)
+	changes.DryRun = dryRun
+	if dryRun || changes.Empty() {
+		return changes, nil
+	}
+
+	for i, op := range ops {
+		if err := op.apply(ctx); err != nil {
+			// Revert in reverse order what was applied
+			var undoErrs []error
+			for j := i - 1; j >= 0; j-- {
+				if undoErr := ops[j].undo(ctx); undoErr != nil {
+					undoErrs = append(undoErrs, undoErr)
+				}
+			}
+			if len(undoErrs) > 0 {
+				return StateChanges{}, fmt.Errorf("failed to apply state: %w (reverting failed: %w)", err, errors.Join(undoErrs...))
+			}
+			return StateChanges{}, fmt.Errorf("failed to apply state: %w", err)
+		}
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "state"})
+	return changes, nil
+}
+
+// lineup is the lineup held by the repositories, or described by a
+// document.
+type lineup struct {
+	groups   []group.Group // In order
+	channels map[string]channel.Channel
+	streams  map[string]stream.Stream // By infohash
+	rules    []rule.Rule              // In the order they apply
+}
+
+// streamsOf returns the streams of the named channel, by infohash.
+func (l lineup) streamsOf(channelName string) []stream.Stream {
+	var streams []stream.Stream
+	for _, st := range l.streams {
+		if st.ChannelName() == channelName {
+			streams = append(streams, st)
+		}
+	}
+	slices.SortFunc(streams, func(a, b stream.Stream) int {
+		return cmp.Compare(a.InfoHash(), b.InfoHash())
+	})
+	return streams
+}
+
+// load reads the current lineup.
+func (s *StateService) load(ctx context.Context) (lineup, error) {
+	groups, err := s.groupRepo.FindAll(ctx)
+	if err != nil {
+		return lineup{}, fmt.Errorf("failed to list groups: %w", err)
+	}
+	group.Sort(groups)
+
+	channels, err := s.channelRepo.FindAll(ctx)
+	if err != nil {
+		return lineup{}, fmt.Errorf("failed to list channels: %w", err)
+	}
+	streams, err := s.streamRepo.FindAll(ctx)
+	if err != nil {
+		return lineup{}, fmt.Errorf("failed to list streams: %w", err)
+	}
+	rules, err := s.ruleRepo.FindAll(ctx)
+	if err != nil {
+		return lineup{}, fmt.Errorf("failed to list override rules: %w", err)
+	}
+
+	l := lineup{
+		groups:   groups,
+		channels: make(map[string]channel.Channel, len(channels)),
+		streams:  make(map[string]stream.Stream, len(streams)),
+		rules:    rules,
+	}
+	for _, ch := range channels {
+		l.channels[ch.Name()] = ch
+	}
+	for _, st := range streams {
+		l.streams[st.InfoHash()] = st
+	}
+	return l, nil
+}
+
+// desiredState builds and validates the lineup doc describes. Streams
+// without a source get stream.SourceUnknown, to be filled in by plan.
+func (s *StateService) desiredState(doc state.Document) (lineup, error) {
+	now := s.now()
+	l := lineup{
+		channels: make(map[string]channel.Channel, len(doc.Channels)),
+		streams:  make(map[string]stream.Stream),
+	}
+
+	groupIDs := make(map[string]bool, len(doc.Groups))
+	for i, d := range doc.Groups {
+		g, err := group.NewGroup(d.Name)
+		if err != nil {
+			return lineup{}, fmt.Errorf("groups[%d]: %w", i, err)
+		}
+		if d.ID != "" && d.ID != g.ID() {
+			if group.Slug(d.ID) != d.ID {
+				return lineup{}, fmt.Errorf("groups[%d]: id %q must be lowercase letters, digits and single dashes", i, d.ID)
+			}
+			g = group.ReconstructGroup(d.ID, g.Name(), 0, true)
+		}
+		if groupIDs[g.ID()] {
+			return lineup{}, fmt.Errorf("groups[%d]: %w: %s", i, group.ErrGroupAlreadyExists, g.ID())
+		}
+		groupIDs[g.ID()] = true
+		g.SetPosition(i)
+		g.SetEnabled(!d.Disabled)
+		l.groups = append(l.groups, g)
+	}
+
+	aliases := make(map[string]string)
+	for i, d := range doc.Channels {
+		ch, err := documentChannel(d, now)
+		if err != nil {
+			return lineup{}, fmt.Errorf("channels[%d]: %w", i, err)
+		}
+		if _, ok := l.channels[ch.Name()]; ok {
+			return lineup{}, fmt.Errorf("channels[%d]: %w: %s", i, channel.ErrChannelAlreadyExists, ch.Name())
+		}
+		if ch.Group() != "" && !groupIDs[ch.Group()] {
+			return lineup{}, fmt.Errorf("channels[%d]: %w: %s", i, group.ErrGroupNotFound, ch.Group())
+		}
+		for _, alias := range ch.Aliases() {
+			if other, ok := aliases[alias]; ok {
+				return lineup{}, fmt.Errorf("channels[%d]: %w: %q is used by %s", i, channel.ErrAliasInUse, alias, other)
+			}
+			aliases[alias] = ch.Name()
+		}
+
+		for j, ds := range d.Streams {
+			st, err := stream.NewStream(ds.InfoHash, ch.Name(), ds.Source)
+			if err != nil {
+				return lineup{}, fmt.Errorf("channels[%d].streams[%d]: %w", i, j, err)
+			}
+			if other, ok := l.streams[st.InfoHash()]; ok {
+				return lineup{}, fmt.Errorf("channels[%d].streams[%d]: %w: listed under %s", i, j, stream.ErrStreamAlreadyExists, other.ChannelName())
+			}
+			if ds.Quality != "" {
+				q, err := channel.ParseQuality(ds.Quality)
+				if err != nil {
+					return lineup{}, fmt.Errorf("channels[%d].streams[%d]: %w", i, j, err)
+				}
+				ch.SetStreamQuality(st.InfoHash(), q)
+			}
+			l.streams[st.InfoHash()] = st
+		}
+		l.channels[ch.Name()] = ch
+	}
+
+	for i, d := range doc.Overrides {
+		match := rule.Match{NamePattern: d.Match.NamePattern, Group: d.Match.Group, Source: d.Match.Source}
+		action := rule.Action{Rename: d.Action.Rename, Group: d.Action.Group, TVGID: d.Action.TVGID, Disable: d.Action.Disable}
+		r, err := rule.NewRule(match, action, now)
+		if err != nil {
+			return lineup{}, fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+		l.rules = append(l.rules, r)
+	}
+	return l, nil
+}
+
+// documentChannel builds the channel a document describes, without its
+// stream qualities. A channel with an EPG ID gets a manual mapping to it.
+func documentChannel(d state.Channel, now time.Time) (channel.Channel, error) {
+	ch, err := channel.NewChannel(d.Name)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	ch.SetGroup(d.Group)
+	if err := ch.SetNumber(d.Number); err != nil {
+		return channel.Channel{}, err
+	}
+	if err := ch.SetTVGShift(time.Duration(d.TVGShift * float64(time.Hour))); err != nil {
+		return channel.Channel{}, err
+	}
+	if err := ch.SetAliases(d.Aliases); err != nil {
+		return channel.Channel{}, err
+	}
+	if err := ch.SetTags(d.Tags); err != nil {
+		return channel.Channel{}, err
+	}
+	if d.EPGID != "" {
+		mapping, err := channel.NewEPGMapping(d.EPGID, channel.MappingManual, now)
+		if err != nil {
+			return channel.Channel{}, err
+		}
+		ch.SetEPGMapping(mapping)
+	}
+
+	transcode, err := channel.ParseAudioTranscode(d.TranscodeAudio)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	ch.SetAudioTranscode(transcode)
+	video, err := channel.ParseVideoTranscode(d.TranscodeVideo)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	ch.SetVideoTranscode(video)
+	variants, err := channel.ParseVariants(d.Variants)
+	if err != nil {
+		return channel.Channel{}, err
+	}
+	ch.SetVariants(variants)
+
+	preference := make([]channel.Quality, len(d.QualityPreference))
+	for i, label := range d.QualityPreference {
+		if preference[i], err = channel.ParseQuality(label); err != nil {
+			return channel.Channel{}, err
+		}
+	}
+	ch.SetQualityPreference(preference)
+
+	if d.Archived {
+		ch.Archive()
+	}
+	return ch, nil
+}
+
+// channelDocument describes a channel and its streams in a document.
+func channelDocument(ch channel.Channel, streams []stream.Stream) state.Channel {
+	d := state.Channel{
+		Name:           ch.Name(),
+		Group:          ch.Group(),
+		Number:         ch.Number(),
+		Aliases:        ch.Aliases(),
+		Tags:           ch.Tags(),
+		TranscodeAudio: string(ch.AudioTranscode()),
+		TranscodeVideo: string(ch.VideoTranscode()),
+		Variants:       string(ch.Variants()),
+		Archived:       ch.Status() == channel.StatusArchived,
+	}
+	if m := ch.EPGMapping(); m != nil {
+		d.EPGID = m.EPGID()
+	}
+	d.TVGShift = ch.TVGShift().Hours()
+	for _, q := range ch.QualityPreference() {
+		d.QualityPreference = append(d.QualityPreference, string(q))
+	}
+	for _, st := range streams {
+		d.Streams = append(d.Streams, state.Stream{
+			InfoHash: st.InfoHash(),
+			Source:   st.Source(),
+			Quality:  string(ch.StreamQuality(st.InfoHash())),
+		})
+	}
+	return d
+}
+
+// sameChannel reports whether two channels have the same settings. EPG
+// mappings are compared by EPG ID only.
+func sameChannel(a, b channel.Channel) bool {
+	epgID := func(ch channel.Channel) string {
+		if m := ch.EPGMapping(); m != nil {
+			return m.EPGID()
+		}
+		return ""
+	}
+	return a.Name() == b.Name() &&
+		a.Status() == b.Status() &&
+		epgID(a) == epgID(b) &&
+		a.AudioTranscode() == b.AudioTranscode() &&
+		a.VideoTranscode() == b.VideoTranscode() &&
+		a.Group() == b.Group() &&
+		a.Number() == b.Number() &&
+		a.TVGShift() == b.TVGShift() &&
+		slices.Equal(a.Aliases(), b.Aliases()) &&
+		slices.Equal(a.Tags(), b.Tags()) &&
+		maps.Equal(a.StreamQualities(), b.StreamQualities()) &&
+		slices.Equal(a.QualityPreference(), b.QualityPreference()) &&
+		a.Variants() == b.Variants()
+}
+
+// stateOp is a single change to the lineup and how to revert it.
+type stateOp struct {
+	apply func(ctx context.Context) error
+	undo  func(ctx context.Context) error
+}
+
+// plan lists the changes that turn current into desired, in an order that
+// keeps the lineup consistent throughout: groups are created before the
+// channels in them and deleted after, and channels before their streams.
+func (s *StateService) plan(current, desired lineup) (StateChanges, []stateOp) {
+	var changes StateChanges
+	var ops, late []stateOp
+
+	currentGroups := make(map[string]group.Group, len(current.groups))
+	for _, g := range current.groups {
+		currentGroups[g.ID()] = g
+	}
+	desiredGroups := make(map[string]bool, len(desired.groups))
+	for _, g := range desired.groups {
+		desiredGroups[g.ID()] = true
+		old, ok := currentGroups[g.ID()]
+		switch {
+		case !ok:
+			changes.Groups.Created = append(changes.Groups.Created, g.ID())
+			ops = append(ops, stateOp{
+				apply: func(ctx context.Context) error { return s.groupRepo.Save(ctx, g) },
+				undo:  func(ctx context.Context) error { return s.groupRepo.Delete(ctx, g.ID()) },
+			})
+		case old != g:
+			changes.Groups.Updated = append(changes.Groups.Updated, g.ID())
+			ops = append(ops, stateOp{
+				apply: func(ctx context.Context) error { return s.groupRepo.Update(ctx, g) },
+				undo:  func(ctx context.Context) error { return s.groupRepo.Update(ctx, old) },
+			})
+		}
+	}
+	for _, g := range current.groups {
+		if !desiredGroups[g.ID()] {
+			changes.Groups.Deleted = append(changes.Groups.Deleted, g.ID())
+			late = append(late, stateOp{
+				apply: func(ctx context.Context) error { return s.groupRepo.Delete(ctx, g.ID()) },
+				undo:  func(ctx context.Context) error { return s.groupRepo.Save(ctx, g) },
+			})
+		}
+	}
+
+	// Channels that are gone take their streams with them
+	for _, name := range slices.Sorted(maps.Keys(current.channels)) {
+		if _, ok := desired.channels[name]; ok {
+			continue
+		}
+		old, streams := current.channels[name], current.streamsOf(name)
+		changes.Channels.Deleted = append(changes.Channels.Deleted, name)
+		for _, st := range streams {
+			if _, ok := desired.streams[st.InfoHash()]; !ok {
+				changes.Streams.Deleted = append(changes.Streams.Deleted, st.InfoHash())
+			}
+		}
+		ops = append(ops, stateOp{
+			apply: func(ctx context.Context) error {
+				if err := s.streamRepo.DeleteByChannelName(ctx, name); err != nil {
+					return err
+				}
+				return s.channelRepo.Delete(ctx, name)
+			},
+			undo: func(ctx context.Context) error {
+				if err := s.channelRepo.Save(ctx, old); err != nil {
+					return err
+				}
+				return s.saveStreams(ctx, streams)
+			},
+		})
+	}
+
+	// Streams that are gone, or move to another channel or source, are
+	// deleted here and saved again once their channel exists
+	var saves []stream.Stream
+	for _, hash := range slices.Sorted(maps.Keys(current.streams)) {
+		old := current.streams[hash]
+		st, ok := desired.streams[hash]
+		if ok && st.Source() == stream.SourceUnknown {
+			st = stream.ReconstructStream(hash, st.ChannelName(), old.Source())
+			desired.streams[hash] = st
+		}
+		if ok && st == old {
+			continue
+		}
+		if ok {
+			changes.Streams.Updated = append(changes.Streams.Updated, hash)
+			saves = append(saves, st)
+		}
+
+		_, had := current.channels[old.ChannelName()]
+		if _, keeps := desired.channels[old.ChannelName()]; had && !keeps {
+			// Deleted with its channel
+			continue
+		}
+		if !ok {
+			changes.Streams.Deleted = append(changes.Streams.Deleted, hash)
+		}
+		ops = append(ops, stateOp{
+			apply: func(ctx context.Context) error { return s.streamRepo.Delete(ctx, hash) },
+			undo:  func(ctx context.Context) error { return s.streamRepo.Save(ctx, old) },
+		})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(desired.channels)) {
+		ch := desired.channels[name]
+		old, ok := current.channels[name]
+		if ok {
+			// An unchanged EPG mapping keeps how and when it was made
+			if m := old.EPGMapping(); m != nil && ch.EPGMapping() != nil && m.EPGID() == ch.EPGMapping().EPGID() {
+				ch.SetEPGMapping(*m)
+			}
+			if sameChannel(old, ch) {
+				continue
+			}
+			changes.Channels.Updated = append(changes.Channels.Updated, name)
+			ops = append(ops, stateOp{
+				apply: func(ctx context.Context) error { return s.channelRepo.Update(ctx, ch) },
+				undo:  func(ctx context.Context) error { return s.channelRepo.Update(ctx, old) },
+			})
+			continue
+		}
+		changes.Channels.Created = append(changes.Channels.Created, name)
+		ops = append(ops, stateOp{
+			apply: func(ctx context.Context) error { return s.channelRepo.Save(ctx, ch) },
+			undo:  func(ctx context.Context) error { return s.channelRepo.Delete(ctx, name) },
+		})
+	}
+
+	for _, hash := range slices.Sorted(maps.Keys(desired.streams)) {
+		st := desired.streams[hash]
+		if _, ok := current.streams[hash]; !ok {
+			if st.Source() == stream.SourceUnknown {
+				st = stream.ReconstructStream(hash, st.ChannelName(), stream.SourceManual)
+			}
+			changes.Streams.Created = append(changes.Streams.Created, hash)
+			saves = append(saves, st)
+		}
+	}
+	for _, st := range saves {
+		ops = append(ops, stateOp{
+			apply: func(ctx context.Context) error { return s.streamRepo.Save(ctx, st) },
+			undo:  func(ctx context.Context) error { return s.streamRepo.Delete(ctx, st.InfoHash()) },
+		})
+	}
+
+	ops = append(ops, late...)
+	ops = append(ops, s.planRules(&changes, current.rules, desired.rules)...)
+	return changes, ops
+}
+
+// planRules lists the changes that turn the current rules into the desired
+// ones. Rules apply in creation order, so the rules after the first that
+// differs are all replaced.
+func (s *StateService) planRules(changes *StateChanges, current, desired []rule.Rule) []stateOp {
+	kept := 0
+	for kept < len(current) && kept < len(desired) &&
+		current[kept].Match() == desired[kept].Match() && current[kept].Action() == desired[kept].Action() {
+		kept++
+	}
+
+	var ops []stateOp
+	for _, r := range current[kept:] {
+		changes.Overrides.Deleted = append(changes.Overrides.Deleted, r.ID())
+		ops = append(ops, stateOp{
+			apply: func(ctx context.Context) error { return s.ruleRepo.Delete(ctx, r.ID()) },
+			undo:  func(ctx context.Context) error { return s.ruleRepo.Save(ctx, r) },
+		})
+	}
+
+	// New rules are created a nanosecond apart, after the ones kept
+	createdAt := s.now()
+	if kept > 0 && !createdAt.After(current[kept-1].CreatedAt()) {
+		createdAt = current[kept-1].CreatedAt().Add(time.Nanosecond)
+	}
+	for i, d := range desired[kept:] {
+		r := rule.ReconstructRule(d.ID(), d.Match(), d.Action(), createdAt.Add(time.Duration(i)))
+		changes.Overrides.Created = append(changes.Overrides.Created, r.ID())
+		ops = append(ops, stateOp{
+			apply: func(ctx context.Context) error { return s.ruleRepo.Save(ctx, r) },
+			undo:  func(ctx context.Context) error { return s.ruleRepo.Delete(ctx, r.ID()) },
+		})
+	}
+	return ops
+}
+
+// saveStreams saves streams, as when restoring the streams of a deleted
+// channel.
+func (s *StateService) saveStreams(ctx context.Context, streams []stream.Stream) error {
+	for _, st := range streams {
+		if err := s.streamRepo.Save(ctx, st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/adapter/driven"
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/rule"
+	"github.com/alorle/iptv-manager/internal/state"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// failingStreamRepository fails to save the stream with the given infohash.
+type failingStreamRepository struct {
+	*driven.StreamBoltDBRepository
+	failHash string
+}
+
+func (r *failingStreamRepository) Save(ctx context.Context, s stream.Stream) error {
+	if s.InfoHash() == r.failHash {
+		return errors.New("disk full")
+	}
+	return r.StreamBoltDBRepository.Save(ctx, s)
+}
+
+type stateTestRepos struct {
+	channels *driven.ChannelBoltDBRepository
+	streams  *failingStreamRepository
+	groups   *driven.GroupBoltDBRepository
+	rules    *driven.RuleBoltDBRepository
+}
+
+func newTestStateService(t *testing.T) (*StateService, stateTestRepos) {
+	t.Helper()
+
+	db, cleanup := setupE2ETestDB(t)
+	t.Cleanup(cleanup)
+
+	channelRepo, err := driven.NewChannelBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create channel repository: %v", err)
+	}
+	streamRepo, err := driven.NewStreamBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create stream repository: %v", err)
+	}
+	groupRepo, err := driven.NewGroupBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create group repository: %v", err)
+	}
+	ruleRepo, err := driven.NewRuleBoltDBRepository(db)
+	if err != nil {
+		t.Fatalf("failed to create rule repository: %v", err)
+	}
+
+	repos := stateTestRepos{
+		channels: channelRepo,
+		streams:  &failingStreamRepository{StreamBoltDBRepository: streamRepo},
+		groups:   groupRepo,
+		rules:    ruleRepo,
+	}
+	return NewStateService(channelRepo, repos.streams, groupRepo, ruleRepo), repos
+}
+
+func TestStateService_Apply(t *testing.T) {
+	ctx := context.Background()
+	const (
+		hashA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		hashB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+		hashC = "cccccccccccccccccccccccccccccccccccccccc"
+	)
+
+	// seed stores a lineup with a group, two channels with a stream each
+	// and an override rule
+	seed := func(t *testing.T, repos stateTestRepos) {
+		t.Helper()
+		g, _ := group.NewGroup("Sports")
+		if err := repos.groups.Save(ctx, g); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"DAZN 1", "La 1"} {
+			ch, _ := channel.NewChannel(name)
+			if err := repos.channels.Save(ctx, ch); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for hash, name := range map[string]string{hashA: "DAZN 1", hashB: "La 1"} {
+			if err := repos.streams.Save(ctx, stream.ReconstructStream(hash, name, stream.SourceElcano)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		r, _ := rule.NewRule(rule.Match{Group: "Spam"}, rule.Action{Disable: true}, time.Now())
+		if err := repos.rules.Save(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	desired := state.Document{
+		Version: state.Version,
+		Groups:  []state.Group{{Name: "Sports"}, {Name: "News"}},
+		Channels: []state.Channel{
+			{
+				Name:    "DAZN 1",
+				Group:   "sports",
+				Aliases: []string{"dazn1"},
+				Streams: []state.Stream{{InfoHash: hashA, Quality: "1080p"}, {InfoHash: hashB}},
+			},
+			{Name: "24h", Group: "news", EPGID: "24h.es", TVGShift: -1, Streams: []state.Stream{{InfoHash: hashC}}},
+		},
+		Overrides: []state.Override{
+			{Match: state.OverrideMatch{Group: "Spam"}, Action: state.OverrideAction{Disable: true}},
+			{Match: state.OverrideMatch{NamePattern: "^(.*) HD$"}, Action: state.OverrideAction{Rename: "$1"}},
+		},
+	}
+
+	t.Run("makes the lineup match the document", func(t *testing.T) {
+		service, repos := newTestStateService(t)
+		seed(t, repos)
+
+		changes, err := service.Apply(ctx, desired, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(changes.Groups.Created, []string{"news"}) ||
+			!slices.Equal(changes.Channels.Created, []string{"24h"}) ||
+			!slices.Equal(changes.Channels.Updated, []string{"DAZN 1"}) ||
+			!slices.Equal(changes.Channels.Deleted, []string{"La 1"}) ||
+			!slices.Equal(changes.Streams.Created, []string{hashC}) ||
+			!slices.Equal(changes.Streams.Updated, []string{hashB}) ||
+			len(changes.Streams.Deleted) != 0 ||
+			len(changes.Overrides.Created) != 1 || len(changes.Overrides.Deleted) != 0 {
+			t.Errorf("unexpected changes %+v", changes)
+		}
+
+		moved, err := repos.streams.FindByInfoHash(ctx, hashB)
+		if err != nil || moved.ChannelName() != "DAZN 1" || moved.Source() != stream.SourceElcano {
+			t.Errorf("expected stream B moved to DAZN 1 keeping its source, got %+v (%v)", moved, err)
+		}
+		created, err := repos.streams.FindByInfoHash(ctx, hashC)
+		if err != nil || created.Source() != stream.SourceManual {
+			t.Errorf("expected stream C created as manual, got %+v (%v)", created, err)
+		}
+		if _, err := repos.channels.FindByName(ctx, "La 1"); !errors.Is(err, channel.ErrChannelNotFound) {
+			t.Errorf("expected La 1 deleted, got %v", err)
+		}
+
+		// Exporting gives back the document, and applying it again changes
+		// nothing
+		doc, err := service.Export(ctx)
+		if err != nil {
+			t.Fatalf("unexpected export error: %v", err)
+		}
+		if len(doc.Channels) != 2 || doc.Channels[0].Name != "24h" || doc.Channels[0].EPGID != "24h.es" || doc.Channels[0].TVGShift != -1 ||
+			doc.Channels[1].Streams[0].Quality != "1080p" || len(doc.Overrides) != 2 || doc.Groups[1].ID != "news" {
+			t.Errorf("unexpected export %+v", doc)
+		}
+		changes, err = service.Apply(ctx, doc, false)
+		if err != nil || !changes.Empty() {
+			t.Errorf("expected re-applying the export to change nothing, got %+v (%v)", changes, err)
+		}
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		service, repos := newTestStateService(t)
+		seed(t, repos)
+
+		changes, err := service.Apply(ctx, desired, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !changes.DryRun || !slices.Equal(changes.Channels.Deleted, []string{"La 1"}) {
+			t.Errorf("unexpected changes %+v", changes)
+		}
+		if _, err := repos.channels.FindByName(ctx, "La 1"); err != nil {
+			t.Errorf("expected La 1 kept, got %v", err)
+		}
+		if _, err := repos.streams.FindByInfoHash(ctx, hashC); !errors.Is(err, stream.ErrStreamNotFound) {
+			t.Errorf("expected stream C not created, got %v", err)
+		}
+	})
+
+	t.Run("reverts what was changed when a change fails", func(t *testing.T) {
+		service, repos := newTestStateService(t)
+		seed(t, repos)
+		before, err := service.Export(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		repos.streams.failHash = hashC
+		if _, err := service.Apply(ctx, desired, false); err == nil {
+			t.Fatal("expected an error")
+		}
+
+		after, err := service.Export(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(after.Groups) != 1 || len(after.Channels) != 2 || after.Channels[1].Name != "La 1" ||
+			len(after.Channels[1].Streams) != 1 || len(after.Channels[0].Aliases) != 0 || len(after.Overrides) != 1 {
+			t.Errorf("expected the lineup restored to %+v, got %+v", before, after)
+		}
+	})
+
+	t.Run("rejects impossible documents", func(t *testing.T) {
+		tests := []struct {
+			name string
+			doc  state.Document
+		}{
+			{"unknown group", state.Document{Channels: []state.Channel{{Name: "A", Group: "missing"}}}},
+			{"duplicate channel", state.Document{Channels: []state.Channel{{Name: "A"}, {Name: "A"}}}},
+			{"duplicate alias", state.Document{Channels: []state.Channel{{Name: "A", Aliases: []string{"x"}}, {Name: "B", Aliases: []string{"x"}}}}},
+			{"stream in two channels", state.Document{Channels: []state.Channel{
+				{Name: "A", Streams: []state.Stream{{InfoHash: hashA}}},
+				{Name: "B", Streams: []state.Stream{{InfoHash: hashA}}},
+			}}},
+			{"invalid infohash", state.Document{Channels: []state.Channel{{Name: "A", Streams: []state.Stream{{InfoHash: "nope"}}}}}},
+			{"invalid quality", state.Document{Channels: []state.Channel{{Name: "A", QualityPreference: []string{"8k"}}}}},
+			{"invalid override", state.Document{Overrides: []state.Override{{Match: state.OverrideMatch{Group: "x"}}}}},
+			{"invalid group id", state.Document{Groups: []state.Group{{ID: "Not A Slug", Name: "News"}}}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				service, repos := newTestStateService(t)
+				seed(t, repos)
+				if _, err := service.Apply(ctx, tt.doc, false); !errors.Is(err, state.ErrInvalidDocument) {
+					t.Errorf("expected ErrInvalidDocument, got %v", err)
+				}
+				if _, err := repos.channels.FindByName(ctx, "La 1"); err != nil {
+					t.Errorf("expected the lineup unchanged, got %v", err)
+				}
+			})
+		}
+	})
+}
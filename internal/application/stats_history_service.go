@@ -0,0 +1,123 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/streamstats"
+)
+
+// ErrInvalidStatsRange indicates a stats history range that is not positive.
+var ErrInvalidStatsRange = errors.New("stats history range must be positive")
+
+// statsHistoryPoints is roughly how many points History returns for a range.
+const statsHistoryPoints = 120
+
+// StatsSource provides the engine statistics of the streams being played.
+type StatsSource interface {
+	CollectStats(ctx context.Context) []driven.StreamStats
+}
+
+// StatsHistoryService records the engine statistics of playing streams over
+// time and serves their history for charting. Readings are kept as recorded
+// for rawRetention, then compacted into hourly averages that are kept until
+// retention.
+type StatsHistoryService struct {
+	repo         driven.StreamStatsRepository
+	source       StatsSource
+	rawRetention time.Duration
+	retention    time.Duration
+	logger       *slog.Logger
+	now          func() time.Time
+}
+
+// NewStatsHistoryService creates a new stats history service.
+func NewStatsHistoryService(
+	repo driven.StreamStatsRepository,
+	source StatsSource,
+	rawRetention time.Duration,
+	retention time.Duration,
+	logger *slog.Logger,
+) *StatsHistoryService {
+	return &StatsHistoryService{
+		repo:         repo,
+		source:       source,
+		rawRetention: rawRetention,
+		retention:    retention,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// Record saves a reading of the engine statistics of every playing stream.
+func (s *StatsHistoryService) Record(ctx context.Context) error {
+	now := s.now()
+	var samples []streamstats.Sample
+	for _, stats := range s.source.CollectStats(ctx) {
+		sample, err := streamstats.NewSample(stats.InfoHash, now, stats.Peers, stats.SpeedDown, stats.SpeedUp)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, sample)
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	if err := s.repo.Save(ctx, samples...); err != nil {
+		return fmt.Errorf("failed to save stats samples: %w", err)
+	}
+	return nil
+}
+
+// Compact averages readings older than the raw retention into hourly samples
+// and removes samples older than the retention. Only whole hours are
+// compacted, so an hour is never split between readings and an average.
+func (s *StatsHistoryService) Compact(ctx context.Context) error {
+	now := s.now()
+
+	cutoff := now.Add(-s.rawRetention).Truncate(time.Hour)
+	readings, err := s.repo.FindReadingsBefore(ctx, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to find stats readings: %w", err)
+	}
+	if len(readings) > 0 {
+		hourly := streamstats.Aggregate(readings, time.Hour)
+		if err := s.repo.ReplaceReadingsBefore(ctx, cutoff, hourly); err != nil {
+			return fmt.Errorf("failed to compact stats readings: %w", err)
+		}
+		s.logger.DebugContext(ctx, "compacted stats history", "readings", len(readings), "samples", len(hourly))
+	}
+
+	if _, err := s.repo.DeleteBefore(ctx, now.Add(-s.retention)); err != nil {
+		return fmt.Errorf("failed to delete old stats samples: %w", err)
+	}
+	return nil
+}
+
+// History returns the stats of a stream over the last rng, averaged into
+// about 120 points of at least a minute each, oldest first. Ranges reaching
+// past the raw retention use points of at least an hour, the resolution of
+// compacted samples. Points are only returned for periods with recorded
+// samples.
+// Returns ErrInvalidStatsRange if rng is not positive.
+func (s *StatsHistoryService) History(ctx context.Context, infoHash string, rng time.Duration) ([]streamstats.Sample, error) {
+	if rng <= 0 {
+		return nil, ErrInvalidStatsRange
+	}
+
+	samples, err := s.repo.FindByInfoHashSince(ctx, infoHash, s.now().Add(-rng))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stats samples: %w", err)
+	}
+
+	step := max(rng/statsHistoryPoints, time.Minute)
+	if rng > s.rawRetention {
+		step = max(step, time.Hour)
+	}
+	return streamstats.Aggregate(samples, step), nil
+}
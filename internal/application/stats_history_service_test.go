@@ -0,0 +1,154 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/streamstats"
+)
+
+// memStreamStatsRepository is an in-memory driven.StreamStatsRepository for testing.
+type memStreamStatsRepository struct {
+	samples []streamstats.Sample
+}
+
+func (r *memStreamStatsRepository) Save(ctx context.Context, samples ...streamstats.Sample) error {
+	r.samples = append(r.samples, samples...)
+	return nil
+}
+
+func (r *memStreamStatsRepository) FindByInfoHashSince(ctx context.Context, infoHash string, since time.Time) ([]streamstats.Sample, error) {
+	var result []streamstats.Sample
+	for _, s := range r.samples {
+		if s.InfoHash() == infoHash && !s.Timestamp().Before(since) {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (r *memStreamStatsRepository) FindReadingsBefore(ctx context.Context, before time.Time) ([]streamstats.Sample, error) {
+	var result []streamstats.Sample
+	for _, s := range r.samples {
+		if s.Span() == 0 && s.Timestamp().Before(before) {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (r *memStreamStatsRepository) ReplaceReadingsBefore(ctx context.Context, before time.Time, samples []streamstats.Sample) error {
+	r.samples = slices.DeleteFunc(r.samples, func(s streamstats.Sample) bool {
+		return s.Span() == 0 && s.Timestamp().Before(before)
+	})
+	r.samples = append(r.samples, samples...)
+	return nil
+}
+
+func (r *memStreamStatsRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	n := len(r.samples)
+	r.samples = slices.DeleteFunc(r.samples, func(s streamstats.Sample) bool {
+		return s.Timestamp().Before(before)
+	})
+	return n - len(r.samples), nil
+}
+
+type stubStatsSource []driven.StreamStats
+
+func (s stubStatsSource) CollectStats(ctx context.Context) []driven.StreamStats { return s }
+
+func TestStatsHistoryService(t *testing.T) {
+	ctx := context.Background()
+	repo := &memStreamStatsRepository{}
+	source := stubStatsSource{{InfoHash: "a", Peers: 10, SpeedDown: 1000, SpeedUp: 100}}
+	service := NewStatsHistoryService(repo, source, 24*time.Hour, 30*24*time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// Record a reading every 10 minutes for two days.
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	clock := start
+	service.now = func() time.Time { return clock }
+	for ; clock.Before(start.Add(48 * time.Hour)); clock = clock.Add(10 * time.Minute) {
+		if err := service.Record(ctx); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if len(repo.samples) != 288 {
+		t.Fatalf("expected 288 readings, got %d", len(repo.samples))
+	}
+
+	if err := service.Compact(ctx); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	// The first day is compacted into 24 hourly samples of 6 readings each.
+	if len(repo.samples) != 24+144 {
+		t.Fatalf("expected 168 samples after compaction, got %d", len(repo.samples))
+	}
+
+	points, err := service.History(ctx, "a", 12*time.Hour)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(points) != 72 || points[0].Span() != 6*time.Minute {
+		t.Fatalf("expected a 6-minute point per reading, got %d", len(points))
+	}
+
+	points, err = service.History(ctx, "a", 48*time.Hour)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(points) != 48 {
+		t.Fatalf("expected 48 hourly points past the raw retention, got %d", len(points))
+	}
+	for _, p := range points {
+		if p.Span() != time.Hour || p.Peers() != 10 || p.SpeedDown() != 1000 || p.SpeedUp() != 100 {
+			t.Fatalf("unexpected point %+v", p)
+		}
+	}
+
+	if _, err := service.History(ctx, "a", 0); !errors.Is(err, ErrInvalidStatsRange) {
+		t.Errorf("expected ErrInvalidStatsRange, got %v", err)
+	}
+
+	// Samples past the retention are removed.
+	clock = start.Add(31 * 24 * time.Hour)
+	if err := service.Compact(ctx); err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if len(repo.samples) != 24 {
+		t.Errorf("expected only the compacted second day to be kept, got %d samples", len(repo.samples))
+	}
+}
+
+func TestAceStreamProxyService_CollectStats(t *testing.T) {
+	engine, _ := blockingEngine("dl")
+	service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+	if stats := service.CollectStats(context.Background()); len(stats) != 0 {
+		t.Errorf("expected no stats without playing streams, got %+v", stats)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = service.StreamToClient(ctx, "infohash-1", io.Discard)
+	}()
+	waitForClientSessions(t, service, 1)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		stats := service.CollectStats(context.Background())
+		if len(stats) == 1 && stats[0].InfoHash == "infohash-1" && stats[0].Status == "dl" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected stats of the playing stream, got %+v", stats)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,118 @@
+package application
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/alorle/iptv-manager/internal/ratelimit"
+)
+
+// ErrInvalidBandwidthLimit indicates a negative bandwidth limit.
+var ErrInvalidBandwidthLimit = errors.New("bandwidth limits cannot be negative")
+
+// BandwidthLimits caps how fast streams are served, in bytes per second.
+// Zero leaves a limit off.
+type BandwidthLimits struct {
+	// Global caps the total sent to all clients.
+	Global int64
+	// PerClient caps the total sent to each client IP, across its streams.
+	PerClient int64
+	// PerStream caps how fast each engine stream is read and fanned out to
+	// its clients.
+	PerStream int64
+}
+
+// bandwidthState holds the bandwidth limits and the limiters enforcing them.
+// Per-client and per-stream limiters are shared by everyone using the same
+// key and dropped once the last of them is done.
+type bandwidthState struct {
+	mu      sync.Mutex
+	limits  BandwidthLimits
+	global  *ratelimit.ByteLimiter
+	clients map[string]*sharedByteLimiter
+	streams map[string]*sharedByteLimiter
+}
+
+// sharedByteLimiter is a limiter and the number of users holding it.
+type sharedByteLimiter struct {
+	limiter *ratelimit.ByteLimiter
+	refs    int
+}
+
+func newBandwidthState() *bandwidthState {
+	return &bandwidthState{
+		global:  ratelimit.NewByteLimiter(0),
+		clients: make(map[string]*sharedByteLimiter),
+		streams: make(map[string]*sharedByteLimiter),
+	}
+}
+
+// SetBandwidthLimits changes the bandwidth limits. Running streams and
+// connected clients switch to the new limits right away.
+// Returns ErrInvalidBandwidthLimit if a limit is negative.
+func (s *AceStreamProxyService) SetBandwidthLimits(limits BandwidthLimits) error {
+	if limits.Global < 0 || limits.PerClient < 0 || limits.PerStream < 0 {
+		return ErrInvalidBandwidthLimit
+	}
+
+	b := s.bandwidth
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.limits = limits
+	b.global.SetRate(limits.Global)
+	for _, l := range b.clients {
+		l.limiter.SetRate(limits.PerClient)
+	}
+	for _, l := range b.streams {
+		l.limiter.SetRate(limits.PerStream)
+	}
+	return nil
+}
+
+// BandwidthLimits returns the current bandwidth limits.
+func (s *AceStreamProxyService) BandwidthLimits() BandwidthLimits {
+	s.bandwidth.mu.Lock()
+	defer s.bandwidth.mu.Unlock()
+	return s.bandwidth.limits
+}
+
+// acquireClient returns the limiters a client with the given IP is served
+// through and a function releasing them once the client is gone.
+func (b *bandwidthState) acquireClient(clientIP string) ([]*ratelimit.ByteLimiter, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	client, release := b.acquireLocked(b.clients, clientIP, b.limits.PerClient)
+	return []*ratelimit.ByteLimiter{b.global, client}, release
+}
+
+// acquireStream returns the limiter an engine stream is read through and a
+// function releasing it once the stream ends.
+func (b *bandwidthState) acquireStream(key string) (*ratelimit.ByteLimiter, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.acquireLocked(b.streams, key, b.limits.PerStream)
+}
+
+// acquireLocked takes a reference on the limiter for key in limiters,
+// creating it with rate if needed. Callers must hold b.mu.
+func (b *bandwidthState) acquireLocked(limiters map[string]*sharedByteLimiter, key string, rate int64) (*ratelimit.ByteLimiter, func()) {
+	shared, ok := limiters[key]
+	if !ok {
+		shared = &sharedByteLimiter{limiter: ratelimit.NewByteLimiter(rate)}
+		limiters[key] = shared
+	}
+	shared.refs++
+
+	var once sync.Once
+	return shared.limiter, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			shared.refs--
+			if shared.refs == 0 && limiters[key] == shared {
+				delete(limiters, key)
+			}
+		})
+	}
+}
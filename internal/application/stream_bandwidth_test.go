@@ -0,0 +1,73 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestAceStreamProxyService_SetBandwidthLimits(t *testing.T) {
+	proxy := NewAceStreamProxyService(&mockAceStreamEngine{}, newTestLogger(), 10*time.Second, nil)
+
+	if err := proxy.SetBandwidthLimits(BandwidthLimits{PerClient: -1}); !errors.Is(err, ErrInvalidBandwidthLimit) {
+		t.Errorf("expected ErrInvalidBandwidthLimit, got %v", err)
+	}
+
+	first, releaseFirst := proxy.bandwidth.acquireClient("10.0.0.1")
+	second, releaseSecond := proxy.bandwidth.acquireClient("10.0.0.1")
+	if first[1] != second[1] {
+		t.Error("expected connections from the same IP to share a limiter")
+	}
+	stream, releaseStream := proxy.bandwidth.acquireStream("abc123")
+
+	limits := BandwidthLimits{Global: 10 << 20, PerClient: 2 << 20, PerStream: 4 << 20}
+	if err := proxy.SetBandwidthLimits(limits); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxy.BandwidthLimits() != limits {
+		t.Errorf("BandwidthLimits() = %+v, want %+v", proxy.BandwidthLimits(), limits)
+	}
+	if first[0].Rate() != limits.Global || first[1].Rate() != limits.PerClient || stream.Rate() != limits.PerStream {
+		t.Error("expected connected clients and running streams to switch to the new limits")
+	}
+
+	releaseFirst()
+	releaseFirst()
+	if len(proxy.bandwidth.clients) != 1 {
+		t.Error("expected the limiter to be kept while a connection still uses it")
+	}
+	releaseSecond()
+	releaseStream()
+	if len(proxy.bandwidth.clients) != 0 || len(proxy.bandwidth.streams) != 0 {
+		t.Error("expected limiters to be dropped once unused")
+	}
+}
+
+func TestAceStreamProxyService_StreamToClient_PerStreamLimit(t *testing.T) {
+	const size = 5 << 18 // 1.25 MiB
+	engine := &mockAceStreamEngine{
+		streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+			_, err := dst.Write(make([]byte, size))
+			return err
+		},
+	}
+	proxy := NewAceStreamProxyService(engine, newTestLogger(), 10*time.Second, nil)
+	if err := proxy.SetBandwidthLimits(BandwidthLimits{PerStream: 1 << 20}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var dst bytes.Buffer
+	start := time.Now()
+	if err := proxy.StreamToClient(context.Background(), "abc123", &dst); err != nil && !errors.Is(err, io.EOF) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dst.Len() != size {
+		t.Errorf("expected %d bytes, got %d", size, dst.Len())
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("expected the stream to be paced to 1 MiB/s, took %v", elapsed)
+	}
+}
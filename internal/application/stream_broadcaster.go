@@ -2,64 +2,294 @@ package application
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
-	"net/http"
 	"sync"
 	"time"
 
 	"github.com/alorle/iptv-manager/internal/streaming"
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
 )
 
-const broadcastBufferSize = 128
+// ErrInvalidBufferPolicy indicates an unknown client buffer policy name.
+var ErrInvalidBufferPolicy = errors.New("invalid client buffer policy")
+
+// ClientBufferPolicy decides what happens when a client reads a shared stream
+// slower than the engine delivers it and its buffer fills up.
+type ClientBufferPolicy string
+
+const (
+	// ClientBufferDropOldest discards the client's oldest buffered data,
+	// skipping ahead to the latest keyframe so playback resumes cleanly.
+	ClientBufferDropOldest ClientBufferPolicy = "drop-oldest"
+	// ClientBufferDisconnect skips ahead like ClientBufferDropOldest, but
+	// disconnects clients that stay behind for longer than MaxLag.
+	ClientBufferDisconnect ClientBufferPolicy = "disconnect"
+	// ClientBufferPauseUpstream stops reading from the engine until every
+	// client has room again. A client that stops reading altogether is
+	// still dropped by its write timeout.
+	ClientBufferPauseUpstream ClientBufferPolicy = "pause-upstream"
+)
+
+// ParseClientBufferPolicy returns the policy with the given name.
+// Returns ErrInvalidBufferPolicy for unknown names.
+func ParseClientBufferPolicy(name string) (ClientBufferPolicy, error) {
+	switch policy := ClientBufferPolicy(name); policy {
+	case ClientBufferDropOldest, ClientBufferDisconnect, ClientBufferPauseUpstream:
+		return policy, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidBufferPolicy, name)
+}
+
+// defaultClientBufferSize bounds each client's buffer when no size is
+// configured: a few seconds of an HD stream.
+const defaultClientBufferSize = 4 << 20
+
+// ClientBufferOptions configures how much of a shared stream is buffered for
+// each client and what happens when a client falls behind.
+type ClientBufferOptions struct {
+	// Policy defaults to ClientBufferDropOldest.
+	Policy ClientBufferPolicy
+	// Size is the most bytes buffered per client. Zero or negative uses
+	// a 4 MiB default.
+	Size int
+	// MaxLag is how long a client may stay behind under
+	// ClientBufferDisconnect. Zero disconnects as soon as its buffer fills.
+	MaxLag time.Duration
+	// JoinAtPAT holds data back from a client joining an MPEG-TS stream
+	// until the next program association table, for at most 2s, so that
+	// its decoder learns the programs before any of their data.
+	JoinAtPAT bool
+}
+
+func (o ClientBufferOptions) withDefaults() ClientBufferOptions {
+	if o.Policy == "" {
+		o.Policy = ClientBufferDropOldest
+	}
+	if o.Size <= 0 {
+		o.Size = defaultClientBufferSize
+	}
+	return o
+}
+
+// bufferedChunk is a piece of the stream waiting to be written to a client.
+type bufferedChunk struct {
+	data []byte
+	// keyframe is the offset in data of the first packet a decoder can
+	// start from, or -1 if there is none.
+	keyframe int
+}
+
+// maxPATWait bounds how long a client joining with JoinAtPAT waits for a
+// program association table before it receives the stream anyway.
+const maxPATWait = 2 * time.Second
+
+// minRingSlots is the number of chunks a client ring holds before it grows.
+const minRingSlots = 16
+
+// chunkRing is a FIFO of chunks. Its slot array starts small, doubles while a
+// client lags behind and shrinks back once the client has drained it, so
+// clients that keep up hold little memory.
+type chunkRing struct {
+	slots []bufferedChunk
+	head  int
+	count int
+	bytes int
+}
+
+func (r *chunkRing) push(c bufferedChunk) {
+	if r.count == len(r.slots) {
+		r.grow()
+	}
+	r.slots[(r.head+r.count)%len(r.slots)] = c
+	r.count++
+	r.bytes += len(c.data)
+}
+
+func (r *chunkRing) pop() (bufferedChunk, bool) {
+	if r.count == 0 {
+		return bufferedChunk{}, false
+	}
+	c := r.slots[r.head]
+	r.slots[r.head] = bufferedChunk{}
+	r.head = (r.head + 1) % len(r.slots)
+	r.count--
+	r.bytes -= len(c.data)
+	if r.count == 0 && len(r.slots) > minRingSlots {
+		r.slots = make([]bufferedChunk, minRingSlots)
+		r.head = 0
+	}
+	return c, true
+}
+
+func (r *chunkRing) grow() {
+	slots := make([]bufferedChunk, max(minRingSlots, 2*len(r.slots)))
+	for i := 0; i < r.count; i++ {
+		slots[i] = r.slots[(r.head+i)%len(r.slots)]
+	}
+	r.slots = slots
+	r.head = 0
+}
+
+// skipToKeyframe drops the chunks before the most recent one holding a
+// keyframe and trims that chunk to start at it. Returns the number of bytes
+// dropped, which is zero if no keyframe is buffered.
+func (r *chunkRing) skipToKeyframe() int {
+	last := -1
+	for i := r.count - 1; i >= 0; i-- {
+		if r.slots[(r.head+i)%len(r.slots)].keyframe >= 0 {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		return 0
+	}
+
+	dropped := 0
+	for i := 0; i < last; i++ {
+		c, _ := r.pop()
+		dropped += len(c.data)
+	}
+	if c := &r.slots[r.head]; c.keyframe > 0 {
+		dropped += c.keyframe
+		r.bytes -= c.keyframe
+		c.data = c.data[c.keyframe:]
+		c.keyframe = 0
+	}
+	return dropped
+}
 
 // broadcastClient represents a single subscriber to a broadcast stream.
+// Its ring and state are guarded by the broadcaster lock; ready wakes the
+// subscriber when data is buffered or the client is closed.
 type broadcastClient struct {
-	chunks chan []byte
-	pid    string
+	ring        chunkRing
+	ready       chan struct{}
+	pid         string
+	closed      bool
+	behindSince time.Time // when the client last overflowed without catching up
+	joinedAt    time.Time
+	awaitingPAT bool // data is held back until a program association table
+	// lastSend is when the client last finished a write or was caught up,
+	// and sending whether a write to it is in progress.
+	lastSend time.Time
+	sending  bool
+	err      error // why the client was dropped, if it was reaped
+}
+
+func newBroadcastClient(pid string) *broadcastClient {
+	return &broadcastClient{
+		ready: make(chan struct{}, 1),
+		pid:   pid,
+	}
+}
+
+// fits reports whether n more bytes can be buffered within size. A single
+// chunk larger than size is accepted into an empty buffer.
+func (c *broadcastClient) fits(n, size int) bool {
+	return c.ring.count == 0 || c.ring.bytes+n <= size
+}
+
+// close marks the client as closed and wakes its subscriber. Data already
+// buffered is still delivered. It is safe to call more than once.
+func (c *broadcastClient) close() {
+	c.closed = true
+	c.notify()
+}
+
+// idle reports whether the client has had data waiting for it, or a write in
+// progress, without finishing a write for at least timeout.
+func (c *broadcastClient) idle(now time.Time, timeout time.Duration) bool {
+	return (c.sending || c.ring.count > 0) && now.Sub(c.lastSend) >= timeout
+}
+
+func (c *broadcastClient) notify() {
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
 }
 
 // streamBroadcaster reads from a single engine stream and distributes data
 // to multiple subscribers. It implements io.Writer so it can be used as the
 // destination for engine.StreamContent.
+//
+// MPEG-TS data is buffered in whole 188-byte packets whatever the size of
+// the writes, so that clients joining a running stream or skipped ahead
+// always start on a packet boundary instead of showing corruption until
+// their decoder resyncs.
 type streamBroadcaster struct {
-	mu       sync.Mutex
+	mu    sync.Mutex
+	space *sync.Cond // signalled when the clients a writer waits for have room, or one leaves
+	// blocked counts the clients a writer paused under
+	// ClientBufferPauseUpstream waits for to have room for waitFor bytes.
+	// They signal space once the last of them has, so the writer is woken
+	// once per chunk rather than once per client.
+	blocked  int
+	waitFor  int
 	clients  map[string]*broadcastClient
+	aligner  mpegts.Aligner
 	closed   bool
 	err      error // error that caused the broadcaster to close
+	buffer   ClientBufferOptions
 	logger   *slog.Logger
 	infoHash string
 }
 
-func newStreamBroadcaster(infoHash string, logger *slog.Logger) *streamBroadcaster {
-	return &streamBroadcaster{
+func newStreamBroadcaster(infoHash string, logger *slog.Logger, buffer ClientBufferOptions) *streamBroadcaster {
+	b := &streamBroadcaster{
 		clients:  make(map[string]*broadcastClient),
+		buffer:   buffer.withDefaults(),
 		logger:   logger,
 		infoHash: infoHash,
 	}
+	b.space = sync.NewCond(&b.mu)
+	return b
 }
 
-// Write implements io.Writer. It copies data to all subscribed clients' channels.
-// Slow clients whose channel buffers are full are dropped.
+// Write implements io.Writer. It buffers a copy of p for every subscribed
+// client, handling clients whose buffers are full according to the buffer
+// policy. Under ClientBufferPauseUpstream it blocks until all clients have room.
 func (b *streamBroadcaster) Write(p []byte) (int, error) {
-	data := make([]byte, len(p))
-	copy(data, p)
-
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+	data := b.aligner.Align(p)
+	if len(data) == 0 {
+		return len(p), nil
+	}
+	chunk := bufferedChunk{data: data, keyframe: mpegts.RandomAccessOffset(data)}
+
+	if b.buffer.Policy == ClientBufferPauseUpstream {
+		for !b.closed {
+			b.blocked, b.waitFor = b.countBlocked(len(data)), len(data)
+			if b.blocked == 0 {
+				break
+			}
+			b.space.Wait()
+		}
+		// Any other paused writer recounts the clients it waits for.
+		b.blocked, b.waitFor = 0, 0
+		b.space.Broadcast()
+	}
 	if b.closed {
 		return 0, io.ErrClosedPipe
 	}
 
+	now := time.Now()
 	for pid, client := range b.clients {
-		select {
-		case client.chunks <- data:
-		default:
+		if !b.deliver(client, chunk, now) {
 			b.logger.Warn("dropping slow client from broadcast",
 				"infohash", b.infoHash,
-				"pid", pid)
-			close(client.chunks)
+				"pid", pid,
+				"behind", now.Sub(client.behindSince))
+			client.close()
 			delete(b.clients, pid)
 		}
 	}
@@ -67,7 +297,114 @@ func (b *streamBroadcaster) Write(p []byte) (int, error) {
 	return len(p), nil
 }
 
-// Close signals all subscribers that the stream has ended by closing their channels.
+// countBlocked returns how many clients have no room for n more bytes.
+func (b *streamBroadcaster) countBlocked(n int) int {
+	blocked := 0
+	for _, client := range b.clients {
+		if !client.fits(n, b.buffer.Size) {
+			blocked++
+		}
+	}
+	return blocked
+}
+
+// deliver buffers chunk for client, skipping the client ahead if its buffer
+// is full. Returns false if the client should be disconnected instead.
+func (b *streamBroadcaster) deliver(client *broadcastClient, chunk bufferedChunk, now time.Time) bool {
+	if client.closed {
+		return false
+	}
+	if client.awaitingPAT {
+		var ok bool
+		if chunk, ok = b.joinAtPAT(client, chunk, now); !ok {
+			return true
+		}
+	}
+	if client.ring.count == 0 && !client.sending {
+		// A client with nothing left to send is up to date
+		client.lastSend = now
+	}
+	if client.fits(len(chunk.data), b.buffer.Size) {
+		client.ring.push(chunk)
+		client.notify()
+		return true
+	}
+
+	if client.behindSince.IsZero() {
+		client.behindSince = now
+		b.logger.Warn("client falling behind broadcast",
+			"infohash", b.infoHash,
+			"pid", client.pid,
+			"policy", b.buffer.Policy)
+	}
+	if b.buffer.Policy == ClientBufferDisconnect && now.Sub(client.behindSince) >= b.buffer.MaxLag {
+		return false
+	}
+
+	client.ring.push(chunk)
+	skipped := client.ring.skipToKeyframe()
+	for client.ring.bytes > b.buffer.Size && client.ring.count > 1 {
+		c, _ := client.ring.pop()
+		skipped += len(c.data)
+	}
+	b.logger.Debug("skipped lagging client ahead",
+		"infohash", b.infoHash,
+		"pid", client.pid,
+		"skipped_bytes", skipped)
+	client.notify()
+	return true
+}
+
+// joinAtPAT trims chunk to start at its first program association table
+// for a client still waiting for one. Returns false if the chunk holds none
+// and the client is to keep waiting.
+func (b *streamBroadcaster) joinAtPAT(client *broadcastClient, chunk bufferedChunk, now time.Time) (bufferedChunk, bool) {
+	if !b.aligner.Synced() || now.Sub(client.joinedAt) >= maxPATWait {
+		client.awaitingPAT = false
+		return chunk, true
+	}
+	offset := mpegts.PATOffset(chunk.data)
+	if offset < 0 {
+		return chunk, false
+	}
+	client.awaitingPAT = false
+	if offset == 0 {
+		return chunk, true
+	}
+	data := chunk.data[offset:]
+	keyframe := chunk.keyframe - offset
+	if chunk.keyframe < offset {
+		keyframe = mpegts.RandomAccessOffset(data)
+	}
+	return bufferedChunk{data: data, keyframe: keyframe}, true
+}
+
+// reapIdle drops the clients that have been idle (see broadcastClient.idle)
+// for at least timeout, typically because their connection is stuck, and
+// returns their PIDs. Their subscribers return ErrClientIdle.
+func (b *streamBroadcaster) reapIdle(timeout time.Duration, now time.Time) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var reaped []string
+	for pid, client := range b.clients {
+		if !client.idle(now, timeout) {
+			continue
+		}
+		client.err = ErrClientIdle
+		client.ring = chunkRing{}
+		client.close()
+		delete(b.clients, pid)
+		reaped = append(reaped, pid)
+	}
+	if len(reaped) > 0 {
+		// A paused writer may have been waiting for one of them
+		b.space.Broadcast()
+	}
+	return reaped
+}
+
+// Close signals all subscribers that the stream has ended.
 func (b *streamBroadcaster) Close() {
 	b.CloseWithError(nil)
 }
@@ -84,18 +421,35 @@ func (b *streamBroadcaster) CloseWithError(err error) {
 	b.closed = true
 	b.err = err
 
+	// The start of a packet cut off by the end of the stream is still the
+	// clients' data
+	now := time.Now()
+	if rest := b.aligner.Flush(); len(rest) > 0 {
+		for _, client := range b.clients {
+			if !client.awaitingPAT {
+				b.deliver(client, bufferedChunk{data: rest, keyframe: -1}, now)
+			}
+		}
+	}
 	for _, client := range b.clients {
-		close(client.chunks)
+		client.close()
 	}
+	b.space.Broadcast()
+}
+
+// isClosed reports whether the stream has ended.
+func (b *streamBroadcaster) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
 }
 
 // Subscribe registers a new client and blocks until the stream ends, the context
 // is cancelled, or a write error occurs. Each received chunk is written to dst.
+// Write deadlines of writeTimeout are only set when dst is an
+// http.ResponseWriter; zero leaves them to dst.
 func (b *streamBroadcaster) Subscribe(ctx context.Context, pid string, dst io.Writer, writeTimeout time.Duration) error {
-	client := &broadcastClient{
-		chunks: make(chan []byte, broadcastBufferSize),
-		pid:    pid,
-	}
+	client := newBroadcastClient(pid)
 
 	b.mu.Lock()
 	if b.closed {
@@ -103,38 +457,74 @@ func (b *streamBroadcaster) Subscribe(ctx context.Context, pid string, dst io.Wr
 		b.mu.Unlock()
 		return err
 	}
+	client.joinedAt = time.Now()
+	client.lastSend = client.joinedAt
+	client.awaitingPAT = b.buffer.JoinAtPAT
 	b.clients[pid] = client
 	b.mu.Unlock()
 
-	defer b.unsubscribe(pid)
+	defer b.unsubscribe(pid, client)
 
 	tw := streaming.NewTimeoutWriter(dst, writeTimeout, b.logger, b.infoHash, pid)
 
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case data, ok := <-client.chunks:
-			if !ok {
-				b.mu.Lock()
-				err := b.err
-				b.mu.Unlock()
-				return err
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		b.mu.Lock()
+		// The previous write, if any, has finished
+		client.lastSend = time.Now()
+		wasBlocked := b.blocked > 0 && !client.fits(b.waitFor, b.buffer.Size)
+		chunk, ok := client.ring.pop()
+		if wasBlocked && client.fits(b.waitFor, b.buffer.Size) {
+			b.blocked--
+			if b.blocked == 0 {
+				b.space.Broadcast()
 			}
-			if _, err := tw.Write(data); err != nil {
+		}
+		if client.ring.count == 0 {
+			client.behindSince = time.Time{}
+		}
+		client.sending = ok
+		closed, err := client.closed, b.err
+		if client.err != nil {
+			err = client.err
+		}
+		b.mu.Unlock()
+
+		if !ok {
+			if closed {
 				return err
 			}
-			if f, ok := dst.(http.Flusher); ok {
-				f.Flush()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-client.ready:
+			}
+			continue
+		}
+
+		if _, err := tw.Write(chunk.data); err != nil {
+			// A reaped client's write is aborted on purpose
+			b.mu.Lock()
+			if client.err != nil {
+				err = client.err
 			}
+			b.mu.Unlock()
+			return err
 		}
 	}
 }
 
-// unsubscribe removes a client from the broadcast. It does not close the
-// client's channel — that is handled by Write (slow client) or Close (stream end).
-func (b *streamBroadcaster) unsubscribe(pid string) {
+// unsubscribe removes a client from the broadcast, unless it has already been
+// dropped, and releases any writer waiting for it to make room.
+func (b *streamBroadcaster) unsubscribe(pid string, client *broadcastClient) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	delete(b.clients, pid)
+	if b.clients[pid] == client {
+		delete(b.clients, pid)
+	}
+	client.close()
+	b.space.Broadcast()
 }
@@ -0,0 +1,154 @@
+package application
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
+)
+
+// benchClient is a client connection that drains instantly and records how
+// long each chunk took from the broadcaster's Write to the client's Write.
+// The chunks carry their write time in their last bytes.
+type benchClient struct {
+	header    http.Header
+	latencies []time.Duration
+}
+
+func (c *benchClient) Header() http.Header                { return c.header }
+func (c *benchClient) WriteHeader(int)                    {}
+func (c *benchClient) Flush()                             {}
+func (c *benchClient) SetWriteDeadline(d time.Time) error { return nil }
+
+func (c *benchClient) Write(p []byte) (int, error) {
+	sent := int64(binary.BigEndian.Uint64(p[len(p)-8:]))
+	c.latencies = append(c.latencies, time.Duration(time.Now().UnixNano()-sent))
+	return len(p), nil
+}
+
+// BenchmarkStreamBroadcaster_FanOut broadcasts 32KB chunks, as read from
+// the engine, to simulated clients, and reports the 99th percentile delay
+// before a chunk reaches a client. Client buffers hold two chunks, so the
+// delay is that of delivery rather than of a backlog of buffered chunks.
+func BenchmarkStreamBroadcaster_FanOut(b *testing.B) {
+	for _, clients := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("clients=%d", clients), func(b *testing.B) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			bc := newStreamBroadcaster("bench-hash", logger, ClientBufferOptions{Policy: ClientBufferPauseUpstream, Size: 64 * 1024})
+
+			conns := newBenchClients(clients, b.N)
+			var wg sync.WaitGroup
+			for i := range conns {
+				wg.Add(1)
+				go func(pid string, c *benchClient) {
+					defer wg.Done()
+					_ = bc.Subscribe(context.Background(), pid, c, 10*time.Second)
+				}(fmt.Sprintf("pid-%d", i), conns[i])
+			}
+			for {
+				bc.mu.Lock()
+				subscribed := len(bc.clients)
+				bc.mu.Unlock()
+				if subscribed == clients {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			chunk := benchChunk()
+			b.SetBytes(int64(len(chunk)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				stampBenchChunk(chunk)
+				if _, err := bc.Write(chunk); err != nil {
+					b.Fatal(err)
+				}
+			}
+			bc.Close()
+			wg.Wait()
+			b.StopTimer()
+
+			reportP99(b, conns)
+		})
+	}
+}
+
+// BenchmarkFanOut_GoroutinePerChunk is the reference the broadcaster is
+// measured against: fanning each chunk out with a goroutine per client, as
+// the multiplexer once did, instead of one long-lived writer per client.
+func BenchmarkFanOut_GoroutinePerChunk(b *testing.B) {
+	for _, clients := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("clients=%d", clients), func(b *testing.B) {
+			conns := newBenchClients(clients, b.N)
+			var mu sync.Mutex // serialises writes to each client, one at a time
+
+			chunk := benchChunk()
+			b.SetBytes(int64(len(chunk)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				stampBenchChunk(chunk)
+				data := make([]byte, len(chunk))
+				copy(data, chunk)
+				var wg sync.WaitGroup
+				for _, c := range conns {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						mu.Lock()
+						defer mu.Unlock()
+						_, _ = c.Write(data)
+					}()
+				}
+				wg.Wait()
+			}
+			b.StopTimer()
+
+			reportP99(b, conns)
+		})
+	}
+}
+
+// benchChunk returns a 32KB read of an MPEG-TS stream without keyframes.
+func benchChunk() []byte {
+	chunk := make([]byte, 32*1024)
+	for i := 0; i < len(chunk); i += mpegts.PacketSize {
+		chunk[i] = 0x47
+	}
+	return chunk
+}
+
+// stampBenchChunk records the current time in the last bytes of chunk.
+func stampBenchChunk(chunk []byte) {
+	binary.BigEndian.PutUint64(chunk[len(chunk)-8:], uint64(time.Now().UnixNano()))
+}
+
+func newBenchClients(n, chunks int) []*benchClient {
+	conns := make([]*benchClient, n)
+	for i := range conns {
+		conns[i] = &benchClient{header: make(http.Header), latencies: make([]time.Duration, 0, chunks)}
+	}
+	return conns
+}
+
+// reportP99 reports the 99th percentile delivery delay of every chunk to
+// every client.
+func reportP99(b *testing.B, conns []*benchClient) {
+	var latencies []time.Duration
+	for _, c := range conns {
+		latencies = append(latencies, c.latencies...)
+	}
+	slices.Sort(latencies)
+	if len(latencies) > 0 {
+		b.ReportMetric(float64(latencies[len(latencies)*99/100]), "p99-ns")
+	}
+}
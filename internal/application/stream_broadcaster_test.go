@@ -3,16 +3,22 @@ package application
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"os"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming"
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
 )
 
 func TestStreamBroadcaster_Write(t *testing.T) {
 	t.Run("broadcasts to multiple subscribers", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
 
 		var buf1, buf2 bytes.Buffer
 		done1 := make(chan error, 1)
@@ -51,7 +57,7 @@ func TestStreamBroadcaster_Write(t *testing.T) {
 	})
 
 	t.Run("write to closed broadcaster returns error", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
 		b.Close()
 
 		_, err := b.Write([]byte("data"))
@@ -61,7 +67,7 @@ func TestStreamBroadcaster_Write(t *testing.T) {
 	})
 
 	t.Run("write with no subscribers succeeds", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
 
 		n, err := b.Write([]byte("nobody listening"))
 		if err != nil {
@@ -75,7 +81,7 @@ func TestStreamBroadcaster_Write(t *testing.T) {
 
 func TestStreamBroadcaster_Close(t *testing.T) {
 	t.Run("close stops all subscribers", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
 
 		var buf bytes.Buffer
 		done := make(chan error, 1)
@@ -92,7 +98,7 @@ func TestStreamBroadcaster_Close(t *testing.T) {
 	})
 
 	t.Run("double close is safe", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
 		b.Close()
 		b.Close() // should not panic
 	})
@@ -100,7 +106,7 @@ func TestStreamBroadcaster_Close(t *testing.T) {
 
 func TestStreamBroadcaster_Subscribe(t *testing.T) {
 	t.Run("subscribe after close returns immediately", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
 		b.Close()
 
 		var buf bytes.Buffer
@@ -111,7 +117,7 @@ func TestStreamBroadcaster_Subscribe(t *testing.T) {
 	})
 
 	t.Run("context cancellation stops subscriber", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
 
 		ctx, cancel := context.WithCancel(context.Background())
 		var buf bytes.Buffer
@@ -131,7 +137,7 @@ func TestStreamBroadcaster_Subscribe(t *testing.T) {
 	})
 
 	t.Run("multiple chunks delivered in order", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
 
 		var buf bytes.Buffer
 		done := make(chan error, 1)
@@ -157,44 +163,275 @@ func TestStreamBroadcaster_Subscribe(t *testing.T) {
 	})
 }
 
+// addStalledClient registers a client that never reads from the broadcast.
+func addStalledClient(b *streamBroadcaster, pid string) *broadcastClient {
+	client := newBroadcastClient(pid)
+	b.mu.Lock()
+	b.clients[pid] = client
+	b.mu.Unlock()
+	return client
+}
+
+// keyframeChunk returns a TS packet flagged as a random access point.
+func keyframeChunk() []byte {
+	pkt := make([]byte, mpegts.PacketSize)
+	pkt[0] = 0x47
+	pkt[3] = 0x30
+	pkt[4] = 1
+	pkt[5] = 0x40
+	return pkt
+}
+
 func TestStreamBroadcaster_SlowClient(t *testing.T) {
-	t.Run("slow client is dropped when buffer fills", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+	t.Run("drop-oldest skips a lagging client ahead to the latest keyframe", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{Size: 1024})
+		slow := addStalledClient(b, "slow-pid")
 
-		// Subscribe a client that never reads
-		slowClient := &broadcastClient{
-			chunks: make(chan []byte, broadcastBufferSize),
-			pid:    "slow-pid",
+		for i := 0; i < 4; i++ {
+			if _, err := b.Write(bytes.Repeat([]byte("x"), 200)); err != nil {
+				t.Fatalf("Write %d failed: %v", i, err)
+			}
 		}
+		keyframe := append(tsPackets(0x100, 1), keyframeChunk()...)
+		if _, err := b.Write(keyframe); err != nil {
+			t.Fatalf("keyframe Write failed: %v", err)
+		}
+		if _, err := b.Write(bytes.Repeat([]byte("y"), 600)); err != nil {
+			t.Fatalf("overflow Write failed: %v", err)
+		}
+
 		b.mu.Lock()
-		b.clients["slow-pid"] = slowClient
-		b.mu.Unlock()
+		defer b.mu.Unlock()
+		if _, exists := b.clients["slow-pid"]; !exists {
+			t.Fatal("slow client should be kept under drop-oldest")
+		}
+		first, _ := slow.ring.pop()
+		if !bytes.Equal(first.data, keyframeChunk()) {
+			t.Errorf("expected buffer to resume at the keyframe packet, got %d bytes starting %q", len(first.data), first.data[:8])
+		}
+		if slow.ring.count != 1 || slow.ring.bytes != 600 {
+			t.Errorf("expected only the newest chunk to follow, got %d chunks of %d bytes", slow.ring.count, slow.ring.bytes)
+		}
+	})
 
-		// Fill the buffer
-		for i := 0; i < broadcastBufferSize; i++ {
-			if _, err := b.Write([]byte("x")); err != nil {
-				t.Fatalf("Write %d failed: %v", i, err)
+	t.Run("drop-oldest discards the oldest data when there is no keyframe", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{Size: 10})
+		slow := addStalledClient(b, "slow-pid")
+
+		for _, c := range []string{"aaaa", "bbbb", "cccc", "dddd"} {
+			if _, err := b.Write([]byte(c)); err != nil {
+				t.Fatalf("Write failed: %v", err)
 			}
 		}
 
-		// Next write should drop the slow client
-		if _, err := b.Write([]byte("overflow")); err != nil {
-			t.Fatalf("overflow Write failed: %v", err)
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		var got []byte
+		for c, ok := slow.ring.pop(); ok; c, ok = slow.ring.pop() {
+			got = append(got, c.data...)
 		}
+		if string(got) != "ccccdddd" {
+			t.Errorf("got %q, want %q", got, "ccccdddd")
+		}
+	})
 
+	t.Run("disconnect drops a client that stays behind longer than the max lag", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{
+			Policy: ClientBufferDisconnect,
+			Size:   4,
+			MaxLag: 50 * time.Millisecond,
+		})
+		addStalledClient(b, "slow-pid")
+
+		for i := 0; i < 3; i++ {
+			if _, err := b.Write([]byte("data")); err != nil {
+				t.Fatalf("Write %d failed: %v", i, err)
+			}
+		}
 		b.mu.Lock()
 		_, exists := b.clients["slow-pid"]
 		b.mu.Unlock()
+		if !exists {
+			t.Fatal("client should be kept until it has been behind for the max lag")
+		}
 
+		time.Sleep(60 * time.Millisecond)
+		if _, err := b.Write([]byte("data")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		b.mu.Lock()
+		_, exists = b.clients["slow-pid"]
+		b.mu.Unlock()
 		if exists {
 			t.Error("slow client should have been dropped")
 		}
 	})
+
+	t.Run("pause-upstream blocks writes until the client makes room", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{
+			Policy: ClientBufferPauseUpstream,
+			Size:   4,
+		})
+		defer b.Close()
+		addStalledClient(b, "slow-pid")
+
+		if _, err := b.Write([]byte("data")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		written := make(chan error, 1)
+		go func() {
+			_, err := b.Write([]byte("more"))
+			written <- err
+		}()
+
+		select {
+		case <-written:
+			t.Fatal("expected Write to block while the client's buffer is full")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		b.mu.Lock()
+		b.clients["slow-pid"].ring.pop()
+		b.space.Broadcast()
+		b.mu.Unlock()
+
+		select {
+		case err := <-written:
+			if err != nil {
+				t.Fatalf("Write returned error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Write still blocked after the client made room")
+		}
+	})
+
+	t.Run("pause-upstream releases writes when the stream closes", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{
+			Policy: ClientBufferPauseUpstream,
+			Size:   4,
+		})
+		addStalledClient(b, "slow-pid")
+
+		if _, err := b.Write([]byte("data")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		written := make(chan error, 1)
+		go func() {
+			_, err := b.Write([]byte("more"))
+			written <- err
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		b.Close()
+
+		select {
+		case err := <-written:
+			if err == nil {
+				t.Error("expected Write to fail once the broadcaster is closed")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Write still blocked after Close")
+		}
+	})
+}
+
+// tsPackets returns n TS packets of the given PID, each starting a payload
+// unit.
+func tsPackets(pid uint16, n int) []byte {
+	var data []byte
+	for range n {
+		pkt := make([]byte, mpegts.PacketSize)
+		pkt[0] = 0x47
+		pkt[1] = 0x40 | byte(pid>>8)
+		pkt[2] = byte(pid)
+		pkt[3] = 0x10
+		data = append(data, pkt...)
+	}
+	return data
+}
+
+func TestStreamBroadcaster_PacketAlignment(t *testing.T) {
+	t.Run("buffers whole packets whatever the write size", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
+		ts := tsPackets(0x100, 10)
+		for i := 0; i < 300; i += 100 {
+			_, _ = b.Write(ts[i : i+100])
+		}
+		client := addStalledClient(b, "late-pid")
+		for i := 300; i < len(ts); i += 100 {
+			_, _ = b.Write(ts[i:min(i+100, len(ts))])
+		}
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		var got []byte
+		for {
+			c, ok := client.ring.pop()
+			if !ok {
+				break
+			}
+			if len(c.data)%mpegts.PacketSize != 0 {
+				t.Fatalf("expected whole packets, got a chunk of %d bytes", len(c.data))
+			}
+			got = append(got, c.data...)
+		}
+		if !bytes.Equal(got, ts[mpegts.PacketSize:]) {
+			t.Errorf("expected the client to start at the next packet boundary, got %d bytes", len(got))
+		}
+	})
+
+	t.Run("delivers the tail of a packet cut off by the end of the stream", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
+		client := addStalledClient(b, "client-pid")
+		ts := tsPackets(0x100, 3)
+		_, _ = b.Write(ts[:len(ts)-10])
+		b.Close()
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if client.ring.bytes != len(ts)-10 {
+			t.Errorf("expected %d bytes buffered, got %d", len(ts)-10, client.ring.bytes)
+		}
+	})
+
+	t.Run("holds data back from a joining client until a PAT", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{JoinAtPAT: true})
+		_, _ = b.Write(tsPackets(0x100, 2))
+		client := addStalledClient(b, "client-pid")
+		b.mu.Lock()
+		client.joinedAt, client.awaitingPAT = time.Now(), true
+		b.mu.Unlock()
+
+		_, _ = b.Write(tsPackets(0x100, 2))
+		pat := append(tsPackets(0x100, 1), tsPackets(0, 1)...)
+		_, _ = b.Write(pat)
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		first, ok := client.ring.pop()
+		if !ok || !bytes.Equal(first.data, tsPackets(0, 1)) {
+			t.Errorf("expected the client to start at the PAT, got %d bytes", len(first.data))
+		}
+		if client.ring.count != 0 {
+			t.Errorf("expected nothing else buffered, got %d chunks", client.ring.count)
+		}
+	})
+}
+
+func TestParseClientBufferPolicy(t *testing.T) {
+	for _, name := range []string{"drop-oldest", "disconnect", "pause-upstream"} {
+		if policy, err := ParseClientBufferPolicy(name); err != nil || string(policy) != name {
+			t.Errorf("ParseClientBufferPolicy(%q) = %q, %v", name, policy, err)
+		}
+	}
+	if _, err := ParseClientBufferPolicy("block"); !errors.Is(err, ErrInvalidBufferPolicy) {
+		t.Errorf("expected ErrInvalidBufferPolicy, got %v", err)
+	}
 }
 
 func TestStreamBroadcaster_ConcurrentAccess(t *testing.T) {
 	t.Run("concurrent subscribe unsubscribe and write", func(t *testing.T) {
-		b := newStreamBroadcaster("test-hash", slog.Default())
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
 
 		var wg sync.WaitGroup
 		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
@@ -232,3 +469,157 @@ func TestStreamBroadcaster_ConcurrentAccess(t *testing.T) {
 		b.Close()
 	})
 }
+
+// stallingResponseWriter simulates a client whose connection has stopped
+// draining: every write blocks until the write deadline set through
+// http.ResponseController expires.
+type stallingResponseWriter struct {
+	header   http.Header
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+func (w *stallingResponseWriter) Header() http.Header { return w.header }
+
+func (w *stallingResponseWriter) WriteHeader(int) {}
+
+func (w *stallingResponseWriter) SetWriteDeadline(d time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.deadline = d
+	return nil
+}
+
+func (w *stallingResponseWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	deadline := w.deadline
+	w.mu.Unlock()
+
+	time.Sleep(time.Until(deadline))
+	return 0, os.ErrDeadlineExceeded
+}
+
+func TestStreamBroadcaster_PerClientWriteTimeout(t *testing.T) {
+	t.Run("client with short timeout is dropped before client with long timeout", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
+		defer b.Close()
+
+		type result struct {
+			pid string
+			err error
+			at  time.Time
+		}
+		results := make(chan result, 2)
+
+		subscribe := func(pid string, timeout time.Duration) {
+			w := &stallingResponseWriter{header: make(http.Header)}
+			err := b.Subscribe(context.Background(), pid, w, timeout)
+			results <- result{pid: pid, err: err, at: time.Now()}
+		}
+
+		go subscribe("lan-pid", 50*time.Millisecond)
+		go subscribe("wan-pid", 400*time.Millisecond)
+
+		time.Sleep(20 * time.Millisecond)
+		start := time.Now()
+		if _, err := b.Write([]byte("stalled chunk")); err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+
+		first := <-results
+		second := <-results
+
+		if first.pid != "lan-pid" {
+			t.Fatalf("expected short-timeout client to be dropped first, got %s", first.pid)
+		}
+		for _, r := range []result{first, second} {
+			if !errors.Is(r.err, streaming.ErrWriteTimeout) {
+				t.Errorf("%s: expected ErrWriteTimeout, got %v", r.pid, r.err)
+			}
+		}
+		if elapsed := first.at.Sub(start); elapsed >= 400*time.Millisecond {
+			t.Errorf("short-timeout client took %v to drop, expected well under 400ms", elapsed)
+		}
+		if elapsed := second.at.Sub(start); elapsed < 400*time.Millisecond {
+			t.Errorf("long-timeout client dropped after %v, expected at least 400ms", elapsed)
+		}
+	})
+}
+
+func TestStreamBroadcaster_ReapIdle(t *testing.T) {
+	b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
+	stuck := addStalledClient(b, "stuck-pid")
+	if _, err := b.Write([]byte("pending")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	caughtUp := addStalledClient(b, "caught-up-pid")
+
+	if reaped := b.reapIdle(time.Minute, time.Now()); len(reaped) != 0 {
+		t.Fatalf("expected no client idle yet, got %v", reaped)
+	}
+	reaped := b.reapIdle(time.Minute, time.Now().Add(2*time.Minute))
+	if len(reaped) != 1 || reaped[0] != "stuck-pid" {
+		t.Fatalf("expected only the client with pending data to be reaped, got %v", reaped)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, exists := b.clients["stuck-pid"]; exists || !stuck.closed || !errors.Is(stuck.err, ErrClientIdle) {
+		t.Error("expected the reaped client to be closed and removed")
+	}
+	if _, exists := b.clients["caught-up-pid"]; !exists || caughtUp.closed {
+		t.Error("expected the client with nothing to send to be kept")
+	}
+}
+
+func TestStreamBroadcaster_SendCloseRace(t *testing.T) {
+	t.Run("rapid writes, slow-client drops and closes never panic", func(t *testing.T) {
+		for round := 0; round < 50; round++ {
+			b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{Policy: ClientBufferDisconnect, Size: 4})
+
+			// Clients that never read so their buffers overflow and get dropped
+			// concurrently with Close.
+			for i := 0; i < 10; i++ {
+				addStalledClient(b, fmt.Sprintf("stalled-%d", i))
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 100; j++ {
+						_, _ = b.Write([]byte("data"))
+					}
+				}()
+			}
+
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func(id int) {
+					defer wg.Done()
+					ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+					defer cancel()
+					var buf bytes.Buffer
+					_ = b.Subscribe(ctx, fmt.Sprintf("reader-%d", id), &buf, time.Second)
+				}(i)
+			}
+
+			wg.Add(2)
+			go func() { defer wg.Done(); b.Close() }()
+			go func() { defer wg.Done(); b.CloseWithError(errors.New("engine gone")) }()
+
+			wg.Wait()
+		}
+	})
+
+	t.Run("client close is idempotent", func(t *testing.T) {
+		b := newStreamBroadcaster("test-hash", slog.Default(), ClientBufferOptions{})
+		c := newBroadcastClient("pid-1")
+		c.close()
+		c.close()
+		if b.deliver(c, bufferedChunk{data: []byte("late"), keyframe: -1}, time.Now()) {
+			t.Error("delivering to a closed client should report failure")
+		}
+	})
+}
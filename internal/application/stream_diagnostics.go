@@ -1,6 +1,7 @@
 package application
 
 import (
+	"io"
 	"sync/atomic"
 	"time"
 )
@@ -15,7 +16,12 @@ type streamCounters struct {
 	streamStopFailures    atomic.Int64
 	reconnectionAttempts  atomic.Int64
 	reconnectionSuccesses atomic.Int64
+	upstreamResumes       atomic.Int64
 	clientsServed         atomic.Int64
+	clientsResumed        atomic.Int64
+	clientStalls          atomic.Int64
+	clientsReaped         atomic.Int64
+	bytesStreamed         atomic.Int64
 }
 
 func (c *streamCounters) snapshot() StreamCountersSnapshot {
@@ -26,10 +32,27 @@ func (c *streamCounters) snapshot() StreamCountersSnapshot {
 		StreamStopFailures:    c.streamStopFailures.Load(),
 		ReconnectionAttempts:  c.reconnectionAttempts.Load(),
 		ReconnectionSuccesses: c.reconnectionSuccesses.Load(),
+		UpstreamResumes:       c.upstreamResumes.Load(),
 		ClientsServed:         c.clientsServed.Load(),
+		ClientsResumed:        c.clientsResumed.Load(),
+		ClientStalls:          c.clientStalls.Load(),
+		ClientsReaped:         c.clientsReaped.Load(),
+		BytesStreamed:         c.bytesStreamed.Load(),
 	}
 }
 
+// countingWriter adds the number of bytes written through it to a counter.
+type countingWriter struct {
+	dst   io.Writer
+	count *atomic.Int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.dst.Write(p)
+	w.count.Add(int64(n))
+	return n, err
+}
+
 // StreamDiagnostics is the complete diagnostic snapshot returned by Diagnostics().
 type StreamDiagnostics struct {
 	Uptime   time.Duration          `json:"uptime"`
@@ -46,7 +69,12 @@ type StreamCountersSnapshot struct {
 	StreamStopFailures    int64 `json:"stream_stop_failures"`
 	ReconnectionAttempts  int64 `json:"reconnection_attempts"`
 	ReconnectionSuccesses int64 `json:"reconnection_successes"`
+	UpstreamResumes       int64 `json:"upstream_resumes"`
 	ClientsServed         int64 `json:"clients_served"`
+	ClientsResumed        int64 `json:"clients_resumed"`
+	ClientStalls          int64 `json:"client_stalls"`
+	ClientsReaped         int64 `json:"clients_reaped"`
+	BytesStreamed         int64 `json:"bytes_streamed"`
 }
 
 // LeakedSessions returns the number of sessions that were started but never stopped.
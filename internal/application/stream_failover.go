@@ -0,0 +1,243 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoStreams indicates there are no candidate streams to fail over between.
+	ErrNoStreams = errors.New("no streams available")
+	// ErrAllStreamsFailed indicates every candidate stream failed to start or stalled.
+	ErrAllStreamsFailed = errors.New("all streams failed")
+
+	errStreamStalled     = errors.New("stream stalled before first byte")
+	errStreamEndedEmpty  = errors.New("stream ended before first byte")
+	errFailoverAbandoned = errors.New("stream attempt abandoned by failover")
+)
+
+// FailoverPolicy controls how StreamWithFailover moves between candidate streams.
+type FailoverPolicy struct {
+	// MaxAttempts caps the number of candidates tried per request.
+	// Zero or negative tries every candidate.
+	MaxAttempts int
+	// StallTimeout is how long a candidate may take to deliver its first
+	// byte before the next one is tried. Zero or negative waits indefinitely.
+	StallTimeout time.Duration
+}
+
+// failoverState holds the failover policy and the last stream that worked
+// for each failover key (typically a channel name).
+type failoverState struct {
+	mu      sync.Mutex
+	policy  FailoverPolicy
+	winners map[string]string
+}
+
+// SetFailoverPolicy configures how StreamWithFailover tries candidate streams.
+func (s *AceStreamProxyService) SetFailoverPolicy(policy FailoverPolicy) {
+	s.failover.mu.Lock()
+	defer s.failover.mu.Unlock()
+	s.failover.policy = policy
+}
+
+// LastWorkingStream returns the infohash that most recently served a client
+// for the given failover key.
+func (s *AceStreamProxyService) LastWorkingStream(key string) (string, bool) {
+	s.failover.mu.Lock()
+	defer s.failover.mu.Unlock()
+	infoHash, ok := s.failover.winners[key]
+	return infoHash, ok
+}
+
+// StreamWithFailover streams the first candidate that starts delivering data.
+// Candidates are tried in order, except that the stream which last worked for
+// key is tried first. A candidate is abandoned if it fails to start, ends
+// without data, or delivers nothing within the policy's stall timeout. Once a
+// candidate has delivered data the client stays on it, and the infohash is
+// recorded and returned along with the result of streaming it.
+// Every candidate is streamed with the same opts.
+// Returns ErrAllStreamsFailed, wrapping the last failure, if no candidate works.
+func (s *AceStreamProxyService) StreamWithFailover(ctx context.Context, key string, infoHashes []string, dst io.Writer, opts StreamOptions) (string, error) {
+	if len(infoHashes) == 0 {
+		return "", ErrNoStreams
+	}
+
+	s.failover.mu.Lock()
+	policy := s.failover.policy
+	preferred := s.failover.winners[key]
+	s.failover.mu.Unlock()
+
+	candidates := orderCandidates(infoHashes, preferred)
+	if policy.MaxAttempts > 0 && len(candidates) > policy.MaxAttempts {
+		candidates = candidates[:policy.MaxAttempts]
+	}
+
+	var lastErr error
+	for i, infoHash := range candidates {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		started, err := s.attemptStream(ctx, infoHash, dst, opts, policy.StallTimeout)
+		if started {
+			s.recordFailoverWinner(key, infoHash)
+			if i > 0 {
+				s.logger.Info("failover stream selected", "key", key, "infohash", infoHash, "attempt", i+1)
+				s.events.Publish(EventStreamFailover, StreamFailoverData{Key: key, InfoHash: infoHash, Attempts: i + 1})
+			}
+			return infoHash, err
+		}
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			return "", err
+		}
+
+		lastErr = err
+		s.logger.Warn("stream failed before first byte, trying next candidate",
+			"key", key,
+			"infohash", infoHash,
+			"attempt", i+1,
+			"candidates", len(candidates),
+			"error", err)
+	}
+
+	s.events.Publish(EventStreamFailover, StreamFailoverData{Key: key, Attempts: len(candidates), Error: lastErr.Error()})
+	return "", fmt.Errorf("%w for %s after %d attempts: %w", ErrAllStreamsFailed, key, len(candidates), lastErr)
+}
+
+// attemptStream streams a single candidate and reports whether it delivered
+// any data. If it stalls, it is cancelled and its late writes are discarded so
+// the next candidate owns dst exclusively.
+func (s *AceStreamProxyService) attemptStream(ctx context.Context, infoHash string, dst io.Writer, opts StreamOptions, stallTimeout time.Duration) (bool, error) {
+	fw := newFailoverWriter(dst)
+
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.StreamToClientWithOptions(attemptCtx, infoHash, fw.writer(), opts)
+	}()
+
+	var stall <-chan time.Time
+	if stallTimeout > 0 {
+		timer := time.NewTimer(stallTimeout)
+		defer timer.Stop()
+		stall = timer.C
+	}
+
+	select {
+	case <-fw.started:
+		return true, <-done
+	case err := <-done:
+		if fw.abandon() {
+			if err == nil {
+				err = errStreamEndedEmpty
+			}
+			return false, err
+		}
+		return true, err
+	case <-stall:
+		if !fw.abandon() {
+			return true, <-done
+		}
+		cancel()
+		<-done
+		return false, errStreamStalled
+	}
+}
+
+func (s *AceStreamProxyService) recordFailoverWinner(key, infoHash string) {
+	s.failover.mu.Lock()
+	defer s.failover.mu.Unlock()
+	if s.failover.winners == nil {
+		s.failover.winners = make(map[string]string)
+	}
+	s.failover.winners[key] = infoHash
+}
+
+// orderCandidates returns infoHashes with preferred moved to the front.
+func orderCandidates(infoHashes []string, preferred string) []string {
+	ordered := make([]string, 0, len(infoHashes))
+	for _, h := range infoHashes {
+		if h == preferred {
+			ordered = append(ordered, h)
+		}
+	}
+	for _, h := range infoHashes {
+		if h != preferred {
+			ordered = append(ordered, h)
+		}
+	}
+	return ordered
+}
+
+// failoverWriter forwards writes to dst once a candidate commits by writing
+// its first byte. An abandoned candidate can no longer write.
+type failoverWriter struct {
+	dst       io.Writer
+	started   chan struct{}
+	mu        sync.Mutex
+	committed bool
+	abandoned bool
+}
+
+func newFailoverWriter(dst io.Writer) *failoverWriter {
+	return &failoverWriter{dst: dst, started: make(chan struct{})}
+}
+
+func (w *failoverWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	if w.abandoned {
+		w.mu.Unlock()
+		return 0, errFailoverAbandoned
+	}
+	if !w.committed {
+		w.committed = true
+		close(w.started)
+	}
+	w.mu.Unlock()
+
+	return w.dst.Write(p)
+}
+
+// abandon stops further writes unless the candidate has already committed.
+// It reports whether the candidate was abandoned.
+func (w *failoverWriter) abandon() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.committed {
+		return false
+	}
+	w.abandoned = true
+	return true
+}
+
+// writer returns w, preserving the http.ResponseWriter interface of dst so
+// write deadlines and flushing keep working through the wrapper.
+func (w *failoverWriter) writer() io.Writer {
+	if rw, ok := w.dst.(http.ResponseWriter); ok {
+		return &failoverResponseWriter{failoverWriter: w, rw: rw}
+	}
+	return w
+}
+
+type failoverResponseWriter struct {
+	*failoverWriter
+	rw http.ResponseWriter
+}
+
+func (w *failoverResponseWriter) Header() http.Header         { return w.rw.Header() }
+func (w *failoverResponseWriter) WriteHeader(statusCode int)  { w.rw.WriteHeader(statusCode) }
+func (w *failoverResponseWriter) Unwrap() http.ResponseWriter { return w.rw }
+
+func (w *failoverResponseWriter) Flush() {
+	if f, ok := w.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
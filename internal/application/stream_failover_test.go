@@ -0,0 +1,163 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newFailoverTestEngine returns an engine mock whose per-infohash behaviour
+// is driven by content: a missing entry fails to start, a nil entry starts
+// but never delivers data, and any other entry is streamed once.
+func newFailoverTestEngine(content map[string][]byte) (*mockAceStreamEngine, func() []string) {
+	var mu sync.Mutex
+	var started []string
+
+	engine := &mockAceStreamEngine{
+		startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+			mu.Lock()
+			started = append(started, infoHash)
+			mu.Unlock()
+			if _, ok := content[infoHash]; !ok {
+				return "", errors.New("no peers")
+			}
+			return "http://engine/" + infoHash, nil
+		},
+		streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+			data := content[infoHash]
+			if data == nil {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			// Give the subscriber time to attach to the broadcaster
+			time.Sleep(20 * time.Millisecond)
+			_, err := dst.Write(data)
+			return err
+		},
+		stopStreamFunc: func(ctx context.Context, pid string) error { return nil },
+	}
+
+	return engine, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), started...)
+	}
+}
+
+func TestAceStreamProxyService_StreamWithFailover(t *testing.T) {
+	t.Run("falls over to next stream when first fails to start", func(t *testing.T) {
+		engine, _ := newFailoverTestEngine(map[string][]byte{"good": []byte("good stream")})
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+		var buf bytes.Buffer
+		infoHash, err := service.StreamWithFailover(context.Background(), "Channel1", []string{"broken", "good"}, &buf, StreamOptions{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if infoHash != "good" {
+			t.Errorf("expected 'good' to be selected, got %q", infoHash)
+		}
+		if buf.String() != "good stream" {
+			t.Errorf("expected content from working stream, got %q", buf.String())
+		}
+		if winner, ok := service.LastWorkingStream("Channel1"); !ok || winner != "good" {
+			t.Errorf("expected 'good' recorded as last working stream, got %q (%v)", winner, ok)
+		}
+	})
+
+	t.Run("falls over when a stream stalls before its first byte", func(t *testing.T) {
+		engine, _ := newFailoverTestEngine(map[string][]byte{"stalled": nil, "good": []byte("good stream")})
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		service.SetFailoverPolicy(FailoverPolicy{StallTimeout: 100 * time.Millisecond})
+
+		var buf bytes.Buffer
+		start := time.Now()
+		infoHash, err := service.StreamWithFailover(context.Background(), "Channel1", []string{"stalled", "good"}, &buf, StreamOptions{})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if infoHash != "good" {
+			t.Errorf("expected 'good' to be selected, got %q", infoHash)
+		}
+		if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+			t.Errorf("expected failover to wait for the stall timeout, took %v", elapsed)
+		}
+		if buf.String() != "good stream" {
+			t.Errorf("expected only content from working stream, got %q", buf.String())
+		}
+		if n := len(service.GetActiveStreams()); n != 0 {
+			t.Errorf("expected stalled session to be cleaned up, got %d active", n)
+		}
+	})
+
+	t.Run("tries the last working stream first", func(t *testing.T) {
+		engine, started := newFailoverTestEngine(map[string][]byte{"good": []byte("good stream")})
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+		var buf bytes.Buffer
+		if _, err := service.StreamWithFailover(context.Background(), "Channel1", []string{"broken", "good"}, &buf, StreamOptions{}); err != nil {
+			t.Fatalf("first request: unexpected error %v", err)
+		}
+		if _, err := service.StreamWithFailover(context.Background(), "Channel1", []string{"broken", "good"}, &buf, StreamOptions{}); err != nil {
+			t.Fatalf("second request: unexpected error %v", err)
+		}
+
+		want := []string{"broken", "good", "good"}
+		got := started()
+		if len(got) != len(want) {
+			t.Fatalf("expected engine starts %v, got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("expected engine starts %v, got %v", want, got)
+				break
+			}
+		}
+	})
+
+	t.Run("respects max attempts", func(t *testing.T) {
+		engine, started := newFailoverTestEngine(map[string][]byte{"good": []byte("good stream")})
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		service.SetFailoverPolicy(FailoverPolicy{MaxAttempts: 2})
+
+		var buf bytes.Buffer
+		_, err := service.StreamWithFailover(context.Background(), "Channel1", []string{"broken1", "broken2", "good"}, &buf, StreamOptions{})
+		if !errors.Is(err, ErrAllStreamsFailed) {
+			t.Fatalf("expected ErrAllStreamsFailed, got %v", err)
+		}
+		if n := len(started()); n != 2 {
+			t.Errorf("expected 2 engine starts, got %d", n)
+		}
+	})
+
+	t.Run("returns error when every stream fails", func(t *testing.T) {
+		engine, _ := newFailoverTestEngine(map[string][]byte{})
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+		var buf bytes.Buffer
+		infoHash, err := service.StreamWithFailover(context.Background(), "Channel1", []string{"a", "b"}, &buf, StreamOptions{})
+		if !errors.Is(err, ErrAllStreamsFailed) {
+			t.Fatalf("expected ErrAllStreamsFailed, got %v", err)
+		}
+		if infoHash != "" {
+			t.Errorf("expected no infohash, got %q", infoHash)
+		}
+		if _, ok := service.LastWorkingStream("Channel1"); ok {
+			t.Error("expected no last working stream to be recorded")
+		}
+	})
+
+	t.Run("returns ErrNoStreams without candidates", func(t *testing.T) {
+		service := NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, nil)
+
+		var buf bytes.Buffer
+		if _, err := service.StreamWithFailover(context.Background(), "Channel1", nil, &buf, StreamOptions{}); !errors.Is(err, ErrNoStreams) {
+			t.Fatalf("expected ErrNoStreams, got %v", err)
+		}
+	})
+}
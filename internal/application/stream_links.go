@@ -0,0 +1,131 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// ErrStreamLinkRequired indicates an unsigned stream link while signed links
+// are required.
+var ErrStreamLinkRequired = errors.New("signed stream link required")
+
+// StreamLinks signs the stream links of playlists so a playlist can be
+// shared without granting lasting access to the proxy: each link expires,
+// and a link generated for a request authenticated with an API token is
+// signed with a key of that token and stops working once it is revoked.
+type StreamLinks struct {
+	signer    *auth.LinkSigner
+	ttl       time.Duration
+	required  bool
+	tokenRepo driven.TokenRepository
+	now       func() time.Time
+}
+
+// NewStreamLinks creates stream links valid for ttl, signed with signer.
+// With required set, unsigned links are refused; otherwise they keep
+// working and only signed links are checked.
+func NewStreamLinks(signer *auth.LinkSigner, ttl time.Duration, required bool, tokenRepo driven.TokenRepository) *StreamLinks {
+	return &StreamLinks{
+		signer:    signer,
+		ttl:       ttl,
+		required:  required,
+		tokenRepo: tokenRepo,
+		now:       time.Now,
+	}
+}
+
+// expiry returns when links signed now expire. The time is rounded down to
+// a quarter of the TTL first, so a playlist fetched again within the same
+// quarter gets the same links and ETag; links then stay valid for at least
+// three quarters of the TTL.
+func (l *StreamLinks) expiry() time.Time {
+	now := l.now()
+	if step := l.ttl / 4; step > 0 {
+		now = now.Truncate(step)
+	}
+	return now.Add(l.ttl)
+}
+
+// streamResource, aliasResource, channelResource and catchupResource name
+// what a link grants access to: a stream by infohash, a channel by alias or
+// by name, or the recordings of a channel by name.
+func streamResource(infoHash string) string     { return "stream:" + infoHash }
+func aliasResource(alias string) string         { return "alias:" + alias }
+func channelResource(channelName string) string { return "channel:" + channelName }
+func catchupResource(channelName string) string { return "catchup:" + channelName }
+
+// sign appends the signature of resource to rawURL, scoped to the API token
+// the request in ctx was authenticated with, if any.
+func (l *StreamLinks) sign(ctx context.Context, rawURL, resource string) string {
+	sig := l.signer.Sign(resource, APITokenFromContext(ctx), l.expiry())
+	sep := "?"
+	if u, err := url.Parse(rawURL); err == nil && u.RawQuery != "" {
+		sep = "&"
+	}
+	return rawURL + sep + sig.Query().Encode()
+}
+
+// VerifyStream checks the signature in the query of a link to the stream
+// with infoHash. See verify.
+func (l *StreamLinks) VerifyStream(ctx context.Context, infoHash string, query url.Values) error {
+	return l.verify(ctx, streamResource(infoHash), query)
+}
+
+// VerifyAlias checks the signature in the query of a link to the channel
+// with alias. See verify.
+func (l *StreamLinks) VerifyAlias(ctx context.Context, alias string, query url.Values) error {
+	return l.verify(ctx, aliasResource(alias), query)
+}
+
+// VerifyChannel checks the signature in the query of a link to the channel
+// named channelName. See verify.
+func (l *StreamLinks) VerifyChannel(ctx context.Context, channelName string, query url.Values) error {
+	return l.verify(ctx, channelResource(channelName), query)
+}
+
+// VerifyCatchup checks the signature in the query of a catchup link to the
+// recordings of the channel named channelName. See verify.
+func (l *StreamLinks) VerifyCatchup(ctx context.Context, channelName string, query url.Values) error {
+	return l.verify(ctx, catchupResource(channelName), query)
+}
+
+// verify checks the signature in query for resource.
+// Returns ErrStreamLinkRequired for an unsigned link if signed links are
+// required, auth.ErrInvalidLinkSignature if the signature is not valid for
+// resource or its API token was revoked, and auth.ErrLinkExpired if it is
+// past its expiry.
+func (l *StreamLinks) verify(ctx context.Context, resource string, query url.Values) error {
+	sig, signed, err := auth.ParseLinkSignature(query)
+	if err != nil {
+		return err
+	}
+	if !signed {
+		if l.required {
+			return ErrStreamLinkRequired
+		}
+		return nil
+	}
+	if err := l.signer.Verify(resource, sig, l.now()); err != nil {
+		return err
+	}
+	if sig.TokenID == "" {
+		return nil
+	}
+
+	tokens, err := l.tokenRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to look up token: %w", err)
+	}
+	for _, tok := range tokens {
+		if tok.ID() == sig.TokenID {
+			return nil
+		}
+	}
+	return auth.ErrInvalidLinkSignature
+}
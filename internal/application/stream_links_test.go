@@ -0,0 +1,136 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/auth"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+const testLinkHash = "6162633132330000000000000000000000000000"
+
+func newTestStreamLinks(required bool, repo *mockTokenRepository) *StreamLinks {
+	signer := auth.NewLinkSigner([]auth.LinkKey{{ID: "k1", Secret: []byte("link-secret")}})
+	return NewStreamLinks(signer, time.Hour, required, repo)
+}
+
+// signedQuery returns the query of the link to the stream with infoHash in
+// the playlist generated by p with ctx.
+func signedQuery(t *testing.T, p *PlaylistService, ctx context.Context, infoHash string) url.Values {
+	t.Helper()
+	m3u, err := p.GenerateM3U(ctx, "http://localhost:8080")
+	if err != nil {
+		t.Fatalf("GenerateM3U() error = %v", err)
+	}
+	link := regexp.MustCompile(`http://localhost:8080/ace/getstream\?id=` + infoHash + `\S*`).FindString(m3u)
+	if link == "" {
+		t.Fatalf("no link to %s in playlist:\n%s", infoHash, m3u)
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) error = %v", link, err)
+	}
+	return u.Query()
+}
+
+func TestStreamLinks(t *testing.T) {
+	st, _ := stream.NewStream(testLinkHash, "Channel1", "")
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{st}, nil
+		},
+	}
+	newPlaylist := func(links *StreamLinks) *PlaylistService {
+		p := NewPlaylistService(streamRepo, &mockChannelRepository{}, &mockProbeRepository{}, 24*time.Hour)
+		p.SetStreamLinks(links)
+		return p
+	}
+	ctx := context.Background()
+
+	t.Run("signs playlist links that verify for their stream only", func(t *testing.T) {
+		links := newTestStreamLinks(false, newMockTokenRepository())
+		q := signedQuery(t, newPlaylist(links), ctx, testLinkHash)
+		if q.Get("sig") == "" || q.Get("exp") == "" || q.Get("kid") != "k1" {
+			t.Fatalf("playlist link not signed: %v", q)
+		}
+
+		if err := links.VerifyStream(ctx, testLinkHash, q); err != nil {
+			t.Errorf("VerifyStream() error = %v", err)
+		}
+		if err := links.VerifyStream(ctx, "6465663435360000000000000000000000000000", q); !errors.Is(err, auth.ErrInvalidLinkSignature) {
+			t.Errorf("other stream: expected ErrInvalidLinkSignature, got %v", err)
+		}
+		if err := links.VerifyAlias(ctx, testLinkHash, q); !errors.Is(err, auth.ErrInvalidLinkSignature) {
+			t.Errorf("alias: expected ErrInvalidLinkSignature, got %v", err)
+		}
+	})
+
+	t.Run("rejects expired links", func(t *testing.T) {
+		links := newTestStreamLinks(false, newMockTokenRepository())
+		q := signedQuery(t, newPlaylist(links), ctx, testLinkHash)
+		links.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		if err := links.VerifyStream(ctx, testLinkHash, q); !errors.Is(err, auth.ErrLinkExpired) {
+			t.Errorf("expected ErrLinkExpired, got %v", err)
+		}
+	})
+
+	t.Run("keeps links within a quarter of the TTL", func(t *testing.T) {
+		links := newTestStreamLinks(false, newMockTokenRepository())
+		p := newPlaylist(links)
+		at := func(hhmm string) url.Values {
+			now, _ := time.Parse("15:04", hhmm)
+			links.now = func() time.Time { return now }
+			return signedQuery(t, p, ctx, testLinkHash)
+		}
+
+		first := at("12:00")
+		if got := at("12:14"); got.Encode() != first.Encode() {
+			t.Errorf("expected the same link within the quarter, got %v and %v", first, got)
+		}
+		later := at("12:16")
+		if later.Encode() == first.Encode() {
+			t.Error("expected a new link in the next quarter")
+		}
+		if err := links.VerifyStream(ctx, testLinkHash, later); err != nil {
+			t.Errorf("VerifyStream() error = %v", err)
+		}
+	})
+
+	t.Run("accepts unsigned links unless required", func(t *testing.T) {
+		unsigned := url.Values{"id": {testLinkHash}}
+		if err := newTestStreamLinks(false, newMockTokenRepository()).VerifyStream(ctx, testLinkHash, unsigned); err != nil {
+			t.Errorf("optional: VerifyStream() error = %v", err)
+		}
+		if err := newTestStreamLinks(true, newMockTokenRepository()).VerifyStream(ctx, testLinkHash, unsigned); !errors.Is(err, ErrStreamLinkRequired) {
+			t.Errorf("required: expected ErrStreamLinkRequired, got %v", err)
+		}
+	})
+
+	t.Run("scopes links to the API token until it is revoked", func(t *testing.T) {
+		repo := newMockTokenRepository()
+		token, _, err := auth.NewToken("tv", time.Now())
+		if err != nil {
+			t.Fatalf("NewToken() error = %v", err)
+		}
+		repo.Save(ctx, token)
+		links := newTestStreamLinks(true, repo)
+
+		q := signedQuery(t, newPlaylist(links), WithAPIToken(ctx, token.ID()), testLinkHash)
+		if q.Get("tok") != token.ID() {
+			t.Fatalf("link tok = %q, want %q", q.Get("tok"), token.ID())
+		}
+		if err := links.VerifyStream(ctx, testLinkHash, q); err != nil {
+			t.Errorf("VerifyStream() error = %v", err)
+		}
+
+		repo.Delete(ctx, token.ID())
+		if err := links.VerifyStream(ctx, testLinkHash, q); !errors.Is(err, auth.ErrInvalidLinkSignature) {
+			t.Errorf("revoked token: expected ErrInvalidLinkSignature, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package application
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
+)
+
+// defaultPrebufferMaxWait bounds how long a prebuffer holds data back when
+// no maximum is configured, for streams whose clock cannot be read.
+const defaultPrebufferMaxWait = 10 * time.Second
+
+// prebufferReportInterval is the least time between two progress events of
+// the same prebuffer.
+const prebufferReportInterval = 250 * time.Millisecond
+
+// PrebufferOptions configures how much of a new engine stream is held back
+// before clients receive the first byte, so players start with a full
+// buffer instead of stuttering while the engine warms up. The prebuffer is
+// complete once either threshold is reached.
+type PrebufferOptions struct {
+	// Duration is the stream time to buffer, measured by the program
+	// clock references in the stream. Zero leaves it off.
+	Duration time.Duration
+	// Bytes is the amount of data to buffer. Zero leaves it off.
+	Bytes int64
+	// MaxWait releases the data held back after this long from the first
+	// byte even if no threshold is reached. Zero or negative uses a 10s
+	// default.
+	MaxWait time.Duration
+}
+
+// enabled reports whether a threshold is set.
+func (o PrebufferOptions) enabled() bool {
+	return o.Duration > 0 || o.Bytes > 0
+}
+
+// SetPrebuffer configures the prebuffer of engine streams started afterwards.
+// A zero PrebufferOptions disables it.
+func (s *AceStreamProxyService) SetPrebuffer(opts PrebufferOptions) {
+	if opts.MaxWait <= 0 {
+		opts.MaxWait = defaultPrebufferMaxWait
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prebuffer = opts
+}
+
+// Prebuffer returns the current prebuffer settings.
+func (s *AceStreamProxyService) Prebuffer() PrebufferOptions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.prebuffer
+}
+
+// prebufferWriter holds the start of an engine stream back from dst until the
+// prebuffer is complete, then passes writes straight through. Progress is
+// reported through report as data comes in.
+type prebufferWriter struct {
+	dst      io.Writer
+	opts     PrebufferOptions
+	report   func(StreamPrebufferData)
+	analyzer *mpegts.Analyzer
+
+	mu         sync.Mutex
+	buf        []byte
+	done       bool
+	err        error // error of writing the held back data to dst
+	timer      *time.Timer
+	lastReport time.Time
+}
+
+func newPrebufferWriter(dst io.Writer, opts PrebufferOptions, report func(StreamPrebufferData)) *prebufferWriter {
+	return &prebufferWriter{
+		dst:      dst,
+		opts:     opts,
+		report:   report,
+		analyzer: mpegts.NewAnalyzer(),
+	}
+}
+
+// Write implements io.Writer.
+func (w *prebufferWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.done {
+		if w.err != nil {
+			return 0, w.err
+		}
+		return w.dst.Write(p)
+	}
+
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.opts.MaxWait, w.Release)
+	}
+	w.buf = append(w.buf, p...)
+	_, _ = w.analyzer.Write(p)
+
+	progress := w.progress()
+	if progress.Percent >= 100 {
+		w.release()
+		return len(p), w.err
+	}
+	if now := time.Now(); now.Sub(w.lastReport) >= prebufferReportInterval {
+		w.lastReport = now
+		w.report(progress)
+	}
+	return len(p), nil
+}
+
+// Release writes the data held back to dst, whether or not the prebuffer is
+// complete. It is called once MaxWait passes and when the engine stream ends.
+func (w *prebufferWriter) Release() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.release()
+	}
+}
+
+// release ends the prebuffer. The caller must hold w.mu.
+func (w *prebufferWriter) release() {
+	w.done = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+
+	progress := w.progress()
+	progress.Phase = "ready"
+	w.report(progress)
+
+	if len(w.buf) > 0 {
+		_, w.err = w.dst.Write(w.buf)
+	}
+	w.buf = nil
+}
+
+// progress returns how far the prebuffer has come. The caller must hold w.mu.
+func (w *prebufferWriter) progress() StreamPrebufferData {
+	buffered := w.analyzer.Info().Duration
+	data := StreamPrebufferData{
+		Phase:      "buffering",
+		Bytes:      int64(len(w.buf)),
+		BufferedMs: buffered.Milliseconds(),
+	}
+	if w.opts.Bytes > 0 {
+		data.Percent = max(data.Percent, int(data.Bytes*100/w.opts.Bytes))
+	}
+	if w.opts.Duration > 0 {
+		data.Percent = max(data.Percent, int(buffered*100/w.opts.Duration))
+	}
+	data.Percent = min(data.Percent, 100)
+	return data
+}
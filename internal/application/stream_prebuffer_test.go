@@ -0,0 +1,138 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer safe to write from a timer goroutine.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Len()
+}
+
+func TestPrebufferWriter(t *testing.T) {
+	t.Run("holds data back until enough stream time is buffered", func(t *testing.T) {
+		var dst lockedBuffer
+		var reports []StreamPrebufferData
+		w := newPrebufferWriter(&dst, PrebufferOptions{Duration: 2 * time.Second, MaxWait: time.Minute}, func(data StreamPrebufferData) {
+			reports = append(reports, data)
+		})
+
+		packets := audioOnlyTS()
+		for _, pkt := range packets[:len(packets)-1] {
+			_, _ = w.Write(pkt)
+		}
+		if dst.Len() != 0 {
+			t.Fatalf("expected nothing written before 2s are buffered, got %d bytes", dst.Len())
+		}
+
+		_, _ = w.Write(packets[len(packets)-1])
+		if want := len(packets) * len(packets[0]); dst.Len() != want {
+			t.Fatalf("expected the buffered %d bytes, got %d", want, dst.Len())
+		}
+		last := reports[len(reports)-1]
+		if last.Phase != "ready" || last.Percent != 100 || last.BufferedMs != 2000 {
+			t.Errorf("unexpected final report %+v", last)
+		}
+		if reports[0].Phase != "buffering" || reports[0].Percent != 0 {
+			t.Errorf("unexpected first report %+v", reports[0])
+		}
+	})
+
+	t.Run("releases data once enough bytes are buffered", func(t *testing.T) {
+		var dst lockedBuffer
+		w := newPrebufferWriter(&dst, PrebufferOptions{Bytes: 1000, MaxWait: time.Minute}, func(StreamPrebufferData) {})
+
+		_, _ = w.Write(make([]byte, 600))
+		if dst.Len() != 0 {
+			t.Fatalf("expected nothing written yet, got %d bytes", dst.Len())
+		}
+		_, _ = w.Write(make([]byte, 600))
+		_, _ = w.Write(make([]byte, 10))
+		if dst.Len() != 1210 {
+			t.Errorf("expected all 1210 bytes written through, got %d", dst.Len())
+		}
+	})
+
+	t.Run("releases data after the maximum wait", func(t *testing.T) {
+		var dst lockedBuffer
+		w := newPrebufferWriter(&dst, PrebufferOptions{Bytes: 1 << 20, MaxWait: 20 * time.Millisecond}, func(StreamPrebufferData) {})
+
+		_, _ = w.Write(make([]byte, 10))
+		deadline := time.Now().Add(time.Second)
+		for dst.Len() == 0 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if dst.Len() != 10 {
+			t.Errorf("expected the buffered bytes after the maximum wait, got %d", dst.Len())
+		}
+	})
+}
+
+func TestAceStreamProxyService_Prebuffer(t *testing.T) {
+	t.Run("defaults the maximum wait", func(t *testing.T) {
+		service := NewAceStreamProxyService(&mockAceStreamEngine{}, newTestLogger(), time.Second, nil)
+		service.SetPrebuffer(PrebufferOptions{Bytes: 1024})
+
+		if got := service.Prebuffer(); got.Bytes != 1024 || got.MaxWait != defaultPrebufferMaxWait {
+			t.Errorf("unexpected prebuffer %+v", got)
+		}
+	})
+
+	t.Run("delivers data held back when the stream ends", func(t *testing.T) {
+		engine := &mockAceStreamEngine{
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				_, err := dst.Write(make([]byte, 600))
+				return err
+			},
+		}
+		events := NewEventBus()
+		defer events.Close()
+		received, unsubscribe := events.Subscribe()
+		defer unsubscribe()
+		service := NewAceStreamProxyService(engine, newTestLogger(), time.Second, nil)
+		service.SetEventBus(events)
+		service.SetPrebuffer(PrebufferOptions{Bytes: 1 << 20})
+
+		var dst lockedBuffer
+		if err := service.StreamToClient(context.Background(), "abc123", &dst); err != nil {
+			t.Fatalf("StreamToClient() error = %v", err)
+		}
+		if dst.Len() != 600 {
+			t.Errorf("expected 600 bytes, got %d", dst.Len())
+		}
+
+		for {
+			select {
+			case event := <-received:
+				data, ok := event.Data.(StreamPrebufferData)
+				if event.Type != EventStreamPrebuffer || data.Phase != "ready" {
+					continue
+				}
+				if !ok || data.InfoHash != "abc123" || data.Bytes != 600 {
+					t.Errorf("unexpected ready event %+v", event.Data)
+				}
+				return
+			case <-time.After(time.Second):
+				t.Fatal("expected a stream.prebuffer ready event")
+			}
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// ErrInvalidStreamQueue indicates stream queue settings out of range.
+var ErrInvalidStreamQueue = errors.New("invalid stream queue settings")
+
+// streamQueueRetry is how often a queued request tries again when no stream
+// has ended, as the engine may have sessions this proxy did not start.
+const streamQueueRetry = time.Second
+
+// StreamQueueOptions configures how requests for a new engine stream wait
+// while the engine is saturated, that is while the maximum number of engine
+// streams is running or the engine reports too many active sessions.
+// Waiting requests try again whenever a stream ends.
+type StreamQueueOptions struct {
+	// MaxWait is how long a request waits before it fails with
+	// ErrStreamLimitReached. Zero fails at once.
+	MaxWait time.Duration
+	// MaxDepth is the most requests waiting at once; requests beyond it
+	// fail at once. Zero or negative is unlimited.
+	MaxDepth int
+}
+
+// Validate checks the options are in range.
+// Returns ErrInvalidStreamQueue if MaxWait is negative.
+func (o StreamQueueOptions) Validate() error {
+	if o.MaxWait < 0 {
+		return ErrInvalidStreamQueue
+	}
+	return nil
+}
+
+// StreamQueueStats is a point-in-time view of the stream queue.
+type StreamQueueStats struct {
+	// Depth is the number of requests waiting now.
+	Depth int
+	// Queued counts the requests that had to wait, and Rejected those that
+	// failed because the queue was full or they waited too long.
+	Queued   int64
+	Rejected int64
+}
+
+// streamQueue holds back requests for a new engine stream while the engine
+// is saturated.
+type streamQueue struct {
+	mu       sync.Mutex
+	opts     StreamQueueOptions
+	depth    int
+	queued   atomic.Int64
+	rejected atomic.Int64
+}
+
+// queueTicket tracks the wait of a single request.
+type queueTicket struct {
+	deadline time.Time
+	waiting  bool
+}
+
+// wait blocks until freed is closed, the retry interval passes or ctx ends,
+// counting the request as queued on its first wait. Returns
+// ErrStreamLimitReached if the queue is full or the request has waited for
+// MaxWait, and ctx.Err() if ctx ends.
+func (q *streamQueue) wait(ctx context.Context, t *queueTicket, freed <-chan struct{}) error {
+	q.mu.Lock()
+	if !t.waiting {
+		if q.opts.MaxWait <= 0 || (q.opts.MaxDepth > 0 && q.depth >= q.opts.MaxDepth) {
+			q.mu.Unlock()
+			q.rejected.Add(1)
+			return ErrStreamLimitReached
+		}
+		q.depth++
+		q.queued.Add(1)
+		t.waiting = true
+		t.deadline = time.Now().Add(q.opts.MaxWait)
+	}
+	q.mu.Unlock()
+
+	remaining := time.Until(t.deadline)
+	if remaining <= 0 {
+		q.rejected.Add(1)
+		return ErrStreamLimitReached
+	}
+	timer := time.NewTimer(min(remaining, streamQueueRetry))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-freed:
+	case <-timer.C:
+	}
+	return nil
+}
+
+// leave takes a request that waited off the queue.
+func (q *streamQueue) leave(t *queueTicket) {
+	if !t.waiting {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.depth--
+	t.waiting = false
+}
+
+// SetStreamQueue configures how requests for a new engine stream wait while
+// the engine is saturated. It may be called while requests are waiting;
+// they keep the deadline they started with.
+// Returns ErrInvalidStreamQueue if the options are out of range.
+func (s *AceStreamProxyService) SetStreamQueue(opts StreamQueueOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	s.queue.mu.Lock()
+	defer s.queue.mu.Unlock()
+	s.queue.opts = opts
+	return nil
+}
+
+// StreamQueue returns the current stream queue settings.
+func (s *AceStreamProxyService) StreamQueue() StreamQueueOptions {
+	s.queue.mu.Lock()
+	defer s.queue.mu.Unlock()
+	return s.queue.opts
+}
+
+// StreamQueueStats returns the current depth of the stream queue and how
+// many requests it has held back and rejected.
+func (s *AceStreamProxyService) StreamQueueStats() StreamQueueStats {
+	s.queue.mu.Lock()
+	depth := s.queue.depth
+	s.queue.mu.Unlock()
+	return StreamQueueStats{
+		Depth:    depth,
+		Queued:   s.queue.queued.Load(),
+		Rejected: s.queue.rejected.Load(),
+	}
+}
+
+// joinSession registers the client pid with the session for key, starting
+// the engine stream if the session is new. While the engine is saturated the
+// request waits in the stream queue and tries again.
+func (s *AceStreamProxyService) joinSession(ctx context.Context, key, infoHash string, engineOpts driven.StreamOptions, video channel.VideoTranscode, pid string) (*streamSession, error) {
+	var ticket queueTicket
+	defer s.queue.leave(&ticket)
+
+	for {
+		// Taken before trying, so a stream ending meanwhile is not missed
+		freed := s.sessions.slotFreed()
+
+		session, isNew, err := s.sessions.AddClient(key, infoHash, engineOpts, video, pid, s.hotPathLogger())
+		if errors.Is(err, ErrStreamLimitReached) {
+			if err = s.queue.wait(ctx, &ticket, freed); err == nil {
+				continue
+			}
+		}
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed to register client", "infohash", infoHash, "pid", pid, "error", err)
+			return nil, fmt.Errorf("failed to register client: %w", err)
+		}
+
+		// If this is a new session, start the engine stream and the broadcast pump
+		if isNew {
+			s.logger.InfoContext(ctx, "creating new stream session", "infohash", infoHash, "pid", pid)
+			if err := s.startEngineStream(ctx, session); err != nil {
+				s.sessions.RemoveClient(key, pid)
+				if errors.Is(err, driven.ErrEngineBusy) {
+					if err = s.queue.wait(ctx, &ticket, freed); err == nil {
+						continue
+					}
+				}
+				s.logger.ErrorContext(ctx, "failed to start engine stream", "infohash", infoHash, "pid", pid, "error", err)
+				return nil, fmt.Errorf("failed to start engine stream: %w", err)
+			}
+
+			// The engine stream outlives this client but keeps its context
+			// values, so engine logs stay correlated with the request that
+			// started it
+			engineCtx, engineCancel := context.WithCancel(context.WithoutCancel(ctx))
+			session.SetEngineCancel(engineCancel)
+			go s.pumpEngineToSession(engineCtx, session)
+			return session, nil
+		}
+
+		s.logger.DebugContext(ctx, "joining existing stream session", "infohash", infoHash, "pid", pid)
+		// Wait for the stream to be ready if another client is starting it
+		if err := s.waitForStreamReady(ctx, session); err != nil {
+			s.sessions.RemoveClient(key, pid)
+			if errors.Is(err, driven.ErrEngineBusy) {
+				if err = s.queue.wait(ctx, &ticket, freed); err == nil {
+					continue
+				}
+			}
+			s.logger.ErrorContext(ctx, "stream not ready", "infohash", infoHash, "pid", pid, "error", err)
+			return nil, err
+		}
+		return session, nil
+	}
+}
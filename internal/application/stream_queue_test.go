@@ -0,0 +1,130 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// waitForQueueDepth waits until n requests are waiting in the stream queue.
+func waitForQueueDepth(t *testing.T, service *AceStreamProxyService, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if service.StreamQueueStats().Depth == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("expected %d queued requests, got %d", n, service.StreamQueueStats().Depth)
+}
+
+func TestAceStreamProxyService_StreamQueue(t *testing.T) {
+	t.Run("starts a queued stream once another one ends", func(t *testing.T) {
+		engine, _ := blockingEngine("dl")
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		service.SetMaxEngineStreams(1)
+		if err := service.SetStreamQueue(StreamQueueOptions{MaxWait: 5 * time.Second}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		firstCtx, cancelFirst := context.WithCancel(context.Background())
+		go func() {
+			_ = service.StreamToClient(firstCtx, "infohash-1", io.Discard)
+		}()
+		waitForClientSessions(t, service, 1)
+
+		secondCtx, cancelSecond := context.WithCancel(context.Background())
+		defer cancelSecond()
+		go func() {
+			_ = service.StreamToClient(secondCtx, "infohash-2", io.Discard)
+		}()
+		waitForQueueDepth(t, service, 1)
+
+		cancelFirst()
+		waitForQueueDepth(t, service, 0)
+		deadline := time.Now().Add(time.Second)
+		for !service.IsStreamActive("infohash-2") && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if !service.IsStreamActive("infohash-2") {
+			t.Fatal("expected the queued stream to start")
+		}
+		if stats := service.StreamQueueStats(); stats.Queued != 1 || stats.Rejected != 0 {
+			t.Errorf("expected 1 queued and no rejected requests, got %+v", stats)
+		}
+	})
+
+	t.Run("rejects requests beyond the wait or depth", func(t *testing.T) {
+		engine, _ := blockingEngine("dl")
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		service.SetMaxEngineStreams(1)
+		_ = service.SetStreamQueue(StreamQueueOptions{MaxWait: 50 * time.Millisecond, MaxDepth: 1})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = service.StreamToClient(ctx, "infohash-1", io.Discard)
+		}()
+		waitForClientSessions(t, service, 1)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- service.StreamToClient(context.Background(), "infohash-2", io.Discard)
+		}()
+		waitForQueueDepth(t, service, 1)
+
+		if err := service.StreamToClient(context.Background(), "infohash-3", io.Discard); !errors.Is(err, ErrStreamLimitReached) {
+			t.Errorf("expected a full queue to reject at once, got %v", err)
+		}
+		if err := <-done; !errors.Is(err, ErrStreamLimitReached) {
+			t.Errorf("expected ErrStreamLimitReached after the wait, got %v", err)
+		}
+		if stats := service.StreamQueueStats(); stats.Depth != 0 || stats.Rejected != 2 {
+			t.Errorf("expected an empty queue and 2 rejected requests, got %+v", stats)
+		}
+	})
+
+	t.Run("retries while the engine is busy", func(t *testing.T) {
+		engine, _ := blockingEngine("dl")
+		var starts atomic.Int32
+		engine.startStreamFunc = func(ctx context.Context, infoHash, pid string) (string, error) {
+			if starts.Add(1) == 1 {
+				return "", driven.ErrEngineBusy
+			}
+			return "http://engine/stream", nil
+		}
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+		_ = service.SetStreamQueue(StreamQueueOptions{MaxWait: 5 * time.Second})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = service.StreamToClient(ctx, "infohash-1", io.Discard)
+		}()
+
+		deadline := time.Now().Add(3 * time.Second)
+		for !service.IsStreamActive("infohash-1") && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if starts.Load() != 2 || !service.IsStreamActive("infohash-1") {
+			t.Errorf("expected the stream to start on the second try, got %d tries", starts.Load())
+		}
+	})
+}
+
+func TestStreamQueueOptions_Validate(t *testing.T) {
+	if err := (StreamQueueOptions{MaxWait: -time.Second}).Validate(); !errors.Is(err, ErrInvalidStreamQueue) {
+		t.Errorf("expected ErrInvalidStreamQueue, got %v", err)
+	}
+	if err := (StreamQueueOptions{}).Validate(); err != nil {
+		t.Errorf("expected the zero options to be valid, got %v", err)
+	}
+}
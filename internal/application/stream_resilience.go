@@ -0,0 +1,110 @@
+package application
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrInvalidResilienceConfig indicates resilience settings out of range.
+var ErrInvalidResilienceConfig = errors.New("invalid resilience settings")
+
+// ResilienceConfig tunes how streams ride out engine and network trouble.
+type ResilienceConfig struct {
+	// ReconnectAttempts is how many times an engine stream is started,
+	// counting the first, before its clients are dropped.
+	ReconnectAttempts int
+	// ReconnectInitialBackoff is the wait before the first restart; each
+	// further restart waits twice as long, up to ReconnectMaxBackoff.
+	ReconnectInitialBackoff time.Duration
+	ReconnectMaxBackoff     time.Duration
+	// BreakerThreshold is how many consecutive failed stream starts open
+	// the engine circuit breaker; zero disables it.
+	BreakerThreshold int
+	// BreakerTimeout is how long an open breaker rejects stream starts.
+	BreakerTimeout time.Duration
+	// ClientBufferSize is the most bytes buffered per client of a shared
+	// stream.
+	ClientBufferSize int
+}
+
+// DefaultResilienceConfig returns the settings used unless configured
+// otherwise.
+func DefaultResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		ReconnectAttempts:       3,
+		ReconnectInitialBackoff: 2 * time.Second,
+		ReconnectMaxBackoff:     30 * time.Second,
+		BreakerThreshold:        5,
+		BreakerTimeout:          30 * time.Second,
+		ClientBufferSize:        defaultClientBufferSize,
+	}
+}
+
+// Validate checks that the settings are in range.
+// Returns ErrInvalidResilienceConfig otherwise.
+func (c ResilienceConfig) Validate() error {
+	if c.ReconnectAttempts < 1 || c.ReconnectInitialBackoff <= 0 || c.ReconnectMaxBackoff < c.ReconnectInitialBackoff ||
+		c.BreakerThreshold < 0 || c.BreakerTimeout <= 0 || c.ClientBufferSize <= 0 {
+		return ErrInvalidResilienceConfig
+	}
+	return nil
+}
+
+// backoff returns the wait before restart number attempt, counting from 1.
+func (c ResilienceConfig) backoff(attempt int) time.Duration {
+	d := c.ReconnectInitialBackoff
+	for i := 1; i < attempt && d < c.ReconnectMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, c.ReconnectMaxBackoff)
+}
+
+// resilienceState holds the reconnection settings streams read when they
+// start reconnecting.
+type resilienceState struct {
+	mu  sync.Mutex
+	cfg ResilienceConfig
+}
+
+// get returns the current settings.
+func (r *resilienceState) get() ResilienceConfig {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cfg
+}
+
+// SetResilience changes how streams reconnect, the engine circuit breaker
+// and the per-client buffer size. Engine streams started afterwards use the
+// new reconnection and buffer settings; the breaker switches right away.
+// Returns ErrInvalidResilienceConfig if a setting is out of range.
+func (s *AceStreamProxyService) SetResilience(cfg ResilienceConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	s.resilience.mu.Lock()
+	s.resilience.cfg = cfg
+	s.resilience.mu.Unlock()
+
+	if s.breaker != nil {
+		s.breaker.Configure(cfg.BreakerThreshold, cfg.BreakerTimeout)
+	}
+	s.sessions.mu.Lock()
+	s.sessions.buffer.Size = cfg.ClientBufferSize
+	s.sessions.mu.Unlock()
+	return nil
+}
+
+// Resilience returns the current resilience settings.
+func (s *AceStreamProxyService) Resilience() ResilienceConfig {
+	cfg := s.resilience.get()
+	if s.breaker != nil {
+		stats := s.breaker.Stats()
+		cfg.BreakerThreshold, cfg.BreakerTimeout = max(stats.Threshold, 0), stats.Timeout
+	}
+	s.sessions.mu.Lock()
+	cfg.ClientBufferSize = s.sessions.buffer.withDefaults().Size
+	s.sessions.mu.Unlock()
+	return cfg
+}
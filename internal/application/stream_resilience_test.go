@@ -0,0 +1,109 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/circuitbreaker"
+)
+
+func TestResilienceConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *ResilienceConfig)
+		valid  bool
+	}{
+		{name: "defaults", modify: func(c *ResilienceConfig) {}, valid: true},
+		{name: "disabled breaker", modify: func(c *ResilienceConfig) { c.BreakerThreshold = 0 }, valid: true},
+		{name: "no attempts", modify: func(c *ResilienceConfig) { c.ReconnectAttempts = 0 }},
+		{name: "zero initial backoff", modify: func(c *ResilienceConfig) { c.ReconnectInitialBackoff = 0 }},
+		{name: "max below initial backoff", modify: func(c *ResilienceConfig) { c.ReconnectMaxBackoff = time.Second }},
+		{name: "negative breaker threshold", modify: func(c *ResilienceConfig) { c.BreakerThreshold = -1 }},
+		{name: "zero breaker timeout", modify: func(c *ResilienceConfig) { c.BreakerTimeout = 0 }},
+		{name: "zero buffer size", modify: func(c *ResilienceConfig) { c.ClientBufferSize = 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultResilienceConfig()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("expected valid settings, got %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidResilienceConfig) {
+				t.Errorf("expected ErrInvalidResilienceConfig, got %v", err)
+			}
+		})
+	}
+}
+
+func TestResilienceConfig_Backoff(t *testing.T) {
+	cfg := ResilienceConfig{ReconnectInitialBackoff: 2 * time.Second, ReconnectMaxBackoff: 5 * time.Second}
+	for attempt, want := range map[int]time.Duration{1: 2 * time.Second, 2: 4 * time.Second, 3: 5 * time.Second, 10: 5 * time.Second} {
+		if got := cfg.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestAceStreamProxyService_SetResilience(t *testing.T) {
+	t.Run("applies the breaker and buffer settings", func(t *testing.T) {
+		breaker := circuitbreaker.New(5, 30*time.Second)
+		service := NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, breaker)
+
+		if got := service.Resilience(); got != DefaultResilienceConfig() {
+			t.Errorf("expected the default settings, got %+v", got)
+		}
+
+		cfg := DefaultResilienceConfig()
+		cfg.BreakerThreshold, cfg.BreakerTimeout, cfg.ClientBufferSize = 2, time.Minute, 1<<20
+		if err := service.SetResilience(cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := service.Resilience(); got != cfg {
+			t.Errorf("expected %+v, got %+v", cfg, got)
+		}
+		if stats := breaker.Stats(); stats.Threshold != 2 || stats.Timeout != time.Minute {
+			t.Errorf("expected the breaker to be reconfigured, got %+v", stats)
+		}
+
+		cfg.ReconnectAttempts = 0
+		if err := service.SetResilience(cfg); !errors.Is(err, ErrInvalidResilienceConfig) {
+			t.Errorf("expected ErrInvalidResilienceConfig, got %v", err)
+		}
+	})
+
+	t.Run("new streams reconnect as configured", func(t *testing.T) {
+		var attempts int
+		engine := &mockAceStreamEngine{
+			startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+				return "http://localhost:6878/stream/test", nil
+			},
+			streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+				attempts++
+				return errors.New("persistent error")
+			},
+		}
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+		cfg := DefaultResilienceConfig()
+		cfg.ReconnectAttempts, cfg.ReconnectInitialBackoff, cfg.ReconnectMaxBackoff = 4, time.Millisecond, 2*time.Millisecond
+		if err := service.SetResilience(cfg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := service.StreamToClient(context.Background(), "test-infohash", &buf); err == nil {
+			t.Fatal("expected the stream to fail")
+		}
+		if attempts != 4 {
+			t.Errorf("expected 4 attempts, got %d", attempts)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package application
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// resumeState holds the client slots of disconnected clients that may come
+// back with their resume token.
+type resumeState struct {
+	grace  atomic.Int64
+	mu     sync.Mutex
+	parked map[string]*parkedClient
+}
+
+// parkedClient is the slot of a disconnected client: its PID stays registered
+// in the session, keeping the engine stream running, until the timer fires.
+type parkedClient struct {
+	key   string
+	pid   string
+	timer *time.Timer
+}
+
+func newResumeState() *resumeState {
+	return &resumeState{parked: make(map[string]*parkedClient)}
+}
+
+// SetResumeGrace sets how long a client that drops its connection keeps its
+// PID and its place in the shared stream. A client reconnecting with the same
+// StreamOptions.ResumeToken within the window takes them over instead of
+// restarting the stream. Zero or negative disables resuming; clients already
+// waiting keep their window. It may be called while streams are running.
+func (s *AceStreamProxyService) SetResumeGrace(d time.Duration) {
+	s.resume.grace.Store(int64(d))
+}
+
+// resumeClient takes over the slot parked under token for the session key.
+// Returns false if there is none, or if the stream it belonged to has ended.
+func (s *AceStreamProxyService) resumeClient(token, key string) (*streamSession, string, bool) {
+	if token == "" {
+		return nil, "", false
+	}
+
+	s.resume.mu.Lock()
+	p := s.resume.parked[token]
+	if p == nil || p.key != key {
+		s.resume.mu.Unlock()
+		return nil, "", false
+	}
+	delete(s.resume.parked, token)
+	p.timer.Stop()
+	s.resume.mu.Unlock()
+
+	session := s.sessions.GetSession(key)
+	if session == nil || session.GetBroadcaster().isClosed() {
+		s.cleanupClient(p.key, p.pid)
+		return nil, "", false
+	}
+	return session, p.pid, true
+}
+
+// releaseClient removes a client that stopped streaming, or parks its slot
+// under token when park is set and resuming is enabled.
+func (s *AceStreamProxyService) releaseClient(key, pid, token string, park bool) {
+	grace := time.Duration(s.resume.grace.Load())
+	if token == "" || grace <= 0 || !park {
+		s.cleanupClient(key, pid)
+		return
+	}
+	session := s.sessions.GetSession(key)
+	if session == nil || session.GetBroadcaster().isClosed() {
+		s.cleanupClient(key, pid)
+		return
+	}
+
+	p := &parkedClient{key: key, pid: pid}
+	s.resume.mu.Lock()
+	previous := s.resume.parked[token]
+	s.resume.parked[token] = p
+	p.timer = time.AfterFunc(grace, func() { s.expireParked(token, p) })
+	s.resume.mu.Unlock()
+
+	s.logger.Info("client disconnected, keeping its slot for resume",
+		"infohash", session.InfoHash(),
+		"pid", pid,
+		"grace", grace)
+
+	// A token reused by another client replaces the older slot
+	if previous != nil {
+		previous.timer.Stop()
+		s.cleanupClient(previous.key, previous.pid)
+	}
+}
+
+// expireParked removes a parked client whose window ended without a resume.
+func (s *AceStreamProxyService) expireParked(token string, p *parkedClient) {
+	s.resume.mu.Lock()
+	if s.resume.parked[token] != p {
+		s.resume.mu.Unlock()
+		return
+	}
+	delete(s.resume.parked, token)
+	s.resume.mu.Unlock()
+
+	s.cleanupClient(p.key, p.pid)
+}
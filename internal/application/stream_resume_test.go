@@ -0,0 +1,132 @@
+package application
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newResumeTestService returns a proxy service whose engine streams run until
+// stopped, counting engine starts and stops.
+func newResumeTestService(grace time.Duration) (*AceStreamProxyService, *atomic.Int32, *atomic.Int32) {
+	var starts, stops atomic.Int32
+	engine := &mockAceStreamEngine{
+		startStreamFunc: func(ctx context.Context, infoHash, pid string) (string, error) {
+			starts.Add(1)
+			return "http://localhost:6878/stream/" + pid, nil
+		},
+		streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+			ticker := time.NewTicker(5 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-ticker.C:
+					if _, err := dst.Write([]byte("chunk")); err != nil {
+						return err
+					}
+				}
+			}
+		},
+		stopStreamFunc: func(ctx context.Context, pid string) error {
+			stops.Add(1)
+			return nil
+		},
+	}
+	service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+	service.SetResumeGrace(grace)
+	return service, &starts, &stops
+}
+
+// streamFor streams to a client that disconnects after d.
+func streamFor(service *AceStreamProxyService, d time.Duration, opts StreamOptions) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	_ = service.StreamToClientWithOptions(ctx, "resume-infohash", io.Discard, opts)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAceStreamProxyService_Resume(t *testing.T) {
+	t.Run("a reconnect within the grace window reuses the engine stream", func(t *testing.T) {
+		service, starts, stops := newResumeTestService(200 * time.Millisecond)
+		opts := StreamOptions{ResumeToken: "token-1"}
+
+		streamFor(service, 30*time.Millisecond, opts)
+		if stops.Load() != 0 || !service.IsStreamActive("resume-infohash") {
+			t.Fatal("expected the stream to keep running during the grace window")
+		}
+		pids := service.GetActiveStreams()[0].PIDs
+
+		streamFor(service, 30*time.Millisecond, opts)
+		if starts.Load() != 1 {
+			t.Errorf("expected a single engine start, got %d", starts.Load())
+		}
+		if got := service.GetActiveStreams()[0].PIDs; len(got) != 1 || got[0] != pids[0] {
+			t.Errorf("expected the resumed client to keep PID %v, got %v", pids, got)
+		}
+		if c := service.Counters(); c.ClientsResumed != 1 || c.ClientsServed != 1 {
+			t.Errorf("expected 1 client served and 1 resumed, got %+v", c)
+		}
+
+		// Without a reconnect the slot is released once the window ends
+		waitFor(t, func() bool { return stops.Load() == 1 })
+		if service.IsStreamActive("resume-infohash") {
+			t.Error("expected the stream to stop after the grace window")
+		}
+	})
+
+	t.Run("clients without a token are removed at once", func(t *testing.T) {
+		service, _, stops := newResumeTestService(time.Minute)
+
+		streamFor(service, 30*time.Millisecond, StreamOptions{})
+
+		if stops.Load() != 1 {
+			t.Errorf("expected the stream to stop when its only client left, got %d stops", stops.Load())
+		}
+	})
+
+	t.Run("resuming is disabled without a grace window", func(t *testing.T) {
+		service, starts, stops := newResumeTestService(0)
+		opts := StreamOptions{ResumeToken: "token-1"}
+
+		streamFor(service, 30*time.Millisecond, opts)
+		streamFor(service, 30*time.Millisecond, opts)
+
+		if starts.Load() != 2 || stops.Load() != 2 {
+			t.Errorf("expected each connection to start and stop the stream, got %d starts and %d stops", starts.Load(), stops.Load())
+		}
+	})
+
+	t.Run("clients disconnected on purpose are not kept", func(t *testing.T) {
+		service, _, stops := newResumeTestService(time.Minute)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			streamFor(service, time.Minute, StreamOptions{ResumeToken: "token-1"})
+		}()
+		waitFor(t, func() bool { return len(service.ClientSessions()) == 1 })
+		if err := service.DisconnectClient(service.ClientSessions()[0].ID); err != nil {
+			t.Fatalf("DisconnectClient() error = %v", err)
+		}
+		<-done
+
+		if stops.Load() != 1 {
+			t.Errorf("expected the stream to stop when its client was disconnected, got %d stops", stops.Load())
+		}
+	})
+}
@@ -2,16 +2,36 @@ package application
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"strings"
 
+	"github.com/alorle/iptv-manager/internal/audit"
 	"github.com/alorle/iptv-manager/internal/port/driven"
 	"github.com/alorle/iptv-manager/internal/stream"
 )
 
+// Stream search errors
+var (
+	ErrEmptySearchQuery  = errors.New("search query cannot be empty")
+	ErrSearchUnavailable = errors.New("stream search is not available")
+)
+
 // StreamService provides use cases for stream management.
 // It depends only on domain packages and port interfaces.
 type StreamService struct {
 	streamRepo  driven.StreamRepository
 	channelRepo driven.ChannelRepository
+	searcher    driven.AceStreamSearcher
+	audit       *AuditService
+	events      *EventBus
+}
+
+// StreamCandidate is a stream found by SearchStreams. ChannelName is set
+// when the infohash is already attached to a channel.
+type StreamCandidate struct {
+	driven.SearchResult
+	ChannelName string
 }
 
 // NewStreamService creates a new StreamService with the given repositories.
@@ -22,9 +42,25 @@ func NewStreamService(streamRepo driven.StreamRepository, channelRepo driven.Cha
 	}
 }
 
+// SetSearcher enables SearchStreams using the given AceStream search backend.
+func (s *StreamService) SetSearcher(searcher driven.AceStreamSearcher) {
+	s.searcher = searcher
+}
+
+// SetAuditService makes MoveStream record each move in the audit log.
+func (s *StreamService) SetAuditService(service *AuditService) {
+	s.audit = service
+}
+
+// SetEventBus enables publishing EventOverrideUpdated when a stream moves to
+// another channel.
+func (s *StreamService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
 // CreateStream creates a new stream with the given infohash and channel name.
 // It validates that the channel exists before creating the stream.
-// Returns stream.ErrEmptyInfoHash if the infohash is invalid.
+// Returns stream.ErrEmptyInfoHash or stream.ErrInvalidInfoHash if the infohash is invalid.
 // Returns stream.ErrEmptyChannelName if the channel name is invalid.
 // Returns channel.ErrChannelNotFound if the referenced channel does not exist.
 // Returns stream.ErrStreamAlreadyExists if a stream with the same infohash already exists.
@@ -60,8 +96,122 @@ func (s *StreamService) ListStreams(ctx context.Context) ([]stream.Stream, error
 	return s.streamRepo.FindAll(ctx)
 }
 
+// streamSortFields are the fields stream listings can be sorted by.
+var streamSortFields = map[string]func(a, b stream.Stream) int{
+	"info_hash":    func(a, b stream.Stream) int { return strings.Compare(a.InfoHash(), b.InfoHash()) },
+	"channel_name": func(a, b stream.Stream) int { return strings.Compare(a.ChannelName(), b.ChannelName()) },
+	"source":       func(a, b stream.Stream) int { return strings.Compare(a.Source(), b.Source()) },
+}
+
+// ListStreamsPage retrieves one page of the streams, sorted by info_hash,
+// channel_name or source.
+// Returns ErrInvalidSortField or ErrInvalidPagination for invalid options.
+func (s *StreamService) ListStreamsPage(ctx context.Context, opts ListOptions) (ListPage[stream.Stream], error) {
+	streams, err := s.streamRepo.FindAll(ctx)
+	if err != nil {
+		return ListPage[stream.Stream]{}, err
+	}
+	return listItems(streams, opts, streamSortFields)
+}
+
+// ListChannelStreams retrieves all streams of a channel.
+// Returns an empty slice if the channel has no streams.
+func (s *StreamService) ListChannelStreams(ctx context.Context, channelName string) ([]stream.Stream, error) {
+	streams, err := s.streamRepo.FindByChannelName(ctx, channelName)
+	if err != nil && !errors.Is(err, stream.ErrStreamNotFound) {
+		return nil, err
+	}
+	if streams == nil {
+		streams = []stream.Stream{}
+	}
+	return streams, nil
+}
+
 // DeleteStream removes a stream by its infohash.
 // Returns stream.ErrStreamNotFound if the stream does not exist.
 func (s *StreamService) DeleteStream(ctx context.Context, infoHash string) error {
 	return s.streamRepo.Delete(ctx, infoHash)
 }
+
+// MoveStream reassigns a stream to another channel in place, keeping its
+// source and everything recorded under its infohash, such as health and
+// stats history. Moving a stream to the channel it is on changes nothing.
+// A move that cannot be recorded in the audit log is logged and kept.
+// Returns stream.ErrStreamNotFound if the stream does not exist.
+// Returns stream.ErrEmptyChannelName if the channel name is invalid.
+// Returns channel.ErrChannelNotFound if the target channel does not exist.
+func (s *StreamService) MoveStream(ctx context.Context, infoHash, channelName string) (stream.Stream, error) {
+	st, err := s.streamRepo.FindByInfoHash(ctx, infoHash)
+	if err != nil {
+		return stream.Stream{}, err
+	}
+	if strings.TrimSpace(channelName) == "" {
+		return stream.Stream{}, stream.ErrEmptyChannelName
+	}
+	target, err := s.channelRepo.FindByName(ctx, strings.TrimSpace(channelName))
+	if err != nil {
+		return stream.Stream{}, err
+	}
+	if target.Name() == st.ChannelName() {
+		return st, nil
+	}
+
+	moved, err := st.MoveTo(target.Name())
+	if err != nil {
+		return stream.Stream{}, err
+	}
+	if err := s.streamRepo.Update(ctx, moved); err != nil {
+		return stream.Stream{}, err
+	}
+
+	if s.audit != nil {
+		if err := s.audit.Record(ctx, audit.ActionStreamMoved, moved.InfoHash(), st.ChannelName(), moved.ChannelName()); err != nil {
+			slog.WarnContext(ctx, "failed to record stream move in audit log", "infohash", moved.InfoHash(), "error", err)
+		}
+	}
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: st.ChannelName()})
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "channel", Name: moved.ChannelName()})
+	return moved, nil
+}
+
+// SearchStreams looks up candidate streams for query in the AceStream engine,
+// marking those already attached to a channel. Candidates are attached with
+// CreateStream.
+// Returns ErrEmptySearchQuery if the query is empty.
+// Returns ErrSearchUnavailable if no search backend is configured.
+func (s *StreamService) SearchStreams(ctx context.Context, query string) ([]StreamCandidate, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	if s.searcher == nil {
+		return nil, ErrSearchUnavailable
+	}
+
+	results, err := s.searcher.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]string)
+	if streams, err := s.streamRepo.FindAll(ctx); err == nil {
+		for _, st := range streams {
+			known[st.InfoHash()] = st.ChannelName()
+		}
+	}
+
+	candidates := make([]StreamCandidate, 0, len(results))
+	seen := make(map[string]bool, len(results))
+	for _, r := range results {
+		if seen[r.InfoHash] {
+			continue
+		}
+		seen[r.InfoHash] = true
+		candidates = append(candidates, StreamCandidate{
+			SearchResult: r,
+			ChannelName:  known[r.InfoHash],
+		})
+	}
+
+	return candidates, nil
+}
@@ -3,9 +3,12 @@ package application
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 
+	"github.com/alorle/iptv-manager/internal/audit"
 	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/port/driven"
 	"github.com/alorle/iptv-manager/internal/stream"
 )
 
@@ -22,8 +25,8 @@ func TestStreamService_CreateStream(t *testing.T) {
 		}
 		streamRepo := &mockStreamRepository{
 			saveFunc: func(ctx context.Context, s stream.Stream) error {
-				if s.InfoHash() != "abc123" {
-					t.Errorf("expected infohash 'abc123', got %q", s.InfoHash())
+				if s.InfoHash() != "6162633132330000000000000000000000000000" {
+					t.Errorf("expected infohash %q, got %q", "6162633132330000000000000000000000000000", s.InfoHash())
 				}
 				if s.ChannelName() != "TestChannel" {
 					t.Errorf("expected channel name 'TestChannel', got %q", s.ChannelName())
@@ -33,12 +36,12 @@ func TestStreamService_CreateStream(t *testing.T) {
 		}
 		service := NewStreamService(streamRepo, channelRepo)
 
-		st, err := service.CreateStream(context.Background(), "abc123", "TestChannel")
+		st, err := service.CreateStream(context.Background(), "6162633132330000000000000000000000000000", "TestChannel")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if st.InfoHash() != "abc123" {
-			t.Errorf("expected infohash 'abc123', got %q", st.InfoHash())
+		if st.InfoHash() != "6162633132330000000000000000000000000000" {
+			t.Errorf("expected infohash %q, got %q", "6162633132330000000000000000000000000000", st.InfoHash())
 		}
 		if st.ChannelName() != "TestChannel" {
 			t.Errorf("expected channel name 'TestChannel', got %q", st.ChannelName())
@@ -54,7 +57,7 @@ func TestStreamService_CreateStream(t *testing.T) {
 		streamRepo := &mockStreamRepository{}
 		service := NewStreamService(streamRepo, channelRepo)
 
-		_, err := service.CreateStream(context.Background(), "abc123", "NonExistent")
+		_, err := service.CreateStream(context.Background(), "6162633132330000000000000000000000000000", "NonExistent")
 		if !errors.Is(err, channel.ErrChannelNotFound) {
 			t.Errorf("expected ErrChannelNotFound, got %v", err)
 		}
@@ -86,7 +89,7 @@ func TestStreamService_CreateStream(t *testing.T) {
 		streamRepo := &mockStreamRepository{}
 		service := NewStreamService(streamRepo, channelRepo)
 
-		_, err := service.CreateStream(context.Background(), "abc123", "")
+		_, err := service.CreateStream(context.Background(), "6162633132330000000000000000000000000000", "")
 		if !errors.Is(err, stream.ErrEmptyChannelName) {
 			t.Errorf("expected ErrEmptyChannelName, got %v", err)
 		}
@@ -106,7 +109,7 @@ func TestStreamService_CreateStream(t *testing.T) {
 		}
 		service := NewStreamService(streamRepo, channelRepo)
 
-		_, err := service.CreateStream(context.Background(), "abc123", "TestChannel")
+		_, err := service.CreateStream(context.Background(), "6162633132330000000000000000000000000000", "TestChannel")
 		if !errors.Is(err, stream.ErrStreamAlreadyExists) {
 			t.Errorf("expected ErrStreamAlreadyExists, got %v", err)
 		}
@@ -115,11 +118,11 @@ func TestStreamService_CreateStream(t *testing.T) {
 
 func TestStreamService_GetStream(t *testing.T) {
 	t.Run("gets stream successfully", func(t *testing.T) {
-		expectedStream, _ := stream.NewStream("abc123", "TestChannel", "")
+		expectedStream, _ := stream.NewStream("6162633132330000000000000000000000000000", "TestChannel", "")
 		streamRepo := &mockStreamRepository{
 			findByInfoHashFunc: func(ctx context.Context, infoHash string) (stream.Stream, error) {
-				if infoHash != "abc123" {
-					t.Errorf("expected infohash 'abc123', got %q", infoHash)
+				if infoHash != "6162633132330000000000000000000000000000" {
+					t.Errorf("expected infohash %q, got %q", "6162633132330000000000000000000000000000", infoHash)
 				}
 				return expectedStream, nil
 			},
@@ -127,12 +130,12 @@ func TestStreamService_GetStream(t *testing.T) {
 		channelRepo := &mockChannelRepository{}
 		service := NewStreamService(streamRepo, channelRepo)
 
-		st, err := service.GetStream(context.Background(), "abc123")
+		st, err := service.GetStream(context.Background(), "6162633132330000000000000000000000000000")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if st.InfoHash() != "abc123" {
-			t.Errorf("expected infohash 'abc123', got %q", st.InfoHash())
+		if st.InfoHash() != "6162633132330000000000000000000000000000" {
+			t.Errorf("expected infohash %q, got %q", "6162633132330000000000000000000000000000", st.InfoHash())
 		}
 		if st.ChannelName() != "TestChannel" {
 			t.Errorf("expected channel name 'TestChannel', got %q", st.ChannelName())
@@ -157,8 +160,8 @@ func TestStreamService_GetStream(t *testing.T) {
 
 func TestStreamService_ListStreams(t *testing.T) {
 	t.Run("lists all streams successfully", func(t *testing.T) {
-		st1, _ := stream.NewStream("abc123", "Channel1", "")
-		st2, _ := stream.NewStream("def456", "Channel2", "")
+		st1, _ := stream.NewStream("6162633132330000000000000000000000000000", "Channel1", "")
+		st2, _ := stream.NewStream("6465663435360000000000000000000000000000", "Channel2", "")
 		expectedStreams := []stream.Stream{st1, st2}
 
 		streamRepo := &mockStreamRepository{
@@ -176,7 +179,7 @@ func TestStreamService_ListStreams(t *testing.T) {
 		if len(streams) != 2 {
 			t.Fatalf("expected 2 streams, got %d", len(streams))
 		}
-		if streams[0].InfoHash() != "abc123" || streams[1].InfoHash() != "def456" {
+		if streams[0].InfoHash() != "6162633132330000000000000000000000000000" || streams[1].InfoHash() != "6465663435360000000000000000000000000000" {
 			t.Errorf("unexpected stream infohashes: %q, %q", streams[0].InfoHash(), streams[1].InfoHash())
 		}
 	})
@@ -206,8 +209,8 @@ func TestStreamService_DeleteStream(t *testing.T) {
 		streamRepo := &mockStreamRepository{
 			deleteFunc: func(ctx context.Context, infoHash string) error {
 				deleteCalled = true
-				if infoHash != "abc123" {
-					t.Errorf("expected infohash 'abc123', got %q", infoHash)
+				if infoHash != "6162633132330000000000000000000000000000" {
+					t.Errorf("expected infohash %q, got %q", "6162633132330000000000000000000000000000", infoHash)
 				}
 				return nil
 			},
@@ -215,7 +218,7 @@ func TestStreamService_DeleteStream(t *testing.T) {
 		channelRepo := &mockChannelRepository{}
 		service := NewStreamService(streamRepo, channelRepo)
 
-		err := service.DeleteStream(context.Background(), "abc123")
+		err := service.DeleteStream(context.Background(), "6162633132330000000000000000000000000000")
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -239,3 +242,181 @@ func TestStreamService_DeleteStream(t *testing.T) {
 		}
 	})
 }
+
+// mockAceStreamSearcher is a mock implementation of driven.AceStreamSearcher for testing.
+type mockAceStreamSearcher struct {
+	searchFunc func(ctx context.Context, query string) ([]driven.SearchResult, error)
+}
+
+func (m *mockAceStreamSearcher) Search(ctx context.Context, query string) ([]driven.SearchResult, error) {
+	if m.searchFunc != nil {
+		return m.searchFunc(ctx, query)
+	}
+	return []driven.SearchResult{}, nil
+}
+
+// memAuditLogRepository keeps audit entries in memory, oldest first.
+type memAuditLogRepository struct {
+	entries []audit.Entry
+}
+
+func (r *memAuditLogRepository) Append(ctx context.Context, e audit.Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *memAuditLogRepository) FindRecent(ctx context.Context, limit int) ([]audit.Entry, error) {
+	entries := slices.Clone(r.entries)
+	slices.Reverse(entries)
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func TestStreamService_MoveStream(t *testing.T) {
+	const hash = "6162633132330000000000000000000000000000"
+
+	newService := func(t *testing.T) (*StreamService, *[]stream.Stream, *memAuditLogRepository) {
+		t.Helper()
+		var updated []stream.Stream
+		streamRepo := &mockStreamRepository{
+			findByInfoHashFunc: func(ctx context.Context, infoHash string) (stream.Stream, error) {
+				if infoHash != hash {
+					return stream.Stream{}, stream.ErrStreamNotFound
+				}
+				return stream.NewStream(hash, "La 1", stream.SourceElcano)
+			},
+			updateFunc: func(ctx context.Context, s stream.Stream) error {
+				updated = append(updated, s)
+				return nil
+			},
+		}
+		channelRepo := &mockChannelRepository{
+			findByNameFunc: func(ctx context.Context, name string) (channel.Channel, error) {
+				if name != "La 1" && name != "La 1 HD" {
+					return channel.Channel{}, channel.ErrChannelNotFound
+				}
+				return channel.NewChannel(name)
+			},
+		}
+		auditRepo := &memAuditLogRepository{}
+		service := NewStreamService(streamRepo, channelRepo)
+		service.SetAuditService(NewAuditService(auditRepo))
+		return service, &updated, auditRepo
+	}
+
+	t.Run("moves the stream and records who moved it", func(t *testing.T) {
+		service, updated, auditRepo := newService(t)
+
+		ctx := WithAPIToken(context.Background(), "token-1")
+		moved, err := service.MoveStream(ctx, hash, " La 1 HD ")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if moved.ChannelName() != "La 1 HD" || moved.Source() != stream.SourceElcano {
+			t.Errorf("unexpected moved stream %+v", moved)
+		}
+		if len(*updated) != 1 || (*updated)[0] != moved {
+			t.Errorf("expected the moved stream to be updated, got %v", *updated)
+		}
+		if len(auditRepo.entries) != 1 {
+			t.Fatalf("expected one audit entry, got %d", len(auditRepo.entries))
+		}
+		if e := auditRepo.entries[0]; e.Action() != audit.ActionStreamMoved || e.Subject() != hash ||
+			e.From() != "La 1" || e.To() != "La 1 HD" || e.Actor() != "token-1" {
+			t.Errorf("unexpected audit entry %+v", e)
+		}
+	})
+
+	t.Run("leaves a stream moved to its own channel alone", func(t *testing.T) {
+		service, updated, auditRepo := newService(t)
+
+		if _, err := service.MoveStream(context.Background(), hash, "La 1"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(*updated) != 0 || len(auditRepo.entries) != 0 {
+			t.Errorf("expected nothing changed, got %d updates and %d audit entries", len(*updated), len(auditRepo.entries))
+		}
+	})
+
+	t.Run("rejects invalid moves", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			infoHash string
+			channel  string
+			wantErr  error
+		}{
+			{"unknown stream", "missing", "La 1 HD", stream.ErrStreamNotFound},
+			{"empty channel", hash, "  ", stream.ErrEmptyChannelName},
+			{"unknown channel", hash, "La 2", channel.ErrChannelNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				service, updated, _ := newService(t)
+
+				if _, err := service.MoveStream(context.Background(), tt.infoHash, tt.channel); !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				if len(*updated) != 0 {
+					t.Errorf("expected no update, got %v", *updated)
+				}
+			})
+		}
+	})
+}
+
+func TestStreamService_SearchStreams(t *testing.T) {
+	t.Run("marks candidates already attached to a channel", func(t *testing.T) {
+		attached, _ := stream.NewStream("6162630000000000000000000000000000000000", "DAZN 1", "")
+		streamRepo := &mockStreamRepository{
+			findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+				return []stream.Stream{attached}, nil
+			},
+		}
+		service := NewStreamService(streamRepo, &mockChannelRepository{})
+		service.SetSearcher(&mockAceStreamSearcher{
+			searchFunc: func(ctx context.Context, query string) ([]driven.SearchResult, error) {
+				if query != "dazn" {
+					t.Errorf("expected trimmed query 'dazn', got %q", query)
+				}
+				return []driven.SearchResult{
+					{InfoHash: "6162630000000000000000000000000000000000", Name: "DAZN 1 HD"},
+					{InfoHash: "def", Name: "DAZN 2"},
+					{InfoHash: "6162630000000000000000000000000000000000", Name: "DAZN 1 HD (mirror)"},
+				}, nil
+			},
+		})
+
+		candidates, err := service.SearchStreams(context.Background(), "  dazn ")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(candidates) != 2 {
+			t.Fatalf("expected duplicates to be dropped, got %d candidates", len(candidates))
+		}
+		if candidates[0].ChannelName != "DAZN 1" {
+			t.Errorf("expected abc to be attached to DAZN 1, got %q", candidates[0].ChannelName)
+		}
+		if candidates[1].ChannelName != "" {
+			t.Errorf("expected def to be unattached, got %q", candidates[1].ChannelName)
+		}
+	})
+
+	t.Run("rejects empty queries", func(t *testing.T) {
+		service := NewStreamService(&mockStreamRepository{}, &mockChannelRepository{})
+		service.SetSearcher(&mockAceStreamSearcher{})
+
+		if _, err := service.SearchStreams(context.Background(), " "); !errors.Is(err, ErrEmptySearchQuery) {
+			t.Errorf("expected ErrEmptySearchQuery, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrSearchUnavailable without a searcher", func(t *testing.T) {
+		service := NewStreamService(&mockStreamRepository{}, &mockChannelRepository{})
+
+		if _, err := service.SearchStreams(context.Background(), "dazn"); !errors.Is(err, ErrSearchUnavailable) {
+			t.Errorf("expected ErrSearchUnavailable, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+// WatchStats reports the engine statistics of the session streaming
+// infoHash to fn, once straight away and then every interval, until ctx is
+// done, fn returns an error, or the session ends. Readings the engine fails
+// to provide are skipped. Returns nil when the session ends.
+// Returns ErrStreamNotActive if the infohash is not being streamed.
+func (s *AceStreamProxyService) WatchStats(ctx context.Context, infoHash string, interval time.Duration, fn func(driven.StreamStats) error) error {
+	session := s.sessions.FindByInfoHash(infoHash)
+	if session == nil {
+		return ErrStreamNotActive
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if s.sessions.GetSession(session.Key()) != session {
+			return nil
+		}
+
+		if pid := session.GetEnginePID(); pid != "" {
+			stats, err := s.engine.GetStats(ctx, pid)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				s.logger.DebugContext(ctx, "failed to get engine stats", "infohash", infoHash, "pid", pid, "error", err)
+			} else if err := fn(stats); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CollectStats reads the engine statistics of every stream being played.
+// Streams whose engine playback has not started, or that the engine fails
+// to report on, are skipped.
+func (s *AceStreamProxyService) CollectStats(ctx context.Context) []driven.StreamStats {
+	seen := make(map[string]bool)
+	var result []driven.StreamStats
+	for _, info := range s.sessions.GetAllSessions() {
+		if seen[info.InfoHash] {
+			continue
+		}
+		seen[info.InfoHash] = true
+
+		session := s.sessions.FindByInfoHash(info.InfoHash)
+		if session == nil {
+			continue
+		}
+		pid := session.GetEnginePID()
+		if pid == "" {
+			continue
+		}
+
+		stats, err := s.engine.GetStats(ctx, pid)
+		if err != nil {
+			s.logger.DebugContext(ctx, "failed to get engine stats", "infohash", info.InfoHash, "pid", pid, "error", err)
+			continue
+		}
+		stats.InfoHash = info.InfoHash
+		result = append(result, stats)
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+)
+
+func TestAceStreamProxyService_WatchStats(t *testing.T) {
+	t.Run("returns ErrStreamNotActive for streams not being played", func(t *testing.T) {
+		engine, _ := blockingEngine("dl")
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+		err := service.WatchStats(context.Background(), "infohash-1", time.Millisecond, func(driven.StreamStats) error {
+			return nil
+		})
+		if !errors.Is(err, ErrStreamNotActive) {
+			t.Errorf("expected ErrStreamNotActive, got %v", err)
+		}
+	})
+
+	t.Run("reports stats until the session ends", func(t *testing.T) {
+		engine, _ := blockingEngine("dl")
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		streamDone := make(chan error, 1)
+		go func() {
+			streamDone <- service.StreamToClient(ctx, "infohash-1", io.Discard)
+		}()
+		pid := waitForClientSessions(t, service, 1)[0].ID
+
+		readings := make(chan driven.StreamStats, 100)
+		watchDone := make(chan error, 1)
+		go func() {
+			watchDone <- service.WatchStats(context.Background(), "infohash-1", 5*time.Millisecond, func(stats driven.StreamStats) error {
+				readings <- stats
+				return nil
+			})
+		}()
+
+		select {
+		case stats := <-readings:
+			if stats.PID != pid || stats.Status != "dl" {
+				t.Errorf("unexpected stats %+v", stats)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no stats reported")
+		}
+
+		cancel()
+		<-streamDone
+
+		select {
+		case err := <-watchDone:
+			if err != nil {
+				t.Errorf("expected nil when the session ends, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("watch did not end with the session")
+		}
+	})
+
+	t.Run("stops when the callback fails", func(t *testing.T) {
+		engine, _ := blockingEngine("dl")
+		service := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = service.StreamToClient(ctx, "infohash-1", io.Discard)
+		}()
+		waitForClientSessions(t, service, 1)
+
+		errClosed := errors.New("client gone")
+		err := service.WatchStats(context.Background(), "infohash-1", 5*time.Millisecond, func(driven.StreamStats) error {
+			return errClosed
+		})
+		if !errors.Is(err, errClosed) {
+			t.Errorf("expected callback error, got %v", err)
+		}
+	})
+}
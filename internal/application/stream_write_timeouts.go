@@ -0,0 +1,93 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrInvalidWriteTimeoutRule indicates a write timeout rule that matches no
+// client, has a malformed user agent pattern or sets no timeout.
+var ErrInvalidWriteTimeoutRule = errors.New("write timeout rules need a user agent pattern or a token, and a timeout above zero")
+
+// WriteTimeoutRule gives the clients it matches their own write timeout, as
+// players stall differently: a smart TV may pause reading for far longer
+// than VLC before carrying on. A rule with both a pattern and a token only
+// matches clients meeting both.
+type WriteTimeoutRule struct {
+	// UserAgent is a regular expression matched against the client's
+	// User-Agent header.
+	UserAgent string
+	// Token matches the clients streaming with this session token.
+	Token   string
+	Timeout time.Duration
+}
+
+// writeTimeoutRules holds the write timeout rules and their compiled
+// user agent patterns, nil for rules without one.
+type writeTimeoutRules struct {
+	mu       sync.RWMutex
+	rules    []WriteTimeoutRule
+	patterns []*regexp.Regexp
+}
+
+// SetWriteTimeoutRules replaces the rules choosing a client's write timeout.
+// They are applied when a client connects, the first matching rule winning;
+// clients already streaming keep their timeout. A timeout hinted by the
+// client itself takes precedence, and clients matching no rule get the
+// default set with SetWriteTimeout.
+// Returns ErrInvalidWriteTimeoutRule if a rule is malformed.
+func (s *AceStreamProxyService) SetWriteTimeoutRules(rules []WriteTimeoutRule) error {
+	patterns := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		if rule.Timeout <= 0 || (rule.UserAgent == "" && rule.Token == "") {
+			return ErrInvalidWriteTimeoutRule
+		}
+		if rule.UserAgent == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(rule.UserAgent)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidWriteTimeoutRule, err)
+		}
+		patterns[i] = pattern
+	}
+
+	s.timeoutRules.mu.Lock()
+	defer s.timeoutRules.mu.Unlock()
+	s.timeoutRules.rules = slices.Clone(rules)
+	s.timeoutRules.patterns = patterns
+	return nil
+}
+
+// WriteTimeoutRules returns the rules choosing a client's write timeout.
+func (s *AceStreamProxyService) WriteTimeoutRules() []WriteTimeoutRule {
+	s.timeoutRules.mu.RLock()
+	defer s.timeoutRules.mu.RUnlock()
+	return slices.Clone(s.timeoutRules.rules)
+}
+
+// WriteTimeout returns the default timeout for writes to a client.
+func (s *AceStreamProxyService) WriteTimeout() time.Duration {
+	return time.Duration(s.writeTimeout.Load())
+}
+
+// clientWriteTimeout returns the write timeout of the first rule matching a
+// client, or the default if none does.
+func (s *AceStreamProxyService) clientWriteTimeout(userAgent, token string) time.Duration {
+	s.timeoutRules.mu.RLock()
+	defer s.timeoutRules.mu.RUnlock()
+	for i, rule := range s.timeoutRules.rules {
+		if rule.Token != "" && rule.Token != token {
+			continue
+		}
+		if pattern := s.timeoutRules.patterns[i]; pattern != nil && !pattern.MatchString(userAgent) {
+			continue
+		}
+		return rule.Timeout
+	}
+	return s.WriteTimeout()
+}
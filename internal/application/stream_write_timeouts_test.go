@@ -0,0 +1,55 @@
+package application
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestAceStreamProxyService_WriteTimeoutRules(t *testing.T) {
+	proxy := NewAceStreamProxyService(&mockAceStreamEngine{}, slog.Default(), 10*time.Second, nil)
+
+	for _, rules := range [][]WriteTimeoutRule{
+		{{UserAgent: "VLC", Timeout: 0}},
+		{{Timeout: time.Second}},
+		{{UserAgent: "(", Timeout: time.Second}},
+	} {
+		if err := proxy.SetWriteTimeoutRules(rules); !errors.Is(err, ErrInvalidWriteTimeoutRule) {
+			t.Errorf("SetWriteTimeoutRules(%+v): expected ErrInvalidWriteTimeoutRule, got %v", rules, err)
+		}
+	}
+
+	rules := []WriteTimeoutRule{
+		{Token: "living-room", Timeout: 5 * time.Second},
+		{UserAgent: "(?i)tizen|webos", Timeout: 30 * time.Second},
+		{UserAgent: "VLC", Token: "bedroom", Timeout: 20 * time.Second},
+	}
+	if err := proxy.SetWriteTimeoutRules(rules); err != nil {
+		t.Fatalf("SetWriteTimeoutRules() error = %v", err)
+	}
+	if got := proxy.WriteTimeoutRules(); len(got) != 3 || got[1] != rules[1] {
+		t.Errorf("WriteTimeoutRules() = %+v, want %+v", got, rules)
+	}
+
+	tests := []struct {
+		userAgent, token string
+		want             time.Duration
+	}{
+		{"Mozilla/5.0 (SMART-TV; Linux; Tizen 6.0)", "", 30 * time.Second},
+		{"Mozilla/5.0 (SMART-TV; Linux; Tizen 6.0)", "living-room", 5 * time.Second},
+		{"VLC/3.0.20 LibVLC/3.0.20", "bedroom", 20 * time.Second},
+		{"VLC/3.0.20 LibVLC/3.0.20", "", 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := proxy.clientWriteTimeout(tt.userAgent, tt.token); got != tt.want {
+			t.Errorf("clientWriteTimeout(%q, %q) = %v, want %v", tt.userAgent, tt.token, got, tt.want)
+		}
+	}
+
+	// The default follows SetWriteTimeout
+	proxy.SetWriteTimeout(time.Minute)
+	if got := proxy.clientWriteTimeout("curl/8.0", ""); got != time.Minute {
+		t.Errorf("expected clients matching no rule to get the default, got %v", got)
+	}
+}
@@ -21,10 +21,14 @@ const epgCacheTTL = 1 * time.Hour
 type SubscriptionService struct {
 	subscriptionRepo driven.SubscriptionRepository
 	epgFetcher       driven.EPGFetcher
+	events           *EventBus
 
-	epgCacheMu  sync.RWMutex
-	epgCache    []epg.Channel
-	epgCachedAt time.Time
+	epgCacheMu           sync.RWMutex
+	epgCache             []epg.Channel
+	epgCachedAt          time.Time
+	staleWhileRevalidate bool
+	epgRefreshing        bool
+	epgRefreshes         sync.WaitGroup
 }
 
 // NewSubscriptionService creates a new subscription service with the required dependencies.
@@ -38,6 +42,22 @@ func NewSubscriptionService(
 	}
 }
 
+// SetEventBus enables publishing EventOverrideUpdated when a subscription
+// is added, changed or removed.
+func (s *SubscriptionService) SetEventBus(events *EventBus) {
+	s.events = events
+}
+
+// SetStaleWhileRevalidate makes an expired EPG channel cache be served
+// immediately while a single background fetch refreshes it, instead of
+// making the caller wait for the upstream. The first fetch, with nothing
+// cached yet, is always waited for.
+func (s *SubscriptionService) SetStaleWhileRevalidate(enabled bool) {
+	s.epgCacheMu.Lock()
+	s.staleWhileRevalidate = enabled
+	s.epgCacheMu.Unlock()
+}
+
 // Subscribe creates a new subscription for the given EPG channel ID.
 // Returns subscription.ErrSubscriptionAlreadyExists if already subscribed.
 // Returns subscription.ErrEmptyEPGChannelID if epgChannelID is empty.
@@ -53,6 +73,7 @@ func (s *SubscriptionService) Subscribe(ctx context.Context, epgChannelID string
 		return fmt.Errorf("failed to save subscription: %w", err)
 	}
 
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "subscription", Name: epgChannelID})
 	return nil
 }
 
@@ -64,9 +85,54 @@ func (s *SubscriptionService) Unsubscribe(ctx context.Context, epgChannelID stri
 		return fmt.Errorf("failed to delete subscription: %w", err)
 	}
 
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "subscription", Name: epgChannelID})
 	return nil
 }
 
+// SubscriptionUpdate holds the subscription settings to change; nil fields
+// are left as is.
+type SubscriptionUpdate struct {
+	Paused *bool
+	// ExpiresAt is when the subscription stops contributing its channel;
+	// the zero time removes the expiry date.
+	ExpiresAt *time.Time
+	Notes     *string
+}
+
+// UpdateSubscription pauses or resumes a subscription and changes its
+// expiry date and notes.
+// Returns subscription.ErrNotesTooLong if the notes are too long.
+// Returns subscription.ErrSubscriptionNotFound if not subscribed.
+func (s *SubscriptionService) UpdateSubscription(ctx context.Context, epgChannelID string, update SubscriptionUpdate) (subscription.Subscription, error) {
+	sub, err := s.subscriptionRepo.FindByEPGID(ctx, epgChannelID)
+	if err != nil {
+		return subscription.Subscription{}, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	if update.Paused != nil {
+		if *update.Paused {
+			sub = sub.Pause()
+		} else {
+			sub = sub.Resume()
+		}
+	}
+	if update.ExpiresAt != nil {
+		sub = sub.WithExpiry(*update.ExpiresAt)
+	}
+	if update.Notes != nil {
+		if sub, err = sub.WithNotes(*update.Notes); err != nil {
+			return subscription.Subscription{}, err
+		}
+	}
+
+	if err := s.subscriptionRepo.Update(ctx, sub); err != nil {
+		return subscription.Subscription{}, fmt.Errorf("failed to update subscription: %w", err)
+	}
+
+	s.events.Publish(EventOverrideUpdated, OverrideEventData{Kind: "subscription", Name: epgChannelID})
+	return sub, nil
+}
+
 // ListSubscriptions retrieves all current subscriptions.
 func (s *SubscriptionService) ListSubscriptions(ctx context.Context) ([]subscription.Subscription, error) {
 	subscriptions, err := s.subscriptionRepo.FindAll(ctx)
@@ -110,7 +176,9 @@ func (s *SubscriptionService) ListAvailableEPGChannels(ctx context.Context, filt
 }
 
 // getCachedEPGChannels returns cached EPG channels or fetches fresh data if the cache
-// has expired. Uses double-checked locking to minimize lock contention.
+// has expired. Uses double-checked locking to minimize lock contention. In
+// stale-while-revalidate mode an expired cache is returned as is and
+// refreshed in the background.
 func (s *SubscriptionService) getCachedEPGChannels(ctx context.Context) ([]epg.Channel, error) {
 	s.epgCacheMu.RLock()
 	if s.epgCache != nil && time.Since(s.epgCachedAt) < epgCacheTTL {
@@ -128,6 +196,16 @@ func (s *SubscriptionService) getCachedEPGChannels(ctx context.Context) ([]epg.C
 		return s.epgCache, nil
 	}
 
+	if s.epgCache != nil && s.staleWhileRevalidate {
+		if !s.epgRefreshing {
+			s.epgRefreshing = true
+			s.epgRefreshes.Add(1)
+			// The refresh must outlive the request that triggered it
+			go s.refreshEPGChannels(context.WithoutCancel(ctx))
+		}
+		return s.epgCache, nil
+	}
+
 	channels, err := s.epgFetcher.FetchEPG(ctx)
 	if err != nil {
 		return nil, err
@@ -138,6 +216,23 @@ func (s *SubscriptionService) getCachedEPGChannels(ctx context.Context) ([]epg.C
 	return channels, nil
 }
 
+// refreshEPGChannels fetches EPG channels into the cache in the background.
+// On failure the stale cache is kept and the next request retries.
+func (s *SubscriptionService) refreshEPGChannels(ctx context.Context) {
+	defer s.epgRefreshes.Done()
+
+	channels, err := s.epgFetcher.FetchEPG(ctx)
+
+	s.epgCacheMu.Lock()
+	defer s.epgCacheMu.Unlock()
+	s.epgRefreshing = false
+	if err != nil {
+		return
+	}
+	s.epgCache = channels
+	s.epgCachedAt = time.Now()
+}
+
 // matchesFilter checks if a channel matches the provided filter criteria.
 // All non-empty filter fields must match (AND logic).
 func (s *SubscriptionService) matchesFilter(ch epg.Channel, filter ChannelFilter) bool {
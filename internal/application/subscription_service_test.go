@@ -2,8 +2,10 @@ package application
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alorle/iptv-manager/internal/epg"
 	"github.com/alorle/iptv-manager/internal/subscription"
@@ -24,6 +26,9 @@ type stubSubscriptionRepo struct{}
 func (r *stubSubscriptionRepo) Save(ctx context.Context, sub subscription.Subscription) error {
 	return nil
 }
+func (r *stubSubscriptionRepo) Update(ctx context.Context, sub subscription.Subscription) error {
+	return nil
+}
 func (r *stubSubscriptionRepo) FindAll(ctx context.Context) ([]subscription.Subscription, error) {
 	return nil, nil
 }
@@ -63,3 +68,52 @@ func TestSubscriptionService_ListAvailableEPGChannels_Caching(t *testing.T) {
 		t.Fatalf("expected still 1 fetch call after cache hit, got %d", fetcher.callCount.Load())
 	}
 }
+
+// gatedEPGFetcher returns its current channels once release is signalled.
+type gatedEPGFetcher struct {
+	countingEPGFetcher
+	release chan struct{}
+}
+
+func (f *gatedEPGFetcher) FetchEPG(ctx context.Context) ([]epg.Channel, error) {
+	<-f.release
+	return f.countingEPGFetcher.FetchEPG(ctx)
+}
+
+func TestSubscriptionService_ListAvailableEPGChannels_StaleWhileRevalidate(t *testing.T) {
+	oldCh, _ := epg.NewChannel("old", "Old", "", "General", "en", "old")
+	newCh, _ := epg.NewChannel("new", "New", "", "General", "en", "new")
+	fetcher := &gatedEPGFetcher{release: make(chan struct{})}
+	service := NewSubscriptionService(&stubSubscriptionRepo{}, fetcher)
+	service.SetStaleWhileRevalidate(true)
+
+	// Seed an expired cache
+	service.epgCache = []epg.Channel{oldCh}
+	service.epgCachedAt = time.Now().Add(-2 * epgCacheTTL)
+	fetcher.channels = []epg.Channel{newCh}
+
+	// Concurrent requests are served the stale channels without waiting
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			channels, err := service.ListAvailableEPGChannels(context.Background(), ChannelFilter{})
+			if err != nil || len(channels) != 1 || channels[0].ID() != "old" {
+				t.Errorf("expected stale channels, got %v (err=%v)", channels, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	close(fetcher.release)
+	service.epgRefreshes.Wait()
+
+	if got := fetcher.callCount.Load(); got != 1 {
+		t.Errorf("expected a single background fetch, got %d", got)
+	}
+	channels, err := service.ListAvailableEPGChannels(context.Background(), ChannelFilter{})
+	if err != nil || len(channels) != 1 || channels[0].ID() != "new" {
+		t.Errorf("expected refreshed channels, got %v (err=%v)", channels, err)
+	}
+}
@@ -0,0 +1,120 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/playlist"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/user"
+)
+
+// UserService provides use cases for managing the users a server is shared
+// with and serving each of them their own playlist.
+type UserService struct {
+	userRepo driven.UserRepository
+	playlist *PlaylistService
+}
+
+// NewUserService creates a new UserService. The playlist service renders
+// the playlists of users.
+func NewUserService(userRepo driven.UserRepository, playlist *PlaylistService) *UserService {
+	return &UserService{
+		userRepo: userRepo,
+		playlist: playlist,
+	}
+}
+
+// CreateUser adds a user who may see the given channels, by name, and the
+// channels of the given groups, by ID or name.
+// Returns user.ErrEmptyName if the name is empty.
+func (s *UserService) CreateUser(ctx context.Context, name string, channels, groups []string) (user.User, error) {
+	u, err := user.NewUser(name, channels, slugGroups(groups), time.Now())
+	if err != nil {
+		return user.User{}, err
+	}
+
+	if err := s.userRepo.Save(ctx, u); err != nil {
+		return user.User{}, err
+	}
+	return u, nil
+}
+
+// ListUsers retrieves all users, oldest first.
+func (s *UserService) ListUsers(ctx context.Context) ([]user.User, error) {
+	return s.userRepo.FindAll(ctx)
+}
+
+// GetUser retrieves a user by ID.
+// Returns user.ErrUserNotFound if the user does not exist.
+func (s *UserService) GetUser(ctx context.Context, id string) (user.User, error) {
+	return s.userRepo.FindByID(ctx, id)
+}
+
+// UpdateUser renames a user and replaces the channels and groups they may
+// see. Their playlist URL is kept.
+// Returns user.ErrUserNotFound if the user does not exist, or
+// user.ErrEmptyName if the name is empty.
+func (s *UserService) UpdateUser(ctx context.Context, id, name string, channels, groups []string) (user.User, error) {
+	u, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u, err = u.Update(name, channels, slugGroups(groups))
+	if err != nil {
+		return user.User{}, err
+	}
+
+	if err := s.userRepo.Save(ctx, u); err != nil {
+		return user.User{}, err
+	}
+	return u, nil
+}
+
+// RegeneratePlaylistToken gives a user a new playlist URL; the old one
+// stops working.
+// Returns user.ErrUserNotFound if the user does not exist.
+func (s *UserService) RegeneratePlaylistToken(ctx context.Context, id string) (user.User, error) {
+	u, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u, err = u.WithNewPlaylistToken()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	if err := s.userRepo.Save(ctx, u); err != nil {
+		return user.User{}, err
+	}
+	return u, nil
+}
+
+// DeleteUser removes a user, revoking their playlist URL.
+// Returns user.ErrUserNotFound if the user does not exist.
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+	return s.userRepo.Delete(ctx, id)
+}
+
+// Playlist renders the playlist of the user owning token in the given
+// format, listing only the channels the user may see.
+// Returns user.ErrUserNotFound if no user has the token.
+func (s *UserService) Playlist(ctx context.Context, token, baseURL string, format playlist.Format) ([]byte, error) {
+	u, err := s.userRepo.FindByPlaylistToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return s.playlist.GenerateFor(ctx, baseURL, format, u)
+}
+
+// slugGroups converts group names to IDs; IDs are left as they are.
+func slugGroups(groups []string) []string {
+	ids := make([]string, len(groups))
+	for i, g := range groups {
+		ids[i] = group.Slug(g)
+	}
+	return ids
+}
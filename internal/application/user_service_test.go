@@ -0,0 +1,149 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/group"
+	"github.com/alorle/iptv-manager/internal/playlist"
+	"github.com/alorle/iptv-manager/internal/stream"
+	"github.com/alorle/iptv-manager/internal/user"
+)
+
+// memUserRepository is an in-memory driven.UserRepository for testing.
+type memUserRepository struct {
+	users map[string]user.User
+}
+
+func (r *memUserRepository) Save(ctx context.Context, u user.User) error {
+	r.users[u.ID()] = u
+	return nil
+}
+
+func (r *memUserRepository) FindAll(ctx context.Context) ([]user.User, error) {
+	users := make([]user.User, 0, len(r.users))
+	for _, u := range r.users {
+		users = append(users, u)
+	}
+	user.Sort(users)
+	return users, nil
+}
+
+func (r *memUserRepository) FindByID(ctx context.Context, id string) (user.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return user.User{}, user.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (r *memUserRepository) FindByPlaylistToken(ctx context.Context, token string) (user.User, error) {
+	for _, u := range r.users {
+		if u.PlaylistToken() == token {
+			return u, nil
+		}
+	}
+	return user.User{}, user.ErrUserNotFound
+}
+
+func (r *memUserRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := r.users[id]; !ok {
+		return user.ErrUserNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func newUserTestService() *UserService {
+	la1, _ := stream.NewStream("6c61310000000000000000000000000000000000", "La 1", stream.SourceManual)
+	clan, _ := stream.NewStream("636c616e00000000000000000000000000000000", "Clan", stream.SourceManual)
+	dazn, _ := stream.NewStream("64617a6e00000000000000000000000000000000", "DAZN 1", stream.SourceManual)
+	streamRepo := &mockStreamRepository{
+		findAllFunc: func(ctx context.Context) ([]stream.Stream, error) {
+			return []stream.Stream{la1, clan, dazn}, nil
+		},
+	}
+
+	clanCh, _ := channel.NewChannel("Clan")
+	clanCh.SetGroup("kids-tv")
+	channelRepo, _ := newMemChannelRepository(clanCh)
+	kids, _ := group.NewGroup("Kids TV")
+
+	playlistService := NewPlaylistService(streamRepo, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+	playlistService.SetGroupRepository(newMemGroupRepository(kids))
+
+	return NewUserService(&memUserRepository{users: make(map[string]user.User)}, playlistService)
+}
+
+func TestUserService_Playlist(t *testing.T) {
+	ctx := context.Background()
+	service := newUserTestService()
+
+	u, err := service.CreateUser(ctx, "Kids", []string{"La 1"}, []string{"Kids TV"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(u.Groups(), []string{"kids-tv"}) {
+		t.Errorf("expected group names to be stored as IDs, got %q", u.Groups())
+	}
+
+	data, err := service.Playlist(ctx, u.PlaylistToken(), "http://localhost:8080", playlist.M3U)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m3u := string(data)
+	if !strings.Contains(m3u, "La 1 - 6c61310000000000000000000000000000000000") || !strings.Contains(m3u, "Clan - 636c616e00000000000000000000000000000000") {
+		t.Errorf("expected the user's channel and group to be listed, got:\n%s", m3u)
+	}
+	if strings.Contains(m3u, "DAZN") {
+		t.Errorf("expected other channels to be left out, got:\n%s", m3u)
+	}
+
+	if _, err := service.Playlist(ctx, "unknown", "http://localhost:8080", playlist.M3U); !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestUserService_ManageUsers(t *testing.T) {
+	ctx := context.Background()
+	service := newUserTestService()
+
+	if _, err := service.CreateUser(ctx, " ", nil, nil); !errors.Is(err, user.ErrEmptyName) {
+		t.Errorf("expected ErrEmptyName, got %v", err)
+	}
+
+	u, _ := service.CreateUser(ctx, "Grandma", []string{"La 1"}, nil)
+	updated, err := service.UpdateUser(ctx, u.ID(), "Grandma", []string{"DAZN 1"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.PlaylistToken() != u.PlaylistToken() || !slices.Equal(updated.Channels(), []string{"DAZN 1"}) {
+		t.Errorf("unexpected update %+v", updated)
+	}
+
+	rotated, err := service.RegeneratePlaylistToken(ctx, u.ID())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.Playlist(ctx, u.PlaylistToken(), "http://localhost:8080", playlist.M3U); !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("expected the old playlist URL to stop working, got %v", err)
+	}
+	if _, err := service.Playlist(ctx, rotated.PlaylistToken(), "http://localhost:8080", playlist.M3U); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := service.DeleteUser(ctx, u.ID()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.UpdateUser(ctx, u.ID(), "Grandma", nil, nil); !errors.Is(err, user.ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+	if users, _ := service.ListUsers(ctx); len(users) != 0 {
+		t.Errorf("expected no users, got %d", len(users))
+	}
+}
@@ -0,0 +1,292 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/epg"
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// MaxWarmupDuration bounds how long a single warm-up keeps a stream open.
+const MaxWarmupDuration = 6 * time.Hour
+
+// warmupRetryDelay is how long a warm-up waits before re-attaching to a
+// stream that ended before the warm-up did.
+const warmupRetryDelay = 5 * time.Second
+
+// warmupGuideRefresh is how long the programme guide fetched for scheduled
+// warm-ups is used before it is fetched again.
+const warmupGuideRefresh = time.Hour
+
+// ErrInvalidWarmupDuration indicates a warm-up duration that is not positive
+// or exceeds MaxWarmupDuration.
+var ErrInvalidWarmupDuration = errors.New("invalid warm-up duration")
+
+// Warmup is a stream kept open without viewers.
+type Warmup struct {
+	InfoHash string
+	Until    time.Time
+}
+
+// WarmupService keeps streams open server-side for a while without anyone
+// watching, so the engine has joined the swarm and buffered by the time
+// viewers tune in, for instance at the start of a match. A warm-up joins the
+// stream through the proxy like any other client and discards what it
+// receives; viewers arriving meanwhile share its engine stream.
+type WarmupService struct {
+	proxy  *AceStreamProxyService
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu     sync.Mutex
+	active map[string]*activeWarmup
+	wg     sync.WaitGroup
+
+	// Scheduled warm-ups, see SetSchedule.
+	guide          driven.EPGProgrammeFetcher
+	channelRepo    driven.ChannelRepository
+	streamRepo     driven.StreamRepository
+	probe          *ProbeService
+	channels       []string
+	lead           time.Duration
+	programmes     []epg.Programme
+	guideFetchedAt time.Time
+}
+
+// activeWarmup is a warm-up in progress.
+type activeWarmup struct {
+	until  time.Time
+	ctx    context.Context
+	cancel context.CancelFunc
+	timer  *time.Timer
+}
+
+// NewWarmupService creates a new WarmupService on top of the proxy.
+func NewWarmupService(proxy *AceStreamProxyService, logger *slog.Logger) *WarmupService {
+	return &WarmupService{
+		proxy:  proxy,
+		logger: logger,
+		now:    time.Now,
+		active: make(map[string]*activeWarmup),
+	}
+}
+
+// SetSchedule makes RunSchedule warm up the given channels lead before each
+// programme in their guide starts, keeping them warm until lead after it
+// started. A channel's guide is found through its EPG mapping, and its best
+// stream is warmed.
+func (s *WarmupService) SetSchedule(guide driven.EPGProgrammeFetcher, channelRepo driven.ChannelRepository, streamRepo driven.StreamRepository, channels []string, lead time.Duration) {
+	s.guide = guide
+	s.channelRepo = channelRepo
+	s.streamRepo = streamRepo
+	s.channels = channels
+	s.lead = lead
+}
+
+// SetProbeService makes scheduled warm-ups pick a channel's best stream by
+// quality score rather than the first one.
+func (s *WarmupService) SetProbeService(probe *ProbeService) {
+	s.probe = probe
+}
+
+// Warm keeps the stream with infoHash open for d. Warming a stream that is
+// already warm extends its warm-up if d ends later.
+// Returns ErrInvalidInfoHash if infoHash is empty, and
+// ErrInvalidWarmupDuration if d is not positive or exceeds
+// MaxWarmupDuration.
+func (s *WarmupService) Warm(infoHash string, d time.Duration) (Warmup, error) {
+	if infoHash == "" {
+		return Warmup{}, ErrInvalidInfoHash
+	}
+	if d <= 0 || d > MaxWarmupDuration {
+		return Warmup{}, ErrInvalidWarmupDuration
+	}
+	return s.warm(infoHash, s.now().Add(d), ""), nil
+}
+
+// Warmups returns the warm-ups in progress ordered by infohash.
+func (s *WarmupService) Warmups() []Warmup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	warmups := make([]Warmup, 0, len(s.active))
+	for infoHash, active := range s.active {
+		warmups = append(warmups, Warmup{InfoHash: infoHash, Until: active.until})
+	}
+	slices.SortFunc(warmups, func(a, b Warmup) int {
+		return cmp.Compare(a.InfoHash, b.InfoHash)
+	})
+	return warmups
+}
+
+// warm starts or extends the warm-up of infoHash until the given time.
+// channelName only labels the warm-up's client session.
+func (s *WarmupService) warm(infoHash string, until time.Time, channelName string) Warmup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A warm-up that has ended but not yet left its stream is replaced
+	if active, ok := s.active[infoHash]; ok && active.ctx.Err() == nil {
+		if until.After(active.until) {
+			active.until = until
+			s.logger.Info("stream warm-up extended", "infohash", infoHash, "until", until)
+		}
+		return Warmup{InfoHash: infoHash, Until: active.until}
+	}
+
+	ctx := WithClientInfo(context.Background(), ClientInfo{UserAgent: "warmup", Channel: channelName})
+	ctx, cancel := context.WithCancel(ctx)
+	active := &activeWarmup{until: until, ctx: ctx, cancel: cancel}
+	active.timer = time.AfterFunc(until.Sub(s.now()), func() { s.expire(infoHash, active) })
+	s.active[infoHash] = active
+
+	s.logger.Info("stream warm-up started", "infohash", infoHash, "channel", channelName, "until", until)
+	s.wg.Add(1)
+	go s.run(ctx, infoHash, active)
+	return Warmup{InfoHash: infoHash, Until: until}
+}
+
+// expire ends the warm-up once its time is up, or re-arms its timer if it
+// was extended meanwhile.
+func (s *WarmupService) expire(infoHash string, active *activeWarmup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if remaining := active.until.Sub(s.now()); remaining > 0 {
+		active.timer.Reset(remaining)
+		return
+	}
+	active.cancel()
+}
+
+// run streams infoHash into the void until ctx ends, re-attaching whenever
+// the stream ends early.
+func (s *WarmupService) run(ctx context.Context, infoHash string, active *activeWarmup) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		if s.active[infoHash] == active {
+			delete(s.active, infoHash)
+		}
+		s.mu.Unlock()
+		active.timer.Stop()
+		active.cancel()
+	}()
+
+	for {
+		err := s.proxy.StreamToClient(ctx, infoHash, io.Discard)
+		if ctx.Err() != nil {
+			s.logger.Info("stream warm-up ended", "infohash", infoHash)
+			return
+		}
+		if err == nil {
+			err = errors.New("stream ended")
+		}
+
+		s.logger.Warn("warm-up stream ended early, retrying",
+			"infohash", infoHash,
+			"error", err,
+			"delay", warmupRetryDelay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(warmupRetryDelay):
+		}
+	}
+}
+
+// RunSchedule warms up the scheduled channels whose next programme starts
+// within the lead time. It is meant to be run by a scheduler.
+func (s *WarmupService) RunSchedule(ctx context.Context) error {
+	if s.guide == nil || len(s.channels) == 0 {
+		return nil
+	}
+
+	now := s.now()
+	if s.guideFetchedAt.IsZero() || now.Sub(s.guideFetchedAt) >= warmupGuideRefresh {
+		programmes, err := s.guide.FetchProgrammes(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch programme guide: %w", err)
+		}
+		s.programmes, s.guideFetchedAt = programmes, now
+	}
+
+	for _, name := range s.channels {
+		ch, err := s.channelRepo.FindByName(ctx, name)
+		if err != nil {
+			s.logger.Warn("failed to find channel to warm up", "channel", name, "error", err)
+			continue
+		}
+		mapping := ch.EPGMapping()
+		if mapping == nil {
+			s.logger.Debug("channel to warm up has no EPG mapping", "channel", name)
+			continue
+		}
+
+		for _, p := range s.programmes {
+			// The guide lists the channel's programmes shifted by its tvg-shift
+			start := p.Start().Add(ch.TVGShift())
+			if p.ChannelID() != mapping.EPGID() || !start.After(now) || start.Sub(now) > s.lead {
+				continue
+			}
+			if err := s.warmChannel(ctx, ch, start.Add(s.lead)); err != nil {
+				s.logger.Warn("failed to warm up channel", "channel", name, "programme", p.Title(), "error", err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// warmChannel warms the best stream of ch until the given time.
+func (s *WarmupService) warmChannel(ctx context.Context, ch channel.Channel, until time.Time) error {
+	streams, err := s.streamRepo.FindByChannelName(ctx, ch.Name())
+	if err != nil && !errors.Is(err, stream.ErrStreamNotFound) {
+		return err
+	}
+	if len(streams) == 0 {
+		return stream.ErrStreamNotFound
+	}
+
+	infoHashes := make([]string, len(streams))
+	for i, st := range streams {
+		infoHashes[i] = st.InfoHash()
+	}
+	if s.probe != nil {
+		infoHashes = s.probe.RankStreams(ctx, ch.Name(), infoHashes)
+	}
+	s.warm(infoHashes[0], until, ch.Name())
+	return nil
+}
+
+// Shutdown ends all warm-ups and waits for them to leave their streams, or
+// for ctx to end.
+func (s *WarmupService) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	for _, active := range s.active {
+		active.cancel()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,204 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/epg"
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+const warmupTestHash = "6e6577732d686173680000000000000000000000"
+
+// stubProgrammeFetcher returns a fixed programme guide, or err if set.
+type stubProgrammeFetcher struct {
+	programmes []epg.Programme
+	err        error
+	calls      int
+}
+
+func (f *stubProgrammeFetcher) FetchProgrammes(ctx context.Context) ([]epg.Programme, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.programmes, nil
+}
+
+// newWarmupTestService returns a WarmupService whose engine streams stay open
+// until stopped.
+func newWarmupTestService(t *testing.T) (*WarmupService, *AceStreamProxyService) {
+	t.Helper()
+
+	engine := &mockAceStreamEngine{
+		streamContentFunc: func(ctx context.Context, streamURL string, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	proxy := NewAceStreamProxyService(engine, slog.Default(), 10*time.Second, nil)
+	service := NewWarmupService(proxy, slog.Default())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = service.Shutdown(ctx)
+	})
+	return service, proxy
+}
+
+func TestWarmupService_Warm(t *testing.T) {
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		service, _ := newWarmupTestService(t)
+		if _, err := service.Warm("", time.Minute); !errors.Is(err, ErrInvalidInfoHash) {
+			t.Errorf("expected ErrInvalidInfoHash, got %v", err)
+		}
+		if _, err := service.Warm(warmupTestHash, 0); !errors.Is(err, ErrInvalidWarmupDuration) {
+			t.Errorf("expected ErrInvalidWarmupDuration for zero, got %v", err)
+		}
+		if _, err := service.Warm(warmupTestHash, MaxWarmupDuration+time.Second); !errors.Is(err, ErrInvalidWarmupDuration) {
+			t.Errorf("expected ErrInvalidWarmupDuration above the maximum, got %v", err)
+		}
+	})
+
+	t.Run("keeps the stream open for the duration", func(t *testing.T) {
+		service, proxy := newWarmupTestService(t)
+
+		w, err := service.Warm(warmupTestHash, 300*time.Millisecond)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if w.InfoHash != warmupTestHash || w.Until.IsZero() {
+			t.Errorf("unexpected warm-up %+v", w)
+		}
+
+		waitFor(t, func() bool { return proxy.IsStreamActive(warmupTestHash) })
+		sessions := proxy.ClientSessions()
+		if len(sessions) != 1 || sessions[0].UserAgent != "warmup" {
+			t.Errorf("expected one warm-up client session, got %+v", sessions)
+		}
+		if got := service.Warmups(); len(got) != 1 {
+			t.Errorf("expected one warm-up in progress, got %d", len(got))
+		}
+
+		waitFor(t, func() bool {
+			return len(service.Warmups()) == 0 && !proxy.IsStreamActive(warmupTestHash)
+		})
+	})
+
+	t.Run("warming again extends the warm-up", func(t *testing.T) {
+		service, proxy := newWarmupTestService(t)
+
+		first, _ := service.Warm(warmupTestHash, 100*time.Millisecond)
+		second, err := service.Warm(warmupTestHash, 500*time.Millisecond)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !second.Until.After(first.Until) {
+			t.Errorf("expected the warm-up to be extended past %v, got %v", first.Until, second.Until)
+		}
+		shorter, _ := service.Warm(warmupTestHash, 50*time.Millisecond)
+		if !shorter.Until.Equal(second.Until) {
+			t.Errorf("expected a shorter warm-up to keep %v, got %v", second.Until, shorter.Until)
+		}
+
+		time.Sleep(250 * time.Millisecond)
+		if !proxy.IsStreamActive(warmupTestHash) {
+			t.Error("expected the stream to still be open after the first duration")
+		}
+		waitFor(t, func() bool { return len(service.Warmups()) == 0 })
+	})
+}
+
+func TestWarmupService_RunSchedule(t *testing.T) {
+	now := time.Date(2024, 5, 1, 19, 55, 0, 0, time.UTC)
+
+	ch, _ := channel.NewChannel("Sport")
+	mapping, _ := channel.NewEPGMapping("sport-1", channel.MappingManual, now)
+	ch.SetEPGMapping(mapping)
+	unmapped, _ := channel.NewChannel("News")
+	channelRepo, _ := newMemChannelRepository(ch, unmapped)
+	st, _ := stream.NewStream(warmupTestHash, "Sport", stream.SourceManual)
+	streamRepo := &mockStreamRepository{
+		findByChannelNameFunc: func(ctx context.Context, channelName string) ([]stream.Stream, error) {
+			return []stream.Stream{st}, nil
+		},
+	}
+
+	final, _ := epg.NewProgramme("sport-1", "Final", now.Add(5*time.Minute), now.Add(2*time.Hour))
+	later, _ := epg.NewProgramme("sport-1", "Highlights", now.Add(3*time.Hour), now.Add(4*time.Hour))
+	other, _ := epg.NewProgramme("news-1", "Bulletin", now.Add(time.Minute), now.Add(time.Hour))
+	guide := &stubProgrammeFetcher{programmes: []epg.Programme{later, other, final}}
+
+	t.Run("warms channels whose programme starts within the lead", func(t *testing.T) {
+		service, proxy := newWarmupTestService(t)
+		service.SetSchedule(guide, channelRepo, streamRepo, []string{"Sport", "News", "Missing"}, 10*time.Minute)
+		service.now = func() time.Time { return now }
+
+		if err := service.RunSchedule(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		warmups := service.Warmups()
+		if len(warmups) != 1 || warmups[0].InfoHash != warmupTestHash {
+			t.Fatalf("expected the Sport stream to be warming, got %+v", warmups)
+		}
+		if want := final.Start().Add(10 * time.Minute); !warmups[0].Until.Equal(want) {
+			t.Errorf("expected the warm-up to last until %v, got %v", want, warmups[0].Until)
+		}
+		waitFor(t, func() bool { return proxy.IsStreamActive(warmupTestHash) })
+	})
+
+	t.Run("leaves channels alone outside the lead", func(t *testing.T) {
+		service, _ := newWarmupTestService(t)
+		service.SetSchedule(guide, channelRepo, streamRepo, []string{"Sport"}, time.Minute)
+		service.now = func() time.Time { return now }
+
+		if err := service.RunSchedule(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if warmups := service.Warmups(); len(warmups) != 0 {
+			t.Errorf("expected no warm-ups, got %+v", warmups)
+		}
+	})
+
+	t.Run("applies the channel's tvg-shift to programme times", func(t *testing.T) {
+		shifted, _ := channel.NewChannel("Sport")
+		shifted.SetEPGMapping(mapping)
+		_ = shifted.SetTVGShift(time.Hour)
+		channelRepo, _ := newMemChannelRepository(shifted)
+		service, _ := newWarmupTestService(t)
+		service.SetSchedule(guide, channelRepo, streamRepo, []string{"Sport"}, 10*time.Minute)
+		service.now = func() time.Time { return now }
+
+		if err := service.RunSchedule(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if warmups := service.Warmups(); len(warmups) != 0 {
+			t.Errorf("expected no warm-ups for a programme an hour away, got %+v", warmups)
+		}
+	})
+
+	t.Run("reuses the guide until it is due for a refresh", func(t *testing.T) {
+		service, _ := newWarmupTestService(t)
+		guide := &stubProgrammeFetcher{}
+		service.SetSchedule(guide, channelRepo, streamRepo, []string{"Sport"}, time.Minute)
+		service.now = func() time.Time { return now }
+
+		_ = service.RunSchedule(context.Background())
+		_ = service.RunSchedule(context.Background())
+		if guide.calls != 1 {
+			t.Errorf("expected the guide to be fetched once, got %d", guide.calls)
+		}
+
+		service.now = func() time.Time { return now.Add(warmupGuideRefresh) }
+		_ = service.RunSchedule(context.Background())
+		if guide.calls != 2 {
+			t.Errorf("expected the guide to be fetched again after the refresh interval, got %d", guide.calls)
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/port/driven"
+	"github.com/alorle/iptv-manager/internal/webhook"
+)
+
+// webhookQueueSize is how many notifications may wait for delivery before
+// further notifications are dropped.
+const webhookQueueSize = 64
+
+// WebhookService manages webhooks and notifies them of the significant
+// events published on the event bus.
+type WebhookService struct {
+	repo   driven.WebhookRepository
+	sender driven.WebhookSender
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewWebhookService creates a new webhook service.
+func NewWebhookService(repo driven.WebhookRepository, sender driven.WebhookSender, logger *slog.Logger) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		sender: sender,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// CreateWebhook validates and persists a new webhook.
+func (s *WebhookService) CreateWebhook(ctx context.Context, url string, format webhook.Format, chatID string, events []webhook.Event) (webhook.Webhook, error) {
+	w, err := webhook.NewWebhook(url, format, chatID, events, s.now())
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+	if err := s.repo.Save(ctx, w); err != nil {
+		return webhook.Webhook{}, err
+	}
+	return w, nil
+}
+
+// ListWebhooks returns all webhooks, oldest first.
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]webhook.Webhook, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// DeleteWebhook removes a webhook.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Run notifies webhooks of the events published on the bus until ctx is
+// done or the bus is closed. Notifications are queued and delivered one at a
+// time apart from the bus subscription, so slow webhooks don't make the
+// subscription fall behind and miss events. When webhookQueueSize
+// notifications are waiting, further ones are dropped rather than piling up
+// requests.
+func (s *WebhookService) Run(ctx context.Context, events *EventBus) {
+	ch, unsubscribe := events.Subscribe()
+	defer unsubscribe()
+
+	queue := make(chan webhook.Notification, webhookQueueSize)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := range queue {
+			if ctx.Err() != nil {
+				return
+			}
+			s.Notify(ctx, n)
+		}
+	}()
+	defer wg.Wait()
+	defer close(queue)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-ch:
+			if !ok {
+				return
+			}
+			n, ok := notificationFor(event)
+			if !ok {
+				continue
+			}
+			select {
+			case queue <- n:
+			default:
+				s.logger.Warn("webhook queue full, dropping notification", "event", n.Event)
+			}
+		}
+	}
+}
+
+// Notify posts a notification to every webhook subscribed to its event.
+// Delivery failures are logged and do not stop the other webhooks.
+func (s *WebhookService) Notify(ctx context.Context, n webhook.Notification) {
+	webhooks, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("failed to load webhooks", "error", err)
+		return
+	}
+
+	for _, w := range webhooks {
+		if !w.Subscribes(n.Event) {
+			continue
+		}
+		payload, err := w.Payload(n)
+		if err == nil {
+			err = s.sender.Send(ctx, w.URL(), payload)
+		}
+		if err != nil {
+			s.logger.Warn("webhook delivery failed", "webhook", w.ID(), "event", n.Event, "error", err)
+		}
+	}
+}
+
+// notificationFor translates a bus event into a webhook notification,
+// reporting false for events webhooks are not notified of.
+func notificationFor(e Event) (webhook.Notification, bool) {
+	n := webhook.Notification{Time: e.Time, Data: e.Data}
+
+	switch data := e.Data.(type) {
+	case EngineHealthData:
+		if data.Status != "error" {
+			return webhook.Notification{}, false
+		}
+		n.Event = webhook.EventEngineDown
+		n.Message = "AceStream engine is down: " + data.Error
+	case StreamFailoverData:
+		n.Event = webhook.EventStreamFailover
+		if data.InfoHash == "" {
+			n.Message = fmt.Sprintf("All %d streams of %s failed: %s", data.Attempts, data.Key, data.Error)
+		} else {
+			n.Message = fmt.Sprintf("%s failed over to stream %s after %d attempts", data.Key, data.InfoHash, data.Attempts)
+		}
+	case EPGSyncProgress:
+		if data.Phase != "failed" {
+			return webhook.Notification{}, false
+		}
+		n.Event = webhook.EventEPGSyncFailed
+		n.Message = "EPG sync failed: " + data.Error
+	case SourceChangeEventData:
+		n.Event = webhook.EventSourceChanged
+		var parts []string
+		if len(data.Added) > 0 {
+			parts = append(parts, "streams added for "+strings.Join(data.Added, ", "))
+		}
+		if len(data.Removed) > 0 {
+			parts = append(parts, "streams removed for "+strings.Join(data.Removed, ", "))
+		}
+		n.Message = fmt.Sprintf("Source %s changed: %s", data.Source, strings.Join(parts, "; "))
+	case RecordingEventData:
+		n.Event = webhook.EventRecordingFinished
+		n.Message = fmt.Sprintf("Recording of %s finished (%s, %d bytes)", data.ChannelName, data.Status, data.Bytes)
+		if data.Error != "" {
+			n.Message += ": " + data.Error
+		}
+	default:
+		return webhook.Notification{}, false
+	}
+	return n, true
+}
@@ -0,0 +1,219 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/webhook"
+)
+
+// memWebhookRepository is an in-memory driven.WebhookRepository for testing.
+type memWebhookRepository struct {
+	webhooks map[string]webhook.Webhook
+}
+
+func (r *memWebhookRepository) Save(ctx context.Context, w webhook.Webhook) error {
+	r.webhooks[w.ID()] = w
+	return nil
+}
+
+func (r *memWebhookRepository) FindAll(ctx context.Context) ([]webhook.Webhook, error) {
+	webhooks := make([]webhook.Webhook, 0, len(r.webhooks))
+	for _, w := range r.webhooks {
+		webhooks = append(webhooks, w)
+	}
+	webhook.Sort(webhooks)
+	return webhooks, nil
+}
+
+func (r *memWebhookRepository) Delete(ctx context.Context, id string) error {
+	if _, ok := r.webhooks[id]; !ok {
+		return webhook.ErrWebhookNotFound
+	}
+	delete(r.webhooks, id)
+	return nil
+}
+
+// sentWebhook is a payload delivered by recordingWebhookSender.
+type sentWebhook struct {
+	url     string
+	payload map[string]any
+}
+
+// recordingWebhookSender is a driven.WebhookSender that keeps what it sends.
+type recordingWebhookSender struct {
+	mu   sync.Mutex
+	sent []sentWebhook
+	err  error
+	done chan struct{}
+}
+
+func (s *recordingWebhookSender) Send(ctx context.Context, url string, payload []byte) error {
+	var body map[string]any
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.sent = append(s.sent, sentWebhook{url: url, payload: body})
+	s.mu.Unlock()
+	if s.done != nil {
+		s.done <- struct{}{}
+	}
+	return s.err
+}
+
+func TestWebhookService_Run(t *testing.T) {
+	ctx := context.Background()
+	sender := &recordingWebhookSender{done: make(chan struct{}, 10)}
+	service := NewWebhookService(&memWebhookRepository{webhooks: make(map[string]webhook.Webhook)}, sender, slog.Default())
+
+	if _, err := service.CreateWebhook(ctx, "https://example.com/all", "", "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.CreateWebhook(ctx, "https://discord.example.com/engine", webhook.FormatDiscord, "", []webhook.Event{webhook.EventEngineDown}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.CreateWebhook(ctx, "ftp://example.com", "", "", nil); !errors.Is(err, webhook.ErrInvalidURL) {
+		t.Errorf("expected ErrInvalidURL, got %v", err)
+	}
+
+	bus := NewEventBus()
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		service.Run(runCtx, bus)
+		close(done)
+	}()
+	for bus.SubscriberCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	bus.Publish(EventEngineHealth, EngineHealthData{Status: "ok"})
+	bus.Publish(EventStreamStarted, StreamEventData{InfoHash: "abc"})
+	bus.Publish(EventEngineHealth, EngineHealthData{Status: "error", Error: "connection refused"})
+	bus.Publish(EventRecordingFinished, RecordingEventData{ChannelName: "La 1", Status: "completed", Bytes: 42})
+	for range 3 {
+		select {
+		case <-sender.done:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for webhook deliveries")
+		}
+	}
+	cancel()
+	<-done
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	if len(sender.sent) != 3 {
+		t.Fatalf("expected 3 deliveries, got %+v", sender.sent)
+	}
+	if sender.sent[0].url != "https://example.com/all" || sender.sent[0].payload["event"] != "engine.down" ||
+		sender.sent[0].payload["message"] != "AceStream engine is down: connection refused" {
+		t.Errorf("unexpected generic delivery %+v", sender.sent[0])
+	}
+	if sender.sent[1].url != "https://discord.example.com/engine" || sender.sent[1].payload["content"] != "AceStream engine is down: connection refused" {
+		t.Errorf("unexpected discord delivery %+v", sender.sent[1])
+	}
+	if sender.sent[2].url != "https://example.com/all" || sender.sent[2].payload["event"] != "recording.finished" {
+		t.Errorf("expected only the unfiltered webhook to get the recording, got %+v", sender.sent[2])
+	}
+}
+
+// blockingWebhookSender is a driven.WebhookSender whose deliveries wait
+// until release is closed, like a slow receiver.
+type blockingWebhookSender struct {
+	recordingWebhookSender
+	release chan struct{}
+}
+
+func (s *blockingWebhookSender) Send(ctx context.Context, url string, payload []byte) error {
+	<-s.release
+	return s.recordingWebhookSender.Send(ctx, url, payload)
+}
+
+func TestWebhookService_Run_SlowReceiver(t *testing.T) {
+	ctx := context.Background()
+	sender := &blockingWebhookSender{
+		recordingWebhookSender: recordingWebhookSender{done: make(chan struct{}, 10)},
+		release:                make(chan struct{}),
+	}
+	service := NewWebhookService(&memWebhookRepository{webhooks: make(map[string]webhook.Webhook)}, sender, slog.Default())
+	if _, err := service.CreateWebhook(ctx, "https://example.com/all", "", "", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bus := NewEventBus()
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		service.Run(runCtx, bus)
+		close(done)
+	}()
+	for bus.SubscriberCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// While the receiver holds the first delivery, more events than the
+	// subscription buffers are published; the notification after them must
+	// not be lost.
+	bus.Publish(EventEngineHealth, EngineHealthData{Status: "error", Error: "connection refused"})
+	for i := range 2 * eventBufferSize {
+		bus.Publish(EventStreamStarted, StreamEventData{InfoHash: "abc"})
+		if i%8 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	bus.Publish(EventRecordingFinished, RecordingEventData{ChannelName: "La 1", Status: "completed", Bytes: 42})
+	time.Sleep(10 * time.Millisecond)
+	close(sender.release)
+
+	for range 2 {
+		select {
+		case <-sender.done:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for webhook deliveries")
+		}
+	}
+	cancel()
+	<-done
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	if len(sender.sent) != 2 || sender.sent[1].payload["event"] != "recording.finished" {
+		t.Fatalf("expected the engine and recording deliveries, got %+v", sender.sent)
+	}
+}
+
+func TestNotificationFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      any
+		wantEvent webhook.Event
+		wantMsg   string
+	}{
+		{"failover", StreamFailoverData{Key: "La 1", InfoHash: "abc", Attempts: 2}, webhook.EventStreamFailover, "La 1 failed over to stream abc after 2 attempts"},
+		{"failover exhausted", StreamFailoverData{Key: "La 1", Attempts: 3, Error: "stalled"}, webhook.EventStreamFailover, "All 3 streams of La 1 failed: stalled"},
+		{"epg sync failed", EPGSyncProgress{Phase: "failed", Error: "timeout"}, webhook.EventEPGSyncFailed, "EPG sync failed: timeout"},
+		{"source changed", SourceChangeEventData{Source: "new-era", Added: []string{"DAZN 1", "Clan"}, Removed: []string{"La 1"}}, webhook.EventSourceChanged,
+			"Source new-era changed: streams added for DAZN 1, Clan; streams removed for La 1"},
+		{"recording failed", RecordingEventData{ChannelName: "La 1", Status: "failed", Error: "no data received"}, webhook.EventRecordingFinished,
+			"Recording of La 1 finished (failed, 0 bytes): no data received"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, ok := notificationFor(Event{Data: tt.data})
+			if !ok || n.Event != tt.wantEvent || n.Message != tt.wantMsg {
+				t.Errorf("notificationFor() = (%q, %q, %v), want (%q, %q)", n.Event, n.Message, ok, tt.wantEvent, tt.wantMsg)
+			}
+		})
+	}
+
+	if _, ok := notificationFor(Event{Data: EPGSyncProgress{Phase: "completed"}}); ok {
+		t.Error("expected successful syncs not to notify")
+	}
+}
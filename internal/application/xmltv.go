@@ -0,0 +1,99 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/epg"
+	"github.com/alorle/iptv-manager/internal/epg/xmltv"
+	"github.com/alorle/iptv-manager/internal/favorite"
+)
+
+// playlistGuideRefresh is how long the programme guide listed in XMLTV
+// documents is reused before it is fetched again.
+const playlistGuideRefresh = time.Hour
+
+// GenerateXMLTV generates an XMLTV document listing every EPG-mapped channel,
+// using the EPG ID as the channel id so it matches the tvg-id attributes of
+// the M3U playlist, along with their programmes when a guide is set, moved by
+// the tvg-shift of their channel. When no channel is mapped yet, a valid empty
+// <tv> document is returned so players that require a reachable EPG URL still
+// work.
+func (p *PlaylistService) GenerateXMLTV(ctx context.Context) ([]byte, error) {
+	return p.generateXMLTV(ctx, nil)
+}
+
+// GenerateXMLTVForFavorites generates the XMLTV document of a favorite list,
+// like GenerateXMLTV but listing only the list's channels.
+func (p *PlaylistService) GenerateXMLTVForFavorites(ctx context.Context, list favorite.List) ([]byte, error) {
+	listed := make(map[string]bool)
+	for _, name := range list.Channels() {
+		listed[name] = true
+	}
+	return p.generateXMLTV(ctx, func(channelName string) bool { return listed[channelName] })
+}
+
+// generateXMLTV generates an XMLTV document listing the EPG-mapped channels
+// include accepts, or all of them if include is nil.
+func (p *PlaylistService) generateXMLTV(ctx context.Context, include func(channelName string) bool) ([]byte, error) {
+	channels, err := p.channelRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	guide := make([]xmltv.Channel, 0, len(channels))
+	shift := make(map[string]time.Duration, len(channels))
+	for _, ch := range channels {
+		if include != nil && !include(ch.Name()) {
+			continue
+		}
+		if m := ch.EPGMapping(); m != nil && m.EPGID() != "" {
+			guide = append(guide, xmltv.Channel{ID: m.EPGID(), DisplayName: ch.Name()})
+			// The document lists an EPG ID once, as its first channel
+			if _, ok := shift[m.EPGID()]; !ok {
+				shift[m.EPGID()] = ch.TVGShift()
+			}
+		}
+	}
+
+	var programmes []xmltv.Programme
+	for _, pr := range p.guideProgrammes(ctx) {
+		offset := shift[pr.ChannelID()]
+		programmes = append(programmes, xmltv.Programme{
+			ChannelID: pr.ChannelID(),
+			Title:     pr.Title(),
+			Start:     pr.Start().Add(offset),
+			Stop:      pr.Stop().Add(offset),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := xmltv.Encode(&buf, guide, programmes); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// guideProgrammes returns the programmes of the guide, fetching them again
+// when they are older than playlistGuideRefresh. A failed fetch keeps the
+// programmes fetched last, if any, so the XMLTV document is still served.
+func (p *PlaylistService) guideProgrammes(ctx context.Context) []epg.Programme {
+	if p.guide == nil {
+		return nil
+	}
+
+	p.guideMu.Lock()
+	defer p.guideMu.Unlock()
+	if !p.guideFetchedAt.IsZero() && time.Since(p.guideFetchedAt) < playlistGuideRefresh {
+		return p.programmes
+	}
+	programmes, err := p.guide.FetchProgrammes(ctx)
+	if err != nil {
+		slog.Warn("failed to fetch programme guide", "error", err)
+		return p.programmes
+	}
+	p.programmes, p.guideFetchedAt = programmes, time.Now()
+	return p.programmes
+}
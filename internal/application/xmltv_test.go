@@ -0,0 +1,211 @@
+package application
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+	"github.com/alorle/iptv-manager/internal/epg"
+)
+
+// xmltvDocument mirrors the parts of an XMLTV document the tests inspect.
+type xmltvDocument struct {
+	XMLName           xml.Name `xml:"tv"`
+	GeneratorInfoName string   `xml:"generator-info-name,attr"`
+	Channels          []struct {
+		ID          string `xml:"id,attr"`
+		DisplayName string `xml:"display-name"`
+	} `xml:"channel"`
+	Programmes []struct {
+		Start   string `xml:"start,attr"`
+		Stop    string `xml:"stop,attr"`
+		Channel string `xml:"channel,attr"`
+		Title   string `xml:"title"`
+	} `xml:"programme"`
+}
+
+func TestPlaylistService_GenerateXMLTV(t *testing.T) {
+	t.Run("returns empty but valid document when no channels are mapped", func(t *testing.T) {
+		unmapped, _ := channel.NewChannel("Unmapped")
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{unmapped}, nil
+			},
+		}
+		service := NewPlaylistService(&mockStreamRepository{}, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+
+		doc, err := service.GenerateXMLTV(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if !strings.HasPrefix(string(doc), "<?xml") {
+			t.Errorf("expected XML declaration, got %q", doc)
+		}
+
+		var parsed xmltvDocument
+		if err := xml.Unmarshal(doc, &parsed); err != nil {
+			t.Fatalf("document is not valid XML: %v", err)
+		}
+		if parsed.XMLName.Local != "tv" {
+			t.Errorf("expected root element <tv>, got <%s>", parsed.XMLName.Local)
+		}
+		if parsed.GeneratorInfoName != "iptv-manager" {
+			t.Errorf("expected generator-info-name 'iptv-manager', got %q", parsed.GeneratorInfoName)
+		}
+		if len(parsed.Channels) != 0 {
+			t.Errorf("expected no channels, got %d", len(parsed.Channels))
+		}
+	})
+
+	t.Run("lists mapped channels by EPG ID", func(t *testing.T) {
+		ch1, _ := channel.NewChannel("Channel B")
+		m1, _ := channel.NewEPGMapping("b.tv", channel.MappingAuto, time.Now())
+		ch1.SetEPGMapping(m1)
+		ch2, _ := channel.NewChannel("Channel A")
+		m2, _ := channel.NewEPGMapping("a.tv", channel.MappingManual, time.Now())
+		ch2.SetEPGMapping(m2)
+		ch3, _ := channel.NewChannel("Unmapped")
+
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{ch1, ch2, ch3}, nil
+			},
+		}
+		service := NewPlaylistService(&mockStreamRepository{}, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+
+		doc, err := service.GenerateXMLTV(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		var parsed xmltvDocument
+		if err := xml.Unmarshal(doc, &parsed); err != nil {
+			t.Fatalf("document is not valid XML: %v", err)
+		}
+		if len(parsed.Channels) != 2 {
+			t.Fatalf("expected 2 channels, got %d", len(parsed.Channels))
+		}
+		if parsed.Channels[0].ID != "a.tv" || parsed.Channels[0].DisplayName != "Channel A" {
+			t.Errorf("unexpected first channel: %+v", parsed.Channels[0])
+		}
+		if parsed.Channels[1].ID != "b.tv" || parsed.Channels[1].DisplayName != "Channel B" {
+			t.Errorf("unexpected second channel: %+v", parsed.Channels[1])
+		}
+	})
+
+	t.Run("lists the guide's programmes of the listed channels", func(t *testing.T) {
+		ch, _ := channel.NewChannel("Channel A")
+		m, _ := channel.NewEPGMapping("a.tv", channel.MappingManual, time.Now())
+		ch.SetEPGMapping(m)
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{ch}, nil
+			},
+		}
+		start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+		news, _ := epg.NewProgramme("a.tv", "News", start, start.Add(time.Hour))
+		other, _ := epg.NewProgramme("z.tv", "Other", start, start.Add(time.Hour))
+		guide := &stubProgrammeFetcher{programmes: []epg.Programme{news, other}}
+
+		service := NewPlaylistService(&mockStreamRepository{}, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+		service.SetGuide(guide)
+
+		for range 2 {
+			doc, err := service.GenerateXMLTV(context.Background())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			var parsed xmltvDocument
+			if err := xml.Unmarshal(doc, &parsed); err != nil {
+				t.Fatalf("document is not valid XML: %v", err)
+			}
+			if len(parsed.Programmes) != 1 {
+				t.Fatalf("expected 1 programme, got %+v", parsed.Programmes)
+			}
+			got := parsed.Programmes[0]
+			if got.Channel != "a.tv" || got.Title != "News" || got.Start != "20240501200000 +0000" || got.Stop != "20240501210000 +0000" {
+				t.Errorf("unexpected programme: %+v", got)
+			}
+		}
+		if guide.calls != 1 {
+			t.Errorf("expected the guide to be fetched once, got %d fetches", guide.calls)
+		}
+	})
+
+	t.Run("moves programmes by the tvg-shift of their channel", func(t *testing.T) {
+		ch, _ := channel.NewChannel("Channel A")
+		m, _ := channel.NewEPGMapping("a.tv", channel.MappingManual, time.Now())
+		ch.SetEPGMapping(m)
+		if err := ch.SetTVGShift(-2 * time.Hour); err != nil {
+			t.Fatalf("SetTVGShift: %v", err)
+		}
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{ch}, nil
+			},
+		}
+		start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+		news, _ := epg.NewProgramme("a.tv", "News", start, start.Add(time.Hour))
+
+		service := NewPlaylistService(&mockStreamRepository{}, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+		service.SetGuide(&stubProgrammeFetcher{programmes: []epg.Programme{news}})
+
+		doc, err := service.GenerateXMLTV(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var parsed xmltvDocument
+		if err := xml.Unmarshal(doc, &parsed); err != nil {
+			t.Fatalf("document is not valid XML: %v", err)
+		}
+		if len(parsed.Programmes) != 1 {
+			t.Fatalf("expected 1 programme, got %+v", parsed.Programmes)
+		}
+		if got := parsed.Programmes[0]; got.Start != "20240501180000 +0000" || got.Stop != "20240501190000 +0000" {
+			t.Errorf("expected the programme shifted back 2 hours, got %+v", got)
+		}
+	})
+
+	t.Run("serves channels without programmes when the guide fails", func(t *testing.T) {
+		ch, _ := channel.NewChannel("Channel A")
+		m, _ := channel.NewEPGMapping("a.tv", channel.MappingManual, time.Now())
+		ch.SetEPGMapping(m)
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return []channel.Channel{ch}, nil
+			},
+		}
+		service := NewPlaylistService(&mockStreamRepository{}, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+		service.SetGuide(&stubProgrammeFetcher{err: errors.New("source down")})
+
+		doc, err := service.GenerateXMLTV(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var parsed xmltvDocument
+		if err := xml.Unmarshal(doc, &parsed); err != nil {
+			t.Fatalf("document is not valid XML: %v", err)
+		}
+		if len(parsed.Channels) != 1 || len(parsed.Programmes) != 0 {
+			t.Errorf("expected 1 channel and no programmes, got %+v", parsed)
+		}
+	})
+
+	t.Run("returns error when channels cannot be listed", func(t *testing.T) {
+		channelRepo := &mockChannelRepository{
+			findAllFunc: func(ctx context.Context) ([]channel.Channel, error) {
+				return nil, errors.New("db error")
+			},
+		}
+		service := NewPlaylistService(&mockStreamRepository{}, channelRepo, &mockProbeRepository{}, 24*time.Hour)
+
+		if _, err := service.GenerateXMLTV(context.Background()); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
@@ -0,0 +1,68 @@
+// Package audit models the trail of changes made to the lineup through the
+// API, kept so an operator can tell what changed, when and by whom.
+package audit
+
+import (
+	"strings"
+	"time"
+)
+
+// Action names the kind of change an entry records.
+type Action string
+
+const (
+	// ActionStreamMoved records a stream reassigned to another channel.
+	// The subject is the stream's infohash; From and To are channel names.
+	ActionStreamMoved Action = "stream.moved"
+)
+
+// Entry records a single change. It is an immutable value object.
+type Entry struct {
+	action  Action
+	subject string
+	from    string
+	to      string
+	actor   string
+	at      time.Time
+}
+
+// NewEntry creates an entry with validation. The actor identifies who made
+// the change, such as an API token ID, and is empty if unknown.
+// Returns ErrEmptyAction or ErrEmptySubject.
+func NewEntry(action Action, subject, from, to, actor string, at time.Time) (Entry, error) {
+	if strings.TrimSpace(string(action)) == "" {
+		return Entry{}, ErrEmptyAction
+	}
+	subject = strings.TrimSpace(subject)
+	if subject == "" {
+		return Entry{}, ErrEmptySubject
+	}
+	return Entry{
+		action:  action,
+		subject: subject,
+		from:    from,
+		to:      to,
+		actor:   actor,
+		at:      at,
+	}, nil
+}
+
+// ReconstructEntry rebuilds an Entry from persisted state.
+// Intended for repository adapters only — bypasses validation.
+func ReconstructEntry(action Action, subject, from, to, actor string, at time.Time) Entry {
+	return Entry{
+		action:  action,
+		subject: subject,
+		from:    from,
+		to:      to,
+		actor:   actor,
+		at:      at,
+	}
+}
+
+func (e Entry) Action() Action  { return e.action }
+func (e Entry) Subject() string { return e.subject }
+func (e Entry) From() string    { return e.from }
+func (e Entry) To() string      { return e.to }
+func (e Entry) Actor() string   { return e.actor }
+func (e Entry) At() time.Time   { return e.at }
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewEntry(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		action    Action
+		subject   string
+		wantError error
+	}{
+		{name: "valid entry", action: ActionStreamMoved, subject: " abc "},
+		{name: "empty action", action: " ", subject: "abc", wantError: ErrEmptyAction},
+		{name: "empty subject", action: ActionStreamMoved, subject: "", wantError: ErrEmptySubject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEntry(tt.action, tt.subject, "La 1", "La 1 HD", "token-1", now)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NewEntry() error = %v, want %v", err, tt.wantError)
+			}
+			if err == nil && (e.Subject() != "abc" || e.From() != "La 1" || e.To() != "La 1 HD" || e.Actor() != "token-1" || !e.At().Equal(now)) {
+				t.Errorf("unexpected entry %+v", e)
+			}
+		})
+	}
+}
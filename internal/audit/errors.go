@@ -0,0 +1,9 @@
+package audit
+
+import "errors"
+
+// Domain errors for audit entries.
+var (
+	ErrEmptyAction  = errors.New("audit action cannot be empty")
+	ErrEmptySubject = errors.New("audit subject cannot be empty")
+)
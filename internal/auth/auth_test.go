@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewToken(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("generates an ID and a secret that matches its hash", func(t *testing.T) {
+		tok, secret, err := NewToken("  living room tv  ", now)
+		if err != nil {
+			t.Fatalf("NewToken() error = %v", err)
+		}
+		if tok.Name() != "living room tv" {
+			t.Errorf("Name() = %q, want trimmed name", tok.Name())
+		}
+		if tok.ID() == "" {
+			t.Error("expected non-empty ID")
+		}
+		if !strings.HasPrefix(secret, tokenPrefix) {
+			t.Errorf("expected secret to start with %q, got %q", tokenPrefix, secret)
+		}
+		if tok.SecretHash() == secret {
+			t.Error("secret must not be stored in plaintext")
+		}
+		if !tok.Matches(secret) {
+			t.Error("expected token to match its own secret")
+		}
+		if tok.Matches(secret + "x") {
+			t.Error("expected token not to match a different secret")
+		}
+		if !tok.CreatedAt().Equal(now) {
+			t.Errorf("CreatedAt() = %v, want %v", tok.CreatedAt(), now)
+		}
+	})
+
+	t.Run("generates distinct tokens", func(t *testing.T) {
+		a, sa, _ := NewToken("a", now)
+		b, sb, _ := NewToken("b", now)
+		if a.ID() == b.ID() || sa == sb {
+			t.Error("expected unique IDs and secrets")
+		}
+	})
+
+	t.Run("rejects empty name", func(t *testing.T) {
+		if _, _, err := NewToken("   ", now); !errors.Is(err, ErrEmptyTokenName) {
+			t.Errorf("expected ErrEmptyTokenName, got %v", err)
+		}
+	})
+}
+
+func TestNewPlaylistPrefs(t *testing.T) {
+	p := NewPlaylistPrefs([]string{" sports ", "", "news", "sports"}, " 1080p ", "m3u8", true)
+
+	if got := p.Groups(); len(got) != 2 || got[0] != "news" || got[1] != "sports" {
+		t.Errorf("Groups() = %v, want [news sports]", got)
+	}
+	if p.Quality() != "1080p" || p.Format() != "m3u8" || !p.IncludeDisabled() {
+		t.Errorf("unexpected prefs %+v", p)
+	}
+	if !p.ShowsGroup("news") || p.ShowsGroup("kids") {
+		t.Error("expected only the listed groups to be shown")
+	}
+	if !(PlaylistPrefs{}).ShowsGroup("kids") {
+		t.Error("expected no groups to show every group")
+	}
+}
+
+func TestSessionSigner(t *testing.T) {
+	signer := NewSessionSigner([]byte("test-key"))
+	now := time.Unix(1700000000, 0)
+
+	t.Run("round-trips a valid session", func(t *testing.T) {
+		value := signer.Issue("admin", now.Add(time.Hour))
+		user, err := signer.Verify(value, now)
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if user != "admin" {
+			t.Errorf("Verify() user = %q, want admin", user)
+		}
+	})
+
+	t.Run("rejects expired sessions", func(t *testing.T) {
+		value := signer.Issue("admin", now.Add(time.Hour))
+		if _, err := signer.Verify(value, now.Add(time.Hour)); !errors.Is(err, ErrSessionExpired) {
+			t.Errorf("expected ErrSessionExpired, got %v", err)
+		}
+	})
+
+	t.Run("rejects tampered and foreign sessions", func(t *testing.T) {
+		value := signer.Issue("admin", now.Add(time.Hour))
+		tampered := strings.Replace(value, ".", ".9", 1)
+		other := NewSessionSigner([]byte("other-key")).Issue("admin", now.Add(time.Hour))
+
+		for _, v := range []string{tampered, other, "", "garbage"} {
+			if _, err := signer.Verify(v, now); !errors.Is(err, ErrInvalidSession) {
+				t.Errorf("Verify(%q) expected ErrInvalidSession, got %v", v, err)
+			}
+		}
+	})
+}
+
+func TestParseLinkKeys(t *testing.T) {
+	keys, err := ParseLinkKeys(" new:s2 , old:s1 ")
+	if err != nil {
+		t.Fatalf("ParseLinkKeys() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "new" || string(keys[1].Secret) != "s1" {
+		t.Errorf("ParseLinkKeys() = %+v", keys)
+	}
+
+	for _, s := range []string{"", "nosecret", ":s", "k:", "k:a,k:b"} {
+		if _, err := ParseLinkKeys(s); !errors.Is(err, ErrInvalidLinkKey) {
+			t.Errorf("ParseLinkKeys(%q) expected ErrInvalidLinkKey, got %v", s, err)
+		}
+	}
+}
+
+func TestLinkSigner(t *testing.T) {
+	oldKey := LinkKey{ID: "old", Secret: []byte("s1")}
+	newKey := LinkKey{ID: "new", Secret: []byte("s2")}
+	signer := NewLinkSigner([]LinkKey{newKey, oldKey})
+	now := time.Unix(1700000000, 0)
+
+	t.Run("round-trips through the query", func(t *testing.T) {
+		for _, tokenID := range []string{"", "tok-1"} {
+			sig := signer.Sign("stream:abc", tokenID, now.Add(time.Hour))
+			if sig.KeyID != "new" {
+				t.Errorf("Sign() key = %q, want new", sig.KeyID)
+			}
+			parsed, signed, err := ParseLinkSignature(sig.Query())
+			if err != nil || !signed {
+				t.Fatalf("ParseLinkSignature() = %v, %v", signed, err)
+			}
+			if err := signer.Verify("stream:abc", parsed, now); err != nil {
+				t.Errorf("Verify() with token %q error = %v", tokenID, err)
+			}
+		}
+	})
+
+	t.Run("verifies links signed with a rotated-out key", func(t *testing.T) {
+		sig := NewLinkSigner([]LinkKey{oldKey}).Sign("stream:abc", "", now.Add(time.Hour))
+		if err := signer.Verify("stream:abc", sig, now); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+		dropped := NewLinkSigner([]LinkKey{newKey})
+		if err := dropped.Verify("stream:abc", sig, now); !errors.Is(err, ErrInvalidLinkSignature) {
+			t.Errorf("expected ErrInvalidLinkSignature after dropping the key, got %v", err)
+		}
+	})
+
+	t.Run("rejects expired links", func(t *testing.T) {
+		sig := signer.Sign("stream:abc", "", now.Add(time.Hour))
+		if err := signer.Verify("stream:abc", sig, now.Add(time.Hour)); !errors.Is(err, ErrLinkExpired) {
+			t.Errorf("expected ErrLinkExpired, got %v", err)
+		}
+	})
+
+	t.Run("rejects tampered links", func(t *testing.T) {
+		sig := signer.Sign("stream:abc", "tok-1", now.Add(time.Hour))
+		otherToken, later := sig, sig
+		otherToken.TokenID = "tok-2"
+		later.Expires = sig.Expires.Add(time.Hour)
+
+		if err := signer.Verify("stream:def", sig, now); !errors.Is(err, ErrInvalidLinkSignature) {
+			t.Errorf("other resource: expected ErrInvalidLinkSignature, got %v", err)
+		}
+		for _, s := range []LinkSignature{otherToken, later} {
+			if err := signer.Verify("stream:abc", s, now); !errors.Is(err, ErrInvalidLinkSignature) {
+				t.Errorf("Verify(%+v) expected ErrInvalidLinkSignature, got %v", s, err)
+			}
+		}
+	})
+
+	t.Run("parses unsigned and malformed queries", func(t *testing.T) {
+		if _, signed, err := ParseLinkSignature(url.Values{"id": {"abc"}}); signed || err != nil {
+			t.Errorf("unsigned query: signed = %v, err = %v", signed, err)
+		}
+		if _, _, err := ParseLinkSignature(url.Values{"exp": {"soon"}, "kid": {"new"}, "sig": {"x"}}); !errors.Is(err, ErrInvalidLinkSignature) {
+			t.Errorf("malformed query: expected ErrInvalidLinkSignature, got %v", err)
+		}
+	})
+}
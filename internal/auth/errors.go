@@ -0,0 +1,23 @@
+package auth
+
+import "errors"
+
+// Domain errors for authentication operations.
+var (
+	// Token validation errors
+	ErrEmptyTokenName = errors.New("token name cannot be empty")
+
+	// Token operation errors
+	ErrTokenNotFound = errors.New("token not found")
+	ErrInvalidToken  = errors.New("invalid token")
+
+	// Login and session errors
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidSession     = errors.New("invalid session")
+	ErrSessionExpired     = errors.New("session expired")
+
+	// Stream link errors
+	ErrInvalidLinkKey       = errors.New("stream link keys must be comma-separated id:secret pairs with unique IDs")
+	ErrInvalidLinkSignature = errors.New("invalid stream link signature")
+	ErrLinkExpired          = errors.New("stream link expired")
+)
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LinkKey is a key stream links are signed with, named in the links by ID.
+type LinkKey struct {
+	ID     string
+	Secret []byte
+}
+
+// ParseLinkKeys parses a comma-separated list of id:secret pairs.
+// Returns ErrInvalidLinkKey if a pair has no ID or no secret, or if an ID is
+// used twice.
+func ParseLinkKeys(s string) ([]LinkKey, error) {
+	var keys []LinkKey
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok || id == "" || secret == "" || seen[id] {
+			return nil, ErrInvalidLinkKey
+		}
+		seen[id] = true
+		keys = append(keys, LinkKey{ID: id, Secret: []byte(secret)})
+	}
+	if len(keys) == 0 {
+		return nil, ErrInvalidLinkKey
+	}
+	return keys, nil
+}
+
+// LinkSignature authorises access to a resource until it expires. It is
+// carried in the query of a stream link as exp, kid, tok and sig.
+type LinkSignature struct {
+	Expires time.Time
+	KeyID   string
+	// TokenID scopes the link to an API token: it is signed with a key
+	// derived from the token, and dies with it.
+	TokenID string
+	Sig     string
+}
+
+// Query returns the signature as query parameters.
+func (s LinkSignature) Query() url.Values {
+	q := url.Values{
+		"exp": {strconv.FormatInt(s.Expires.Unix(), 10)},
+		"kid": {s.KeyID},
+		"sig": {s.Sig},
+	}
+	if s.TokenID != "" {
+		q.Set("tok", s.TokenID)
+	}
+	return q
+}
+
+// ParseLinkSignature reads a signature from the query of a stream link.
+// Returns false if the query carries none, and ErrInvalidLinkSignature if
+// it carries a malformed one.
+func ParseLinkSignature(q url.Values) (LinkSignature, bool, error) {
+	if !q.Has("sig") && !q.Has("exp") && !q.Has("kid") {
+		return LinkSignature{}, false, nil
+	}
+	expiry, err := strconv.ParseInt(q.Get("exp"), 10, 64)
+	if err != nil || q.Get("kid") == "" || q.Get("sig") == "" {
+		return LinkSignature{}, true, ErrInvalidLinkSignature
+	}
+	return LinkSignature{
+		Expires: time.Unix(expiry, 0),
+		KeyID:   q.Get("kid"),
+		TokenID: q.Get("tok"),
+		Sig:     q.Get("sig"),
+	}, true, nil
+}
+
+// LinkSigner signs and verifies expiring stream links with HMAC-SHA256.
+// Links are signed with the first key and verified with any of them, so
+// keys can be rotated by putting a new one first and dropping the old one
+// once the links it signed have expired.
+type LinkSigner struct {
+	keys []LinkKey
+}
+
+// NewLinkSigner creates a signer with the given keys, the first of which
+// signs new links. keys must not be empty.
+func NewLinkSigner(keys []LinkKey) *LinkSigner {
+	return &LinkSigner{keys: keys}
+}
+
+// Sign returns the signature authorising resource until expires, scoped to
+// the API token tokenID unless it is empty.
+func (s *LinkSigner) Sign(resource, tokenID string, expires time.Time) LinkSignature {
+	sig := LinkSignature{Expires: expires.Truncate(time.Second), KeyID: s.keys[0].ID, TokenID: tokenID}
+	sig.Sig = linkMAC(s.keys[0].Secret, resource, sig)
+	return sig
+}
+
+// Verify checks that sig authorises resource at now.
+// Returns ErrInvalidLinkSignature if it was not issued by a current key for
+// resource, and ErrLinkExpired if it is past its expiry.
+func (s *LinkSigner) Verify(resource string, sig LinkSignature, now time.Time) error {
+	for _, key := range s.keys {
+		if key.ID != sig.KeyID {
+			continue
+		}
+		if !hmac.Equal([]byte(sig.Sig), []byte(linkMAC(key.Secret, resource, sig))) {
+			return ErrInvalidLinkSignature
+		}
+		if !now.Before(sig.Expires) {
+			return ErrLinkExpired
+		}
+		return nil
+	}
+	return ErrInvalidLinkSignature
+}
+
+// linkMAC signs resource and the fields of sig with secret, or with the key
+// derived from it for sig's token.
+func linkMAC(secret []byte, resource string, sig LinkSignature) string {
+	key := secret
+	if sig.TokenID != "" {
+		derive := hmac.New(sha256.New, secret)
+		derive.Write([]byte("token:" + sig.TokenID))
+		key = derive.Sum(nil)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(resource + "\n" + strconv.FormatInt(sig.Expires.Unix(), 10) + "\n" + sig.KeyID + "\n" + sig.TokenID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
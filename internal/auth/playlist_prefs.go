@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"slices"
+	"strings"
+)
+
+// PlaylistPrefs customizes the playlist served to requests authenticated
+// with a token, so players only need the token in their playlist URL. The
+// zero value leaves the playlist as it is.
+type PlaylistPrefs struct {
+	groups          []string
+	quality         string
+	format          string
+	includeDisabled bool
+}
+
+// NewPlaylistPrefs creates playlist preferences. Group IDs are trimmed, and
+// blanks and duplicates are dropped. Quality and format are kept as given;
+// validating them is up to the caller.
+func NewPlaylistPrefs(groups []string, quality, format string, includeDisabled bool) PlaylistPrefs {
+	ids := make([]string, 0, len(groups))
+	for _, g := range groups {
+		if g = strings.TrimSpace(g); g != "" {
+			ids = append(ids, g)
+		}
+	}
+	slices.Sort(ids)
+	return PlaylistPrefs{
+		groups:          slices.Compact(ids),
+		quality:         strings.TrimSpace(quality),
+		format:          strings.TrimSpace(format),
+		includeDisabled: includeDisabled,
+	}
+}
+
+// Groups returns the IDs of the groups whose channels are listed, sorted;
+// empty lists every channel.
+func (p PlaylistPrefs) Groups() []string {
+	return slices.Clone(p.groups)
+}
+
+// Quality returns the stream quality listed first for each channel, or ""
+// to keep the channel's own preference.
+func (p PlaylistPrefs) Quality() string {
+	return p.quality
+}
+
+// Format returns the name of the format served when the request does not
+// choose one, or "" for the default.
+func (p PlaylistPrefs) Format() string {
+	return p.format
+}
+
+// IncludeDisabled reports whether channels of disabled groups are listed.
+func (p PlaylistPrefs) IncludeDisabled() bool {
+	return p.includeDisabled
+}
+
+// ShowsGroup reports whether channels of the group with the given ID are
+// listed.
+func (p PlaylistPrefs) ShowsGroup(groupID string) bool {
+	if len(p.groups) == 0 {
+		return true
+	}
+	_, ok := slices.BinarySearch(p.groups, groupID)
+	return ok
+}
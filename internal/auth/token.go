@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// tokenPrefix marks API token secrets so they are recognisable in configs.
+const tokenPrefix = "iptv_"
+
+// Token is a named API credential. Only a hash of the secret is kept; the
+// secret itself is shown once, when the token is created.
+type Token struct {
+	id         string
+	name       string
+	secretHash string
+	createdAt  time.Time
+	prefs      PlaylistPrefs
+}
+
+// NewToken creates a token with a random ID and secret. It returns the token
+// and the plaintext secret, which cannot be recovered later.
+// Returns ErrEmptyTokenName if the name is empty or contains only whitespace.
+func NewToken(name string, now time.Time) (Token, string, error) {
+	trimmedName := strings.TrimSpace(name)
+	if trimmedName == "" {
+		return Token{}, "", ErrEmptyTokenName
+	}
+
+	idBytes := make([]byte, 8)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return Token{}, "", err
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return Token{}, "", err
+	}
+	secret := tokenPrefix + base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	return Token{
+		id:         hex.EncodeToString(idBytes),
+		name:       trimmedName,
+		secretHash: HashSecret(secret),
+		createdAt:  now,
+	}, secret, nil
+}
+
+// ReconstructToken rebuilds a token from persisted fields.
+func ReconstructToken(id, name, secretHash string, createdAt time.Time, prefs PlaylistPrefs) Token {
+	return Token{
+		id:         id,
+		name:       name,
+		secretHash: secretHash,
+		createdAt:  createdAt,
+		prefs:      prefs,
+	}
+}
+
+// HashSecret returns the hex-encoded SHA-256 of a token secret.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// ID returns the token's public identifier.
+func (t Token) ID() string {
+	return t.id
+}
+
+// Name returns the human-readable token name.
+func (t Token) Name() string {
+	return t.name
+}
+
+// SecretHash returns the hash of the token secret.
+func (t Token) SecretHash() string {
+	return t.secretHash
+}
+
+// CreatedAt returns when the token was created.
+func (t Token) CreatedAt() time.Time {
+	return t.createdAt
+}
+
+// PlaylistPrefs returns the customization of the playlist served to
+// requests authenticated with the token.
+func (t Token) PlaylistPrefs() PlaylistPrefs {
+	return t.prefs
+}
+
+// WithPlaylistPrefs returns a copy of the token with new playlist preferences.
+func (t Token) WithPlaylistPrefs(prefs PlaylistPrefs) Token {
+	t.prefs = prefs
+	return t
+}
+
+// Matches reports whether secret is this token's secret, in constant time.
+func (t Token) Matches(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSecret(secret)), []byte(t.secretHash)) == 1
+}
@@ -0,0 +1,68 @@
+// Package backup names and orders database backup files.
+package backup
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidBackup is returned when uploaded data is not a usable database snapshot.
+var ErrInvalidBackup = errors.New("invalid backup")
+
+const (
+	namePrefix = "iptv-manager-"
+	nameSuffix = ".db"
+	timeLayout = "20060102T150405Z"
+)
+
+// Reason labels why a backup was taken.
+type Reason string
+
+const (
+	ReasonScheduled  Reason = ""            // Periodic backup
+	ReasonPreRestore Reason = "pre-restore" // Snapshot taken before a restore overwrote the database
+)
+
+// FileName returns the file name for a backup taken at t. Names sort in the
+// order the backups were taken.
+func FileName(t time.Time, reason Reason) string {
+	name := namePrefix + t.UTC().Format(timeLayout)
+	if reason != ReasonScheduled {
+		name += "-" + string(reason)
+	}
+	return name + nameSuffix
+}
+
+// IsFileName reports whether name has the shape produced by FileName.
+func IsFileName(name string) bool {
+	rest, ok := strings.CutPrefix(name, namePrefix)
+	if !ok || !strings.HasSuffix(rest, nameSuffix) || len(rest) < len(timeLayout) {
+		return false
+	}
+	_, err := time.Parse(timeLayout, rest[:len(timeLayout)])
+	return err == nil
+}
+
+// Expired returns the backup file names that fall outside a retention of keep
+// backups, oldest first. Names not produced by FileName are ignored. A keep
+// of zero or less retains everything.
+func Expired(names []string, keep int) []string {
+	if keep <= 0 {
+		return nil
+	}
+
+	var backups []string
+	for _, name := range names {
+		if IsFileName(name) {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) <= keep {
+		return nil
+	}
+
+	slices.Sort(backups)
+	return backups[:len(backups)-keep]
+}
@@ -0,0 +1,53 @@
+package backup
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFileName(t *testing.T) {
+	at := time.Date(2026, 3, 1, 4, 5, 6, 0, time.FixedZone("CET", 3600))
+
+	if got, want := FileName(at, ReasonScheduled), "iptv-manager-20260301T030506Z.db"; got != want {
+		t.Errorf("FileName(scheduled) = %q, want %q", got, want)
+	}
+	if got, want := FileName(at, ReasonPreRestore), "iptv-manager-20260301T030506Z-pre-restore.db"; got != want {
+		t.Errorf("FileName(pre-restore) = %q, want %q", got, want)
+	}
+
+	for _, name := range []string{FileName(at, ReasonScheduled), FileName(at, ReasonPreRestore)} {
+		if !IsFileName(name) {
+			t.Errorf("expected %q to be recognised as a backup file", name)
+		}
+	}
+	for _, name := range []string{"", "iptv-manager.db", "iptv-manager-yesterday.db", "notes.txt", "../iptv-manager-20260301T030506Z.db"} {
+		if IsFileName(name) {
+			t.Errorf("expected %q not to be recognised as a backup file", name)
+		}
+	}
+}
+
+func TestExpired(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	names := []string{
+		FileName(day(3), ReasonScheduled),
+		"unrelated.txt",
+		FileName(day(1), ReasonScheduled),
+		FileName(day(2), ReasonPreRestore),
+		FileName(day(4), ReasonScheduled),
+	}
+
+	got := Expired(names, 2)
+	want := []string{FileName(day(1), ReasonScheduled), FileName(day(2), ReasonPreRestore)}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expired(keep=2) = %v, want %v", got, want)
+	}
+
+	if got := Expired(names, 4); got != nil {
+		t.Errorf("Expired(keep=4) = %v, want nothing", got)
+	}
+	if got := Expired(names, 0); got != nil {
+		t.Errorf("Expired(keep=0) = %v, want nothing", got)
+	}
+}
@@ -1,7 +1,9 @@
 package channel
 
 import (
+	"cmp"
 	"errors"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
@@ -9,12 +11,24 @@ import (
 
 // Domain errors
 var (
-	ErrEmptyName            = errors.New("channel name cannot be empty")
-	ErrChannelNotFound      = errors.New("channel not found")
-	ErrChannelAlreadyExists = errors.New("channel already exists")
-	ErrInvalidMappingSource = errors.New("invalid mapping source")
+	ErrEmptyName             = errors.New("channel name cannot be empty")
+	ErrChannelNotFound       = errors.New("channel not found")
+	ErrChannelAlreadyExists  = errors.New("channel already exists")
+	ErrInvalidMappingSource  = errors.New("invalid mapping source")
+	ErrInvalidAudioTranscode = errors.New("invalid audio transcode")
+	ErrInvalidVideoTranscode = errors.New("invalid video transcode")
+	ErrInvalidNumber         = errors.New("channel number cannot be negative")
+	ErrInvalidAlias          = errors.New("channel alias must be 1-64 lowercase letters, digits or dashes")
+	ErrAliasInUse            = errors.New("channel alias already in use")
+	ErrInvalidQuality        = errors.New("invalid stream quality")
+	ErrInvalidVariants       = errors.New("invalid variants mode")
+	ErrInvalidTag            = errors.New("channel tag must be 1-32 lowercase letters, digits or dashes")
+	ErrInvalidTVGShift       = errors.New("tvg-shift must be a multiple of 15 minutes within 24 hours")
 )
 
+// maxAliasLength bounds the length of a channel alias.
+const maxAliasLength = 64
+
 // Status represents the operational status of a channel.
 type Status string
 
@@ -31,14 +45,75 @@ const (
 	MappingManual MappingSource = "manual" // Manually specified by user
 )
 
+// AudioTranscode selects which of a channel's audio tracks the engine
+// re-encodes to AAC, for players that cannot decode the original codec.
+type AudioTranscode string
+
+const (
+	AudioTranscodeNone AudioTranscode = ""    // Audio is passed through unchanged
+	AudioTranscodeAll  AudioTranscode = "all" // Every audio track is re-encoded
+	AudioTranscodeAC3  AudioTranscode = "ac3" // Only AC3 tracks are re-encoded
+	AudioTranscodeMP3  AudioTranscode = "mp3" // Only MP3 tracks are re-encoded
+)
+
+// ParseAudioTranscode validates an audio transcode setting.
+// Returns ErrInvalidAudioTranscode for unknown values.
+func ParseAudioTranscode(s string) (AudioTranscode, error) {
+	switch t := AudioTranscode(s); t {
+	case AudioTranscodeNone, AudioTranscodeAll, AudioTranscodeAC3, AudioTranscodeMP3:
+		return t, nil
+	default:
+		return AudioTranscodeNone, ErrInvalidAudioTranscode
+	}
+}
+
+// VideoTranscode selects the largest video resolution a channel is streamed
+// at. Larger video is downscaled, for players or links that cannot keep up
+// with the original.
+type VideoTranscode string
+
+const (
+	VideoTranscodeNone  VideoTranscode = ""      // Video is passed through unchanged
+	VideoTranscode1080p VideoTranscode = "1080p" // Video is downscaled to 1080 lines
+	VideoTranscode720p  VideoTranscode = "720p"  // Video is downscaled to 720 lines
+	VideoTranscode480p  VideoTranscode = "480p"  // Video is downscaled to 480 lines
+)
+
+// ParseVideoTranscode validates a video transcode setting.
+// Returns ErrInvalidVideoTranscode for unknown values.
+func ParseVideoTranscode(s string) (VideoTranscode, error) {
+	switch t := VideoTranscode(s); t {
+	case VideoTranscodeNone, VideoTranscode1080p, VideoTranscode720p, VideoTranscode480p:
+		return t, nil
+	default:
+		return VideoTranscodeNone, ErrInvalidVideoTranscode
+	}
+}
+
+// Height returns the largest number of lines video is streamed at, or 0 if
+// it is not downscaled.
+func (t VideoTranscode) Height() int {
+	switch t {
+	case VideoTranscode1080p:
+		return 1080
+	case VideoTranscode720p:
+		return 720
+	case VideoTranscode480p:
+		return 480
+	}
+	return 0
+}
+
 // EPGMapping holds the EPG correlation data for a channel.
 type EPGMapping struct {
 	epgID      string
 	source     MappingSource
 	lastSynced time.Time
+	confidence float64
 }
 
 // NewEPGMapping creates a new EPGMapping with the given attributes.
+// The mapping is fully confident until WithConfidence says otherwise.
 // Returns ErrInvalidMappingSource if the source is not valid.
 func NewEPGMapping(epgID string, source MappingSource, lastSynced time.Time) (EPGMapping, error) {
 	if source != MappingAuto && source != MappingManual {
@@ -48,9 +123,17 @@ func NewEPGMapping(epgID string, source MappingSource, lastSynced time.Time) (EP
 		epgID:      strings.TrimSpace(epgID),
 		source:     source,
 		lastSynced: lastSynced,
+		confidence: 1,
 	}, nil
 }
 
+// WithConfidence returns a copy of the mapping with the given confidence,
+// clamped between 0.0 and 1.0.
+func (m EPGMapping) WithConfidence(confidence float64) EPGMapping {
+	m.confidence = min(max(confidence, 0), 1)
+	return m
+}
+
 // EPGID returns the EPG identifier this channel is mapped to.
 func (m EPGMapping) EPGID() string {
 	return m.epgID
@@ -66,12 +149,28 @@ func (m EPGMapping) LastSynced() time.Time {
 	return m.lastSynced
 }
 
+// Confidence returns how likely the mapping is to be right, from 0.0 to 1.0.
+// Only automatic mappings made by name similarity are less than 1.0.
+func (m EPGMapping) Confidence() float64 {
+	return m.confidence
+}
+
 // Channel represents a TV channel in the domain.
 // It is the core entity for managing IPTV channels.
 type Channel struct {
-	name       string
-	status     Status
-	epgMapping *EPGMapping
+	name           string
+	status         Status
+	epgMapping     *EPGMapping
+	audioTranscode AudioTranscode
+	videoTranscode VideoTranscode
+	group          string
+	number         int
+	tvgShift       time.Duration
+	aliases        []string
+	qualities      map[string]Quality
+	preference     []Quality
+	variants       Variants
+	tags           []string
 }
 
 // NewChannel creates a new Channel with the given name.
@@ -116,6 +215,131 @@ func (c Channel) EPGMapping() *EPGMapping {
 	return c.epgMapping
 }
 
+// AudioTranscode returns how the channel's audio is re-encoded when streamed.
+func (c Channel) AudioTranscode() AudioTranscode {
+	return c.audioTranscode
+}
+
+// SetAudioTranscode changes how the channel's audio is re-encoded when streamed.
+func (c *Channel) SetAudioTranscode(t AudioTranscode) {
+	c.audioTranscode = t
+}
+
+// VideoTranscode returns the resolution the channel's video is downscaled to
+// when streamed.
+func (c Channel) VideoTranscode() VideoTranscode {
+	return c.videoTranscode
+}
+
+// SetVideoTranscode changes the resolution the channel's video is downscaled
+// to when streamed.
+func (c *Channel) SetVideoTranscode(t VideoTranscode) {
+	c.videoTranscode = t
+}
+
+// Group returns the ID of the group the channel is assigned to, or "" if
+// it is ungrouped.
+func (c Channel) Group() string {
+	return c.group
+}
+
+// SetGroup assigns the channel to the group with the given ID. An empty ID
+// leaves the channel ungrouped.
+func (c *Channel) SetGroup(groupID string) {
+	c.group = groupID
+}
+
+// Number returns the channel number players list the channel under, or 0 if
+// none was assigned.
+func (c Channel) Number() int {
+	return c.number
+}
+
+// SetNumber assigns the channel number. Zero removes the assignment.
+// Returns ErrInvalidNumber if the number is negative.
+func (c *Channel) SetNumber(number int) error {
+	if number < 0 {
+		return ErrInvalidNumber
+	}
+	c.number = number
+	return nil
+}
+
+// MaxTVGShift bounds the guide time shift of a channel either way.
+const MaxTVGShift = 24 * time.Hour
+
+// TVGShift returns how far the guide times of the channel are moved, for a
+// channel broadcast in another timezone than its guide, or 0 if they are not.
+func (c Channel) TVGShift() time.Duration {
+	return c.tvgShift
+}
+
+// SetTVGShift sets how far the guide times of the channel are moved. Zero
+// removes the shift.
+// Returns ErrInvalidTVGShift if shift is not a multiple of 15 minutes or
+// exceeds MaxTVGShift either way.
+func (c *Channel) SetTVGShift(shift time.Duration) error {
+	if shift%(15*time.Minute) != 0 || shift > MaxTVGShift || shift < -MaxTVGShift {
+		return ErrInvalidTVGShift
+	}
+	c.tvgShift = shift
+	return nil
+}
+
+// Aliases returns the slugs the channel can be streamed by, e.g. "la1" for
+// /ace/c/la1, in the order they were set. The first one is used in playlists.
+func (c Channel) Aliases() []string {
+	return slices.Clone(c.aliases)
+}
+
+// SetAliases replaces the channel's aliases. Aliases are lowercased and
+// duplicates dropped. Returns ErrInvalidAlias if one is not a valid slug.
+func (c *Channel) SetAliases(aliases []string) error {
+	var parsed []string
+	for _, a := range aliases {
+		alias, err := ParseAlias(a)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(parsed, alias) {
+			parsed = append(parsed, alias)
+		}
+	}
+	c.aliases = parsed
+	return nil
+}
+
+// ParseAlias validates a channel alias and returns it lowercased.
+// Aliases are slugs of letters, digits and dashes, not starting or ending
+// with a dash, so they fit in a URL path unescaped.
+// Returns ErrInvalidAlias otherwise.
+func ParseAlias(s string) (string, error) {
+	alias := strings.ToLower(strings.TrimSpace(s))
+	if alias == "" || len(alias) > maxAliasLength || alias[0] == '-' || alias[len(alias)-1] == '-' {
+		return "", ErrInvalidAlias
+	}
+	for _, r := range alias {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return "", ErrInvalidAlias
+		}
+	}
+	return alias, nil
+}
+
+// CompareOrder orders channels the way playlists list them: numbered
+// channels first by number, then the rest by name.
+func CompareOrder(a, b Channel) int {
+	switch {
+	case a.number != 0 && b.number == 0:
+		return -1
+	case a.number == 0 && b.number != 0:
+		return 1
+	case a.number != b.number:
+		return cmp.Compare(a.number, b.number)
+	}
+	return strings.Compare(a.name, b.name)
+}
+
 // Archive marks the channel as archived (disappeared from source).
 func (c *Channel) Archive() {
 	c.status = StatusArchived
@@ -131,6 +355,52 @@ func (c *Channel) ClearEPGMapping() {
 	c.epgMapping = nil
 }
 
+// Absorb fills in the settings of c that are unset from other, as when other
+// is merged into c. c's own settings win, except that a manual EPG mapping on
+// other replaces an automatic one on c. Other's aliases are added to c's so
+// URLs using them keep working, as are its tags, and the quality labels of
+// its streams, which move to c, are kept.
+func (c *Channel) Absorb(other Channel) {
+	if m := other.epgMapping; m != nil {
+		if c.epgMapping == nil || (c.epgMapping.source == MappingAuto && m.source == MappingManual) {
+			mapping := *m
+			c.epgMapping = &mapping
+		}
+	}
+	if c.audioTranscode == AudioTranscodeNone {
+		c.audioTranscode = other.audioTranscode
+	}
+	if c.videoTranscode == VideoTranscodeNone {
+		c.videoTranscode = other.videoTranscode
+	}
+	if c.group == "" {
+		c.group = other.group
+	}
+	if c.number == 0 {
+		c.number = other.number
+	}
+	if c.tvgShift == 0 {
+		c.tvgShift = other.tvgShift
+	}
+	for _, alias := range other.aliases {
+		if !slices.Contains(c.aliases, alias) {
+			c.aliases = append(c.aliases, alias)
+		}
+	}
+	for infoHash, q := range other.qualities {
+		if c.StreamQuality(infoHash) == "" {
+			c.SetStreamQuality(infoHash, q)
+		}
+	}
+	if len(c.preference) == 0 {
+		c.preference = slices.Clone(other.preference)
+	}
+	if c.variants == VariantsAll {
+		c.variants = other.variants
+	}
+	_ = c.SetTags(append(c.Tags(), other.tags...))
+}
+
 // qualitySuffixes are broadcast quality/resolution tokens stripped during
 // name normalization so that "DAZN 1 FHD" and "DAZN 1 HD" compare equal.
 var qualitySuffixes = map[string]bool{
@@ -168,9 +438,12 @@ func NormalizeName(name string) string {
 // Returns a value between 0.0 (no match) and 1.0 (exact match).
 // Uses normalized name comparison and common substring matching.
 func FuzzyMatch(name1, name2 string) float64 {
-	n1 := NormalizeName(name1)
-	n2 := NormalizeName(name2)
+	return FuzzyMatchNormalized(NormalizeName(name1), NormalizeName(name2))
+}
 
+// FuzzyMatchNormalized is FuzzyMatch for names already passed through
+// NormalizeName, for callers comparing many names against each other.
+func FuzzyMatchNormalized(n1, n2 string) float64 {
 	// Exact match after normalization
 	if n1 == n2 {
 		return 1.0
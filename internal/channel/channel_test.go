@@ -2,6 +2,8 @@ package channel_test
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -192,10 +194,31 @@ func TestNewEPGMapping(t *testing.T) {
 			if got := mapping.LastSynced(); !got.Equal(tt.wantSynced) {
 				t.Errorf("EPGMapping.LastSynced() = %v, want %v", got, tt.wantSynced)
 			}
+
+			if got := mapping.Confidence(); got != 1 {
+				t.Errorf("EPGMapping.Confidence() = %v, want 1", got)
+			}
 		})
 	}
 }
 
+func TestEPGMappingWithConfidence(t *testing.T) {
+	mapping, _ := channel.NewEPGMapping("hbo-hd", channel.MappingAuto, time.Now())
+
+	if got := mapping.WithConfidence(0.82).Confidence(); got != 0.82 {
+		t.Errorf("Confidence() = %v, want 0.82", got)
+	}
+	if got := mapping.WithConfidence(1.5).Confidence(); got != 1 {
+		t.Errorf("Confidence() = %v, want clamped to 1", got)
+	}
+	if got := mapping.WithConfidence(-1).Confidence(); got != 0 {
+		t.Errorf("Confidence() = %v, want clamped to 0", got)
+	}
+	if mapping.Confidence() != 1 {
+		t.Error("expected WithConfidence not to modify the original mapping")
+	}
+}
+
 func TestChannelEPGMapping(t *testing.T) {
 	ch, err := channel.NewChannel("HBO")
 	if err != nil {
@@ -233,6 +256,307 @@ func TestChannelEPGMapping(t *testing.T) {
 	}
 }
 
+func TestParseAudioTranscode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    channel.AudioTranscode
+		wantErr error
+	}{
+		{input: "", want: channel.AudioTranscodeNone},
+		{input: "all", want: channel.AudioTranscodeAll},
+		{input: "ac3", want: channel.AudioTranscodeAC3},
+		{input: "mp3", want: channel.AudioTranscodeMP3},
+		{input: "aac", want: channel.AudioTranscodeNone, wantErr: channel.ErrInvalidAudioTranscode},
+		{input: "ALL", want: channel.AudioTranscodeNone, wantErr: channel.ErrInvalidAudioTranscode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := channel.ParseAudioTranscode(tt.input)
+			if err != tt.wantErr {
+				t.Fatalf("ParseAudioTranscode(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAudioTranscode(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVideoTranscode(t *testing.T) {
+	tests := []struct {
+		input      string
+		want       channel.VideoTranscode
+		wantHeight int
+		wantErr    error
+	}{
+		{input: "", want: channel.VideoTranscodeNone},
+		{input: "1080p", want: channel.VideoTranscode1080p, wantHeight: 1080},
+		{input: "720p", want: channel.VideoTranscode720p, wantHeight: 720},
+		{input: "480p", want: channel.VideoTranscode480p, wantHeight: 480},
+		{input: "360p", want: channel.VideoTranscodeNone, wantErr: channel.ErrInvalidVideoTranscode},
+		{input: "720", want: channel.VideoTranscodeNone, wantErr: channel.ErrInvalidVideoTranscode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := channel.ParseVideoTranscode(tt.input)
+			if err != tt.wantErr {
+				t.Fatalf("ParseVideoTranscode(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want || got.Height() != tt.wantHeight {
+				t.Errorf("ParseVideoTranscode(%q) = %q (%d lines), want %q (%d lines)", tt.input, got, got.Height(), tt.want, tt.wantHeight)
+			}
+		})
+	}
+}
+
+func TestChannelAudioTranscode(t *testing.T) {
+	ch, err := channel.NewChannel("HBO")
+	if err != nil {
+		t.Fatalf("NewChannel() unexpected error = %v", err)
+	}
+
+	if got := ch.AudioTranscode(); got != channel.AudioTranscodeNone {
+		t.Fatalf("initial AudioTranscode() = %q, want none", got)
+	}
+
+	ch.SetAudioTranscode(channel.AudioTranscodeAC3)
+
+	if got := ch.AudioTranscode(); got != channel.AudioTranscodeAC3 {
+		t.Errorf("AudioTranscode() after SetAudioTranscode() = %q, want %q", got, channel.AudioTranscodeAC3)
+	}
+}
+
+func TestChannelGroup(t *testing.T) {
+	ch, err := channel.NewChannel("HBO")
+	if err != nil {
+		t.Fatalf("NewChannel() unexpected error = %v", err)
+	}
+
+	if got := ch.Group(); got != "" {
+		t.Fatalf("initial Group() = %q, want ungrouped", got)
+	}
+
+	ch.SetGroup("movies")
+	if got := ch.Group(); got != "movies" {
+		t.Errorf("Group() after SetGroup() = %q, want %q", got, "movies")
+	}
+
+	ch.SetGroup("")
+	if got := ch.Group(); got != "" {
+		t.Errorf("Group() after clearing = %q, want ungrouped", got)
+	}
+}
+
+func TestChannelNumber(t *testing.T) {
+	ch, err := channel.NewChannel("HBO")
+	if err != nil {
+		t.Fatalf("NewChannel() unexpected error = %v", err)
+	}
+
+	if got := ch.Number(); got != 0 {
+		t.Fatalf("initial Number() = %d, want unnumbered", got)
+	}
+
+	if err := ch.SetNumber(7); err != nil {
+		t.Fatalf("SetNumber() unexpected error = %v", err)
+	}
+	if got := ch.Number(); got != 7 {
+		t.Errorf("Number() after SetNumber() = %d, want 7", got)
+	}
+
+	if err := ch.SetNumber(-1); !errors.Is(err, channel.ErrInvalidNumber) {
+		t.Errorf("SetNumber(-1) error = %v, want ErrInvalidNumber", err)
+	}
+	if got := ch.Number(); got != 7 {
+		t.Errorf("Number() after rejected SetNumber() = %d, want 7", got)
+	}
+}
+
+func TestChannelTVGShift(t *testing.T) {
+	ch, _ := channel.NewChannel("La 1 Canarias")
+	if got := ch.TVGShift(); got != 0 {
+		t.Fatalf("initial TVGShift() = %v, want no shift", got)
+	}
+
+	if err := ch.SetTVGShift(-90 * time.Minute); err != nil {
+		t.Fatalf("SetTVGShift() unexpected error = %v", err)
+	}
+	if got := ch.TVGShift(); got != -90*time.Minute {
+		t.Errorf("TVGShift() after SetTVGShift() = %v, want -1h30m", got)
+	}
+
+	for _, invalid := range []time.Duration{10 * time.Minute, 25 * time.Hour, -25 * time.Hour} {
+		if err := ch.SetTVGShift(invalid); !errors.Is(err, channel.ErrInvalidTVGShift) {
+			t.Errorf("SetTVGShift(%v) error = %v, want ErrInvalidTVGShift", invalid, err)
+		}
+	}
+	if got := ch.TVGShift(); got != -90*time.Minute {
+		t.Errorf("TVGShift() after rejected SetTVGShift() = %v, want unchanged", got)
+	}
+}
+
+func TestChannelAliases(t *testing.T) {
+	ch, _ := channel.NewChannel("La 1")
+	if got := ch.Aliases(); len(got) != 0 {
+		t.Fatalf("initial Aliases() = %v, want none", got)
+	}
+
+	if err := ch.SetAliases([]string{"La1", " tve-1 ", "la1"}); err != nil {
+		t.Fatalf("SetAliases() unexpected error = %v", err)
+	}
+	if got, want := ch.Aliases(), []string{"la1", "tve-1"}; !slices.Equal(got, want) {
+		t.Errorf("Aliases() = %v, want %v", got, want)
+	}
+
+	for _, invalid := range []string{"", "la 1", "-la1", "la1-", "la/1", "ñ", strings.Repeat("a", 65)} {
+		if err := ch.SetAliases([]string{invalid}); !errors.Is(err, channel.ErrInvalidAlias) {
+			t.Errorf("SetAliases(%q) error = %v, want ErrInvalidAlias", invalid, err)
+		}
+	}
+	if got := ch.Aliases(); len(got) != 2 {
+		t.Errorf("Aliases() after rejected SetAliases() = %v, want unchanged", got)
+	}
+
+	if err := ch.SetAliases(nil); err != nil || len(ch.Aliases()) != 0 {
+		t.Errorf("SetAliases(nil) = %v, aliases %v; want them cleared", err, ch.Aliases())
+	}
+}
+
+func TestCompareOrder(t *testing.T) {
+	numbered := func(name string, number int) channel.Channel {
+		ch, _ := channel.NewChannel(name)
+		_ = ch.SetNumber(number)
+		return ch
+	}
+
+	channels := []channel.Channel{
+		numbered("Zeta", 0),
+		numbered("Beta", 2),
+		numbered("Alpha", 0),
+		numbered("Gamma", 1),
+	}
+	slices.SortFunc(channels, channel.CompareOrder)
+
+	var got []string
+	for _, ch := range channels {
+		got = append(got, ch.Name())
+	}
+	want := []string{"Gamma", "Beta", "Alpha", "Zeta"}
+	if !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestChannelAbsorb(t *testing.T) {
+	auto, _ := channel.NewEPGMapping("auto.tv", channel.MappingAuto, time.Now())
+	manual, _ := channel.NewEPGMapping("manual.tv", channel.MappingManual, time.Now())
+
+	tests := []struct {
+		name        string
+		target      *channel.EPGMapping
+		source      *channel.EPGMapping
+		wantMapping string
+	}{
+		{"fills missing mapping", nil, &auto, "auto.tv"},
+		{"keeps own mapping", &auto, &auto, "auto.tv"},
+		{"manual replaces auto", &auto, &manual, "manual.tv"},
+		{"auto does not replace manual", &manual, &auto, "manual.tv"},
+		{"no mappings", nil, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := channel.ReconstructChannel("Target", channel.StatusActive, tt.target)
+			source := channel.ReconstructChannel("Source", channel.StatusActive, tt.source)
+
+			target.Absorb(source)
+
+			got := ""
+			if m := target.EPGMapping(); m != nil {
+				got = m.EPGID()
+			}
+			if got != tt.wantMapping {
+				t.Errorf("EPG mapping = %q, want %q", got, tt.wantMapping)
+			}
+		})
+	}
+
+	t.Run("fills unset settings only", func(t *testing.T) {
+		target, _ := channel.NewChannel("Target")
+		target.SetGroup("sports")
+		source, _ := channel.NewChannel("Source")
+		source.SetGroup("news")
+		source.SetAudioTranscode(channel.AudioTranscodeAC3)
+		_ = source.SetNumber(5)
+		_ = source.SetTVGShift(time.Hour)
+
+		target.Absorb(source)
+
+		if target.Group() != "sports" {
+			t.Errorf("Group() = %q, want sports", target.Group())
+		}
+		if target.AudioTranscode() != channel.AudioTranscodeAC3 {
+			t.Errorf("AudioTranscode() = %q, want ac3", target.AudioTranscode())
+		}
+		if target.Number() != 5 {
+			t.Errorf("Number() = %d, want 5", target.Number())
+		}
+		if target.TVGShift() != time.Hour {
+			t.Errorf("TVGShift() = %v, want 1h", target.TVGShift())
+		}
+	})
+
+	t.Run("keeps the aliases of both", func(t *testing.T) {
+		target, _ := channel.NewChannel("Target")
+		_ = target.SetAliases([]string{"la1", "tve1"})
+		source, _ := channel.NewChannel("Source")
+		_ = source.SetAliases([]string{"tve1", "la1-hd"})
+
+		target.Absorb(source)
+
+		if got, want := target.Aliases(), []string{"la1", "tve1", "la1-hd"}; !slices.Equal(got, want) {
+			t.Errorf("Aliases() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("keeps the quality labels of both", func(t *testing.T) {
+		target, _ := channel.NewChannel("Target")
+		target.SetStreamQuality("hash1", channel.Quality1080p)
+		source, _ := channel.NewChannel("Source")
+		source.SetStreamQuality("hash1", channel.QualitySD)
+		source.SetStreamQuality("hash2", channel.Quality720p)
+		source.SetQualityPreference([]channel.Quality{channel.Quality720p})
+		source.SetVariants(channel.VariantsPreferred)
+
+		target.Absorb(source)
+
+		if target.StreamQuality("hash1") != channel.Quality1080p || target.StreamQuality("hash2") != channel.Quality720p {
+			t.Errorf("StreamQualities() = %v, want hash1 1080p and hash2 720p", target.StreamQualities())
+		}
+		if got := target.QualityPreference(); !slices.Equal(got, []channel.Quality{channel.Quality720p}) {
+			t.Errorf("QualityPreference() = %v, want [720p]", got)
+		}
+		if target.Variants() != channel.VariantsPreferred {
+			t.Errorf("Variants() = %q, want preferred", target.Variants())
+		}
+	})
+
+	t.Run("merges tags", func(t *testing.T) {
+		target, _ := channel.NewChannel("Target")
+		_ = target.SetTags([]string{"sports"})
+		source, _ := channel.NewChannel("Source")
+		_ = source.SetTags([]string{"4k", "sports"})
+
+		target.Absorb(source)
+
+		if got := target.Tags(); !slices.Equal(got, []string{"4k", "sports"}) {
+			t.Errorf("Tags() = %v, want [4k sports]", got)
+		}
+	})
+}
+
 func TestNormalizeName(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -425,6 +749,46 @@ func TestDomainErrors(t *testing.T) {
 			err:  channel.ErrInvalidMappingSource,
 			msg:  "invalid mapping source",
 		},
+		{
+			name: "ErrInvalidAudioTranscode",
+			err:  channel.ErrInvalidAudioTranscode,
+			msg:  "invalid audio transcode",
+		},
+		{
+			name: "ErrInvalidTVGShift",
+			err:  channel.ErrInvalidTVGShift,
+			msg:  "tvg-shift must be a multiple of 15 minutes within 24 hours",
+		},
+		{
+			name: "ErrInvalidNumber",
+			err:  channel.ErrInvalidNumber,
+			msg:  "channel number cannot be negative",
+		},
+		{
+			name: "ErrInvalidAlias",
+			err:  channel.ErrInvalidAlias,
+			msg:  "channel alias must be 1-64 lowercase letters, digits or dashes",
+		},
+		{
+			name: "ErrAliasInUse",
+			err:  channel.ErrAliasInUse,
+			msg:  "channel alias already in use",
+		},
+		{
+			name: "ErrInvalidQuality",
+			err:  channel.ErrInvalidQuality,
+			msg:  "invalid stream quality",
+		},
+		{
+			name: "ErrInvalidVariants",
+			err:  channel.ErrInvalidVariants,
+			msg:  "invalid variants mode",
+		},
+		{
+			name: "ErrInvalidTag",
+			err:  channel.ErrInvalidTag,
+			msg:  "channel tag must be 1-32 lowercase letters, digits or dashes",
+		},
 	}
 
 	for _, tt := range tests {
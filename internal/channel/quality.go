@@ -0,0 +1,148 @@
+package channel
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Quality labels a stream of a channel by its video resolution.
+type Quality string
+
+const (
+	Quality2160p Quality = "2160p"
+	Quality1080p Quality = "1080p"
+	Quality720p  Quality = "720p"
+	QualitySD    Quality = "SD"
+)
+
+// defaultPreference is the order streams are preferred in when a channel
+// has no preference of its own: highest resolution first.
+var defaultPreference = []Quality{Quality2160p, Quality1080p, Quality720p, QualitySD}
+
+// ParseQuality validates a quality label, ignoring case. The usual broadcast
+// names are accepted too: "4K" and "UHD" for 2160p, "FHD" for 1080p and "HD"
+// for 720p.
+// Returns ErrInvalidQuality for unknown labels.
+func ParseQuality(s string) (Quality, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "2160p", "4k", "uhd":
+		return Quality2160p, nil
+	case "1080p", "1080i", "fhd":
+		return Quality1080p, nil
+	case "720p", "hd":
+		return Quality720p, nil
+	case "sd", "576p", "576i", "480p", "480i":
+		return QualitySD, nil
+	default:
+		return "", ErrInvalidQuality
+	}
+}
+
+// QualityForHeight returns the label of a video with the given height in
+// lines, as measured when analyzing a stream. Returns "" if the height is
+// unknown.
+func QualityForHeight(height int) Quality {
+	switch {
+	case height >= 2000:
+		return Quality2160p
+	case height >= 1000:
+		return Quality1080p
+	case height >= 700:
+		return Quality720p
+	case height > 0:
+		return QualitySD
+	default:
+		return ""
+	}
+}
+
+// Variants selects how the streams of a channel are listed in playlists.
+type Variants string
+
+const (
+	VariantsAll       Variants = ""          // Every stream, labelled ones suffixed with their quality
+	VariantsPreferred Variants = "preferred" // Only the most preferred stream
+)
+
+// ParseVariants validates a variants mode. "all" is accepted for VariantsAll.
+// Returns ErrInvalidVariants for unknown values.
+func ParseVariants(s string) (Variants, error) {
+	switch v := Variants(s); v {
+	case VariantsAll, VariantsPreferred:
+		return v, nil
+	case "all":
+		return VariantsAll, nil
+	default:
+		return VariantsAll, ErrInvalidVariants
+	}
+}
+
+// StreamQuality returns the quality label of the channel's stream with the
+// given infohash, or "" if it has none.
+func (c Channel) StreamQuality(infoHash string) Quality {
+	return c.qualities[infoHash]
+}
+
+// StreamQualities returns the quality labels of the channel's streams by
+// infohash.
+func (c Channel) StreamQualities() map[string]Quality {
+	return maps.Clone(c.qualities)
+}
+
+// SetStreamQuality labels the channel's stream with the given infohash. An
+// empty quality removes its label.
+func (c *Channel) SetStreamQuality(infoHash string, q Quality) {
+	if q == "" {
+		delete(c.qualities, infoHash)
+		return
+	}
+	if c.qualities == nil {
+		c.qualities = make(map[string]Quality)
+	}
+	c.qualities[infoHash] = q
+}
+
+// QualityPreference returns the order the channel's streams are preferred in
+// by quality, or nil if the channel uses the default of highest resolution
+// first.
+func (c Channel) QualityPreference() []Quality {
+	return slices.Clone(c.preference)
+}
+
+// SetQualityPreference replaces the order the channel's streams are preferred
+// in by quality. Duplicates are dropped; an empty list restores the default.
+func (c *Channel) SetQualityPreference(order []Quality) {
+	var preference []Quality
+	for _, q := range order {
+		if !slices.Contains(preference, q) {
+			preference = append(preference, q)
+		}
+	}
+	c.preference = preference
+}
+
+// QualityRank returns how preferred the channel's stream with the given
+// infohash is, lower being better. Labelled streams rank by the position of
+// their label in the channel's preference; streams without a label, or with
+// one the preference leaves out, rank after them all.
+func (c Channel) QualityRank(infoHash string) int {
+	preference := c.preference
+	if len(preference) == 0 {
+		preference = defaultPreference
+	}
+	if i := slices.Index(preference, c.qualities[infoHash]); i >= 0 {
+		return i
+	}
+	return len(preference)
+}
+
+// Variants returns how the channel's streams are listed in playlists.
+func (c Channel) Variants() Variants {
+	return c.variants
+}
+
+// SetVariants changes how the channel's streams are listed in playlists.
+func (c *Channel) SetVariants(v Variants) {
+	c.variants = v
+}
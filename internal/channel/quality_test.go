@@ -0,0 +1,103 @@
+package channel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+)
+
+func TestParseQuality(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    channel.Quality
+		wantErr error
+	}{
+		{"1080p", channel.Quality1080p, nil},
+		{" FHD ", channel.Quality1080p, nil},
+		{"4K", channel.Quality2160p, nil},
+		{"hd", channel.Quality720p, nil},
+		{"sd", channel.QualitySD, nil},
+		{"", "", channel.ErrInvalidQuality},
+		{"8k", "", channel.ErrInvalidQuality},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := channel.ParseQuality(tt.input)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("ParseQuality(%q) = %q, %v; want %q, %v", tt.input, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestQualityForHeight(t *testing.T) {
+	tests := []struct {
+		height int
+		want   channel.Quality
+	}{
+		{2160, channel.Quality2160p},
+		{1080, channel.Quality1080p},
+		{720, channel.Quality720p},
+		{576, channel.QualitySD},
+		{0, ""},
+	}
+
+	for _, tt := range tests {
+		if got := channel.QualityForHeight(tt.height); got != tt.want {
+			t.Errorf("QualityForHeight(%d) = %q, want %q", tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestParseVariants(t *testing.T) {
+	for input, want := range map[string]channel.Variants{
+		"":          channel.VariantsAll,
+		"all":       channel.VariantsAll,
+		"preferred": channel.VariantsPreferred,
+	} {
+		if got, err := channel.ParseVariants(input); err != nil || got != want {
+			t.Errorf("ParseVariants(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := channel.ParseVariants("best"); !errors.Is(err, channel.ErrInvalidVariants) {
+		t.Errorf("ParseVariants(best) error = %v, want ErrInvalidVariants", err)
+	}
+}
+
+func TestChannelQualityRank(t *testing.T) {
+	ch, _ := channel.NewChannel("La 1")
+	ch.SetStreamQuality("uhd", channel.Quality2160p)
+	ch.SetStreamQuality("hd", channel.Quality720p)
+	ch.SetStreamQuality("sd", channel.QualitySD)
+
+	t.Run("prefers the highest resolution by default", func(t *testing.T) {
+		if !(ch.QualityRank("uhd") < ch.QualityRank("hd") && ch.QualityRank("hd") < ch.QualityRank("sd")) {
+			t.Error("expected 2160p before 720p before SD")
+		}
+		if ch.QualityRank("unlabelled") <= ch.QualityRank("sd") {
+			t.Error("expected unlabelled streams last")
+		}
+	})
+
+	t.Run("follows the channel's preference", func(t *testing.T) {
+		ch.SetQualityPreference([]channel.Quality{channel.Quality720p, channel.QualitySD, channel.Quality720p})
+		if got := ch.QualityPreference(); len(got) != 2 {
+			t.Errorf("QualityPreference() = %v, want duplicates dropped", got)
+		}
+		if !(ch.QualityRank("hd") < ch.QualityRank("sd") && ch.QualityRank("sd") < ch.QualityRank("uhd")) {
+			t.Error("expected 720p before SD before labels left out of the preference")
+		}
+		if ch.QualityRank("uhd") != ch.QualityRank("unlabelled") {
+			t.Error("expected labels left out of the preference to rank as unlabelled")
+		}
+	})
+
+	t.Run("removes labels", func(t *testing.T) {
+		ch.SetStreamQuality("uhd", "")
+		if got := ch.StreamQualities(); len(got) != 2 {
+			t.Errorf("StreamQualities() = %v, want the 2160p label removed", got)
+		}
+	})
+}
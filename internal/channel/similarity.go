@@ -0,0 +1,91 @@
+package channel
+
+import "strings"
+
+// regionSuffixes are country tokens some sources append to channel names
+// ("La 1 ES"). They are dropped from the end of names compared by Similarity.
+var regionSuffixes = map[string]bool{
+	"es": true, "esp": true, "spain": true,
+}
+
+// Similarity scores how alike two channel names are, from 0.0 (unrelated) to
+// 1.0 (the same channel). Names are normalized with NormalizeName and lose
+// trailing region tokens, then scored by the higher of their edit distance
+// and token overlap similarities. Edit distance is ignored when the names
+// carry different numbers, so that "DAZN 1" and "DAZN 2" stay apart.
+func Similarity(name1, name2 string) float64 {
+	n1, n2 := similarityKey(name1), similarityKey(name2)
+	if n1 == "" || n2 == "" {
+		return 0.0
+	}
+	if n1 == n2 {
+		return 1.0
+	}
+
+	tokens1, tokens2 := strings.Fields(n1), strings.Fields(n2)
+	score := tokenSimilarity(tokens1, tokens2)
+	if numbers(tokens1) == numbers(tokens2) {
+		score = max(score, editSimilarity(n1, n2))
+	}
+	return score
+}
+
+// similarityKey normalizes a name and strips its trailing region tokens.
+func similarityKey(name string) string {
+	fields := strings.Fields(NormalizeName(name))
+	for len(fields) > 1 && regionSuffixes[fields[len(fields)-1]] {
+		fields = fields[:len(fields)-1]
+	}
+	return strings.Join(fields, " ")
+}
+
+// numbers joins the purely numeric tokens of a name.
+func numbers(tokens []string) string {
+	var nums []string
+	for _, t := range tokens {
+		if strings.Trim(t, "0123456789") == "" {
+			nums = append(nums, t)
+		}
+	}
+	return strings.Join(nums, " ")
+}
+
+// tokenSimilarity is the Dice coefficient of the two token sets.
+func tokenSimilarity(tokens1, tokens2 []string) float64 {
+	set := make(map[string]bool, len(tokens1))
+	for _, t := range tokens1 {
+		set[t] = true
+	}
+	common := 0
+	seen := make(map[string]bool, len(tokens2))
+	for _, t := range tokens2 {
+		if set[t] && !seen[t] {
+			common++
+		}
+		seen[t] = true
+	}
+	return 2 * float64(common) / float64(len(set)+len(seen))
+}
+
+// editSimilarity is one minus the Levenshtein distance between the names,
+// relative to the length of the longer one.
+func editSimilarity(s1, s2 string) float64 {
+	r1, r2 := []rune(s1), []rune(s2)
+	prev := make([]int, len(r2)+1)
+	curr := make([]int, len(r2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(r2)])/float64(max(len(r1), len(r2)))
+}
@@ -0,0 +1,36 @@
+package channel_test
+
+import (
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		atLeast float64
+		below   float64
+	}{
+		{name: "identical after normalization", a: "DAZN 1 FHD", b: "dazn 1", atLeast: 1.0, below: 1.01},
+		{name: "region suffix is ignored", a: "La 1 ES", b: "La 1 HD", atLeast: 1.0, below: 1.01},
+		{name: "typo", a: "Movistar Deportes", b: "Movistar Deprotes", atLeast: 0.85, below: 1.0},
+		{name: "shared tokens", a: "Movistar LaLiga", b: "LaLiga Movistar", atLeast: 1.0, below: 1.01},
+		{name: "different numbers stay apart", a: "DAZN 1", b: "DAZN 2", atLeast: 0.0, below: 0.6},
+		{name: "unrelated", a: "Cuatro", b: "Telecinco", atLeast: 0.0, below: 0.5},
+		{name: "empty", a: "", b: "Cuatro", atLeast: 0.0, below: 0.01},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := channel.Similarity(tt.a, tt.b)
+			if score < tt.atLeast || score >= tt.below {
+				t.Errorf("Similarity(%q, %q) = %v, want in [%v, %v)", tt.a, tt.b, score, tt.atLeast, tt.below)
+			}
+			if reverse := channel.Similarity(tt.b, tt.a); reverse != score {
+				t.Errorf("Similarity is not symmetric: %v vs %v", score, reverse)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package channel
+
+import (
+	"slices"
+	"strings"
+)
+
+// maxTagLength bounds the length of a channel tag.
+const maxTagLength = 32
+
+// ParseTag validates a channel tag and returns it lowercased. Tags are free
+// labels such as "spanish", "kids" or "4k" made of letters, digits and
+// dashes, so they fit in a playlist URL unescaped.
+// Returns ErrInvalidTag otherwise.
+func ParseTag(s string) (string, error) {
+	tag := strings.ToLower(strings.TrimSpace(s))
+	if tag == "" || len(tag) > maxTagLength {
+		return "", ErrInvalidTag
+	}
+	for _, r := range tag {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return "", ErrInvalidTag
+		}
+	}
+	return tag, nil
+}
+
+// Tags returns the channel's tags in alphabetical order.
+func (c Channel) Tags() []string {
+	return slices.Clone(c.tags)
+}
+
+// HasTag reports whether the channel is tagged with tag, in any case.
+func (c Channel) HasTag(tag string) bool {
+	_, found := slices.BinarySearch(c.tags, strings.ToLower(tag))
+	return found
+}
+
+// SetTags replaces the channel's tags. Tags are lowercased, sorted and
+// duplicates dropped. Returns ErrInvalidTag if one is not a valid tag, in
+// which case the tags are left unchanged.
+func (c *Channel) SetTags(tags []string) error {
+	var parsed []string
+	for _, t := range tags {
+		tag, err := ParseTag(t)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, tag)
+	}
+	slices.Sort(parsed)
+	c.tags = slices.Compact(parsed)
+	return nil
+}
@@ -0,0 +1,60 @@
+package channel_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/channel"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr error
+	}{
+		{"spanish", "spanish", nil},
+		{" Kids ", "kids", nil},
+		{"4K", "4k", nil},
+		{"news-24h", "news-24h", nil},
+		{"", "", channel.ErrInvalidTag},
+		{"two words", "", channel.ErrInvalidTag},
+		{"ñ", "", channel.ErrInvalidTag},
+		{"abcdefghijklmnopqrstuvwxyz0123456", "", channel.ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := channel.ParseTag(tt.input)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("ParseTag(%q) = %q, %v; want %q, %v", tt.input, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChannel_SetTags(t *testing.T) {
+	ch, _ := channel.NewChannel("La 1")
+
+	if err := ch.SetTags([]string{"Spanish", "4k", "spanish"}); err != nil {
+		t.Fatalf("SetTags() error = %v", err)
+	}
+	if got := ch.Tags(); !slices.Equal(got, []string{"4k", "spanish"}) {
+		t.Errorf("Tags() = %v, want [4k spanish]", got)
+	}
+	if !ch.HasTag("SPANISH") || ch.HasTag("kids") {
+		t.Errorf("HasTag() does not match Tags() = %v", ch.Tags())
+	}
+
+	if err := ch.SetTags([]string{"kids", "not valid"}); !errors.Is(err, channel.ErrInvalidTag) {
+		t.Errorf("SetTags() error = %v, want ErrInvalidTag", err)
+	}
+	if got := ch.Tags(); !slices.Equal(got, []string{"4k", "spanish"}) {
+		t.Errorf("expected tags unchanged after an invalid tag, got %v", got)
+	}
+
+	if err := ch.SetTags(nil); err != nil || len(ch.Tags()) != 0 {
+		t.Errorf("SetTags(nil) = %v, leaving %v", err, ch.Tags())
+	}
+}
@@ -0,0 +1,194 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State represents the current state of a circuit breaker.
+type State string
+
+const (
+	StateClosed   State = "closed"    // Calls flow normally
+	StateOpen     State = "open"      // Calls are rejected until the timeout elapses
+	StateHalfOpen State = "half-open" // A trial call is allowed through
+)
+
+// Breaker is a consecutive-failure circuit breaker. After threshold
+// consecutive failures it opens and rejects calls for the configured timeout,
+// then half-opens to let a trial call through. A success closes it again; a
+// failure while half-open re-opens it for another full timeout.
+//
+// A threshold of zero or less disables the breaker: Allow always succeeds.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	timeout   time.Duration
+	now       func() time.Time
+	state     State
+	failures  int
+	openedAt  time.Time
+}
+
+// New creates a closed breaker that opens after threshold consecutive failures
+// and stays open for timeout.
+func New(threshold int, timeout time.Duration) *Breaker {
+	return NewWithClock(threshold, timeout, time.Now)
+}
+
+// NewWithClock is like New but uses the given clock, which lets callers
+// control time in tests.
+func NewWithClock(threshold int, timeout time.Duration, now func() time.Time) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		timeout:   timeout,
+		now:       now,
+		state:     StateClosed,
+	}
+}
+
+// Allow reports whether a call may proceed. While the breaker is open it
+// returns an *OpenError carrying the remaining open duration. Once the
+// timeout has elapsed the breaker transitions to half-open and the call is
+// allowed through as a trial.
+func (b *Breaker) Allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateOpen {
+		return nil
+	}
+
+	if remaining := b.remainingLocked(); remaining > 0 {
+		return &OpenError{RetryAfter: remaining}
+	}
+
+	b.state = StateHalfOpen
+	return nil
+}
+
+// RecordSuccess resets the failure count and closes the breaker.
+func (b *Breaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.state = StateClosed
+}
+
+// RecordFailure counts a failed call, opening the breaker once the threshold
+// is reached or immediately if the failed call was a half-open trial.
+func (b *Breaker) RecordFailure() {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+}
+
+// State returns the breaker's current state. An open breaker whose timeout
+// has elapsed reports StateHalfOpen.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.remainingLocked() <= 0 {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// RetryAfter returns how long until an open breaker half-opens.
+// Returns zero when the breaker is not open.
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateOpen {
+		return 0
+	}
+	return max(b.remainingLocked(), 0)
+}
+
+// Failures returns the current consecutive failure count.
+func (b *Breaker) Failures() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures
+}
+
+// Reset closes the breaker and clears its failure count, e.g. once an
+// operator has fixed the problem that opened it.
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.state = StateClosed
+	b.openedAt = time.Time{}
+}
+
+// Configure changes the failure threshold and open timeout. An open breaker
+// keeps its opening time, so a new timeout also moves when it half-opens.
+// A threshold of zero or less disables the breaker and closes it.
+func (b *Breaker) Configure(threshold int, timeout time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.threshold = threshold
+	b.timeout = timeout
+	if threshold <= 0 {
+		b.failures = 0
+		b.state = StateClosed
+	}
+}
+
+// Stats is a snapshot of a breaker's configuration and state.
+type Stats struct {
+	State     State
+	Failures  int
+	Threshold int // zero or less when the breaker is disabled
+	Timeout   time.Duration
+	// OpenedAt is when the breaker last opened, zero if it never has.
+	OpenedAt time.Time
+	// RetryAt is when an open breaker half-opens, zero when not open.
+	RetryAt time.Time
+}
+
+// Stats returns a snapshot of the breaker. Like State, an open breaker whose
+// timeout has elapsed reports StateHalfOpen.
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := Stats{
+		State:     b.state,
+		Failures:  b.failures,
+		Threshold: b.threshold,
+		Timeout:   b.timeout,
+		OpenedAt:  b.openedAt,
+	}
+	if b.state == StateOpen {
+		if b.remainingLocked() <= 0 {
+			st.State = StateHalfOpen
+		} else {
+			st.RetryAt = b.openedAt.Add(b.timeout)
+		}
+	}
+	return st
+}
+
+func (b *Breaker) remainingLocked() time.Duration {
+	return b.timeout - b.now().Sub(b.openedAt)
+}
@@ -0,0 +1,181 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for deterministic breaker tests.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := NewWithClock(3, 30*time.Second, clock.Now)
+
+	for i := 0; i < 2; i++ {
+		b.RecordFailure()
+		if err := b.Allow(); err != nil {
+			t.Fatalf("breaker should stay closed after %d failures, got %v", i+1, err)
+		}
+	}
+
+	b.RecordFailure()
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %q, want %q", got, StateOpen)
+	}
+
+	err := b.Allow()
+	if !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() = %v, want ErrOpen", err)
+	}
+}
+
+func TestBreaker_RetryAfterReflectsRemainingOpenTime(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := NewWithClock(1, 30*time.Second, clock.Now)
+
+	b.RecordFailure()
+	clock.Advance(12 * time.Second)
+
+	var openErr *OpenError
+	if err := b.Allow(); !errors.As(err, &openErr) {
+		t.Fatalf("Allow() = %v, want *OpenError", err)
+	}
+	if openErr.RetryAfter != 18*time.Second {
+		t.Errorf("RetryAfter = %v, want 18s", openErr.RetryAfter)
+	}
+	if got := b.RetryAfter(); got != 18*time.Second {
+		t.Errorf("RetryAfter() = %v, want 18s", got)
+	}
+}
+
+func TestBreaker_HalfOpenTransitions(t *testing.T) {
+	t.Run("success after timeout closes the breaker", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1700000000, 0)}
+		b := NewWithClock(1, 10*time.Second, clock.Now)
+
+		b.RecordFailure()
+		clock.Advance(10 * time.Second)
+
+		if got := b.State(); got != StateHalfOpen {
+			t.Fatalf("State() = %q, want %q", got, StateHalfOpen)
+		}
+		if err := b.Allow(); err != nil {
+			t.Fatalf("trial call should be allowed, got %v", err)
+		}
+
+		b.RecordSuccess()
+		if got := b.State(); got != StateClosed {
+			t.Errorf("State() = %q, want %q", got, StateClosed)
+		}
+		if b.Failures() != 0 {
+			t.Errorf("Failures() = %d, want 0", b.Failures())
+		}
+	})
+
+	t.Run("failed trial re-opens for a full timeout", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(1700000000, 0)}
+		b := NewWithClock(3, 10*time.Second, clock.Now)
+
+		for i := 0; i < 3; i++ {
+			b.RecordFailure()
+		}
+		clock.Advance(11 * time.Second)
+		if err := b.Allow(); err != nil {
+			t.Fatalf("trial call should be allowed, got %v", err)
+		}
+
+		b.RecordFailure()
+		if got := b.RetryAfter(); got != 10*time.Second {
+			t.Errorf("RetryAfter() = %v, want 10s", got)
+		}
+	})
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := New(0, time.Minute)
+
+	for i := 0; i < 10; i++ {
+		b.RecordFailure()
+	}
+
+	if err := b.Allow(); err != nil {
+		t.Errorf("disabled breaker should always allow, got %v", err)
+	}
+	if got := b.State(); got != StateClosed {
+		t.Errorf("State() = %q, want %q", got, StateClosed)
+	}
+}
+
+func TestBreaker_Stats(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := NewWithClock(2, 30*time.Second, clock.Now)
+
+	b.RecordFailure()
+	st := b.Stats()
+	if st.State != StateClosed || st.Failures != 1 || st.Threshold != 2 || st.Timeout != 30*time.Second {
+		t.Fatalf("unexpected stats for closed breaker: %+v", st)
+	}
+	if !st.RetryAt.IsZero() {
+		t.Errorf("closed breaker should have no retry time, got %v", st.RetryAt)
+	}
+
+	openedAt := clock.Now()
+	b.RecordFailure()
+	st = b.Stats()
+	if st.State != StateOpen || !st.OpenedAt.Equal(openedAt) || !st.RetryAt.Equal(openedAt.Add(30*time.Second)) {
+		t.Fatalf("unexpected stats for open breaker: %+v", st)
+	}
+
+	clock.Advance(31 * time.Second)
+	if st := b.Stats(); st.State != StateHalfOpen || !st.RetryAt.IsZero() {
+		t.Errorf("expected half-open with no retry time after the timeout, got %+v", st)
+	}
+}
+
+func TestBreaker_Reset(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := NewWithClock(1, time.Minute, clock.Now)
+
+	b.RecordFailure()
+	if err := b.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() = %v, want ErrOpen", err)
+	}
+
+	b.Reset()
+	if err := b.Allow(); err != nil {
+		t.Fatalf("Allow() after Reset = %v, want nil", err)
+	}
+	if st := b.Stats(); st.State != StateClosed || st.Failures != 0 || !st.OpenedAt.IsZero() {
+		t.Errorf("unexpected stats after Reset: %+v", st)
+	}
+}
+
+func TestBreaker_Configure(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := NewWithClock(3, 30*time.Second, clock.Now)
+
+	b.Configure(1, 10*time.Second)
+	b.RecordFailure()
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("State() = %q, want %q after one failure with threshold 1", got, StateOpen)
+	}
+	if got := b.RetryAfter(); got != 10*time.Second {
+		t.Errorf("RetryAfter() = %v, want %v", got, 10*time.Second)
+	}
+
+	b.Configure(0, 10*time.Second)
+	if err := b.Allow(); err != nil {
+		t.Errorf("Allow() = %v, want nil once disabled", err)
+	}
+	if got := b.State(); got != StateClosed {
+		t.Errorf("State() = %q, want %q once disabled", got, StateClosed)
+	}
+}
@@ -0,0 +1,30 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrOpen indicates the breaker is open and calls are being rejected.
+	ErrOpen = errors.New("circuit breaker is open")
+	// ErrNotFound indicates no breaker is registered under the given ID.
+	ErrNotFound = errors.New("circuit breaker not found")
+)
+
+// OpenError is returned by Allow while the breaker is open. It carries the
+// remaining time until the breaker half-opens so callers can surface an
+// accurate Retry-After hint.
+type OpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s: retry after %s", ErrOpen, e.RetryAfter)
+}
+
+// Is reports whether target is ErrOpen, so errors.Is(err, ErrOpen) matches.
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
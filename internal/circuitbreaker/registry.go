@@ -0,0 +1,60 @@
+package circuitbreaker
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry names the breakers guarding upstreams so their state can be
+// inspected and reset from outside the code that uses them.
+type Registry struct {
+	mu       sync.RWMutex
+	breakers map[string]*Breaker
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{breakers: make(map[string]*Breaker)}
+}
+
+// Register tracks b under id, replacing any breaker already registered there.
+func (r *Registry) Register(id string, b *Breaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breakers[id] = b
+}
+
+// Get returns the breaker registered under id.
+// Returns ErrNotFound if there is none.
+func (r *Registry) Get(id string) (*Breaker, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	b, ok := r.breakers[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return b, nil
+}
+
+// IDs returns the IDs of all registered breakers in sorted order.
+func (r *Registry) IDs() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.breakers))
+	for id := range r.breakers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Reset closes the breaker registered under id.
+// Returns ErrNotFound if there is none.
+func (r *Registry) Reset(id string) error {
+	b, err := r.Get(id)
+	if err != nil {
+		return err
+	}
+	b.Reset()
+	return nil
+}
@@ -0,0 +1,38 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	engine := New(1, time.Minute)
+	r.Register("engine", engine)
+	r.Register("source", New(1, time.Minute))
+
+	if got := r.IDs(); !slices.Equal(got, []string{"engine", "source"}) {
+		t.Errorf("IDs() = %v", got)
+	}
+
+	b, err := r.Get("engine")
+	if err != nil || b != engine {
+		t.Fatalf("Get(engine) = %v, %v", b, err)
+	}
+	if _, err := r.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	engine.RecordFailure()
+	if err := r.Reset("engine"); err != nil {
+		t.Fatalf("Reset(engine) = %v", err)
+	}
+	if engine.State() != StateClosed {
+		t.Errorf("expected engine breaker closed after reset, got %q", engine.State())
+	}
+	if err := r.Reset("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Reset(missing) error = %v, want ErrNotFound", err)
+	}
+}
@@ -10,6 +10,9 @@ var (
 	ErrEmptyEPGID = errors.New("epg channel epg id cannot be empty")
 	ErrEmptyURL   = errors.New("epg source url cannot be empty")
 
+	// Programme validation errors
+	ErrInvalidProgrammeTime = errors.New("epg programme must stop after it starts")
+
 	// EPG operation errors
 	ErrInvalidEPGFormat = errors.New("invalid epg format")
 	ErrChannelNotFound  = errors.New("epg channel not found")
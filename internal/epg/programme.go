@@ -0,0 +1,54 @@
+package epg
+
+import (
+	"strings"
+	"time"
+)
+
+// Programme is a broadcast listed in the guide of an EPG channel.
+type Programme struct {
+	channelID string
+	title     string
+	start     time.Time
+	stop      time.Time
+}
+
+// NewProgramme creates a new Programme of the EPG channel channelID.
+// Returns ErrEmptyID if channelID is empty or contains only whitespace.
+// Returns ErrInvalidProgrammeTime if start is zero or stop is not after it.
+func NewProgramme(channelID, title string, start, stop time.Time) (Programme, error) {
+	trimmedID := strings.TrimSpace(channelID)
+	if trimmedID == "" {
+		return Programme{}, ErrEmptyID
+	}
+	if start.IsZero() || !stop.After(start) {
+		return Programme{}, ErrInvalidProgrammeTime
+	}
+
+	return Programme{
+		channelID: trimmedID,
+		title:     strings.TrimSpace(title),
+		start:     start,
+		stop:      stop,
+	}, nil
+}
+
+// ChannelID returns the EPG identifier of the programme's channel.
+func (p Programme) ChannelID() string {
+	return p.channelID
+}
+
+// Title returns the programme's title.
+func (p Programme) Title() string {
+	return p.title
+}
+
+// Start returns when the programme starts.
+func (p Programme) Start() time.Time {
+	return p.start
+}
+
+// Stop returns when the programme ends.
+func (p Programme) Stop() time.Time {
+	return p.stop
+}
@@ -0,0 +1,49 @@
+package epg_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/epg"
+)
+
+func TestNewProgramme(t *testing.T) {
+	start := time.Date(2024, 5, 1, 20, 0, 0, 0, time.UTC)
+	stop := start.Add(2 * time.Hour)
+
+	tests := []struct {
+		name      string
+		channelID string
+		start     time.Time
+		stop      time.Time
+		wantError error
+	}{
+		{name: "valid programme", channelID: " sport-1 ", start: start, stop: stop},
+		{name: "empty channel id", channelID: "  ", start: start, stop: stop, wantError: epg.ErrEmptyID},
+		{name: "zero start", channelID: "sport-1", stop: stop, wantError: epg.ErrInvalidProgrammeTime},
+		{name: "stop before start", channelID: "sport-1", start: stop, stop: start, wantError: epg.ErrInvalidProgrammeTime},
+		{name: "stop equal to start", channelID: "sport-1", start: start, stop: start, wantError: epg.ErrInvalidProgrammeTime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := epg.NewProgramme(tt.channelID, " Final ", tt.start, tt.stop)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NewProgramme() error = %v, want %v", err, tt.wantError)
+			}
+			if err != nil {
+				return
+			}
+			if p.ChannelID() != "sport-1" {
+				t.Errorf("ChannelID() = %q, want %q", p.ChannelID(), "sport-1")
+			}
+			if p.Title() != "Final" {
+				t.Errorf("Title() = %q, want %q", p.Title(), "Final")
+			}
+			if !p.Start().Equal(tt.start) || !p.Stop().Equal(tt.stop) {
+				t.Errorf("times = %v-%v, want %v-%v", p.Start(), p.Stop(), tt.start, tt.stop)
+			}
+		})
+	}
+}
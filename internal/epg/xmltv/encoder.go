@@ -0,0 +1,107 @@
+// Package xmltv encodes channel guides in the XMLTV format understood by
+// players such as Jellyfin and TiviMate.
+package xmltv
+
+import (
+	"cmp"
+	"encoding/xml"
+	"io"
+	"slices"
+	"time"
+)
+
+// GeneratorName identifies iptv-manager as the producer of encoded documents.
+const GeneratorName = "iptv-manager"
+
+// timeLayout is the XMLTV date format of programme start and stop times.
+const timeLayout = "20060102150405 -0700"
+
+// Channel is a single guide channel. ID must match the tvg-id used in the
+// M3U playlist so players can link the two.
+type Channel struct {
+	ID          string
+	DisplayName string
+}
+
+// Programme is a broadcast on the guide channel ChannelID.
+type Programme struct {
+	ChannelID string
+	Title     string
+	Start     time.Time
+	Stop      time.Time
+}
+
+// document is the root <tv> element of an XMLTV document.
+type document struct {
+	XMLName           xml.Name           `xml:"tv"`
+	GeneratorInfoName string             `xml:"generator-info-name,attr"`
+	Channels          []channelElement   `xml:"channel"`
+	Programmes        []programmeElement `xml:"programme"`
+}
+
+// channelElement is a <channel> element of an XMLTV document.
+type channelElement struct {
+	ID          string `xml:"id,attr"`
+	DisplayName string `xml:"display-name"`
+}
+
+// programmeElement is a <programme> element of an XMLTV document.
+type programmeElement struct {
+	Start   string `xml:"start,attr"`
+	Stop    string `xml:"stop,attr"`
+	Channel string `xml:"channel,attr"`
+	Title   string `xml:"title"`
+}
+
+// Encode writes an XMLTV document containing the given channels and their
+// programmes to w. Channels are sorted by ID and duplicate IDs keep their
+// first occurrence. Programmes of channels not listed are left out; the rest
+// are sorted by channel and start time. An empty channel list produces a
+// valid, empty <tv> document.
+func Encode(w io.Writer, channels []Channel, programmes []Programme) error {
+	doc := document{GeneratorInfoName: GeneratorName}
+
+	seen := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		if ch.ID == "" || seen[ch.ID] {
+			continue
+		}
+		seen[ch.ID] = true
+		doc.Channels = append(doc.Channels, channelElement{ID: ch.ID, DisplayName: ch.DisplayName})
+	}
+
+	slices.SortFunc(doc.Channels, func(a, b channelElement) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	listed := make([]Programme, 0, len(programmes))
+	for _, p := range programmes {
+		if seen[p.ChannelID] {
+			listed = append(listed, p)
+		}
+	}
+	slices.SortStableFunc(listed, func(a, b Programme) int {
+		return cmp.Or(cmp.Compare(a.ChannelID, b.ChannelID), a.Start.Compare(b.Start))
+	})
+	for _, p := range listed {
+		doc.Programmes = append(doc.Programmes, programmeElement{
+			Start:   p.Start.Format(timeLayout),
+			Stop:    p.Stop.Format(timeLayout),
+			Channel: p.ChannelID,
+			Title:   p.Title,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,100 @@
+package xmltv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	t.Run("empty channel list produces valid empty document", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Encode(&buf, nil, nil); err != nil {
+			t.Fatalf("Encode returned error: %v", err)
+		}
+
+		if !strings.HasPrefix(buf.String(), xml.Header) {
+			t.Errorf("expected XML declaration, got %q", buf.String())
+		}
+
+		var doc document
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("output is not valid XML: %v", err)
+		}
+		if doc.XMLName.Local != "tv" {
+			t.Errorf("expected root element <tv>, got <%s>", doc.XMLName.Local)
+		}
+		if doc.GeneratorInfoName != GeneratorName {
+			t.Errorf("generator-info-name = %q, want %q", doc.GeneratorInfoName, GeneratorName)
+		}
+		if len(doc.Channels) != 0 {
+			t.Errorf("expected no channels, got %d", len(doc.Channels))
+		}
+	})
+
+	t.Run("channels are sorted, deduplicated and escaped", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := Encode(&buf, []Channel{
+			{ID: "b.tv", DisplayName: "B & Co"},
+			{ID: "a.tv", DisplayName: "A"},
+			{ID: "b.tv", DisplayName: "Duplicate"},
+			{ID: "", DisplayName: "No ID"},
+		}, nil)
+		if err != nil {
+			t.Fatalf("Encode returned error: %v", err)
+		}
+
+		if !strings.Contains(buf.String(), "B &amp; Co") {
+			t.Errorf("expected display name to be escaped, got %q", buf.String())
+		}
+
+		var doc document
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("output is not valid XML: %v", err)
+		}
+		want := []channelElement{{ID: "a.tv", DisplayName: "A"}, {ID: "b.tv", DisplayName: "B & Co"}}
+		if len(doc.Channels) != len(want) {
+			t.Fatalf("expected %d channels, got %d", len(want), len(doc.Channels))
+		}
+		for i := range want {
+			if doc.Channels[i] != want[i] {
+				t.Errorf("channel %d = %+v, want %+v", i, doc.Channels[i], want[i])
+			}
+		}
+	})
+	t.Run("programmes of listed channels are sorted by channel and start", func(t *testing.T) {
+		madrid := time.FixedZone("CET", 3600)
+		at := func(hour int) time.Time { return time.Date(2024, 5, 1, hour, 0, 0, 0, madrid) }
+
+		var buf bytes.Buffer
+		err := Encode(&buf, []Channel{{ID: "a.tv", DisplayName: "A"}, {ID: "b.tv", DisplayName: "B"}}, []Programme{
+			{ChannelID: "b.tv", Title: "Late", Start: at(21), Stop: at(22)},
+			{ChannelID: "a.tv", Title: "News", Start: at(20), Stop: at(21)},
+			{ChannelID: "b.tv", Title: "Early", Start: at(8), Stop: at(9)},
+			{ChannelID: "c.tv", Title: "Unlisted", Start: at(8), Stop: at(9)},
+		})
+		if err != nil {
+			t.Fatalf("Encode returned error: %v", err)
+		}
+
+		var doc document
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("output is not valid XML: %v", err)
+		}
+		want := []programmeElement{
+			{Start: "20240501200000 +0100", Stop: "20240501210000 +0100", Channel: "a.tv", Title: "News"},
+			{Start: "20240501080000 +0100", Stop: "20240501090000 +0100", Channel: "b.tv", Title: "Early"},
+			{Start: "20240501210000 +0100", Stop: "20240501220000 +0100", Channel: "b.tv", Title: "Late"},
+		}
+		if len(doc.Programmes) != len(want) {
+			t.Fatalf("expected %d programmes, got %+v", len(want), doc.Programmes)
+		}
+		for i := range want {
+			if doc.Programmes[i] != want[i] {
+				t.Errorf("programme %d = %+v, want %+v", i, doc.Programmes[i], want[i])
+			}
+		}
+	})
+}
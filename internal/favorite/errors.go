@@ -0,0 +1,15 @@
+package favorite
+
+import "errors"
+
+// Domain errors for favorite list operations.
+var (
+	// List validation errors
+	ErrEmptyName        = errors.New("favorite list name cannot be empty")
+	ErrInvalidName      = errors.New("favorite list name must contain a letter or digit")
+	ErrEmptyChannelName = errors.New("favorite channel name cannot be empty")
+
+	// List operation errors
+	ErrListNotFound      = errors.New("favorite list not found")
+	ErrListAlreadyExists = errors.New("favorite list already exists")
+)
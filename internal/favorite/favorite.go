@@ -0,0 +1,126 @@
+// Package favorite models named lists of favorite channels, each kept for a
+// device such as "Living room TV" or "Kids tablet" and served as a playlist
+// of its own.
+package favorite
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// List is a named, ordered selection of channels. Its playlist lists the
+// channels in the order of the list, and its guide, if enabled, only
+// covers them.
+type List struct {
+	id       string
+	name     string
+	channels []string
+	guide    bool
+}
+
+// NewList creates an empty List. Its ID is derived from the name (see Slug)
+// and stays the same if the list is renamed.
+// Returns ErrEmptyName if the name is empty or contains only whitespace.
+// Returns ErrInvalidName if the name has no letters or digits to build an ID from.
+func NewList(name string) (List, error) {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		return List{}, ErrEmptyName
+	}
+
+	id := Slug(trimmed)
+	if id == "" {
+		return List{}, ErrInvalidName
+	}
+
+	return List{id: id, name: trimmed}, nil
+}
+
+// ReconstructList rebuilds a List from persisted state.
+// This is intended for repository adapters only — it bypasses the validation
+// applied by NewList and SetChannels.
+func ReconstructList(id, name string, channels []string, guide bool) List {
+	return List{
+		id:       id,
+		name:     name,
+		channels: channels,
+		guide:    guide,
+	}
+}
+
+// ID returns the list's stable identifier, used in its playlist URL.
+func (l List) ID() string {
+	return l.id
+}
+
+// Name returns the list's display name.
+func (l List) Name() string {
+	return l.name
+}
+
+// Channels returns the names of the list's channels in order.
+func (l List) Channels() []string {
+	return slices.Clone(l.channels)
+}
+
+// HasGuide reports whether the list's playlist points players at a guide
+// covering only its channels, rather than at the full guide.
+func (l List) HasGuide() bool {
+	return l.guide
+}
+
+// Rename changes the list's display name, keeping its ID.
+// Returns ErrEmptyName if the name is empty or contains only whitespace.
+func (l *List) Rename(name string) error {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		return ErrEmptyName
+	}
+	l.name = trimmed
+	return nil
+}
+
+// SetChannels replaces the list's channels, in order. Names are trimmed and
+// a channel listed twice keeps its first position.
+// Returns ErrEmptyChannelName if a name is empty; the list is unchanged in
+// that case.
+func (l *List) SetChannels(names []string) error {
+	channels := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return ErrEmptyChannelName
+		}
+		if !slices.Contains(channels, name) {
+			channels = append(channels, name)
+		}
+	}
+	l.channels = channels
+	return nil
+}
+
+// SetGuide enables or disables the guide covering only the list's channels.
+func (l *List) SetGuide(enabled bool) {
+	l.guide = enabled
+}
+
+// Slug derives a list ID from a name: letters and digits are lowercased
+// and every other run of characters becomes a single hyphen, e.g.
+// "Living room TV" becomes "living-room-tv".
+func Slug(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package favorite_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/favorite"
+)
+
+func TestNewList(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantID    string
+		wantName  string
+		wantError error
+	}{
+		{name: "valid list", input: "Living room TV", wantID: "living-room-tv", wantName: "Living room TV"},
+		{name: "trims whitespace", input: "  Kids tablet ", wantID: "kids-tablet", wantName: "Kids tablet"},
+		{name: "empty name", input: "  ", wantError: favorite.ErrEmptyName},
+		{name: "no letters or digits", input: "***", wantError: favorite.ErrInvalidName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := favorite.NewList(tt.input)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NewList() error = %v, want %v", err, tt.wantError)
+			}
+			if tt.wantError != nil {
+				return
+			}
+			if l.ID() != tt.wantID || l.Name() != tt.wantName {
+				t.Errorf("NewList() = (%q, %q), want (%q, %q)", l.ID(), l.Name(), tt.wantID, tt.wantName)
+			}
+			if len(l.Channels()) != 0 || l.HasGuide() {
+				t.Errorf("expected an empty list without guide, got channels=%v guide=%v", l.Channels(), l.HasGuide())
+			}
+		})
+	}
+}
+
+func TestList_Rename(t *testing.T) {
+	l, _ := favorite.NewList("Living room")
+
+	if err := l.Rename(" Salón "); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if l.Name() != "Salón" || l.ID() != "living-room" {
+		t.Errorf("expected renamed list to keep its ID, got (%q, %q)", l.ID(), l.Name())
+	}
+	if err := l.Rename(""); !errors.Is(err, favorite.ErrEmptyName) {
+		t.Errorf("Rename() error = %v, want ErrEmptyName", err)
+	}
+}
+
+func TestList_SetChannels(t *testing.T) {
+	l, _ := favorite.NewList("Kids")
+
+	if err := l.SetChannels([]string{" Cartoons ", "Nature", "Cartoons", "Music"}); err != nil {
+		t.Fatalf("SetChannels() error = %v", err)
+	}
+	if want := []string{"Cartoons", "Nature", "Music"}; !slices.Equal(l.Channels(), want) {
+		t.Errorf("Channels() = %v, want %v", l.Channels(), want)
+	}
+
+	if err := l.SetChannels([]string{"News", " "}); !errors.Is(err, favorite.ErrEmptyChannelName) {
+		t.Errorf("SetChannels() error = %v, want ErrEmptyChannelName", err)
+	}
+	if want := []string{"Cartoons", "Nature", "Music"}; !slices.Equal(l.Channels(), want) {
+		t.Errorf("expected a rejected update to leave the channels, got %v", l.Channels())
+	}
+
+	channels := l.Channels()
+	channels[0] = "Changed"
+	if l.Channels()[0] != "Cartoons" {
+		t.Error("expected Channels() to return a copy")
+	}
+}
@@ -0,0 +1,132 @@
+package group
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Domain errors
+var (
+	ErrEmptyName          = errors.New("group name cannot be empty")
+	ErrInvalidName        = errors.New("group name must contain a letter or digit")
+	ErrGroupNotFound      = errors.New("group not found")
+	ErrGroupAlreadyExists = errors.New("group already exists")
+)
+
+// Group is a manager-defined set of channels. Playlists list a channel under
+// its group's name, independently of the group-title of its upstream source,
+// and leave out the channels of disabled groups.
+type Group struct {
+	id       string
+	name     string
+	position int
+	enabled  bool
+}
+
+// NewGroup creates an enabled Group at position zero. Its ID is derived from
+// the name (see Slug) and stays the same if the group is renamed.
+// Returns ErrEmptyName if the name is empty or contains only whitespace.
+// Returns ErrInvalidName if the name has no letters or digits to build an ID from.
+func NewGroup(name string) (Group, error) {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		return Group{}, ErrEmptyName
+	}
+
+	id := Slug(trimmed)
+	if id == "" {
+		return Group{}, ErrInvalidName
+	}
+
+	return Group{
+		id:      id,
+		name:    trimmed,
+		enabled: true,
+	}, nil
+}
+
+// ReconstructGroup rebuilds a Group from persisted state.
+// This is intended for repository adapters only — it bypasses the validation
+// and defaults applied by NewGroup.
+func ReconstructGroup(id, name string, position int, enabled bool) Group {
+	return Group{
+		id:       id,
+		name:     name,
+		position: position,
+		enabled:  enabled,
+	}
+}
+
+// ID returns the group's stable identifier.
+func (g Group) ID() string {
+	return g.id
+}
+
+// Name returns the group's display name, emitted as the playlist group-title.
+func (g Group) Name() string {
+	return g.name
+}
+
+// Position returns where the group is listed relative to other groups.
+// Lower positions come first.
+func (g Group) Position() int {
+	return g.position
+}
+
+// IsEnabled returns whether the group's channels are included in playlists.
+func (g Group) IsEnabled() bool {
+	return g.enabled
+}
+
+// Rename changes the group's display name, keeping its ID.
+// Returns ErrEmptyName if the name is empty or contains only whitespace.
+func (g *Group) Rename(name string) error {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		return ErrEmptyName
+	}
+	g.name = trimmed
+	return nil
+}
+
+// SetPosition moves the group to the given position.
+func (g *Group) SetPosition(position int) {
+	g.position = position
+}
+
+// SetEnabled includes or excludes the group's channels from playlists.
+func (g *Group) SetEnabled(enabled bool) {
+	g.enabled = enabled
+}
+
+// Sort orders groups by position, breaking ties by name.
+func Sort(groups []Group) {
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].position != groups[j].position {
+			return groups[i].position < groups[j].position
+		}
+		return groups[i].name < groups[j].name
+	})
+}
+
+// Slug derives a group ID from a name: letters and digits are lowercased
+// and every other run of characters becomes a single hyphen, e.g.
+// "Sports & News" becomes "sports-news".
+func Slug(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package group_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/group"
+)
+
+func TestNewGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantID    string
+		wantName  string
+		wantError error
+	}{
+		{name: "valid group", input: "Sports", wantID: "sports", wantName: "Sports"},
+		{name: "trims whitespace", input: "  News  ", wantID: "news", wantName: "News"},
+		{name: "slugs punctuation", input: "Movies & Series (HD)", wantID: "movies-series-hd", wantName: "Movies & Series (HD)"},
+		{name: "keeps unicode letters", input: "Fútbol España", wantID: "fútbol-españa", wantName: "Fútbol España"},
+		{name: "empty name", input: "   ", wantError: group.ErrEmptyName},
+		{name: "no letters or digits", input: "!!!", wantError: group.ErrInvalidName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := group.NewGroup(tt.input)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NewGroup() error = %v, want %v", err, tt.wantError)
+			}
+			if tt.wantError != nil {
+				return
+			}
+			if g.ID() != tt.wantID || g.Name() != tt.wantName {
+				t.Errorf("NewGroup() = (%q, %q), want (%q, %q)", g.ID(), g.Name(), tt.wantID, tt.wantName)
+			}
+			if !g.IsEnabled() || g.Position() != 0 {
+				t.Errorf("expected an enabled group at position 0, got enabled=%v position=%d", g.IsEnabled(), g.Position())
+			}
+		})
+	}
+}
+
+func TestGroup_Rename(t *testing.T) {
+	g, _ := group.NewGroup("Sports")
+
+	if err := g.Rename("  Deportes "); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if g.Name() != "Deportes" || g.ID() != "sports" {
+		t.Errorf("expected renamed group to keep its ID, got (%q, %q)", g.ID(), g.Name())
+	}
+
+	if err := g.Rename(" "); !errors.Is(err, group.ErrEmptyName) {
+		t.Errorf("Rename() error = %v, want ErrEmptyName", err)
+	}
+}
+
+func TestSort(t *testing.T) {
+	groups := []group.Group{
+		group.ReconstructGroup("news", "News", 2, true),
+		group.ReconstructGroup("sports", "Sports", 1, true),
+		group.ReconstructGroup("kids", "Kids", 2, false),
+	}
+
+	group.Sort(groups)
+
+	var ids []string
+	for _, g := range groups {
+		ids = append(ids, g.ID())
+	}
+	if want := []string{"sports", "kids", "news"}; !slices.Equal(ids, want) {
+		t.Errorf("Sort() = %v, want %v", ids, want)
+	}
+}
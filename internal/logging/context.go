@@ -0,0 +1,62 @@
+// Package logging carries request-scoped attributes such as the request ID
+// through contexts so that every log line written on behalf of a request,
+// from the HTTP handler down to the engine adapter, can be correlated, and
+// samples the messages logged on hot paths.
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// RequestIDKey is the log attribute key for request IDs.
+const RequestIDKey = "request_id"
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the given request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// ContextHandler is a slog.Handler that adds the request ID carried by the
+// context of each record, so callers only have to use the *Context logging
+// methods (InfoContext, WarnContext, ...) to get correlated logs.
+type ContextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler wraps next with request ID propagation.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds the request ID, if any, and passes the record on.
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r = r.Clone()
+		r.AddAttrs(slog.String(RequestIDKey, id))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a ContextHandler wrapping the wrapped handler's WithAttrs.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup returns a ContextHandler wrapping the wrapped handler's WithGroup.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,50 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestContextHandler(t *testing.T) {
+	t.Run("adds the request ID from the context", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+
+		ctx := WithRequestID(context.Background(), "req-1")
+		logger.With("component", "test").InfoContext(ctx, "hello")
+
+		var record map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("failed to decode log line: %v", err)
+		}
+		if record[RequestIDKey] != "req-1" {
+			t.Errorf("expected request_id req-1, got %v", record[RequestIDKey])
+		}
+		if record["component"] != "test" {
+			t.Errorf("expected attributes to be kept, got %v", record)
+		}
+	})
+
+	t.Run("leaves records without a request ID untouched", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil)))
+
+		logger.InfoContext(context.Background(), "hello")
+
+		if bytes.Contains(buf.Bytes(), []byte(RequestIDKey)) {
+			t.Errorf("expected no request_id, got %s", buf.String())
+		}
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	if id := RequestID(context.Background()); id != "" {
+		t.Errorf("expected empty request ID, got %q", id)
+	}
+	if id := RequestID(WithRequestID(context.Background(), "abc")); id != "abc" {
+		t.Errorf("expected abc, got %q", id)
+	}
+}
@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// OccurrencesKey is the log attribute key for the number of times a sampled
+// message occurred, logged or not.
+const OccurrencesKey = "occurrences"
+
+// SamplingHandler is a slog.Handler that passes on the first of every n
+// records with the same message and drops the others, so messages logged
+// for every client or every failed write on a busy stream do not flood the
+// output. Records that are passed on carry how many times their message
+// occurred so far.
+type SamplingHandler struct {
+	next   slog.Handler
+	n      uint64
+	counts *sampleCounts
+}
+
+// sampleCounts is shared by a SamplingHandler and the handlers derived from
+// it, so a message is sampled the same whatever attributes it is logged
+// with.
+type sampleCounts struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// NewSamplingHandler wraps next with sampling of one in every n records of
+// each message. n of 1 or less passes every record on.
+func NewSamplingHandler(next slog.Handler, n int) *SamplingHandler {
+	return &SamplingHandler{
+		next:   next,
+		n:      uint64(max(n, 1)),
+		counts: &sampleCounts{counts: make(map[string]uint64)},
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle passes r on if it is one of the sampled records of its message.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.n == 1 {
+		return h.next.Handle(ctx, r)
+	}
+
+	h.counts.mu.Lock()
+	h.counts.counts[r.Message]++
+	count := h.counts.counts[r.Message]
+	h.counts.mu.Unlock()
+
+	if (count-1)%h.n != 0 {
+		return nil
+	}
+	r = r.Clone()
+	r.AddAttrs(slog.Uint64(OccurrencesKey, count))
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a SamplingHandler wrapping the wrapped handler's
+// WithAttrs, sharing the message counts.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{next: h.next.WithAttrs(attrs), n: h.n, counts: h.counts}
+}
+
+// WithGroup returns a SamplingHandler wrapping the wrapped handler's
+// WithGroup, sharing the message counts.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{next: h.next.WithGroup(name), n: h.n, counts: h.counts}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	records := func(buf *bytes.Buffer) []map[string]any {
+		t.Helper()
+		var got []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var record map[string]any
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				t.Fatalf("failed to decode log line: %v", err)
+			}
+			got = append(got, record)
+		}
+		return got
+	}
+
+	t.Run("passes on one in every n records of each message", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewSamplingHandler(slog.NewJSONHandler(&buf, nil), 3))
+
+		for i := range 7 {
+			logger.With("client", i).Warn("client dropped")
+		}
+		logger.Info("stream started")
+
+		got := records(&buf)
+		var occurrences []float64
+		for _, r := range got {
+			if r["msg"] == "client dropped" {
+				occurrences = append(occurrences, r[OccurrencesKey].(float64))
+			}
+		}
+		if len(occurrences) != 3 || occurrences[0] != 1 || occurrences[1] != 4 || occurrences[2] != 7 {
+			t.Errorf("expected occurrences 1, 4 and 7 logged, got %v", occurrences)
+		}
+		if last := got[len(got)-1]; last["msg"] != "stream started" {
+			t.Errorf("expected other messages sampled separately, got %v", last)
+		}
+	})
+
+	t.Run("passes on every record with a rate of 1", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(NewSamplingHandler(slog.NewJSONHandler(&buf, nil), 1))
+
+		for range 3 {
+			logger.InfoContext(context.Background(), "client dropped")
+		}
+
+		got := records(&buf)
+		if len(got) != 3 {
+			t.Fatalf("expected 3 records, got %d", len(got))
+		}
+		if _, ok := got[0][OccurrencesKey]; ok {
+			t.Errorf("expected no occurrences count without sampling, got %v", got[0])
+		}
+	})
+}
@@ -0,0 +1,28 @@
+// Package logo identifies cached channel logos.
+package logo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+)
+
+// ErrLogoNotFound is returned when no cached logo exists for a key.
+var ErrLogoNotFound = errors.New("logo not found")
+
+// keyPattern matches the keys produced by Key.
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Key returns the cache key for an EPG channel's logo. Keys are derived from
+// the EPG ID, so the playlist can point at a logo without knowing its source
+// URL, and are safe to use as file names.
+func Key(epgID string) string {
+	sum := sha256.Sum256([]byte(epgID))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ValidKey reports whether key has the shape produced by Key.
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
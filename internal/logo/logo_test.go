@@ -0,0 +1,21 @@
+package logo
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	a := Key("hbo.es")
+	if a != Key("hbo.es") {
+		t.Error("expected Key to be deterministic")
+	}
+	if a == Key("cnn.us") {
+		t.Error("expected different EPG IDs to produce different keys")
+	}
+	if !ValidKey(a) {
+		t.Errorf("expected %q to be a valid key", a)
+	}
+	for _, bad := range []string{"", "../etc/passwd", "ABCDEF", a + "0"} {
+		if ValidKey(bad) {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package m3u reads, transforms and writes extended M3U playlists such as
+// those published by other acestream playlist tools.
+package m3u
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+// ErrNotPlaylist is returned when the input has no #EXTINF entries at all.
+var ErrNotPlaylist = errors.New("not an M3U playlist")
+
+// Entry is a single #EXTINF entry and the stream URL that follows it.
+type Entry struct {
+	Name       string // Display name after the attribute list
+	TVGID      string
+	TVGName    string
+	TVGLogo    string
+	GroupTitle string
+	URL        string
+}
+
+// ChannelName returns the name the entry should be listed under: the display
+// name, falling back to tvg-name and then tvg-id when it is blank.
+func (e Entry) ChannelName() string {
+	switch {
+	case e.Name != "":
+		return e.Name
+	case e.TVGName != "":
+		return e.TVGName
+	default:
+		return e.TVGID
+	}
+}
+
+// InfoHash returns the acestream infohash the entry's URL points at, or an
+// empty string if the URL is not a valid acestream link. Every format
+// accepted by stream.ParseInfoHash is recognised, and the hash is returned
+// normalized.
+func (e Entry) InfoHash() string {
+	hash, err := stream.ParseInfoHash(e.URL)
+	if err != nil {
+		return ""
+	}
+	return hash.String()
+}
+
+// Parse reads the #EXTINF entries of an M3U playlist in order. Entries without
+// a URL line are dropped, and directives other than #EXTINF are ignored.
+// Returns ErrNotPlaylist if the input contains no #EXTINF line.
+func Parse(r io.Reader) ([]Entry, error) {
+	d := NewDecoder(r)
+	var entries []Entry
+	for {
+		e, err := d.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+}
+
+// Decoder reads the entries of an M3U playlist one at a time, so large
+// playlists can be processed without holding them in memory.
+type Decoder struct {
+	scanner   *bufio.Scanner
+	current   Entry
+	pending   bool
+	sawExtinf bool
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	scanner := bufio.NewScanner(r)
+	// Logo URLs make #EXTINF lines long
+	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024)
+	return &Decoder{scanner: scanner}
+}
+
+// Next returns the next entry of the playlist, following the same rules as
+// Parse. Returns io.EOF after the last entry, or ErrNotPlaylist instead if the
+// input contained no #EXTINF line.
+func (d *Decoder) Next() (Entry, error) {
+	for d.scanner.Scan() {
+		line := bytes.TrimSpace(d.scanner.Bytes())
+
+		switch {
+		case len(line) == 0:
+			continue
+		case bytes.HasPrefix(line, extinfPrefix):
+			d.sawExtinf = true
+			d.current = parseExtinf(string(line))
+			d.pending = true
+		case line[0] == '#':
+			continue
+		case d.pending:
+			d.current.URL = string(line)
+			d.pending = false
+			return d.current, nil
+		}
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return Entry{}, fmt.Errorf("reading M3U: %w", err)
+	}
+	if !d.sawExtinf {
+		return Entry{}, ErrNotPlaylist
+	}
+	return Entry{}, io.EOF
+}
+
+var extinfPrefix = []byte("#EXTINF:")
+
+// parseExtinf extracts the attributes and display name of an #EXTINF line.
+func parseExtinf(line string) Entry {
+	e := Entry{
+		TVGID:      attribute(line, "tvg-id"),
+		TVGName:    attribute(line, "tvg-name"),
+		TVGLogo:    attribute(line, "tvg-logo"),
+		GroupTitle: attribute(line, "group-title"),
+	}
+
+	// The display name follows the first comma outside a quoted attribute
+	inQuotes := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == ',' && !inQuotes:
+			e.Name = strings.TrimSpace(line[i+1:])
+			return e
+		}
+	}
+	return e
+}
+
+// attribute returns the value of a quoted attribute (e.g. tvg-id="X"), or an
+// empty string if it is missing.
+func attribute(line, name string) string {
+	marker := " " + name + `="`
+	idx := strings.Index(line, marker)
+	if idx < 0 {
+		return ""
+	}
+	start := idx + len(marker)
+	end := strings.Index(line[start:], `"`)
+	if end < 0 {
+		return ""
+	}
+	return strings.TrimSpace(line[start : start+end])
+}
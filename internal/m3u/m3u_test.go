@@ -0,0 +1,156 @@
+package m3u
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("parses attributes, display name and URL", func(t *testing.T) {
+		input := `#EXTM3U
+#EXTINF:-1 tvg-id="hbo.es" tvg-name="HBO" tvg-logo="http://logo/hbo.png" group-title="Movies, Series",HBO HD
+#EXTGRP:Movies
+acestream://aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+
+#EXTINF:-1,Plain Channel
+http://localhost:6878/ace/getstream?id=bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb
+`
+		entries, err := Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("expected 2 entries, got %d", len(entries))
+		}
+
+		want := Entry{
+			Name:       "HBO HD",
+			TVGID:      "hbo.es",
+			TVGName:    "HBO",
+			TVGLogo:    "http://logo/hbo.png",
+			GroupTitle: "Movies, Series",
+			URL:        "acestream://aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		}
+		if entries[0] != want {
+			t.Errorf("entries[0] = %+v, want %+v", entries[0], want)
+		}
+		if entries[1].Name != "Plain Channel" || entries[1].InfoHash() != "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb" {
+			t.Errorf("unexpected second entry %+v", entries[1])
+		}
+	})
+
+	t.Run("drops entries without a URL line", func(t *testing.T) {
+		input := "#EXTM3U\n#EXTINF:-1,Dangling\n#EXTINF:-1,Kept\nacestream://cccc\n"
+		entries, err := Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if len(entries) != 1 || entries[0].Name != "Kept" {
+			t.Errorf("expected only the entry with a URL, got %+v", entries)
+		}
+	})
+
+	t.Run("rejects input without EXTINF lines", func(t *testing.T) {
+		_, err := Parse(strings.NewReader("<html>not a playlist</html>"))
+		if !errors.Is(err, ErrNotPlaylist) {
+			t.Errorf("expected ErrNotPlaylist, got %v", err)
+		}
+	})
+}
+
+func TestEntry_ChannelName(t *testing.T) {
+	tests := []struct {
+		entry Entry
+		want  string
+	}{
+		{Entry{Name: "HBO", TVGName: "HBO ES", TVGID: "hbo.es"}, "HBO"},
+		{Entry{TVGName: "HBO ES", TVGID: "hbo.es"}, "HBO ES"},
+		{Entry{TVGID: "hbo.es"}, "hbo.es"},
+		{Entry{}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.entry.ChannelName(); got != tt.want {
+			t.Errorf("ChannelName() for %+v = %q, want %q", tt.entry, got, tt.want)
+		}
+	}
+}
+
+func TestEntry_InfoHash(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"acestream://94c2fd8fa9b16211252c5e9f0b836d94155b505a", "94c2fd8fa9b16211252c5e9f0b836d94155b505a"},
+		{"http://127.0.0.1:6878/ace/getstream?id=94c2fd8fa9b16211252c5e9f0b836d94155b505a", "94c2fd8fa9b16211252c5e9f0b836d94155b505a"},
+		{"http://127.0.0.1:6878/ace/manifest.m3u8?infohash=94C2FD8FA9B16211252C5E9F0B836D94155B505A", "94c2fd8fa9b16211252c5e9f0b836d94155b505a"},
+		{"magnet:?xt=urn:btih:94c2fd8fa9b16211252c5e9f0b836d94155b505a&dn=HBO", "94c2fd8fa9b16211252c5e9f0b836d94155b505a"},
+		{"acestream://abc123", ""},
+		{"http://example.com/live/stream.ts?id=94c2fd8fa9b16211252c5e9f0b836d94155b505a", ""},
+		{"http://example.com/stream.m3u8", ""},
+	}
+
+	for _, tt := range tests {
+		if got := (Entry{URL: tt.url}).InfoHash(); got != tt.want {
+			t.Errorf("InfoHash() for %q = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	t.Run("returns entries one at a time", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("#EXTM3U\n#EXTINF:-1,One\nhttp://a\n#EXTINF:-1,Two\nhttp://b\n"))
+
+		var names []string
+		for {
+			e, err := d.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+			names = append(names, e.Name)
+		}
+		if strings.Join(names, ",") != "One,Two" {
+			t.Errorf("expected One,Two, got %v", names)
+		}
+	})
+
+	t.Run("rejects input without EXTINF lines", func(t *testing.T) {
+		_, err := NewDecoder(strings.NewReader("http://a\n")).Next()
+		if !errors.Is(err, ErrNotPlaylist) {
+			t.Errorf("expected ErrNotPlaylist, got %v", err)
+		}
+	})
+}
+
+// benchmarkPlaylist returns a playlist of n entries where every tenth stream
+// repeats an earlier one.
+func benchmarkPlaylist(n int) string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for i := range n {
+		id := i
+		if i%10 == 9 {
+			id = i - 5
+		}
+		fmt.Fprintf(&b, "#EXTINF:-1 tvg-id=\"ch%d\" tvg-logo=\"http://logos/%d.png\" group-title=\"Group %d\",Channel %d\n", i, i, i%20, (n-i)%1000)
+		fmt.Fprintf(&b, "acestream://%040x\n", id)
+	}
+	return b.String()
+}
+
+func BenchmarkParse(b *testing.B) {
+	playlist := benchmarkPlaylist(50000)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(playlist)))
+	for b.Loop() {
+		if _, err := Parse(strings.NewReader(playlist)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
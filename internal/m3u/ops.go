@@ -0,0 +1,69 @@
+package m3u
+
+import (
+	"slices"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DeduplicateStreams drops entries pointing at a stream already listed
+// earlier in the playlist, keeping the first. Acestream entries are compared
+// by infohash, so the same stream behind different URL formats counts once;
+// other entries are compared by URL. The result reuses the backing array of
+// entries.
+func DeduplicateStreams(entries []Entry) []Entry {
+	seen := make(map[string]struct{}, len(entries))
+	kept := entries[:0]
+	for _, e := range entries {
+		key := e.InfoHash()
+		if key == "" {
+			key = e.URL
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		kept = append(kept, e)
+	}
+	clear(entries[len(kept):])
+	return kept
+}
+
+// SortStreamsByName sorts entries in place by channel name, ignoring case.
+// Entries with the same name keep their playlist order.
+func SortStreamsByName(entries []Entry) {
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		return compareFold(a.ChannelName(), b.ChannelName())
+	})
+}
+
+// StripLogos clears the tvg-logo of every entry.
+func StripLogos(entries []Entry) {
+	for i := range entries {
+		entries[i].TVGLogo = ""
+	}
+}
+
+// compareFold compares two strings like strings.Compare after lowercasing
+// both, without allocating.
+func compareFold(a, b string) int {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra, rb = unicode.ToLower(ra), unicode.ToLower(rb); ra != rb {
+			if ra < rb {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[na:], b[nb:]
+	}
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,109 @@
+package m3u
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	hashA = "94c2fd8fa9b16211252c5e9f0b836d94155b505a"
+	hashB = "0123456789abcdef0123456789abcdef01234567"
+)
+
+func entryNames(entries []Entry) string {
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.ChannelName()
+	}
+	return strings.Join(names, ",")
+}
+
+func TestDeduplicateStreams(t *testing.T) {
+	entries := []Entry{
+		{Name: "HBO", URL: "acestream://" + hashA},
+		{Name: "ESPN", URL: "acestream://" + hashB},
+		{Name: "HBO backup", URL: "http://127.0.0.1:6878/ace/getstream?id=" + strings.ToUpper(hashA)},
+		{Name: "Web", URL: "http://example.com/live.m3u8"},
+		{Name: "Web again", URL: "http://example.com/live.m3u8"},
+	}
+
+	got := DeduplicateStreams(entries)
+
+	if names := entryNames(got); names != "HBO,ESPN,Web" {
+		t.Errorf("DeduplicateStreams() kept %s, want HBO,ESPN,Web", names)
+	}
+}
+
+func TestSortStreamsByName(t *testing.T) {
+	entries := []Entry{
+		{Name: "cuatro"},
+		{Name: "Antena 3", URL: "first"},
+		{TVGName: "Ñ TV"},
+		{Name: "antena 3", URL: "second"},
+		{Name: "La 1"},
+	}
+
+	SortStreamsByName(entries)
+
+	if names := entryNames(entries); names != "Antena 3,antena 3,cuatro,La 1,Ñ TV" {
+		t.Errorf("SortStreamsByName() = %s", names)
+	}
+	if entries[0].URL != "first" || entries[1].URL != "second" {
+		t.Error("expected entries with the same name to keep their order")
+	}
+}
+
+func TestStripLogos(t *testing.T) {
+	entries := []Entry{{Name: "HBO", TVGLogo: "http://logo/hbo.png"}, {Name: "ESPN"}}
+
+	StripLogos(entries)
+
+	if entries[0].TVGLogo != "" || entries[0].Name != "HBO" {
+		t.Errorf("StripLogos() left %+v", entries[0])
+	}
+}
+
+func TestOperationsDoNotAllocatePerEntry(t *testing.T) {
+	entries, err := Parse(strings.NewReader(benchmarkPlaylist(50000)))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	allocs := testing.AllocsPerRun(1, func() {
+		work := slices.Clone(entries)
+		work = DeduplicateStreams(work)
+		SortStreamsByName(work)
+		StripLogos(work)
+	})
+	// Only the clone and the tables of the dedupe set allocate
+	if allocs > float64(len(entries))/100 {
+		t.Errorf("expected far fewer allocations than entries, got %.0f for %d entries", allocs, len(entries))
+	}
+}
+
+func BenchmarkDeduplicateStreams(b *testing.B) {
+	entries, err := Parse(strings.NewReader(benchmarkPlaylist(50000)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	work := make([]Entry, len(entries))
+	b.ReportAllocs()
+	for b.Loop() {
+		copy(work, entries)
+		DeduplicateStreams(work)
+	}
+}
+
+func BenchmarkSortStreamsByName(b *testing.B) {
+	entries, err := Parse(strings.NewReader(benchmarkPlaylist(50000)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	work := make([]Entry, len(entries))
+	b.ReportAllocs()
+	for b.Loop() {
+		copy(work, entries)
+		SortStreamsByName(work)
+	}
+}
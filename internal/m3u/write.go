@@ -0,0 +1,38 @@
+package m3u
+
+import (
+	"bufio"
+	"io"
+)
+
+// Write writes entries as an extended M3U playlist that Parse reads back
+// unchanged. Empty attributes are omitted.
+func Write(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("#EXTM3U\n")
+	for _, e := range entries {
+		_, _ = bw.WriteString("#EXTINF:-1")
+		writeAttribute(bw, "tvg-id", e.TVGID)
+		writeAttribute(bw, "tvg-name", e.TVGName)
+		writeAttribute(bw, "tvg-logo", e.TVGLogo)
+		writeAttribute(bw, "group-title", e.GroupTitle)
+		_, _ = bw.WriteString(",")
+		_, _ = bw.WriteString(e.Name)
+		_, _ = bw.WriteString("\n")
+		_, _ = bw.WriteString(e.URL)
+		_, _ = bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// writeAttribute writes a quoted #EXTINF attribute unless value is empty.
+func writeAttribute(bw *bufio.Writer, name, value string) {
+	if value == "" {
+		return
+	}
+	_, _ = bw.WriteString(" ")
+	_, _ = bw.WriteString(name)
+	_, _ = bw.WriteString(`="`)
+	_, _ = bw.WriteString(value)
+	_, _ = bw.WriteString(`"`)
+}
@@ -0,0 +1,35 @@
+package m3u
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	entries := []Entry{
+		{Name: "HBO HD", TVGID: "hbo.es", TVGName: "HBO", TVGLogo: "http://logo/hbo.png", GroupTitle: "Movies, Series", URL: "acestream://" + hashA},
+		{Name: "Plain", URL: "http://example.com/live.m3u8"},
+	}
+
+	var b strings.Builder
+	if err := Write(&b, entries); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	want := "#EXTM3U\n" +
+		`#EXTINF:-1 tvg-id="hbo.es" tvg-name="HBO" tvg-logo="http://logo/hbo.png" group-title="Movies, Series",HBO HD` + "\n" +
+		"acestream://" + hashA + "\n" +
+		"#EXTINF:-1,Plain\n" +
+		"http://example.com/live.m3u8\n"
+	if b.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	parsed, err := Parse(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(parsed) != len(entries) || parsed[0] != entries[0] || parsed[1] != entries[1] {
+		t.Errorf("expected the written playlist to parse back unchanged, got %+v", parsed)
+	}
+}
@@ -0,0 +1,239 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// atomicFloat is a float64 updated with compare-and-swap.
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) add(delta float64) {
+	for {
+		old := f.bits.Load()
+		updated := math.Float64bits(math.Float64frombits(old) + delta)
+		if f.bits.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) set(v float64) { f.bits.Store(math.Float64bits(v)) }
+func (f *atomicFloat) load() float64 { return math.Float64frombits(f.bits.Load()) }
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	value atomicFloat
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() { c.value.add(1) }
+
+// Add increments the counter by delta. Negative deltas are ignored.
+func (c *Counter) Add(delta float64) {
+	if delta > 0 {
+		c.value.add(delta)
+	}
+}
+
+// Value returns the current counter value.
+func (c *Counter) Value() float64 { return c.value.load() }
+
+// CounterVec is a counter family partitioned by labels.
+type CounterVec struct {
+	*family[Counter]
+}
+
+// NewCounter registers a counter family with the given label names.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *CounterVec {
+	v := &CounterVec{newFamily(name, help, "counter", labelNames, func() *Counter { return &Counter{} })}
+	r.register(v)
+	return v
+}
+
+// With returns the counter for the given label values.
+func (v *CounterVec) With(labelValues ...string) *Counter { return v.with(labelValues) }
+
+func (v *CounterVec) write(w io.Writer) error {
+	if err := v.writeHeader(w); err != nil {
+		return err
+	}
+	return v.each(func(labels string, c *Counter) error {
+		_, err := fmt.Fprintf(w, "%s%s %s\n", v.metricName, labels, formatValue(c.Value()))
+		return err
+	})
+}
+
+// Gauge is a value that can go up and down.
+type Gauge struct {
+	value atomicFloat
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64) { g.value.set(v) }
+
+// Add adds delta to the gauge.
+func (g *Gauge) Add(delta float64) { g.value.add(delta) }
+
+// Value returns the current gauge value.
+func (g *Gauge) Value() float64 { return g.value.load() }
+
+// GaugeVec is a gauge family partitioned by labels.
+type GaugeVec struct {
+	*family[Gauge]
+}
+
+// NewGauge registers a gauge family with the given label names.
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *GaugeVec {
+	v := &GaugeVec{newFamily(name, help, "gauge", labelNames, func() *Gauge { return &Gauge{} })}
+	r.register(v)
+	return v
+}
+
+// With returns the gauge for the given label values.
+func (v *GaugeVec) With(labelValues ...string) *Gauge { return v.with(labelValues) }
+
+func (v *GaugeVec) write(w io.Writer) error {
+	if err := v.writeHeader(w); err != nil {
+		return err
+	}
+	return v.each(func(labels string, g *Gauge) error {
+		_, err := fmt.Fprintf(w, "%s%s %s\n", v.metricName, labels, formatValue(g.Value()))
+		return err
+	})
+}
+
+// funcMetric reports a value read from a callback at scrape time.
+type funcMetric struct {
+	metricName string
+	help       string
+	kind       string
+	fn         func() float64
+}
+
+func (m *funcMetric) name() string { return m.metricName }
+
+func (m *funcMetric) write(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n",
+		m.metricName, escapeHelp(m.help), m.metricName, m.kind, m.metricName, formatValue(m.fn()))
+	return err
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn at scrape time.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{metricName: name, help: help, kind: "gauge", fn: fn})
+}
+
+// NewCounterFunc registers a counter whose value is read from fn at scrape
+// time. fn must be monotonically increasing.
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&funcMetric{metricName: name, help: help, kind: "counter", fn: fn})
+}
+
+// Histogram samples observations into cumulative buckets.
+type Histogram struct {
+	upperBounds []float64
+
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe records a single observation.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, ub := range h.upperBounds {
+		if v <= ub {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// ObserveDuration records the time elapsed since start, in seconds.
+func (h *Histogram) ObserveDuration(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// HistogramVec is a histogram family partitioned by labels.
+type HistogramVec struct {
+	*family[Histogram]
+}
+
+// NewHistogram registers a histogram family with the given bucket upper
+// bounds and label names. Nil buckets use DefaultBuckets.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	bounds := slices.Clone(buckets)
+	slices.Sort(bounds)
+
+	v := &HistogramVec{newFamily(name, help, "histogram", labelNames, func() *Histogram {
+		return &Histogram{upperBounds: bounds, counts: make([]uint64, len(bounds))}
+	})}
+	r.register(v)
+	return v
+}
+
+// With returns the histogram for the given label values.
+func (v *HistogramVec) With(labelValues ...string) *Histogram { return v.with(labelValues) }
+
+func (v *HistogramVec) write(w io.Writer) error {
+	if err := v.writeHeader(w); err != nil {
+		return err
+	}
+
+	v.family.mu.Lock()
+	keys := make([]string, 0, len(v.children))
+	for k := range v.children {
+		keys = append(keys, k)
+	}
+	v.family.mu.Unlock()
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		v.family.mu.Lock()
+		h, values := v.children[k], v.keys[k]
+		v.family.mu.Unlock()
+
+		h.mu.Lock()
+		counts := slices.Clone(h.counts)
+		count, sum := h.count, h.sum
+		h.mu.Unlock()
+
+		for i, ub := range h.upperBounds {
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", v.metricName, formatLabels(v.labelNames, values, "le", formatValue(ub)), counts[i]); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", v.metricName, formatLabels(v.labelNames, values, "le", "+Inf"), count); err != nil {
+			return err
+		}
+		labels := formatLabels(v.labelNames, values, "", "")
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", v.metricName, labels, formatValue(sum), v.metricName, labels, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InstrumentHandler records the latency of every request served by next.
+func InstrumentHandler(h *Histogram, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		h.ObserveDuration(start)
+	})
+}
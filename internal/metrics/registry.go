@@ -0,0 +1,192 @@
+// Package metrics provides a minimal metrics registry that renders its
+// contents in the Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram upper bounds in seconds suited to request and
+// storage latencies.
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector renders one metric family.
+type collector interface {
+	name() string
+	write(w io.Writer) error
+}
+
+// Registry holds metric families and serves them over HTTP.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.collectors {
+		if existing.name() == c.name() {
+			panic(fmt.Sprintf("metrics: duplicate registration of %q", c.name()))
+		}
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText writes all registered metrics in the Prometheus text format,
+// ordered by metric name.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := slices.Clone(r.collectors)
+	r.mu.Unlock()
+
+	slices.SortFunc(collectors, func(a, b collector) int {
+		return strings.Compare(a.name(), b.name())
+	})
+
+	for _, c := range collectors {
+		if err := c.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP handles GET /metrics.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WriteText(w)
+}
+
+// family holds the metadata and labelled children of a metric family.
+type family[T any] struct {
+	metricName string
+	help       string
+	kind       string
+	labelNames []string
+	newChild   func() *T
+
+	mu       sync.Mutex
+	children map[string]*T
+	keys     map[string][]string
+}
+
+func newFamily[T any](name, help, kind string, labelNames []string, newChild func() *T) *family[T] {
+	return &family[T]{
+		metricName: name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		newChild:   newChild,
+		children:   make(map[string]*T),
+		keys:       make(map[string][]string),
+	}
+}
+
+func (f *family[T]) name() string { return f.metricName }
+
+// with returns the child for the given label values, creating it if needed.
+func (f *family[T]) with(values []string) *T {
+	if len(values) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %q expects %d label values, got %d", f.metricName, len(f.labelNames), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	child, ok := f.children[key]
+	if !ok {
+		child = f.newChild()
+		f.children[key] = child
+		f.keys[key] = slices.Clone(values)
+	}
+	return child
+}
+
+// each calls fn for every child in a stable order.
+func (f *family[T]) each(fn func(labels string, child *T) error) error {
+	f.mu.Lock()
+	keys := make([]string, 0, len(f.children))
+	for k := range f.children {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	type entry struct {
+		labels string
+		child  *T
+	}
+	entries := make([]entry, len(keys))
+	for i, k := range keys {
+		entries[i] = entry{labels: formatLabels(f.labelNames, f.keys[k], "", ""), child: f.children[k]}
+	}
+	f.mu.Unlock()
+
+	for _, e := range entries {
+		if err := fn(e.labels, e.child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *family[T]) writeHeader(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.metricName, escapeHelp(f.help), f.metricName, f.kind)
+	return err
+}
+
+// formatLabels renders {a="x",b="y"}, optionally appending one extra label.
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", n, escapeLabel(values[i]))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s=%q", extraName, extraValue)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	t.Run("renders counters, gauges and func metrics sorted by name", func(t *testing.T) {
+		reg := NewRegistry()
+		requests := reg.NewCounter("test_requests_total", "Total requests.", "code")
+		requests.With("200").Add(3)
+		requests.With("500").Inc()
+		reg.NewGauge("test_temperature", "Current temperature.").With().Set(21.5)
+		reg.NewGaugeFunc("test_active", "Active things.", func() float64 { return 7 })
+
+		var sb strings.Builder
+		if err := reg.WriteText(&sb); err != nil {
+			t.Fatalf("WriteText() error = %v", err)
+		}
+
+		want := `# HELP test_active Active things.
+# TYPE test_active gauge
+test_active 7
+# HELP test_requests_total Total requests.
+# TYPE test_requests_total counter
+test_requests_total{code="200"} 3
+test_requests_total{code="500"} 1
+# HELP test_temperature Current temperature.
+# TYPE test_temperature gauge
+test_temperature 21.5
+`
+		if got := sb.String(); got != want {
+			t.Errorf("WriteText() =\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("renders cumulative histogram buckets", func(t *testing.T) {
+		reg := NewRegistry()
+		h := reg.NewHistogram("test_duration_seconds", "Durations.", []float64{0.1, 1}, "op")
+		h.With("read").Observe(0.05)
+		h.With("read").Observe(0.5)
+		h.With("read").Observe(2)
+
+		var sb strings.Builder
+		if err := reg.WriteText(&sb); err != nil {
+			t.Fatalf("WriteText() error = %v", err)
+		}
+
+		for _, line := range []string{
+			`test_duration_seconds_bucket{op="read",le="0.1"} 1`,
+			`test_duration_seconds_bucket{op="read",le="1"} 2`,
+			`test_duration_seconds_bucket{op="read",le="+Inf"} 3`,
+			`test_duration_seconds_sum{op="read"} 2.55`,
+			`test_duration_seconds_count{op="read"} 3`,
+		} {
+			if !strings.Contains(sb.String(), line+"\n") {
+				t.Errorf("output missing %q:\n%s", line, sb.String())
+			}
+		}
+	})
+
+	t.Run("counter ignores negative deltas", func(t *testing.T) {
+		c := NewRegistry().NewCounter("test_total", "Test.").With()
+		c.Add(2)
+		c.Add(-1)
+		if c.Value() != 2 {
+			t.Errorf("Value() = %v, want 2", c.Value())
+		}
+	})
+
+	t.Run("panics on duplicate registration", func(t *testing.T) {
+		reg := NewRegistry()
+		reg.NewCounter("test_total", "Test.")
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic on duplicate metric name")
+			}
+		}()
+		reg.NewGauge("test_total", "Test.")
+	})
+
+	t.Run("panics on label count mismatch", func(t *testing.T) {
+		vec := NewRegistry().NewCounter("test_total", "Test.", "a", "b")
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic on label count mismatch")
+			}
+		}()
+		vec.With("only-one")
+	})
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("test_total", "Test.").With().Inc()
+
+	t.Run("GET returns text exposition", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		reg.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+			t.Errorf("unexpected Content-Type %q", ct)
+		}
+		if !strings.Contains(rec.Body.String(), "test_total 1\n") {
+			t.Errorf("body missing counter sample:\n%s", rec.Body.String())
+		}
+	})
+
+	t.Run("POST is rejected", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		reg.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected status 405, got %d", rec.Code)
+		}
+	})
+}
+
+func TestInstrumentHandler(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.NewHistogram("test_latency_seconds", "Latency.", nil).With()
+
+	handler := InstrumentHandler(h, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected wrapped handler status 204, got %d", rec.Code)
+	}
+	var sb strings.Builder
+	_ = reg.WriteText(&sb)
+	if !strings.Contains(sb.String(), "test_latency_seconds_count 1\n") {
+		t.Errorf("expected one observation:\n%s", sb.String())
+	}
+}
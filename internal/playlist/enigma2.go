@@ -0,0 +1,42 @@
+package playlist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// BouquetName is the name Enigma2 receivers show for the exported bouquet.
+const BouquetName = "IPTV Manager"
+
+// enigma2Format writes an Enigma2 userbouquet (userbouquet.*.tv) listing
+// every stream as an IPTV service, with a marker line before each group.
+type enigma2Format struct{}
+
+func (enigma2Format) Name() string        { return "enigma2" }
+func (enigma2Format) ContentType() string { return "text/plain; charset=utf-8" }
+
+func (enigma2Format) Encode(w io.Writer, p Playlist) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#NAME %s\n", BouquetName)
+
+	group := ""
+	markers := 0
+	for _, e := range p.Entries {
+		if e.Group != "" && e.Group != group {
+			markers++
+			fmt.Fprintf(bw, "#SERVICE 1:64:%d:0:0:0:0:0:0:0::%s\n", markers, e.Group)
+			fmt.Fprintf(bw, "#DESCRIPTION %s\n", e.Group)
+		}
+		group = e.Group
+
+		name := fmt.Sprintf("%s - %s", e.DisplayName(), e.InfoHash)
+		// Service type 4097 plays the URL with the receiver's media player.
+		// Colons separate service reference fields, so they are escaped.
+		fmt.Fprintf(bw, "#SERVICE 4097:0:1:0:0:0:0:0:0:0:%s:%s\n", strings.ReplaceAll(e.URL, ":", "%3a"), name)
+		fmt.Fprintf(bw, "#DESCRIPTION %s\n", name)
+	}
+
+	return bw.Flush()
+}
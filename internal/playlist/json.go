@@ -0,0 +1,57 @@
+package playlist
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonFormat writes the playlist as a channel list for custom apps, with
+// the streams of each channel nested under it.
+type jsonFormat struct{}
+
+type jsonPlaylist struct {
+	GuideURL string        `json:"guide_url"`
+	Channels []jsonChannel `json:"channels"`
+}
+
+type jsonChannel struct {
+	Number       int          `json:"number"`
+	Name         string       `json:"name"`
+	TVGID        string       `json:"tvg_id"`
+	LogoURL      string       `json:"logo_url,omitempty"`
+	Group        string       `json:"group,omitempty"`
+	Availability string       `json:"availability,omitempty"`
+	Streams      []jsonStream `json:"streams"`
+}
+
+type jsonStream struct {
+	InfoHash string `json:"info_hash"`
+	URL      string `json:"url"`
+	Quality  string `json:"quality,omitempty"`
+}
+
+func (jsonFormat) Name() string        { return "json" }
+func (jsonFormat) ContentType() string { return "application/json" }
+
+func (jsonFormat) Encode(w io.Writer, p Playlist) error {
+	doc := jsonPlaylist{GuideURL: p.GuideURL, Channels: []jsonChannel{}}
+	for _, e := range p.Entries {
+		last := len(doc.Channels) - 1
+		if last < 0 || doc.Channels[last].Name != e.ChannelName {
+			doc.Channels = append(doc.Channels, jsonChannel{
+				Number:       e.Number,
+				Name:         e.ChannelName,
+				TVGID:        e.TVGID,
+				LogoURL:      e.LogoURL,
+				Group:        e.Group,
+				Availability: e.Availability,
+			})
+			last++
+		}
+		doc.Channels[last].Streams = append(doc.Channels[last].Streams, jsonStream{InfoHash: e.InfoHash, URL: e.URL, Quality: e.Quality})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,83 @@
+package playlist
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// m3uFormat writes M3U playlists. The plain variant carries the tvg-id,
+// tvg-logo and group-title attributes most players rely on, plus tvg-chno
+// for channels with an assigned number, tvg-shift for channels with a guide
+// time shift and tvg-status for entries with an availability; the extended variant numbers every channel and adds
+// tvg-name, #EXTGRP lines and catchup tags, with a catchup-source for the
+// entries that have one.
+type m3uFormat struct {
+	extended bool
+}
+
+func (f m3uFormat) Name() string {
+	if f.extended {
+		return "m3u8"
+	}
+	return "m3u"
+}
+
+func (f m3uFormat) ContentType() string {
+	if f.extended {
+		return "application/x-mpegurl"
+	}
+	return "audio/mpegurl"
+}
+
+func (f m3uFormat) Encode(w io.Writer, p Playlist) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "#EXTM3U url-tvg=\"%s\"", p.GuideURL)
+	if f.extended {
+		fmt.Fprintf(bw, " x-tvg-url=\"%s\"", p.GuideURL)
+	}
+	bw.WriteString("\n")
+
+	for _, e := range p.Entries {
+		fmt.Fprintf(bw, "#EXTINF:-1 tvg-id=\"%s\"", e.TVGID)
+		if f.extended {
+			fmt.Fprintf(bw, " tvg-chno=\"%d\" tvg-name=\"%s\"", e.Number, attr(e.ChannelName))
+		} else if e.NumberAssigned {
+			fmt.Fprintf(bw, " tvg-chno=\"%d\"", e.Number)
+		}
+		if e.TVGShift != 0 {
+			fmt.Fprintf(bw, " tvg-shift=\"%s\"", strconv.FormatFloat(e.TVGShift, 'f', -1, 64))
+		}
+		if e.LogoURL != "" {
+			fmt.Fprintf(bw, " tvg-logo=\"%s\"", e.LogoURL)
+		}
+		if e.Group != "" {
+			fmt.Fprintf(bw, " group-title=\"%s\"", attr(e.Group))
+		}
+		if e.Availability != "" {
+			fmt.Fprintf(bw, " tvg-status=\"%s\"", e.Availability)
+		}
+		switch {
+		case f.extended && e.CatchupSource != "":
+			fmt.Fprintf(bw, " catchup=\"default\" catchup-days=\"%d\" catchup-source=\"%s\"", max(e.CatchupDays, 1), e.CatchupSource)
+		case f.extended && p.CatchupDays > 0:
+			fmt.Fprintf(bw, " catchup=\"default\" catchup-days=\"%d\"", p.CatchupDays)
+		}
+		fmt.Fprintf(bw, ",%s - %s\n", e.DisplayName(), e.InfoHash)
+		if f.extended && e.Group != "" {
+			fmt.Fprintf(bw, "#EXTGRP:%s\n", e.Group)
+		}
+		fmt.Fprintf(bw, "%s\n", e.URL)
+	}
+
+	return bw.Flush()
+}
+
+// attr makes s safe to use as a quoted attribute value. Players do not
+// understand escaped quotes, so double quotes become single quotes.
+func attr(s string) string {
+	return strings.ReplaceAll(s, `"`, "'")
+}
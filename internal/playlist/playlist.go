@@ -0,0 +1,98 @@
+// Package playlist renders the channel list served to players in the output
+// formats they understand: M3U, extended M3U, JSON and Enigma2 bouquets.
+package playlist
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrUnknownFormat is returned when a format name is not recognized.
+var ErrUnknownFormat = errors.New("unknown playlist format")
+
+// Entry is a single stream of a channel. A channel with several streams has
+// one entry per stream, best stream first.
+type Entry struct {
+	// Number is the channel number, shared by all entries of a channel.
+	Number int
+	// NumberAssigned reports whether Number was assigned to the channel
+	// rather than derived from its position in the playlist.
+	NumberAssigned bool
+	ChannelName    string
+	TVGID          string
+	// TVGShift is how many hours players shift the channel's guide by, for
+	// guides published in another time zone. Zero leaves it out.
+	TVGShift float64
+	LogoURL  string
+	Group    string
+	InfoHash string
+	URL      string
+	// Quality labels the stream among the variants of its channel, e.g.
+	// "1080p". Empty for unlabelled streams.
+	Quality string
+	// Availability is the status of the channel as last probed, e.g.
+	// "unavailable". Empty leaves it out.
+	Availability string
+	// CatchupSource is the URL template players request past programmes of
+	// the channel from, with {utc} and {utcend} standing for their start and
+	// end as Unix times. Empty falls back to the playlist's CatchupDays.
+	CatchupSource string
+	// CatchupDays is how far back CatchupSource reaches.
+	CatchupDays int
+	// Source is where the stream was discovered; it is not rendered.
+	Source string
+}
+
+// DisplayName is the name players show for the entry: the channel name,
+// suffixed with the quality label if the entry has one.
+func (e Entry) DisplayName() string {
+	if e.Quality == "" {
+		return e.ChannelName
+	}
+	return e.ChannelName + " [" + e.Quality + "]"
+}
+
+// Playlist is the rendered channel list. Entries of the same channel are
+// adjacent.
+type Playlist struct {
+	// GuideURL points players at the XMLTV guide matching the tvg-id values.
+	GuideURL string
+	// CatchupDays is advertised in extended M3U playlists for entries
+	// without a CatchupSource. Zero omits their catchup tags.
+	CatchupDays int
+	Entries     []Entry
+}
+
+// Format encodes a Playlist in one output format.
+type Format interface {
+	// Name identifies the format in ?format= query parameters.
+	Name() string
+	// ContentType is the media type of the encoded playlist.
+	ContentType() string
+	// Encode writes p to w.
+	Encode(w io.Writer, p Playlist) error
+}
+
+// Supported formats.
+var (
+	M3U         Format = m3uFormat{}
+	ExtendedM3U Format = m3uFormat{extended: true}
+	JSON        Format = jsonFormat{}
+	Enigma2     Format = enigma2Format{}
+)
+
+// Formats returns every supported format, the default M3U first.
+func Formats() []Format {
+	return []Format{M3U, ExtendedM3U, JSON, Enigma2}
+}
+
+// ByName returns the format with the given name.
+// Returns ErrUnknownFormat if there is none.
+func ByName(name string) (Format, error) {
+	for _, f := range Formats() {
+		if f.Name() == name {
+			return f, nil
+		}
+	}
+	return nil, ErrUnknownFormat
+}
@@ -0,0 +1,171 @@
+package playlist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testPlaylist() Playlist {
+	return Playlist{
+		GuideURL: "http://host/epg.xml",
+		Entries: []Entry{
+			{Number: 1, ChannelName: "News 24", TVGID: "news.es", LogoURL: "http://host/logos/news.es", Group: "News", InfoHash: "aaa", URL: "http://host/ace/getstream?id=aaa", Quality: "1080p"},
+			{Number: 1, ChannelName: "News 24", TVGID: "news.es", LogoURL: "http://host/logos/news.es", Group: "News", InfoHash: "bbb", URL: "http://host/ace/getstream?id=bbb"},
+			{Number: 2, ChannelName: `Sport "HD"`, TVGID: "sport.hd", InfoHash: "ccc", URL: "http://host/ace/getstream?id=ccc", Availability: "unavailable"},
+		},
+	}
+}
+
+func encode(t *testing.T, f Format, p Playlist) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := f.Encode(&buf, p); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestM3U(t *testing.T) {
+	got := encode(t, M3U, testPlaylist())
+	want := `#EXTM3U url-tvg="http://host/epg.xml"
+#EXTINF:-1 tvg-id="news.es" tvg-logo="http://host/logos/news.es" group-title="News",News 24 [1080p] - aaa
+http://host/ace/getstream?id=aaa
+#EXTINF:-1 tvg-id="news.es" tvg-logo="http://host/logos/news.es" group-title="News",News 24 - bbb
+http://host/ace/getstream?id=bbb
+#EXTINF:-1 tvg-id="sport.hd" tvg-status="unavailable",Sport "HD" - ccc
+http://host/ace/getstream?id=ccc
+`
+	if got != want {
+		t.Errorf("M3U output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExtendedM3U(t *testing.T) {
+	t.Run("adds channel numbers, names and group lines", func(t *testing.T) {
+		got := encode(t, ExtendedM3U, testPlaylist())
+
+		for _, want := range []string{
+			`#EXTM3U url-tvg="http://host/epg.xml" x-tvg-url="http://host/epg.xml"`,
+			`#EXTINF:-1 tvg-id="news.es" tvg-chno="1" tvg-name="News 24" tvg-logo="http://host/logos/news.es" group-title="News",News 24 [1080p] - aaa` + "\n#EXTGRP:News\n",
+			`tvg-chno="2" tvg-name="Sport 'HD'" tvg-status="unavailable",`,
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expected output to contain %q, got:\n%s", want, got)
+			}
+		}
+		if strings.Contains(got, "catchup") {
+			t.Errorf("expected no catchup tags without catchup days, got:\n%s", got)
+		}
+	})
+
+	t.Run("adds the guide time shift of shifted channels", func(t *testing.T) {
+		p := testPlaylist()
+		p.Entries[2].TVGShift = -1.5
+
+		got := encode(t, ExtendedM3U, p)
+
+		if !strings.Contains(got, `tvg-name="Sport 'HD'" tvg-shift="-1.5" tvg-status="unavailable",`) {
+			t.Errorf("expected a tvg-shift on the shifted entry, got:\n%s", got)
+		}
+		if strings.Count(got, "tvg-shift") != 1 {
+			t.Errorf("expected no tvg-shift on unshifted entries, got:\n%s", got)
+		}
+	})
+
+	t.Run("adds catchup tags when a catchup window is set", func(t *testing.T) {
+		p := testPlaylist()
+		p.CatchupDays = 3
+
+		got := encode(t, ExtendedM3U, p)
+
+		if strings.Count(got, `catchup="default" catchup-days="3"`) != 3 {
+			t.Errorf("expected catchup tags on every entry, got:\n%s", got)
+		}
+	})
+
+	t.Run("points entries with a catchup source at it", func(t *testing.T) {
+		p := testPlaylist()
+		p.CatchupDays = 3
+		p.Entries[2].CatchupSource = "http://host/ace/catchup/Sport?utc={utc}&utcend={utcend}"
+		p.Entries[2].CatchupDays = 7
+
+		got := encode(t, ExtendedM3U, p)
+
+		want := `tvg-status="unavailable" catchup="default" catchup-days="7" catchup-source="http://host/ace/catchup/Sport?utc={utc}&utcend={utcend}",`
+		if !strings.Contains(got, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, got)
+		}
+		if strings.Count(got, `catchup-days="3"`) != 2 {
+			t.Errorf("expected the other entries to keep the playlist's window, got:\n%s", got)
+		}
+		if got := encode(t, M3U, p); strings.Contains(got, "catchup") {
+			t.Errorf("expected no catchup tags in plain M3U, got:\n%s", got)
+		}
+	})
+}
+
+func TestJSON(t *testing.T) {
+	var doc jsonPlaylist
+	if err := json.Unmarshal([]byte(encode(t, JSON, testPlaylist())), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if doc.GuideURL != "http://host/epg.xml" {
+		t.Errorf("GuideURL = %q", doc.GuideURL)
+	}
+	if len(doc.Channels) != 2 {
+		t.Fatalf("expected 2 channels, got %d", len(doc.Channels))
+	}
+	news := doc.Channels[0]
+	if news.Number != 1 || news.Name != "News 24" || news.Group != "News" || len(news.Streams) != 2 {
+		t.Errorf("unexpected first channel %+v", news)
+	}
+	if news.Streams[1].InfoHash != "bbb" || news.Streams[1].URL != "http://host/ace/getstream?id=bbb" {
+		t.Errorf("unexpected second stream %+v", news.Streams[1])
+	}
+	if news.Streams[0].Quality != "1080p" || news.Streams[1].Quality != "" {
+		t.Errorf("expected only the first stream labelled, got %+v", news.Streams)
+	}
+	if news.Availability != "" || doc.Channels[1].Availability != "unavailable" {
+		t.Errorf("expected only the second channel's availability, got %q and %q", news.Availability, doc.Channels[1].Availability)
+	}
+
+	t.Run("encodes an empty playlist as an empty list", func(t *testing.T) {
+		got := encode(t, JSON, Playlist{GuideURL: "http://host/epg.xml"})
+		if !strings.Contains(got, `"channels": []`) {
+			t.Errorf("expected empty channel list, got %s", got)
+		}
+	})
+}
+
+func TestEnigma2(t *testing.T) {
+	got := encode(t, Enigma2, testPlaylist())
+	want := `#NAME IPTV Manager
+#SERVICE 1:64:1:0:0:0:0:0:0:0::News
+#DESCRIPTION News
+#SERVICE 4097:0:1:0:0:0:0:0:0:0:http%3a//host/ace/getstream?id=aaa:News 24 [1080p] - aaa
+#DESCRIPTION News 24 [1080p] - aaa
+#SERVICE 4097:0:1:0:0:0:0:0:0:0:http%3a//host/ace/getstream?id=bbb:News 24 - bbb
+#DESCRIPTION News 24 - bbb
+#SERVICE 4097:0:1:0:0:0:0:0:0:0:http%3a//host/ace/getstream?id=ccc:Sport "HD" - ccc
+#DESCRIPTION Sport "HD" - ccc
+`
+	if got != want {
+		t.Errorf("Enigma2 output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestByName(t *testing.T) {
+	for _, f := range Formats() {
+		got, err := ByName(f.Name())
+		if err != nil || got != f {
+			t.Errorf("ByName(%q) = %v, %v", f.Name(), got, err)
+		}
+	}
+	if _, err := ByName("pls"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/access"
+)
+
+// AccessRuleRepository persists the rules deciding which clients may fetch
+// playlists and streams.
+type AccessRuleRepository interface {
+	// Save persists a new access rule.
+	Save(ctx context.Context, r access.Rule) error
+
+	// FindAll retrieves all access rules, oldest first (see access.Sort).
+	FindAll(ctx context.Context) ([]access.Rule, error)
+
+	// Delete removes an access rule by its ID. Returns access.ErrRuleNotFound
+	// if the rule does not exist.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,38 @@
+package driven
+
+import "context"
+
+// EngineCommand names a maintenance command of the AceStream Engine web UI
+// API that operators may run through the manager.
+type EngineCommand string
+
+const (
+	EngineCommandVersion       EngineCommand = "get_version"                   // Engine version and platform
+	EngineCommandNetworkStatus EngineCommand = "get_network_connection_status" // Whether the engine is connected to the P2P network
+	EngineCommandClearCache    EngineCommand = "clear_cache"                   // Deletes the engine's cached content
+)
+
+// EngineCommands lists every command operators may run, in display order.
+var EngineCommands = []EngineCommand{EngineCommandVersion, EngineCommandNetworkStatus, EngineCommandClearCache}
+
+// Mutates reports whether the command changes the state of the engine
+// rather than only reading it.
+func (c EngineCommand) Mutates() bool {
+	return c == EngineCommandClearCache
+}
+
+// AceStreamCommander runs maintenance commands on AceStream Engines.
+type AceStreamCommander interface {
+	// RunCommand runs the command on every engine and returns the result
+	// of each.
+	RunCommand(ctx context.Context, command EngineCommand) ([]EngineCommandResult, error)
+}
+
+// EngineCommandResult is the outcome of a command on one engine. Result is
+// the decoded "result" of the engine's JSON response; Error is set instead
+// when the engine reported an error or could not be reached.
+type EngineCommandResult struct {
+	Engine string
+	Result any
+	Error  string
+}
@@ -2,16 +2,25 @@ package driven
 
 import (
 	"context"
+	"errors"
 	"io"
 	"time"
+
+	"github.com/alorle/iptv-manager/internal/channel"
 )
 
+// ErrEngineBusy indicates the engine refused to start a stream because it
+// has too many active sessions. Starting it may succeed once one ends.
+var ErrEngineBusy = errors.New("engine has too many active sessions")
+
 // AceStreamEngine defines the interface for interacting with the AceStream Engine HTTP API.
 // This is a driven port that will be implemented by concrete adapters (e.g., HTTP client).
 type AceStreamEngine interface {
-	// StartStream initiates a stream for the given infohash with a unique PID.
-	// Returns the stream URL endpoint and any error encountered.
-	StartStream(ctx context.Context, infoHash, pid string) (streamURL string, err error)
+	// StartStream initiates a stream for the given infohash with a unique PID,
+	// applying any engine-side processing requested in opts.
+	// Returns the stream URL endpoint and any error encountered, which is
+	// ErrEngineBusy if the engine has too many active sessions.
+	StartStream(ctx context.Context, infoHash, pid string, opts StreamOptions) (streamURL string, err error)
 
 	// GetStats retrieves statistics for an active stream identified by its PID.
 	// Returns stream statistics and any error encountered.
@@ -31,6 +40,13 @@ type AceStreamEngine interface {
 	Ping(ctx context.Context) error
 }
 
+// StreamOptions holds engine-side processing applied to a started stream.
+// The zero value streams the content unchanged.
+type StreamOptions struct {
+	// TranscodeAudio selects which audio tracks the engine re-encodes to AAC.
+	TranscodeAudio channel.AudioTranscode
+}
+
 // StreamStats contains statistics about an active AceStream.
 type StreamStats struct {
 	PID        string
@@ -0,0 +1,18 @@
+package driven
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// AceStreamRangeStreamer is implemented by engines that can pick a content
+// stream up from a byte offset, so that a dropped connection is resumed
+// instead of restarting the stream from the live edge.
+type AceStreamRangeStreamer interface {
+	// StreamContentFrom behaves like StreamContent, asking the upstream for
+	// the content from offset on with a Range request.
+	// Returns streaming.ErrRangeNotSupported, having written nothing, if the
+	// upstream does not answer with the requested range.
+	StreamContentFrom(ctx context.Context, streamURL string, offset int64, dst io.Writer, infoHash, pid string, writeTimeout time.Duration) error
+}
@@ -0,0 +1,22 @@
+package driven
+
+import (
+	"context"
+)
+
+// AceStreamSearcher queries the AceStream Engine's content search to discover
+// streams that are not yet known to the manager.
+type AceStreamSearcher interface {
+	// Search returns the streams whose name matches query.
+	// Returns an empty slice if nothing matches.
+	Search(ctx context.Context, query string) ([]SearchResult, error)
+}
+
+// SearchResult is a stream found by an AceStream Engine search.
+type SearchResult struct {
+	InfoHash     string
+	Name         string
+	Categories   []string
+	Bitrate      int     // bits per second, 0 if unknown
+	Availability float64 // fraction of recent checks the stream was live, 0 to 1
+}
@@ -0,0 +1,16 @@
+package driven
+
+// AceStreamSessionRestorer is implemented by engines whose streams can be
+// reached again by another process, such as the manager after a restart, so
+// the streams it left running can be checked and stopped.
+type AceStreamSessionRestorer interface {
+	// SessionHandle returns an opaque handle to the stream started with
+	// pid, or false if the engine does not know pid.
+	SessionHandle(pid string) (string, bool)
+
+	// RestoreSession makes GetStats and StopStream reach the stream of
+	// handle under pid again.
+	// Returns an error if the handle is malformed or names an engine that
+	// is no longer configured.
+	RestoreSession(pid, handle string) error
+}
@@ -11,3 +11,14 @@ type AcestreamSource interface {
 	// Multiple hashes per channel are supported for redundancy.
 	FetchHashes(ctx context.Context, source string) (map[string][]string, error)
 }
+
+// AcestreamNamedSource is an AcestreamSource that can also report the
+// display names of the channels it lists.
+type AcestreamNamedSource interface {
+	AcestreamSource
+
+	// FetchNamedHashes retrieves Acestream hashes like FetchHashes, along
+	// with the display name of each channel that has one, keyed like the
+	// hashes.
+	FetchNamedHashes(ctx context.Context, source string) (map[string][]string, map[string]string, error)
+}
@@ -0,0 +1,17 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/audit"
+)
+
+// AuditLogRepository persists the trail of changes made to the lineup.
+type AuditLogRepository interface {
+	// Append records a new entry.
+	Append(ctx context.Context, e audit.Entry) error
+
+	// FindRecent retrieves up to limit entries, most recent first. A
+	// non-positive limit returns every entry.
+	FindRecent(ctx context.Context, limit int) ([]audit.Entry, error)
+}
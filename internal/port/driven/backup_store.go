@@ -0,0 +1,19 @@
+package driven
+
+import (
+	"context"
+	"io"
+)
+
+// BackupStore keeps backup files by name.
+type BackupStore interface {
+	// Save stores a backup under name, with its content produced by write.
+	// A failed write leaves no partial file behind.
+	Save(ctx context.Context, name string, write func(w io.Writer) error) error
+
+	// List returns the names of all stored backups.
+	List(ctx context.Context) ([]string, error)
+
+	// Delete removes the backup stored under name.
+	Delete(ctx context.Context, name string) error
+}
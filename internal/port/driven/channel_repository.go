@@ -24,6 +24,10 @@ type ChannelRepository interface {
 	// FindAll retrieves all channels.
 	FindAll(ctx context.Context) ([]channel.Channel, error)
 
+	// FindByTag retrieves the channels tagged with tag, which must be
+	// lowercase. Returns an empty slice if no channel has the tag.
+	FindByTag(ctx context.Context, tag string) ([]channel.Channel, error)
+
 	// Delete removes a channel by its name. Returns channel.ErrChannelNotFound
 	// if the channel does not exist.
 	Delete(ctx context.Context, name string) error
@@ -0,0 +1,18 @@
+package driven
+
+import (
+	"context"
+	"io"
+)
+
+// DatabaseBackup snapshots and restores the application database.
+type DatabaseBackup interface {
+	// WriteBackup writes a consistent snapshot of the database to w and
+	// returns the number of bytes written.
+	WriteBackup(ctx context.Context, w io.Writer) (int64, error)
+
+	// Restore replaces the database contents with the snapshot read from r.
+	// Returns backup.ErrInvalidBackup, leaving the database untouched, if r
+	// is not a valid snapshot.
+	Restore(ctx context.Context, r io.Reader) error
+}
@@ -0,0 +1,35 @@
+package driven
+
+import (
+	"context"
+	"time"
+)
+
+// EngineSession is a stream the proxy started on the engine and has not yet
+// stopped.
+type EngineSession struct {
+	// PID is the player ID the stream was started with.
+	PID string
+	// Key identifies the proxy session of the stream: its infohash, with any
+	// engine-side processing.
+	Key      string
+	InfoHash string
+	// Handle lets the engine reach the stream again after a restart; see
+	// AceStreamSessionRestorer. It is empty for engines without one.
+	Handle    string
+	StartedAt time.Time
+}
+
+// EngineSessionRepository persists the engine streams the proxy has open, so
+// that streams left running by a previous process can be stopped.
+type EngineSessionRepository interface {
+	// Save stores a session, replacing any with the same PID.
+	Save(ctx context.Context, s EngineSession) error
+
+	// FindAll retrieves all sessions, oldest first.
+	FindAll(ctx context.Context) ([]EngineSession, error)
+
+	// Delete removes the session with the given PID. Deleting a session
+	// that does not exist is not an error.
+	Delete(ctx context.Context, pid string) error
+}
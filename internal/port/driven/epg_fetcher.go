@@ -13,3 +13,11 @@ type EPGFetcher interface {
 	// Returns a slice of EPG channels or an error if the fetch operation fails.
 	FetchEPG(ctx context.Context) ([]epg.Channel, error)
 }
+
+// EPGProgrammeFetcher defines the interface for fetching the programme guide
+// of the EPG channels from external sources.
+type EPGProgrammeFetcher interface {
+	// FetchProgrammes retrieves the programmes listed by the source.
+	// Returns an error if the fetch operation fails.
+	FetchProgrammes(ctx context.Context) ([]epg.Programme, error)
+}
@@ -0,0 +1,30 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/favorite"
+)
+
+// FavoriteRepository defines the interface for favorite list persistence operations.
+// This is a driven port that will be implemented by concrete adapters (e.g., BoltDB).
+type FavoriteRepository interface {
+	// Save persists a new list. Returns favorite.ErrListAlreadyExists
+	// if a list with the same ID already exists.
+	Save(ctx context.Context, l favorite.List) error
+
+	// Update persists changes to an existing list.
+	// Returns favorite.ErrListNotFound if the list does not exist.
+	Update(ctx context.Context, l favorite.List) error
+
+	// FindAll retrieves all lists ordered by name.
+	FindAll(ctx context.Context) ([]favorite.List, error)
+
+	// FindByID retrieves a list by its ID.
+	// Returns favorite.ErrListNotFound if the list does not exist.
+	FindByID(ctx context.Context, id string) (favorite.List, error)
+
+	// Delete removes a list by its ID.
+	// Returns favorite.ErrListNotFound if the list does not exist.
+	Delete(ctx context.Context, id string) error
+}
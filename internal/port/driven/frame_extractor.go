@@ -0,0 +1,14 @@
+package driven
+
+import (
+	"context"
+	"io"
+)
+
+// FrameExtractor decodes still images out of video streams.
+type FrameExtractor interface {
+	// ExtractFrame reads an MPEG-TS stream from r until the first video
+	// frame can be decoded and returns it encoded as a JPEG. Reading stops
+	// once the frame is extracted; r is not read to the end.
+	ExtractFrame(ctx context.Context, r io.Reader) ([]byte, error)
+}
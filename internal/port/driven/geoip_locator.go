@@ -0,0 +1,10 @@
+package driven
+
+import "net/netip"
+
+// GeoIPLocator finds the country of an IP address.
+type GeoIPLocator interface {
+	// Country returns the ISO 3166 alpha-2 code of the country addr is
+	// located in, reporting false if it is unknown.
+	Country(addr netip.Addr) (string, bool)
+}
@@ -0,0 +1,30 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/group"
+)
+
+// GroupRepository defines the interface for channel group persistence operations.
+// This is a driven port that will be implemented by concrete adapters (e.g., BoltDB).
+type GroupRepository interface {
+	// Save persists a new group. Returns group.ErrGroupAlreadyExists
+	// if a group with the same ID already exists.
+	Save(ctx context.Context, g group.Group) error
+
+	// Update persists changes to an existing group.
+	// Returns group.ErrGroupNotFound if the group does not exist.
+	Update(ctx context.Context, g group.Group) error
+
+	// FindAll retrieves all groups ordered by position.
+	FindAll(ctx context.Context) ([]group.Group, error)
+
+	// FindByID retrieves a group by its ID.
+	// Returns group.ErrGroupNotFound if the group does not exist.
+	FindByID(ctx context.Context, id string) (group.Group, error)
+
+	// Delete removes a group by its ID.
+	// Returns group.ErrGroupNotFound if the group does not exist.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,12 @@
+package driven
+
+import (
+	"context"
+)
+
+// LogoFetcher downloads logo images from remote URLs.
+type LogoFetcher interface {
+	// FetchLogo downloads the image at url. Returns an error if the response
+	// is not a reasonably sized image.
+	FetchLogo(ctx context.Context, url string) ([]byte, error)
+}
@@ -0,0 +1,19 @@
+package driven
+
+import (
+	"context"
+)
+
+// LogoStore persists downloaded channel logos by key.
+type LogoStore interface {
+	// Save stores logo data under key, recording the URL it was fetched from.
+	Save(ctx context.Context, key, sourceURL string, data []byte) error
+
+	// Load returns the logo stored under key. Returns logo.ErrLogoNotFound if
+	// no logo is stored.
+	Load(ctx context.Context, key string) ([]byte, error)
+
+	// SourceURL returns the URL the logo under key was fetched from. Returns
+	// logo.ErrLogoNotFound if no logo is stored.
+	SourceURL(ctx context.Context, key string) (string, error)
+}
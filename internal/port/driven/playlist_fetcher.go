@@ -0,0 +1,12 @@
+package driven
+
+import (
+	"context"
+)
+
+// PlaylistFetcher downloads M3U playlists from remote URLs.
+type PlaylistFetcher interface {
+	// FetchPlaylist downloads the playlist at url. Returns an error if the
+	// response is not successful or is unreasonably large.
+	FetchPlaylist(ctx context.Context, url string) ([]byte, error)
+}
@@ -22,7 +22,7 @@ type ProbeRepository interface {
 	// rolling window requirement.
 	FindByInfoHashSince(ctx context.Context, infoHash string, since time.Time) ([]probe.Result, error)
 
-	// DeleteBefore removes all probe results older than the given time.
-	// This is used for retention/cleanup.
-	DeleteBefore(ctx context.Context, before time.Time) error
+	// DeleteBefore removes all probe results older than the given time and
+	// returns how many were removed. This is used for retention/cleanup.
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
 }
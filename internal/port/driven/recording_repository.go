@@ -0,0 +1,28 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+// RecordingRepository persists recordings.
+type RecordingRepository interface {
+	// Save persists a new recording.
+	Save(ctx context.Context, r recording.Recording) error
+
+	// Update persists changes to an existing recording. Returns
+	// recording.ErrRecordingNotFound if the recording does not exist.
+	Update(ctx context.Context, r recording.Recording) error
+
+	// FindAll retrieves all recordings ordered by start time.
+	FindAll(ctx context.Context) ([]recording.Recording, error)
+
+	// FindByID retrieves a recording by its ID. Returns
+	// recording.ErrRecordingNotFound if the recording does not exist.
+	FindByID(ctx context.Context, id string) (recording.Recording, error)
+
+	// Delete removes a recording by its ID. Returns
+	// recording.ErrRecordingNotFound if the recording does not exist.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,21 @@
+package driven
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// RecordingStore keeps the captured transport stream of each recording,
+// keyed by recording ID.
+type RecordingStore interface {
+	// Create starts an empty file for the recording, replacing any previous one.
+	Create(ctx context.Context, id string) (io.WriteCloser, error)
+
+	// Open returns the recording's file and when it was last written.
+	// Returns recording.ErrNoFile if nothing has been captured.
+	Open(ctx context.Context, id string) (io.ReadSeekCloser, time.Time, error)
+
+	// Delete removes the recording's file. Deleting a missing file is not an error.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,24 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/rule"
+)
+
+// RuleRepository persists override rules.
+type RuleRepository interface {
+	// Save persists a new rule.
+	Save(ctx context.Context, r rule.Rule) error
+
+	// FindAll retrieves all rules in the order they apply (see rule.Sort).
+	FindAll(ctx context.Context) ([]rule.Rule, error)
+
+	// FindByID retrieves a rule by its ID. Returns rule.ErrRuleNotFound if
+	// the rule does not exist.
+	FindByID(ctx context.Context, id string) (rule.Rule, error)
+
+	// Delete removes a rule by its ID. Returns rule.ErrRuleNotFound if the
+	// rule does not exist.
+	Delete(ctx context.Context, id string) error
+}
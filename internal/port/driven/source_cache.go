@@ -0,0 +1,29 @@
+package driven
+
+import (
+	"context"
+	"time"
+)
+
+// CachedSource describes a downloaded upstream file kept in cache.
+type CachedSource struct {
+	// Key identifies the entry in the cache.
+	Key string
+	// URL is where the file was downloaded from.
+	URL string
+	// Size is the size of the cached body in bytes.
+	Size int64
+	// FetchedAt is when the file was last downloaded or revalidated.
+	FetchedAt time.Time
+}
+
+// SourceCache defines the interface for inspecting and invalidating the
+// cached copies of upstream sources such as the EPG guide and hash lists.
+type SourceCache interface {
+	// Entries returns the cached entries.
+	Entries(ctx context.Context) ([]CachedSource, error)
+	// Invalidate removes the entry with the given key, so that the next
+	// fetch downloads the file again. Returns false if there is no such
+	// entry.
+	Invalidate(ctx context.Context, key string) (bool, error)
+}
@@ -0,0 +1,29 @@
+package driven
+
+import (
+	"context"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/sourcechange"
+)
+
+// SourceChangeRepository persists the last refresh of each upstream source
+// and the changes detected between refreshes.
+type SourceChangeRepository interface {
+	// FindSnapshot retrieves the hashes of the last refresh of a source,
+	// keyed by channel name. Returns sourcechange.ErrSnapshotNotFound if
+	// the source has not been refreshed yet.
+	FindSnapshot(ctx context.Context, source string) (map[string][]string, error)
+
+	// Record saves the hashes of a new refresh of a source, replacing the
+	// previous snapshot, together with the changes detected against it.
+	Record(ctx context.Context, source string, snapshot map[string][]string, changes []sourcechange.Change) error
+
+	// FindBySource retrieves up to limit changes of a source, most recent
+	// first. A non-positive limit returns every change.
+	FindBySource(ctx context.Context, source string, limit int) ([]sourcechange.Change, error)
+
+	// DeleteBefore removes all changes detected before the given time and
+	// returns how many were removed. This is used for retention/cleanup.
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+}
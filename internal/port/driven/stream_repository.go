@@ -13,6 +13,11 @@ type StreamRepository interface {
 	// with the same infohash already exists.
 	Save(ctx context.Context, s stream.Stream) error
 
+	// Update replaces a stream with the same infohash, such as one moved to
+	// another channel. Returns stream.ErrStreamNotFound if the stream does
+	// not exist.
+	Update(ctx context.Context, s stream.Stream) error
+
 	// FindByInfoHash retrieves a stream by its infohash. Returns stream.ErrStreamNotFound
 	// if the stream does not exist.
 	FindByInfoHash(ctx context.Context, infoHash string) (stream.Stream, error)
@@ -0,0 +1,33 @@
+package driven
+
+import (
+	"context"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streamstats"
+)
+
+// StreamStatsRepository defines the interface for persisting the history of
+// engine statistics of streams.
+// This is a driven port implemented by concrete adapters (e.g., BoltDB).
+type StreamStatsRepository interface {
+	// Save persists stats samples.
+	Save(ctx context.Context, samples ...streamstats.Sample) error
+
+	// FindByInfoHashSince retrieves the samples of a stream since the given
+	// time, ordered by timestamp ascending.
+	FindByInfoHashSince(ctx context.Context, infoHash string, since time.Time) ([]streamstats.Sample, error)
+
+	// FindReadingsBefore retrieves the single readings (samples with a zero
+	// span) of every stream older than the given time.
+	FindReadingsBefore(ctx context.Context, before time.Time) ([]streamstats.Sample, error)
+
+	// ReplaceReadingsBefore removes the single readings older than the given
+	// time and saves samples in their place, atomically. This is used to
+	// compact readings into coarser samples.
+	ReplaceReadingsBefore(ctx context.Context, before time.Time, samples []streamstats.Sample) error
+
+	// DeleteBefore removes all samples older than the given time and returns
+	// how many were removed. This is used for retention/cleanup.
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+}
@@ -13,6 +13,10 @@ type SubscriptionRepository interface {
 	// if a subscription with the same EPG channel ID already exists.
 	Save(ctx context.Context, sub subscription.Subscription) error
 
+	// Update replaces a stored subscription with the same EPG channel ID.
+	// Returns subscription.ErrSubscriptionNotFound if the subscription does not exist.
+	Update(ctx context.Context, sub subscription.Subscription) error
+
 	// FindAll retrieves all subscriptions.
 	FindAll(ctx context.Context) ([]subscription.Subscription, error)
 
@@ -0,0 +1,27 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/auth"
+)
+
+type TokenRepository interface {
+	// Save persists an API token, replacing any token with the same ID.
+	Save(ctx context.Context, token auth.Token) error
+
+	// FindAll retrieves all API tokens.
+	FindAll(ctx context.Context) ([]auth.Token, error)
+
+	// FindByID retrieves the token with the given ID. Returns
+	// auth.ErrTokenNotFound if the token does not exist.
+	FindByID(ctx context.Context, id string) (auth.Token, error)
+
+	// FindBySecretHash retrieves the token whose secret hashes to the given
+	// value. Returns auth.ErrTokenNotFound if no token matches.
+	FindBySecretHash(ctx context.Context, secretHash string) (auth.Token, error)
+
+	// Delete removes a token by its ID. Returns auth.ErrTokenNotFound if the
+	// token does not exist.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,28 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/user"
+)
+
+// UserRepository persists the users a server is shared with.
+type UserRepository interface {
+	// Save persists a user, replacing any user with the same ID.
+	Save(ctx context.Context, u user.User) error
+
+	// FindAll retrieves all users, oldest first (see user.Sort).
+	FindAll(ctx context.Context) ([]user.User, error)
+
+	// FindByID retrieves a user by its ID. Returns user.ErrUserNotFound if
+	// the user does not exist.
+	FindByID(ctx context.Context, id string) (user.User, error)
+
+	// FindByPlaylistToken retrieves the user owning a playlist token.
+	// Returns user.ErrUserNotFound if no user has the token.
+	FindByPlaylistToken(ctx context.Context, token string) (user.User, error)
+
+	// Delete removes a user by its ID. Returns user.ErrUserNotFound if the
+	// user does not exist.
+	Delete(ctx context.Context, id string) error
+}
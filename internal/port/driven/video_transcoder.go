@@ -0,0 +1,15 @@
+package driven
+
+import (
+	"context"
+	"io"
+)
+
+// VideoTranscoder re-encodes the video of live streams.
+type VideoTranscoder interface {
+	// Transcode starts re-encoding an MPEG-TS stream so that its video is at
+	// most height lines tall, writing the result to dst. The stream is
+	// written to the returned writer; closing it flushes the remaining
+	// output to dst and stops the transcoder.
+	Transcode(ctx context.Context, dst io.Writer, height int) (io.WriteCloser, error)
+}
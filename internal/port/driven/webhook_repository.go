@@ -0,0 +1,20 @@
+package driven
+
+import (
+	"context"
+
+	"github.com/alorle/iptv-manager/internal/webhook"
+)
+
+// WebhookRepository persists the webhooks notified of significant events.
+type WebhookRepository interface {
+	// Save persists a new webhook.
+	Save(ctx context.Context, w webhook.Webhook) error
+
+	// FindAll retrieves all webhooks, oldest first (see webhook.Sort).
+	FindAll(ctx context.Context) ([]webhook.Webhook, error)
+
+	// Delete removes a webhook by its ID. Returns webhook.ErrWebhookNotFound
+	// if the webhook does not exist.
+	Delete(ctx context.Context, id string) error
+}
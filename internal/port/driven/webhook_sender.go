@@ -0,0 +1,12 @@
+package driven
+
+import (
+	"context"
+)
+
+// WebhookSender delivers notification payloads to webhook URLs.
+type WebhookSender interface {
+	// Send posts a JSON payload to url. Returns an error if the request
+	// fails or the receiver does not answer with a 2xx status.
+	Send(ctx context.Context, url string, payload []byte) error
+}
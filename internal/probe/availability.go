@@ -0,0 +1,26 @@
+package probe
+
+// Availability is the aggregated availability of a channel across its streams.
+type Availability string
+
+const (
+	AvailabilityAvailable   Availability = "available"
+	AvailabilityUnavailable Availability = "unavailable"
+	AvailabilityUnknown     Availability = "unknown"
+)
+
+// AggregateAvailability folds the latest probe result of each stream into a
+// single channel-level availability. A channel is available if any stream is
+// available, unavailable if every probed stream is dead, and unknown when no
+// probe data exists.
+func AggregateAvailability(latest []Result) Availability {
+	if len(latest) == 0 {
+		return AvailabilityUnknown
+	}
+	for _, r := range latest {
+		if r.Available() {
+			return AvailabilityAvailable
+		}
+	}
+	return AvailabilityUnavailable
+}
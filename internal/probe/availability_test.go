@@ -0,0 +1,30 @@
+package probe
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregateAvailability(t *testing.T) {
+	now := time.Now()
+	alive := ReconstructResult("hash1", now, true, time.Second, 10, 1000, "dl", "")
+	dead := ReconstructResult("hash2", now, false, 0, 0, 0, "", "timeout")
+
+	tests := []struct {
+		name   string
+		latest []Result
+		want   Availability
+	}{
+		{"one available one dead", []Result{dead, alive}, AvailabilityAvailable},
+		{"all dead", []Result{dead, dead}, AvailabilityUnavailable},
+		{"no data", nil, AvailabilityUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AggregateAvailability(tt.latest); got != tt.want {
+				t.Errorf("AggregateAvailability() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// maxThrottledChunk is the largest write passed through a throttled writer at
+// once, so a large chunk is paced out instead of sent in one burst.
+const maxThrottledChunk = 32 << 10
+
+// ByteLimiter is a token bucket measured in bytes. It holds up to one second
+// of bytes, so an idle stream may burst that much before being paced. Writes
+// larger than the bucket are allowed and paid back by the writes after them.
+//
+// A rate of zero or less disables the limiter: Reserve never waits. The rate
+// can be changed while the limiter is in use.
+type ByteLimiter struct {
+	mu     sync.Mutex
+	rate   int64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewByteLimiter creates a limiter allowing rate bytes per second.
+func NewByteLimiter(rate int64) *ByteLimiter {
+	return NewByteLimiterWithClock(rate, time.Now)
+}
+
+// NewByteLimiterWithClock is like NewByteLimiter but uses the given clock,
+// which lets callers control time in tests.
+func NewByteLimiterWithClock(rate int64, now func() time.Time) *ByteLimiter {
+	return &ByteLimiter{
+		rate:   rate,
+		tokens: float64(max(rate, 0)),
+		last:   now(),
+		now:    now,
+	}
+}
+
+// Rate returns the limit in bytes per second; zero or less means unlimited.
+func (l *ByteLimiter) Rate() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// SetRate changes the limit, keeping the bytes already allowed.
+func (l *ByteLimiter) SetRate(rate int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refillLocked(l.now())
+	l.rate = rate
+	l.tokens = min(l.tokens, float64(max(rate, 0)))
+}
+
+// Reserve takes n bytes from the bucket and returns how long the caller must
+// wait before sending them.
+func (l *ByteLimiter) Reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0
+	}
+	l.refillLocked(l.now())
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / float64(l.rate) * float64(time.Second))
+}
+
+// WaitN waits until n bytes may be sent or ctx is done.
+func (l *ByteLimiter) WaitN(ctx context.Context, n int) error {
+	wait := l.Reserve(n)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// refillLocked adds the bytes earned since the last update. Callers must hold l.mu.
+func (l *ByteLimiter) refillLocked(now time.Time) {
+	if l.rate > 0 {
+		l.tokens = min(float64(l.rate), l.tokens+now.Sub(l.last).Seconds()*float64(l.rate))
+	}
+	l.last = now
+}
+
+// throttledWriter paces writes through every one of its limiters.
+type throttledWriter struct {
+	ctx      context.Context
+	dst      io.Writer
+	limiters []*ByteLimiter
+}
+
+// NewWriter returns a writer that passes writes to dst no faster than every
+// one of the limiters allows. Waiting ends early with ctx's error once ctx
+// is done. Nil limiters are ignored.
+func NewWriter(ctx context.Context, dst io.Writer, limiters ...*ByteLimiter) io.Writer {
+	active := make([]*ByteLimiter, 0, len(limiters))
+	for _, l := range limiters {
+		if l != nil {
+			active = append(active, l)
+		}
+	}
+	return &throttledWriter{ctx: ctx, dst: dst, limiters: active}
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), maxThrottledChunk)]
+		for _, l := range w.limiters {
+			if err := l.WaitN(w.ctx, len(chunk)); err != nil {
+				return written, err
+			}
+		}
+
+		n, err := w.dst.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestByteLimiter_Reserve(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := NewByteLimiterWithClock(1000, clock.Now)
+
+	if wait := l.Reserve(1000); wait != 0 {
+		t.Errorf("a full bucket should allow one second of bytes, got wait %v", wait)
+	}
+	if wait := l.Reserve(500); wait != 500*time.Millisecond {
+		t.Errorf("Reserve() = %v, want 500ms", wait)
+	}
+
+	clock.Advance(time.Second)
+	if wait := l.Reserve(500); wait != 0 {
+		t.Errorf("the debt should be paid back after a second, got wait %v", wait)
+	}
+
+	clock.Advance(time.Hour)
+	if wait := l.Reserve(1500); wait != 500*time.Millisecond {
+		t.Errorf("an idle bucket should hold at most one second of bytes, got wait %v", wait)
+	}
+}
+
+func TestByteLimiter_SetRate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := NewByteLimiterWithClock(0, clock.Now)
+
+	if wait := l.Reserve(1 << 30); wait != 0 {
+		t.Errorf("an unlimited limiter should never wait, got %v", wait)
+	}
+
+	l.SetRate(100)
+	if l.Rate() != 100 {
+		t.Errorf("Rate() = %d, want 100", l.Rate())
+	}
+	if wait := l.Reserve(100); wait != time.Second {
+		t.Errorf("a new limit should start from an empty bucket, got wait %v", wait)
+	}
+
+	l.SetRate(0)
+	if wait := l.Reserve(100); wait != 0 {
+		t.Errorf("removing the limit should stop waiting, got %v", wait)
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	t.Run("paces writes to the slowest limiter", func(t *testing.T) {
+		var dst bytes.Buffer
+		fast, slow := NewByteLimiter(1<<20), NewByteLimiter(maxThrottledChunk*10)
+		w := NewWriter(context.Background(), &dst, fast, nil, slow)
+
+		start := time.Now()
+		payload := make([]byte, maxThrottledChunk*12)
+		n, err := w.Write(payload)
+		if err != nil || n != len(payload) || dst.Len() != len(payload) {
+			t.Fatalf("Write() = (%d, %v), wrote %d bytes", n, err, dst.Len())
+		}
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("expected writes beyond the bucket to be paced, took %v", elapsed)
+		}
+	})
+
+	t.Run("stops waiting when the context ends", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var dst bytes.Buffer
+		w := NewWriter(ctx, &dst, NewByteLimiter(1))
+
+		time.AfterFunc(10*time.Millisecond, cancel)
+		n, err := w.Write(make([]byte, 100))
+		if !errors.Is(err, context.Canceled) || n != 0 {
+			t.Errorf("Write() = (%d, %v), want context.Canceled", n, err)
+		}
+	})
+}
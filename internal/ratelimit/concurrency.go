@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrencyLimiter caps how many operations each key may have in flight.
+//
+// A max of zero or less disables the limiter: Acquire always succeeds.
+type ConcurrencyLimiter struct {
+	mu       sync.Mutex
+	max      int
+	active   map[string]int
+	rejected atomic.Uint64
+}
+
+// NewConcurrencyLimiter creates a limiter allowing up to max concurrent
+// operations per key.
+func NewConcurrencyLimiter(max int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		max:    max,
+		active: make(map[string]int),
+	}
+}
+
+// Acquire reserves a slot for key. If the key is at its limit it returns
+// false; otherwise the returned release function must be called once the
+// operation ends. Calling release more than once has no further effect.
+func (c *ConcurrencyLimiter) Acquire(key string) (func(), bool) {
+	if c.max <= 0 {
+		return func() {}, true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.active[key] >= c.max {
+		c.rejected.Add(1)
+		return nil, false
+	}
+	c.active[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { c.release(key) })
+	}, true
+}
+
+func (c *ConcurrencyLimiter) release(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.active[key]--
+	if c.active[key] <= 0 {
+		delete(c.active, key)
+	}
+}
+
+// Active returns the number of operations in flight across all keys.
+func (c *ConcurrencyLimiter) Active() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := 0
+	for _, n := range c.active {
+		total += n
+	}
+	return total
+}
+
+// Rejected returns the number of acquisitions refused since the limiter was created.
+func (c *ConcurrencyLimiter) Rejected() uint64 {
+	return c.rejected.Load()
+}
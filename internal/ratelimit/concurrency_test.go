@@ -0,0 +1,49 @@
+package ratelimit
+
+import "testing"
+
+func TestConcurrencyLimiter_CapsPerKey(t *testing.T) {
+	c := NewConcurrencyLimiter(2)
+
+	release1, ok := c.Acquire("10.0.0.1")
+	if !ok {
+		t.Fatal("first acquire should succeed")
+	}
+	if _, ok := c.Acquire("10.0.0.1"); !ok {
+		t.Fatal("second acquire should succeed")
+	}
+	if _, ok := c.Acquire("10.0.0.1"); ok {
+		t.Fatal("third acquire should be rejected")
+	}
+	if _, ok := c.Acquire("10.0.0.2"); !ok {
+		t.Fatal("other keys should have their own limit")
+	}
+
+	if got := c.Active(); got != 3 {
+		t.Errorf("Active() = %d, want 3", got)
+	}
+	if got := c.Rejected(); got != 1 {
+		t.Errorf("Rejected() = %d, want 1", got)
+	}
+
+	release1()
+	release1()
+	if got := c.Active(); got != 2 {
+		t.Errorf("Active() after release = %d, want 2", got)
+	}
+	if _, ok := c.Acquire("10.0.0.1"); !ok {
+		t.Error("acquire should succeed after a release")
+	}
+}
+
+func TestConcurrencyLimiter_DisabledWithZeroMax(t *testing.T) {
+	c := NewConcurrencyLimiter(0)
+
+	for i := 0; i < 10; i++ {
+		release, ok := c.Acquire("a")
+		if !ok {
+			t.Fatal("disabled limiter should allow every acquire")
+		}
+		defer release()
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Limiter is a per-key token bucket rate limiter. Each key may make burst
+// requests at once and regains rate requests per second after that.
+//
+// A rate of zero or less disables the limiter: Allow always succeeds.
+type Limiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     float64
+	now       func() time.Time
+	buckets   map[string]*bucket
+	lastPrune time.Time
+	rejected  atomic.Uint64
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// pruneInterval is how often buckets that have refilled completely are
+// dropped, so keys that stop making requests do not accumulate.
+const pruneInterval = time.Minute
+
+// New creates a limiter allowing rate requests per second per key with bursts
+// of up to burst requests. A burst below one is treated as one.
+func New(rate float64, burst int) *Limiter {
+	return NewWithClock(rate, burst, time.Now)
+}
+
+// NewWithClock is like New but uses the given clock, which lets callers
+// control time in tests.
+func NewWithClock(rate float64, burst int, now func() time.Time) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		now:     now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow reports whether a request for key may proceed, consuming a token if
+// so. When it may not, the returned duration is how long until a token is
+// available again.
+func (l *Limiter) Allow(key string) (time.Duration, bool) {
+	if l.rate <= 0 {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.pruneLocked(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	l.rejected.Add(1)
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return wait, false
+}
+
+// Rejected returns the number of requests refused since the limiter was created.
+func (l *Limiter) Rejected() uint64 {
+	return l.rejected.Load()
+}
+
+// pruneLocked drops buckets that would be full by now. Callers must hold l.mu.
+func (l *Limiter) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < pruneInterval {
+		return
+	}
+	l.lastPrune = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
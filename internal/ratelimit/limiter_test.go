@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for deterministic limiter tests.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestLimiter_AllowsBurstThenRejects(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := NewWithClock(2, 3, clock.Now)
+
+	for i := 0; i < 3; i++ {
+		if _, ok := l.Allow("10.0.0.1"); !ok {
+			t.Fatalf("request %d within burst should be allowed", i+1)
+		}
+	}
+
+	wait, ok := l.Allow("10.0.0.1")
+	if ok {
+		t.Fatal("request beyond burst should be rejected")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("retry after = %v, want 500ms", wait)
+	}
+	if got := l.Rejected(); got != 1 {
+		t.Errorf("Rejected() = %d, want 1", got)
+	}
+
+	if _, ok := l.Allow("10.0.0.2"); !ok {
+		t.Error("other keys should have their own bucket")
+	}
+}
+
+func TestLimiter_Refills(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := NewWithClock(1, 1, clock.Now)
+
+	if _, ok := l.Allow("a"); !ok {
+		t.Fatal("first request should be allowed")
+	}
+	if _, ok := l.Allow("a"); ok {
+		t.Fatal("second request should be rejected")
+	}
+
+	clock.Advance(time.Second)
+	if _, ok := l.Allow("a"); !ok {
+		t.Error("request should be allowed once the bucket refills")
+	}
+}
+
+func TestLimiter_PrunesIdleKeys(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := NewWithClock(1, 5, clock.Now)
+
+	l.Allow("a")
+	clock.Advance(2 * pruneInterval)
+	l.Allow("b")
+
+	if _, ok := l.buckets["a"]; ok {
+		t.Error("expected refilled bucket to be pruned")
+	}
+	if _, ok := l.buckets["b"]; !ok {
+		t.Error("expected active bucket to be kept")
+	}
+}
+
+func TestLimiter_DisabledWithZeroRate(t *testing.T) {
+	l := New(0, 1)
+
+	for i := 0; i < 100; i++ {
+		if _, ok := l.Allow("a"); !ok {
+			t.Fatal("disabled limiter should allow every request")
+		}
+	}
+}
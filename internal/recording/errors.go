@@ -0,0 +1,16 @@
+package recording
+
+import "errors"
+
+// Domain errors for recording operations.
+var (
+	// Recording validation errors
+	ErrEmptyChannelName = errors.New("recording channel name cannot be empty")
+	ErrInvalidWindow    = errors.New("recording must stop after it starts")
+	ErrWindowPassed     = errors.New("recording window has already passed")
+
+	// Recording operation errors
+	ErrRecordingNotFound = errors.New("recording not found")
+	ErrNoFile            = errors.New("recording has no file yet")
+	ErrNoCatchup         = errors.New("no recording of the channel covers that time")
+)
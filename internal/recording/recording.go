@@ -0,0 +1,152 @@
+// Package recording models scheduled captures of a channel's live stream to
+// disk.
+package recording
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Status is where a recording is in its lifecycle.
+type Status string
+
+const (
+	StatusScheduled Status = "scheduled" // Waiting for its start time
+	StatusRecording Status = "recording" // Capturing the stream
+	StatusCompleted Status = "completed" // Stopped at its stop time
+	StatusFailed    Status = "failed"    // Ended early; the file holds whatever was captured
+	StatusCancelled Status = "cancelled" // Stopped by the user
+)
+
+// Recording captures a channel's stream between a start and a stop time.
+type Recording struct {
+	id          string
+	channelName string
+	startAt     time.Time
+	stopAt      time.Time
+	status      Status
+	size        int64
+	lastError   string
+	createdAt   time.Time
+}
+
+// NewRecording schedules a recording of the channel with a random ID.
+// Returns ErrEmptyChannelName if the channel name is empty or contains only whitespace.
+// Returns ErrInvalidWindow if stopAt is not after startAt.
+// Returns ErrWindowPassed if stopAt is not after now.
+func NewRecording(channelName string, startAt, stopAt, now time.Time) (Recording, error) {
+	trimmed := strings.TrimSpace(channelName)
+	if trimmed == "" {
+		return Recording{}, ErrEmptyChannelName
+	}
+	if !stopAt.After(startAt) {
+		return Recording{}, ErrInvalidWindow
+	}
+	if !stopAt.After(now) {
+		return Recording{}, ErrWindowPassed
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return Recording{}, err
+	}
+
+	return Recording{
+		id:          hex.EncodeToString(idBytes),
+		channelName: trimmed,
+		startAt:     startAt,
+		stopAt:      stopAt,
+		status:      StatusScheduled,
+		createdAt:   now,
+	}, nil
+}
+
+// ReconstructRecording rebuilds a Recording from persisted state.
+// This is intended for repository adapters only — it bypasses validation.
+func ReconstructRecording(id, channelName string, startAt, stopAt time.Time, status Status, size int64, lastError string, createdAt time.Time) Recording {
+	return Recording{
+		id:          id,
+		channelName: channelName,
+		startAt:     startAt,
+		stopAt:      stopAt,
+		status:      status,
+		size:        size,
+		lastError:   lastError,
+		createdAt:   createdAt,
+	}
+}
+
+// ID returns the recording's identifier, which also names its file.
+func (r Recording) ID() string {
+	return r.id
+}
+
+// ChannelName returns the name of the recorded channel.
+func (r Recording) ChannelName() string {
+	return r.channelName
+}
+
+// StartAt returns when recording begins.
+func (r Recording) StartAt() time.Time {
+	return r.startAt
+}
+
+// StopAt returns when recording ends.
+func (r Recording) StopAt() time.Time {
+	return r.stopAt
+}
+
+// Status returns where the recording is in its lifecycle.
+func (r Recording) Status() Status {
+	return r.status
+}
+
+// Size returns the number of bytes captured so far.
+func (r Recording) Size() int64 {
+	return r.size
+}
+
+// LastError describes why a failed recording ended early.
+func (r Recording) LastError() string {
+	return r.lastError
+}
+
+// CreatedAt returns when the recording was scheduled.
+func (r Recording) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+// IsDue reports whether a scheduled recording should be capturing at now.
+func (r Recording) IsDue(now time.Time) bool {
+	return r.status == StatusScheduled && !now.Before(r.startAt)
+}
+
+// IsFinished reports whether the recording will capture no more data.
+func (r Recording) IsFinished() bool {
+	return r.status == StatusCompleted || r.status == StatusFailed || r.status == StatusCancelled
+}
+
+// Start marks the recording as capturing.
+func (r *Recording) Start() {
+	r.status = StatusRecording
+}
+
+// Finish marks a capture as ended with size bytes written. A nil err
+// completes the recording; otherwise it fails with err as its last error.
+func (r *Recording) Finish(size int64, err error) {
+	r.size = size
+	if err != nil {
+		r.status = StatusFailed
+		r.lastError = err.Error()
+		return
+	}
+	r.status = StatusCompleted
+}
+
+// Cancel stops the recording, keeping size bytes already captured.
+func (r *Recording) Cancel(size int64) {
+	r.status = StatusCancelled
+	r.size = size
+}
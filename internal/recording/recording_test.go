@@ -0,0 +1,83 @@
+package recording_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/recording"
+)
+
+func TestNewRecording(t *testing.T) {
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		channel   string
+		startAt   time.Time
+		stopAt    time.Time
+		wantError error
+	}{
+		{name: "valid recording", channel: " News ", startAt: now.Add(time.Hour), stopAt: now.Add(2 * time.Hour)},
+		{name: "already started", channel: "News", startAt: now.Add(-time.Hour), stopAt: now.Add(time.Hour)},
+		{name: "empty channel", channel: "  ", startAt: now, stopAt: now.Add(time.Hour), wantError: recording.ErrEmptyChannelName},
+		{name: "stop before start", channel: "News", startAt: now.Add(time.Hour), stopAt: now, wantError: recording.ErrInvalidWindow},
+		{name: "window passed", channel: "News", startAt: now.Add(-2 * time.Hour), stopAt: now.Add(-time.Hour), wantError: recording.ErrWindowPassed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, err := recording.NewRecording(tt.channel, tt.startAt, tt.stopAt, now)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NewRecording() error = %v, want %v", err, tt.wantError)
+			}
+			if tt.wantError != nil {
+				return
+			}
+			if rec.ID() == "" || rec.ChannelName() != "News" {
+				t.Errorf("NewRecording() = (%q, %q), want a random ID for channel News", rec.ID(), rec.ChannelName())
+			}
+			if rec.Status() != recording.StatusScheduled || !rec.CreatedAt().Equal(now) {
+				t.Errorf("expected a scheduled recording created at %v, got %q at %v", now, rec.Status(), rec.CreatedAt())
+			}
+		})
+	}
+}
+
+func TestRecording_Lifecycle(t *testing.T) {
+	now := time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+	rec, err := recording.NewRecording("News", now.Add(time.Hour), now.Add(2*time.Hour), now)
+	if err != nil {
+		t.Fatalf("NewRecording() error = %v", err)
+	}
+
+	if rec.IsDue(now) {
+		t.Error("expected recording not to be due before its start time")
+	}
+	if !rec.IsDue(now.Add(time.Hour)) {
+		t.Error("expected recording to be due at its start time")
+	}
+
+	rec.Start()
+	if rec.IsDue(now.Add(time.Hour)) || rec.IsFinished() {
+		t.Errorf("expected a capturing recording to be neither due nor finished, got %q", rec.Status())
+	}
+
+	failed := rec
+	failed.Finish(10, errors.New("stream ended"))
+	if failed.Status() != recording.StatusFailed || failed.LastError() != "stream ended" || failed.Size() != 10 {
+		t.Errorf("Finish(err) = (%q, %q, %d), want (failed, stream ended, 10)", failed.Status(), failed.LastError(), failed.Size())
+	}
+
+	completed := rec
+	completed.Finish(20, nil)
+	if completed.Status() != recording.StatusCompleted || completed.Size() != 20 || !completed.IsFinished() {
+		t.Errorf("Finish(nil) = (%q, %d), want (completed, 20)", completed.Status(), completed.Size())
+	}
+
+	cancelled := rec
+	cancelled.Cancel(5)
+	if cancelled.Status() != recording.StatusCancelled || cancelled.Size() != 5 || !cancelled.IsFinished() {
+		t.Errorf("Cancel() = (%q, %d), want (cancelled, 5)", cancelled.Status(), cancelled.Size())
+	}
+}
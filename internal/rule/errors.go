@@ -0,0 +1,14 @@
+package rule
+
+import "errors"
+
+// Domain errors for override rule operations.
+var (
+	// Rule validation errors
+	ErrNoMatcher      = errors.New("rule must match on a name pattern, group or source")
+	ErrNoAction       = errors.New("rule must rename, re-group, disable or set a tvg-id")
+	ErrInvalidPattern = errors.New("invalid name pattern")
+
+	// Rule operation errors
+	ErrRuleNotFound = errors.New("override rule not found")
+)
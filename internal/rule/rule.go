@@ -0,0 +1,201 @@
+// Package rule models override rules: pattern-based transformations applied
+// to every matching playlist entry when a playlist is generated, instead of
+// configuring channels one by one.
+package rule
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Match selects the entries a rule applies to. Every non-empty field must
+// match.
+type Match struct {
+	// NamePattern is a regular expression matched against the channel name.
+	NamePattern string
+	// Group is compared case-insensitively with the entry's group-title.
+	Group string
+	// Source is compared case-insensitively with the source of the stream.
+	Source string
+}
+
+// Action is what a rule does to the entries it matches. Empty fields are
+// left unchanged.
+type Action struct {
+	// Rename replaces the channel name. With a name pattern, only the
+	// matched text is replaced and $1-style references expand to the
+	// pattern's groups, so "^(.*) HD$" with "$1" drops an " HD" suffix.
+	Rename string
+	// Group sets the group-title.
+	Group string
+	// TVGID sets the tvg-id used to look up the channel in the guide.
+	TVGID string
+	// Disable leaves matching entries out of the playlist.
+	Disable bool
+}
+
+// Subject is the part of a playlist entry that rules match and change.
+type Subject struct {
+	ChannelName string
+	Group       string
+	Source      string
+	TVGID       string
+}
+
+// Rule transforms the playlist entries it matches.
+type Rule struct {
+	id        string
+	match     Match
+	action    Action
+	pattern   *regexp.Regexp
+	createdAt time.Time
+}
+
+// NewRule creates a rule with a random ID. Surrounding whitespace is trimmed
+// from every field except the rename replacement.
+// Returns ErrNoMatcher if the match has no fields set.
+// Returns ErrNoAction if the action changes nothing.
+// Returns ErrInvalidPattern if the name pattern is not a valid regular expression.
+func NewRule(match Match, action Action, now time.Time) (Rule, error) {
+	match = Match{
+		NamePattern: strings.TrimSpace(match.NamePattern),
+		Group:       strings.TrimSpace(match.Group),
+		Source:      strings.TrimSpace(match.Source),
+	}
+	action.Group = strings.TrimSpace(action.Group)
+	action.TVGID = strings.TrimSpace(action.TVGID)
+
+	if match == (Match{}) {
+		return Rule{}, ErrNoMatcher
+	}
+	if action == (Action{}) {
+		return Rule{}, ErrNoAction
+	}
+
+	var pattern *regexp.Regexp
+	if match.NamePattern != "" {
+		re, err := regexp.Compile(match.NamePattern)
+		if err != nil {
+			return Rule{}, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+		}
+		pattern = re
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return Rule{}, err
+	}
+
+	return Rule{
+		id:        hex.EncodeToString(idBytes),
+		match:     match,
+		action:    action,
+		pattern:   pattern,
+		createdAt: now,
+	}, nil
+}
+
+// ReconstructRule rebuilds a Rule from persisted state.
+// This is intended for repository adapters only — it bypasses validation.
+// A rule whose name pattern no longer compiles matches nothing.
+func ReconstructRule(id string, match Match, action Action, createdAt time.Time) Rule {
+	r := Rule{
+		id:        id,
+		match:     match,
+		action:    action,
+		createdAt: createdAt,
+	}
+	if match.NamePattern != "" {
+		r.pattern, _ = regexp.Compile(match.NamePattern)
+	}
+	return r
+}
+
+// ID returns the rule's identifier.
+func (r Rule) ID() string {
+	return r.id
+}
+
+// Match returns what the rule matches.
+func (r Rule) Match() Match {
+	return r.match
+}
+
+// Action returns what the rule does to matching entries.
+func (r Rule) Action() Action {
+	return r.action
+}
+
+// CreatedAt returns when the rule was created. Rules apply in creation order.
+func (r Rule) CreatedAt() time.Time {
+	return r.createdAt
+}
+
+// Matches reports whether the rule applies to s.
+func (r Rule) Matches(s Subject) bool {
+	if r.match.NamePattern != "" && (r.pattern == nil || !r.pattern.MatchString(s.ChannelName)) {
+		return false
+	}
+	if r.match.Group != "" && !strings.EqualFold(r.match.Group, s.Group) {
+		return false
+	}
+	if r.match.Source != "" && !strings.EqualFold(r.match.Source, s.Source) {
+		return false
+	}
+	return true
+}
+
+// Apply returns s transformed by the rule's action, and false if the rule
+// disables the entry. It does not check that the rule matches s.
+func (r Rule) Apply(s Subject) (Subject, bool) {
+	if r.action.Disable {
+		return s, false
+	}
+	if r.action.Rename != "" {
+		if r.pattern != nil {
+			s.ChannelName = r.pattern.ReplaceAllString(s.ChannelName, r.action.Rename)
+		} else {
+			s.ChannelName = r.action.Rename
+		}
+	}
+	if r.action.Group != "" {
+		s.Group = r.action.Group
+	}
+	if r.action.TVGID != "" {
+		s.TVGID = r.action.TVGID
+	}
+	return s, true
+}
+
+// ApplyAll applies each matching rule in turn, so later rules see the
+// changes of earlier ones. It returns false as soon as a rule disables the
+// entry.
+func ApplyAll(rules []Rule, s Subject) (Subject, bool) {
+	for _, r := range rules {
+		if !r.Matches(s) {
+			continue
+		}
+		var keep bool
+		if s, keep = r.Apply(s); !keep {
+			return s, false
+		}
+	}
+	return s, true
+}
+
+// Sort orders rules by creation time, the order in which they apply,
+// breaking ties by ID.
+func Sort(rules []Rule) {
+	slices.SortStableFunc(rules, func(a, b Rule) int {
+		if c := a.createdAt.Compare(b.createdAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+}
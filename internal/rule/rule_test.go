@@ -0,0 +1,77 @@
+package rule_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/rule"
+)
+
+func TestNewRule(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		match     rule.Match
+		action    rule.Action
+		wantError error
+	}{
+		{name: "no action", match: rule.Match{NamePattern: " HD$ "}, wantError: rule.ErrNoAction},
+		{name: "rename by pattern", match: rule.Match{NamePattern: "^(.*) HD$"}, action: rule.Action{Rename: "$1"}},
+		{name: "disable by group", match: rule.Match{Group: "Adult"}, action: rule.Action{Disable: true}},
+		{name: "no matcher", match: rule.Match{Group: "  "}, action: rule.Action{Disable: true}, wantError: rule.ErrNoMatcher},
+		{name: "invalid pattern", match: rule.Match{NamePattern: "(unclosed"}, action: rule.Action{Disable: true}, wantError: rule.ErrInvalidPattern},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := rule.NewRule(tt.match, tt.action, now)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NewRule() error = %v, want %v", err, tt.wantError)
+			}
+			if tt.wantError != nil {
+				return
+			}
+			if r.ID() == "" || !r.CreatedAt().Equal(now) {
+				t.Errorf("NewRule() = (%q, %v), want a random ID created at %v", r.ID(), r.CreatedAt(), now)
+			}
+		})
+	}
+}
+
+func TestRule_MatchesAndApply(t *testing.T) {
+	now := time.Now()
+	hd, _ := rule.NewRule(rule.Match{NamePattern: `^(.*) HD$`, Source: "NEW_ERA"}, rule.Action{Rename: "$1", TVGID: "sports.es"}, now)
+	adult, _ := rule.NewRule(rule.Match{Group: "adult"}, rule.Action{Disable: true}, now)
+	regroup, _ := rule.NewRule(rule.Match{NamePattern: `^DAZN`}, rule.Action{Group: "Sports"}, now)
+
+	subject := rule.Subject{ChannelName: "DAZN 1 HD", Group: "Movies", Source: "new_era"}
+
+	if !hd.Matches(subject) {
+		t.Error("expected the HD rule to match")
+	}
+	if hd.Matches(rule.Subject{ChannelName: "DAZN 1 HD", Source: "elcano"}) {
+		t.Error("expected the HD rule not to match another source")
+	}
+
+	got, keep := rule.ApplyAll([]rule.Rule{hd, regroup, adult}, subject)
+	if !keep {
+		t.Fatal("expected the entry to be kept")
+	}
+	want := rule.Subject{ChannelName: "DAZN 1", Group: "Sports", Source: "new_era", TVGID: "sports.es"}
+	if got != want {
+		t.Errorf("ApplyAll() = %+v, want %+v", got, want)
+	}
+
+	if _, keep := rule.ApplyAll([]rule.Rule{hd, adult}, rule.Subject{ChannelName: "Late", Group: "Adult"}); keep {
+		t.Error("expected the adult rule to disable the entry")
+	}
+}
+
+func TestReconstructRule_InvalidPattern(t *testing.T) {
+	r := rule.ReconstructRule("aa", rule.Match{NamePattern: "(unclosed"}, rule.Action{Disable: true}, time.Now())
+	if r.Matches(rule.Subject{ChannelName: "(unclosed"}) {
+		t.Error("expected a rule with an invalid pattern to match nothing")
+	}
+}
@@ -0,0 +1,223 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron indicates a cron expression could not be parsed.
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// Schedule decides when a scheduler runs next.
+type Schedule interface {
+	// Next returns the first run time strictly after t.
+	Next(t time.Time) time.Time
+	// String describes the schedule for status reporting.
+	String() string
+}
+
+// intervalSchedule runs at a fixed interval after the previous run.
+type intervalSchedule time.Duration
+
+// Every returns a schedule that runs every d.
+func Every(d time.Duration) Schedule {
+	return intervalSchedule(d)
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(s))
+}
+
+func (s intervalSchedule) String() string {
+	return "every " + time.Duration(s).String()
+}
+
+// cronSchedule is a parsed five-field cron expression. Each field is a
+// bitset of the allowed values.
+type cronSchedule struct {
+	expr                         string
+	minute, hour, dom, month     uint64
+	dow                          uint64
+	domRestricted, dowRestricted bool
+}
+
+// cronField describes the valid range of one cron field.
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard five-field cron expression
+// ("minute hour day-of-month month day-of-week"). Fields accept "*", single
+// values, ranges ("1-5"), steps ("*/15", "0-30/10") and comma-separated
+// lists. Day of week 7 is accepted as Sunday. The @hourly, @daily,
+// @weekly, @monthly and @yearly macros are also supported. As in cron, when
+// both day fields are restricted a day matches if either one does.
+// Times are evaluated in the location of the time passed to Next.
+func ParseCron(expr string) (Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidCron, len(parts))
+	}
+
+	var bits [5]uint64
+	for i, part := range parts {
+		field := cronFields[i]
+		if i == 4 {
+			// Allow 7 as Sunday
+			field.max = 7
+		}
+		b, err := parseCronField(part, field)
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &cronSchedule{
+		expr:          strings.TrimSpace(expr),
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseCronField parses a comma-separated list of cron terms into a bitset.
+func parseCronField(s string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, term := range strings.Split(s, ",") {
+		b, err := parseCronTerm(term, field)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+// parseCronTerm parses a single "*", "n", "a-b" term with an optional "/step".
+func parseCronTerm(term string, field cronField) (uint64, error) {
+	invalid := func() error {
+		return fmt.Errorf("%w: bad %s %q", ErrInvalidCron, field.name, term)
+	}
+
+	rangePart, stepPart, hasStep := strings.Cut(term, "/")
+	step := 1
+	if hasStep {
+		n, err := strconv.Atoi(stepPart)
+		if err != nil || n <= 0 {
+			return 0, invalid()
+		}
+		step = n
+	}
+
+	lo, hi := field.min, field.max
+	switch {
+	case rangePart == "*":
+	case strings.Contains(rangePart, "-"):
+		a, b, _ := strings.Cut(rangePart, "-")
+		var errA, errB error
+		lo, errA = strconv.Atoi(a)
+		hi, errB = strconv.Atoi(b)
+		if errA != nil || errB != nil {
+			return 0, invalid()
+		}
+	default:
+		n, err := strconv.Atoi(rangePart)
+		if err != nil {
+			return 0, invalid()
+		}
+		lo = n
+		if !hasStep {
+			hi = n
+		}
+	}
+
+	if lo < field.min || hi > field.max || lo > hi {
+		return 0, invalid()
+	}
+
+	var bits uint64
+	for v := lo; v <= hi; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+// maxCronSearch bounds the search for the next matching time, so that
+// expressions that can never match (e.g. February 31st) do not loop forever.
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// Next returns the first matching minute strictly after t. It returns the
+// zero time if the expression never matches.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxCronSearch)
+
+	for next.Before(limit) {
+		if s.month&(1<<uint(next.Month())) == 0 {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !s.dayMatches(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if s.hour&(1<<uint(next.Hour())) == 0 {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			continue
+		}
+		if s.minute&(1<<uint(next.Minute())) == 0 {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+func (s *cronSchedule) String() string {
+	return s.expr
+}
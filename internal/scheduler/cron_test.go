@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	from := time.Date(2026, 3, 14, 10, 17, 30, 0, time.UTC) // Saturday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 14, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 30, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)},
+		{"30 4 * * *", time.Date(2026, 3, 15, 4, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+			if schedule.String() != tt.expr {
+				t.Errorf("String() = %q, want %q", schedule.String(), tt.expr)
+			}
+		})
+	}
+}
+
+func TestParseCron_NeverMatches(t *testing.T) {
+	schedule, err := ParseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron() error = %v", err)
+	}
+	if got := schedule.Next(time.Now()); !got.IsZero() {
+		t.Errorf("expected zero time, got %v", got)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every 1h",
+	} {
+		if _, err := ParseCron(expr); !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("ParseCron(%q) error = %v, want ErrInvalidCron", expr, err)
+		}
+	}
+}
+
+func TestEvery(t *testing.T) {
+	from := time.Date(2026, 3, 14, 10, 17, 30, 0, time.UTC)
+	schedule := Every(time.Hour)
+
+	if got := schedule.Next(from); !got.Equal(from.Add(time.Hour)) {
+		t.Errorf("Next() = %v, want %v", got, from.Add(time.Hour))
+	}
+	if got := schedule.String(); got != "every 1h0m0s" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Package scheduler runs periodic background tasks with an explicit
+// start/stop lifecycle and records the outcome of every run.
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Task is the unit of work run on every tick.
+type Task func(ctx context.Context) error
+
+// Stats is a snapshot of a scheduler's run history.
+type Stats struct {
+	Name string
+	// Interval is the fixed run interval, zero for cron schedules.
+	Interval time.Duration
+	// Schedule describes when the scheduler runs, e.g. "every 1h0m0s" or a cron expression.
+	Schedule    string
+	Running     bool
+	Runs        int
+	Successes   int
+	Failures    int
+	LastRun     time.Time
+	LastSuccess time.Time
+	LastFailure time.Time
+	LastError   string
+	// NextRun is when the next run is due, zero while stopped.
+	NextRun time.Time
+}
+
+// Scheduler runs a Task on a Schedule until stopped.
+type Scheduler struct {
+	name     string
+	schedule Schedule
+	task     Task
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	stats  Stats
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a scheduler that runs task every interval once started.
+func New(name string, interval time.Duration, task Task, logger *slog.Logger) *Scheduler {
+	s := NewWithSchedule(name, Every(interval), task, logger)
+	s.stats.Interval = interval
+	return s
+}
+
+// NewWithSchedule creates a scheduler that runs task at the times given by
+// schedule once started.
+func NewWithSchedule(name string, schedule Schedule, task Task, logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		name:     name,
+		schedule: schedule,
+		task:     task,
+		logger:   logger,
+		stats:    Stats{Name: name, Schedule: schedule.String()},
+	}
+}
+
+// Start launches the scheduler loop. The first run happens at the first
+// scheduled time after now, e.g. after one interval has elapsed. Calling Start on a running scheduler is a no-op.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.stats.Running = true
+
+	go s.loop(ctx, s.done)
+}
+
+// Stop cancels the scheduler and waits for any in-flight run to return.
+// Calling Stop on a stopped scheduler is a no-op.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	<-done
+}
+
+// Stats returns a snapshot of the scheduler's run history.
+func (s *Scheduler) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+func (s *Scheduler) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	s.logger.Info("scheduler started", "name", s.name, "schedule", s.schedule.String())
+
+	for {
+		next := s.schedule.Next(time.Now())
+
+		s.mu.Lock()
+		s.stats.NextRun = next
+		s.mu.Unlock()
+
+		if next.IsZero() {
+			s.logger.Warn("schedule has no upcoming runs", "name", s.name, "schedule", s.schedule.String())
+		}
+
+		if !s.wait(ctx, next) {
+			s.mu.Lock()
+			s.stats.Running = false
+			s.stats.NextRun = time.Time{}
+			s.mu.Unlock()
+			s.logger.Info("scheduler stopped", "name", s.name)
+			return
+		}
+
+		// The timer and cancellation can be ready together; don't start
+		// a new run once the scheduler has been stopped.
+		if ctx.Err() == nil {
+			s.run(ctx)
+		}
+	}
+}
+
+// wait blocks until next, or until ctx is cancelled in which case it
+// returns false. A zero next waits for cancellation only.
+func (s *Scheduler) wait(ctx context.Context, next time.Time) bool {
+	if next.IsZero() {
+		<-ctx.Done()
+		return false
+	}
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	s.logger.Info("starting scheduled run", "name", s.name)
+
+	start := time.Now()
+	err := s.task(ctx)
+
+	s.mu.Lock()
+	s.stats.Runs++
+	s.stats.LastRun = start
+	if err != nil {
+		s.stats.Failures++
+		s.stats.LastFailure = start
+		s.stats.LastError = err.Error()
+	} else {
+		s.stats.Successes++
+		s.stats.LastSuccess = start
+		s.stats.LastError = ""
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logger.Error("scheduled run failed", "name", s.name, "error", err, "duration", time.Since(start))
+		return
+	}
+	s.logger.Info("scheduled run completed", "name", s.name, "duration", time.Since(start))
+}
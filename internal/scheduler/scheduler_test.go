@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestScheduler_RecordsOutcomes(t *testing.T) {
+	var calls atomic.Int32
+	task := func(ctx context.Context) error {
+		if calls.Add(1)%2 == 0 {
+			return errors.New("source unreachable")
+		}
+		return nil
+	}
+
+	s := New("refresh", 10*time.Millisecond, task, newTestLogger())
+	s.Start(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for s.Stats().Runs < 4 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	s.Stop()
+
+	stats := s.Stats()
+	if stats.Runs < 4 {
+		t.Fatalf("expected at least 4 runs, got %d", stats.Runs)
+	}
+	if stats.Successes+stats.Failures != stats.Runs {
+		t.Errorf("successes (%d) + failures (%d) != runs (%d)", stats.Successes, stats.Failures, stats.Runs)
+	}
+	if stats.Successes == 0 || stats.Failures == 0 {
+		t.Errorf("expected both successes and failures, got %+v", stats)
+	}
+	if stats.LastSuccess.IsZero() || stats.LastFailure.IsZero() {
+		t.Errorf("expected last success and failure times to be set, got %+v", stats)
+	}
+	if stats.Running {
+		t.Error("expected scheduler to report not running after Stop")
+	}
+}
+
+func TestScheduler_StopWaitsForInFlightRun(t *testing.T) {
+	started := make(chan struct{})
+	var finished atomic.Bool
+	task := func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+		finished.Store(true)
+		return ctx.Err()
+	}
+
+	s := New("slow", 5*time.Millisecond, task, newTestLogger())
+	s.Start(context.Background())
+
+	<-started
+	s.Stop()
+
+	if !finished.Load() {
+		t.Error("Stop returned before the in-flight run finished")
+	}
+}
+
+func TestScheduler_Lifecycle(t *testing.T) {
+	t.Run("stop without start is a no-op", func(t *testing.T) {
+		s := New("idle", time.Hour, func(ctx context.Context) error { return nil }, newTestLogger())
+		s.Stop()
+	})
+
+	t.Run("double start and double stop are safe", func(t *testing.T) {
+		s := New("twice", time.Hour, func(ctx context.Context) error { return nil }, newTestLogger())
+		s.Start(context.Background())
+		s.Start(context.Background())
+		if !s.Stats().Running {
+			t.Error("expected scheduler to be running")
+		}
+		s.Stop()
+		s.Stop()
+	})
+
+	t.Run("parent context cancellation stops the loop", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		s := New("parent", time.Hour, func(ctx context.Context) error { return nil }, newTestLogger())
+		s.Start(ctx)
+		cancel()
+
+		deadline := time.Now().Add(time.Second)
+		for s.Stats().Running && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if s.Stats().Running {
+			t.Error("expected scheduler to stop after parent context cancellation")
+		}
+		s.Stop()
+	})
+}
+
+// scheduleFunc adapts a function to the Schedule interface.
+type scheduleFunc func(t time.Time) time.Time
+
+func (f scheduleFunc) Next(t time.Time) time.Time { return f(t) }
+
+func (f scheduleFunc) String() string { return "custom" }
+
+func TestScheduler_NewWithSchedule(t *testing.T) {
+	var calls atomic.Int32
+	schedule := scheduleFunc(func(t time.Time) time.Time { return t.Add(5 * time.Millisecond) })
+	s := NewWithSchedule("custom", schedule, func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}, newTestLogger())
+
+	if got := s.Stats().Schedule; got != "custom" {
+		t.Errorf("expected schedule %q, got %q", "custom", got)
+	}
+
+	s.Start(context.Background())
+	deadline := time.Now().Add(time.Second)
+	for calls.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if s.Stats().NextRun.IsZero() {
+		t.Error("expected next run to be set while running")
+	}
+	s.Stop()
+
+	if calls.Load() < 2 {
+		t.Fatalf("expected at least 2 runs, got %d", calls.Load())
+	}
+	if !s.Stats().NextRun.IsZero() {
+		t.Error("expected next run to be cleared after Stop")
+	}
+}
+
+func TestScheduler_NeverDueScheduleStops(t *testing.T) {
+	never := scheduleFunc(func(time.Time) time.Time { return time.Time{} })
+	s := NewWithSchedule("never", never, func(ctx context.Context) error {
+		t.Error("task should not run")
+		return nil
+	}, newTestLogger())
+
+	s.Start(context.Background())
+	s.Stop()
+}
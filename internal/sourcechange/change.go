@@ -0,0 +1,100 @@
+// Package sourcechange models the differences between successive refreshes
+// of an upstream Acestream source: which hashes appeared and which went away.
+package sourcechange
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Kind tells whether a hash appeared in or disappeared from a source.
+type Kind string
+
+const (
+	KindAdded   Kind = "added"
+	KindRemoved Kind = "removed"
+)
+
+// Change records a hash appearing in or disappearing from a source.
+// It is an immutable value object.
+type Change struct {
+	source      string
+	channelName string
+	infoHash    string
+	kind        Kind
+	detectedAt  time.Time
+}
+
+// NewChange creates a change with validation.
+// Returns ErrEmptySource, ErrEmptyInfoHash or ErrInvalidKind.
+func NewChange(source, channelName, infoHash string, kind Kind, detectedAt time.Time) (Change, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return Change{}, ErrEmptySource
+	}
+	infoHash = strings.TrimSpace(infoHash)
+	if infoHash == "" {
+		return Change{}, ErrEmptyInfoHash
+	}
+	if kind != KindAdded && kind != KindRemoved {
+		return Change{}, ErrInvalidKind
+	}
+	return Change{
+		source:      source,
+		channelName: strings.TrimSpace(channelName),
+		infoHash:    infoHash,
+		kind:        kind,
+		detectedAt:  detectedAt,
+	}, nil
+}
+
+// ReconstructChange rebuilds a Change from persisted state.
+// Intended for repository adapters only — bypasses validation.
+func ReconstructChange(source, channelName, infoHash string, kind Kind, detectedAt time.Time) Change {
+	return Change{
+		source:      source,
+		channelName: channelName,
+		infoHash:    infoHash,
+		kind:        kind,
+		detectedAt:  detectedAt,
+	}
+}
+
+func (c Change) Source() string        { return c.source }
+func (c Change) ChannelName() string   { return c.channelName }
+func (c Change) InfoHash() string      { return c.infoHash }
+func (c Change) Kind() Kind            { return c.kind }
+func (c Change) DetectedAt() time.Time { return c.detectedAt }
+
+// Diff compares two refreshes of a source, each mapping channel names to
+// hashes, and returns the hashes added to and removed from the source. A
+// hash moving to another channel is both removed and added. Changes are
+// ordered by channel name, then removals first, then hash.
+func Diff(source string, previous, current map[string][]string, now time.Time) []Change {
+	changes := []Change{}
+	for channelName, hashes := range current {
+		for _, h := range hashes {
+			if !slices.Contains(previous[channelName], h) {
+				changes = append(changes, Change{source: source, channelName: channelName, infoHash: h, kind: KindAdded, detectedAt: now})
+			}
+		}
+	}
+	for channelName, hashes := range previous {
+		for _, h := range hashes {
+			if !slices.Contains(current[channelName], h) {
+				changes = append(changes, Change{source: source, channelName: channelName, infoHash: h, kind: KindRemoved, detectedAt: now})
+			}
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b Change) int {
+		return cmp.Or(
+			strings.Compare(a.channelName, b.channelName),
+			-strings.Compare(string(a.kind), string(b.kind)),
+			strings.Compare(a.infoHash, b.infoHash),
+		)
+	})
+	return changes
+}
@@ -0,0 +1,77 @@
+package sourcechange
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewChange(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		source    string
+		infoHash  string
+		kind      Kind
+		wantError error
+	}{
+		{name: "valid addition", source: "new-era", infoHash: "abc", kind: KindAdded},
+		{name: "valid removal", source: "elcano", infoHash: "abc", kind: KindRemoved},
+		{name: "empty source", source: " ", infoHash: "abc", kind: KindAdded, wantError: ErrEmptySource},
+		{name: "empty infohash", source: "new-era", infoHash: "", kind: KindAdded, wantError: ErrEmptyInfoHash},
+		{name: "unknown kind", source: "new-era", infoHash: "abc", kind: "moved", wantError: ErrInvalidKind},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewChange(tt.source, " La 1 ", tt.infoHash, tt.kind, now)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NewChange() error = %v, want %v", err, tt.wantError)
+			}
+			if err == nil && (c.ChannelName() != "La 1" || c.Kind() != tt.kind || !c.DetectedAt().Equal(now)) {
+				t.Errorf("unexpected change %+v", c)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	previous := map[string][]string{
+		"La 1":   {"a1", "a2"},
+		"DAZN 1": {"d1"},
+		"Clan":   {"c1"},
+	}
+	current := map[string][]string{
+		"La 1":   {"a2", "a3"},
+		"DAZN 1": {"d1"},
+		"Neox":   {"c1"},
+	}
+
+	got := Diff("new-era", previous, current, now)
+
+	want := []struct {
+		channelName, infoHash string
+		kind                  Kind
+	}{
+		{"Clan", "c1", KindRemoved},
+		{"La 1", "a1", KindRemoved},
+		{"La 1", "a3", KindAdded},
+		{"Neox", "c1", KindAdded},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Diff() returned %d changes, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		c := got[i]
+		if c.ChannelName() != w.channelName || c.InfoHash() != w.infoHash || c.Kind() != w.kind ||
+			c.Source() != "new-era" || !c.DetectedAt().Equal(now) {
+			t.Errorf("change %d = %+v, want %+v", i, c, w)
+		}
+	}
+
+	if got := Diff("new-era", current, current, now); len(got) != 0 {
+		t.Errorf("expected no changes between identical refreshes, got %+v", got)
+	}
+}
@@ -0,0 +1,14 @@
+package sourcechange
+
+import "errors"
+
+// Domain errors for source change operations.
+var (
+	// Change validation errors
+	ErrEmptySource   = errors.New("change source cannot be empty")
+	ErrEmptyInfoHash = errors.New("change infohash cannot be empty")
+	ErrInvalidKind   = errors.New("change kind must be added or removed")
+
+	// Snapshot errors
+	ErrSnapshotNotFound = errors.New("source snapshot not found")
+)
@@ -0,0 +1,139 @@
+// Package state describes the channel lineup as a single declarative
+// document: the groups, channels with their streams, and override rules the
+// manager should hold. It is read and written as YAML or JSON so a lineup
+// can be kept under version control and applied as a whole.
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/alorle/iptv-manager/internal/yamlcodec"
+)
+
+// Version is the version of the document format.
+const Version = 1
+
+// ErrInvalidDocument is returned for documents that cannot be read or
+// describe an impossible state.
+var ErrInvalidDocument = errors.New("invalid state document")
+
+// Document is the desired state of the lineup. Whatever it does not list is
+// removed when it is applied, except that upstream sources are configured
+// with environment variables and are not part of it.
+type Document struct {
+	Version  int       `json:"version"`
+	Groups   []Group   `json:"groups"`
+	Channels []Channel `json:"channels"`
+	// Overrides are the override rules, in the order they apply.
+	Overrides []Override `json:"overrides"`
+}
+
+// Group is a channel group. Groups are listed in the order of the document.
+type Group struct {
+	// ID defaults to the slug of the name.
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// Channel is a channel and its streams.
+type Channel struct {
+	Name string `json:"name"`
+	// Group is the ID of a group of the document.
+	Group   string   `json:"group,omitempty"`
+	Number  int      `json:"number,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	EPGID   string   `json:"epg_id,omitempty"`
+	// TVGShift moves the guide times of the channel, in hours.
+	TVGShift          float64  `json:"tvg_shift,omitempty"`
+	TranscodeAudio    string   `json:"transcode_audio,omitempty"`
+	TranscodeVideo    string   `json:"transcode_video,omitempty"`
+	Variants          string   `json:"variants,omitempty"`
+	QualityPreference []string `json:"quality_preference,omitempty"`
+	Archived          bool     `json:"archived,omitempty"`
+	Streams           []Stream `json:"streams,omitempty"`
+}
+
+// Stream is a stream of a channel.
+type Stream struct {
+	InfoHash string `json:"info_hash"`
+	// Source defaults to the stream's current source, or manual for new
+	// streams.
+	Source  string `json:"source,omitempty"`
+	Quality string `json:"quality,omitempty"`
+}
+
+// Override is an override rule.
+type Override struct {
+	Match  OverrideMatch  `json:"match"`
+	Action OverrideAction `json:"action"`
+}
+
+// OverrideMatch selects the entries an override applies to.
+type OverrideMatch struct {
+	NamePattern string `json:"name_pattern,omitempty"`
+	Group       string `json:"group,omitempty"`
+	Source      string `json:"source,omitempty"`
+}
+
+// OverrideAction is what an override does to the entries it matches.
+type OverrideAction struct {
+	Rename  string `json:"rename,omitempty"`
+	Group   string `json:"group,omitempty"`
+	TVGID   string `json:"tvg_id,omitempty"`
+	Disable bool   `json:"disable,omitempty"`
+}
+
+// Decode reads a document in YAML or, if it starts with "{", JSON. Unknown
+// fields are rejected so misspelt settings are not silently dropped, and so
+// is an empty document, which would remove everything.
+// Returns ErrInvalidDocument, wrapping the cause, if r does not hold a
+// document of the current version.
+func Decode(r io.Reader) (Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Document{}, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		node, err := yamlcodec.Parse(data)
+		if err != nil {
+			return Document{}, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+		}
+		if _, ok := node.(yamlcodec.Mapping); !ok {
+			return Document{}, fmt.Errorf("%w: expected a mapping of groups, channels and overrides", ErrInvalidDocument)
+		}
+		data = yamlcodec.ToJSON(node)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var doc Document
+	if err := dec.Decode(&doc); err != nil {
+		return Document{}, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	if doc.Version != Version {
+		return Document{}, fmt.Errorf("%w: version must be %d", ErrInvalidDocument, Version)
+	}
+	return doc, nil
+}
+
+// EncodeYAML writes doc to w as YAML.
+func EncodeYAML(w io.Writer, doc Document) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	node, err := yamlcodec.FromJSON(data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(yamlcodec.Marshal(node))
+	return err
+}
@@ -0,0 +1,151 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	t.Run("reads YAML", func(t *testing.T) {
+		input := `# Lineup
+version: 1
+groups:
+  - id: sports
+    name: "Sports: Live"
+  - name: News
+    disabled: true
+channels:
+  - name: DAZN 1   # trailing comment
+    group: sports
+    number: 7
+    tvg_shift: -1.5
+    aliases: [dazn1, dazn-1]
+    tags:
+    - football
+    quality_preference: ['1080p', "720p"]
+    streams:
+      - info_hash: 0123456789abcdef0123456789abcdef01234567
+        quality: 1080p
+  - name: "Channel #2"
+overrides:
+  - match:
+      name_pattern: '^(.*) HD$'
+    action:
+      rename: $1
+`
+		doc, err := Decode(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := Document{
+			Version: 1,
+			Groups:  []Group{{ID: "sports", Name: "Sports: Live"}, {Name: "News", Disabled: true}},
+			Channels: []Channel{
+				{
+					Name:              "DAZN 1",
+					Group:             "sports",
+					Number:            7,
+					TVGShift:          -1.5,
+					Aliases:           []string{"dazn1", "dazn-1"},
+					Tags:              []string{"football"},
+					QualityPreference: []string{"1080p", "720p"},
+					Streams:           []Stream{{InfoHash: "0123456789abcdef0123456789abcdef01234567", Quality: "1080p"}},
+				},
+				{Name: "Channel #2"},
+			},
+			Overrides: []Override{{Match: OverrideMatch{NamePattern: "^(.*) HD$"}, Action: OverrideAction{Rename: "$1"}}},
+		}
+		if !reflect.DeepEqual(doc, want) {
+			t.Errorf("got %+v, want %+v", doc, want)
+		}
+	})
+
+	t.Run("reads JSON", func(t *testing.T) {
+		doc, err := Decode(strings.NewReader(`{"version": 1, "channels": [{"name": "La 1"}]}`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(doc.Channels) != 1 || doc.Channels[0].Name != "La 1" {
+			t.Errorf("unexpected document %+v", doc)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"only comments", "# nothing\n"},
+		{"not a mapping", "- a\n- b\n"},
+		{"missing version", "channels: []\n"},
+		{"unknown version", "version: 2\n"},
+		{"unknown field", "version: 1\nchannel: []\n"},
+		{"wrong type", "version: 1\nchannels: yes\n"},
+		{"duplicate key", "version: 1\nversion: 1\n"},
+		{"tab indent", "version: 1\nchannels:\n\t- name: a\n"},
+		{"block scalar", "version: 1\nchannels:\n  - name: |\n      a\n"},
+		{"anchor", "version: 1\ngroups: &g []\n"},
+		{"several documents", "version: 1\n---\nversion: 1\n"},
+		{"unterminated quote", "version: 1\nchannels:\n  - name: \"a\n"},
+	}
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(tt.input)); !errors.Is(err, ErrInvalidDocument) {
+				t.Errorf("expected ErrInvalidDocument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEncodeYAML(t *testing.T) {
+	doc := Document{
+		Version: 1,
+		Groups:  []Group{{ID: "sports", Name: "Sports: Live"}},
+		Channels: []Channel{{
+			Name:    "Channel #2",
+			Group:   "sports",
+			Aliases: []string{"two"},
+			Streams: []Stream{{InfoHash: "0123456789abcdef0123456789abcdef01234567", Source: "manual"}},
+		}, {
+			Name: "true",
+		}},
+		Overrides: []Override{},
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeYAML(&buf, doc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `version: 1
+groups:
+  - id: sports
+    name: "Sports: Live"
+channels:
+  - name: "Channel #2"
+    group: sports
+    aliases:
+      - two
+    streams:
+      - info_hash: 0123456789abcdef0123456789abcdef01234567
+        source: manual
+  - name: "true"
+overrides: []
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// What is written reads back the same
+	got, err := Decode(strings.NewReader(want))
+	if err != nil {
+		t.Fatalf("failed to decode encoded document: %v", err)
+	}
+	if got.Channels[0].Name != "Channel #2" || got.Channels[1].Name != "true" || got.Groups[0].Name != "Sports: Live" ||
+		got.Channels[0].Streams[0].Source != "manual" {
+		t.Errorf("round trip got %+v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package stream
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// infoHashLength is the length of a hex-encoded infohash or content ID.
+const infoHashLength = 40
+
+// InfoHash identifies an Acestream stream: a 40-character lowercase
+// hexadecimal infohash or content ID.
+type InfoHash string
+
+// ParseInfoHash extracts and normalizes an infohash from whatever format a
+// user has at hand:
+//
+//   - a bare infohash or content ID, in any case
+//   - an acestream:// URI
+//   - a magnet:?xt=urn:btih: link, hex or base32 encoded
+//   - an engine URL under /ace/ with an id, content_id or infohash parameter
+//
+// Surrounding whitespace is ignored.
+// Returns ErrEmptyInfoHash if the input is empty or contains only whitespace.
+// Returns ErrInvalidInfoHash if no valid infohash can be extracted.
+func ParseInfoHash(s string) (InfoHash, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", ErrEmptyInfoHash
+	}
+
+	switch {
+	case hasPrefixFold(s, "acestream://"):
+		s = s[len("acestream://"):]
+		s, _, _ = strings.Cut(s, "?")
+		s = strings.TrimSuffix(s, "/")
+	case hasPrefixFold(s, "magnet:"):
+		return parseMagnet(s)
+	case hasPrefixFold(s, "http://"), hasPrefixFold(s, "https://"):
+		s = engineURLID(s)
+	}
+
+	if len(s) != infoHashLength || !isHex(s) {
+		return "", ErrInvalidInfoHash
+	}
+	return InfoHash(strings.ToLower(s)), nil
+}
+
+// parseMagnet reads the BitTorrent infohash of a magnet link. Base32 hashes,
+// used by some older clients, are converted to hex.
+func parseMagnet(s string) (InfoHash, error) {
+	_, rawQuery, _ := strings.Cut(s, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", ErrInvalidInfoHash
+	}
+	for _, xt := range query["xt"] {
+		if !hasPrefixFold(xt, "urn:btih:") {
+			continue
+		}
+		hash := xt[len("urn:btih:"):]
+		if len(hash) == 32 {
+			decoded, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash))
+			if err != nil {
+				return "", ErrInvalidInfoHash
+			}
+			hash = hex.EncodeToString(decoded)
+		}
+		return ParseInfoHash(hash)
+	}
+	return "", ErrInvalidInfoHash
+}
+
+// engineURLID returns the stream ID carried by an Acestream engine URL, or an
+// empty string if the URL is not one.
+func engineURLID(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || !strings.HasPrefix(u.Path, "/ace/") {
+		return ""
+	}
+	q := u.Query()
+	for _, param := range []string{"id", "content_id", "infohash"} {
+		if id := q.Get(param); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// hasPrefixFold reports whether s begins with prefix, ignoring case.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// isHex reports whether s consists of hexadecimal digits only.
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the infohash as a lowercase hex string.
+func (h InfoHash) String() string {
+	return string(h)
+}
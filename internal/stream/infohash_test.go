@@ -0,0 +1,55 @@
+package stream_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/stream"
+)
+
+func TestParseInfoHash(t *testing.T) {
+	const hash = "94c2fd8fa9b16211252c5e9f0b836d94155b505a"
+
+	tests := []struct {
+		name    string
+		input   string
+		want    stream.InfoHash
+		wantErr error
+	}{
+		{name: "bare hash", input: hash, want: hash},
+		{name: "uppercase hash", input: "94C2FD8FA9B16211252C5E9F0B836D94155B505A", want: hash},
+		{name: "surrounding whitespace", input: "  " + hash + "\n", want: hash},
+		{name: "acestream URI", input: "acestream://" + hash, want: hash},
+		{name: "acestream URI with trailing slash", input: "ACESTREAM://" + hash + "/", want: hash},
+		{name: "magnet link", input: "magnet:?xt=urn:btih:" + hash + "&dn=HBO&tr=udp%3A%2F%2Ftracker", want: hash},
+		{name: "magnet link with base32 hash", input: "magnet:?dn=HBO&xt=urn:btih:STBP3D5JWFRBCJJML2PQXA3NSQKVWUC2", want: hash},
+		{name: "engine getstream URL", input: "http://127.0.0.1:6878/ace/getstream?id=" + hash, want: hash},
+		{name: "engine content_id URL", input: "http://127.0.0.1:6878/ace/getstream?content_id=" + hash, want: hash},
+		{name: "engine manifest URL", input: "https://engine/ace/manifest.m3u8?infohash=" + hash, want: hash},
+		{name: "empty", input: "  ", wantErr: stream.ErrEmptyInfoHash},
+		{name: "too short", input: "abc123", wantErr: stream.ErrInvalidInfoHash},
+		{name: "not hexadecimal", input: "zzc2fd8fa9b16211252c5e9f0b836d94155b505a", wantErr: stream.ErrInvalidInfoHash},
+		{name: "acestream URI without hash", input: "acestream://", wantErr: stream.ErrInvalidInfoHash},
+		{name: "magnet link without btih", input: "magnet:?xt=urn:sha1:" + hash, wantErr: stream.ErrInvalidInfoHash},
+		{name: "magnet link with bad base32", input: "magnet:?xt=urn:btih:" + "11111111111111111111111111111111", wantErr: stream.ErrInvalidInfoHash},
+		{name: "non-engine URL", input: "http://example.com/live?id=" + hash, wantErr: stream.ErrInvalidInfoHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stream.ParseInfoHash(tt.input)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ParseInfoHash() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseInfoHash() unexpected error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseInfoHash() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
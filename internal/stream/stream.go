@@ -8,6 +8,7 @@ import (
 // Domain errors
 var (
 	ErrEmptyInfoHash       = errors.New("infohash cannot be empty")
+	ErrInvalidInfoHash     = errors.New("infohash must be 40 hexadecimal characters")
 	ErrEmptyChannelName    = errors.New("channel name cannot be empty")
 	ErrStreamNotFound      = errors.New("stream not found")
 	ErrStreamAlreadyExists = errors.New("stream already exists")
@@ -17,6 +18,7 @@ const (
 	SourceNewEra  = "new-era"
 	SourceElcano  = "elcano"
 	SourceManual  = "manual"
+	SourceImport  = "import"
 	SourceUnknown = ""
 )
 
@@ -27,13 +29,15 @@ type Stream struct {
 }
 
 // NewStream creates a new Stream with the given infohash and channel name.
-// It validates that both infohash and channelName are not empty and trims whitespace.
+// The infohash is parsed and normalized with ParseInfoHash, so any format it
+// accepts may be given, and the channel name is trimmed.
 // Returns ErrEmptyInfoHash if the infohash is empty or contains only whitespace.
+// Returns ErrInvalidInfoHash if the infohash is malformed.
 // Returns ErrEmptyChannelName if the channelName is empty or contains only whitespace.
 func NewStream(infoHash, channelName, source string) (Stream, error) {
-	trimmedHash := strings.TrimSpace(infoHash)
-	if trimmedHash == "" {
-		return Stream{}, ErrEmptyInfoHash
+	hash, err := ParseInfoHash(infoHash)
+	if err != nil {
+		return Stream{}, err
 	}
 
 	trimmedName := strings.TrimSpace(channelName)
@@ -42,12 +46,35 @@ func NewStream(infoHash, channelName, source string) (Stream, error) {
 	}
 
 	return Stream{
-		infoHash:    trimmedHash,
+		infoHash:    hash.String(),
 		channelName: trimmedName,
 		source:      source,
 	}, nil
 }
 
+// ReconstructStream rebuilds a Stream from persisted state.
+// This is intended for repository adapters only — it bypasses the infohash
+// validation of NewStream so streams stored before it still load.
+func ReconstructStream(infoHash, channelName, source string) Stream {
+	return Stream{
+		infoHash:    infoHash,
+		channelName: channelName,
+		source:      source,
+	}
+}
+
+// MoveTo returns a copy of the stream associated with another channel,
+// keeping its infohash and source. The channel name is trimmed.
+// Returns ErrEmptyChannelName if the channelName is empty or contains only whitespace.
+func (s Stream) MoveTo(channelName string) (Stream, error) {
+	trimmedName := strings.TrimSpace(channelName)
+	if trimmedName == "" {
+		return Stream{}, ErrEmptyChannelName
+	}
+	s.channelName = trimmedName
+	return s, nil
+}
+
 // InfoHash returns the stream's infohash identifier.
 func (s Stream) InfoHash() string {
 	return s.infoHash
@@ -80,6 +80,30 @@ func TestNewStream(t *testing.T) {
 			wantChannelName: "",
 			wantError:       stream.ErrEmptyInfoHash,
 		},
+		{
+			name:            "uppercase infohash is normalized",
+			infoHash:        "94C2FD8FA9B16211252C5E9F0B836D94155B505A",
+			channelName:     "HBO",
+			wantInfoHash:    "94c2fd8fa9b16211252c5e9f0b836d94155b505a",
+			wantChannelName: "HBO",
+			wantError:       nil,
+		},
+		{
+			name:            "acestream URI",
+			infoHash:        "acestream://94c2fd8fa9b16211252c5e9f0b836d94155b505a",
+			channelName:     "HBO",
+			wantInfoHash:    "94c2fd8fa9b16211252c5e9f0b836d94155b505a",
+			wantChannelName: "HBO",
+			wantError:       nil,
+		},
+		{
+			name:            "malformed infohash",
+			infoHash:        "abc123",
+			channelName:     "HBO",
+			wantInfoHash:    "",
+			wantChannelName: "",
+			wantError:       stream.ErrInvalidInfoHash,
+		},
 		{
 			name:            "empty channel name",
 			infoHash:        "94c2fd8fa9b16211252c5e9f0b836d94155b505a",
@@ -168,6 +192,11 @@ func TestDomainErrors(t *testing.T) {
 			err:  stream.ErrEmptyInfoHash,
 			msg:  "infohash cannot be empty",
 		},
+		{
+			name: "ErrInvalidInfoHash",
+			err:  stream.ErrInvalidInfoHash,
+			msg:  "infohash must be 40 hexadecimal characters",
+		},
 		{
 			name: "ErrEmptyChannelName",
 			err:  stream.ErrEmptyChannelName,
@@ -193,3 +222,30 @@ func TestDomainErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestStream_MoveTo(t *testing.T) {
+	s, _ := stream.NewStream("a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2", "HBO", stream.SourceElcano)
+
+	moved, err := s.MoveTo("  HBO HD  ")
+	if err != nil {
+		t.Fatalf("MoveTo() error = %v", err)
+	}
+	if moved.ChannelName() != "HBO HD" || moved.InfoHash() != s.InfoHash() || moved.Source() != stream.SourceElcano {
+		t.Errorf("MoveTo() = %+v, want the stream on HBO HD", moved)
+	}
+	if s.ChannelName() != "HBO" {
+		t.Errorf("MoveTo() changed the original stream to %q", s.ChannelName())
+	}
+
+	if _, err := s.MoveTo("   "); err != stream.ErrEmptyChannelName {
+		t.Errorf("MoveTo() error = %v, want ErrEmptyChannelName", err)
+	}
+}
+
+func TestReconstructStream(t *testing.T) {
+	s := stream.ReconstructStream("legacy-hash", "HBO", stream.SourceManual)
+
+	if s.InfoHash() != "legacy-hash" || s.ChannelName() != "HBO" || s.Source() != stream.SourceManual {
+		t.Errorf("ReconstructStream() = %+v, want the given fields unchanged", s)
+	}
+}
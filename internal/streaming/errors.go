@@ -8,6 +8,10 @@ import (
 	"syscall"
 )
 
+// ErrRangeNotSupported indicates an upstream that did not answer a Range
+// request with the range asked for.
+var ErrRangeNotSupported = errors.New("upstream does not support range requests")
+
 // IsClientDisconnectError reports whether err indicates the remote client
 // closed the connection. These errors are expected during normal IPTV usage
 // (e.g. channel switching) and should not be logged at ERROR level.
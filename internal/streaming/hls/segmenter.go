@@ -0,0 +1,217 @@
+// Package hls remuxes a live MPEG-TS byte stream into an HLS media playlist
+// backed by an in-memory window of TS segments.
+package hls
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
+)
+
+// ErrClosed is returned by Write after the segmenter has been closed.
+var ErrClosed = errors.New("hls segmenter closed")
+
+// Segment is a completed chunk of the transport stream.
+type Segment struct {
+	Sequence uint64
+	Duration time.Duration
+	Data     []byte
+}
+
+// Segmenter cuts an incoming MPEG-TS stream into segments of roughly the
+// target duration and keeps the most recent ones for serving.
+//
+// Segment boundaries are aligned to packets carrying the random access
+// indicator (usually keyframes) once the target duration has elapsed. If the
+// stream never signals random access, a segment is cut unconditionally at
+// twice the target duration. Durations are measured by wall clock, which is
+// accurate for live sources that arrive at playback rate.
+type Segmenter struct {
+	target    time.Duration
+	retention int
+	now       func() time.Time
+
+	mu           sync.Mutex
+	pending      []byte
+	current      []byte
+	currentStart time.Time
+	segments     []Segment
+	nextSeq      uint64
+	closed       bool
+	err          error
+	ready        chan struct{}
+	readyOnce    sync.Once
+}
+
+// New creates a segmenter that targets segments of the given duration and
+// retains the given number of completed segments.
+func New(target time.Duration, retention int) *Segmenter {
+	return NewWithClock(target, retention, time.Now)
+}
+
+// NewWithClock is like New but uses the given clock, for testing.
+func NewWithClock(target time.Duration, retention int, now func() time.Time) *Segmenter {
+	if retention < 1 {
+		retention = 1
+	}
+	return &Segmenter{
+		target:    target,
+		retention: retention,
+		now:       now,
+		ready:     make(chan struct{}),
+	}
+}
+
+// Write consumes transport stream bytes. Partial packets are buffered until
+// complete and bytes outside packet sync are discarded.
+func (s *Segmenter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, ErrClosed
+	}
+
+	s.pending = append(s.pending, p...)
+	for len(s.pending) >= mpegts.PacketSize {
+		if s.pending[0] != mpegts.SyncByte {
+			next := bytes.IndexByte(s.pending[1:], mpegts.SyncByte)
+			if next < 0 {
+				s.pending = s.pending[:0]
+				break
+			}
+			s.pending = s.pending[next+1:]
+			continue
+		}
+		s.addPacket(s.pending[:mpegts.PacketSize])
+		s.pending = s.pending[mpegts.PacketSize:]
+	}
+	// Compact so the backing array does not grow without bound.
+	s.pending = append([]byte(nil), s.pending...)
+
+	return len(p), nil
+}
+
+func (s *Segmenter) addPacket(pkt []byte) {
+	now := s.now()
+	if len(s.current) > 0 {
+		elapsed := now.Sub(s.currentStart)
+		if elapsed >= 2*s.target || (elapsed >= s.target && mpegts.IsRandomAccess(pkt)) {
+			s.cut(now)
+		}
+	}
+	if len(s.current) == 0 {
+		s.currentStart = now
+	}
+	s.current = append(s.current, pkt...)
+}
+
+// cut completes the current segment. The caller must hold s.mu.
+func (s *Segmenter) cut(now time.Time) {
+	s.segments = append(s.segments, Segment{
+		Sequence: s.nextSeq,
+		Duration: now.Sub(s.currentStart),
+		Data:     s.current,
+	})
+	s.nextSeq++
+	s.current = nil
+	if len(s.segments) > s.retention {
+		s.segments = append([]Segment(nil), s.segments[len(s.segments)-s.retention:]...)
+	}
+	s.readyOnce.Do(func() { close(s.ready) })
+}
+
+// Close flushes the in-progress segment and marks the stream as ended.
+func (s *Segmenter) Close() {
+	s.CloseWithError(nil)
+}
+
+// CloseWithError flushes the in-progress segment and marks the stream as
+// ended with the given error.
+func (s *Segmenter) CloseWithError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	if len(s.current) > 0 {
+		s.cut(s.now())
+	}
+	s.closed = true
+	s.err = err
+	s.readyOnce.Do(func() { close(s.ready) })
+}
+
+// Ready is closed once the first segment is available or the segmenter has
+// been closed.
+func (s *Segmenter) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Err returns the error the segmenter was closed with, if any.
+func (s *Segmenter) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Segment returns the retained segment with the given sequence number.
+func (s *Segmenter) Segment(seq uint64) (Segment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, seg := range s.segments {
+		if seg.Sequence == seq {
+			return seg, true
+		}
+	}
+	return Segment{}, false
+}
+
+// SegmentCount returns the number of retained segments.
+func (s *Segmenter) SegmentCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.segments)
+}
+
+// Playlist renders the retained segments as an HLS media playlist.
+// segmentURI maps a sequence number to the URI clients fetch it from.
+func (s *Segmenter) Playlist(segmentURI func(seq uint64) string) string {
+	s.mu.Lock()
+	segments := append([]Segment(nil), s.segments...)
+	closed := s.closed
+	s.mu.Unlock()
+
+	target := s.target
+	for _, seg := range segments {
+		target = max(target, seg.Duration)
+	}
+	var firstSeq uint64
+	if len(segments) > 0 {
+		firstSeq = segments[0].Sequence
+	}
+
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	fmt.Fprintf(&b, "#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(target.Seconds())))
+	fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", firstSeq)
+	for _, seg := range segments {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%s\n", seg.Duration.Seconds(), segmentURI(seg.Sequence))
+	}
+	if closed {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	}
+	return b.String()
+}
+
+var _ io.Writer = (*Segmenter)(nil)
@@ -0,0 +1,164 @@
+package hls
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alorle/iptv-manager/internal/streaming/mpegts"
+)
+
+// packet builds a TS packet, optionally flagged as a random access point.
+func packet(randomAccess bool) []byte {
+	pkt := make([]byte, mpegts.PacketSize)
+	pkt[0] = mpegts.SyncByte
+	if randomAccess {
+		pkt[3] = 0x30 // adaptation field followed by payload
+		pkt[4] = 1
+		pkt[5] = 0x40
+	} else {
+		pkt[3] = 0x10 // payload only
+	}
+	return pkt
+}
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestSegmenter(t *testing.T) {
+	t.Run("cuts at random access point after target duration", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		s := NewWithClock(2*time.Second, 5, clock.Now)
+
+		_, _ = s.Write(packet(true))
+		clock.now = clock.now.Add(time.Second)
+		_, _ = s.Write(packet(true)) // too early to cut
+		clock.now = clock.now.Add(time.Second)
+		_, _ = s.Write(packet(false)) // target reached but not a random access point
+		if s.SegmentCount() != 0 {
+			t.Fatalf("expected no segments yet, got %d", s.SegmentCount())
+		}
+
+		_, _ = s.Write(packet(true))
+		if s.SegmentCount() != 1 {
+			t.Fatalf("expected 1 segment, got %d", s.SegmentCount())
+		}
+		seg, ok := s.Segment(0)
+		if !ok {
+			t.Fatal("expected segment 0 to exist")
+		}
+		if len(seg.Data) != 3*mpegts.PacketSize {
+			t.Errorf("expected segment of 3 packets, got %d bytes", len(seg.Data))
+		}
+		if seg.Duration != 2*time.Second {
+			t.Errorf("expected duration 2s, got %v", seg.Duration)
+		}
+		select {
+		case <-s.Ready():
+		default:
+			t.Error("expected Ready to be closed after the first segment")
+		}
+	})
+
+	t.Run("forces a cut at twice the target without random access", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		s := NewWithClock(time.Second, 5, clock.Now)
+
+		_, _ = s.Write(packet(false))
+		clock.now = clock.now.Add(2 * time.Second)
+		_, _ = s.Write(packet(false))
+
+		if s.SegmentCount() != 1 {
+			t.Errorf("expected forced cut, got %d segments", s.SegmentCount())
+		}
+	})
+
+	t.Run("reassembles packets split across writes and resyncs", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		s := NewWithClock(time.Second, 5, clock.Now)
+
+		stream := append([]byte{0x00, 0x01}, packet(true)...)
+		stream = append(stream, packet(false)...)
+		_, _ = s.Write(stream[:100])
+		_, _ = s.Write(stream[100:])
+		s.Close()
+
+		seg, ok := s.Segment(0)
+		if !ok {
+			t.Fatal("expected flushed segment on close")
+		}
+		if len(seg.Data) != 2*mpegts.PacketSize {
+			t.Errorf("expected 2 packets, got %d bytes", len(seg.Data))
+		}
+		if seg.Data[0] != mpegts.SyncByte || seg.Data[mpegts.PacketSize] != mpegts.SyncByte {
+			t.Error("expected segment to start on packet boundaries")
+		}
+	})
+
+	t.Run("retains only the most recent segments", func(t *testing.T) {
+		clock := &fakeClock{now: time.Unix(0, 0)}
+		s := NewWithClock(time.Second, 2, clock.Now)
+
+		for range 5 {
+			_, _ = s.Write(packet(true))
+			clock.now = clock.now.Add(time.Second)
+		}
+
+		if s.SegmentCount() != 2 {
+			t.Fatalf("expected 2 retained segments, got %d", s.SegmentCount())
+		}
+		if _, ok := s.Segment(0); ok {
+			t.Error("expected segment 0 to be evicted")
+		}
+		if _, ok := s.Segment(3); !ok {
+			t.Error("expected segment 3 to be retained")
+		}
+	})
+
+	t.Run("rejects writes after close and reports the error", func(t *testing.T) {
+		s := New(time.Second, 2)
+		cause := errors.New("engine gone")
+		s.CloseWithError(cause)
+
+		if _, err := s.Write(packet(true)); !errors.Is(err, ErrClosed) {
+			t.Errorf("expected ErrClosed, got %v", err)
+		}
+		if !errors.Is(s.Err(), cause) {
+			t.Errorf("expected Err() = %v, got %v", cause, s.Err())
+		}
+	})
+}
+
+func TestSegmenter_Playlist(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	s := NewWithClock(2*time.Second, 2, clock.Now)
+
+	for range 4 {
+		_, _ = s.Write(packet(true))
+		clock.now = clock.now.Add(3 * time.Second)
+	}
+	uri := func(seq uint64) string { return "/seg/" + strconv.FormatUint(seq, 10) + ".ts" }
+
+	want := "#EXTM3U\n" +
+		"#EXT-X-VERSION:3\n" +
+		"#EXT-X-TARGETDURATION:3\n" +
+		"#EXT-X-MEDIA-SEQUENCE:1\n" +
+		"#EXTINF:3.000,\n/seg/1.ts\n" +
+		"#EXTINF:3.000,\n/seg/2.ts\n"
+	if got := s.Playlist(uri); got != want {
+		t.Errorf("Playlist() =\n%s\nwant\n%s", got, want)
+	}
+
+	s.Close()
+	if got := s.Playlist(uri); !strings.HasSuffix(got, "#EXT-X-ENDLIST\n") {
+		t.Errorf("expected ENDLIST after close, got:\n%s", got)
+	}
+	seg, _ := s.Segment(3)
+	if !bytes.Equal(seg.Data, packet(true)) {
+		t.Error("expected final segment to hold the last packet")
+	}
+}
@@ -0,0 +1,113 @@
+package streaming
+
+import (
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// latencySamples is how many of the most recent writes the latency
+// percentiles are computed over.
+const latencySamples = 256
+
+// WriteLatencyStats summarizes how long writes through a LatencyWriter took.
+type WriteLatencyStats struct {
+	Writes          int64
+	P50             time.Duration // percentiles over the most recent writes
+	P95             time.Duration
+	P99             time.Duration
+	Max             time.Duration // slowest write since the writer was created
+	SlowWrites      int64         // writes that took at least the slow threshold
+	ConsecutiveSlow int           // slow writes in a row up to the last one
+	Stalls          int64         // runs of consecutive slow writes that reached the stall count
+}
+
+// LatencyWriter wraps an io.Writer and records how long each write takes.
+// Unlike TimeoutWriter, which only tells whether a write timed out, it tells
+// how close a client is to falling behind: a run of consecutive slow writes
+// is reported as a stall before any write actually times out.
+type LatencyWriter struct {
+	dst        io.Writer
+	slow       time.Duration
+	stallAfter int
+	onStall    func(WriteLatencyStats)
+
+	mu          sync.Mutex
+	samples     [latencySamples]time.Duration
+	writes      int64
+	max         time.Duration
+	slowWrites  int64
+	consecutive int
+	stalls      int64
+}
+
+// NewLatencyWriter creates a writer timing writes to dst. A write taking at
+// least slow counts as slow, and onStall, if not nil, is called once for each
+// run of stallAfter consecutive slow writes. onStall is called from Write
+// after the write returned.
+func NewLatencyWriter(dst io.Writer, slow time.Duration, stallAfter int, onStall func(WriteLatencyStats)) *LatencyWriter {
+	return &LatencyWriter{
+		dst:        dst,
+		slow:       slow,
+		stallAfter: stallAfter,
+		onStall:    onStall,
+	}
+}
+
+// Write writes p to the underlying writer, recording how long it took.
+func (w *LatencyWriter) Write(p []byte) (int, error) {
+	start := time.Now()
+	n, err := w.dst.Write(p)
+	if stalled := w.record(time.Since(start)); stalled && w.onStall != nil {
+		w.onStall(w.Stats())
+	}
+	return n, err
+}
+
+// record accounts a write that took d and reports whether it completed a stall.
+func (w *LatencyWriter) record(d time.Duration) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.samples[w.writes%latencySamples] = d
+	w.writes++
+	w.max = max(w.max, d)
+
+	if d < w.slow {
+		w.consecutive = 0
+		return false
+	}
+	w.slowWrites++
+	w.consecutive++
+	if w.consecutive != w.stallAfter {
+		return false
+	}
+	w.stalls++
+	return true
+}
+
+// Stats returns the write latency statistics so far.
+func (w *LatencyWriter) Stats() WriteLatencyStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := WriteLatencyStats{
+		Writes:          w.writes,
+		Max:             w.max,
+		SlowWrites:      w.slowWrites,
+		ConsecutiveSlow: w.consecutive,
+		Stalls:          w.stalls,
+	}
+	if w.writes == 0 {
+		return stats
+	}
+
+	recent := slices.Clone(w.samples[:min(w.writes, latencySamples)])
+	slices.Sort(recent)
+	percentile := func(p int) time.Duration {
+		return recent[(len(recent)-1)*p/100]
+	}
+	stats.P50, stats.P95, stats.P99 = percentile(50), percentile(95), percentile(99)
+	return stats
+}
@@ -0,0 +1,72 @@
+package streaming
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+// sleepyWriter takes delay to accept each write.
+type sleepyWriter struct {
+	delay time.Duration
+}
+
+func (w *sleepyWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(p), nil
+}
+
+func TestLatencyWriter(t *testing.T) {
+	t.Run("passes writes through", func(t *testing.T) {
+		var buf bytes.Buffer
+		lw := NewLatencyWriter(&buf, time.Second, 3, nil)
+
+		n, err := lw.Write([]byte("chunk"))
+		if err != nil || n != 5 || buf.String() != "chunk" {
+			t.Fatalf("Write() = %d, %v with buffer %q", n, err, buf.String())
+		}
+		if stats := lw.Stats(); stats.Writes != 1 || stats.SlowWrites != 0 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("reports each run of consecutive slow writes once", func(t *testing.T) {
+		var stalls []WriteLatencyStats
+		lw := NewLatencyWriter(&sleepyWriter{delay: 5 * time.Millisecond}, time.Millisecond, 2, func(stats WriteLatencyStats) {
+			stalls = append(stalls, stats)
+		})
+
+		for range 3 {
+			_, _ = lw.Write([]byte("x"))
+		}
+		if len(stalls) != 1 || stalls[0].ConsecutiveSlow != 2 || stalls[0].Stalls != 1 {
+			t.Fatalf("expected one stall after two slow writes, got %+v", stalls)
+		}
+
+		// A fast write ends the run, so the next slow ones are a new stall
+		lw.record(0)
+		lw.record(time.Second)
+		if !lw.record(time.Second) {
+			t.Error("expected a new run of slow writes to be reported")
+		}
+		if stats := lw.Stats(); stats.SlowWrites != 5 || stats.Stalls != 2 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("computes percentiles over recent writes", func(t *testing.T) {
+		lw := NewLatencyWriter(io.Discard, time.Hour, 1, nil)
+		for i := 1; i <= 100; i++ {
+			lw.record(time.Duration(i) * time.Millisecond)
+		}
+
+		stats := lw.Stats()
+		if stats.P50 != 50*time.Millisecond || stats.P95 != 95*time.Millisecond || stats.P99 != 99*time.Millisecond {
+			t.Errorf("unexpected percentiles: p50=%v p95=%v p99=%v", stats.P50, stats.P95, stats.P99)
+		}
+		if stats.Max != 100*time.Millisecond {
+			t.Errorf("expected max 100ms, got %v", stats.Max)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package mpegts
+
+import "bytes"
+
+// Aligner cuts a transport stream delivered in arbitrary pieces into runs of
+// whole packets, so that every piece it returns starts and ends on a packet
+// boundary. The tail of a packet cut off at the end of a piece is held back
+// and returned with the next one.
+//
+// The Aligner locks on to the stream once it finds two consecutive sync
+// bytes a packet apart, dropping the bytes before them. If a piece no longer
+// starts on a sync byte it locks on again, and data in which no packet can
+// be found is passed through unchanged, if delayed by up to two packets, so
+// that streams other than MPEG-TS are not affected. It is not safe for
+// concurrent use.
+type Aligner struct {
+	rest   []byte
+	synced bool
+}
+
+// Align returns the whole packets of the data held back and p, in a newly
+// allocated slice. The result is empty while a packet is still incomplete.
+func (a *Aligner) Align(p []byte) []byte {
+	buf := make([]byte, 0, len(a.rest)+len(p))
+	buf = append(append(buf, a.rest...), p...)
+	a.rest = a.rest[:0]
+	if len(buf) == 0 {
+		return buf
+	}
+
+	start := 0
+	if !a.synced || buf[0] != SyncByte {
+		start = syncOffset(buf)
+		if start < 0 && len(buf) <= 2*PacketSize && bytes.IndexByte(buf, SyncByte) >= 0 {
+			// Too short to tell whether a packet starts there
+			a.rest = append(a.rest, buf...)
+			return buf[:0]
+		}
+		if start < 0 {
+			a.synced = false
+			return buf
+		}
+		a.synced = true
+	}
+	end := start + (len(buf)-start)/PacketSize*PacketSize
+	a.rest = append(a.rest, buf[end:]...)
+	return buf[start:end]
+}
+
+// Flush returns the data held back, such as the start of a packet cut off
+// at the end of the stream, and forgets it.
+func (a *Aligner) Flush() []byte {
+	rest := append([]byte(nil), a.rest...)
+	a.rest = a.rest[:0]
+	return rest
+}
+
+// Synced reports whether the Aligner is locked on to a transport stream.
+func (a *Aligner) Synced() bool {
+	return a.synced
+}
+
+// syncOffset returns the offset of the first sync byte in p followed by
+// another one a packet later, or -1 if there is none.
+func syncOffset(p []byte) int {
+	for i := 0; i+PacketSize < len(p); i++ {
+		if p[i] == SyncByte && p[i+PacketSize] == SyncByte {
+			return i
+		}
+	}
+	return -1
+}
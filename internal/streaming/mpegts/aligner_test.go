@@ -0,0 +1,79 @@
+package mpegts
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAligner(t *testing.T) {
+	var ts []byte
+	for i := range 4 {
+		ts = append(ts, tsPacket(uint16(0x100+i), true, nil)...)
+	}
+
+	t.Run("returns whole packets of pieces cut anywhere", func(t *testing.T) {
+		var a Aligner
+		var got []byte
+		for _, cut := range [][2]int{{0, 400}, {400, 500}, {500, len(ts)}} {
+			out := a.Align(ts[cut[0]:cut[1]])
+			if len(out)%PacketSize != 0 {
+				t.Fatalf("Align() returned %d bytes, not whole packets", len(out))
+			}
+			got = append(got, out...)
+		}
+		if !bytes.Equal(got, ts) {
+			t.Error("expected the stream back unchanged")
+		}
+		if !a.Synced() {
+			t.Error("expected the aligner to be synced")
+		}
+	})
+
+	t.Run("drops bytes before the first packet", func(t *testing.T) {
+		var a Aligner
+		got := a.Align(ts[100:])
+		if !bytes.Equal(got, ts[PacketSize:]) {
+			t.Errorf("expected the packets after the cut one, got %d bytes", len(got))
+		}
+	})
+
+	t.Run("locks on again after a broken packet", func(t *testing.T) {
+		var a Aligner
+		a.Align(ts[:PacketSize*2])
+		got := a.Align(append([]byte{1, 2, 3}, ts[PacketSize*2:]...))
+		if !bytes.Equal(got, ts[PacketSize*2:]) {
+			t.Errorf("expected the packets after the broken bytes, got %d bytes", len(got))
+		}
+	})
+
+	t.Run("passes data other than MPEG-TS through", func(t *testing.T) {
+		var a Aligner
+		if got := a.Align([]byte("hello")); string(got) != "hello" {
+			t.Errorf("Align() = %q, want %q", got, "hello")
+		}
+		if a.Synced() {
+			t.Error("expected the aligner not to be synced")
+		}
+	})
+
+	t.Run("holds back a piece too short to find a packet in", func(t *testing.T) {
+		var a Aligner
+		if got := a.Align(ts[:100]); len(got) != 0 {
+			t.Errorf("expected nothing yet, got %d bytes", len(got))
+		}
+		if got := a.Align(ts[100:]); !bytes.Equal(got, ts) {
+			t.Errorf("expected the whole stream once synced, got %d bytes", len(got))
+		}
+	})
+
+	t.Run("flushes the tail of a cut off packet", func(t *testing.T) {
+		var a Aligner
+		a.Align(ts[:len(ts)-10])
+		if got := a.Flush(); !bytes.Equal(got, ts[3*PacketSize:len(ts)-10]) {
+			t.Errorf("Flush() returned %d bytes, want %d", len(got), PacketSize-10)
+		}
+		if got := a.Flush(); len(got) != 0 {
+			t.Errorf("expected nothing after a flush, got %d bytes", len(got))
+		}
+	})
+}
@@ -0,0 +1,378 @@
+// Package mpegts inspects an MPEG transport stream to report the codecs,
+// video resolution, audio tracks and bitrate of its first program, much like
+// a minimal ffprobe.
+package mpegts
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// PacketSize is the size of an MPEG-TS packet in bytes.
+	PacketSize = 188
+
+	// SyncByte is the first byte of every MPEG-TS packet.
+	SyncByte = 0x47
+
+	// patPID carries the program association table.
+	patPID = 0x0000
+
+	// pcrClock is the frequency of the program clock reference.
+	pcrClock = 27_000_000
+
+	// maxVideoBuffer bounds how much video elementary stream data is kept
+	// while looking for a sequence header.
+	maxVideoBuffer = 1 << 20
+)
+
+// VideoInfo describes the video elementary stream of a program.
+type VideoInfo struct {
+	PID    uint16
+	Codec  string
+	Width  int // 0 until a sequence header has been parsed
+	Height int
+}
+
+// AudioTrack describes an audio elementary stream of a program.
+type AudioTrack struct {
+	PID      uint16
+	Codec    string
+	Language string // ISO 639 code, empty if not signalled
+}
+
+// MediaInfo is what the analyzer has learned about the stream so far.
+type MediaInfo struct {
+	Video    *VideoInfo
+	Audio    []AudioTrack
+	Bitrate  int64         // bits per second measured over Duration, 0 if unknown
+	Duration time.Duration // stream time covered by program clock references
+	Bytes    int64         // transport stream bytes analyzed
+}
+
+// Analyzer consumes a transport stream through Write and collects MediaInfo.
+// Only the first program listed in the program association table is
+// inspected. It is safe for concurrent use.
+type Analyzer struct {
+	mu sync.Mutex
+
+	pending  []byte
+	sections map[uint16][]byte
+
+	pmtPID  int
+	pmtSeen bool
+	pcrPID  int
+
+	video    *VideoInfo
+	videoES  []byte
+	videoPES bool
+	audio    []AudioTrack
+
+	bytes         int64
+	firstPCR      int64
+	firstPCRBytes int64
+	lastPCR       int64
+	lastPCRBytes  int64
+	hasPCR        bool
+}
+
+// NewAnalyzer creates an analyzer with nothing learned yet.
+func NewAnalyzer() *Analyzer {
+	return &Analyzer{
+		sections: make(map[uint16][]byte),
+		pmtPID:   -1,
+		pcrPID:   -1,
+	}
+}
+
+// Write consumes transport stream bytes. Partial packets are buffered until
+// complete and bytes outside packet sync are discarded. It never fails.
+func (a *Analyzer) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pending = append(a.pending, p...)
+	for len(a.pending) >= PacketSize {
+		if a.pending[0] != SyncByte {
+			next := bytes.IndexByte(a.pending[1:], SyncByte)
+			if next < 0 {
+				a.pending = a.pending[:0]
+				break
+			}
+			a.pending = a.pending[next+1:]
+			continue
+		}
+		a.packet(a.pending[:PacketSize])
+		a.pending = a.pending[PacketSize:]
+	}
+	// Compact so the backing array does not grow without bound.
+	a.pending = append([]byte(nil), a.pending...)
+
+	return len(p), nil
+}
+
+// Info returns a snapshot of what has been learned so far.
+func (a *Analyzer) Info() MediaInfo {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info := MediaInfo{
+		Audio:    append([]AudioTrack{}, a.audio...),
+		Duration: a.pcrSpan(),
+		Bytes:    a.bytes,
+	}
+	if a.video != nil {
+		v := *a.video
+		info.Video = &v
+	}
+	if info.Duration > 0 {
+		info.Bitrate = int64(float64(a.lastPCRBytes-a.firstPCRBytes) * 8 / info.Duration.Seconds())
+	}
+	return info
+}
+
+// Complete reports whether the program's streams are known, the video
+// resolution has been found and at least minDuration of stream time has been
+// seen to measure the bitrate over.
+func (a *Analyzer) Complete(minDuration time.Duration) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.pmtSeen {
+		return false
+	}
+	if a.video != nil && a.video.Width == 0 {
+		return false
+	}
+	return a.pcrSpan() >= minDuration
+}
+
+func (a *Analyzer) pcrSpan() time.Duration {
+	if !a.hasPCR || a.lastPCR <= a.firstPCR {
+		return 0
+	}
+	return time.Duration((a.lastPCR - a.firstPCR) * int64(time.Second) / pcrClock)
+}
+
+// packet processes a single 188-byte packet. The caller must hold a.mu.
+func (a *Analyzer) packet(pkt []byte) {
+	a.bytes += PacketSize
+
+	pid := uint16(pkt[1]&0x1F)<<8 | uint16(pkt[2])
+	unitStart := pkt[1]&0x40 != 0
+	adaptationControl := (pkt[3] >> 4) & 0x3
+
+	payload := pkt[4:]
+	if adaptationControl == 2 || adaptationControl == 3 {
+		length := int(pkt[4])
+		if int(pid) == a.pcrPID && length > 0 {
+			a.readPCR(pkt[5 : 5+min(length, PacketSize-5)])
+		}
+		if 5+length > PacketSize {
+			return
+		}
+		payload = pkt[5+length:]
+	}
+	if adaptationControl == 0 || adaptationControl == 2 || len(payload) == 0 {
+		return
+	}
+
+	switch {
+	case pid == patPID:
+		a.section(pid, unitStart, payload, a.parsePAT)
+	case int(pid) == a.pmtPID && !a.pmtSeen:
+		a.section(pid, unitStart, payload, a.parsePMT)
+	case a.video != nil && pid == a.video.PID && a.video.Width == 0:
+		a.videoPayload(unitStart, payload)
+	}
+}
+
+// readPCR records the program clock reference in an adaptation field, given
+// the field without its length byte.
+func (a *Analyzer) readPCR(field []byte) {
+	if len(field) < 7 || field[0]&0x10 == 0 {
+		return
+	}
+	base := int64(field[1])<<25 | int64(field[2])<<17 | int64(field[3])<<9 | int64(field[4])<<1 | int64(field[5])>>7
+	ext := int64(field[5]&0x01)<<8 | int64(field[6])
+	pcr := base*300 + ext
+
+	// Start over on the first PCR and whenever the clock jumps backwards
+	// (wrap-around or discontinuity).
+	if !a.hasPCR || pcr < a.lastPCR {
+		a.firstPCR, a.firstPCRBytes = pcr, a.bytes
+		a.hasPCR = true
+	}
+	a.lastPCR, a.lastPCRBytes = pcr, a.bytes
+}
+
+// section reassembles a PSI section carried on pid and calls parse once it is
+// complete.
+func (a *Analyzer) section(pid uint16, unitStart bool, payload []byte, parse func([]byte)) {
+	if unitStart {
+		pointer := int(payload[0])
+		if 1+pointer >= len(payload) {
+			return
+		}
+		a.sections[pid] = append([]byte(nil), payload[1+pointer:]...)
+	} else if buf, ok := a.sections[pid]; ok {
+		a.sections[pid] = append(buf, payload...)
+	} else {
+		return
+	}
+
+	buf := a.sections[pid]
+	if len(buf) < 3 {
+		return
+	}
+	length := 3 + (int(buf[1]&0x0F)<<8 | int(buf[2]))
+	if len(buf) < length {
+		return
+	}
+	delete(a.sections, pid)
+	parse(buf[:length])
+}
+
+// parsePAT picks the PMT PID of the first program.
+func (a *Analyzer) parsePAT(section []byte) {
+	if section[0] != 0x00 || len(section) < 12 {
+		return
+	}
+	for i := 8; i+4 <= len(section)-4; i += 4 {
+		program := uint16(section[i])<<8 | uint16(section[i+1])
+		if program == 0 {
+			continue // network information table
+		}
+		a.pmtPID = int(section[i+2]&0x1F)<<8 | int(section[i+3])
+		return
+	}
+}
+
+// parsePMT collects the elementary streams of the program.
+func (a *Analyzer) parsePMT(section []byte) {
+	if section[0] != 0x02 || len(section) < 16 {
+		return
+	}
+	a.pcrPID = int(section[8]&0x1F)<<8 | int(section[9])
+	programInfoLength := int(section[10]&0x0F)<<8 | int(section[11])
+
+	end := len(section) - 4
+	for i := 12 + programInfoLength; i+5 <= end; {
+		streamType := section[i]
+		pid := uint16(section[i+1]&0x1F)<<8 | uint16(section[i+2])
+		infoLength := int(section[i+3]&0x0F)<<8 | int(section[i+4])
+		descriptors := section[i+5 : min(i+5+infoLength, end)]
+		i += 5 + infoLength
+
+		if codec, ok := videoCodecs[streamType]; ok {
+			if a.video == nil {
+				a.video = &VideoInfo{PID: pid, Codec: codec}
+			}
+			continue
+		}
+		if codec := audioCodec(streamType, descriptors); codec != "" {
+			a.audio = append(a.audio, AudioTrack{PID: pid, Codec: codec, Language: language(descriptors)})
+		}
+	}
+	a.pmtSeen = true
+}
+
+// videoPayload accumulates video elementary stream data and looks for a
+// sequence header to read the resolution from.
+func (a *Analyzer) videoPayload(unitStart bool, payload []byte) {
+	if unitStart {
+		// Skip the PES header: start code, stream id, length, flags and
+		// optional fields.
+		if len(payload) < 9 || payload[0] != 0 || payload[1] != 0 || payload[2] != 1 {
+			return
+		}
+		headerEnd := 9 + int(payload[8])
+		if headerEnd > len(payload) {
+			return
+		}
+		payload = payload[headerEnd:]
+		a.videoPES = true
+	}
+	if !a.videoPES {
+		return
+	}
+
+	a.videoES = append(a.videoES, payload...)
+	if width, height, ok := findResolution(a.video.Codec, a.videoES); ok {
+		a.video.Width, a.video.Height = width, height
+		a.videoES = nil
+		return
+	}
+	if len(a.videoES) > maxVideoBuffer {
+		a.videoES = append([]byte(nil), a.videoES[len(a.videoES)-4:]...)
+	}
+}
+
+// videoCodecs maps video stream types to codec names.
+var videoCodecs = map[byte]string{
+	0x01: "mpeg1video",
+	0x02: "mpeg2video",
+	0x10: "mpeg4",
+	0x1B: "h264",
+	0x24: "hevc",
+	0xEA: "vc1",
+}
+
+// audioCodec names the codec of an audio stream type, looking at the
+// descriptors for private data streams. It returns "" for non-audio streams.
+func audioCodec(streamType byte, descriptors []byte) string {
+	switch streamType {
+	case 0x03, 0x04:
+		return "mp2"
+	case 0x0F:
+		return "aac"
+	case 0x11:
+		return "aac_latm"
+	case 0x81:
+		return "ac3"
+	case 0x87:
+		return "eac3"
+	case 0x06:
+		codec := ""
+		eachDescriptor(descriptors, func(tag byte, data []byte) {
+			switch tag {
+			case 0x6A:
+				codec = "ac3"
+			case 0x7A:
+				codec = "eac3"
+			case 0x7B:
+				codec = "dts"
+			case 0x7C:
+				codec = "aac"
+			}
+		})
+		return codec
+	}
+	return ""
+}
+
+// language returns the ISO 639 language code from the descriptors, if any.
+func language(descriptors []byte) string {
+	lang := ""
+	eachDescriptor(descriptors, func(tag byte, data []byte) {
+		if tag == 0x0A && len(data) >= 3 && lang == "" {
+			lang = string(bytes.TrimRight(data[:3], "\x00 "))
+		}
+	})
+	return lang
+}
+
+func eachDescriptor(descriptors []byte, fn func(tag byte, data []byte)) {
+	for i := 0; i+2 <= len(descriptors); {
+		tag, length := descriptors[i], int(descriptors[i+1])
+		if i+2+length > len(descriptors) {
+			return
+		}
+		fn(tag, descriptors[i+2:i+2+length])
+		i += 2 + length
+	}
+}
+
+var _ io.Writer = (*Analyzer)(nil)
@@ -0,0 +1,182 @@
+package mpegts
+
+import (
+	"testing"
+	"time"
+)
+
+// x264SPS is a High profile sequence parameter set for 1920x1080 video
+// (coded as 1920x1088 with bottom cropping), without its start code.
+var x264SPS = []byte{
+	0x67, 0x64, 0x00, 0x28, 0xAC, 0xD9, 0x40, 0x78, 0x02, 0x27, 0xE5, 0xC0,
+	0x44, 0x00, 0x00, 0x03, 0x00, 0x04, 0x00, 0x00, 0x03, 0x00, 0xC8, 0x3C,
+	0x60, 0xC6, 0x58,
+}
+
+// tsPacket builds a TS packet on pid carrying payload, padded with stuffing.
+func tsPacket(pid uint16, unitStart bool, payload []byte) []byte {
+	pkt := make([]byte, PacketSize)
+	pkt[0] = SyncByte
+	pkt[1] = byte(pid>>8) & 0x1F
+	if unitStart {
+		pkt[1] |= 0x40
+	}
+	pkt[2] = byte(pid)
+	pkt[3] = 0x10
+	n := copy(pkt[4:], payload)
+	for i := 4 + n; i < PacketSize; i++ {
+		pkt[i] = 0xFF
+	}
+	return pkt
+}
+
+// pcrPacket builds an adaptation-field-only packet on pid carrying the
+// given program clock reference, in 27 MHz units.
+func pcrPacket(pid uint16, pcr int64) []byte {
+	pkt := make([]byte, PacketSize)
+	pkt[0] = SyncByte
+	pkt[1] = byte(pid>>8) & 0x1F
+	pkt[2] = byte(pid)
+	pkt[3] = 0x20
+	pkt[4] = PacketSize - 5
+	pkt[5] = 0x10
+	base, ext := pcr/300, pcr%300
+	pkt[6] = byte(base >> 25)
+	pkt[7] = byte(base >> 17)
+	pkt[8] = byte(base >> 9)
+	pkt[9] = byte(base >> 1)
+	pkt[10] = byte(base<<7) | 0x7E | byte(ext>>8)
+	pkt[11] = byte(ext)
+	for i := 12; i < PacketSize; i++ {
+		pkt[i] = 0xFF
+	}
+	return pkt
+}
+
+// psi wraps a section body in a pointer field, table header and dummy CRC.
+func psi(tableID byte, body []byte) []byte {
+	length := 5 + len(body) + 4
+	section := []byte{0x00, tableID, 0xB0 | byte(length>>8), byte(length), 0x00, 0x01, 0xC1, 0x00, 0x00}
+	section = append(section, body...)
+	return append(section, 0xDE, 0xAD, 0xBE, 0xEF)
+}
+
+func testStream() []byte {
+	var ts []byte
+	ts = append(ts, tsPacket(patPID, true, psi(0x00, []byte{0x00, 0x01, 0xF0, 0x00}))...)
+	ts = append(ts, tsPacket(0x1000, true, psi(0x02, []byte{
+		0xE1, 0x00, 0xF0, 0x00, // PCR PID 0x100, no program info
+		0x1B, 0xE1, 0x00, 0xF0, 0x00, // h264 on 0x100
+		0x0F, 0xE1, 0x01, 0xF0, 0x06, 0x0A, 0x04, 'e', 'n', 'g', 0x00, // aac on 0x101
+		0x06, 0xE1, 0x02, 0xF0, 0x09, 0x0A, 0x04, 's', 'p', 'a', 0x00, 0x6A, 0x01, 0x00, // ac3 on 0x102
+		0x06, 0xE1, 0x03, 0xF0, 0x03, 0x59, 0x01, 0x00, // subtitles on 0x103
+	}))...)
+
+	pes := []byte{0x00, 0x00, 0x01, 0xE0, 0x00, 0x00, 0x80, 0x00, 0x00}
+	pes = append(pes, 0x00, 0x00, 0x00, 0x01, 0x09, 0xF0)
+	pes = append(pes, 0x00, 0x00, 0x00, 0x01)
+	pes = append(pes, x264SPS...)
+	ts = append(ts, tsPacket(0x100, true, pes)...)
+	ts = append(ts, tsPacket(0x100, false, []byte{0x00, 0x00, 0x00, 0x01, 0x68, 0xEB})...)
+
+	// 2 seconds of clock over 10 more packets
+	ts = append(ts, pcrPacket(0x100, 0)...)
+	for i := 0; i < 9; i++ {
+		ts = append(ts, tsPacket(0x1FFF, false, nil)...)
+	}
+	ts = append(ts, pcrPacket(0x100, 2*pcrClock)...)
+	return ts
+}
+
+func TestAnalyzer(t *testing.T) {
+	t.Run("reports streams, resolution and bitrate", func(t *testing.T) {
+		a := NewAnalyzer()
+		ts := testStream()
+		// Feed in odd-sized chunks to exercise packet reassembly
+		for len(ts) > 0 {
+			n := min(100, len(ts))
+			_, _ = a.Write(ts[:n])
+			ts = ts[n:]
+		}
+
+		if !a.Complete(2 * time.Second) {
+			t.Fatal("expected analysis to be complete")
+		}
+		info := a.Info()
+		if info.Video == nil {
+			t.Fatal("expected video stream")
+		}
+		if info.Video.Codec != "h264" || info.Video.Width != 1920 || info.Video.Height != 1080 {
+			t.Errorf("unexpected video %+v", *info.Video)
+		}
+
+		want := []AudioTrack{
+			{PID: 0x101, Codec: "aac", Language: "eng"},
+			{PID: 0x102, Codec: "ac3", Language: "spa"},
+		}
+		if len(info.Audio) != len(want) {
+			t.Fatalf("expected %d audio tracks, got %+v", len(want), info.Audio)
+		}
+		for i := range want {
+			if info.Audio[i] != want[i] {
+				t.Errorf("audio track %d: expected %+v, got %+v", i, want[i], info.Audio[i])
+			}
+		}
+
+		if info.Duration != 2*time.Second {
+			t.Errorf("expected duration 2s, got %v", info.Duration)
+		}
+		// 10 packets between the two PCRs over 2 seconds
+		if want := int64(10 * PacketSize * 8 / 2); info.Bitrate != want {
+			t.Errorf("expected bitrate %d, got %d", want, info.Bitrate)
+		}
+	})
+
+	t.Run("is incomplete until enough clock has been seen", func(t *testing.T) {
+		a := NewAnalyzer()
+		ts := testStream()
+		_, _ = a.Write(ts[:len(ts)-PacketSize])
+
+		if a.Complete(time.Second) {
+			t.Error("expected analysis to be incomplete with a single PCR")
+		}
+		if a.Info().Bitrate != 0 {
+			t.Error("expected no bitrate with a single PCR")
+		}
+	})
+
+	t.Run("resyncs after garbage", func(t *testing.T) {
+		a := NewAnalyzer()
+		_, _ = a.Write([]byte{0x00, 0x01, 0x02})
+		_, _ = a.Write(testStream())
+
+		if info := a.Info(); info.Video == nil || len(info.Audio) != 2 {
+			t.Errorf("expected streams after resync, got %+v", info)
+		}
+	})
+}
+
+func TestFindResolution(t *testing.T) {
+	t.Run("mpeg2 sequence header", func(t *testing.T) {
+		es := []byte{0x00, 0x00, 0x01, 0xB3, 0x2D, 0x02, 0x40, 0x33, 0xFF}
+		w, h, ok := findResolution("mpeg2video", es)
+		if !ok || w != 720 || h != 576 {
+			t.Errorf("expected 720x576, got %dx%d (ok=%v)", w, h, ok)
+		}
+	})
+
+	t.Run("waits for the end of the h264 SPS", func(t *testing.T) {
+		es := append([]byte{0x00, 0x00, 0x01}, x264SPS...)
+		if _, _, ok := findResolution("h264", es); ok {
+			t.Error("expected no resolution before the next start code")
+		}
+	})
+
+	t.Run("removes emulation prevention bytes", func(t *testing.T) {
+		got := unescapeRBSP([]byte{0x01, 0x00, 0x00, 0x03, 0x00, 0x00, 0x03, 0x01})
+		want := []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x01}
+		if string(got) != string(want) {
+			t.Errorf("expected %x, got %x", want, got)
+		}
+	})
+}
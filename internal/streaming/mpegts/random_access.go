@@ -0,0 +1,52 @@
+package mpegts
+
+// RandomAccessOffset returns the offset in p of the first transport packet
+// whose adaptation field sets the random access indicator (usually a
+// keyframe), or -1 if there is none. p need not start on a packet boundary:
+// packets are located by their sync bytes, and a packet cut off at the end of
+// p is not inspected.
+func RandomAccessOffset(p []byte) int {
+	for i := 0; i+PacketSize <= len(p); {
+		if p[i] != SyncByte || (i+PacketSize < len(p) && p[i+PacketSize] != SyncByte) {
+			i++
+			continue
+		}
+		if IsRandomAccess(p[i : i+PacketSize]) {
+			return i
+		}
+		i += PacketSize
+	}
+	return -1
+}
+
+// PATOffset returns the offset in p of the first transport packet starting a
+// program association table, or -1 if there is none. A decoder joining the
+// stream there learns its programs before any of their data, as the program
+// map tables follow. Packets are located like in RandomAccessOffset.
+func PATOffset(p []byte) int {
+	for i := 0; i+PacketSize <= len(p); {
+		if p[i] != SyncByte || (i+PacketSize < len(p) && p[i+PacketSize] != SyncByte) {
+			i++
+			continue
+		}
+		pid := uint16(p[i+1]&0x1F)<<8 | uint16(p[i+2])
+		if pid == patPID && p[i+1]&0x40 != 0 {
+			return i
+		}
+		i += PacketSize
+	}
+	return -1
+}
+
+// IsRandomAccess reports whether the transport packet's adaptation field sets
+// the random access indicator. pkt must hold a whole packet.
+func IsRandomAccess(pkt []byte) bool {
+	adaptationControl := (pkt[3] >> 4) & 0x3
+	if adaptationControl != 2 && adaptationControl != 3 {
+		return false
+	}
+	if pkt[4] == 0 {
+		return false
+	}
+	return pkt[5]&0x40 != 0
+}
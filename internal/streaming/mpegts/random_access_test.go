@@ -0,0 +1,57 @@
+package mpegts
+
+import "testing"
+
+func TestRandomAccessOffset(t *testing.T) {
+	keyframe := pcrPacket(0x100, 0)
+	keyframe[5] |= 0x40
+
+	var ts []byte
+	ts = append(ts, tsPacket(0x100, false, nil)...)
+	ts = append(ts, pcrPacket(0x100, 0)...)
+	ts = append(ts, keyframe...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"finds the keyframe packet", ts, 2 * PacketSize},
+		{"resynchronises on a chunk starting mid-packet", ts[100:], 2*PacketSize - 100},
+		{"ignores a keyframe packet cut off at the end", ts[:len(ts)-1], -1},
+		{"no keyframe", ts[:2*PacketSize], -1},
+		{"empty", nil, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RandomAccessOffset(tt.data); got != tt.want {
+				t.Errorf("RandomAccessOffset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPATOffset(t *testing.T) {
+	var ts []byte
+	ts = append(ts, tsPacket(0x100, true, nil)...)
+	ts = append(ts, tsPacket(patPID, false, nil)...)
+	ts = append(ts, tsPacket(patPID, true, nil)...)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"finds the packet starting a table", ts, 2 * PacketSize},
+		{"resynchronises on a chunk starting mid-packet", ts[100:], 2*PacketSize - 100},
+		{"no table start", ts[:2*PacketSize], -1},
+		{"empty", nil, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PATOffset(tt.data); got != tt.want {
+				t.Errorf("PATOffset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package mpegts
+
+import (
+	"bytes"
+	"errors"
+)
+
+var errShortBitstream = errors.New("bitstream too short")
+
+// findResolution looks for a sequence header of the given codec in es and
+// returns the coded picture size it declares. ok is false until a complete
+// header is available.
+func findResolution(codec string, es []byte) (width, height int, ok bool) {
+	switch codec {
+	case "mpeg1video", "mpeg2video":
+		return mpeg2Resolution(es)
+	case "h264":
+		if nal := findNAL(es, func(header byte) bool { return header&0x1F == 7 }); nal != nil {
+			return h264Resolution(unescapeRBSP(nal[1:]))
+		}
+	case "hevc":
+		if nal := findNAL(es, func(header byte) bool { return (header>>1)&0x3F == 33 }); nal != nil && len(nal) > 2 {
+			return hevcResolution(unescapeRBSP(nal[2:]))
+		}
+	}
+	return 0, 0, false
+}
+
+// mpeg2Resolution reads the picture size from an MPEG-1/2 sequence header.
+func mpeg2Resolution(es []byte) (int, int, bool) {
+	i := bytes.Index(es, []byte{0x00, 0x00, 0x01, 0xB3})
+	if i < 0 || i+7 > len(es) {
+		return 0, 0, false
+	}
+	b := es[i+4:]
+	width := int(b[0])<<4 | int(b[1])>>4
+	height := int(b[1]&0x0F)<<8 | int(b[2])
+	return width, height, width > 0 && height > 0
+}
+
+// findNAL returns the first complete NAL unit (header included) in an Annex B
+// byte stream whose header byte satisfies match. A NAL unit is complete once
+// the start code of the next one has been seen.
+func findNAL(es []byte, match func(header byte) bool) []byte {
+	startCode := []byte{0x00, 0x00, 0x01}
+	for offset := 0; ; {
+		i := bytes.Index(es[offset:], startCode)
+		if i < 0 {
+			return nil
+		}
+		start := offset + i + len(startCode)
+		if start >= len(es) {
+			return nil
+		}
+		if !match(es[start]) {
+			offset = start
+			continue
+		}
+		end := bytes.Index(es[start:], startCode)
+		if end < 0 {
+			return nil
+		}
+		return es[start : start+end]
+	}
+}
+
+// unescapeRBSP removes emulation prevention bytes (00 00 03) from a NAL unit
+// payload.
+func unescapeRBSP(nal []byte) []byte {
+	out := make([]byte, 0, len(nal))
+	zeros := 0
+	for _, b := range nal {
+		if zeros >= 2 && b == 0x03 {
+			zeros = 0
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// h264Resolution parses an H.264 sequence parameter set (ITU-T H.264
+// 7.3.2.1.1) up to the frame cropping fields.
+func h264Resolution(rbsp []byte) (int, int, bool) {
+	r := &bitReader{data: rbsp}
+
+	profile := r.bits(8)
+	r.skip(16) // constraint flags, level_idc
+	r.ue()     // seq_parameter_set_id
+
+	chromaFormat := uint(1)
+	separateColourPlane := false
+	switch profile {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		chromaFormat = r.ue()
+		if chromaFormat == 3 {
+			separateColourPlane = r.bit() == 1
+		}
+		r.ue()    // bit_depth_luma_minus8
+		r.ue()    // bit_depth_chroma_minus8
+		r.skip(1) // qpprime_y_zero_transform_bypass_flag
+		if r.bit() == 1 {
+			lists := 8
+			if chromaFormat == 3 {
+				lists = 12
+			}
+			for i := 0; i < lists; i++ {
+				if r.bit() == 1 {
+					size := 16
+					if i >= 6 {
+						size = 64
+					}
+					r.skipScalingList(size)
+				}
+			}
+		}
+	}
+
+	r.ue() // log2_max_frame_num_minus4
+	switch r.ue() {
+	case 0:
+		r.ue() // log2_max_pic_order_cnt_lsb_minus4
+	case 1:
+		r.skip(1) // delta_pic_order_always_zero_flag
+		r.se()    // offset_for_non_ref_pic
+		r.se()    // offset_for_top_to_bottom_field
+		cycle := r.ue()
+		for i := uint(0); i < cycle && r.err == nil; i++ {
+			r.se()
+		}
+	}
+	r.ue()    // max_num_ref_frames
+	r.skip(1) // gaps_in_frame_num_value_allowed_flag
+
+	widthInMbs := r.ue() + 1
+	heightInMapUnits := r.ue() + 1
+	frameMbsOnly := r.bit()
+	if frameMbsOnly == 0 {
+		r.skip(1) // mb_adaptive_frame_field_flag
+	}
+	r.skip(1) // direct_8x8_inference_flag
+
+	var cropLeft, cropRight, cropTop, cropBottom uint
+	if r.bit() == 1 {
+		cropLeft, cropRight, cropTop, cropBottom = r.ue(), r.ue(), r.ue(), r.ue()
+	}
+	if r.err != nil {
+		return 0, 0, false
+	}
+
+	cropUnitX, cropUnitY := uint(1), 2-frameMbsOnly
+	if chromaFormat != 0 && !separateColourPlane {
+		subWidth, subHeight := uint(2), uint(2)
+		switch chromaFormat {
+		case 2:
+			subHeight = 1
+		case 3:
+			subWidth, subHeight = 1, 1
+		}
+		cropUnitX, cropUnitY = subWidth, subHeight*(2-frameMbsOnly)
+	}
+
+	width := int(widthInMbs*16) - int(cropUnitX*(cropLeft+cropRight))
+	height := int((2-frameMbsOnly)*heightInMapUnits*16) - int(cropUnitY*(cropTop+cropBottom))
+	return width, height, width > 0 && height > 0
+}
+
+// hevcResolution parses an HEVC sequence parameter set (ITU-T H.265
+// 7.3.2.2) up to the conformance window.
+func hevcResolution(rbsp []byte) (int, int, bool) {
+	r := &bitReader{data: rbsp}
+
+	r.skip(4) // sps_video_parameter_set_id
+	maxSubLayers := r.bits(3) + 1
+	r.skip(1) // sps_temporal_id_nesting_flag
+
+	// profile_tier_level: general profile and level, then sub-layer flags
+	// and whichever sub-layer profiles and levels they announce.
+	r.skip(88 + 8)
+	subLayerProfile := make([]bool, maxSubLayers-1)
+	subLayerLevel := make([]bool, maxSubLayers-1)
+	for i := range subLayerProfile {
+		subLayerProfile[i] = r.bit() == 1
+		subLayerLevel[i] = r.bit() == 1
+	}
+	if maxSubLayers > 1 {
+		r.skip(int(2 * (9 - maxSubLayers))) // reserved_zero_2bits
+	}
+	for i := range subLayerProfile {
+		if subLayerProfile[i] {
+			r.skip(88)
+		}
+		if subLayerLevel[i] {
+			r.skip(8)
+		}
+	}
+
+	r.ue() // sps_seq_parameter_set_id
+	chromaFormat := r.ue()
+	if chromaFormat == 3 {
+		r.skip(1) // separate_colour_plane_flag
+	}
+	width := r.ue()
+	height := r.ue()
+
+	if r.bit() == 1 { // conformance_window_flag
+		left, right, top, bottom := r.ue(), r.ue(), r.ue(), r.ue()
+		subWidth, subHeight := uint(1), uint(1)
+		switch chromaFormat {
+		case 1:
+			subWidth, subHeight = 2, 2
+		case 2:
+			subWidth = 2
+		}
+		width -= subWidth * (left + right)
+		height -= subHeight * (top + bottom)
+	}
+	if r.err != nil {
+		return 0, 0, false
+	}
+	return int(width), int(height), width > 0 && height > 0
+}
+
+// bitReader reads big-endian bit fields and Exp-Golomb codes. Reading past
+// the end sets err and yields zeros.
+type bitReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *bitReader) bit() uint {
+	if r.pos >= len(r.data)*8 {
+		r.err = errShortBitstream
+		return 0
+	}
+	b := r.data[r.pos/8] >> (7 - r.pos%8) & 1
+	r.pos++
+	return uint(b)
+}
+
+func (r *bitReader) bits(n int) uint {
+	var v uint
+	for i := 0; i < n; i++ {
+		v = v<<1 | r.bit()
+	}
+	return v
+}
+
+func (r *bitReader) skip(n int) {
+	r.pos += n
+	if r.pos > len(r.data)*8 {
+		r.err = errShortBitstream
+	}
+}
+
+// ue reads an unsigned Exp-Golomb code.
+func (r *bitReader) ue() uint {
+	zeros := 0
+	for r.bit() == 0 {
+		if r.err != nil || zeros >= 32 {
+			r.err = errShortBitstream
+			return 0
+		}
+		zeros++
+	}
+	return (1<<zeros - 1) + r.bits(zeros)
+}
+
+// se reads a signed Exp-Golomb code.
+func (r *bitReader) se() int {
+	v := r.ue()
+	if v%2 == 1 {
+		return int(v+1) / 2
+	}
+	return -int(v / 2)
+}
+
+// skipScalingList skips an H.264 scaling_list of the given size.
+func (r *bitReader) skipScalingList(size int) {
+	last, next := 8, 8
+	for j := 0; j < size && r.err == nil; j++ {
+		if next != 0 {
+			next = (last + r.se() + 256) % 256
+		}
+		if next != 0 {
+			last = next
+		}
+	}
+}
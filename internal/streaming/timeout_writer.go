@@ -19,6 +19,9 @@ var (
 // TimeoutWriter wraps an io.Writer and enforces a write timeout.
 // If a write operation takes longer than the configured timeout,
 // it returns ErrWriteTimeout and the writer is considered slow.
+// Deadlines are only set, and writes flushed, when dst is the
+// http.ResponseWriter itself, so it must wrap the response directly rather
+// than a writer layered on top of it.
 type TimeoutWriter struct {
 	dst          io.Writer
 	timeout      time.Duration
@@ -67,8 +70,8 @@ func (tw *TimeoutWriter) Write(p []byte) (n int, err error) {
 
 	// Check for timeout-related errors
 	if err != nil {
-		// Check if this is a timeout error
-		if IsTimeoutError(err) {
+		// Check if this is a timeout error not already reported below
+		if IsTimeoutError(err) && !errors.Is(err, ErrWriteTimeout) {
 			tw.logger.Warn("slow client detected - write timeout",
 				"infohash", tw.infoHash,
 				"pid", tw.pid,
@@ -77,9 +80,13 @@ func (tw *TimeoutWriter) Write(p []byte) (n int, err error) {
 				"error", err)
 			return n, fmt.Errorf("%w: %v", ErrWriteTimeout, err)
 		}
+		return n, err
 	}
 
-	return n, err
+	if f, ok := tw.dst.(http.Flusher); ok {
+		f.Flush()
+	}
+	return n, nil
 }
 
 // BytesWritten returns the total number of bytes written successfully.
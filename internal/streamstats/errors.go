@@ -0,0 +1,8 @@
+package streamstats
+
+import "errors"
+
+var (
+	ErrEmptyInfoHash    = errors.New("stats sample infohash cannot be empty")
+	ErrInvalidTimestamp = errors.New("stats sample timestamp must not be zero")
+)
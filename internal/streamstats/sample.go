@@ -0,0 +1,127 @@
+package streamstats
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sample is a reading of the engine statistics of a stream, or the average
+// of several readings over a span of time once compacted.
+// It is an immutable value object.
+type Sample struct {
+	infoHash  string
+	timestamp time.Time
+	span      time.Duration
+	count     int
+	peers     int
+	speedDown int64
+	speedUp   int64
+}
+
+// NewSample creates a single reading with validation.
+func NewSample(infoHash string, timestamp time.Time, peers int, speedDown, speedUp int64) (Sample, error) {
+	infoHash = strings.TrimSpace(infoHash)
+	if infoHash == "" {
+		return Sample{}, ErrEmptyInfoHash
+	}
+	if timestamp.IsZero() {
+		return Sample{}, ErrInvalidTimestamp
+	}
+	return Sample{
+		infoHash:  infoHash,
+		timestamp: timestamp,
+		count:     1,
+		peers:     peers,
+		speedDown: speedDown,
+		speedUp:   speedUp,
+	}, nil
+}
+
+// ReconstructSample rebuilds a Sample from persisted state.
+// Intended for repository adapters only — bypasses validation.
+func ReconstructSample(
+	infoHash string,
+	timestamp time.Time,
+	span time.Duration,
+	count int,
+	peers int,
+	speedDown int64,
+	speedUp int64,
+) Sample {
+	return Sample{
+		infoHash:  infoHash,
+		timestamp: timestamp,
+		span:      span,
+		count:     count,
+		peers:     peers,
+		speedDown: speedDown,
+		speedUp:   speedUp,
+	}
+}
+
+func (s Sample) InfoHash() string     { return s.infoHash }
+func (s Sample) Timestamp() time.Time { return s.timestamp }
+
+// Span is the period the sample averages, starting at its timestamp.
+// It is zero for a single reading.
+func (s Sample) Span() time.Duration { return s.span }
+
+// Count is the number of readings the sample averages.
+func (s Sample) Count() int       { return s.count }
+func (s Sample) Peers() int       { return s.peers }
+func (s Sample) SpeedDown() int64 { return s.speedDown }
+func (s Sample) SpeedUp() int64   { return s.speedUp }
+
+// Aggregate averages samples into one per stream and step-aligned period,
+// weighting each sample by the readings it already averages. The result is
+// ordered by infohash, then timestamp.
+func Aggregate(samples []Sample, step time.Duration) []Sample {
+	type bucket struct {
+		infoHash  string
+		start     time.Time
+		count     int
+		peers     int64
+		speedDown int64
+		speedUp   int64
+	}
+
+	type key struct {
+		infoHash string
+		start    int64
+	}
+	buckets := make(map[key]*bucket)
+	for _, s := range samples {
+		start := s.timestamp.Truncate(step)
+		k := key{s.infoHash, start.UnixNano()}
+		b, ok := buckets[k]
+		if !ok {
+			b = &bucket{infoHash: s.infoHash, start: start}
+			buckets[k] = b
+		}
+		weight := int64(max(s.count, 1))
+		b.count += int(weight)
+		b.peers += int64(s.peers) * weight
+		b.speedDown += s.speedDown * weight
+		b.speedUp += s.speedUp * weight
+	}
+
+	result := make([]Sample, 0, len(buckets))
+	for _, b := range buckets {
+		n := int64(b.count)
+		result = append(result, Sample{
+			infoHash:  b.infoHash,
+			timestamp: b.start,
+			span:      step,
+			count:     b.count,
+			peers:     int((b.peers + n/2) / n),
+			speedDown: (b.speedDown + n/2) / n,
+			speedUp:   (b.speedUp + n/2) / n,
+		})
+	}
+	slices.SortFunc(result, func(a, b Sample) int {
+		return cmp.Or(strings.Compare(a.infoHash, b.infoHash), a.timestamp.Compare(b.timestamp))
+	})
+	return result
+}
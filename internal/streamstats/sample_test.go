@@ -0,0 +1,79 @@
+package streamstats
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewSample(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		infoHash  string
+		timestamp time.Time
+		wantError error
+	}{
+		{name: "valid sample", infoHash: "abc123", timestamp: now},
+		{name: "empty infohash", infoHash: "", timestamp: now, wantError: ErrEmptyInfoHash},
+		{name: "whitespace-only infohash", infoHash: "   ", timestamp: now, wantError: ErrEmptyInfoHash},
+		{name: "zero timestamp", infoHash: "abc123", wantError: ErrInvalidTimestamp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSample(tt.infoHash, tt.timestamp, 12, 500000, 100000)
+			if !errors.Is(err, tt.wantError) {
+				t.Fatalf("NewSample() error = %v, want %v", err, tt.wantError)
+			}
+			if err != nil {
+				return
+			}
+			if s.Count() != 1 || s.Span() != 0 {
+				t.Errorf("expected a single reading, got count %d and span %v", s.Count(), s.Span())
+			}
+			if s.Peers() != 12 || s.SpeedDown() != 500000 || s.SpeedUp() != 100000 {
+				t.Errorf("unexpected stats %+v", s)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	base := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	a1, _ := NewSample("a", base.Add(5*time.Minute), 10, 100, 10)
+	a2, _ := NewSample("a", base.Add(50*time.Minute), 20, 200, 20)
+	// An hourly sample already averaging three readings weighs three times as much.
+	a3 := ReconstructSample("a", base.Add(time.Hour), time.Hour, 3, 4, 400, 40)
+	a4, _ := NewSample("a", base.Add(time.Hour+time.Minute), 8, 800, 80)
+	b1, _ := NewSample("b", base.Add(time.Minute), 1, 1, 1)
+
+	got := Aggregate([]Sample{a4, b1, a2, a3, a1}, time.Hour)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 points, got %d", len(got))
+	}
+
+	want := []struct {
+		infoHash  string
+		timestamp time.Time
+		count     int
+		peers     int
+		speedDown int64
+	}{
+		{"a", base, 2, 15, 150},
+		{"a", base.Add(time.Hour), 4, 5, 500},
+		{"b", base, 1, 1, 1},
+	}
+	for i, w := range want {
+		p := got[i]
+		if p.InfoHash() != w.infoHash || !p.Timestamp().Equal(w.timestamp) || p.Count() != w.count ||
+			p.Peers() != w.peers || p.SpeedDown() != w.speedDown || p.Span() != time.Hour {
+			t.Errorf("point %d = %+v, want %+v", i, p, w)
+		}
+	}
+
+	if got := Aggregate(nil, time.Hour); len(got) != 0 {
+		t.Errorf("expected no points for no samples, got %d", len(got))
+	}
+}
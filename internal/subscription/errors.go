@@ -6,6 +6,7 @@ import "errors"
 var (
 	// Subscription validation errors
 	ErrEmptyEPGChannelID = errors.New("subscription epg channel id cannot be empty")
+	ErrNotesTooLong      = errors.New("subscription notes cannot exceed 1000 characters")
 
 	// Subscription operation errors
 	ErrSubscriptionNotFound      = errors.New("subscription not found")
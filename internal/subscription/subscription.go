@@ -2,14 +2,23 @@ package subscription
 
 import (
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+// maxNotesLength is the maximum length of a subscription's notes, in characters.
+const maxNotesLength = 1000
+
 // Subscription represents a user's subscription to an EPG channel.
 // It tracks which EPG channels are enabled and whether the user has manually overridden the default state.
+// A subscription can also be paused or given an expiry date, and carry free-text notes.
 type Subscription struct {
 	epgChannelID   string
 	enabled        bool
 	manualOverride bool
+	paused         bool
+	expiresAt      time.Time
+	notes          string
 }
 
 // NewSubscription creates a new Subscription for the given EPG channel.
@@ -43,32 +52,85 @@ func (s Subscription) HasManualOverride() bool {
 	return s.manualOverride
 }
 
+// IsPaused returns whether the subscription is paused.
+func (s Subscription) IsPaused() bool {
+	return s.paused
+}
+
+// ExpiresAt returns when the subscription expires, or the zero time if it
+// never does.
+func (s Subscription) ExpiresAt() time.Time {
+	return s.expiresAt
+}
+
+// IsExpired returns whether the subscription has an expiry date at or before now.
+func (s Subscription) IsExpired(now time.Time) bool {
+	return !s.expiresAt.IsZero() && !now.Before(s.expiresAt)
+}
+
+// IsActive returns whether the subscription contributes its channel at now:
+// it is enabled, not paused and not expired.
+func (s Subscription) IsActive(now time.Time) bool {
+	return s.enabled && !s.paused && !s.IsExpired(now)
+}
+
+// Notes returns the free-text notes of the subscription.
+func (s Subscription) Notes() string {
+	return s.notes
+}
+
 // Enable enables the subscription.
 // If the subscription was previously disabled, this is considered a manual override.
 func (s Subscription) Enable() Subscription {
-	return Subscription{
-		epgChannelID:   s.epgChannelID,
-		enabled:        true,
-		manualOverride: !s.enabled || s.manualOverride,
-	}
+	s.manualOverride = !s.enabled || s.manualOverride
+	s.enabled = true
+	return s
 }
 
 // Disable disables the subscription.
 // If the subscription was previously enabled, this is considered a manual override.
 func (s Subscription) Disable() Subscription {
-	return Subscription{
-		epgChannelID:   s.epgChannelID,
-		enabled:        false,
-		manualOverride: s.enabled || s.manualOverride,
-	}
+	s.manualOverride = s.enabled || s.manualOverride
+	s.enabled = false
+	return s
 }
 
 // ClearOverride resets the manual override flag and enables the subscription.
 // This returns the subscription to its default enabled state.
 func (s Subscription) ClearOverride() Subscription {
-	return Subscription{
-		epgChannelID:   s.epgChannelID,
-		enabled:        true,
-		manualOverride: false,
+	s.enabled = true
+	s.manualOverride = false
+	return s
+}
+
+// Pause pauses the subscription. Unlike Disable, pausing is not a manual
+// override and is undone with Resume.
+func (s Subscription) Pause() Subscription {
+	s.paused = true
+	return s
+}
+
+// Resume resumes a paused subscription.
+func (s Subscription) Resume() Subscription {
+	s.paused = false
+	return s
+}
+
+// WithExpiry returns the subscription expiring at t. The zero time removes
+// the expiry date.
+func (s Subscription) WithExpiry(t time.Time) Subscription {
+	s.expiresAt = t
+	return s
+}
+
+// WithNotes returns the subscription with the given notes, trimmed of
+// surrounding whitespace. An empty string removes them.
+// Returns ErrNotesTooLong if the notes exceed 1000 characters.
+func (s Subscription) WithNotes(notes string) (Subscription, error) {
+	notes = strings.TrimSpace(notes)
+	if utf8.RuneCountInString(notes) > maxNotesLength {
+		return Subscription{}, ErrNotesTooLong
 	}
+	s.notes = notes
+	return s, nil
 }
@@ -2,7 +2,9 @@ package subscription_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alorle/iptv-manager/internal/subscription"
 )
@@ -404,6 +406,71 @@ func TestSubscriptionWorkflow(t *testing.T) {
 	}
 }
 
+func TestSubscriptionPauseAndExpiry(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	sub, err := subscription.NewSubscription("ch-123")
+	if err != nil {
+		t.Fatalf("NewSubscription() unexpected error = %v", err)
+	}
+
+	if sub.IsPaused() || !sub.ExpiresAt().IsZero() || !sub.IsActive(now) {
+		t.Fatalf("new subscription: paused=%v expires=%v active=%v, want an active subscription without expiry",
+			sub.IsPaused(), sub.ExpiresAt(), sub.IsActive(now))
+	}
+
+	paused := sub.Pause()
+	if !paused.IsPaused() || paused.IsActive(now) {
+		t.Errorf("after Pause: paused=%v active=%v, want paused and inactive", paused.IsPaused(), paused.IsActive(now))
+	}
+	if paused.HasManualOverride() || !paused.IsEnabled() {
+		t.Error("Pause() should not change the enabled state or create an override")
+	}
+	if sub.IsPaused() {
+		t.Error("original subscription was mutated by Pause()")
+	}
+	if resumed := paused.Resume(); resumed.IsPaused() || !resumed.IsActive(now) {
+		t.Errorf("after Resume: paused=%v active=%v, want active", resumed.IsPaused(), resumed.IsActive(now))
+	}
+
+	expiring := sub.WithExpiry(now.Add(time.Hour))
+	if expiring.IsExpired(now) || !expiring.IsActive(now) {
+		t.Error("subscription expiring in an hour should still be active")
+	}
+	if !expiring.IsExpired(now.Add(time.Hour)) || expiring.IsActive(now.Add(2*time.Hour)) {
+		t.Error("subscription should be expired and inactive from its expiry date on")
+	}
+	if cleared := expiring.WithExpiry(time.Time{}); !cleared.ExpiresAt().IsZero() || cleared.IsExpired(now.AddDate(10, 0, 0)) {
+		t.Error("WithExpiry(zero) should remove the expiry date")
+	}
+
+	// State changes keep the new fields
+	if disabled := paused.WithExpiry(now).Disable().Enable(); !disabled.IsPaused() || !disabled.ExpiresAt().Equal(now) {
+		t.Error("Disable() and Enable() should keep the pause and expiry")
+	}
+}
+
+func TestSubscriptionNotes(t *testing.T) {
+	sub, _ := subscription.NewSubscription("ch-123")
+
+	noted, err := sub.WithNotes("  Paid until the end of the season  ")
+	if err != nil {
+		t.Fatalf("WithNotes() unexpected error = %v", err)
+	}
+	if got := noted.Notes(); got != "Paid until the end of the season" {
+		t.Errorf("Notes() = %q, want trimmed notes", got)
+	}
+	if got := noted.ClearOverride().Notes(); got != noted.Notes() {
+		t.Errorf("ClearOverride() dropped the notes, got %q", got)
+	}
+
+	if _, err := sub.WithNotes(strings.Repeat("ñ", 1000)); err != nil {
+		t.Errorf("WithNotes() of 1000 characters unexpected error = %v", err)
+	}
+	if _, err := sub.WithNotes(strings.Repeat("a", 1001)); !errors.Is(err, subscription.ErrNotesTooLong) {
+		t.Errorf("WithNotes() error = %v, want ErrNotesTooLong", err)
+	}
+}
+
 func TestSubscriptionDomainErrors(t *testing.T) {
 	tests := []struct {
 		name string
@@ -415,6 +482,11 @@ func TestSubscriptionDomainErrors(t *testing.T) {
 			err:  subscription.ErrEmptyEPGChannelID,
 			msg:  "subscription epg channel id cannot be empty",
 		},
+		{
+			name: "ErrNotesTooLong",
+			err:  subscription.ErrNotesTooLong,
+			msg:  "subscription notes cannot exceed 1000 characters",
+		},
 		{
 			name: "ErrSubscriptionNotFound",
 			err:  subscription.ErrSubscriptionNotFound,
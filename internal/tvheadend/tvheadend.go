@@ -0,0 +1,81 @@
+// Package tvheadend reads the IPTV muxes of Tvheadend network exports, as
+// returned by its mux grid API or stored in its configuration directory.
+package tvheadend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alorle/iptv-manager/internal/m3u"
+)
+
+// ErrInvalidExport is returned when the input is not a Tvheadend export.
+var ErrInvalidExport = errors.New("not a Tvheadend mux export")
+
+// mux is an IPTV mux. Muxes of other network types have no iptv_url.
+type mux struct {
+	URL         string `json:"iptv_url"`
+	MuxName     string `json:"iptv_muxname"`
+	ServiceName string `json:"iptv_sname"`
+	EPGID       string `json:"iptv_epgid"`
+	Icon        string `json:"iptv_icon"`
+}
+
+// Parse reads the IPTV muxes of a Tvheadend export as playlist entries, in
+// order. The export may be the response of /api/mpegts/mux/grid, with the
+// muxes under entries, a list of muxes, or a single mux configuration file.
+// Muxes are named after their service name, falling back to the mux name,
+// and muxes without an IPTV URL are left out.
+// Returns ErrInvalidExport if r holds none of those.
+func Parse(r io.Reader) ([]m3u.Entry, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading Tvheadend export: %w", err)
+	}
+
+	var muxes []mux
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		if err := json.Unmarshal(trimmed, &muxes); err != nil {
+			return nil, ErrInvalidExport
+		}
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		var grid struct {
+			Entries []mux `json:"entries"`
+			mux
+		}
+		if err := json.Unmarshal(trimmed, &grid); err != nil {
+			return nil, ErrInvalidExport
+		}
+		switch {
+		case grid.Entries != nil:
+			muxes = grid.Entries
+		case grid.URL != "":
+			muxes = []mux{grid.mux}
+		default:
+			return nil, ErrInvalidExport
+		}
+	default:
+		return nil, ErrInvalidExport
+	}
+
+	var entries []m3u.Entry
+	for _, m := range muxes {
+		if strings.TrimSpace(m.URL) == "" {
+			continue
+		}
+		entries = append(entries, m3u.Entry{
+			Name:    strings.TrimSpace(m.ServiceName),
+			TVGName: strings.TrimSpace(m.MuxName),
+			TVGID:   strings.TrimSpace(m.EPGID),
+			TVGLogo: m.Icon,
+			URL:     strings.TrimSpace(m.URL),
+		})
+	}
+	return entries, nil
+}
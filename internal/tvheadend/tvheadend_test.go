@@ -0,0 +1,62 @@
+package tvheadend
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alorle/iptv-manager/internal/m3u"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("reads the muxes of a grid response", func(t *testing.T) {
+		input := `{"entries":[
+			{"uuid":"1","iptv_url":"acestream://aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","iptv_muxname":"mux 1","iptv_sname":"HBO","iptv_epgid":"hbo.es","iptv_icon":"http://logo/hbo.png"},
+			{"uuid":"2","iptv_muxname":"DVB-T mux"},
+			{"uuid":"3","iptv_url":"http://127.0.0.1:6878/ace/getstream?id=bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb","iptv_muxname":"Sports"}
+		],"total":3}`
+		entries, err := Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("expected the 2 IPTV muxes, got %d", len(entries))
+		}
+		want := m3u.Entry{
+			Name:    "HBO",
+			TVGID:   "hbo.es",
+			TVGName: "mux 1",
+			TVGLogo: "http://logo/hbo.png",
+			URL:     "acestream://aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		}
+		if entries[0] != want {
+			t.Errorf("entries[0] = %+v, want %+v", entries[0], want)
+		}
+		if entries[1].ChannelName() != "Sports" || entries[1].InfoHash() != strings.Repeat("b", 40) {
+			t.Errorf("unexpected second entry %+v", entries[1])
+		}
+	})
+
+	t.Run("reads a list of muxes and a single mux file", func(t *testing.T) {
+		for _, input := range []string{
+			`[{"iptv_url":"acestream://aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","iptv_sname":"HBO"}]`,
+			`{"enabled":true,"iptv_url":"acestream://aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","iptv_sname":"HBO"}`,
+		} {
+			entries, err := Parse(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", input, err)
+			}
+			if len(entries) != 1 || entries[0].Name != "HBO" {
+				t.Errorf("Parse(%q) = %+v", input, entries)
+			}
+		}
+	})
+
+	t.Run("rejects other input", func(t *testing.T) {
+		for _, input := range []string{"#EXTM3U", `{"name":"HBO"}`, ""} {
+			if _, err := Parse(strings.NewReader(input)); !errors.Is(err, ErrInvalidExport) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidExport", input, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,12 @@
+package user
+
+import "errors"
+
+// Domain errors for user operations.
+var (
+	// User validation errors
+	ErrEmptyName = errors.New("user name cannot be empty")
+
+	// User operation errors
+	ErrUserNotFound = errors.New("user not found")
+)
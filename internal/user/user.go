@@ -0,0 +1,167 @@
+// Package user models the people a server is shared with. Each user has a
+// playlist of their own, reached through a secret URL, that only lists the
+// channels they are allowed to see.
+package user
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+)
+
+// User is someone the server is shared with. A user sees the channels named
+// in their channel set and every channel of the groups in their group set;
+// with both sets empty, their playlist is empty.
+type User struct {
+	id            string
+	name          string
+	playlistToken string
+	channels      []string
+	groups        []string
+	createdAt     time.Time
+}
+
+// NewUser creates a user with a random ID and playlist token. Channel names
+// and group IDs are trimmed, and blanks and duplicates are dropped.
+// Returns ErrEmptyName if the name is empty or contains only whitespace.
+func NewUser(name string, channels, groups []string, now time.Time) (User, error) {
+	trimmedName := strings.TrimSpace(name)
+	if trimmedName == "" {
+		return User{}, ErrEmptyName
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return User{}, err
+	}
+	token, err := newPlaylistToken()
+	if err != nil {
+		return User{}, err
+	}
+
+	return User{
+		id:            hex.EncodeToString(idBytes),
+		name:          trimmedName,
+		playlistToken: token,
+		channels:      normalize(channels),
+		groups:        normalize(groups),
+		createdAt:     now,
+	}, nil
+}
+
+// ReconstructUser rebuilds a User from persisted state.
+// This is intended for repository adapters only — it bypasses validation.
+func ReconstructUser(id, name, playlistToken string, channels, groups []string, createdAt time.Time) User {
+	return User{
+		id:            id,
+		name:          name,
+		playlistToken: playlistToken,
+		channels:      channels,
+		groups:        groups,
+		createdAt:     createdAt,
+	}
+}
+
+// ID returns the user's identifier.
+func (u User) ID() string {
+	return u.id
+}
+
+// Name returns the user's display name.
+func (u User) Name() string {
+	return u.name
+}
+
+// PlaylistToken returns the secret that identifies the user's playlist URL.
+// Unlike API token secrets it is kept, so the URL can be shown again.
+func (u User) PlaylistToken() string {
+	return u.playlistToken
+}
+
+// Channels returns the names of the channels the user may see, sorted.
+func (u User) Channels() []string {
+	return slices.Clone(u.channels)
+}
+
+// Groups returns the IDs of the groups whose channels the user may see, sorted.
+func (u User) Groups() []string {
+	return slices.Clone(u.groups)
+}
+
+// CreatedAt returns when the user was created.
+func (u User) CreatedAt() time.Time {
+	return u.createdAt
+}
+
+// CanSee reports whether the user may see the channel with the given name
+// in the group with the given ID.
+func (u User) CanSee(channelName, groupID string) bool {
+	if _, ok := slices.BinarySearch(u.channels, channelName); ok {
+		return true
+	}
+	if groupID == "" {
+		return false
+	}
+	_, ok := slices.BinarySearch(u.groups, groupID)
+	return ok
+}
+
+// Update returns a copy of the user with a new name and visible channels,
+// normalized as by NewUser.
+// Returns ErrEmptyName if the name is empty or contains only whitespace.
+func (u User) Update(name string, channels, groups []string) (User, error) {
+	trimmedName := strings.TrimSpace(name)
+	if trimmedName == "" {
+		return User{}, ErrEmptyName
+	}
+	u.name = trimmedName
+	u.channels = normalize(channels)
+	u.groups = normalize(groups)
+	return u, nil
+}
+
+// WithNewPlaylistToken returns a copy of the user with a fresh playlist
+// token, so the previous playlist URL stops working.
+func (u User) WithNewPlaylistToken() (User, error) {
+	token, err := newPlaylistToken()
+	if err != nil {
+		return User{}, err
+	}
+	u.playlistToken = token
+	return u, nil
+}
+
+// Sort orders users by creation time, breaking ties by ID.
+func Sort(users []User) {
+	slices.SortStableFunc(users, func(a, b User) int {
+		if c := a.createdAt.Compare(b.createdAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+}
+
+// newPlaylistToken returns a random secret safe for use in a URL path.
+func newPlaylistToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// normalize trims values, drops blanks and duplicates, and sorts the rest.
+func normalize(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
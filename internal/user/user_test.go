@@ -0,0 +1,100 @@
+package user
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewUser(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("normalizes the visible channels and generates a token", func(t *testing.T) {
+		u, err := NewUser("  Grandma  ", []string{" La 1 ", "", "Clan", "La 1"}, []string{"kids", " kids "}, now)
+		if err != nil {
+			t.Fatalf("NewUser() error = %v", err)
+		}
+		if u.Name() != "Grandma" {
+			t.Errorf("Name() = %q, want trimmed name", u.Name())
+		}
+		if u.ID() == "" || u.PlaylistToken() == "" {
+			t.Error("expected non-empty ID and playlist token")
+		}
+		if !slices.Equal(u.Channels(), []string{"Clan", "La 1"}) {
+			t.Errorf("Channels() = %q", u.Channels())
+		}
+		if !slices.Equal(u.Groups(), []string{"kids"}) {
+			t.Errorf("Groups() = %q", u.Groups())
+		}
+		if !u.CreatedAt().Equal(now) {
+			t.Errorf("CreatedAt() = %v, want %v", u.CreatedAt(), now)
+		}
+	})
+
+	t.Run("generates distinct tokens", func(t *testing.T) {
+		a, _ := NewUser("a", nil, nil, now)
+		b, _ := NewUser("b", nil, nil, now)
+		if a.ID() == b.ID() || a.PlaylistToken() == b.PlaylistToken() {
+			t.Error("expected unique IDs and playlist tokens")
+		}
+	})
+
+	t.Run("rejects empty name", func(t *testing.T) {
+		if _, err := NewUser("   ", nil, nil, now); !errors.Is(err, ErrEmptyName) {
+			t.Errorf("expected ErrEmptyName, got %v", err)
+		}
+	})
+}
+
+func TestUser_CanSee(t *testing.T) {
+	u, _ := NewUser("kid", []string{"La 1"}, []string{"kids"}, time.Now())
+
+	tests := []struct {
+		channel, group string
+		want           bool
+	}{
+		{"La 1", "", true},
+		{"La 1", "news", true},
+		{"Clan", "kids", true},
+		{"DAZN 1", "sports", false},
+		{"DAZN 1", "", false},
+		{"la 1", "", false},
+	}
+	for _, tt := range tests {
+		if got := u.CanSee(tt.channel, tt.group); got != tt.want {
+			t.Errorf("CanSee(%q, %q) = %v, want %v", tt.channel, tt.group, got, tt.want)
+		}
+	}
+
+	nobody, _ := NewUser("nobody", nil, nil, time.Now())
+	if nobody.CanSee("La 1", "kids") {
+		t.Error("expected a user without channels or groups to see nothing")
+	}
+}
+
+func TestUser_Update(t *testing.T) {
+	u, _ := NewUser("kid", []string{"La 1"}, nil, time.Now())
+
+	updated, err := u.Update(" Kid ", nil, []string{"kids"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updated.ID() != u.ID() || updated.PlaylistToken() != u.PlaylistToken() {
+		t.Error("expected the ID and playlist token to be kept")
+	}
+	if updated.Name() != "Kid" || len(updated.Channels()) != 0 || !slices.Equal(updated.Groups(), []string{"kids"}) {
+		t.Errorf("unexpected update %q %q %q", updated.Name(), updated.Channels(), updated.Groups())
+	}
+	if _, err := u.Update("", nil, nil); !errors.Is(err, ErrEmptyName) {
+		t.Errorf("expected ErrEmptyName, got %v", err)
+	}
+
+	rotated, err := u.WithNewPlaylistToken()
+	if err != nil {
+		t.Fatalf("WithNewPlaylistToken() error = %v", err)
+	}
+	if rotated.PlaylistToken() == u.PlaylistToken() || rotated.ID() != u.ID() {
+		t.Error("expected a new playlist token for the same user")
+	}
+}
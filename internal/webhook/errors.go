@@ -0,0 +1,15 @@
+package webhook
+
+import "errors"
+
+// Domain errors for webhook operations.
+var (
+	// Webhook validation errors
+	ErrInvalidURL    = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidFormat = errors.New("webhook format must be json, telegram or discord")
+	ErrMissingChatID = errors.New("telegram webhooks need a chat id")
+	ErrUnknownEvent  = errors.New("unknown webhook event")
+
+	// Webhook operation errors
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
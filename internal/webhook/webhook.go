@@ -0,0 +1,208 @@
+// Package webhook models the URLs notified when something significant
+// happens, such as the engine going down or a recording finishing, and the
+// payloads posted to them.
+package webhook
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Event names a kind of notification a webhook can subscribe to.
+type Event string
+
+const (
+	// EventEngineDown is sent when the AceStream engine stops responding.
+	EventEngineDown Event = "engine.down"
+	// EventStreamFailover is sent when a channel had to fall back from its
+	// preferred stream.
+	EventStreamFailover Event = "stream.failover"
+	// EventEPGSyncFailed is sent when an EPG sync ends with an error.
+	EventEPGSyncFailed Event = "epg.sync_failed"
+	// EventSourceChanged is sent when streams are added to or removed from
+	// an upstream source.
+	EventSourceChanged Event = "source.changed"
+	// EventRecordingFinished is sent when a recording ends.
+	EventRecordingFinished Event = "recording.finished"
+)
+
+// Events lists every event a webhook can subscribe to.
+var Events = []Event{EventEngineDown, EventStreamFailover, EventEPGSyncFailed, EventSourceChanged, EventRecordingFinished}
+
+// Format selects the shape of the payload posted to a webhook.
+type Format string
+
+const (
+	// FormatJSON posts the notification as a generic JSON document.
+	FormatJSON Format = "json"
+	// FormatTelegram posts a Telegram Bot API sendMessage request; the URL
+	// is https://api.telegram.org/bot<token>/sendMessage.
+	FormatTelegram Format = "telegram"
+	// FormatDiscord posts a Discord webhook message.
+	FormatDiscord Format = "discord"
+)
+
+// discordMaxContent is the longest message Discord accepts.
+const discordMaxContent = 2000
+
+// Notification is something that happened, to be posted to the webhooks
+// subscribed to its event.
+type Notification struct {
+	Event   Event
+	Time    time.Time
+	Message string
+	// Data holds event details and is included in JSON payloads only.
+	Data any
+}
+
+// Webhook is a URL notified of the events it subscribes to. A webhook with
+// no events subscribes to all of them.
+type Webhook struct {
+	id        string
+	url       string
+	format    Format
+	chatID    string
+	events    []Event
+	createdAt time.Time
+}
+
+// NewWebhook creates a webhook with a random ID. An empty format means
+// FormatJSON. Events are sorted and duplicates are dropped.
+// Returns ErrInvalidURL, ErrInvalidFormat, ErrMissingChatID for a Telegram
+// webhook without a chat ID, or ErrUnknownEvent.
+func NewWebhook(rawURL string, format Format, chatID string, events []Event, now time.Time) (Webhook, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Webhook{}, ErrInvalidURL
+	}
+
+	if format == "" {
+		format = FormatJSON
+	}
+	switch format {
+	case FormatJSON, FormatDiscord:
+	case FormatTelegram:
+		if strings.TrimSpace(chatID) == "" {
+			return Webhook{}, ErrMissingChatID
+		}
+	default:
+		return Webhook{}, ErrInvalidFormat
+	}
+
+	for _, e := range events {
+		if !slices.Contains(Events, e) {
+			return Webhook{}, ErrUnknownEvent
+		}
+	}
+	events = slices.Clone(events)
+	slices.Sort(events)
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return Webhook{}, err
+	}
+
+	return Webhook{
+		id:        hex.EncodeToString(idBytes),
+		url:       rawURL,
+		format:    format,
+		chatID:    strings.TrimSpace(chatID),
+		events:    slices.Compact(events),
+		createdAt: now,
+	}, nil
+}
+
+// ReconstructWebhook rebuilds a Webhook from persisted state.
+// This is intended for repository adapters only — it bypasses validation.
+func ReconstructWebhook(id, rawURL string, format Format, chatID string, events []Event, createdAt time.Time) Webhook {
+	return Webhook{
+		id:        id,
+		url:       rawURL,
+		format:    format,
+		chatID:    chatID,
+		events:    events,
+		createdAt: createdAt,
+	}
+}
+
+// ID returns the webhook's identifier.
+func (w Webhook) ID() string {
+	return w.id
+}
+
+// URL returns where notifications are posted.
+func (w Webhook) URL() string {
+	return w.url
+}
+
+// Format returns the shape of the payloads posted to the webhook.
+func (w Webhook) Format() Format {
+	return w.format
+}
+
+// ChatID returns the Telegram chat notifications are sent to.
+func (w Webhook) ChatID() string {
+	return w.chatID
+}
+
+// Events returns the events the webhook subscribes to, sorted. Empty means all.
+func (w Webhook) Events() []Event {
+	return slices.Clone(w.events)
+}
+
+// CreatedAt returns when the webhook was created.
+func (w Webhook) CreatedAt() time.Time {
+	return w.createdAt
+}
+
+// Subscribes reports whether the webhook is notified of the given event.
+func (w Webhook) Subscribes(e Event) bool {
+	if len(w.events) == 0 {
+		return true
+	}
+	_, ok := slices.BinarySearch(w.events, e)
+	return ok
+}
+
+// Payload renders a notification as the JSON body posted to the webhook.
+func (w Webhook) Payload(n Notification) ([]byte, error) {
+	switch w.format {
+	case FormatTelegram:
+		return json.Marshal(struct {
+			ChatID string `json:"chat_id"`
+			Text   string `json:"text"`
+		}{w.chatID, n.Message})
+	case FormatDiscord:
+		content := n.Message
+		if len(content) > discordMaxContent {
+			content = strings.ToValidUTF8(content[:discordMaxContent-3], "") + "..."
+		}
+		return json.Marshal(struct {
+			Content string `json:"content"`
+		}{content})
+	default:
+		return json.Marshal(struct {
+			Event   Event     `json:"event"`
+			Time    time.Time `json:"time"`
+			Message string    `json:"message"`
+			Data    any       `json:"data,omitempty"`
+		}{n.Event, n.Time, n.Message, n.Data})
+	}
+}
+
+// Sort orders webhooks by creation time, breaking ties by ID.
+func Sort(webhooks []Webhook) {
+	slices.SortStableFunc(webhooks, func(a, b Webhook) int {
+		if c := a.createdAt.Compare(b.createdAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+}
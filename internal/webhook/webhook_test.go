@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewWebhook(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("defaults to json and normalizes events", func(t *testing.T) {
+		w, err := NewWebhook(" https://example.com/hook ", "", "", []Event{EventRecordingFinished, EventEngineDown, EventEngineDown}, now)
+		if err != nil {
+			t.Fatalf("NewWebhook() error = %v", err)
+		}
+		if w.ID() == "" || w.URL() != "https://example.com/hook" || w.Format() != FormatJSON {
+			t.Errorf("unexpected webhook %q %q %q", w.ID(), w.URL(), w.Format())
+		}
+		if !slices.Equal(w.Events(), []Event{EventEngineDown, EventRecordingFinished}) {
+			t.Errorf("Events() = %q", w.Events())
+		}
+		if !w.CreatedAt().Equal(now) {
+			t.Errorf("CreatedAt() = %v, want %v", w.CreatedAt(), now)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		url     string
+		format  Format
+		chatID  string
+		events  []Event
+		wantErr error
+	}{
+		{"relative url", "/hook", FormatJSON, "", nil, ErrInvalidURL},
+		{"unsupported scheme", "ftp://example.com", FormatJSON, "", nil, ErrInvalidURL},
+		{"unknown format", "https://example.com", "slack", "", nil, ErrInvalidFormat},
+		{"telegram without chat", "https://api.telegram.org/botX/sendMessage", FormatTelegram, " ", nil, ErrMissingChatID},
+		{"unknown event", "https://example.com", FormatDiscord, "", []Event{"engine.up"}, ErrUnknownEvent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWebhook(tt.url, tt.format, tt.chatID, tt.events, now); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWebhook_Subscribes(t *testing.T) {
+	all, _ := NewWebhook("https://example.com", FormatJSON, "", nil, time.Now())
+	some, _ := NewWebhook("https://example.com", FormatJSON, "", []Event{EventEngineDown}, time.Now())
+
+	if !all.Subscribes(EventSourceChanged) {
+		t.Error("expected a webhook without events to subscribe to every event")
+	}
+	if !some.Subscribes(EventEngineDown) || some.Subscribes(EventSourceChanged) {
+		t.Error("expected the webhook to subscribe to its events only")
+	}
+}
+
+func TestWebhook_Payload(t *testing.T) {
+	n := Notification{
+		Event:   EventEngineDown,
+		Time:    time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC),
+		Message: "AceStream engine is down",
+		Data:    map[string]string{"error": "refused"},
+	}
+
+	tests := []struct {
+		format Format
+		chatID string
+		want   string
+	}{
+		{FormatJSON, "", `{"event":"engine.down","time":"2026-05-01T10:00:00Z","message":"AceStream engine is down","data":{"error":"refused"}}`},
+		{FormatTelegram, "42", `{"chat_id":"42","text":"AceStream engine is down"}`},
+		{FormatDiscord, "", `{"content":"AceStream engine is down"}`},
+	}
+	for _, tt := range tests {
+		w, err := NewWebhook("https://example.com", tt.format, tt.chatID, nil, time.Now())
+		if err != nil {
+			t.Fatalf("NewWebhook() error = %v", err)
+		}
+		payload, err := w.Payload(n)
+		if err != nil {
+			t.Fatalf("Payload() error = %v", err)
+		}
+		if string(payload) != tt.want {
+			t.Errorf("%s payload = %s, want %s", tt.format, payload, tt.want)
+		}
+	}
+
+	t.Run("truncates long discord messages", func(t *testing.T) {
+		w, _ := NewWebhook("https://example.com", FormatDiscord, "", nil, time.Now())
+		payload, _ := w.Payload(Notification{Message: strings.Repeat("é", discordMaxContent)})
+
+		var body struct{ Content string }
+		if err := json.Unmarshal(payload, &body); err != nil {
+			t.Fatalf("invalid payload: %v", err)
+		}
+		if len(body.Content) > discordMaxContent || !strings.HasSuffix(body.Content, "...") {
+			t.Errorf("expected content truncated to %d bytes, got %d", discordMaxContent, len(body.Content))
+		}
+	})
+}
@@ -0,0 +1,599 @@
+// Package yamlcodec reads and writes the block subset of YAML used by the
+// config file and by state documents: nested mappings and sequences, plain,
+// single- and double-quoted scalars, flow sequences of scalars, empty flow
+// collections and comments. Anchors, tags, block scalars and multiple
+// documents are rejected.
+package yamlcodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Mapping is a YAML mapping, keeping the order of its keys.
+type Mapping []MappingEntry
+
+// MappingEntry is a key of a mapping and its value.
+type MappingEntry struct {
+	Key   string
+	Value any
+}
+
+// Scalar is a scalar as written in the document. Text is the scalar with
+// its quotes and escapes resolved, so configuration values read back as
+// written; Plain is set if it was not quoted.
+type Scalar struct {
+	Text  string
+	Plain bool
+}
+
+// Value types the scalar as YAML 1.2's core schema does: plain scalars
+// become nil, bool or json.Number where they read as one, and everything
+// else a string.
+func (s Scalar) Value() any {
+	if !s.Plain {
+		return s.Text
+	}
+	switch s.Text {
+	case "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if intPattern.MatchString(s.Text) {
+		if n, err := strconv.ParseInt(s.Text, 10, 64); err == nil {
+			return json.Number(strconv.FormatInt(n, 10))
+		}
+	}
+	if floatPattern.MatchString(s.Text) {
+		if f, err := strconv.ParseFloat(s.Text, 64); err == nil {
+			return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	}
+	return s.Text
+}
+
+// line is a significant line of a YAML document.
+type line struct {
+	no     int
+	indent int
+	text   string
+}
+
+// Parse reads a YAML document into Mapping, []any and Scalar nodes, with
+// nil for empty values. An empty document is nil.
+func Parse(data []byte) (any, error) {
+	lines, err := splitLines(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	node, err := p.parseNode(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return node, nil
+}
+
+// splitLines drops blank lines, comments and document markers and measures
+// the indentation of the rest.
+func splitLines(data []byte) ([]line, error) {
+	var lines []line
+	started := false
+	for i, raw := range strings.Split(string(data), "\n") {
+		text := stripComment(strings.TrimRight(raw, " \t\r"))
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" {
+			continue
+		}
+		indent := len(text) - len(trimmed)
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs cannot indent", i+1)
+		}
+
+		if indent == 0 && (trimmed == "---" || trimmed == "...") {
+			if started {
+				return nil, fmt.Errorf("line %d: multiple documents are not supported", i+1)
+			}
+			continue
+		}
+		if indent == 0 && trimmed[0] == '%' {
+			continue
+		}
+		started = true
+		lines = append(lines, line{no: i + 1, indent: indent, text: trimmed})
+	}
+	return lines, nil
+}
+
+// stripComment cuts a comment off a line. A comment starts with "#" at the
+// start of the line or after whitespace, outside quoted scalars.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[,", s[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return strings.TrimRight(s[:i], " \t")
+		}
+	}
+	return s
+}
+
+type yamlParser struct {
+	lines []line
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.lines[p.pos].no, fmt.Sprintf(format, args...))
+}
+
+// parseNode reads the block node starting at the current line, which is
+// indented by indent.
+func (p *yamlParser) parseNode(indent int) (any, error) {
+	l := p.lines[p.pos]
+	if isSequenceItem(l.text) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok, err := splitKey(l.text); err != nil {
+		return nil, p.errorf("%v", err)
+	} else if ok {
+		return p.parseMapping(indent)
+	}
+
+	value, err := parseScalar(l.text)
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	p.pos++
+	return value, nil
+}
+
+// parseSequence reads the "- item" lines indented by indent.
+func (p *yamlParser) parseSequence(indent int) (any, error) {
+	items := []any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		l := p.lines[p.pos]
+		rest := strings.TrimLeft(l.text[1:], " ")
+
+		var item any
+		var err error
+		switch {
+		case rest == "":
+			item, err = p.parseNested(indent)
+		case isSequenceItem(rest) || isKeyValue(rest):
+			// The item is a block node starting on the same line, indented
+			// to where it starts
+			p.lines[p.pos] = line{no: l.no, indent: indent + len(l.text) - len(rest), text: rest}
+			item, err = p.parseNode(p.lines[p.pos].indent)
+		default:
+			item, err = parseScalar(rest)
+			if err != nil {
+				err = p.errorf("%v", err)
+			}
+			p.pos++
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return items, nil
+}
+
+// parseMapping reads the "key: value" lines indented by indent.
+func (p *yamlParser) parseMapping(indent int) (any, error) {
+	m := Mapping{}
+	seen := make(map[string]bool)
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && !isSequenceItem(p.lines[p.pos].text) {
+		key, rest, ok, err := splitKey(p.lines[p.pos].text)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		if !ok {
+			return nil, p.errorf(`expected "key: value"`)
+		}
+		if seen[key] {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		seen[key] = true
+
+		var value any
+		if rest == "" {
+			value, err = p.parseNested(indent)
+		} else {
+			value, err = parseScalar(rest)
+			if err != nil {
+				err = p.errorf("%v", err)
+			}
+			p.pos++
+		}
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, MappingEntry{Key: key, Value: value})
+	}
+
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return m, nil
+}
+
+// parseNested reads the value of a key or sequence item left empty on its
+// own line: a block node indented further, a sequence at the same
+// indentation, which YAML allows under a key, or otherwise null.
+func (p *yamlParser) parseNested(indent int) (any, error) {
+	parentIsItem := isSequenceItem(p.lines[p.pos].text)
+	p.pos++
+	if p.pos == len(p.lines) {
+		return nil, nil
+	}
+
+	next := p.lines[p.pos]
+	switch {
+	case next.indent > indent:
+		return p.parseNode(next.indent)
+	case next.indent == indent && !parentIsItem && isSequenceItem(next.text):
+		return p.parseSequence(indent)
+	}
+	return nil, nil
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func isKeyValue(text string) bool {
+	_, _, ok, err := splitKey(text)
+	return ok && err == nil
+}
+
+// splitKey splits a "key: value" line. ok is false if the line is not one.
+func splitKey(text string) (key, rest string, ok bool, err error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 {
+			return "", "", false, errors.New("unterminated quoted scalar")
+		}
+		after := text[end+1:]
+		if after != ":" && !strings.HasPrefix(after, ": ") {
+			return "", "", false, nil
+		}
+		k, err := parseScalar(text[:end+1])
+		if err != nil {
+			return "", "", false, err
+		}
+		return k.(Scalar).Text, strings.TrimSpace(after[1:]), true, nil
+	}
+	if strings.IndexByte("[{", text[0]) >= 0 {
+		return "", "", false, nil
+	}
+
+	if i := strings.Index(text, ": "); i >= 0 {
+		return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+2:]), true, nil
+	}
+	if strings.HasSuffix(text, ":") {
+		return strings.TrimSpace(text[:len(text)-1]), "", true, nil
+	}
+	return "", "", false, nil
+}
+
+// closingQuote returns the index of the quote closing the scalar s starts
+// with, or -1 if there is none.
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+var (
+	intPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatPattern = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// parseScalar reads a scalar or a flow sequence of scalars.
+func parseScalar(s string) (any, error) {
+	switch s[0] {
+	case '"':
+		if end := closingQuote(s); end != len(s)-1 {
+			return nil, errors.New("unterminated quoted scalar")
+		}
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted scalar %s", s)
+		}
+		return Scalar{Text: v}, nil
+	case '\'':
+		if end := closingQuote(s); end != len(s)-1 {
+			return nil, errors.New("unterminated quoted scalar")
+		}
+		return Scalar{Text: strings.ReplaceAll(s[1:len(s)-1], "''", "'")}, nil
+	case '[':
+		return parseFlowSequence(s)
+	case '{':
+		if strings.TrimSpace(s[1:]) == "}" {
+			return Mapping{}, nil
+		}
+		return nil, errors.New("flow mappings are not supported")
+	case '|', '>':
+		return nil, errors.New("block scalars are not supported")
+	case '&', '*':
+		return nil, errors.New("anchors and aliases are not supported")
+	case '!':
+		return nil, errors.New("tags are not supported")
+	}
+	return Scalar{Text: s, Plain: true}, nil
+}
+
+// parseFlowSequence reads a "[a, b]" sequence of scalars.
+func parseFlowSequence(s string) (any, error) {
+	if !strings.HasSuffix(s, "]") {
+		return nil, errors.New("unterminated flow sequence")
+	}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	items := []any{}
+	for inner != "" {
+		end := strings.IndexByte(inner, ',')
+		if inner[0] == '"' || inner[0] == '\'' {
+			q := closingQuote(inner)
+			if q < 0 {
+				return nil, errors.New("unterminated quoted scalar")
+			}
+			end = strings.IndexByte(inner[q:], ',')
+			if end >= 0 {
+				end += q
+			}
+		}
+		item := inner
+		if end >= 0 {
+			item, inner = inner[:end], strings.TrimSpace(inner[end+1:])
+		} else {
+			inner = ""
+		}
+
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return nil, errors.New("empty flow sequence item")
+		}
+		if item[0] == '[' || item[0] == '{' {
+			return nil, errors.New("nested flow collections are not supported")
+		}
+		v, err := parseScalar(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// ToJSON returns a node as JSON, typing its scalars with Scalar.Value.
+func ToJSON(node any) []byte {
+	var buf bytes.Buffer
+	writeJSON(&buf, node)
+	return buf.Bytes()
+}
+
+func writeJSON(buf *bytes.Buffer, node any) {
+	switch v := node.(type) {
+	case Mapping:
+		buf.WriteByte('{')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(e.Key)
+			buf.Write(key)
+			buf.WriteByte(':')
+			writeJSON(buf, e.Value)
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSON(buf, item)
+		}
+		buf.WriteByte(']')
+	case Scalar:
+		if n, ok := v.Value().(json.Number); ok {
+			buf.WriteString(string(n))
+			return
+		}
+		data, _ := json.Marshal(v.Value())
+		buf.Write(data)
+	default:
+		buf.WriteString("null")
+	}
+}
+
+// FromJSON reads a JSON value into the nodes Parse returns, keeping the
+// order of object keys, so it can be written as YAML with Marshal.
+func FromJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return readJSONValue(dec)
+}
+
+func readJSONValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		m := Mapping{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, MappingEntry{Key: key.(string), Value: value})
+		}
+		_, err := dec.Token()
+		return m, err
+	case json.Delim('['):
+		items := []any{}
+		for dec.More() {
+			item, err := readJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := dec.Token()
+		return items, err
+	}
+
+	switch v := tok.(type) {
+	case string:
+		return Scalar{Text: v}, nil
+	case json.Number:
+		return Scalar{Text: string(v), Plain: true}, nil
+	case bool:
+		return Scalar{Text: strconv.FormatBool(v), Plain: true}, nil
+	}
+	return nil, nil
+}
+
+// Marshal returns a Mapping or []any node as block YAML.
+func Marshal(node any) []byte {
+	var buf bytes.Buffer
+	writeYAML(&buf, node, 0)
+	return buf.Bytes()
+}
+
+// writeYAML writes a node as block YAML, indented by indent.
+func writeYAML(buf *bytes.Buffer, node any, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch v := node.(type) {
+	case Mapping:
+		for _, e := range v {
+			buf.WriteString(pad)
+			buf.WriteString(formatScalar(Scalar{Text: e.Key}))
+			buf.WriteByte(':')
+			writeYAMLValue(buf, e.Value, indent+2)
+		}
+	case []any:
+		for _, item := range v {
+			if m, ok := item.(Mapping); ok && len(m) > 0 {
+				// The item's first key goes on the dash's line
+				var nested bytes.Buffer
+				writeYAML(&nested, m, indent+2)
+				buf.WriteString(pad)
+				buf.WriteString("- ")
+				buf.Write(nested.Bytes()[indent+2:])
+				continue
+			}
+			buf.WriteString(pad)
+			buf.WriteByte('-')
+			writeYAMLValue(buf, item, indent+2)
+		}
+	}
+}
+
+// writeYAMLValue writes the value of a key or sequence item: on the same
+// line if it is a scalar or empty, and otherwise on the lines below.
+func writeYAMLValue(buf *bytes.Buffer, value any, indent int) {
+	switch v := value.(type) {
+	case Mapping:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYAML(buf, v, indent)
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteByte('\n')
+		writeYAML(buf, v, indent)
+	case Scalar:
+		buf.WriteByte(' ')
+		buf.WriteString(formatScalar(v))
+		buf.WriteByte('\n')
+	default:
+		buf.WriteString(" null\n")
+	}
+}
+
+// formatScalar formats a scalar, quoting it if it is not plain and would
+// otherwise read back as something else.
+func formatScalar(s Scalar) string {
+	if !s.Plain && needsQuotes(s.Text) {
+		return strconv.Quote(s.Text)
+	}
+	return s.Text
+}
+
+// needsQuotes reports whether a string must be quoted to read back as the
+// same string.
+func needsQuotes(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return true
+	}
+	if v, err := parseScalar(s); err != nil {
+		return true
+	} else if sc, ok := v.(Scalar); !ok || sc.Value() != any(s) {
+		return true
+	}
+	if strings.IndexByte("-?:,[]{}#&*!|>'\"%@`", s[0]) >= 0 || strings.HasSuffix(s, ":") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
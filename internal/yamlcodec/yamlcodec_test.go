@@ -0,0 +1,84 @@
+package yamlcodec
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	node, err := Parse([]byte(`# settings
+port: 08080
+ratio: 1.50
+enabled: True
+name: "a: b"
+empty:
+list: [x, 'y''s']
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := Mapping{
+		{Key: "port", Value: Scalar{Text: "08080", Plain: true}},
+		{Key: "ratio", Value: Scalar{Text: "1.50", Plain: true}},
+		{Key: "enabled", Value: Scalar{Text: "True", Plain: true}},
+		{Key: "name", Value: Scalar{Text: "a: b"}},
+		{Key: "empty", Value: nil},
+		{Key: "list", Value: []any{Scalar{Text: "x", Plain: true}, Scalar{Text: "y's"}}},
+	}
+	if !reflect.DeepEqual(node, want) {
+		t.Errorf("Parse() = %#v, want %#v", node, want)
+	}
+
+	if got := string(ToJSON(node)); got != `{"port":8080,"ratio":1.5,"enabled":true,"name":"a: b","empty":null,"list":["x","y's"]}` {
+		t.Errorf("ToJSON() = %s", got)
+	}
+}
+
+func TestScalar_Value(t *testing.T) {
+	tests := []struct {
+		scalar Scalar
+		want   any
+	}{
+		{Scalar{Text: "~", Plain: true}, nil},
+		{Scalar{Text: "false", Plain: true}, false},
+		{Scalar{Text: "-12", Plain: true}, json.Number("-12")},
+		{Scalar{Text: "1e3", Plain: true}, json.Number("1000")},
+		{Scalar{Text: "30s", Plain: true}, "30s"},
+		{Scalar{Text: "true"}, "true"},
+	}
+	for _, tt := range tests {
+		if got := tt.scalar.Value(); got != tt.want {
+			t.Errorf("%+v.Value() = %#v, want %#v", tt.scalar, got, tt.want)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	node, err := FromJSON([]byte(`{"name":"true","count":3,"tags":["a #1"],"none":null,"nested":{}}`))
+	if err != nil {
+		t.Fatalf("FromJSON() error = %v", err)
+	}
+
+	got := string(Marshal(node))
+	want := `name: "true"
+count: 3
+tags:
+  - "a #1"
+none: null
+nested: {}
+`
+	if got != want {
+		t.Errorf("Marshal() got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// What is written reads back the same
+	back, err := Parse([]byte(got))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if string(ToJSON(back)) != string(ToJSON(node)) {
+		t.Errorf("round trip got %s, want %s", ToJSON(back), ToJSON(node))
+	}
+}
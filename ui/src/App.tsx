@@ -1,11 +1,51 @@
+import { useState, useEffect } from "react";
 import { Routes, Route, Link } from "react-router-dom";
 import Channels from "./pages/Channels";
 import Streams from "./pages/Streams";
 import EPGSubscriptions from "./pages/EPGSubscriptions";
 import EPGMappingAdmin from "./pages/EPGMappingAdmin";
 import DebugStreams from "./pages/DebugStreams";
+import Tokens from "./pages/Tokens";
+import Login from "./pages/Login";
+
+type AuthState = "checking" | "anonymous" | "authenticated";
 
 export default function App() {
+  const [auth, setAuth] = useState<AuthState>("checking");
+  const [authEnabled, setAuthEnabled] = useState(false);
+
+  const checkSession = async () => {
+    try {
+      const response = await fetch("/api/auth/session");
+      if (response.status === 401) {
+        setAuth("anonymous");
+        return;
+      }
+      const data = await response.json();
+      setAuthEnabled(Boolean(data.enabled));
+      setAuth("authenticated");
+    } catch (error) {
+      console.error(error);
+      setAuth("authenticated");
+    }
+  };
+
+  useEffect(() => {
+    checkSession();
+  }, []);
+
+  const handleLogout = async () => {
+    await fetch("/api/auth/logout", { method: "POST" });
+    setAuth("anonymous");
+  };
+
+  if (auth === "checking") {
+    return null;
+  }
+  if (auth === "anonymous") {
+    return <Login onLogin={checkSession} />;
+  }
+
   return (
     <div className="mx-auto max-w-4xl p-8">
       <nav className="mb-8 flex gap-4">
@@ -14,6 +54,12 @@ export default function App() {
         <Link to="/epg-subscriptions" className="text-blue-600 hover:underline">EPG Subscriptions</Link>
         <Link to="/epg-mapping-admin" className="text-blue-600 hover:underline">EPG Mapping Admin</Link>
         <Link to="/debug/streams" className="text-blue-600 hover:underline">Debug</Link>
+        <Link to="/tokens" className="text-blue-600 hover:underline">Tokens</Link>
+        {authEnabled && (
+          <button onClick={handleLogout} className="ml-auto text-blue-600 hover:underline">
+            Log out
+          </button>
+        )}
       </nav>
       <Routes>
         <Route path="/" element={<Channels />} />
@@ -21,6 +67,7 @@ export default function App() {
         <Route path="/epg-subscriptions" element={<EPGSubscriptions />} />
         <Route path="/epg-mapping-admin" element={<EPGMappingAdmin />} />
         <Route path="/debug/streams" element={<DebugStreams />} />
+        <Route path="/tokens" element={<Tokens />} />
       </Routes>
     </div>
   );
@@ -0,0 +1,44 @@
+import { useEffect, useRef } from "react";
+
+// Event types pushed by the server on /api/events.
+export type ServerEventType =
+  | "stream.started"
+  | "stream.stopped"
+  | "stream.prebuffer"
+  | "epg.sync"
+  | "engine.health"
+  | "override.updated";
+
+export interface ServerEvent<T = unknown> {
+  type: ServerEventType;
+  time: string;
+  data?: T;
+}
+
+// useServerEvents subscribes to the live event stream and calls onEvent for
+// every event of the given types. The browser reconnects automatically if
+// the connection drops.
+export function useServerEvents(
+  types: ServerEventType[],
+  onEvent: (event: ServerEvent) => void,
+) {
+  const handlerRef = useRef(onEvent);
+  handlerRef.current = onEvent;
+
+  const key = types.join(",");
+
+  useEffect(() => {
+    const source = new EventSource("/api/events");
+    const listener = (e: MessageEvent) => {
+      handlerRef.current(JSON.parse(e.data) as ServerEvent);
+    };
+
+    const subscribed = key.split(",");
+    subscribed.forEach((type) => source.addEventListener(type, listener));
+
+    return () => {
+      subscribed.forEach((type) => source.removeEventListener(type, listener));
+      source.close();
+    };
+  }, [key]);
+}
@@ -20,6 +20,7 @@ import { Input } from "@/components/ui/input";
 import { Label } from "@/components/ui/label";
 import { Badge } from "@/components/ui/badge";
 import { toast } from "sonner";
+import { useServerEvents } from "@/lib/events";
 import { Trash2, Plus, Activity, Loader2, AlertTriangle } from "lucide-react";
 
 interface Stream {
@@ -45,6 +46,7 @@ interface ChannelHealth {
   stream_count: number;
   best_score: number;
   health_level: "green" | "yellow" | "red" | "unknown";
+  availability: "available" | "unavailable" | "unknown";
   last_probe?: ProbeResult;
   watching: number;
 }
@@ -98,9 +100,9 @@ export default function Channels() {
   const [streamFormError, setStreamFormError] = useState<string | null>(null);
   const [probingStream, setProbingStream] = useState<string | null>(null);
 
-  const fetchData = async () => {
+  const fetchData = async (showLoading = true) => {
     try {
-      setLoading(true);
+      if (showLoading) setLoading(true);
       setError(null);
 
       const [dashboardRes, streamsRes] = await Promise.all([
@@ -131,6 +133,12 @@ export default function Channels() {
     fetchData();
   }, []);
 
+  // Refresh in the background when streams, engine health or overrides change
+  useServerEvents(
+    ["stream.started", "stream.stopped", "engine.health", "override.updated"],
+    () => fetchData(false),
+  );
+
   const handleCreateChannel = async (e: React.FormEvent) => {
     e.preventDefault();
     setFormError(null);
@@ -439,6 +447,11 @@ export default function Channels() {
                         archived
                       </Badge>
                     )}
+                    {ch.availability === "unavailable" && (
+                      <Badge variant="destructive" className="text-xs">
+                        offline
+                      </Badge>
+                    )}
                   </div>
                 </td>
                 <td
@@ -11,6 +11,7 @@ interface StreamCounters {
   reconnection_attempts: number;
   reconnection_successes: number;
   clients_served: number;
+  bytes_streamed: number;
 }
 
 interface SessionDiagnostic {
@@ -24,6 +24,8 @@ interface EPGMapping {
 interface Channel {
   name: string;
   status: string;
+  available?: boolean;
+  availability?: "available" | "unavailable" | "unknown";
   epg_mapping?: EPGMapping;
 }
 
@@ -13,7 +13,7 @@ import { Input } from "@/components/ui/input";
 import { Label } from "@/components/ui/label";
 import { Badge } from "@/components/ui/badge";
 import { toast } from "sonner";
-import { Trash2 } from "lucide-react";
+import { Search, Trash2 } from "lucide-react";
 
 interface Stream {
   info_hash: string;
@@ -25,6 +25,15 @@ interface Channel {
   name: string;
 }
 
+interface SearchResult {
+  info_hash: string;
+  name: string;
+  categories: string[];
+  bitrate?: number;
+  availability: number;
+  channel_name?: string;
+}
+
 export default function Streams() {
   const [streams, setStreams] = useState<Stream[]>([]);
   const [channels, setChannels] = useState<Channel[]>([]);
@@ -36,6 +45,10 @@ export default function Streams() {
   const [searchTerm, setSearchTerm] = useState("");
   const [submitting, setSubmitting] = useState(false);
   const [formError, setFormError] = useState<string | null>(null);
+  const [searchOpen, setSearchOpen] = useState(false);
+  const [engineQuery, setEngineQuery] = useState("");
+  const [engineResults, setEngineResults] = useState<SearchResult[] | null>(null);
+  const [searching, setSearching] = useState(false);
 
   const fetchStreams = async () => {
     try {
@@ -153,6 +166,37 @@ export default function Streams() {
     }
   };
 
+  const handleEngineSearch = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!engineQuery.trim()) {
+      return;
+    }
+
+    setSearching(true);
+    try {
+      const response = await fetch(`/api/search?q=${encodeURIComponent(engineQuery)}`);
+      if (!response.ok) {
+        const errorData = await response.json().catch(() => ({}));
+        toast.error(errorData.error || "Search failed");
+        return;
+      }
+      setEngineResults(await response.json());
+    } catch (err) {
+      toast.error(err instanceof Error ? err.message : "An error occurred");
+    } finally {
+      setSearching(false);
+    }
+  };
+
+  const handleAttach = (result: SearchResult) => {
+    setSearchOpen(false);
+    setInfoHash(result.info_hash);
+    setChannelName("");
+    setSearchTerm("");
+    setFormError(null);
+    setDialogOpen(true);
+  };
+
   const filteredChannels = channels.filter((channel) =>
     channel.name.toLowerCase().includes(searchTerm.toLowerCase())
   );
@@ -254,6 +298,68 @@ export default function Streams() {
     <div>
       <div className="flex justify-between items-center mb-4">
         <h1 className="text-2xl font-bold">Streams</h1>
+        <div className="flex gap-2">
+        <Dialog open={searchOpen} onOpenChange={setSearchOpen}>
+          <DialogTrigger asChild>
+            <Button variant="outline">
+              <Search className="h-4 w-4 mr-2" />
+              Search engine
+            </Button>
+          </DialogTrigger>
+          <DialogContent className="max-w-2xl">
+            <DialogHeader>
+              <DialogTitle>Search AceStream</DialogTitle>
+              <DialogDescription>
+                Find streams known to the AceStream engine and attach them to a channel.
+              </DialogDescription>
+            </DialogHeader>
+            <form onSubmit={handleEngineSearch} className="flex gap-2">
+              <Input
+                value={engineQuery}
+                onChange={(e) => setEngineQuery(e.target.value)}
+                placeholder="Channel name..."
+                disabled={searching}
+              />
+              <Button type="submit" disabled={searching || !engineQuery.trim()}>
+                {searching ? "Searching..." : "Search"}
+              </Button>
+            </form>
+            {engineResults && engineResults.length === 0 && (
+              <p className="text-sm text-gray-600">No streams found.</p>
+            )}
+            {engineResults && engineResults.length > 0 && (
+              <div className="border rounded-md max-h-96 overflow-y-auto divide-y">
+                {engineResults.map((result) => (
+                  <div key={result.info_hash} className="flex items-center justify-between gap-4 px-4 py-2">
+                    <div className="min-w-0">
+                      <p className="text-sm font-medium truncate">{result.name}</p>
+                      <p className="text-xs text-gray-500 font-mono truncate">{result.info_hash}</p>
+                      <div className="flex gap-1 mt-1">
+                        {result.categories.map((category) => (
+                          <Badge key={category} variant="outline" className="text-xs">
+                            {category}
+                          </Badge>
+                        ))}
+                        <span className="text-xs text-gray-500">
+                          {Math.round(result.availability * 100)}% available
+                        </span>
+                      </div>
+                    </div>
+                    {result.channel_name ? (
+                      <span className="text-xs text-gray-500 whitespace-nowrap">
+                        In {result.channel_name}
+                      </span>
+                    ) : (
+                      <Button size="sm" variant="outline" onClick={() => handleAttach(result)}>
+                        Attach
+                      </Button>
+                    )}
+                  </div>
+                ))}
+              </div>
+            )}
+          </DialogContent>
+        </Dialog>
         <Dialog open={dialogOpen} onOpenChange={handleDialogOpenChange}>
           <DialogTrigger asChild>
             <Button>New stream</Button>
@@ -333,6 +439,7 @@ export default function Streams() {
             </form>
           </DialogContent>
         </Dialog>
+        </div>
       </div>
       {renderContent()}
     </div>
@@ -0,0 +1,123 @@
+import { useState, useEffect } from "react";
+import { toast } from "sonner";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Card, CardContent, CardHeader, CardTitle } from "@/components/ui/card";
+
+interface Token {
+  id: string;
+  name: string;
+  created_at: string;
+  token?: string;
+}
+
+export default function Tokens() {
+  const [tokens, setTokens] = useState<Token[]>([]);
+  const [name, setName] = useState("");
+  const [created, setCreated] = useState<Token | null>(null);
+  const [loading, setLoading] = useState(true);
+
+  useEffect(() => {
+    loadTokens();
+  }, []);
+
+  const loadTokens = async () => {
+    try {
+      const response = await fetch("/api/tokens");
+      if (!response.ok) throw new Error("Failed to load tokens");
+      const data = await response.json();
+      setTokens(data || []);
+    } catch (error) {
+      toast.error("Failed to load tokens");
+      console.error(error);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleCreate = async (e: React.FormEvent) => {
+    e.preventDefault();
+    try {
+      const response = await fetch("/api/tokens", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ name }),
+      });
+      if (!response.ok) throw new Error("Failed to create token");
+      const data: Token = await response.json();
+      setCreated(data);
+      setName("");
+      await loadTokens();
+    } catch (error) {
+      toast.error("Failed to create token");
+      console.error(error);
+    }
+  };
+
+  const handleRevoke = async (id: string) => {
+    try {
+      const response = await fetch(`/api/tokens/${id}`, { method: "DELETE" });
+      if (!response.ok) throw new Error("Failed to revoke token");
+      toast.success("Token revoked");
+      if (created?.id === id) setCreated(null);
+      await loadTokens();
+    } catch (error) {
+      toast.error("Failed to revoke token");
+      console.error(error);
+    }
+  };
+
+  return (
+    <div className="space-y-6">
+      <h1 className="text-2xl font-bold">API Tokens</h1>
+
+      <form onSubmit={handleCreate} className="flex gap-2">
+        <Input
+          placeholder="Token name (e.g. Living room TV)"
+          value={name}
+          onChange={(e) => setName(e.target.value)}
+        />
+        <Button type="submit" disabled={!name.trim()}>
+          Create
+        </Button>
+      </form>
+
+      {created?.token && (
+        <Card>
+          <CardHeader>
+            <CardTitle>New token "{created.name}"</CardTitle>
+          </CardHeader>
+          <CardContent className="space-y-2 text-sm">
+            <p>Copy it now, it will not be shown again.</p>
+            <code className="block break-all rounded bg-muted p-2">{created.token}</code>
+            <p className="text-muted-foreground">
+              Players can use <code>/playlist.m3u?token={created.token}</code>
+            </p>
+          </CardContent>
+        </Card>
+      )}
+
+      {loading ? (
+        <p>Loading...</p>
+      ) : tokens.length === 0 ? (
+        <p className="text-muted-foreground">No tokens yet.</p>
+      ) : (
+        <ul className="divide-y rounded border">
+          {tokens.map((t) => (
+            <li key={t.id} className="flex items-center justify-between p-3">
+              <div>
+                <div className="font-medium">{t.name}</div>
+                <div className="text-xs text-muted-foreground">
+                  Created {new Date(t.created_at).toLocaleString()}
+                </div>
+              </div>
+              <Button variant="destructive" size="sm" onClick={() => handleRevoke(t.id)}>
+                Revoke
+              </Button>
+            </li>
+          ))}
+        </ul>
+      )}
+    </div>
+  );
+}